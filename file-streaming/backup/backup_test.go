@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testChunker 테스트 파일이 작아도 청크가 여러 개 나오게
+var testChunker = ChunkerOptions{Min: 1 << 10, Avg: 4 << 10, Max: 16 << 10}
+
+func randomBytes(n int, seed uint64) []byte {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rng.Uint32())
+	}
+	return b
+}
+
+// makeSource 백업할 원본 트리 (상대 경로 → 내용)
+func makeSource(t *testing.T) (string, map[string][]byte) {
+	t.Helper()
+	src := t.TempDir()
+	files := map[string][]byte{
+		"big.bin":       randomBytes(200<<10, 1),
+		"notes.txt":     []byte("백업할 메모\n"),
+		"empty":         nil,
+		"sub/deep.bin":  randomBytes(50<<10, 2),
+		"sub/again.bin": randomBytes(50<<10, 2), // deep.bin 과 같은 내용 - 청크를 나눠 써
+	}
+	for rel, data := range files {
+		p := filepath.Join(src, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src, files
+}
+
+func checkRestored(t *testing.T, dst string, files map[string][]byte) {
+	t.Helper()
+	for rel, want := range files {
+		got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(rel)))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s: 복원 내용이 다름 (%d 바이트, want %d, %v)", rel, len(got), len(want), err)
+		}
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "repo")
+	src, files := makeSource(t)
+
+	repo, err := Init(dir, "secret", testChunker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Init(dir, "secret", testChunker); err == nil {
+		t.Error("이미 있는 저장소에 Init 인데 에러가 없음")
+	}
+	snap, stats, err := repo.Backup(ctx, src, BackupOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Files != 5 || stats.Bytes != snap.Size() || stats.DedupBytes < 50<<10 {
+		t.Errorf("첫 백업 = %+v, 같은 내용의 두 파일은 청크를 나눠 써야 함", stats)
+	}
+
+	// 다시 열어서 (같은 비밀번호) 복원
+	repo, err = Open(dir, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := repo.FindSnapshot("latest")
+	if err != nil || latest.ID != snap.ID {
+		t.Fatalf("FindSnapshot(latest) = %v, %v", latest, err)
+	}
+	dst := t.TempDir()
+	rs, err := repo.Restore(ctx, latest, dst, RestoreOptions{})
+	if err != nil || rs.Files != 5 || rs.Bytes != snap.Size() {
+		t.Fatalf("Restore = %+v, %v", rs, err)
+	}
+	checkRestored(t, dst, files)
+
+	// 앞에 한 바이트 끼워 넣어도 내용 기준으로 잘라서 청크 대부분을 다시 써
+	files["big.bin"] = append([]byte{'!'}, files["big.bin"]...)
+	os.WriteFile(filepath.Join(src, "big.bin"), files["big.bin"], 0644)
+	snap2, stats, err := repo.Backup(ctx, src, BackupOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap2.Parent != snap.ID || stats.Unchanged != 4 || stats.NewBytes > 40<<10 {
+		t.Errorf("두 번째 백업 = %+v (parent %q)", stats, snap2.Parent)
+	}
+
+	// 일부만 복원
+	part := t.TempDir()
+	if rs, err := repo.Restore(ctx, snap2, part, RestoreOptions{Include: []string{"sub"}}); err != nil || rs.Files != 2 {
+		t.Errorf("sub 만 복원 = %+v, %v", rs, err)
+	}
+	if _, err := os.Stat(filepath.Join(part, "big.bin")); !os.IsNotExist(err) {
+		t.Errorf("Include 밖의 파일이 복원됨: %v", err)
+	}
+
+	// 예전 스냅샷을 정리하면 더 안 쓰는 청크도 지워
+	pr, err := repo.Prune(ctx, PruneOptions{KeepLast: 1})
+	if err != nil || len(pr.Removed) != 1 || pr.DeletedChunks == 0 {
+		t.Errorf("Prune = %+v, %v", pr, err)
+	}
+	if report, err := repo.Verify(ctx, true); err != nil || !report.OK() || report.Unreferenced != 0 {
+		t.Errorf("정리 뒤 Verify = %+v, %v", report, err)
+	}
+}
+
+func TestWrongPassword(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Init(dir, "secret", testChunker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, "wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("틀린 비밀번호 = %v, want ErrWrongPassword", err)
+	}
+	if _, err := Open(t.TempDir(), "secret"); err == nil {
+		t.Error("저장소가 아닌데 에러가 없음")
+	}
+	if _, err := Init(t.TempDir(), "", testChunker); err == nil {
+		t.Error("빈 비밀번호인데 에러가 없음")
+	}
+}
+
+// 청크 파일을 한 바이트 바꾸면 검사와 복원이 알아채
+func TestTamperedChunk(t *testing.T) {
+	ctx := context.Background()
+	src, _ := makeSource(t)
+	repo, err := Init(filepath.Join(t.TempDir(), "repo"), "secret", testChunker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, _, err := repo.Backup(ctx, src, BackupOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var node Node
+	for _, n := range snap.Nodes {
+		if n.Path == "notes.txt" {
+			node = n
+		}
+	}
+	if len(node.Chunks) != 1 {
+		t.Fatalf("notes.txt 청크 = %v", node.Chunks)
+	}
+	id := node.Chunks[0]
+	data, _ := os.ReadFile(repo.chunkPath(id))
+	data[len(data)/2] ^= 0xff
+	os.WriteFile(repo.chunkPath(id), data, 0600)
+
+	// 존재만 보면 모르고, 내용까지 읽어야 알아
+	if report, _ := repo.Verify(ctx, false); !report.OK() {
+		t.Errorf("readData 없이 Verify = %+v", report)
+	}
+	report, err := repo.Verify(ctx, true)
+	if err != nil || report.OK() || len(report.Corrupt) != 1 || report.Corrupt[0] != id || len(report.Broken) != 1 {
+		t.Errorf("Verify = %+v, %v", report, err)
+	}
+
+	dst := t.TempDir()
+	var ce *CorruptError
+	if _, err := repo.Restore(ctx, snap, dst, RestoreOptions{Include: []string{"notes.txt"}}); !errors.As(err, &ce) || ce.ID != id {
+		t.Errorf("Restore = %v, want CorruptError(%s)", err, id)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("손상된 파일이 복원됨: %v", err)
+	}
+
+	// 청크가 아예 없으면 Missing
+	os.Remove(repo.chunkPath(id))
+	if report, _ := repo.Verify(ctx, false); len(report.Missing) != 1 || report.Missing[0] != id {
+		t.Errorf("지운 뒤 Verify = %+v", report)
+	}
+}
@@ -0,0 +1,132 @@
+// Package backup 은 중복 제거 + 암호화 백업 저장소야.
+// 파일을 내용 기준 가변 크기 청크로 자르고, 같은 청크는 한 번만 암호화해서 저장해.
+// 스냅샷(언제 어떤 파일이 어떤 청크로 이뤄져 있었는지)도 암호화된 매니페스트로 남겨.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/bits"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 내용 기준 청크 분할 (FastCDC)
+// ⭐ 고정 크기로 자르면 파일 앞에 한 바이트만 끼어들어도 뒤의 모든 청크가 밀려서 전부 새 청크가 돼.
+// 대신 최근 64바이트의 롤링 해시(gear)가 특정 모양일 때 자르면, 경계가 내용을 따라가서
+// 삽입/삭제 근처 청크 한두 개만 바뀌어 - 나머지는 이전 백업의 청크를 그대로 재사용해.
+
+// ChunkerOptions 청크 크기 범위 - 저장소를 만들 때 정해지고 바꾸면 중복 제거가 안 돼
+type ChunkerOptions struct {
+	Min int `json:"min"` // 이보다 짧게는 안 잘라
+	Avg int `json:"avg"` // 평균 (2의 거듭제곱)
+	Max int `json:"max"` // 경계가 안 나와도 여기서는 잘라
+}
+
+// DefaultChunkerOptions 256KB ~ 1MB ~ 4MB
+var DefaultChunkerOptions = ChunkerOptions{Min: 256 << 10, Avg: 1 << 20, Max: 4 << 20}
+
+func (o ChunkerOptions) validate() error {
+	if o.Min <= 0 || o.Min >= o.Avg || o.Avg >= o.Max || o.Avg&(o.Avg-1) != 0 {
+		return msg.New("청크 크기는 0 < Min < Avg < Max, Avg 는 2의 거듭제곱이어야 합니다")
+	}
+	return nil
+}
+
+// gearTable 바이트마다 섞을 난수 - 저장소 키에서 만들어서, 청크 경계만 보고 내용을 추측할 수 없게 해
+type gearTable [256]uint64
+
+func newGearTable(seed []byte) *gearTable {
+	var t gearTable
+	for i := range t {
+		sum := sha256.Sum256(append(append([]byte(nil), seed...), byte(i)))
+		t[i] = binary.LittleEndian.Uint64(sum[:8])
+	}
+	return &t
+}
+
+// Chunker r 을 청크로 잘라서 하나씩 돌려줘
+type Chunker struct {
+	r          io.Reader
+	gear       *gearTable
+	opts       ChunkerOptions
+	maskS      uint64 // 평균보다 짧은 구간: 잘 안 맞는 마스크 (너무 작은 청크 억제)
+	maskL      uint64 // 평균을 넘은 구간: 잘 맞는 마스크 (너무 큰 청크 억제)
+	buf        []byte
+	start, end int
+	eof        bool
+}
+
+func newChunker(r io.Reader, gear *gearTable, opts ChunkerOptions) *Chunker {
+	b := bits.TrailingZeros(uint(opts.Avg))
+	return &Chunker{
+		r:     r,
+		gear:  gear,
+		opts:  opts,
+		maskS: ^uint64(0) << (64 - (b + 2)),
+		maskL: ^uint64(0) << (64 - (b - 2)),
+		buf:   make([]byte, opts.Max),
+	}
+}
+
+// Next 다음 청크 - 돌려준 슬라이스는 다음 Next 호출 전까지만 유효해. 끝나면 io.EOF
+func (c *Chunker) Next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+	n := c.cut(c.buf[c.start:c.end])
+	chunk := c.buf[c.start : c.start+n]
+	c.start += n
+	return chunk, nil
+}
+
+// fill 버퍼에 최대 크기만큼 채워 (남은 부분은 앞으로 당겨)
+func (c *Chunker) fill() error {
+	if c.eof || c.end-c.start >= c.opts.Max {
+		return nil
+	}
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	for c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cut data 앞에서 자를 위치
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.opts.Min {
+		return n
+	}
+	normal := min(c.opts.Avg, n)
+	limit := min(c.opts.Max, n)
+
+	var h uint64
+	i := c.opts.Min
+	for ; i < normal; i++ {
+		h = h<<1 + c.gear[data[i]]
+		if h&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < limit; i++ {
+		h = h<<1 + c.gear[data[i]]
+		if h&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
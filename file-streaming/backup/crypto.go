@@ -0,0 +1,134 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"golang.org/x/crypto/scrypt"
+)
+
+// 암호화 계층
+// ⭐ 저장소에는 무작위 마스터 키(암호화용 + 청크 ID 용)가 있고, 그 키를 비밀번호에서 유도한 키로 감싸서 key 파일에 둬.
+// 비밀번호를 바꿔도 key 파일만 다시 쓰면 되고, 청크는 그대로야.
+// 청크 ID 도 평범한 SHA-256 대신 HMAC 이라서, 저장소만 본 사람은 "이 파일이 들어 있나" 를 해시로 확인할 수 없어.
+
+const keySize = 32
+
+// ErrWrongPassword 비밀번호가 틀림 (또는 key 파일이 손상됨 - 둘은 구분할 수 없어)
+var ErrWrongPassword = msg.New("비밀번호가 틀렸거나 key 파일이 손상됐습니다")
+
+// keyFile 디스크의 key 파일 (JSON) - 비밀번호로 감싼 마스터 키
+type keyFile struct {
+	KDF     string    `json:"kdf"`
+	N       int       `json:"n"`
+	R       int       `json:"r"`
+	P       int       `json:"p"`
+	Salt    []byte    `json:"salt"`
+	Data    []byte    `json:"data"` // 감싼 마스터 키 (nonce + AES-GCM)
+	Created time.Time `json:"created"`
+}
+
+// keys 열린 저장소의 키
+type keys struct {
+	aead cipher.AEAD
+	id   []byte // 청크 ID(HMAC) 키
+}
+
+func newKeys(master []byte) (*keys, error) {
+	if len(master) != 2*keySize {
+		return nil, msg.New("마스터 키 길이가 이상합니다")
+	}
+	aead, err := newAEAD(master[:keySize])
+	if err != nil {
+		return nil, err
+	}
+	return &keys{aead: aead, id: master[keySize:]}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal nonce + 암호문 (nonce 는 매번 무작위 12바이트)
+func seal(aead cipher.AEAD, plain []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	rand.Read(nonce)
+	return aead.Seal(nonce, nonce, plain, nil)
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, msg.New("암호문이 너무 짧습니다")
+	}
+	ns := aead.NonceSize()
+	return aead.Open(nil, data[:ns], data[ns:], nil)
+}
+
+func (k *keys) seal(plain []byte) []byte { return seal(k.aead, plain) }
+
+func (k *keys) open(data []byte) ([]byte, error) { return open(k.aead, data) }
+
+// chunkID 평문 청크의 ID (HMAC-SHA256, hex)
+func (k *keys) chunkID(plain []byte) string {
+	h := hmac.New(sha256.New, k.id)
+	h.Write(plain)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// gearSeed 청크 경계용 난수 테이블의 시드 (ID 키에서 파생)
+func (k *keys) gearSeed() []byte {
+	h := hmac.New(sha256.New, k.id)
+	h.Write([]byte("chunker"))
+	return h.Sum(nil)
+}
+
+// newKeyFile 새 마스터 키를 만들어 password 로 감싸
+func newKeyFile(password string) (*keyFile, []byte, error) {
+	master := make([]byte, 2*keySize)
+	if _, err := rand.Read(master); err != nil {
+		return nil, nil, err
+	}
+	kf := &keyFile{KDF: "scrypt", N: 1 << 15, R: 8, P: 1, Salt: make([]byte, 16), Created: time.Now().UTC()}
+	rand.Read(kf.Salt)
+	wrap, err := kf.wrapKey(password)
+	if err != nil {
+		return nil, nil, err
+	}
+	kf.Data = seal(wrap, master)
+	return kf, master, nil
+}
+
+// wrapKey 비밀번호 → 감싸는 키 (scrypt 로 일부러 느리게 해서 비밀번호 대입을 어렵게)
+func (kf *keyFile) wrapKey(password string) (cipher.AEAD, error) {
+	if kf.KDF != "scrypt" {
+		return nil, msg.Errorf("지원하지 않는 키 유도 방식: %q", kf.KDF)
+	}
+	key, err := scrypt.Key([]byte(password), kf.Salt, kf.N, kf.R, kf.P, keySize)
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(key)
+}
+
+// unlock password 로 마스터 키 꺼내기
+func (kf *keyFile) unlock(password string) ([]byte, error) {
+	wrap, err := kf.wrapKey(password)
+	if err != nil {
+		return nil, err
+	}
+	master, err := open(wrap, kf.Data)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	return master, nil
+}
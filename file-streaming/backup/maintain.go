@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// VerifyReport 검사 결과
+type VerifyReport struct {
+	Snapshots    int      `json:"snapshots"`
+	Chunks       int      `json:"chunks"`                 // 스냅샷들이 참조하는 청크 수 (중복 제외)
+	Missing      []string `json:"missing,omitempty"`      // 참조하는데 없는 청크
+	Corrupt      []string `json:"corrupt,omitempty"`      // 복호화 실패 또는 ID 불일치 (ReadData 일 때만)
+	Unreferenced int      `json:"unreferenced"`           // 아무 스냅샷도 안 쓰는 청크 (prune 대상)
+	Broken       []string `json:"broken_snaps,omitempty"` // 청크가 빠지거나 손상돼서 온전히 복원할 수 없는 스냅샷
+}
+
+// OK 문제가 없는지
+func (v *VerifyReport) OK() bool {
+	return len(v.Missing) == 0 && len(v.Corrupt) == 0
+}
+
+// Verify 모든 스냅샷이 참조하는 청크가 있는지 (readData 면 전부 읽어서 복호화와 ID 까지) 확인
+func (r *Repo) Verify(ctx context.Context, readData bool) (*VerifyReport, error) {
+	snaps, err := r.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := r.listChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool, len(stored))
+	for _, c := range stored {
+		have[c.id] = true
+	}
+
+	report := &VerifyReport{Snapshots: len(snaps)}
+	bad := map[string]bool{}
+	checked := map[string]bool{}
+	for _, s := range snaps {
+		broken := false
+		for _, n := range s.Nodes {
+			for _, id := range n.Chunks {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				if !checked[id] {
+					checked[id] = true
+					switch {
+					case !have[id]:
+						report.Missing = append(report.Missing, id)
+						bad[id] = true
+					case readData:
+						if _, err := r.loadChunk(id); err != nil {
+							var ce *CorruptError
+							if !errors.As(err, &ce) {
+								return nil, err
+							}
+							report.Corrupt = append(report.Corrupt, id)
+							bad[id] = true
+						}
+					}
+				}
+				broken = broken || bad[id]
+			}
+		}
+		if broken {
+			report.Broken = append(report.Broken, s.ID)
+		}
+	}
+	report.Chunks = len(checked)
+	for id := range have {
+		if !checked[id] {
+			report.Unreferenced++
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Corrupt)
+	return report, nil
+}
+
+// PruneOptions 보존 규칙 - 원본 디렉토리마다 따로 적용하고, 둘 중 하나라도 맞으면 남겨
+type PruneOptions struct {
+	KeepLast   int           // 최근 N 개
+	KeepWithin time.Duration // 최근 이 기간 안의 것
+	DryRun     bool          // 지우지 않고 결과만 계산
+}
+
+// PruneReport 정리 결과
+type PruneReport struct {
+	Kept          []string `json:"kept"`
+	Removed       []string `json:"removed"`
+	DeletedChunks int      `json:"deleted_chunks"`
+	FreedBytes    int64    `json:"freed_bytes"`
+	DryRun        bool     `json:"dry_run"`
+}
+
+// Prune 보존 규칙에 안 맞는 스냅샷을 지우고, 남은 스냅샷이 안 쓰는 청크를 지워 (mark & sweep)
+func (r *Repo) Prune(ctx context.Context, opts PruneOptions) (*PruneReport, error) {
+	if opts.KeepLast <= 0 && opts.KeepWithin <= 0 {
+		return nil, msg.New("보존 규칙(KeepLast 또는 KeepWithin)이 없으면 스냅샷이 전부 지워집니다")
+	}
+	unlock, err := r.Lock("prune")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	snaps, err := r.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	report := &PruneReport{DryRun: opts.DryRun}
+	keep := keepSet(snaps, opts, time.Now())
+	used := map[string]bool{}
+	for _, s := range snaps {
+		if !keep[s.ID] {
+			report.Removed = append(report.Removed, s.ID)
+			continue
+		}
+		report.Kept = append(report.Kept, s.ID)
+		for _, n := range s.Nodes {
+			for _, id := range n.Chunks {
+				used[id] = true
+			}
+		}
+	}
+
+	// ⭐ 스냅샷을 먼저 지우고 청크를 나중에 - 반대 순서로 하다 중간에 죽으면 청크 빠진 스냅샷이 남아
+	if !opts.DryRun {
+		for _, id := range report.Removed {
+			if err := os.Remove(filepath.Join(r.snapshotDir(), id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return report, err
+			}
+		}
+	}
+
+	chunks, err := r.listChunks(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, c := range chunks {
+		if used[c.id] {
+			continue
+		}
+		if !opts.DryRun {
+			if err := os.Remove(r.chunkPath(c.id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return report, err
+			}
+		}
+		report.DeletedChunks++
+		report.FreedBytes += c.size
+	}
+	return report, nil
+}
+
+// keepSet 원본별로 최근 KeepLast 개와 KeepWithin 안의 스냅샷 (snaps 는 오래된 순)
+func keepSet(snaps []*Snapshot, opts PruneOptions, now time.Time) map[string]bool {
+	keep := map[string]bool{}
+	count := map[string]int{}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		s := snaps[i]
+		count[s.Source]++
+		if count[s.Source] <= opts.KeepLast || (opts.KeepWithin > 0 && now.Sub(s.Time) <= opts.KeepWithin) {
+			keep[s.ID] = true
+		}
+	}
+	return keep
+}
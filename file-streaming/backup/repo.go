@@ -0,0 +1,259 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 저장소 구조
+//
+//	repo/
+//	  config          저장소 설정 (청크 크기 범위) - 평문
+//	  key             비밀번호로 감싼 마스터 키
+//	  data/ab/abcd... 암호화된 청크 (ID 앞 두 글자로 디렉토리를 나눠서 한 디렉토리에 파일이 몰리지 않게)
+//	  snapshots/<ID>  암호화된 스냅샷 매니페스트
+//	  lock            백업/정리 중일 때만 있는 잠금 파일
+
+const repoVersion = 1
+
+// Config 저장소 설정
+type Config struct {
+	Version int            `json:"version"`
+	Chunker ChunkerOptions `json:"chunker"`
+	Created time.Time      `json:"created"`
+}
+
+// Repo 열린 저장소
+type Repo struct {
+	Dir    string
+	Config Config
+	keys   *keys
+	gear   *gearTable
+}
+
+func (r *Repo) dataDir() string     { return filepath.Join(r.Dir, "data") }
+func (r *Repo) snapshotDir() string { return filepath.Join(r.Dir, "snapshots") }
+
+func (r *Repo) chunkPath(id string) string {
+	return filepath.Join(r.dataDir(), id[:2], id)
+}
+
+// Init dir 에 새 저장소를 만들어 (이미 있으면 에러)
+func Init(dir, password string, chunker ChunkerOptions) (*Repo, error) {
+	if password == "" {
+		return nil, msg.New("비밀번호가 비어 있습니다")
+	}
+	if err := chunker.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config")); err == nil {
+		return nil, msg.Errorf("이미 저장소가 있습니다: %s", dir)
+	}
+	for _, sub := range []string{"data", "snapshots"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	kf, master, err := newKeyFile(password)
+	if err != nil {
+		return nil, err
+	}
+	cfg := Config{Version: repoVersion, Chunker: chunker, Created: kf.Created}
+	if err := writeJSON(filepath.Join(dir, "key"), kf); err != nil {
+		return nil, err
+	}
+	// config 를 마지막에 써야 중간에 실패한 디렉토리를 저장소로 착각하지 않아
+	if err := writeJSON(filepath.Join(dir, "config"), cfg); err != nil {
+		return nil, err
+	}
+	return newRepo(dir, cfg, master)
+}
+
+// Open 기존 저장소 열기
+func Open(dir, password string) (*Repo, error) {
+	var cfg Config
+	if err := readJSON(filepath.Join(dir, "config"), &cfg); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, msg.Errorf("저장소가 아닙니다 (먼저 init 하세요): %s", dir)
+		}
+		return nil, err
+	}
+	if cfg.Version != repoVersion {
+		return nil, msg.Errorf("지원하지 않는 저장소 버전: %d", cfg.Version)
+	}
+	var kf keyFile
+	if err := readJSON(filepath.Join(dir, "key"), &kf); err != nil {
+		return nil, err
+	}
+	master, err := kf.unlock(password)
+	if err != nil {
+		return nil, err
+	}
+	return newRepo(dir, cfg, master)
+}
+
+func newRepo(dir string, cfg Config, master []byte) (*Repo, error) {
+	k, err := newKeys(master)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Dir: dir, Config: cfg, keys: k, gear: newGearTable(k.gearSeed())}, nil
+}
+
+// Lock 저장소 잠금 - 백업과 정리가 동시에 돌면, 정리가 방금 재사용하기로 한 청크를 지울 수 있어
+// ⭐ O_EXCL 로 파일을 만드는 방식이라 프로세스가 죽으면 남아 - 그때는 내용(누가, 언제)을 보고 직접 지워
+func (r *Repo) Lock(op string) (unlock func() error, err error) {
+	name := filepath.Join(r.Dir, "lock")
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		owner, _ := os.ReadFile(name)
+		return nil, msg.Errorf("저장소가 잠겨 있습니다 (%s) - 실행 중인 작업이 없으면 %s 를 지우세요", strings.TrimSpace(string(owner)), name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	fmt.Fprintf(f, "%s pid=%d host=%s at=%s\n", op, os.Getpid(), host, time.Now().Format(time.RFC3339))
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return nil, err
+	}
+	return func() error { return os.Remove(name) }, nil
+}
+
+// UnlockStale 죽은 프로세스가 남긴 잠금 지우기 - 잠금 내용의 pid/host 가 맞을 때만 (다른 작업의 잠금은 건드리지 않아)
+// 저장소를 열지 않아도 돼서 비밀번호 없이 부를 수 있어. 지웠으면 true.
+func UnlockStale(dir string, pid int, host string) (bool, error) {
+	name := filepath.Join(dir, "lock")
+	owner, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !strings.Contains(string(owner), fmt.Sprintf(" pid=%d host=%s ", pid, host)) {
+		return false, nil
+	}
+	return true, os.Remove(name)
+}
+
+// hasChunk 이미 저장된 청크인지
+func (r *Repo) hasChunk(id string) bool {
+	_, err := os.Stat(r.chunkPath(id))
+	return err == nil
+}
+
+// saveChunk 청크를 암호화해서 저장 - 디스크에 쓴 바이트 수를 돌려줘
+func (r *Repo) saveChunk(id string, plain []byte) (int64, error) {
+	data := r.keys.seal(plain)
+	if err := writeAtomic(r.chunkPath(id), data); err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// loadChunk 청크를 읽고 복호화 - ID 도 다시 계산해서 다른 청크와 바뀌지 않았는지 확인해
+func (r *Repo) loadChunk(id string) ([]byte, error) {
+	data, err := os.ReadFile(r.chunkPath(id))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := r.keys.open(data)
+	if err != nil {
+		return nil, &CorruptError{ID: id, Err: err}
+	}
+	if got := r.keys.chunkID(plain); got != id {
+		return nil, &CorruptError{ID: id, Err: msg.Errorf("내용의 ID 가 다름 (%s)", got[:12])}
+	}
+	return plain, nil
+}
+
+// CorruptError 청크를 복호화할 수 없거나 내용이 ID 와 다름
+type CorruptError struct {
+	ID  string
+	Err error
+}
+
+func (e *CorruptError) Error() string { return msg.Sprintf("손상된 청크 %s: %v", e.ID, e.Err) }
+func (e *CorruptError) Unwrap() error { return e.Err }
+
+// chunkFile 저장소의 청크 파일 하나
+type chunkFile struct {
+	id   string
+	size int64
+}
+
+// listChunks 저장된 모든 청크
+func (r *Repo) listChunks(ctx context.Context) ([]chunkFile, error) {
+	var chunks []chunkFile
+	err := filepath.WalkDir(r.dataDir(), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, chunkFile{id: d.Name(), size: info.Size()})
+		return nil
+	})
+	return chunks, err
+}
+
+// writeAtomic 같은 디렉토리 임시 파일에 쓰고 rename (중간에 죽어도 반쪽짜리 청크가 안 남게)
+func writeAtomic(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // rename 에 성공하면 없는 파일이라 무시돼
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), name)
+}
+
+func writeJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(name, append(data, '\n'))
+}
+
+func readJSON(name string, v any) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,164 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// RestoreOptions 복원 옵션
+type RestoreOptions struct {
+	Include []string       // 이 glob 에 맞는 경로만 (비우면 전부, path.Match 규칙, 상대 경로 기준)
+	Hooks   streamio.Hooks // 진행률은 복원한 바이트 합계
+}
+
+func (o RestoreOptions) hooks() streamio.Hooks {
+	if o.Hooks == nil {
+		return streamio.NopHooks{}
+	}
+	return o.Hooks
+}
+
+func (o RestoreOptions) match(rel string) bool {
+	if len(o.Include) == 0 {
+		return true
+	}
+	for _, pattern := range o.Include {
+		// 디렉토리 패턴이면 그 아래 전부
+		for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RestoreStats 복원 결과
+type RestoreStats struct {
+	Files int   `json:"files"`
+	Dirs  int   `json:"dirs"`
+	Links int   `json:"links"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Restore 스냅샷을 dst 디렉토리 아래에 되살려 (있는 파일은 덮어써)
+// ⭐ 파일은 임시 파일에 청크를 이어 쓴 뒤 rename 해서, 중간에 실패해도 원래 있던 파일이 반쯤 깨지지 않아
+func (r *Repo) Restore(ctx context.Context, snap *Snapshot, dst string, opts RestoreOptions) (*RestoreStats, error) {
+	hooks := opts.hooks()
+	var total int64
+	for _, n := range snap.Nodes {
+		if opts.match(n.Path) {
+			total += n.Size
+		}
+	}
+	info := streamio.TransferInfo{ID: snap.ID, Src: r.Dir, Dst: dst, Size: total}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	stats := &RestoreStats{}
+	err := r.restore(ctx, snap, dst, opts, stats, func(n int64) { hooks.OnProgress(info, n) })
+	if err != nil {
+		hooks.OnError(info, err)
+		return nil, err
+	}
+	hooks.OnComplete(info, stats.Bytes, time.Since(start))
+	return stats, nil
+}
+
+func (r *Repo) restore(ctx context.Context, snap *Snapshot, dst string, opts RestoreOptions, stats *RestoreStats, progress func(int64)) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	var dirs []Node
+	for _, n := range snap.Nodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !opts.match(n.Path) {
+			continue
+		}
+		// 스냅샷이 조작됐어도 dst 밖에는 못 쓰게
+		if !filepath.IsLocal(filepath.FromSlash(n.Path)) {
+			return msg.Errorf("스냅샷의 경로가 이상합니다: %q", n.Path)
+		}
+		target := filepath.Join(dst, filepath.FromSlash(n.Path))
+
+		switch n.Type {
+		case TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, n)
+			stats.Dirs++
+		case TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(n.Target, target); err != nil {
+				return err
+			}
+			stats.Links++
+		case TypeFile:
+			if err := r.restoreFile(ctx, n, target, stats, progress); err != nil {
+				return fmt.Errorf("%s: %w", n.Path, err)
+			}
+			stats.Files++
+		}
+	}
+
+	// 디렉토리 권한/시각은 안에 파일을 다 만든 다음에 (안쪽부터) - 먼저 하면 파일을 만들면서 시각이 바뀌어
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target := filepath.Join(dst, filepath.FromSlash(dirs[i].Path))
+		os.Chmod(target, dirs[i].Mode)
+		os.Chtimes(target, dirs[i].ModTime, dirs[i].ModTime)
+	}
+	return nil
+}
+
+func (r *Repo) restoreFile(ctx context.Context, n Node, target string, stats *RestoreStats, progress func(int64)) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	for _, id := range n.Chunks {
+		if err := ctx.Err(); err != nil {
+			tmp.Close()
+			return err
+		}
+		chunk, err := r.loadChunk(id)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if _, err := tmp.Write(chunk); err != nil {
+			tmp.Close()
+			return err
+		}
+		stats.Bytes += int64(len(chunk))
+		progress(stats.Bytes)
+	}
+	if err := tmp.Chmod(n.Mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), n.ModTime, n.ModTime); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), target)
+}
@@ -0,0 +1,311 @@
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Node 스냅샷 안의 파일/디렉토리/링크 하나
+type Node struct {
+	Path    string      `json:"path"` // 원본 디렉토리 기준 상대 경로 (슬래시 구분)
+	Type    string      `json:"type"` // file, dir, symlink
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mtime"`
+	Size    int64       `json:"size,omitempty"`
+	Target  string      `json:"target,omitempty"` // symlink 가 가리키는 곳
+	Chunks  []string    `json:"chunks,omitempty"` // 파일 내용을 이루는 청크 ID 순서대로
+}
+
+const (
+	TypeFile    = "file"
+	TypeDir     = "dir"
+	TypeSymlink = "symlink"
+)
+
+// Snapshot 한 번의 백업 - 파일 목록과 각 파일의 청크 순서 (매니페스트)
+type Snapshot struct {
+	ID     string    `json:"id"`
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"` // 백업한 디렉토리 (절대 경로)
+	Host   string    `json:"host"`
+	Parent string    `json:"parent,omitempty"` // 바뀌지 않은 파일을 가져온 이전 스냅샷
+	Nodes  []Node    `json:"nodes"`
+}
+
+// Size 파일 내용 전체 크기
+func (s *Snapshot) Size() int64 {
+	var n int64
+	for _, node := range s.Nodes {
+		n += node.Size
+	}
+	return n
+}
+
+func newSnapshotID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (r *Repo) saveSnapshot(s *Snapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(r.snapshotDir(), s.ID), r.keys.seal(data))
+}
+
+// LoadSnapshot ID 로 스냅샷 읽기
+func (r *Repo) LoadSnapshot(id string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(r.snapshotDir(), id))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := r.keys.open(data)
+	if err != nil {
+		return nil, msg.Errorf("스냅샷 %s 를 복호화할 수 없습니다: %w", id, err)
+	}
+	s := new(Snapshot)
+	if err := json.Unmarshal(plain, s); err != nil {
+		return nil, msg.Errorf("스냅샷 %s: %w", id, err)
+	}
+	return s, nil
+}
+
+// Snapshots 모든 스냅샷, 오래된 순
+func (r *Repo) Snapshots() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(r.snapshotDir())
+	if err != nil {
+		return nil, err
+	}
+	var list []*Snapshot
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		s, err := r.LoadSnapshot(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+	return list, nil
+}
+
+// FindSnapshot ID 앞부분(겹치지 않을 만큼) 또는 "latest" 로 찾기
+func (r *Repo) FindSnapshot(ref string) (*Snapshot, error) {
+	list, err := r.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	if ref == "latest" {
+		if len(list) == 0 {
+			return nil, msg.New("스냅샷이 없습니다")
+		}
+		return list[len(list)-1], nil
+	}
+	var found *Snapshot
+	for _, s := range list {
+		if strings.HasPrefix(s.ID, ref) {
+			if found != nil {
+				return nil, msg.Errorf("스냅샷 ID %q 에 해당하는 게 여러 개입니다", ref)
+			}
+			found = s
+		}
+	}
+	if found == nil {
+		return nil, msg.Errorf("스냅샷 없음: %s", ref)
+	}
+	return found, nil
+}
+
+// BackupOptions 백업 옵션
+type BackupOptions struct {
+	Exclude []string       // 제외할 glob (fstree.WalkOptions 와 같음)
+	Force   bool           // 이전 스냅샷과 크기/수정 시각이 같아도 다시 읽어
+	Hooks   streamio.Hooks // 진행률은 읽은 바이트 합계 (새로 읽은 파일만)
+}
+
+func (o BackupOptions) hooks() streamio.Hooks {
+	if o.Hooks == nil {
+		return streamio.NopHooks{}
+	}
+	return o.Hooks
+}
+
+// BackupStats 백업 결과
+type BackupStats struct {
+	Files      int     `json:"files"`
+	Dirs       int     `json:"dirs"`
+	Unchanged  int     `json:"unchanged"`   // 이전 스냅샷에서 그대로 가져온 파일
+	Bytes      int64   `json:"bytes"`       // 파일 내용 전체
+	ReadBytes  int64   `json:"read_bytes"`  // 이번에 실제로 읽은 바이트
+	NewChunks  int     `json:"new_chunks"`  // 새로 저장한 청크
+	NewBytes   int64   `json:"new_bytes"`   // 새 청크의 원래 크기
+	Stored     int64   `json:"stored"`      // 새 청크가 디스크에 차지한 크기 (암호화 후)
+	DedupBytes int64   `json:"dedup_bytes"` // 읽었지만 이미 있는 청크라 안 쓴 바이트
+	Errors     []error `json:"-"`           // 읽지 못해서 빠진 파일 (백업은 계속돼)
+}
+
+// Backup src 디렉토리를 새 스냅샷으로
+// ⭐ 같은 원본의 직전 스냅샷을 parent 로 잡아서, 크기와 수정 시각이 같은 파일은 읽지도 않고 청크 목록을 재사용해
+func (r *Repo) Backup(ctx context.Context, src string, opts BackupOptions) (*Snapshot, *BackupStats, error) {
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fi, err := os.Stat(abs); err != nil {
+		return nil, nil, err
+	} else if !fi.IsDir() {
+		return nil, nil, msg.Errorf("디렉토리가 아닙니다: %s", src)
+	}
+
+	unlock, err := r.Lock("backup")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	host, _ := os.Hostname()
+	snap := &Snapshot{ID: newSnapshotID(), Time: time.Now(), Source: abs, Host: host}
+	parent := map[string]Node{}
+	if !opts.Force {
+		if p, err := r.latestOf(abs); err != nil {
+			return nil, nil, err
+		} else if p != nil {
+			snap.Parent = p.ID
+			for _, n := range p.Nodes {
+				parent[n.Path] = n
+			}
+		}
+	}
+
+	hooks := opts.hooks()
+	info := streamio.TransferInfo{ID: filepath.Base(abs), Src: abs, Dst: r.Dir, Size: -1}
+	hooks.OnStart(info)
+	start := time.Now()
+	stats := &BackupStats{}
+	progress := func(n int64) { hooks.OnProgress(info, stats.ReadBytes+n) }
+
+	walk := fstree.WalkOptions{Exclude: opts.Exclude, IncludeDirs: true, Symlinks: fstree.SymlinkCopy}
+	for e := range fstree.Walk(ctx, abs, walk) {
+		if e.Err != nil {
+			stats.Errors = append(stats.Errors, e.Err)
+			continue
+		}
+		node := Node{Path: e.RelPath, Mode: e.Info.Mode().Perm(), ModTime: e.Info.ModTime()}
+		switch {
+		case e.Info.IsDir():
+			node.Type = TypeDir
+			stats.Dirs++
+		case e.Info.Mode()&fs.ModeSymlink != 0:
+			node.Type = TypeSymlink
+			if node.Target, err = os.Readlink(e.Path); err != nil {
+				stats.Errors = append(stats.Errors, err)
+				continue
+			}
+		case e.Info.Mode().IsRegular():
+			node.Type = TypeFile
+			node.Size = e.Info.Size()
+			if old, ok := parent[e.RelPath]; ok && old.Type == TypeFile && old.Size == node.Size && old.ModTime.Equal(node.ModTime) {
+				node.Chunks = old.Chunks
+				stats.Unchanged++
+			} else if node.Chunks, err = r.backupFile(ctx, e.Path, stats, progress); err != nil {
+				if ctx.Err() != nil {
+					hooks.OnError(info, ctx.Err())
+					return nil, nil, ctx.Err()
+				}
+				stats.Errors = append(stats.Errors, err)
+				continue
+			}
+			stats.Files++
+			stats.Bytes += node.Size
+		default:
+			continue // 소켓, 장치 파일 등은 백업하지 않아
+		}
+		snap.Nodes = append(snap.Nodes, node)
+	}
+	if err := ctx.Err(); err != nil {
+		hooks.OnError(info, err)
+		return nil, nil, err
+	}
+
+	// ⭐ 스냅샷은 모든 청크를 쓴 다음에 저장 - 중간에 죽으면 청크만 남고(prune 이 치워) 반쪽 스냅샷은 안 생겨
+	if err := r.saveSnapshot(snap); err != nil {
+		hooks.OnError(info, err)
+		return nil, nil, err
+	}
+	hooks.OnComplete(info, stats.ReadBytes, time.Since(start))
+	return snap, stats, nil
+}
+
+// latestOf source 의 가장 최근 스냅샷 (없으면 nil)
+func (r *Repo) latestOf(source string) (*Snapshot, error) {
+	list, err := r.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].Source == source {
+			return list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// backupFile 파일을 청크로 잘라 없는 청크만 저장
+func (r *Repo) backupFile(ctx context.Context, name string, stats *BackupStats, progress func(int64)) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var read int64
+	var ids []string
+	c := newChunker(f, r.gear, r.Config.Chunker)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		id := r.keys.chunkID(chunk)
+		if r.hasChunk(id) {
+			stats.DedupBytes += int64(len(chunk))
+		} else {
+			n, err := r.saveChunk(id, chunk)
+			if err != nil {
+				return nil, err
+			}
+			stats.NewChunks++
+			stats.NewBytes += int64(len(chunk))
+			stats.Stored += n
+		}
+		ids = append(ids, id)
+		read += int64(len(chunk))
+		progress(read)
+	}
+	stats.ReadBytes += read
+	return ids, nil
+}
@@ -0,0 +1,316 @@
+// Package cas 는 내용 주소 저장소(content-addressable storage)야 - 파일 내용을 sha256 으로 한 번만 저장하고 이름은 그 해시를 가리켜.
+// 같은 내용을 다른 이름으로 백 번 올려도 디스크는 한 벌만 써.
+//
+// ⭐ 블롭은 store/ab/cdef… (해시 앞 두 글자가 디렉토리)에 두고, 이름이 있는 디렉토리(서버의 uploads)의 파일은 그 블롭의 하드 링크야 -
+// 그래서 목록, 정적 서빙, 검색처럼 디렉토리를 그대로 읽는 쪽은 아무것도 몰라도 되고, 이름 → 해시 색인(store/index.json)이 참조 수를 세서
+// 마지막 이름이 지워지면 블롭도 지워. 하드 링크라 저장소와 이름 디렉토리는 같은 파일시스템이어야 해 (Open 이 확인해).
+// 블롭은 읽기 전용(0444)이라 한 이름으로 내용을 고쳐서 같은 내용을 가리키는 다른 이름까지 바뀌는 일은 없어.
+//
+//	st, _ := cas.Open("./store", "./uploads")
+//	dup, _ := st.Put("a.iso", tmp, sum)   // tmp(uploads 안에서 다 쓴 파일)를 블롭으로, uploads/a.iso 는 그 링크 (dup 이면 이미 있던 블롭)
+//	release, ok := st.Hold(sum)           // 이미 있는 내용이면 놓을 때까지 블롭을 잡아 둬 (마지막 이름이 그사이 지워져도 남아)
+//	st.Link("copy.iso", sum)              // 파일 없이 링크만 - 잡아 뒀거나 Refs(sum) > 0 일 때
+//	release()
+//	f, _ := st.Open("a.iso")              // 색인을 따라 블롭을 열어
+//	st.Rename("a.iso", "b.iso")           // uploads/a.iso 를 b.iso 로 옮긴 뒤 - 색인도 따라가
+//	st.Remove("a.iso")                    // uploads/a.iso 를 치운 뒤 - 참조가 0 이 되면 블롭도 지워
+package cas
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// indexFile 저장소 안의 이름 → 해시 색인
+const indexFile = "index.json"
+
+// Store 블롭 디렉토리와 이름 → sha256 색인 - 여러 고루틴에서 같이 써도 돼
+// 같은 이름을 동시에 Put/Remove 하지 않는 건 부르는 쪽 몫이야 (서버는 경로 잠금 안에서 불러).
+type Store struct {
+	dir   string // 블롭과 색인
+	files string // 이름이 있는 디렉토리
+
+	mu    sync.Mutex
+	names map[string]string // 이름 → sha256
+	refs  map[string]int    // sha256 → 그 내용을 가리키는 이름 수
+	holds map[string]int    // sha256 → Hold 하고 아직 놓지 않은 수 (색인에는 안 남아)
+}
+
+// Open dir 의 저장소를 열고 files 디렉토리와 맞춰 - 없으면 만들어
+// 꺼진 사이 files 에서 지워지거나 다른 내용으로 바뀐 이름은 색인에서 빼고, 아무도 안 가리키는 블롭은 지워.
+func Open(dir, files string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := checkSameFS(dir, files); err != nil {
+		return nil, msg.Errorf("저장소 %s 와 %s 는 같은 파일시스템이어야 합니다 (하드 링크): %w", dir, files, err)
+	}
+	st := &Store{dir: dir, files: files, names: map[string]string{}, refs: map[string]int{}, holds: map[string]int{}}
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &st.names); err != nil {
+			return nil, msg.Errorf("저장소 색인 %s: %w", filepath.Join(dir, indexFile), err)
+		}
+	}
+	if err := st.reconcile(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// checkSameFS dir 의 파일을 files 로 하드 링크할 수 있는지 미리 해 봐
+func checkSameFS(dir, files string) error {
+	probe, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	defer os.Remove(probe.Name())
+	link := filepath.Join(files, filepath.Base(probe.Name()))
+	if err := os.Link(probe.Name(), link); err != nil {
+		return err
+	}
+	return os.Remove(link)
+}
+
+// BlobPath sum 의 블롭 경로 (dir/ab/cdef…)
+func (st *Store) BlobPath(sum string) string {
+	return filepath.Join(st.dir, sum[:2], sum[2:])
+}
+
+// Lookup name 이 가리키는 sha256
+func (st *Store) Lookup(name string) (string, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sum, ok := st.names[name]
+	return sum, ok
+}
+
+// Refs sum 을 가리키는 이름 수
+func (st *Store) Refs(sum string) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.refs[sum]
+}
+
+// Hold 이미 있는 블롭 sum 을 release 할 때까지 지우지 않게 잡아 둬 - 가리키는 이름이 없으면 false
+// ⭐ Refs 로 있는 걸 보고 Link 하기 전에 마지막 이름이 지워지면 블롭도 같이 사라져 - 내용을 받지 않고 Link 할 거면 그동안 잡아 둬.
+// release 는 여러 번 불러도 돼. 놓을 때 아무도 안 가리키면 그때 지워.
+func (st *Store) Hold(sum string) (release func(), ok bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.refs[sum] == 0 {
+		return nil, false
+	}
+	st.holds[sum]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			if st.holds[sum]--; st.holds[sum] == 0 {
+				delete(st.holds, sum)
+			}
+			st.dropBlobLocked(sum)
+		})
+	}, true
+}
+
+// Open name 을 색인을 따라 블롭에서 열어 (색인에 없으면 fs.ErrNotExist)
+func (st *Store) Open(name string) (*os.File, error) {
+	sum, ok := st.Lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return streamio.OpenShared(st.BlobPath(sum))
+}
+
+// Put src(files 안의 다 쓴 파일, 내용의 sha256 이 sum)를 name 의 내용으로 넣어 - files/name 은 블롭의 하드 링크가 돼
+// 블롭이 없으면 src 가 그대로 블롭이 되고(링크만 하나 더), 이미 있으면 src 는 지우고 있던 블롭을 링크해서 dup 이 true 야.
+// src 가 files/name 자체여도 돼. name 이 전에 다른 내용을 가리켰으면 그 참조를 놓아.
+func (st *Store) Put(name, src, sum string) (dup bool, err error) {
+	target := filepath.Join(st.files, name)
+	blob := st.BlobPath(sum)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+		return false, err
+	}
+	err = os.Link(src, blob)
+	switch {
+	case err == nil: // 새 내용 - src 가 블롭이 됐어
+		os.Chmod(blob, 0444)
+		if src != target {
+			err = streamio.Rename(src, target)
+		}
+	case errors.Is(err, fs.ErrExist): // 이미 있는 내용 - 블롭을 링크하고 src 는 버려
+		dup = true
+		if err = replaceWithLink(blob, target); err == nil && src != target {
+			os.Remove(src)
+		}
+	}
+	if err != nil {
+		st.dropBlobLocked(sum) // 새로 만든 블롭이 아무에게도 안 붙었으면 치워
+		return false, err
+	}
+
+	return dup, st.pointLocked(name, sum)
+}
+
+// Link 이미 있는 블롭 sum 을 name 의 내용으로 - 내용을 다시 받지 않고 files/name 을 그 블롭의 하드 링크로 바꿔
+// sum 을 가리키는 이름도 Hold 도 없으면 fs.ErrNotExist (그사이 마지막 이름이 지워졌을 수도 있어).
+func (st *Store) Link(name, sum string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.refs[sum] == 0 && st.holds[sum] == 0 {
+		return &fs.PathError{Op: "link", Path: sum, Err: fs.ErrNotExist}
+	}
+	if err := replaceWithLink(st.BlobPath(sum), filepath.Join(st.files, name)); err != nil {
+		return err
+	}
+	return st.pointLocked(name, sum)
+}
+
+// pointLocked 색인에서 name 이 sum 을 가리키게 - 전에 다른 내용을 가리켰으면 그 참조를 놓아
+func (st *Store) pointLocked(name, sum string) error {
+	if old, ok := st.names[name]; ok {
+		st.refs[old]--
+		defer st.dropBlobLocked(old)
+	}
+	st.names[name] = sum
+	st.refs[sum]++
+	return st.saveLocked()
+}
+
+// replaceWithLink target 을 blob 의 하드 링크로 원자적으로 바꿔 (옆에 링크를 만들고 rename)
+func replaceWithLink(blob, target string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".link-*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	if err := os.Link(blob, tmp.Name()); err != nil {
+		return err
+	}
+	if err := streamio.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Remove name 을 색인에서 빼 (files/name 을 치운 뒤에 불러) - 그 내용의 마지막 이름이었으면 블롭도 지우고 freed 가 true
+// 휴지통으로 옮긴 파일은 같은 inode 라 블롭을 지워도 휴지통의 내용은 남아.
+func (st *Store) Remove(name string) (freed bool, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sum, ok := st.names[name]
+	if !ok {
+		return false, nil
+	}
+	delete(st.names, name)
+	st.refs[sum]--
+	freed = st.dropBlobLocked(sum)
+	return freed, st.saveLocked()
+}
+
+// Rename files/oldName 을 files/newName 으로 옮긴 뒤 색인도 따라가 (oldName 이 색인에 없으면 아무것도 안 해)
+// newName 이 전에 다른 내용을 가리켰으면 그 참조를 놓아 - 그 내용의 마지막 이름이었으면 블롭도 지워.
+func (st *Store) Rename(oldName, newName string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sum, ok := st.names[oldName]
+	if !ok {
+		return nil
+	}
+	if old, ok := st.names[newName]; ok {
+		st.refs[old]--
+		defer st.dropBlobLocked(old)
+	}
+	delete(st.names, oldName)
+	st.names[newName] = sum
+	return st.saveLocked()
+}
+
+// dropBlobLocked 참조도 Hold 도 없는 블롭을 지워
+func (st *Store) dropBlobLocked(sum string) bool {
+	if st.refs[sum] > 0 || st.holds[sum] > 0 {
+		return false
+	}
+	delete(st.refs, sum)
+	path := st.BlobPath(sum)
+	os.Chmod(path, 0644) // Windows 는 읽기 전용 파일을 못 지워
+	return os.Remove(path) == nil
+}
+
+// reconcile 색인을 files 디렉토리와 맞추고 고아 블롭을 지워 (Open 에서)
+func (st *Store) reconcile() error {
+	changed := false
+	for name, sum := range st.names {
+		fi, err1 := os.Stat(filepath.Join(st.files, name))
+		bi, err2 := os.Stat(st.BlobPath(sum))
+		if err1 != nil || err2 != nil || !os.SameFile(fi, bi) {
+			delete(st.names, name) // 지워졌거나, 밖에서 다른 내용으로 바뀌었어 (그 파일은 평범한 파일로 남아)
+			changed = true
+			continue
+		}
+		st.refs[sum]++
+	}
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range entries {
+		if !d.IsDir() || len(d.Name()) != 2 {
+			continue
+		}
+		blobs, err := os.ReadDir(filepath.Join(st.dir, d.Name()))
+		if err != nil {
+			return err
+		}
+		for _, b := range blobs {
+			st.dropBlobLocked(d.Name() + b.Name()) // 참조가 있으면 그대로
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return st.saveLocked()
+}
+
+// saveLocked 색인을 임시 파일 → rename 으로 저장
+func (st *Store) saveLocked() error {
+	data, err := json.MarshalIndent(st.names, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(st.dir, indexFile)
+	tmp, err := os.CreateTemp(st.dir, "."+indexFile+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = streamio.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return msg.Errorf("저장소 색인 저장 실패: %w", err)
+	}
+	return nil
+}
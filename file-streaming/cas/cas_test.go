@@ -0,0 +1,214 @@
+package cas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// write files 안에 content 를 쓴 임시 파일과 그 sha256
+func write(t *testing.T, files, content string) (string, string) {
+	t.Helper()
+	f, err := os.CreateTemp(files, ".upload-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(content)
+	f.Close()
+	sum := sha256.Sum256([]byte(content))
+	return f.Name(), hex.EncodeToString(sum[:])
+}
+
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	ai, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return os.SameFile(ai, bi)
+}
+
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	dir, files := filepath.Join(root, "store"), filepath.Join(root, "uploads")
+	os.Mkdir(files, 0755)
+	st, err := Open(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 같은 내용을 두 이름으로 - 블롭은 하나, 두 번째는 dup
+	src, sum := write(t, files, "같은 내용")
+	if dup, err := st.Put("a.txt", src, sum); err != nil || dup {
+		t.Fatalf("Put a.txt = %v, %v", dup, err)
+	}
+	src2, _ := write(t, files, "같은 내용")
+	if dup, err := st.Put("b.txt", src2, sum); err != nil || !dup {
+		t.Fatalf("Put b.txt = %v, %v", dup, err)
+	}
+	if _, err := os.Stat(src2); !os.IsNotExist(err) {
+		t.Errorf("dup 인데 임시 파일이 남음: %v", err)
+	}
+	blob := st.BlobPath(sum)
+	if !sameFile(t, filepath.Join(files, "a.txt"), blob) || !sameFile(t, filepath.Join(files, "b.txt"), blob) {
+		t.Error("이름이 블롭의 하드 링크가 아님")
+	}
+	if filepath.Base(filepath.Dir(blob)) != sum[:2] || st.Refs(sum) != 2 {
+		t.Errorf("블롭 %s, 참조 %d", blob, st.Refs(sum))
+	}
+	f, err := st.Open("b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "같은 내용" {
+		t.Errorf("Open = %q", data)
+	}
+
+	// a.txt 를 다른 내용으로 덮어쓰면 예전 내용은 참조 하나를 놓아
+	src3, sum3 := write(t, files, "새 내용")
+	if _, err := st.Put("a.txt", src3, sum3); err != nil {
+		t.Fatal(err)
+	}
+	if st.Refs(sum) != 1 || st.Refs(sum3) != 1 {
+		t.Errorf("덮어쓴 뒤 참조 %d, %d", st.Refs(sum), st.Refs(sum3))
+	}
+
+	// 이름을 옮기면 색인도 따라가 (참조 수는 그대로)
+	os.Rename(filepath.Join(files, "a.txt"), filepath.Join(files, "c.txt"))
+	if err := st.Rename("a.txt", "c.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := st.Lookup("c.txt"); !ok || got != sum3 {
+		t.Errorf("옮긴 뒤 Lookup c.txt = %q, %v", got, ok)
+	}
+	if _, ok := st.Lookup("a.txt"); ok || st.Refs(sum3) != 1 {
+		t.Errorf("옮긴 뒤 a.txt 가 색인에 남거나 참조 %d", st.Refs(sum3))
+	}
+	os.Rename(filepath.Join(files, "c.txt"), filepath.Join(files, "a.txt"))
+	st.Rename("c.txt", "a.txt")
+
+	// 재시작하면 색인을 다시 읽고, 밖에서 지운 이름은 빠지고 참조가 0 인 블롭도 지워
+	os.Remove(filepath.Join(files, "a.txt"))
+	st, err = Open(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := st.Lookup("a.txt"); ok {
+		t.Error("밖에서 지운 a.txt 가 색인에 남음")
+	}
+	if _, err := os.Stat(st.BlobPath(sum3)); !os.IsNotExist(err) {
+		t.Errorf("고아 블롭이 남음: %v", err)
+	}
+	if got, ok := st.Lookup("b.txt"); !ok || got != sum {
+		t.Errorf("Lookup b.txt = %q, %v", got, ok)
+	}
+
+	// 마지막 이름을 지우면 블롭도
+	os.Remove(filepath.Join(files, "b.txt"))
+	if freed, err := st.Remove("b.txt"); err != nil || !freed {
+		t.Errorf("Remove = %v, %v", freed, err)
+	}
+	if _, err := os.Stat(blob); !os.IsNotExist(err) {
+		t.Errorf("블롭이 남음: %v", err)
+	}
+}
+
+func TestLink(t *testing.T) {
+	root := t.TempDir()
+	dir, files := filepath.Join(root, "store"), filepath.Join(root, "uploads")
+	os.Mkdir(files, 0755)
+	st, err := Open(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 없는 내용은 링크할 수 없어
+	src, sum := write(t, files, "있는 내용")
+	if err := st.Link("b.txt", sum); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("블롭 없이 Link = %v, want ErrNotExist", err)
+	}
+	if _, err := st.Put("a.txt", src, sum); err != nil {
+		t.Fatal(err)
+	}
+
+	// 있으면 파일 없이 이름만 붙어 - 예전 내용을 가리키던 이름이면 그 참조를 놓아
+	old, oldSum := write(t, files, "예전 내용")
+	if _, err := st.Put("b.txt", old, oldSum); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Link("b.txt", sum); err != nil {
+		t.Fatal(err)
+	}
+	if !sameFile(t, filepath.Join(files, "b.txt"), st.BlobPath(sum)) || st.Refs(sum) != 2 {
+		t.Errorf("Link 뒤 b.txt 가 블롭 링크가 아니거나 참조 %d", st.Refs(sum))
+	}
+	if _, err := os.Stat(st.BlobPath(oldSum)); !os.IsNotExist(err) {
+		t.Errorf("아무도 안 가리키는 예전 블롭이 남음: %v", err)
+	}
+
+	// 같은 이름에 같은 내용을 다시 링크해도 참조 수는 그대로
+	if err := st.Link("a.txt", sum); err != nil || st.Refs(sum) != 2 {
+		t.Errorf("다시 Link = %v, 참조 %d", err, st.Refs(sum))
+	}
+}
+
+// Refs 를 보고 Link 하기 전에 마지막 이름이 지워지는 사이 - Hold 해 뒀으면 블롭이 남아서 Link 가 돼
+func TestHold(t *testing.T) {
+	root := t.TempDir()
+	dir, files := filepath.Join(root, "store"), filepath.Join(root, "uploads")
+	os.Mkdir(files, 0755)
+	st, err := Open(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := st.Hold("없는 내용"); ok {
+		t.Fatal("가리키는 이름이 없는데 Hold 됨")
+	}
+
+	src, sum := write(t, files, "곧 지워질 내용")
+	if _, err := st.Put("a.txt", src, sum); err != nil {
+		t.Fatal(err)
+	}
+	release, ok := st.Hold(sum)
+	if !ok {
+		t.Fatal("Hold 실패")
+	}
+	os.Remove(filepath.Join(files, "a.txt"))
+	if freed, err := st.Remove("a.txt"); freed || err != nil {
+		t.Fatalf("잡아 둔 블롭을 Remove 가 지움: %v, %v", freed, err)
+	}
+	if err := st.Link("b.txt", sum); err != nil {
+		t.Fatalf("잡아 둔 블롭에 Link = %v", err)
+	}
+	release()
+	release() // 두 번 놓아도 돼
+	if !sameFile(t, filepath.Join(files, "b.txt"), st.BlobPath(sum)) || st.Refs(sum) != 1 {
+		t.Errorf("놓은 뒤 b.txt 가 블롭 링크가 아니거나 참조 %d", st.Refs(sum))
+	}
+
+	// 아무도 링크하지 않고 놓으면 그때 지워
+	release, _ = st.Hold(sum)
+	os.Remove(filepath.Join(files, "b.txt"))
+	st.Remove("b.txt")
+	if _, err := os.Stat(st.BlobPath(sum)); err != nil {
+		t.Fatalf("놓기 전에 블롭이 지워짐: %v", err)
+	}
+	release()
+	if _, err := os.Stat(st.BlobPath(sum)); !os.IsNotExist(err) {
+		t.Errorf("놓은 뒤에도 블롭이 남음: %v", err)
+	}
+	if err := st.Link("c.txt", sum); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("놓은 뒤 Link = %v, want ErrNotExist", err)
+	}
+}
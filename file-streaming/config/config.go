@@ -0,0 +1,644 @@
+// Package config 는 streamctl, step09 서버, step06 분석기가 같이 쓰는 설정이야.
+// 버퍼 크기, 디렉토리, 제한, 압축 방식 같은 값을 한 구조체로 모아서 어디서 실행하든 같은 방식으로 설정해.
+//
+// ⭐ 우선순위: 플래그 > 환경 변수 > 설정 파일(YAML, JSON 도 YAML 로 읽혀) > 기본값
+// Load 가 기본값 위에 파일과 환경 변수를 덮고, 각 섹션의 RegisterFlags 가 그 결과를 플래그 기본값으로 걸어 -
+// 그래서 사용자가 직접 준 플래그만 마지막에 덮어쓰고, -h 에는 실제로 적용될 값이 보여.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/extract"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/notify"
+	"github.com/hellotect2022go/study-go/file-streaming/policy"
+	"github.com/hellotect2022go/study-go/file-streaming/schedule"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"go.yaml.in/yaml/v3"
+)
+
+// EnvFile 설정 파일 경로를 주는 환경 변수 (-config 플래그가 우선)
+const EnvFile = "FS_CONFIG"
+
+// Config 전체 설정 - YAML 키는 yaml 태그, 환경 변수 이름은 env 태그 (쉼표로 여러 개면 앞에서부터 찾아)
+type Config struct {
+	Transfer Transfer          `yaml:"transfer"`
+	Compress Compress          `yaml:"compress"`
+	Server   Server            `yaml:"server"`
+	Search   Search            `yaml:"search"`
+	Analyzer Analyzer          `yaml:"analyzer"`
+	Log      Log               `yaml:"log"`
+	Trace    Trace             `yaml:"trace"`
+	Schedule Schedule          `yaml:"schedule"`
+	Queue    Queue             `yaml:"queue"`
+	Checksum Checksum          `yaml:"checksum"`
+	Extract  Extract           `yaml:"extract"`
+	Scan     Scan              `yaml:"scan"`
+	Policy   Policy            `yaml:"policy"`
+	Notify   Notify            `yaml:"notify"`
+	Cache    Cache             `yaml:"cache"`
+	Stats    Stats             `yaml:"stats"`
+	Locale   Locale            `yaml:"locale"`
+	Daemon   Daemon            `yaml:"daemon"`
+	Usage    Usage             `yaml:"usage"`
+	Auth     Auth              `yaml:"auth"`
+	CORS     CORS              `yaml:"cors"`
+	Plugins  map[string]Plugin `yaml:"plugins,omitempty"`
+}
+
+// Transfer 복사/업로드/다운로드 공통
+type Transfer struct {
+	Buffer   Size                  `yaml:"buffer" env:"FS_BUFFER"`     // 복사 버퍼 크기
+	Rate     Size                  `yaml:"rate" env:"FS_RATE"`         // 초당 최대 전송량 (0 이면 제한 없음)
+	Retries  int                   `yaml:"retries" env:"FS_RETRIES"`   // 실패 시 재시도 횟수
+	Progress streamio.ProgressMode `yaml:"progress" env:"FS_PROGRESS"` // none | text | bar | json
+}
+
+// Compress 압축 방식
+type Compress struct {
+	Codec string `yaml:"codec" env:"FS_CODEC"`          // 지금은 gzip 만
+	Level int    `yaml:"level" env:"FS_COMPRESS_LEVEL"` // -1(기본) 또는 1 빠름 ~ 9 작음
+}
+
+// Server step09 HTTP 서버
+type Server struct {
+	Addr       string `yaml:"addr" env:"FS_ADDR"`
+	UploadDir  string `yaml:"upload_dir" env:"FS_UPLOAD_DIR"`
+	TrashDir   string `yaml:"trash_dir" env:"FS_TRASH_DIR"`
+	SessionDir string `yaml:"session_dir" env:"FS_SESSION_DIR"` // 이어 올리기(/api/uploads) 중인 파일과 세션 저널
+	IndexFile  string `yaml:"index_file" env:"FS_INDEX_FILE"`   // 비우면 내장 웹 UI
+	DedupDir   string `yaml:"dedup_dir" env:"FS_DEDUP_DIR"`     // 업로드를 sha256 으로 한 벌만 두는 저장소 (비우면 안 써, upload_dir 과 같은 파일시스템)
+	MaxUpload  Size   `yaml:"max_upload" env:"FS_MAX_UPLOAD"`   // 업로드 한 건의 최대 크기 (0 이면 제한 없음)
+	Gzip       bool   `yaml:"gzip" env:"FS_GZIP"`               // /download 응답을 gzip 으로 (Accept-Encoding: gzip 이고 텍스트 같은 형식만, 레벨은 compress.level)
+	GzipSkip   string `yaml:"gzip_skip" env:"FS_GZIP_SKIP"`     // 압축하지 않을 확장자 (쉼표 구분, zip/gz/이미지/동영상은 안 적어도 건너뛰어)
+	Collision  string `yaml:"collision" env:"FS_COLLISION"`     // 올린 이름의 파일이 이미 있을 때 (Collisions)
+	// Thumbnails 이미지(JPEG, PNG, GIF) 업로드마다 small/medium 썸네일을 upload_dir/.thumbs 에 만들어 /thumb 로 (로컬 디렉토리만)
+	Thumbnails   bool `yaml:"thumbnails" env:"FS_THUMBNAILS"`
+	ThumbWorkers int  `yaml:"thumb_workers" env:"FS_THUMB_WORKERS"` // 썸네일을 동시에 만들 수
+	// HLS MPEG-TS(.ts) 동영상을 /hls?file= 로 HLS 재생 목록(원본의 바이트 범위 조각)으로 내보내
+	HLS        bool          `yaml:"hls" env:"FS_HLS"`
+	HLSSegment time.Duration `yaml:"hls_segment" env:"FS_HLS_SEGMENT"` // 조각 길이 목표 (실제로는 다음 키프레임까지)
+	// UploadWorkers /upload 의 "files" 파트를 동시에 저장할 수 (본문은 차례로 받고, 검사/커밋이 다음 파일 받기와 겹쳐)
+	UploadWorkers int `yaml:"upload_workers" env:"FS_UPLOAD_WORKERS"`
+	// Versions 업로드가 같은 이름을 덮어쓸 때 예전 내용을 version_dir 에 남길 개수 (0 이면 안 남겨, 로컬 디렉토리만)
+	Versions   int    `yaml:"versions" env:"FS_VERSIONS"`
+	VersionDir string `yaml:"version_dir" env:"FS_VERSION_DIR"` // upload_dir 과 같은 파일시스템이면 하드 링크라 복사가 없어
+	// ExpireAfter X-Expire-After 없이 올린 파일을 지울 때까지의 시간 (0 이면 안 지워) - 헤더로는 이보다 짧게만
+	ExpireAfter time.Duration `yaml:"expire_after" env:"FS_EXPIRE_AFTER"`
+	ExpireScan  time.Duration `yaml:"expire_scan" env:"FS_EXPIRE_SCAN"` // 만료된 파일을 찾는 주기
+	// TrashRetention 지운 파일을 휴지통(trash_dir)에 남겨 두는 기간 - 지나면 expire_scan 마다 영구 삭제 (0 이면 안 지워)
+	TrashRetention time.Duration `yaml:"trash_retention" env:"FS_TRASH_RETENTION"`
+	// WebDAV /dav 로 업로드 디렉토리를 탐색기/Finder 에서 드라이브처럼 (WebDAVModes - 로컬 디렉토리만)
+	WebDAV string `yaml:"webdav" env:"FS_WEBDAV"`
+	// SFTPAddr 같은 저장소를 SFTP 로도 열 주소 (예: ":2022", 비우면 안 열어) - ssh 공개키로만 로그인
+	SFTPAddr           string `yaml:"sftp_addr" env:"FS_SFTP_ADDR"`
+	SFTPHostKey        string `yaml:"sftp_host_key" env:"FS_SFTP_HOST_KEY"`               // 호스트 개인키 (없으면 ed25519 로 만들어 저장)
+	SFTPAuthorizedKeys string `yaml:"sftp_authorized_keys" env:"FS_SFTP_AUTHORIZED_KEYS"` // 로그인할 수 있는 공개키 (authorized_keys 형식, 인증을 켰으면 주석이 usage.keys 의 계정 이름)
+	// TCPAddr transfer 프로토콜(streamctl send)로도 받을 주소 (예: ":9000", 비우면 안 열어) - 자격 증명이 없어서 인증을 켜면 거절
+	TCPAddr string `yaml:"tcp_addr" env:"FS_TCP_ADDR"`
+	// Backend 업로드 파일을 둘 저장소 - 비우면 upload_dir, "memory"(재시작하면 비어), "s3://bucket/prefix" (키는 AWS_ACCESS_KEY_ID 같은 환경 변수)
+	Backend    string `yaml:"backend" env:"FS_BACKEND"`
+	S3Endpoint string `yaml:"s3_endpoint" env:"FS_S3_ENDPOINT"` // s3:// 저장소의 엔드포인트 (MinIO 같은 자체 호스팅, 비우면 AWS)
+	// CopyTargets /api/copy 로 업로드와 파일을 주고받을 저장소 (이름 → "s3://bucket/prefix", "memory" 또는 로컬 디렉토리, 설정 파일로만)
+	CopyTargets map[string]string `yaml:"copy_targets,omitempty"`
+	// EncryptionKeys 업로드를 AES-256-GCM 으로 암호화해서 둘 키 파일 - 줄마다 32바이트 키 (hex/base64), 첫 줄로 암호화하고 나머지는 예전 파일 읽기용
+	EncryptionKeys string `yaml:"encryption_keys" env:"FS_ENCRYPTION_KEYS"`
+	// DownloadRate 다운로드 한 건(연결)의 초당 최대 바이트 (0 이면 제한 없음) - 요청의 ?limit= 은 이보다 낮게만
+	DownloadRate Size   `yaml:"download_rate" env:"FS_DOWNLOAD_RATE"`
+	UploadRate   Size   `yaml:"upload_rate" env:"FS_UPLOAD_RATE"` // 업로드 한 건(연결)이 본문을 읽는 초당 최대 바이트 (0 이면 제한 없음)
+	TLS          bool   `yaml:"tls" env:"FS_TLS"`                 // HTTPS 로 (cert/key 를 비우면 실행할 때마다 자체 서명 인증서를 만들어)
+	Cert         string `yaml:"cert" env:"FS_TLS_CERT"`           // PEM 인증서 (체인 포함) - 주면 tls 를 안 켜도 HTTPS
+	Key          string `yaml:"key" env:"FS_TLS_KEY"`             // PEM 개인키
+	// MaxDownloads 동시에 보낼 큰 다운로드 수 (0 이면 제한 없음) - 자리가 없으면 download_queue 만큼 기다리다가 503 + Retry-After
+	MaxDownloads  int           `yaml:"max_downloads" env:"FS_MAX_DOWNLOADS"`
+	DownloadQueue time.Duration `yaml:"download_queue" env:"FS_DOWNLOAD_QUEUE"` // 자리가 날 때까지 기다릴 최대 시간 (0 이면 바로 503)
+	LargeDownload Size          `yaml:"large_download" env:"FS_LARGE_DOWNLOAD"` // max_downloads 로 셀 다운로드의 최소 크기 (0 이면 전부)
+	// MaxUploadsPerIP 클라이언트 주소 하나가 동시에 올릴 수 있는 업로드 수 (0 이면 제한 없음) - 넘으면 429 + Retry-After
+	MaxUploadsPerIP int `yaml:"max_uploads_per_ip" env:"FS_MAX_UPLOADS_PER_IP"`
+	// MinFree 업로드 디렉토리에 이보다 적게 남으면 /healthz, /readyz 가 503 (0 이면 보기만)
+	MinFree Size `yaml:"min_free" env:"FS_MIN_FREE"`
+	// UploadWebhooks 업로드가 저장될 때마다 이름, 크기, sha256, 올린 계정을 JSON 으로 POST 할 URL (쉼표 구분, 비우면 안 보내)
+	UploadWebhooks string `yaml:"upload_webhooks" env:"FS_UPLOAD_WEBHOOKS"`
+	WebhookRetries int    `yaml:"webhook_retries" env:"FS_WEBHOOK_RETRIES"` // 실패하면 간격을 두 배씩 늘리며 다시 보낼 횟수
+	// AccessLog 요청마다 한 줄씩 남길 접근 로그 파일 (비우면 안 남겨) - access_log_max_size 를 넘으면 .1, .2 … 로 돌려
+	AccessLog        string `yaml:"access_log" env:"FS_ACCESS_LOG"`
+	AccessLogFormat  string `yaml:"access_log_format" env:"FS_ACCESS_LOG_FORMAT"`     // json | combined
+	AccessLogMaxSize Size   `yaml:"access_log_max_size" env:"FS_ACCESS_LOG_MAX_SIZE"` // 돌릴 크기
+	AccessLogBackups int    `yaml:"access_log_backups" env:"FS_ACCESS_LOG_BACKUPS"`   // 남길 예전 파일 수 (0 이면 돌릴 때 버려)
+	// AuditLog 지우기, 이름 바꾸기(/delete, /api/files/<이름>)를 누가 언제 했는지 JSON 한 줄씩 덧붙일 파일 (비우면 안 남겨, 돌리지 않아)
+	AuditLog string `yaml:"audit_log" env:"FS_AUDIT_LOG"`
+}
+
+// AccessLogFormats server.access_log_format 으로 쓸 수 있는 값 - JSON 한 줄, combined + 받은 바이트 + 걸린 시간
+var AccessLogFormats = []string{"json", "combined"}
+
+// validBackend server.backend 로 쓸 수 있는 값 - 비우면 upload_dir, "memory", "s3://bucket/prefix"
+func validBackend(b string) bool {
+	return b == "" || b == "memory" || strings.HasPrefix(b, "s3://")
+}
+
+// Collisions server.collision 으로 쓸 수 있는 값 - 덮어쓰기, 409 로 거절, 이름-<uuid>, 이름-v2 …
+var Collisions = []string{"overwrite", "reject", "uuid", "version"}
+
+// WebDAVModes server.webdav 로 쓸 수 있는 값 - 끔, 읽기만, 올리기/지우기/이름 바꾸기까지
+var WebDAVModes = []string{"off", "read-only", "read-write"}
+
+// Search 전문 검색 색인 (서버, index/search 명령이 같이 써)
+type Search struct {
+	Index string `yaml:"index" env:"FS_SEARCH_INDEX"` // 비우면 서버 검색 끔
+}
+
+// Analyzer step06 로그 분석기
+type Analyzer struct {
+	Report  string `yaml:"report" env:"FS_REPORT"`   // 보고서 파일 (비우면 저장 안 함)
+	Journal string `yaml:"journal" env:"FS_JOURNAL"` // 분석 요약을 덧붙일 공유 저널 (비우면 안 씀)
+	Parquet string `yaml:"parquet" env:"FS_PARQUET"` // 줄마다 파싱한 레코드를 쓸 Parquet 파일 (비우면 안 씀)
+}
+
+// Log 로그 레벨/형식 (logging.Options 로 넘겨)
+type Log struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug | info | warn | error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // text | json
+}
+
+// Trace OpenTelemetry 트레이스 대상 (tracing.Setup 으로 넘겨)
+type Trace struct {
+	Target string `yaml:"target" env:"FS_TRACE,STREAMCTL_TRACE,TRACE"` // stderr, grpc://호스트:4317, http://호스트:4318
+}
+
+// Schedule streamctl schedule 이 주기적으로 돌릴 작업
+type Schedule struct {
+	State string `yaml:"state" env:"FS_SCHEDULE_STATE"` // 작업 상태/이력 파일 (비우면 저장 안 함)
+	Jobs  []Job  `yaml:"jobs,omitempty"`
+}
+
+// Job 예약 작업 하나 - task 에 따라 쓰는 필드가 달라 (작업 목록은 설정 파일로만)
+type Job struct {
+	Name    string        `yaml:"name"`
+	Cron    string        `yaml:"cron"`              // "0 3 * * *", "@daily", "@every 10m"
+	Task    string        `yaml:"task"`              // compress | sync | verify
+	Jitter  time.Duration `yaml:"jitter,omitempty"`  // 실행 시각마다 0~jitter 무작위로 늦춤
+	Timeout time.Duration `yaml:"timeout,omitempty"` // 한 번 실행의 최대 시간 (0 이면 제한 없음)
+
+	Src       string        `yaml:"src"`                  // compress: 로그 디렉토리, sync: 원본, verify: 검증할 디렉토리
+	Dst       string        `yaml:"dst,omitempty"`        // sync: 대상 (sftp:// 가능)
+	Match     string        `yaml:"match,omitempty"`      // compress: 파일 glob (기본 *.log)
+	OlderThan time.Duration `yaml:"older_than,omitempty"` // compress: 이만큼 안 바뀐 파일만 (쓰는 중인 로그는 건너뛰게)
+	Keep      bool          `yaml:"keep,omitempty"`       // compress: 원본을 지우지 않고 남김
+	Delete    bool          `yaml:"delete,omitempty"`     // sync: 원본에 없는 파일을 대상에서 삭제
+	Manifest  string        `yaml:"manifest,omitempty"`   // verify: sha256sum 매니페스트 (기본 <src>/SHA256SUMS)
+}
+
+// Queue streamctl queue 의 작업 큐
+type Queue struct {
+	Dir     string `yaml:"dir" env:"FS_QUEUE_DIR"`         // 작업 파일을 둘 디렉토리
+	Workers int    `yaml:"workers" env:"FS_QUEUE_WORKERS"` // 동시에 돌릴 작업 수
+}
+
+// Checksum 파일마다 남기는 체크섬 기록 (copy/compress/sync 가 쓸 때 기록하고 scrub 이 다시 확인해)
+type Checksum struct {
+	Store string `yaml:"store" env:"FS_CHECKSUM_STORE"` // off | xattr | db
+	DB    string `yaml:"db" env:"FS_CHECKSUM_DB"`       // store: db 일 때 사이드카 DB 파일
+}
+
+// ChecksumStores checksum.store 로 쓸 수 있는 값
+var ChecksumStores = []string{"off", "xattr", "db"}
+
+// Extract 아카이브 풀기 한도 (streamctl extract, 서버 /api/extract, /upload-archive) - zip bomb 방지
+type Extract struct {
+	MaxFiles    int  `yaml:"max_files" env:"FS_EXTRACT_MAX_FILES"`         // 엔트리 수
+	MaxFileSize Size `yaml:"max_file_size" env:"FS_EXTRACT_MAX_FILE_SIZE"` // 풀린 파일 하나의 크기
+	MaxTotal    Size `yaml:"max_total" env:"FS_EXTRACT_MAX_TOTAL"`         // 풀린 전체 크기
+}
+
+// Scan 서버가 업로드를 저장하기 전에 돌릴 검사 명령 (command 는 설정 파일로만, 비우면 검사 없음)
+type Scan struct {
+	Command  []string      `yaml:"command,omitempty"`             // [clamdscan, --no-summary, -] - 내용은 stdin 으로, 셸을 거치지 않아
+	Timeout  time.Duration `yaml:"timeout" env:"FS_SCAN_TIMEOUT"` // 파일 하나 검사 시간 제한 (0 이면 없음)
+	Infected []int         `yaml:"infected_exit,omitempty"`       // 악성으로 볼 종료 코드 (비우면 1 - clamscan/clamdscan)
+}
+
+// Policy 서버가 받을 업로드의 확장자와 내용 형식 (설정 파일로만, 비우면 다 받아 - 걸리면 415)
+type Policy struct {
+	Allow   []string        `yaml:"allow,omitempty"`    // 받을 확장자 [.jpg, .png, .pdf] - 비우면 block 에 없는 건 다
+	Block   []string        `yaml:"block,omitempty"`    // 받지 않을 확장자 [.exe, .bat] - allow 보다 먼저 봐
+	Types   []string        `yaml:"types,omitempty"`    // 받을 내용 형식 (앞 512 바이트로 본) [image/*, application/pdf] - 비우면 다
+	MaxSize map[string]Size `yaml:"max_size,omitempty"` // 내용 형식 → 최대 크기 {image/*: 10MB} (server.max_upload 와 같이 봐)
+}
+
+// Locale 사용자에게 보이는 문구(도움말, 진행률, 결과, 에러)의 언어 - 로그는 그대로 한국어
+type Locale struct {
+	Lang msg.Lang `yaml:"lang" env:"FS_LANG"` // ko | en (비우면 LC_ALL, LC_MESSAGES, LANG 으로)
+}
+
+// Stats SIGUSR1 을 받으면 쓰는 상태 스냅샷 (진행 중인 전송, 고루틴, 속도 제한)
+type Stats struct {
+	Dump string `yaml:"dump" env:"FS_STATS_DUMP"` // 비우면 stderr, 파일이면 덧붙여 (.json/.jsonl 이면 JSON 한 줄)
+}
+
+// Daemon 서버(serve, step09)를 서비스로 돌릴 때 - systemd 소켓 활성화, sd_notify, SIGHUP 다시 읽기는 설정 없이 알아서 돼
+type Daemon struct {
+	PIDFile string `yaml:"pid_file" env:"FS_PID_FILE"`   // 시작하면 PID 를 적고 끝나면 지워 (비우면 안 씀)
+	Detach  bool   `yaml:"detach" env:"FS_DAEMON"`       // 백그라운드로 떼어내고 준비되면 부모는 끝나 (systemd 밑에서는 끄고 Type=notify 로)
+	LogFile string `yaml:"log_file" env:"FS_DAEMON_LOG"` // detach 일 때 stdout/stderr 를 덧붙일 파일 (비우면 버려)
+}
+
+// Usage 서버의 API 키별 전송량 계정과 달마다의 전송 한도 (keys 를 비우면 키 없이 모두 "anonymous" 한 계정으로 세)
+type Usage struct {
+	File    string            `yaml:"file" env:"FS_USAGE_FILE"`       // 전송량 기록 파일 (비우면 세지도 막지도 않아)
+	Flush   time.Duration     `yaml:"flush" env:"FS_USAGE_FLUSH"`     // 기록 파일에 쓰는 주기
+	Monthly Size              `yaml:"monthly" env:"FS_USAGE_MONTHLY"` // 키마다 한 달 업로드+다운로드 한도 (0 이면 제한 없음)
+	Keys    map[string]APIKey `yaml:"keys,omitempty"`                 // 계정 이름 → 키 (설정 파일로만)
+
+	StorageFile string `yaml:"storage_file" env:"FS_USAGE_STORAGE_FILE"` // 파일마다 누가 올렸는지 적는 기록 (비우면 저장 공간을 세지도 막지도 않아)
+	Storage     Size   `yaml:"storage" env:"FS_USAGE_STORAGE"`           // 키마다 올려 둘 수 있는 총 크기 (0 이면 제한 없음, 넘으면 413)
+}
+
+// APIKey 계정 하나 - X-API-Key 헤더나 Authorization: Bearer 로 보내는 키
+type APIKey struct {
+	Key     string   `yaml:"key"`
+	Monthly *Size    `yaml:"monthly,omitempty"` // 이 계정만 다른 한도 (비우면 usage.monthly, 0 이면 제한 없음)
+	Storage *Size    `yaml:"storage,omitempty"` // 이 계정만 다른 저장 공간 (비우면 usage.storage, 0 이면 제한 없음)
+	Scopes  []string `yaml:"scopes,omitempty"`  // 할 수 있는 일 - upload, download, delete (비우면 전부)
+}
+
+// Scopes usage.keys 의 scopes 와 JWT scope 클레임에 쓸 수 있는 값
+var Scopes = []string{"upload", "download", "delete"}
+
+// Auth 서버 인증 - usage.keys 의 API 키에 더해 HMAC 서명 JWT 도 받아 (키도 jwt_secret 도 없으면 인증 없이 열려 있어)
+type Auth struct {
+	JWTSecret   string `yaml:"jwt_secret" env:"FS_JWT_SECRET"`     // HS256/HS384/HS512 비밀 키 (32바이트 이상, 플래그로는 안 받아)
+	JWTIssuer   string `yaml:"jwt_issuer" env:"FS_JWT_ISSUER"`     // 비우지 않으면 토큰의 iss 가 같아야 해
+	JWTAudience string `yaml:"jwt_audience" env:"FS_JWT_AUDIENCE"` // 비우지 않으면 토큰의 aud 에 있어야 해
+	PublicFiles bool   `yaml:"public_files" env:"FS_PUBLIC_FILES"` // 인증을 켜도 /files/ 는 자격 증명 없이 열어 둬
+	// SignSecret /api/sign 이 임시 다운로드 URL(/download?file=&exp=&sig=)에 서명할 비밀 키 (32바이트 이상, 비우면 끔, 플래그로는 안 받아)
+	SignSecret string        `yaml:"sign_secret" env:"FS_SIGN_SECRET"`
+	SignMaxTTL time.Duration `yaml:"sign_max_ttl" env:"FS_SIGN_MAX_TTL"` // 서명 URL 에 줄 수 있는 최대 유효 시간
+}
+
+// CORS 다른 출처(https://app.example.com 같은)의 브라우저 앱이 서버 API 를 부를 때 (origins 를 비우면 CORS 헤더를 안 붙여)
+type CORS struct {
+	Origins string        `yaml:"origins" env:"FS_CORS_ORIGINS"` // 허락할 출처 (쉼표 구분, https://*.example.com 은 하위 도메인 전부, * 면 어디서든)
+	Methods string        `yaml:"methods" env:"FS_CORS_METHODS"` // 허락할 메서드 (쉼표 구분, 비우면 GET,HEAD,POST,PUT,PATCH,DELETE)
+	Headers string        `yaml:"headers" env:"FS_CORS_HEADERS"` // 보내도 되는 요청 헤더 (쉼표 구분, 비우면 브라우저가 preflight 로 물어본 그대로)
+	Expose  string        `yaml:"expose" env:"FS_CORS_EXPOSE"`   // 브라우저 JS 가 읽을 수 있는 응답 헤더 (쉼표 구분, 비우면 Content-Range, X-Content-SHA256 같은 기본 목록)
+	MaxAge  time.Duration `yaml:"max_age" env:"FS_CORS_MAX_AGE"` // 브라우저가 preflight 결과를 기억할 시간
+}
+
+// validOrigin cors.origins 로 쓸 수 있는 값 - "*" 나 scheme://host[:port] (host 앞에 "*." 하나까지, 경로나 끝의 "/" 는 안 돼)
+func validOrigin(o string) bool {
+	if o == "*" {
+		return true
+	}
+	u, err := url.Parse(strings.Replace(o, "://*.", "://x.", 1))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.User == nil && u.Path == "" && u.RawQuery == "" && u.Fragment == "" && !strings.Contains(u.Host, "*")
+}
+
+// Cache 원격(sftp://) 원본을 읽을 때 앞에 두는 디스크 캐시 (copy, analyze - dir 를 비우면 안 써)
+type Cache struct {
+	Dir     string        `yaml:"dir" env:"FS_CACHE_DIR"`
+	MaxSize Size          `yaml:"max_size" env:"FS_CACHE_MAX_SIZE"` // 넘으면 오래 안 쓴 것부터 지워
+	MaxAge  time.Duration `yaml:"max_age" env:"FS_CACHE_MAX_AGE"`   // 마지막으로 쓴 지 이만큼 지나면 지워
+}
+
+// Notify streamctl 명령이나 schedule/queue 작업이 끝나면 보내는 알림 (webhook, smtp 를 둘 다 비우면 안 보내)
+type Notify struct {
+	On       string        `yaml:"on" env:"FS_NOTIFY_ON"`                     // all | failure
+	Webhook  string        `yaml:"webhook" env:"FS_NOTIFY_WEBHOOK"`           // POST 할 URL
+	SMTP     string        `yaml:"smtp" env:"FS_NOTIFY_SMTP"`                 // 메일 서버 호스트:포트
+	From     string        `yaml:"from" env:"FS_NOTIFY_FROM"`                 // 보내는 사람
+	To       string        `yaml:"to" env:"FS_NOTIFY_TO"`                     // 받는 사람 (쉼표로 여럿)
+	Username string        `yaml:"username" env:"FS_NOTIFY_USERNAME"`         // SMTP 인증 (비우면 인증 없이)
+	Password string        `yaml:"-" env:"FS_NOTIFY_PASSWORD"`                // 설정 파일에 남지 않게 환경 변수로만
+	Subject  string        `yaml:"subject,omitempty"`                         // 메일 제목 템플릿 (text/template)
+	Template string        `yaml:"template,omitempty"`                        // 본문 템플릿 (비우면 웹훅은 JSON, 메일은 기본 문구)
+	Retries  int           `yaml:"retries" env:"FS_NOTIFY_RETRIES"`           // 보내기 실패 시 재시도 횟수
+	Timeout  time.Duration `yaml:"timeout,omitempty" env:"FS_NOTIFY_TIMEOUT"` // 한 번 보내기 제한 (0 이면 10초)
+}
+
+// NotifyOn notify.on 으로 쓸 수 있는 값
+var NotifyOn = []string{"all", "failure"}
+
+// Plugin -filter 로 이름만 불러 쓸 외부 명령 (stdin → stdout 변환, 설정 파일로만)
+type Plugin struct {
+	Command []string      `yaml:"command"`           // [실행 파일, 인자...] - 셸을 거치지 않아
+	Timeout time.Duration `yaml:"timeout,omitempty"` // 명령 전체 실행 시간 제한 (0 이면 없음)
+	OKExit  []int         `yaml:"ok_exit,omitempty"` // 0 말고도 성공으로 볼 종료 코드
+	Env     []string      `yaml:"env,omitempty"`     // 덧붙일 KEY=VALUE
+}
+
+// JobTasks job.task 로 쓸 수 있는 값
+var JobTasks = []string{"compress", "sync", "verify"}
+
+// Default 기본값 - 설정 파일도 환경 변수도 없으면 이대로 돌아
+func Default() Config {
+	return Config{
+		Transfer: Transfer{Buffer: 32 << 10, Progress: streamio.ProgressText},
+		Compress: Compress{Codec: "gzip", Level: -1},
+		Server: Server{
+			Addr:       ":8080",
+			UploadDir:  "./uploads",
+			TrashDir:   "./.trash",
+			SessionDir: "./.upload-sessions",
+			Gzip:       true,
+			Collision:  "overwrite",
+			WebDAV:     "off",
+
+			ThumbWorkers: 2,
+			SFTPHostKey:  "./.sftp_host_key",
+
+			DownloadQueue: 30 * time.Second,
+			LargeDownload: 1 << 20,
+			HLSSegment:    6 * time.Second,
+			UploadWorkers: 4,
+			VersionDir:    "./.versions",
+			ExpireScan:    time.Minute,
+
+			TrashRetention: 30 * 24 * time.Hour,
+
+			WebhookRetries: notify.DefaultRetries,
+			MinFree:        100 << 20,
+
+			AccessLogFormat:  "json",
+			AccessLogMaxSize: logging.DefaultMaxSize,
+			AccessLogBackups: logging.DefaultBackups,
+		},
+		Search:   Search{Index: "./.search.idx"},
+		Log:      Log{Level: "info", Format: "text"},
+		Schedule: Schedule{State: "./.schedule.json"},
+		Queue:    Queue{Dir: "./.queue", Workers: 1},
+		Checksum: Checksum{Store: "off", DB: "./.checksums.json"},
+		Extract:  Extract{MaxFiles: extract.DefaultMaxFiles, MaxFileSize: extract.DefaultMaxFileSize, MaxTotal: extract.DefaultMaxTotal},
+		Scan:     Scan{Timeout: 5 * time.Minute},
+		Notify:   Notify{On: "all", Retries: notify.DefaultRetries},
+		Cache:    Cache{MaxSize: storage.DefaultCacheMaxSize, MaxAge: storage.DefaultCacheMaxAge},
+		Usage:    Usage{Flush: 30 * time.Second},
+		CORS:     CORS{MaxAge: 10 * time.Minute},
+	}
+}
+
+// Load 기본값 → path 의 YAML 파일(비우면 건너뛰어) → 환경 변수 순서로 덮은 설정
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, msg.Errorf("설정 파일 읽기 실패: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true) // 오타 난 키를 조용히 무시하지 않게
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return cfg, msg.Errorf("설정 파일 %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(&cfg, os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// PathFromArgs 플래그를 파싱하기 전에 -config 값만 먼저 찾아 (없으면 FS_CONFIG)
+// ⭐ 플래그 기본값을 설정 파일 값으로 걸려면 파일을 먼저 읽어야 해서, 이 하나만 미리 꺼내
+func PathFromArgs(args []string) string {
+	if path, ok := ArgValue(args, "config"); ok {
+		return path
+	}
+	return os.Getenv(EnvFile)
+}
+
+// Apply 명령줄의 -lang 을 파싱 전에 미리 얹고 msg.SetLang - 도움말과 플래그 설명도 그 언어로 나오게 플래그를 등록하기 전에 불러
+func (l *Locale) Apply(args []string) error {
+	if v, ok := ArgValue(args, "lang"); ok {
+		if err := l.Lang.Set(v); err != nil {
+			return err
+		}
+	}
+	msg.SetLang(l.Lang)
+	return nil
+}
+
+// LocaleFromArgs 설정 파일을 안 읽는 도구용 - FS_LANG 위에 -lang 을 얹어서 Apply 까지
+func LocaleFromArgs(args []string) (Locale, error) {
+	var l Locale
+	if err := applyEnvStruct(reflect.ValueOf(&l).Elem(), os.LookupEnv); err != nil {
+		return l, err
+	}
+	return l, l.Apply(args)
+}
+
+// ArgValue 플래그를 파싱하기 전에 -name 값 하나만 미리 찾아 (-name v, -name=v, --name 도)
+func ArgValue(args []string, name string) (string, bool) {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		n, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "-") || n != name {
+			continue
+		}
+		if hasValue {
+			return value, true
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// Reload 설정 파일과 환경 변수를 다시 읽고, 처음 실행할 때 명령줄에서 직접 준 플래그(fs 에서 Set 된 것)만 그 위에 다시 덮어 (SIGHUP 용)
+// register 는 처음과 같은 섹션을 새 FlagSet 에 등록하는 함수 - 그래야 우선순위(플래그 > 환경 변수 > 파일)가 처음과 같아
+func Reload(path string, fs *flag.FlagSet, register func(fs *flag.FlagSet, cfg *Config)) (Config, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return cfg, err
+	}
+	again := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	register(again, &cfg)
+	fs.Visit(func(f *flag.Flag) {
+		if again.Lookup(f.Name) != nil && err == nil {
+			err = again.Set(f.Name, f.Value.String())
+		}
+	})
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate 값 범위 확인 - 잘못된 항목을 한 번에 모아서 알려줘
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, msg.Errorf(format, args...))
+		}
+	}
+
+	check(c.Transfer.Buffer > 0 && c.Transfer.Buffer <= 64<<20, "transfer.buffer 는 1B ~ 64MB 여야 합니다: %s", c.Transfer.Buffer)
+	check(c.Transfer.Rate >= 0, "transfer.rate 는 0 이상이어야 합니다: %s", c.Transfer.Rate)
+	check(c.Transfer.Retries >= 0, "transfer.retries 는 0 이상이어야 합니다: %d", c.Transfer.Retries)
+	var mode streamio.ProgressMode
+	if err := mode.Set(string(c.Transfer.Progress)); err != nil {
+		errs = append(errs, fmt.Errorf("transfer.progress: %w", err))
+	}
+
+	check(c.Compress.Codec == "gzip", "지원하지 않는 compress.codec: %q (gzip)", c.Compress.Codec)
+	check(c.Compress.Level >= -2 && c.Compress.Level <= 9, "compress.level 은 -2 ~ 9 여야 합니다: %d", c.Compress.Level)
+
+	check(c.Server.Addr != "", "server.addr 가 비어 있습니다")
+	check(c.Server.UploadDir != "", "server.upload_dir 가 비어 있습니다")
+	check(c.Server.TrashDir != "", "server.trash_dir 가 비어 있습니다")
+	check(c.Server.SessionDir != "", "server.session_dir 가 비어 있습니다")
+	check(c.Server.MaxUpload >= 0, "server.max_upload 는 0 이상이어야 합니다: %s", c.Server.MaxUpload)
+	check(c.Server.DownloadRate >= 0, "server.download_rate 는 0 이상이어야 합니다: %s", c.Server.DownloadRate)
+	check(c.Server.UploadRate >= 0, "server.upload_rate 는 0 이상이어야 합니다: %s", c.Server.UploadRate)
+	check(c.Server.MaxDownloads >= 0, "server.max_downloads 는 0 이상이어야 합니다: %d", c.Server.MaxDownloads)
+	check(c.Server.DownloadQueue >= 0, "server.download_queue 는 0 이상이어야 합니다: %s", c.Server.DownloadQueue)
+	check(c.Server.LargeDownload >= 0, "server.large_download 는 0 이상이어야 합니다: %s", c.Server.LargeDownload)
+	check(c.Server.MinFree >= 0, "server.min_free 는 0 이상이어야 합니다: %s", c.Server.MinFree)
+	check(c.Server.MaxUploadsPerIP >= 0, "server.max_uploads_per_ip 는 0 이상이어야 합니다: %d", c.Server.MaxUploadsPerIP)
+	check(c.Server.WebhookRetries >= 0, "server.webhook_retries 는 0 이상이어야 합니다: %d", c.Server.WebhookRetries)
+	for _, hook := range strings.Split(c.Server.UploadWebhooks, ",") {
+		if hook = strings.TrimSpace(hook); hook != "" {
+			u, err := url.Parse(hook)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "server.upload_webhooks 의 URL 이 잘못되었습니다: %q", hook)
+		}
+	}
+	check(c.Server.HLSSegment >= time.Second, "server.hls_segment 는 1초 이상이어야 합니다: %s", c.Server.HLSSegment)
+	check(c.Server.ThumbWorkers >= 1 && c.Server.ThumbWorkers <= 64, "server.thumb_workers 는 1 ~ 64 여야 합니다: %d", c.Server.ThumbWorkers)
+	check(c.Server.UploadWorkers >= 1 && c.Server.UploadWorkers <= 64, "server.upload_workers 는 1 ~ 64 여야 합니다: %d", c.Server.UploadWorkers)
+	check(c.Server.Versions >= 0 && c.Server.Versions <= 1000, "server.versions 는 0 ~ 1000 이어야 합니다: %d", c.Server.Versions)
+	check(c.Server.Versions == 0 || c.Server.VersionDir != "", "server.versions 를 켰는데 server.version_dir 가 비어 있습니다")
+	check(c.Server.ExpireAfter >= 0, "server.expire_after 는 0 이상이어야 합니다: %s", c.Server.ExpireAfter)
+	check(c.Server.ExpireScan >= time.Second, "server.expire_scan 은 1초 이상이어야 합니다: %s", c.Server.ExpireScan)
+	check(c.Server.TrashRetention >= 0, "server.trash_retention 은 0 이상이어야 합니다: %s", c.Server.TrashRetention)
+
+	names := make(map[string]bool)
+	for i, j := range c.Schedule.Jobs {
+		label := fmt.Sprintf("schedule.jobs[%d] (%s)", i, j.Name)
+		check(j.Name != "", "schedule.jobs[%d]: name 이 비어 있습니다", i)
+		check(!names[j.Name], "%s: 같은 이름의 작업이 또 있습니다", label)
+		names[j.Name] = true
+		if _, err := schedule.Parse(j.Cron); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+		}
+		check(slices.Contains(JobTasks, j.Task), "%s: 알 수 없는 task: %q (%s)", label, j.Task, strings.Join(JobTasks, ", "))
+		check(j.Src != "", "%s: src 가 비어 있습니다", label)
+		check(j.Task != "sync" || j.Dst != "", "%s: sync 에는 dst 가 필요합니다", label)
+		check(j.Jitter >= 0 && j.Timeout >= 0 && j.OlderThan >= 0, "%s: jitter, timeout, older_than 은 0 이상이어야 합니다", label)
+	}
+
+	check(c.Queue.Dir != "", "queue.dir 가 비어 있습니다")
+	check(c.Queue.Workers >= 1 && c.Queue.Workers <= 64, "queue.workers 는 1 ~ 64 여야 합니다: %d", c.Queue.Workers)
+
+	check((c.Server.Cert == "") == (c.Server.Key == ""), "server.cert 와 server.key 는 같이 줘야 합니다")
+	check(validBackend(c.Server.Backend), "알 수 없는 server.backend: %q (memory 또는 s3://bucket/prefix)", c.Server.Backend)
+	check(c.Server.Backend == "" || c.Server.DedupDir == "", "server.dedup_dir 는 server.backend 를 비웠을 때(로컬 디렉토리)만 쓸 수 있습니다")
+	for name, u := range c.Server.CopyTargets {
+		check(name != "" && name != "uploads" && u != "", "server.copy_targets 의 %q: 이름은 uploads 가 아니어야 하고 주소가 있어야 합니다", name)
+	}
+	check(slices.Contains(AccessLogFormats, c.Server.AccessLogFormat), "알 수 없는 server.access_log_format: %q (%s)", c.Server.AccessLogFormat, strings.Join(AccessLogFormats, ", "))
+	check(c.Server.AccessLogMaxSize > 0 && c.Server.AccessLogBackups >= 0, "server.access_log_max_size 는 0 보다, access_log_backups 는 0 이상이어야 합니다")
+	check(slices.Contains(Collisions, c.Server.Collision), "알 수 없는 server.collision: %q (%s)", c.Server.Collision, strings.Join(Collisions, ", "))
+	check(c.Server.SFTPAddr == "" || (c.Server.SFTPHostKey != "" && c.Server.SFTPAuthorizedKeys != ""), "server.sftp_addr 를 주면 server.sftp_host_key 와 server.sftp_authorized_keys 도 필요합니다")
+	check(slices.Contains(WebDAVModes, c.Server.WebDAV), "알 수 없는 server.webdav: %q (%s)", c.Server.WebDAV, strings.Join(WebDAVModes, ", "))
+	check(slices.Contains(ChecksumStores, c.Checksum.Store), "알 수 없는 checksum.store: %q (%s)", c.Checksum.Store, strings.Join(ChecksumStores, ", "))
+	check(c.Checksum.Store != "db" || c.Checksum.DB != "", "checksum.store 가 db 인데 checksum.db 가 비어 있습니다")
+
+	check(c.Extract.MaxFiles > 0, "extract.max_files 는 1 이상이어야 합니다: %d", c.Extract.MaxFiles)
+	check(c.Extract.MaxFileSize > 0 && c.Extract.MaxTotal > 0, "extract.max_file_size, extract.max_total 은 0 보다 커야 합니다")
+
+	check(len(c.Scan.Command) == 0 || c.Scan.Command[0] != "", "scan.command 의 첫 값(실행 파일)이 비어 있습니다")
+	check(c.Scan.Timeout >= 0, "scan.timeout 은 0 이상이어야 합니다: %s", c.Scan.Timeout)
+
+	for _, t := range c.Policy.Types {
+		check(policy.ValidType(t), "policy.types 의 형식이 잘못됐습니다 (예: image/png, image/*): %q", t)
+	}
+	for _, t := range slices.Sorted(maps.Keys(c.Policy.MaxSize)) {
+		check(policy.ValidType(t), "policy.max_size 의 형식이 잘못됐습니다 (예: image/png, image/*): %q", t)
+		check(c.Policy.MaxSize[t] > 0, "policy.max_size 의 %s 는 0 보다 커야 합니다", t)
+	}
+
+	check(c.Cache.MaxSize > 0 && c.Cache.MaxAge > 0, "cache.max_size, cache.max_age 는 0 보다 커야 합니다")
+
+	check(c.Usage.Flush > 0, "usage.flush 는 0 보다 커야 합니다: %s", c.Usage.Flush)
+	check(c.Usage.Monthly >= 0, "usage.monthly 는 0 이상이어야 합니다: %s", c.Usage.Monthly)
+	check(c.Usage.File != "" || c.Usage.Monthly == 0, "usage.monthly 를 쓰려면 usage.file 이 필요합니다")
+	check(c.Usage.Storage >= 0, "usage.storage 는 0 이상이어야 합니다: %s", c.Usage.Storage)
+	check(c.Usage.StorageFile != "" || c.Usage.Storage == 0, "usage.storage 를 쓰려면 usage.storage_file 이 필요합니다")
+	keys := make(map[string]bool)
+	for name, k := range c.Usage.Keys {
+		check(k.Key != "", "usage.keys.%s: key 가 비어 있습니다", name)
+		check(k.Key == "" || !keys[k.Key], "usage.keys.%s: 다른 계정과 같은 key 입니다", name)
+		check(k.Monthly == nil || *k.Monthly >= 0, "usage.keys.%s: monthly 는 0 이상이어야 합니다", name)
+		check(k.Storage == nil || *k.Storage >= 0, "usage.keys.%s: storage 는 0 이상이어야 합니다", name)
+		check(k.Storage == nil || c.Usage.StorageFile != "", "usage.keys.%s: storage 를 쓰려면 usage.storage_file 이 필요합니다", name)
+		for _, sc := range k.Scopes {
+			check(slices.Contains(Scopes, sc), "usage.keys.%s: 알 수 없는 scope: %q (%s)", name, sc, strings.Join(Scopes, ", "))
+		}
+		keys[k.Key] = true
+	}
+	check(c.Auth.JWTSecret == "" || len(c.Auth.JWTSecret) >= 32, "auth.jwt_secret 은 32바이트 이상이어야 합니다")
+	check(c.Auth.SignSecret == "" || len(c.Auth.SignSecret) >= 32, "auth.sign_secret 은 32바이트 이상이어야 합니다")
+	check(c.Auth.SignMaxTTL >= 0, "auth.sign_max_ttl 은 0 이상이어야 합니다: %s", c.Auth.SignMaxTTL)
+
+	for o := range strings.SplitSeq(c.CORS.Origins, ",") {
+		if o = strings.TrimSpace(o); o == "" {
+			continue
+		}
+		check(validOrigin(o), "cors.origins 의 출처가 잘못됐습니다 (예: https://app.example.com, https://*.example.com, *): %q", o)
+	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age 는 0 이상이어야 합니다: %s", c.CORS.MaxAge)
+
+	check(slices.Contains(NotifyOn, c.Notify.On), "알 수 없는 notify.on: %q (%s)", c.Notify.On, strings.Join(NotifyOn, ", "))
+	check(c.Notify.Retries >= 0 && c.Notify.Timeout >= 0, "notify.retries, notify.timeout 은 0 이상이어야 합니다")
+	check(c.Notify.SMTP == "" || (c.Notify.From != "" && c.Notify.To != ""), "notify.smtp 를 쓰려면 notify.from 과 notify.to 가 필요합니다")
+
+	for name, p := range c.Plugins {
+		check(len(p.Command) > 0 && p.Command[0] != "", "plugins.%s: command 가 비어 있습니다", name)
+		check(p.Timeout >= 0, "plugins.%s: timeout 은 0 이상이어야 합니다", name)
+		for _, kv := range p.Env {
+			check(strings.Contains(kv, "="), "plugins.%s: env 는 KEY=VALUE 형식이어야 합니다: %q", name, kv)
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "text", "json":
+	default:
+		errs = append(errs, msg.Errorf("알 수 없는 log.format: %q (text, json)", c.Log.Format))
+	}
+	return errors.Join(errs...)
+}
+
+// YAML 설정 파일 형식으로 (지금 적용된 값 확인용, 그대로 파일로 저장해서 써도 돼)
+func (c Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
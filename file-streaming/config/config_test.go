@@ -0,0 +1,249 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+func writeYAML(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// 플래그 > 환경 변수 > 파일 > 기본값
+func TestPrecedence(t *testing.T) {
+	path := writeYAML(t, "transfer:\n  buffer: 64KB\n  retries: 2\nlog:\n  level: warn\n  format: json\n")
+	t.Setenv("FS_RETRIES", "5")
+	t.Setenv("LOG_LEVEL", "error")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.Transfer.RegisterFlags(fs)
+	cfg.Log.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-log-level", "debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Transfer.Buffer != 64<<10 {
+		t.Errorf("buffer = %s, want 64KB (파일)", cfg.Transfer.Buffer)
+	}
+	if cfg.Transfer.Retries != 5 {
+		t.Errorf("retries = %d, want 5 (환경 변수)", cfg.Transfer.Retries)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("log.level = %q, want debug (플래그)", cfg.Log.Level)
+	}
+	if cfg.Log.Format != "json" {
+		t.Errorf("log.format = %q, want json (파일)", cfg.Log.Format)
+	}
+	if cfg.Compress.Codec != "gzip" || cfg.Server.Addr != ":8080" {
+		t.Errorf("기본값이 사라짐: codec %q, addr %q", cfg.Compress.Codec, cfg.Server.Addr)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(writeYAML(t, "transfer:\n  bufer: 64KB\n")); err == nil {
+		t.Error("오타 난 키인데 에러가 없음")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "none.yaml")); err == nil {
+		t.Error("없는 파일인데 에러가 없음")
+	}
+	if _, err := Load(writeYAML(t, "")); err != nil {
+		t.Errorf("빈 파일 = %v, 기본값이어야 해", err)
+	}
+	t.Setenv("FS_BUFFER", "lots")
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "FS_BUFFER") {
+		t.Errorf("잘못된 환경 변수 = %v", err)
+	}
+}
+
+// env 태그에 이름이 여러 개면 앞에 있는 게 이겨 - 시간 간격, bool 도 환경 변수로
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"STREAMCTL_TRACE":    "stderr",
+		"TRACE":              "grpc://x:4317",
+		"FS_TRASH_RETENTION": "48h",
+		"FS_GZIP":            "false",
+	}
+	cfg := Default()
+	err := applyEnv(&cfg, func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Trace.Target != "stderr" {
+		t.Errorf("trace = %q, want stderr", cfg.Trace.Target)
+	}
+	if cfg.Server.TrashRetention != 48*time.Hour {
+		t.Errorf("trash_retention = %v", cfg.Server.TrashRetention)
+	}
+	if cfg.Server.Gzip {
+		t.Error("FS_GZIP=false 인데 gzip 이 켜져 있음")
+	}
+
+	env = map[string]string{"FS_TRASH_RETENTION": "30"}
+	if err := applyEnv(&cfg, func(name string) (string, bool) { v, ok := env[name]; return v, ok }); err == nil {
+		t.Error("단위 없는 시간 간격인데 에러가 없음")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("기본값 Validate = %v", err)
+	}
+	cfg.Transfer.Buffer = 0
+	cfg.Compress.Codec = "zstd"
+	cfg.Log.Format = "xml"
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("잘못된 값인데 에러가 없음")
+	}
+	// 잘못된 항목을 한 번에 모아서
+	for _, want := range []string{"transfer.buffer", "compress.codec", "log.format"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("에러에 %s 가 없음: %v", want, err)
+		}
+	}
+}
+
+func TestSize(t *testing.T) {
+	for in, want := range map[string]string{"512": "512", "64KB": "64KB", "1024KB": "1MB", "2GB": "2GB", "1500": "1500", "0": "0"} {
+		var s Size
+		if err := s.Set(in); err != nil {
+			t.Errorf("Set(%q) = %v", in, err)
+			continue
+		}
+		if s.String() != want {
+			t.Errorf("Set(%q).String() = %q, want %q", in, s.String(), want)
+		}
+	}
+	var s Size
+	if err := s.Set("big"); err == nil {
+		t.Error("잘못된 크기인데 에러가 없음")
+	}
+}
+
+func TestArgValue(t *testing.T) {
+	args := []string{"-json", "-config", "a.yaml", "--level=3", "--", "-x", "y"}
+	for name, want := range map[string]string{"config": "a.yaml", "level": "3"} {
+		if got, ok := ArgValue(args, name); !ok || got != want {
+			t.Errorf("ArgValue(%s) = %q, %v, want %q", name, got, ok, want)
+		}
+	}
+	if _, ok := ArgValue(args, "x"); ok {
+		t.Error("-- 뒤의 값은 플래그가 아님")
+	}
+
+	t.Setenv(EnvFile, "env.yaml")
+	if got := PathFromArgs(args); got != "a.yaml" {
+		t.Errorf("PathFromArgs = %q, 플래그가 먼저여야 해", got)
+	}
+	if got := PathFromArgs(nil); got != "env.yaml" {
+		t.Errorf("PathFromArgs = %q, want %s 값", got, EnvFile)
+	}
+}
+
+// -lang 이 FS_LANG 보다 먼저, 고른 언어는 바로 msg 에 반영
+func TestLocale(t *testing.T) {
+	t.Cleanup(func() { msg.SetLang(msg.Korean) })
+	t.Setenv("FS_LANG", "en")
+
+	l, err := LocaleFromArgs([]string{"-json", "x"})
+	if err != nil || l.Lang != msg.English || msg.Current() != msg.English {
+		t.Errorf("FS_LANG=en = %q, %v, 지금 언어 %q", l.Lang, err, msg.Current())
+	}
+	if l, err = LocaleFromArgs([]string{"-lang=ko", "x"}); err != nil || msg.Current() != msg.Korean {
+		t.Errorf("-lang=ko = %q, %v, 지금 언어 %q", l.Lang, err, msg.Current())
+	}
+	if _, err := LocaleFromArgs([]string{"-lang", "fr"}); err == nil {
+		t.Error("모르는 언어인데 에러가 없음")
+	}
+
+	t.Setenv("FS_LANG", "")
+	cfg, _ := Load(writeYAML(t, "locale:\n  lang: en\n"))
+	if err := cfg.Locale.Apply(nil); err != nil || msg.Current() != msg.English {
+		t.Errorf("설정 파일 locale = %v, 지금 언어 %q", err, msg.Current())
+	}
+}
+
+// 파일이 바뀌면 새 값을, 명령줄에서 준 플래그는 그대로
+func TestReload(t *testing.T) {
+	path := writeYAML(t, "transfer:\n  retries: 1\n  buffer: 64KB\n")
+	register := func(fs *flag.FlagSet, cfg *Config) {
+		cfg.Transfer.RegisterFlags(fs)
+		fs.IntVar(&cfg.Transfer.Retries, "retries", cfg.Transfer.Retries, "")
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	register(fs, &cfg)
+	if err := fs.Parse([]string{"-buffer", "1MB"}); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(path, []byte("transfer:\n  retries: 3\n  buffer: 128KB\n"), 0644)
+	cfg, err = Reload(path, fs, register)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Transfer.Retries != 3 || cfg.Transfer.Buffer != 1<<20 {
+		t.Errorf("Reload = retries %d, buffer %s, want 3, 1MB", cfg.Transfer.Retries, cfg.Transfer.Buffer)
+	}
+
+	os.WriteFile(path, []byte("transfer:\n  retries: -1\n"), 0644)
+	if _, err := Reload(path, fs, register); err == nil {
+		t.Error("잘못된 값으로 바뀌었는데 에러가 없음")
+	}
+}
+
+// YAML() 로 내보낸 걸 다시 읽으면 같은 설정 - 예시 파일도 읽히고 기본값이어야 해
+func TestYAMLRoundTrip(t *testing.T) {
+	cfg := Default()
+	cfg.Transfer.Buffer = 1 << 20
+	cfg.Server.Gzip = false
+	data, err := cfg.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(writeYAML(t, string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("다시 읽은 설정이 다름:\n%s", data)
+	}
+
+	example, err := Load("example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := example.Validate(); err != nil {
+		t.Errorf("example.yaml Validate = %v", err)
+	}
+	def := Default()
+	if example.Transfer != def.Transfer || example.Compress != def.Compress || example.Log != def.Log {
+		t.Errorf("example.yaml 이 기본값과 다름: %+v %+v %+v", example.Transfer, example.Compress, example.Log)
+	}
+}
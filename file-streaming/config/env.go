@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// applyEnv env 태그가 붙은 필드를 환경 변수 값으로 덮어 (설정 안 된 변수는 그대로 둬)
+// ⭐ lookup 을 받아서 os.LookupEnv 대신 맵으로도 돌릴 수 있어
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	return applyEnvStruct(reflect.ValueOf(cfg).Elem(), lookup)
+}
+
+func applyEnvStruct(v reflect.Value, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field, fv := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnvStruct(fv, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+		tag := field.Tag.Get("env")
+		if tag == "" {
+			continue
+		}
+		for _, name := range strings.Split(tag, ",") {
+			raw, ok := lookup(name)
+			if !ok || raw == "" {
+				continue
+			}
+			if err := setField(fv, raw); err != nil {
+				return msg.Errorf("환경 변수 %s: %w", name, err)
+			}
+			break // 앞에 있는 이름이 이겨
+		}
+	}
+	return nil
+}
+
+// setField 문자열 하나를 필드 타입에 맞게 넣어 (Size 처럼 UnmarshalText 가 있으면 그걸 먼저)
+func setField(fv reflect.Value, raw string) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	// time.Duration 도 Kind 는 Int64 라서 정수보다 먼저 "10m" 같은 형식으로
+	if d, ok := fv.Addr().Interface().(*time.Duration); ok {
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return msg.Errorf("시간 간격이 아닙니다 (예: 30s, 10m, 168h): %q", raw)
+		}
+		*d = v
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return msg.Errorf("정수가 아닙니다: %q", raw)
+		}
+		fv.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return msg.Errorf("true/false 가 아닙니다: %q", raw)
+		}
+		fv.SetBool(b)
+	default:
+		return msg.Errorf("지원하지 않는 필드 타입: %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/extract"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/notify"
+	"github.com/hellotect2022go/study-go/file-streaming/policy"
+	"github.com/hellotect2022go/study-go/file-streaming/scan"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+)
+
+// 섹션별 플래그 등록 - 플래그가 설정 필드를 직접 가리켜서, 명령줄에서 준 값만 파일/환경 변수 값을 덮어
+// ⭐ 같은 섹션은 어느 도구에서든 같은 플래그 이름이라, streamctl serve -addr 과 step09 -addr 이 같은 뜻이야
+
+// RegisterFlags -buffer -rate -progress
+func (t *Transfer) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(&t.Buffer, "buffer", msg.T("복사 버퍼 크기 (예: 64KB, 1MB)"))
+	fs.Var(&t.Rate, "rate", msg.T("초당 최대 전송량 (예: 10MB, 0 이면 제한 없음)"))
+	fs.Var(&t.Progress, "progress", msg.T("진행률 출력 방식 (none|text|bar|json) - stderr 로 나가"))
+}
+
+// RegisterFlags -codec -level
+func (c *Compress) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Codec, "codec", c.Codec, msg.T("압축 방식 (gzip)"))
+	fs.IntVar(&c.Level, "level", c.Level, msg.T("압축 레벨 (1 빠름 ~ 9 작음, -1 기본)"))
+}
+
+// RegisterFlags -addr -dir -trash -sessions -index -backend -s3-endpoint -encryption-keys -dedup-dir -max-upload -upload-workers -download-rate -upload-rate -max-downloads -download-queue -large-download -max-uploads-per-ip -min-free -upload-webhooks -webhook-retries -gzip -gzip-skip -collision -versions -version-dir -expire-after -expire-scan -trash-retention -thumbnails -thumb-workers -hls -hls-segment -webdav -sftp-addr -sftp-host-key -sftp-authorized-keys -tcp-addr -tls -cert -key
+func (s *Server) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.Addr, "addr", s.Addr, msg.T("listen 주소"))
+	fs.StringVar(&s.UploadDir, "dir", s.UploadDir, msg.T("업로드/다운로드 디렉토리"))
+	fs.StringVar(&s.TrashDir, "trash", s.TrashDir, msg.T("삭제한 파일을 옮겨둘 휴지통"))
+	fs.StringVar(&s.SessionDir, "sessions", s.SessionDir, msg.T("이어 올리기(/api/uploads) 중인 파일과 세션 저널을 둘 디렉토리"))
+	fs.StringVar(&s.IndexFile, "index", s.IndexFile, msg.T("/ 에서 내장 UI 대신 보여줄 페이지 (비우면 내장 UI)"))
+	fs.StringVar(&s.Backend, "backend", s.Backend, msg.T("업로드 파일을 둘 저장소: 비우면 -dir, memory, s3://bucket/prefix (키는 AWS_ACCESS_KEY_ID 같은 환경 변수)"))
+	fs.StringVar(&s.S3Endpoint, "s3-endpoint", s.S3Endpoint, msg.T("s3:// 저장소의 엔드포인트 (예: http://localhost:9000, 비우면 AWS)"))
+	fs.StringVar(&s.EncryptionKeys, "encryption-keys", s.EncryptionKeys, msg.T("업로드를 암호화해서 둘 키 파일 (줄마다 32바이트 키를 hex/base64 로, 첫 줄로 암호화 - 비우면 그대로)"))
+	fs.StringVar(&s.DedupDir, "dedup-dir", s.DedupDir, msg.T("같은 내용의 업로드를 한 벌만 둘 저장소 (예: ./store, 비우면 안 써 - -dir 과 같은 파일시스템)"))
+	fs.Var(&s.MaxUpload, "max-upload", msg.T("업로드 한 건의 최대 크기 (예: 1GB, 0 이면 제한 없음)"))
+	fs.IntVar(&s.UploadWorkers, "upload-workers", s.UploadWorkers, msg.T("/upload 의 files 파트를 동시에 저장할 수"))
+	fs.Var(&s.DownloadRate, "download-rate", msg.T("다운로드 한 건의 초당 최대 크기 (예: 10MB, 0 이면 제한 없음 - 요청의 ?limit= 은 이보다 낮게만)"))
+	fs.Var(&s.UploadRate, "upload-rate", msg.T("업로드 한 건의 초당 최대 크기 (예: 5MB, 0 이면 제한 없음)"))
+	fs.IntVar(&s.MaxDownloads, "max-downloads", s.MaxDownloads, msg.T("동시에 보낼 큰 다운로드 수 (0 이면 제한 없음 - 넘치면 기다렸다가 503)"))
+	fs.DurationVar(&s.DownloadQueue, "download-queue", s.DownloadQueue, msg.T("다운로드 자리가 날 때까지 기다릴 최대 시간 (0 이면 바로 503)"))
+	fs.Var(&s.LargeDownload, "large-download", msg.T("-max-downloads 로 셀 다운로드의 최소 크기 (예: 1MB, 0 이면 전부)"))
+	fs.IntVar(&s.MaxUploadsPerIP, "max-uploads-per-ip", s.MaxUploadsPerIP, msg.T("클라이언트 주소 하나가 동시에 올릴 수 있는 업로드 수 (0 이면 제한 없음 - 넘치면 429)"))
+	fs.Var(&s.MinFree, "min-free", msg.T("업로드 디렉토리에 이보다 적게 남으면 /healthz, /readyz 가 503 (예: 1GB, 0 이면 보기만)"))
+	fs.StringVar(&s.UploadWebhooks, "upload-webhooks", s.UploadWebhooks, msg.T("업로드가 저장될 때마다 JSON 을 POST 할 웹훅 URL (쉼표 구분)"))
+	fs.IntVar(&s.WebhookRetries, "webhook-retries", s.WebhookRetries, msg.T("업로드 웹훅이 실패하면 다시 보낼 횟수"))
+	fs.BoolVar(&s.Gzip, "gzip", s.Gzip, msg.T("/download 응답을 gzip 으로 압축 (클라이언트가 받을 수 있고 텍스트 같은 형식일 때만)"))
+	fs.StringVar(&s.GzipSkip, "gzip-skip", s.GzipSkip, msg.T("압축하지 않을 확장자 (쉼표 구분, 예: .bin,.dat - zip/gz/이미지/동영상은 알아서 건너뛰어)"))
+	fs.BoolVar(&s.TLS, "tls", s.TLS, msg.T("HTTPS 로 서빙 (-cert/-key 를 안 주면 자체 서명 인증서를 만들어 - 로컬 테스트용)"))
+	fs.StringVar(&s.Cert, "cert", s.Cert, msg.T("TLS 인증서 PEM 파일 (주면 -tls 없이도 HTTPS)"))
+	fs.StringVar(&s.Key, "key", s.Key, msg.T("TLS 개인키 PEM 파일"))
+	fs.StringVar(&s.Collision, "collision", s.Collision, msg.T("올린 이름의 파일이 이미 있을 때: overwrite(덮어쓰기) | reject(409) | uuid(이름-<uuid>) | version(이름-v2 …)"))
+	fs.IntVar(&s.Versions, "versions", s.Versions, msg.T("같은 이름을 덮어쓸 때 예전 내용을 남길 개수 (0 이면 안 남겨 - /api/files/<이름>/versions 로 보고 되돌려)"))
+	fs.StringVar(&s.VersionDir, "version-dir", s.VersionDir, msg.T("덮어쓴 파일의 예전 버전을 둘 디렉토리"))
+	fs.DurationVar(&s.ExpireAfter, "expire-after", s.ExpireAfter, msg.T("X-Expire-After 없이 올린 파일을 지울 때까지의 시간 (0 이면 안 지워, 헤더로는 이보다 짧게만)"))
+	fs.DurationVar(&s.ExpireScan, "expire-scan", s.ExpireScan, msg.T("만료된 업로드를 찾아 지우는 주기"))
+	fs.DurationVar(&s.TrashRetention, "trash-retention", s.TrashRetention, msg.T("지운 파일을 휴지통에 남겨 두는 기간 - 지나면 영구 삭제 (0 이면 안 지워)"))
+	fs.BoolVar(&s.Thumbnails, "thumbnails", s.Thumbnails, msg.T("이미지 업로드마다 small/medium 썸네일을 만들어 /thumb 로 내보내"))
+	fs.IntVar(&s.ThumbWorkers, "thumb-workers", s.ThumbWorkers, msg.T("썸네일을 동시에 만들 수"))
+	fs.BoolVar(&s.HLS, "hls", s.HLS, msg.T("MPEG-TS(.ts) 동영상을 /hls?file= 로 HLS 재생 목록으로 내보내"))
+	fs.DurationVar(&s.HLSSegment, "hls-segment", s.HLSSegment, msg.T("HLS 조각 길이 목표 (실제로는 다음 키프레임까지)"))
+	fs.StringVar(&s.WebDAV, "webdav", s.WebDAV, msg.T("/dav 로 업로드 디렉토리를 WebDAV 드라이브로: off | read-only | read-write"))
+	fs.StringVar(&s.SFTPAddr, "sftp-addr", s.SFTPAddr, msg.T("같은 파일을 SFTP 로도 열 주소 (예: :2022, 비우면 안 열어 - 공개키로만 로그인)"))
+	fs.StringVar(&s.SFTPHostKey, "sftp-host-key", s.SFTPHostKey, msg.T("SFTP 호스트 개인키 파일 (없으면 ed25519 로 만들어 저장)"))
+	fs.StringVar(&s.SFTPAuthorizedKeys, "sftp-authorized-keys", s.SFTPAuthorizedKeys, msg.T("SFTP 로 로그인할 수 있는 공개키 (authorized_keys 형식, 인증을 켰으면 주석이 계정 이름)"))
+	fs.StringVar(&s.TCPAddr, "tcp-addr", s.TCPAddr, msg.T("streamctl send 의 TCP 프로토콜로도 받을 주소 (예: :9000, 비우면 안 열어 - 인증을 켜면 거절)"))
+	fs.StringVar(&s.AccessLog, "access-log", s.AccessLog, msg.T("요청마다 한 줄씩 남길 접근 로그 파일 (비우면 안 남겨)"))
+	fs.StringVar(&s.AccessLogFormat, "access-log-format", s.AccessLogFormat, msg.T("접근 로그 형식 (json|combined)"))
+	fs.Var(&s.AccessLogMaxSize, "access-log-max-size", msg.T("접근 로그를 이 크기에서 .1, .2 … 로 돌려 (예: 100MB)"))
+	fs.IntVar(&s.AccessLogBackups, "access-log-backups", s.AccessLogBackups, msg.T("남길 예전 접근 로그 파일 수 (0 이면 돌릴 때 버려)"))
+	fs.StringVar(&s.AuditLog, "audit-log", s.AuditLog, msg.T("지우기, 이름 바꾸기를 한 줄씩 덧붙일 감사 로그 파일 (비우면 안 남겨)"))
+}
+
+// RegisterFlags -search-index
+func (s *Search) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.Index, "search-index", s.Index, msg.T("전문 검색 색인 파일"))
+}
+
+// RegisterFlags -report -journal -parquet
+func (a *Analyzer) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.Report, "report", a.Report, msg.T("리포트를 저장할 파일"))
+	fs.StringVar(&a.Journal, "journal", a.Journal, msg.T("분석 요약을 덧붙일 공유 저널 파일 (여러 인스턴스가 동시에 써도 안전)"))
+	fs.StringVar(&a.Parquet, "parquet", a.Parquet, msg.T("줄마다 파싱한 레코드(시간, 레벨, IP, 경로, 상태, 지연)를 쓸 Parquet 파일"))
+}
+
+// RegisterFlags -queue -workers
+func (q *Queue) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&q.Dir, "queue", q.Dir, msg.T("작업 큐 디렉토리"))
+	fs.IntVar(&q.Workers, "workers", q.Workers, msg.T("동시에 돌릴 작업 수"))
+}
+
+// RegisterFlags -checksum-store -checksum-db
+func (c *Checksum) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Store, "checksum-store", c.Store, msg.T("체크섬 기록을 둘 곳 (off|xattr|db)"))
+	fs.StringVar(&c.DB, "checksum-db", c.DB, msg.T("checksum-store 가 db 일 때 사이드카 DB 파일"))
+}
+
+// RegisterFlags -max-files -max-file-size -max-total
+func (e *Extract) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&e.MaxFiles, "max-files", e.MaxFiles, msg.T("아카이브 엔트리 수 한도"))
+	fs.Var(&e.MaxFileSize, "max-file-size", msg.T("풀린 파일 하나의 크기 한도 (예: 1GB)"))
+	fs.Var(&e.MaxTotal, "max-total", msg.T("풀린 전체 크기 한도 (예: 4GB)"))
+}
+
+// RegisterFlags -lang (값은 등록하기 전에 Apply 로 미리 읽어서 msg.SetLang 해 둬야 해 - 여기서는 -h 에 보이고 파싱할 때 걸리지 않게)
+func (l *Locale) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(&l.Lang, "lang", msg.T("문구 언어 (ko|en, 비우면 LANG 환경 변수로)"))
+}
+
+// RegisterFlags -stats-dump
+func (s *Stats) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&s.Dump, "stats-dump", s.Dump, msg.T("SIGUSR1 을 받으면 상태를 덧붙일 파일 (.json/.jsonl 이면 JSON 한 줄, 비우면 stderr)"))
+}
+
+// RegisterFlags -pid-file -daemon -daemon-log
+func (d *Daemon) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&d.PIDFile, "pid-file", d.PIDFile, msg.T("시작하면 PID 를 적고 끝나면 지울 파일"))
+	fs.BoolVar(&d.Detach, "daemon", d.Detach, msg.T("백그라운드로 떼어내서 실행 (준비되면 돌아와, systemd 에서는 쓰지 마)"))
+	fs.StringVar(&d.LogFile, "daemon-log", d.LogFile, msg.T("-daemon 일 때 stdout/stderr 를 덧붙일 파일 (비우면 버려)"))
+}
+
+// RegisterFlags -usage-file -usage-monthly -usage-storage-file -usage-storage (키 목록은 설정 파일로만)
+func (u *Usage) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&u.File, "usage-file", u.File, msg.T("키별 전송량을 세서 저장할 파일 (비우면 안 세)"))
+	fs.Var(&u.Monthly, "usage-monthly", msg.T("키마다 한 달 전송 한도 (업로드+다운로드, 예: 100GB, 0 이면 제한 없음)"))
+	fs.StringVar(&u.StorageFile, "usage-storage-file", u.StorageFile, msg.T("파일마다 올린 키를 적어 두고 키별 저장 공간을 셀 파일 (비우면 안 세)"))
+	fs.Var(&u.Storage, "usage-storage", msg.T("키마다 올려 둘 수 있는 총 크기 (예: 20GB, 0 이면 제한 없음)"))
+}
+
+// RegisterFlags -public-files -jwt-issuer -jwt-audience -sign-max-ttl (비밀 키는 설정 파일이나 FS_JWT_SECRET, FS_SIGN_SECRET 으로만 - 플래그는 ps 에 보여)
+func (a *Auth) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&a.PublicFiles, "public-files", a.PublicFiles, msg.T("인증을 켜도 /files/ 는 키 없이 열어 둬"))
+	fs.StringVar(&a.JWTIssuer, "jwt-issuer", a.JWTIssuer, msg.T("받을 JWT 의 발급자(iss) (비우면 확인 안 함)"))
+	fs.StringVar(&a.JWTAudience, "jwt-audience", a.JWTAudience, msg.T("받을 JWT 의 대상(aud) (비우면 확인 안 함)"))
+	fs.DurationVar(&a.SignMaxTTL, "sign-max-ttl", a.SignMaxTTL, msg.T("서명한 다운로드 URL 의 최대 유효 시간 (0 이면 24h)"))
+}
+
+// RegisterFlags -cors-origins -cors-methods -cors-headers -cors-expose -cors-max-age
+func (c *CORS) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Origins, "cors-origins", c.Origins, msg.T("API 를 부를 수 있는 다른 출처 (쉼표 구분, 예: https://app.example.com,https://*.example.com, * 면 어디서든 - 비우면 CORS 끔)"))
+	fs.StringVar(&c.Methods, "cors-methods", c.Methods, msg.T("CORS 로 허락할 메서드 (쉼표 구분, 비우면 GET,HEAD,POST,PUT,PATCH,DELETE)"))
+	fs.StringVar(&c.Headers, "cors-headers", c.Headers, msg.T("CORS 로 보내도 되는 요청 헤더 (쉼표 구분, 비우면 브라우저가 물어본 그대로)"))
+	fs.StringVar(&c.Expose, "cors-expose", c.Expose, msg.T("브라우저 JS 가 읽을 수 있는 응답 헤더 (쉼표 구분, 비우면 Content-Range, X-Content-SHA256 같은 기본 목록)"))
+	fs.DurationVar(&c.MaxAge, "cors-max-age", c.MaxAge, msg.T("브라우저가 preflight 결과를 기억할 시간"))
+}
+
+// RegisterFlags -cache -cache-max-size
+func (c *Cache) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Dir, "cache", c.Dir, msg.T("원격(sftp://) 원본을 이 디렉토리에 캐시해서 다음에는 원본이 안 바뀌었으면 캐시에서 읽어"))
+	fs.Var(&c.MaxSize, "cache-max-size", msg.T("캐시 크기 한도 (넘으면 오래 안 쓴 것부터 지워)"))
+}
+
+// Options storage.NewCache 에 넘길 설정
+func (c Cache) Options() storage.CacheOptions {
+	return storage.CacheOptions{Dir: c.Dir, MaxSize: int64(c.MaxSize), MaxAge: c.MaxAge}
+}
+
+// RegisterFlags -notify-webhook -notify-on (메일 설정은 설정 파일/환경 변수로)
+func (n *Notify) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&n.Webhook, "notify-webhook", n.Webhook, msg.T("끝나면 결과를 POST 할 웹훅 URL"))
+	fs.StringVar(&n.On, "notify-on", n.On, msg.T("알림을 보낼 때: all | failure"))
+}
+
+// Options notify.New 에 넘길 설정
+func (n Notify) Options() notify.Options {
+	var to []string
+	for addr := range strings.SplitSeq(n.To, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	return notify.Options{
+		FailuresOnly: n.On == "failure",
+		Webhook:      n.Webhook,
+		SMTP:         n.SMTP, From: n.From, To: to, Username: n.Username, Password: n.Password,
+		Subject: n.Subject, Template: n.Template,
+		Retries: n.Retries, Timeout: n.Timeout,
+	}
+}
+
+// Options extract.ExtractFile 에 넘길 한도
+func (e Extract) Options() extract.Options {
+	return extract.Options{MaxFiles: e.MaxFiles, MaxFileSize: int64(e.MaxFileSize), MaxTotal: int64(e.MaxTotal)}
+}
+
+// Scanner scan.command 가 있으면 그 명령, 없으면 scan.Nop
+func (s Scan) Scanner() scan.Scanner {
+	if len(s.Command) == 0 {
+		return scan.Nop{}
+	}
+	return scan.Command{Args: s.Command, Timeout: s.Timeout, Infected: s.Infected}
+}
+
+// Rules policy 섹션을 policy.Rules 로 (크기는 바이트로)
+func (p Policy) Rules() policy.Rules {
+	var sizes map[string]int64
+	if len(p.MaxSize) > 0 {
+		sizes = make(map[string]int64, len(p.MaxSize))
+		for t, size := range p.MaxSize {
+			sizes[t] = int64(size)
+		}
+	}
+	return policy.Rules{Allow: p.Allow, Block: p.Block, Types: p.Types, MaxSize: sizes}
+}
+
+// RegisterFlags -log-level -log-format
+func (l *Log) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&l.Level, "log-level", l.Level, msg.T("로그 레벨 (debug|info|warn|error)"))
+	fs.StringVar(&l.Format, "log-format", l.Format, msg.T("로그 형식 (text|json) - stderr 로 나가"))
+}
+
+// RegisterFlags -trace
+func (t *Trace) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&t.Target, "trace", t.Target, msg.T("OpenTelemetry 트레이스 대상 (stderr, grpc://호스트:4317, http://호스트:4318)"))
+}
+
+// RegisterConfigFlag -config (값은 PathFromArgs 가 파싱 전에 이미 읽었고, 여기서는 -h 에 보이고 파싱 오류가 안 나게만)
+func RegisterConfigFlag(fs *flag.FlagSet) {
+	fs.String("config", "", msg.Sprintf("YAML 설정 파일 (기본: %s 환경 변수)", EnvFile))
+}
+
+// Options logging.Setup 에 넘길 값
+func (l Log) Options() logging.Options {
+	return logging.Options{Level: l.Level, Format: l.Format}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+)
+
+// Size 바이트 크기 - YAML, 환경 변수, 플래그 어디서든 "64KB", "10MB" 처럼 써 (1KB = 1024 바이트)
+// ⭐ flag.Value 와 encoding.TextUnmarshaler 를 둘 다 구현해서 파싱 규칙이 한 군데야
+type Size int64
+
+// Set flag.Value
+func (s *Size) Set(v string) error {
+	n, err := gendata.ParseSize(v)
+	if err != nil {
+		return err
+	}
+	*s = Size(n)
+	return nil
+}
+
+// String 나누어떨어지는 가장 큰 단위로 ("32KB", "1GB", "1500")
+func (s Size) String() string {
+	n := int64(s)
+	switch {
+	case n == 0:
+		return "0"
+	case n%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", n>>30)
+	case n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	}
+	return fmt.Sprint(n)
+}
+
+// UnmarshalText YAML 값과 환경 변수
+func (s *Size) UnmarshalText(text []byte) error { return s.Set(string(text)) }
+
+// MarshalText YAML 로 내보낼 때 사람이 읽는 단위로
+func (s Size) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// Int 버퍼 크기처럼 int 가 필요한 곳에
+func (s Size) Int() int { return int(s) }
@@ -0,0 +1,191 @@
+// Package daemon 은 서버를 서비스로 돌릴 때 필요한 약속들이야 - systemd 소켓 활성화, sd_notify, PID 파일, SIGHUP 다시 읽기, 백그라운드 실행.
+//
+// ⭐ systemd 가 없는 곳에서도 그대로 돌아가게 만들었어 - LISTEN_FDS 가 없으면 Listen 은 그냥 net.Listen,
+// NOTIFY_SOCKET 이 없으면 Notify 는 아무것도 안 해. 그래서 서버 코드는 조건 없이 부르기만 하면 돼.
+//
+//	ln, _ := daemon.Listen("tcp", ":8080")     // systemd 가 넘겨준 소켓이 있으면 그걸
+//	daemon.Notify(daemon.Ready)                // Type=notify 면 여기서 "시작 완료"
+//	daemon.OnReload(ctx, reload, nil)          // systemctl reload (SIGHUP)
+package daemon
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// sd_notify 상태 (여러 줄을 "\n" 으로 이어서 한 번에 보내도 돼)
+const (
+	Ready     = "READY=1"
+	Stopping  = "STOPPING=1"
+	Reloading = "RELOADING=1"
+)
+
+// listenFDsStart systemd 가 넘겨주는 첫 소켓 번호 (SD_LISTEN_FDS_START) - 테스트에서만 바꿔
+var listenFDsStart = 3
+
+var (
+	inheritOnce sync.Once
+	inherited   []net.Listener
+	inheritErr  error
+)
+
+// Listeners systemd 소켓 활성화로 물려받은 리스너 (LISTEN_PID 가 내 PID 이고 LISTEN_FDS 가 있을 때만, 아니면 nil)
+// ⭐ 환경 변수는 한 번 읽고 지워 - 필터 같은 자식 프로세스가 자기 소켓인 줄 알면 안 돼서. 그래서 결과는 기억해 뒀다가 돌려줘
+func Listeners() ([]net.Listener, error) {
+	inheritOnce.Do(func() { inherited, inheritErr = listenFDs() })
+	return inherited, inheritErr
+}
+
+func listenFDs() ([]net.Listener, error) {
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if pid == "" || fds == "" {
+		return nil, nil
+	}
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		return nil, nil // 다른 프로세스 몫 (systemd 가 부모에게 준 걸 그대로 물려받은 경우)
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, msg.Errorf("LISTEN_FDS 형식 오류: %q", fds)
+	}
+	lns := make([]net.Listener, 0, n)
+	for i := range n {
+		name := "LISTEN_FD_" + strconv.Itoa(listenFDsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(listenFDsStart+i), name)
+		// FileListener 가 close-on-exec 로 복제하니까 원래 번호는 닫아 (안 닫으면 자식에게 새어 나가)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range lns {
+				l.Close()
+			}
+			return nil, msg.Errorf("물려받은 소켓 %s: %w", name, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// Listen systemd 가 넘겨준 소켓이 있으면 첫 번째를, 없으면 net.Listen(network, addr)
+// (소켓을 넘겨받으면 주소는 .socket 유닛의 ListenStream= 이 정해서 addr 은 안 봐)
+func Listen(network, addr string) (net.Listener, error) {
+	lns, err := Listeners()
+	if err != nil {
+		return nil, err
+	}
+	if len(lns) > 0 {
+		return lns[0], nil
+	}
+	return net.Listen(network, addr)
+}
+
+// Notify NOTIFY_SOCKET 으로 sd_notify 상태를 보내 - 소켓이 없으면 (systemd 밖이면) false, nil
+func Notify(state string) (bool, error) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return false, nil
+	}
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:] // 리눅스 추상 소켓
+	}
+	if state == Reloading {
+		state += monotonicUsec() // Type=notify-reload 는 MONOTONIC_USEC 도 같이 와야 받아
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// OnReload ctx 가 끝날 때까지 SIGHUP 을 받을 때마다 reload 를 불러 - 앞뒤로 RELOADING/READY 를 알려서 systemctl reload 가 끝을 기다릴 수 있어
+// (SIGHUP 이 없는 OS 에서는 아무것도 안 해)
+func OnReload(ctx context.Context, reload func(), logger *slog.Logger) {
+	if len(reloadSignals) == 0 {
+		return
+	}
+	if logger == nil {
+		logger = logging.For("daemon")
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, reloadSignals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				logger.Info("설정 다시 읽기")
+				Notify(Reloading)
+				reload()
+				Notify(Ready)
+			}
+		}
+	}()
+}
+
+// ErrRunning PID 파일의 프로세스가 아직 살아 있어
+var ErrRunning = msg.New("이미 실행 중입니다")
+
+// WritePIDFile path 에 지금 PID 를 적어 - 살아 있는 프로세스의 PID 파일이 있으면 ErrRunning, 죽은 프로세스가 남긴 거면 덮어써
+// 돌려준 remove 는 끝날 때 불러 (그 사이에 다른 프로세스가 덮어썼으면 안 지워)
+func WritePIDFile(path string) (remove func(), err error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid != os.Getpid() && alive(pid) {
+			return nil, msg.Errorf("%w (PID %d, %s)", ErrRunning, pid, path)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// 임시 파일 → rename 이라 읽는 쪽(systemd PIDFile=, 스크립트)이 반쯤 쓴 파일을 보지 않아
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	pid := strconv.Itoa(os.Getpid())
+	_, err = tmp.WriteString(pid + "\n")
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, msg.Errorf("PID 파일 쓰기 실패: %w", err)
+	}
+	return func() {
+		if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) == pid {
+			os.Remove(path)
+		}
+	}, nil
+}
@@ -0,0 +1,174 @@
+//go:build unix
+
+package daemon
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if ok, err := Notify(Ready); ok || err != nil {
+		t.Fatalf("소켓 없이 Notify = %v, %v", ok, err)
+	}
+
+	sock := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", sock)
+
+	read := func() string {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 256)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+	if ok, err := Notify(Ready); !ok || err != nil {
+		t.Fatalf("Notify = %v, %v", ok, err)
+	}
+	if got := read(); got != Ready {
+		t.Errorf("받은 상태 = %q", got)
+	}
+	Notify(Reloading)
+	if got := read(); !strings.HasPrefix(got, Reloading) {
+		t.Errorf("받은 상태 = %q", got)
+	}
+}
+
+func TestListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// systemd 대신 소켓을 복제해 두고 그 번호를 첫 번호로 (listenFDs 가 닫으니까 os.File 이 아닌 번호만)
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := syscall.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := listenFDsStart
+	listenFDsStart = fd
+	t.Cleanup(func() { listenFDsStart = old })
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "http")
+
+	lns, err := listenFDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lns) != 1 {
+		t.Fatalf("리스너 %d개", len(lns))
+	}
+	defer lns[0].Close()
+	if lns[0].Addr().String() != ln.Addr().String() {
+		t.Errorf("주소 = %s, want %s", lns[0].Addr(), ln.Addr())
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("LISTEN_FDS 가 지워지지 않음")
+	}
+
+	// 물려받은 리스너로 실제로 받아지는지
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		c, err := lns[0].Accept()
+		if err == nil {
+			c.Write([]byte("ok"))
+			c.Close()
+		}
+	})
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	got, _ := io.ReadAll(c)
+	wg.Wait()
+	if string(got) != "ok" {
+		t.Errorf("받은 내용 = %q", got)
+	}
+}
+
+func TestListenersOtherPID(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	if lns, err := listenFDs(); lns != nil || err != nil {
+		t.Errorf("다른 프로세스 몫인데 %v, %v", lns, err)
+	}
+}
+
+func TestWritePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "serve.pid")
+	remove, err := WritePIDFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("PID 파일 = %q", data)
+	}
+	remove()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("remove 후에도 남음 (err=%v)", err)
+	}
+
+	// 살아 있는 다른 프로세스(부모)의 PID 면 거절, 죽은 프로세스 PID 면 덮어써
+	os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())+"\n"), 0644)
+	if _, err := WritePIDFile(path); !errors.Is(err, ErrRunning) {
+		t.Errorf("살아 있는 PID 인데 err = %v", err)
+	}
+	dead := deadPID(t)
+	os.WriteFile(path, []byte(strconv.Itoa(dead)+"\n"), 0644)
+	remove, err = WritePIDFile(path)
+	if err != nil {
+		t.Fatalf("죽은 PID(%d) 인데 %v", dead, err)
+	}
+	defer remove()
+}
+
+// deadPID 끝난 자식 프로세스의 PID (재사용되기 전이라 죽은 PID 로 쓸 수 있어)
+func deadPID(t *testing.T) int {
+	t.Helper()
+	p, err := os.StartProcess("/bin/sh", []string{"sh", "-c", "exit 0"}, &os.ProcAttr{})
+	if err != nil {
+		t.Skip(err)
+	}
+	p.Wait()
+	if alive(p.Pid) {
+		t.Skip("PID 가 벌써 재사용됨")
+	}
+	return p.Pid
+}
+
+func TestOnReload(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	OnReload(t.Context(), func() { reloaded <- struct{}{} }, slog.New(slog.DiscardHandler))
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP 을 보냈는데 reload 가 안 불림")
+	}
+}
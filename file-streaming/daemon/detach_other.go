@@ -0,0 +1,14 @@
+//go:build !unix
+
+package daemon
+
+import (
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Detach 이 OS 에서는 새 세션으로 떼어낼 수가 없어 - 서비스 관리자로 띄워
+func Detach(logFile string, timeout time.Duration) (parent bool, err error) {
+	return true, msg.New("이 OS 에서는 백그라운드 실행(-daemon)을 지원하지 않습니다 - 서비스 관리자로 띄우세요")
+}
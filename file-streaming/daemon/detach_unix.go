@@ -0,0 +1,84 @@
+//go:build unix
+
+package daemon
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// detachedEnv Detach 가 다시 실행한 자식이라는 표시
+const detachedEnv = "FS_DETACHED"
+
+// Detach 지금 명령을 새 세션에서 백그라운드로 다시 실행하고, 자식이 Notify(Ready) 를 보낼 때까지 기다려
+// 부모면 parent 가 true (err 가 없으면 그냥 끝내면 돼), Detach 로 떠 있는 자식이면 false 라서 하던 대로 서버를 띄우면 돼
+// ⭐ Go 는 런타임 스레드 때문에 fork 만 할 수가 없어서 자기 자신을 다시 exec 해. 준비 신호는 sd_notify 와 같은 길(NOTIFY_SOCKET)로 받아
+func Detach(logFile string, timeout time.Duration) (parent bool, err error) {
+	if os.Getenv(detachedEnv) != "" {
+		os.Unsetenv(detachedEnv)
+		return false, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return true, err
+	}
+	dir, err := os.MkdirTemp("", "fs-daemon-*")
+	if err != nil {
+		return true, err
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		return true, err
+	}
+	defer conn.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), detachedEnv+"=1", "NOTIFY_SOCKET="+sock)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true} // 터미널이 닫혀도 SIGHUP 을 안 받게 새 세션으로
+	if logFile != "" {
+		out, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return true, err
+		}
+		defer out.Close()
+		cmd.Stdout, cmd.Stderr = out, out
+	}
+	if err := cmd.Start(); err != nil {
+		return true, err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	ready := make(chan struct{})
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if bytes.Contains(append([]byte("\n"), buf[:n]...), []byte("\n"+Ready)) {
+				close(ready)
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-ready:
+		return true, nil
+	case err := <-exited:
+		return true, msg.Errorf("백그라운드 프로세스가 준비되기 전에 끝났습니다: %v", err)
+	case <-time.After(timeout):
+		return true, msg.Errorf("백그라운드 프로세스(PID %d)의 준비 신호를 %s 안에 못 받았습니다", cmd.Process.Pid, timeout)
+	}
+}
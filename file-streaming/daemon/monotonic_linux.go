@@ -0,0 +1,16 @@
+package daemon
+
+import (
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// monotonicUsec RELOADING=1 에 붙일 "\nMONOTONIC_USEC=..." (CLOCK_MONOTONIC 기준 - time.Now 의 단조 시계는 꺼낼 수가 없어)
+func monotonicUsec() string {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return ""
+	}
+	return "\nMONOTONIC_USEC=" + strconv.FormatInt(ts.Nano()/1000, 10)
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package daemon
+
+// monotonicUsec systemd 는 리눅스에만 있어서 붙일 게 없어
+func monotonicUsec() string { return "" }
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package daemon
+
+import "os"
+
+// reloadSignals SIGHUP 이 없어서 비워 둬 - 다시 읽으려면 재시작해
+var reloadSignals []os.Signal
+
+// alive 프로세스 핸들을 열 수 있으면 살아 있는 걸로
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package daemon
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// reloadSignals 설정 다시 읽기를 부르는 시그널 (systemctl reload 의 ExecReload=kill -HUP $MAINPID 도 이거)
+var reloadSignals = []os.Signal{syscall.SIGHUP}
+
+// alive pid 프로세스가 살아 있는지 - 시그널 0 은 보내지 않고 확인만 해 (EPERM 은 남의 프로세스라 살아 있는 거)
+func alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,223 @@
+package delta
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Options Push/Pull 옵션
+type Options struct {
+	Name      string         // 서버 쪽 파일 이름 (Push 기본: 로컬 파일 이름)
+	BlockSize int            // Pull 에서 내 옛 파일을 나눌 블록 크기 (0 이면 DefaultBlockSize) - Push 는 서버가 정해
+	Hooks     streamio.Hooks // 진행률은 새 파일 기준 위치 (Push 는 읽은 바이트, Pull 은 쓴 바이트)
+}
+
+func (o Options) hooks() streamio.Hooks {
+	if o.Hooks == nil {
+		return streamio.NopHooks{}
+	}
+	return o.Hooks
+}
+
+// extraDialOptions dial 에 덧붙는 옵션 - 테스트가 연결을 메모리 안의 bufconn 으로 바꿔 끼울 때 써
+var extraDialOptions []grpc.DialOption
+
+// dial 평문 gRPC 연결 (transfer 의 TCP 모드처럼 신뢰하는 네트워크용)
+func dial(addr string) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	}
+	return grpc.NewClient(addr, append(opts, extraDialOptions...)...)
+}
+
+func openStream(ctx context.Context, conn *grpc.ClientConn, i int) (grpc.ClientStream, error) {
+	desc := &serviceDesc.Streams[i]
+	return conn.NewStream(ctx, desc, "/"+serviceName+"/"+desc.StreamName)
+}
+
+// remoteError 서버가 돌려준 gRPC 상태를 읽기 좋게
+func remoteError(err error) error {
+	if st, ok := status.FromError(err); ok && err != nil {
+		return msg.Errorf("서버 오류 (%s): %s", st.Code(), st.Message())
+	}
+	return err
+}
+
+// Push local 을 서버로 - 서버에 같은 이름의 옛 파일이 있으면 바뀐 부분만 가
+func Push(ctx context.Context, addr, local string, opts Options) (*Result, error) {
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(local)
+	}
+	file, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	st, err := openStream(ctx, conn, 0)
+	if err != nil {
+		return nil, remoteError(err)
+	}
+
+	hooks := opts.hooks()
+	info := streamio.TransferInfo{ID: name, Src: local, Dst: addr, Size: fi.Size()}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	res, err := push(ctx, st, file, name, fi.Size(), func(n int64) { hooks.OnProgress(info, n) })
+	if err != nil {
+		err = remoteError(err)
+		hooks.OnError(info, err)
+		return nil, err
+	}
+	hooks.OnComplete(info, res.Literal, time.Since(start))
+	return res, nil
+}
+
+func push(ctx context.Context, st grpc.ClientStream, r io.Reader, name string, size int64, onProgress func(int64)) (*Result, error) {
+	if err := st.SendMsg(&Message{Header: &Header{Name: name, Size: size}}); err != nil {
+		return nil, recvError(st, err)
+	}
+	sig, err := recvSignature(st, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sig.BlockSize <= 0 || sig.BlockSize > maxBlockSize {
+		return nil, msg.Errorf("서버가 보낸 블록 크기가 이상해: %d", sig.BlockSize)
+	}
+	if _, _, err := sendDelta(ctx, st, sig, r, nil, onProgress); err != nil {
+		return nil, recvError(st, err)
+	}
+	if err := st.CloseSend(); err != nil {
+		return nil, err
+	}
+	m, err := recv(st)
+	if err != nil {
+		return nil, err
+	}
+	if m.Result == nil {
+		return nil, msg.New("서버가 결과 대신 다른 메시지를 보냈어")
+	}
+	return m.Result, nil
+}
+
+// recvError 보내다 실패했으면 (서버가 스트림을 끝냄) 진짜 이유는 RecvMsg 로 읽어야 나와
+func recvError(st grpc.ClientStream, err error) error {
+	if errors.Is(err, io.EOF) {
+		if _, recvErr := recv(st); recvErr != nil {
+			return recvErr
+		}
+	}
+	return err
+}
+
+// Pull 서버의 name 을 local 로 - local 에 옛 버전이 있으면 바뀐 부분만 받아
+// ⭐ 새 파일은 local 옆 임시 파일에 만들고 해시를 확인한 뒤에 rename 해서, 실패해도 옛 파일은 그대로야.
+func Pull(ctx context.Context, addr, name, local string, opts Options) (res *Result, err error) {
+	basis, basisSize, err := openBasis(local)
+	if err != nil {
+		return nil, err
+	}
+	if basis != nil {
+		defer basis.Close()
+	}
+	bs := opts.BlockSize
+	if bs <= 0 {
+		bs = DefaultBlockSize(basisSize)
+	}
+	sig := &Signature{BlockSize: bs}
+	if basis != nil {
+		if sig, err = ComputeSignature(ctx, io.NewSectionReader(basis, 0, basisSize), bs); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	st, err := openStream(ctx, conn, 1)
+	if err != nil {
+		return nil, remoteError(err)
+	}
+
+	if err := st.SendMsg(&Message{Header: &Header{Name: name}}); err != nil {
+		return nil, remoteError(recvError(st, err))
+	}
+	if err := sendSignature(st, sig); err != nil {
+		return nil, remoteError(recvError(st, err))
+	}
+	if err := st.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	m, err := recv(st)
+	if err != nil {
+		return nil, remoteError(err)
+	}
+	if m.Header == nil {
+		return nil, msg.New("서버가 파일 정보 대신 다른 메시지를 보냈어")
+	}
+
+	hooks := opts.hooks()
+	info := streamio.TransferInfo{ID: name, Src: addr, Dst: local, Size: m.Header.Size}
+	hooks.OnStart(info)
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			hooks.OnError(info, err)
+		}
+	}()
+
+	out, err := newOutput(local)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			out.abort()
+		}
+	}()
+
+	p := &Patcher{BasisSize: basisSize, BlockSize: bs, Out: out}
+	if basis != nil {
+		p.Basis = basis
+	}
+	res = &Result{Name: name, BlockSize: bs}
+	done, err := applyStream(st, p, &res.Stats, func(n int64) { hooks.OnProgress(info, n) })
+	if err != nil {
+		return nil, remoteError(err)
+	}
+	if res.SHA256, err = out.commit(done.SHA256); err != nil {
+		return nil, err
+	}
+	hooks.OnComplete(info, res.Literal, time.Since(start))
+	return res, nil
+}
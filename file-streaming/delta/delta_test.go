@@ -0,0 +1,126 @@
+package delta
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// versions 옛 파일과, 앞쪽에 몇 바이트 끼워 넣고 중간을 고친 새 파일 (블록 경계가 다 어긋나)
+func versions() (before, after []byte) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	before = make([]byte, 300<<10)
+	for i := range before {
+		before[i] = byte(rng.Uint32())
+	}
+	after = append([]byte("inserted!"), before...)
+	copy(after[150<<10:], "changed in the middle")
+	return before, after
+}
+
+// 서명 → 델타 → 적용만으로 새 파일이 그대로 나오고, 보내는 건 바뀐 부분뿐이야
+func TestSignatureDiffPatch(t *testing.T) {
+	before, after := versions()
+	sig, err := ComputeSignature(t.Context(), bytes.NewReader(before), 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	p := &Patcher{Basis: bytes.NewReader(before), BasisSize: int64(len(before)), BlockSize: sig.BlockSize, Out: &out}
+	stats, err := Diff(t.Context(), sig, bytes.NewReader(after), p.Apply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), after) {
+		t.Fatal("적용 결과가 새 파일과 다름")
+	}
+	if stats.Size != int64(len(after)) || stats.Matched+stats.Literal != stats.Size || stats.Literal > 3*4096 {
+		t.Errorf("Stats = %+v, 바뀐 블록만 보내야 함", stats)
+	}
+
+	// 옛 파일이 없으면 전부 데이터
+	out.Reset()
+	p = &Patcher{BlockSize: 4096, Out: &out}
+	if stats, err = Diff(t.Context(), &Signature{BlockSize: 4096}, bytes.NewReader(after), p.Apply); err != nil || stats.Literal != int64(len(after)) {
+		t.Errorf("빈 서명 = %+v, %v", stats, err)
+	}
+	if err := p.Apply(Op{Kind: OpCopy, Index: 0, Count: 1}); err == nil {
+		t.Error("옛 파일 없이 복사 지시인데 에러가 없음")
+	}
+}
+
+// withBufconn 메모리 안의 리스너로 Server 를 띄우고 Push/Pull 이 그리로 가게 해
+func withBufconn(t *testing.T, s *Server) string {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, lis) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+
+	prev := extraDialOptions
+	extraDialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})}
+	t.Cleanup(func() { extraDialOptions = prev })
+	return "passthrough:///bufnet"
+}
+
+func TestPushPull(t *testing.T) {
+	s := &Server{Dir: t.TempDir()}
+	addr := withBufconn(t, s)
+	before, after := versions()
+	local := filepath.Join(t.TempDir(), "data.bin")
+
+	// 처음엔 서버에 아무것도 없으니 전부 보내
+	os.WriteFile(local, before, 0644)
+	res, err := Push(t.Context(), addr, local, Options{})
+	if err != nil || res.Literal != int64(len(before)) {
+		t.Fatalf("첫 Push = %+v, %v", res, err)
+	}
+
+	// 새 버전은 바뀐 부분만
+	os.WriteFile(local, after, 0644)
+	res, err = Push(t.Context(), addr, local, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Literal >= int64(len(after))/10 || res.Size != int64(len(after)) {
+		t.Errorf("두 번째 Push = %+v, 바뀐 부분만 보내야 함", res)
+	}
+	if got, _ := os.ReadFile(filepath.Join(s.Dir, "data.bin")); !bytes.Equal(got, after) {
+		t.Fatal("서버 파일이 새 버전과 다름")
+	}
+
+	// 옛 버전을 가진 쪽이 Pull 하면 새 버전이 돼
+	stale := filepath.Join(t.TempDir(), "stale.bin")
+	os.WriteFile(stale, before, 0644)
+	res, err = Pull(t.Context(), addr, "data.bin", stale, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(stale); !bytes.Equal(got, after) {
+		t.Error("Pull 결과가 새 버전과 다름")
+	}
+	if res.Literal >= int64(len(after))/10 {
+		t.Errorf("Pull = %+v, 바뀐 부분만 받아야 함", res)
+	}
+
+	// 디렉토리 밖 이름은 거절
+	if _, err := Pull(t.Context(), addr, "../data.bin", stale, Options{}); err == nil {
+		t.Error("잘못된 이름인데 에러가 없음")
+	}
+}
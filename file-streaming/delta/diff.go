@@ -0,0 +1,179 @@
+package delta
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// OpKind 재구성 지시 종류
+type OpKind uint8
+
+const (
+	OpCopy OpKind = iota + 1 // 옛 파일의 Index 번 블록부터 Count 개를 그대로
+	OpData                   // Data 를 그대로 (옛 파일에 없는 부분)
+)
+
+// Op 받는 쪽이 새 파일을 만들 때 따르는 지시 하나
+type Op struct {
+	Kind  OpKind
+	Index int
+	Count int
+	Data  []byte
+}
+
+// MaxLiteral 데이터 지시 하나에 담는 최대 바이트 - 바뀐 부분이 길어도 메모리를 이만큼만 써
+const MaxLiteral = 64 * 1024
+
+// Stats 델타 계산 결과
+type Stats struct {
+	Size    int64 `json:"size"`    // 새 파일 크기
+	Matched int64 `json:"matched"` // 옛 파일에서 복사해 쓸 바이트
+	Literal int64 `json:"literal"` // 실제로 보내야 하는 바이트
+}
+
+// Diff sig(받는 쪽의 옛 파일 서명)와 비교해서 r(새 파일)을 만드는 지시들을 emit 으로 흘려보내
+// ⭐ 창을 한 바이트씩 굴리면서 약한 해시로 후보를 찾고, 강한 해시로 확인되면 그 블록만큼 건너뛰어.
+// 데이터가 중간에 끼어들거나 빠져서 블록 경계가 어긋나도 다시 맞춰져 - 이게 고정 블록 비교와 다른 점이야.
+func Diff(ctx context.Context, sig *Signature, r io.Reader, emit func(Op) error) (Stats, error) {
+	bs := sig.BlockSize
+	if bs <= 0 {
+		return Stats{}, msg.Errorf("잘못된 블록 크기: %d", bs)
+	}
+	d := &differ{idx: newIndex(sig), emit: emit, next: -1}
+
+	readSize := max(bs, 256*1024)
+	buf := make([]byte, 0, MaxLiteral+bs+readSize)
+	pos, lit := 0, 0 // 창 시작, 아직 안 보낸 데이터 시작 (lit <= pos)
+	eof := false
+	var rs rollsum
+	hashed := false
+
+	for {
+		// 창 하나 + 굴릴 다음 바이트 하나를 확보
+		for !eof && len(buf)-pos < bs+1 {
+			if lit > 0 {
+				n := copy(buf, buf[lit:])
+				buf = buf[:n]
+				pos -= lit
+				lit = 0
+			}
+			if cap(buf)-len(buf) < readSize {
+				grown := make([]byte, len(buf), len(buf)+readSize+bs)
+				copy(grown, buf)
+				buf = grown
+			}
+			n, err := r.Read(buf[len(buf) : len(buf)+readSize])
+			buf = buf[:len(buf)+n]
+			d.stats.Size += int64(n)
+			if errors.Is(err, io.EOF) {
+				eof = true
+			} else if err != nil {
+				return d.stats, err
+			}
+			if err := ctx.Err(); err != nil {
+				return d.stats, err
+			}
+		}
+
+		avail := len(buf) - pos
+		if avail < bs {
+			break
+		}
+		window := buf[pos : pos+bs]
+		if !hashed {
+			rs = newRollsum(window)
+			hashed = true
+		}
+		if i, ok := d.idx.find(rs.sum(), window, d.next); ok {
+			if err := d.literal(buf[lit:pos]); err != nil {
+				return d.stats, err
+			}
+			if err := d.copyBlock(i, bs); err != nil {
+				return d.stats, err
+			}
+			pos += bs
+			lit = pos
+			hashed = false
+			continue
+		}
+		if avail == bs {
+			break // 파일 끝 - 더 굴릴 바이트가 없어
+		}
+
+		rs.roll(buf[pos], buf[pos+bs])
+		pos++
+		if pos-lit >= MaxLiteral {
+			if err := d.literal(buf[lit:pos]); err != nil {
+				return d.stats, err
+			}
+			lit = pos
+		}
+	}
+
+	// 남은 꼬리 - 옛 파일의 (짧은) 마지막 블록과 똑같으면 그것도 복사
+	tail := buf[pos:]
+	if last := len(sig.Blocks) - 1; last >= 0 && len(tail) > 0 && len(tail) < bs {
+		if i, ok := d.idx.find(newRollsum(tail).sum(), tail, last); ok && i == last {
+			if err := d.literal(buf[lit:pos]); err != nil {
+				return d.stats, err
+			}
+			if err := d.copyBlock(i, len(tail)); err != nil {
+				return d.stats, err
+			}
+			lit = len(buf)
+		}
+	}
+	if err := d.literal(buf[lit:]); err != nil {
+		return d.stats, err
+	}
+	return d.stats, d.flushCopy()
+}
+
+// differ 지시를 모아서 내보내는 상태 - 이어지는 블록 복사는 하나로 합쳐
+type differ struct {
+	idx     *index
+	emit    func(Op) error
+	pending *Op // 아직 안 보낸 복사 지시
+	next    int // pending 다음에 오면 합칠 수 있는 블록 번호
+	stats   Stats
+}
+
+func (d *differ) copyBlock(i, n int) error {
+	d.stats.Matched += int64(n)
+	if d.pending != nil && i == d.next {
+		d.pending.Count++
+		d.next++
+		return nil
+	}
+	if err := d.flushCopy(); err != nil {
+		return err
+	}
+	d.pending = &Op{Kind: OpCopy, Index: i, Count: 1}
+	d.next = i + 1
+	return nil
+}
+
+func (d *differ) flushCopy() error {
+	if d.pending == nil {
+		return nil
+	}
+	op := *d.pending
+	d.pending = nil
+	return d.emit(op)
+}
+
+// literal 데이터 지시 - 버퍼는 곧 재사용되니까 복사해서 보내
+func (d *differ) literal(p []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+	if err := d.flushCopy(); err != nil {
+		return err
+	}
+	d.next = -1
+	d.stats.Literal += int64(len(p))
+	return d.emit(Op{Kind: OpData, Data: append([]byte(nil), p...)})
+}
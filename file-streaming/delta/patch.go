@@ -0,0 +1,49 @@
+package delta
+
+import (
+	"io"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Patcher 옛 파일(basis) + 지시들 → 새 파일
+// basis 가 없으면(처음 받는 파일) nil 로 두면 돼 - 그럼 복사 지시가 오면 에러야.
+type Patcher struct {
+	Basis     io.ReaderAt
+	BasisSize int64
+	BlockSize int
+	Out       io.Writer
+
+	written int64
+	buf     []byte
+}
+
+// Apply 지시 하나를 Out 에 반영
+func (p *Patcher) Apply(op Op) error {
+	switch op.Kind {
+	case OpData:
+		n, err := p.Out.Write(op.Data)
+		p.written += int64(n)
+		return err
+
+	case OpCopy:
+		off := int64(op.Index) * int64(p.BlockSize)
+		length := int64(op.Count) * int64(p.BlockSize)
+		if op.Index < 0 || op.Count <= 0 || p.Basis == nil || off >= p.BasisSize {
+			return msg.Errorf("잘못된 복사 지시: 블록 %d부터 %d개 (옛 파일 %d 바이트)", op.Index, op.Count, p.BasisSize)
+		}
+		length = min(length, p.BasisSize-off) // 마지막 블록은 짧아
+		if p.buf == nil {
+			p.buf = make([]byte, 64*1024)
+		}
+		n, err := io.CopyBuffer(p.Out, io.NewSectionReader(p.Basis, off, length), p.buf)
+		p.written += n
+		return err
+	}
+	return msg.Errorf("알 수 없는 지시: %d", op.Kind)
+}
+
+// Written 지금까지 쓴 바이트
+func (p *Patcher) Written() int64 {
+	return p.written
+}
@@ -0,0 +1,34 @@
+package delta
+
+// rollsum rsync 의 약한 체크섬 (Adler-32 변형)
+// ⭐ 창을 한 바이트 밀 때 빠지는 바이트와 들어오는 바이트만으로 O(1) 에 갱신돼.
+// 그래서 새 파일의 모든 위치에서 "여기서 시작하는 블록이 옛 파일에 있나?" 를 싸게 물어볼 수 있어.
+//
+//	a = Σ x_i            (mod 2^16)
+//	b = Σ (len - i)·x_i  (mod 2^16)
+type rollsum struct {
+	a, b uint32
+	n    uint32 // 창 길이
+}
+
+func newRollsum(p []byte) rollsum {
+	var r rollsum
+	r.n = uint32(len(p))
+	for i, c := range p {
+		r.a += uint32(c)
+		r.b += (r.n - uint32(i)) * uint32(c)
+	}
+	r.a &= 0xffff
+	r.b &= 0xffff
+	return r
+}
+
+// roll out 을 빼고 in 을 넣어서 창을 한 칸 이동
+func (r *rollsum) roll(out, in byte) {
+	r.a = (r.a - uint32(out) + uint32(in)) & 0xffff
+	r.b = (r.b - r.n*uint32(out) + r.a) & 0xffff
+}
+
+func (r rollsum) sum() uint32 {
+	return r.a | r.b<<16
+}
@@ -0,0 +1,334 @@
+package delta
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// maxBlockSize 상대가 보낸 서명의 블록 크기 상한 (터무니없는 값으로 메모리를 잡아먹지 않게)
+const maxBlockSize = 16 << 20
+
+// Server Dir 안의 파일을 델타로 받고(Push) 내보내는(Pull) gRPC 서비스
+// 같은 이름의 파일이 이미 있으면 그게 옛 파일(basis)이 돼.
+type Server struct {
+	Dir   string
+	Hooks streamio.Hooks // 파일마다 시작/진행/완료 (nil 이면 호출 안 함)
+}
+
+func (s *Server) hooks() streamio.Hooks {
+	if s.Hooks == nil {
+		return streamio.NopHooks{}
+	}
+	return s.Hooks
+}
+
+// Register 다른 서비스와 같은 gRPC 서버에 올릴 때
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	r.RegisterService(&serviceDesc, s)
+}
+
+// ListenAndServe addr 에서 gRPC 서버 시작 (ctx 가 취소되면 진행 중인 스트림을 마치고 종료)
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+	stop := context.AfterFunc(ctx, gs.GracefulStop)
+	defer stop()
+
+	if err := gs.Serve(ln); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// path 클라이언트가 준 이름을 Dir 안의 경로로 (디렉토리 밖으로 못 나가게 파일 이름만 받아)
+func (s *Server) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return "", status.Errorf(codes.InvalidArgument, msg.T("잘못된 파일 이름: %q"), name)
+	}
+	return filepath.Join(s.Dir, name), nil
+}
+
+func errUnexpected(want string) error {
+	return status.Errorf(codes.InvalidArgument, msg.T("예상하지 못한 메시지 (기다린 것: %s)"), want)
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// push 클라이언트가 보내는 새 파일을 옛 파일 + 지시로 재구성
+func (s *Server) push(st grpc.ServerStream) error {
+	ctx := st.Context()
+	m, err := recv(st)
+	if err != nil {
+		return err
+	}
+	if m.Header == nil {
+		return errUnexpected("Header")
+	}
+	final, err := s.path(m.Header.Name)
+	if err != nil {
+		return err
+	}
+
+	// 같은 파일을 동시에 두 클라이언트가 밀어 넣으면 basis 가 중간에 바뀌어
+	unlock := streamio.LockPath(final)
+	defer unlock()
+
+	hooks := s.hooks()
+	info := streamio.TransferInfo{ID: m.Header.Name, Src: peerAddr(ctx), Dst: final, Size: m.Header.Size}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	res, err := s.receive(ctx, st, final, info)
+	if err != nil {
+		hooks.OnError(info, err)
+		return err
+	}
+	hooks.OnComplete(info, res.Literal, time.Since(start))
+	return st.SendMsg(&Message{Result: res})
+}
+
+func (s *Server) receive(ctx context.Context, st grpc.ServerStream, final string, info streamio.TransferInfo) (res *Result, err error) {
+	basis, basisSize, err := openBasis(final)
+	if err != nil {
+		return nil, err
+	}
+	if basis != nil {
+		defer basis.Close()
+	}
+
+	bs := DefaultBlockSize(basisSize)
+	sig := &Signature{BlockSize: bs}
+	if basis != nil {
+		if sig, err = ComputeSignature(ctx, io.NewSectionReader(basis, 0, basisSize), bs); err != nil {
+			return nil, err
+		}
+	}
+	if err := sendSignature(st, sig); err != nil {
+		return nil, err
+	}
+
+	out, err := newOutput(final)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			out.abort()
+		}
+	}()
+
+	p := &Patcher{BasisSize: basisSize, BlockSize: bs, Out: out}
+	if basis != nil {
+		p.Basis = basis
+	}
+	res = &Result{Name: info.ID, BlockSize: bs}
+	done, err := applyStream(st, p, &res.Stats, func(written int64) { s.hooks().OnProgress(info, written) })
+	if err != nil {
+		return nil, err
+	}
+	if res.SHA256, err = out.commit(done.SHA256); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// pull 클라이언트의 옛 파일 서명을 받아서, 여기 있는 새 파일과의 차이만 보내
+func (s *Server) pull(st grpc.ServerStream) error {
+	ctx := st.Context()
+	m, err := recv(st)
+	if err != nil {
+		return err
+	}
+	if m.Header == nil {
+		return errUnexpected("Header")
+	}
+	final, err := s.path(m.Header.Name)
+	if err != nil {
+		return err
+	}
+	sig, err := recvSignature(st, nil)
+	if err != nil {
+		return err
+	}
+	if sig.BlockSize <= 0 || sig.BlockSize > maxBlockSize {
+		return status.Errorf(codes.InvalidArgument, msg.T("잘못된 블록 크기: %d"), sig.BlockSize)
+	}
+
+	unlock := streamio.LockPath(final)
+	defer unlock()
+	file, err := os.Open(final)
+	if errors.Is(err, os.ErrNotExist) {
+		return status.Errorf(codes.NotFound, msg.T("파일 없음: %s"), m.Header.Name)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	hooks := s.hooks()
+	info := streamio.TransferInfo{ID: m.Header.Name, Src: final, Dst: peerAddr(ctx), Size: fi.Size()}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	stats, _, err := sendDelta(ctx, st, sig, file, &Header{Name: m.Header.Name, Size: fi.Size()}, func(n int64) { hooks.OnProgress(info, n) })
+	if err != nil {
+		hooks.OnError(info, err)
+		return err
+	}
+	hooks.OnComplete(info, stats.Literal, time.Since(start))
+	return nil
+}
+
+// sendDelta header 를 보내고 r 과 sig 의 차이를 지시로 흘려보낸 뒤 Done 으로 마무리
+func sendDelta(ctx context.Context, s stream, sig *Signature, r io.Reader, header *Header, onProgress func(int64)) (Stats, string, error) {
+	if header != nil {
+		if err := s.SendMsg(&Message{Header: header}); err != nil {
+			return Stats{}, "", err
+		}
+	}
+	h := sha256.New()
+	src := io.TeeReader(streamio.NewProgressReader(r, 0, func(current, _ int64) { onProgress(current) }), h)
+	ops := &opSender{s: s}
+	stats, err := Diff(ctx, sig, src, ops.add)
+	if err == nil {
+		err = ops.flush()
+	}
+	if err != nil {
+		return stats, "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	return stats, sum, s.SendMsg(&Message{Done: &Done{SHA256: sum}})
+}
+
+// applyStream Done 이 올 때까지 지시를 받아 p 에 적용하면서 stats 를 채워
+func applyStream(s stream, p *Patcher, stats *Stats, onProgress func(int64)) (*Done, error) {
+	for {
+		m, err := recv(s)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case m.Done != nil:
+			stats.Size = p.Written()
+			return m.Done, nil
+		case m.Ops != nil:
+			for _, op := range m.Ops {
+				before := p.Written()
+				if err := p.Apply(op); err != nil {
+					return nil, err
+				}
+				if op.Kind == OpData {
+					stats.Literal += p.Written() - before
+				} else {
+					stats.Matched += p.Written() - before
+				}
+			}
+			onProgress(p.Written())
+		default:
+			return nil, errUnexpected(msg.T("Ops 또는 Done"))
+		}
+	}
+}
+
+// openBasis 옛 파일 열기 - 없으면 nil (처음 받는 파일이라 전부 데이터로 와)
+func openBasis(name string) (*os.File, int64, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if !fi.Mode().IsRegular() {
+		f.Close()
+		return nil, 0, msg.Errorf("일반 파일이 아닙니다: %s", name)
+	}
+	return f, fi.Size(), nil
+}
+
+// output 재구성한 새 파일 - 옛 파일 옆 임시 파일에 쓰다가 해시가 맞으면 rename
+// ⭐ 옛 파일은 재구성이 끝날 때까지 basis 로 읽어야 하니까 제자리에 덮어쓰면 안 돼
+type output struct {
+	tmp   *os.File
+	hash  hash.Hash
+	final string
+}
+
+func newOutput(final string) (*output, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(final), "."+filepath.Base(final)+".delta-*")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Chmod(0644)
+	return &output{tmp: tmp, hash: sha256.New(), final: final}, nil
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.hash.Write(p)
+	return o.tmp.Write(p)
+}
+
+// commit 해시를 확인하고 제자리로 - 틀리면 지우고 ChecksumError
+func (o *output) commit(want string) (string, error) {
+	got := hex.EncodeToString(o.hash.Sum(nil))
+	if got != want {
+		return "", &streamio.ChecksumError{Path: o.final, Expected: want, Actual: got}
+	}
+	if err := o.tmp.Sync(); err != nil {
+		return "", err
+	}
+	if err := o.tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := streamio.Rename(o.tmp.Name(), o.final); err != nil {
+		return "", err
+	}
+	return got, nil
+}
+
+func (o *output) abort() {
+	o.tmp.Close()
+	os.Remove(o.tmp.Name())
+}
@@ -0,0 +1,111 @@
+// Package delta 는 rsync 방식의 델타 동기화야.
+// 받는 쪽이 가진 옛 파일을 블록으로 나눠 서명(약한 롤링 해시 + 강한 해시)을 보내면,
+// 보내는 쪽은 새 파일을 한 바이트씩 굴려가며 같은 블록을 찾아서 "n번 블록 복사" 지시와
+// 바뀐 부분의 데이터만 보내. 큰 파일의 일부만 바뀌었을 때 전송량이 바뀐 만큼으로 줄어.
+package delta
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"math"
+)
+
+// StrongSize 강한 해시 길이 (SHA-256 앞 16바이트) - 약한 해시가 우연히 같을 때 걸러내는 용도라 이 정도면 충분해
+const StrongSize = 16
+
+// BlockSig 블록 하나의 서명
+type BlockSig struct {
+	Weak   uint32
+	Strong [StrongSize]byte
+}
+
+// Signature 옛 파일 전체의 서명
+type Signature struct {
+	BlockSize int
+	FileSize  int64 // 마지막 블록은 BlockSize 보다 짧을 수 있어
+	Blocks    []BlockSig
+}
+
+// blockLen i 번째 블록의 실제 길이
+func (s *Signature) blockLen(i int) int {
+	if rest := s.FileSize - int64(i)*int64(s.BlockSize); rest < int64(s.BlockSize) {
+		return int(rest)
+	}
+	return s.BlockSize
+}
+
+// DefaultBlockSize 파일 크기의 제곱근 근처 (1KB 단위, 2KB~128KB)
+// ⭐ 블록이 작으면 바뀐 부분만 정확히 골라내지만 서명이 커지고, 크면 그 반대야.
+// 제곱근이면 서명 크기와 바뀐 블록 크기가 둘 다 √n 수준이라 합이 가장 작아져.
+func DefaultBlockSize(size int64) int {
+	bs := int(math.Sqrt(float64(size))+1023) / 1024 * 1024
+	return min(max(bs, 2048), 128*1024)
+}
+
+// strongSum 강한 해시
+func strongSum(p []byte) [StrongSize]byte {
+	sum := sha256.Sum256(p)
+	var s [StrongSize]byte
+	copy(s[:], sum[:])
+	return s
+}
+
+// ComputeSignature r 을 blockSize 씩 읽으면서 서명 계산 (파일 전체를 메모리에 올리지 않아)
+func ComputeSignature(ctx context.Context, r io.Reader, blockSize int) (*Signature, error) {
+	sig := &Signature{BlockSize: blockSize}
+	buf := make([]byte, blockSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			sig.Blocks = append(sig.Blocks, BlockSig{Weak: newRollsum(buf[:n]).sum(), Strong: strongSum(buf[:n])})
+			sig.FileSize += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return sig, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// index 약한 해시 → 블록 번호들 (약한 해시는 충돌할 수 있어서 여러 개)
+type index struct {
+	sig    *Signature
+	blocks map[uint32][]int
+}
+
+func newIndex(sig *Signature) *index {
+	idx := &index{sig: sig, blocks: make(map[uint32][]int, len(sig.Blocks))}
+	for i, b := range sig.Blocks {
+		idx.blocks[b.Weak] = append(idx.blocks[b.Weak], i)
+	}
+	return idx
+}
+
+// find window 와 같은 블록 번호 - 바로 다음 블록(prefer)이 맞으면 그걸 골라서 복사 지시가 이어지게
+func (x *index) find(weak uint32, window []byte, prefer int) (int, bool) {
+	candidates := x.blocks[weak]
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	strong := strongSum(window)
+	found := -1
+	for _, i := range candidates {
+		if x.sig.blockLen(i) != len(window) || !bytes.Equal(x.sig.Blocks[i].Strong[:], strong[:]) {
+			continue
+		}
+		if i == prefer {
+			return i, true
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+	return found, found >= 0
+}
@@ -0,0 +1,191 @@
+package delta
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// gRPC 위의 델타 프로토콜
+//
+// ⭐ .proto + protoc 생성 코드 대신 Go 구조체를 gob 코덱으로 주고받아 (빌드에 protoc 가 필요 없게).
+// 서비스/메서드 이름과 스트림 구조는 생성 코드와 똑같아서, 나중에 protobuf 로 바꿔도 흐름은 그대로야.
+//
+//	Push (클라이언트 → 서버로 새 파일 보내기)
+//	  C: Header{Name, Size}
+//	  S: Signature... (서버가 가진 옛 파일 서명, 마지막 조각은 Last)
+//	  C: Ops... , Done{SHA256}
+//	  S: Result
+//
+//	Pull (서버의 새 파일 받기)
+//	  C: Header{Name}, Signature... (클라이언트가 가진 옛 파일 서명)
+//	  S: Header{Size}, Ops... , Done{SHA256}
+
+const (
+	serviceName = "streamio.delta.Delta"
+	codecName   = "gob"
+
+	sigChunkBlocks = 4096      // 서명 조각 하나에 담는 블록 수 (gRPC 기본 메시지 한도 4MB 보다 한참 작게)
+	opsBatchBytes  = 512 << 10 // 지시 묶음 하나의 대략적인 최대 크기
+)
+
+// Header 파일 정보
+type Header struct {
+	Name string
+	Size int64
+}
+
+// SignatureChunk 서명을 나눠 보내는 조각 - 블록 수가 많으면 메시지 하나에 다 안 들어가
+type SignatureChunk struct {
+	BlockSize int
+	FileSize  int64
+	Blocks    []BlockSig
+	Last      bool
+}
+
+// Done 지시를 다 보냈다는 표시 + 새 파일 전체 해시 (받는 쪽이 재구성 결과를 검증)
+type Done struct {
+	SHA256 string
+}
+
+// Result Push 결과
+type Result struct {
+	Name      string `json:"name"`
+	SHA256    string `json:"sha256"`
+	BlockSize int    `json:"block_size"`
+	Stats
+}
+
+// Message 스트림에 오가는 메시지 하나 - protobuf oneof 처럼 필드 하나만 채워
+type Message struct {
+	Header    *Header
+	Signature *SignatureChunk
+	Ops       []Op
+	Done      *Done
+	Result    *Result
+}
+
+// gobCodec gRPC 메시지 코덱
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) Name() string { return codecName }
+
+func init() {
+	encoding.RegisterCodec(gobCodec{})
+}
+
+// service Server 가 구현하는 핸들러 (생성 코드의 XxxServer 인터페이스 자리)
+type service interface {
+	push(stream grpc.ServerStream) error
+	pull(stream grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*service)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Push",
+			Handler:       func(srv any, stream grpc.ServerStream) error { return srv.(service).push(stream) },
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Pull",
+			Handler:       func(srv any, stream grpc.ServerStream) error { return srv.(service).pull(stream) },
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+// stream 서버/클라이언트 스트림 공통 부분
+type stream interface {
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+func recv(s stream) (*Message, error) {
+	m := new(Message)
+	if err := s.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// sendSignature 서명을 조각내서 보내기
+func sendSignature(s stream, sig *Signature) error {
+	blocks := sig.Blocks
+	for {
+		n := min(len(blocks), sigChunkBlocks)
+		chunk := &SignatureChunk{BlockSize: sig.BlockSize, FileSize: sig.FileSize, Blocks: blocks[:n], Last: n == len(blocks)}
+		if err := s.SendMsg(&Message{Signature: chunk}); err != nil {
+			return err
+		}
+		if chunk.Last {
+			return nil
+		}
+		blocks = blocks[n:]
+	}
+}
+
+// recvSignature 조각들을 모아서 서명 하나로 (first 는 이미 받은 첫 메시지, 없으면 nil)
+func recvSignature(s stream, first *Message) (*Signature, error) {
+	sig := new(Signature)
+	m := first
+	for {
+		if m == nil {
+			var err error
+			if m, err = recv(s); err != nil {
+				return nil, err
+			}
+		}
+		if m.Signature == nil {
+			return nil, errUnexpected(msg.T("서명"))
+		}
+		sig.BlockSize, sig.FileSize = m.Signature.BlockSize, m.Signature.FileSize
+		sig.Blocks = append(sig.Blocks, m.Signature.Blocks...)
+		if m.Signature.Last {
+			return sig, nil
+		}
+		m = nil
+	}
+}
+
+// opSender 지시를 묶어서 보내기 (작은 복사 지시마다 메시지 하나면 오버헤드가 커)
+type opSender struct {
+	s     stream
+	batch []Op
+	size  int
+}
+
+func (o *opSender) add(op Op) error {
+	o.batch = append(o.batch, op)
+	o.size += len(op.Data) + 16
+	if o.size >= opsBatchBytes {
+		return o.flush()
+	}
+	return nil
+}
+
+func (o *opSender) flush() error {
+	if len(o.batch) == 0 {
+		return nil
+	}
+	err := o.s.SendMsg(&Message{Ops: o.batch})
+	o.batch, o.size = nil, 0
+	return err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// rsync 스타일 디렉토리 동기화 도구
+//
+//	go run ./dirsync [-delete] [-dry-run] [-checksum] <원본 디렉토리> <대상 디렉토리>
+func main() {
+	// 플래그 설명과 출력도 FS_LANG / -lang 을 따라가게 (플래그를 등록하기 전에)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	locale.RegisterFlags(flag.CommandLine)
+	deleteExtra := flag.Bool("delete", false, msg.T("원본에 없는 파일을 대상에서 삭제"))
+	trashDir := flag.String("trash", "", msg.T("-delete 때 영구 삭제 대신 옮길 휴지통 디렉토리 (go run ./trash 로 복원)"))
+	dryRun := flag.Bool("dry-run", false, msg.T("실제로 바꾸지 않고 할 일만 출력"))
+	checksum := flag.Bool("checksum", false, msg.T("크기+수정시각 대신 내용 해시로 비교"))
+	include := flag.String("include", "", msg.T("포함할 glob (쉼표 구분)"))
+	exclude := flag.String("exclude", "", msg.T("제외할 glob (쉼표 구분)"))
+	sparse := flag.Bool("sparse", false, msg.T("sparse 파일의 구멍을 대상에서도 유지"))
+	hardLinks := flag.Bool("hardlinks", false, msg.T("하드링크를 대상에서도 하드링크로 유지"))
+	var symlinks fstree.SymlinkPolicy
+	flag.Var(&symlinks, "symlinks", msg.T("심볼릭 링크 처리 (skip|follow|copy)"))
+	quiet := flag.Bool("quiet", false, msg.T("파일별 출력 생략"))
+	flag.Parse()
+	logging.SetupFromEnv()
+
+	if flag.NArg() < 2 {
+		fmt.Println(msg.T("사용법: go run ./dirsync [-delete] [-dry-run] [-checksum] <원본> <대상>"))
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := fstree.SyncOptions{
+		Checksum:  *checksum,
+		Delete:    *deleteExtra,
+		DryRun:    *dryRun,
+		Sparse:    *sparse,
+		HardLinks: *hardLinks,
+	}
+	opts.Walk.Symlinks = symlinks
+	if *trashDir != "" {
+		trash, err := fstree.OpenTrash(*trashDir)
+		if err != nil {
+			logging.Fatal("휴지통 열기 실패", "err", err)
+		}
+		opts.Trash = trash
+	}
+	if *include != "" {
+		opts.Walk.Include = strings.Split(*include, ",")
+	}
+	if *exclude != "" {
+		opts.Walk.Exclude = strings.Split(*exclude, ",")
+	}
+	if !*quiet {
+		opts.OnAction = func(a fstree.SyncAction) {
+			if a.Err != nil {
+				slog.Warn("동기화 실패", "kind", a.Kind, "file", a.RelPath, "err", a.Err)
+				return
+			}
+			msg.Printf("%-7s %s (%d 바이트)\n", a.Kind, a.RelPath, a.Size)
+		}
+	}
+
+	report, err := fstree.Sync(ctx, flag.Arg(0), flag.Arg(1), opts)
+	fmt.Println(report)
+	if err != nil {
+		logging.Fatal("동기화 중단", "err", err)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 디스크 사용량 분석 도구 (du 비슷)
+// 디렉토리 집계가 끝나는 대로 바로 출력하고, 마지막에 가장 큰 파일/디렉토리 순위를 보여줘.
+//
+//	go run ./du [-depth 1] [-top 10] [-json] <디렉토리>
+func main() {
+	// 플래그 설명과 출력도 FS_LANG / -lang 을 따라가게 (플래그를 등록하기 전에)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	locale.RegisterFlags(flag.CommandLine)
+	depth := flag.Int("depth", 1, msg.T("이 깊이까지의 디렉토리만 진행 중에 출력 (-1 이면 출력 안 함)"))
+	top := flag.Int("top", 10, msg.T("가장 큰 파일/디렉토리 몇 개"))
+	asJSON := flag.Bool("json", false, msg.T("줄 단위 JSON 출력 (디렉토리마다 한 줄, 마지막에 요약 한 줄)"))
+	exclude := flag.String("exclude", "", msg.T("제외할 glob (쉼표 구분)"))
+	flag.Parse()
+	logging.SetupFromEnv()
+
+	root := "."
+	if flag.NArg() > 0 {
+		root = flag.Arg(0)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	encoder := json.NewEncoder(os.Stdout)
+	opts := fstree.UsageOptions{TopN: *top}
+	if *exclude != "" {
+		opts.Walk.Exclude = strings.Split(*exclude, ",")
+	}
+	opts.OnDir = func(d fstree.DirUsage) {
+		if d.Depth > *depth {
+			return
+		}
+		if *asJSON {
+			encoder.Encode(struct {
+				Type string `json:"type"`
+				fstree.DirUsage
+			}{"dir", d})
+			return
+		}
+		msg.Printf("%10s  %6d 파일  %s\n", humanSize(d.Allocated), d.Files, d.Path)
+	}
+
+	report, err := fstree.DiskUsage(ctx, root, opts)
+
+	if *asJSON {
+		encoder.Encode(struct {
+			Type string `json:"type"`
+			fstree.UsageReport
+		}{"summary", report})
+	} else {
+		printReport(report)
+	}
+
+	if err != nil {
+		logging.Fatal("중단", "err", err)
+	}
+}
+
+func printReport(r fstree.UsageReport) {
+	msg.Printf("\n합계: 논리 %s, 실제 %s, 파일 %d개, 디렉토리 %d개 (에러 %d)\n",
+		humanSize(r.Total.Size), humanSize(r.Total.Allocated), r.Total.Files, r.Total.Dirs, r.Errors)
+
+	fmt.Println(msg.T("\n가장 큰 파일:"))
+	for i, f := range r.TopFiles {
+		fmt.Printf("%3d. %10s  %s\n", i+1, humanSize(f.Size), f.RelPath)
+	}
+	fmt.Println(msg.T("\n가장 큰 디렉토리:"))
+	for i, d := range r.TopDirs {
+		fmt.Printf("%3d. %10s  %s\n", i+1, humanSize(d.Size), d.RelPath)
+	}
+}
+
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
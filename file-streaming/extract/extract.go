@@ -0,0 +1,387 @@
+// Package extract 는 zip / tar / tar.gz 아카이브를 디스크에 푸는 패키지야.
+// streamctl extract 와 step09 서버의 /api/extract 가 이 위에 올라가.
+//
+// ⭐ 믿을 수 없는 아카이브를 푼다고 가정해:
+//   - 엔트리 경로가 대상 디렉토리 밖을 가리키면(../, 절대 경로 = zip slip) 통째로 거절하고,
+//     대상 디렉토리는 os.Root 로 열어서 이미 있던 심볼릭 링크를 타고 나가는 것도 막아
+//   - 헤더에 적힌 크기를 믿지 않고 실제로 풀린 바이트와 엔트리 수를 세서 한도를 넘으면 끊어 (zip bomb)
+//   - 권한은 0644/0755 로만 남기고 (setuid, 그룹/다른 사용자 쓰기 제거), 링크·장치 파일은 풀지 않고 건너뛰어
+//
+// 4GB 넘는 엔트리나 65535개 넘는 엔트리가 든 zip(Zip64)도 archive/zip 이 그대로 읽어 - 크기는 전부 int64 로 다뤄서
+// 기본 한도(MaxFiles, MaxFileSize, MaxTotal)만 올리면 돼. 8GB 넘는 tar 엔트리(PAX/GNU 헤더)도 마찬가지야.
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Format 아카이브 형식
+type Format string
+
+const (
+	Zip   Format = "zip"
+	Tar   Format = "tar"
+	TarGz Format = "tar.gz"
+)
+
+var (
+	// ErrUnsafePath 대상 디렉토리 밖을 가리키는 엔트리 (zip slip)
+	ErrUnsafePath = msg.New("대상 디렉토리 밖을 가리키는 경로")
+	// ErrLimit 엔트리 수나 풀린 크기가 한도를 넘음 (zip bomb)
+	ErrLimit = msg.New("아카이브 한도 초과")
+	// ErrFormat zip, tar, tar.gz 가 아니거나 깨진 아카이브
+	ErrFormat = msg.New("지원하지 않거나 깨진 아카이브 (zip, tar, tar.gz)")
+)
+
+// 한도 기본값
+const (
+	DefaultMaxFiles    = 10000
+	DefaultMaxFileSize = 1 << 30
+	DefaultMaxTotal    = 4 << 30
+)
+
+// Options 풀기 옵션 (한도를 0 으로 두면 기본값)
+type Options struct {
+	MaxFiles    int   // 엔트리 수 한도 (디렉토리, 건너뛴 링크도 세)
+	MaxFileSize int64 // 파일 하나의 풀린 크기 한도
+	MaxTotal    int64 // 풀린 전체 크기 한도 (zip 을 스트림으로 받으면 임시 파일에 받는 크기도 이 근처로 막아)
+
+	Overwrite bool // 대상에 이미 있는 파일을 덮어써 (기본은 에러)
+	Sparse    bool // 0 으로만 채워진 블록은 쓰지 않고 구멍으로 남겨 (디스크 이미지, VM 디스크 같은 큰 파일)
+
+	// Hooks 엔트리마다 OnStart/OnProgress/OnComplete (ID 는 아카이브 안의 경로, Size 는 헤더에 적힌 크기)
+	Hooks      streamio.Hooks
+	BufferSize int
+}
+
+func (o *Options) setDefaults() {
+	if o.MaxFiles <= 0 {
+		o.MaxFiles = DefaultMaxFiles
+	}
+	if o.MaxFileSize <= 0 {
+		o.MaxFileSize = DefaultMaxFileSize
+	}
+	if o.MaxTotal <= 0 {
+		o.MaxTotal = DefaultMaxTotal
+	}
+}
+
+// Result 푼 결과
+type Result struct {
+	Format  Format   `json:"format"`
+	Files   int      `json:"files"`
+	Dirs    int      `json:"dirs"`
+	Bytes   int64    `json:"bytes"`             // 풀린 전체 크기
+	Skipped []string `json:"skipped,omitempty"` // 링크, 장치 파일처럼 풀지 않은 엔트리
+}
+
+func (r Result) String() string {
+	return msg.Sprintf("%s: 파일 %d, 디렉토리 %d, %d 바이트, 건너뜀 %d", r.Format, r.Files, r.Dirs, r.Bytes, len(r.Skipped))
+}
+
+// ExtractFile 아카이브 파일을 dstDir 에 풀어
+func ExtractFile(ctx context.Context, archive, dstDir string, opts Options) (Result, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+	return Extract(ctx, f, dstDir, opts)
+}
+
+// Extract r 의 아카이브를 dstDir 에 풀어 (형식은 앞부분 매직 바이트로 알아내)
+// ⭐ tar/tar.gz 는 읽는 대로 바로 풀고, zip 은 끝에 있는 목록(central directory)이 필요해서
+// r 이 일반 파일이 아니면(stdin, HTTP 본문) 임시 파일에 한 번 받은 뒤 풀어.
+// 중간에 실패하면 그때까지 푼 파일은 남아 - 통째로 되돌리려면 빈 임시 디렉토리에 풀고 rename 해.
+func Extract(ctx context.Context, r io.Reader, dstDir string, opts Options) (res Result, err error) {
+	opts.setDefaults()
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return res, err
+	}
+	root, err := os.OpenRoot(dstDir)
+	if err != nil {
+		return res, err
+	}
+	defer root.Close()
+
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	res.Format = detect(head)
+
+	ctx, st := streamio.StartStage(ctx, "extract", attribute.String("format", string(res.Format)), attribute.String("dst", dstDir))
+	defer func() {
+		st.Add(res.Bytes)
+		st.End(err)
+	}()
+
+	x := &extractor{ctx: ctx, root: root, opts: opts, res: &res}
+	switch res.Format {
+	case Zip:
+		err = x.zip(r, br)
+	case TarGz:
+		var zr *gzip.Reader
+		if zr, err = gzip.NewReader(br); err != nil {
+			return res, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		defer zr.Close()
+		err = x.tar(zr)
+	default:
+		err = x.tar(br)
+	}
+	return res, err
+}
+
+// detect zip 은 PK 로, gzip 은 1f 8b 로 시작해 - 나머지는 tar 로 보고 읽어 봐 (옛 tar 는 ustar 표시가 없어)
+func detect(head []byte) Format {
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return Zip
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return TarGz
+	}
+	return Tar
+}
+
+type extractor struct {
+	ctx     context.Context
+	root    *os.Root
+	opts    Options
+	res     *Result
+	entries int
+}
+
+func (x *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if errors.Is(err, tar.ErrHeader) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("%w: %v", ErrFormat, err)
+			}
+			return err
+		}
+		var kind fs.FileMode
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+		case tar.TypeDir:
+			kind = fs.ModeDir
+		case tar.TypeXGlobalHeader:
+			continue // pax 전역 헤더 - 파일이 아니야
+		default:
+			kind = fs.ModeIrregular // 링크, 장치 파일, FIFO
+		}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		if err := x.entry(hdr.Name, kind|hdr.FileInfo().Mode().Perm(), hdr.Size, hdr.ModTime, open); err != nil {
+			return err
+		}
+	}
+}
+
+func (x *extractor) zip(r io.Reader, br *bufio.Reader) error {
+	ra, size, cleanup, err := x.readerAt(r, br)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	// 목록이 먼저 있으니까 풀기 전에 엔트리 수부터 확인
+	if len(zr.File) > x.opts.MaxFiles {
+		return msg.Errorf("%w: 엔트리 %d개 (한도 %d)", ErrLimit, len(zr.File), x.opts.MaxFiles)
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		kind := mode.Type()
+		if kind != 0 && kind != fs.ModeDir {
+			kind = fs.ModeIrregular
+		}
+		if strings.HasSuffix(f.Name, "/") {
+			kind = fs.ModeDir
+		}
+		size := int64(f.UncompressedSize64)
+		if size < 0 {
+			size = -1
+		}
+		if err := x.entry(f.Name, kind|mode.Perm(), size, f.Modified, f.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readerAt zip 은 임의 접근이 필요해 - 일반 파일이면 그대로, 아니면 임시 파일에 받아
+func (x *extractor) readerAt(r io.Reader, br *bufio.Reader) (io.ReaderAt, int64, func(), error) {
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			return f, info.Size(), func() {}, nil // ReadAt 은 Peek 로 옮겨진 위치와 상관없어
+		}
+	}
+	tmp, err := os.CreateTemp("", "extract-*.zip")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	// 압축 안 한(stored) 엔트리면 zip 이 풀린 크기보다 헤더만큼 커 - 엔트리당 1KB 여유를 줘
+	limit := x.opts.MaxTotal + int64(x.opts.MaxFiles)<<10
+	n, err := io.Copy(tmp, io.LimitReader(br, limit+1))
+	if err == nil && n > limit {
+		err = msg.Errorf("%w: zip 이 %d 바이트를 넘음", ErrLimit, limit)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	return tmp, n, cleanup, nil
+}
+
+// entry 엔트리 하나 - kind 는 0(일반 파일), ModeDir, ModeIrregular(풀지 않음)
+func (x *extractor) entry(name string, mode fs.FileMode, size int64, modTime time.Time, open func() (io.ReadCloser, error)) error {
+	if err := x.ctx.Err(); err != nil {
+		return err
+	}
+	x.entries++
+	if x.entries > x.opts.MaxFiles {
+		return msg.Errorf("%w: 엔트리가 %d개를 넘음", ErrLimit, x.opts.MaxFiles)
+	}
+	rel, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil // "./" 같은 최상위 디렉토리 엔트리
+	}
+
+	switch mode.Type() {
+	case fs.ModeDir:
+		if err := x.root.MkdirAll(rel, 0755); err != nil {
+			return err
+		}
+		x.res.Dirs++
+		return nil
+	case 0:
+		return x.file(name, rel, mode, size, modTime, open)
+	}
+	x.res.Skipped = append(x.res.Skipped, name)
+	return nil
+}
+
+// file 같은 디렉토리의 임시 파일에 풀고 rename - 중간에 끊겨도 반쪽 파일이 최종 이름으로 남지 않게
+func (x *extractor) file(name, rel string, mode fs.FileMode, size int64, modTime time.Time, open func() (io.ReadCloser, error)) (err error) {
+	if dir := path.Dir(rel); dir != "." {
+		if err := x.root.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if info, err := x.root.Lstat(rel); err == nil {
+		if !x.opts.Overwrite || !info.Mode().IsRegular() {
+			return msg.Errorf("%s 이 대상에 이미 있습니다: %w", rel, fs.ErrExist)
+		}
+	}
+
+	limit := min(x.opts.MaxFileSize, x.opts.MaxTotal-x.res.Bytes)
+	src, err := open()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer src.Close()
+
+	tmp := path.Join(path.Dir(rel), fmt.Sprintf(".%s.extract-%06d", path.Base(rel), rand.IntN(1e6)))
+	dst, err := x.root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, sanitizePerm(mode))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			x.root.Remove(tmp)
+		}
+	}()
+
+	info := streamio.TransferInfo{ID: name, Dst: filepath.Join(x.root.Name(), filepath.FromSlash(rel)), Size: size}
+	copyOpts := streamio.CopyOptions{BufferSize: x.opts.BufferSize, Hooks: x.opts.Hooks}
+	var out io.Writer = dst
+	var sparse *streamio.SparseWriter
+	if x.opts.Sparse {
+		sparse = streamio.NewSparseWriter(dst)
+		out = sparse
+	}
+	n, err := streamio.Copy(x.ctx, out, io.LimitReader(src, limit+1), info, copyOpts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if n > limit {
+		which := msg.T("파일 하나")
+		if limit < x.opts.MaxFileSize {
+			which = msg.T("전체")
+		}
+		return msg.Errorf("%w: %s 를 풀다가 %s 크기 한도를 넘음", ErrLimit, name, which)
+	}
+	if sparse != nil {
+		if err = sparse.Finish(); err != nil {
+			return msg.Errorf("%s: sparse 파일 크기 맞추기 실패: %w", name, err)
+		}
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		x.root.Chtimes(tmp, modTime, modTime)
+	}
+	if err = x.root.Rename(tmp, rel); err != nil {
+		return err
+	}
+	x.res.Files++
+	x.res.Bytes += n
+	return nil
+}
+
+// cleanName 아카이브 안의 경로를 대상 디렉토리 기준 상대 경로로 - 밖으로 나가면 ErrUnsafePath
+func cleanName(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/") // Windows 에서 만든 zip 은 \ 로 구분하기도 해
+	if path.IsAbs(slashed) || (len(slashed) > 1 && slashed[1] == ':') {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	rel := path.Clean(slashed)
+	if rel == "." {
+		return rel, nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return rel, nil
+}
+
+// sanitizePerm 실행 비트가 하나라도 있으면 0755, 아니면 0644 (setuid/setgid/sticky, 그룹/다른 사용자 쓰기는 버려)
+func sanitizePerm(mode fs.FileMode) fs.FileMode {
+	if mode.Perm()&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
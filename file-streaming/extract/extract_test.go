@@ -0,0 +1,229 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+type tarEntry struct {
+	name string
+	body string
+	mode int64
+	kind byte
+}
+
+func makeTarGz(t *testing.T, entries ...tarEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: e.mode, Size: int64(len(e.body)), Typeflag: e.kind}
+		if e.kind == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if e.kind == tar.TypeSymlink {
+			hdr.Linkname, hdr.Size = "/etc/passwd", 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.body))
+	}
+	tw.Close()
+	zw.Close()
+	return &buf
+}
+
+func TestExtractTarGz(t *testing.T) {
+	dst := t.TempDir()
+	archive := makeTarGz(t,
+		tarEntry{name: "dir/", kind: tar.TypeDir, mode: 0777},
+		tarEntry{name: "dir/run.sh", body: "#!/bin/sh", mode: 04777},
+		tarEntry{name: "note.txt", body: "hello", mode: 0666},
+		tarEntry{name: "link", kind: tar.TypeSymlink, mode: 0777},
+	)
+	res, err := Extract(context.Background(), archive, dst, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != TarGz || res.Files != 2 || res.Dirs != 1 || len(res.Skipped) != 1 {
+		t.Errorf("결과 = %v", res)
+	}
+	info, err := os.Stat(filepath.Join(dst, "dir", "run.sh"))
+	if err != nil || info.Mode() != 0755 {
+		t.Errorf("run.sh 권한 = %v (%v), want 0755 (setuid, 그룹 쓰기 제거)", info.Mode(), err)
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "link")); err == nil {
+		t.Error("심볼릭 링크를 풀었음")
+	}
+}
+
+func TestExtractRejectsUnsafePaths(t *testing.T) {
+	for _, name := range []string{"../evil", "a/../../evil", "/etc/evil", `..\evil`, "C:/evil"} {
+		dst := t.TempDir()
+		_, err := Extract(context.Background(), makeTarGz(t, tarEntry{name: name, body: "x"}), dst, Options{})
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("%q: err = %v, want ErrUnsafePath", name, err)
+		}
+	}
+
+	// 대상 안에 이미 있던 심볼릭 링크를 타고 나가는 것도 막아
+	dst, outside := t.TempDir(), t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dst, "sub")); err != nil {
+		t.Skip("심볼릭 링크를 만들 수 없음:", err)
+	}
+	_, err := Extract(context.Background(), makeTarGz(t, tarEntry{name: "sub/evil", body: "x"}), dst, Options{})
+	if err == nil {
+		t.Error("심볼릭 링크를 타고 대상 밖에 씀")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "evil")); err == nil {
+		t.Error("대상 밖에 파일이 생김")
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	// 0 으로 채운 1MB 는 gzip 으로 1KB 남짓 - 헤더가 아니라 실제로 풀린 크기로 잡아야 해
+	bomb := makeTarGz(t, tarEntry{name: "zeros", body: strings.Repeat("\x00", 1<<20)})
+	dst := t.TempDir()
+	_, err := Extract(context.Background(), bomb, dst, Options{MaxFileSize: 64 << 10})
+	if !errors.Is(err, ErrLimit) {
+		t.Fatalf("err = %v, want ErrLimit", err)
+	}
+	if entries, _ := os.ReadDir(dst); len(entries) != 0 {
+		t.Errorf("한도를 넘은 파일의 조각이 남음: %v", entries)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"a", "b", "c"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(name))
+	}
+	zw.Close()
+	// bytes.Reader 는 *os.File 이 아니라서 임시 파일에 받아서 풀어
+	_, err = Extract(context.Background(), bytes.NewReader(buf.Bytes()), t.TempDir(), Options{MaxFiles: 2})
+	if !errors.Is(err, ErrLimit) {
+		t.Errorf("엔트리 3개, 한도 2: err = %v, want ErrLimit", err)
+	}
+	res, err := Extract(context.Background(), bytes.NewReader(buf.Bytes()), t.TempDir(), Options{MaxTotal: 3})
+	if err != nil || res.Format != Zip || res.Bytes != 3 {
+		t.Errorf("zip = %v, %v", res, err)
+	}
+}
+
+// zeroReader 0 을 끝없이 읽어 (io.LimitReader 로 크기를 잘라서 써)
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestExtractZip64LargeMember(t *testing.T) {
+	if testing.Short() {
+		t.Skip("4GB 넘는 엔트리를 만들고 풀어서 -short 에서는 건너뜀")
+	}
+	// ⭐ 0 으로 채운 4GB+ 엔트리를 압축 없이(Store) sparse 파일에 써서 실제 디스크는 거의 안 써
+	const size = 1<<32 + 1<<20
+	archive := filepath.Join(t.TempDir(), "big.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := streamio.NewSparseWriter(f)
+	zw := zip.NewWriter(sw)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "disk.img", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(w, io.LimitReader(zeroReader{}, size-4)); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("tail"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := f.Stat()
+	f.Close()
+	if !streamio.IsSparse(info) {
+		t.Skip("이 파일시스템은 sparse 파일을 지원하지 않음")
+	}
+
+	dst := t.TempDir()
+	res, err := ExtractFile(context.Background(), archive, dst, Options{MaxFileSize: 8 << 30, MaxTotal: 8 << 30, Sparse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Files != 1 || res.Bytes != size {
+		t.Errorf("결과 = %v, want %d 바이트", res, int64(size))
+	}
+	out, err := os.Open(filepath.Join(dst, "disk.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	tail := make([]byte, 4)
+	out.ReadAt(tail, size-4)
+	if info, _ := out.Stat(); info.Size() != size || string(tail) != "tail" || !streamio.IsSparse(info) {
+		t.Errorf("풀린 파일: 크기 %d, 끝 %q, sparse %v", info.Size(), tail, streamio.IsSparse(info))
+	}
+
+	// 한도는 그대로 지켜 - 기본값(1GB)이면 4GB 엔트리를 거절
+	if _, err := ExtractFile(context.Background(), archive, t.TempDir(), Options{Sparse: true}); !errors.Is(err, ErrLimit) {
+		t.Errorf("기본 한도: err = %v, want ErrLimit", err)
+	}
+}
+
+func TestExtractZip64ManyEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("엔트리 7만 개를 풀어서 -short 에서는 건너뜀")
+	}
+	// 65535개를 넘으면 zip.Writer 가 Zip64 끝 레코드를 써
+	const n = 70000
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := range n {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: fmt.Sprintf("d%02d/f%05d", i%100, i), Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte{'x'})
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "many.zip")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractFile(context.Background(), archive, t.TempDir(), Options{}); !errors.Is(err, ErrLimit) {
+		t.Errorf("기본 한도 %d: err = %v, want ErrLimit", DefaultMaxFiles, err)
+	}
+	dst := t.TempDir()
+	res, err := ExtractFile(context.Background(), archive, dst, Options{MaxFiles: n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Files != n || res.Bytes != n {
+		t.Errorf("결과 = %v, want 파일 %d", res, n)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "d99", fmt.Sprintf("f%05d", n-1))); err != nil {
+		t.Error(err)
+	}
+}
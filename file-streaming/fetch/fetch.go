@@ -0,0 +1,320 @@
+// Package fetch 는 HTTP 로 파일을 내려받는 클라이언트야. 끊기면 받은 곳부터 Range 로 이어받아.
+//
+// 흐름
+//
+//	GET url                                    처음: 200 + ETag (+ X-Content-SHA256)
+//	GET url  Range: bytes=N-  If-Range: ETag   이어받기: 그대로면 206, 파일이 바뀌었으면 200 (처음부터)
+//
+// 받는 중인 파일은 .<이름>.part 로 두고, 처음 받은 ETag 와 sha256 은 .<이름>.part.json 에 남겨.
+// 다 받으면 sha256 을 맞춰 보고(-sha256 으로 준 값, 없으면 서버가 알려 준 값) 맞을 때만 rename 해.
+package fetch
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// ChecksumHeader 서버가 알려 주는 파일의 sha256 (step09 서버의 /range-download)
+const ChecksumHeader = "X-Content-SHA256"
+
+// Options 내려받기 옵션
+// ⭐ BufferSize, RateLimit, Hooks, Retries, RetryDelay 는 streamio.CopyOptions 그대로야 (Retries 는 끊겼을 때 이어받는 횟수)
+type Options struct {
+	streamio.CopyOptions
+
+	SHA256 string       // 기대하는 sha256 (비우면 서버의 X-Content-SHA256, 그것도 없으면 검증 안 함)
+	Header http.Header  // 요청마다 붙일 헤더 (Authorization 등)
+	Client *http.Client // nil 이면 http.DefaultClient
+}
+
+// Result 내려받은 결과
+type Result struct {
+	URL       string `json:"url"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	Verified  bool   `json:"verified"`            // 기대하는 sha256 과 맞춰 봤는지 (모르면 false)
+	Received  int64  `json:"received"`            // 모든 시도에서 실제로 받은 바이트
+	Resumed   int64  `json:"resumed"`             // 마지막 시도가 이어받은 위치 (.part 에 이미 있던 바이트)
+	Restarted bool   `json:"restarted,omitempty"` // .part 가 있었지만 서버 파일이 바뀌어서 처음부터 받았는지
+	Attempts  int    `json:"attempts"`
+}
+
+// StatusError 서버가 200/206 이 아닌 상태로 답했을 때
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string { return msg.Sprintf("서버 응답: %s", e.Status) }
+
+// temporary 다시 요청하면 나아질 수 있는 상태인지 (5xx, 408, 429)
+func (e *StatusError) temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests
+}
+
+// ErrChecksum 다 받은 파일의 sha256 이 기대한 값과 다를 때 (.part 는 지워서 다음에는 처음부터 받아)
+var ErrChecksum = errors.New("sha256 mismatch")
+
+// state .part.json - 이어받아도 되는지 판단할 처음 응답의 정보
+type state struct {
+	URL    string `json:"url"`
+	ETag   string `json:"etag,omitempty"`
+	SHA256 string `json:"sha256,omitempty"` // 서버가 알려 준 값
+	Size   int64  `json:"size"`             // 전체 크기 (모르면 -1)
+}
+
+// Download url 을 dest 로 내려받아
+// ⭐ .part 와 .part.json 이 남아 있으면 그 크기부터 Range + If-Range 로 이어받아 - 서버 파일이 그 사이 바뀌었으면
+// 서버가 200 으로 전체를 보내니까 .part 를 비우고 처음부터 받아. 강한 ETag 가 없는 서버는 안전하게 이어 붙일 방법이 없어서 늘 처음부터야.
+func Download(ctx context.Context, url, dest string, opts Options) (Result, error) {
+	part, statePath := partPaths(dest)
+	res := Result{URL: url, Path: dest}
+	hooks := opts.Hooks
+	if hooks == nil {
+		hooks = streamio.NopHooks{}
+	}
+	info := streamio.TransferInfo{ID: filepath.Base(dest), Src: url, Dst: dest, Size: -1}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	var (
+		st  state
+		err error
+	)
+	for attempt := 0; ; attempt++ {
+		res.Attempts++
+		st, err = fetchOnce(ctx, url, part, statePath, opts, hooks, &info, &res)
+		if err == nil || attempt >= opts.Retries || ctx.Err() != nil || permanent(err) {
+			break
+		}
+		hooks.OnRetry(info, attempt+1, err)
+		select {
+		case <-time.After(opts.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+		}
+	}
+	if err == nil {
+		err = finish(part, statePath, dest, cmp.Or(opts.SHA256, st.SHA256), &res)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		hooks.OnError(info, err)
+		return res, err
+	}
+	hooks.OnComplete(info, res.Received, time.Since(start))
+	return res, nil
+}
+
+// partPaths dest 를 받는 동안 쓰는 .<이름>.part 와 .<이름>.part.json
+func partPaths(dest string) (part, statePath string) {
+	part = filepath.Join(filepath.Dir(dest), "."+filepath.Base(dest)+".part")
+	return part, part + ".json"
+}
+
+// fetchOnce 요청 한 번 - .part 크기부터 받아서 뒤에 이어 써
+func fetchOnce(ctx context.Context, url, part, statePath string, opts Options, hooks streamio.Hooks, info *streamio.TransferInfo, res *Result) (state, error) {
+	old := loadState(statePath, url)
+	var offset int64
+	if resumable(old.ETag) {
+		if fi, err := os.Stat(part); err == nil {
+			offset = fi.Size()
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return state{}, err
+	}
+	for k, v := range opts.Header {
+		req.Header[k] = v
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		req.Header.Set("If-Range", old.ETag)
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return state{}, err
+	}
+	defer resp.Body.Close()
+
+	cur := state{URL: url, ETag: resp.Header.Get("ETag"), SHA256: strings.ToLower(resp.Header.Get(ChecksumHeader)), Size: -1}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		res.Restarted = res.Restarted || offset > 0
+		offset = 0
+		cur.Size = resp.ContentLength
+	case http.StatusPartialContent:
+		first, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || first != offset {
+			// 요청한 곳이 아닌 데를 주면 이어 붙일 수 없어 - 기록을 지워서 다음 시도는 처음부터
+			os.Remove(statePath)
+			return state{}, msg.Errorf("요청한 위치(%d)와 다른 부분을 받음: %s", offset, resp.Header.Get("Content-Range"))
+		}
+		cur.Size = total
+		cur.ETag = cmp.Or(cur.ETag, old.ETag)
+		cur.SHA256 = cmp.Or(cur.SHA256, old.SHA256)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// ETag 가 그대로인데 .part 가 이미 끝까지 있으면 (지난번에 다 받고 검증 전에 멈춘 경우) 받을 게 없어
+		if _, total, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && offset > 0 && total == offset {
+			res.Resumed, res.Size = offset, offset
+			return old, nil
+		}
+		os.Remove(statePath)
+		return state{}, &StatusError{Code: resp.StatusCode, Status: resp.Status}
+	default:
+		return state{}, &StatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+	if err := saveState(statePath, cur); err != nil {
+		return state{}, err
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if offset == 0 {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(part, flag, 0644)
+	if err != nil {
+		return state{}, err
+	}
+	res.Resumed = offset
+	info.Size = cur.Size
+	var body io.Reader = resp.Body
+	if opts.RateLimit > 0 {
+		body = streamio.NewThrottledReader(body, opts.RateLimit)
+	}
+	bufSize := opts.BufferSize
+	if bufSize <= 0 {
+		bufSize = streamio.DefaultBufferSize
+	}
+	pw := &progressWriter{w: f, hooks: hooks, info: *info, done: offset}
+	n, err := io.CopyBuffer(pw, body, make([]byte, bufSize))
+	res.Received += n
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return state{}, err
+	}
+	res.Size = offset + n
+	if cur.Size >= 0 && res.Size != cur.Size {
+		return state{}, io.ErrUnexpectedEOF
+	}
+	return cur, nil
+}
+
+// finish .part 의 sha256 을 want 와 맞춰 보고 dest 로 rename
+func finish(part, statePath, dest, want string, res *Result) error {
+	sum, err := streamio.FileSHA256(part)
+	if err != nil {
+		return err
+	}
+	res.SHA256 = sum
+	if want != "" {
+		if !strings.EqualFold(sum, want) {
+			// 어디가 깨졌는지 모르니 .part 는 믿을 수 없어
+			os.Remove(part)
+			os.Remove(statePath)
+			return fmt.Errorf("%w: %s", ErrChecksum, msg.Sprintf("기대한 값 %s, 받은 파일 %s", want, sum))
+		}
+		res.Verified = true
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return err
+	}
+	os.Remove(statePath)
+	return nil
+}
+
+// resumable If-Range 에 쓸 수 있는 ETag 인지 - 약한 ETag(W/)는 If-Range 가 받아 주지 않아
+func resumable(etag string) bool {
+	return strings.HasPrefix(etag, `"`)
+}
+
+// permanent 다시 시도해도 같은 결과일 에러 (4xx)
+func permanent(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && !se.temporary()
+}
+
+// loadState url 을 받던 기록 (없거나 다른 url 이면 빈 값 - 처음부터)
+func loadState(path, url string) state {
+	var st state
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &st) != nil || st.URL != url {
+		return state{}
+	}
+	return st
+}
+
+func saveState(path string, st state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// parseContentRange "bytes 100-199/200" → 100, 200 ("bytes */200" 이면 -1, 200, 전체가 * 면 -1)
+func parseContentRange(v string) (first, total int64, ok bool) {
+	rest, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		total = n
+	}
+	if rng == "*" {
+		return -1, total, true
+	}
+	start, _, found := strings.Cut(rng, "-")
+	n, err := strconv.ParseInt(start, 10, 64)
+	if !found || err != nil {
+		return 0, 0, false
+	}
+	return n, total, true
+}
+
+// progressWriter 파일 전체 기준(이어받은 위치부터)으로 진행률 훅 호출
+type progressWriter struct {
+	w     io.Writer
+	hooks streamio.Hooks
+	info  streamio.TransferInfo
+	done  int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	p.hooks.OnProgress(p.info, p.done)
+	return n, err
+}
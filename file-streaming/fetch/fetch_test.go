@@ -0,0 +1,219 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fileServer /range-download 처럼 ServeContent 에 ETag 와 X-Content-SHA256 을 붙여 서빙하는 테스트 서버
+type fileServer struct {
+	mu      sync.Mutex
+	data    []byte
+	version int
+	cutOnce int64    // 0 보다 크면 다음 응답은 이만큼만 보내고 끊어
+	ranges  []string // 받은 Range 헤더 (없으면 "")
+}
+
+func (f *fileServer) set(data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data = data
+	f.version++
+}
+
+func (f *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	data, version, cut := f.data, f.version, f.cutOnce
+	f.cutOnce = 0
+	f.ranges = append(f.ranges, r.Header.Get("Range"))
+	f.mu.Unlock()
+
+	sum := sha256.Sum256(data)
+	w.Header().Set("ETag", `"v`+strconv.Itoa(version)+`"`)
+	w.Header().Set(ChecksumHeader, hex.EncodeToString(sum[:]))
+	if cut > 0 {
+		// Content-Length 는 전체로 알려 놓고 중간에 끊어 - 클라이언트는 unexpected EOF
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data[:cut])
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(data))
+}
+
+func randomData(t *testing.T, n int) []byte {
+	t.Helper()
+	data := make([]byte, n)
+	rand.New(rand.NewSource(int64(n))).Read(data)
+	return data
+}
+
+func newFileServer(t *testing.T, data []byte) (*fileServer, string) {
+	t.Helper()
+	fsrv := &fileServer{}
+	fsrv.set(data)
+	ts := httptest.NewServer(fsrv)
+	t.Cleanup(ts.Close)
+	return fsrv, ts.URL + "/file.bin"
+}
+
+func expectFile(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s: 내용이 다름 (%d 바이트, 기대 %d)", path, len(got), len(want))
+	}
+	part, statePath := partPaths(path)
+	for _, p := range []string{part, statePath} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s 가 남아 있음 (err=%v)", p, err)
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	data := randomData(t, 100_000)
+	_, url := newFileServer(t, data)
+	dest := filepath.Join(t.TempDir(), "out.bin")
+
+	res, err := Download(context.Background(), url, dest, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, dest, data)
+	if !res.Verified || res.Size != int64(len(data)) || res.Received != int64(len(data)) || res.Resumed != 0 || res.Attempts != 1 {
+		t.Errorf("결과 = %+v", res)
+	}
+}
+
+func TestDownloadResumesAfterCut(t *testing.T) {
+	data := randomData(t, 200_000)
+	fsrv, url := newFileServer(t, data)
+	fsrv.cutOnce = 70_000
+	dest := filepath.Join(t.TempDir(), "out.bin")
+
+	res, err := Download(context.Background(), url, dest, Options{})
+	if err == nil {
+		t.Fatal("끊긴 전송이 성공함")
+	}
+	part, _ := partPaths(dest)
+	if fi, err := os.Stat(part); err != nil || fi.Size() != 70_000 {
+		t.Fatalf(".part = %v, %v (기대 70000 바이트)", fi, err)
+	}
+
+	// 다시 실행하면 .part 크기부터 If-Range 로 이어받아
+	res, err = Download(context.Background(), url, dest, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, dest, data)
+	if res.Resumed != 70_000 || res.Received != int64(len(data))-70_000 || !res.Verified || res.Restarted {
+		t.Errorf("결과 = %+v", res)
+	}
+	if last := fsrv.ranges[len(fsrv.ranges)-1]; last != "bytes=70000-" {
+		t.Errorf("Range = %q", last)
+	}
+}
+
+func TestDownloadRetries(t *testing.T) {
+	data := randomData(t, 150_000)
+	fsrv, url := newFileServer(t, data)
+	fsrv.cutOnce = 40_000
+	dest := filepath.Join(t.TempDir(), "out.bin")
+
+	opts := Options{}
+	opts.Retries = 2
+	res, err := Download(context.Background(), url, dest, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, dest, data)
+	if res.Attempts != 2 || res.Resumed != 40_000 || res.Received != int64(len(data)) {
+		t.Errorf("결과 = %+v", res)
+	}
+}
+
+func TestDownloadRestartsWhenChanged(t *testing.T) {
+	fsrv, url := newFileServer(t, randomData(t, 80_000))
+	fsrv.cutOnce = 30_000
+	dest := filepath.Join(t.TempDir(), "out.bin")
+	if _, err := Download(context.Background(), url, dest, Options{}); err == nil {
+		t.Fatal("끊긴 전송이 성공함")
+	}
+
+	// 그 사이 서버 파일이 바뀌면 ETag 가 달라서 If-Range 가 안 맞아 - 200 으로 처음부터
+	changed := randomData(t, 90_000)
+	fsrv.set(changed)
+	res, err := Download(context.Background(), url, dest, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, dest, changed)
+	if !res.Restarted || res.Resumed != 0 || !res.Verified {
+		t.Errorf("결과 = %+v", res)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	_, url := newFileServer(t, randomData(t, 10_000))
+	dest := filepath.Join(t.TempDir(), "out.bin")
+
+	opts := Options{SHA256: hex.EncodeToString(make([]byte, sha256.Size))}
+	if _, err := Download(context.Background(), url, dest, opts); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("err = %v, 기대 ErrChecksum", err)
+	}
+	part, statePath := partPaths(dest)
+	for _, p := range []string{dest, part, statePath} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s 가 남아 있음 (err=%v)", p, err)
+		}
+	}
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(ts.Close)
+	opts := Options{}
+	opts.Retries = 3
+	res, err := Download(context.Background(), ts.URL+"/missing", filepath.Join(t.TempDir(), "x"), opts)
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusNotFound {
+		t.Fatalf("err = %v", err)
+	}
+	if res.Attempts != 1 {
+		t.Errorf("404 를 %d 번 시도함", res.Attempts)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	for _, tc := range []struct {
+		in           string
+		first, total int64
+		ok           bool
+	}{
+		{"bytes 100-199/200", 100, 200, true},
+		{"bytes 0-9/*", 0, -1, true},
+		{"bytes */300", -1, 300, true},
+		{"items 0-1/2", 0, 0, false},
+		{"bytes 5-9", 0, 0, false},
+	} {
+		first, total, ok := parseContentRange(tc.in)
+		if first != tc.first || total != tc.total || ok != tc.ok {
+			t.Errorf("%q = %d, %d, %v", tc.in, first, total, ok)
+		}
+	}
+}
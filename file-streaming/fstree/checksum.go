@@ -0,0 +1,213 @@
+package fstree
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Checksum 파일마다 남겨 두는 체크섬 기록 - 언제 잰 값이고 마지막으로 언제 다시 읽어 확인했는지까지
+type Checksum struct {
+	SHA256   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mtime"`    // 해시를 잴 때의 수정 시각
+	Verified time.Time `json:"verified"` // 마지막으로 내용을 다시 읽어 확인한 시각
+}
+
+// Stale 기록한 뒤에 파일이 고쳐졌는지 (크기나 수정 시각이 다름)
+// ⭐ 고쳐진 파일은 해시가 달라도 정상이야 - 크기/수정 시각은 그대로인데 내용만 바뀐 게 비트 부패(bit rot)야.
+func (c Checksum) Stale(info fs.FileInfo) bool {
+	return c.Size != info.Size() || !c.ModTime.Equal(info.ModTime())
+}
+
+// ChecksumStore 체크섬 기록을 두는 곳 (XattrChecksums 또는 OpenChecksumDB)
+// 여러 고루틴에서 같이 불러도 돼.
+type ChecksumStore interface {
+	Get(path string) (Checksum, bool, error)
+	Put(path string, c Checksum) error
+	Close() error
+}
+
+// ErrXattrUnsupported 플랫폼이나 파일시스템이 확장 속성을 지원하지 않음 - 사이드카 DB 를 써
+var ErrXattrUnsupported = msg.New("확장 속성(xattr)을 지원하지 않는 플랫폼/파일시스템입니다 - 사이드카 DB 를 쓰세요")
+
+// checksumXattr 기록을 담는 확장 속성 이름 (값은 Checksum JSON)
+const checksumXattr = "user.streamio.checksum"
+
+// XattrChecksums 파일 자신의 확장 속성에 기록하는 저장소
+// ⭐ 기록이 파일에 붙어 다녀서 이름을 바꾸거나 옮겨도(copy -preserve, cp --preserve=xattr) 따라가고 따로 DB 가 필요 없어.
+// 대신 쓰기 권한이 없는 파일에는 못 남기고, FAT/일부 네트워크 파일시스템은 지원하지 않아.
+func XattrChecksums() ChecksumStore { return xattrChecksums{} }
+
+type xattrChecksums struct{}
+
+func (xattrChecksums) Get(path string) (Checksum, bool, error) {
+	data, ok, err := getXattr(path, checksumXattr)
+	if err != nil || !ok {
+		return Checksum{}, false, err
+	}
+	var c Checksum
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Checksum{}, false, msg.Errorf("%s: 체크섬 속성 형식 오류: %w", path, err)
+	}
+	return c, true, nil
+}
+
+func (xattrChecksums) Put(path string, c Checksum) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return setXattr(path, checksumXattr, data)
+}
+
+func (xattrChecksums) Close() error { return nil }
+
+// ChecksumDB 체크섬을 JSON 파일 하나에 모아 두는 사이드카 DB (xattr 을 못 쓰는 곳용)
+// 경로는 DB 파일이 있는 디렉토리 기준 상대 경로로 저장해서 트리째 옮겨도 그대로 맞아 (바깥 파일은 절대 경로로).
+// 바꾼 내용은 Close 때 한 번에 저장해.
+type ChecksumDB struct {
+	path string
+	dir  string
+
+	mu      sync.Mutex
+	files   map[string]Checksum
+	changed map[string]Checksum
+}
+
+type checksumDBFile struct {
+	Files map[string]Checksum `json:"files"`
+}
+
+// OpenChecksumDB 사이드카 DB 열기 (없으면 빈 DB 로 시작해서 Close 때 만들어)
+func OpenChecksumDB(path string) (*ChecksumDB, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := readChecksumDB(abs)
+	if err != nil {
+		return nil, err
+	}
+	return &ChecksumDB{path: abs, dir: filepath.Dir(abs), files: files, changed: make(map[string]Checksum)}, nil
+}
+
+func readChecksumDB(path string) (map[string]Checksum, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]Checksum), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f checksumDBFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, msg.Errorf("체크섬 DB %s: %w", path, err)
+	}
+	if f.Files == nil {
+		f.Files = make(map[string]Checksum)
+	}
+	return f.Files, nil
+}
+
+// Path DB 파일 경로 (절대 경로)
+func (db *ChecksumDB) Path() string { return db.path }
+
+func (db *ChecksumDB) key(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(db.dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs), nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (db *ChecksumDB) Get(path string) (Checksum, bool, error) {
+	key, err := db.key(path)
+	if err != nil {
+		return Checksum{}, false, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	c, ok := db.files[key]
+	return c, ok, nil
+}
+
+func (db *ChecksumDB) Put(path string, c Checksum) error {
+	key, err := db.key(path)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.files[key] = c
+	db.changed[key] = c
+	return nil
+}
+
+// Close 바꾼 항목 저장 - 그 사이 다른 프로세스가 저장한 내용을 다시 읽어서 그 위에 덮어 (서로 지우지 않게)
+func (db *ChecksumDB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.changed) == 0 {
+		return nil
+	}
+	files, err := readChecksumDB(db.path)
+	if err != nil {
+		return err
+	}
+	for key, c := range db.changed {
+		files[key] = c
+	}
+	data, err := json.MarshalIndent(checksumDBFile{Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(db.path, data); err != nil {
+		return msg.Errorf("체크섬 DB 저장 실패: %w", err)
+	}
+	db.files, db.changed = files, make(map[string]Checksum)
+	return nil
+}
+
+// writeFileAtomic 임시 파일 → rename (저장하다 죽어도 이전 DB 는 멀쩡하게)
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), path)
+}
+
+// RecordChecksum path 를 읽어서 해시하고 기록 (방금 쓴 파일에 - 확인 시각도 지금으로)
+func RecordChecksum(store ChecksumStore, path string) (Checksum, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Checksum{}, err
+	}
+	sum, err := hashFile(path)
+	if err != nil {
+		return Checksum{}, err
+	}
+	c := Checksum{SHA256: sum, Size: info.Size(), ModTime: info.ModTime(), Verified: time.Now()}
+	return c, store.Put(path, c)
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package fstree
+
+import "io/fs"
+
+type fileID struct {
+	dev, ino uint64
+}
+
+// hardLinkID inode 를 알 수 없는 플랫폼은 하드링크를 각각 별개 파일로 복사해
+func hardLinkID(info fs.FileInfo) (fileID, bool) { return fileID{}, false }
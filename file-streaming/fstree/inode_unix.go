@@ -0,0 +1,24 @@
+//go:build unix
+
+package fstree
+
+import (
+	"io/fs"
+	"syscall"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// fileID 같은 파일(inode)인지 구분하는 키 - 하드링크는 장치+inode 번호가 같아
+type fileID struct {
+	dev, ino uint64
+}
+
+// hardLinkID 링크 수가 2 이상인 파일의 fileID (하드링크가 아니면 false)
+func hardLinkID(info fs.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st.Nlink < 2 || streamio.Portable() {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
@@ -0,0 +1,177 @@
+package fstree
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// ManifestOptions 매니페스트 생성/검증 옵션
+type ManifestOptions struct {
+	Walk    WalkOptions // 대상 필터 (매니페스트 파일 자신은 Exclude 에 넣어줘)
+	Workers int         // 병렬 해시 워커 수 (기본: CPU 수)
+}
+
+func (o ManifestOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.NumCPU()
+}
+
+type hashResult struct {
+	rel  string
+	sum  string
+	err  error
+	size int64
+}
+
+// hashEntries 채널로 들어오는 파일들을 워커 풀로 해시 - 결과도 끝나는 대로 채널로 흘려보내
+func hashEntries(entries <-chan Entry, workers int) <-chan hashResult {
+	results := make(chan hashResult, workers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range entries {
+				if entry.Err != nil {
+					results <- hashResult{rel: entry.RelPath, err: entry.Err}
+					continue
+				}
+				sum, err := hashFile(entry.Path)
+				results <- hashResult{rel: entry.RelPath, sum: sum, err: err, size: entry.Info.Size()}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// WriteManifest root 아래 파일들의 SHA256SUMS 형식 매니페스트를 w 에 써
+// ⭐ 순회 → 병렬 해시 → 출력이 모두 채널로 이어져서, 파일이 많아도 목록 전체를 메모리에 두지 않아.
+// 줄 순서는 해시가 끝난 순서야 (sha256sum -c 는 순서를 따지지 않아).
+// 해시하지 못한 파일이 있으면 나머지는 쓰고 첫 에러를 돌려줘.
+func WriteManifest(ctx context.Context, root string, w io.Writer, opts ManifestOptions) (int, error) {
+	entries := Walk(ctx, root, opts.Walk)
+	bw := bufio.NewWriter(w)
+
+	count := 0
+	var firstErr error
+	for r := range hashEntries(entries, opts.workers()) {
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", r.rel, r.err)
+			}
+			continue
+		}
+		// sha256sum 과 같은 형식: "<해시>  <경로>"
+		fmt.Fprintf(bw, "%s  %s\n", r.sum, r.rel)
+		count++
+	}
+
+	if err := bw.Flush(); err != nil {
+		return count, err
+	}
+	if err := ctx.Err(); err != nil {
+		return count, err
+	}
+	return count, firstErr
+}
+
+// ReadManifest SHA256SUMS 형식 파싱 (경로 → 해시)
+func ReadManifest(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// "<해시>  <경로>" 또는 바이너리 모드 "<해시> *<경로>"
+		sum, rel, ok := strings.Cut(line, " ")
+		if !ok || len(sum) != 64 || len(rel) < 2 {
+			return nil, msg.Errorf("매니페스트 %d번째 줄 형식 오류: %q", lineNumber, line)
+		}
+		rel = rel[1:]
+		sums[filepath.ToSlash(rel)] = strings.ToLower(sum)
+	}
+	return sums, scanner.Err()
+}
+
+// VerifyReport 검증 결과
+type VerifyReport struct {
+	OK        int
+	Missing   []string // 매니페스트에는 있는데 트리에 없음
+	Extra     []string // 트리에는 있는데 매니페스트에 없음
+	Corrupted []string // 해시가 다름
+	Errors    []string // 읽기 실패 등
+}
+
+// Clean 문제가 하나도 없는지
+func (r VerifyReport) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Corrupted) == 0 && len(r.Errors) == 0
+}
+
+func (r VerifyReport) String() string {
+	return msg.Sprintf("정상 %d, 누락 %d, 추가됨 %d, 손상 %d, 에러 %d",
+		r.OK, len(r.Missing), len(r.Extra), len(r.Corrupted), len(r.Errors))
+}
+
+// VerifyManifest 트리를 매니페스트와 대조
+// 트리를 한 번 순회하면서 병렬로 해시하고, 끝까지 보지 못한 매니페스트 항목은 누락으로 처리해.
+func VerifyManifest(ctx context.Context, root string, sums map[string]string, opts ManifestOptions) (VerifyReport, error) {
+	var report VerifyReport
+	seen := make(map[string]bool, len(sums))
+
+	for r := range hashEntries(Walk(ctx, root, opts.Walk), opts.workers()) {
+		expected, listed := sums[r.rel]
+		if listed {
+			seen[r.rel] = true
+		}
+
+		switch {
+		case r.err != nil:
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", r.rel, r.err))
+		case !listed:
+			report.Extra = append(report.Extra, r.rel)
+		case r.sum != expected:
+			report.Corrupted = append(report.Corrupted, r.rel)
+		default:
+			report.OK++
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	for rel := range sums {
+		if !seen[rel] {
+			report.Missing = append(report.Missing, rel)
+		}
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+	sort.Strings(report.Corrupted)
+	sort.Strings(report.Errors)
+	return report, nil
+}
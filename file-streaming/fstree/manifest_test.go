@@ -0,0 +1,65 @@
+package fstree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.txt": "a", "sub/b.txt": "bb", "sub/c.txt": "ccc", "d.txt": "dddd"})
+
+	var buf bytes.Buffer
+	n, err := WriteManifest(t.Context(), root, &buf, ManifestOptions{Workers: 3})
+	if err != nil || n != 4 {
+		t.Fatalf("WriteManifest = %d, %v", n, err)
+	}
+	sums, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sha256.Sum256([]byte("bb"))
+	if got := sums["sub/b.txt"]; got != hex.EncodeToString(want[:]) {
+		t.Errorf("sub/b.txt 해시 = %q", got)
+	}
+
+	report, err := VerifyManifest(t.Context(), root, sums, ManifestOptions{})
+	if err != nil || !report.Clean() || report.OK != 4 {
+		t.Fatalf("손대지 않은 트리 = %v, %v", report, err)
+	}
+
+	// 하나 고치고, 하나 지우고, 하나 더하고
+	writeTree(t, root, map[string]string{"a.txt": "A", "new.txt": "new"})
+	os.Remove(filepath.Join(root, "d.txt"))
+	report, err = VerifyManifest(t.Context(), root, sums, ManifestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK != 2 || !slices.Equal(report.Corrupted, []string{"a.txt"}) ||
+		!slices.Equal(report.Missing, []string{"d.txt"}) || !slices.Equal(report.Extra, []string{"new.txt"}) {
+		t.Errorf("바뀐 트리 = %+v", report)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	sum := strings.Repeat("AB", 32)
+	sums, err := ReadManifest(strings.NewReader("# 주석\n\n" + sum + "  a b.txt\n" + sum + " *bin/x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 해시는 소문자로, 바이너리 모드 표시(*)는 떼
+	if len(sums) != 2 || sums["a b.txt"] != strings.ToLower(sum) || sums["bin/x"] == "" {
+		t.Errorf("ReadManifest = %v", sums)
+	}
+	for _, bad := range []string{"abc  a.txt\n", sum + "\n"} {
+		if _, err := ReadManifest(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadManifest(%q) 에러가 없음", bad)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package fstree
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// WalkStorage Walk 의 저장소 버전 - 원격(SFTP 등) 트리도 같은 Entry 채널로 흘려보내
+// Include/Exclude/MaxDepth/크기/시각 필터는 Walk 와 같고, 심볼릭 링크는 List 가 알려준 그대로라 따라가지 않아.
+// Entry.Path 는 저장소 안의 슬래시 경로야.
+func WalkStorage(ctx context.Context, s storage.Storage, root string, opts WalkOptions) <-chan Entry {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = 64
+	}
+	out := make(chan Entry, size)
+
+	go func() {
+		defer close(out)
+		w := &walker{ctx: ctx, opts: opts, out: out}
+		w.walkStorage(s, root, "", 0)
+	}()
+	return out
+}
+
+// walkStorage dir 아래를 깊이 우선으로 (List 는 이름순이라 결과 순서도 Walk 와 같아)
+func (w *walker) walkStorage(s storage.Storage, dir, relBase string, depth int) error {
+	infos, err := s.List(w.ctx, dir)
+	if err != nil {
+		rel := relBase
+		if rel == "" {
+			rel = "."
+		}
+		return w.send(Entry{Path: dir, RelPath: rel, Depth: depth, Err: err})
+	}
+
+	opts := w.opts
+	for _, info := range infos {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		p := path.Join(dir, info.Name())
+		rel := path.Join(relBase, info.Name())
+		d := depth + 1
+
+		if matchAny(opts.Exclude, rel) {
+			continue
+		}
+		if info.IsDir() {
+			if opts.IncludeDirs {
+				if err := w.send(Entry{Path: p, RelPath: rel, Info: info, Depth: d}); err != nil {
+					return err
+				}
+			}
+			if opts.MaxDepth > 0 && d >= opts.MaxDepth {
+				continue
+			}
+			if err := w.walkStorage(s, p, rel, d); err != nil && w.ctx.Err() != nil {
+				return err
+			}
+			continue
+		}
+
+		if opts.MaxDepth > 0 && d > opts.MaxDepth {
+			continue
+		}
+		if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+			continue
+		}
+		if !opts.accept(info) {
+			continue
+		}
+		if err := w.send(Entry{Path: p, RelPath: rel, Info: info, Depth: d}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncStorage Sync 의 저장소 버전 - 원본이나 대상이 원격일 때
+// 비교(크기+수정시각 또는 해시), 복사, -delete, dry-run, 필터는 Sync 와 같아.
+// 하드링크/sparse/심볼릭 링크/소유자 같은 로컬 파일시스템 기능은 없고, 휴지통은 대상이 로컬일 때만 써.
+func SyncStorage(ctx context.Context, src storage.Storage, srcDir string, dst storage.Storage, dstDir string, opts SyncOptions) (SyncReport, error) {
+	report := SyncReport{DryRun: opts.DryRun}
+	notify := func(a SyncAction) {
+		if opts.OnAction != nil {
+			opts.OnAction(a)
+		}
+	}
+	if _, local := dst.(storage.Local); opts.Trash != nil && !local {
+		return report, msg.New("휴지통은 로컬 대상에서만 쓸 수 있습니다")
+	}
+
+	copyOpts := streamio.CopyOptions{
+		Hooks:    opts.Hooks,
+		Preserve: streamio.PreserveTimes,
+
+		BufferSize: opts.BufferSize,
+		RateLimit:  opts.RateLimit,
+	}
+
+	seen := make(map[string]bool)
+	walkOpts := opts.Walk
+	walkOpts.IncludeDirs = true
+
+	for entry := range WalkStorage(ctx, src, srcDir, walkOpts) {
+		if entry.Err != nil {
+			report.Failed++
+			notify(SyncAction{Kind: SyncError, RelPath: entry.RelPath, Err: entry.Err})
+			continue
+		}
+		seen[entry.RelPath] = true
+		if entry.Info.IsDir() {
+			continue // 디렉토리는 Create 가 필요할 때 만들어
+		}
+		target := path.Join(dstDir, entry.RelPath)
+
+		kind, err := compareStorage(ctx, src, entry, dst, target, opts.Checksum)
+		if err != nil {
+			report.Failed++
+			notify(SyncAction{Kind: SyncError, RelPath: entry.RelPath, Err: err})
+			continue
+		}
+		if kind == "" {
+			report.Unchanged++
+			continue
+		}
+
+		action := SyncAction{Kind: kind, RelPath: entry.RelPath, Size: entry.Info.Size()}
+		if !opts.DryRun {
+			if _, err := storage.CopyFile(ctx, src, entry.Path, dst, target, copyOpts); err != nil {
+				action.Kind, action.Err = SyncError, err
+			}
+		}
+
+		switch action.Kind {
+		case SyncCopy:
+			report.Copied++
+			report.BytesCopied += action.Size
+		case SyncUpdate:
+			report.Updated++
+			report.BytesCopied += action.Size
+		case SyncError:
+			report.Failed++
+		}
+		notify(action)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if opts.Delete {
+		deleteExtraneousStorage(ctx, dst, dstDir, walkOpts, seen, opts, &report, notify)
+	}
+	return report, ctx.Err()
+}
+
+// compareStorage compareForSync 의 저장소 버전
+// ⭐ SFTP 는 수정 시각을 초 단위로만 저장하니까 초 단위로 비교해 (안 그러면 매번 다시 복사)
+func compareStorage(ctx context.Context, src storage.Storage, entry Entry, dst storage.Storage, target string, checksum bool) (SyncActionKind, error) {
+	dstInfo, err := dst.Stat(ctx, target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return SyncCopy, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if dstInfo.IsDir() {
+		return "", msg.Errorf("대상이 디렉토리입니다: %s", target)
+	}
+	if dstInfo.Size() != entry.Info.Size() {
+		return SyncUpdate, nil
+	}
+	if checksum {
+		ha, err := hashStorage(ctx, src, entry.Path)
+		if err != nil {
+			return "", err
+		}
+		hb, err := hashStorage(ctx, dst, target)
+		if err != nil {
+			return "", err
+		}
+		if ha != hb {
+			return SyncUpdate, nil
+		}
+		return "", nil
+	}
+	if dstInfo.ModTime().Unix() != entry.Info.ModTime().Unix() {
+		return SyncUpdate, nil
+	}
+	return "", nil
+}
+
+func hashStorage(ctx context.Context, s storage.Storage, name string) (string, error) {
+	r, err := s.Open(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// deleteExtraneousStorage deleteExtraneous 의 저장소 버전 - 파일 먼저, 빈 디렉토리는 깊은 것부터
+func deleteExtraneousStorage(ctx context.Context, dst storage.Storage, dstDir string, walkOpts WalkOptions, seen map[string]bool, opts SyncOptions, report *SyncReport, notify func(SyncAction)) {
+	remove := func(p string) error { return dst.Delete(ctx, p) }
+	if opts.Trash != nil {
+		remove = func(p string) error {
+			_, err := opts.Trash.Delete(p)
+			return err
+		}
+	}
+
+	var dirs []Entry
+	for entry := range WalkStorage(ctx, dst, dstDir, walkOpts) {
+		if entry.Err != nil || seen[entry.RelPath] {
+			continue
+		}
+		if entry.Info.IsDir() {
+			dirs = append(dirs, entry)
+			continue
+		}
+		action := SyncAction{Kind: SyncDelete, RelPath: entry.RelPath, Size: entry.Info.Size()}
+		if !opts.DryRun {
+			if err := remove(entry.Path); err != nil {
+				action.Kind, action.Err = SyncError, err
+				report.Failed++
+				notify(action)
+				continue
+			}
+		}
+		report.Deleted++
+		notify(action)
+	}
+
+	// 필터로 제외된 파일이 남아 있는 디렉토리는 지우지 않아 (제외 = 보호)
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Depth > dirs[j].Depth })
+	for _, dir := range dirs {
+		if !opts.DryRun {
+			if children, _ := dst.List(ctx, dir.Path); len(children) > 0 {
+				continue
+			}
+			if err := dst.Delete(ctx, dir.Path); err != nil {
+				report.Failed++
+				notify(SyncAction{Kind: SyncError, RelPath: dir.RelPath, Err: err})
+				continue
+			}
+		}
+		report.Deleted++
+		notify(SyncAction{Kind: SyncDelete, RelPath: dir.RelPath})
+	}
+}
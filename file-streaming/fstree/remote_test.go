@@ -0,0 +1,59 @@
+package fstree
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+)
+
+// 로컬 트리를 다른 저장소로 동기화 (원격 대신 메모리 저장소)
+func TestSyncStorage(t *testing.T) {
+	ctx := context.Background()
+	src := t.TempDir()
+	writeTree(t, src, map[string]string{"a.txt": "a", "sub/b.txt": "bb"})
+	dst := storage.NewMemory()
+
+	report, err := SyncStorage(ctx, storage.Local{}, src, dst, "mirror", SyncOptions{})
+	if err != nil || report.Copied != 2 {
+		t.Fatalf("첫 동기화 = %v, %v", report, err)
+	}
+	r, err := dst.Open(ctx, "mirror/sub/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if string(got) != "bb" {
+		t.Errorf("mirror/sub/b.txt = %q", got)
+	}
+
+	// 수정 시각을 옮겨 둬서 다시 돌리면 변경 없음
+	if report, _ = SyncStorage(ctx, storage.Local{}, src, dst, "mirror", SyncOptions{}); report.Unchanged != 2 {
+		t.Errorf("두 번째 동기화 = %v, want 변경 없음 2", report)
+	}
+
+	w, _ := dst.Create(ctx, "mirror/extra.txt")
+	io.WriteString(w, "x")
+	w.Close()
+	report, err = SyncStorage(ctx, storage.Local{}, src, dst, "mirror", SyncOptions{Delete: true})
+	if err != nil || report.Deleted != 1 {
+		t.Errorf("삭제 동기화 = %v, %v", report, err)
+	}
+
+	var rels []string
+	for e := range WalkStorage(ctx, dst, "mirror", WalkOptions{}) {
+		rels = append(rels, e.RelPath)
+	}
+	if want := []string{"a.txt", "sub/b.txt"}; !slices.Equal(rels, want) {
+		t.Errorf("WalkStorage = %v, want %v", rels, want)
+	}
+
+	// 휴지통은 로컬 대상에서만
+	trash, _ := OpenTrash(t.TempDir())
+	if _, err := SyncStorage(ctx, storage.Local{}, src, dst, "mirror", SyncOptions{Delete: true, Trash: trash}); err == nil {
+		t.Error("원격 대상에 휴지통인데 에러가 없음")
+	}
+}
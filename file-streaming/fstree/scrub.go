@@ -0,0 +1,171 @@
+package fstree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// ScrubOptions 검증 스윕 옵션
+type ScrubOptions struct {
+	Walk    WalkOptions // 대상 필터 (사이드카 DB 를 트리 안에 두면 Exclude 에 넣어줘)
+	Workers int         // 병렬 해시 워커 수 (기본: CPU 수)
+
+	// OlderThan 마지막 확인이 이보다 오래된 파일만 다시 읽어 (0 이면 전부)
+	// ⭐ 큰 트리를 매일 조금씩 나눠 확인하려면 주기보다 짧게 돌리면 돼 - 예: 30일 기준으로 매일 돌리면 하루 1/30 씩.
+	OlderThan time.Duration
+
+	// Manifest 기록이 아직 없는 파일은 이 해시(상대 경로 → SHA-256, ReadManifest 결과)와 대조해
+	// 매니페스트를 만든 뒤에 이미 망가진 파일도 첫 스윕에서 잡을 수 있어 (없으면 지금 내용을 그대로 믿고 기록)
+	// 매니페스트 뒤로 일부러 고친 파일도 손상으로 보이니까 매니페스트가 최신일 때만 줘.
+	Manifest map[string]string
+
+	// DryRun 기록을 바꾸지 않고 확인만
+	DryRun bool
+}
+
+// ScrubReport 검증 스윕 결과
+type ScrubReport struct {
+	Verified int      // 다시 읽어서 기록과 같았음
+	Skipped  int      // 최근에 확인해서 건너뜀
+	Added    []string // 기록이 없어서 새로 잼
+	Updated  []string // 기록한 뒤에 고쳐진 파일이라 새로 잼 (손상 아님)
+	Rot      []string // 크기/수정 시각은 그대로인데 내용이 달라짐 = 비트 부패
+	Errors   []string // 읽기/기록 실패
+}
+
+// Clean 손상도 에러도 없는지
+func (r ScrubReport) Clean() bool { return len(r.Rot) == 0 && len(r.Errors) == 0 }
+
+func (r ScrubReport) String() string {
+	return msg.Sprintf("확인 %d, 건너뜀 %d, 새로 기록 %d, 갱신 %d, 손상 %d, 에러 %d",
+		r.Verified, r.Skipped, len(r.Added), len(r.Updated), len(r.Rot), len(r.Errors))
+}
+
+// scrubPlan 해시하기 전에 본 기록과 파일 정보 (해시 결과와 맞춰 보려고)
+type scrubPlan struct {
+	info     fs.FileInfo
+	rec      Checksum
+	known    bool
+	expected string // 기록이 없을 때 매니페스트 해시
+}
+
+// Scrub root 아래 파일들을 다시 읽어서 store 의 기록과 대조 (비트 부패 찾기)
+// ⭐ 고쳐진 파일(크기/수정 시각이 기록과 다름)은 새로 재서 기록을 갱신하고, 그대로인데 해시만 다른 파일만 손상으로 알려.
+// 손상된 파일의 기록은 덮어쓰지 않아 - 백업에서 되살린 뒤 다시 돌리면 정상으로 돌아와.
+func Scrub(ctx context.Context, root string, store ChecksumStore, opts ScrubOptions) (ScrubReport, error) {
+	var report ScrubReport
+	var mu sync.Mutex
+	plans := make(map[string]scrubPlan)
+
+	// 순회하면서 기록을 보고 다시 읽을 파일만 해시 워커로 넘겨
+	toHash := make(chan Entry)
+	go func() {
+		defer close(toHash)
+		for e := range Walk(ctx, root, opts.Walk) {
+			if e.Err == nil {
+				rec, known, err := store.Get(e.Path)
+				switch {
+				case err != nil:
+					e.Err = err
+				case known && !rec.Stale(e.Info) && opts.OlderThan > 0 && time.Since(rec.Verified) < opts.OlderThan:
+					mu.Lock()
+					report.Skipped++
+					mu.Unlock()
+					continue
+				default:
+					mu.Lock()
+					plans[e.RelPath] = scrubPlan{info: e.Info, rec: rec, known: known, expected: opts.Manifest[e.RelPath]}
+					mu.Unlock()
+				}
+			}
+			select {
+			case toHash <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var fatal error
+	for r := range hashEntries(toHash, opts.workers()) {
+		if errors.Is(r.err, ErrXattrUnsupported) {
+			fatal = r.err
+			continue
+		}
+		if r.err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", r.rel, r.err))
+			continue
+		}
+		mu.Lock()
+		p := plans[r.rel]
+		delete(plans, r.rel)
+		mu.Unlock()
+		path := filepath.Join(root, filepath.FromSlash(r.rel))
+
+		var rot, changed bool
+		switch {
+		case !p.known:
+			rot = p.expected != "" && p.expected != r.sum
+		case p.rec.Stale(p.info):
+			changed = true
+		default:
+			rot = p.rec.SHA256 != r.sum
+		}
+		if rot && modifiedSince(path, p.info) {
+			// 해시하는 동안 누가 고쳤어 - 손상이 아니라 다음 스윕에서 새로 재
+			report.Updated = append(report.Updated, r.rel)
+			continue
+		}
+
+		switch {
+		case rot:
+			report.Rot = append(report.Rot, r.rel)
+			continue
+		case changed:
+			report.Updated = append(report.Updated, r.rel)
+		case !p.known && p.expected == "":
+			report.Added = append(report.Added, r.rel)
+		default:
+			report.Verified++
+		}
+		if opts.DryRun || fatal != nil {
+			continue
+		}
+		c := Checksum{SHA256: r.sum, Size: p.info.Size(), ModTime: p.info.ModTime(), Verified: time.Now()}
+		if err := store.Put(path, c); err != nil {
+			if errors.Is(err, ErrXattrUnsupported) {
+				fatal = err // 파일마다 같은 에러를 쌓지 않게 - 남은 결과만 받아 두고 끝내
+				continue
+			}
+			report.Errors = append(report.Errors, msg.Sprintf("%s: 기록 실패: %v", r.rel, err))
+		}
+	}
+
+	sort.Strings(report.Added)
+	sort.Strings(report.Updated)
+	sort.Strings(report.Rot)
+	sort.Strings(report.Errors)
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, fatal
+}
+
+// modifiedSince 해시를 시작한 뒤로 파일이 바뀌었는지
+func modifiedSince(path string, before fs.FileInfo) bool {
+	info, err := os.Stat(path)
+	return err != nil || info.Size() != before.Size() || !info.ModTime().Equal(before.ModTime())
+}
+
+func (o ScrubOptions) workers() int {
+	return ManifestOptions{Workers: o.Workers}.workers()
+}
@@ -0,0 +1,61 @@
+package fstree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScrubFindsBitRot(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a", "hello")
+	write("b", "world")
+	write("c", "keep")
+	dbPath := filepath.Join(t.TempDir(), "sums.json")
+	db, err := OpenChecksumDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Scrub(context.Background(), root, db, ScrubOptions{})
+	if err != nil || len(report.Added) != 3 {
+		t.Fatalf("첫 스윕 = %v, %v - 셋 다 새로 기록해야 해", report, err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a 는 수정 시각을 그대로 두고 내용만 바꿔(비트 부패), b 는 정상적으로 고쳐
+	a := filepath.Join(root, "a")
+	before, _ := os.Stat(a)
+	write("a", "jello")
+	os.Chtimes(a, before.ModTime(), before.ModTime())
+	write("b", "world!")
+
+	db, _ = OpenChecksumDB(dbPath)
+	report, err = Scrub(context.Background(), root, db, ScrubOptions{OlderThan: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Skipped != 2 || len(report.Updated) != 1 || report.Updated[0] != "b" {
+		t.Errorf("최근에 확인한 파일 = %v, want 건너뜀 2 (a, c) 갱신 1 (b)", report)
+	}
+
+	report, _ = Scrub(context.Background(), root, db, ScrubOptions{})
+	if len(report.Rot) != 1 || report.Rot[0] != "a" || report.Verified != 2 || report.Clean() {
+		t.Errorf("전체 스윕 = %v (손상 %v), want a 만 손상", report, report.Rot)
+	}
+	// 손상된 파일의 기록은 그대로 남아서 다음에도 손상으로 보여
+	if c, _, _ := db.Get(a); c.SHA256 == "" {
+		t.Error("손상된 파일의 기록이 사라짐")
+	}
+	if report, _ = Scrub(context.Background(), root, db, ScrubOptions{}); len(report.Rot) != 1 {
+		t.Errorf("두 번째 스윕에서 손상을 놓침: %v", report)
+	}
+}
@@ -0,0 +1,391 @@
+package fstree
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// SyncOptions 동기화 옵션
+type SyncOptions struct {
+	Walk     WalkOptions // 대상 필터 (양쪽 트리에 똑같이 적용, Walk.Symlinks 로 링크 처리 방식 선택)
+	Checksum bool        // 크기+수정시각 대신 내용 해시로 비교 (느리지만 정확)
+	Delete   bool        // 원본에 없는 파일/디렉토리를 대상에서 삭제
+	Trash    *Trash      // Delete 때 영구 삭제 대신 휴지통으로 옮김 (nil 이면 os.Remove)
+	DryRun   bool        // 실제로 바꾸지 않고 무엇을 할지만 보고
+
+	// Preserve 추가로 복제할 메타데이터 (권한/수정시각은 항상 복제해 - 다음 비교에 필요하니까)
+	Preserve streamio.Metadata
+	Hooks    streamio.Hooks
+
+	// Sparse 원본의 구멍을 대상에서도 구멍으로 유지 (VM 이미지, DB 파일 등)
+	Sparse bool
+
+	// HardLinks 원본에서 같은 inode 를 가리키는 파일들을 대상에서도 하드링크로 만들어
+	// 끄면 링크마다 내용을 따로 복사해서 디스크를 그만큼 더 써
+	HardLinks bool
+
+	BufferSize int   // 파일 복사 버퍼 크기 (0 이면 streamio.DefaultBufferSize)
+	RateLimit  int64 // 초당 최대 복사 바이트 (0 이면 제한 없음)
+
+	// OnAction 파일 하나를 처리할 때마다 호출 (진행 상황 출력용, nil 가능)
+	OnAction func(SyncAction)
+}
+
+// SyncActionKind 파일별 처리 결과 종류
+type SyncActionKind string
+
+const (
+	SyncCopy   SyncActionKind = "copy"   // 대상에 없어서 새로 복사
+	SyncUpdate SyncActionKind = "update" // 바뀌어서 다시 복사
+	SyncDelete SyncActionKind = "delete" // 원본에 없어서 삭제
+	SyncLink   SyncActionKind = "link"   // 앞서 복사한 파일의 하드링크로 생성
+	SyncError  SyncActionKind = "error"
+)
+
+// SyncAction 파일 하나에 대한 처리
+type SyncAction struct {
+	Kind    SyncActionKind
+	RelPath string
+	Size    int64
+	Err     error
+}
+
+// SyncReport 동기화 요약
+type SyncReport struct {
+	Copied      int
+	Updated     int
+	Deleted     int
+	Linked      int
+	Unchanged   int
+	Failed      int
+	BytesCopied int64
+	DryRun      bool
+}
+
+func (r SyncReport) String() string {
+	prefix := ""
+	if r.DryRun {
+		prefix = "(dry-run) "
+	}
+	return msg.Sprintf("%s복사 %d, 갱신 %d, 삭제 %d, 하드링크 %d, 변경 없음 %d, 실패 %d, 전송 %d 바이트",
+		prefix, r.Copied, r.Updated, r.Deleted, r.Linked, r.Unchanged, r.Failed, r.BytesCopied)
+}
+
+// Sync srcDir 의 내용을 dstDir 에 맞춰 (rsync -a 와 비슷)
+// ⭐ 원본 트리는 Walk 로 스트리밍하면서 바로 비교/복사하고, 비교 결과 같은 파일은 건드리지 않아.
+// 파일 단위 에러는 보고서에 세고 계속 진행하고, ctx 취소만 중단 사유로 돌려줘.
+func Sync(ctx context.Context, srcDir, dstDir string, opts SyncOptions) (SyncReport, error) {
+	report := SyncReport{DryRun: opts.DryRun}
+	notify := func(a SyncAction) {
+		if opts.OnAction != nil {
+			opts.OnAction(a)
+		}
+	}
+
+	copyOpts := streamio.CopyOptions{
+		Hooks:    opts.Hooks,
+		Preserve: opts.Preserve | streamio.PreserveMode | streamio.PreserveTimes,
+		Sparse:   opts.Sparse,
+
+		BufferSize: opts.BufferSize,
+		RateLimit:  opts.RateLimit,
+	}
+
+	if !opts.DryRun {
+		if err := os.MkdirAll(dstDir, 0755); err != nil {
+			return report, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	links := make(map[fileID]string) // inode → 처음 복사한 대상 경로
+	walkOpts := opts.Walk
+	walkOpts.IncludeDirs = true
+
+	for entry := range Walk(ctx, srcDir, walkOpts) {
+		if entry.Err != nil {
+			report.Failed++
+			notify(SyncAction{Kind: SyncError, RelPath: entry.RelPath, Err: entry.Err})
+			continue
+		}
+		seen[entry.RelPath] = true
+		dst := filepath.Join(dstDir, filepath.FromSlash(entry.RelPath))
+
+		if entry.Info.IsDir() {
+			if !opts.DryRun {
+				os.MkdirAll(dst, 0755)
+			}
+			continue
+		}
+
+		// 같은 프로세스의 다른 쓰기(업로드 등)와 겹치지 않게 대상 경로를 잠가
+		unlock := streamio.LockPath(dst)
+
+		// 링크는 sync 함수가 직접 만들고 (dry-run 이면 판단만), 일반 파일은 아래에서 CopyFile
+		var kind SyncActionKind
+		var err error
+		handled := true
+		if entry.Info.Mode()&fs.ModeSymlink != 0 {
+			kind, err = syncSymlink(entry.Path, dst, opts.DryRun)
+		} else if first, ok := firstLink(links, entry, dst, opts.HardLinks); ok {
+			kind, err = syncHardLink(first, dst, opts.DryRun)
+		} else {
+			kind, err = compareForSync(entry, dst, opts.Checksum)
+			handled = false
+		}
+		if err != nil {
+			unlock()
+			report.Failed++
+			notify(SyncAction{Kind: SyncError, RelPath: entry.RelPath, Err: err})
+			continue
+		}
+		if kind == "" {
+			unlock()
+			report.Unchanged++
+			continue
+		}
+
+		action := SyncAction{Kind: kind, RelPath: entry.RelPath, Size: entry.Info.Size()}
+		if kind == SyncLink {
+			action.Size = 0
+		}
+		if !opts.DryRun && !handled {
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				action.Kind, action.Err = SyncError, err
+			} else if _, err := streamio.CopyFile(ctx, entry.Path, dst, copyOpts); err != nil {
+				action.Kind, action.Err = SyncError, err
+			}
+		}
+		unlock()
+
+		switch action.Kind {
+		case SyncCopy:
+			report.Copied++
+			report.BytesCopied += action.Size
+		case SyncUpdate:
+			report.Updated++
+			report.BytesCopied += action.Size
+		case SyncLink:
+			report.Linked++
+		case SyncError:
+			report.Failed++
+		}
+		notify(action)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	if opts.Delete {
+		// 대상 쪽 링크는 따라가지 않고 링크 자체만 지워야 해 (따라가면 링크 밖 파일을 지울 수 있어)
+		if walkOpts.Symlinks == SymlinkFollow {
+			walkOpts.Symlinks = SymlinkCopy
+		}
+		// 휴지통이 대상 트리 안에 있으면 그 안은 지울 대상이 아니야
+		if opts.Trash != nil {
+			absDst, _ := filepath.Abs(dstDir)
+			if rel, err := filepath.Rel(absDst, opts.Trash.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+				walkOpts.Exclude = append(walkOpts.Exclude[:len(walkOpts.Exclude):len(walkOpts.Exclude)], filepath.ToSlash(rel))
+			}
+		}
+		deleteExtraneous(ctx, dstDir, walkOpts, seen, opts.DryRun, opts.Trash, &report, notify)
+	}
+
+	return report, ctx.Err()
+}
+
+// compareForSync 대상 파일과 비교해서 복사가 필요하면 종류를, 같으면 "" 를 돌려줘
+func compareForSync(entry Entry, dst string, checksum bool) (SyncActionKind, error) {
+	dstInfo, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return SyncCopy, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if dstInfo.IsDir() {
+		return "", msg.Errorf("대상이 디렉토리입니다: %s", dst)
+	}
+	if !dstInfo.Mode().IsRegular() {
+		// 대상이 링크 등이면 일반 파일로 바꿔 (rename 이 링크 자체를 교체해)
+		return SyncUpdate, nil
+	}
+
+	if dstInfo.Size() != entry.Info.Size() {
+		return SyncUpdate, nil
+	}
+	if checksum {
+		same, err := sameContent(entry.Path, dst)
+		if err != nil {
+			return "", err
+		}
+		if !same {
+			return SyncUpdate, nil
+		}
+		return "", nil
+	}
+	if !dstInfo.ModTime().Equal(entry.Info.ModTime()) {
+		return SyncUpdate, nil
+	}
+	return "", nil
+}
+
+// syncSymlink 대상에 같은 내용(가리키는 경로)의 심볼릭 링크를 만들어
+// ⭐ 링크 대상 경로는 그대로 복사해 - 상대 경로 링크면 대상 트리 안에서도 똑같이 동작해.
+func syncSymlink(src, dst string, dryRun bool) (SyncActionKind, error) {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return "", err
+	}
+
+	kind := SyncCopy
+	if current, err := os.Readlink(dst); err == nil {
+		if current == target {
+			return "", nil
+		}
+		kind = SyncUpdate
+	} else if _, err := os.Lstat(dst); err == nil {
+		kind = SyncUpdate
+	}
+
+	if dryRun {
+		return kind, nil
+	}
+	return kind, replaceWith(dst, func(tmp string) error { return os.Symlink(target, tmp) })
+}
+
+// firstLink 이미 복사한 inode 면 그 대상 경로를, 처음 보는 inode 면 기록만 하고 false
+func firstLink(links map[fileID]string, entry Entry, dst string, enabled bool) (string, bool) {
+	if !enabled {
+		return "", false
+	}
+	id, ok := hardLinkID(entry.Info)
+	if !ok {
+		return "", false
+	}
+	if first, seen := links[id]; seen {
+		return first, true
+	}
+	links[id] = dst
+	return "", false
+}
+
+// syncHardLink dst 를 first 의 하드링크로 만들어 (이미 같은 파일이면 그대로)
+func syncHardLink(first, dst string, dryRun bool) (SyncActionKind, error) {
+	if dstInfo, err := os.Lstat(dst); err == nil {
+		if firstInfo, err := os.Lstat(first); err == nil && os.SameFile(firstInfo, dstInfo) {
+			return "", nil
+		}
+	}
+
+	if dryRun {
+		return SyncLink, nil
+	}
+	return SyncLink, replaceWith(dst, func(tmp string) error { return os.Link(first, tmp) })
+}
+
+// replaceWith 임시 이름으로 만든 뒤 rename 해서 dst 를 원자적으로 교체
+func replaceWith(dst string, create func(tmp string) error) error {
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".link-tmp")
+	os.Remove(tmp)
+	if err := create(tmp); err != nil {
+		return err
+	}
+	if err := streamio.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// deleteExtraneous 원본에 없는 대상 파일 삭제 - 파일 먼저, 디렉토리는 깊은 것부터
+func deleteExtraneous(ctx context.Context, dstDir string, walkOpts WalkOptions, seen map[string]bool, dryRun bool, trash *Trash, report *SyncReport, notify func(SyncAction)) {
+	var dirs []Entry
+	for entry := range Walk(ctx, dstDir, walkOpts) {
+		if entry.Err != nil || seen[entry.RelPath] {
+			continue
+		}
+		if entry.Info.IsDir() {
+			dirs = append(dirs, entry)
+			continue
+		}
+		deleteEntry(entry, dryRun, trash, report, notify)
+	}
+
+	// 필터로 제외된 파일이 남아 있는 디렉토리는 지우지 않아 (제외 = 보호)
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Depth > dirs[j].Depth })
+	for _, dir := range dirs {
+		if !dryRun {
+			if children, _ := os.ReadDir(dir.Path); len(children) > 0 {
+				continue
+			}
+		}
+		deleteEntry(dir, dryRun, nil, report, notify)
+	}
+}
+
+// deleteEntry 파일은 휴지통이 있으면 그리로 옮기고, 빈 디렉토리는 그냥 지워 (되살릴 내용이 없으니까)
+func deleteEntry(entry Entry, dryRun bool, trash *Trash, report *SyncReport, notify func(SyncAction)) {
+	action := SyncAction{Kind: SyncDelete, RelPath: entry.RelPath}
+	if !entry.Info.IsDir() {
+		action.Size = entry.Info.Size()
+	}
+	if !dryRun {
+		remove := os.Remove
+		if trash != nil {
+			remove = func(path string) error {
+				_, err := trash.Delete(path)
+				return err
+			}
+		}
+		unlock := streamio.LockPath(entry.Path)
+		err := remove(entry.Path)
+		unlock()
+		if err != nil {
+			action.Kind, action.Err = SyncError, err
+			report.Failed++
+			notify(action)
+			return
+		}
+	}
+	report.Deleted++
+	notify(action)
+}
+
+// sameContent 두 파일을 SHA-256 으로 비교
+func sameContent(a, b string) (bool, error) {
+	ha, err := hashFile(a)
+	if err != nil {
+		return false, err
+	}
+	hb, err := hashFile(b)
+	if err != nil {
+		return false, err
+	}
+	return ha == hb, nil
+}
+
+func hashFile(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,151 @@
+package fstree
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// readTree root 아래 일반 파일의 상대 경로 → 내용
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	for e := range Walk(t.Context(), root, WalkOptions{}) {
+		if e.Err != nil {
+			t.Fatal(e.Err)
+		}
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[e.RelPath] = string(data)
+	}
+	return out
+}
+
+func TestSync(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "mirror")
+	writeTree(t, src, map[string]string{"a.txt": "a", "sub/b.txt": "bb", "sub/deep/c.txt": "ccc"})
+
+	report, err := Sync(t.Context(), src, dst, SyncOptions{})
+	if err != nil || report.Copied != 3 || report.BytesCopied != 6 {
+		t.Fatalf("첫 동기화 = %v, %v", report, err)
+	}
+	if got := readTree(t, dst); len(got) != 3 || got["sub/deep/c.txt"] != "ccc" {
+		t.Fatalf("대상 = %v", got)
+	}
+	// 수정 시각까지 복제해서 다시 돌리면 아무것도 안 해
+	if report, _ = Sync(t.Context(), src, dst, SyncOptions{}); report.Unchanged != 3 || report.Copied+report.Updated != 0 {
+		t.Errorf("두 번째 동기화 = %v, want 변경 없음 3", report)
+	}
+
+	// 하나 고치고 하나 지우고, 대상에만 있는 파일도 하나
+	writeTree(t, src, map[string]string{"a.txt": "a2"})
+	os.Remove(filepath.Join(src, "sub", "b.txt"))
+	writeTree(t, dst, map[string]string{"extra.txt": "x"})
+
+	// dry-run 은 보고만 하고 안 바꿔
+	var actions []string
+	report, err = Sync(t.Context(), src, dst, SyncOptions{Delete: true, DryRun: true, OnAction: func(a SyncAction) {
+		actions = append(actions, string(a.Kind)+" "+a.RelPath)
+	}})
+	slices.Sort(actions)
+	if want := []string{"delete extra.txt", "delete sub/b.txt", "update a.txt"}; err != nil || !slices.Equal(actions, want) {
+		t.Errorf("dry-run 처리 = %v, %v, want %v", actions, err, want)
+	}
+	if got := readTree(t, dst); got["a.txt"] != "a" || got["extra.txt"] != "x" {
+		t.Errorf("dry-run 인데 대상이 바뀜: %v", got)
+	}
+
+	report, err = Sync(t.Context(), src, dst, SyncOptions{Delete: true})
+	if err != nil || report.Updated != 1 || report.Deleted != 2 {
+		t.Errorf("삭제 동기화 = %v, %v", report, err)
+	}
+	if got, want := readTree(t, dst), readTree(t, src); !mapsEqual(got, want) {
+		t.Errorf("대상 = %v, want %v", got, want)
+	}
+}
+
+// 크기와 수정 시각이 같아도 Checksum 이면 내용이 바뀐 걸 찾아
+func TestSyncChecksum(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{"a.txt": "same"})
+	if _, err := Sync(t.Context(), src, dst, SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dst, "a.txt")
+	info, _ := os.Stat(target)
+	os.WriteFile(target, []byte("diff"), 0644)
+	os.Chtimes(target, time.Now(), info.ModTime())
+
+	if report, _ := Sync(t.Context(), src, dst, SyncOptions{}); report.Unchanged != 1 {
+		t.Errorf("크기+시각 비교 = %v, 못 알아채야 해", report)
+	}
+	if report, _ := Sync(t.Context(), src, dst, SyncOptions{Checksum: true}); report.Updated != 1 {
+		t.Errorf("Checksum 비교 = %v, want 갱신 1", report)
+	}
+	if got, _ := os.ReadFile(target); string(got) != "same" {
+		t.Errorf("대상 = %q", got)
+	}
+}
+
+func mapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSyncLinks(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{"a.bin": "shared"})
+	if err := os.Link(filepath.Join(src, "a.bin"), filepath.Join(src, "b.bin")); err != nil {
+		t.Skip("하드링크를 만들 수 없음:", err)
+	}
+	symlink(t, "a.bin", filepath.Join(src, "l"))
+
+	// 대체 구현(streamio_portable)은 inode 를 몰라서 하드링크도 따로 복사돼
+	links := !streamio.Portable()
+	wantCopied, wantLinked := 2, 1
+	if !links {
+		wantCopied, wantLinked = 3, 0
+	}
+
+	opts := SyncOptions{HardLinks: true, Walk: WalkOptions{Symlinks: SymlinkCopy}}
+	report, err := Sync(t.Context(), src, dst, opts)
+	if err != nil || report.Copied != wantCopied || report.Linked != wantLinked {
+		t.Fatalf("첫 동기화 = %v, %v, want 복사 %d, 하드링크 %d", report, err, wantCopied, wantLinked)
+	}
+	a, _ := os.Stat(filepath.Join(dst, "a.bin"))
+	b, _ := os.Stat(filepath.Join(dst, "b.bin"))
+	if os.SameFile(a, b) != links {
+		t.Errorf("대상의 a.bin, b.bin 하드링크 = %v, want %v", os.SameFile(a, b), links)
+	}
+	if target, err := os.Readlink(filepath.Join(dst, "l")); err != nil || target != "a.bin" {
+		t.Errorf("대상 링크 = %q, %v, want a.bin", target, err)
+	}
+
+	if report, _ = Sync(t.Context(), src, dst, opts); report.Unchanged != 3 {
+		t.Errorf("두 번째 동기화 = %v, want 변경 없음 3", report)
+	}
+
+	// HardLinks 를 끄면 따로 복사돼
+	plain := t.TempDir()
+	if report, _ = Sync(t.Context(), src, plain, SyncOptions{}); report.Linked != 0 {
+		t.Errorf("HardLinks 없이 = %v", report)
+	}
+	a, _ = os.Stat(filepath.Join(plain, "a.bin"))
+	b, _ = os.Stat(filepath.Join(plain, "b.bin"))
+	if os.SameFile(a, b) {
+		t.Error("HardLinks 가 꺼져 있는데 하드링크가 됨")
+	}
+}
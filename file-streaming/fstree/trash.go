@@ -0,0 +1,206 @@
+package fstree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Trash 되돌릴 수 있는 삭제 - 지운 파일을 휴지통 디렉토리로 옮기고 원래 위치를 기록해 둬
+//
+//	<Dir>/files/<ID>       옮겨진 파일/디렉토리
+//	<Dir>/info/<ID>.json   원래 경로, 삭제 시각 (사이드카)
+//
+// ⭐ os.Remove 는 되돌릴 수 없으니, 동기화 --delete 나 HTTP 삭제 API 처럼
+// 실수 한 번에 데이터가 날아가는 곳은 Trash 를 거치게 해.
+type Trash struct {
+	Dir string
+}
+
+// TrashItem 휴지통 항목 하나 (사이드카 JSON 내용)
+type TrashItem struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"original_path"` // 절대 경로
+	DeletedAt    time.Time `json:"deleted_at"`
+	Size         int64     `json:"size"`
+	IsDir        bool      `json:"is_dir"`
+}
+
+// ErrRestoreConflict 복원할 위치에 이미 다른 파일이 있음
+var ErrRestoreConflict = msg.New("복원할 위치에 이미 파일이 있습니다")
+
+// OpenTrash 휴지통 디렉토리 준비 (없으면 생성)
+func OpenTrash(dir string) (*Trash, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	t := &Trash{Dir: abs}
+	for _, sub := range []string{t.filesDir(), t.infoDir()} {
+		if err := os.MkdirAll(sub, 0700); err != nil {
+			return nil, msg.Errorf("휴지통 생성 실패: %w", err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Trash) filesDir() string { return filepath.Join(t.Dir, "files") }
+func (t *Trash) infoDir() string  { return filepath.Join(t.Dir, "info") }
+
+// Delete path 를 휴지통으로 옮겨
+// 사이드카를 먼저 쓰고 옮겨서, 중간에 죽어도 "어디서 왔는지 모르는 파일" 은 생기지 않아.
+func (t *Trash) Delete(path string) (TrashItem, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return TrashItem{}, err
+	}
+
+	item := TrashItem{
+		ID:           fmt.Sprintf("%s_%s", time.Now().UTC().Format("20060102T150405.000000000"), filepath.Base(abs)),
+		OriginalPath: abs,
+		DeletedAt:    time.Now(),
+		Size:         info.Size(),
+		IsDir:        info.IsDir(),
+	}
+	if err := t.writeInfo(item); err != nil {
+		return item, err
+	}
+
+	dst := filepath.Join(t.filesDir(), item.ID)
+	if info.Mode().IsRegular() {
+		// 휴지통이 다른 파일시스템에 있어도 옮길 수 있게 Move 사용 (rename 실패 시 복사+검증)
+		err = streamio.Move(context.Background(), abs, dst, streamio.CopyOptions{})
+	} else {
+		err = streamio.Rename(abs, dst)
+	}
+	if err != nil {
+		os.Remove(t.infoPath(item.ID))
+		return item, msg.Errorf("휴지통으로 옮기기 실패: %w", err)
+	}
+	return item, nil
+}
+
+// List 휴지통 항목 (최근에 지운 것부터)
+func (t *Trash) List() ([]TrashItem, error) {
+	entries, err := os.ReadDir(t.infoDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var items []TrashItem
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		item, err := t.readInfo(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// Item id 의 사이드카 (원래 경로, 삭제 시각) - 없으면 fs.ErrNotExist 를 감싼 에러
+func (t *Trash) Item(id string) (TrashItem, error) {
+	return t.readInfo(id)
+}
+
+// Restore 항목을 원래 위치로 되돌려 - 그 자리에 이미 뭔가 있으면 ErrRestoreConflict
+func (t *Trash) Restore(id string) (TrashItem, error) {
+	item, err := t.readInfo(id)
+	if err != nil {
+		return item, err
+	}
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return item, fmt.Errorf("%s: %w", item.OriginalPath, ErrRestoreConflict)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return item, err
+	}
+
+	src := filepath.Join(t.filesDir(), id)
+	if item.IsDir {
+		err = streamio.Rename(src, item.OriginalPath)
+	} else {
+		err = streamio.Move(context.Background(), src, item.OriginalPath, streamio.CopyOptions{})
+	}
+	if err != nil {
+		return item, msg.Errorf("복원 실패: %w", err)
+	}
+	return item, os.Remove(t.infoPath(id))
+}
+
+// Purge olderThan 보다 오래전에 지운 항목을 영구 삭제 (0 이면 전부)
+func (t *Trash) Purge(olderThan time.Duration) (int, error) {
+	items, err := t.List()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+	for _, item := range items {
+		if olderThan > 0 && item.DeletedAt.After(cutoff) {
+			continue
+		}
+		if err := t.Discard(item.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Discard 항목 하나를 영구 삭제 - 파일을 먼저 지우고 사이드카를 지워
+func (t *Trash) Discard(id string) error {
+	if id != filepath.Base(id) || id == "." || id == ".." {
+		return msg.Errorf("잘못된 휴지통 ID: %q", id)
+	}
+	if err := os.RemoveAll(filepath.Join(t.filesDir(), id)); err != nil {
+		return err
+	}
+	return os.Remove(t.infoPath(id))
+}
+
+func (t *Trash) infoPath(id string) string {
+	return filepath.Join(t.infoDir(), id+".json")
+}
+
+func (t *Trash) writeInfo(item TrashItem) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.infoPath(item.ID), append(data, '\n'), 0600)
+}
+
+func (t *Trash) readInfo(id string) (TrashItem, error) {
+	var item TrashItem
+	// id 는 사용자 입력일 수 있으니 경로 조작 방지
+	if id != filepath.Base(id) || id == "." || id == ".." {
+		return item, msg.Errorf("잘못된 휴지통 ID: %q", id)
+	}
+	data, err := os.ReadFile(t.infoPath(id))
+	if err != nil {
+		return item, msg.Errorf("휴지통 항목 읽기 실패: %w", err)
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, msg.Errorf("휴지통 항목 파싱 실패: %w", err)
+	}
+	return item, nil
+}
@@ -0,0 +1,118 @@
+package fstree
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.txt": "aaa", "dir/b.txt": "b"})
+	trash, err := OpenTrash(filepath.Join(t.TempDir(), "trash"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := trash.Delete(filepath.Join(root, "a.txt"))
+	if err != nil || file.Size != 3 || file.IsDir {
+		t.Fatalf("Delete(a.txt) = %+v, %v", file, err)
+	}
+	dir, err := trash.Delete(filepath.Join(root, "dir"))
+	if err != nil || !dir.IsDir {
+		t.Fatalf("Delete(dir) = %+v, %v", dir, err)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 0 {
+		t.Errorf("지웠는데 남아 있음: %v", entries)
+	}
+	// 최근에 지운 것부터
+	items, err := trash.List()
+	if err != nil || len(items) != 2 || items[0].ID != dir.ID || items[1].ID != file.ID {
+		t.Fatalf("List = %+v, %v", items, err)
+	}
+
+	if _, err := trash.Restore(dir.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "dir", "b.txt")); string(got) != "b" {
+		t.Errorf("되살린 dir/b.txt = %q", got)
+	}
+
+	// 같은 자리에 새 파일이 생겼으면 되살리지 않아
+	writeTree(t, root, map[string]string{"a.txt": "new"})
+	if _, err := trash.Restore(file.ID); !errors.Is(err, ErrRestoreConflict) {
+		t.Errorf("충돌 Restore = %v, want ErrRestoreConflict", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(got) != "new" {
+		t.Errorf("충돌인데 덮어씀: %q", got)
+	}
+
+	if _, err := trash.Item("../a.txt"); err == nil {
+		t.Error("경로가 섞인 ID 인데 에러가 없음")
+	}
+	if _, err := trash.Item("nope"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("없는 ID = %v, want ErrNotExist", err)
+	}
+}
+
+func TestTrashPurge(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"old.txt": "o", "new.txt": "n"})
+	trash, err := OpenTrash(filepath.Join(t.TempDir(), "trash"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := trash.Delete(filepath.Join(root, "old.txt"))
+	trash.Delete(filepath.Join(root, "new.txt"))
+
+	// 사이드카의 삭제 시각을 하루 전으로
+	old.DeletedAt = time.Now().Add(-24 * time.Hour)
+	if err := trash.writeInfo(old); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := trash.Purge(time.Hour); err != nil || n != 1 {
+		t.Fatalf("Purge(1h) = %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(trash.filesDir(), old.ID)); !os.IsNotExist(err) {
+		t.Errorf("영구 삭제된 파일이 남아 있음: %v", err)
+	}
+	if items, _ := trash.List(); len(items) != 1 || items[0].OriginalPath != filepath.Join(root, "new.txt") {
+		t.Errorf("남은 항목 = %+v", items)
+	}
+	if n, err := trash.Purge(0); err != nil || n != 1 {
+		t.Errorf("Purge(0) = %d, %v", n, err)
+	}
+}
+
+// Delete 에 Trash 를 주면 지우는 대신 휴지통으로 옮겨
+func TestSyncTrash(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, dst, map[string]string{"extra.txt": "keep me"})
+	trash, err := OpenTrash(filepath.Join(dst, ".trash"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Sync(t.Context(), src, dst, SyncOptions{Delete: true, Trash: trash})
+	if err != nil || report.Deleted != 1 {
+		t.Fatalf("Sync = %v, %v", report, err)
+	}
+	items, _ := trash.List()
+	if len(items) != 1 || items[0].OriginalPath != filepath.Join(dst, "extra.txt") {
+		t.Fatalf("휴지통 = %+v", items)
+	}
+	// 대상 안의 휴지통은 삭제 대상이 아니야
+	if _, err := os.Stat(trash.Dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trash.Restore(items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "extra.txt")); string(got) != "keep me" {
+		t.Errorf("되살린 파일 = %q", got)
+	}
+}
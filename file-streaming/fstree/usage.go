@@ -0,0 +1,160 @@
+package fstree
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"strings"
+)
+
+// DirUsage 디렉토리 하나의 사용량 (하위 전체 포함)
+type DirUsage struct {
+	Path      string `json:"path"`
+	RelPath   string `json:"rel_path"`
+	Depth     int    `json:"depth"`
+	Size      int64  `json:"size"`      // 논리 크기 합 (ls -l 기준)
+	Allocated int64  `json:"allocated"` // 실제 할당 크기 합 (du 기준, sparse 파일이면 더 작아)
+	Files     int    `json:"files"`
+	Dirs      int    `json:"dirs"` // 하위 디렉토리 수 (자기 자신 제외)
+}
+
+// FileUsage 큰 파일 순위용
+type FileUsage struct {
+	Path    string `json:"path"`
+	RelPath string `json:"rel_path"`
+	Size    int64  `json:"size"`
+}
+
+// UsageOptions 사용량 분석 옵션
+type UsageOptions struct {
+	Walk WalkOptions
+	TopN int // 가장 큰 파일/디렉토리 몇 개를 남길지 (기본 10)
+
+	// OnDir 디렉토리 하나의 집계가 끝날 때마다 호출 (하위 디렉토리가 항상 부모보다 먼저 와)
+	OnDir func(DirUsage)
+}
+
+// UsageReport 전체 결과
+type UsageReport struct {
+	Total    DirUsage    `json:"total"`
+	TopFiles []FileUsage `json:"top_files"`
+	TopDirs  []DirUsage  `json:"top_dirs"`
+	Errors   int         `json:"errors"`
+}
+
+// DiskUsage du 처럼 트리의 디렉토리별 크기/파일 수를 집계
+// ⭐ Walk 는 사전순 깊이 우선이라, 다음 Entry 가 어떤 디렉토리 밖으로 나가는 순간
+// 그 디렉토리는 다 센 거야. 열린 디렉토리를 스택으로 들고 있다가 그때 OnDir 로 내보내고
+// 부모에 합쳐서, 트리 전체를 메모리에 올리지 않고도 결과를 바로바로 흘려보낼 수 있어.
+// 하드링크는 한 번만 세 (du 와 같음).
+func DiskUsage(ctx context.Context, root string, opts UsageOptions) (UsageReport, error) {
+	if opts.TopN <= 0 {
+		opts.TopN = 10
+	}
+
+	var report UsageReport
+	topFiles := &sizeHeap[FileUsage]{size: func(f FileUsage) int64 { return f.Size }}
+	topDirs := &sizeHeap[DirUsage]{size: func(d DirUsage) int64 { return d.Size }}
+
+	stack := []DirUsage{{Path: root, RelPath: "."}}
+	// finish 스택 맨 위 디렉토리를 닫고 부모에 합쳐
+	finish := func() {
+		done := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if opts.OnDir != nil {
+			opts.OnDir(done)
+		}
+		if len(stack) == 0 {
+			report.Total = done
+			return
+		}
+		topDirs.push(done, opts.TopN)
+
+		parent := &stack[len(stack)-1]
+		parent.Size += done.Size
+		parent.Allocated += done.Allocated
+		parent.Files += done.Files
+		parent.Dirs += done.Dirs + 1
+	}
+
+	walkOpts := opts.Walk
+	walkOpts.IncludeDirs = true
+	links := make(map[fileID]bool)
+
+	for entry := range Walk(ctx, root, walkOpts) {
+		if entry.Err != nil {
+			report.Errors++
+			continue
+		}
+
+		// entry 의 조상이 아닌 디렉토리는 다 끝났어
+		for len(stack) > 1 && !isUnder(entry.RelPath, stack[len(stack)-1].RelPath) {
+			finish()
+		}
+
+		if entry.Info.IsDir() {
+			stack = append(stack, DirUsage{Path: entry.Path, RelPath: entry.RelPath, Depth: entry.Depth})
+			continue
+		}
+
+		if id, ok := hardLinkID(entry.Info); ok {
+			if links[id] {
+				continue
+			}
+			links[id] = true
+		}
+
+		dir := &stack[len(stack)-1]
+		dir.Size += entry.Info.Size()
+		dir.Allocated += entry.AllocatedSize()
+		dir.Files++
+		topFiles.push(FileUsage{Path: entry.Path, RelPath: entry.RelPath, Size: entry.Info.Size()}, opts.TopN)
+	}
+
+	for len(stack) > 0 {
+		finish()
+	}
+
+	report.TopFiles = topFiles.sorted()
+	report.TopDirs = topDirs.sorted()
+	return report, ctx.Err()
+}
+
+// isUnder rel 이 dir 아래에 있는지 (슬래시 구분 상대 경로)
+func isUnder(rel, dir string) bool {
+	return dir == "." || strings.HasPrefix(rel, dir+"/")
+}
+
+// sizeHeap 크기 기준 상위 N 개만 유지하는 최소 힙 - 가장 작은 게 맨 위라 바로 밀어낼 수 있어
+type sizeHeap[T any] struct {
+	items []T
+	size  func(T) int64
+}
+
+func (h *sizeHeap[T]) Len() int           { return len(h.items) }
+func (h *sizeHeap[T]) Less(i, j int) bool { return h.size(h.items[i]) < h.size(h.items[j]) }
+func (h *sizeHeap[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *sizeHeap[T]) Push(x any)         { h.items = append(h.items, x.(T)) }
+func (h *sizeHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+func (h *sizeHeap[T]) push(item T, limit int) {
+	if h.Len() < limit {
+		heap.Push(h, item)
+		return
+	}
+	if h.size(item) > h.size(h.items[0]) {
+		h.items[0] = item
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted 큰 것부터
+func (h *sizeHeap[T]) sorted() []T {
+	out := append([]T(nil), h.items...)
+	sort.Slice(out, func(i, j int) bool { return h.size(out[i]) > h.size(out[j]) })
+	return out
+}
@@ -0,0 +1,65 @@
+package fstree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+func TestDiskUsage(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"top.txt":     strings.Repeat("t", 10),
+		"a/one.txt":   strings.Repeat("1", 100),
+		"a/b/two.txt": strings.Repeat("2", 200),
+		"a-x.txt":     strings.Repeat("x", 5), // "a" 디렉토리 바로 뒤에 오는 형제
+		"c/three.txt": strings.Repeat("3", 30),
+	})
+	// 하드링크는 한 번만 세
+	if err := os.Link(filepath.Join(root, "a", "b", "two.txt"), filepath.Join(root, "c", "two-link.txt")); err != nil {
+		t.Skip("하드링크를 만들 수 없음:", err)
+	}
+
+	var order []string
+	report, err := DiskUsage(t.Context(), root, UsageOptions{TopN: 2, OnDir: func(d DirUsage) {
+		order = append(order, d.RelPath)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 하위 디렉토리가 항상 부모보다 먼저
+	if want := []string{"a/b", "a", "c", "."}; !slices.Equal(order, want) {
+		t.Errorf("OnDir 순서 = %v, want %v", order, want)
+	}
+	// 대체 구현(streamio_portable)은 inode 를 몰라서 하드링크도 따로 세
+	wantSize, wantFiles := int64(345), 5
+	wantTop, wantDirs := []string{"a/b/two.txt", "a/one.txt"}, []string{"a:2", "a/b:1"} // a 는 a/b 까지 합쳐서 300, a/b 는 200
+	if streamio.Portable() {
+		wantSize, wantFiles = 545, 6
+		wantTop, wantDirs = []string{"a/b/two.txt", "c/two-link.txt"}, []string{"a:2", "c:2"}
+	}
+	if got := report.Total; got.Size != wantSize || got.Files != wantFiles || got.Dirs != 3 {
+		t.Errorf("Total = %+v, want 크기 %d, 파일 %d", got, wantSize, wantFiles)
+	}
+
+	var files, dirs []string
+	for _, f := range report.TopFiles {
+		files = append(files, f.RelPath)
+	}
+	for _, d := range report.TopDirs {
+		dirs = append(dirs, fmt.Sprintf("%s:%d", d.RelPath, d.Files))
+	}
+	slices.Sort(files) // 크기가 같으면 순서가 정해져 있지 않아서 이름순으로 비교
+	if !slices.Equal(files, wantTop) {
+		t.Errorf("TopFiles = %v, want %v", files, wantTop)
+	}
+	if !slices.Equal(dirs, wantDirs) {
+		t.Errorf("TopDirs = %v, want %v", dirs, wantDirs)
+	}
+}
@@ -0,0 +1,322 @@
+// Package fstree 는 디렉토리 트리 단위 작업(순회, 동기화, 매니페스트 등)을 모아둔 패키지야.
+// 개별 스트림 처리는 streamio, 여러 파일을 다루는 건 fstree 가 담당해.
+package fstree
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Entry 순회 결과 하나
+type Entry struct {
+	Path    string      // 전체 경로
+	RelPath string      // root 기준 상대 경로 (슬래시 구분)
+	Info    fs.FileInfo // 파일 정보
+	Depth   int         // root 바로 아래가 1
+	Err     error       // 이 경로를 처리하다 난 에러 (순회는 계속돼)
+}
+
+// AllocatedSize 디스크에 실제로 할당된 크기 (Info.Size() 는 논리 크기)
+func (e Entry) AllocatedSize() int64 {
+	if e.Info == nil {
+		return 0
+	}
+	return streamio.AllocatedSize(e.Info)
+}
+
+// Sparse 구멍이 있는 파일인지
+func (e Entry) Sparse() bool {
+	return e.Info != nil && streamio.IsSparse(e.Info)
+}
+
+// WalkOptions 순회 필터 옵션 - 비워두면 모든 일반 파일
+type WalkOptions struct {
+	Include  []string // glob 패턴, 하나라도 맞는 파일만 (비우면 전부)
+	Exclude  []string // glob 패턴, 맞으면 제외 (디렉토리면 하위까지 통째로 건너뜀)
+	MaxDepth int      // 0 이면 제한 없음, 1 이면 root 바로 아래만
+
+	MinSize int64 // 이 크기 미만 파일 제외
+	MaxSize int64 // 0 이면 제한 없음
+
+	ModifiedAfter  time.Time // 이 시각 이후 수정된 파일만 (zero 면 무시)
+	ModifiedBefore time.Time // 이 시각 이전 수정된 파일만 (zero 면 무시)
+
+	IncludeDirs bool // 디렉토리도 Entry 로 보낼지
+	BufferSize  int  // 결과 채널 버퍼 크기 (기본 64)
+
+	// Symlinks 심볼릭 링크 처리 방식 (기본 skip)
+	Symlinks SymlinkPolicy
+}
+
+// SymlinkPolicy 순회 중 심볼릭 링크를 만났을 때의 처리 방식
+type SymlinkPolicy string
+
+const (
+	SymlinkSkip   SymlinkPolicy = "skip"   // 링크는 무시 (기본)
+	SymlinkFollow SymlinkPolicy = "follow" // 링크가 가리키는 파일/디렉토리를 따라가 (순환 감지)
+	SymlinkCopy   SymlinkPolicy = "copy"   // 링크 자체를 Entry 로 보내 (Info 는 Lstat 결과)
+)
+
+// String flag.Value 구현
+func (p *SymlinkPolicy) String() string {
+	if p == nil || *p == "" {
+		return string(SymlinkSkip)
+	}
+	return string(*p)
+}
+
+// Set flag.Value 구현
+func (p *SymlinkPolicy) Set(s string) error {
+	switch SymlinkPolicy(s) {
+	case SymlinkSkip, SymlinkFollow, SymlinkCopy:
+		*p = SymlinkPolicy(s)
+		return nil
+	}
+	return msg.Errorf("알 수 없는 심볼릭 링크 정책: %q (skip|follow|copy)", s)
+}
+
+// ErrSymlinkCycle 따라가면 자기 조상 디렉토리로 돌아오는 링크 (Entry.Err 로 전달돼)
+var ErrSymlinkCycle = msg.New("심볼릭 링크 순환")
+
+// Walk root 아래를 순회하면서 조건에 맞는 Entry 를 채널로 흘려보내
+// ⭐ 전체 목록을 메모리에 모으지 않고 찾는 즉시 보내니까, 파일이 수백만 개여도
+// 소비하는 쪽(압축기, 동기화, 매니페스트)이 바로 일을 시작할 수 있어.
+// 채널은 순회가 끝나거나 ctx 가 취소되면 닫혀.
+func Walk(ctx context.Context, root string, opts WalkOptions) <-chan Entry {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = 64
+	}
+	out := make(chan Entry, size)
+
+	go func() {
+		defer close(out)
+
+		w := &walker{ctx: ctx, opts: opts, out: out}
+		realRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			realRoot = root
+		}
+		w.walk(root, root, "", 0, []string{realRoot})
+	}()
+
+	return out
+}
+
+// walker 순회 상태 - 링크를 따라가면 walk 가 재귀로 다시 불려
+type walker struct {
+	ctx  context.Context
+	opts WalkOptions
+	out  chan<- Entry
+}
+
+func (w *walker) send(e Entry) error {
+	select {
+	case w.out <- e:
+		return nil
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+// walk realDir 을 순회하되, 보여주는 경로는 shownDir/relBase 기준으로 바꿔서 보내
+// 링크를 따라간 경우 realDir 은 링크 대상, shownDir 은 링크 자신의 경로야.
+// ancestors 는 지금까지 따라 들어온 실제 디렉토리들 (순환 감지용).
+func (w *walker) walk(realDir, shownDir, relBase string, depthBase int, ancestors []string) error {
+	opts := w.opts
+	return filepath.WalkDir(realDir, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := w.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		sub, _ := filepath.Rel(realDir, p)
+		shown := filepath.Join(shownDir, sub)
+		rel := path.Join(relBase, filepath.ToSlash(sub))
+		if rel == "" {
+			rel = "."
+		}
+		depth := depthBase
+		if sub != "." {
+			depth += strings.Count(filepath.ToSlash(sub), "/") + 1
+		}
+
+		if err != nil {
+			// 권한 없음 등 - 에러를 알리고 그 경로만 건너뛰기
+			if sendErr := w.send(Entry{Path: shown, RelPath: rel, Depth: depth, Err: err}); sendErr != nil {
+				return sendErr
+			}
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if sub == "." {
+			return nil
+		}
+
+		if matchAny(opts.Exclude, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			return w.symlink(p, shown, rel, depth, d, ancestors)
+		}
+
+		if d.IsDir() {
+			if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+				// 디렉토리 자체는 보내되 더 내려가지는 않아
+				if opts.IncludeDirs {
+					if sendErr := sendDir(w.send, shown, rel, depth, d); sendErr != nil {
+						return sendErr
+					}
+				}
+				return filepath.SkipDir
+			}
+			if opts.IncludeDirs {
+				return sendDir(w.send, shown, rel, depth, d)
+			}
+			return nil
+		}
+
+		if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+			return nil
+		}
+		if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return w.send(Entry{Path: shown, RelPath: rel, Depth: depth, Err: err})
+		}
+		if !opts.accept(info) {
+			return nil
+		}
+
+		return w.send(Entry{Path: shown, RelPath: rel, Info: info, Depth: depth})
+	})
+}
+
+// symlink 정책에 따라 링크 처리 - 디렉토리를 가리키는 링크를 따라가면 그 아래를 재귀로 순회해
+func (w *walker) symlink(real, shown, rel string, depth int, d fs.DirEntry, ancestors []string) error {
+	opts := w.opts
+	if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+		return nil
+	}
+
+	switch opts.Symlinks {
+	case SymlinkCopy:
+		if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+			return nil
+		}
+		info, err := d.Info()
+		return w.send(Entry{Path: shown, RelPath: rel, Info: info, Depth: depth, Err: err})
+
+	case SymlinkFollow:
+		info, err := os.Stat(real)
+		if err != nil {
+			// 대상이 없는 (끊어진) 링크
+			return w.send(Entry{Path: shown, RelPath: rel, Depth: depth, Err: err})
+		}
+
+		if !info.IsDir() {
+			if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+				return nil
+			}
+			if !opts.accept(info) {
+				return nil
+			}
+			return w.send(Entry{Path: shown, RelPath: rel, Info: info, Depth: depth})
+		}
+
+		target, err := filepath.EvalSymlinks(real)
+		if err != nil {
+			return w.send(Entry{Path: shown, RelPath: rel, Depth: depth, Err: err})
+		}
+		if isCycle(real, target, ancestors) {
+			return w.send(Entry{Path: shown, RelPath: rel, Depth: depth,
+				Err: &fs.PathError{Op: "follow", Path: shown, Err: ErrSymlinkCycle}})
+		}
+
+		if opts.IncludeDirs {
+			if err := w.send(Entry{Path: shown, RelPath: rel, Info: info, Depth: depth}); err != nil {
+				return err
+			}
+		}
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			return nil
+		}
+		// 따라 들어간 트리 안의 에러는 Entry 로 이미 보냈으니, 취소만 위로 전달
+		if err := w.walk(target, shown, rel, depth, append(ancestors[:len(ancestors):len(ancestors)], target)); err != nil && w.ctx.Err() != nil {
+			return err
+		}
+		return nil
+	}
+	return nil
+}
+
+// isCycle 링크 대상이 이미 따라 들어온 디렉토리거나, 링크가 있는 디렉토리의 조상이면 순환
+func isCycle(link, target string, ancestors []string) bool {
+	for _, a := range ancestors {
+		if a == target {
+			return true
+		}
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(link))
+	if err != nil {
+		return true
+	}
+	return parent == target || strings.HasPrefix(parent, target+string(filepath.Separator))
+}
+
+func sendDir(send func(Entry) error, p, rel string, depth int, d fs.DirEntry) error {
+	info, err := d.Info()
+	return send(Entry{Path: p, RelPath: rel, Info: info, Depth: depth, Err: err})
+}
+
+// accept 크기/수정시각 필터 (일반 파일만 통과)
+func (o WalkOptions) accept(info fs.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if info.Size() < o.MinSize {
+		return false
+	}
+	if o.MaxSize > 0 && info.Size() > o.MaxSize {
+		return false
+	}
+	if !o.ModifiedAfter.IsZero() && info.ModTime().Before(o.ModifiedAfter) {
+		return false
+	}
+	if !o.ModifiedBefore.IsZero() && info.ModTime().After(o.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// matchAny 패턴을 상대 경로 전체와 파일 이름 양쪽에 대조
+// "*.log" 는 어느 깊이의 로그 파일이든, "logs/*.log" 는 해당 경로만 맞아
+func matchAny(patterns []string, rel string) bool {
+	base := path.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
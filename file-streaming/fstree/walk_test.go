@@ -0,0 +1,188 @@
+package fstree
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeTree root 아래에 상대 경로 → 내용대로 파일을 만들어 (디렉토리도 같이)
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// symlink 을 못 만드는 환경(권한 없는 Windows)이면 테스트를 건너뛰어
+func symlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("심볼릭 링크를 만들 수 없음: %v", err)
+	}
+}
+
+// walkRel 순회 결과의 상대 경로 (정렬) 와 에러가 난 Entry
+func walkRel(t *testing.T, root string, opts WalkOptions) (rels []string, errs []Entry) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	for e := range Walk(ctx, root, opts) {
+		if e.Err != nil {
+			errs = append(errs, e)
+			continue
+		}
+		rels = append(rels, e.RelPath)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("순회가 끝나지 않음 (순환?)")
+	}
+	slices.Sort(rels)
+	return rels, errs
+}
+
+func TestWalkFilters(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.log":             "0123456789",
+		"b.txt":             "x",
+		"logs/app.log":      "01234",
+		"logs/deep/old.log": "0123456789abcdef",
+		"node_modules/x.js": "skip",
+	})
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(root, "logs", "deep", "old.log"), old, old)
+
+	tests := []struct {
+		name string
+		opts WalkOptions
+		want []string
+	}{
+		{"전부", WalkOptions{}, []string{"a.log", "b.txt", "logs/app.log", "logs/deep/old.log", "node_modules/x.js"}},
+		{"include 는 이름이나 경로", WalkOptions{Include: []string{"*.log"}}, []string{"a.log", "logs/app.log", "logs/deep/old.log"}},
+		{"exclude 디렉토리는 통째로", WalkOptions{Exclude: []string{"node_modules", "deep"}}, []string{"a.log", "b.txt", "logs/app.log"}},
+		{"깊이", WalkOptions{MaxDepth: 1}, []string{"a.log", "b.txt"}},
+		{"깊이와 디렉토리", WalkOptions{MaxDepth: 1, IncludeDirs: true}, []string{"a.log", "b.txt", "logs", "node_modules"}},
+		{"크기", WalkOptions{MinSize: 5, MaxSize: 10}, []string{"a.log", "logs/app.log"}},
+		{"수정 시각", WalkOptions{Include: []string{"*.log"}, ModifiedAfter: time.Now().Add(-time.Hour)}, []string{"a.log", "logs/app.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := walkRel(t, root, tt.opts)
+			if len(errs) > 0 || !slices.Equal(got, tt.want) {
+				t.Errorf("Walk = %v (에러 %v), want %v", got, errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeTree(t, root, map[string]string{"dir/a.txt": "a"})
+	writeTree(t, outside, map[string]string{"b.txt": "b"})
+	symlink(t, filepath.Join(root, "dir", "a.txt"), filepath.Join(root, "file-link"))
+	symlink(t, outside, filepath.Join(root, "dir-link"))
+	symlink(t, "..", filepath.Join(root, "dir", "loop")) // 자기 조상으로 돌아가
+	symlink(t, filepath.Join(root, "없음"), filepath.Join(root, "dangling"))
+
+	t.Run("skip", func(t *testing.T) {
+		got, errs := walkRel(t, root, WalkOptions{})
+		if !slices.Equal(got, []string{"dir/a.txt"}) || len(errs) > 0 {
+			t.Errorf("Walk = %v (에러 %v), want 링크 없이 dir/a.txt 만", got, errs)
+		}
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		var links []string
+		for e := range Walk(t.Context(), root, WalkOptions{Symlinks: SymlinkCopy}) {
+			if e.Err == nil && e.Info.Mode()&os.ModeSymlink != 0 {
+				links = append(links, e.RelPath)
+			}
+		}
+		slices.Sort(links)
+		if want := []string{"dangling", "dir-link", "dir/loop", "file-link"}; !slices.Equal(links, want) {
+			t.Errorf("링크 Entry = %v, want %v (Lstat 결과로 링크 자체)", links, want)
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		got, errs := walkRel(t, root, WalkOptions{Symlinks: SymlinkFollow})
+		if want := []string{"dir-link/b.txt", "dir/a.txt", "file-link"}; !slices.Equal(got, want) {
+			t.Errorf("Walk = %v, want %v (따라간 파일은 링크 경로로)", got, want)
+		}
+		var cycle, dangling bool
+		for _, e := range errs {
+			switch {
+			case e.RelPath == "dir/loop" && errors.Is(e.Err, ErrSymlinkCycle):
+				cycle = true
+			case e.RelPath == "dangling" && errors.Is(e.Err, os.ErrNotExist):
+				dangling = true
+			default:
+				t.Errorf("예상 밖 에러 Entry %s: %v", e.RelPath, e.Err)
+			}
+		}
+		if !cycle || !dangling {
+			t.Errorf("순환 %v, 끊어진 링크 %v - 둘 다 Entry.Err 로 와야 해", cycle, dangling)
+		}
+	})
+
+	// 밖에서 안으로 서로 가리키는 링크 두 개도 한 바퀴만
+	t.Run("follow 상호 순환", func(t *testing.T) {
+		a, b := t.TempDir(), t.TempDir()
+		symlink(t, b, filepath.Join(a, "to-b"))
+		symlink(t, a, filepath.Join(b, "to-a"))
+		_, errs := walkRel(t, a, WalkOptions{Symlinks: SymlinkFollow})
+		if len(errs) != 1 || !errors.Is(errs[0].Err, ErrSymlinkCycle) || errs[0].RelPath != "to-b/to-a" {
+			t.Errorf("에러 Entry = %v, want to-b/to-a 에서 순환 하나", errs)
+		}
+	})
+}
+
+func TestSymlinkPolicySet(t *testing.T) {
+	var p SymlinkPolicy
+	if p.String() != "skip" {
+		t.Errorf("기본 = %q, want skip", p.String())
+	}
+	if err := p.Set("follow"); err != nil || p != SymlinkFollow {
+		t.Errorf("Set(follow) = %v, %q", err, p)
+	}
+	if err := p.Set("hardlink"); err == nil {
+		t.Error("모르는 정책인데 에러가 없음")
+	}
+}
+
+func TestWalkSparse(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"dense.txt": "dense"})
+	f, err := os.Create(filepath.Join(root, "hole.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Truncate(1 << 20) // 통째로 구멍
+	f.Close()
+
+	sparse := map[string]bool{}
+	for e := range Walk(t.Context(), root, WalkOptions{}) {
+		sparse[e.RelPath] = e.Sparse()
+		if e.RelPath == "hole.img" && !e.Sparse() {
+			t.Skip("이 파일시스템은 sparse 파일을 지원하지 않음")
+		}
+		if e.RelPath == "hole.img" && e.AllocatedSize() >= e.Info.Size() {
+			t.Errorf("AllocatedSize = %d, 크기 %d", e.AllocatedSize(), e.Info.Size())
+		}
+	}
+	if !sparse["hole.img"] || sparse["dense.txt"] {
+		t.Errorf("Sparse = %v", sparse)
+	}
+}
@@ -0,0 +1,272 @@
+package fstree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchAction 새로 생기거나 바뀐 파일마다 실행할 작업
+type WatchAction func(ctx context.Context, path string) error
+
+// WatchOptions 감시 옵션
+type WatchOptions struct {
+	Include []string // glob 패턴 (WalkOptions 와 같은 규칙)
+	Exclude []string
+
+	// Debounce 마지막 변경 후 이만큼 조용해야 작업 실행 (기본 1초)
+	// ⭐ 큰 파일은 복사되는 동안 Write 이벤트가 수천 번 오니까, 다 써질 때까지 기다려야 해
+	Debounce time.Duration
+	// Concurrency 동시에 실행할 작업 수 (기본 2)
+	Concurrency int
+
+	// ForcePolling fsnotify 를 쓰지 않고 주기적 스캔만 사용 (NFS 등 inotify 가 안 되는 곳)
+	ForcePolling bool
+	// PollInterval 폴링 주기 (기본 2초)
+	PollInterval time.Duration
+
+	// ProcessExisting 시작할 때 이미 있던 파일도 처리할지
+	ProcessExisting bool
+
+	// OnError 작업/감시 에러 콜백 (nil 이면 무시)
+	OnError func(path string, err error)
+}
+
+func (o *WatchOptions) setDefaults() {
+	if o.Debounce <= 0 {
+		o.Debounce = time.Second
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 2
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 2 * time.Second
+	}
+}
+
+// Watch dir 을 감시하다가 파일이 생기거나 바뀌면 action 을 실행해
+// fsnotify 를 먼저 시도하고, 실패하면 폴링으로 대체해. ctx 가 취소될 때까지 블록돼.
+func Watch(ctx context.Context, dir string, action WatchAction, opts WatchOptions) error {
+	opts.setDefaults()
+
+	d := newDebouncer(ctx, action, opts)
+	defer d.wait()
+
+	if opts.ProcessExisting {
+		for entry := range Walk(ctx, dir, WalkOptions{Include: opts.Include, Exclude: opts.Exclude}) {
+			if entry.Err == nil {
+				d.touch(entry.Path)
+			}
+		}
+	}
+
+	if !opts.ForcePolling {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			return watchNotify(ctx, watcher, dir, d, opts)
+		}
+		d.reportError(dir, err)
+	}
+
+	return watchPoll(ctx, dir, d, opts)
+}
+
+// watchNotify fsnotify 이벤트 기반 감시 (하위 디렉토리는 직접 등록해줘야 해)
+func watchNotify(ctx context.Context, watcher *fsnotify.Watcher, dir string, d *debouncer, opts WatchOptions) error {
+	defer watcher.Close()
+
+	addTree := func(root string) {
+		for entry := range Walk(ctx, root, WalkOptions{Exclude: opts.Exclude, IncludeDirs: true}) {
+			if entry.Err == nil && entry.Info != nil && entry.Info.IsDir() {
+				watcher.Add(entry.Path)
+			}
+		}
+	}
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+	addTree(dir)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			rel, _ := filepath.Rel(dir, event.Name)
+			rel = filepath.ToSlash(rel)
+			if matchAny(opts.Exclude, rel) {
+				continue
+			}
+
+			switch {
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				info, err := os.Stat(event.Name)
+				if err != nil {
+					continue
+				}
+				if info.IsDir() {
+					// 새 디렉토리: 감시 등록 + 그 안에 이미 들어온 파일 처리
+					watcher.Add(event.Name)
+					addTree(event.Name)
+					for entry := range Walk(ctx, event.Name, WalkOptions{Include: opts.Include, Exclude: opts.Exclude}) {
+						if entry.Err == nil {
+							d.touch(entry.Path)
+						}
+					}
+					continue
+				}
+				if len(opts.Include) == 0 || matchAny(opts.Include, rel) {
+					d.touch(event.Name)
+				}
+
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				d.cancel(event.Name)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			d.reportError(dir, err)
+		}
+	}
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watchPoll 주기적으로 트리를 스캔해서 크기/수정시각이 바뀐 파일을 찾는 방식
+func watchPoll(ctx context.Context, dir string, d *debouncer, opts WatchOptions) error {
+	scan := func() map[string]fileState {
+		states := make(map[string]fileState)
+		for entry := range Walk(ctx, dir, WalkOptions{Include: opts.Include, Exclude: opts.Exclude}) {
+			if entry.Err == nil {
+				states[entry.Path] = fileState{entry.Info.Size(), entry.Info.ModTime()}
+			}
+		}
+		return states
+	}
+
+	prev := scan()
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			cur := scan()
+			for path, st := range cur {
+				if old, ok := prev[path]; !ok || old != st {
+					d.touch(path)
+				}
+			}
+			for path := range prev {
+				if _, ok := cur[path]; !ok {
+					d.cancel(path)
+				}
+			}
+			prev = cur
+		}
+	}
+}
+
+// debouncer 경로별 타이머로 변경을 모았다가, 세마포어로 동시 실행 수를 제한해서 작업 실행
+type debouncer struct {
+	ctx    context.Context
+	action WatchAction
+	opts   WatchOptions
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+	closed  bool
+	sem     chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newDebouncer(ctx context.Context, action WatchAction, opts WatchOptions) *debouncer {
+	return &debouncer{
+		ctx:     ctx,
+		action:  action,
+		opts:    opts,
+		pending: make(map[string]*time.Timer),
+		sem:     make(chan struct{}, opts.Concurrency),
+	}
+}
+
+// touch 변경 발생 - 타이머를 다시 맞춰서 조용해질 때까지 미뤄
+func (d *debouncer) touch(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.pending[path]; ok {
+		t.Reset(d.opts.Debounce)
+		return
+	}
+	d.pending[path] = time.AfterFunc(d.opts.Debounce, func() { d.fire(path) })
+}
+
+// cancel 삭제/이동된 파일은 대기 중인 작업 취소
+func (d *debouncer) cancel(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if t, ok := d.pending[path]; ok {
+		t.Stop()
+		delete(d.pending, path)
+	}
+}
+
+func (d *debouncer) fire(path string) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, path)
+	d.wg.Add(1)
+	d.mu.Unlock()
+	defer d.wg.Done()
+
+	// 동시 실행 수 제한
+	select {
+	case d.sem <- struct{}{}:
+	case <-d.ctx.Done():
+		return
+	}
+	defer func() { <-d.sem }()
+
+	if err := d.action(d.ctx, path); err != nil {
+		d.reportError(path, err)
+	}
+}
+
+func (d *debouncer) reportError(path string, err error) {
+	if d.opts.OnError != nil {
+		d.opts.OnError(path, err)
+	}
+}
+
+// wait 대기 중인 타이머는 버리고, 실행 중인 작업이 끝날 때까지 기다려
+func (d *debouncer) wait() {
+	d.mu.Lock()
+	d.closed = true
+	for path, t := range d.pending {
+		t.Stop()
+		delete(d.pending, path)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
@@ -0,0 +1,91 @@
+package fstree
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	for _, polling := range []bool{false, true} {
+		name := "fsnotify"
+		if polling {
+			name = "polling"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, map[string]string{"existing.log": "old", "skip.tmp": "x"})
+
+			var (
+				mu  sync.Mutex
+				got = map[string]int{}
+			)
+			fired := make(chan string, 16)
+			action := func(_ context.Context, p string) error {
+				rel, _ := filepath.Rel(dir, p)
+				mu.Lock()
+				got[filepath.ToSlash(rel)]++
+				mu.Unlock()
+				fired <- filepath.ToSlash(rel)
+				return nil
+			}
+			ctx, cancel := context.WithCancel(t.Context())
+			done := make(chan error, 1)
+			go func() {
+				done <- Watch(ctx, dir, action, WatchOptions{
+					Include:         []string{"*.log"},
+					Exclude:         []string{"ignored"},
+					Debounce:        100 * time.Millisecond,
+					ForcePolling:    polling,
+					PollInterval:    20 * time.Millisecond,
+					ProcessExisting: true,
+				})
+			}()
+			wait := func(want string) {
+				t.Helper()
+				select {
+				case rel := <-fired:
+					if rel != want {
+						t.Fatalf("작업 = %s, want %s", rel, want)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("%s 작업이 안 불림", want)
+				}
+			}
+			wait("existing.log")
+
+			// 감시가 자리를 잡을 틈을 주고, 새 디렉토리 안의 파일을 여러 번 나눠 써 - 조용해진 뒤 한 번만
+			time.Sleep(100 * time.Millisecond)
+			os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+			os.MkdirAll(filepath.Join(dir, "ignored"), 0755)
+			time.Sleep(50 * time.Millisecond)
+			f, err := os.Create(filepath.Join(dir, "sub", "new.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 5 {
+				f.WriteString("line\n")
+				time.Sleep(10 * time.Millisecond)
+			}
+			f.Close()
+			writeTree(t, dir, map[string]string{"ignored/x.log": "x", "other.tmp": "x"})
+			wait("sub/new.log")
+
+			time.Sleep(300 * time.Millisecond) // 늦게 오는 작업이 없는지
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if keys := slices.Sorted(maps.Keys(got)); !slices.Equal(keys, []string{"existing.log", "sub/new.log"}) || got["sub/new.log"] != 1 {
+				t.Errorf("작업 = %v, want existing.log, sub/new.log 한 번씩", got)
+			}
+		})
+	}
+}
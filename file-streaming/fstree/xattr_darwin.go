@@ -0,0 +1,5 @@
+package fstree
+
+import "golang.org/x/sys/unix"
+
+const noAttr = unix.ENOATTR
@@ -0,0 +1,6 @@
+package fstree
+
+import "golang.org/x/sys/unix"
+
+// 리눅스는 없는 속성을 ENODATA 로 알려 (ENOATTR 이 따로 없어)
+const noAttr = unix.ENODATA
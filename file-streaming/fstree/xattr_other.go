@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package fstree
+
+// 확장 속성 API 가 다른 플랫폼은 xattr 저장소를 못 써 (사이드카 DB 를 써)
+func getXattr(path, name string) ([]byte, bool, error) { return nil, false, ErrXattrUnsupported }
+
+func setXattr(path, name string, value []byte) error { return ErrXattrUnsupported }
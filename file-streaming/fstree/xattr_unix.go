@@ -0,0 +1,52 @@
+//go:build linux || darwin
+
+package fstree
+
+import (
+	"errors"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"golang.org/x/sys/unix"
+)
+
+// getXattr 확장 속성 하나 읽기 (없으면 nil, false, nil)
+func getXattr(path, name string) ([]byte, bool, error) {
+	if streamio.Portable() {
+		return nil, false, ErrXattrUnsupported
+	}
+	for {
+		n, err := unix.Getxattr(path, name, nil)
+		if err != nil {
+			return nil, false, xattrErr(err)
+		}
+		value := make([]byte, n)
+		m, err := unix.Getxattr(path, name, value)
+		if errors.Is(err, unix.ERANGE) {
+			continue // 두 번 부르는 사이에 값이 커졌어
+		}
+		if err != nil {
+			return nil, false, xattrErr(err)
+		}
+		return value[:m], true, nil
+	}
+}
+
+func setXattr(path, name string, value []byte) error {
+	if streamio.Portable() {
+		return ErrXattrUnsupported
+	}
+	return xattrErr(unix.Setxattr(path, name, value, 0))
+}
+
+// xattrErr 파일시스템이 지원 안 하면 ErrXattrUnsupported 로 (속성이 없는 건 에러가 아니야)
+func xattrErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, noAttr):
+		return nil // getXattr 에서만 나와 - found=false 로
+	case errors.Is(err, unix.ENOTSUP), errors.Is(err, unix.EOPNOTSUPP):
+		return ErrXattrUnsupported
+	}
+	return err
+}
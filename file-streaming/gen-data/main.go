@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 예제/벤치마크용 테스트 데이터 생성기
+//
+//	go run ./gen-data -kind log -size 100MB -o fake.log
+//	go run ./gen-data -kind random -size 1G -o test_large_file.dat
+//	go run ./gen-data -size 1G -o - | go run ./streamctl compress - - > big.gz
+//	go run ./gen-data -tree ./data -depth 2 -files 5 -min 4KB -max 1MB
+func main() {
+	// 플래그 설명과 출력도 FS_LANG / -lang 을 따라가게 (플래그를 등록하기 전에)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	locale.RegisterFlags(flag.CommandLine)
+	kind := gendata.KindLog
+	flag.Var(&kind, "kind", msg.T("데이터 종류 (log|random|repeat)"))
+	size := flag.String("size", "10MB", msg.T("파일 크기 (예: 512, 64KB, 10MB, 2G)"))
+	out := flag.String("o", "fake.log", msg.T("출력 파일 (- 면 stdout)"))
+	seed := flag.Uint64("seed", 1, msg.T("난수 시드 (같으면 같은 내용)"))
+
+	tree := flag.String("tree", "", msg.T("지정하면 파일 대신 이 디렉토리에 트리 생성"))
+	depth := flag.Int("depth", 2, msg.T("트리 깊이"))
+	dirs := flag.Int("dirs", 2, msg.T("디렉토리마다 하위 디렉토리 수"))
+	files := flag.Int("files", 3, msg.T("디렉토리마다 파일 수"))
+	minSize := flag.String("min", "1KB", msg.T("트리 파일 최소 크기"))
+	maxSize := flag.String("max", "64KB", msg.T("트리 파일 최대 크기"))
+	kinds := flag.String("kinds", "log,random,repeat", msg.T("트리에서 돌아가며 쓸 종류 (쉼표 구분)"))
+	flag.Parse()
+	logging.SetupFromEnv()
+
+	if *tree != "" {
+		err = generateTree(*tree, *depth, *dirs, *files, *minSize, *maxSize, *kinds, *seed)
+	} else {
+		err = generateFile(*out, kind, *size, *seed)
+	}
+	if err != nil {
+		logging.Fatal("생성 실패", "err", err)
+	}
+}
+
+func generateFile(path string, kind gendata.Kind, sizeText string, seed uint64) error {
+	size, err := gendata.ParseSize(sizeText)
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		// stdout 은 데이터 용이라 완료 안내는 stderr 로
+		r, err := gendata.NewReader(kind, size, seed)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(os.Stdout, r); err != nil {
+			return err
+		}
+		msg.Fprintf(os.Stderr, "stdout 으로 생성 완료 (%s, %d 바이트)\n", kind, size)
+		return nil
+	}
+	if err := gendata.WriteFile(path, kind, size, seed); err != nil {
+		return err
+	}
+	msg.Printf("%s 생성 완료 (%s, %d 바이트)\n", path, kind, size)
+	return nil
+}
+
+func generateTree(root string, depth, dirs, files int, minText, maxText, kindsText string, seed uint64) error {
+	opts := gendata.TreeOptions{Depth: depth, Dirs: dirs, Files: files, Seed: seed}
+
+	var err error
+	if opts.MinSize, err = gendata.ParseSize(minText); err != nil {
+		return err
+	}
+	if opts.MaxSize, err = gendata.ParseSize(maxText); err != nil {
+		return err
+	}
+	for _, k := range strings.Split(kindsText, ",") {
+		var kind gendata.Kind
+		if err := kind.Set(strings.TrimSpace(k)); err != nil {
+			return err
+		}
+		opts.Kinds = append(opts.Kinds, kind)
+	}
+
+	stats, err := gendata.GenerateTree(root, opts)
+	if err != nil {
+		return err
+	}
+	msg.Printf("%s 생성 완료: 디렉토리 %d개, 파일 %d개, %d 바이트\n", root, stats.Dirs, stats.Files, stats.Bytes)
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package gendata 는 예제/벤치마크용 테스트 데이터(가짜 로그, 랜덤 바이너리, 반복 패턴)를 만드는 패키지야.
+// 모든 생성기는 io.Reader 라서 몇 GB 짜리 파일도 메모리 걱정 없이 스트리밍으로 만들 수 있어.
+// 같은 seed 면 항상 같은 내용이 나와서 벤치마크 결과를 비교하기 좋아.
+package gendata
+
+import (
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Kind 생성할 데이터 종류
+type Kind string
+
+const (
+	KindLog    Kind = "log"    // 가짜 로그 줄 (step06 분석기가 읽는 형식: 레벨, IP 포함)
+	KindRandom Kind = "random" // 랜덤 바이너리 (압축이 거의 안 됨)
+	KindRepeat Kind = "repeat" // 같은 패턴 반복 (압축이 아주 잘 됨)
+)
+
+// String flag.Value 구현
+func (k *Kind) String() string {
+	if k == nil || *k == "" {
+		return string(KindLog)
+	}
+	return string(*k)
+}
+
+// Set flag.Value 구현
+func (k *Kind) Set(s string) error {
+	switch Kind(s) {
+	case KindLog, KindRandom, KindRepeat:
+		*k = Kind(s)
+		return nil
+	}
+	return msg.Errorf("알 수 없는 데이터 종류: %q (log|random|repeat)", s)
+}
+
+// NewReader size 바이트를 만들어내는 Reader
+// KindLog 는 마지막 줄을 잘라서라도 정확히 size 바이트에 맞추고, 항상 줄바꿈으로 끝나.
+func NewReader(kind Kind, size int64, seed uint64) (io.Reader, error) {
+	if size < 0 {
+		return nil, msg.New("크기는 0 이상이어야 합니다")
+	}
+
+	var src io.Reader
+	switch kind {
+	case KindLog, "":
+		return &logReader{rng: newRand(seed), remaining: size, now: baseTime}, nil
+	case KindRandom:
+		var key [32]byte
+		copy(key[:], strconv.FormatUint(seed, 10))
+		src = rand.NewChaCha8(key)
+	case KindRepeat:
+		src = &repeatReader{pattern: []byte(repeatPattern)}
+	default:
+		return nil, msg.Errorf("알 수 없는 데이터 종류: %q", kind)
+	}
+	return io.LimitReader(src, size), nil
+}
+
+// WriteFile path 에 size 바이트짜리 데이터 파일 생성
+func WriteFile(path string, kind Kind, size int64, seed uint64) (err error) {
+	r, err := NewReader(kind, size, seed)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.CopyBuffer(file, r, make([]byte, 64*1024))
+	return err
+}
+
+// Ensure path 가 없을 때만 생성 - 예제 코드가 "fake.log 가 있다고 가정" 하지 않게 시작할 때 불러
+func Ensure(path string, kind Kind, size int64) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return WriteFile(path, kind, size, 1)
+}
+
+// ParseSize "512", "64KB", "10MB", "2G" 같은 크기 문자열 파싱 (1KB = 1024 바이트)
+func ParseSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"GB", 1 << 30}, {"G", 1 << 30},
+		{"MB", 1 << 20}, {"M", 1 << 20},
+		{"KB", 1 << 10}, {"K", 1 << 10},
+		{"B", 1},
+	}
+
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n < 0 {
+		return 0, msg.Errorf("크기 형식 오류: %q (예: 512, 64KB, 10MB, 2G)", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// baseTime 로그 타임스탬프 시작 시각 (seed 와 함께 내용을 재현 가능하게 고정)
+var baseTime = time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+const repeatPattern = "The quick brown fox jumps over the lazy dog. 0123456789\n"
+
+type repeatReader struct {
+	pattern []byte
+	offset  int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.pattern[r.offset:])
+		n += c
+		r.offset = (r.offset + c) % len(r.pattern)
+	}
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package gendata
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readAll(t *testing.T, kind Kind, size int64, seed uint64) []byte {
+	t.Helper()
+	r, err := NewReader(kind, size, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestNewReader(t *testing.T) {
+	for _, kind := range []Kind{KindLog, KindRandom, KindRepeat} {
+		for _, size := range []int64{0, 1, 1000, 100 << 10} {
+			data := readAll(t, kind, size, 7)
+			if int64(len(data)) != size {
+				t.Errorf("%s %d: 크기 = %d", kind, size, len(data))
+			}
+			// 같은 seed 면 같은 내용
+			if again := readAll(t, kind, size, 7); !bytes.Equal(data, again) {
+				t.Errorf("%s %d: 같은 seed 인데 내용이 다름", kind, size)
+			}
+			if kind == KindLog && size > 0 && data[len(data)-1] != '\n' {
+				t.Errorf("log %d: 줄바꿈으로 끝나지 않음", size)
+			}
+		}
+	}
+	if bytes.Equal(readAll(t, KindRandom, 64, 1), readAll(t, KindRandom, 64, 2)) {
+		t.Error("seed 가 다른데 random 내용이 같음")
+	}
+	if _, err := NewReader("bogus", 10, 1); err == nil {
+		t.Error("알 수 없는 종류인데 에러가 없음")
+	}
+	if _, err := NewReader(KindLog, -1, 1); err == nil {
+		t.Error("음수 크기인데 에러가 없음")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "64KB": 64 << 10, "10mb": 10 << 20, "2G": 2 << 30, "1.5K": 1536, " 3 B ": 3} {
+		if got, err := ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "abc", "-1MB", "10XB"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) 에러가 없음", in)
+		}
+	}
+}
+
+func TestGenerateTree(t *testing.T) {
+	root := t.TempDir()
+	stats, err := GenerateTree(root, TreeOptions{Depth: 2, Dirs: 2, Files: 3, MinSize: 100, MaxSize: 200, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files, dirs int
+	var total int64
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs++
+			}
+			return nil
+		}
+		info, _ := d.Info()
+		if info.Size() < 100 || info.Size() > 200 {
+			t.Errorf("%s: 크기 %d 가 범위 밖", path, info.Size())
+		}
+		files++
+		total += info.Size()
+		return nil
+	})
+	// 깊이 2, 디렉토리마다 하위 2개, 파일 3개 → 디렉토리 2+4, 파일 3*(1+2+4)
+	if files != 21 || dirs != 6 || stats.Files != files || stats.Bytes != total {
+		t.Errorf("stats = %+v, 실제 파일 %d, 디렉토리 %d, %d 바이트", stats, files, dirs, total)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "fake.log")
+	if err := Ensure(path, KindLog, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 1000 {
+		t.Fatalf("Ensure 결과 = %v, %v", info, err)
+	}
+	// 이미 있으면 건드리지 않아
+	os.WriteFile(path, []byte("mine"), 0644)
+	if err := Ensure(path, KindLog, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "mine" {
+		t.Errorf("있는 파일을 덮어씀: %q", got)
+	}
+}
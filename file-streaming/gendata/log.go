@@ -0,0 +1,87 @@
+package gendata
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"time"
+)
+
+// 레벨별 비율: INFO 80%, WARNING 12%, ERROR 5%, DEBUG 3% - 실제 서비스 로그와 비슷하게
+var levels = []struct {
+	name   string
+	weight int
+}{
+	{"INFO", 80}, {"WARNING", 12}, {"ERROR", 5}, {"DEBUG", 3},
+}
+
+var (
+	methods  = []string{"GET", "GET", "GET", "POST", "PUT", "DELETE"}
+	paths    = []string{"/api/users", "/api/orders", "/api/products", "/login", "/health", "/static/app.js"}
+	statuses = []int{200, 200, 200, 200, 201, 304, 400, 404, 500}
+	messages = map[string][]string{
+		"INFO":    {"request completed", "cache hit", "user logged in"},
+		"WARNING": {"slow response", "retrying upstream", "cache miss"},
+		"ERROR":   {"database timeout", "upstream connection refused", "failed to parse body"},
+		"DEBUG":   {"payload decoded", "query planned"},
+	}
+)
+
+// logReader 한 줄씩 만들어서 내보내는 Reader
+type logReader struct {
+	rng       *rand.Rand
+	remaining int64
+	now       time.Time
+	line      []byte // 아직 내보내지 않은 현재 줄
+}
+
+func (r *logReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for n < len(p) && r.remaining > 0 {
+		if len(r.line) == 0 {
+			r.line = r.nextLine()
+			if int64(len(r.line)) > r.remaining {
+				// 마지막 줄: 남은 크기에 맞춰 자르고 줄바꿈으로 끝내
+				r.line = append(r.line[:r.remaining-1], '\n')
+			}
+		}
+		c := copy(p[n:], r.line)
+		r.line = r.line[c:]
+		n += c
+		r.remaining -= int64(c)
+	}
+	return n, nil
+}
+
+func (r *logReader) nextLine() []byte {
+	r.now = r.now.Add(time.Duration(r.rng.IntN(500)) * time.Millisecond)
+	level := r.level()
+	msgs := messages[level]
+
+	return fmt.Appendf(nil, "%s %-7s [worker-%d] %d.%d.%d.%d %s %s %d %dms %s\n",
+		r.now.Format("2006-01-02 15:04:05.000"),
+		level,
+		r.rng.IntN(8)+1,
+		[]int{10, 172, 192}[r.rng.IntN(3)], r.rng.IntN(256), r.rng.IntN(256), r.rng.IntN(254)+1,
+		methods[r.rng.IntN(len(methods))],
+		paths[r.rng.IntN(len(paths))],
+		statuses[r.rng.IntN(len(statuses))],
+		r.rng.IntN(900)+5,
+		msgs[r.rng.IntN(len(msgs))],
+	)
+}
+
+func (r *logReader) level() string {
+	n := r.rng.IntN(100)
+	for _, l := range levels {
+		if n < l.weight {
+			return l.name
+		}
+		n -= l.weight
+	}
+	return levels[0].name
+}
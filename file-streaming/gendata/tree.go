@@ -0,0 +1,101 @@
+package gendata
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// TreeOptions 디렉토리 트리 생성 옵션
+type TreeOptions struct {
+	Depth   int    // 하위 디렉토리 깊이 (0 이면 root 에만 파일)
+	Dirs    int    // 디렉토리마다 만들 하위 디렉토리 수 (기본 2)
+	Files   int    // 디렉토리마다 만들 파일 수 (기본 3)
+	MinSize int64  // 최소 파일 크기 (기본 1KB)
+	MaxSize int64  // 최대 파일 크기 (기본 64KB)
+	Kinds   []Kind // 파일마다 돌아가며 사용할 종류 (기본: 전부)
+	Seed    uint64
+	OnFile  func(path string, size int64) // 파일 하나 만들 때마다 호출 (nil 가능)
+}
+
+// TreeStats 생성 결과
+type TreeStats struct {
+	Dirs  int
+	Files int
+	Bytes int64
+}
+
+// GenerateTree root 아래에 디렉토리/파일 트리 생성 (dirsync, manifest, 압축 예제용)
+// 파일 이름은 종류에 맞는 확장자를 붙여: log → .log, random → .bin, repeat → .txt
+func GenerateTree(root string, opts TreeOptions) (TreeStats, error) {
+	if opts.Dirs <= 0 {
+		opts.Dirs = 2
+	}
+	if opts.Files <= 0 {
+		opts.Files = 3
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1 << 10
+	}
+	if opts.MaxSize < opts.MinSize {
+		opts.MaxSize = max(opts.MinSize, 64<<10)
+	}
+	if len(opts.Kinds) == 0 {
+		opts.Kinds = []Kind{KindLog, KindRandom, KindRepeat}
+	}
+
+	g := &treeGenerator{opts: opts, rng: newRand(opts.Seed)}
+	err := g.dir(root, 0)
+	return g.stats, err
+}
+
+type treeGenerator struct {
+	opts  TreeOptions
+	rng   *rand.Rand
+	stats TreeStats
+	seq   int
+}
+
+func (g *treeGenerator) dir(dir string, depth int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	g.stats.Dirs++
+
+	for i := 0; i < g.opts.Files; i++ {
+		kind := g.opts.Kinds[g.seq%len(g.opts.Kinds)]
+		size := g.opts.MinSize + g.rng.Int64N(g.opts.MaxSize-g.opts.MinSize+1)
+		path := filepath.Join(dir, fmt.Sprintf("file_%d%s", i+1, extension(kind)))
+
+		if err := WriteFile(path, kind, size, g.opts.Seed+uint64(g.seq)); err != nil {
+			return err
+		}
+		g.seq++
+		g.stats.Files++
+		g.stats.Bytes += size
+		if g.opts.OnFile != nil {
+			g.opts.OnFile(path, size)
+		}
+	}
+
+	if depth >= g.opts.Depth {
+		return nil
+	}
+	for i := 0; i < g.opts.Dirs; i++ {
+		if err := g.dir(filepath.Join(dir, fmt.Sprintf("dir_%d", i+1)), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extension(kind Kind) string {
+	switch kind {
+	case KindRandom:
+		return ".bin"
+	case KindRepeat:
+		return ".txt"
+	}
+	return ".log"
+}
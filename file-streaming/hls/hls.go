@@ -0,0 +1,184 @@
+// Package hls 는 MPEG-TS 동영상 파일 하나를 HLS 재생 목록(.m3u8)으로 나누는 패키지야.
+// step09 서버의 /hls 가 이 위에 올라가서, 모바일 플레이어가 큰 동영상을 통째로 받지 않고 조각씩 받아 재생하고 앞뒤로 넘겨.
+//
+//	pl, err := hls.Scan(ctx, f, hls.DefaultSegment) // 파일을 한 번 훑어서 조각 경계를 찾아
+//	pl.Write(w, "/download?file=a.ts")              // 조각마다 #EXT-X-BYTERANGE:길이@오프셋 + 같은 URI
+//
+// ⭐ 조각을 따로 만들지 않아 (ffmpeg 없이 표준 라이브러리만) - 재생 목록은 원본 파일의 바이트 범위를 가리키고(HLS 버전 4),
+// 플레이어는 Range 요청으로 그 조각만 받아. 경계는 188 바이트 패킷 단위로, 키프레임 표시(random_access_indicator)가 있는 패킷에서만 잘라서
+// 조각마다 디코딩을 새로 시작할 수 있어. 키프레임 표시를 안 넣는 먹서로 만든 파일이면 PCR 을 나르는 스트림의 PES 시작에서 잘라.
+// 시간은 PCR(27MHz 시계)로 재 - 33비트라 26시간마다 되감기는 것도 이어 붙여.
+// MP4 는 조각(moof)으로 나뉜 fMP4 가 아니면 바이트 범위로 못 나눠서 받지 않아 (ErrNotTS).
+package hls
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+const (
+	// PacketSize MPEG-TS 패킷 크기
+	PacketSize = 188
+	// DefaultSegment 조각 길이 목표 (Apple 권장 6초) - 실제 조각은 다음 키프레임까지라 조금 길어
+	DefaultSegment = 6 * time.Second
+
+	syncByte = 0x47
+	pcrHz    = 27_000_000
+	pcrWrap  = (1 << 33) * 300 // PCR 기준 33비트 × 300 + 확장 9비트
+)
+
+// ErrNotTS MPEG-TS 가 아니거나 시간(PCR)이 없어서 나눌 수 없는 파일
+var ErrNotTS = msg.New("HLS 로 나눌 수 있는 MPEG-TS 가 아닙니다")
+
+// Segment 원본 파일의 한 조각
+type Segment struct {
+	Offset   int64
+	Length   int64
+	Duration time.Duration
+}
+
+// Playlist 파일 하나의 조각들
+type Playlist struct {
+	Segments []Segment
+	Keyframe bool // 키프레임에서 잘랐는지 (아니면 PES 시작 - 조각 앞부분이 깨져 보일 수 있어)
+}
+
+// Duration 전체 길이
+func (p Playlist) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range p.Segments {
+		d += s.Duration
+	}
+	return d
+}
+
+// cut 자를 수 있는 패킷 - 오프셋과 그때까지 본 PCR
+type cut struct {
+	offset int64
+	clock  int64
+}
+
+// Scan r 의 MPEG-TS 를 끝까지 읽어서 segment 길이쯤으로 나눠 - 마지막의 모자란 패킷은 버려
+func Scan(ctx context.Context, r io.Reader, segment time.Duration) (Playlist, error) {
+	if segment <= 0 {
+		segment = DefaultSegment
+	}
+	br := bufio.NewReaderSize(r, 64*PacketSize)
+	var (
+		pkt         [PacketSize]byte
+		offset      int64
+		pcrPID      = -1
+		first       = int64(-1) // 처음 본 PCR (되감기를 이어 붙인 값)
+		clock       = int64(-1) // 마지막으로 본 PCR
+		wraps       int64
+		keyframes   []cut
+		pesStarts   []cut // 키프레임 표시가 하나도 없을 때만 써
+		sawKeyframe bool
+	)
+	for ; ; offset += PacketSize {
+		if offset%(PacketSize<<12) == 0 {
+			if err := ctx.Err(); err != nil {
+				return Playlist{}, err
+			}
+		}
+		if _, err := io.ReadFull(br, pkt[:]); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return Playlist{}, err
+		}
+		if pkt[0] != syncByte {
+			return Playlist{}, msg.Errorf("%w: %d 바이트에 동기 바이트가 없습니다", ErrNotTS, offset)
+		}
+		pid := int(pkt[1]&0x1f)<<8 | int(pkt[2])
+		randomAccess := false
+		if pkt[3]&0x20 != 0 && pkt[4] > 0 { // 적응 필드
+			flags := pkt[5]
+			randomAccess = flags&0x40 != 0
+			if flags&0x10 != 0 && pkt[4] >= 7 && (pcrPID < 0 || pid == pcrPID) {
+				pcrPID = pid
+				base := int64(pkt[6])<<25 | int64(pkt[7])<<17 | int64(pkt[8])<<9 | int64(pkt[9])<<1 | int64(pkt[10])>>7
+				pcr := base*300 + (int64(pkt[10]&1)<<8 | int64(pkt[11])) + wraps*pcrWrap
+				if clock >= 0 && pcr < clock-pcrWrap/2 {
+					wraps++
+					pcr += pcrWrap
+				}
+				clock = pcr
+				if first < 0 {
+					first = pcr
+				}
+			}
+		}
+		if clock < 0 {
+			continue // 시간을 모르는 앞부분은 첫 조각에 붙어
+		}
+		switch {
+		case randomAccess:
+			if !sawKeyframe {
+				sawKeyframe, pesStarts = true, nil
+			}
+			keyframes = append(keyframes, cut{offset, clock})
+		case !sawKeyframe && pid == pcrPID && pkt[1]&0x40 != 0:
+			pesStarts = append(pesStarts, cut{offset, clock})
+		}
+	}
+	if offset == 0 {
+		return Playlist{}, msg.Errorf("%w: 빈 파일", ErrNotTS)
+	}
+	if first < 0 {
+		return Playlist{}, msg.Errorf("%w: PCR 이 없어 길이를 알 수 없습니다", ErrNotTS)
+	}
+
+	cuts := keyframes
+	if !sawKeyframe {
+		cuts = pesStarts
+	}
+	pl := Playlist{Keyframe: sawKeyframe}
+	start, startClock := int64(0), first
+	target := segment.Seconds() * pcrHz
+	for _, c := range cuts {
+		if c.offset > start && float64(c.clock-startClock) >= target {
+			pl.Segments = append(pl.Segments, Segment{Offset: start, Length: c.offset - start, Duration: pcrDuration(c.clock - startClock)})
+			start, startClock = c.offset, c.clock
+		}
+	}
+	last := Segment{Offset: start, Length: offset - start, Duration: pcrDuration(clock - startClock)}
+	if n := len(pl.Segments); n > 0 && last.Duration < time.Second {
+		// 1초도 안 되는 꼬리는 앞 조각에 붙여
+		pl.Segments[n-1].Length += last.Length
+		pl.Segments[n-1].Duration += last.Duration
+	} else {
+		pl.Segments = append(pl.Segments, last)
+	}
+	return pl, nil
+}
+
+func pcrDuration(ticks int64) time.Duration {
+	return time.Duration(ticks * 1000 / (pcrHz / 1_000_000)) // 27 틱이 1µs
+}
+
+// Write VOD 재생 목록을 써 - 조각마다 같은 uri 에 바이트 범위만 달라
+func (p Playlist) Write(w io.Writer, uri string) error {
+	var target float64
+	for _, s := range p.Segments {
+		target = max(target, s.Duration.Seconds())
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "#EXTM3U\n#EXT-X-VERSION:4\n#EXT-X-TARGETDURATION:%d\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n", int(math.Ceil(target)))
+	if p.Keyframe {
+		b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	}
+	for _, s := range p.Segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n#EXT-X-BYTERANGE:%d@%d\n%s\n", s.Duration.Seconds(), s.Length, s.Offset, uri)
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
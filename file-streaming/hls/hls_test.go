@@ -0,0 +1,140 @@
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tsPacket PID 0x100 패킷 하나 - clock 이 0 이상이면 PCR(27MHz 틱)을 싣고, key 면 키프레임 표시
+func tsPacket(clock int64, key, start bool) []byte {
+	p := bytes.Repeat([]byte{0xff}, PacketSize)
+	p[0], p[1], p[2], p[3] = syncByte, 0x01, 0x00, 0x10
+	if start {
+		p[1] |= 0x40
+	}
+	if clock < 0 && !key {
+		return p
+	}
+	p[3] = 0x30
+	p[4], p[5] = 1, 0
+	if key {
+		p[5] |= 0x40
+	}
+	if clock >= 0 {
+		base, ext := (clock/300)%(1<<33), clock%300
+		p[4], p[5] = 7, p[5]|0x10
+		p[6], p[7], p[8], p[9] = byte(base>>25), byte(base>>17), byte(base>>9), byte(base>>1)
+		p[10] = byte(base&1)<<7 | 0x7e | byte(ext>>8)
+		p[11] = byte(ext)
+	}
+	return p
+}
+
+// tsFile seconds 초짜리 - 10ms 마다 패킷, 100ms 마다 PCR, keyEvery 초마다 키프레임 (0 이면 표시 없이 1초마다 PES 시작)
+func tsFile(seconds int, keyEvery int, startClock int64) []byte {
+	var b bytes.Buffer
+	b.Write(tsPacket(-1, false, false)) // PCR 앞의 패킷 (PAT 같은)
+	for i := range seconds * 100 {
+		clock := int64(-1)
+		if i%10 == 0 {
+			clock = startClock + int64(i)*pcrHz/100
+		}
+		key := keyEvery > 0 && i%(keyEvery*100) == 0
+		start := keyEvery == 0 && i%100 == 0
+		b.Write(tsPacket(clock, key, start))
+	}
+	return b.Bytes()
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		durations []time.Duration
+		keyframe  bool
+	}{
+		// 키프레임이 2초마다라 6초에서 딱 잘리고, 마지막 조각은 마지막 PCR(19.9초)까지
+		{"keyframes", tsFile(20, 2, 0), []time.Duration{6 * time.Second, 6 * time.Second, 6 * time.Second, 1900 * time.Millisecond}, true},
+		// 5초마다면 6초가 넘는 첫 키프레임(10초)에서, 1초가 안 되는 꼬리는 앞 조각에 붙어
+		{"sparse keyframes", tsFile(21, 5, 0), []time.Duration{10 * time.Second, 10900 * time.Millisecond}, true},
+		// 표시가 없으면 PES 시작에서
+		{"pes starts", tsFile(12, 0, 0), []time.Duration{6 * time.Second, 5900 * time.Millisecond}, false},
+		// 33비트 PCR 이 되감겨도 이어져
+		{"wrap", tsFile(8, 2, pcrWrap-3*pcrHz), []time.Duration{6 * time.Second, 1900 * time.Millisecond}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl, err := Scan(t.Context(), bytes.NewReader(append(tt.data, 0x47, 0x00)), DefaultSegment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pl.Keyframe != tt.keyframe {
+				t.Errorf("Keyframe = %v", pl.Keyframe)
+			}
+			var got []time.Duration
+			next := int64(0)
+			for _, s := range pl.Segments {
+				got = append(got, s.Duration)
+				if s.Offset != next || s.Length%PacketSize != 0 {
+					t.Errorf("조각 %+v - 앞 조각에 이어지는 패킷 단위여야 해 (offset %d)", s, next)
+				}
+				next = s.Offset + s.Length
+			}
+			if next != int64(len(tt.data)) {
+				t.Errorf("조각 끝 = %d, want %d (모자란 마지막 패킷만 빼고 다)", next, len(tt.data))
+			}
+			if !slices.Equal(got, tt.durations) {
+				t.Errorf("길이 = %v, want %v", got, tt.durations)
+			}
+		})
+	}
+}
+
+func TestScanNotTS(t *testing.T) {
+	noPCR := bytes.Repeat(tsPacket(-1, false, true), 10)
+	for name, data := range map[string][]byte{
+		"text":   []byte(strings.Repeat("not a transport stream\n", 20)),
+		"empty":  nil,
+		"no pcr": noPCR,
+		"broken": append(tsFile(1, 1, 0), bytes.Repeat([]byte("garbage "), 50)...),
+	} {
+		if _, err := Scan(t.Context(), bytes.NewReader(data), DefaultSegment); !errors.Is(err, ErrNotTS) {
+			t.Errorf("%s: err = %v, want ErrNotTS", name, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	pl := Playlist{Keyframe: true, Segments: []Segment{
+		{Offset: 0, Length: 1880, Duration: 6006 * time.Millisecond},
+		{Offset: 1880, Length: 376, Duration: 2 * time.Second},
+	}}
+	var b strings.Builder
+	if err := pl.Write(&b, "/download?file=a.ts"); err != nil {
+		t.Fatal(err)
+	}
+	want := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-TARGETDURATION:7
+#EXT-X-MEDIA-SEQUENCE:0
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXTINF:6.006,
+#EXT-X-BYTERANGE:1880@0
+/download?file=a.ts
+#EXTINF:2.000,
+#EXT-X-BYTERANGE:376@1880
+/download?file=a.ts
+#EXT-X-ENDLIST
+`
+	if b.String() != want {
+		t.Errorf("재생 목록 =\n%s\nwant\n%s", b.String(), want)
+	}
+	if pl.Duration() != 8006*time.Millisecond {
+		t.Errorf("Duration = %v", pl.Duration())
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/step06-log-analyzer/analyzer"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 디렉토리 감시 데몬
+// inbox 에 파일이 들어오면 (다 써질 때까지 기다렸다가) 압축/분석/업로드 중 하나를 실행해.
+//
+//	go run ./ingest -dir ./inbox -action compress -out ./ingested
+//	go run ./ingest -dir ./inbox -action upload -url http://localhost:8080/upload
+func main() {
+	// 플래그 설명과 출력도 FS_LANG / -lang 을 따라가게 (플래그를 등록하기 전에)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	locale.RegisterFlags(flag.CommandLine)
+	dir := flag.String("dir", "./inbox", msg.T("감시할 디렉토리"))
+	action := flag.String("action", "compress", msg.T("실행할 작업 (compress|analyze|upload)"))
+	out := flag.String("out", "./ingested", msg.T("compress/analyze 결과 저장 디렉토리"))
+	url := flag.String("url", "http://localhost:8080/upload", msg.T("upload 대상 URL"))
+	include := flag.String("include", "", msg.T("처리할 파일 glob (쉼표 구분, 예: *.log,*.txt)"))
+	debounce := flag.Duration("debounce", time.Second, msg.T("마지막 변경 후 대기 시간"))
+	workers := flag.Int("workers", 2, msg.T("동시 실행 작업 수"))
+	poll := flag.Bool("poll", false, msg.T("fsnotify 대신 폴링 사용"))
+	interval := flag.Duration("interval", 2*time.Second, msg.T("폴링 주기"))
+	existing := flag.Bool("existing", false, msg.T("시작 시 이미 있는 파일도 처리"))
+	var logOpts logging.Options
+	flag.StringVar(&logOpts.Level, "log-level", "info", msg.T("로그 레벨 (debug|info|warn|error)"))
+	flag.StringVar(&logOpts.Format, "log-format", "text", msg.T("로그 형식 (text|json)"))
+	flag.Parse()
+
+	if err := logging.Setup(logOpts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var run fstree.WatchAction
+	switch *action {
+	case "compress":
+		run = compressAction(*dir, *out)
+	case "analyze":
+		run = analyzeAction(*out)
+	case "upload":
+		run = uploadAction(*url)
+	default:
+		logging.Fatal("알 수 없는 작업", "action", *action)
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		logging.Fatal("감시 디렉토리 생성 실패", "err", err)
+	}
+
+	// Ctrl+C / SIGTERM 이면 감시를 멈추고 실행 중인 작업이 끝나길 기다려
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := fstree.WatchOptions{
+		// 작업 중 생기는 임시 파일은 무시
+		Exclude:         []string{".*", "*.tmp", "*.part"},
+		Debounce:        *debounce,
+		Concurrency:     *workers,
+		ForcePolling:    *poll,
+		PollInterval:    *interval,
+		ProcessExisting: *existing,
+		OnError: func(path string, err error) {
+			slog.Error("처리 실패", "file", path, "err", err)
+		},
+	}
+	if *include != "" {
+		opts.Include = strings.Split(*include, ",")
+	}
+
+	slog.Info("감시 시작", "dir", *dir, "action", *action, "workers", *workers)
+	if err := fstree.Watch(ctx, *dir, run, opts); err != nil {
+		logging.Fatal("감시 실패", "err", err)
+	}
+	slog.Info("감시 종료")
+}
+
+// compressAction 들어온 파일을 out 아래 같은 상대 경로로 gzip 압축
+func compressAction(root, outDir string) fstree.WatchAction {
+	return func(ctx context.Context, path string) (err error) {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outDir, rel+".gz")
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		// 임시 파일에 쓰고 다 끝나면 rename - 중간에 죽어도 반쪽짜리 .gz 가 안 남아
+		tmp := dst + ".part"
+		output, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			output.Close()
+			if err != nil {
+				os.Remove(tmp)
+			}
+		}()
+
+		gzipWriter := gzip.NewWriter(output)
+		info := streamio.TransferInfo{ID: rel, Src: path, Dst: dst, Size: -1}
+		written, err := streamio.Copy(ctx, gzipWriter, src, info, streamio.CopyOptions{})
+		if err != nil {
+			return err
+		}
+		if err = gzipWriter.Close(); err != nil {
+			return err
+		}
+		if err = output.Close(); err != nil {
+			return err
+		}
+		if err = streamio.Rename(tmp, dst); err != nil {
+			return err
+		}
+
+		slog.InfoContext(ctx, "압축 완료", "file", path, "dst", dst, "bytes", written)
+		return nil
+	}
+}
+
+// analyzeAction step06 분석기로 로그를 분석하고 리포트를 out 에 저장
+func analyzeAction(outDir string) fstree.WatchAction {
+	return func(ctx context.Context, path string) error {
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return err
+		}
+
+		la := analyzer.NewLogAnalyzer()
+		la.ProgressMode = streamio.ProgressNone
+		if err := la.AnalyzerFile(path); err != nil {
+			return err
+		}
+
+		report := filepath.Join(outDir, filepath.Base(path)+".report.txt")
+		if err := la.SaveReport(report); err != nil {
+			return err
+		}
+
+		slog.InfoContext(ctx, "분석 완료", "file", path, "report", report, "lines", la.Stats().TotalLines)
+		return nil
+	}
+}
+
+// uploadAction 파일을 멀티파트로 스트리밍 업로드 (step09 서버의 /upload)
+// ⭐ io.Pipe 로 멀티파트 본문을 만들면서 동시에 보내서, 파일을 메모리에 올리지 않아
+func uploadAction(url string) fstree.WatchAction {
+	return func(ctx context.Context, path string) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		pr, pw := io.Pipe()
+		mw := multipart.NewWriter(pw)
+
+		go func() {
+			part, err := mw.CreateFormFile("file", filepath.Base(path))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(part, file); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			pw.CloseWithError(mw.Close())
+		}()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+		if err != nil {
+			pr.Close()
+			return err
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode/100 != 2 {
+			return msg.Errorf("업로드 실패: %s", resp.Status)
+		}
+
+		slog.InfoContext(ctx, "업로드 완료", "file", path)
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+// Package logging 은 모든 도구/단계가 같이 쓰는 slog 설정이야.
+// 앱(main)이 시작할 때 Setup 을 한 번 부르고, 패키지들은 For("컴포넌트") 로 받은 로거만 써.
+//
+// ⭐ 로그와 결과 출력은 구분해 - 분석 보고서, 목록, 표처럼 프로그램의 "결과"는 그대로 stdout(fmt)에,
+// 시작/완료/실패/재시도 같은 "일어난 일"은 slog 로 stderr 에 남겨. 그래야 -json 결과를 파이프로 넘겨도 로그가 섞이지 않아.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options 로그 설정
+type Options struct {
+	Level  string    // debug | info | warn | error (기본 info)
+	Format string    // text | json (기본 text)
+	Output io.Writer // 기본 os.Stderr
+}
+
+// Setup 전역 기본 로거(slog.Default) 설정 - log 패키지로 찍는 로그도 같은 핸들러로 가
+func Setup(opts Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+	out := opts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	ho := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		h = slog.NewTextHandler(out, ho)
+	case "json":
+		h = slog.NewJSONHandler(out, ho)
+	default:
+		return msg.Errorf("알 수 없는 로그 형식: %q (text, json)", opts.Format)
+	}
+	slog.SetDefault(slog.New(traceHandler{h}))
+	return nil
+}
+
+// SetupFromEnv LOG_LEVEL, LOG_FORMAT 환경 변수로 Setup (플래그가 없는 단계 예제용) - 값이 잘못되면 기본값으로
+func SetupFromEnv() {
+	opts := Options{Level: os.Getenv("LOG_LEVEL"), Format: os.Getenv("LOG_FORMAT")}
+	if err := Setup(opts); err != nil {
+		Setup(Options{})
+		slog.Warn("로그 설정 무시", "err", err)
+	}
+}
+
+// Fatal Error 로그를 남기고 종료 (slog 에는 log.Fatal 같은 게 없어서)
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// ParseLevel "debug", "info", "warn", "error" (빈 문자열은 info)
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, msg.Errorf("알 수 없는 로그 레벨: %q (debug, info, warn, error)", s)
+	}
+	return level, nil
+}
+
+// For component 속성이 붙은 로거
+// ⭐ 패키지 변수로 잡아둬도 되게, 로그를 찍는 순간의 slog.Default() 로 보내 (Setup 보다 먼저 만들어진 로거도 설정을 따라가)
+func For(component string) *slog.Logger {
+	return slog.New(lazyHandler{apply: func(h slog.Handler) slog.Handler {
+		return h.WithAttrs([]slog.Attr{slog.String("component", component)})
+	}})
+}
+
+type ctxKey struct{}
+
+// WithContext 요청 하나에 쓸 로거를 ctx 에 담아 (요청 ID 등이 붙은 로거)
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext ctx 에 담긴 로거 (없으면 fallback, fallback 도 nil 이면 slog.Default())
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return slog.Default()
+}
+
+// lazyHandler 로그를 찍을 때마다 현재 기본 핸들러에 apply(WithAttrs/WithGroup)를 적용해서 넘겨
+type lazyHandler struct {
+	apply func(slog.Handler) slog.Handler
+}
+
+func (h lazyHandler) target() slog.Handler { return h.apply(slog.Default().Handler()) }
+
+func (h lazyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h lazyHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.target().Handle(ctx, r)
+}
+
+func (h lazyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return lazyHandler{apply: func(t slog.Handler) slog.Handler { return h.apply(t).WithAttrs(attrs) }}
+}
+
+func (h lazyHandler) WithGroup(name string) slog.Handler {
+	return lazyHandler{apply: func(t slog.Handler) slog.Handler { return h.apply(t).WithGroup(name) }}
+}
+
+// traceHandler ctx 에 기록 중인 스팬이 있으면 trace_id/span_id 를 붙여 - 트레이싱 백엔드에서 로그와 스팬을 이어 볼 수 있게
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
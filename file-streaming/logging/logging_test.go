@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupBuffer Setup 을 buf 로 - 끝나면 원래 기본 로거로 돌려놔
+func setupBuffer(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+	old := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(old) })
+	var buf bytes.Buffer
+	opts.Output = &buf
+	if err := Setup(opts); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+// jsonLines JSON 로그를 줄마다 맵으로
+func jsonLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("JSON 이 아닌 줄: %q", line)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("모르는 레벨인데 에러가 없음")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	buf := setupBuffer(t, Options{Level: "warn", Format: "json"})
+	slog.Info("안 보여야 해")
+	slog.Warn("보여야 해", "n", 1)
+
+	lines := jsonLines(t, buf)
+	if len(lines) != 1 || lines[0]["msg"] != "보여야 해" || lines[0]["level"] != "WARN" {
+		t.Errorf("로그 = %v", lines)
+	}
+
+	if err := Setup(Options{Format: "xml"}); err == nil {
+		t.Error("모르는 형식인데 에러가 없음")
+	}
+	buf = setupBuffer(t, Options{})
+	slog.Info("hello")
+	if !strings.Contains(buf.String(), "level=INFO msg=hello") {
+		t.Errorf("text 로그 = %q", buf.String())
+	}
+}
+
+// Setup 보다 먼저 만든 컴포넌트 로거도 나중 설정을 따라가
+func TestFor(t *testing.T) {
+	logger := For("copy").With("job", 7).WithGroup("g")
+	buf := setupBuffer(t, Options{Format: "json"})
+	logger.Info("done", "bytes", 10)
+	For("copy").Debug("info 레벨이라 안 보여야 해")
+
+	lines := jsonLines(t, buf)
+	if len(lines) != 1 {
+		t.Fatalf("로그 %d 줄, want 1: %q", len(lines), buf.String())
+	}
+	l := lines[0]
+	if l["component"] != "copy" || l["job"] != float64(7) {
+		t.Errorf("속성 = %v", l)
+	}
+	if g, _ := l["g"].(map[string]any); g["bytes"] != float64(10) {
+		t.Errorf("그룹 = %v", l["g"])
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := t.Context()
+	fallback := slog.New(slog.DiscardHandler)
+	if FromContext(ctx, nil) != slog.Default() {
+		t.Error("아무것도 없으면 slog.Default 여야 해")
+	}
+	if FromContext(ctx, fallback) != fallback {
+		t.Error("ctx 에 없으면 fallback 이어야 해")
+	}
+	reqLogger := slog.New(slog.DiscardHandler).With("req", "abc")
+	if FromContext(WithContext(ctx, reqLogger), fallback) != reqLogger {
+		t.Error("ctx 에 담은 로거가 먼저여야 해")
+	}
+}
+
+// ctx 에 스팬이 있으면 trace_id/span_id 가 붙어
+func TestTraceIDs(t *testing.T) {
+	buf := setupBuffer(t, Options{Format: "json"})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	slog.InfoContext(ctx, "with span")
+	For("server").InfoContext(ctx, "component too")
+	slog.Info("without span")
+
+	lines := jsonLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("로그 %d 줄, want 3", len(lines))
+	}
+	for _, l := range lines[:2] {
+		if l["trace_id"] != sc.TraceID().String() || l["span_id"] != sc.SpanID().String() {
+			t.Errorf("%v: trace_id/span_id 가 없음", l["msg"])
+		}
+	}
+	if _, ok := lines[2]["trace_id"]; ok {
+		t.Error("스팬이 없는데 trace_id 가 붙음")
+	}
+}
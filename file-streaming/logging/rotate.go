@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 크기로 돌리는 로그 파일 (서버 접근 로그 등)
+// ⭐ 쓰려는 줄이 MaxSize 를 넘기면 path → path.1 → path.2 … 로 밀어내고 새 path 에 이어 써 - Backups 보다 오래된 건 지워.
+// 한 번의 Write 는 통째로 한 파일에 들어가니까 줄 단위로 Write 하면 줄이 두 파일에 갈라지지 않아.
+// logrotate 같은 바깥 도구가 파일을 옮기면 다음 Write 가 path 를 새로 열어 (바로 열고 싶으면 Reopen).
+//
+// 같은 프로세스에서 한 경로를 여러 RotatingFile 로 열어도 돼 (설정을 다시 읽은 뒤, 접근 로그와 앱 로그가 한 파일일 때) -
+// 쓰기와 돌리기는 streamio.LockPath 로 줄 세우고, 쓰기 전에 다른 쪽이 그새 돌렸으면 새 path 를 다시 열어서 크기도 맞춰.
+
+// 기본값
+const (
+	DefaultMaxSize = 100 << 20 // 100MB
+	DefaultBackups = 5
+)
+
+// RotatingFile 크기가 차면 돌리는 파일 (동시에 써도 돼)
+type RotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+
+	// 아래는 streamio.LockPath(path) 를 잡고서만
+	f    *os.File
+	size int64
+}
+
+// OpenRotating path 를 이어 쓰기로 열어 (maxSize 가 0 이하면 DefaultMaxSize, backups 가 음수면 DefaultBackups, 0 이면 돌릴 때 예전 내용을 버려)
+func OpenRotating(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if backups < 0 {
+		backups = DefaultBackups
+	}
+	r := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// Write p 를 이어 써 - p 를 쓰면 MaxSize 를 넘길 때는 먼저 돌려 (빈 파일이면 MaxSize 보다 커도 그대로 써)
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	unlock := streamio.LockPath(r.path)
+	defer unlock()
+	if err := r.follow(); err != nil {
+		return 0, err
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// follow 같은 경로를 연 다른 RotatingFile 이 그새 돌렸으면 새 path 를 다시 열고, 덧붙였으면 크기를 맞춰
+func (r *RotatingFile) follow() error {
+	info, err := r.f.Stat()
+	if err != nil {
+		return err
+	}
+	if cur, err := os.Stat(r.path); err != nil || !os.SameFile(info, cur) {
+		r.f.Close()
+		return r.open()
+	}
+	r.size = info.Size()
+	return nil
+}
+
+// rotate path.N-1 → path.N, …, path → path.1 로 밀고 새 path 를 열어
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.backups == 0 {
+		os.Remove(r.path)
+	} else {
+		os.Remove(r.backup(r.backups))
+		for i := r.backups - 1; i >= 1; i-- {
+			os.Rename(r.backup(i), r.backup(i+1)) // 없는 번호는 건너뛰어
+		}
+		if err := os.Rename(r.path, r.backup(1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return r.open()
+}
+
+func (r *RotatingFile) backup(i int) string { return r.path + "." + strconv.Itoa(i) }
+
+// Reopen path 를 다시 열어 (바깥에서 파일을 옮기거나 지운 뒤)
+func (r *RotatingFile) Reopen() error {
+	unlock := streamio.LockPath(r.path)
+	defer unlock()
+	r.f.Close()
+	return r.open()
+}
+
+// Close 파일을 닫아
+func (r *RotatingFile) Close() error {
+	unlock := streamio.LockPath(r.path)
+	defer unlock()
+	return r.f.Close()
+}
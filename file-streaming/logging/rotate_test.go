@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	r, err := OpenRotating(path, 100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 40 바이트 줄 10개 - 파일마다 두 줄씩, 돌릴 때마다 .1 → .2 로 밀리고 .2 는 버려
+	for i := range 10 {
+		line := fmt.Sprintf("%-39d\n", i)
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string]string{path: "8,9", path + ".1": "6,7", path + ".2": "4,5"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for line := range strings.Lines(string(data)) {
+			got = append(got, strings.TrimSpace(line))
+		}
+		if strings.Join(got, ",") != want {
+			t.Errorf("%s = %q, want %s", filepath.Base(file), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("backups 2 인데 .3 이 있음 (err=%v)", err)
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 이미 있는 크기까지 세서 다음 줄이 넘치면 돌려
+	r, err := OpenRotating(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("new1\n"))
+	r.Close()
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "old\n" {
+		t.Errorf(".1 = %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new1\n" {
+		t.Errorf("현재 파일 = %q", data)
+	}
+}
+
+// 같은 경로를 연 RotatingFile 두 개가 동시에 써도 줄이 섞이거나 사라지지 않고, 파일마다 MaxSize 를 안 넘어
+func TestRotatingFileSharedPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var files [2]*RotatingFile
+	for i := range files {
+		r, err := OpenRotating(path, 100, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		files[i] = r
+	}
+
+	var wg sync.WaitGroup
+	for w, r := range files {
+		wg.Go(func() {
+			for i := range 50 {
+				if _, err := fmt.Fprintf(r, "writer %d line %03d\n", w, i); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	names, _ := filepath.Glob(path + "*")
+	seen := map[string]bool{}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 100 {
+			t.Errorf("%s = %d 바이트, MaxSize 100 을 넘음", filepath.Base(name), len(data))
+		}
+		for line := range strings.Lines(string(data)) {
+			if seen[line] {
+				t.Errorf("같은 줄이 두 번: %q", line)
+			}
+			seen[line] = true
+		}
+	}
+	if len(seen) != 100 {
+		t.Errorf("남은 줄 %d 개, want 100", len(seen))
+	}
+}
+
+// 바깥에서 옮기면 다음 Write 가 path 를 새로 만들어
+func TestRotatingFileMovedAway(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := OpenRotating(path, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.Write([]byte("before\n"))
+	if err := os.Rename(path, path+".old"); err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("after\n"))
+	if data, _ := os.ReadFile(path); string(data) != "after\n" {
+		t.Errorf("새 파일 = %q", data)
+	}
+	if data, _ := os.ReadFile(path + ".old"); string(data) != "before\n" {
+		t.Errorf("옮긴 파일 = %q", data)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 디렉토리 체크섬 매니페스트 도구 (SHA256SUMS 형식)
+//
+//	go run ./manifest create <디렉토리> [-o SHA256SUMS]
+//	go run ./manifest verify <디렉토리> [-m SHA256SUMS]
+//	go run ./manifest create <디렉토리> -o - | ssh host 'cd dir && sha256sum -c'
+func main() {
+	// 사용법, 플래그 설명, 결과도 FS_LANG / -lang 을 따라가게
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	if len(os.Args) < 3 {
+		usage()
+	}
+
+	cmd, dir := os.Args[1], os.Args[2]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	locale.RegisterFlags(fs)
+	file := fs.String("m", "SHA256SUMS", msg.T("매니페스트 파일 경로 (- 면 create 는 stdout, verify 는 stdin)"))
+	fs.StringVar(file, "o", "SHA256SUMS", msg.T("매니페스트 파일 경로 (-m 과 같음)"))
+	workers := fs.Int("workers", 0, msg.T("병렬 해시 워커 수 (0 이면 CPU 수)"))
+	fs.Parse(os.Args[3:])
+	logging.SetupFromEnv()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := fstree.ManifestOptions{Workers: *workers}
+	// 매니페스트를 대상 디렉토리 안에 두는 경우 자기 자신은 제외
+	opts.Walk.Exclude = []string{filepath.Base(*file)}
+
+	switch cmd {
+	case "create":
+		err = create(ctx, dir, *file, opts)
+	case "verify":
+		err = verify(ctx, dir, *file, opts)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		logging.Fatal("실패", "command", cmd, "err", err)
+	}
+}
+
+func usage() {
+	fmt.Println(msg.T("사용법:"))
+	fmt.Println(msg.T("  go run ./manifest create <디렉토리> [-o SHA256SUMS]"))
+	fmt.Println(msg.T("  go run ./manifest verify <디렉토리> [-m SHA256SUMS]"))
+	os.Exit(2)
+}
+
+func create(ctx context.Context, dir, path string, opts fstree.ManifestOptions) (err error) {
+	if path == "-" {
+		// stdout 은 매니페스트 용이라 개수 안내는 stderr 로
+		count, err := fstree.WriteManifest(ctx, dir, os.Stdout, opts)
+		msg.Fprintf(os.Stderr, "%d개 파일의 체크섬을 stdout 으로 내보냈습니다\n", count)
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	count, err := fstree.WriteManifest(ctx, dir, out, opts)
+	msg.Printf("%d개 파일의 체크섬을 %s 에 저장했습니다\n", count, path)
+	return err
+}
+
+func verify(ctx context.Context, dir, path string, opts fstree.ManifestOptions) error {
+	in := os.Stdin
+	if path != "-" {
+		var err error
+		if in, err = os.Open(path); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+
+	sums, err := fstree.ReadManifest(in)
+	if err != nil {
+		return err
+	}
+
+	report, err := fstree.VerifyManifest(ctx, dir, sums, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range report.Missing {
+		msg.Printf("누락:   %s\n", rel)
+	}
+	for _, rel := range report.Extra {
+		msg.Printf("추가됨: %s\n", rel)
+	}
+	for _, rel := range report.Corrupted {
+		msg.Printf("손상:   %s\n", rel)
+	}
+	for _, e := range report.Errors {
+		msg.Printf("에러:   %s\n", e)
+	}
+	fmt.Println(report)
+
+	if !report.Clean() {
+		return msg.Errorf("검증 실패")
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+// Package metrics 는 Prometheus 텍스트 형식(/metrics)으로 내보내는 작은 카운터/게이지/히스토그램이야.
+// 클라이언트 라이브러리 없이 필요한 만큼만 - 레이블 값 조합마다 시계열 하나, 내보낼 때 이름과 레이블 순으로 정렬해.
+//
+//	reg := metrics.NewRegistry()
+//	reqs := reg.Counter("fs_http_requests_total", "처리한 요청 수", "handler", "code")
+//	reqs.Inc("/download", "200")
+//	http.Handle("/metrics", reg)
+//
+// ⭐ 레이블 값은 handler, code 처럼 가짓수가 정해진 것만 - 파일 이름이나 IP 를 넣으면 시계열이 끝없이 늘어나.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType Prometheus 텍스트 형식 0.0.4
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// 기본 버킷 (초)
+var (
+	DefBuckets      = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10} // 짧은 요청
+	TransferBuckets = []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900, 3600}         // 파일 전송
+)
+
+// Registry 내보낼 지표 모음 (http.Handler 라서 그대로 /metrics 에 달면 돼)
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry { return &Registry{} }
+
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, old := range r.metrics {
+		if old.name() == m.name() {
+			panic("metrics: 같은 이름을 두 번 등록: " + m.name())
+		}
+	}
+	r.metrics = append(r.metrics, m)
+	sort.Slice(r.metrics, func(i, j int) bool { return r.metrics[i].name() < r.metrics[j].name() })
+}
+
+// WriteTo 모든 지표를 텍스트 형식으로
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	ms := slices.Clone(r.metrics)
+	r.mu.Unlock()
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, m := range ms {
+		m.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP GET /metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+// vec 레이블 값 조합별 시계열
+type vec[T any] struct {
+	fqName, help, kind string
+	labels             []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+	newT   func() *T
+}
+
+func newVec[T any](name, help, kind string, labels []string, newT func() *T) *vec[T] {
+	return &vec[T]{fqName: name, help: help, kind: kind, labels: labels, series: map[string]*T{}, values: map[string][]string{}, newT: newT}
+}
+
+func (v *vec[T]) name() string { return v.fqName }
+
+// get 레이블 값의 시계열 (처음이면 만들어) - 값 개수가 레이블 수와 다르면 panic (코드 실수라서)
+func (v *vec[T]) get(lvs []string) *T {
+	if len(lvs) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s 의 레이블은 %d개인데 값 %d개", v.fqName, len(v.labels), len(lvs)))
+	}
+	key := strings.Join(lvs, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = v.newT()
+		v.series[key] = s
+		v.values[key] = slices.Clone(lvs)
+	}
+	return s
+}
+
+// each 레이블 값 순으로 시계열마다
+func (v *vec[T]) each(fn func(lvs []string, s *T)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	type pair struct {
+		lvs []string
+		s   *T
+	}
+	pairs := make([]pair, len(keys))
+	for i, k := range keys {
+		pairs[i] = pair{v.values[k], v.series[k]}
+	}
+	v.mu.Unlock()
+	for _, p := range pairs {
+		fn(p.lvs, p.s)
+	}
+}
+
+func (v *vec[T]) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.fqName, escapeHelp(v.help), v.fqName, v.kind)
+}
+
+// value 값 하나 (Counter, Gauge)
+type value struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (x *value) add(d float64) {
+	x.mu.Lock()
+	x.v += d
+	x.mu.Unlock()
+}
+
+func (x *value) load() float64 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.v
+}
+
+// Counter 늘기만 하는 값
+type Counter struct{ v *vec[value] }
+
+// Counter 카운터 등록 (이름은 _total 로 끝나게)
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{newVec(name, help, "counter", labels, func() *value { return &value{} })}
+	r.add(c)
+	return c
+}
+
+func (c *Counter) name() string { return c.v.fqName }
+
+// Add d(0 이상)를 더해 (음수는 무시 - 카운터는 줄지 않아)
+func (c *Counter) Add(d float64, labelValues ...string) {
+	if d < 0 {
+		return
+	}
+	c.v.get(labelValues).add(d)
+}
+
+// Inc 1 더해
+func (c *Counter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+// Value 지금 값 (테스트용)
+func (c *Counter) Value(labelValues ...string) float64 { return c.v.get(labelValues).load() }
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.v.header(w)
+	c.v.each(func(lvs []string, s *value) {
+		writeSample(w, c.v.fqName, c.v.labels, lvs, "", "", s.load())
+	})
+}
+
+// Gauge 오르내리는 값
+type Gauge struct{ v *vec[value] }
+
+// Gauge 게이지 등록
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{newVec(name, help, "gauge", labels, func() *value { return &value{} })}
+	r.add(g)
+	return g
+}
+
+func (g *Gauge) name() string { return g.v.fqName }
+
+// Add d 만큼 (음수면 줄어)
+func (g *Gauge) Add(d float64, labelValues ...string) { g.v.get(labelValues).add(d) }
+
+// Inc, Dec 1 씩
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+// Value 지금 값 (테스트용)
+func (g *Gauge) Value(labelValues ...string) float64 { return g.v.get(labelValues).load() }
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.v.header(w)
+	g.v.each(func(lvs []string, s *value) {
+		writeSample(w, g.v.fqName, g.v.labels, lvs, "", "", s.load())
+	})
+}
+
+// Histogram 값의 분포 (버킷마다 누적 개수, 합, 개수)
+type Histogram struct {
+	v       *vec[histSeries]
+	buckets []float64
+}
+
+type histSeries struct {
+	mu     sync.Mutex
+	counts []uint64 // 버킷별 (누적 아님)
+	sum    float64
+	count  uint64
+}
+
+// Histogram 히스토그램 등록 (buckets 는 오름차순, +Inf 는 알아서 붙어)
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	bs := slices.Clone(buckets)
+	slices.Sort(bs)
+	h := &Histogram{buckets: bs}
+	h.v = newVec(name, help, "histogram", labels, func() *histSeries { return &histSeries{counts: make([]uint64, len(bs))} })
+	r.add(h)
+	return h
+}
+
+func (h *Histogram) name() string { return h.v.fqName }
+
+// Observe 값 하나를 기록
+func (h *Histogram) Observe(x float64, labelValues ...string) {
+	s := h.v.get(labelValues)
+	i := sort.SearchFloat64s(h.buckets, x) // x 이상인 첫 버킷 (le 는 "이하")
+	s.mu.Lock()
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.sum += x
+	s.count++
+	s.mu.Unlock()
+}
+
+// Count 기록한 개수 (테스트용)
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	s := h.v.get(labelValues)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.v.header(w)
+	h.v.each(func(lvs []string, s *histSeries) {
+		s.mu.Lock()
+		counts, sum, count := slices.Clone(s.counts), s.sum, s.count
+		s.mu.Unlock()
+		var cum uint64
+		for i, b := range h.buckets {
+			cum += counts[i]
+			writeSample(w, h.v.fqName+"_bucket", h.v.labels, lvs, "le", formatFloat(b), float64(cum))
+		}
+		writeSample(w, h.v.fqName+"_bucket", h.v.labels, lvs, "le", "+Inf", float64(count))
+		writeSample(w, h.v.fqName+"_sum", h.v.labels, lvs, "", "", sum)
+		writeSample(w, h.v.fqName+"_count", h.v.labels, lvs, "", "", float64(count))
+	})
+}
+
+// writeSample name{l1="v1",...,extra="x"} value
+func writeSample(w *bufio.Writer, name string, labels, lvs []string, extraName, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", l, escapeLabel(lvs[i]))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(v string) string { return labelEscaper.Replace(v) }
+func escapeHelp(v string) string  { return helpEscaper.Replace(v) }
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryText(t *testing.T) {
+	reg := NewRegistry()
+	reqs := reg.Counter("fs_requests_total", "처리한 요청 수", "handler", "code")
+	active := reg.Gauge("fs_active", "진행 중")
+	dur := reg.Histogram("fs_duration_seconds", "걸린 시간", []float64{1, 0.1}, "handler")
+
+	reqs.Inc("/upload", "200")
+	reqs.Add(2, "/download", "200")
+	reqs.Inc("/download", "404")
+	reqs.Add(-5, "/download", "200") // 카운터는 줄지 않아
+	active.Inc()
+	active.Inc()
+	active.Dec()
+	dur.Observe(0.05, `/a"b`)
+	dur.Observe(0.1, `/a"b`) // le 는 "이하"라 0.1 버킷에 들어가
+	dur.Observe(3, `/a"b`)
+
+	var b strings.Builder
+	if _, err := reg.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP fs_active 진행 중
+# TYPE fs_active gauge
+fs_active 1
+# HELP fs_duration_seconds 걸린 시간
+# TYPE fs_duration_seconds histogram
+fs_duration_seconds_bucket{handler="/a\"b",le="0.1"} 2
+fs_duration_seconds_bucket{handler="/a\"b",le="1"} 2
+fs_duration_seconds_bucket{handler="/a\"b",le="+Inf"} 3
+fs_duration_seconds_sum{handler="/a\"b"} 3.15
+fs_duration_seconds_count{handler="/a\"b"} 3
+# HELP fs_requests_total 처리한 요청 수
+# TYPE fs_requests_total counter
+fs_requests_total{handler="/download",code="200"} 2
+fs_requests_total{handler="/download",code="404"} 1
+fs_requests_total{handler="/upload",code="200"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("출력:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("fs_x_total", "x").Inc()
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "fs_x_total 1\n") {
+		t.Errorf("본문 = %q", rec.Body.String())
+	}
+}
+
+func TestDuplicateNamePanics(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("fs_x_total", "x")
+	defer func() {
+		if recover() == nil {
+			t.Error("같은 이름을 두 번 등록했는데 panic 이 없음")
+		}
+	}()
+	reg.Gauge("fs_x_total", "x")
+}
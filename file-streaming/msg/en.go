@@ -0,0 +1,987 @@
+package msg
+
+// en 영어 카탈로그 - 키는 코드에 쓴 한국어 원문 그대로 (띄어쓰기, \n, % 동사까지 똑같아야 찾아)
+//
+// ⭐ 번역에서도 %d, %s 같은 동사는 원문과 같은 순서로 둬. 순서를 바꿔야 하면 %[2]s 처럼 번호를 붙이고,
+// msg_test.go 가 원문과 번역의 동사가 짝이 맞는지 확인해 줘.
+var en = map[string]string{
+	"알 수 없는 언어: %q (ko, en)":                                 "unknown language: %q (ko, en)",
+	"비밀번호가 틀렸거나 key 파일이 손상됐습니다":                              "wrong password or damaged key file",
+	"설정 파일 읽기 실패: %w":                                        "failed to read config file: %w",
+	"설정 파일 %s: %w":                                           "config file %s: %w",
+	"transfer.buffer 는 1B ~ 64MB 여야 합니다: %s":                 "transfer.buffer must be between 1B and 64MB: %s",
+	"transfer.rate 는 0 이상이어야 합니다: %s":                        "transfer.rate must be 0 or more: %s",
+	"transfer.retries 는 0 이상이어야 합니다: %d":                     "transfer.retries must be 0 or more: %d",
+	"지원하지 않는 compress.codec: %q (gzip)":                      "unsupported compress.codec: %q (gzip)",
+	"compress.level 은 -2 ~ 9 여야 합니다: %d":                     "compress.level must be between -2 and 9: %d",
+	"server.addr 가 비어 있습니다":                                  "server.addr is empty",
+	"server.upload_dir 가 비어 있습니다":                            "server.upload_dir is empty",
+	"server.trash_dir 가 비어 있습니다":                             "server.trash_dir is empty",
+	"server.max_upload 는 0 이상이어야 합니다: %s":                    "server.max_upload must be 0 or more: %s",
+	"schedule.jobs[%d]: name 이 비어 있습니다":                      "schedule.jobs[%d]: name is empty",
+	"%s: 같은 이름의 작업이 또 있습니다":                                  "%s: another job has the same name",
+	"%s: 알 수 없는 task: %q (%s)":                               "%s: unknown task: %q (%s)",
+	"%s: src 가 비어 있습니다":                                      "%s: src is empty",
+	"%s: sync 에는 dst 가 필요합니다":                                "%s: sync needs dst",
+	"%s: jitter, timeout, older_than 은 0 이상이어야 합니다":          "%s: jitter, timeout and older_than must be 0 or more",
+	"queue.dir 가 비어 있습니다":                                    "queue.dir is empty",
+	"queue.workers 는 1 ~ 64 여야 합니다: %d":                      "queue.workers must be between 1 and 64: %d",
+	"알 수 없는 checksum.store: %q (%s)":                         "unknown checksum.store: %q (%s)",
+	"checksum.store 가 db 인데 checksum.db 가 비어 있습니다":           "checksum.store is db but checksum.db is empty",
+	"extract.max_files 는 1 이상이어야 합니다: %d":                    "extract.max_files must be 1 or more: %d",
+	"extract.max_file_size, extract.max_total 은 0 보다 커야 합니다": "extract.max_file_size and extract.max_total must be greater than 0",
+	"cache.max_size, cache.max_age 는 0 보다 커야 합니다":            "cache.max_size and cache.max_age must be greater than 0",
+	"알 수 없는 notify.on: %q (%s)":                              "unknown notify.on: %q (%s)",
+	"notify.retries, notify.timeout 은 0 이상이어야 합니다":           "notify.retries and notify.timeout must be 0 or more",
+	"notify.smtp 를 쓰려면 notify.from 과 notify.to 가 필요합니다":      "notify.smtp needs notify.from and notify.to",
+	"plugins.%s: command 가 비어 있습니다":                          "plugins.%s: command is empty",
+	"plugins.%s: timeout 은 0 이상이어야 합니다":                      "plugins.%s: timeout must be 0 or more",
+	"알 수 없는 log.format: %q (text, json)":                     "unknown log.format: %q (text, json)",
+	"환경 변수 %s: %w":                                           "environment variable %s: %w",
+	"시간 간격이 아닙니다 (예: 30s, 10m, 168h): %q":                    "not a duration (e.g. 30s, 10m, 168h): %q",
+	"정수가 아닙니다: %q":                                           "not an integer: %q",
+	"true/false 가 아닙니다: %q":                                  "not true/false: %q",
+	"지원하지 않는 필드 타입: %s":                                      "unsupported field type: %s",
+	"복사 버퍼 크기 (예: 64KB, 1MB)":                                "copy buffer size (e.g. 64KB, 1MB)",
+	"초당 최대 전송량 (예: 10MB, 0 이면 제한 없음)":                        "maximum transfer rate per second (e.g. 10MB, 0 for unlimited)",
+	"진행률 출력 방식 (none|text|bar|json) - stderr 로 나가":           "progress output (none|text|bar|json) - written to stderr",
+	"압축 방식 (gzip)":                                           "compression codec (gzip)",
+	"압축 레벨 (1 빠름 ~ 9 작음, -1 기본)":                             "compression level (1 fastest ~ 9 smallest, -1 default)",
+	"listen 주소":                           "listen address",
+	"업로드/다운로드 디렉토리":                       "upload/download directory",
+	"삭제한 파일을 옮겨둘 휴지통":                     "trash directory for deleted files",
+	"/ 에서 내장 UI 대신 보여줄 페이지 (비우면 내장 UI)":   "page to serve at / instead of the built-in UI (empty for the built-in UI)",
+	"업로드 한 건의 최대 크기 (예: 1GB, 0 이면 제한 없음)": "maximum size of a single upload (e.g. 1GB, 0 for unlimited)",
+	"전문 검색 색인 파일":                         "full-text search index file",
+	"리포트를 저장할 파일":                         "file to save the report to",
+	"분석 요약을 덧붙일 공유 저널 파일 (여러 인스턴스가 동시에 써도 안전)":          "shared journal file to append the summary to (safe for concurrent instances)",
+	"줄마다 파싱한 레코드(시간, 레벨, IP, 경로, 상태, 지연)를 쓸 Parquet 파일": "Parquet file for the per-line records (time, level, IP, path, status, latency)",
+	"작업 큐 디렉토리":                          "job queue directory",
+	"동시에 돌릴 작업 수":                        "number of jobs to run at once",
+	"체크섬 기록을 둘 곳 (off|xattr|db)":         "where to keep checksum records (off|xattr|db)",
+	"checksum-store 가 db 일 때 사이드카 DB 파일": "sidecar DB file when checksum-store is db",
+	"아카이브 엔트리 수 한도":                      "maximum number of archive entries",
+	"풀린 파일 하나의 크기 한도 (예: 1GB)":           "maximum size of one extracted file (e.g. 1GB)",
+	"풀린 전체 크기 한도 (예: 4GB)":               "maximum total extracted size (e.g. 4GB)",
+	"문구 언어 (ko|en, 비우면 LANG 환경 변수로)":     "message language (ko|en, empty to follow LANG)",
+	"SIGUSR1 을 받으면 상태를 덧붙일 파일 (.json/.jsonl 이면 JSON 한 줄, 비우면 stderr)":  "file to append the status to on SIGUSR1 (.json/.jsonl for one JSON line, empty for stderr)",
+	"원격(sftp://) 원본을 이 디렉토리에 캐시해서 다음에는 원본이 안 바뀌었으면 캐시에서 읽어":            "cache remote (sftp://) sources in this directory and read from it while the source is unchanged",
+	"캐시 크기 한도 (넘으면 오래 안 쓴 것부터 지워)":                                     "cache size limit (least recently used entries are evicted first)",
+	"끝나면 결과를 POST 할 웹훅 URL":                                            "webhook URL to POST the result to when done",
+	"알림을 보낼 때: all | failure":                                          "when to notify: all | failure",
+	"로그 레벨 (debug|info|warn|error)":                                    "log level (debug|info|warn|error)",
+	"로그 형식 (text|json) - stderr 로 나가":                                  "log format (text|json) - written to stderr",
+	"OpenTelemetry 트레이스 대상 (stderr, grpc://호스트:4317, http://호스트:4318)": "OpenTelemetry trace target (stderr, grpc://host:4317, http://host:4318)",
+	"YAML 설정 파일 (기본: %s 환경 변수)":                                        "YAML config file (default: %s environment variable)",
+	"대상 디렉토리 밖을 가리키는 경로":                                               "path points outside the target directory",
+	"아카이브 한도 초과":                                                       "archive limit exceeded",
+	"지원하지 않거나 깨진 아카이브 (zip, tar, tar.gz)":                              "unsupported or corrupt archive (zip, tar, tar.gz)",
+	"확장 속성(xattr)을 지원하지 않는 플랫폼/파일시스템입니다 - 사이드카 DB 를 쓰세요":               "extended attributes (xattr) are not supported on this platform/filesystem - use the sidecar DB",
+	"복원할 위치에 이미 파일이 있습니다":                                              "a file already exists at the restore location",
+	"심볼릭 링크 순환":                                                        "symbolic link cycle",
+	"텍스트 파일이 아닙니다":                                                     "not a text file",
+	"캐시 파일이 깨졌습니다":                                                     "cache file is corrupt",
+	"<원본|-> <대상|->":                                                    "<source|-> <destination|->",
+	"파일 복사 (원자적 교체, 재시도, 속도 제한, sftp:// 원격, - 는 stdin/stdout, -filter 로 외부 명령 변환)": "copy a file (atomic replace, retries, rate limit, sftp:// remotes, - for stdin/stdout, -filter for external commands)",
+	"권한/시각/소유자/xattr 까지 복제 (원격이면 수정 시각만)":                                          "also copy permissions/times/owner/xattrs (modification time only for remotes)",
+	"0 블록을 구멍으로 남겨 (sparse 파일)":                                                    "leave zero blocks as holes (sparse files)",
+	"실패 시 재시도 횟수": "retries on failure",
+	"-sparse, -preserve 는 파일끼리 필터 없이 복사할 때만 쓸 수 있어":  "-sparse and -preserve only work for plain file-to-file copies without filters",
+	"-sparse 는 로컬끼리 복사할 때만 쓸 수 있어":                   "-sparse only works for local-to-local copies",
+	"복사 완료: %s → %s (%d 바이트, %.2f MB/s)":             "copied: %s → %s (%d bytes, %.2f MB/s)",
+	"sftp:// 접속에 쓸 개인키 (기본: ssh-agent, ~/.ssh/id_*)": "private key for sftp:// (default: ssh-agent, ~/.ssh/id_*)",
+	"호스트 키 목록 (기본: ~/.ssh/known_hosts)":              "known hosts file (default: ~/.ssh/known_hosts)",
+	"호스트 키 확인 안 함 (테스트 서버용)":                         "skip host key verification (test servers only)",
+	"<파일|->": "<file|->",
+	"파일(- 면 stdin)을 청크로 분할 (기본: 줄 단위로 자름, 매니페스트 저장)": "split a file (- for stdin) into chunks (default: on line boundaries, with a manifest)",
+	"목표 청크 크기":                         "target chunk size",
+	"청크를 저장할 디렉토리":                     "directory to store chunks in",
+	"매니페스트 경로 (기본: <dir>/chunks.json)": "manifest path (default: <dir>/chunks.json)",
+	"줄바꿈과 상관없이 정확히 -size 바이트마다 자르기 (바이너리용)": "cut at exactly -size bytes regardless of newlines (for binary files)",
+	"잘못된 -size 값: %q":                 "invalid -size value: %q",
+	"%d개 청크로 분할 완료 (매니페스트: %s)":       "split into %d chunks (manifest: %s)",
+	"<chunks.json|청크 디렉토리> <출력 파일|->": "<chunks.json|chunk directory> <output file|->",
+	"분할된 청크를 순서대로 병합 (매니페스트가 있으면 체크섬 검증, - 면 stdout 으로)": "join split chunks in order (verifies checksums when a manifest exists, - for stdout)",
+	"청크 %d (%s) 를 다시 받아야 해: %w":                          "chunk %d (%s) must be fetched again: %w",
+	"병합 완료: %s (%d개 청크, %d 바이트)":                         "joined: %s (%d chunks, %d bytes)",
+	"<파일|-> [출력|-]": "<file|-> [output|-]",
+	"gzip 압축 (-d 면 해제, - 는 stdin/stdout, -filter 는 압축 전/해제 후 내용에)": "gzip compression (-d to decompress, - for stdin/stdout, -filter applies before compressing/after decompressing)",
+	"압축 해제": "decompress",
+	"출력 파일, 두 번째 인자와 같아 (기본: <파일>.gz, 해제면 .gz 를 뗀 이름, 입력이 - 면 stdout)": "output file, same as the second argument (default: <file>.gz, or without .gz when decompressing; stdout when the input is -)",
+	"출력은 -o 나 두 번째 인자 중 하나로만 줘":                                        "give the output either with -o or as the second argument, not both",
+	"압축": "compress",
+	"%s 완료: %s → %s (%d → %d 바이트)": "%s done: %s → %s (%d → %d bytes)",
+	"gzip 헤더 읽기 실패: %w":            "failed to read gzip header: %w",
+	"<로그 파일|->":                    "<log file|->",
+	"로그 분석 (레벨별 개수, IP 통계, 에러 샘플, sftp:// 원격, - 면 stdin)": "analyze logs (counts per level, IP stats, error samples, sftp:// remotes, - for stdin)",
+	"분석한 로그를 이 전문 검색 색인에도 추가 (로컬 파일만)":                    "also add the analyzed log to this full-text search index (local files only)",
+	"파일열기 실패 : %w":        "failed to open file: %w",
+	"<원본 디렉토리> <대상 디렉토리>": "<source directory> <destination directory>",
+	"디렉토리 동기화 (바뀐 파일만 복사, -delete 로 미러링, sftp:// 원격)":            "sync directories (copy changed files only, -delete to mirror, sftp:// remotes)",
+	"원본에 없는 파일을 대상에서 삭제":                                         "delete files from the destination that are not in the source",
+	"-delete 때 영구 삭제 대신 옮길 휴지통 디렉토리":                             "trash directory to move files to instead of deleting them with -delete",
+	"실제로 바꾸지 않고 할 일만 출력":                                         "print what would change without changing anything",
+	"크기+수정시각 대신 내용 해시로 비교":                                       "compare by content hash instead of size+mtime",
+	"포함할 glob (쉼표 구분)":                                           "glob to include (comma separated)",
+	"제외할 glob (쉼표 구분)":                                           "glob to exclude (comma separated)",
+	"sparse 파일의 구멍을 대상에서도 유지":                                    "keep sparse file holes at the destination",
+	"하드링크를 대상에서도 하드링크로 유지":                                       "keep hard links as hard links at the destination",
+	"심볼릭 링크 처리 (skip|follow|copy)":                               "symbolic link handling (skip|follow|copy)",
+	"휴지통 열기 실패: %w":                                              "failed to open trash: %w",
+	"%-7s %s (%d 바이트)\n":                                         "%-7s %s (%d bytes)\n",
+	"-sparse, -hardlinks, -symlinks 는 로컬끼리 동기화할 때만 쓸 수 있어":       "-sparse, -hardlinks and -symlinks only work for local-to-local syncs",
+	"동기화 중단: %w":                                                 "sync aborted: %w",
+	"%d개 파일 실패":                                                  "%d files failed",
+	"<파일|디렉토리|->":                                                "<file|directory|->",
+	"SHA-256 계산 (디렉토리면 sha256sum -c 로 검증 가능한 매니페스트, - 면 stdin)":  "compute SHA-256 (for a directory, a manifest that sha256sum -c can verify; - for stdin)",
+	"디렉토리 매니페스트를 저장할 파일 (기본: stdout)":                            "file to save the directory manifest to (default: stdout)",
+	"디렉토리 병렬 해시 워커 수 (기본: CPU 수)":                                "parallel hash workers for directories (default: number of CPUs)",
+	"디렉토리 해시에 -json 을 쓰려면 -o 로 매니페스트 파일을 지정해줘":                   "to use -json with a directory hash, give a manifest file with -o",
+	"%d개 파일 해시 완료: %s":                                           "hashed %d files: %s",
+	"TCP/QUIC 파일 수신 서버 (끊긴 전송은 이어받기, 청크 CRC + 전체 다이제스트 검증)":      "TCP/QUIC file receive server (resumes broken transfers, per-chunk CRC + full digest verification)",
+	"TCP listen 주소 (빈 문자열이면 TCP 안 받음)":                           "TCP listen address (empty to disable TCP)",
+	"QUIC(UDP) listen 주소 (예: :9001)":                             "QUIC (UDP) listen address (e.g. :9001)",
+	"QUIC TLS 인증서 (비우면 자체 서명 인증서를 만들고 지문 출력)":                    "QUIC TLS certificate (empty to generate a self-signed one and print its fingerprint)",
+	"QUIC TLS 개인키":                                               "QUIC TLS private key",
+	"받은 파일을 저장할 디렉토리":                                            "directory to store received files in",
+	"인증서 읽기 실패: %w":                                              "failed to load certificate: %w",
+	"-addr 나 -quic 중 하나는 있어야 해":                                  "need at least one of -addr or -quic",
+	"<주소> <파일>":                                                  "<address> <file>",
+	"recv 서버로 파일 전송 (끊기면 받은 곳부터 재전송, -transport quic 이면 병렬 스트림)": "send a file to a recv server (resumes from where it broke off, parallel streams with -transport quic)",
+	"서버에 저장할 이름 (기본: 파일 이름)":                                     "name to store on the server (default: the file name)",
+	"연결이 끊기면 다시 접속하는 횟수":                                         "reconnect attempts when the connection drops",
+	"전송 방식 (tcp|quic)":                                           "transport (tcp|quic)",
+	"QUIC 병렬 스트림 수":                                              "number of parallel QUIC streams",
+	"QUIC 서버 인증서 SHA-256 지문 (recv 가 출력한 값)":                      "SHA-256 fingerprint of the QUIC server certificate (as printed by recv)",
+	"QUIC 서버 인증서를 검증하지 않음 (실습용)":                                 "do not verify the QUIC server certificate (for practice only)",
+	"QUIC 전송 중 이만큼 뒤에 새 UDP 소켓으로 경로 이동 (연결 이동 실습)":               "migrate to a new UDP socket this long into a QUIC transfer (connection migration practice)",
+	"QUIC 은 -fingerprint 나 -insecure 가 필요해":                      "QUIC needs -fingerprint or -insecure",
+	"stdin 을 보낼 때는 -name 으로 서버에 저장할 이름을 줘":                       "when sending stdin, give the name to store on the server with -name",
+	"전송 완료: %s → %s/%s (%d 바이트, 시도 %d번)":                         "sent: %s → %s/%s (%d bytes, %d attempts)",
+	", 전송 중 경로 이동":                                               ", migrated path during transfer",
+	", %d 바이트부터 이어서 보냄":                                          ", resumed from byte %d",
+	"델타 동기화 서버 (gRPC, 바뀐 블록만 주고받기)":                              "delta sync server (gRPC, exchanges changed blocks only)",
+	"gRPC listen 주소":                                             "gRPC listen address",
+	"파일을 두는 디렉토리 (같은 이름의 기존 파일이 옛 버전)":                           "directory holding the files (an existing file with the same name is the old version)",
+	"<주소> <로컬 파일>":                                               "<address> <local file>",
+	"delta-serve 와 파일 동기화 (옛 버전과 달라진 부분만 전송, -pull 이면 받기)":       "sync a file with delta-serve (sends only what differs from the old version, -pull to receive)",
+	"서버 쪽 파일 이름 (기본: 로컬 파일 이름)":                                  "file name on the server (default: the local file name)",
+	"서버의 파일로 로컬 파일을 갱신":                                          "update the local file from the server's file",
+	"보냄": "sent",
+	"받음": "received",
+	"동기화 완료: %s (%d 바이트, 블록 %d) - 데이터 %d 바이트 %s, %d 바이트는 옛 파일에서 재사용 (%.1f%% 절약)": "synced: %s (%d bytes, %d blocks) - %d bytes of data %s, %d bytes reused from the old file (%.1f%% saved)",
+	"S3 호환 엔드포인트 (예: http://localhost:9000, 비우면 AWS)":                            "S3-compatible endpoint (e.g. http://localhost:9000, empty for AWS)",
+	"리전 (비우면 AWS_REGION, 그것도 없으면 us-east-1)":                                     "region (empty for AWS_REGION, or us-east-1 if that is unset too)",
+	"버킷을 경로에 넣는 주소 방식 (MinIO 등)":                                                 "put the bucket in the path (MinIO etc.)",
+	"<파일|-> s3://버킷/키": "<file|-> s3://bucket/key",
+	"S3 로 업로드 (큰 파일은 파트를 나눠 동시에, 파트마다 재시도, 실패하면 업로드 정리, - 면 stdin)": "upload to S3 (large files in parallel parts, per-part retries, cleans up on failure, - for stdin)",
+	"파트 크기 (최소 5MB)":   "part size (at least 5MB)",
+	"동시에 올리는 파트 수":     "number of parts to upload at once",
+	"파트마다 재시도 횟수":      "retries per part",
+	"객체의 Content-Type": "Content-Type of the object",
+	"stdin 은 이름이 없어서 키를 끝까지 줘야 해 (s3://버킷/경로/이름)": "stdin has no name, so give the full key (s3://bucket/path/name)",
+	"잘못된 -part-size 값: %q (최소 5MB)":               "invalid -part-size value: %q (at least 5MB)",
+	"한 번에":   "single request",
+	"파트 %d개": "%d parts",
+	"업로드 완료: %s → %s (%d 바이트, %s, %.2f MB/s, ETag %s)": "uploaded: %s → %s (%d bytes, %s, %.2f MB/s, ETag %s)",
+	"s3://버킷[/접두사]": "s3://bucket[/prefix]",
+	"끝나지 않은 멀티파트 업로드를 찾아 정리 (남은 파트도 용량을 차지해)": "find and clean up unfinished multipart uploads (leftover parts take up space too)",
+	"이보다 오래된 업로드만 (0 이면 전부 - 진행 중인 업로드도 지워)":  "only uploads older than this (0 for all - including uploads in progress)",
+	"지우지 않고 목록만 출력":  "only list, do not delete",
+	"정리함":            "cleaned up",
+	"정리 대상":          "to clean up",
+	"미완료 업로드 %d개 %s": "%d unfinished uploads %s",
+	"init | create <디렉토리> | list | restore <스냅샷|latest> <대상> | verify | prune": "init | create <directory> | list | restore <snapshot|latest> <destination> | verify | prune",
+	"중복 제거 + 암호화 백업 (내용 기준 청크, 스냅샷 보존 정리)":                                     "deduplicating + encrypted backup (content-defined chunks, snapshot retention)",
+	"백업 저장소 디렉토리 (필수)":                                                         "backup repository directory (required)",
+	"비밀번호 파일 (없으면 BACKUP_PASSWORD 환경 변수)":                                      "password file (default: BACKUP_PASSWORD environment variable)",
+	"create: 제외할 glob (쉼표 구분)":                                                 "create: glob to exclude (comma separated)",
+	"create: 바뀌지 않은 것 같은 파일도 다시 읽기":                                            "create: re-read files that look unchanged",
+	"restore: 이 glob 에 맞는 경로만 (쉼표 구분)":                                         "restore: only paths matching this glob (comma separated)",
+	"verify: 청크를 전부 읽어서 복호화/무결성까지 확인":                                          "verify: read every chunk and check decryption/integrity too",
+	"prune: 원본마다 최근 N 개 보존":                                                    "prune: keep the last N snapshots per source",
+	"prune: 이 기간 안의 스냅샷 보존 (예: 720h)":                                          "prune: keep snapshots within this period (e.g. 720h)",
+	"prune: 지우지 않고 결과만":                                                        "prune: show the result without deleting",
+	"-repo 가 필요해":                                                              "-repo is required",
+	"저장소 생성: %s (비밀번호를 잃어버리면 복구할 수 없어요)":                                       "repository created: %s (a lost password cannot be recovered)",
+	"스냅샷 %s: 파일 %d개 (%d 바이트, %d개는 변경 없음), 새 청크 %d개 %d 바이트 → 저장 %d 바이트, 중복 %d 바이트": "snapshot %s: %d files (%d bytes, %d unchanged), %d new chunks %d bytes → %d bytes stored, %d bytes deduplicated",
+	"%d개 항목을 백업하지 못했어 (스냅샷에서 빠짐)":                                                 "%d items could not be backed up (left out of the snapshot)",
+	"%s  %s  %-10s 항목 %6d개 %14d 바이트  %s\n":                                        "%s  %s  %-10s %6d items %14d bytes  %s\n",
+	"스냅샷 %d개": "%d snapshots",
+	"복원 완료: 스냅샷 %s → %s (파일 %d개, 디렉토리 %d개, 링크 %d개, %d 바이트)": "restored: snapshot %s → %s (%d files, %d directories, %d links, %d bytes)",
+	"스냅샷 %d개, 청크 %d개 확인 - 없음 %d, 손상 %d, 안 쓰는 청크 %d":         "checked %d snapshots, %d chunks - missing %d, corrupt %d, unused chunks %d",
+	"온전히 복원할 수 없는 스냅샷: %s":                                  "snapshots that cannot be fully restored: %s",
+	"삭제":    "deleted",
+	"삭제 예정": "to delete",
+	"스냅샷 %d개 보존, %d개 %s, 청크 %d개 %s (%d 바이트)":                "kept %d snapshots, %d %s, %d chunks %s (%d bytes)",
+	"알 수 없는 작업: %s (init|create|list|restore|verify|prune)": "unknown action: %s (init|create|list|restore|verify|prune)",
+	"비밀번호가 필요해 (-password-file 또는 BACKUP_PASSWORD)":         "a password is required (-password-file or BACKUP_PASSWORD)",
+	"<파일|디렉토리>...": "<file|directory>...",
+	"텍스트/로그(gzip 포함) 전문 검색 색인 갱신 (바뀐 파일만 다시 읽기)":             "update the full-text search index for text/log files (gzip too; re-reads changed files only)",
+	"색인 갱신: 새로 %d개, 그대로 %d개, 텍스트 아님 %d개, 삭제 %d개 (전체 %d개 파일)": "index updated: %d new, %d unchanged, %d not text, %d removed (%d files total)",
+	"<검색어>...": "<term>...",
+	"색인된 파일 전문 검색 (모든 단어가 들어 있는 파일, 맞는 줄 표시)": "full-text search over indexed files (files containing every term, with matching lines)",
+	"최대 결과 수": "maximum number of results",
+	"파일마다 보여줄 줄 수 (-1 이면 파일 이름만)": "lines to show per file (-1 for file names only)",
+	"%s (점수 %.2f, %d 바이트)\n":      "%s (score %.2f, %d bytes)\n",
+	"%d개 파일":                      "%d files",
+	"적용될 설정을 YAML 로 출력 (-config, FS_* 환경 변수, 플래그 반영)": "print the effective config as YAML (-config, FS_* environment variables and flags applied)",
+	"<아카이브|-> [디렉토리]": "<archive|-> [directory]",
+	"zip/tar/tar.gz 풀기 (대상 밖을 가리키는 경로 거절, 엔트리 수/풀린 크기 한도, 권한 정리, - 는 stdin)": "extract zip/tar/tar.gz (rejects paths outside the target, entry count/size limits, sanitized permissions, - for stdin)",
+	"이미 있는 파일을 덮어써":        "overwrite existing files",
+	"건너뜀: %s (링크/장치 파일)\n": "skipped: %s (link/device file)\n",
+	"풀기 완료: %s → %s (%s)":  "extracted: %s → %s (%s)",
+	"데이터를 이 외부 명령(stdin → stdout)에 통과시켜 - plugins 이름이나 명령줄, 여러 번 주면 차례로": "pass the data through this external command (stdin → stdout) - a plugins name or a command line; repeat to chain",
+	"빈 필터": "empty filter",
+	"필터 명령 하나의 실행 시간 제한 (0 이면 plugins 설정값, 그것도 없으면 제한 없음)": "time limit for one filter command (0 for the plugins setting, unlimited if that is unset too)",
+	"따옴표나 \\ 가 닫히지 않았습니다":                                  "unterminated quote or \\",
+	"빈 명령": "empty command",
+	"결과를 사람이 읽는 글 대신 JSON 한 줄로 stdout 에 출력": "print the result as one JSON line on stdout instead of text",
+	"알 수 없는 명령: %s\n\n":                     "unknown command: %s\n\n",
+	"사용법: streamctl %s [옵션] %s\n%s\n\n":     "usage: streamctl %s [options] %s\n%s\n\n",
+	"streamctl %s: 설정 오류\n%v\n":             "streamctl %s: invalid config\n%v\n",
+	"사용법: streamctl <명령> [옵션] <인자...>":      "usage: streamctl <command> [options] <args...>",
+	"명령:": "commands:",
+	"공통 옵션: -buffer <크기> -rate <크기/초> -progress none|text|bar|json -json -log-level <레벨> -log-format text|json -trace <대상> -notify-webhook <URL> -notify-on all|failure -stats-dump <파일> -lang ko|en -config <YAML>": "common options: -buffer <size> -rate <size/s> -progress none|text|bar|json -json -log-level <level> -log-format text|json -trace <target> -notify-webhook <URL> -notify-on all|failure -stats-dump <file> -lang ko|en -config <YAML>",
+	"명령별 옵션: streamctl <명령> -h":                                                                     "per-command options: streamctl <command> -h",
+	"인자가 %d개 필요해 (받은 개수: %d)":                                                                       "needs %d arguments (got %d)",
+	"add <compress|sync|backup> <원본> [대상] | run | status [ID] | cancel <ID> | retry <ID> | rm <ID>": "add <compress|sync|backup> <source> [destination] | run | status [ID] | cancel <ID> | retry <ID> | rm <ID>",
+	"작업 큐 (압축 묶음/동기화/백업을 디스크에 쌓아 두고 차례로, 재시작하면 이어서)":                                                "job queue (queues compress batches/syncs/backups on disk and runs them in order, resumes after restart)",
+	"run: 기다리는 작업을 다 돌리면 끝 (기본은 새 작업을 계속 기다려)":                                                      "run: exit once the waiting jobs are done (default: keep waiting for new jobs)",
+	"add compress: 압축할 파일 glob":                                                                     "add compress: glob of files to compress",
+	"add compress: 이만큼 안 바뀐 파일만":                                                                    "add compress: only files unchanged for this long",
+	"add compress: 원본을 지우지 않고 남김":                                                                   "add compress: keep the originals",
+	"add sync: 원본에 없는 파일을 대상에서 삭제":                                                                  "add sync: delete files from the destination that are not in the source",
+	"add backup: 백업 저장소 디렉토리":                                                                       "add backup: backup repository directory",
+	"add backup: 비밀번호 파일 (없으면 run 할 때의 BACKUP_PASSWORD)":                                            "add backup: password file (default: BACKUP_PASSWORD at run time)",
+	"add backup: 제외할 glob (쉼표 구분)":                                                                  "add backup: glob to exclude (comma separated)",
+	"작업 %d/%d 돌아가는 중":                                                                               "%d/%d jobs running",
+	"sync 에는 대상이 필요해: queue add sync <원본> <대상>":                                                     "sync needs a destination: queue add sync <source> <destination>",
+	"backup 에는 -repo 가 필요해":                                                                         "backup needs -repo",
+	"알 수 없는 작업 종류: %q (%s)":                                                                         "unknown job kind: %q (%s)",
+	"작업 추가: %s (%s %s)":                                                                             "job added: %s (%s %s)",
+	"취소 요청":                                                                                         "cancel requested",
+	"다시 넣음":                                                                                         "requeued",
+	"지움":                                                                                            "removed",
+	"알 수 없는 queue 명령: %q":                                                                           "unknown queue command: %q",
+	"스냅샷 %s: 파일 %d개 (%d 바이트), 새 청크 %d개 → 저장 %d 바이트":                                                 "snapshot %s: %d files (%d bytes), %d new chunks → %d bytes stored",
+	"%d개 항목을 백업하지 못했어 (스냅샷에서 빠짐): %w":                                                               "%d items could not be backed up (left out of the snapshot): %w",
+	"러너: 없음 (queue run 으로 돌려)\n":                                                                    "runner: none (start one with queue run)\n",
+	"러너: %s\n": "runner: %s\n",
+	"러너: %s (응답 없음 - 죽은 것 같아, 다음 queue run 이 넘겨받아)\n": "runner: %s (not responding - probably dead, the next queue run takes over)\n",
+	"작업 없음":                         "no jobs",
+	"%s  %-8s  %-8s  시도 %d  %s  %s": "%s  %-8s  %-8s  attempt %d  %s  %s",
+	"기다림 %d, 실행 중 %d, 완료 %d, 실패 %d, 취소 %d":                          "waiting %d, running %d, done %d, failed %d, canceled %d",
+	"ID:       %s\n종류:     %s\n상태:     %s\n인자:     %s\n만든 시각: %s\n": "ID:         %s\nkind:       %s\nstate:      %s\nparams:     %s\ncreated:    %s\n",
+	"시작:     %s\n":           "started:    %s\n",
+	"끝:       %s (%s)\n":     "finished:   %s (%s)\n",
+	"시도:     %d (재시작 복구 %d)": "attempts:   %d (recovered after restart %d)",
+	"\n실행:     %s":           "\nowner:      %s",
+	"\n체크포인트: %s":            "\ncheckpoint: %s",
+	"\n요약:     %s":           "\nsummary:    %s",
+	"\n에러:     %s":           "\nerror:      %s",
+	"설정 파일의 예약 작업(schedule.jobs)을 주기대로 실행 (로그 압축, 동기화, 체크섬 검증)": "run the scheduled jobs in the config file (schedule.jobs) on their schedule (log compression, sync, checksum verification)",
+	"작업 상태/이력 파일": "job state/history file",
+	"작업 상태와 최근 실행 기록을 보여주고 끝 (스케줄러가 다른 곳에서 돌고 있어도 돼)":           "show job state and recent runs, then exit (fine while the scheduler runs elsewhere)",
+	"이 작업만 지금 한 번 실행하고 끝":                                       "run only this job once now, then exit",
+	"설정 파일에 schedule.jobs 가 없어 - config/example.yaml 의 예시를 참고해": "no schedule.jobs in the config file - see the example in config/example.yaml",
+	"%s 완료 (%s): %s":                       "%s done (%s): %s",
+	"%-20s %-8s %-16s 다음 %s":               "%-20s %-8s %-16s next %s",
+	"압축 %d개 (%d → %d 바이트), 건너뜀 %d":         "compressed %d (%d → %d bytes), skipped %d",
+	"매니페스트 열기 실패: %w":                      "failed to open manifest: %w",
+	"검증 실패: %s":                            "verification failed: %s",
+	"설정된 작업이 없어":                           "no jobs configured",
+	" [실행 중]":                              " [running]",
+	"%s (%s, %s)%s - 실행 %d, 실패 %d, 건너뜀 %d": "%s (%s, %s)%s - runs %d, failures %d, skipped %d",
+	", 다음 %s":                              ", next %s",
+	"성공":                                   "ok",
+	"건너뜀 (이전 실행 중)":                        "skipped (previous run still going)",
+	"실패: ":                                 "failed: ",
+	"<디렉토리>":                               "<directory>",
+	"체크섬 기록(xattr 또는 사이드카 DB)과 대조해서 비트 부패 찾기 (-days 동안 확인 안 한 파일만 다시 읽어)": "find bit rot by comparing with checksum records (xattr or sidecar DB; re-reads files not checked for -days)",
+	"마지막 확인이 이 일수보다 오래된 파일만 다시 읽어 (0 이면 전부)":                              "re-read only files last checked more than this many days ago (0 for all)",
+	"기록이 없는 파일은 이 SHA256SUMS 매니페스트와 대조 (hash -o, manifest create 로 만든 것)": "check files without a record against this SHA256SUMS manifest (from hash -o or manifest create)",
+	"병렬 해시 워커 수 (기본: CPU 수)": "parallel hash workers (default: number of CPUs)",
+	"기록을 바꾸지 않고 확인만":         "only check, do not update the records",
+	"-days 는 0 이상이어야 해":      "-days must be 0 or more",
+	"체크섬 저장소가 꺼져 있어 - -checksum-store xattr|db 나 설정의 checksum.store 로 정해줘": "the checksum store is off - choose one with -checksum-store xattr|db or checksum.store in the config",
+	"손상:   %s\n":        "corrupt: %s\n",
+	"에러:   %s\n":        "error:   %s\n",
+	"검증 중단: %w":         "verification aborted: %w",
+	"손상 %d개, 에러 %d개":    "%d corrupt, %d errors",
+	"체크섬 저장소 열기 실패: %w": "failed to open checksum store: %w",
+	"바이너리 데이터를 터미널에 출력하지 않아 - 파이프나 > 로 받거나 출력 파일을 지정해줘": "not writing binary data to a terminal - pipe it, redirect it with > or give an output file",
+	"디렉토리는 복사할 수 없습니다: %s":                             "cannot copy a directory: %s",
+	"소스 파일 열기 실패: %w":                                  "failed to open source file: %w",
+	"stdin 은 이름이 없어서 디렉토리에 쓸 수 없어 - 파일 경로를 줘: %s":      "stdin has no name, so it cannot be written into a directory - give a file path: %s",
+	"대상 파일 생성 실패: %w":                                  "failed to create destination file: %w",
+	"복사 실패: %w":                                        "copy failed: %w",
+	"대상 파일 닫기 실패: %w":                                  "failed to close destination file: %w",
+	"임시 파일 생성 실패: %w":                                  "failed to create temporary file: %w",
+	"stdin 받아 두기 실패: %w":                               "failed to spool stdin: %w",
+	"알 수 없는 진행률 모드: %q (none|text|bar|json)":           "unknown progress mode: %q (none|text|bar|json)",
+	"\r진행률: %.2f%% (%d/%d 바이트, %.1f KB/s)":             "\rprogress: %.2f%% (%d/%d bytes, %.1f KB/s)",
+	"\r처리: %d 바이트 (%.1f KB/s)":                         "\rprocessed: %d bytes (%.1f KB/s)",
+	"[%s] %5.1f%%  %9s/%-9s %9s/s  남은 시간 %-8s":         "[%s] %5.1f%%  %9s/%-9s %9s/s  ETA %-8s",
+	"프레임이 너무 큼":                                        "frame too large",
+	"청크 크기는 0 < Min < Avg < Max, Avg 는 2의 거듭제곱이어야 합니다": "chunk sizes must satisfy 0 < Min < Avg < Max with Avg a power of two",
+	"마스터 키 길이가 이상합니다":                                  "unexpected master key length",
+	"암호문이 너무 짧습니다":                                     "ciphertext too short",
+	"지원하지 않는 키 유도 방식: %q":                              "unsupported key derivation: %q",
+	"보존 규칙(KeepLast 또는 KeepWithin)이 없으면 스냅샷이 전부 지워집니다": "without a retention rule (KeepLast or KeepWithin) every snapshot would be deleted",
+	"비밀번호가 비어 있습니다":                                    "password is empty",
+	"이미 저장소가 있습니다: %s":                                 "a repository already exists: %s",
+	"저장소가 아닙니다 (먼저 init 하세요): %s":                      "not a repository (run init first): %s",
+	"지원하지 않는 저장소 버전: %d":                               "unsupported repository version: %d",
+	"저장소가 잠겨 있습니다 (%s) - 실행 중인 작업이 없으면 %s 를 지우세요":      "repository is locked (%s) - if nothing is running, delete %s",
+	"내용의 ID 가 다름 (%s)":                                 "content ID mismatch (%s)",
+	"손상된 청크 %s: %v":                                    "corrupt chunk %s: %v",
+	"스냅샷의 경로가 이상합니다: %q":                               "invalid path in snapshot: %q",
+	"스냅샷 %s 를 복호화할 수 없습니다: %w":                         "cannot decrypt snapshot %s: %w",
+	"스냅샷 %s: %w":                                       "snapshot %s: %w",
+	"스냅샷이 없습니다":                                        "no snapshots",
+	"스냅샷 ID %q 에 해당하는 게 여러 개입니다":                       "snapshot ID %q matches more than one snapshot",
+	"스냅샷 없음: %s":                                       "snapshot not found: %s",
+	"디렉토리가 아닙니다: %s":                                   "not a directory: %s",
+	"서버 오류 (%s): %s":                                   "server error (%s): %s",
+	"서버가 보낸 블록 크기가 이상해: %d":                            "server sent an invalid block size: %d",
+	"서버가 결과 대신 다른 메시지를 보냈어":                            "server sent another message instead of the result",
+	"서버가 파일 정보 대신 다른 메시지를 보냈어":                         "server sent another message instead of the file info",
+	"잘못된 블록 크기: %d":                                    "invalid block size: %d",
+	"잘못된 복사 지시: 블록 %d부터 %d개 (옛 파일 %d 바이트)":             "invalid copy op: from block %d, %d blocks (old file %d bytes)",
+	"알 수 없는 지시: %d":                                    "unknown op: %d",
+	"잘못된 파일 이름: %q":                                    "invalid file name: %q",
+	"예상하지 못한 메시지 (기다린 것: %s)":                          "unexpected message (waiting for: %s)",
+	"파일 없음: %s":                                        "file not found: %s",
+	"Ops 또는 Done":                                      "Ops or Done",
+	"일반 파일이 아닙니다: %s":                                  "not a regular file: %s",
+	"서명":                                               "signature",
+	"%s: 파일 %d, 디렉토리 %d, %d 바이트, 건너뜀 %d":               "%s: %d files, %d directories, %d bytes, %d skipped",
+	"%w: 엔트리 %d개 (한도 %d)":                              "%w: %d entries (limit %d)",
+	"%w: zip 이 %d 바이트를 넘음":                             "%w: zip exceeds %d bytes",
+	"%w: 엔트리가 %d개를 넘음":                                 "%w: more than %d entries",
+	"%s 이 대상에 이미 있습니다: %w":                             "%s already exists at the destination: %w",
+	"파일 하나": "per-file",
+	"전체":    "total",
+	"%w: %s 를 풀다가 %s 크기 한도를 넘음":                                  "%w: extracting %s exceeded the %s size limit",
+	"%s: sparse 파일 크기 맞추기 실패: %w":                                "%s: failed to set sparse file size: %w",
+	"%s: 체크섬 속성 형식 오류: %w":                                       "%s: malformed checksum attribute: %w",
+	"체크섬 DB %s: %w":                                              "checksum DB %s: %w",
+	"체크섬 DB 저장 실패: %w":                                           "failed to save checksum DB: %w",
+	"매니페스트 %d번째 줄 형식 오류: %q":                                     "manifest line %d is malformed: %q",
+	"정상 %d, 누락 %d, 추가됨 %d, 손상 %d, 에러 %d":                         "ok %d, missing %d, added %d, corrupt %d, errors %d",
+	"휴지통은 로컬 대상에서만 쓸 수 있습니다":                                     "the trash only works for local destinations",
+	"대상이 디렉토리입니다: %s":                                            "destination is a directory: %s",
+	"확인 %d, 건너뜀 %d, 새로 기록 %d, 갱신 %d, 손상 %d, 에러 %d":               "checked %d, skipped %d, newly recorded %d, updated %d, corrupt %d, errors %d",
+	"%s: 기록 실패: %v":                                              "%s: failed to record: %v",
+	"%s복사 %d, 갱신 %d, 삭제 %d, 하드링크 %d, 변경 없음 %d, 실패 %d, 전송 %d 바이트": "%scopied %d, updated %d, deleted %d, hard links %d, unchanged %d, failed %d, transferred %d bytes",
+	"휴지통 생성 실패: %w":                                              "failed to create trash: %w",
+	"휴지통으로 옮기기 실패: %w":                                           "failed to move to trash: %w",
+	"복원 실패: %w":                                                  "restore failed: %w",
+	"잘못된 휴지통 ID: %q":                                             "invalid trash ID: %q",
+	"휴지통 항목 읽기 실패: %w":                                           "failed to read trash entry: %w",
+	"휴지통 항목 파싱 실패: %w":                                           "failed to parse trash entry: %w",
+	"알 수 없는 심볼릭 링크 정책: %q (skip|follow|copy)":                    "unknown symlink policy: %q (skip|follow|copy)",
+	"알 수 없는 데이터 종류: %q (log|random|repeat)":                      "unknown data kind: %q (log|random|repeat)",
+	"크기는 0 이상이어야 합니다":                                            "size must be 0 or more",
+	"알 수 없는 데이터 종류: %q":                                          "unknown data kind: %q",
+	"크기 형식 오류: %q (예: 512, 64KB, 10MB, 2G)":                      "invalid size: %q (e.g. 512, 64KB, 10MB, 2G)",
+	"알 수 없는 로그 형식: %q (text, json)":                              "unknown log format: %q (text, json)",
+	"알 수 없는 로그 레벨: %q (debug, info, warn, error)":                "unknown log level: %q (debug, info, warn, error)",
+	"SMTP 인증 실패: %w":                                             "SMTP authentication failed: %w",
+	"완료":                                                         "done",
+	"실패":                                                         "failed",
+	"%s 템플릿 오류: %w":                                              "%s template error: %w",
+	"%s 템플릿 실행 실패: %w":                                           "%s template execution failed: %w",
+	"메일 알림에는 보내는 사람(from)과 받는 사람(to)이 필요합니다":                     "email notifications need a sender (from) and recipients (to)",
+	"웹훅 응답 %s":                                                   "webhook response %s",
+	"큐 디렉토리가 비어 있습니다":                                            "queue directory is empty",
+	"큐 디렉토리 만들기 실패: %w":                                          "failed to create queue directory: %w",
+	"작업 종류가 비어 있습니다":                                             "job kind is empty",
+	"작업 인자: %w":                                                  "job params: %w",
+	"없는 작업: %s":                                                  "no such job: %s",
+	"%s 로 시작하는 작업이 %d개입니다 - 더 길게 주세요":                            "more than one job starts with %s (%d) - give a longer ID",
+	"%s 은 이미 끝난 작업입니다 (%s)":                                      "%s has already finished (%s)",
+	"%s 은 실패하거나 취소된 작업이 아닙니다 (%s)":                               "%s has not failed or been canceled (%s)",
+	"%s 은 아직 끝나지 않은 작업입니다 (%s) - 먼저 취소하세요":                       "%s has not finished yet (%s) - cancel it first",
+	"작업 파일 %s: %w":                                               "job file %s: %w",
+	"취소됨":                                                        "canceled",
+	"패닉: %v":                                                     "panic: %v",
+	"다른 프로세스가 이 큐를 돌리고 있습니다 (%s)":                                "another process is running this queue (%s)",
+	"AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY 환경 변수가 필요합니다":     "AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY environment variables are required",
+	"잘못된 엔드포인트: %w":                                              "invalid endpoint: %w",
+	"S3 요청 실패: HTTP %d":                                          "S3 request failed: HTTP %d",
+	"S3 요청 실패: HTTP %d %s: %s":                                   "S3 request failed: HTTP %d %s: %s",
+	"s3:// 주소가 아닙니다: %q":                                         "not an s3:// address: %q",
+	"버킷 이름이 없습니다: %q":                                            "missing bucket name: %q",
+	"%w (업로드 %s 정리도 실패: %v)":                                     "%w (cleaning up upload %s also failed: %v)",
+	"파트 %d/%d: %w":                                               "part %d/%d: %w",
+	"파트 %d: %w":                                                  "part %d: %w",
+	"응답에 ETag 가 없습니다":                                            "response has no ETag",
+	"응답에 UploadId 가 없습니다":                                        "response has no UploadId",
+	"cron %q: 간격은 0 보다 커야 합니다":                                   "cron %q: interval must be greater than 0",
+	"알 수 없는 cron 줄임말: %q":                                        "unknown cron shorthand: %q",
+	"cron %q: 분 시 일 월 요일 다섯 칸이어야 합니다":                            "cron %q: needs five fields (minute hour day month weekday)",
+	"잘못된 간격: %q":                                                 "invalid interval: %q",
+	"범위를 벗어남: %q (%d~%d)":                                        "out of range: %q (%d~%d)",
+	"숫자가 아닙니다: %q":                                               "not a number: %q",
+	"작업에는 이름, 실행 주기, 실행 함수가 필요합니다":                               "a job needs a name, a schedule and a function",
+	"이미 있는 작업 이름: %s":                                            "job name already exists: %s",
+	"등록된 작업이 없습니다":                                               "no jobs registered",
+	"%s: 이전 실행이 아직 안 끝났습니다":                                      "%s: the previous run has not finished yet",
+	"상태 파일 %s: %w":                                               "state file %s: %w",
+	"색인 파일을 읽을 수 없습니다 (%s): %w":                                  "cannot read index file (%s): %w",
+	"지원하지 않는 색인 버전: %d (지우고 다시 만드세요)":                            "unsupported index version: %d (delete it and rebuild)",
+	"검색어가 비어 있습니다 (영문/숫자 한 글자는 검색하지 않아요)":                        "empty query (single English letters/digits are not searched)",
+	"=== %s 상태 %s (pid %d, 실행 %s) ===\n":                         "=== %s status %s (pid %d, up %s) ===\n",
+	"고루틴 %d, 힙 %.1fMB (확보 %.1fMB), GC %d회\n":                     "goroutines %d, heap %.1fMB (reserved %.1fMB), GC %d times\n",
+	"전송: 진행 중 %d, 시작 %d, 완료 %d, 실패 %d, 재시도 %d, 옮긴 바이트 %d\n": "transfers: active %d, started %d, done %d, failed %d, retries %d, bytes moved %d\n",
+	"없음": "none",
+	"속도 제한: %s, 대기 %d회 (총 %s)\n": "rate limit: %s, waited %d times (total %s)\n",
+	"  재시도 %d":                                       "  retries %d",
+	"상태 파일 열기 실패: %w":                                "failed to open state file: %w",
+	"읽기 에러: %w":                                      "read error: %w",
+	"레코드 쓰기 실패: %w":                                  "failed to write records: %w",
+	"\r진행률: %.2f%% (%d 줄 처리)":                        "\rprogress: %.2f%% (%d lines processed)",
+	"\r%d 줄, %d 바이트 처리":                              "\r%d lines, %d bytes processed",
+	"📊 로그 분석 보고서":                                    "📊 Log analysis report",
+	"\n총 라인 수: %d\n":                                 "\ntotal lines: %d\n",
+	"에러 수: %d (%.2f%%)\n":                            "errors: %d (%.2f%%)\n",
+	"경고 수: %d (%.2f%%)\n":                            "warnings: %d (%.2f%%)\n",
+	"정보 수: %d (%.2f%%)\n":                            "info: %d (%.2f%%)\n",
+	"\n고유 IP 주소 수: %d\n":                             "\nunique IP addresses: %d\n",
+	"가장 빈번한 IP: %s (%d회)\n":                          "most frequent IP: %s (%d times)\n",
+	"\n최근 에러 메시지 샘플:":                                "\nrecent error message samples:",
+	"로그 분석 보고서\n":                                    "Log analysis report\n",
+	"생성 시간: %s\n\n":                                  "generated: %s\n\n",
+	"총 라인 수: %d\n":                                   "total lines: %d\n",
+	"에러 수: %d\n":                                     "errors: %d\n",
+	"경고 수: %d\n":                                     "warnings: %d\n",
+	"정보 수: %d\n":                                     "info: %d\n",
+	"\n고유 IP 주소 목록:\n":                               "\nunique IP addresses:\n",
+	"%s: %d회\n":                                      "%s: %d times\n",
+	"parquet 쓰기 실패: %w":                              "failed to write parquet: %w",
+	"캐시 디렉토리가 비어 있습니다":                               "cache directory is empty",
+	"캐시 디렉토리 만들기 실패: %w":                             "failed to create cache directory: %w",
+	"잘못된 SFTP 주소: %q (sftp://user@host[:port]/path)": "invalid SFTP address: %q (sftp://user@host[:port]/path)",
+	"ssh 접속 실패 (%s): %w":                             "ssh connection failed (%s): %w",
+	"sftp 세션 시작 실패: %w":                              "failed to start sftp session: %w",
+	"개인키 읽기 실패: %w":                                  "failed to read private key: %w",
+	"개인키 해석 실패 (암호가 걸린 키는 ssh-agent 로 써): %w":                "failed to parse private key (use ssh-agent for passphrase-protected keys): %w",
+	"ssh 인증 수단이 없습니다 (ssh-agent, 개인키, SSH_PASSWORD 중 하나 필요)": "no ssh authentication method (needs ssh-agent, a private key or SSH_PASSWORD)",
+	"known_hosts 읽기 실패 (처음 접속하는 서버면 ssh 로 한 번 접속해서 등록해): %w": "failed to read known_hosts (for a new server, connect once with ssh to add it): %w",
+	"append 파일 열기 실패: %w":              "failed to open append file: %w",
+	"파일 잠금 실패: %w":                     "failed to lock file: %w",
+	"%s: 종료 코드 %d":                     "%s: exit code %d",
+	": 시그널로 종료됨":                       ": killed by signal",
+	"실행할 명령이 비어 있습니다":                  "command to run is empty",
+	"%s 실행 실패: %w":                     "failed to run %s: %w",
+	"%s: %s 안에 끝나지 않아서 중단했습니다: %w":     "%s: stopped because it did not finish within %s: %w",
+	"동일 (%d 바이트)":                      "identical (%d bytes)",
+	"다름 (첫 차이: %d 바이트 위치)":             "different (first difference at byte %d)",
+	"다름: %d 구간, %d 바이트 차이, 유사도 %.2f%%": "different: %d ranges, %d bytes differ, similarity %.2f%%",
+	"%s 읽기 실패: %w":                     "failed to read %s: %w",
+	"sparse 파일 크기 맞추기 실패: %w":          "failed to set sparse file size: %w",
+	"동기화 실패: %w":                       "sync failed: %w",
+	"임시 파일 닫기 실패: %w":                  "failed to close temporary file: %w",
+	"이름 변경 실패: %w":                     "rename failed: %w",
+	"대상 열기 실패: %w":                     "failed to open destination: %w",
+	"전체 체크섬 불일치: 예상 %s, 실제 %s":         "overall checksum mismatch: expected %s, got %s",
+	"청크 %d 체크섬 불일치 [%s]: 예상 %s, 실제 %s": "chunk %d checksum mismatch [%s]: expected %s, got %s",
+	"매니페스트 읽기 실패: %w":                  "failed to read manifest: %w",
+	"매니페스트 파싱 실패: %w":                  "failed to parse manifest: %w",
+	"%s 에 청크 파일이 없습니다":                 "no chunk files in %s",
+	"청크 %d 가 없습니다":                     "chunk %d is missing",
+	"병합할 청크가 없습니다":                     "no chunks to merge",
+	"크기 불일치: 예상 %d, 실제 %d":             "size mismatch: expected %d, got %d",
+	"청크 %d 열기 실패: %w":                  "failed to open chunk %d: %w",
+	"청크 %d 복사 실패: %w":                  "failed to copy chunk %d: %w",
+	"청크 %d 크기 불일치: 예상 %d, 실제 %d":       "chunk %d size mismatch: expected %d, got %d",
+	"원본 정보 읽기 실패: %w":                  "failed to read source info: %w",
+	"권한 복사 실패: %w":                     "failed to copy permissions: %w",
+	"소유자 복사 실패: %w":                    "failed to copy owner: %w",
+	"확장 속성 복사 실패: %w":                  "failed to copy extended attributes: %w",
+	"시각 복사 실패: %w":                     "failed to copy times: %w",
+	"mmap: 이미 닫힘":                      "mmap: already closed",
+	"mmap: 음수 오프셋":                     "mmap: negative offset",
+	"mmap 파일 열기 실패: %w":                "failed to open mmap file: %w",
+	"mmap: 일반 파일이 아닙니다: %s":            "mmap: not a regular file: %s",
+	"mmap: 파일이 너무 큽니다: %d 바이트":         "mmap: file too large: %d bytes",
+	"mmap 실패: %w":                      "mmap failed: %w",
+	"다른 파일시스템으로는 일반 파일만 이동할 수 있습니다: %s": "only regular files can be moved across filesystems: %s",
+	"이동(복사) 실패: %w":                 "move (copy) failed: %w",
+	"복사는 끝났지만 원본 삭제 실패: %w":         "copied, but failed to delete the source: %w",
+	"원본 해시 계산 실패: %w":               "failed to hash source: %w",
+	"대상 해시 계산 실패: %w":               "failed to hash destination: %w",
+	"offset 과 length 는 0 이상이어야 합니다": "offset and length must be 0 or more",
+	"mmap: 일반 파일이 아닙니다 (%s): %s":    "mmap: not a regular file (%s): %s",
+	"청크 크기는 0보다 커야 합니다":             "chunk size must be greater than 0",
+	"청크 %d 읽기 실패: %w":               "failed to read chunk %d: %w",
+	"청크 %d 쓰기 실패: %w":               "failed to write chunk %d: %w",
+	"매니페스트 저장 실패: %w":               "failed to save manifest: %w",
+	"청크 %d 생성 실패: %w":               "failed to create chunk %d: %w",
+	"잘못된 트레이스 대상: %q (stderr, grpc://호스트:4317, http://호스트:4318)": "invalid trace target: %q (stderr, grpc://host:4317, http://host:4318)",
+	"지원하지 않는 트레이스 프로토콜: %q (grpc, http, https)":                  "unsupported trace protocol: %q (grpc, http, https)",
+	"알 수 없는 전송 방식: %q (tcp|quic)":                                "unknown transport: %q (tcp|quic)",
+	"일반 파일만 보낼 수 있어: %s":                                         "only regular files can be sent: %s",
+	"해시 계산 실패: %w":                                               "failed to compute hash: %w",
+	"accept 읽기 실패: %w":                                           "failed to read accept: %w",
+	"서버가 잘못된 오프셋을 보냄: %d":                                        "server sent an invalid offset: %d",
+	"상대방 에러: ":                                                   "peer error: ",
+	"%w: %d 바이트":                                                 "%w: %d bytes",
+	"프레임 본문 읽기 실패: %w":                                           "failed to read frame body: %w",
+	"예상하지 못한 프레임: %d (기대: %d)":                                   "unexpected frame: %d (expected: %d)",
+	"data 프레임이 너무 짧음":                                            "data frame too short",
+	"청크 CRC 불일치 (오프셋 %d)":                                        "chunk CRC mismatch (offset %d)",
+	"offer 읽기 실패: %w":                                            "failed to read offer: %w",
+	"빠진 구간이 있음: %d/%d 바이트":                                       "missing ranges: %d/%d bytes",
+	"done 읽기 실패: %w":                                             "failed to read done: %w",
+	"데이터 스트림 읽기 실패: %w":                                          "failed to read data stream: %w",
+	"데이터 스트림에 예상하지 못한 프레임: %d":                                   "unexpected frame on data stream: %d",
+	"범위를 벗어난 청크: %d+%d (허용: %d~%d)":                              "chunk out of range: %d+%d (allowed: %d~%d)",
+	"청크 오프셋 불일치: %d (기대: %d)":                                    "chunk offset mismatch: %d (expected: %d)",
+	"수신 중단됨":                                                     "receive aborted",
+	"쓰기 실패: %w":                                                  "write failed: %w",
+	"QUIC 모드는 TLS 설정이 필요해 (SendOptions.TLS, 자체 서명 서버면 PinnedTLS)": "QUIC mode needs TLS config (SendOptions.TLS, PinnedTLS for self-signed servers)",
+	"프레임 읽기 실패 (%d/%d 바이트 수신): %w":                                "failed to read frame (%d/%d bytes received): %w",
+	"offer 크기(%d)보다 많이 보냄":                                        "sent more than the offer size (%d)",
+	"크기 불일치: %d 바이트 수신 (offer: %d)":                               "size mismatch: received %d bytes (offer: %d)",
+	"예상하지 못한 프레임: %d":                                             "unexpected frame: %d",
+	"예약된 파일 이름: %q":                                               "reserved file name: %q",
+	"잘못된 크기: %d":                                                  "invalid size: %d",
+	"잘못된 sha256: %q":                                              "invalid sha256: %q",
+	"메타 파일 저장 실패: %w":                                             "failed to save meta file: %w",
+	".part 파일 생성 실패: %w":                                          "failed to create .part file: %w",
+	"다이제스트 계산 실패: %w":                                             "failed to compute digest: %w",
+	"서버 인증서 없음":                                                   "no server certificate",
+	"서버 인증서 지문 불일치: %s":                                           "server certificate fingerprint mismatch: %s",
+	"시작하면 PID 를 적고 끝나면 지울 파일":                                     "file to write the PID to on start and remove on exit",
+	"백그라운드로 떼어내서 실행 (준비되면 돌아와, systemd 에서는 쓰지 마)": "detach and run in the background (returns once ready; do not use under systemd)",
+	"-daemon 일 때 stdout/stderr 를 덧붙일 파일 (비우면 버려)": "file to append stdout/stderr to with -daemon (empty to discard)",
+	"LISTEN_FDS 형식 오류: %q": "malformed LISTEN_FDS: %q",
+	"물려받은 소켓 %s: %w":       "inherited socket %s: %w",
+	"이미 실행 중입니다":           "already running",
+	"PID 파일 쓰기 실패: %w":     "failed to write PID file: %w",
+	"이 OS 에서는 백그라운드 실행(-daemon)을 지원하지 않습니다 - 서비스 관리자로 띄우세요": "background mode (-daemon) is not supported on this OS - use a service manager",
+	"백그라운드 프로세스가 준비되기 전에 끝났습니다: %v":                         "background process exited before it was ready: %v",
+	"백그라운드 프로세스(PID %d)의 준비 신호를 %s 안에 못 받았습니다":              "no readiness signal from the background process (PID %d) within %s",
+	"usage.flush 는 0 보다 커야 합니다: %s":                         "usage.flush must be greater than 0: %s",
+	"usage.monthly 는 0 이상이어야 합니다: %s":                       "usage.monthly must be 0 or more: %s",
+	"usage.keys.%s: key 가 비어 있습니다":                          "usage.keys.%s: key is empty",
+	"usage.keys.%s: 다른 계정과 같은 key 입니다":                      "usage.keys.%s: key is the same as another account's",
+	"usage.keys.%s: monthly 는 0 이상이어야 합니다":                  "usage.keys.%s: monthly must be 0 or more",
+	"키별 전송량을 세서 저장할 파일 (비우면 안 세)":                           "file to record per-key transfer usage in (empty disables it)",
+	"키마다 한 달 전송 한도 (업로드+다운로드, 예: 100GB, 0 이면 제한 없음)":        "monthly transfer quota per key (upload+download, e.g. 100GB, 0 means unlimited)",
+	"HTTP 파일 서버 (업로드/다운로드/Range/삭제/검색, API 키별 전송량/월 한도, systemd 소켓 활성화/sd_notify, SIGHUP 으로 설정 다시 읽기)": "HTTP file server (upload/download/Range/delete/search, per-API-key usage and monthly quotas, systemd socket activation/sd_notify, reloads config on SIGHUP)",
+	"이번 달 전송 한도를 넘었습니다":                         "monthly transfer quota exceeded",
+	"전송량 기록 %s: %w":                             "usage records %s: %w",
+	"전송량 기록 저장 실패: %w":                          "failed to save usage records: %w",
+	"server.session_dir 가 비어 있습니다":              "server.session_dir is empty",
+	"이어 올리기(/api/uploads) 중인 파일과 세션 저널을 둘 디렉토리": "directory for in-progress resumable uploads (/api/uploads) and their session journal",
+	"/download 응답을 gzip 으로 압축 (클라이언트가 받을 수 있고 텍스트 같은 형식일 때만)":                                "gzip-compress /download responses (only when the client accepts it and the content is text-like)",
+	"압축하지 않을 확장자 (쉼표 구분, 예: .bin,.dat - zip/gz/이미지/동영상은 알아서 건너뛰어)":                           "extensions never to compress (comma-separated, e.g. .bin,.dat - zip/gz/images/video are skipped automatically)",
+	"usage.monthly 를 쓰려면 usage.file 이 필요합니다":                                                 "usage.monthly requires usage.file",
+	"usage.storage 는 0 이상이어야 합니다: %s":                                                        "usage.storage must be 0 or greater: %s",
+	"usage.storage 를 쓰려면 usage.storage_file 이 필요합니다":                                         "usage.storage requires usage.storage_file",
+	"usage.keys.%s: storage 는 0 이상이어야 합니다":                                                   "usage.keys.%s: storage must be 0 or greater",
+	"usage.keys.%s: storage 를 쓰려면 usage.storage_file 이 필요합니다":                                "usage.keys.%s: storage requires usage.storage_file",
+	"파일마다 올린 키를 적어 두고 키별 저장 공간을 셀 파일 (비우면 안 세)":                                              "file recording which key uploaded each file, used to count per-key storage (empty disables it)",
+	"키마다 올려 둘 수 있는 총 크기 (예: 20GB, 0 이면 제한 없음)":                                               "total size each key may keep stored (e.g. 20GB, 0 means unlimited)",
+	"저장 공간 한도를 넘었습니다":                                                                        "storage quota exceeded",
+	"저장 공간 기록 %s: %w":                                                                        "storage ledger %s: %w",
+	"저장 공간 기록 저장 실패: %w":                                                                     "failed to save storage ledger: %w",
+	"알 수 없는 server.collision: %q (%s)":                                                       "unknown server.collision: %q (%s)",
+	"알 수 없는 server.webdav: %q (%s)":                                                          "unknown server.webdav: %q (%s)",
+	"server.sftp_addr 를 주면 server.sftp_host_key 와 server.sftp_authorized_keys 도 필요합니다":       "server.sftp_addr needs server.sftp_host_key and server.sftp_authorized_keys",
+	"올린 이름의 파일이 이미 있을 때: overwrite(덮어쓰기) | reject(409) | uuid(이름-<uuid>) | version(이름-v2 …)": "when an uploaded name already exists: overwrite | reject (409) | uuid (name-<uuid>) | version (name-v2 …)",
+	"server.cert 와 server.key 는 같이 줘야 합니다":                                                   "server.cert and server.key must be given together",
+	"HTTPS 로 서빙 (-cert/-key 를 안 주면 자체 서명 인증서를 만들어 - 로컬 테스트용)":                                "serve over HTTPS (without -cert/-key a self-signed certificate is generated - for local testing)",
+	"TLS 인증서 PEM 파일 (주면 -tls 없이도 HTTPS)":                                                     "TLS certificate PEM file (implies HTTPS even without -tls)",
+	"TLS 개인키 PEM 파일":                                                                         "TLS private key PEM file",
+	"usage.keys.%s: 알 수 없는 scope: %q (%s)":                                                   "usage.keys.%s: unknown scope: %q (%s)",
+	"auth.jwt_secret 은 32바이트 이상이어야 합니다":                                                      "auth.jwt_secret must be at least 32 bytes",
+	"인증을 켜도 /files/ 는 키 없이 열어 둬":                                                             "keep /files/ open without a key even when authentication is on",
+	"받을 JWT 의 발급자(iss) (비우면 확인 안 함)":                                                         "required JWT issuer (iss) (empty: not checked)",
+	"받을 JWT 의 대상(aud) (비우면 확인 안 함)":                                                          "required JWT audience (aud) (empty: not checked)",
+	"server.download_rate 는 0 이상이어야 합니다: %s":                                                 "server.download_rate must be 0 or more: %s",
+	"다운로드 한 건의 초당 최대 크기 (예: 10MB, 0 이면 제한 없음 - 요청의 ?limit= 은 이보다 낮게만)":                       "max bytes per second for one download (e.g. 10MB, 0: unlimited - a request's ?limit= can only go lower)",
+	"저장소 %s 와 %s 는 같은 파일시스템이어야 합니다 (하드 링크): %w":                                              "store %s and %s must be on the same filesystem (hard links): %w",
+	"저장소 색인 %s: %w":                                                                          "store index %s: %w",
+	"저장소 색인 저장 실패: %w":                                                                       "failed to save store index: %w",
+	"같은 내용의 업로드를 한 벌만 둘 저장소 (예: ./store, 비우면 안 써 - -dir 과 같은 파일시스템)":                         "store that keeps one copy of identical uploads (e.g. ./store, empty: off - same filesystem as -dir)",
+	"server.dedup_dir 는 server.backend 를 비웠을 때(로컬 디렉토리)만 쓸 수 있습니다":                           "server.dedup_dir can only be used when server.backend is empty (local directory)",
+	"업로드 파일을 둘 저장소: 비우면 -dir, memory, s3://bucket/prefix (키는 AWS_ACCESS_KEY_ID 같은 환경 변수)":    "storage for uploaded files: empty for -dir, memory, s3://bucket/prefix (keys from environment variables such as AWS_ACCESS_KEY_ID)",
+	"s3:// 저장소의 엔드포인트 (예: http://localhost:9000, 비우면 AWS)":                                   "endpoint for an s3:// backend (e.g. http://localhost:9000, empty for AWS)",
+	"음수 위치로 이동할 수 없습니다":                                                                      "cannot seek to a negative position",
+	"파트 수 한도(%d)를 넘었습니다 - 파트 크기를 키우세요":                                                       "exceeded the part limit (%d) - use a larger part size",
+	"알 수 없는 server.backend: %q (memory 또는 s3://bucket/prefix)":                               "unknown server.backend: %q (memory or s3://bucket/prefix)",
+	"서버 응답: %s": "server responded: %s",
+	"요청한 위치(%d)와 다른 부분을 받음: %s": "received a different range than requested (offset %d): %s",
+	"기대한 값 %s, 받은 파일 %s":        "expected %s, downloaded file is %s",
+	"<URL> <대상>":                "<url> <dest>",
+	"HTTP 로 파일 받기 (.part 가 남아 있으면 그 크기부터 이어받고, 다 받으면 sha256 검증)": "download a file over HTTP (resumes from the size of a leftover .part, verifies sha256 when done)",
+	"기대하는 sha256 (비우면 서버의 X-Content-SHA256 으로 검증)":               "expected sha256 (empty: verify against the server's X-Content-SHA256)",
+	"연결이 끊기면 다시 요청하는 횟수":                                         "number of times to request again when the connection drops",
+	"서버의 API 키나 JWT (Authorization: Bearer 로 보내)":                "server API key or JWT (sent as Authorization: Bearer)",
+	"받기 완료: %s → %s (%d 바이트, 시도 %d번)":                            "download complete: %s → %s (%d bytes, %d attempts)",
+	", %d 바이트부터 이어받음":                                            ", resumed from byte %d",
+	", 서버 파일이 바뀌어서 처음부터 받음":                                      ", server file changed so it restarted from the beginning",
+	", sha256 확인 %s": ", sha256 verified %s",
+	", sha256 %s (서버가 알려 주지 않아 검증 안 함)":                                   ", sha256 %s (not verified: the server did not provide one)",
+	"URL 에서 파일 이름을 알 수 없어: %s (대상을 파일 경로로 줘)":                             "cannot tell the file name from the URL: %s (give a file path as the destination)",
+	"알 수 없는 server.access_log_format: %q (%s)":                            "unknown server.access_log_format: %q (%s)",
+	"server.access_log_max_size 는 0 보다, access_log_backups 는 0 이상이어야 합니다": "server.access_log_max_size must be greater than 0 and access_log_backups 0 or more",
+	"요청마다 한 줄씩 남길 접근 로그 파일 (비우면 안 남겨)":                                    "access log file with one line per request (empty: none)",
+	"접근 로그 형식 (json|combined)":                                            "access log format (json|combined)",
+	"접근 로그를 이 크기에서 .1, .2 … 로 돌려 (예: 100MB)":                              "rotate the access log to .1, .2 … at this size (e.g. 100MB)",
+	"남길 예전 접근 로그 파일 수 (0 이면 돌릴 때 버려)":                                     "number of rotated access log files to keep (0: discard on rotation)",
+	"server.upload_rate 는 0 이상이어야 합니다: %s":                                "server.upload_rate must be 0 or more: %s",
+	"server.max_downloads 는 0 이상이어야 합니다: %d":                              "server.max_downloads must be 0 or more: %d",
+	"server.download_queue 는 0 이상이어야 합니다: %s":                             "server.download_queue must be 0 or more: %s",
+	"server.large_download 는 0 이상이어야 합니다: %s":                             "server.large_download must be 0 or more: %s",
+	"업로드 한 건의 초당 최대 크기 (예: 5MB, 0 이면 제한 없음)":                              "max bytes per second for one upload (e.g. 5MB, 0 means no limit)",
+	"동시에 보낼 큰 다운로드 수 (0 이면 제한 없음 - 넘치면 기다렸다가 503)":                        "large downloads streamed at the same time (0 means no limit - excess requests wait, then 503)",
+	"다운로드 자리가 날 때까지 기다릴 최대 시간 (0 이면 바로 503)":                              "how long a download waits for a free slot (0 means 503 right away)",
+	"-max-downloads 로 셀 다운로드의 최소 크기 (예: 1MB, 0 이면 전부)":                    "smallest download counted by -max-downloads (e.g. 1MB, 0 means all)",
+	"scan.command 의 첫 값(실행 파일)이 비어 있습니다":                                  "scan.command: the first value (the executable) is empty",
+	"scan.timeout 은 0 이상이어야 합니다: %s":                                      "scan.timeout must be 0 or more: %s",
+	"%s: 검사에서 거절되었습니다":                                                    "%s: rejected by the scanner",
+	"%s: 검사에서 거절되었습니다: %s":                                                "%s: rejected by the scanner: %s",
+	"policy.types 의 형식이 잘못됐습니다 (예: image/png, image/*): %q":               "policy.types: invalid type (e.g. image/png, image/*): %q",
+	"policy.max_size 의 형식이 잘못됐습니다 (예: image/png, image/*): %q":            "policy.max_size: invalid type (e.g. image/png, image/*): %q",
+	"policy.max_size 의 %s 는 0 보다 커야 합니다":                                  "policy.max_size: %s must be greater than 0",
+	"%s: 업로드 정책에서 거절되었습니다: %s":                                            "%s: rejected by the upload policy: %s",
+	"확장자 %s 는 받지 않습니다":                                                    "extension %s is not accepted",
+	"확장자가 없는 파일은 받지 않습니다 (허용: %s)":                                        "files without an extension are not accepted (allowed: %s)",
+	"확장자 %s 는 허용 목록(%s)에 없습니다":                                            "extension %s is not in the allowed list (%s)",
+	"내용 형식 %s 는 허용 목록(%s)에 없습니다":                                          "content type %s is not in the allowed list (%s)",
+	"%s 검사 실패: %w": "%s: scan failed: %w",
+	"암호화된 파일이 아닙니다 (헤더가 맞지 않음)":                                                                            "not an encrypted file (header mismatch)",
+	"이 파일을 암호화한 키가 없습니다":                                                                                   "the key this file was encrypted with is not configured",
+	"암호화 키가 없습니다":                                                                                          "no encryption key",
+	"암호화 키 %d 의 길이가 %d 바이트입니다 (32바이트여야 합니다)":                                                               "encryption key %d is %d bytes long (must be 32 bytes)",
+	"%d번째 줄: 32바이트 키를 hex(64자리)나 base64 로 적어야 합니다":                                                         "line %d: write a 32-byte key as hex (64 digits) or base64",
+	"저장소의 파일이 Seek 을 지원하지 않습니다":                                                                            "the stored file does not support Seek",
+	"청크 %d 복호화 실패 (키가 다르거나 파일이 손상됨): %w":                                                                   "failed to decrypt chunk %d (wrong key or corrupt file): %w",
+	"음수 위치로 Seek 할 수 없습니다":                                                                                 "cannot Seek to a negative position",
+	"업로드를 암호화해서 둘 키 파일 (줄마다 32바이트 키를 hex/base64 로, 첫 줄로 암호화 - 비우면 그대로)":                                    "key file for encrypting uploads at rest (one 32-byte hex/base64 key per line, the first encrypts - empty: plain)",
+	"auth.sign_secret 은 32바이트 이상이어야 합니다":                                                                   "auth.sign_secret must be at least 32 bytes",
+	"auth.sign_max_ttl 은 0 이상이어야 합니다: %s":                                                                  "auth.sign_max_ttl must be 0 or more: %s",
+	"cors.origins 의 출처가 잘못됐습니다 (예: https://app.example.com, https://*.example.com, *): %q":                 "cors.origins: invalid origin (e.g. https://app.example.com, https://*.example.com, *): %q",
+	"cors.max_age 는 0 이상이어야 합니다: %s":                                                                       "cors.max_age must be 0 or more: %s",
+	"서명한 다운로드 URL 의 최대 유효 시간 (0 이면 24h)":                                                                   "maximum lifetime of a signed download URL (0 means 24h)",
+	"API 를 부를 수 있는 다른 출처 (쉼표 구분, 예: https://app.example.com,https://*.example.com, * 면 어디서든 - 비우면 CORS 끔)": "other origins allowed to call the API (comma-separated, e.g. https://app.example.com,https://*.example.com, * for any - empty disables CORS)",
+	"CORS 로 허락할 메서드 (쉼표 구분, 비우면 GET,HEAD,POST,PUT,PATCH,DELETE)":                                           "methods allowed over CORS (comma-separated, empty means GET,HEAD,POST,PUT,PATCH,DELETE)",
+	"CORS 로 보내도 되는 요청 헤더 (쉼표 구분, 비우면 브라우저가 물어본 그대로)":                                                       "request headers allowed over CORS (comma-separated, empty allows whatever the browser asks for)",
+	"브라우저 JS 가 읽을 수 있는 응답 헤더 (쉼표 구분, 비우면 Content-Range, X-Content-SHA256 같은 기본 목록)":                        "response headers readable by browser JS (comma-separated, empty means a default list such as Content-Range, X-Content-SHA256)",
+	"브라우저가 preflight 결과를 기억할 시간":                                                                           "how long browsers may cache a preflight result",
+	"지우기, 이름 바꾸기를 한 줄씩 덧붙일 감사 로그 파일 (비우면 안 남겨)":                                                            "audit log file; one line is appended per delete or rename (empty: none)",
+	"이미지 업로드마다 small/medium 썸네일을 만들어 /thumb 로 내보내":                                                         "make small/medium thumbnails for each image upload and serve them at /thumb",
+	"썸네일을 동시에 만들 수":                                                                                        "number of thumbnails generated concurrently",
+	"/dav 로 업로드 디렉토리를 WebDAV 드라이브로: off | read-only | read-write":                                          "serve the upload directory as a WebDAV drive at /dav: off | read-only | read-write",
+	"같은 파일을 SFTP 로도 열 주소 (예: :2022, 비우면 안 열어 - 공개키로만 로그인)":                                                 "address to also serve the same files over SFTP (e.g. :2022, empty = off - public-key login only)",
+	"SFTP 호스트 개인키 파일 (없으면 ed25519 로 만들어 저장)":                                                               "SFTP host private key file (generated as ed25519 if missing)",
+	"SFTP 로 로그인할 수 있는 공개키 (authorized_keys 형식, 인증을 켰으면 주석이 계정 이름)":                                         "public keys allowed to log in over SFTP (authorized_keys format; with auth on, the comment is the account name)",
+	"server.thumb_workers 는 1 ~ 64 여야 합니다: %d":                                                             "server.thumb_workers must be between 1 and 64: %d",
+	"이미지가 아니거나 지원하지 않는 형식 (JPEG, PNG, GIF)":                                                                "not an image or unsupported format (JPEG, PNG, GIF)",
+	"이미지 픽셀 수가 한도를 넘음":                                                                                     "image pixel count exceeds the limit",
+	"server.copy_targets 의 %q: 이름은 uploads 가 아니어야 하고 주소가 있어야 합니다":                                          "server.copy_targets %q: the name must not be uploads and the address must not be empty",
+
+	"server.hls_segment 는 1초 이상이어야 합니다: %s":          "server.hls_segment must be at least 1s: %s",
+	"MPEG-TS(.ts) 동영상을 /hls?file= 로 HLS 재생 목록으로 내보내": "serve MPEG-TS (.ts) videos as HLS playlists at /hls?file=",
+	"HLS 조각 길이 목표 (실제로는 다음 키프레임까지)":                  "target HLS segment length (segments run to the next keyframe)",
+	"HLS 로 나눌 수 있는 MPEG-TS 가 아닙니다":                   "not an MPEG-TS file that can be split for HLS",
+	"%w: %d 바이트에 동기 바이트가 없습니다":                       "%w: no sync byte at byte %d",
+	"%w: 빈 파일": "%w: empty file",
+	"%w: PCR 이 없어 길이를 알 수 없습니다":                 "%w: no PCR, so the duration is unknown",
+	"server.upload_workers 는 1 ~ 64 여야 합니다: %d": "server.upload_workers must be between 1 and 64: %d",
+	"/upload 의 files 파트를 동시에 저장할 수":             "number of /upload \"files\" parts saved concurrently",
+
+	"server.versions 는 0 ~ 1000 이어야 합니다: %d":                                    "server.versions must be between 0 and 1000: %d",
+	"server.versions 를 켰는데 server.version_dir 가 비어 있습니다":                        "server.versions is set but server.version_dir is empty",
+	"같은 이름을 덮어쓸 때 예전 내용을 남길 개수 (0 이면 안 남겨 - /api/files/<이름>/versions 로 보고 되돌려)": "number of previous versions to keep when an upload overwrites a name (0 keeps none - list and restore them at /api/files/<name>/versions)",
+	"덮어쓴 파일의 예전 버전을 둘 디렉토리":                                                     "directory for previous versions of overwritten files",
+
+	"server.expire_after 는 0 이상이어야 합니다: %s":                         "server.expire_after must not be negative: %s",
+	"server.expire_scan 은 1초 이상이어야 합니다: %s":                         "server.expire_scan must be at least 1s: %s",
+	"X-Expire-After 없이 올린 파일을 지울 때까지의 시간 (0 이면 안 지워, 헤더로는 이보다 짧게만)": "how long an upload without X-Expire-After is kept before it is deleted (0 keeps it forever; the header can only shorten it)",
+	"만료된 업로드를 찾아 지우는 주기":                                            "how often expired uploads are looked up and deleted",
+
+	"server.max_uploads_per_ip 는 0 이상이어야 합니다: %d":           "server.max_uploads_per_ip must not be negative: %d",
+	"클라이언트 주소 하나가 동시에 올릴 수 있는 업로드 수 (0 이면 제한 없음 - 넘치면 429)": "number of concurrent uploads allowed per client IP (0 for unlimited - 429 beyond that)",
+
+	"streamctl send 의 TCP 프로토콜로도 받을 주소 (예: :9000, 비우면 안 열어 - 인증을 켜면 거절)": "address to also accept uploads over the streamctl send TCP protocol (e.g. :9000, empty to disable - refused when auth is on)",
+
+	"server.upload_webhooks 의 URL 이 잘못되었습니다: %q": "invalid URL in server.upload_webhooks: %q",
+	"server.webhook_retries 는 0 이상이어야 합니다: %d":   "server.webhook_retries must not be negative: %d",
+	"업로드가 저장될 때마다 JSON 을 POST 할 웹훅 URL (쉼표 구분)":  "webhook URLs to POST JSON to whenever an upload is stored (comma-separated)",
+	"업로드 웹훅이 실패하면 다시 보낼 횟수":                      "how many times a failed upload webhook is retried",
+
+	"server.min_free 는 0 이상이어야 합니다: %s":                               "server.min_free must be 0 or more: %s",
+	"업로드 디렉토리에 이보다 적게 남으면 /healthz, /readyz 가 503 (예: 1GB, 0 이면 보기만)": "return 503 from /healthz and /readyz when the upload directory has less free space than this (e.g. 1GB, 0 only reports it)",
+
+	"server.trash_retention 은 0 이상이어야 합니다: %s":     "server.trash_retention must not be negative: %s",
+	"지운 파일을 휴지통에 남겨 두는 기간 - 지나면 영구 삭제 (0 이면 안 지워)": "how long deleted files stay in the trash before they are purged (0 keeps them forever)",
+
+	"사용법 : go run main.go [-progress=text|json|none] <로그파일 경로|->": "usage: go run main.go [-progress=text|json|none] <log file path|->",
+
+	"-delete 때 영구 삭제 대신 옮길 휴지통 디렉토리 (go run ./trash 로 복원)": "trash directory to move files to instead of deleting them with -delete (restore with go run ./trash)",
+	"파일별 출력 생략": "don't print each file",
+	"사용법: go run ./dirsync [-delete] [-dry-run] [-checksum] <원본> <대상>": "usage: go run ./dirsync [-delete] [-dry-run] [-checksum] <source> <destination>",
+
+	"이 깊이까지의 디렉토리만 진행 중에 출력 (-1 이면 출력 안 함)":  "print directories up to this depth while scanning (-1 prints none)",
+	"가장 큰 파일/디렉토리 몇 개":                       "how many of the largest files/directories to show",
+	"줄 단위 JSON 출력 (디렉토리마다 한 줄, 마지막에 요약 한 줄)": "line-delimited JSON output (one line per directory, a summary line at the end)",
+	"%10s  %6d 파일  %s\n": "%10s  %6d files  %s\n",
+	"\n합계: 논리 %s, 실제 %s, 파일 %d개, 디렉토리 %d개 (에러 %d)\n": "\ntotal: logical %s, allocated %s, %d files, %d directories (%d errors)\n",
+	"\n가장 큰 파일:":   "\nlargest files:",
+	"\n가장 큰 디렉토리:": "\nlargest directories:",
+
+	"휴지통 디렉토리": "trash directory",
+	"purge: 이보다 오래전에 지운 것만 (0 이면 전부)":                                     "purge: only items deleted longer ago than this (0 for all)",
+	"사용법: go run ./trash [-dir .trash] list|delete|restore|purge [인자...]": "usage: go run ./trash [-dir .trash] list|delete|restore|purge [args...]",
+	"%d개 항목\n":                                  "%d items\n",
+	"휴지통으로 이동: %s (ID: %s)\n":                   "moved to trash: %s (ID: %s)\n",
+	"복원: %s\n":                                  "restored: %s\n",
+	"%d개 항목 영구 삭제\n":                            "permanently deleted %d items\n",
+	"알 수 없는 명령: %s (list|delete|restore|purge)": "unknown command: %s (list|delete|restore|purge)",
+
+	"매니페스트 파일 경로 (- 면 create 는 stdout, verify 는 stdin)": "manifest file path (- means stdout for create, stdin for verify)",
+	"매니페스트 파일 경로 (-m 과 같음)":                             "manifest file path (same as -m)",
+	"병렬 해시 워커 수 (0 이면 CPU 수)":                           "parallel hash workers (0 for the number of CPUs)",
+	"사용법:": "usage:",
+	"  go run ./manifest create <디렉토리> [-o SHA256SUMS]": "  go run ./manifest create <directory> [-o SHA256SUMS]",
+	"  go run ./manifest verify <디렉토리> [-m SHA256SUMS]": "  go run ./manifest verify <directory> [-m SHA256SUMS]",
+	"%d개 파일의 체크섬을 stdout 으로 내보냈습니다\n":                   "wrote checksums of %d files to stdout\n",
+	"%d개 파일의 체크섬을 %s 에 저장했습니다\n":                        "saved checksums of %d files to %s\n",
+	"누락:   %s\n": "missing: %s\n",
+	"추가됨: %s\n":  "extra:   %s\n",
+	"검증 실패":      "verification failed",
+
+	"데이터 종류 (log|random|repeat)":     "kind of data (log|random|repeat)",
+	"파일 크기 (예: 512, 64KB, 10MB, 2G)": "file size (e.g. 512, 64KB, 10MB, 2G)",
+	"출력 파일 (- 면 stdout)":             "output file (- for stdout)",
+	"난수 시드 (같으면 같은 내용)":              "random seed (same seed, same content)",
+	"지정하면 파일 대신 이 디렉토리에 트리 생성":       "generate a tree in this directory instead of a single file",
+	"트리 깊이": "tree depth",
+	"디렉토리마다 하위 디렉토리 수":                     "subdirectories per directory",
+	"디렉토리마다 파일 수":                          "files per directory",
+	"트리 파일 최소 크기":                          "minimum size of tree files",
+	"트리 파일 최대 크기":                          "maximum size of tree files",
+	"트리에서 돌아가며 쓸 종류 (쉼표 구분)":               "kinds to rotate through in the tree (comma separated)",
+	"stdout 으로 생성 완료 (%s, %d 바이트)\n":       "generated to stdout (%s, %d bytes)\n",
+	"%s 생성 완료 (%s, %d 바이트)\n":              "generated %s (%s, %d bytes)\n",
+	"%s 생성 완료: 디렉토리 %d개, 파일 %d개, %d 바이트\n": "generated %s: %d directories, %d files, %d bytes\n",
+
+	"감시할 디렉토리":                            "directory to watch",
+	"실행할 작업 (compress|analyze|upload)":    "action to run (compress|analyze|upload)",
+	"compress/analyze 결과 저장 디렉토리":         "directory for compress/analyze output",
+	"upload 대상 URL":                       "upload target URL",
+	"처리할 파일 glob (쉼표 구분, 예: *.log,*.txt)": "globs of files to process (comma separated, e.g. *.log,*.txt)",
+	"마지막 변경 후 대기 시간":                      "how long to wait after the last change",
+	"동시 실행 작업 수":                          "number of actions to run at once",
+	"fsnotify 대신 폴링 사용":                   "poll instead of using fsnotify",
+	"폴링 주기":                               "polling interval",
+	"시작 시 이미 있는 파일도 처리":                   "also process files already present at startup",
+	"로그 형식 (text|json)":                   "log format (text|json)",
+	"업로드 실패: %s":                          "upload failed: %s",
+
+	"보낼 파일 크기":                                  "size of the file to send",
+	"편도 지연 (RTT 는 두 배)":                         "one-way delay (RTT is twice this)",
+	"링크 대역폭 (초당, 0 이면 제한 없음)":                   "link bandwidth (per second, 0 for unlimited)",
+	"손실률 목록 (쉼표 구분, 0~1)":                       "loss rates (comma separated, 0-1)",
+	"비교할 방식 (쉼표 구분, tcp/http/quic/h1/h2)":       "modes to compare (comma separated, tcp/http/quic/h1/h2)",
+	"h1/h2 동시 다운로드 수 목록 (쉼표 구분)":                "concurrent download counts for h1/h2 (comma separated)",
+	"전송 하나의 최대 시간":                              "maximum time for one transfer",
+	"결과를 줄 단위 JSON 으로":                          "print results as line-delimited JSON",
+	"잘못된 손실률: %q":                               "invalid loss rate: %q",
+	"파일 %s, 편도 지연 %v, 대역폭 %s/s, QUIC 스트림 %d개\n": "file %s, one-way delay %v, bandwidth %s/s, %d QUIC streams\n",
+	"(TCP 손실은 재전송 지연으로만 흉내내서 혼잡 창 감소가 빠져 있어 - 실제보다 TCP 에 유리해)\n\n": "(TCP loss is only simulated as retransmission delay, without congestion window cuts - this favors TCP over reality)\n\n",
+	"손실률":                      "loss",
+	"방식":                       "mode",
+	"시간":                       "time",
+	"%-6s %-6s %10s  실패: %s\n": "%-6s %-6s %10s  failed: %s\n",
+	"알 수 없는 방식: %s":            "unknown mode: %s",
+	"잘못된 동시 다운로드 수: %q":        "invalid concurrency: %q",
+	"다운로드 실패: %s":              "download failed: %s",
+	"%s 로 협상됐습니다 (%s 모드)":      "negotiated %s (%s mode)",
+
+	"알 수 없는 접근 로그 형식입니다: %q (%s, %s)":                "unknown access log format: %q (%s, %s)",
+	"알 수 없는 자격 증명입니다":                                "unknown credential",
+	"인증이 필요합니다 (X-API-Key 또는 Authorization: Bearer)": "authentication required (X-API-Key or Authorization: Bearer)",
+	"API 키나 토큰이 올바르지 않습니다":                           "invalid API key or token",
+	"권한이 없습니다: %s":                                   "permission denied: %s",
+	"지원하지 않는 토큰 서명 방식입니다: %s":                        "unsupported token signing algorithm: %s",
+	"토큰 서명이 올바르지 않습니다":                               "invalid token signature",
+	"토큰 클레임을 읽지 못했습니다":                               "could not read token claims",
+	"토큰에 sub 가 없습니다":                                 "token has no sub",
+	"토큰이 만료되었습니다":                                    "token has expired",
+	"아직 쓸 수 없는 토큰입니다 (nbf)":                          "token is not valid yet (nbf)",
+	"토큰 발급자(iss)가 다릅니다":                              "token issuer (iss) does not match",
+	"이 서버용 토큰(aud)이 아닙니다":                            "token is not for this server (aud)",
+	"서명 URL 이 꺼져 있습니다":                               "signed URLs are disabled",
+	"ttl 은 1h, 30m 같은 양의 시간이어야 합니다":                  "ttl must be a positive duration such as 1h or 30m",
+	"ttl 은 %s 이하여야 합니다":                              "ttl must be at most %s",
+	"서명이 올바르지 않습니다":                                  "invalid signature",
+	"만료된 URL 입니다":                                    "the URL has expired",
+
+	"암호화 키 파일 %s: %w": "encryption key file %s: %w",
+	"알 수 없는 저장소입니다: %s (memory 또는 s3://bucket/prefix)": "unknown storage: %s (memory or s3://bucket/prefix)",
+	"복사 대상 %q 가 Targets 와 TargetURLs 에 다 있습니다":         "copy target %q is in both Targets and TargetURLs",
+	"복사 대상 %q: %w": "copy target %q: %w",
+	"복사 대상 이름으로 %q 는 쓸 수 없습니다":                              "%q cannot be used as a copy target name",
+	"중복 제거 저장소(DedupDir)는 암호화하지 않은 로컬 디렉토리 저장소에서만 쓸 수 있습니다": "the dedup store (DedupDir) only works with an unencrypted local directory storage",
+	"저장소(%T)의 파일이 Seek 을 지원하지 않습니다":                         "files from the storage (%T) do not support Seek",
+	"파일 정보를 가져올 수 없습니다":                                     "could not get file info",
+	"SFTP 를 열려면 SFTPAuthorizedKeys 가 필요합니다 (공개키로만 로그인)":     "SFTP needs SFTPAuthorizedKeys (public key login only)",
+	"SFTP 호스트 키 %s: %w":                            "SFTP host key %s: %w",
+	"authorized_keys 에 없는 키입니다":                    "key is not in authorized_keys",
+	"authorized_keys 의 주석 %q 와 이름이 같은 계정이 없습니다":    "no account is named after the authorized_keys comment %q",
+	"SFTP 가 꺼져 있습니다 (SFTPAddr)":                    "SFTP is disabled (SFTPAddr)",
+	"session 채널만 됩니다":                              "only session channels are supported",
+	"인증을 켠 서버는 TCP 로 받지 않습니다 - HTTP 나 SFTP 로 올리세요": "a server with auth enabled does not accept TCP uploads - use HTTP or SFTP",
+	"남은 공간이 %d 바이트로 최소 %d 바이트보다 적습니다":              "only %d bytes free, less than the minimum of %d bytes",
+	"%s 의 버전 %d 을 남기지 못함: %w":                      "%s: could not keep version %d: %w",
+
+	"GET 메서드만 허용됩니다":                    "only GET is allowed",
+	"POST 메서드만 허용됩니다":                   "only POST is allowed",
+	"DELETE 메서드만 허용됩니다":                 "only DELETE is allowed",
+	"DELETE 또는 POST 메서드만 허용됩니다":         "only DELETE or POST is allowed",
+	"PUT, DELETE 메서드만 허용됩니다":            "only PUT and DELETE are allowed",
+	"PUT, GET, POST, DELETE 메서드만 허용됩니다": "only PUT, GET, POST and DELETE are allowed",
+	"HEAD, PATCH, DELETE 메서드만 허용됩니다":    "only HEAD, PATCH and DELETE are allowed",
+	"지원하지 않는 메서드입니다":                    "unsupported method",
+	"허락하지 않은 출처입니다: %s":                 "origin not allowed: %s",
+	"잘못된 파일명입니다":                        "invalid file name",
+	"파일명이 필요합니다":                        "file name is required",
+	"파일을 찾을 수 없습니다":                     "file not found",
+	"파일을 열 수 없습니다":                      "could not open the file",
+	"파일을 읽을 수 없습니다":                     "could not read the file",
+	"파일 정보를 읽을 수 없습니다":                  "could not read file info",
+	"파일을 가져올 수 없습니다":                    "could not get the file",
+	"파일 생성 실패":                          "failed to create the file",
+	"파일 저장 실패":                          "failed to save the file",
+	"파일 삭제 실패":                          "failed to delete the file",
+	"폼 파싱 실패":                           "failed to parse the form",
+	"임시 파일 생성 실패":                       "failed to create a temporary file",
+	"임시 디렉토리 생성 실패":                     "failed to create a temporary directory",
+	"목록을 읽을 수 없습니다":                     "could not read the list",
+	"동시에 보낼 수 있는 다운로드가 다 찼습니다 - 잠시 뒤 다시 시도하세요":            "too many downloads in progress - try again later",
+	"업로드 크기 제한(%d 바이트)을 넘었습니다":                            "upload size limit (%d bytes) exceeded",
+	"이번 달 전송 한도를 넘었습니다 (%s 에 초기화)":                        "monthly transfer limit exceeded (resets on %s)",
+	"전송량 집계가 꺼져 있습니다":                                     "usage accounting is disabled",
+	"이 주소에서 동시에 올리는 업로드가 %d 개를 넘었습니다 - 하나가 끝난 뒤 다시 시도하세요": "more than %d concurrent uploads from this address - try again after one finishes",
+	"limit 은 0 보다 큰 크기여야 합니다 (예: 2MB): %s":                "limit must be a size greater than 0 (e.g. 2MB): %s",
+	"%s 는 초(예: 3600)나 기간(예: 1h)이어야 합니다: %s":               "%s must be seconds (e.g. 3600) or a duration (e.g. 1h): %s",
+	"sort 는 name, size, mtime 중 하나입니다":                    "sort must be one of name, size, mtime",
+	"order 는 asc 또는 desc 입니다":                             "order must be asc or desc",
+	"offset, limit 은 0 이상의 정수입니다 (limit 은 최대 %d)":         "offset and limit must be non-negative integers (limit at most %d)",
+	"검색이 꺼져 있습니다":                                         "search is disabled",
+	"검색어(q)가 필요합니다":                                       "a search query (q) is required",
+
+	"파일 업로드 성공: %s (%d 바이트, %s 은 이미 있어서 새 이름으로, sha256 %s)\n": "uploaded: %s (%d bytes, renamed because %s already exists, sha256 %s)\n",
+	"파일 업로드 성공: %s (%d 바이트, sha256 %s)\n":                     "uploaded: %s (%d bytes, sha256 %s)\n",
+	"파일 삭제 완료: %s\n":                    "deleted: %s\n",
+	"파일 삭제 완료: %s (휴지통 ID: %s)\n":       "deleted: %s (trash ID: %s)\n",
+	"본문을 끝까지 받지 못했습니다":                  "the request body was not fully received",
+	"%s 는 64자리 16진수 sha256 이어야 합니다: %s": "%s must be a 64-digit hex sha256: %s",
+	"sha256 가 맞지 않습니다":                  "sha256 mismatch",
+	"업로드 정책에서 거절되었습니다":                  "rejected by the upload policy",
+	"업로드 검사 실패 - 잠시 뒤 다시 시도하세요":         "upload scan failed - try again later",
+	"업로드가 검사에서 거절되었습니다":                 "the upload was rejected by the scan",
+
+	"이미 있는 파일 이름입니다":         "a file with that name already exists",
+	"이미 있는 파일 이름입니다: %s":     "a file with that name already exists: %s",
+	"%s: 버전 %d 개를 다 썼습니다":    "%s: all %d versions are used up",
+	"저장할 이름을 고르지 못했습니다":      "could not pick a name to save as",
+	"그 이름의 파일이 이미 있습니다":      "a file with that name already exists",
+	"같은 이름의 파일이 이미 있습니다":     "a file with the same name already exists",
+	"같은 이름의 파일이 이미 있습니다: %s": "files with the same name already exist: %s",
+	"새 파일명(to)이 잘못됐습니다":      "invalid new file name (to)",
+	"새 파일명이 지금 이름과 같습니다":     "the new file name is the same as the current one",
+	"이름 바꾸기 실패":              "rename failed",
+
+	"복사한 파일의 sha256 이 원본과 다릅니다":   "the sha256 of the copied file differs from the source",
+	"새 파일명(as)이 잘못됐습니다":           "invalid new file name (as)",
+	"from, to 는 이 중 하나여야 합니다: %s": "from and to must be one of: %s",
+	"원본과 대상이 같습니다":                "source and destination are the same",
+	"복사 실패":                       "copy failed",
+
+	"WebDAV 는 읽기 전용입니다":                     "WebDAV is read-only",
+	"폴더는 만들 수 없습니다 (업로드 디렉토리는 한 층입니다)":      "cannot create folders (the upload directory is flat)",
+	"쓸 수 없는 이름입니다 (폴더나 점으로 시작하는 이름은 안 됩니다)": "name not allowed (no folders or names starting with a dot)",
+	"Destination 이 잘못됐습니다":                  "invalid Destination",
+	"쓸 수 없는 Destination 입니다":                "Destination not allowed",
+
+	"잘못된 디렉토리 이름입니다":                "invalid directory name",
+	"같은 이름의 파일이나 디렉토리가 이미 있습니다: %s": "a file or directory with the same name already exists: %s",
+	"아카이브 풀기 실패":                    "failed to extract the archive",
+	"아카이브 풀기 실패: %v":                "failed to extract the archive: %v",
+	"아카이브 받기 실패":                    "failed to receive the archive",
+	"zip 파일만 받습니다: %s":              "only zip files are accepted: %s",
+	"zip 파일이 아닙니다: %s":              "not a zip file: %s",
+	"이 저장소에서는 압축 풀기를 지원하지 않습니다 (로컬 디렉토리만)": "this storage does not support extraction (local directories only)",
+
+	"HLS 가 꺼져 있습니다":                            "HLS is disabled",
+	"HLS 로 나눌 수 있는 MPEG-TS(.ts) 동영상이 아닙니다: %s": "not an MPEG-TS (.ts) video that can be split for HLS: %s",
+	"썸네일이 꺼져 있습니다":                             "thumbnails are disabled",
+	"size 는 small 또는 medium 입니다":               "size must be small or medium",
+	"썸네일을 읽을 수 없습니다":                           "could not read the thumbnail",
+	"썸네일을 만들 수 없는 형식입니다 (JPEG, PNG, GIF)":      "cannot make thumbnails for this format (JPEG, PNG, GIF)",
+	"썸네일을 만드는 중입니다":                            "the thumbnail is being generated",
+
+	"멀티파트 업로드가 없거나 만료됐습니다":      "multipart upload not found or expired",
+	"멀티파트 업로드를 읽을 수 없습니다":       "could not read the multipart upload",
+	"멀티파트 업로드를 열 수 없습니다":        "could not open the multipart upload",
+	"멀티파트 업로드 생성 실패":            "failed to create the multipart upload",
+	"파트를 받는 중입니다":               "a part is being received",
+	"part 는 1 ~ %d 이어야 합니다":     "part must be between 1 and %d",
+	"업로드를 합치거나 취소하는 중입니다":       "the upload is being completed or aborted",
+	"파트 저장 실패":                  "failed to save the part",
+	"완료 요청 본문(JSON)을 읽을 수 없습니다": "could not read the completion request body (JSON)",
+	"파트 합치기 실패":                 "failed to combine the parts",
+	"받은 파트가 없습니다":               "no parts received",
+	"파트 번호는 늘어나는 순서로 적어야 합니다":   "part numbers must be in increasing order",
+	"받지 않은 파트입니다: %d":           "part not received: %d",
+	"파트 %d 의 ETag 가 받은 것과 다릅니다": "the ETag of part %d does not match the received one",
+
+	"받는 중인 업로드가 없습니다":                                        "no upload in progress",
+	"범위를 받는 중입니다":                                            "a range is being received",
+	"Content-Range 는 bytes 시작-끝/전체 나 bytes */전체 여야 합니다":      "Content-Range must be bytes start-end/total or bytes */total",
+	"Content-Range 나 Content-Length 가 필요합니다":                 "Content-Range or Content-Length is required",
+	"본문 길이가 Content-Range 와 다릅니다":                            "the body length does not match Content-Range",
+	"받는 중인 업로드의 전체 크기(%d 바이트)와 다릅니다 - DELETE 로 버리고 다시 보내세요":  "differs from the total size of the upload in progress (%d bytes) - DELETE it and send again",
+	"업로드 세션 생성 실패":                                           "failed to create the upload session",
+	"업로드를 저장하거나 취소하는 중입니다":                                   "the upload is being saved or cancelled",
+	"업로드 세션을 열 수 없습니다":                                       "could not open the upload session",
+	"업로드 세션 저장 실패":                                           "failed to save the upload session",
+	"업로드 세션이 없거나 만료됐습니다":                                     "upload session not found or expired",
+	"업로드 세션을 읽을 수 없습니다":                                      "could not read the upload session",
+	"다른 요청이 이어 쓰는 중입니다":                                      "another request is appending to it",
+	"Upload-Length 가 필요합니다":                                  "Upload-Length is required",
+	"Content-Type 은 application/offset+octet-stream 이어야 합니다": "Content-Type must be application/offset+octet-stream",
+	"Upload-Offset 이 받은 위치와 다릅니다":                            "Upload-Offset does not match the received offset",
+
+	"휴지통을 쓰지 않는 저장소입니다": "this storage does not use a trash",
+	"휴지통 목록을 읽을 수 없습니다": "could not read the trash list",
+	"잘못된 휴지통 경로입니다":     "invalid trash path",
+	"휴지통 항목을 찾을 수 없습니다": "trash item not found",
+	"휴지통에서 되살리기 실패":     "failed to restore from the trash",
+	"버전 목록을 읽을 수 없습니다":  "could not read the version list",
+	"잘못된 버전입니다":         "invalid version",
+	"버전을 찾을 수 없습니다":     "version not found",
+	"버전을 읽을 수 없습니다":     "could not read the version",
+	"버전 되돌리기 실패":        "failed to restore the version",
+
+	"사용법:\n  stream-cli upload [옵션] <파일>...       파일을 /upload 로 올리기\n  stream-cli download [옵션] <이름> [대상]  /download?file=<이름> 을 받아 대상(없으면 같은 이름, 디렉토리면 그 안)에 저장\n\n옵션:\n  -server <URL>     서버 주소 (기본 http://localhost:8080, FS_SERVER)\n  -api-key <키>     API 키나 JWT (Authorization: Bearer, FS_API_KEY)\n  -progress <방식>  none|text|bar|json (기본 bar, stderr 가 터미널이 아니면 끔)\n  -lang <언어>      ko|en (기본 FS_LANG, 없으면 LANG)": "usage:\n  stream-cli upload [options] <file>...        upload files to /upload\n  stream-cli download [options] <name> [dest]  fetch /download?file=<name> and save it to dest (default: same name; inside it if it is a directory)\n\noptions:\n  -server <URL>     server address (default http://localhost:8080, FS_SERVER)\n  -api-key <key>    API key or JWT (Authorization: Bearer, FS_API_KEY)\n  -progress <mode>  none|text|bar|json (default bar, off when stderr is not a terminal)\n  -lang <lang>      ko|en (default FS_LANG, then LANG)",
+	"서버 주소 (FS_SERVER)": "server address (FS_SERVER)",
+	"서버의 API 키나 JWT - Authorization: Bearer 로 보내 (FS_API_KEY)": "API key or JWT for the server - sent as Authorization: Bearer (FS_API_KEY)",
+	"서버 응답을 알아볼 수 없습니다: %v":                                    "could not understand the server response: %v",
+	"서버가 받은 내용이 다릅니다: %d 바이트 sha256 %s (보낸 건 %d 바이트 %s)":       "the server received different content: %d bytes sha256 %s (sent %d bytes %s)",
+	"올림: %s → %s (같은 이름이 있어서 새 이름으로, %d 바이트, sha256 %s)\n":     "uploaded: %s → %s (renamed because the name was taken, %d bytes, sha256 %s)\n",
+	"올림: %s → %s (%d 바이트, sha256 %s)\n":                        "uploaded: %s → %s (%d bytes, sha256 %s)\n",
+	"%d 바이트 중 %d 바이트만 받았습니다":                                   "expected %d bytes but received only %d",
+	"sha256 이 다릅니다: 받은 %s, 서버 %s":                              "sha256 mismatch: received %s, server %s",
+	"받음: %s → %s (%d 바이트, sha256 %s - 서버가 알려 주지 않아 검증 안 함)\n":  "downloaded: %s → %s (%d bytes, sha256 %s - not verified, the server did not send one)\n",
+	"받음: %s → %s (%d 바이트, sha256 확인 %s)\n":                     "downloaded: %s → %s (%d bytes, sha256 verified %s)\n",
+}
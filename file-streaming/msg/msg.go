@@ -0,0 +1,132 @@
+// Package msg 는 사용자에게 보이는 문구(도움말, 진행률, 결과, 에러)를 한국어/영어로 바꿔 주는 메시지 카탈로그야.
+//
+// ⭐ gettext 처럼 한국어 원문이 그대로 키라서, 코드에는 지금처럼 한국어를 쓰고 msg.T / msg.Errorf 로 감싸기만 하면 돼.
+// 번역이 없는 문구는 원문(한국어)이 그대로 나가니까, 카탈로그(en.go)를 조금씩 채워 나가도 깨지는 데가 없어.
+//
+//	fmt.Println(msg.T("복사 완료"))
+//	return msg.Errorf("파일 열기 실패: %w", err)      // %w 는 그대로라 errors.Is/As 도 그대로
+//	var ErrLimit = msg.New("아카이브 한도 초과")        // 고정 에러도 Error() 를 부를 때 번역해서 errors.Is 로 비교 가능
+//
+// slog 로그 메시지는 번역하지 않아 - 로그는 grep 하고 모으는 거라 언어가 바뀌면 곤란해.
+package msg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Lang 문구 언어
+type Lang string
+
+const (
+	Auto    Lang = ""   // 환경 변수(LC_ALL, LC_MESSAGES, LANG)로 정해
+	Korean  Lang = "ko" // 원문
+	English Lang = "en"
+)
+
+// catalogs 언어별 번역 (한국어 원문 → 번역) - 한국어는 원문 그대로라 없어
+var catalogs = map[Lang]map[string]string{English: en}
+
+// current 지금 언어 - 앱(main)이 SetLang 을 부르기 전까지는 원문 그대로 (라이브러리 테스트가 LANG 에 흔들리지 않게)
+var current atomic.Value
+
+func init() { current.Store(Korean) }
+
+// ParseLang "ko", "en", "en_US.UTF-8" 같은 값을 Lang 으로 (비우면 Auto)
+func ParseLang(s string) (Lang, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "" || s == "auto":
+		return Auto, nil
+	case strings.HasPrefix(s, "ko"):
+		return Korean, nil
+	case strings.HasPrefix(s, "en"):
+		return English, nil
+	}
+	return Auto, Errorf("알 수 없는 언어: %q (ko, en)", s)
+}
+
+func (l Lang) String() string { return string(l) }
+
+// Set flag.Value
+func (l *Lang) Set(s string) error {
+	v, err := ParseLang(s)
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// UnmarshalText YAML/환경 변수에서 읽을 때
+func (l *Lang) UnmarshalText(b []byte) error { return l.Set(string(b)) }
+
+// MarshalText config 출력용
+func (l Lang) MarshalText() ([]byte, error) { return []byte(l), nil }
+
+// Detect 로캘 환경 변수로 언어 고르기 - ko 로 시작하면 한국어, 비었거나 C/POSIX 면 원래대로 한국어, 나머지는 영어
+func Detect() Lang {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		v = strings.ToLower(v)
+		switch {
+		case strings.HasPrefix(v, "ko"), v == "c", v == "posix", strings.HasPrefix(v, "c."):
+			return Korean
+		}
+		return English
+	}
+	return Korean
+}
+
+// SetLang 이후 문구를 l 로 (Auto 면 Detect) - 프로그램 시작할 때 플래그를 등록하기 전에 한 번
+func SetLang(l Lang) {
+	if l == Auto {
+		l = Detect()
+	}
+	current.Store(l)
+}
+
+// Current 지금 언어
+func Current() Lang { return current.Load().(Lang) }
+
+// T 원문 s 를 지금 언어로 (번역이 없으면 s 그대로)
+func T(s string) string {
+	if tr, ok := catalogs[Current()][s]; ok {
+		return tr
+	}
+	return s
+}
+
+// Sprintf format 을 번역한 뒤 fmt.Sprintf
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Fprintf format 을 번역한 뒤 fmt.Fprintf
+func Fprintf(w io.Writer, format string, args ...any) (int, error) {
+	return fmt.Fprintf(w, T(format), args...)
+}
+
+// Printf format 을 번역한 뒤 fmt.Printf
+func Printf(format string, args ...any) (int, error) {
+	return fmt.Printf(T(format), args...)
+}
+
+// Errorf format 을 번역한 뒤 fmt.Errorf - %w 로 감싼 에러는 그대로 꺼낼 수 있어
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// Error New 로 만든 에러 - 포인터로 비교하니까 errors.Is 는 언어와 상관없이 맞아
+type Error struct{ text string }
+
+// New text 를 Error() 할 때마다 지금 언어로 번역하는 에러 (패키지 변수로 두는 고정 에러용)
+func New(text string) error { return &Error{text: text} }
+
+func (e *Error) Error() string { return T(e.text) }
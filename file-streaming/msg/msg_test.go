@@ -0,0 +1,98 @@
+package msg
+
+import (
+	"errors"
+	"io/fs"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// verbRe printf 동사 (%%, 플래그/폭/정밀도, %[n] 인덱스 포함)
+var verbRe = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// verbs 동사 글자만 순서대로 (%% 는 빼고)
+func verbs(s string) []string {
+	var out []string
+	for _, m := range verbRe.FindAllString(s, -1) {
+		v := m[len(m)-1:]
+		if v == "%" {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestCatalogVerbs(t *testing.T) {
+	for key, tr := range en {
+		if tr == "" {
+			t.Errorf("%q: 번역이 비어 있음", key)
+			continue
+		}
+		if a, b := verbs(key), verbs(tr); !slices.Equal(a, b) {
+			t.Errorf("%q → %q: 동사 %v ≠ %v", key, tr, a, b)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLang(Current())
+
+	errBase := New("심볼릭 링크 순환")
+	wrapped := Errorf("파일 열기 실패: %w", fs.ErrNotExist)
+
+	SetLang(Korean)
+	if got := Sprintf("%d개 파일", 3); got != "3개 파일" {
+		t.Errorf("한국어 = %q", got)
+	}
+	if errBase.Error() != "심볼릭 링크 순환" {
+		t.Errorf("한국어 에러 = %q", errBase)
+	}
+
+	SetLang(English)
+	if got := Sprintf("%d개 파일", 3); got != "3 files" {
+		t.Errorf("영어 = %q", got)
+	}
+	if got := T("카탈로그에 없는 문구"); got != "카탈로그에 없는 문구" {
+		t.Errorf("번역 없는 문구는 원문 그대로여야 함: %q", got)
+	}
+	if errBase.Error() != "symbolic link cycle" {
+		t.Errorf("영어 에러 = %q", errBase)
+	}
+	// 언어가 바뀌어도 errors.Is 는 그대로
+	if !errors.Is(Errorf("동기화 중단: %w", errBase), errBase) || !errors.Is(wrapped, fs.ErrNotExist) {
+		t.Error("errors.Is 가 안 맞음")
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	for in, want := range map[string]Lang{"": Auto, "auto": Auto, "ko": Korean, "ko_KR.UTF-8": Korean, "EN": English, "en_US.UTF-8": English} {
+		if got, err := ParseLang(in); err != nil || got != want {
+			t.Errorf("ParseLang(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLang("fr"); err == nil {
+		t.Error("fr 은 에러여야 함")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, c := range []struct {
+		all, lang string
+		want      Lang
+	}{
+		{"", "", Korean},
+		{"", "C.UTF-8", Korean},
+		{"", "ko_KR.UTF-8", Korean},
+		{"", "en_US.UTF-8", English},
+		{"ko_KR.UTF-8", "en_US.UTF-8", Korean}, // LC_ALL 이 먼저
+	} {
+		t.Setenv("LC_ALL", c.all)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", c.lang)
+		if got := Detect(); got != c.want {
+			t.Errorf("LC_ALL=%q LANG=%q: %q, want %q", c.all, c.lang, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Email SMTP 로 메일을 보내는 Sender
+type Email struct {
+	Addr     string // 호스트:포트
+	From     string
+	To       []string
+	Username string // 비우면 인증 없이
+	Password string
+
+	Subject *template.Template
+	Body    *template.Template
+}
+
+func (m *Email) String() string { return "smtp " + m.Addr }
+
+func (m *Email) Send(ctx context.Context, e Event) error {
+	data, err := m.message(e)
+	if err != nil {
+		return err
+	}
+	host, port, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return permanentError{err}
+	}
+
+	// 465 는 처음부터 TLS (SMTPS), 나머지는 평문으로 붙고 서버가 지원하면 STARTTLS
+	var conn net.Conn
+	if port == "465" {
+		conn, err = (&tls.Dialer{Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", m.Addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", m.Addr)
+	}
+	if err != nil {
+		return err
+	}
+	// net/smtp 는 context 를 몰라서 연결 deadline 으로 제한 시간을 걸어
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && port != "465" {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		// PlainAuth 는 TLS 가 아니면 (localhost 빼고) 비밀번호를 보내지 않고 에러를 내
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, host)); err != nil {
+			return permanentError{msg.Errorf("SMTP 인증 실패: %w", err)}
+		}
+	}
+	if err := c.Mail(m.From); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message 헤더 + quoted-printable 본문 (제목의 한글은 RFC 2047 로 인코딩)
+func (m *Email) message(e Event) ([]byte, error) {
+	subject, err := render(m.Subject, e)
+	if err != nil {
+		return nil, err
+	}
+	body, err := render(m.Body, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	qp.Close()
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,308 @@
+// Package notify 는 명령이나 예약/큐 작업이 끝났을 때 웹훅(POST)과 메일(SMTP)로 알리는 패키지야.
+//
+// ⭐ streamio.Hooks 에 붙여서 써 - 복사/작업 코드는 알림이 있는지 몰라도 돼:
+//   - Batch: 명령 하나 동안의 전송을 모아서 끝날 때 알림 한 번 (sync 로 파일 천 개를 옮겨도 메일은 한 통)
+//   - JobHooks: schedule/queue 작업이 끝날 때마다 알림 한 번
+//
+// 본문은 text/template 으로 바꿀 수 있고, 보내기에 실패하면 간격을 두 배씩 늘리며 다시 보내.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Event 알림 하나에 담기는 내용 - 템플릿에서 {{.Name}}, {{.OK}}, {{.Bytes}} 처럼 써
+type Event struct {
+	Name     string        `json:"name"`   // 명령("streamctl copy a b")이나 작업 이름
+	Source   string        `json:"source"` // streamctl | schedule | queue
+	Host     string        `json:"host"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Files    int           `json:"files"` // 끝난 전송 수
+	Failed   int           `json:"failed,omitempty"`
+	Bytes    int64         `json:"bytes"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"-"` // JSON 에는 duration_ms 로
+}
+
+// MarshalJSON 걸린 시간은 나노초 대신 duration_ms 로 (웹훅 받는 쪽에서 읽기 쉽게)
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plain Event
+	return json.Marshal(struct {
+		plain
+		DurationMS int64 `json:"duration_ms"`
+	}{plain(e), e.Duration.Milliseconds()})
+}
+
+// Status 성공/실패 한 단어 (템플릿용)
+func (e Event) Status() string {
+	if e.OK {
+		return msg.T("완료")
+	}
+	return msg.T("실패")
+}
+
+// Sender 알림을 실제로 보내는 곳 (웹훅, 메일)
+type Sender interface {
+	Send(ctx context.Context, e Event) error
+	String() string // 로그에 남길 대상 이름
+}
+
+// permanentError 다시 보내도 똑같이 실패할 에러 (잘못된 URL, 4xx 응답) - 재시도하지 않아
+type permanentError struct{ err error }
+
+func (p permanentError) Error() string { return p.err.Error() }
+func (p permanentError) Unwrap() error { return p.err }
+
+// 기본값
+const (
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+	DefaultTimeout = 10 * time.Second
+)
+
+// Options 알림 설정 - Webhook 도 SMTP 도 비어 있으면 아무것도 안 보내
+type Options struct {
+	FailuresOnly bool // 실패했을 때만 보내
+
+	Webhook string // POST 할 URL
+
+	SMTP     string   // 메일 서버 호스트:포트 (465 면 처음부터 TLS, 아니면 서버가 지원할 때 STARTTLS)
+	From     string   // 보내는 사람
+	To       []string // 받는 사람
+	Username string   // SMTP 인증 (비우면 인증 없이)
+	Password string
+
+	Subject  string // 메일 제목 템플릿 (기본: [streamctl] 이름 완료/실패)
+	Template string // 본문 템플릿 - 비우면 웹훅은 Event JSON, 메일은 기본 문구
+
+	Retries int           // 보내기 실패 시 재시도 횟수 (0 이면 한 번만)
+	Backoff time.Duration // 첫 재시도까지 기다리는 시간 (두 배씩 늘어나)
+	Timeout time.Duration // 한 번 보내기 제한 시간
+
+	Logger *slog.Logger // 기본 component=notify
+}
+
+const (
+	defaultSubject = `[streamctl] {{.Name}} {{.Status}}`
+	defaultBody    = `{{.Name}} {{.Status}}{{if .Error}}: {{.Error}}{{end}}
+
+출처: {{.Source}} ({{.Host}})
+시작: {{.Start.Format "2006-01-02 15:04:05"}}
+걸린 시간: {{.Duration}}
+전송: {{.Files}}개{{if .Failed}} (실패 {{.Failed}}개){{end}}, {{.Bytes}} 바이트
+`
+)
+
+// funcs 템플릿에서 쓰는 함수 - {{json .Error}} 는 따옴표까지 붙은 JSON 문자열 (Slack 같은 JSON 본문 만들 때)
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, msg.Errorf("%s 템플릿 오류: %w", name, err)
+	}
+	return t, nil
+}
+
+func render(t *template.Template, e Event) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, e); err != nil {
+		return "", permanentError{msg.Errorf("%s 템플릿 실행 실패: %w", t.Name(), err)}
+	}
+	return sb.String(), nil
+}
+
+// Notifier 설정된 곳들로 알림을 보내
+type Notifier struct {
+	opts    Options
+	senders []Sender
+	host    string
+}
+
+// New 설정대로 Notifier 생성 (템플릿은 여기서 미리 확인해)
+func New(opts Options) (*Notifier, error) {
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.For("notify")
+	}
+	n := &Notifier{opts: opts}
+	n.host, _ = os.Hostname()
+
+	var body *template.Template
+	if opts.Template != "" {
+		var err error
+		if body, err = parseTemplate("template", opts.Template); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Webhook != "" {
+		n.senders = append(n.senders, &Webhook{URL: opts.Webhook, Body: body})
+	}
+	if opts.SMTP != "" {
+		if opts.From == "" || len(opts.To) == 0 {
+			return nil, msg.New("메일 알림에는 보내는 사람(from)과 받는 사람(to)이 필요합니다")
+		}
+		subjectText := opts.Subject
+		if subjectText == "" {
+			subjectText = defaultSubject
+		}
+		subject, err := parseTemplate("subject", subjectText)
+		if err != nil {
+			return nil, err
+		}
+		mailBody := body
+		if mailBody == nil {
+			mailBody = template.Must(parseTemplate("body", defaultBody))
+		}
+		n.senders = append(n.senders, &Email{
+			Addr: opts.SMTP, From: opts.From, To: opts.To,
+			Username: opts.Username, Password: opts.Password,
+			Subject: subject, Body: mailBody,
+		})
+	}
+	return n, nil
+}
+
+// Enabled 보낼 곳이 하나라도 있는지 (nil 이어도 돼)
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.senders) > 0
+}
+
+// Notify 모든 대상에 보내 - 대상마다 재시도하고, 끝내 못 보낸 건 로그를 남기고 에러로 돌려줘
+// ⭐ 알림은 부가 기능이라 부르는 쪽은 보통 에러를 무시해도 돼 (전송 결과가 알림 실패로 바뀌면 안 되니까)
+func (n *Notifier) Notify(ctx context.Context, e Event) error {
+	if !n.Enabled() || (e.OK && n.opts.FailuresOnly) {
+		return nil
+	}
+	if e.Host == "" {
+		e.Host = n.host
+	}
+	var errs []error
+	for _, s := range n.senders {
+		if err := n.deliver(ctx, s, e); err != nil {
+			n.opts.Logger.Warn("알림 보내기 실패", "to", s.String(), "event", e.Name, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver 한 대상에 재시도하며 보내기 (간격 Backoff, 2배씩)
+func (n *Notifier) deliver(ctx context.Context, s Sender, e Event) error {
+	wait := n.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, n.opts.Timeout)
+		err := s.Send(sendCtx, e)
+		cancel()
+		var perm permanentError
+		if err == nil || errors.As(err, &perm) || attempt >= n.opts.Retries {
+			return err
+		}
+		n.opts.Logger.Info("알림 재시도", "to", s.String(), "attempt", attempt+1, "wait", wait, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// Batch 명령 하나 동안 끝난 전송을 모으는 훅 - 명령이 끝나면 Event 로 알림 한 통
+type Batch struct {
+	streamio.NopHooks
+	name  string
+	start time.Time
+
+	mu     sync.Mutex
+	files  int
+	failed int
+	bytes  int64
+}
+
+// NewBatch name 으로 알릴 Batch (시작 시각은 지금)
+func NewBatch(name string) *Batch {
+	return &Batch{name: name, start: time.Now()}
+}
+
+func (b *Batch) OnComplete(info streamio.TransferInfo, transferred int64, elapsed time.Duration) {
+	b.mu.Lock()
+	b.files++
+	b.bytes += transferred
+	b.mu.Unlock()
+}
+
+func (b *Batch) OnError(info streamio.TransferInfo, err error) {
+	b.mu.Lock()
+	b.failed++
+	b.mu.Unlock()
+}
+
+// Event 지금까지 모은 걸로 알림 내용 만들기 - err 는 명령 전체의 결과
+func (b *Batch) Event(err error) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := Event{Name: b.name, Source: "streamctl", OK: err == nil, Files: b.files, Failed: b.failed, Bytes: b.bytes, Start: b.start, Duration: time.Since(b.start)}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// JobHooks 작업(TransferInfo.ID 가 작업 이름) 하나가 끝날 때마다 알리는 훅 - schedule/queue 의 Options.Hooks 에 넣어
+// 알림은 작업을 돌린 고루틴에서 바로 보내 (재시도 동안 그 작업 자리는 비지 않아)
+func (n *Notifier) JobHooks(source string) streamio.Hooks {
+	if !n.Enabled() {
+		return streamio.NopHooks{}
+	}
+	return &jobHooks{n: n, source: source}
+}
+
+type jobHooks struct {
+	streamio.NopHooks
+	n      *Notifier
+	source string
+	starts sync.Map // ID → 시작 시각 (실패도 걸린 시간을 알리려고)
+}
+
+func (h *jobHooks) OnStart(info streamio.TransferInfo) {
+	h.starts.Store(info.ID, time.Now())
+}
+
+func (h *jobHooks) OnComplete(info streamio.TransferInfo, transferred int64, elapsed time.Duration) {
+	h.starts.Delete(info.ID)
+	h.n.Notify(context.Background(), Event{Name: info.ID, Source: h.source, OK: true, Files: 1, Bytes: transferred, Start: time.Now().Add(-elapsed), Duration: elapsed})
+}
+
+func (h *jobHooks) OnError(info streamio.TransferInfo, err error) {
+	e := Event{Name: info.ID, Source: h.source, Error: err.Error(), Start: time.Now()}
+	if start, ok := h.starts.LoadAndDelete(info.ID); ok {
+		e.Start = start.(time.Time)
+		e.Duration = time.Since(e.Start)
+	}
+	h.n.Notify(context.Background(), e)
+}
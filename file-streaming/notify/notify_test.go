@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+func TestWebhookRetry(t *testing.T) {
+	var calls atomic.Int32
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- r.Header.Get("Content-Type") + " " + string(b)
+		if calls.Add(1) < 3 {
+			http.Error(w, "잠깐 안 됨", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	n, err := New(Options{Webhook: srv.URL, Retries: 3, Backoff: time.Millisecond, Template: `{"text": {{json (printf "%s %s" .Name .Status)}}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := NewBatch("streamctl copy a b")
+	batch.OnComplete(streamio.TransferInfo{ID: "a"}, 42, time.Second)
+	if err := n.Notify(context.Background(), batch.Event(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("보낸 횟수 = %d, want 3 (503 두 번 뒤 성공)", calls.Load())
+	}
+	if got := <-bodies; got != `application/json {"text": "streamctl copy a b 완료"}` {
+		t.Errorf("본문 = %s", got)
+	}
+
+	// 4xx 는 다시 보내도 같으니까 재시도하지 않아
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "잘못된 요청", http.StatusBadRequest)
+	})
+	calls.Store(0)
+	if err := n.Notify(context.Background(), Event{Name: "x"}); err == nil || calls.Load() != 1 {
+		t.Errorf("400: err = %v, 보낸 횟수 %d (want 1)", err, calls.Load())
+	}
+
+	// 실패만 알리면 성공은 보내지 않아
+	n.opts.FailuresOnly = true
+	calls.Store(0)
+	n.Notify(context.Background(), Event{Name: "x", OK: true})
+	if calls.Load() != 0 {
+		t.Error("FailuresOnly 인데 성공 알림을 보냄")
+	}
+}
+
+func TestWebhookDefaultJSON(t *testing.T) {
+	got := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]any
+		json.NewDecoder(r.Body).Decode(&m)
+		got <- m
+	}))
+	defer srv.Close()
+
+	n, _ := New(Options{Webhook: srv.URL})
+	hooks := n.JobHooks("schedule")
+	info := streamio.TransferInfo{ID: "nightly-sync"}
+	hooks.OnStart(info)
+	hooks.OnError(info, errors.New("디스크 가득 참"))
+	m := <-got
+	if m["name"] != "nightly-sync" || m["source"] != "schedule" || m["ok"] != false || m["error"] != "디스크 가득 참" {
+		t.Errorf("웹훅 JSON = %v", m)
+	}
+	if _, ok := m["duration_ms"]; !ok {
+		t.Errorf("duration_ms 가 없음: %v", m)
+	}
+}
+
+// fakeSMTP 메일 한 통만 받는 최소 SMTP 서버 - 받은 DATA 를 돌려줘
+func fakeSMTP(t *testing.T) (addr string, data <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 fake")
+			case "DATA":
+				reply("354 go ahead")
+				var sb strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					sb.WriteString(l)
+				}
+				out <- sb.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestEmail(t *testing.T) {
+	addr, data := fakeSMTP(t)
+	n, err := New(Options{SMTP: addr, From: "backup@example.com", To: []string{"ops@example.com", "me@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := Event{Name: "streamctl sync ./data /backup", Source: "streamctl", Error: "권한 없음", Files: 3, Bytes: 1024, Duration: 2 * time.Second, Start: time.Now()}
+	if err := n.Notify(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-data
+	header, body, _ := strings.Cut(msg, "\r\n\r\n")
+	if !strings.Contains(header, "To: ops@example.com, me@example.com") || !strings.Contains(header, "Subject: =?utf-8?q?") {
+		t.Errorf("헤더:\n%s", header)
+	}
+	decoded, _ := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+	if text := string(decoded); !strings.Contains(text, "streamctl sync ./data /backup 실패: 권한 없음") || !strings.Contains(text, "3개, 1024 바이트") {
+		t.Errorf("본문:\n%s", text)
+	}
+
+	if _, err := New(Options{SMTP: addr}); err == nil {
+		t.Error("from/to 없이 SMTP 설정이 통과됨")
+	}
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Webhook URL 로 POST 하는 Sender
+// Body 가 nil 이면 Event 를 JSON 으로, 있으면 템플릿 결과를 보내 ({ 로 시작하면 JSON, 아니면 text/plain)
+type Webhook struct {
+	URL    string
+	Body   *template.Template
+	Client *http.Client // nil 이면 http.DefaultClient (시간 제한은 ctx 로)
+}
+
+func (w *Webhook) String() string {
+	// 쿼리에 토큰을 넣는 웹훅이 많아서 로그에는 호스트까지만
+	if u, err := url.Parse(w.URL); err == nil && u.Host != "" {
+		return "webhook " + u.Scheme + "://" + u.Host
+	}
+	return "webhook"
+}
+
+func (w *Webhook) Send(ctx context.Context, e Event) error {
+	var body []byte
+	contentType := "application/json"
+	if w.Body == nil {
+		var err error
+		if body, err = json.Marshal(e); err != nil {
+			return permanentError{err}
+		}
+	} else {
+		text, err := render(w.Body, e)
+		if err != nil {
+			return err
+		}
+		body = []byte(text)
+		if trimmed := strings.TrimSpace(text); !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			contentType = "text/plain; charset=utf-8"
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", contentType)
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // 연결을 재사용하려면 본문을 비워야 해
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = msg.Errorf("웹훅 응답 %s", resp.Status)
+	// 4xx 는 요청이 잘못된 거라 다시 보내도 같아 (408 시간 초과, 429 너무 많음은 빼고)
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
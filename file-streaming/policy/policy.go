@@ -0,0 +1,137 @@
+// Package policy 는 업로드를 받을지 확장자와 내용 형식(MIME)으로 정하는 규칙이야.
+// 서버는 본문 앞 SniffLen 바이트만 받아 보고 판정해서, 안 되는 파일은 디스크에 쓰기 전에 415 로 돌려보내.
+//
+//	rules := policy.Rules{Block: []string{".exe"}, Types: []string{"image/*", "application/pdf"}, MaxSize: map[string]int64{"image/*": 10 << 20}}
+//	ct, limit, err := rules.Check("a.png", head) // head = 본문 앞 512 바이트, limit 은 그 형식의 최대 크기 (0 이면 없음)
+//
+// ⭐ 형식은 클라이언트가 보낸 Content-Type 이나 확장자가 아니라 내용으로 봐 (http.DetectContentType) -
+// 이름만 .png 로 바꾼 실행 파일은 application/octet-stream 으로 보여서 image/* 만 받으면 걸려.
+// 확장자는 마지막 것만 보고(.tar.gz 는 .gz) 대소문자는 가리지 않아. Block 이 Allow 보다 먼저야.
+package policy
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// SniffLen 형식을 볼 때 읽는 앞부분 크기 (http.DetectContentType 이 보는 만큼)
+const SniffLen = 512
+
+// Rules 업로드 정책 - 빈 값이면 다 받아
+type Rules struct {
+	Allow   []string         // 받을 확장자 (".jpg" 나 "jpg") - 비우면 Block 에 없는 건 다
+	Block   []string         // 받지 않을 확장자
+	Types   []string         // 받을 내용 형식 ("image/png", "image/*") - 비우면 다
+	MaxSize map[string]int64 // 형식 패턴 → 최대 크기 (여러 개 맞으면 가장 구체적인 것)
+}
+
+// Rejected 정책에 걸린 업로드
+type Rejected struct {
+	Name   string
+	Type   string // 내용으로 본 형식 (확장자로 걸렸으면 비어 있어)
+	Reason string
+}
+
+func (e *Rejected) Error() string {
+	return msg.Sprintf("%s: 업로드 정책에서 거절되었습니다: %s", e.Name, e.Reason)
+}
+
+// IsZero 아무 규칙도 없는지 (서버는 이러면 앞부분을 미리 읽지도 않아)
+func (r Rules) IsZero() bool {
+	return len(r.Allow) == 0 && len(r.Block) == 0 && len(r.Types) == 0 && len(r.MaxSize) == 0
+}
+
+// CheckName 이름(확장자)만으로 판정 - 내용을 받기 전에 거를 수 있는 만큼
+func (r Rules) CheckName(name string) error {
+	ext := Ext(name)
+	if hasExt(r.Block, ext) {
+		return &Rejected{Name: name, Reason: msg.Sprintf("확장자 %s 는 받지 않습니다", ext)}
+	}
+	if len(r.Allow) > 0 && !hasExt(r.Allow, ext) {
+		allowed := strings.Join(r.Allow, ", ")
+		if ext == "" {
+			return &Rejected{Name: name, Reason: msg.Sprintf("확장자가 없는 파일은 받지 않습니다 (허용: %s)", allowed)}
+		}
+		return &Rejected{Name: name, Reason: msg.Sprintf("확장자 %s 는 허용 목록(%s)에 없습니다", ext, allowed)}
+	}
+	return nil
+}
+
+// Check name 과 내용 앞부분 head 로 판정 - 통과하면 본 형식과 그 형식의 최대 크기 (0 이면 없음), 걸리면 *Rejected
+func (r Rules) Check(name string, head []byte) (string, int64, error) {
+	if err := r.CheckName(name); err != nil {
+		return "", 0, err
+	}
+	ct := Sniff(head)
+	if len(r.Types) > 0 && best(r.Types, ct) == "" {
+		return ct, 0, &Rejected{Name: name, Type: ct, Reason: msg.Sprintf("내용 형식 %s 는 허용 목록(%s)에 없습니다", ct, strings.Join(r.Types, ", "))}
+	}
+	var limit int64
+	if pattern := best(slices.Collect(maps.Keys(r.MaxSize)), ct); pattern != "" {
+		limit = r.MaxSize[pattern]
+	}
+	return ct, limit, nil
+}
+
+// Sniff head 로 본 형식 - 매개변수는 떼어 ("text/plain; charset=utf-8" → "text/plain")
+func Sniff(head []byte) string {
+	ct, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return strings.TrimSpace(ct)
+}
+
+// Ext 소문자 마지막 확장자 ("a.TAR.GZ" → ".gz", 없으면 "")
+func Ext(name string) string {
+	i := strings.LastIndexAny(name, "./\\")
+	if i < 0 || name[i] != '.' {
+		return ""
+	}
+	return strings.ToLower(name[i:])
+}
+
+// ValidType 형식 패턴이 "종류/세부", "종류/*", "*/*" 중 하나인지 (설정 검사용)
+func ValidType(pattern string) bool {
+	major, minor, ok := strings.Cut(pattern, "/")
+	if !ok || major == "" || minor == "" || strings.ContainsAny(minor, "/ ;") {
+		return false
+	}
+	return major != "*" || minor == "*"
+}
+
+// hasExt exts 에 ext 가 있는지 (설정의 점, 대소문자는 가리지 않아)
+func hasExt(exts []string, ext string) bool {
+	for _, e := range exts {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// best patterns 중 ct 에 맞는 가장 구체적인 것 (정확히 같은 것 > "종류/*" > "*/*"), 없으면 "" - 적힌 그대로 돌려줘
+func best(patterns []string, ct string) string {
+	major, _, _ := strings.Cut(ct, "/")
+	found, rank := "", 0
+	for _, p := range patterns {
+		r := 0
+		switch q := strings.ToLower(strings.TrimSpace(p)); {
+		case q == ct:
+			r = 3
+		case q == major+"/*":
+			r = 2
+		case q == "*/*":
+			r = 1
+		}
+		if r > rank {
+			found, rank = p, r
+		}
+	}
+	return found
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+var (
+	pngHead = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdfHead = []byte("%PDF-1.7\n")
+	exeHead = []byte("MZ\x90\x00\x03\x00\x00\x00")
+)
+
+func TestCheck(t *testing.T) {
+	rules := Rules{
+		Allow:   []string{".png", "PDF", ".txt", ".bin"},
+		Block:   []string{".bin"},
+		Types:   []string{"image/*", "application/pdf", "text/plain"},
+		MaxSize: map[string]int64{"image/*": 100, "image/png": 50, "*/*": 1000},
+	}
+	tests := []struct {
+		name   string
+		head   []byte
+		ct     string
+		limit  int64
+		reject bool
+	}{
+		{"a.png", pngHead, "image/png", 50, false},         // image/png 가 image/* 보다 구체적
+		{"A.PDF", pdfHead, "application/pdf", 1000, false}, // 대소문자, 점 없는 설정
+		{"note.txt", []byte("안녕\n"), "text/plain", 1000, false},
+		{"empty.txt", nil, "text/plain", 1000, false},
+		{"fake.png", exeHead, "application/octet-stream", 0, true}, // 이름만 .png
+		{"a.bin", pngHead, "", 0, true},                            // block 이 allow 보다 먼저
+		{"a.exe", exeHead, "", 0, true},                            // allow 에 없어
+		{"README", []byte("hi"), "", 0, true},                      // 확장자 없음
+	}
+	for _, tt := range tests {
+		ct, limit, err := rules.Check(tt.name, tt.head)
+		var rej *Rejected
+		if tt.reject != errors.As(err, &rej) {
+			t.Errorf("Check(%q) err = %v, reject %v", tt.name, err, tt.reject)
+			continue
+		}
+		if tt.reject {
+			if rej.Name != tt.name || rej.Type != tt.ct || rej.Reason == "" {
+				t.Errorf("Check(%q) Rejected = %+v", tt.name, rej)
+			}
+			continue
+		}
+		if ct != tt.ct || limit != tt.limit {
+			t.Errorf("Check(%q) = %q, %d, want %q, %d", tt.name, ct, limit, tt.ct, tt.limit)
+		}
+	}
+}
+
+func TestZeroRules(t *testing.T) {
+	var rules Rules
+	if !rules.IsZero() {
+		t.Error("빈 Rules 가 IsZero 가 아님")
+	}
+	if ct, limit, err := rules.Check("a.exe", exeHead); err != nil || ct != "application/octet-stream" || limit != 0 {
+		t.Errorf("Check = %q, %d, %v", ct, limit, err)
+	}
+	if (Rules{Block: []string{".exe"}}).IsZero() {
+		t.Error("Block 만 있어도 규칙이 있는 것")
+	}
+}
+
+func TestExt(t *testing.T) {
+	for name, want := range map[string]string{
+		"a.TAR.GZ": ".gz",
+		"a":        "",
+		"dir.d/a":  "",
+		`dir.d\a`:  "",
+		".bashrc":  ".bashrc",
+	} {
+		if got := Ext(name); got != want {
+			t.Errorf("Ext(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestValidType(t *testing.T) {
+	for pattern, want := range map[string]bool{
+		"image/png":                 true,
+		"image/*":                   true,
+		"*/*":                       true,
+		"*/png":                     false,
+		"image":                     false,
+		"image/":                    false,
+		"/png":                      false,
+		"a/b/c":                     false,
+		"text/plain; charset=utf-8": false,
+	} {
+		if got := ValidType(pattern); got != want {
+			t.Errorf("ValidType(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package queue
+
+// processAlive 시그널 0 이 없는 플랫폼은 모른다고 보고 살아 있는 걸로 쳐 (잠금 갱신 시각으로만 판단)
+func processAlive(pid int) bool { return true }
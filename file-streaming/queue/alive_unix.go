@@ -0,0 +1,19 @@
+//go:build unix
+
+package queue
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processAlive 같은 호스트의 pid 가 아직 살아 있는지 (시그널 0 은 보내지 않고 확인만 해)
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
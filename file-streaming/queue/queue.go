@@ -0,0 +1,296 @@
+// Package queue 는 오래 걸리는 작업(압축 묶음, 동기화, 백업)을 디스크에 쌓아 두고 차례로 돌리는 작업 큐야.
+// streamctl queue 가 이 위에 올라가.
+//
+// ⭐ 작업 하나가 디렉토리 안의 JSON 파일 하나라서, 넣는 프로세스(queue add)와 돌리는 프로세스(queue run)가 달라도 되고
+// 돌리던 프로세스가 죽어도 상태가 남아 - 다음에 Run 이 시작할 때 "실행 중" 으로 남은 작업을 찾아서
+// 작업 종류마다 정한 대로 정리(Recover)한 뒤 이어서 돌리거나, 정리가 안 되면 실패로 되돌려.
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// State 작업 상태
+type State string
+
+const (
+	Queued   State = "queued"   // 기다리는 중 (재시작으로 중단된 것도 여기로 돌아와)
+	Running  State = "running"  // 돌고 있음 - Run 이 아닌 곳에서 이 상태가 보이면 돌리던 프로세스가 죽은 거야
+	Done     State = "done"     // 성공
+	Failed   State = "failed"   // 실패 (Retry 로 다시 넣을 수 있어)
+	Canceled State = "canceled" // Cancel 로 취소
+)
+
+// Finished 다시 돌지 않는 상태인지
+func (s State) Finished() bool { return s == Done || s == Failed || s == Canceled }
+
+// Job 큐에 든 작업 하나 (파일 내용 그대로)
+type Job struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	State      State           `json:"state"`
+	Created    time.Time       `json:"created"`
+	Started    time.Time       `json:"started,omitzero"`
+	Finished   time.Time       `json:"finished,omitzero"`
+	Attempts   int             `json:"attempts"`            // 실행을 시작한 횟수 (재시작으로 이어 간 것도 포함)
+	Recovered  int             `json:"recovered,omitempty"` // 죽은 프로세스에서 넘겨받은 횟수
+	Summary    string          `json:"summary,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Checkpoint json.RawMessage `json:"checkpoint,omitempty"` // 작업이 남긴 진행 상태 (어디까지 했는지)
+	Owner      string          `json:"owner,omitempty"`      // 돌리고 있는 프로세스 (pid@host)
+}
+
+// Options 큐 옵션
+type Options struct {
+	Workers int           // 동시에 돌릴 작업 수 (기본 1)
+	Poll    time.Duration // 다른 프로세스가 넣은 작업과 취소 요청을 확인하는 주기 (기본 1초)
+	Logger  *slog.Logger  // 기본 component=queue
+
+	// Hooks 작업을 시작할 때 OnStart, 끝나면 OnComplete/OnError (ID 는 "kind 작업ID") - 종료 신호로 멈춘 건 끝난 게 아니라 안 불러
+	Hooks streamio.Hooks
+}
+
+// Queue 디렉토리 하나에 든 작업 큐
+type Queue struct {
+	dir  string
+	opts Options
+
+	mu       sync.Mutex // 작업 파일 읽고 고쳐 쓰기
+	handlers map[string]Handler
+	wake     chan struct{} // 같은 프로세스에서 Enqueue/Retry 하면 Run 을 바로 깨워
+	busy     atomic.Int32  // 지금 돌고 있는 작업 수 (Busy)
+}
+
+// Open 큐 디렉토리 열기 (없으면 만들어)
+func Open(dir string, opts Options) (*Queue, error) {
+	if dir == "" {
+		return nil, msg.New("큐 디렉토리가 비어 있습니다")
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Poll <= 0 {
+		opts.Poll = time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.For("queue")
+	}
+	if opts.Hooks == nil {
+		opts.Hooks = streamio.NopHooks{}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, msg.Errorf("큐 디렉토리 만들기 실패: %w", err)
+	}
+	return &Queue{dir: dir, opts: opts, handlers: make(map[string]Handler), wake: make(chan struct{}, 1)}, nil
+}
+
+// Dir 큐 디렉토리
+func (q *Queue) Dir() string { return q.dir }
+
+// Enqueue 작업 추가 - params 는 JSON 으로 저장돼서 실행할 때 Task.Params 로 다시 꺼내
+func (q *Queue) Enqueue(kind string, params any) (Job, error) {
+	if kind == "" {
+		return Job{}, msg.New("작업 종류가 비어 있습니다")
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return Job{}, msg.Errorf("작업 인자: %w", err)
+	}
+	now := time.Now()
+	j := Job{ID: newID(now), Kind: kind, Params: raw, State: Queued, Created: now}
+	q.mu.Lock()
+	err = q.write(j)
+	q.mu.Unlock()
+	if err != nil {
+		return Job{}, err
+	}
+	q.notify()
+	return j, nil
+}
+
+// newID 만든 시각 순으로 정렬되는 ID (20261015-143612-9f2c1a)
+func newID(t time.Time) string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// List 모든 작업 (만든 순서)
+func (q *Queue) List() ([]Job, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		j, err := q.read(strings.TrimSuffix(name, ".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // 방금 지워졌어
+		}
+		if err != nil {
+			q.opts.Logger.Warn("작업 파일을 읽지 못해서 건너뜀", "file", name, "err", err)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	sort.SliceStable(jobs, func(a, b int) bool { return jobs[a].Created.Before(jobs[b].Created) })
+	return jobs, nil
+}
+
+// Get 작업 하나 - ID 앞부분만 줘도 하나로 정해지면 찾아줘
+func (q *Queue) Get(id string) (Job, error) {
+	if j, err := q.read(id); err == nil {
+		return j, nil
+	}
+	jobs, err := q.List()
+	if err != nil {
+		return Job{}, err
+	}
+	var found []Job
+	for _, j := range jobs {
+		if strings.HasPrefix(j.ID, id) {
+			found = append(found, j)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Job{}, msg.Errorf("없는 작업: %s", id)
+	case 1:
+		return found[0], nil
+	default:
+		return Job{}, msg.Errorf("%s 로 시작하는 작업이 %d개입니다 - 더 길게 주세요", id, len(found))
+	}
+}
+
+// Cancel 취소 요청 - 기다리는 작업은 바로 취소되고, 돌고 있는 작업은 Run 이 다음 확인 때 멈춰
+// ⭐ 돌리는 프로세스가 따로 있을 수 있어서 <id>.cancel 파일로 알려 (같은 작업 파일을 두 프로세스가 동시에 고쳐 쓰지 않게)
+func (q *Queue) Cancel(id string) (Job, error) {
+	j, err := q.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if j.State.Finished() {
+		return j, msg.Errorf("%s 은 이미 끝난 작업입니다 (%s)", j.ID, j.State)
+	}
+	if err := os.WriteFile(q.cancelPath(j.ID), nil, 0644); err != nil {
+		return j, err
+	}
+	if j.State == Queued {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		j.State, j.Finished, j.Error = Canceled, time.Now(), "취소됨"
+		return j, q.write(j)
+	}
+	q.notify()
+	return j, nil
+}
+
+// Retry 실패/취소된 작업을 다시 기다리는 상태로 (체크포인트가 남아 있으면 거기서부터)
+func (q *Queue) Retry(id string) (Job, error) {
+	j, err := q.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if j.State != Failed && j.State != Canceled {
+		return j, msg.Errorf("%s 은 실패하거나 취소된 작업이 아닙니다 (%s)", j.ID, j.State)
+	}
+	os.Remove(q.cancelPath(j.ID))
+	q.mu.Lock()
+	j.State, j.Error, j.Summary, j.Finished, j.Owner = Queued, "", "", time.Time{}, ""
+	err = q.write(j)
+	q.mu.Unlock()
+	if err != nil {
+		return j, err
+	}
+	q.notify()
+	return j, nil
+}
+
+// Remove 끝난 작업 파일 지우기 (돌고 있거나 기다리는 작업은 먼저 취소해)
+func (q *Queue) Remove(id string) (Job, error) {
+	j, err := q.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if !j.State.Finished() {
+		return j, msg.Errorf("%s 은 아직 끝나지 않은 작업입니다 (%s) - 먼저 취소하세요", j.ID, j.State)
+	}
+	os.Remove(q.cancelPath(j.ID))
+	return j, os.Remove(q.jobPath(j.ID))
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) jobPath(id string) string    { return filepath.Join(q.dir, id+".json") }
+func (q *Queue) cancelPath(id string) string { return filepath.Join(q.dir, id+".cancel") }
+
+func (q *Queue) cancelRequested(id string) bool {
+	_, err := os.Stat(q.cancelPath(id))
+	return err == nil
+}
+
+func (q *Queue) read(id string) (Job, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return Job{}, os.ErrNotExist
+	}
+	data, err := os.ReadFile(q.jobPath(id))
+	if err != nil {
+		return Job{}, err
+	}
+	var j Job
+	if err := json.Unmarshal(data, &j); err != nil {
+		return Job{}, msg.Errorf("작업 파일 %s: %w", id, err)
+	}
+	return j, nil
+}
+
+// write 작업 파일 쓰기 (q.mu 를 잡고) - 임시 파일 → rename 이라 다른 프로세스가 반쯤 쓴 파일을 보지 않아
+func (q *Queue) write(j Job) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(q.dir, "."+j.ID+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// 전원이 나가도 작업 상태는 남아야 하니까 rename 전에 디스크까지
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), q.jobPath(j.ID))
+}
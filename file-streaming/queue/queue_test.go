@@ -0,0 +1,154 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 숫자 n 개를 하나씩 처리하면서 체크포인트를 남기는 작업 - stop 번째에서 "죽어"
+type countParams struct{ N, Stop int }
+
+func countHandler(seen *[]int) Handler {
+	return Handler{
+		Run: func(ctx context.Context, t *Task) (string, error) {
+			var p countParams
+			if err := t.Params(&p); err != nil {
+				return "", err
+			}
+			next := 0
+			t.Checkpoint(&next)
+			for i := next; i < p.N; i++ {
+				if i == p.Stop && t.Attempts == 1 {
+					panic("프로세스가 죽은 셈")
+				}
+				*seen = append(*seen, i)
+				if err := t.SaveCheckpoint(i + 1); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("%d개", p.N), nil
+		},
+	}
+}
+
+func TestQueueDrainAndResume(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(dir, Options{Poll: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []int
+	q.Handle("count", countHandler(&seen))
+	j, err := q.Enqueue("count", countParams{N: 5, Stop: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := q.Get(j.ID)
+	if got.State != Failed || got.Error == "" {
+		t.Fatalf("패닉 난 작업 = %s %q, want failed", got.State, got.Error)
+	}
+
+	// 죽은 프로세스가 남긴 것처럼 "실행 중" 으로 되돌려 놓고 새 큐로 열면 체크포인트부터 이어 가야 해
+	got.State, got.Error, got.Owner = Running, "", "1@다른호스트"
+	q.mu.Lock()
+	q.write(got)
+	q.mu.Unlock()
+
+	q2, _ := Open(dir, Options{Poll: 10 * time.Millisecond})
+	recovered := false
+	h := countHandler(&seen)
+	h.Recover = func(ctx context.Context, t *Task) error {
+		var next int
+		recovered = t.Checkpoint(&next) && next == 3
+		return nil
+	}
+	q2.Handle("count", h)
+	if err := q2.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !recovered {
+		t.Error("Recover 가 체크포인트 3 으로 불리지 않음")
+	}
+	got, _ = q2.Get(j.ID[:10]) // 앞부분만 줘도 찾아
+	if got.State != Done || got.Recovered != 1 || got.Attempts != 2 {
+		t.Errorf("복구 후 작업 = %s (복구 %d, 시도 %d), want done/1/2", got.State, got.Recovered, got.Attempts)
+	}
+	if want := []int{0, 1, 2, 3, 4}; fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("처리한 순서 = %v, want %v (이미 한 건 다시 하지 않아야 해)", seen, want)
+	}
+}
+
+func TestQueueRecoverFailure(t *testing.T) {
+	q, _ := Open(t.TempDir(), Options{Poll: 10 * time.Millisecond})
+	j, _ := q.Enqueue("x", nil)
+	j.State = Running
+	q.write(j)
+
+	q.Handle("x", Handler{
+		Run:     func(context.Context, *Task) (string, error) { return "", errors.New("돌면 안 됨") },
+		Recover: func(context.Context, *Task) error { return errors.New("정리 불가") },
+	})
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := q.Get(j.ID)
+	if got.State != Failed || got.Error != "재시작 복구 실패: 정리 불가" {
+		t.Errorf("작업 = %s %q, want 복구 실패로 failed", got.State, got.Error)
+	}
+}
+
+func TestQueueCancelAndRetry(t *testing.T) {
+	q, _ := Open(t.TempDir(), Options{Poll: 10 * time.Millisecond})
+	started := make(chan struct{})
+	q.Handle("wait", Handler{Run: func(ctx context.Context, t *Task) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}})
+	running, _ := q.Enqueue("wait", nil)
+	queued, _ := q.Enqueue("wait", nil)
+	if _, err := q.Cancel(queued.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- q.Drain(context.Background()) }()
+	<-started
+	if holder, alive := q.Runner(); holder == "" || !alive {
+		t.Errorf("Runner() = %q, %v - 돌고 있는 러너가 보여야 해", holder, alive)
+	}
+	other, _ := Open(q.Dir(), Options{})
+	if err := other.Drain(context.Background()); err == nil {
+		t.Error("같은 큐를 두 번째 러너가 잡음")
+	}
+	if _, err := q.Cancel(running.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{running.ID, queued.ID} {
+		if got, _ := q.Get(id); got.State != Canceled {
+			t.Errorf("%s = %s, want canceled", id, got.State)
+		}
+	}
+	if got, err := q.Retry(queued.ID); err != nil || got.State != Queued {
+		t.Errorf("Retry = %s, %v", got.State, err)
+	}
+	if _, err := q.Remove(queued.ID); err == nil {
+		t.Error("기다리는 작업을 지움")
+	}
+	if _, err := q.Remove(running.ID); err != nil {
+		t.Error(err)
+	}
+	if jobs, _ := q.List(); len(jobs) != 1 {
+		t.Errorf("남은 작업 %d개, want 1", len(jobs))
+	}
+}
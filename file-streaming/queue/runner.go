@@ -0,0 +1,346 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Handler 작업 종류 하나를 어떻게 돌리는지
+type Handler struct {
+	// Run 작업 본체 - 돌려준 문자열은 작업에 남는 한 줄 요약이야.
+	// 오래 걸리면 중간중간 Task.SaveCheckpoint 로 어디까지 했는지 남겨 두고, 시작할 때 Task.Checkpoint 로 이어서 해.
+	Run func(ctx context.Context, t *Task) (string, error)
+
+	// Recover 돌리던 프로세스가 죽어서 "실행 중" 으로 남은 작업을 다시 돌리기 전에 정리 (반쯤 쓴 결과 지우기, 남은 잠금 풀기)
+	// nil 이면 정리 없이 다시 Run 해. 에러를 돌려주면 이어 가지 않고 실패로 남겨 (Retry 로 사람이 다시 넣어).
+	Recover func(ctx context.Context, t *Task) error
+}
+
+// Task Run/Recover 에 넘어가는 작업 - 인자와 체크포인트를 꺼내고 저장해
+type Task struct {
+	Job
+	q *Queue
+}
+
+// Params Enqueue 때 준 인자를 v 로
+func (t *Task) Params(v any) error {
+	if err := json.Unmarshal(t.Job.Params, v); err != nil {
+		return msg.Errorf("작업 인자: %w", err)
+	}
+	return nil
+}
+
+// Checkpoint 저장된 체크포인트를 v 로 (없으면 false - 처음부터)
+func (t *Task) Checkpoint(v any) bool {
+	if len(t.Job.Checkpoint) == 0 {
+		return false
+	}
+	return json.Unmarshal(t.Job.Checkpoint, v) == nil
+}
+
+// SaveCheckpoint 어디까지 했는지 작업 파일에 저장 - 여기서 죽어도 다음 Run 은 이 값부터 이어 가
+func (t *Task) SaveCheckpoint(v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.Job.Checkpoint = raw
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+	return t.q.write(t.Job)
+}
+
+// Handle 작업 종류 등록 (Run/Drain 전에)
+func (q *Queue) Handle(kind string, h Handler) {
+	q.handlers[kind] = h
+}
+
+// Busy 이 프로세스의 Run/Drain 에서 지금 돌고 있는 작업 수와 최대 동시 작업 수 (상태 확인용)
+func (q *Queue) Busy() (running, workers int) {
+	return int(q.busy.Load()), q.opts.Workers
+}
+
+// errCanceled Cancel 로 멈춘 작업 (종료 신호로 멈춘 것과 구분)
+var errCanceled = msg.New("취소됨")
+
+// Run ctx 가 취소될 때까지 작업을 돌려 (새로 들어오는 작업도 기다려)
+// ⭐ 취소되면 돌고 있던 작업을 멈추고 "기다리는 중" 으로 돌려놔서, 다음 Run 이 체크포인트부터 이어 가.
+func (q *Queue) Run(ctx context.Context) error { return q.run(ctx, false) }
+
+// Drain 지금 기다리는 작업을 다 돌리면 끝 (cron 에서 주기적으로 부를 때)
+func (q *Queue) Drain(ctx context.Context) error { return q.run(ctx, true) }
+
+func (q *Queue) run(ctx context.Context, drain bool) error {
+	unlock, err := q.lockRunner()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	beatCtx, stopBeat := context.WithCancel(context.Background())
+	defer stopBeat()
+	go q.heartbeat(beatCtx)
+
+	if err := q.recoverJobs(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan string)
+	running := make(map[string]context.CancelCauseFunc)
+	ticker := time.NewTicker(q.opts.Poll)
+	defer ticker.Stop()
+	ctxDone := ctx.Done()
+
+	for {
+		for id, cancel := range running {
+			if q.cancelRequested(id) {
+				cancel(errCanceled)
+			}
+		}
+
+		waiting := 0
+		if ctx.Err() == nil {
+			jobs, err := q.List()
+			if err != nil {
+				q.opts.Logger.Warn("큐 디렉토리를 읽지 못함", "dir", q.dir, "err", err)
+			}
+			for _, j := range jobs {
+				if j.State != Queued || running[j.ID] != nil {
+					continue
+				}
+				if len(running) >= q.opts.Workers {
+					waiting++
+					continue
+				}
+				h, ok := q.handlers[j.Kind]
+				switch {
+				case q.cancelRequested(j.ID):
+					q.finish(j, Canceled, errCanceled.Error())
+				case !ok:
+					q.finish(j, Failed, fmt.Sprintf("모르는 작업 종류: %s", j.Kind))
+				default:
+					jctx, cancel := context.WithCancelCause(ctx)
+					running[j.ID] = cancel
+					go func() {
+						defer cancel(nil)
+						q.execute(jctx, j, h)
+						done <- j.ID
+					}()
+				}
+			}
+		}
+		if len(running) == 0 && (ctx.Err() != nil || (drain && waiting == 0)) {
+			q.busy.Store(0)
+			return nil
+		}
+
+		q.busy.Store(int32(len(running)))
+		select {
+		case id := <-done:
+			delete(running, id)
+		case <-ticker.C:
+		case <-q.wake:
+		case <-ctxDone:
+			ctxDone = nil // 작업들이 멈추길 기다리는 동안 계속 깨지 않게
+		}
+	}
+}
+
+// execute 작업 한 번 실행하고 결과 저장
+func (q *Queue) execute(ctx context.Context, j Job, h Handler) {
+	lg := q.opts.Logger.With("job", j.ID, "kind", j.Kind)
+	j.State, j.Started, j.Finished, j.Error, j.Owner = Running, time.Now(), time.Time{}, "", owner()
+	j.Attempts++
+	q.mu.Lock()
+	err := q.write(j)
+	q.mu.Unlock()
+	if err != nil {
+		lg.Error("작업 파일 쓰기 실패", "err", err)
+		return
+	}
+	if len(j.Checkpoint) > 0 {
+		lg.Info("작업 이어서 시작", "attempt", j.Attempts)
+	} else {
+		lg.Info("작업 시작")
+	}
+
+	info := streamio.TransferInfo{ID: j.Kind + " " + j.ID, Src: "queue", Size: -1}
+	q.opts.Hooks.OnStart(info)
+	t := &Task{Job: j, q: q}
+	summary, err := call(func() (string, error) { return h.Run(ctx, t) })
+	j = t.Job
+	j.Summary, j.Owner = summary, ""
+	elapsed := time.Since(j.Started)
+
+	switch {
+	case err == nil:
+		lg.Info("작업 완료", "elapsed", elapsed, "summary", summary)
+		q.finish(j, Done, "")
+		q.opts.Hooks.OnComplete(info, 0, elapsed)
+	case context.Cause(ctx) == errCanceled:
+		lg.Warn("작업 취소됨", "elapsed", elapsed)
+		q.finish(j, Canceled, errCanceled.Error())
+		q.opts.Hooks.OnError(info, errCanceled)
+	case ctx.Err() != nil:
+		// 종료 신호 - 실패가 아니라 다음 Run 에서 체크포인트부터 이어 가
+		lg.Warn("종료 신호로 작업을 멈춤 - 다음 실행 때 이어서 해", "elapsed", elapsed)
+		j.State, j.Summary = Queued, "중단됨 - 다음 실행 때 이어서"
+
+		q.mu.Lock()
+		q.write(j)
+		q.mu.Unlock()
+	default:
+		lg.Error("작업 실패", "elapsed", elapsed, "err", err)
+		q.finish(j, Failed, err.Error())
+		q.opts.Hooks.OnError(info, err)
+	}
+}
+
+// finish 끝난 상태로 저장 (취소 요청 파일도 정리)
+func (q *Queue) finish(j Job, state State, msg string) {
+	j.State, j.Error, j.Finished, j.Owner = state, msg, time.Now(), ""
+	q.mu.Lock()
+	err := q.write(j)
+	q.mu.Unlock()
+	if err != nil {
+		q.opts.Logger.Error("작업 파일 쓰기 실패", "job", j.ID, "err", err)
+	}
+	os.Remove(q.cancelPath(j.ID))
+}
+
+// recoverJobs "실행 중" 으로 남은 작업 = 돌리던 프로세스가 죽은 작업 (러너 잠금을 잡았으니 지금 돌리는 곳은 없어)
+func (q *Queue) recoverJobs(ctx context.Context) error {
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.State != Running {
+			continue
+		}
+		lg := q.opts.Logger.With("job", j.ID, "kind", j.Kind, "owner", j.Owner)
+		t := &Task{Job: j, q: q}
+		var err error
+		if h, ok := q.handlers[j.Kind]; ok && h.Recover != nil {
+			err = callErr(func() error { return h.Recover(ctx, t) })
+		}
+		j = t.Job
+		j.Recovered++
+		if err != nil {
+			lg.Error("중단된 작업을 정리하지 못해서 실패로 남김", "err", err)
+			q.finish(j, Failed, "재시작 복구 실패: "+err.Error())
+			continue
+		}
+		lg.Warn("이전 프로세스가 돌리다 멈춘 작업 - 다시 기다리는 상태로")
+		j.State, j.Owner = Queued, ""
+		q.mu.Lock()
+		err = q.write(j)
+		q.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call 작업이 패닉을 내도 러너는 계속 돌게
+func call(fn func() (string, error)) (summary string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = msg.Errorf("패닉: %v", r)
+		}
+	}()
+	return fn()
+}
+
+func callErr(fn func() error) error {
+	_, err := call(func() (string, error) { return "", fn() })
+	return err
+}
+
+// ---------- 러너 잠금 ----------
+
+// 러너 잠금 파일은 돌리는 동안 heartbeatInterval 마다 수정 시각을 갱신해 - lockStale 동안 안 바뀌었으면 주인이 죽은 거야
+// ⭐ 같은 호스트면 pid 가 살아 있는지도 봐서 kill -9 직후에 다시 띄워도 바로 넘겨받아 (다른 호스트는 시각으로만)
+const (
+	heartbeatInterval = 5 * time.Second
+	lockStale         = 30 * time.Second
+)
+
+func (q *Queue) lockPath() string { return filepath.Join(q.dir, "runner.lock") }
+
+func owner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%d@%s", os.Getpid(), host)
+}
+
+// lockRunner 큐 하나는 한 프로세스만 돌려 (같은 작업을 두 번 돌리지 않게)
+func (q *Queue) lockRunner() (unlock func(), err error) {
+	name := q.lockPath()
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%s since=%s\n", owner(), time.Now().Format(time.RFC3339))
+			if err := f.Close(); err != nil {
+				os.Remove(name)
+				return nil, err
+			}
+			return func() { os.Remove(name) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		holder, alive := q.Runner()
+		if alive || attempt > 0 {
+			return nil, msg.Errorf("다른 프로세스가 이 큐를 돌리고 있습니다 (%s)", holder)
+		}
+		q.opts.Logger.Warn("주인이 없는 러너 잠금을 지움", "lock", holder)
+		os.Remove(name)
+	}
+}
+
+// Runner 큐를 돌리고 있는 프로세스 (없으면 "") 와, 그게 아직 살아 있는지
+func (q *Queue) Runner() (holder string, alive bool) {
+	fi, err := os.Stat(q.lockPath())
+	if err != nil {
+		return "", false
+	}
+	data, _ := os.ReadFile(q.lockPath())
+	holder = strings.TrimSpace(string(data))
+	if time.Since(fi.ModTime()) >= lockStale {
+		return holder, false
+	}
+	who, _, _ := strings.Cut(holder, " ")
+	pidText, host, _ := strings.Cut(who, "@")
+	if this, _ := os.Hostname(); host == this {
+		if pid, err := strconv.Atoi(pidText); err == nil {
+			return holder, processAlive(pid)
+		}
+	}
+	return holder, true
+}
+
+func (q *Queue) heartbeat(ctx context.Context) {
+	t := time.NewTicker(heartbeatInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if err := os.Chtimes(q.lockPath(), now, now); err != nil {
+				q.opts.Logger.Warn("러너 잠금 갱신 실패", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package s3 는 S3 호환 저장소(AWS S3, MinIO, Ceph RGW 등) 클라이언트야.
+// SDK 없이 net/http + 서명(SigV4)만으로 필요한 API(업로드, 멀티파트, 목록)를 직접 불러.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Client S3 호환 엔드포인트 하나
+type Client struct {
+	Endpoint    string // 예: https://s3.ap-northeast-2.amazonaws.com, http://localhost:9000 (비우면 AWS 리전 엔드포인트)
+	Region      string // 비우면 us-east-1
+	Credentials Credentials
+	PathStyle   bool         // 버킷을 호스트 이름 대신 경로에 (MinIO 등 대부분의 자체 호스팅은 이쪽)
+	HTTP        *http.Client // nil 이면 http.DefaultClient
+}
+
+// NewClientFromEnv AWS CLI 와 같은 환경 변수(AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION)로 클라이언트 생성
+func NewClientFromEnv(endpoint string) (*Client, error) {
+	c := &Client{
+		Endpoint: endpoint,
+		Region:   os.Getenv("AWS_REGION"),
+		Credentials: Credentials{
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		},
+	}
+	if c.Credentials.AccessKey == "" || c.Credentials.SecretKey == "" {
+		return nil, msg.New("AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY 환경 변수가 필요합니다")
+	}
+	return c, nil
+}
+
+func (c *Client) region() string {
+	if c.Region == "" {
+		return "us-east-1"
+	}
+	return c.Region
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+// objectURL 버킷/키의 URL (query 는 그대로 붙여)
+func (c *Client) objectURL(bucket, key string, query url.Values) (*url.URL, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + c.region() + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, msg.Errorf("잘못된 엔드포인트: %w", err)
+	}
+	if c.PathStyle {
+		u.Path = "/" + bucket + "/" + key
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// Error S3 가 돌려준 XML 에러
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+	RequestID  string `xml:"RequestId"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return msg.Sprintf("S3 요청 실패: HTTP %d", e.StatusCode)
+	}
+	return msg.Sprintf("S3 요청 실패: HTTP %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Retryable 다시 보내면 될 수도 있는 에러 (5xx, 속도 제한, 네트워크)
+// 4xx(권한, 없는 버킷 등)는 몇 번을 보내도 똑같으니 바로 포기해.
+func Retryable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests || e.Code == "SlowDown" || e.Code == "RequestTimeout"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// request 요청 하나 - body 는 다시 읽을 수 있어야 해서 ReadSeeker (재시도는 호출하는 쪽에서)
+// payloadHash 가 비어 있으면 body 를 한 번 읽어서 계산해.
+type request struct {
+	method      string
+	bucket, key string
+	query       url.Values
+	header      http.Header
+	body        io.ReadSeeker
+	size        int64
+	payloadHash string
+}
+
+// do 서명해서 보내고, 2xx 가 아니면 *Error 로 - 성공하면 응답 본문은 호출한 쪽이 닫아
+func (c *Client) do(ctx context.Context, r request) (_ *http.Response, err error) {
+	u, err := c.objectURL(r.bucket, r.key, r.query)
+	if err != nil {
+		return nil, err
+	}
+	ctx, st := streamio.StartStage(ctx, "S3 "+r.method,
+		attribute.String("http.request.method", r.method),
+		attribute.String("s3.bucket", r.bucket), attribute.String("s3.key", r.key))
+	defer func() { st.End(err) }()
+
+	hash := r.payloadHash
+	if hash == "" {
+		hash = emptySHA256
+		if r.body != nil {
+			if hash, err = hashSeeker(r.body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		if _, err := r.body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		body = r.body
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if r.body != nil {
+		req.ContentLength = r.size
+	}
+	for name, vs := range r.header {
+		req.Header[name] = vs
+	}
+	sign(req, c.Credentials, c.region(), hash, time.Now())
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	st.Add(r.size)
+	st.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode),
+		attribute.String("aws.request_id", resp.Header.Get("x-amz-request-id")))
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, parseError(resp.StatusCode, resp.Body)
+	}
+	return resp, nil
+}
+
+// doXML 요청을 보내고 응답 XML 을 v 로 (v 가 nil 이면 본문은 버려)
+// ⭐ CompleteMultipartUpload 는 200 을 주고도 본문에 <Error> 를 담을 수 있어서 본문을 먼저 확인해
+func (c *Client) doXML(ctx context.Context, r request, v any) error {
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data[:min(len(data), 256)], []byte("<Error>")) {
+		return parseError(resp.StatusCode, bytes.NewReader(data))
+	}
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	return xml.Unmarshal(data, v)
+}
+
+func parseError(status int, body io.Reader) error {
+	e := &Error{StatusCode: status}
+	data, _ := io.ReadAll(io.LimitReader(body, 64*1024))
+	xml.Unmarshal(data, e)
+	return e
+}
+
+func hashSeeker(r io.ReadSeeker) (string, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ParseURL s3://bucket/key/path → bucket, key
+func ParseURL(s string) (bucket, key string, err error) {
+	rest, ok := strings.CutPrefix(s, "s3://")
+	if !ok {
+		return "", "", msg.Errorf("s3:// 주소가 아닙니다: %q", s)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", msg.Errorf("버킷 이름이 없습니다: %q", s)
+	}
+	return bucket, key, nil
+}
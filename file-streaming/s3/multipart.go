@@ -0,0 +1,433 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 멀티파트 업로드
+// ⭐ 큰 파일을 파트로 나눠서 동시에 여러 개 올리고, 마지막에 Complete 로 하나의 객체로 합쳐.
+// 파트 하나가 실패하면 그 파트만 다시 보내면 되고, 끝내 실패하면 Abort 로 이미 올라간 파트를 치워 -
+// 안 치우면 객체 목록에는 안 보이면서 저장 용량(=요금)만 차지해.
+
+const (
+	MinPartSize     = 5 << 20 // S3 파트 최소 크기 (마지막 파트만 예외)
+	MaxParts        = 10000   // 업로드 하나의 최대 파트 수
+	DefaultPartSize = 16 << 20
+)
+
+// UploadOptions 업로드 옵션
+type UploadOptions struct {
+	PartSize    int64         // 파트 크기 (0 이면 DefaultPartSize, 파트 수가 MaxParts 를 넘으면 자동으로 키워)
+	Concurrency int           // 동시에 올리는 파트 수 (0 이면 4)
+	Retries     int           // 파트마다 재시도 횟수
+	RetryDelay  time.Duration // 재시도 간격 (회차만큼 곱해서 늘어나)
+	ContentType string
+	Hooks       streamio.Hooks // 진행률은 모든 파트를 합친 바이트
+}
+
+func (o UploadOptions) hooks() streamio.Hooks {
+	if o.Hooks == nil {
+		return streamio.NopHooks{}
+	}
+	return o.Hooks
+}
+
+// partSize 파일 크기에 맞춘 파트 크기 - MaxParts 안에 들어가게 키워
+func (o UploadOptions) partSize(size int64) int64 {
+	ps := o.PartSize
+	if ps <= 0 {
+		ps = DefaultPartSize
+	}
+	ps = max(ps, MinPartSize)
+	if need := (size + MaxParts - 1) / MaxParts; need > ps {
+		ps = need
+	}
+	return ps
+}
+
+// UploadResult 업로드 결과
+type UploadResult struct {
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	ETag     string `json:"etag"`
+	Size     int64  `json:"size"`
+	Parts    int    `json:"parts"` // 0 이면 PutObject 한 번으로 올림
+	UploadID string `json:"upload_id,omitempty"`
+}
+
+// UploadFile 로컬 파일을 bucket/key 로 - 파트 하나보다 작으면 PutObject 한 번으로 끝내
+func (c *Client) UploadFile(ctx context.Context, bucket, key, name string, opts UploadOptions) (_ *UploadResult, err error) {
+	ctx, st := streamio.StartStage(ctx, "s3.UploadFile", attribute.String("s3.bucket", bucket), attribute.String("transfer.src", name))
+	defer func() { st.End(err) }()
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if key == "" || key[len(key)-1] == '/' {
+		key = path.Join(key, fi.Name())
+	}
+
+	hooks := opts.hooks()
+	info := streamio.TransferInfo{ID: key, Src: name, Dst: "s3://" + bucket + "/" + key, Size: fi.Size()}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	ps := opts.partSize(fi.Size())
+	st.Add(fi.Size())
+	st.SetAttributes(attribute.String("s3.key", key), attribute.Int64("s3.part_size", ps))
+
+	var res *UploadResult
+	if fi.Size() <= ps {
+		res, err = c.putFile(ctx, bucket, key, file, fi.Size(), info, opts)
+	} else {
+		res, err = c.uploadParts(ctx, bucket, key, file, fi.Size(), ps, info, opts)
+	}
+	if err != nil {
+		hooks.OnError(info, err)
+		return nil, err
+	}
+	hooks.OnComplete(info, fi.Size(), time.Since(start))
+	return res, nil
+}
+
+func (c *Client) putFile(ctx context.Context, bucket, key string, file *os.File, size int64, info streamio.TransferInfo, opts UploadOptions) (*UploadResult, error) {
+	hooks := opts.hooks()
+	hash, err := hashSeeker(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	var etag string
+	err = retry(ctx, opts, func(attempt int, err error) {
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt), attribute.String("error", err.Error())))
+		hooks.OnRetry(info, attempt, err)
+	}, func() error {
+		body := newProgressSection(file, 0, size, func(n int64) { hooks.OnProgress(info, n) })
+		resp, err := c.do(ctx, request{method: http.MethodPut, bucket: bucket, key: key, header: contentType(opts), body: body, size: size, payloadHash: hash})
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		etag = resp.Header.Get("ETag")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &UploadResult{Bucket: bucket, Key: key, ETag: etag, Size: size}, nil
+}
+
+// completedPart Complete 요청에 들어가는 파트 하나
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadParts Create → 파트들을 동시에 → Complete, 중간에 실패하면 Abort
+func (c *Client) uploadParts(ctx context.Context, bucket, key string, file *os.File, size, partSize int64, info streamio.TransferInfo, opts UploadOptions) (res *UploadResult, err error) {
+	uploadID, err := c.createMultipartUpload(ctx, bucket, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			// ⭐ ctx 가 취소돼서 실패했을 수도 있으니 Abort 는 새 컨텍스트로
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			if abortErr := c.AbortMultipartUpload(abortCtx, bucket, key, uploadID); abortErr != nil {
+				err = msg.Errorf("%w (업로드 %s 정리도 실패: %v)", err, uploadID, abortErr)
+			}
+		}
+	}()
+
+	count := int((size + partSize - 1) / partSize)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	progress := newPartProgress(count, info, opts.hooks())
+	parts := make([]completedPart, count)
+	numbers := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, count) {
+		wg.Go(func() {
+			for n := range numbers {
+				off := int64(n-1) * partSize
+				etag, err := c.uploadPart(ctx, bucket, key, uploadID, n, file, off, min(partSize, size-off), progress, opts)
+				if err != nil {
+					cancel(msg.Errorf("파트 %d/%d: %w", n, count, err))
+					return
+				}
+				parts[n-1] = completedPart{PartNumber: n, ETag: etag}
+			}
+		})
+	}
+feed:
+	for n := 1; n <= count; n++ {
+		select {
+		case numbers <- n:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(numbers)
+	wg.Wait()
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	etag, err := c.completeMultipartUpload(ctx, bucket, key, uploadID, parts)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadResult{Bucket: bucket, Key: key, ETag: etag, Size: size, Parts: count, UploadID: uploadID}, nil
+}
+
+// uploadPart 파트 하나 - 본문은 파일의 구간이라 재시도할 때 다시 처음부터 읽을 수 있어
+func (c *Client) uploadPart(ctx context.Context, bucket, key, uploadID string, n int, file *os.File, off, length int64, progress *partProgress, opts UploadOptions) (etag string, err error) {
+	ctx, st := streamio.StartStage(ctx, "s3.UploadPart", attribute.Int("s3.part", n), attribute.Int64("s3.part_offset", off))
+	defer func() { st.End(err) }()
+	st.Add(length)
+
+	// ⭐ 서명에 본문 SHA-256 이 들어가서 보내기 전에 구간을 한 번 읽어 (재시도해도 내용은 같으니 한 번만)
+	_, hashing := streamio.StartStage(ctx, "sha256")
+	hash, err := hashSeeker(io.NewSectionReader(file, off, length))
+	hashing.Add(length)
+	hashing.End(err)
+	if err != nil {
+		return "", err
+	}
+	err = retry(ctx, opts, func(attempt int, err error) {
+		st.Event("retry", attribute.Int("attempt", attempt), attribute.String("error", err.Error()))
+		progress.retry(n, attempt, msg.Errorf("파트 %d: %w", n, err))
+	}, func() error {
+		body := newProgressSection(file, off, length, func(sent int64) { progress.set(n, sent) })
+		q := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {uploadID}}
+		resp, err := c.do(ctx, request{method: http.MethodPut, bucket: bucket, key: key, query: q, body: body, size: length, payloadHash: hash})
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if etag = resp.Header.Get("ETag"); etag == "" {
+			return msg.New("응답에 ETag 가 없습니다")
+		}
+		return nil
+	})
+	return etag, err
+}
+
+// retry 재시도할 만한 에러면 opts.Retries 번까지 다시
+func retry(ctx context.Context, opts UploadOptions, onRetry func(int, error), fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= opts.Retries || ctx.Err() != nil || !Retryable(err) {
+			return err
+		}
+		onRetry(attempt+1, err)
+		select {
+		case <-time.After(opts.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func contentType(opts UploadOptions) http.Header {
+	h := http.Header{}
+	if opts.ContentType != "" {
+		h.Set("Content-Type", opts.ContentType)
+	}
+	return h
+}
+
+func (c *Client) createMultipartUpload(ctx context.Context, bucket, key string, opts UploadOptions) (string, error) {
+	var out struct {
+		UploadID string `xml:"UploadId"`
+	}
+	r := request{method: http.MethodPost, bucket: bucket, key: key, query: url.Values{"uploads": {""}}, header: contentType(opts)}
+	if err := c.doXML(ctx, r, &out); err != nil {
+		return "", err
+	}
+	if out.UploadID == "" {
+		return "", msg.New("응답에 UploadId 가 없습니다")
+	}
+	return out.UploadID, nil
+}
+
+func (c *Client) completeMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []completedPart) (string, error) {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		ETag string `xml:"ETag"`
+	}
+	r := request{method: http.MethodPost, bucket: bucket, key: key, query: url.Values{"uploadId": {uploadID}}, body: bytes.NewReader(body), size: int64(len(body))}
+	if err := c.doXML(ctx, r, &out); err != nil {
+		return "", err
+	}
+	return out.ETag, nil
+}
+
+// AbortMultipartUpload 끝나지 않은 업로드와 이미 올라간 파트를 삭제
+func (c *Client) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	resp, err := c.do(ctx, request{method: http.MethodDelete, bucket: bucket, key: key, query: url.Values{"uploadId": {uploadID}}})
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.Code == "NoSuchUpload" {
+			return nil // 이미 끝났거나 치워짐
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Upload 끝나지 않은 멀티파트 업로드 하나
+type Upload struct {
+	Key       string    `xml:"Key" json:"key"`
+	UploadID  string    `xml:"UploadId" json:"upload_id"`
+	Initiated time.Time `xml:"Initiated" json:"initiated"`
+}
+
+// ListMultipartUploads prefix 아래의 끝나지 않은 업로드 (여러 페이지면 끝까지 따라가)
+func (c *Client) ListMultipartUploads(ctx context.Context, bucket, prefix string) ([]Upload, error) {
+	var all []Upload
+	keyMarker, idMarker := "", ""
+	for {
+		q := url.Values{"uploads": {""}}
+		if prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if keyMarker != "" {
+			q.Set("key-marker", keyMarker)
+			q.Set("upload-id-marker", idMarker)
+		}
+		var out struct {
+			Uploads            []Upload `xml:"Upload"`
+			IsTruncated        bool     `xml:"IsTruncated"`
+			NextKeyMarker      string   `xml:"NextKeyMarker"`
+			NextUploadIDMarker string   `xml:"NextUploadIdMarker"`
+		}
+		if err := c.doXML(ctx, request{method: http.MethodGet, bucket: bucket, query: q}, &out); err != nil {
+			return nil, err
+		}
+		all = append(all, out.Uploads...)
+		if !out.IsTruncated || out.NextKeyMarker == "" {
+			break
+		}
+		keyMarker, idMarker = out.NextKeyMarker, out.NextUploadIDMarker
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Initiated.Before(all[j].Initiated) })
+	return all, nil
+}
+
+// AbortIncomplete olderThan 보다 오래된 미완료 업로드를 정리 (0 이면 전부) - 정리한 목록을 돌려줘
+// ⭐ 프로세스가 죽어서 Abort 도 못 한 업로드가 남아 있을 수 있어서, 주기적으로 돌리는 용도
+func (c *Client) AbortIncomplete(ctx context.Context, bucket, prefix string, olderThan time.Duration) ([]Upload, error) {
+	uploads, err := c.ListMultipartUploads(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var aborted []Upload
+	cutoff := time.Now().Add(-olderThan)
+	for _, u := range uploads {
+		if olderThan > 0 && u.Initiated.After(cutoff) {
+			continue
+		}
+		if err := c.AbortMultipartUpload(ctx, bucket, u.Key, u.UploadID); err != nil {
+			return aborted, fmt.Errorf("%s (%s): %w", u.Key, u.UploadID, err)
+		}
+		aborted = append(aborted, u)
+	}
+	return aborted, nil
+}
+
+// partProgress 파트별 보낸 바이트를 합쳐서 하나의 진행률로
+// ⭐ 파트가 재시도하면 그 파트가 보낸 만큼 합계에서 빼 (처음부터 다시 보내니까)
+type partProgress struct {
+	mu    sync.Mutex
+	sent  []int64
+	total int64
+	info  streamio.TransferInfo
+	hooks streamio.Hooks
+}
+
+func newPartProgress(count int, info streamio.TransferInfo, hooks streamio.Hooks) *partProgress {
+	return &partProgress{sent: make([]int64, count), info: info, hooks: hooks}
+}
+
+func (p *partProgress) set(n int, sent int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += sent - p.sent[n-1]
+	p.sent[n-1] = sent
+	p.hooks.OnProgress(p.info, p.total)
+}
+
+// retry OnRetry 를 받은 훅(ProgressHooks 등)은 카운트를 0 으로 돌리니까, 바로 남은 합계를 다시 알려
+func (p *partProgress) retry(n, attempt int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total -= p.sent[n-1]
+	p.sent[n-1] = 0
+	p.hooks.OnRetry(p.info, attempt, err)
+	p.hooks.OnProgress(p.info, p.total)
+}
+
+// progressSection 파일 구간을 읽으면서 진행률 콜백 - Seek 로 처음부터 다시 읽을 수 있어
+type progressSection struct {
+	*io.SectionReader
+	read       int64
+	onProgress func(int64)
+}
+
+func newProgressSection(r io.ReaderAt, off, n int64, onProgress func(int64)) *progressSection {
+	return &progressSection{SectionReader: io.NewSectionReader(r, off, n), onProgress: onProgress}
+}
+
+func (p *progressSection) Read(b []byte) (int, error) {
+	n, err := p.SectionReader.Read(b)
+	p.read += int64(n)
+	p.onProgress(p.read)
+	return n, err
+}
+
+// Seek 다시 보낼 때 처음으로 되감으면 카운트도 같이
+func (p *progressSection) Seek(offset int64, whence int) (int64, error) {
+	pos, err := p.SectionReader.Seek(offset, whence)
+	if err == nil {
+		p.read = pos
+		p.onProgress(pos)
+	}
+	return pos, err
+}
@@ -0,0 +1,211 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// fakeMultipart 멀티파트 API 만 흉내 내는 S3 (서명은 확인 안 해)
+type fakeMultipart struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]*fakeUpload
+	next    int
+
+	failOnce map[int]bool // 이 파트는 처음 한 번 500
+	deny     map[int]bool // 이 파트는 계속 403 (재시도해도 소용없는 에러)
+}
+
+type fakeUpload struct {
+	key       string
+	parts     map[int][]byte
+	initiated time.Time
+}
+
+func newFakeMultipart() *fakeMultipart {
+	return &fakeMultipart{objects: map[string][]byte{}, uploads: map[string]*fakeUpload{}, failOnce: map[int]bool{}, deny: map[int]bool{}}
+}
+
+func (f *fakeMultipart) start(key string, initiated time.Time) string {
+	f.next++
+	id := strconv.Itoa(f.next)
+	f.uploads[id] = &fakeUpload{key: key, parts: map[int][]byte{}, initiated: initiated}
+	return id
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>fake</Message></Error>", code)
+}
+
+func (f *fakeMultipart) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	q := r.URL.Query()
+	id := q.Get("uploadId")
+
+	switch {
+	case r.Method == http.MethodGet && q.Has("uploads"):
+		var out struct {
+			XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+			Uploads []Upload `xml:"Upload"`
+		}
+		for id, u := range f.uploads {
+			out.Uploads = append(out.Uploads, Upload{Key: u.key, UploadID: id, Initiated: u.initiated})
+		}
+		xml.NewEncoder(w).Encode(out)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", f.start(key, time.Now()))
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		u, ok := f.uploads[id]
+		switch {
+		case !ok:
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+		case f.deny[n]:
+			s3Error(w, http.StatusForbidden, "AccessDenied")
+		case f.failOnce[n]:
+			delete(f.failOnce, n)
+			s3Error(w, http.StatusInternalServerError, "InternalError")
+		default:
+			u.parts[n] = body
+			w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, n))
+		}
+	case r.Method == http.MethodPost && id != "":
+		u, ok := f.uploads[id]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var req struct {
+			Parts []completedPart `xml:"Part"`
+		}
+		xml.Unmarshal(body, &req)
+		var data []byte
+		for i, p := range req.Parts {
+			// 파트는 번호 순서대로, 올릴 때 받은 ETag 로 와야 해
+			if p.PartNumber != i+1 || p.ETag != fmt.Sprintf(`"etag-%d"`, p.PartNumber) {
+				s3Error(w, http.StatusBadRequest, "InvalidPartOrder")
+				return
+			}
+			data = append(data, u.parts[p.PartNumber]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, id)
+		io.WriteString(w, `<CompleteMultipartUploadResult><ETag>"done"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodDelete && id != "":
+		if _, ok := f.uploads[id]; !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		delete(f.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		w.Header().Set("ETag", `"single"`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func fakeClient(t *testing.T, f *fakeMultipart) *Client {
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return &Client{Endpoint: srv.URL, PathStyle: true, Credentials: Credentials{AccessKey: "test", SecretKey: "test"}}
+}
+
+// retryHooks 재시도 횟수만 세는 훅
+type retryHooks struct {
+	streamio.NopHooks
+	mu      sync.Mutex
+	retries int
+}
+
+func (h *retryHooks) OnRetry(streamio.TransferInfo, int, error) {
+	h.mu.Lock()
+	h.retries++
+	h.mu.Unlock()
+}
+
+func TestUploadFileMultipart(t *testing.T) {
+	f := newFakeMultipart()
+	c := fakeClient(t, f)
+	dir := t.TempDir()
+	name := filepath.Join(dir, "big.bin")
+	data := bytes.Repeat([]byte("0123456789abcdef"), (2*MinPartSize+100)/16)
+	os.WriteFile(name, data, 0644)
+
+	f.failOnce[2] = true
+	hooks := &retryHooks{}
+	res, err := c.UploadFile(context.Background(), "bucket", "backups/", name, UploadOptions{PartSize: MinPartSize, Concurrency: 3, Retries: 2, Hooks: hooks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Key != "backups/big.bin" || res.Parts != 3 || res.Size != int64(len(data)) {
+		t.Errorf("UploadFile = %+v", res)
+	}
+	if !bytes.Equal(f.objects["backups/big.bin"], data) {
+		t.Error("합친 객체가 원본과 다름")
+	}
+	if hooks.retries != 1 {
+		t.Errorf("재시도 %d 번, want 1", hooks.retries)
+	}
+
+	// 파트 하나에 들어가면 PutObject 한 번
+	small := filepath.Join(dir, "small.txt")
+	os.WriteFile(small, []byte("small"), 0644)
+	if res, err := c.UploadFile(context.Background(), "bucket", "small.txt", small, UploadOptions{}); err != nil || res.Parts != 0 || string(f.objects["small.txt"]) != "small" {
+		t.Errorf("작은 파일 = %+v, %v", res, err)
+	}
+}
+
+// 파트가 끝내 실패하면 업로드를 Abort 해서 파트가 남지 않아
+func TestUploadFileAbort(t *testing.T) {
+	f := newFakeMultipart()
+	c := fakeClient(t, f)
+	name := filepath.Join(t.TempDir(), "big.bin")
+	os.WriteFile(name, make([]byte, MinPartSize+1), 0644)
+
+	f.deny[2] = true
+	if _, err := c.UploadFile(context.Background(), "bucket", "big.bin", name, UploadOptions{PartSize: MinPartSize, Retries: 3}); err == nil || Retryable(err) {
+		t.Fatalf("UploadFile = %v, want 재시도 불가 에러", err)
+	}
+	if len(f.uploads) != 0 || len(f.objects) != 0 {
+		t.Errorf("실패 뒤 남은 업로드 %d 개, 객체 %d 개", len(f.uploads), len(f.objects))
+	}
+}
+
+func TestAbortIncomplete(t *testing.T) {
+	f := newFakeMultipart()
+	c := fakeClient(t, f)
+	f.start("old.bin", time.Now().Add(-48*time.Hour))
+	keep := f.start("new.bin", time.Now())
+
+	aborted, err := c.AbortIncomplete(context.Background(), "bucket", "", 24*time.Hour)
+	if err != nil || len(aborted) != 1 || aborted[0].Key != "old.bin" {
+		t.Fatalf("AbortIncomplete = %+v, %v", aborted, err)
+	}
+	if _, ok := f.uploads[keep]; !ok || len(f.uploads) != 1 {
+		t.Errorf("남은 업로드 = %v", f.uploads)
+	}
+	// 이미 치워진 업로드를 다시 Abort 해도 에러가 아니야
+	if err := c.AbortMultipartUpload(context.Background(), "bucket", "old.bin", aborted[0].UploadID); err != nil {
+		t.Errorf("두 번째 Abort = %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 객체 하나를 읽고 쓰기 (storage.S3 가 이걸로 Storage 를 만들어)
+// ⭐ 본문이 오가는 GET/PUT 은 presigned URL 로 보내서 본문을 서명용으로 미리 읽지 않아 - 받는 쪽은 응답을 그대로 흘려 읽고,
+// 올리는 쪽은 파트 크기만큼만 메모리에 모아서 보내. 목록/HEAD/삭제/멀티파트 시작·완료 같은 짧은 요청은 평소처럼 헤더 서명.
+
+// ObjectInfo 객체 하나의 정보 (HEAD, 목록)
+type ObjectInfo struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+}
+
+// NotFound 없는 객체(키)라는 에러인지
+func NotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && (e.StatusCode == http.StatusNotFound || e.Code == "NoSuchKey")
+}
+
+// HeadObject 객체 크기와 수정 시각 (없으면 NotFound 인 *Error)
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	resp, err := c.do(ctx, request{method: http.MethodHead, bucket: bucket, key: key})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	resp.Body.Close()
+	info := ObjectInfo{Key: key, Size: resp.ContentLength, ETag: resp.Header.Get("ETag")}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return info, nil
+}
+
+// ListObjects prefix 아래 객체들 (ListObjectsV2, 여러 페이지면 끝까지 따라가)
+// delimiter 를 주면("/") 그 아래로 더 들어가지 않고, 중간 경로는 prefixes 로 따로 돌려줘 (디렉토리처럼).
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix, delimiter string) (objects []ObjectInfo, prefixes []string, err error) {
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if delimiter != "" {
+			q.Set("delimiter", delimiter)
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		var out struct {
+			Contents       []ObjectInfo `xml:"Contents"`
+			CommonPrefixes []struct {
+				Prefix string `xml:"Prefix"`
+			} `xml:"CommonPrefixes"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := c.doXML(ctx, request{method: http.MethodGet, bucket: bucket, query: q}, &out); err != nil {
+			return nil, nil, err
+		}
+		objects = append(objects, out.Contents...)
+		for _, p := range out.CommonPrefixes {
+			prefixes = append(prefixes, p.Prefix)
+		}
+		if !out.IsTruncated || out.NextContinuationToken == "" {
+			return objects, prefixes, nil
+		}
+		token = out.NextContinuationToken
+	}
+}
+
+// DeleteObject 객체 삭제 (S3 는 없는 키를 지워도 성공이야)
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	resp, err := c.do(ctx, request{method: http.MethodDelete, bucket: bucket, key: key})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ObjectReader 객체를 presigned GET 으로 흘려 읽어 - Seek 하면 다음 Read 에서 그 위치부터 Range 로 다시 받아
+// (http.ServeContent 의 Range 응답이나 이어받기가 객체 전체를 받지 않고 필요한 구간만 가져와)
+type ObjectReader struct {
+	c           *Client
+	ctx         context.Context
+	bucket, key string
+	info        ObjectInfo
+
+	pos  int64
+	body io.ReadCloser // 지금 위치부터 받는 중인 응답 (nil 이면 다음 Read 에서 요청)
+}
+
+// OpenObject 객체를 읽기용으로 - HEAD 로 크기를 먼저 알아 둬 (없으면 NotFound 인 *Error)
+func (c *Client) OpenObject(ctx context.Context, bucket, key string) (*ObjectReader, error) {
+	info, err := c.HeadObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectReader{c: c, ctx: ctx, bucket: bucket, key: key, info: info}, nil
+}
+
+// Info 열 때 본 객체 정보
+func (o *ObjectReader) Info() ObjectInfo { return o.info }
+
+func (o *ObjectReader) Read(p []byte) (int, error) {
+	if o.pos >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		// presigned URL 은 열 때마다 새로 만들어 - 오래 걸린 다운로드 끝에 Seek 해도 만료된 URL 을 쓰지 않게
+		header := http.Header{}
+		if o.pos > 0 {
+			header.Set("Range", "bytes="+strconv.FormatInt(o.pos, 10)+"-")
+		}
+		resp, err := o.c.presigned(o.ctx, http.MethodGet, o.bucket, o.key, nil, header, nil)
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.pos += int64(n)
+	if err == io.EOF && o.pos < o.info.Size {
+		err = io.ErrUnexpectedEOF // 크기만큼 다 오기 전에 끊겼어
+	}
+	return n, err
+}
+
+func (o *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case io.SeekCurrent:
+		pos += o.pos
+	case io.SeekEnd:
+		pos += o.info.Size
+	}
+	if pos < 0 {
+		return 0, msg.New("음수 위치로 이동할 수 없습니다")
+	}
+	if pos != o.pos && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.pos = pos
+	return pos, nil
+}
+
+func (o *ObjectReader) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+// ObjectWriter 쓰는 대로 객체로 올려 - 파트 크기만큼 모이면 멀티파트의 파트 하나로 보내고, Close 에서 마무리
+// 전체가 파트 하나보다 작으면 멀티파트 없이 PUT 한 번이야. 메모리는 파트 하나만큼만 써.
+// Close 전에는 객체가 안 보이고(멀티파트는 Complete 해야 나타나), 실패하면 Abort 로 올린 파트를 치워.
+type ObjectWriter struct {
+	c           *Client
+	ctx         context.Context
+	bucket, key string
+	opts        UploadOptions
+
+	buf      bytes.Buffer
+	partSize int64
+	uploadID string // 첫 파트를 보낼 때 시작한 멀티파트 (없으면 아직 PUT 한 번이면 돼)
+	parts    []completedPart
+	err      error // 한 번 실패하면 그 뒤로는 계속 이 에러
+}
+
+// NewWriter bucket/key 로 올리는 Writer (크기를 몰라도 돼 - 파트 크기는 opts.PartSize, 파트 수 한도 때문에 최대 크기는 파트 크기 × MaxParts)
+func (c *Client) NewWriter(ctx context.Context, bucket, key string, opts UploadOptions) *ObjectWriter {
+	return &ObjectWriter{c: c, ctx: ctx, bucket: bucket, key: key, opts: opts, partSize: opts.partSize(0)}
+}
+
+func (w *ObjectWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), int(w.partSize)-w.buf.Len())
+		w.buf.Write(p[:n])
+		p, written = p[n:], written+n
+		if int64(w.buf.Len()) == w.partSize {
+			if w.err = w.flushPart(); w.err != nil {
+				return written, w.err
+			}
+		}
+	}
+	return written, nil
+}
+
+// flushPart 모인 버퍼를 파트 하나로 (처음이면 멀티파트 시작)
+func (w *ObjectWriter) flushPart() error {
+	if w.uploadID == "" {
+		id, err := w.c.createMultipartUpload(w.ctx, w.bucket, w.key, w.opts)
+		if err != nil {
+			return err
+		}
+		w.uploadID = id
+	}
+	n := len(w.parts) + 1
+	if n > MaxParts {
+		return msg.Errorf("파트 수 한도(%d)를 넘었습니다 - 파트 크기를 키우세요", MaxParts)
+	}
+	q := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {w.uploadID}}
+	etag, err := w.put(q, w.buf.Bytes())
+	if err != nil {
+		return msg.Errorf("파트 %d: %w", n, err)
+	}
+	w.parts = append(w.parts, completedPart{PartNumber: n, ETag: etag})
+	w.buf.Reset()
+	return nil
+}
+
+// put presigned PUT 하나 (재시도할 만한 에러면 opts.Retries 번까지)
+func (w *ObjectWriter) put(query url.Values, body []byte) (etag string, err error) {
+	err = retry(w.ctx, w.opts, func(int, error) {}, func() error {
+		resp, err := w.c.presigned(w.ctx, http.MethodPut, w.bucket, w.key, query, contentType(w.opts), body)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		etag = resp.Header.Get("ETag")
+		return nil
+	})
+	return etag, err
+}
+
+// Close 남은 버퍼를 보내고 객체로 완성
+func (w *ObjectWriter) Close() error {
+	if w.err != nil {
+		w.Abort()
+		return w.err
+	}
+	if w.uploadID == "" {
+		_, w.err = w.put(nil, w.buf.Bytes())
+		w.buf.Reset()
+		return w.err
+	}
+	if w.buf.Len() > 0 {
+		if w.err = w.flushPart(); w.err != nil {
+			w.Abort()
+			return w.err
+		}
+	}
+	if _, w.err = w.c.completeMultipartUpload(w.ctx, w.bucket, w.key, w.uploadID, w.parts); w.err != nil {
+		w.Abort()
+		return w.err
+	}
+	w.uploadID = ""
+	w.err = errWriterClosed
+	return nil
+}
+
+// Abort 올리던 것을 버려 (멀티파트를 시작했으면 이미 올린 파트까지)
+func (w *ObjectWriter) Abort() error {
+	if w.err == nil {
+		w.err = errWriterClosed
+	}
+	w.buf.Reset()
+	if w.uploadID == "" {
+		return nil
+	}
+	// 요청이 취소돼서 Abort 하는 경우가 많아 - 치우는 건 취소와 상관없이
+	err := w.c.AbortMultipartUpload(context.WithoutCancel(w.ctx), w.bucket, w.key, w.uploadID)
+	w.uploadID = ""
+	return err
+}
+
+var errWriterClosed = fmt.Errorf("s3: %w", io.ErrClosedPipe)
+
+// bytesBody nil 이면 본문 없이 (typed nil 이 http 요청에 들어가지 않게)
+func bytesBody(b []byte) io.Reader {
+	if b == nil {
+		return nil
+	}
+	return bytes.NewReader(b)
+}
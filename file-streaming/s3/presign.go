@@ -0,0 +1,55 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultPresignExpiry Presign 의 expires 를 안 줬을 때
+const DefaultPresignExpiry = 15 * time.Minute
+
+// maxPresignExpiry SigV4 가 허락하는 최대 유효 기간 (7일)
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// Presign bucket/key 에 method 요청을 보낼 수 있는 서명된 URL (expires 가 0 이면 DefaultPresignExpiry)
+// 브라우저나 다른 프로세스에 넘겨주면 자격 증명 없이 그 객체만 올리거나 받을 수 있어.
+func (c *Client) Presign(method, bucket, key string, query url.Values, expires time.Duration) (string, error) {
+	if expires <= 0 {
+		expires = DefaultPresignExpiry
+	}
+	expires = min(expires, maxPresignExpiry)
+	u, err := c.objectURL(bucket, key, query)
+	if err != nil {
+		return "", err
+	}
+	return presign(method, u, c.Credentials, c.region(), expires, time.Now()), nil
+}
+
+// presigned 서명된 URL 로 요청을 보내 - 2xx 가 아니면 *Error (성공하면 응답 본문은 호출한 쪽이 닫아)
+func (c *Client) presigned(ctx context.Context, method, bucket, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u, err := c.Presign(method, bucket, key, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytesBody(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = int64(len(body))
+	}
+	for name, vs := range header {
+		req.Header[name] = vs
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, parseError(resp.StatusCode, resp.Body)
+	}
+	return resp, nil
+}
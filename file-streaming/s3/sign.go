@@ -0,0 +1,184 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4
+// ⭐ 요청의 메서드/경로/쿼리/헤더/본문 해시를 정해진 모양(canonical request)으로 늘어놓고,
+// 비밀 키에서 날짜·리전·서비스 순으로 HMAC 을 이어 만든 서명 키로 서명해.
+// 서버도 같은 계산을 해서 맞는지 봐 - 비밀 키 자체는 절대 안 보내.
+
+const (
+	algorithm     = "AWS4-HMAC-SHA256"
+	amzDateFormat = "20060102T150405Z"
+	emptySHA256   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Credentials 접근 키
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // 임시 자격 증명(STS)일 때만
+}
+
+// sign req 에 Authorization 헤더를 붙여 - payloadHash 는 본문의 SHA-256 (hex) 또는 UNSIGNED-PAYLOAD
+func sign(req *http.Request, creds Credentials, region string, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers, signed := canonicalHeaders(req)
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		headers,
+		signed,
+		payloadHash,
+	}, "\n")
+
+	scope := credentialScope(now, region)
+	signature := signString(creds.SecretKey, now, region, stringToSign(amzDate, scope, canonical))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, creds.AccessKey, scope, signed, signature))
+}
+
+func credentialScope(now time.Time, region string) string {
+	return now.Format("20060102") + "/" + region + "/s3/aws4_request"
+}
+
+func stringToSign(amzDate, scope, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+}
+
+// signString 날짜 → 리전 → 서비스 → "aws4_request" 순으로 HMAC 을 이어서 만든 키로 서명
+func signString(secret string, now time.Time, region, s string) string {
+	key := hmacSHA256([]byte("AWS4"+secret), now.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, s))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalHeaders host 와 x-amz-*, content-type/md5, range 를 소문자 이름순으로 (서명한 헤더 목록도 같이)
+func canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{"host": req.Host}
+	if values["host"] == "" {
+		values["host"] = req.URL.Host
+	}
+	for name, vs := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "content-md5" || lower == "range" {
+			values[lower] = strings.TrimSpace(strings.Join(vs, ","))
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// canonicalURI 경로 조각마다 RFC 3986 인코딩 (슬래시는 그대로)
+func canonicalURI(u *url.URL) string {
+	p := u.Path
+	if p == "" {
+		return "/"
+	}
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery 키 이름순, 키와 값 모두 인코딩
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), q[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 영문/숫자/-_.~ 만 그대로 두고 나머지는 %XX (url.QueryEscape 는 공백을 + 로 바꿔서 못 써)
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// presign 쿼리 문자열 서명 (presigned URL) - 서명이 헤더 대신 URL 에 들어가서, 그 URL 만 있으면 누구든 expires 동안 그 요청을 보낼 수 있어
+// ⭐ 본문 해시 자리에 UNSIGNED-PAYLOAD 를 넣으니 본문을 미리 읽어 해시할 필요 없이 흘려보내면 돼. 서명하는 헤더는 host 하나라
+// Range 같은 헤더는 요청마다 자유롭게 붙여.
+func presign(method string, u *url.URL, creds Credentials, region string, expires time.Duration, now time.Time) string {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	scope := credentialScope(now, region)
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", algorithm)
+	q.Set("X-Amz-Credential", creds.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", fmt.Sprint(int64(expires/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if creds.SessionToken != "" {
+		q.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	canonical := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	q.Set("X-Amz-Signature", signString(creds.SecretKey, now, region, stringToSign(amzDate, scope, canonical)))
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(q) // url.Values.Encode 는 공백을 + 로 써서 서명한 모양과 달라져
+	return signed.String()
+}
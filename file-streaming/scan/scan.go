@@ -0,0 +1,126 @@
+// Package scan 은 업로드 내용을 저장하기 전에 검사하는 훅이야 (바이러스 검사기 같은 것).
+// 서버는 파일이 이름으로 보이기 전에 Scanner 를 불러서, 거절(*Rejected)이면 받은 것을 버리고 422 로 응답해.
+//
+//	st := scan.Start(ctx, scanner, "a.zip") // 업로드를 받으면서 Write 로 같이 흘려
+//	io.Copy(io.MultiWriter(tmp, st), body)
+//	if err := st.Close(); err != nil { ... } // 판정
+//
+// ⭐ Command 는 clamdscan 처럼 stdin 으로 내용을 받아 종료 코드로 답하는 프로그램을 붙여 - 0 이면 깨끗, 1 이면 악성, 나머지는 검사 실패.
+// 검사가 실패하면(명령이 없거나 시간 초과) 통과시키지 않아 - 검사하지 못한 파일을 깨끗하다고 볼 수는 없어서.
+package scan
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Scanner 업로드 내용 검사기
+type Scanner interface {
+	// Scan r 을 읽고 판정 - 악성이면 *Rejected, 검사 자체가 실패하면 다른 에러 (name 은 로그/메시지용)
+	Scan(ctx context.Context, name string, r io.Reader) error
+}
+
+// Rejected 검사기가 거절한 내용
+type Rejected struct {
+	Name   string
+	Reason string // 검사기가 남긴 설명 (clamdscan 이면 "stream: Eicar-Signature FOUND")
+}
+
+func (e *Rejected) Error() string {
+	if e.Reason == "" {
+		return msg.Sprintf("%s: 검사에서 거절되었습니다", e.Name)
+	}
+	return msg.Sprintf("%s: 검사에서 거절되었습니다: %s", e.Name, e.Reason)
+}
+
+// IsRejected err 가 (감싼) *Rejected 인지
+func IsRejected(err error) bool {
+	var rej *Rejected
+	return errors.As(err, &rej)
+}
+
+// Nop 아무것도 검사하지 않아 (기본)
+type Nop struct{}
+
+func (Nop) Scan(context.Context, string, io.Reader) error { return nil }
+
+// outputTail 거절 사유로 남길 검사기 출력 크기
+const outputTail = 1 << 10
+
+// Command 외부 검사 명령 - 내용을 stdin 으로 넣고 종료 코드로 판정
+type Command struct {
+	Args     []string      // [clamdscan, --no-summary, -] - 셸을 거치지 않아
+	Timeout  time.Duration // 한 파일 검사 시간 제한 (0 이면 없음)
+	Infected []int         // 악성으로 볼 종료 코드 (비우면 1 - clamscan/clamdscan)
+}
+
+// Scan 명령을 띄워 r 을 stdin 으로 - 0 이면 nil, Infected 코드면 *Rejected (사유는 stdout/stderr 끝부분)
+func (c Command) Scan(ctx context.Context, name string, r io.Reader) error {
+	cmd := streamio.Command{Args: c.Args, Timeout: c.Timeout}
+	out, err := cmd.Transform(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	var tail tailWriter
+	_, err = io.Copy(&tail, out)
+	if err == nil {
+		return nil
+	}
+	infected := c.Infected
+	if len(infected) == 0 {
+		infected = []int{1}
+	}
+	var ce *streamio.CommandError
+	if errors.As(err, &ce) && slices.Contains(infected, ce.ExitCode) {
+		reason := tail.String()
+		if reason == "" {
+			reason = ce.Stderr
+		}
+		return &Rejected{Name: name, Reason: reason}
+	}
+	return msg.Errorf("%s 검사 실패: %w", name, err)
+}
+
+// File path 를 열어서 검사 (다 받아 둔 파일) - sc 가 nil 이나 Nop 이면 열지도 않아
+func File(ctx context.Context, sc Scanner, path, name string) error {
+	if _, nop := sc.(Nop); nop || sc == nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sc.Scan(ctx, name, f)
+}
+
+// tailWriter 마지막 outputTail 바이트만 남겨
+type tailWriter struct{ buf []byte }
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - outputTail; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+// String 여러 줄이면 " | " 로 이어서 한 줄로
+func (t *tailWriter) String() string {
+	var parts []string
+	for line := range strings.Lines(string(t.buf)) {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
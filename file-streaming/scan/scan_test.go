@@ -0,0 +1,120 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClam EVIL 이 들어 있으면 clamdscan 처럼 FOUND 를 찍고 1, 아니면 0
+var fakeClam = Command{Args: []string{"sh", "-c", `if grep -q EVIL; then echo "stream: Evil-Test FOUND"; exit 1; fi`}}
+
+func needSh(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh 가 없음")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	needSh(t)
+	ctx := t.Context()
+	if err := fakeClam.Scan(ctx, "ok.txt", strings.NewReader("평범한 내용\n")); err != nil {
+		t.Errorf("깨끗한 내용: %v", err)
+	}
+
+	err := fakeClam.Scan(ctx, "bad.txt", strings.NewReader("앞부분\nEVIL\n"))
+	if !IsRejected(err) {
+		t.Fatalf("악성 내용: %v, want *Rejected", err)
+	}
+	if rej := err.(*Rejected); rej.Name != "bad.txt" || rej.Reason != "stream: Evil-Test FOUND" {
+		t.Errorf("Rejected = %+v", rej)
+	}
+
+	// 종료 코드 2 (clamdscan 의 "검사 실패") 는 거절이 아니라 에러
+	broken := Command{Args: []string{"sh", "-c", "cat >/dev/null; echo 'connect error' >&2; exit 2"}}
+	if err := broken.Scan(ctx, "x", strings.NewReader("x")); err == nil || IsRejected(err) {
+		t.Errorf("종료 코드 2: %v", err)
+	}
+	// Infected 로 종료 코드를 바꿀 수 있어
+	broken.Infected = []int{2}
+	if err := broken.Scan(ctx, "x", strings.NewReader("x")); !IsRejected(err) || !strings.Contains(err.Error(), "connect error") {
+		t.Errorf("Infected [2]: %v", err)
+	}
+
+	slow := Command{Args: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond}
+	if err := slow.Scan(ctx, "x", strings.NewReader("x")); err == nil || IsRejected(err) {
+		t.Errorf("시간 초과: %v", err)
+	}
+	missing := Command{Args: []string{"no-such-scanner-xyz"}}
+	if err := missing.Scan(ctx, "x", strings.NewReader("x")); err == nil || IsRejected(err) {
+		t.Errorf("없는 명령: %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	needSh(t)
+	big := bytes.Repeat([]byte("0123456789abcdef"), 1<<16) // 1MB - 파이프 버퍼보다 커야 막히는지 보여
+
+	for _, tc := range []struct {
+		name    string
+		data    []byte
+		scanner Scanner
+		reject  bool
+	}{
+		{"깨끗", big, fakeClam, false},
+		{"악성", append(append([]byte{}, big...), "EVIL\n"...), fakeClam, true},
+		// 앞부분만 읽고 끝나는 검사기 - 나머지 Write 가 막히지 않아야 해
+		{"일찍 끝남", big, Command{Args: []string{"sh", "-c", "head -c 10 >/dev/null"}}, false},
+		{"Nop", big, Nop{}, false},
+		{"nil", big, nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := Start(t.Context(), tc.scanner, "a.bin")
+			n, err := io.Copy(st, bytes.NewReader(tc.data))
+			if err != nil || n != int64(len(tc.data)) {
+				t.Fatalf("Write: %d, %v", n, err)
+			}
+			if err := st.Close(); IsRejected(err) != tc.reject || (err != nil && !tc.reject) {
+				t.Errorf("판정 = %v, 거절 %v 이어야 해", err, tc.reject)
+			}
+			st.Abort() // Close 뒤에 불러도 돼
+		})
+	}
+}
+
+func TestStreamAbort(t *testing.T) {
+	needSh(t)
+	st := Start(context.Background(), Command{Args: []string{"sleep", "10"}}, "a.bin")
+	st.Write([]byte("반쯤"))
+	done := make(chan struct{})
+	go func() {
+		st.Abort()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Abort 가 검사기를 멈추지 못함")
+	}
+}
+
+func TestFile(t *testing.T) {
+	needSh(t)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("EVIL"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := File(t.Context(), fakeClam, path, "a.txt"); !IsRejected(err) {
+		t.Errorf("File = %v", err)
+	}
+	if err := File(t.Context(), nil, path, "a.txt"); err != nil {
+		t.Errorf("nil 검사기 = %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// 받으면서 검사하기
+// ⭐ 업로드를 디스크(나 S3)에 쓰는 것과 같은 흐름을 파이프로 검사기에도 넘겨서, 다 받은 뒤 다시 읽지 않아.
+// 파이프라 검사기가 느리면 업로드도 그만큼 기다려 (배압). 검사기가 다 읽기 전에 판정을 끝내면 나머지는 버려 -
+// 판정은 Close 에서 받아.
+
+// errDone 검사기가 먼저 끝났을 때 파이프에 남기는 에러 (Write 가 보고 나머지를 버려)
+var errDone = errors.New("scan: 검사기가 끝났습니다")
+
+// Stream Write 로 받은 내용을 검사기로 흘리는 io.Writer
+type Stream struct {
+	pw     *io.PipeWriter // nil 이면 검사 없음
+	cancel context.CancelFunc
+	done   chan error
+
+	closed  bool
+	verdict error
+}
+
+// Start sc 를 고루틴으로 띄워 - sc 가 nil 이나 Nop 이면 아무것도 안 하는 Stream
+func Start(ctx context.Context, sc Scanner, name string) *Stream {
+	if sc == nil {
+		return &Stream{}
+	}
+	if _, ok := sc.(Nop); ok {
+		return &Stream{}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	st := &Stream{pw: pw, cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		err := sc.Scan(ctx, name, pr)
+		pr.CloseWithError(errDone)
+		st.done <- err
+	}()
+	return st
+}
+
+// Write p 를 검사기로 - 검사기가 먼저 끝났으면 버리고 성공한 척 (업로드는 계속 받아)
+func (st *Stream) Write(p []byte) (int, error) {
+	if st.pw != nil {
+		st.pw.Write(p) // 에러면 errDone 이나 Abort 뒤 - 어느 쪽이든 판정은 Close 몫
+	}
+	return len(p), nil
+}
+
+// Close 내용 끝을 알리고 판정을 기다려 (두 번 불러도 같은 판정)
+func (st *Stream) Close() error {
+	return st.finish(nil)
+}
+
+// Abort 업로드가 실패했을 때 - 검사기를 멈추고 기다려 (Close 뒤에 불러도 돼)
+func (st *Stream) Abort() {
+	st.finish(context.Canceled)
+}
+
+func (st *Stream) finish(abort error) error {
+	if st.pw == nil || st.closed {
+		return st.verdict
+	}
+	st.closed = true
+	if abort != nil {
+		st.cancel()
+	}
+	st.pw.CloseWithError(abort) // nil 이면 Close 와 같아 (검사기는 EOF)
+	st.verdict = <-st.done
+	st.cancel()
+	return st.verdict
+}
@@ -0,0 +1,193 @@
+// Package schedule 는 cron 식으로 작업을 주기적으로 돌리는 가벼운 스케줄러야.
+// 밤마다 로그 압축, 주기적인 동기화, 체크섬 검증처럼 오래 켜 두는 streamctl schedule 이 이 위에 올라가.
+//
+// ⭐ 같은 작업이 아직 돌고 있으면 다음 실행은 건너뛰고(겹침 방지), 실행 시각마다 무작위로 조금 늦출 수 있고(jitter),
+// 작업마다 최근 실행 기록과 상태를 JSON 파일로 남겨서 다른 프로세스에서도 볼 수 있어.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Spec 다음 실행 시각 계산
+type Spec interface {
+	// Next after 보다 뒤의 첫 실행 시각 (없으면 zero time)
+	Next(after time.Time) time.Time
+}
+
+// descriptors @daily 같은 줄임말
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse cron 식 (분 시 일 월 요일 다섯 칸), @daily 같은 줄임말, 또는 "@every 10m"
+//
+//	"0 3 * * *"       매일 03:00
+//	"*/15 9-18 * * 1-5" 평일 9~18시 15분마다
+//	"@every 30m"      30분마다 (시작 시각 기준)
+//
+// 시각은 로컬 시간대로 계산해.
+func Parse(spec string) (Spec, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, msg.Errorf("cron %q: 간격은 0 보다 커야 합니다", spec)
+		}
+		return every(d), nil
+	}
+	if full, ok := descriptors[spec]; ok {
+		spec = full
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, msg.Errorf("알 수 없는 cron 줄임말: %q", spec)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, msg.Errorf("cron %q: 분 시 일 월 요일 다섯 칸이어야 합니다", spec)
+	}
+	var c cronSpec
+	var err error
+	for i, f := range []struct {
+		bits     *uint64
+		min, max int
+		names    map[string]int
+	}{
+		{&c.minute, 0, 59, nil},
+		{&c.hour, 0, 23, nil},
+		{&c.dom, 1, 31, nil},
+		{&c.month, 1, 12, monthNames},
+		{&c.dow, 0, 7, dowNames},
+	} {
+		if *f.bits, err = parseField(fields[i], f.min, f.max, f.names); err != nil {
+			return nil, fmt.Errorf("cron %q: %w", spec, err)
+		}
+	}
+	// 요일 7 도 일요일
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domAny = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	c.dowAny = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+	return c, nil
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dowNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// parseField 한 칸 ("*", "*/5", "1-5", "1-10/2", "mon,wed,fri") → 비트 집합
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, msg.Errorf("잘못된 간격: %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loText, hiText, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseValue(loText, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(hiText, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = max // "5/10" 은 5 부터 끝까지 10 간격
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, msg.Errorf("범위를 벗어남: %q (%d~%d)", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, msg.Errorf("숫자가 아닙니다: %q", s)
+	}
+	return v, nil
+}
+
+// cronSpec 칸마다 허용하는 값의 비트 집합
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // "*" 였는지 - 일/요일 둘 다 지정하면 둘 중 하나만 맞아도 돼 (cron 과 같은 규칙)
+}
+
+// maxSearch 이만큼 뒤까지 맞는 시각이 없으면 포기 (2월 30일처럼 영영 안 오는 식)
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Next 분 단위로 올라가면서 맞는 시각 찾기 - 안 맞는 칸은 그 칸 단위로 통째로 건너뛰어
+func (c cronSpec) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc) // Truncate(Hour) 는 UTC 기준이라 +05:30 같은 시간대에서 틀려
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// every 일정 간격 (@every)
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
@@ -0,0 +1,121 @@
+package schedule
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseNext(t *testing.T) {
+	loc := time.FixedZone("KST", 9*60*60)
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		{"0 3 * * *", "2026-10-15 02:59", "2026-10-15 03:00"},
+		{"0 3 * * *", "2026-10-15 03:00", "2026-10-16 03:00"},
+		{"@daily", "2026-12-31 23:59", "2027-01-01 00:00"},
+		{"@hourly", "2026-10-15 10:30", "2026-10-15 11:00"},
+		{"*/15 9-18 * * 1-5", "2026-10-16 18:50", "2026-10-19 09:00"}, // 금요일 저녁 → 월요일 아침
+		{"30 2 * * sun", "2026-10-15 12:00", "2026-10-18 02:30"},
+		{"0 0 * * 7", "2026-10-15 12:00", "2026-10-18 00:00"},    // 7 도 일요일
+		{"0 0 13 * 5", "2026-10-01 00:00", "2026-10-02 00:00"},   // 일/요일 둘 다 주면 둘 중 하나 (금요일)
+		{"0 0 29 feb *", "2026-03-01 00:00", "2028-02-29 00:00"}, // 윤년까지
+		{"5/20 * * * *", "2026-10-15 10:46", "2026-10-15 11:05"}, // 5, 25, 45
+		{"0 12 1,15 jan-mar *", "2026-10-15 00:00", "2027-01-01 12:00"},
+	}
+	for _, tt := range tests {
+		spec, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := spec.Next(at(tt.after)); !got.Equal(at(tt.want)) {
+			t.Errorf("%q after %s = %s, want %s", tt.spec, tt.after, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+
+	if got := (every(90 * time.Second)).Next(at("2026-10-15 10:00")); !got.Equal(at("2026-10-15 10:01").Add(30 * time.Second)) {
+		t.Errorf("@every 90s = %s", got)
+	}
+	if spec, _ := Parse("0 0 30 2 *"); !spec.Next(at("2026-01-01 00:00")).IsZero() {
+		t.Error("2월 30일은 영영 안 와야 해")
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@sometimes", "@every -1m", "@every soon"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) 에러가 나야 함", bad)
+		}
+	}
+}
+
+// 실행이 간격보다 오래 걸리면 겹쳐 돌리지 않고 건너뛰어야 해
+func TestSchedulerOverlap(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "schedule.json")
+	s, err := New(Options{StateFile: state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var active, maxActive atomic.Int32
+	err = s.Add(Job{
+		Name: "slow",
+		Spec: every(20 * time.Millisecond),
+		Run: func(ctx context.Context) (string, error) {
+			n := active.Add(1)
+			defer active.Add(-1)
+			if n > maxActive.Load() {
+				maxActive.Store(n)
+			}
+			time.Sleep(70 * time.Millisecond)
+			return "ok", nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 300*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if maxActive.Load() != 1 {
+		t.Errorf("동시에 %d개가 돌았음", maxActive.Load())
+	}
+
+	st := s.Status()[0]
+	if st.Runs == 0 || st.Skips == 0 || st.Running {
+		t.Errorf("status = runs %d, skips %d, running %v", st.Runs, st.Skips, st.Running)
+	}
+
+	// 상태 파일에서 다시 읽으면 이력이 이어져야 해
+	saved, err := LoadStatus(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Runs != st.Runs || len(saved[0].History) != len(st.History) {
+		t.Errorf("저장된 상태가 다름: %+v", saved)
+	}
+	s2, err := New(Options{StateFile: state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2.Add(Job{Name: "slow", Spec: every(time.Hour), Run: func(context.Context) (string, error) { return "", nil }})
+	if _, err := s2.RunNow(t.Context(), "slow"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s2.Status()[0].Runs; got != st.Runs+1 {
+		t.Errorf("이어 쌓은 runs = %d, want %d", got, st.Runs+1)
+	}
+}
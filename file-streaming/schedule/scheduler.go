@@ -0,0 +1,369 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Job 스케줄러가 돌릴 작업 하나
+type Job struct {
+	Name    string
+	Spec    Spec
+	Jitter  time.Duration // 실행 시각마다 0~Jitter 사이 무작위로 늦춰 (여러 서버가 같은 시각에 몰리지 않게)
+	Timeout time.Duration // 한 번 실행의 최대 시간 (0 이면 제한 없음)
+
+	// Run 작업 본체 - 돌려준 문자열은 실행 기록에 남는 한 줄 요약이야
+	Run func(ctx context.Context) (string, error)
+}
+
+// Run 실행 기록 하나
+type Run struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Skipped  bool          `json:"skipped,omitempty"` // 이전 실행이 아직 안 끝나서 건너뜀
+	Summary  string        `json:"summary,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// OK 실패도 건너뜀도 아닌 실행인지
+func (r Run) OK() bool { return !r.Skipped && r.Error == "" }
+
+// Status 작업 상태
+type Status struct {
+	Name     string    `json:"name"`
+	Next     time.Time `json:"next,omitzero"` // 다음 실행 예정 (스케줄러가 안 돌고 있으면 비어 있어)
+	Running  bool      `json:"running"`
+	Runs     int       `json:"runs"`     // 실제로 돌린 횟수
+	Failures int       `json:"failures"` // 그중 실패
+	Skips    int       `json:"skips"`    // 겹쳐서 건너뛴 횟수
+	History  []Run     `json:"history"`  // 최근 것이 앞
+}
+
+// Last 마지막 기록 (없으면 false)
+func (s Status) Last() (Run, bool) {
+	if len(s.History) == 0 {
+		return Run{}, false
+	}
+	return s.History[0], true
+}
+
+// Options 스케줄러 옵션
+type Options struct {
+	StateFile   string       // 작업 상태/이력을 저장할 JSON 파일 (비우면 메모리에만)
+	HistorySize int          // 작업마다 남길 기록 수 (기본 20)
+	Logger      *slog.Logger // 기본 component=schedule
+
+	// Hooks 실행마다 OnStart, 끝나면 OnComplete/OnError (ID 는 작업 이름) - 알림 같은 걸 붙이는 자리, 건너뛴 실행은 안 불러
+	Hooks streamio.Hooks
+}
+
+// stateFile StateFile 형식
+type stateFile struct {
+	Updated time.Time `json:"updated"`
+	Jobs    []Status  `json:"jobs"`
+}
+
+// Scheduler cron 식대로 작업을 돌리는 스케줄러
+// ⭐ 작업마다 고루틴 하나가 다음 시각까지 자다가 깨서 실행을 띄워 - 실행이 길어져도 다음 시각 계산은 밀리지 않고,
+// 그 시각에 아직 돌고 있으면 겹쳐 돌리지 않고 "건너뜀" 으로 기록해.
+type Scheduler struct {
+	opts Options
+
+	mu      sync.Mutex
+	jobs    []*entry
+	byName  map[string]*entry
+	running sync.WaitGroup
+	saveMu  sync.Mutex // 여러 작업이 동시에 저장해도 늦게 찍은 상태가 이기게
+}
+
+type entry struct {
+	job    Job
+	status Status
+}
+
+// New 스케줄러 생성 - StateFile 이 있으면 지난 이력을 이어서 쌓아
+func New(opts Options) (*Scheduler, error) {
+	if opts.HistorySize <= 0 {
+		opts.HistorySize = 20
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.For("schedule")
+	}
+	if opts.Hooks == nil {
+		opts.Hooks = streamio.NopHooks{}
+	}
+	s := &Scheduler{opts: opts, byName: make(map[string]*entry)}
+	if opts.StateFile != "" {
+		saved, err := LoadStatus(opts.StateFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, st := range saved {
+			st.Running, st.Next = false, time.Time{} // 지난 프로세스 값이라 의미 없어
+			s.byName[st.Name] = &entry{status: st}
+		}
+	}
+	return s, nil
+}
+
+// Add 작업 등록 (Run 전에) - 저장된 이력이 있으면 이어 붙여
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Spec == nil || job.Run == nil {
+		return msg.New("작업에는 이름, 실행 주기, 실행 함수가 필요합니다")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.byName[job.Name]
+	switch {
+	case ok && e.job.Run != nil:
+		return msg.Errorf("이미 있는 작업 이름: %s", job.Name)
+	case ok:
+		e.job = job
+	default:
+		e = &entry{job: job, status: Status{Name: job.Name}}
+		s.byName[job.Name] = e
+	}
+	s.jobs = append(s.jobs, e)
+	return nil
+}
+
+// Run ctx 가 취소될 때까지 작업들을 주기대로 돌려 - 취소되면 돌고 있는 실행이 끝나길 기다렸다가 돌아와
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	jobs := append([]*entry(nil), s.jobs...)
+	s.mu.Unlock()
+	if len(jobs) == 0 {
+		return msg.New("등록된 작업이 없습니다")
+	}
+
+	var loops sync.WaitGroup
+	for _, e := range jobs {
+		loops.Go(func() { s.loop(ctx, e) })
+	}
+	loops.Wait()
+	s.running.Wait()
+	return s.save()
+}
+
+// loop 작업 하나의 타이머
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		next := e.job.Spec.Next(time.Now())
+		if next.IsZero() {
+			s.opts.Logger.Warn("다음 실행 시각이 없어서 작업을 멈춤", "job", e.job.Name)
+			return
+		}
+		s.mu.Lock()
+		e.status.Next = next
+		s.mu.Unlock()
+		s.save()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.fire(ctx, e)
+	}
+}
+
+// fire 실행 시각이 됐을 때 - 아직 돌고 있으면 건너뛰고, 아니면 jitter 만큼 쉬었다가 백그라운드로 실행
+func (s *Scheduler) fire(ctx context.Context, e *entry) {
+	if !s.begin(e) {
+		s.opts.Logger.Warn("이전 실행이 아직 안 끝나서 건너뜀", "job", e.job.Name)
+		s.record(e, Run{Start: time.Now(), Skipped: true})
+		return
+	}
+	s.running.Go(func() {
+		if e.job.Jitter > 0 {
+			delay := rand.N(e.job.Jitter)
+			select {
+			case <-ctx.Done():
+				s.end(e)
+				return
+			case <-time.After(delay):
+			}
+		}
+		s.execute(ctx, e)
+	})
+}
+
+// RunNow 주기와 상관없이 지금 한 번 (jitter 없이, 끝날 때까지 기다려) - 이미 돌고 있으면 건너뜀 기록만 남겨
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Run, error) {
+	s.mu.Lock()
+	e, ok := s.byName[name]
+	s.mu.Unlock()
+	if !ok || e.job.Run == nil {
+		return Run{}, msg.Errorf("없는 작업: %s", name)
+	}
+	if !s.begin(e) {
+		run := Run{Start: time.Now(), Skipped: true}
+		s.record(e, run)
+		return run, msg.Errorf("%s: 이전 실행이 아직 안 끝났습니다", name)
+	}
+	run := s.execute(ctx, e)
+	if run.Error != "" {
+		return run, errors.New(run.Error)
+	}
+	return run, nil
+}
+
+// begin 실행 중 표시 (이미 돌고 있으면 false)
+func (s *Scheduler) begin(e *entry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.status.Running {
+		return false
+	}
+	e.status.Running = true
+	return true
+}
+
+func (s *Scheduler) end(e *entry) {
+	s.mu.Lock()
+	e.status.Running = false
+	s.mu.Unlock()
+}
+
+// execute 작업 한 번 실행하고 기록 (begin 이 끝난 상태에서)
+func (s *Scheduler) execute(ctx context.Context, e *entry) Run {
+	s.save() // 다른 프로세스에서 status 로 "실행 중" 이 보이게
+
+	runCtx := ctx
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+	lg := s.opts.Logger.With("job", e.job.Name)
+	lg.Info("작업 시작")
+	info := streamio.TransferInfo{ID: e.job.Name, Src: "schedule", Size: -1}
+	s.opts.Hooks.OnStart(info)
+
+	run := Run{Start: time.Now()}
+	summary, err := s.call(runCtx, e.job)
+	run.Duration = time.Since(run.Start)
+	run.Summary = summary
+	if err != nil {
+		run.Error = err.Error()
+		lg.Error("작업 실패", "elapsed", run.Duration, "err", err)
+		s.opts.Hooks.OnError(info, err)
+	} else {
+		lg.Info("작업 완료", "elapsed", run.Duration, "summary", summary)
+		s.opts.Hooks.OnComplete(info, 0, run.Duration)
+	}
+
+	s.end(e)
+	s.record(e, run)
+	return run
+}
+
+// call 작업이 패닉을 내도 스케줄러는 계속 돌게
+func (s *Scheduler) call(ctx context.Context, job Job) (summary string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = msg.Errorf("패닉: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// record 이력 맨 앞에 추가하고 저장
+func (s *Scheduler) record(e *entry, run Run) {
+	s.mu.Lock()
+	st := &e.status
+	switch {
+	case run.Skipped:
+		st.Skips++
+	case run.Error != "":
+		st.Runs++
+		st.Failures++
+	default:
+		st.Runs++
+	}
+	st.History = append([]Run{run}, st.History...)
+	if len(st.History) > s.opts.HistorySize {
+		st.History = st.History[:s.opts.HistorySize]
+	}
+	s.mu.Unlock()
+	s.save()
+}
+
+// Status 등록된 작업 상태 (이름순)
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		st := e.status
+		st.History = append([]Run(nil), st.History...)
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// save 상태 파일 쓰기 (임시 파일 → rename 이라 status 로 읽는 쪽이 반쯤 쓴 파일을 보지 않아)
+// 저장 실패는 작업을 멈출 일이 아니라서 로그만 남겨.
+func (s *Scheduler) save() error {
+	if s.opts.StateFile == "" {
+		return nil
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	data, err := json.MarshalIndent(stateFile{Updated: time.Now(), Jobs: s.Status()}, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(s.opts.StateFile, data)
+	}
+	if err != nil {
+		s.opts.Logger.Warn("상태 파일 저장 실패", "file", s.opts.StateFile, "err", err)
+	}
+	return err
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), path)
+}
+
+// LoadStatus 상태 파일 읽기 - 스케줄러를 돌리는 프로세스 밖에서 상태를 볼 때
+func LoadStatus(path string) ([]Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f stateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, msg.Errorf("상태 파일 %s: %w", path, err)
+	}
+	return f.Jobs, nil
+}
@@ -0,0 +1,320 @@
+// Package search 는 텍스트/로그 파일 전문 검색이야.
+// 파일에서 단어를 뽑아 "단어 → 그 단어가 나오는 파일" 역색인을 디스크에 두고,
+// 검색하면 역색인으로 후보 파일을 찾은 뒤 파일을 다시 읽어서 맞는 줄(스니펫)을 보여줘.
+package search
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+const indexVersion = 1
+
+// Doc 색인된 파일 하나
+type Doc struct {
+	ID      int32
+	Path    string // 절대 경로
+	Size    int64
+	ModTime time.Time
+	Terms   int // 단어 수 (점수 계산에서 긴 파일이 무조건 이기지 않게)
+}
+
+// posting 단어 하나가 파일 하나에 나온 횟수
+type posting struct {
+	Doc  int32
+	Freq int32
+}
+
+// Index 역색인 - 여러 고루틴에서 써도 돼
+// ⭐ 파일을 지우거나 다시 색인하면 문서만 바로 빼고, 단어 목록에 남은 옛 ID 는 검색할 때 걸러내.
+// Save 할 때 살아 있는 문서만 남기고 정리(compact)해서 파일에 써.
+type Index struct {
+	path string
+
+	mu       sync.RWMutex
+	docs     map[int32]*Doc
+	byPath   map[string]int32
+	postings map[string][]posting
+	nextID   int32
+	dirty    bool
+	sorted   []string // 앞부분 일치 검색용 정렬된 단어 목록 (새 단어가 생기면 다시 만들어)
+	stale    bool
+}
+
+// indexFile 디스크 형식 (gob)
+type indexFile struct {
+	Version  int
+	NextID   int32
+	Docs     []Doc
+	Postings map[string][]posting
+}
+
+// Open path 의 색인을 읽어 (없으면 빈 색인, Save 할 때 만들어져)
+func Open(path string) (*Index, error) {
+	ix := &Index{path: path, docs: map[int32]*Doc{}, byPath: map[string]int32{}, postings: map[string][]posting{}}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var file indexFile
+	if err := gob.NewDecoder(f).Decode(&file); err != nil {
+		return nil, msg.Errorf("색인 파일을 읽을 수 없습니다 (%s): %w", path, err)
+	}
+	if file.Version != indexVersion {
+		return nil, msg.Errorf("지원하지 않는 색인 버전: %d (지우고 다시 만드세요)", file.Version)
+	}
+	for i := range file.Docs {
+		d := &file.Docs[i]
+		ix.docs[d.ID] = d
+		ix.byPath[d.Path] = d.ID
+	}
+	ix.postings = file.Postings
+	if ix.postings == nil {
+		ix.postings = map[string][]posting{}
+	}
+	ix.nextID = file.NextID
+	ix.stale = true
+	return ix, nil
+}
+
+// Len 색인된 파일 수
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Docs 색인된 파일들 (경로순)
+func (ix *Index) Docs() []Doc {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	docs := make([]Doc, 0, len(ix.docs))
+	for _, d := range ix.docs {
+		docs = append(docs, *d)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Path < docs[j].Path })
+	return docs
+}
+
+// Add 파일을 색인 (이미 있으면 새 내용으로 바꿔) - 텍스트가 아니면 ErrBinary
+// ⭐ 파일을 읽는 동안은 잠금 없이 단어만 세고, 색인에 넣을 때만 잠가서 검색을 오래 막지 않아
+func (ix *Index) Add(ctx context.Context, name string) error {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(abs)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return msg.Errorf("일반 파일이 아닙니다: %s", name)
+	}
+	r, err := openText(abs)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	freq := map[string]int32{}
+	total := 0
+	err = scanLines(r, func(n int, line string) error {
+		if n%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		tokenize(line, func(t string) {
+			freq[t]++
+			total++
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(abs)
+	id := ix.nextID
+	ix.nextID++
+	ix.docs[id] = &Doc{ID: id, Path: abs, Size: fi.Size(), ModTime: fi.ModTime(), Terms: total}
+	ix.byPath[abs] = id
+	for t, n := range freq {
+		if _, ok := ix.postings[t]; !ok {
+			ix.stale = true
+		}
+		ix.postings[t] = append(ix.postings[t], posting{Doc: id, Freq: n})
+	}
+	ix.dirty = true
+	return nil
+}
+
+// Remove 색인에서 빼기 (없으면 false)
+func (ix *Index) Remove(name string) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.removeLocked(abs)
+}
+
+func (ix *Index) removeLocked(abs string) bool {
+	id, ok := ix.byPath[abs]
+	if !ok {
+		return false
+	}
+	delete(ix.docs, id)
+	delete(ix.byPath, abs)
+	ix.dirty = true
+	return true
+}
+
+// Fresh 색인된 내용이 지금 파일과 같은지 (크기와 수정 시각으로 판단)
+func (ix *Index) Fresh(name string, fi fs.FileInfo) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	id, ok := ix.byPath[abs]
+	if !ok {
+		return false
+	}
+	d := ix.docs[id]
+	return d.Size == fi.Size() && d.ModTime.Equal(fi.ModTime())
+}
+
+// UpdateStats Update 결과
+type UpdateStats struct {
+	Added   int     `json:"added"`   // 새로 또는 다시 색인한 파일
+	Fresh   int     `json:"fresh"`   // 바뀌지 않아서 건너뛴 파일
+	Removed int     `json:"removed"` // 없어져서 뺀 파일
+	Skipped int     `json:"skipped"` // 텍스트가 아니라 건너뛴 파일
+	Errors  []error `json:"-"`
+}
+
+// Update root 아래를 돌면서 바뀐 파일만 다시 색인하고, root 아래에서 사라진 파일은 빼
+func (ix *Index) Update(ctx context.Context, root string, walk fstree.WalkOptions) (UpdateStats, error) {
+	var stats UpdateStats
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return stats, err
+	}
+	seen := map[string]bool{}
+	for e := range fstree.Walk(ctx, abs, walk) {
+		if e.Err != nil {
+			stats.Errors = append(stats.Errors, e.Err)
+			continue
+		}
+		if !e.Info.Mode().IsRegular() {
+			continue
+		}
+		seen[e.Path] = true
+		if ix.Fresh(e.Path, e.Info) {
+			stats.Fresh++
+			continue
+		}
+		switch err := ix.Add(ctx, e.Path); {
+		case err == nil:
+			stats.Added++
+		case errors.Is(err, ErrBinary):
+			ix.Remove(e.Path) // 텍스트였다가 바이너리로 바뀐 경우
+			stats.Skipped++
+		default:
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			stats.Errors = append(stats.Errors, err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+
+	prefix := abs + string(filepath.Separator)
+	for _, d := range ix.Docs() {
+		if strings.HasPrefix(d.Path, prefix) && !seen[d.Path] {
+			ix.Remove(d.Path)
+			stats.Removed++
+		}
+	}
+	return stats, nil
+}
+
+// Save 바뀐 게 있으면 디스크에 (임시 파일 + rename)
+func (ix *Index) Save() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.dirty {
+		return nil
+	}
+	ix.compactLocked()
+
+	file := indexFile{Version: indexVersion, NextID: ix.nextID, Postings: ix.postings}
+	for _, d := range ix.docs {
+		file.Docs = append(file.Docs, *d)
+	}
+	if dir := filepath.Dir(ix.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(ix.path), "."+filepath.Base(ix.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(&file); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := streamio.Rename(tmp.Name(), ix.path); err != nil {
+		return err
+	}
+	ix.dirty = false
+	return nil
+}
+
+// compactLocked 지워진 문서의 posting 을 걸러내
+func (ix *Index) compactLocked() {
+	for t, list := range ix.postings {
+		kept := list[:0]
+		for _, p := range list {
+			if _, ok := ix.docs[p.Doc]; ok {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(ix.postings, t)
+			ix.stale = true
+		} else {
+			ix.postings[t] = kept
+		}
+	}
+}
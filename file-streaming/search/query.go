@@ -0,0 +1,212 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+const (
+	maxExpansions = 256 // 앞부분 일치로 늘어나는 단어 수 상한 ("a" 같은 짧은 검색어가 색인 전체를 훑지 않게)
+	snippetRunes  = 160 // 스니펫 한 줄 최대 길이 (글자 수)
+)
+
+// SearchOptions 검색 옵션
+type SearchOptions struct {
+	Limit    int // 최대 결과 수 (0 이면 20)
+	Snippets int // 파일마다 보여줄 줄 수 (0 이면 3, 음수면 스니펫 없음)
+}
+
+// Snippet 검색어가 들어 있는 줄
+type Snippet struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Result 검색 결과 하나
+type Result struct {
+	Path     string    `json:"path"`
+	Score    float64   `json:"score"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mtime"`
+	Snippets []Snippet `json:"snippets,omitempty"`
+}
+
+// Search 모든 검색어가 들어 있는 파일을 점수순으로 (검색어는 공백으로 구분, 단어 앞부분만 맞아도 걸려)
+// ⭐ 점수는 BM25: 드문 단어(idf)일수록, 파일 길이에 비해 자주 나올수록 높아. 정확히 같은 단어는 앞부분 일치보다 두 배
+func (ix *Index) Search(ctx context.Context, q string, opts SearchOptions) ([]Result, error) {
+	var terms []string
+	tokenize(q, func(t string) { terms = append(terms, t) })
+	if len(terms) == 0 {
+		return nil, msg.New("검색어가 비어 있습니다 (영문/숫자 한 글자는 검색하지 않아요)")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	if opts.Snippets == 0 {
+		opts.Snippets = 3
+	}
+
+	sorted := ix.sortedTerms()
+	ix.mu.RLock()
+	scores, docs := ix.score(sorted, terms)
+	ix.mu.RUnlock()
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		d := docs[id]
+		results = append(results, Result{Path: d.Path, Score: math.Round(score*1000) / 1000, Size: d.Size, ModTime: d.ModTime})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	// 스니펫은 파일을 다시 읽어서 - 그새 지워진 파일은 결과에서 빼
+	out := results[:0]
+	for _, r := range results {
+		if len(out) == opts.Limit {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.Snippets > 0 {
+			snippets, err := snippets(r.Path, terms, opts.Snippets)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			r.Snippets = snippets
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// sortedTerms 정렬된 단어 목록 (바뀌었으면 다시 만들어)
+func (ix *Index) sortedTerms() []string {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.stale {
+		ix.sorted = make([]string, 0, len(ix.postings))
+		for t := range ix.postings {
+			ix.sorted = append(ix.sorted, t)
+		}
+		sort.Strings(ix.sorted)
+		ix.stale = false
+	}
+	return ix.sorted
+}
+
+// score 검색어마다 후보 파일 점수를 구해서 모든 검색어에 걸린 파일만 남겨 (읽기 잠금 안에서)
+func (ix *Index) score(sorted, terms []string) (map[int32]float64, map[int32]*Doc) {
+	const k1, b = 1.2, 0.75
+	n := float64(len(ix.docs))
+	var avg float64
+	for _, d := range ix.docs {
+		avg += float64(d.Terms)
+	}
+	if n > 0 {
+		avg /= n
+	}
+	if avg == 0 {
+		avg = 1
+	}
+
+	var total map[int32]float64
+	for _, q := range terms {
+		termScore := map[int32]float64{}
+		i := sort.SearchStrings(sorted, q)
+		for j := i; j < len(sorted) && j-i < maxExpansions && strings.HasPrefix(sorted[j], q); j++ {
+			weight := 0.5
+			if sorted[j] == q {
+				weight = 1
+			}
+			list := ix.postings[sorted[j]]
+			idf := math.Log(1 + (n-float64(len(list))+0.5)/(float64(len(list))+0.5))
+			for _, p := range list {
+				d, ok := ix.docs[p.Doc]
+				if !ok {
+					continue // 지워진 문서 (Save 전까지 남아 있어)
+				}
+				tf := float64(p.Freq)
+				termScore[p.Doc] += weight * idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(d.Terms)/avg))
+			}
+		}
+		if total == nil {
+			total = termScore
+			continue
+		}
+		for id, s := range total {
+			if ts, ok := termScore[id]; ok {
+				total[id] = s + ts
+			} else {
+				delete(total, id)
+			}
+		}
+	}
+	docs := make(map[int32]*Doc, len(total))
+	for id := range total {
+		d := *ix.docs[id]
+		docs[id] = &d
+	}
+	return total, docs
+}
+
+// snippets 검색어가 들어 있는 줄을 max 개까지
+func snippets(name string, terms []string, max int) ([]Snippet, error) {
+	r, err := openText(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var out []Snippet
+	errDone := errors.New("done")
+	err = scanLines(r, func(n int, line string) error {
+		lower := strings.ToLower(line)
+		for _, t := range terms {
+			if at := strings.Index(lower, t); at >= 0 {
+				out = append(out, Snippet{Line: n, Text: around(line, lower, at)})
+				break
+			}
+		}
+		if len(out) >= max {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && err != errDone {
+		return out, err
+	}
+	return out, nil
+}
+
+// around 긴 줄이면 맞은 위치 주변만 잘라
+// ⭐ ToLower 로 바이트 길이가 바뀌는 글자도 있어서, 위치는 글자 수로 세서 원래 줄에 옮겨
+func around(line, lower string, at int) string {
+	if utf8.RuneCountInString(line) <= snippetRunes {
+		return strings.TrimSpace(line)
+	}
+	runes := []rune(line)
+	pos := min(utf8.RuneCountInString(lower[:at]), len(runes))
+	start := max(0, pos-snippetRunes/3)
+	end := min(len(runes), start+snippetRunes)
+	s := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
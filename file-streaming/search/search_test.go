@@ -0,0 +1,176 @@
+package search
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+)
+
+func TestTokenize(t *testing.T) {
+	var got []string
+	tokenize("Hello, 서버에서 a 1 x_y 한 GO-lang", func(term string) { got = append(got, term) })
+	want := []string{"hello", "서버에서", "x_y", "한", "go", "lang"}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokenize = %q, want %q", got, want)
+	}
+}
+
+// 처음엔 다 색인, 그대로면 Fresh, 바꾸면 다시, 지우면 빠져 - 바이너리는 건너뛰어
+func TestUpdate(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "alpha beta")
+	write("b.txt", "gamma")
+	write("bin.dat", "\x00\x01\x02")
+
+	ix, err := Open(filepath.Join(t.TempDir(), "index.gob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := ix.Update(t.Context(), root, fstree.WalkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Added != 2 || stats.Skipped != 1 || stats.Fresh != 0 || stats.Removed != 0 {
+		t.Errorf("처음 Update = %+v", stats)
+	}
+	if ix.Len() != 2 {
+		t.Errorf("Len = %d, want 2", ix.Len())
+	}
+
+	stats, _ = ix.Update(t.Context(), root, fstree.WalkOptions{})
+	if stats.Added != 0 || stats.Fresh != 2 {
+		t.Errorf("두 번째 Update = %+v, 다 Fresh 여야 해", stats)
+	}
+
+	// 크기가 같아도 수정 시각이 바뀌면 다시 색인
+	write("a.txt", "delta beta")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(root, "a.txt"), later, later)
+	os.Remove(filepath.Join(root, "b.txt"))
+	stats, _ = ix.Update(t.Context(), root, fstree.WalkOptions{})
+	if stats.Added != 1 || stats.Removed != 1 {
+		t.Errorf("바꾼 뒤 Update = %+v", stats)
+	}
+	if res, _ := ix.Search(t.Context(), "alpha", SearchOptions{}); len(res) != 0 {
+		t.Errorf("옛 내용이 아직 걸려: %+v", res)
+	}
+	if res, _ := ix.Search(t.Context(), "delta", SearchOptions{}); len(res) != 1 {
+		t.Errorf("새 내용 검색 = %d 개, want 1", len(res))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"many.txt":  "server server server\n다른 줄\nserver log",
+		"once.txt":  "one server here\nand some more words to make it longer",
+		"other.txt": "nothing to see",
+		"ko.txt":    "서버에서 로그를 읽어",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ix, _ := Open(filepath.Join(t.TempDir(), "index.gob"))
+	if _, err := ix.Update(t.Context(), root, fstree.WalkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 자주 나오는 쪽이 먼저, 스니펫은 검색어가 든 줄만
+	res, err := ix.Search(t.Context(), "Server", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || filepath.Base(res[0].Path) != "many.txt" || filepath.Base(res[1].Path) != "once.txt" {
+		t.Fatalf("결과 = %+v", res)
+	}
+	if res[0].Score <= res[1].Score {
+		t.Errorf("점수 = %v, %v - 많이 나온 쪽이 높아야 해", res[0].Score, res[1].Score)
+	}
+	want := []Snippet{{1, "server server server"}, {3, "server log"}}
+	if !slices.Equal(res[0].Snippets, want) {
+		t.Errorf("스니펫 = %+v, want %+v", res[0].Snippets, want)
+	}
+
+	// 모든 검색어가 들어 있어야 하고, 앞부분만 맞아도 걸려
+	if res, _ := ix.Search(t.Context(), "server words", SearchOptions{}); len(res) != 1 || filepath.Base(res[0].Path) != "once.txt" {
+		t.Errorf("두 단어 검색 = %+v", res)
+	}
+	if res, _ := ix.Search(t.Context(), "서버", SearchOptions{Snippets: -1}); len(res) != 1 || res[0].Snippets != nil {
+		t.Errorf("앞부분 일치 검색 = %+v", res)
+	}
+	if res, _ := ix.Search(t.Context(), "server", SearchOptions{Limit: 1}); len(res) != 1 {
+		t.Errorf("Limit 1 인데 %d 개", len(res))
+	}
+	if _, err := ix.Search(t.Context(), "a !", SearchOptions{}); err == nil {
+		t.Error("검색어가 없는데 에러가 없음")
+	}
+
+	// 색인 뒤에 지워진 파일은 결과에서 빠져
+	os.Remove(filepath.Join(root, "once.txt"))
+	if res, _ := ix.Search(t.Context(), "server", SearchOptions{}); len(res) != 1 {
+		t.Errorf("지운 파일이 아직 결과에: %+v", res)
+	}
+}
+
+// gzip 은 풀어서 색인하고, Save 한 색인을 다시 열면 같은 결과
+func TestSaveOpen(t *testing.T) {
+	root := t.TempDir()
+	name := filepath.Join(root, "app.log")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("request failed: timeout\n"))
+	gz.Close()
+	f.Close()
+	os.WriteFile(filepath.Join(root, "gone.txt"), []byte("timeout too"), 0644)
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	ix, _ := Open(path)
+	if _, err := ix.Update(t.Context(), root, fstree.WalkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	ix.Remove(filepath.Join(root, "gone.txt"))
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Len() != 1 {
+		t.Errorf("다시 연 색인 Len = %d, want 1", again.Len())
+	}
+	res, err := again.Search(t.Context(), "timeout", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Path != name || len(res[0].Snippets) != 1 || res[0].Snippets[0].Text != "request failed: timeout" {
+		t.Errorf("다시 연 색인 검색 = %+v", res)
+	}
+	fi, _ := os.Stat(name)
+	if !again.Fresh(name, fi) {
+		t.Error("다시 연 색인에서 Fresh 가 아님")
+	}
+
+	// 깨진 파일은 에러
+	os.WriteFile(path, []byte("garbage"), 0644)
+	if _, err := Open(path); err == nil {
+		t.Error("깨진 색인인데 에러가 없음")
+	}
+}
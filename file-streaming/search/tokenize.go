@@ -0,0 +1,132 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+const (
+	maxTermLen = 64      // 이보다 긴 토큰은 잘라 (해시, base64 덩어리 같은 것)
+	maxLineLen = 1 << 20 // 한 줄 최대 길이 - 넘으면 잘라서 읽어
+)
+
+// ErrBinary 텍스트가 아닌 파일 (색인하지 않아)
+var ErrBinary = msg.New("텍스트 파일이 아닙니다")
+
+// openText 파일을 텍스트로 열기 - gzip 이면 풀면서, 바이너리면 ErrBinary
+// ⭐ 확장자 대신 앞 바이트(매직 넘버)로 판단해서, 이름이 .log 인 gzip 이나 .gz 가 아닌 압축 로그도 처리돼
+func openText(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(f, 64*1024)
+	head, _ := br.Peek(8192)
+
+	var r io.Reader = br
+	closer := io.Closer(f)
+	if len(head) >= 2 && head[0] == 0x1f && head[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		gzbr := bufio.NewReaderSize(gz, 64*1024)
+		head, _ = gzbr.Peek(8192)
+		r = gzbr
+		closer = multiCloser{gz, f}
+	}
+	if bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(trimPartialRune(head)) {
+		closer.Close()
+		return nil, ErrBinary
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, closer}, nil
+}
+
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var first error
+	for _, c := range m {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// trimPartialRune Peek 으로 자른 끝에 걸친 UTF-8 글자 조각은 빼고 검사
+func trimPartialRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		r, size := utf8.DecodeLastRune(b)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// scanLines 줄 단위로 fn 호출 (줄 번호는 1부터) - 너무 긴 줄은 앞부분만
+func scanLines(r io.Reader, fn func(n int, line string) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLineLen)
+	n := 0
+	for sc.Scan() {
+		n++
+		if err := fn(n, sc.Text()); err != nil {
+			return err
+		}
+	}
+	if errors.Is(sc.Err(), bufio.ErrTooLong) {
+		return nil // 나머지는 색인 못 해도 앞부분은 살려
+	}
+	return sc.Err()
+}
+
+// tokenize 글자/숫자가 이어진 덩어리를 소문자로 (한글도 한 덩어리)
+// ⭐ 형태소 분석은 안 해 - "서버에서" 는 그대로 하나의 단어라서, 검색은 앞부분 일치로 "서버" 에도 걸리게 해
+func tokenize(s string, fn func(term string)) {
+	start := -1
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			emit(s[start:i], fn)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		emit(s[start:], fn)
+	}
+}
+
+func emit(tok string, fn func(string)) {
+	if utf8.RuneCountInString(tok) < 2 && tok[0] < utf8.RuneSelf {
+		return // 영문/숫자 한 글자는 너무 흔해서 버려 (한글 한 글자는 의미가 있어서 남겨)
+	}
+	tok = strings.ToLower(tok)
+	if len(tok) > maxTermLen {
+		tok = tok[:maxTermLen]
+		for !utf8.ValidString(tok) {
+			tok = tok[:len(tok)-1]
+		}
+	}
+	fn(tok)
+}
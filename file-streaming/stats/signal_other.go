@@ -0,0 +1,8 @@
+//go:build !unix
+
+package stats
+
+import "os"
+
+// dumpSignals SIGUSR1 이 없어서 비워 둬 - 필요하면 Dump 를 직접 불러
+var dumpSignals []os.Signal
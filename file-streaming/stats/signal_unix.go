@@ -0,0 +1,11 @@
+//go:build unix
+
+package stats
+
+import (
+	"os"
+	"syscall"
+)
+
+// dumpSignals 상태 덤프를 부르는 시그널
+var dumpSignals = []os.Signal{syscall.SIGUSR1}
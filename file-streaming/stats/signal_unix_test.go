@@ -0,0 +1,48 @@
+//go:build unix
+
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	c := New("test")
+	c.OnStart(streamio.TransferInfo{ID: "big.iso", Size: -1})
+	target := filepath.Join(t.TempDir(), "stats.jsonl")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.DumpOnSignal(ctx, target, nil)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(target)
+		if bytes.HasSuffix(data, []byte("\n")) {
+			var s Snapshot
+			if err := json.Unmarshal(data, &s); err != nil {
+				t.Fatal(err)
+			}
+			if len(s.Active) != 1 || s.Active[0].ID != "big.iso" {
+				t.Fatalf("덤프 = %+v", s)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("SIGUSR1 을 보냈는데 덤프가 안 생김")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,267 @@
+// Package stats 는 오래 도는 프로세스(serve, schedule, queue run)의 지금 상태를 모아서
+// SIGUSR1 을 받으면 스냅샷을 stderr 나 파일에 쓰는 패키지야 - 프로파일러를 붙이지 않고 "지금 뭐 하고 있지?" 를 볼 때.
+//
+//	kill -USR1 $(pgrep streamctl)
+//
+// ⭐ Collector 가 streamio.Hooks 라서 전송 코드는 그대로 두고 훅에 붙이기만 하면 돼.
+// 작업 풀처럼 훅으로 안 보이는 건 Gauge 로 값을 읽어 오는 함수를 등록해.
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Transfer 진행 중인 전송 하나
+type Transfer struct {
+	ID          string        `json:"id"`
+	Src         string        `json:"src,omitempty"`
+	Dst         string        `json:"dst,omitempty"`
+	Size        int64         `json:"size"` // 모르면 -1
+	Transferred int64         `json:"transferred"`
+	Retries     int           `json:"retries,omitempty"`
+	Elapsed     time.Duration `json:"elapsed"`
+}
+
+// Snapshot 어느 한 순간의 상태
+type Snapshot struct {
+	Name   string        `json:"name"`
+	PID    int           `json:"pid"`
+	Time   time.Time     `json:"time"`
+	Uptime time.Duration `json:"uptime"`
+
+	Active    []Transfer `json:"active"` // 오래 걸린 것부터
+	Started   int64      `json:"started"`
+	Completed int64      `json:"completed"`
+	Failed    int64      `json:"failed"`
+	Retries   int64      `json:"retries"`
+	Bytes     int64      `json:"bytes"` // 끝난 전송 + 진행 중인 전송이 옮긴 바이트
+
+	Goroutines int    `json:"goroutines"`
+	HeapAlloc  uint64 `json:"heap_alloc"`
+	HeapSys    uint64 `json:"heap_sys"`
+	NumGC      uint32 `json:"num_gc"`
+
+	RateLimit int64                 `json:"rate_limit"` // 설정된 초당 바이트 (0 이면 제한 없음)
+	Throttle  streamio.ThrottleStat `json:"throttle"`
+
+	Gauges map[string]any `json:"gauges,omitempty"`
+}
+
+type active struct {
+	info        streamio.TransferInfo
+	start       time.Time
+	transferred int64
+	retries     int
+}
+
+// Collector 훅으로 들어오는 전송을 세고 스냅샷을 만들어
+type Collector struct {
+	streamio.NopHooks
+	name  string
+	start time.Time
+
+	// RateLimit 스냅샷에 같이 보여 줄 속도 제한 설정 (초당 바이트)
+	RateLimit int64
+
+	mu        sync.Mutex
+	active    map[streamio.TransferInfo]*active
+	started   int64
+	completed int64
+	failed    int64
+	retries   int64
+	bytes     int64 // 끝난 전송만
+	gauges    map[string]func() any
+}
+
+// New name(보통 프로그램 이름)으로 Collector 생성 - 실행 시간은 지금부터 세
+func New(name string) *Collector {
+	return &Collector{name: name, start: time.Now(), active: map[streamio.TransferInfo]*active{}, gauges: map[string]func() any{}}
+}
+
+// Gauge 스냅샷을 만들 때마다 fn 을 불러서 name 으로 넣어 (작업 풀 사용량 등) - fn 은 빨리 끝나야 해
+func (c *Collector) Gauge(name string, fn func() any) {
+	c.mu.Lock()
+	c.gauges[name] = fn
+	c.mu.Unlock()
+}
+
+func (c *Collector) OnStart(info streamio.TransferInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started++
+	c.active[info] = &active{info: info, start: time.Now()}
+}
+
+func (c *Collector) OnProgress(info streamio.TransferInfo, transferred int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if a := c.active[info]; a != nil {
+		a.transferred = transferred
+	}
+}
+
+func (c *Collector) OnRetry(info streamio.TransferInfo, attempt int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retries++
+	if a := c.active[info]; a != nil {
+		a.retries = attempt
+	}
+}
+
+func (c *Collector) OnComplete(info streamio.TransferInfo, transferred int64, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.completed++
+	c.bytes += transferred
+	delete(c.active, info)
+}
+
+func (c *Collector) OnError(info streamio.TransferInfo, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failed++
+	if a := c.active[info]; a != nil {
+		c.bytes += a.transferred // 실패해도 옮긴 만큼은 옮긴 거야
+	}
+	delete(c.active, info)
+}
+
+// Snapshot 지금 상태
+func (c *Collector) Snapshot() Snapshot {
+	now := time.Now()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	s := Snapshot{
+		Name: c.name, PID: os.Getpid(), Time: now, Uptime: now.Sub(c.start),
+		Goroutines: runtime.NumGoroutine(), HeapAlloc: mem.HeapAlloc, HeapSys: mem.HeapSys, NumGC: mem.NumGC,
+		RateLimit: c.RateLimit, Throttle: streamio.ThrottleStats(),
+	}
+
+	c.mu.Lock()
+	s.Started, s.Completed, s.Failed, s.Retries, s.Bytes = c.started, c.completed, c.failed, c.retries, c.bytes
+	s.Active = make([]Transfer, 0, len(c.active))
+	for _, a := range c.active {
+		s.Active = append(s.Active, Transfer{
+			ID: a.info.ID, Src: a.info.Src, Dst: a.info.Dst, Size: a.info.Size,
+			Transferred: a.transferred, Retries: a.retries, Elapsed: now.Sub(a.start),
+		})
+		s.Bytes += a.transferred
+	}
+	gauges := make(map[string]func() any, len(c.gauges))
+	for name, fn := range c.gauges {
+		gauges[name] = fn
+	}
+	c.mu.Unlock()
+
+	// 게이지는 잠금 밖에서 - fn 이 다른 잠금을 잡아도 훅이 멈추지 않게
+	if len(gauges) > 0 {
+		s.Gauges = make(map[string]any, len(gauges))
+		for name, fn := range gauges {
+			s.Gauges[name] = fn()
+		}
+	}
+	sort.Slice(s.Active, func(i, j int) bool { return s.Active[i].Elapsed > s.Active[j].Elapsed })
+	return s
+}
+
+// WriteText 사람이 읽는 형식으로
+func (s Snapshot) WriteText(w io.Writer) error {
+	var b strings.Builder
+	msg.Fprintf(&b, "=== %s 상태 %s (pid %d, 실행 %s) ===\n", s.Name, s.Time.Format("2006-01-02 15:04:05"), s.PID, s.Uptime.Round(time.Second))
+	msg.Fprintf(&b, "고루틴 %d, 힙 %.1fMB (확보 %.1fMB), GC %d회\n", s.Goroutines, mb(s.HeapAlloc), mb(s.HeapSys), s.NumGC)
+	msg.Fprintf(&b, "전송: 진행 중 %d, 시작 %d, 완료 %d, 실패 %d, 재시도 %d, 옮긴 바이트 %d\n", len(s.Active), s.Started, s.Completed, s.Failed, s.Retries, s.Bytes)
+	limit := msg.T("없음")
+	if s.RateLimit > 0 {
+		limit = fmt.Sprintf("%d B/s", s.RateLimit)
+	}
+	msg.Fprintf(&b, "속도 제한: %s, 대기 %d회 (총 %s)\n", limit, s.Throttle.Waits, s.Throttle.Waited.Round(time.Millisecond))
+
+	names := make([]string, 0, len(s.Gauges))
+	for name := range s.Gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %v\n", name, s.Gauges[name])
+	}
+
+	for _, t := range s.Active {
+		progress := fmt.Sprintf("%d", t.Transferred)
+		if t.Size > 0 {
+			progress = fmt.Sprintf("%d/%d (%.1f%%)", t.Transferred, t.Size, float64(t.Transferred)/float64(t.Size)*100)
+		}
+		fmt.Fprintf(&b, "  %s  %s → %s  %s  %s", t.ID, t.Src, t.Dst, progress, t.Elapsed.Round(time.Second))
+		if t.Retries > 0 {
+			msg.Fprintf(&b, "  재시도 %d", t.Retries)
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mb(n uint64) float64 { return float64(n) / (1 << 20) }
+
+// Dump 스냅샷을 target 에 써 - 비었으면 stderr 에 글로, 파일이면 뒤에 덧붙여 (.json/.jsonl 이면 JSON 한 줄)
+func (c *Collector) Dump(target string) error {
+	s := c.Snapshot()
+	if target == "" {
+		return s.WriteText(os.Stderr)
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return msg.Errorf("상태 파일 열기 실패: %w", err)
+	}
+	switch filepath.Ext(target) {
+	case ".json", ".jsonl":
+		err = json.NewEncoder(f).Encode(s)
+	default:
+		err = s.WriteText(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// DumpOnSignal ctx 가 끝날 때까지 SIGUSR1 을 받을 때마다 Dump(target) - SIGUSR1 이 없는 OS(Windows)에서는 아무것도 안 해
+func (c *Collector) DumpOnSignal(ctx context.Context, target string, logger *slog.Logger) {
+	if len(dumpSignals) == 0 {
+		return
+	}
+	if logger == nil {
+		logger = logging.For("stats")
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, dumpSignals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if err := c.Dump(target); err != nil {
+					logger.Warn("상태 덤프 실패", "target", target, "err", err)
+				}
+			}
+		}
+	}()
+}
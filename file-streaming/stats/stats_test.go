@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+func TestCollector(t *testing.T) {
+	c := New("test")
+	c.RateLimit = 1 << 20
+	c.Gauge("pool", func() any { return "2/4" })
+
+	a := streamio.TransferInfo{ID: "a.bin", Src: "/src/a.bin", Dst: "/dst/a.bin", Size: 100}
+	b := streamio.TransferInfo{ID: "b.bin", Size: -1}
+	done := streamio.TransferInfo{ID: "c.bin", Size: 10}
+
+	c.OnStart(a)
+	c.OnStart(b)
+	c.OnStart(done)
+	c.OnProgress(a, 40)
+	c.OnRetry(a, 1, errors.New("끊김"))
+	c.OnProgress(b, 7)
+	c.OnComplete(done, 10, time.Millisecond)
+
+	s := c.Snapshot()
+	if len(s.Active) != 2 || s.Started != 3 || s.Completed != 1 || s.Retries != 1 || s.Bytes != 57 {
+		t.Fatalf("스냅샷 = %+v", s)
+	}
+	if s.Gauges["pool"] != "2/4" || s.RateLimit != 1<<20 || s.Goroutines == 0 || s.PID != os.Getpid() {
+		t.Fatalf("스냅샷 = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"=== test 상태", "진행 중 2", "a.bin  /src/a.bin → /dst/a.bin  40/100 (40.0%)", "재시도 1", "pool: 2/4", "1048576 B/s"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("출력에 %q 없음:\n%s", want, buf.String())
+		}
+	}
+
+	// 실패하면 진행 중에서 빠지고, 옮긴 바이트는 합계에 남아
+	c.OnError(a, errors.New("실패"))
+	s = c.Snapshot()
+	if len(s.Active) != 1 || s.Failed != 1 || s.Bytes != 57 {
+		t.Fatalf("실패 후 스냅샷 = %+v", s)
+	}
+}
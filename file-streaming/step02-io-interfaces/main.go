@@ -3,11 +3,15 @@ package main
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
 )
 
 func main() {
+	logging.SetupFromEnv()
 	//readPattern()
 	writePattern()
 }
@@ -26,12 +30,12 @@ func readPattern() {
 			break
 		}
 		if err != nil {
-			fmt.Println("Error:", err)
+			slog.Error("읽기 실패", "err", err)
 			break
 		}
 
 		fmt.Println(buf[:n])
-		fmt.Printf("\n읽은 바이트 수: %d, 내용: %s\n", n, string(buf[:n]))
+		slog.Info("읽음", "bytes", n, "content", string(buf[:n]))
 	}
 }
 
@@ -42,8 +46,9 @@ func writePattern() {
 	writer := os.Stdout
 	n, err := writer.Write(data)
 	if err != nil {
-		fmt.Println("Error:", err)
+		slog.Error("쓰기 실패", "err", err)
 		return
 	}
-	fmt.Printf("\n쓴 바이트 수: %d\n", n)
+	fmt.Println()
+	slog.Info("쓰기 완료", "bytes", n)
 }
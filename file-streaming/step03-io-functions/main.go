@@ -3,11 +3,15 @@ package main
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
 )
 
 func main() {
+	logging.SetupFromEnv()
 	//copyPattern()
 	//copyNPattern()
 	//readAllPattern()
@@ -29,7 +33,8 @@ func multiReaderPattern() {
 
 	//io.Copy(file, multiReader)
 	io.Copy(multiWriter, multiReader)
-	fmt.Println("완료!")
+	fmt.Println()
+	slog.Info("완료", "file", "multi_reader.txt")
 }
 
 func copyPattern() {
@@ -39,7 +44,7 @@ func copyPattern() {
 	// 목적지 Writer (파일)
 	dest, err := os.Create("output.txt")
 	if err != nil {
-		fmt.Println("파일 생성 실패:", err)
+		slog.Error("파일 생성 실패", "err", err)
 		return
 	}
 	defer dest.Close()
@@ -48,10 +53,10 @@ func copyPattern() {
 	// ⭐ io.Copy는 내부적으로 32KB 버퍼를 사용해서 효율적으로 데이터를 전송
 	written, err := io.Copy(dest, reader)
 	if err != nil {
-		fmt.Println("복사 실패:", err)
+		slog.Error("복사 실패", "err", err)
 		return
 	}
-	fmt.Printf("총 %d 바이트를 복사했어요!\n", written)
+	slog.Info("복사 완료", "bytes", written)
 }
 
 func copyNPattern() {
@@ -61,7 +66,7 @@ func copyNPattern() {
 	// 목적지 Writer (파일)
 	dest, err := os.Create("output.txt")
 	if err != nil {
-		fmt.Println("파일 생성 실패:", err)
+		slog.Error("파일 생성 실패", "err", err)
 		return
 	}
 	defer dest.Close()
@@ -70,10 +75,10 @@ func copyNPattern() {
 	// ⭐ io.CopyN은 정확히 n 바이트만 복사
 	written, err := io.CopyN(dest, reader, 20)
 	if err != nil {
-		fmt.Println("복사 실패:", err)
+		slog.Error("복사 실패", "err", err)
 		return
 	}
-	fmt.Printf("총 %d 바이트를 복사했어요!\n", written)
+	slog.Info("복사 완료", "bytes", written)
 }
 
 func readAllPattern() {
@@ -84,7 +89,7 @@ func readAllPattern() {
 	// 💥 ❗❗ io.ReadAll은 모든 데이터를 메모리에 올려. 대용량 파일에는 사용하지 마! 메모리가 터질 수 있어.
 	data, err := io.ReadAll(reader)
 	if err != nil {
-		fmt.Println("읽기 실패:", err)
+		slog.Error("읽기 실패", "err", err)
 		return
 	}
 	fmt.Println(string(data))
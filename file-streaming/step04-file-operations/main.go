@@ -2,16 +2,233 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strconv"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
 )
 
 func main() {
+	logging.SetupFromEnv()
+
+	// 인자가 있으면 분할/병합 도구로 동작
+	//   go run . split fake.log 104857600   → chunk_N.txt + chunks.json
+	//   go run . merge chunks.json merged.log (또는 청크 디렉토리)
+	//   go run . sparse ./data               → sparse 파일의 논리/실제 크기
+	//   go run . move big.log /mnt/backup/big.log  → 다른 디스크면 복사+검증 후 원본 삭제
+	//   go run . compare a.bin b.bin [-first]  → 다른 바이트 구간과 유사도
+	//   go run . punch data.bin 4096 1048576   → 구간을 구멍으로 만들어 공간 회수
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			logging.Fatal("실패", "command", os.Args[1], "err", err)
+		}
+		return
+	}
+
+	// 예제 입력(fake.log)이 없으면 만들어 - 직접 만들려면: go run ../gen-data -size 100MB
+	if err := gendata.Ensure("fake.log", gendata.KindLog, 10<<20); err != nil {
+		slog.Error("예제 파일 생성 실패", "err", err)
+		return
+	}
+
 	//openFilePattern()
 	//createFilePattern()
 	//bufferedFilePattern()
 	chunkedFilePattern()
+
+	// 텍스트/로그는 줄 중간에서 자르면 청크마다 따로 파싱할 수 없어서, 줄 경계에서 잘라야 해:
+	//splitLinesPattern()
+}
+
+func runCommand(cmd string, args []string) error {
+	switch cmd {
+	case "split":
+		if len(args) < 2 {
+			return errors.New("사용법: split <파일> <청크크기(바이트)>")
+		}
+		size, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("청크 크기 파싱 실패: %w", err)
+		}
+		chunks, err := streamio.Split(args[0], streamio.SplitOptions{
+			ChunkSize:    size,
+			Mode:         streamio.SplitLines,
+			ManifestPath: "chunks.json",
+		})
+		if err != nil {
+			return err
+		}
+		slog.Info("분할 완료", "chunks", len(chunks), "manifest", "chunks.json")
+
+	case "merge":
+		if len(args) < 2 {
+			return errors.New("사용법: merge <chunks.json|청크 디렉토리> <출력파일>")
+		}
+		if err := mergeChunks(args[0], args[1]); err != nil {
+			return err
+		}
+		slog.Info("병합 완료", "output", args[1])
+
+	case "sparse":
+		if len(args) < 1 {
+			return errors.New("사용법: sparse <파일|디렉토리>")
+		}
+		return reportSparse(args[0])
+
+	case "move":
+		if len(args) < 2 {
+			return errors.New("사용법: move <원본> <대상>")
+		}
+		// rename 이 바로 되면 진행률은 안 나오고, 복사로 넘어갔을 때만 출력돼
+		hooks := &streamio.ProgressHooks{Mode: streamio.ProgressText, Out: os.Stderr}
+		if err := streamio.Move(context.Background(), args[0], args[1], streamio.CopyOptions{Hooks: hooks}); err != nil {
+			return err
+		}
+		slog.Info("이동 완료", "src", args[0], "dst", args[1])
+
+	case "compare":
+		if len(args) < 2 {
+			return errors.New("사용법: compare <파일A> <파일B> [-first]")
+		}
+		opts := streamio.CompareOptions{StopAtFirst: len(args) > 2 && args[2] == "-first"}
+		result, err := streamio.Compare(args[0], args[1], opts)
+		if err != nil {
+			return err
+		}
+		for _, d := range result.Diffs {
+			fmt.Printf("차이: %d ~ %d (%d 바이트)\n", d.Offset, d.Offset+d.Length-1, d.Length)
+		}
+		if result.Truncated {
+			fmt.Println("... (구간이 너무 많아서 나머지는 생략)")
+		}
+		fmt.Println(result)
+		if !result.Equal {
+			os.Exit(1)
+		}
+
+	case "punch":
+		if len(args) < 3 {
+			return errors.New("사용법: punch <파일> <offset> <length>")
+		}
+		offset, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("offset 파싱 실패: %w", err)
+		}
+		length, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("length 파싱 실패: %w", err)
+		}
+		return punchFile(args[0], offset, length)
+
+	default:
+		return fmt.Errorf("알 수 없는 명령: %s (split|merge|sparse|move|compare|punch)", cmd)
+	}
+	return nil
+}
+
+// sparse 파일 찾기 - 논리 크기(ls -l)와 실제 할당 크기(du)를 같이 보여줘
+// ⭐ truncate -s 10G 로 만든 파일은 10GB 로 보이지만 디스크는 거의 안 써.
+// 이런 파일을 그냥 복사하면 구멍이 0 으로 다 채워지니까 CopyOptions.Sparse 를 켜야 해.
+func reportSparse(root string) error {
+	var apparent, actual int64
+	count := 0
+
+	for entry := range fstree.Walk(context.Background(), root, fstree.WalkOptions{}) {
+		if entry.Err != nil {
+			slog.Warn("순회 에러", "err", entry.Err)
+			continue
+		}
+		if !entry.Sparse() {
+			continue
+		}
+		count++
+		apparent += entry.Info.Size()
+		actual += entry.AllocatedSize()
+		fmt.Printf("%s: 논리 %d 바이트, 실제 %d 바이트\n", entry.RelPath, entry.Info.Size(), entry.AllocatedSize())
+	}
+
+	fmt.Printf("sparse 파일 %d개: 논리 %d 바이트, 실제 %d 바이트\n", count, apparent, actual)
+	return nil
+}
+
+// 구간 할당 해제 전후의 실제 디스크 사용량 비교
+func punchFile(path string, offset, length int64) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	before, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := streamio.PunchHole(file, offset, length); err != nil {
+		return err
+	}
+	after, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("크기 %d 바이트 그대로, 실제 사용량 %d → %d 바이트\n",
+		after.Size(), streamio.AllocatedSize(before), streamio.AllocatedSize(after))
+	return nil
+}
+
+// 분할 → 전송 → 병합 흐름의 마지막 단계
+// 매니페스트가 있으면 청크별/전체 체크섬까지 검증하고, 디렉토리면 chunk_N 번호 순서로만 합쳐
+func mergeChunks(source, output string) error {
+	var manifest streamio.ChunkManifest
+	var err error
+
+	if info, statErr := os.Stat(source); statErr == nil && info.IsDir() {
+		manifest, err = streamio.FindChunks(source)
+	} else {
+		manifest, err = streamio.ReadChunkManifest(source)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = streamio.Merge(output, manifest)
+
+	// 어느 청크가 깨졌는지 알려주기
+	var checksumErr *streamio.ChecksumError
+	if errors.As(err, &checksumErr) && checksumErr.Index > 0 {
+		slog.Error("청크를 다시 받아야 해요", "chunk", checksumErr.Index, "path", checksumErr.Path)
+	}
+	return err
+}
+
+// chunkedFilePattern 의 Split 모드: 목표 크기 근처의 줄바꿈에서 잘라서
+// 각 청크가 완전한 줄들로만 이루어지게 해 (CSV/로그를 청크별로 병렬 분석할 때 필수!)
+func splitLinesPattern() {
+	chunks, err := streamio.Split("fake.log", streamio.SplitOptions{
+		ChunkSize: 1024 * 1024 * 100, // 100MB 근처에서
+		Mode:      streamio.SplitLines,
+		// 한 줄이 100MB 보다 길면 그 줄이 끝날 때까지 청크를 늘리되, 200MB 는 넘기지 않아
+		MaxChunkSize: 1024 * 1024 * 200,
+	})
+	if err != nil {
+		slog.Error("분할 실패", "err", err)
+		return
+	}
+
+	var totalBytes int64
+	for _, c := range chunks {
+		fmt.Printf("청크 %d: %s (오프셋 %d, %d 바이트, sha256 %s)\n", c.Index, c.Path, c.Offset, c.Size, c.SHA256[:12])
+		totalBytes += c.Size
+	}
+	slog.Info("분할 완료", "bytes", totalBytes, "chunks", len(chunks))
 }
 
 // 정말 큰 파일을 처리할 때는 청크(chunk) 단위로 나눠서 읽는 게 좋아:
@@ -29,7 +246,7 @@ func chunkedFilePattern() {
 		// chunkSize 만큼 읽기
 		n, err := file.Read(buffer)
 		if err != nil && err != io.EOF {
-			fmt.Printf("청크 %d 읽기 실패: %v\n", chunkNumber, err)
+			slog.Error("청크 읽기 실패", "chunk", chunkNumber, "err", err)
 			break
 		}
 
@@ -38,7 +255,7 @@ func chunkedFilePattern() {
 		}
 
 		// 여기서 데이터 처리
-		fmt.Printf("청크 %d: %d 바이트 처리\n", chunkNumber, n)
+		slog.Info("청크 처리", "chunk", chunkNumber, "bytes", n)
 		//fmt.Println(string(buffer[:n]))
 		outputFile, _ := os.Create(fmt.Sprintf("chunk_%d.txt", chunkNumber))
 		outputFile.Write(buffer[:n])
@@ -50,7 +267,7 @@ func chunkedFilePattern() {
 		chunkNumber++
 
 	}
-	fmt.Printf("총 %d 바이트 처리 완료!\n", totalBytes)
+	slog.Info("처리 완료", "bytes", totalBytes, "chunks", chunkNumber-1)
 	return
 }
 
@@ -74,7 +291,7 @@ func bufferedFilePattern() {
 	}
 
 	if err := scanner.Err(); err != nil {
-		fmt.Printf("스캔 중 에러: %v\n", err)
+		slog.Error("스캔 중 에러", "err", err)
 	}
 }
 
@@ -104,14 +321,14 @@ func createFilePattern() {
 	defer file2.Close()
 
 	file2.WriteString("이 내용은 파일 끝에 추가돼요!\n")
-	fmt.Println("파일 쓰기 완료!")
+	slog.Info("파일 쓰기 완료", "file", "create_file.txt")
 }
 
 func openFilePattern() {
 	// 읽기 전용으로 파일열기
 	file, err := os.Open("02_버퍼링_vs_논버퍼링.png")
 	if err != nil {
-		fmt.Println("파일 열기 실패:", err)
+		slog.Error("파일 열기 실패", "err", err)
 		return
 	}
 
@@ -121,7 +338,7 @@ func openFilePattern() {
 	// 파일 정보 가져오기
 	fileInfo, err := file.Stat()
 	if err != nil {
-		fmt.Println("파일 정보 가져오기 실패:", err)
+		slog.Error("파일 정보 가져오기 실패", "err", err)
 		return
 	}
 
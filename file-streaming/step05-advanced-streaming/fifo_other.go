@@ -0,0 +1,12 @@
+//go:build !unix
+
+package main
+
+import "log/slog"
+
+func runFifoWriter() bool { return false }
+
+// namedPipePattern mkfifo 가 없는 플랫폼 (Windows 의 이름 있는 파이프는 \\.\pipe\ 아래에 따로 있어)
+func namedPipePattern() {
+	slog.Warn("이 플랫폼에는 mkfifo 가 없어서 FIFO 예제를 건너뜀")
+}
@@ -0,0 +1,103 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"golang.org/x/sys/unix"
+)
+
+// fifoWriterEnv 이 환경 변수가 있으면 이 프로그램은 FIFO 에 쓰는 쪽 프로세스로 실행돼
+const fifoWriterEnv = "STEP05_FIFO_WRITER"
+
+// ⭐ 이름 있는 파이프(FIFO) - io.Pipe 의 프로세스 버전
+// io.Pipe 는 한 프로세스 안의 고루틴끼리, FIFO 는 파일 경로 하나로 서로 다른 프로세스끼리 스트리밍해.
+// 디스크에는 아무것도 쓰지 않고 커널 버퍼(보통 64KB)만 거쳐 가서, 읽는 쪽이 느리면 쓰는 쪽이 기다려 (백프레셔).
+//
+//	mkfifo /tmp/logpipe
+//	go run ./streamctl analyze /tmp/logpipe &                  # 읽는 쪽: 쓰는 쪽이 열 때까지 기다려
+//	go run ./gen-data -size 100MB -o /tmp/logpipe              # 쓰는 쪽: 다 쓰고 닫으면 읽는 쪽이 EOF
+//
+// 주의할 점 - 일반 파일처럼 다루면 안 돼
+//   - Stat 크기가 항상 0 이라 진행률은 퍼센트 대신 처리한 바이트만 (streamio.KnownSize 가 -1)
+//   - Seek, mmap, 실패 후 처음부터 다시 읽기(재시도) 불가 - 한 번 읽은 데이터는 사라져
+//   - 대상이 FIFO 면 임시 파일 + rename 으로 쓰면 파이프가 일반 파일로 바뀌어 버려 (streamio.CopyFile 은 바로 써)
+func namedPipePattern() {
+	dir, err := os.MkdirTemp("", "step05-fifo-")
+	if err != nil {
+		slog.Error("임시 디렉토리 생성 실패", "err", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	fifo := filepath.Join(dir, "logpipe")
+	if err := unix.Mkfifo(fifo, 0600); err != nil {
+		slog.Error("mkfifo 실패", "err", err)
+		return
+	}
+
+	// 쓰는 쪽: 이 프로그램을 한 번 더 실행 (진짜 별개의 프로세스)
+	writer := exec.Command(os.Args[0])
+	writer.Env = append(os.Environ(), fifoWriterEnv+"="+fifo)
+	writer.Stderr = os.Stderr
+	if err := writer.Start(); err != nil {
+		slog.Error("쓰는 프로세스 시작 실패", "err", err)
+		return
+	}
+	slog.Info("FIFO 생성", "path", fifo, "writer_pid", writer.Process.Pid)
+
+	// 읽는 쪽: 일반 파일처럼 복사하지만 크기는 모르는 채로(-1) 끝까지 읽어
+	info, _ := os.Stat(fifo)
+	slog.Info("FIFO 정보", "mode", info.Mode().String(), "stat_size", info.Size(), "known_size", streamio.KnownSize(info))
+
+	n, err := streamio.CopyFile(context.Background(), fifo, "fifo_copy.log", streamio.CopyOptions{Hooks: streamio.LogHooks{}})
+	if err != nil {
+		slog.Error("FIFO 복사 실패", "err", err)
+	}
+	if err := writer.Wait(); err != nil {
+		slog.Error("쓰는 프로세스 실패", "err", err)
+	}
+	slog.Info("FIFO 로 받은 데이터", "bytes", n, "output", "fifo_copy.log")
+}
+
+// runFifoWriter namedPipePattern 이 띄운 자식 프로세스면 FIFO 에 쓰고 true (main 은 바로 끝내)
+func runFifoWriter() bool {
+	path := os.Getenv(fifoWriterEnv)
+	if path == "" {
+		return false
+	}
+	writeToFifo(path)
+	return true
+}
+
+// writeToFifo 쓰는 쪽 프로세스 - 읽는 쪽이 FIFO 를 열 때까지 OpenFile 에서 기다려
+func writeToFifo(path string) {
+	src, err := os.Open("fake.log")
+	if err != nil {
+		slog.Error("원본 열기 실패", "err", err)
+		os.Exit(1)
+	}
+	defer src.Close()
+
+	pipe, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		slog.Error("FIFO 열기 실패", "err", err)
+		os.Exit(1)
+	}
+	n, err := io.Copy(pipe, src)
+	if closeErr := pipe.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		slog.Error("FIFO 쓰기 실패", "err", err)
+		os.Exit(1)
+	}
+	slog.Info("FIFO 에 쓰기 완료", "pid", os.Getpid(), "bytes", n)
+}
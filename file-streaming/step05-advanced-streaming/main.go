@@ -6,15 +6,31 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
 )
 
 // ⭐ io.Pipe는 Reader와 Writer를 연결해주는 메모리 파이프
 func main() {
+	logging.SetupFromEnv()
+	if runFifoWriter() { // namedPipePattern 이 띄운 쓰는 쪽 프로세스
+		return
+	}
+
+	// 예제 입력(fake.log)이 없으면 만들어 - 직접 만들려면: go run ../gen-data -size 100MB
+	if err := gendata.Ensure("fake.log", gendata.KindLog, 10<<20); err != nil {
+		slog.Error("예제 파일 생성 실패", "err", err)
+		return
+	}
+
 	//ioPipePattern()
 	//customReaderWriterPattern()
 	//limitReaderPattern()
+	//namedPipePattern()
 	teeReaderPattern()
 }
 
@@ -47,7 +63,7 @@ func ioPipePattern() {
 	// 메인 고루틴에서 압축하며 읽기
 	outFile, err := os.Create("compressed.zip")
 	if err != nil {
-		fmt.Printf("출력 파일 생성 실패: %v\n", err)
+		slog.Error("출력 파일 생성 실패", "err", err)
 		return
 	}
 	defer outFile.Close()
@@ -58,11 +74,11 @@ func ioPipePattern() {
 	// 파이프에서 읽으면서 동시에 압축
 	written, err := io.Copy(gzipWriter, pr)
 	if err != nil {
-		fmt.Printf("압축 실패: %v\n", err)
+		slog.Error("압축 실패", "err", err)
 		return
 	}
 
-	fmt.Printf("총 %d 바이트를 압축했어요!\n", written)
+	slog.Info("압축 완료", "bytes", written, "output", "compressed.zip")
 }
 
 // 대문자로 변환하는 Reader
@@ -181,7 +197,6 @@ func teeReaderPattern() {
 
 	// 체크썸 출력
 	checksum := hash.Sum(nil)
-	fmt.Printf("체크썸: %x\n", checksum)
-	fmt.Printf("쓴 바이트: %d\n", written)
+	slog.Info("저장 완료", "file", "tee_reader.txt", "bytes", written, "md5", fmt.Sprintf("%x", checksum))
 
 }
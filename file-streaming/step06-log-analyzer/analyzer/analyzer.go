@@ -0,0 +1,289 @@
+// Package analyzer 는 step06 로그 분석기 본체야.
+// step06 main 뿐 아니라 감시 데몬 같은 다른 도구도 같은 분석기를 쓸 수 있게 패키지로 분리했어.
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// logger 시작/완료 같은 진행 상황 로그 (분석 결과는 PrintReport/SaveReport 로)
+var logger = logging.For("analyzer")
+
+// 1. 버퍼링된 I/O - bufio.Reader로 효율적인 읽기
+// 2. 스트리밍 처리 - 한 줄씩 읽어서 메모리 절약
+// 3. 정규표현식 - 패턴 매칭으로 로그 분석
+// 4. 진행률 표시 - 사용자 경험 개선
+// 5. 구조화된 데이터 - 통계를 구조체로 관리
+// 6. 파일 쓰기 - 분석 결과를 파일로 저장
+
+// ipPattern IPv4 주소 (통계와 레코드 파싱이 같이 써)
+const ipPattern = `\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`
+
+// 로그 통계 구조체
+type LogStats struct {
+	TotalLines    int
+	ErrorCount    int
+	WarningCount  int
+	InfoCount     int
+	UniqueIPs     map[string]int
+	ErrorMessages []string
+}
+
+// 로그 분석기
+type LogAnalyzer struct {
+	stats        *LogStats
+	errorRegex   *regexp.Regexp
+	warningRegex *regexp.Regexp
+	ipRegex      *regexp.Regexp
+
+	// 진행률 출력 방식 (text: 1000줄마다 표시, json: stderr 로 NDJSON 레코드)
+	ProgressMode streamio.ProgressMode
+
+	// Sink 가 있으면 줄마다 ParseRecord 한 결과를 넘겨 (Parquet 로 내보내서 DuckDB/Spark 에서 더 깊게 분석)
+	Sink RecordSink
+}
+
+// 스트리밍 방식으로 로그 파일 분석
+func (la *LogAnalyzer) AnalyzerFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return msg.Errorf("파일열기 실패 : %w", err)
+	}
+	defer file.Close()
+
+	// 진행상황 표시를 위한 파일 크기 확인 (FIFO/장치는 Stat 크기가 0 이라 모르는 걸로)
+	size := int64(-1)
+	if fileInfo, err := file.Stat(); err == nil {
+		size = streamio.KnownSize(fileInfo)
+	}
+	return la.AnalyzeReader(file, filename, size)
+}
+
+// 파일이 아닌 스트림(원격 파일, 압축 해제 결과 등)을 분석 - size 는 진행률 계산용 (모르면 0 이하)
+func (la *LogAnalyzer) AnalyzeReader(r io.Reader, name string, fileSize int64) error {
+	// 버퍼링된 Reader 사용
+	reader := bufio.NewReader(r)
+	var processedBytes int64
+
+	logger.Info("로그 분석 시작", "file", name, "size", fileSize)
+	startTime := time.Now()
+
+	// ⭐ json 모드는 일정 주기로 stderr 에 진행률 레코드를 내보내 (stdout 리포트와 섞이지 않게)
+	var reporter *streamio.ProgressReporter
+	if la.ProgressMode == streamio.ProgressJSON {
+		reporter = streamio.NewProgressReporter(name, fileSize, streamio.ProgressJSON, os.Stderr, 0)
+		reporter.Start()
+		defer reporter.Stop()
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return msg.Errorf("읽기 에러: %w", err)
+		}
+
+		if len(line) > 0 {
+			la.processLine(line)
+			processedBytes += int64(len(line))
+			if la.Sink != nil && strings.TrimSpace(line) != "" {
+				if err := la.Sink.WriteRecord(ParseRecord(line)); err != nil {
+					return msg.Errorf("레코드 쓰기 실패: %w", err)
+				}
+			}
+
+			// 진행률 표시 매 1000줄마다
+			if reporter != nil {
+				reporter.Set(processedBytes)
+			} else if la.ProgressMode == streamio.ProgressText && la.stats.TotalLines%1000 == 0 {
+				if fileSize > 0 {
+					progress := float64(processedBytes) / float64(fileSize) * 100
+					msg.Printf("\r진행률: %.2f%% (%d 줄 처리)", progress, la.stats.TotalLines)
+				} else {
+					// 파이프처럼 끝을 모르면 처리한 양만
+					msg.Printf("\r%d 줄, %d 바이트 처리", la.stats.TotalLines, processedBytes)
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if reporter != nil {
+		reporter.Stop()
+	}
+
+	if la.ProgressMode == streamio.ProgressText && la.stats.TotalLines >= 1000 {
+		fmt.Println() // \r 로 갱신하던 진행률 줄 끝내기
+	}
+	logger.Info("로그 분석 완료", "file", name, "lines", la.stats.TotalLines, "elapsed", time.Since(startTime))
+	return nil
+
+}
+
+// 한줄씩 처리
+func (la *LogAnalyzer) processLine(line string) {
+	la.stats.TotalLines++
+
+	// combined 접근 로그(step09 서버)는 레벨 글자가 없어서 상태로 세 (5xx 에러, 4xx 경고)
+	if rec, ok := parseCombined(strings.TrimRight(line, "\r\n")); ok {
+		switch rec.Level {
+		case "ERROR":
+			la.stats.ErrorCount++
+			if len(la.stats.ErrorMessages) < 10 {
+				la.stats.ErrorMessages = append(la.stats.ErrorMessages, strings.TrimSpace(line))
+			}
+		case "WARN":
+			la.stats.WarningCount++
+		default:
+			la.stats.InfoCount++
+		}
+		la.stats.UniqueIPs[rec.IP]++
+		return
+	}
+
+	// 에러 체크
+	if la.errorRegex.MatchString(line) {
+		la.stats.ErrorCount++
+		// 에러 메시지 저장 (최대 10개)
+		if len(la.stats.ErrorMessages) < 10 {
+			la.stats.ErrorMessages = append(la.stats.ErrorMessages, strings.TrimSpace(line))
+		}
+	}
+
+	// 경고 체크
+	if la.warningRegex.MatchString(line) {
+		la.stats.WarningCount++
+	}
+
+	// INFO 체크
+	if strings.Contains(line, "INFO") {
+		la.stats.InfoCount++
+	}
+
+	// IP 주소 추출
+	ips := la.ipRegex.FindAllString(line, -1)
+	for _, ip := range ips {
+		la.stats.UniqueIPs[ip]++
+	}
+}
+
+// 수집된 통계
+func (la *LogAnalyzer) Stats() *LogStats {
+	return la.stats
+}
+
+// 결과 출력
+func (la *LogAnalyzer) PrintReport() {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println(msg.T("📊 로그 분석 보고서"))
+	fmt.Println(strings.Repeat("=", 60))
+
+	msg.Printf("\n총 라인 수: %d\n", la.stats.TotalLines)
+	msg.Printf("에러 수: %d (%.2f%%)\n",
+		la.stats.ErrorCount,
+		float64(la.stats.ErrorCount)/float64(la.stats.TotalLines)*100)
+	msg.Printf("경고 수: %d (%.2f%%)\n",
+		la.stats.WarningCount,
+		float64(la.stats.WarningCount)/float64(la.stats.TotalLines)*100)
+	msg.Printf("정보 수: %d (%.2f%%)\n",
+		la.stats.InfoCount,
+		float64(la.stats.InfoCount)/float64(la.stats.TotalLines)*100)
+
+	msg.Printf("\n고유 IP 주소 수: %d\n", len(la.stats.UniqueIPs))
+
+	// 가장 많이 나타난 IP 찾기
+	if len(la.stats.UniqueIPs) > 0 {
+		maxIP := ""
+		maxCount := 0
+		for ip, count := range la.stats.UniqueIPs {
+			if count > maxCount {
+				maxIP = ip
+				maxCount = count
+			}
+		}
+		msg.Printf("가장 빈번한 IP: %s (%d회)\n", maxIP, maxCount)
+	}
+
+	// 에러 메시지 샘플
+	if len(la.stats.ErrorMessages) > 0 {
+		fmt.Println(msg.T("\n최근 에러 메시지 샘플:"))
+		for i, msg := range la.stats.ErrorMessages {
+			fmt.Printf("%d. %s\n", i+1, msg)
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+// 결과를 파일로 저장
+func (la *LogAnalyzer) SaveReport(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	defer writer.Flush()
+
+	// 보고서 작성
+	msg.Fprintf(writer, "로그 분석 보고서\n")
+	msg.Fprintf(writer, "생성 시간: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
+	msg.Fprintf(writer, "총 라인 수: %d\n", la.stats.TotalLines)
+	msg.Fprintf(writer, "에러 수: %d\n", la.stats.ErrorCount)
+	msg.Fprintf(writer, "경고 수: %d\n", la.stats.WarningCount)
+	msg.Fprintf(writer, "정보 수: %d\n", la.stats.InfoCount)
+	msg.Fprintf(writer, "\n고유 IP 주소 목록:\n")
+
+	for ip, count := range la.stats.UniqueIPs {
+		msg.Fprintf(writer, "%s: %d회\n", ip, count)
+	}
+
+	return nil
+}
+
+// 공유 저널에 요약 한 줄 덧붙이기
+// ⭐ 여러 분석기 프로세스가 동시에 같은 저널에 써도 줄이 섞이지 않게 streamio.Appender 사용
+func (la *LogAnalyzer) AppendSummary(journal, source string) error {
+	line := fmt.Sprintf("%s\tfile=%s\tlines=%d\terrors=%d\twarnings=%d\tinfo=%d\tips=%d",
+		time.Now().Format(time.RFC3339),
+		source,
+		la.stats.TotalLines,
+		la.stats.ErrorCount,
+		la.stats.WarningCount,
+		la.stats.InfoCount,
+		len(la.stats.UniqueIPs))
+
+	appender, err := streamio.OpenAppender(journal)
+	if err != nil {
+		return err
+	}
+	defer appender.Close()
+
+	return appender.AppendLine(line)
+}
+
+func NewLogAnalyzer() *LogAnalyzer {
+	return &LogAnalyzer{
+		stats: &LogStats{
+			UniqueIPs:     make(map[string]int),
+			ErrorMessages: make([]string, 0),
+		},
+		errorRegex:   regexp.MustCompile(`ERROR|Error|error`),
+		warningRegex: regexp.MustCompile(`WARNING|Warning|warning`),
+		ipRegex:      regexp.MustCompile(ipPattern),
+		ProgressMode: streamio.ProgressText,
+	}
+}
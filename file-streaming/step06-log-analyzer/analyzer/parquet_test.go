@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestParseRecord(t *testing.T) {
+	rec := ParseRecord("2026-01-02 15:04:05.123 WARNING [worker-3] 10.1.2.3 POST /api/orders 500 42ms slow response\n")
+	want := Record{
+		Time:    time.Date(2026, 1, 2, 15, 4, 5, 123e6, time.Local),
+		Level:   "WARNING",
+		IP:      "10.1.2.3",
+		Method:  "POST",
+		Path:    "/api/orders",
+		Status:  500,
+		Latency: 42 * time.Millisecond,
+	}
+	if rec != want {
+		t.Errorf("ParseRecord = %+v, want %+v", rec, want)
+	}
+	// 형식이 다른 줄은 찾은 필드만
+	if rec := ParseRecord("ERROR something broke"); rec != (Record{Level: "ERROR"}) {
+		t.Errorf("ParseRecord = %+v", rec)
+	}
+}
+
+func TestParseCombined(t *testing.T) {
+	// step09 서버의 -access-log-format combined 한 줄 (뒤에 받은 바이트, 걸린 시간 µs)
+	line := `10.0.0.7 - - [02/Jan/2026:15:04:05 +0900] "GET /files/my \"report\".txt?v=2 HTTP/1.1" 503 - "-" "curl/8.0" 0 1532` + "\n"
+	want := Record{
+		Time:    time.Date(2026, 1, 2, 15, 4, 5, 0, time.FixedZone("", 9*3600)),
+		Level:   "ERROR",
+		IP:      "10.0.0.7",
+		Method:  "GET",
+		Path:    `/files/my \"report\".txt`,
+		Status:  503,
+		Latency: 1532 * time.Microsecond,
+	}
+	if rec := ParseRecord(line); !rec.Time.Equal(want.Time) || rec.Level != want.Level || rec.IP != want.IP || rec.Method != want.Method || rec.Path != want.Path || rec.Status != want.Status || rec.Latency != want.Latency {
+		t.Errorf("ParseRecord = %+v, want %+v", rec, want)
+	}
+	// 꼬리 없는 보통 combined 도
+	if rec := ParseRecord(`::1 - - [02/Jan/2026:15:04:05 +0000] "PUT /upload HTTP/2.0" 404 12 "-" "Go-http-client/2.0"`); rec.IP != "::1" || rec.Level != "WARN" || rec.Latency != 0 {
+		t.Errorf("ParseRecord = %+v", rec)
+	}
+
+	la := NewLogAnalyzer()
+	la.ProgressMode = streamio.ProgressNone
+	lines := line + `10.0.0.8 - - [02/Jan/2026:15:04:06 +0900] "GET /download?file=a HTTP/1.1" 200 1024 "-" "curl/8.0" 0 90` + "\n"
+	if err := la.AnalyzeReader(strings.NewReader(lines), "access.log", -1); err != nil {
+		t.Fatal(err)
+	}
+	if st := la.Stats(); st.TotalLines != 2 || st.ErrorCount != 1 || st.InfoCount != 1 || len(st.UniqueIPs) != 2 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestParquetSink(t *testing.T) {
+	r, err := gendata.NewReader(gendata.KindLog, 256<<10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	sink := NewParquetSink(&buf)
+	la := NewLogAnalyzer()
+	la.ProgressMode, la.Sink = streamio.ProgressNone, sink
+	if err := la.AnalyzeReader(r, "gen", -1); err != nil {
+		t.Fatal(err)
+	}
+	la.Sink.WriteRecord(ParseRecord("no fields here"))
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := parquet.Read[parquetRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := la.Stats().TotalLines + 1
+	if len(rows) != lines || sink.Count() != int64(lines) {
+		t.Fatalf("행 %d개 (Count %d), want %d", len(rows), sink.Count(), lines)
+	}
+	first := rows[0]
+	if first.Timestamp == 0 || first.Level == "" || first.IP == "" || !strings.HasPrefix(first.Path, "/") || first.Status == 0 || first.LatencyMS == 0 {
+		t.Errorf("첫 행 = %+v", first)
+	}
+	if last := rows[len(rows)-1]; last != (parquetRow{}) {
+		t.Errorf("필드 없는 줄 = %+v, want 전부 null", last)
+	}
+
+	// 파싱 못 한 필드는 빈 값이 아니라 null 이어야 DuckDB 에서 count(ip) 같은 게 맞아
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range f.Schema().Fields() {
+		if !field.Optional() {
+			t.Errorf("%s 컬럼이 optional 이 아님", field.Name())
+		}
+	}
+}
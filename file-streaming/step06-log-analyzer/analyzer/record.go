@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record 로그 한 줄을 필드로 나눈 것 - 없는 필드는 zero 값 (Parquet 에서는 null)
+// gendata 가 만드는 형식 기준: "2026-01-02 15:04:05.000 INFO    [worker-3] 10.0.0.1 GET /api/users 200 42ms 메시지"
+// step09 서버의 combined 접근 로그도 읽어 (parseCombined)
+type Record struct {
+	Time    time.Time     `json:"time"`
+	Level   string        `json:"level"`
+	IP      string        `json:"ip"`
+	Method  string        `json:"method"`
+	Path    string        `json:"path"`
+	Status  int           `json:"status"`
+	Latency time.Duration `json:"latency"`
+}
+
+// 줄 앞부분에 올 수 있는 시간 형식 (앞에서부터 먼저 맞는 것)
+var timeLayouts = []string{"2006-01-02 15:04:05.000", "2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05"}
+
+var (
+	levelRegex   = regexp.MustCompile(`\b(ERROR|WARN(?:ING)?|INFO|DEBUG)\b`)
+	recordIP     = regexp.MustCompile(ipPattern)
+	requestRegex = regexp.MustCompile(`\b([A-Z]{3,7}) (/\S*) (\d{3}) (\d+(?:\.\d+)?(?:ns|us|µs|ms|s))(?:\s|$)`)
+
+	// combined: 10.0.0.1 - alice [02/Jan/2006:15:04:05 -0700] "GET /a?b=1 HTTP/1.1" 200 1024 "referer" "agent" [받은 바이트 걸린 시간(µs)]
+	combinedRegex = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "([A-Z]+) ((?:[^"\\]|\\.)*) HTTP/[\d.]+" (\d{3}) (?:\d+|-)(?: "(?:[^"\\]|\\.)*" "(?:[^"\\]|\\.)*"(?: \d+ (\d+))?)?`)
+)
+
+// combinedTime combined 로그의 [시간] 형식
+const combinedTime = "02/Jan/2006:15:04:05 -0700"
+
+// ParseRecord 한 줄에서 시간, 레벨, IP, 요청(메서드 경로 상태 지연)을 뽑아
+// ⭐ 형식이 조금 달라도 찾을 수 있는 필드만 채워 - 줄 하나가 어긋났다고 분석 전체를 멈추지 않아
+func ParseRecord(line string) Record {
+	line = strings.TrimRight(line, "\r\n")
+	if rec, ok := parseCombined(line); ok {
+		return rec
+	}
+
+	var rec Record
+
+	for _, layout := range timeLayouts {
+		if len(line) < len(layout) {
+			continue
+		}
+		// RFC3339 는 시간대 길이가 달라서 첫 공백까지 잘라서 봐
+		head := line[:len(layout)]
+		if strings.Contains(layout, "T") {
+			head, _, _ = strings.Cut(line, " ")
+		}
+		if t, err := time.ParseInLocation(layout, head, time.Local); err == nil {
+			rec.Time = t
+			break
+		}
+	}
+	if m := levelRegex.FindStringSubmatch(line); m != nil {
+		rec.Level = m[1]
+	}
+	rec.IP = recordIP.FindString(line)
+	if m := requestRegex.FindStringSubmatch(line); m != nil {
+		rec.Method, rec.Path = m[1], m[2]
+		rec.Status, _ = strconv.Atoi(m[3])
+		rec.Latency, _ = time.ParseDuration(m[4])
+	}
+	return rec
+}
+
+// parseCombined Apache/nginx combined 접근 로그 한 줄 (step09 서버의 -access-log-format combined)
+// ⭐ combined 에는 로그 레벨이 없어서 상태로 정해 - 5xx 는 ERROR, 4xx 는 WARN, 나머지는 INFO.
+// 경로는 쿼리를 뗀 것이고, 지연은 step09 가 끝에 붙이는 걸린 시간(µs)이 있을 때만 (없으면 0)
+func parseCombined(line string) (Record, bool) {
+	m := combinedRegex.FindStringSubmatch(line)
+	if m == nil {
+		return Record{}, false
+	}
+	rec := Record{IP: m[1], Method: m[3]}
+	rec.Time, _ = time.Parse(combinedTime, m[2])
+	rec.Path, _, _ = strings.Cut(m[4], "?")
+	rec.Status, _ = strconv.Atoi(m[5])
+	switch {
+	case rec.Status >= 500:
+		rec.Level = "ERROR"
+	case rec.Status >= 400:
+		rec.Level = "WARN"
+	default:
+		rec.Level = "INFO"
+	}
+	if us, err := strconv.ParseInt(m[6], 10, 64); err == nil {
+		rec.Latency = time.Duration(us) * time.Microsecond
+	}
+	return rec, true
+}
+
+// RecordSink 분석하면서 줄마다 뽑은 레코드를 받는 곳 (ParquetSink 등)
+// 분석이 끝나면 부르는 쪽에서 Close 해야 파일이 완성돼.
+type RecordSink interface {
+	WriteRecord(Record) error
+	Close() error
+}
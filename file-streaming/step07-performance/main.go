@@ -1,25 +1,67 @@
 package main
 
 import (
+	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
 )
 
 func main() {
+	logging.SetupFromEnv()
+
+	if err := ensureFixtures(); err != nil {
+		slog.Error("예제 파일 생성 실패", "err", err)
+		return
+	}
+
 	// 버퍼 크기는 성능에 큰 영향을 미쳐. 너무 작으면 시스템 콜이 많아지고, 너무 크면 메모리 낭비야:
 	//bufferTestPattern()
 
 	// 여러 파일을 동시에 처리하거나, 파이프라인을 구성해서 병렬 처리할 수 있어:
 	//compressTestPattern()
 
+	// 파일 목록을 하드코딩하지 않고 디렉토리를 순회하면서 바로 워커에 넘길 수도 있어:
+	//compressDirPattern()
+
 	// sync.Pool을 사용하면 버퍼를 재사용해서 GC 압력을 줄일 수 있어:
 	syncPoolTestPattern()
 
+	// mmap 으로 파일을 매핑하면 Seek 없이 여러 고루틴이 각자 구간을 동시에 읽을 수 있어:
+	//mmapParallelPattern()
+
+}
+
+// ensureFixtures 예제들이 읽는 입력 파일이 없으면 만들어 (gen-data 명령과 같은 생성기)
+// 압축 예제는 압축률 차이가 보이도록 로그/반복 패턴을 섞어서 만들어
+func ensureFixtures() error {
+	if err := gendata.Ensure("test_large_file.dat", gendata.KindRandom, 100<<20); err != nil {
+		return err
+	}
+	if err := gendata.Ensure("fake.log", gendata.KindLog, 10<<20); err != nil {
+		return err
+	}
+	for i := 1; i <= 5; i++ {
+		kind := gendata.KindLog
+		if i%2 == 0 {
+			kind = gendata.KindRepeat
+		}
+		if err := gendata.Ensure(fmt.Sprintf("file%d.txt", i), kind, 5<<20); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func copyWithBuffer(src, dst string, bufferSize int) (time.Duration, error) {
@@ -66,7 +108,7 @@ func bufferTestPattern() {
 	for _, size := range bufferSizes {
 		elapsed, err := copyWithBuffer(testFile, "output.tmp", size)
 		if err != nil {
-			fmt.Printf("에러: %v\n", err)
+			slog.Error("복사 실패", "buffer", size, "err", err)
 			continue
 		}
 
@@ -109,18 +151,20 @@ func compressFilesParallel(files []string, workers int) error {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
+			// ⭐ 워커마다 worker 속성이 붙은 로거 - 여러 워커 로그가 섞여도 누가 찍었는지 보여
+			log := slog.With("worker", workerID)
 
 			for inputFile := range jobs {
 				outputFile := inputFile + ".gz"
-				fmt.Printf("워커 %d: %s 압축 중...\n", workerID, inputFile)
+				log.Debug("압축 중", "file", inputFile)
 
 				err := compressFile(inputFile, outputFile)
 				results <- err
 
 				if err != nil {
-					fmt.Printf("워커 %d: 에러 - %v\n", workerID, err)
+					log.Error("압축 실패", "file", inputFile, "err", err)
 				} else {
-					fmt.Printf("워커 %d: %s 완료!\n", workerID, inputFile)
+					log.Info("압축 완료", "file", inputFile)
 				}
 			}
 		}(i)
@@ -162,14 +206,76 @@ func compressTestPattern() {
 	}
 
 	// 4개의 워커로 병렬 처리
-	fmt.Println("병렬 압축 시작...")
+	slog.Info("병렬 압축 시작", "files", len(files), "workers", 4)
 	err := compressFilesParallel(files, 4)
 	if err != nil {
-		fmt.Printf("압축 실패: %v\n", err)
+		slog.Error("압축 실패", "err", err)
 		return
 	}
 
-	fmt.Println("모든 파일 압축 완료!")
+	slog.Info("모든 파일 압축 완료")
+}
+
+// 디렉토리 순회 결과를 워커 풀에 바로 흘려보내는 병렬 압축
+// ⭐ fstree.Walk 가 채널로 파일을 보내주니까, 전체 목록이 모일 때까지 기다리지 않아도 돼
+func compressDirParallel(ctx context.Context, root string, workers int) error {
+	entries := fstree.Walk(ctx, root, fstree.WalkOptions{
+		Include: []string{"*.txt", "*.log"},
+		Exclude: []string{".git", "*.gz"},
+		MinSize: 1,
+	})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errorCount := 0
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			log := slog.With("worker", workerID)
+
+			for entry := range entries {
+				if entry.Err != nil {
+					log.Warn("순회 에러", "err", entry.Err)
+					continue
+				}
+
+				if err := compressFile(entry.Path, entry.Path+".gz"); err != nil {
+					log.Error("압축 실패", "file", entry.RelPath, "err", err)
+					mu.Lock()
+					errorCount++
+					mu.Unlock()
+					continue
+				}
+				log.Info("압축 완료", "file", entry.RelPath, "bytes", entry.Info.Size())
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d개 파일 압축 실패", errorCount)
+	}
+	return nil
+}
+
+func compressDirPattern() {
+	// 30초 안에 끝나지 않으면 순회와 압축을 모두 중단
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	slog.Info("디렉토리 병렬 압축 시작", "dir", ".", "workers", 4)
+	if err := compressDirParallel(ctx, ".", 4); err != nil {
+		slog.Error("압축 실패", "err", err)
+		return
+	}
+
+	slog.Info("모든 파일 압축 완료")
 }
 
 // 버퍼 풀
@@ -217,13 +323,68 @@ func syncPoolTestPattern() {
 			output := fmt.Sprintf("copy_%d.txt", idx)
 			err := copyFileWithPool(f, output)
 			if err != nil {
-				fmt.Printf("복사 실패 %s: %v\n", f, err)
+				slog.Error("복사 실패", "file", f, "err", err)
 			} else {
-				fmt.Printf("복사 완료: %s -> %s\n", f, output)
+				slog.Info("복사 완료", "src", f, "dst", output)
 			}
 		}(i, file)
 	}
 
 	wg.Wait()
-	fmt.Println("모든 복사 완료!")
+	slog.Info("모든 복사 완료")
+}
+
+// mmap 으로 매핑한 파일을 워커 수만큼 구간으로 나눠 병렬로 줄 수 세기
+// ⭐ 구간 경계는 줄바꿈에 맞춰서, 한 줄이 두 워커에 걸치지 않게 해
+func countLinesMmap(filename string, workers int) (int, error) {
+	m, err := streamio.OpenMmap(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer m.Close()
+
+	// 처음부터 끝까지 한 번씩 읽을 거라 순차 읽기 힌트
+	m.Advise(streamio.AdviceSequential)
+
+	data := m.Bytes()
+	segment := len(data)/workers + 1
+
+	var wg sync.WaitGroup
+	counts := make([]int, workers)
+	start := 0
+	for i := 0; i < workers && start < len(data); i++ {
+		end := start + segment
+		if end >= len(data) {
+			end = len(data)
+		} else if idx := bytes.IndexByte(data[end:], '\n'); idx >= 0 {
+			end += idx + 1
+		} else {
+			end = len(data)
+		}
+
+		wg.Add(1)
+		go func(idx int, part []byte) {
+			defer wg.Done()
+			counts[idx] = bytes.Count(part, []byte{'\n'})
+		}(i, data[start:end])
+
+		start = end
+	}
+	wg.Wait()
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	return total, nil
+}
+
+func mmapParallelPattern() {
+	start := time.Now()
+	lines, err := countLinesMmap("fake.log", 4)
+	if err != nil {
+		slog.Error("줄 수 세기 실패", "err", err)
+		return
+	}
+	fmt.Printf("줄 수: %d (소요 시간: %v)\n", lines, time.Since(start))
 }
@@ -5,20 +5,43 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
 )
 
 // 파일과 스트림을 다룰 때는 에러 처리가 정말 중요해.
 // 실무에서 쓰이는 안전한 패턴들을 알아보자! 🛡️
 
 func main() {
+	logging.SetupFromEnv()
+
+	// 예제 입력 파일이 없으면 만들어 (go run ../gen-data 와 같은 생성기)
+	if err := gendata.Ensure("source.txt", gendata.KindLog, 1<<20); err != nil {
+		slog.Error("예제 파일 생성 실패", "err", err)
+		return
+	}
+	if err := gendata.Ensure("large_file.txt", gendata.KindLog, 50<<20); err != nil {
+		slog.Error("예제 파일 생성 실패", "err", err)
+		return
+	}
+
 	// 이 패턴은 에러가 발생해도 리소스가 제대로 정리되도록 보장해줘! ✨
 	// deferDeletePattern()
 
 	// 네트워크 스트림이나 느린 I/O 작업에는 타임아웃이 필수야:
 	// contextTimeoutPattern()
 
+	// 전송 훅을 붙이면 복사 코드를 고치지 않고도 로그/메트릭을 끼워 넣을 수 있어:
+	// hooksCopyPattern()
+
+	// 백업/동기화용 복사는 내용뿐 아니라 권한과 수정 시각까지 똑같아야 해:
+	// preserveCopyPattern()
+
 	errorWrappingPattern()
 }
 
@@ -62,11 +85,52 @@ func safeCopyFile(src, dst string) (err error) {
 func deferDeletePattern() {
 	err := safeCopyFile("source.txt", "destination.txt")
 	if err != nil {
-		fmt.Printf("파일 복사 실패: %v\n", err)
+		slog.Error("파일 복사 실패", "err", err)
+		return
+	}
+
+	slog.Info("파일 복사 성공", "src", "source.txt", "dst", "destination.txt")
+}
+
+// 진행률만 세는 커스텀 훅 - NopHooks 를 임베드해서 필요한 메서드만 구현
+type progressCountHooks struct {
+	streamio.NopHooks
+	calls int
+}
+
+func (h *progressCountHooks) OnProgress(info streamio.TransferInfo, transferred int64) {
+	h.calls++
+}
+
+func hooksCopyPattern() {
+	counter := &progressCountHooks{}
+	opts := streamio.CopyOptions{
+		// 로그 훅 + 커스텀 훅을 같이 연결
+		Hooks:      streamio.MultiHooks{streamio.LogHooks{}, counter},
+		Retries:    2,
+		RetryDelay: 100 * time.Millisecond,
+	}
+
+	written, err := streamio.CopyFile(context.Background(), "source.txt", "destination.txt", opts)
+	if err != nil {
+		slog.Error("파일 복사 실패", "err", err)
 		return
 	}
 
-	fmt.Println("파일 복사 성공!")
+	slog.Info("파일 복사 성공", "bytes", written, "progress_calls", counter.calls)
+}
+
+func preserveCopyPattern() {
+	opts := streamio.CopyOptions{Preserve: streamio.PreserveAll}
+	if _, err := streamio.CopyFile(context.Background(), "source.txt", "backup.txt", opts); err != nil {
+		slog.Error("파일 복사 실패", "err", err)
+		return
+	}
+
+	src, _ := os.Stat("source.txt")
+	dst, _ := os.Stat("backup.txt")
+	fmt.Printf("권한: %v -> %v\n", src.Mode(), dst.Mode())
+	fmt.Printf("수정 시각: %v -> %v\n", src.ModTime(), dst.ModTime())
 }
 
 // 타임아웃이 있는 파일 읽기
@@ -111,11 +175,11 @@ func contextTimeoutPattern() {
 	// 5초 타임아웃으로 파일 읽기
 	data, err := readFileWithTimeout("large_file.txt", 5*time.Second)
 	if err != nil {
-		fmt.Printf("읽기 실패: %v\n", err)
+		slog.Error("읽기 실패", "err", err)
 		return
 	}
 
-	fmt.Printf("읽은 데이터 크기: %d 바이트\n", len(data))
+	slog.Info("읽기 완료", "bytes", len(data))
 }
 
 // 커스텀 에러 타입
@@ -133,6 +197,15 @@ func (e *FileProcessError) Unwrap() error {
 	return e.Err
 }
 
+// LogValue slog 에 넘기면 문자열 한 덩어리 대신 필드별로 찍혀 (err.file=... err.op=... err.cause=...)
+func (e *FileProcessError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("file", e.Filename),
+		slog.String("op", e.Op),
+		slog.Any("cause", e.Err),
+	)
+}
+
 // 파일 처리 함수
 func processFile(filename string) error {
 	file, err := os.Open(filename)
@@ -167,15 +240,12 @@ func errorWrappingPattern() {
 		// 에러 타입 확인
 		var fileErr *FileProcessError
 		if errors.As(err, &fileErr) {
-			fmt.Printf("파일 에러 발생!\n")
-			fmt.Printf("파일명: %s\n", fileErr.Filename)
-			fmt.Printf("작업: %s\n", fileErr.Op)
-			fmt.Printf("원인: %v\n", fileErr.Err)
+			slog.Error("파일 에러 발생", "err", fileErr)
 		}
 
 		// 특정 에러 확인
 		if errors.Is(err, os.ErrNotExist) {
-			fmt.Println("파일이 존재하지 않습니다.")
+			slog.Warn("파일이 존재하지 않습니다", "file", "nonexistent.txt")
 		}
 	}
 }
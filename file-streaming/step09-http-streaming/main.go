@@ -1,206 +1,134 @@
 package main
 
 import (
-	"fmt"
-	"io"
-	"log"
-	"net/http"
+	"context"
+	"flag"
+	"log/slog"
+	"net"
 	"os"
-	"path/filepath"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/daemon"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/stats"
+	"github.com/hellotect2022go/study-go/file-streaming/step09-http-streaming/server"
+	"github.com/hellotect2022go/study-go/file-streaming/tracing"
 )
 
-// 파일 다운로드 핸들러
-func downloadHandler(w http.ResponseWriter, r *http.Request) {
-	filename := r.URL.Query().Get("file")
-	if filename == "" {
-		http.Error(w, "파일명이 필요합니다", http.StatusBadRequest)
-		return
-	}
-
-	// 파일 열기
-	safeFilename := filepath.Base(filename) // " ../../etc/passwd" -> "passwd"로 변경됨
-	file, err := os.Open("./uploads/" + safeFilename)
-
-	if err != nil {
-		http.Error(w, "파일을 찾을 수 없습니다", http.StatusNotFound)
-		return
-	}
-	defer file.Close()
+// registerFlags 이 서버가 받는 설정 섹션 (SIGHUP 때 config.Reload 가 새 설정에 다시 등록해)
+func registerFlags(fs *flag.FlagSet, cfg *config.Config) {
+	cfg.Transfer.RegisterFlags(fs)
+	cfg.Server.RegisterFlags(fs)
+	cfg.Search.RegisterFlags(fs)
+	cfg.Extract.RegisterFlags(fs)
+	cfg.Log.RegisterFlags(fs)
+	cfg.Trace.RegisterFlags(fs)
+	cfg.Stats.RegisterFlags(fs)
+	cfg.Daemon.RegisterFlags(fs)
+	cfg.Usage.RegisterFlags(fs)
+	cfg.Auth.RegisterFlags(fs)
+	cfg.CORS.RegisterFlags(fs)
+	cfg.Locale.RegisterFlags(fs)
+}
 
-	// 파일 정보 가져오기
-	fileInfo, err := file.Stat()
+// 서버 본체는 server 패키지에 있어 (streamctl serve 에서도 재사용)
+func main() {
+	// 설정: 플래그 > 환경 변수(FS_ADDR, LOG_LEVEL, TRACE ...) > -config YAML/JSON > 기본값
+	cfgPath := config.PathFromArgs(os.Args[1:])
+	cfg, err := config.Load(cfgPath)
 	if err != nil {
-		http.Error(w, "파일 정보를 가져올 수 없습니다", http.StatusInternalServerError)
-		return
+		logging.Fatal("설정 읽기 실패", "err", err)
 	}
-
-	// 헤더 설정
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Length", strconv.FormatInt(fileInfo.Size(), 10))
-
-	// 스트리밍 전송
-	written, err := io.Copy(w, file)
-	if err != nil {
-		log.Printf("전송 중 에러: %v\n", err)
-		return
+	// -h 의 플래그 설명과 응답 에러 문구가 고른 언어로 나오게 플래그를 등록하기 전에
+	if err := cfg.Locale.Apply(os.Args[1:]); err != nil {
+		logging.Fatal("설정 오류", "err", err)
 	}
-
-	log.Printf("%s 파일 전송 완료: %d 바이트\n", filename, written)
-}
-
-// Range 요청을 지원하는 핸들러 (이어받기 지원)
-func rangeDownloadHandler(w http.ResponseWriter, r *http.Request) {
-	filename := r.URL.Query().Get("file")
-	if filename == "" {
-		http.Error(w, "파일명이 필요합니다", http.StatusBadRequest)
-		return
+	config.RegisterConfigFlag(flag.CommandLine)
+	registerFlags(flag.CommandLine, &cfg)
+	flag.Parse()
+	if err := cfg.Validate(); err != nil {
+		logging.Fatal("설정 오류", "err", err)
 	}
-
-	// 파일 열기
-	safeFilename := filepath.Base(filename) // " ../../etc/passwd" -> "passwd"로 변경됨
-	file, err := os.Open("./uploads/" + safeFilename)
-	if err != nil {
-		http.Error(w, "파일을 찾을 수 없습니다", http.StatusNotFound)
-		return
+	// -daemon 이면 백그라운드로 다시 실행하고 자식이 준비되면 부모는 끝
+	if cfg.Daemon.Detach {
+		if parent, err := daemon.Detach(cfg.Daemon.LogFile, 30*time.Second); parent {
+			if err != nil {
+				logging.Fatal("백그라운드 실행 실패", "err", err)
+			}
+			return
+		}
 	}
-	defer file.Close()
-
-	fileInfo, err := file.Stat()
-	if err != nil {
-		http.Error(w, "파일 정보를 가져올 수 없습니다", http.StatusInternalServerError)
-		return
+	if err := logging.Setup(cfg.Log.Options()); err != nil {
+		logging.Fatal("로그 설정 실패", "err", err)
 	}
-
-	fmt.Println("fileInfo : ", fileInfo)
-	// Content-Disposition 설정 (다운로드 창이 뜨게 함)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", safeFilename))
-
-	// http.ServeContent가 Range 헤더를 자동으로 확인하여
-	// 전체 전송(200 OK) 또는 부분 전송(206 Partial Content)을 알아서 처리합니다.
-	http.ServeContent(w, r, safeFilename, fileInfo.ModTime(), file)
-
-	// // Range 헤더 확인
-	// rangeHeader := r.Header.Get("Range")
-	// fmt.Println("rangeHeader :", rangeHeader)
-	// if rangeHeader == "" {
-	// 	// 전체 파일 전송
-	// 	w.Header().Set("Content-Length", strconv.FormatInt(fileInfo.Size(), 10))
-	// 	w.Header().Set("Content-Type", "application/octet-stream")
-	// 	io.Copy(w, file)
-	// 	return
-	// }
-
-	// // Range 요청 처리 (간단한 구현)
-	// // 실제로는 더 복잡한 파싱이 필요해
-	// var start, end int64
-	// fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
-
-	// fmt.Println("start : ", start, " end : ", end)
-
-	// if end == 0 || end >= fileInfo.Size() {
-	// 	end = fileInfo.Size() - 1
-	// }
-
-	// // 파일 포인터 이동
-	// file.Seek(start, 0)
-
-	// // 헤더 설정
-	// w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, fileInfo.Size()))
-	// w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
-	// w.Header().Set("Content-Type", "application/octet-stream")
-	// w.WriteHeader(http.StatusPartialContent)
-
-	// // 부분 전송
-	// io.CopyN(w, file, end-start+1)
-}
-
-// 업로드 핸들러
-func uploadHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "POST 메서드만 허용됩니다", http.StatusMethodNotAllowed)
-		return
+	if cfg.Daemon.PIDFile != "" {
+		remove, err := daemon.WritePIDFile(cfg.Daemon.PIDFile)
+		if err != nil {
+			logging.Fatal("PID 파일 쓰기 실패", "err", err)
+		}
+		defer remove()
 	}
 
-	// 멀티파트 폼 파싱 (최대 10MB 메모리 사용)
-	err := r.ParseMultipartForm(10 << 20)
+	// 전송 훅 - 메트릭/알림/감사 로그는 여기에 streamio.Hooks 구현을 붙이면 돼
+	// 예) streamio.MultiHooks{streamio.LogHooks{}, myMetricsHooks}
+	// 지금은 kill -USR1 <pid> 로 진행 중인 업로드/다운로드를 볼 수 있게 상태 수집기만
+	collector := stats.New("step09-http-streaming")
+	srv, err := server.New(server.FromConfig(cfg, collector))
 	if err != nil {
-		http.Error(w, "폼 파싱 실패", http.StatusBadRequest)
-		return
+		logging.Fatal("서버 생성 실패", "err", err)
 	}
-
-	// 파일 가져오기
-	file, header, err := r.FormFile("file")
+	// systemd 소켓 활성화(.socket 유닛)로 떴으면 넘겨받은 소켓으로, 아니면 -addr 로 직접
+	ln, err := daemon.Listen("tcp", srv.Addr())
 	if err != nil {
-		http.Error(w, "파일을 가져올 수 없습니다", http.StatusBadRequest)
-		return
+		logging.Fatal("리슨 실패", "err", err)
 	}
-	defer file.Close()
 
-	// 저장할 파일 생성
-	dst, err := os.Create("uploads/" + header.Filename)
-	if err != nil {
-		http.Error(w, "파일 생성 실패", http.StatusInternalServerError)
-		return
-	}
-	defer dst.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	base := srv.Scheme() + "://localhost:" + port
+	slog.Info("서버 시작",
+		"url", base,
+		"download", base+"/download?file=example.txt",
+		"upload", base+"/upload",
+		"delete", "curl -X DELETE '"+base+"/delete?file=example.txt'",
+		"search", base+"/api/search?q=error")
+
+	// Ctrl+C 나 SIGTERM 이면 진행 중인 다운로드/업로드를 마치고 종료
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	collector.DumpOnSignal(ctx, cfg.Stats.Dump, nil)
+
+	// kill -HUP (systemctl reload) 이면 설정 파일/환경 변수를 다시 읽어서 업로드 한도, 로그 레벨 같은 걸 재시작 없이 바꿔
+	daemon.OnReload(ctx, func() {
+		next, err := config.Reload(cfgPath, flag.CommandLine, registerFlags)
+		if err != nil {
+			slog.Error("설정 다시 읽기 실패 - 지금 설정 그대로", "err", err)
+			return
+		}
+		if err := logging.Setup(next.Log.Options()); err != nil {
+			slog.Error("로그 설정 실패", "err", err)
+		}
+		msg.SetLang(next.Locale.Lang)
+		for _, name := range srv.Reload(server.FromConfig(next, collector)) {
+			slog.Warn("재시작해야 바뀌는 설정이라 무시", "setting", name)
+		}
+		slog.Info("설정 다시 읽음", "max_upload", next.Server.MaxUpload, "log_level", next.Log.Level)
+	}, nil)
 
-	// 스트리밍 방식으로 저장
-	written, err := io.Copy(dst, file)
+	// 트레이싱 - -trace grpc://localhost:4317 (또는 TRACE 환경 변수)로 요청/업로드 스팬을 collector 로 보내 (비우면 끔)
+	shutdown, err := tracing.Setup(ctx, cfg.Trace.Target, "step09-http-streaming")
 	if err != nil {
-		http.Error(w, "파일 저장 실패", http.StatusInternalServerError)
-		return
+		logging.Fatal("트레이싱 설정 실패", "err", err)
 	}
+	defer shutdown(context.Background())
 
-	fmt.Fprintf(w, "파일 업로드 성공: %s (%d 바이트)\n", header.Filename, written)
-	log.Printf("파일 업로드: %s (%d 바이트)\n", header.Filename, written)
-}
-
-// 진행률을 보여주는 업로드 핸들러
-type ProgressReader struct {
-	reader     io.Reader
-	total      int64
-	current    int64
-	onProgress func(current, total int64)
-}
-
-func (pr *ProgressReader) Read(p []byte) (n int, err error) {
-	n, err = pr.reader.Read(p)
-	pr.current += int64(n)
-
-	if pr.onProgress != nil {
-		pr.onProgress(pr.current, pr.total)
+	daemon.Notify(daemon.Ready)
+	context.AfterFunc(ctx, func() { daemon.Notify(daemon.Stopping) })
+	if err := srv.Serve(ctx, ln); err != nil {
+		logging.Fatal("서버 실행 실패", "err", err)
 	}
-
-	return n, err
-}
-
-func main() {
-	// uploads 디렉토리 생성
-	os.MkdirAll("uploads", 0755)
-
-	// 1. 루트 경로("/") 접속 시 index.html 파일 서빙
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// 경로가 정확히 "/" 일 때만 index.html을 보여줌 (안그러면 모든 경로에서 보임)
-		if r.URL.Path != "/" {
-			http.NotFound(w, r)
-			return
-		}
-		http.ServeFile(w, r, "index.html") // index.html 파일 경로
-	})
-
-	// 핸들러 등록
-	http.HandleFunc("/download", downloadHandler)
-	http.HandleFunc("/range-download", rangeDownloadHandler)
-	http.HandleFunc("/upload", uploadHandler)
-
-	// 정적 파일 서빙
-	http.Handle("/files/", http.StripPrefix("/files", http.FileServer(http.Dir("./uploads"))))
-
-	fmt.Println("서버 시작: http://localhost:8080")
-	fmt.Println("다운로드: http://localhost:8080/download?file=example.txt")
-	fmt.Println("업로드: http://localhost:8080/upload")
-
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	slog.Info("서버 종료")
 }
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 접근 로그 (Config.AccessLog)
+// ⭐ stderr 로 가는 운영 로그(slog)와 따로, 요청마다 한 줄을 파일에 남겨 - 메서드, 경로, 상태, 보낸/받은 바이트, 걸린 시간, 클라이언트 IP.
+// json 은 필드 이름이 붙은 JSON 한 줄이고, combined 는 Apache/nginx 의 combined 형식 뒤에 받은 바이트와 걸린 시간(마이크로초)을 붙인 거야
+// (goaccess 같은 도구는 앞의 combined 부분만 읽으면 돼, step06 분석기는 꼬리까지 읽어서 걸린 시간도 봐). 파일이 AccessLogMaxSize 를 넘으면 .1, .2 … 로 돌려서 디스크를 채우지 않아.
+// 클라이언트 IP 는 연결의 주소야 - X-Forwarded-For 는 아무나 보낼 수 있어서 믿지 않아.
+
+// 접근 로그 형식 (Config.AccessLogFormat)
+const (
+	AccessJSON     = "json"     // JSON 한 줄 (기본)
+	AccessCombined = "combined" // combined + 받은 바이트 + 걸린 시간(µs)
+)
+
+// accessEntry 접근 로그 한 줄
+type accessEntry struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id"`
+	ClientIP   string    `json:"client_ip"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	BytesOut   int64     `json:"bytes_out"` // 응답 본문 (gzip 이면 압축한 크기)
+	BytesIn    int64     `json:"bytes_in"`  // 요청 본문에서 읽은 만큼 (핸들러가 다 안 읽었으면 그만큼만)
+	DurationMS float64   `json:"duration_ms"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// openAccessLog AccessLog 파일을 열어 (형식이 틀리면 에러)
+func (s *Server) openAccessLog() error {
+	switch s.cfg.AccessLogFormat {
+	case AccessJSON, AccessCombined:
+	default:
+		return msg.Errorf("알 수 없는 접근 로그 형식입니다: %q (%s, %s)", s.cfg.AccessLogFormat, AccessJSON, AccessCombined)
+	}
+	f, err := logging.OpenRotating(s.cfg.AccessLog, s.cfg.AccessLogMaxSize, s.cfg.AccessLogBackups)
+	if err != nil {
+		return err
+	}
+	s.access = f
+	return nil
+}
+
+// newAccessEntry 끝난 요청 하나의 기록
+func newAccessEntry(r *http.Request, id string, rec *statusRecorder, in int64, start time.Time) accessEntry {
+	return accessEntry{
+		Time: start, RequestID: id, ClientIP: clientIP(r),
+		Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Proto: r.Proto,
+		Status: rec.status, BytesOut: rec.bytes, BytesIn: in,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Referer:    r.Referer(), UserAgent: r.UserAgent(),
+	}
+}
+
+// writeAccess 접근 로그에 한 줄 (AccessLog 를 안 줬으면 아무것도 안 해)
+func (s *Server) writeAccess(r *http.Request, e accessEntry) {
+	if s.access == nil {
+		return
+	}
+	var line []byte
+	if s.cfg.AccessLogFormat == AccessCombined {
+		line = []byte(e.combined())
+	} else {
+		line, _ = json.Marshal(e)
+		line = append(line, '\n')
+	}
+	if _, err := s.access.Write(line); err != nil {
+		s.logger(r).DebugContext(r.Context(), "접근 로그를 쓰지 못함", "err", err)
+	}
+}
+
+// combined 127.0.0.1 - - [02/Jan/2006:15:04:05 -0700] "GET /download?file=a HTTP/1.1" 200 1024 "-" "curl/8.0" 0 1532
+func (e accessEntry) combined() string {
+	uri := e.Path
+	if e.Query != "" {
+		uri += "?" + e.Query
+	}
+	out := "-"
+	if e.BytesOut > 0 {
+		out = strconv.FormatInt(e.BytesOut, 10)
+	}
+	return fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s \"%s\" \"%s\" %d %d\n",
+		e.ClientIP, e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method, quoteLog(uri), e.Proto,
+		e.Status, out, quoteLog(orDash(e.Referer)), quoteLog(orDash(e.UserAgent)),
+		e.BytesIn, int64(e.DurationMS*1000))
+}
+
+// quoteLog 따옴표 안에 넣을 값 - " 와 \ 는 이스케이프하고 제어 문자는 \xHH 로 (한 줄이 여러 줄로 갈라지지 않게)
+func quoteLog(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func orDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
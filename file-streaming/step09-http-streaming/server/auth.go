@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 인증 - API 키(Config.APIKeys), HMAC 서명 JWT(Config.JWTSecret), 그리고 Config.Validators 에 붙인 검증기
+// ⭐ 요청의 자격 증명(X-API-Key 헤더나 Authorization: Bearer, WebDAV 클라이언트면 Basic 의 비밀번호)을 검증기에 차례로 물어서 처음 알아본 검증기의 계정으로 처리해.
+// 검증기가 하나도 없으면 인증 없이 모두 "anonymous" 계정이고, 하나라도 있으면 파일이 오가는 API 는 자격 증명이 필요해 -
+// 없거나 틀리면 401, 맞는데 그 일을 할 권한(scope)이 없으면 403 (둘 다 JSON 본문).
+// "/" 의 웹 UI 는 페이지만 열려 있고 키를 보내지 않아서, 인증을 켜면 UI 에서 목록/업로드는 401 이야.
+
+// 계정이 할 수 있는 일 (APIKey.Scopes, JWT 의 scope 클레임)
+const (
+	ScopeUpload   = "upload"   // /upload, /upload/<이름>, /api/uploads, /api/multipart, /api/extract, /upload-archive, /api/copy, /dav/ 의 PUT, COPY, LOCK
+	ScopeDownload = "download" // /download, /range-download, /files/, /api/files, /api/search, /api/events, /stats, /api/sign, /thumb, /hls, /dav/ 읽기
+	ScopeDelete   = "delete"   // /delete, /api/files/<이름> (rename, versions 도), /dav/ 의 DELETE, MOVE
+)
+
+// Validator 요청에서 꺼낸 자격 증명(키나 토큰)을 계정으로 바꿔
+// 자기가 모르는 형식이면 ErrUnknownCredential 을 돌려줘야 다음 검증기로 넘어가고, 다른 에러는 그 메시지로 바로 401 이야.
+type Validator interface {
+	Validate(credential string) (APIKey, error)
+}
+
+// ValidatorFunc 함수를 Validator 로
+type ValidatorFunc func(credential string) (APIKey, error)
+
+func (f ValidatorFunc) Validate(credential string) (APIKey, error) { return f(credential) }
+
+// ErrUnknownCredential 이 검증기가 알아보지 못한 자격 증명
+var ErrUnknownCredential = msg.New("알 수 없는 자격 증명입니다")
+
+// keyValidator 정적 API 키 (키 → 계정)
+type keyValidator map[string]APIKey
+
+func (keys keyValidator) Validate(credential string) (APIKey, error) {
+	// 맵을 키로 바로 찾지 않고 전부 상수 시간 비교 - 응답 시간으로 키를 한 글자씩 맞혀 보지 못하게
+	var found APIKey
+	ok := false
+	for k, a := range keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(credential)) == 1 {
+			found, ok = a, true
+		}
+	}
+	if !ok {
+		return APIKey{}, ErrUnknownCredential
+	}
+	return found, nil
+}
+
+// validators 지금 설정의 검증기 - 키, JWT, Config.Validators 순서
+func (c Config) validators() []Validator {
+	var vs []Validator
+	if len(c.APIKeys) > 0 {
+		vs = append(vs, keyValidator(c.APIKeys))
+	}
+	if c.JWTSecret != "" {
+		vs = append(vs, &jwtValidator{
+			secret: []byte(c.JWTSecret), issuer: c.JWTIssuer, audience: c.JWTAudience,
+			monthly: c.MonthlyQuota, storage: c.StorageQuota,
+		})
+	}
+	return append(vs, c.Validators...)
+}
+
+// authError 401/403 응답 본문
+type authError struct {
+	Error string `json:"error"`
+	Scope string `json:"scope,omitempty"` // 403 일 때 필요한 권한
+}
+
+// accountKey 인증된 계정을 요청 context 에 넣는 키
+type accountKey struct{}
+
+// requestKey X-API-Key 헤더, 없으면 Authorization: Bearer 의 키, 그것도 없으면 Basic 의 비밀번호
+// (WebDAV 를 마운트하는 탐색기/Finder 는 Basic 만 보내 - 사용자 이름은 아무거나, 비밀번호에 키나 토큰)
+func requestKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return ""
+}
+
+// account 요청의 계정 - authed 가 확인한 계정, 인증을 안 거쳤으면 "anonymous"
+func (s *Server) account(r *http.Request) APIKey {
+	if acct, ok := r.Context().Value(accountKey{}).(APIKey); ok {
+		return acct
+	}
+	live := s.live()
+	return APIKey{Name: anonymous, Monthly: live.MonthlyQuota, Storage: live.StorageQuota}
+}
+
+// authenticate 자격 증명을 검증기에 차례로 물어
+func authenticate(validators []Validator, credential string) (APIKey, error) {
+	if credential == "" {
+		return APIKey{}, msg.New("인증이 필요합니다 (X-API-Key 또는 Authorization: Bearer)")
+	}
+	for _, v := range validators {
+		acct, err := v.Validate(credential)
+		if errors.Is(err, ErrUnknownCredential) {
+			continue
+		}
+		return acct, err
+	}
+	return APIKey{}, msg.New("API 키나 토큰이 올바르지 않습니다")
+}
+
+// authed scope 권한이 필요한 핸들러 - 검증기가 없으면 그대로, 있으면 확인한 계정을 context 에 넣어 (scope 를 비우면 인증만)
+func (s *Server) authed(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return s.authedIf(scope, func() bool { return true }, next)
+}
+
+// authedIf required 가 false 면 자격 증명 없는 요청도 "anonymous" 로 통과 (자격 증명을 보냈으면 똑같이 확인해)
+func (s *Server) authedIf(scope string, required func() bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		validators := s.live().Validators
+		credential := requestKey(r)
+		if len(validators) == 0 || (credential == "" && !required()) {
+			next(w, r)
+			return
+		}
+		acct, err := authenticate(validators, credential)
+		if err != nil {
+			challenge := `Bearer realm="file-streaming"`
+			if credential != "" {
+				challenge += `, error="invalid_token"`
+			}
+			s.authFailed(w, r, http.StatusUnauthorized, challenge, authError{Error: err.Error()})
+			s.logger(r).WarnContext(r.Context(), "인증 실패", "path", r.URL.Path, "err", err)
+			return
+		}
+		if !acct.Allows(scope) {
+			challenge := fmt.Sprintf(`Bearer realm="file-streaming", error="insufficient_scope", scope=%q`, scope)
+			s.authFailed(w, r, http.StatusForbidden, challenge, authError{Error: msg.Sprintf("권한이 없습니다: %s", scope), Scope: scope})
+			s.logger(r).WarnContext(r.Context(), "권한 없음", "account", acct.Name, "path", r.URL.Path, "scope", scope)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), accountKey{}, acct)))
+	}
+}
+
+func (s *Server) authFailed(w http.ResponseWriter, r *http.Request, status int, challenge string, body authError) {
+	w.Header().Set("WWW-Authenticate", challenge)
+	if isDAV(r) {
+		// WebDAV 클라이언트는 Basic 을 보여야 키를 물어 봐 (웹 UI 의 fetch 에는 안 붙여 - 브라우저 로그인 창이 뜨지 않게)
+		w.Header().Add("WWW-Authenticate", `Basic realm="file-streaming", charset="UTF-8"`)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Allows 이 계정이 scope 를 할 수 있는지 (Scopes 를 비웠거나 scope 가 비었으면 true)
+func (a APIKey) Allows(scope string) bool {
+	return scope == "" || len(a.Scopes) == 0 || slices.Contains(a.Scopes, scope)
+}
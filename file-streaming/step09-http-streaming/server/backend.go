@@ -0,0 +1,304 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 파일을 두는 저장소 (Config.Backend, Config.BackendURL)
+// ⭐ 업로드/다운로드/목록/삭제/정적 서빙 핸들러는 업로드 디렉토리를 직접 만지지 않고 storage.Storage(Stat, List, Open, Create, Delete)에 물어 -
+// 기본은 UploadDir 을 루트로 한 storage.Dir 이고, storage.NewMemory() 나 storage.S3 를 끼우면 같은 핸들러가 그 저장소로 돌아.
+// 다운로드는 Open 이 돌려준 핸들을 Seek 해서 Range 를 처리하니, 직접 만든 저장소도 Open 이 io.Seeker 를 돌려줘야 해.
+// 하드 링크(DedupDir), 확장 속성 체크섬, 휴지통, 검색 색인, 압축 풀기(/api/extract, /upload-archive)처럼 실제 디렉토리가 있어야 하는 기능은 storage.Dir 일 때만이야 -
+// 다른 저장소에서 DedupDir 을 켜면 New 가 거절하고, SearchIndex 는 경고만 남기고 꺼(/api/search 404), /api/extract, /upload-archive 는 501, 삭제는 휴지통 없이 바로 지워.
+// EncryptionKeyFile 을 주면 어느 저장소든 storage.Encrypted 로 감싸 - 디렉토리여도 다른 저장소처럼 다뤄서 모든 업로드가 암호화를 거쳐.
+
+// 백엔드 주소 (Config.BackendURL)
+const (
+	BackendMemory = "memory" // storage.NewMemory - 재시작하면 비어
+	// "s3://bucket/prefix" - storage.NewS3FromEnv (키는 AWS_ACCESS_KEY_ID 같은 환경 변수, 엔드포인트는 Config.S3Endpoint)
+)
+
+// openBackend Config.Backend, 없으면 BackendURL, 그것도 없으면 UploadDir 디렉토리 (EncryptionKeyFile 이 있으면 암호화로 감싸서)
+func (c Config) openBackend() (storage.Storage, error) {
+	backend, err := c.baseBackend()
+	if err != nil || c.EncryptionKeyFile == "" {
+		return backend, err
+	}
+	text, err := os.ReadFile(c.EncryptionKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := storage.ParseKeys(string(text))
+	if err != nil {
+		return nil, msg.Errorf("암호화 키 파일 %s: %w", c.EncryptionKeyFile, err)
+	}
+	return storage.NewEncrypted(backend, keys...)
+}
+
+// baseBackend 암호화하기 전의 저장소
+func (c Config) baseBackend() (storage.Storage, error) {
+	switch {
+	case c.Backend != nil:
+		return c.Backend, nil
+	case c.BackendURL == "":
+		return storage.Dir(c.UploadDir), nil
+	}
+	return c.openURL(c.BackendURL)
+}
+
+// openURL 저장소 주소 - BackendMemory 또는 "s3://bucket/prefix"
+func (c Config) openURL(u string) (storage.Storage, error) {
+	switch {
+	case u == BackendMemory:
+		return storage.NewMemory(), nil
+	case strings.HasPrefix(u, "s3://"):
+		return storage.NewS3FromEnv(c.S3Endpoint, u)
+	}
+	return nil, msg.Errorf("알 수 없는 저장소입니다: %s (memory 또는 s3://bucket/prefix)", u)
+}
+
+// openTargets /api/copy 로 오갈 저장소 (Config.Targets 에 TargetURLs 를 열어서 더해) - 주소가 memory, s3:// 가 아니면 로컬 디렉토리
+func (c Config) openTargets() (map[string]storage.Storage, error) {
+	targets := make(map[string]storage.Storage, len(c.Targets)+len(c.TargetURLs))
+	for name, st := range c.Targets {
+		targets[name] = st
+	}
+	for name, u := range c.TargetURLs {
+		if _, ok := targets[name]; ok {
+			return nil, msg.Errorf("복사 대상 %q 가 Targets 와 TargetURLs 에 다 있습니다", name)
+		}
+		if u != BackendMemory && !strings.HasPrefix(u, "s3://") {
+			if err := os.MkdirAll(u, 0755); err != nil {
+				return nil, err
+			}
+			targets[name] = storage.Dir(u)
+			continue
+		}
+		st, err := c.openURL(u)
+		if err != nil {
+			return nil, msg.Errorf("복사 대상 %q: %w", name, err)
+		}
+		targets[name] = st
+	}
+	for name := range targets {
+		if name == "" || name == TargetUploads {
+			return nil, msg.Errorf("복사 대상 이름으로 %q 는 쓸 수 없습니다", name)
+		}
+	}
+	return targets, nil
+}
+
+// localDir 업로드가 로컬 디렉토리(storage.Dir)에 있는지 - 하드 링크, 확장 속성, 휴지통, 검색은 이때만
+func (s *Server) localDir() bool {
+	_, ok := s.backend.(storage.Dir)
+	return ok
+}
+
+// checkBackend 로컬 디렉토리에서만 되는 기능을 다른 저장소에서 켰는지 (디렉토리를 만들기 전에)
+func (c Config) checkBackend(backend storage.Storage) error {
+	if _, local := backend.(storage.Dir); local {
+		return nil
+	}
+	if c.DedupDir != "" {
+		return msg.New("중복 제거 저장소(DedupDir)는 암호화하지 않은 로컬 디렉토리 저장소에서만 쓸 수 있습니다")
+	}
+	return nil
+}
+
+// openUpload 다운로드할 파일과 그 정보 - 중복 제거 저장소에 있으면 색인을 따라 블롭을, 아니면 저장소의 파일을
+func (s *Server) openUpload(ctx context.Context, name string) (io.ReadSeekCloser, fs.FileInfo, error) {
+	if s.blobs != nil {
+		if f, err := s.blobs.Open(name); err == nil {
+			return statFile(f)
+		}
+	}
+	rc, err := s.backend.Open(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, ok := rc.(io.ReadSeekCloser) // Range 와 형식 추측 때문에 Seek 이 돼야 해
+	if !ok {
+		rc.Close()
+		return nil, nil, msg.Errorf("저장소(%T)의 파일이 Seek 을 지원하지 않습니다", s.backend)
+	}
+	return statFile(f)
+}
+
+// statFile f 의 Stat 이 있으면 그걸로 (열어 둔 핸들의 정보라 Stat 과 Open 사이에 바뀌어도 어긋나지 않아)
+func statFile(f io.ReadSeekCloser) (io.ReadSeekCloser, fs.FileInfo, error) {
+	st, ok := f.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		f.Close()
+		return nil, nil, msg.New("파일 정보를 가져올 수 없습니다")
+	}
+	info, err := st.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, nil, &fs.PathError{Op: "open", Path: info.Name(), Err: fs.ErrNotExist}
+	}
+	return f, info, nil
+}
+
+// uploadWriter savePart 가 받은 본문을 쓰는 곳 - commit 해야 name 으로 보이고, 그 전에 abort 하면 흔적 없이 사라져
+type uploadWriter interface {
+	io.Writer
+	// commit 다 쓴 내용(sha256 이 sum)을 name 으로
+	commit(r *http.Request, sum string) (dup bool, err error)
+	abort()
+}
+
+// newUpload name 으로 저장할 uploadWriter - 로컬 디렉토리면 옆의 임시 파일, 아니면 저장소의 Create
+// expected(미리 알려 준 sha256)의 내용이 중복 제거 저장소에 이미 있으면 아무것도 안 쓰는 blobUpload (dedup.go)
+func (s *Server) newUpload(r *http.Request, name, expected string) (uploadWriter, error) {
+	if s.blobs != nil && expected != "" {
+		// 본문을 받는 동안 그 내용의 마지막 이름이 지워져도 commit 에서 링크할 수 있게 블롭을 잡아 둬
+		if release, ok := s.blobs.Hold(expected); ok {
+			return &blobUpload{s: s, name: name, release: release}, nil
+		}
+	}
+	if !s.localDir() {
+		w, err := s.backend.Create(r.Context(), name)
+		if err != nil {
+			return nil, err
+		}
+		return &backendUpload{Writer: w, s: s, name: name}, nil
+	}
+	// ⭐ 임시 파일에 다 받은 뒤 rename - 받다가 끊겨도, 누가 그 파일을 내려받는 중이어도 예전 내용이 온전히 남아
+	// (storage.Dir.Create 도 같은 방식이지만, 체크섬 속성과 중복 제거는 rename 전의 임시 파일 경로가 있어야 해)
+	f, err := os.CreateTemp(s.cfg.UploadDir, "."+name+".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	f.Chmod(0644)
+	return &localUpload{File: f, s: s, name: name}, nil
+}
+
+// localUpload 업로드 디렉토리 안의 임시 파일
+type localUpload struct {
+	*os.File
+	s       *Server
+	name    string
+	renamed bool
+}
+
+func (u *localUpload) commit(r *http.Request, sum string) (bool, error) {
+	if err := u.File.Close(); err != nil {
+		return false, err
+	}
+	dup, err := u.s.commitLocal(r, u.File.Name(), u.name, sum)
+	u.renamed = err == nil
+	return dup, err
+}
+
+// commitLocal 업로드 디렉토리 안에 다 쓴 src(sha256 이 sum)를 name 으로 - 체크섬을 남기고 중복 제거 저장소에 넣거나 rename
+// src 가 name 자체여도 돼 (이미 옮긴 파일을 색인에만 넣어).
+func (s *Server) commitLocal(r *http.Request, src, name, sum string) (bool, error) {
+	s.storeChecksum(r, src, name, sum) // rename 전에 남겨서 파일이 보일 때는 이미 기록이 붙어 있어
+	return s.dedupe(name, src, sum)
+}
+
+func (u *localUpload) abort() {
+	if !u.renamed {
+		u.File.Close()
+		os.Remove(u.File.Name())
+	}
+}
+
+// blobUpload 이미 있는 내용이라 받은 바이트는 버리고, commit 에서 블롭을 링크만 해
+// commit 전에 saveFile 이 받은 내용의 sha256 이 expected 와 같은지 확인해.
+// 블롭은 newUpload 에서 잡아 두고 abort 에서 놓아 (saveFile 은 commit 한 뒤에도 abort 를 불러).
+type blobUpload struct {
+	s       *Server
+	name    string
+	release func()
+}
+
+func (u *blobUpload) Write(p []byte) (int, error) { return len(p), nil }
+
+func (u *blobUpload) commit(r *http.Request, sum string) (bool, error) {
+	return true, u.s.linkBlob(r, u.name, sum)
+}
+
+func (u *blobUpload) abort() { u.release() }
+
+// backendUpload 저장소의 Writer (Close 해야 보여)
+type backendUpload struct {
+	storage.Writer
+	s    *Server
+	name string
+	done bool
+}
+
+func (u *backendUpload) commit(r *http.Request, sum string) (bool, error) {
+	u.done = true
+	if err := u.Writer.Close(); err != nil {
+		return false, err
+	}
+	if info, err := u.s.backend.Stat(r.Context(), u.name); err == nil {
+		u.s.hashes.put(u.name, info.Size(), info.ModTime(), sum)
+	}
+	return false, nil
+}
+
+func (u *backendUpload) abort() {
+	if !u.done {
+		u.done = true
+		u.Writer.Abort()
+	}
+}
+
+// storeBackend 세션 디렉토리에 다 모은 이어 올리기 파일을 저장소의 name 으로 복사 (로컬 디렉토리가 아닐 때)
+func (s *Server) storeBackend(ctx context.Context, src, name string) error {
+	opts := streamio.CopyOptions{BufferSize: s.live().BufferSize}
+	if _, err := storage.CopyFile(ctx, storage.Local{}, src, s.backend, name, opts); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// opened openUpload 의 결과 - 없으면 404, 다른 에러면 500 을 쓰고 false
+func (s *Server) opened(w http.ResponseWriter, r *http.Request, name string, err error) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+		return false
+	}
+	s.logger(r).ErrorContext(r.Context(), "파일 열기 실패", "file", name, "err", err)
+	http.Error(w, msg.T("파일을 열 수 없습니다"), http.StatusInternalServerError)
+	return false
+}
+
+// staticHandler 로컬 디렉토리가 아닐 때의 /files/이름 - http.FileServer 처럼 Range, If-Modified-Since 는 되지만 디렉토리 목록은 없어
+func (s *Server) staticHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/files/")
+	name, ok := sanitizeFilename(rest)
+	if !ok || name != rest { // 업로드는 디렉토리 없이 한 층이야
+
+		http.NotFound(w, r)
+		return
+	}
+	file, info, err := s.openUpload(r.Context(), name)
+	if !s.opened(w, r, name, err) {
+		return
+	}
+	defer file.Close()
+	w.Header().Set("Content-Type", contentType(name, sniffHead(file)))
+	s.setValidators(w, name, info)
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
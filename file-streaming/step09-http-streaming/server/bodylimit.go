@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 업로드 본문 크기 제한 (Config.MaxUploadSize, 정책의 형식별 한도)
+// ⭐ 본문은 http.MaxBytesReader 로 읽으면서 끊어 - 디스크에 다 받아 놓고 나서 거절하지 않게.
+// 파일 하나짜리 본문(WebDAV PUT, 멀티파트 파트, 압축 풀기)은 Content-Length 가 이미 넘으면 읽기 전에 바로 거절하고,
+// 모르고 받다가 넘으면 그 자리에서 거절해. 어느 쪽이든 413 과 JSON 한 줄이라 클라이언트가 얼마나 줄여야 하는지 알 수 있어:
+//
+//	{"error":"업로드 크기 제한(131072 바이트)을 넘었습니다","limit":131072,"received":131073,"length":2097152}
+//
+// 413 을 보낸 뒤에는 net/http 가 남은 본문을 조금 읽어 버리고 연결을 닫아서, 보내던 클라이언트가 응답 대신 connection reset 을 받지 않아.
+// /upload 는 여러 파일을 한 폼에 보낼 수 있어서 미리 거절하지 않아 - 한도 앞까지 온 파일은 저장돼.
+
+// tooLargeError 413 응답 본문
+type tooLargeError struct {
+	Error    string `json:"error"`
+	Limit    int64  `json:"limit"`
+	Received int64  `json:"received,omitempty"` // 끊기 전까지 받은 바이트 (읽기 전에 거절했으면 빠져)
+	Length   int64  `json:"length,omitempty"`   // 클라이언트가 알린 크기 (Content-Length, Upload-Length 등 - 모르면 빠져)
+}
+
+// bodyTooLarge MaxBytesReader 가 끊은 에러에 받은 바이트를 붙인 것 (errors.As 로 *http.MaxBytesError 도 찾을 수 있어)
+type bodyTooLarge struct {
+	*http.MaxBytesError
+	Received int64
+	Length   int64
+}
+
+func (e *bodyTooLarge) Unwrap() error { return e.MaxBytesError }
+
+// tooLarge 미리 알린 크기 length 가 limit 을 넘었다는 에러 (uploadTooLarge 로 응답)
+func tooLarge(limit, length int64) error {
+	return &bodyTooLarge{MaxBytesError: &http.MaxBytesError{Limit: limit}, Length: length}
+}
+
+// limitedBody http.MaxBytesReader 가 끊으면 그때까지 받은 바이트를 에러에 붙여
+type limitedBody struct {
+	io.ReadCloser                 // http.MaxBytesReader
+	read          *countingReader // MaxBytesReader 아래에서 실제로 받은 만큼
+	length        int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if err != nil && errors.As(err, &maxErr) {
+		err = &bodyTooLarge{MaxBytesError: maxErr, Received: b.read.n, Length: b.length}
+	}
+	return n, err
+}
+
+// limitReader body 를 limit 바이트까지만 읽게 (length 는 알린 크기, 모르면 0 이하)
+func limitReader(w http.ResponseWriter, body io.ReadCloser, limit, length int64) io.ReadCloser {
+	read := &countingReader{ReadCloser: body}
+	return &limitedBody{ReadCloser: http.MaxBytesReader(w, read, limit), read: read, length: max(length, 0)}
+}
+
+// limitBody r.Body 를 limit 바이트까지만 (0 이하면 그대로)
+func limitBody(w http.ResponseWriter, r *http.Request, limit int64) {
+	if limit > 0 {
+		r.Body = limitReader(w, r.Body, limit, r.ContentLength)
+	}
+}
+
+// bodyTooLargeUpfront Content-Length 가 이미 limit 을 넘으면 본문을 읽지 않고 413 을 쓰고 true
+func bodyTooLargeUpfront(w http.ResponseWriter, r *http.Request, limit int64) bool {
+	if limit <= 0 || r.ContentLength <= limit {
+		return false
+	}
+	return uploadTooLarge(w, tooLarge(limit, r.ContentLength))
+}
+
+// uploadTooLarge 본문이 한도를 넘어서 끊긴 에러면 413 과 한도, 받은 바이트를 JSON 으로 응답하고 true
+func uploadTooLarge(w http.ResponseWriter, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+	body := tooLargeError{Error: msg.Sprintf("업로드 크기 제한(%d 바이트)을 넘었습니다", maxErr.Limit), Limit: maxErr.Limit}
+	var cut *bodyTooLarge
+	if errors.As(err, &cut) {
+		body.Received, body.Length = cut.Received, cut.Length
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(body)
+	return true
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 업로드하면서 잰 sha256
+// ⭐ /upload 는 본문을 디스크에 쓰는 흐름에 io.TeeReader 로 sha256 을 끼워서, 다 받은 뒤 파일을 다시 읽지 않고 해시가 나와.
+// 그 값을 파일의 확장 속성(fstree.XattrChecksums - streamctl scrub 이 읽는 그 기록)에 남기고 X-Content-SHA256 으로 돌려줘 -
+// 클라이언트는 자기가 보낸 파일의 해시와 비교하면 되고, /api/files 는 재시작 뒤에도 파일을 다시 읽지 않고 이 값을 써.
+// xattr 을 못 쓰는 파일시스템이면 기록만 빠지고(/api/files 가 처음 한 번 다시 재) 업로드는 그대로 성공이야.
+// 클라이언트가 X-Expected-SHA256 으로 해시를 미리 알려 주면 임시 파일에 다 받은 뒤 비교해서, 다르면 rename 하지 않고 지운 뒤 422 야 -
+// 전송 중에 깨진 파일이 예전 파일을 덮어쓰지 않아.
+
+const (
+	checksumHeader = "X-Content-SHA256"  // 저장한 파일의 sha256 (여러 파일이면 응답 순서대로 쉼표로)
+	expectedHeader = "X-Expected-SHA256" // 클라이언트가 기대하는 sha256 (여러 파일이면 파트 순서대로 쉼표로)
+)
+
+// checksumMismatch 받은 내용의 sha256 이 X-Expected-SHA256 과 다를 때 422 응답 본문
+type checksumMismatch struct {
+	Error    string `json:"error"`
+	File     string `json:"file"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"` // 서버가 받은 내용의 sha256
+}
+
+// expectedSums X-Expected-SHA256 의 값들 (소문자로) - 64자리 16진수가 아닌 게 있으면 에러
+func expectedSums(r *http.Request) ([]string, error) {
+	v := r.Header.Get(expectedHeader)
+	if v == "" {
+		return nil, nil
+	}
+	var sums []string
+	for _, sum := range strings.Split(v, ",") {
+		sum = strings.ToLower(strings.TrimSpace(sum))
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return nil, msg.Errorf("%s 는 64자리 16진수 sha256 이어야 합니다: %s", expectedHeader, sum)
+		}
+		sums = append(sums, sum)
+	}
+	return sums, nil
+}
+
+// checksumFailed 422 와 서버가 잰 해시
+func (s *Server) checksumFailed(w http.ResponseWriter, r *http.Request, name, expected, actual string) {
+	w.Header().Set(checksumHeader, actual)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(checksumMismatch{Error: msg.T("sha256 가 맞지 않습니다"), File: name, Expected: expected, Actual: actual})
+	s.logger(r).WarnContext(r.Context(), "업로드 sha256 불일치", "file", name, "expected", expected, "actual", actual)
+}
+
+// storeChecksum path(아직 rename 전 임시 파일)에 sum 을 기록하고 /api/files 캐시에도 넣어
+func (s *Server) storeChecksum(r *http.Request, path, name, sum string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	s.hashes.put(name, info.Size(), info.ModTime(), sum)
+	c := fstree.Checksum{SHA256: sum, Size: info.Size(), ModTime: info.ModTime(), Verified: time.Now()}
+	if err := fstree.XattrChecksums().Put(path, c); err != nil && !errors.Is(err, fstree.ErrXattrUnsupported) {
+		s.logger(r).DebugContext(r.Context(), "체크섬 속성을 남기지 못함", "file", name, "err", err)
+	}
+}
+
+// setChecksumHeader X-Content-SHA256
+func setChecksumHeader(w http.ResponseWriter, files []uploadedFile) {
+	sums := make([]string, 0, len(files))
+	for _, f := range files {
+		sums = append(sums, f.SHA256)
+	}
+	w.Header().Set(checksumHeader, strings.Join(sums, ", "))
+}
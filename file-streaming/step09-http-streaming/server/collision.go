@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 같은 이름의 파일이 이미 있을 때 (Config.Collision)
+// ⭐ 이름을 고르는 것과 그 이름을 잠그는 걸 한 번에 해 - 고른 뒤 잠그기 전에 다른 업로드가 같은 이름을 가져가지 않게.
+// 잠금은 같은 서버의 업로드/이어 올리기/삭제끼리만 통하니, 밖에서 디렉토리에 직접 쓰는 것까지 막지는 못해.
+const (
+	CollisionOverwrite = "overwrite" // 새 파일로 바꿔 (기본 - 받는 중에도 예전 파일은 온전해)
+	CollisionReject    = "reject"    // 409 로 거절
+	CollisionUUID      = "uuid"      // 이름-<uuid>.확장자 로 따로 저장
+	CollisionVersion   = "version"   // 이름-v2.확장자, 이름-v3.확장자 … 로 따로 저장
+)
+
+// maxVersions version 정책에서 찾아볼 번호의 끝
+const maxVersions = 10000
+
+// errNameTaken reject 정책인데 이미 있는 이름
+var errNameTaken = msg.New("이미 있는 파일 이름입니다")
+
+// claimName 정책에 따라 name 을 저장할 최종 이름을 고르고 그 경로를 잠가 (다 쓰고 unlock 을 꼭 불러)
+func (s *Server) claimName(name string) (final string, unlock func(), err error) {
+	policy := s.live().Collision
+	unlock = streamio.LockPath(s.uploadPath(name))
+	if policy == CollisionOverwrite || !s.exists(name) {
+		return name, unlock, nil
+	}
+	unlock()
+
+	switch policy {
+	case CollisionReject:
+		return "", nil, errNameTaken
+	case CollisionUUID:
+		for {
+			final = withSuffix(name, "-"+uuid.NewString())
+			if unlock, ok := s.tryClaim(final); ok {
+				return final, unlock, nil
+			}
+		}
+	default: // CollisionVersion
+		for v := 2; v <= maxVersions; v++ {
+			final = withSuffix(name, fmt.Sprintf("-v%d", v))
+			if unlock, ok := s.tryClaim(final); ok {
+				return final, unlock, nil
+			}
+		}
+		return "", nil, msg.Errorf("%s: 버전 %d 개를 다 썼습니다", name, maxVersions)
+	}
+}
+
+// tryClaim name 이 비어 있으면 잠근 채로 true
+func (s *Server) tryClaim(name string) (func(), bool) {
+	unlock := streamio.LockPath(s.uploadPath(name))
+	if s.exists(name) {
+		unlock()
+		return nil, false
+	}
+	return unlock, true
+}
+
+func (s *Server) exists(name string) bool {
+	if s.localDir() {
+		_, err := os.Lstat(s.uploadPath(name)) // 깨진 심볼릭 링크도 자리를 차지해
+		return err == nil
+	}
+	_, err := s.backend.Stat(context.Background(), name)
+	return err == nil
+}
+
+// withSuffix 확장자 앞에 suffix 를 끼워 - "a.tar.gz" 는 "a-v2.tar.gz" 처럼 .tar 도 확장자로 쳐
+func withSuffix(name, suffix string) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if inner := path.Ext(stem); strings.EqualFold(inner, ".tar") {
+		stem, ext = strings.TrimSuffix(stem, inner), inner+ext
+	}
+	if stem == "" { // ".bashrc" 같은 이름은 통째로 이름
+		stem, ext = name, ""
+	}
+	return stem + suffix + ext
+}
+
+// nameConflict claimName 이 실패했을 때 응답 - reject 정책이면 409
+func (s *Server) nameConflict(w http.ResponseWriter, r *http.Request, name string, err error) {
+	if errors.Is(err, errNameTaken) {
+		http.Error(w, msg.Sprintf("이미 있는 파일 이름입니다: %s", name), http.StatusConflict)
+		return
+	}
+	s.logger(r).ErrorContext(r.Context(), "저장할 이름을 고르지 못함", "file", name, "err", err)
+	http.Error(w, msg.T("저장할 이름을 고르지 못했습니다"), http.StatusInternalServerError)
+}
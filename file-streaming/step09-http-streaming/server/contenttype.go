@@ -0,0 +1,50 @@
+package server
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// 다운로드 응답의 Content-Type 과 Content-Disposition
+// ⭐ 확장자로 먼저 찾고(.pdf, .mp4 …), 모르는 확장자면 앞 512 바이트를 http.DetectContentType 으로 봐 - http.ServeContent 와 같은 순서야.
+// ?inline=1 이면 브라우저가 저장 창 대신 바로 열어 (이미지, PDF, 동영상 미리보기).
+// 다만 HTML/SVG/XML 처럼 스크립트를 돌릴 수 있는 형식을 inline 으로 열면 올린 사람의 스크립트가 이 서버 출처로 돌아서,
+// 그런 형식은 inline 을 달라고 해도 attachment 로 내보내고, 브라우저가 형식을 다시 추측하지 않게 nosniff 를 붙여.
+
+// activeTypes inline 으로 열어 주지 않는 형식 (브라우저가 스크립트를 실행할 수 있어)
+var activeTypes = []string{
+	"text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml",
+	"text/javascript", "application/javascript",
+}
+
+// contentType name 의 확장자, 모르면 sniff(파일 앞부분)로 본 형식
+func contentType(name string, sniff []byte) string {
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(sniff)
+}
+
+// setDisposition Content-Disposition (?inline=1 이고 안전한 형식이면 inline) 과 nosniff
+// 파일명은 mime.FormatMediaType 이 따옴표나 RFC 2231 의 filename* 로 감싸서 공백, 한글도 그대로 와.
+func setDisposition(w http.ResponseWriter, r *http.Request, name, ct string) {
+	disposition := "attachment"
+	if v := r.URL.Query().Get("inline"); (v == "1" || v == "true") && !isActive(ct) {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+}
+
+func isActive(ct string) bool {
+	mediaType, _, _ := strings.Cut(ct, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, t := range activeTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return strings.HasSuffix(mediaType, "+xml")
+}
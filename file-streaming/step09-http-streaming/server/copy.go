@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 저장소 사이 복사 (Config.Targets, Config.TargetURLs)
+// ⭐ 업로드 저장소와 설정해 둔 다른 저장소(S3 버킷, 백업 디렉토리 …) 사이에서 파일 하나를 서버가 직접 스트리밍해 -
+// 옮기려고 클라이언트가 내려받았다가 다시 올릴 필요가 없어:
+//
+//	POST /api/copy?file=이름&to=archive                     → uploads 에서 archive 로
+//	POST /api/copy?file=이름&from=archive&to=uploads&as=새이름 → 되돌려 받기 (&overwrite=1 이면 덮어써)
+//	→ 200 {"file", "from", "to", "dest", "bytes", "sha256"}
+//
+// 읽는 흐름에 sha256 을 끼워 재고, 다 쓴 뒤 대상에서 다시 읽어서 같은지 확인해 - 다르면 대상 파일을 지우고 502.
+// 진행률은 업로드처럼 streamio.Hooks 로 /api/events 에 나가 (?id= 로 정한 ID, 아니면 파일명).
+// 대상에 같은 이름이 있으면 ?overwrite=1 없이는 409. 업로드 저장소가 암호화돼 있으면 풀어서 내보내 (대상은 평범한 파일로 받아).
+
+// TargetUploads /api/copy 의 from/to 에서 업로드 저장소 (Config.Targets 의 이름으로는 못 써)
+const TargetUploads = "uploads"
+
+// errCopyMismatch 다 쓴 대상 파일을 다시 읽은 sha256 이 원본과 달라
+var errCopyMismatch = msg.New("복사한 파일의 sha256 이 원본과 다릅니다")
+
+// copyResponse /api/copy 응답
+type copyResponse struct {
+	File   string `json:"file"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Dest   string `json:"dest"` // 대상 저장소에서의 이름 (?as=, 없으면 file)
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// target 이름 → 저장소 (TargetUploads 면 업로드 저장소)
+func (s *Server) target(name string) (storage.Storage, bool) {
+	if name == TargetUploads {
+		return s.backend, true
+	}
+	st, ok := s.targets[name]
+	return st, ok
+}
+
+// targetNames 고를 수 있는 저장소 이름 (에러 메시지용)
+func (s *Server) targetNames() string {
+	names := []string{TargetUploads}
+	for name := range s.targets {
+		names = append(names, name)
+	}
+	slices.Sort(names[1:])
+	return strings.Join(names, ", ")
+}
+
+// copyHandler POST /api/copy?file=&from=&to=&as=&overwrite=
+func (s *Server) copyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	name, ok := sanitizeFilename(q.Get("file"))
+	if !ok || name != q.Get("file") {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	dest := name
+	if q.Has("as") {
+		if dest, ok = sanitizeFilename(q.Get("as")); !ok || dest != q.Get("as") {
+			http.Error(w, msg.T("새 파일명(as)이 잘못됐습니다"), http.StatusBadRequest)
+			return
+		}
+	}
+	from, to := q.Get("from"), q.Get("to")
+	if from == "" {
+		from = TargetUploads
+	}
+	src, srcOK := s.target(from)
+	dst, dstOK := s.target(to)
+	if !srcOK || !dstOK {
+		http.Error(w, msg.Sprintf("from, to 는 이 중 하나여야 합니다: %s", s.targetNames()), http.StatusBadRequest)
+		return
+	}
+	if from == to && name == dest {
+		http.Error(w, msg.T("원본과 대상이 같습니다"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	info, err := src.Stat(ctx, name)
+	if err != nil || !info.Mode().IsRegular() {
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+			return
+		}
+		s.logger(r).ErrorContext(ctx, "복사할 파일 정보를 읽지 못함", "from", from, "file", name, "err", err)
+		http.Error(w, msg.T("파일 정보를 읽을 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+	acct := s.account(r)
+	if to == TargetUploads {
+		defer streamio.LockPath(s.uploadPath(dest))()
+		if room := s.storageRoom(acct, dest); room >= 0 && info.Size() > room {
+			s.storageFull(w, r, acct, dest, info.Size())
+			return
+		}
+	}
+	if _, err := dst.Stat(ctx, dest); err == nil && q.Get("overwrite") != "1" {
+		http.Error(w, errDestExists.Error()+": "+to+"/"+dest, http.StatusConflict)
+		return
+	}
+
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferCopy, dest)}
+	xfer := streamio.TransferInfo{ID: uploadID(r, name), Src: from + "/" + name, Dst: to + "/" + dest, Size: info.Size()}
+	n, sum, err := copyVerified(ctx, src, name, dst, dest, xfer, opts)
+	switch {
+	case errors.Is(err, errCopyMismatch):
+		s.logger(r).ErrorContext(ctx, "복사 검증 실패", "from", from, "to", to, "file", name, "dest", dest, "err", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	case err != nil:
+		if ctx.Err() == nil {
+			s.logger(r).ErrorContext(ctx, "저장소 사이 복사 실패", "from", from, "to", to, "file", name, "dest", dest, "err", err)
+			http.Error(w, msg.T("복사 실패"), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if to == TargetUploads {
+		s.copied(r, dest, acct, n, sum)
+	}
+	s.logger(r).InfoContext(ctx, "저장소 사이 복사", "from", from, "to", to, "file", name, "dest", dest, "bytes", n, "sha256", sum)
+	w.Header().Set(checksumHeader, sum)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(copyResponse{File: name, From: from, To: to, Dest: dest, Bytes: n, SHA256: sum})
+}
+
+// copied 업로드 저장소로 복사해 온 dest 를 업로드처럼 기록 (중복 제거 색인, 저장 공간, 만료, sha256, 검색 색인, 썸네일)
+func (s *Server) copied(r *http.Request, dest string, acct APIKey, n int64, sum string) {
+	if s.blobs != nil {
+		// 예전 이름이 가리키던 블롭 대신 복사한 내용으로 (다운로드는 색인을 먼저 봐)
+		if _, err := s.dedupe(dest, s.uploadPath(dest), sum); err != nil {
+			s.logger(r).ErrorContext(r.Context(), "중복 제거 저장소에 넣지 못함 (평범한 파일로 둠)", "file", dest, "err", err)
+		}
+	}
+	s.storePut(r, dest, acct.Name, n)
+	// 덮어썼으면 예전 파일의 만료는 따라오지 않아 - 복사해 온 건 안 지워
+	if err := s.expiry.remove(dest); err != nil {
+		s.logger(r).ErrorContext(r.Context(), "만료 기록 실패", "file", dest, "err", err)
+	}
+	if info, err := s.backend.Stat(r.Context(), dest); err == nil {
+		s.hashes.put(dest, info.Size(), info.ModTime(), sum)
+	}
+	s.queueIndex(s.uploadPath(dest), false)
+	s.queueThumb(dest)
+}
+
+// copyVerified src 의 name 을 dst 의 dest 로 흘리면서 sha256 을 재고, 다 쓴 뒤 dest 를 다시 읽어 같은지 확인
+// (다르면 dest 를 지우고 errCopyMismatch - 쓰다 실패하면 Abort 라 반쪽짜리는 안 남아)
+func copyVerified(ctx context.Context, src storage.Storage, name string, dst storage.Storage, dest string, info streamio.TransferInfo, opts streamio.CopyOptions) (int64, string, error) {
+	in, err := src.Open(ctx, name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+	out, err := dst.Create(ctx, dest)
+	if err != nil {
+		return 0, "", err
+	}
+	hooks := opts.Hooks
+	opts.Hooks = progressOnly{hooks}
+	hooks.OnStart(info)
+	start := time.Now()
+	h := sha256.New()
+	n, err := streamio.Copy(ctx, out, io.TeeReader(in, h), info, opts)
+	if err != nil {
+		out.Abort()
+		hooks.OnError(info, err)
+		return n, "", err
+	}
+	if err := out.Close(); err != nil {
+		hooks.OnError(info, err)
+		return n, "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	got, err := storageSHA256(ctx, dst, dest)
+	if err == nil && got != sum {
+		err = errCopyMismatch
+		if derr := dst.Delete(context.WithoutCancel(ctx), dest); derr != nil {
+			err = errors.Join(err, derr)
+		}
+	}
+	if err != nil {
+		hooks.OnError(info, err)
+		return n, sum, err
+	}
+	hooks.OnComplete(info, n, time.Since(start))
+	return n, sum, nil
+}
+
+// storageSHA256 st 의 name 을 처음부터 읽은 sha256
+func storageSHA256(ctx context.Context, st storage.Storage, name string) (string, error) {
+	rc, err := st.Open(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// progressOnly 진행률만 넘기는 훅 - 시작과 끝은 검증까지 마친 copyVerified 가 알려
+type progressOnly struct{ streamio.Hooks }
+
+func (progressOnly) OnStart(streamio.TransferInfo)                          {}
+func (progressOnly) OnComplete(streamio.TransferInfo, int64, time.Duration) {}
+func (progressOnly) OnError(streamio.TransferInfo, error)                   {}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// CORS (Config.CORSOrigins)
+// ⭐ 브라우저는 다른 출처(https://app.example.com 에서 연 페이지)의 fetch 응답을 서버가 Access-Control-Allow-Origin 으로 허락해야 보여 줘.
+// PUT, PATCH, DELETE 나 Authorization 같은 헤더를 붙인 요청은 먼저 OPTIONS 로 물어봐(preflight) - 자격 증명 없이 오니까 인증보다 앞에서 204 로 답해.
+// 허락하지 않은 출처의 preflight 는 403, 보통 요청은 CORS 헤더 없이 그대로 보내 - 브라우저가 막아.
+// JS 가 읽을 수 있는 응답 헤더는 몇 개뿐이라 이어받기와 검증에 쓰는 Content-Range, X-Content-SHA256, Upload-Offset … 을 Expose 로 열어 둬.
+// Origin 이 없는 요청(curl, 같은 출처)은 손대지 않아. 설정은 SIGHUP 으로 바로 바뀌어.
+
+var (
+	// defaultCORSMethods CORSMethods 를 비웠을 때
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	// defaultCORSExpose CORSExpose 를 비웠을 때 - 다운로드(Range, 검증), 업로드 결과, 이어 올리기(tus), 재시도에 쓰는 헤더
+	defaultCORSExpose = []string{
+		"Content-Length", "Content-Range", "Content-Disposition", "Content-Location", "Accept-Ranges", "ETag", "Last-Modified",
+		"Location", "Retry-After", checksumHeader, expiresHeader, "X-Request-ID",
+		"Tus-Resumable", "Upload-Offset", "Upload-Length", "Upload-Expires",
+	}
+)
+
+// corsPolicy Config 의 CORS 설정을 응답 헤더 값으로 미리 만들어 둔 것
+type corsPolicy struct {
+	origins []string // "*", "https://app.example.com", "https://*.example.com"
+	methods string   // Access-Control-Allow-Methods
+	headers string   // Access-Control-Allow-Headers (비우면 preflight 가 물어본 그대로)
+	expose  string   // Access-Control-Expose-Headers
+	maxAge  string   // Access-Control-Max-Age (비우면 안 보내)
+}
+
+// corsPolicy CORSOrigins 를 안 줬으면 nil
+func (c Config) corsPolicy() *corsPolicy {
+	if len(c.CORSOrigins) == 0 {
+		return nil
+	}
+	p := &corsPolicy{
+		methods: strings.Join(defaultCORSMethods, ", "),
+		headers: strings.Join(c.CORSHeaders, ", "),
+		expose:  strings.Join(defaultCORSExpose, ", "),
+	}
+	for _, o := range c.CORSOrigins {
+		p.origins = append(p.origins, strings.ToLower(strings.TrimSuffix(o, "/")))
+	}
+	if len(c.CORSMethods) > 0 {
+		p.methods = strings.ToUpper(strings.Join(c.CORSMethods, ", "))
+	}
+	if len(c.CORSExpose) > 0 {
+		p.expose = strings.Join(c.CORSExpose, ", ")
+	}
+	if c.CORSMaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(c.CORSMaxAge.Seconds()))
+	}
+	return p
+}
+
+// allow origin 을 허락하면 Access-Control-Allow-Origin 값 ("*" 를 줬으면 "*", 아니면 origin 그대로)
+func (p *corsPolicy) allow(origin string) (string, bool) {
+	lower := strings.ToLower(origin)
+	for _, o := range p.origins {
+		if o == "*" {
+			return "*", true
+		}
+		if o == lower {
+			return origin, true
+		}
+		// https://*.example.com → https://a.example.com, https://a.b.example.com (example.com 자체는 아니야)
+		if scheme, suffix, ok := strings.Cut(o, "://*."); ok {
+			host, found := strings.CutPrefix(lower, scheme+"://")
+			if found && strings.HasSuffix(host, "."+suffix) && len(host) > len(suffix)+1 {
+				return origin, true
+			}
+		}
+	}
+	return "", false
+}
+
+// cors CORSOrigins 를 줬으면 Origin 이 붙은 요청에 CORS 헤더를 달고 preflight 는 여기서 끝내
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := s.live().CORS
+		origin := r.Header.Get("Origin")
+		if p == nil || origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// WebDAV 클라이언트의 OPTIONS 는 Access-Control-Request-Method 가 없어서 그대로 /dav 로 가
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		allow, ok := p.allow(origin)
+		if !ok {
+			if preflight {
+				s.logger(r).DebugContext(r.Context(), "허락하지 않은 출처의 preflight", "origin", origin)
+				http.Error(w, msg.Sprintf("허락하지 않은 출처입니다: %s", origin), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		h.Set("Access-Control-Allow-Origin", allow)
+		if !preflight {
+			h.Set("Access-Control-Expose-Headers", p.expose)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", p.methods)
+		headers := p.headers
+		if headers == "" {
+			headers = r.Header.Get("Access-Control-Request-Headers")
+		}
+		if headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+		if p.maxAge != "" {
+			h.Set("Access-Control-Max-Age", p.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// splitList "a, b,,c" → [a b c] (쉼표로 적는 설정 값)
+func splitList(v string) []string {
+	var out []string
+	for item := range strings.SplitSeq(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,331 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/webdav"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// WebDAV (Config.WebDAV)
+// ⭐ 업로드 디렉토리를 /dav/ 에 WebDAV 로 열어서 Windows 탐색기(네트워크 드라이브 연결), macOS Finder(서버에 연결), davfs2, rclone 이 드라이브처럼 마운트해:
+//
+//	read-only    GET, HEAD, OPTIONS, PROPFIND                 → download 권한
+//	read-write   + PUT, COPY, LOCK, UNLOCK, PROPPATCH         → upload 권한
+//	             + DELETE, MOVE                                → delete 권한 (감사 로그에 남아)
+//
+// 읽기는 golang.org/x/net/webdav 가 하고, 쓰기는 가로채서 /upload, /api/files, /api/copy 와 같은 길로 보내 -
+// 임시 파일에 받고 rename, 검사기, 저장 공간 한도, sha256 기록, 중복 제거, 검색 색인, 썸네일, 휴지통이 그대로 따라가.
+// 업로드는 한 층이라 폴더는 못 만들고(MKCOL 403), 점으로 시작하는 이름(.thumbs, 받는 중인 임시 파일, Finder 의 ._ 파일)은 안 보이고 쓸 수도 없어.
+// 인증을 켰으면 Basic 의 비밀번호에 API 키나 JWT 를 넣어 (사용자 이름은 아무거나). 잠금(LOCK)은 메모리에만 두고, 가로챈 쓰기는 잠금을 확인하지 않아.
+// 파일을 바로 열어야 해서 로컬 디렉토리 저장소에서만 돼.
+
+// Config.WebDAV 값
+const (
+	WebDAVOff       = "off" // 기본 (비워도 끔)
+	WebDAVReadOnly  = "read-only"
+	WebDAVReadWrite = "read-write"
+)
+
+const davPrefix = "/dav"
+
+// isDAV /dav 아래 요청인지
+func isDAV(r *http.Request) bool {
+	return r.URL.Path == davPrefix || strings.HasPrefix(r.URL.Path, davPrefix+"/")
+}
+
+// davHandler /dav/ - 메서드마다 필요한 권한이 달라서 authed 를 여기서 골라
+func (s *Server) davHandler() http.HandlerFunc {
+	dav := &webdav.Handler{
+		Prefix:     davPrefix,
+		FileSystem: davFS(s.cfg.UploadDir),
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				s.logger(r).DebugContext(r.Context(), "WebDAV 요청 실패", "method", r.Method, "path", r.URL.Path, "err", err)
+			}
+		},
+	}
+	store := func(w http.ResponseWriter, r *http.Request) { s.davWrite(w, r, dav) }
+	read := chain(dav.ServeHTTP, s.requireScope(ScopeDownload), s.metered)
+	write := chain(store, s.requireScope(ScopeUpload), s.metered)
+	remove := chain(store, s.requireScope(ScopeDelete))
+	return func(w http.ResponseWriter, r *http.Request) {
+		mode := s.live().WebDAV
+		if mode == "" || mode == WebDAVOff || !s.localDir() {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+			read(w, r)
+		case http.MethodPut, "COPY", "LOCK", "UNLOCK", "PROPPATCH", "MKCOL", http.MethodDelete, "MOVE":
+			if mode != WebDAVReadWrite {
+				http.Error(w, msg.T("WebDAV 는 읽기 전용입니다"), http.StatusForbidden)
+				return
+			}
+			if r.Method == http.MethodDelete || r.Method == "MOVE" {
+				remove(w, r)
+				return
+			}
+			write(w, r)
+		default:
+			http.Error(w, msg.T("지원하지 않는 메서드입니다"), http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// davWrite 쓰기 메서드 - 잠금과 속성은 webdav 에 맡기고, 파일을 바꾸는 건 서버의 업로드/삭제/이름 바꾸기/복사로
+func (s *Server) davWrite(w http.ResponseWriter, r *http.Request, dav *webdav.Handler) {
+	switch r.Method {
+	case "MKCOL":
+		http.Error(w, msg.T("폴더는 만들 수 없습니다 (업로드 디렉토리는 한 층입니다)"), http.StatusForbidden)
+		return
+	case "UNLOCK", "PROPPATCH":
+		dav.ServeHTTP(w, r)
+		return
+	}
+	name, ok := davName(r.URL.Path)
+	if !ok {
+		http.Error(w, msg.T("쓸 수 없는 이름입니다 (폴더나 점으로 시작하는 이름은 안 됩니다)"), http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		s.davPut(w, r, name)
+	case "LOCK":
+		// 없는 이름을 잠그면 빈 파일로 자리를 잡아 (Windows 는 PUT 전에 이렇게 해) - webdav 가 직접 만들지 않게 업로드 길로
+		unlock := streamio.LockPath(s.uploadPath(name))
+		if !s.exists(name) {
+			if _, ok := s.saveFile(w, r, name, name, http.NoBody, s.account(r), ""); !ok {
+				unlock()
+				return
+			}
+		}
+		unlock()
+		dav.ServeHTTP(w, r)
+	case http.MethodDelete:
+		trashID, err := s.deleteFile(r, name)
+		s.audit(w, r, auditEntry{Action: AuditDelete, File: name, TrashID: trashID, Status: deleteStatus(err)}, err)
+		if s.deleted(w, r, name, err) {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case "COPY", "MOVE":
+		s.davCopyMove(w, r, name)
+	}
+}
+
+// davPut 요청 본문을 name 으로 (있으면 덮어써 - WebDAV 의 PUT 은 그 경로에 쓰는 거라 Collision 정책은 안 따라)
+func (s *Server) davPut(w http.ResponseWriter, r *http.Request, name string) {
+	limit := s.live().MaxUploadSize
+	if bodyTooLargeUpfront(w, r, limit) {
+		return
+	}
+	limitBody(w, r, limit)
+	s.throttleBody(r)
+	defer streamio.LockPath(s.uploadPath(name))()
+	existed := s.exists(name)
+	acct := s.account(r)
+	if room := s.storageRoom(acct, name); room >= 0 && r.ContentLength > room {
+		s.storageFull(w, r, acct, name, r.ContentLength)
+		return
+	}
+	f, ok := s.saveFile(w, r, name, name, r.Body, acct, "")
+	if !ok {
+		return
+	}
+	w.Header().Set(checksumHeader, f.SHA256)
+	w.WriteHeader(davStatus(existed))
+}
+
+// davCopyMove Destination 헤더의 이름으로 복사(COPY)하거나 이름을 바꿔(MOVE) - Overwrite: F 면 있는 이름에 412
+func (s *Server) davCopyMove(w http.ResponseWriter, r *http.Request, name string) {
+	u, err := url.Parse(r.Header.Get("Destination"))
+	if err != nil || !strings.HasPrefix(u.Path, davPrefix+"/") {
+		http.Error(w, msg.T("Destination 이 잘못됐습니다"), http.StatusBadRequest)
+		return
+	}
+	dest, ok := davName(u.Path)
+	if !ok || dest == name {
+		http.Error(w, msg.T("쓸 수 없는 Destination 입니다"), http.StatusForbidden)
+		return
+	}
+	if !s.exists(name) {
+		if r.Method == "MOVE" {
+			s.audit(w, r, auditEntry{Action: AuditRename, File: name, To: dest, Status: http.StatusNotFound}, os.ErrNotExist)
+		}
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+		return
+	}
+	overwrite := r.Header.Get("Overwrite") != "F"
+	if r.Method == "COPY" {
+		s.davCopy(w, r, name, dest, overwrite)
+		return
+	}
+
+	existed := s.exists(dest)
+	if existed && !overwrite {
+		http.Error(w, errDestExists.Error()+": "+dest, http.StatusPreconditionFailed)
+		return
+	}
+	if existed {
+		// 덮어쓰는 파일은 먼저 휴지통으로 (rename 은 있는 이름을 덮지 않아)
+		trashID, err := s.deleteFile(r, dest)
+		s.audit(w, r, auditEntry{Action: AuditDelete, File: dest, TrashID: trashID, Status: deleteStatus(err)}, err)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.deleted(w, r, dest, err)
+			return
+		}
+	}
+	err = s.rename(r.Context(), name, dest)
+	status := davStatus(existed)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, errDestExists): // 지운 사이에 다른 요청이 그 이름에 올렸어
+		status = http.StatusPreconditionFailed
+	case err != nil:
+		status = http.StatusInternalServerError
+	}
+	s.audit(w, r, auditEntry{Action: AuditRename, File: name, To: dest, Status: status}, err)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			s.logger(r).ErrorContext(r.Context(), "이름 바꾸기 실패", "file", name, "to", dest, "err", err)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	s.renamed(r, name, dest)
+	w.WriteHeader(status)
+}
+
+// davCopy /api/copy 처럼 sha256 을 재면서 복사하고 다시 읽어 확인
+func (s *Server) davCopy(w http.ResponseWriter, r *http.Request, name, dest string, overwrite bool) {
+	ctx := r.Context()
+	defer streamio.LockPath(s.uploadPath(dest))()
+	existed := s.exists(dest)
+	if existed && !overwrite {
+		http.Error(w, errDestExists.Error()+": "+dest, http.StatusPreconditionFailed)
+		return
+	}
+	info, err := s.backend.Stat(ctx, name)
+	if err != nil {
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+		return
+	}
+	acct := s.account(r)
+	if room := s.storageRoom(acct, dest); room >= 0 && info.Size() > room {
+		s.storageFull(w, r, acct, dest, info.Size())
+		return
+	}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferCopy, dest)}
+	xfer := streamio.TransferInfo{ID: uploadID(r, dest), Src: s.uploadPath(name), Dst: s.uploadPath(dest), Size: info.Size()}
+	n, sum, err := copyVerified(ctx, s.backend, name, s.backend, dest, xfer, opts)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger(r).ErrorContext(ctx, "WebDAV 복사 실패", "file", name, "dest", dest, "err", err)
+			http.Error(w, msg.T("복사 실패"), http.StatusInternalServerError)
+		}
+		return
+	}
+	s.copied(r, dest, acct, n, sum)
+	s.logger(r).InfoContext(ctx, "WebDAV 복사", "file", name, "dest", dest, "bytes", n, "sha256", sum)
+	w.Header().Set(checksumHeader, sum)
+	w.WriteHeader(davStatus(existed))
+}
+
+// davStatus 새로 만들었으면 201, 있던 걸 바꿨으면 204
+func davStatus(existed bool) int {
+	if existed {
+		return http.StatusNoContent
+	}
+	return http.StatusCreated
+}
+
+// davName /dav/<이름> 의 파일 이름 - 한 층이고 점으로 시작하지 않아야 해
+func davName(urlPath string) (string, bool) {
+	return visibleName(strings.TrimPrefix(strings.TrimPrefix(urlPath, davPrefix), "/"))
+}
+
+// visibleName 밖(WebDAV, SFTP)에서 보이는 이름인지 - sanitizeFilename 을 거쳐도 그대로고 점으로 시작하지 않아야 해
+func visibleName(rest string) (string, bool) {
+	name, ok := sanitizeFilename(rest)
+	return name, ok && name == rest && !strings.HasPrefix(name, ".")
+}
+
+// davFS 업로드 디렉토리를 webdav.FileSystem 으로 - 읽기만 (쓰기는 davWrite 가 가로채), 한 층, 점으로 시작하는 이름은 없는 셈
+type davFS string
+
+// resolve webdav 의 이름("/", "/a.txt")을 디스크 경로로
+func (d davFS) resolve(name string) (string, error) {
+	if name == "" || name == "/" {
+		return string(d), nil
+	}
+	base, ok := visibleName(strings.TrimPrefix(name, "/"))
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	return filepath.Join(string(d), base), nil
+}
+
+func (d davFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	p, err := d.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(p)
+	if err == nil && p != string(d) && !fi.Mode().IsRegular() {
+		return nil, os.ErrNotExist
+	}
+	return fi, err
+}
+
+func (d davFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, os.ErrPermission
+	}
+	p, err := d.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	if p == string(d) {
+		return davDir{f}, nil
+	}
+	if fi, err := f.Stat(); err != nil || !fi.Mode().IsRegular() {
+		f.Close()
+		return nil, os.ErrNotExist
+	}
+	return f, nil
+}
+
+func (davFS) Mkdir(context.Context, string, os.FileMode) error { return os.ErrPermission }
+func (davFS) RemoveAll(context.Context, string) error          { return os.ErrPermission }
+func (davFS) Rename(context.Context, string, string) error     { return os.ErrPermission }
+
+// davDir 업로드 디렉토리 - 목록에서 점으로 시작하는 이름과 파일이 아닌 것(.thumbs 같은 디렉토리)을 빼
+type davDir struct{ *os.File }
+
+func (d davDir) Readdir(count int) ([]fs.FileInfo, error) {
+	infos, err := d.File.Readdir(count)
+	kept := infos[:0]
+	for _, fi := range infos {
+		if _, ok := visibleName(fi.Name()); ok && fi.Mode().IsRegular() {
+			kept = append(kept, fi)
+		}
+	}
+	return kept, err
+}
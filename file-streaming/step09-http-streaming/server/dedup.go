@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 중복 제거 저장 (Config.DedupDir)
+// ⭐ 다 받은 파일을 sha256 으로 cas 저장소에 한 벌만 두고, 업로드 디렉토리의 이름은 그 블롭의 하드 링크로 바꿔 -
+// 같은 내용을 여러 이름(또는 여러 계정)이 올려도 디스크는 한 벌이고, 목록/정적 서빙/검색은 디렉토리를 그대로 읽어서 바뀌는 게 없어.
+// 다운로드는 이름 → 해시 색인을 따라 블롭을 열고, 삭제는 색인에서 참조를 하나 빼서 0 이 되면 블롭도 지워 (휴지통의 사본은 같은 inode 라 남아).
+// /upload 는 받으면서 잰 해시를 그대로 쓰고, 이어 올리기는 다 모은 뒤 한 번 더 읽어서 재. /api/extract 로 푼 파일은 평범한 파일이야.
+//
+// ⭐ 클라이언트가 sha256 을 미리 알려 주고(X-Expected-SHA256, streamctl send 의 offer) 저장소에 그 내용이 이미 있으면 디스크에 아예 안 써 -
+// 본문은 받으면서 해시만 재서 맞는지 확인하고(다르면 평소처럼 422), 맞으면 있던 블롭을 name 으로 링크해서 200 과 deduplicated:true.
+// 해시를 안 믿고 끝까지 받는 건, 남의 파일 해시만 알고 내용을 가져가는 걸 막으려고야 (받는 대역폭은 그대로, 디스크 쓰기만 아껴).
+
+// dedupe src(업로드 디렉토리 안의 다 받은 파일)를 name 의 내용으로 - 저장소를 안 쓰면 그냥 rename (name 의 경로 잠금 안에서)
+func (s *Server) dedupe(name, src, sum string) (dup bool, err error) {
+	if src != s.uploadPath(name) {
+		// 덮어쓰기 직전 - Versions 를 켰으면 지금 내용을 버전으로 (versions.go)
+		if err := s.keepVersion(name); err != nil {
+			return false, err
+		}
+	}
+	if s.blobs == nil {
+		if src == s.uploadPath(name) {
+			return false, nil
+		}
+		return false, streamio.Rename(src, s.uploadPath(name))
+	}
+	return s.blobs.Put(name, src, sum)
+}
+
+// linkBlob 이미 있는 블롭 sum 을 name 으로 - 본문은 받기만 하고 버린 뒤 (blobUpload.commit, name 의 경로 잠금 안에서)
+func (s *Server) linkBlob(r *http.Request, name, sum string) error {
+	if err := s.keepVersion(name); err != nil {
+		return err
+	}
+	if err := s.blobs.Link(name, sum); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.uploadPath(name)); err == nil {
+		s.hashes.put(name, info.Size(), info.ModTime(), sum)
+	}
+	return nil
+}
+
+// forget 지운 name 의 참조를 저장소에서 빼 (경로 잠금 안에서)
+func (s *Server) forget(r *http.Request, name string) {
+	if s.blobs == nil {
+		return
+	}
+	freed, err := s.blobs.Remove(name)
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "저장소 색인 기록 실패", "file", name, "err", err)
+		return
+	}
+	if freed {
+		s.logger(r).DebugContext(r.Context(), "마지막 참조라 블롭 삭제", "file", name)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 미리 알려 준 sha256 으로 blobUpload 를 고른 뒤 commit 전에 그 내용의 마지막 이름이 지워져도 링크가 돼야 해
+func TestBlobUploadLastNameRemoved(t *testing.T) {
+	root := t.TempDir()
+	uploads := filepath.Join(root, "uploads")
+	s, err := New(Config{
+		UploadDir:  uploads,
+		DedupDir:   filepath.Join(root, "store"),
+		TrashDir:   t.TempDir(),
+		SessionDir: t.TempDir(),
+		VersionDir: t.TempDir(),
+		Logger:     slog.New(slog.DiscardHandler),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("같은 내용")
+	digest := sha256.Sum256(content)
+	sum := hex.EncodeToString(digest[:])
+	src := filepath.Join(uploads, ".a.bin.upload")
+	if err := os.WriteFile(src, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.blobs.Put("a.bin", src, sum); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	dst, err := s.newUpload(r, "b.bin", sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.abort()
+	if _, ok := dst.(*blobUpload); !ok {
+		t.Fatalf("newUpload = %T, want *blobUpload", dst)
+	}
+	dst.Write(content)
+
+	// 본문을 받는 사이 다른 요청이 a.bin 을 지웠어 (deleteHandler 처럼 파일을 치우고 색인에서 빼)
+	os.Remove(s.uploadPath("a.bin"))
+	s.blobs.Remove("a.bin")
+
+	if dup, err := dst.commit(r, sum); err != nil || !dup {
+		t.Fatalf("commit = %v, %v", dup, err)
+	}
+	dst.abort()
+	if got, err := os.ReadFile(s.uploadPath("b.bin")); err != nil || string(got) != string(content) {
+		t.Errorf("b.bin = %q, %v", got, err)
+	}
+	if s.blobs.Refs(sum) != 1 {
+		t.Errorf("참조 %d, want 1", s.blobs.Refs(sum))
+	}
+}
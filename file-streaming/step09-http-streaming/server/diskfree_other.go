@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package server
+
+import "errors"
+
+// diskFree 남은 공간을 알 수 없는 플랫폼은 disk 검사를 skip 해
+func diskFree(dir string) (int64, error) { return 0, errors.ErrUnsupported }
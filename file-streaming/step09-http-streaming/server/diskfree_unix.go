@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package server
+
+import "syscall"
+
+// diskFree dir 이 있는 파일시스템에서 root 가 아닌 사용자가 쓸 수 있는 바이트
+func diskFree(dir string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
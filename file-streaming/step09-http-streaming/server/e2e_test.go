@@ -0,0 +1,4467 @@
+package server_test
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hellotect2022go/study-go/file-streaming/extract"
+	"github.com/hellotect2022go/study-go/file-streaming/fetch"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/policy"
+	"github.com/hellotect2022go/study-go/file-streaming/scan"
+	"github.com/hellotect2022go/study-go/file-streaming/step09-http-streaming/server"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/testutil"
+	"github.com/hellotect2022go/study-go/file-streaming/transfer"
+)
+
+// 서버를 httptest 로 띄우고 업로드 → 다운로드 → Range → 이어받기를 실제 HTTP 로 돌려보는 통합 테스트
+// ⭐ 결과 체크섬은 원본 픽스처와 비교하고, 픽스처 자체는 testdata/e2e.golden 과 비교해 (생성기가 바뀌면 -update)
+
+var fixtures = []testutil.Fixture{
+	{Name: "app.log", Kind: gendata.KindLog, Size: 512<<10 + 123, Seed: 1},
+	{Name: "random.bin", Kind: gendata.KindRandom, Size: 3<<20 + 7, Seed: 2},
+	{Name: "repeat.txt", Kind: gendata.KindRepeat, Size: 64 << 10, Seed: 3},
+	{Name: "empty.dat", Kind: gendata.KindRandom, Size: 0, Seed: 4},
+}
+
+// testServer 임시 디렉토리를 쓰는 서버 + 그 서버에 올려둔 픽스처
+type testServer struct {
+	srv        *server.Server
+	url        string
+	uploadDir  string
+	sessionDir string
+	fixtures   map[string]string // 이름 → 로컬 원본 경로
+}
+
+func newTestServer(t *testing.T, cfg server.Config) *testServer {
+	t.Helper()
+	cfg.UploadDir = t.TempDir()
+	cfg.TrashDir = t.TempDir()
+	if cfg.SessionDir == "" {
+		cfg.SessionDir = t.TempDir()
+	}
+	cfg.Logger = slog.New(slog.DiscardHandler)
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return &testServer{
+		srv:        srv,
+		url:        ts.URL,
+		uploadDir:  cfg.UploadDir,
+		sessionDir: cfg.SessionDir,
+		fixtures:   testutil.WriteFixtures(t, t.TempDir(), fixtures),
+	}
+}
+
+// uploadAll 픽스처를 전부 업로드 (동시에 - 서버의 경로 잠금도 같이 확인)
+func (s *testServer) uploadAll(t *testing.T) {
+	t.Helper()
+	t.Run("upload", func(t *testing.T) {
+		for name, path := range s.fixtures {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", path)
+				testutil.ExpectStatus(t, resp, http.StatusOK)
+			})
+		}
+	})
+	if t.Failed() {
+		t.FailNow()
+	}
+}
+
+func (s *testServer) fileURL(handler, name string) string {
+	return s.url + "/" + handler + "?file=" + url.QueryEscape(name)
+}
+
+func TestE2EUploadDownload(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	got := make(testutil.Checksums)
+	for name, path := range s.fixtures {
+		want := testutil.SHA256File(t, path)
+		got[name] = want
+
+		// 서버 디스크에 저장된 파일
+		if sum := testutil.SHA256File(t, filepath.Join(s.uploadDir, name)); sum != want {
+			t.Errorf("%s: 저장된 파일 체크섬 %s, want %s", name, sum, want)
+		}
+		// /download 와 /range-download 전체 전송
+		for _, handler := range []string{"download", "range-download"} {
+			resp := testutil.Get(t, t.Context(), s.fileURL(handler, name))
+			testutil.ExpectStatus(t, resp, http.StatusOK)
+			if sum := testutil.SHA256(testutil.ReadBody(t, resp)); sum != want {
+				t.Errorf("%s /%s: 받은 체크섬 %s, want %s", name, handler, sum, want)
+			}
+		}
+	}
+	testutil.CheckGolden(t, "e2e", got)
+}
+
+// HEAD /download - 다운로드 관리자가 받기 전에 크기, ETag, 수정 시각, Range 지원을 확인
+func TestE2EDownloadHead(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	const name = "random.bin"
+	fi, err := os.Stat(filepath.Join(s.uploadDir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodHead, s.fileURL("download", name), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	head, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, head, http.StatusOK)
+	if body := testutil.ReadBody(t, head); len(body) != 0 {
+		t.Errorf("HEAD 응답에 본문 %d 바이트", len(body))
+	}
+	for key, want := range map[string]string{
+		"Content-Length": strconv.FormatInt(fi.Size(), 10),
+		"Accept-Ranges":  "bytes",
+		"Last-Modified":  fi.ModTime().UTC().Format(http.TimeFormat),
+		"Content-Type":   "application/octet-stream",
+	} {
+		if got := head.Header.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	etag := head.Header.Get("ETag")
+	if etag == "" {
+		t.Error("ETag 가 없음")
+	}
+
+	// GET 도 같은 헤더, Range 를 주면 /range-download 처럼 206
+	get := testutil.Get(t, t.Context(), s.fileURL("download", name))
+	testutil.ExpectStatus(t, get, http.StatusOK)
+	get.Body.Close()
+	if get.Header.Get("ETag") != etag || get.Header.Get("Accept-Ranges") != "bytes" {
+		t.Errorf("GET 헤더가 HEAD 와 다름: ETag %q, Accept-Ranges %q", get.Header.Get("ETag"), get.Header.Get("Accept-Ranges"))
+	}
+	part := testutil.Get(t, t.Context(), s.fileURL("download", name), "Range", "bytes=10-19", "If-Range", etag)
+	testutil.ExpectStatus(t, part, http.StatusPartialContent)
+	if cr := part.Header.Get("Content-Range"); cr != fmt.Sprintf("bytes 10-19/%d", fi.Size()) {
+		t.Errorf("Content-Range = %q", cr)
+	}
+	if body := testutil.ReadBody(t, part); len(body) != 10 {
+		t.Errorf("Range 본문 %d 바이트, want 10", len(body))
+	}
+}
+
+func TestE2ERangeDownload(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	const name = "random.bin"
+	original, err := os.ReadFile(s.fixtures[name])
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(len(original))
+
+	tests := []struct {
+		rng        string
+		start, end int64 // 기대하는 구간 [start, end]
+	}{
+		{"bytes=0-99", 0, 99},
+		{"bytes=1048576-2097151", 1 << 20, 2<<20 - 1},
+		{"bytes=-7", size - 7, size - 1},
+		{fmt.Sprintf("bytes=%d-", size-1000), size - 1000, size - 1},
+		{fmt.Sprintf("bytes=%d-%d", size-10, size+100), size - 10, size - 1}, // 끝을 넘으면 파일 끝까지
+	}
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			resp := testutil.Get(t, t.Context(), s.fileURL("range-download", name), "Range", tt.rng)
+			testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+
+			wantRange := fmt.Sprintf("bytes %d-%d/%d", tt.start, tt.end, size)
+			if cr := resp.Header.Get("Content-Range"); cr != wantRange {
+				t.Errorf("Content-Range = %q, want %q", cr, wantRange)
+			}
+			if body := testutil.ReadBody(t, resp); !bytes.Equal(body, original[tt.start:tt.end+1]) {
+				t.Errorf("본문이 원본 [%d, %d] 구간과 다름 (받은 %d 바이트)", tt.start, tt.end, len(body))
+			}
+		})
+	}
+
+	t.Run("범위 밖", func(t *testing.T) {
+		resp := testutil.Get(t, t.Context(), s.fileURL("range-download", name), "Range", fmt.Sprintf("bytes=%d-", size))
+		testutil.ExpectStatus(t, resp, http.StatusRequestedRangeNotSatisfiable)
+	})
+}
+
+func TestE2EResumeDownload(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	const name = "random.bin"
+	want := testutil.SHA256File(t, s.fixtures[name])
+	partial := filepath.Join(t.TempDir(), name+".part")
+
+	// 1) 받다가 1MB 쯤에서 연결이 끊긴 상황
+	first := testutil.Get(t, t.Context(), s.fileURL("range-download", name))
+	testutil.ExpectStatus(t, first, http.StatusOK)
+	lastModified := first.Header.Get("Last-Modified")
+	part, err := os.Create(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.CopyN(part, first.Body, 1<<20+5); err != nil {
+		t.Fatal(err)
+	}
+	first.Body.Close()
+	offset, err := part.Seek(0, io.SeekCurrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2) 받은 만큼부터 이어받기 - If-Range 로 그 사이 파일이 바뀌지 않았을 때만 부분 응답
+	resp := testutil.Get(t, t.Context(), s.fileURL("range-download", name),
+		"Range", fmt.Sprintf("bytes=%d-", offset), "If-Range", lastModified)
+	testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+	if _, err := io.Copy(part, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if err := part.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sum := testutil.SHA256File(t, partial); sum != want {
+		t.Errorf("이어받은 파일 체크섬 %s, want %s", sum, want)
+	}
+
+	// 3) 그 사이 서버 파일이 바뀌었으면 If-Range 가 안 맞아서 처음부터 전체(200)를 다시 받아야 해
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(s.uploadDir, name), later, later); err != nil {
+		t.Fatal(err)
+	}
+	stale := testutil.Get(t, t.Context(), s.fileURL("range-download", name),
+		"Range", fmt.Sprintf("bytes=%d-", offset), "If-Range", lastModified)
+	testutil.ExpectStatus(t, stale, http.StatusOK)
+	if sum := testutil.SHA256(testutil.ReadBody(t, stale)); sum != want {
+		t.Errorf("전체 재전송 체크섬 %s, want %s", sum, want)
+	}
+}
+
+// ETag 와 X-Content-SHA256 - fetch.Download 가 If-Range 로 이어받고 다 받은 뒤 검증하는 데 쓰는 값
+func TestE2EResumeDownloadETag(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	const name = "random.bin"
+	want := testutil.SHA256File(t, s.fixtures[name])
+	first := testutil.Get(t, t.Context(), s.fileURL("range-download", name))
+	testutil.ExpectStatus(t, first, http.StatusOK)
+	first.Body.Close()
+	etag := first.Header.Get("ETag")
+	if !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("ETag = %q, 강한 ETag 가 아님", etag)
+	}
+	if sum := first.Header.Get("X-Content-SHA256"); sum != want {
+		t.Errorf("X-Content-SHA256 = %q, want %s", sum, want)
+	}
+
+	resp := testutil.Get(t, t.Context(), s.fileURL("range-download", name), "Range", "bytes=100-", "If-Range", etag)
+	testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+	resp.Body.Close()
+
+	// 파일이 바뀌면 ETag 도 달라서 예전 ETag 의 If-Range 는 전체(200)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(s.uploadDir, name), later, later); err != nil {
+		t.Fatal(err)
+	}
+	stale := testutil.Get(t, t.Context(), s.fileURL("range-download", name), "Range", "bytes=100-", "If-Range", etag)
+	testutil.ExpectStatus(t, stale, http.StatusOK)
+	stale.Body.Close()
+	if stale.Header.Get("ETag") == etag {
+		t.Errorf("수정 시각이 바뀌었는데 ETag 가 그대로 %s", etag)
+	}
+}
+
+// cutTransport 첫 응답 본문을 cut 바이트에서 끊는 RoundTripper (받다가 연결이 끊긴 상황)
+type cutTransport struct {
+	cut  int64
+	done bool
+}
+
+func (c *cutTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || c.done {
+		return resp, err
+	}
+	c.done = true
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(io.LimitReader(resp.Body, c.cut), iotest.ErrReader(io.ErrUnexpectedEOF)), resp.Body}
+	return resp, nil
+}
+
+func TestE2EFetchResume(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	const name = "random.bin"
+	dest := filepath.Join(t.TempDir(), name)
+	opts := fetch.Options{Client: &http.Client{Transport: &cutTransport{cut: 1 << 20}}}
+	opts.Retries = 1
+	res, err := fetch.Download(t.Context(), s.fileURL("range-download", name), dest, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(s.fixtures[name])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Attempts != 2 || res.Resumed != 1<<20 || res.Received != fi.Size() || !res.Verified {
+		t.Errorf("결과 = %+v", res)
+	}
+	if sum := testutil.SHA256File(t, dest); sum != testutil.SHA256File(t, s.fixtures[name]) {
+		t.Errorf("받은 파일 체크섬 %s", sum)
+	}
+}
+
+// 접근 로그 - 요청마다 메서드, 경로, 상태, 보낸/받은 바이트, 클라이언트 IP 한 줄
+func TestE2EAccessLog(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "access.log")
+		s := newTestServer(t, server.Config{AccessLog: path})
+		up := testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["app.log"])
+		testutil.ExpectStatus(t, up, http.StatusOK)
+		up.Body.Close()
+		resp := testutil.Get(t, t.Context(), s.fileURL("download", "app.log"), "User-Agent", "e2e-test", "X-Request-ID", "req-42")
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		body := testutil.ReadBody(t, resp)
+
+		lines := waitAccessLog(t, path, 2)
+		type entry struct {
+			RequestID  string  `json:"request_id"`
+			ClientIP   string  `json:"client_ip"`
+			Method     string  `json:"method"`
+			Path       string  `json:"path"`
+			Query      string  `json:"query"`
+			Status     int     `json:"status"`
+			BytesOut   int64   `json:"bytes_out"`
+			BytesIn    int64   `json:"bytes_in"`
+			DurationMS float64 `json:"duration_ms"`
+			UserAgent  string  `json:"user_agent"`
+		}
+		var upload, download entry
+		if err := json.Unmarshal([]byte(lines[0]), &upload); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(lines[1]), &download); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(s.fixtures["app.log"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if upload.Method != http.MethodPost || upload.Path != "/upload" || upload.Status != http.StatusOK || upload.BytesIn <= fi.Size() {
+			t.Errorf("업로드 기록 = %+v (본문은 파일 %d 바이트보다 커야 해)", upload, fi.Size())
+		}
+		want := entry{RequestID: "req-42", ClientIP: "127.0.0.1", Method: http.MethodGet, Path: "/download", Query: "file=app.log",
+			Status: http.StatusOK, BytesOut: int64(len(body)), UserAgent: "e2e-test", DurationMS: download.DurationMS}
+		if download != want {
+			t.Errorf("다운로드 기록 = %+v, want %+v", download, want)
+		}
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "access.log")
+		s := newTestServer(t, server.Config{AccessLog: path, AccessLogFormat: server.AccessCombined})
+		resp := testutil.Get(t, t.Context(), s.fileURL("download", "missing.bin"), "Referer", `http://x/"q"`)
+		testutil.ExpectStatus(t, resp, http.StatusNotFound)
+		resp.Body.Close()
+
+		line := waitAccessLog(t, path, 1)[0]
+		re := regexp.MustCompile(`^127\.0\.0\.1 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /download\?file=missing\.bin HTTP/1\.1" 404 \d+ "http://x/\\"q\\"" "Go-http-client/1\.1" 0 \d+$`)
+		if !re.MatchString(line) {
+			t.Errorf("combined 줄 = %q", line)
+		}
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		cfg := server.Config{UploadDir: t.TempDir(), TrashDir: t.TempDir(), SessionDir: t.TempDir(),
+			AccessLog: filepath.Join(t.TempDir(), "access.log"), AccessLogFormat: "xml"}
+		if _, err := server.New(cfg); err == nil {
+			t.Error("알 수 없는 형식인데 New 가 성공함")
+		}
+	})
+}
+
+// waitAccessLog 접근 로그에 n 줄이 찰 때까지 기다려 (응답을 다 받은 뒤에 기록돼서)
+func waitAccessLog(t *testing.T, path string, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(data) > 0 && len(lines) >= n {
+			return lines
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("접근 로그 %d 줄을 기다렸지만 %q", n, data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestE2EMetrics(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	var uploaded int64
+	for _, path := range s.fixtures {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uploaded += fi.Size()
+	}
+	resp := testutil.Get(t, t.Context(), s.fileURL("download", "app.log"), "Accept-Encoding", "identity")
+	body := testutil.ReadBody(t, resp)
+	resp = testutil.Get(t, t.Context(), s.fileURL("download", "missing.bin"))
+	testutil.ExpectStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+	resp = testutil.Get(t, t.Context(), s.url+"/api/files")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// 지표는 핸들러가 끝난 뒤에 세니까 응답을 받은 직후엔 아직일 수 있어
+	m := waitMetric(t, s.url, `fs_transfer_duration_seconds_count{handler="/download"}`, 2)
+	n := float64(len(s.fixtures))
+	for sample, want := range map[string]float64{
+		`fs_http_requests_total{handler="/upload",code="200"}`:         n,
+		`fs_http_requests_total{handler="/download",code="200"}`:       1,
+		`fs_http_requests_total{handler="/download",code="404"}`:       1,
+		`fs_http_requests_total{handler="/api/files",code="200"}`:      1,
+		`fs_active_transfers{handler="/upload"}`:                       0,
+		`fs_active_transfers{handler="/download"}`:                     0,
+		`fs_transfer_duration_seconds_count{handler="/upload"}`:        n,
+		`fs_http_request_duration_seconds_count{handler="/api/files"}`: 1,
+	} {
+		if got, ok := m[sample]; !ok || got != want {
+			t.Errorf("%s = %v (있음 %v), want %v", sample, got, ok, want)
+		}
+	}
+	// 업로드는 multipart 라 파일 크기보다 조금 더, 다운로드는 본문 + 404 메시지
+	if got := m[`fs_uploaded_bytes_total{handler="/upload"}`]; got <= float64(uploaded) {
+		t.Errorf("받은 바이트 %v, 파일 합 %d 보다 커야 해", got, uploaded)
+	}
+	if got := m[`fs_downloaded_bytes_total{handler="/download"}`]; got <= float64(len(body)) {
+		t.Errorf("보낸 바이트 %v, 본문 %d 보다 커야 해", got, len(body))
+	}
+	if _, ok := m[`fs_http_errors_total{handler="/download"}`]; ok {
+		t.Error("404 는 에러로 세지 않아야 해")
+	}
+	if _, ok := m[`fs_uploaded_bytes_total{handler="/api/files"}`]; ok {
+		t.Error("/api/files 는 전송 핸들러가 아님")
+	}
+}
+
+// waitMetric /metrics 의 sample 이 want 가 될 때까지 기다렸다가 모든 샘플을 돌려줘
+func waitMetric(t *testing.T, base, sample string, want float64) map[string]float64 {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp := testutil.Get(t, t.Context(), base+"/metrics")
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+			t.Fatalf("Content-Type = %q", ct)
+		}
+		m := map[string]float64{}
+		for line := range strings.Lines(string(testutil.ReadBody(t, resp))) {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			name, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+			if !ok {
+				t.Fatalf("지표 줄 = %q", line)
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("지표 줄 = %q: %v", line, err)
+			}
+			m[name] = v
+		}
+		if m[sample] == want {
+			return m
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s = %v, want %v", sample, m[sample], want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestE2EUploadLimit(t *testing.T) {
+	s := newTestServer(t, server.Config{MaxUploadSize: 128 << 10})
+
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["repeat.txt"])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+
+	// 받다가 넘으면 한도와 그때까지 받은 바이트를 JSON 으로
+	type tooLarge struct {
+		Error    string `json:"error"`
+		Limit    int64  `json:"limit"`
+		Received int64  `json:"received"`
+		Length   int64  `json:"length"`
+	}
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["random.bin"])
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+	var body tooLarge
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &body); err != nil || body.Limit != 128<<10 || body.Received <= body.Limit || body.Error == "" {
+		t.Errorf("413 본문 = %+v (%v)", body, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "random.bin")); !os.IsNotExist(err) {
+		t.Errorf("제한을 넘은 업로드가 디스크에 남음 (err=%v)", err)
+	}
+
+	// 크기를 미리 알리면 받기 전에 거절하고 알린 크기를 돌려줘
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file=big.bin", nil)
+	req.Header.Set("Upload-Length", fmt.Sprint(1<<20))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+	body = tooLarge{}
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &body); err != nil || body.Limit != 128<<10 || body.Received != 0 || body.Length != 1<<20 {
+		t.Errorf("413 본문 = %+v (%v)", body, err)
+	}
+}
+
+func TestE2EReload(t *testing.T) {
+	cfg := server.Config{MaxUploadSize: 128 << 10}
+	s := newTestServer(t, cfg)
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["random.bin"])
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+
+	// 한도는 다음 요청부터 바로, 디렉토리가 바뀐 건 재시작해야 한다고 알려줘
+	cfg.UploadDir, cfg.TrashDir, cfg.SessionDir = s.uploadDir, filepath.Join(t.TempDir(), "other-trash"), s.sessionDir
+	cfg.MaxUploadSize = 0
+	if got := s.srv.Reload(cfg); !slices.Equal(got, []string{"TrashDir"}) {
+		t.Errorf("재시작 필요 = %v", got)
+	}
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["random.bin"])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+}
+
+func TestE2EGzipDownload(t *testing.T) {
+	s := newTestServer(t, server.Config{Gzip: true})
+	s.uploadAll(t)
+
+	for _, tc := range []struct {
+		name, accept string
+		gzip         bool
+	}{
+		{"app.log", "gzip", true},           // 로그는 텍스트라 압축
+		{"app.log", "br, gzip;q=0.5", true}, // q 가 0 이 아니면 받아
+		{"app.log", "gzip;q=0", false},      // 명시적으로 거절
+		{"app.log", "identity", false},      // 압축 안 받는 클라이언트는 원본
+		{"random.bin", "gzip", false},       // 무작위 바이너리는 줄지 않아서 그대로
+		{"repeat.txt", "deflate, *", true},  // * 도 gzip 으로 쳐
+		{"empty.dat", "gzip", false},        // 너무 작으면 그대로
+	} {
+		t.Run(tc.name+"/"+tc.accept, func(t *testing.T) {
+			// Accept-Encoding 을 직접 주면 http.Client 가 알아서 풀지 않아서 받은 그대로 볼 수 있어
+			resp := testutil.Get(t, t.Context(), s.fileURL("download", tc.name), "Accept-Encoding", tc.accept)
+			testutil.ExpectStatus(t, resp, http.StatusOK)
+			if got := resp.Header.Get("Content-Encoding") == "gzip"; got != tc.gzip {
+				t.Fatalf("gzip = %v, want %v", got, tc.gzip)
+			}
+			if etag := resp.Header.Get("ETag"); (etag == "") != tc.gzip {
+				t.Errorf("ETag = %q - 원본 그대로일 때만 있어야 해", etag)
+			}
+			var body io.Reader = resp.Body
+			if tc.gzip {
+				zr, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			}
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := testutil.SHA256(data), testutil.SHA256File(t, s.fixtures[tc.name]); got != want {
+				t.Errorf("받은 체크섬 %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestE2EUploadMultipleParts(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+
+	// 파일 파트 두 개 사이에 일반 필드 하나 - 파일 파트만 차례로 저장
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		for i, name := range []string{"app.log", "random.bin"} {
+			if i == 1 {
+				mw.WriteField("note", "건너뛰는 필드")
+			}
+			part, _ := mw.CreateFormFile("file", name)
+			f, err := os.Open(s.fixtures[name])
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			io.Copy(part, f)
+			f.Close()
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+	resp, err := http.Post(s.url+"/upload", mw.FormDataContentType(), pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if lines := strings.Count(string(testutil.ReadBody(t, resp)), "\n"); lines != 2 {
+		t.Errorf("응답 %d줄, want 2", lines)
+	}
+	for _, name := range []string{"app.log", "random.bin"} {
+		if got, want := testutil.SHA256File(t, filepath.Join(s.uploadDir, name)), testutil.SHA256File(t, s.fixtures[name]); got != want {
+			t.Errorf("%s: 저장된 파일 체크섬 %s, want %s", name, got, want)
+		}
+	}
+}
+
+// "files" 파트는 동시에 저장하고 파일마다 결과 - 하나가 실패해도 나머지는 저장
+func TestE2EUploadFiles(t *testing.T) {
+	cfg := server.Config{UploadWorkers: 2, Policy: policy.Rules{Block: []string{".bin"}}}
+	s := newTestServer(t, cfg)
+	type result struct {
+		Name     string `json:"name"`
+		Original string `json:"original"`
+		SHA256   string `json:"sha256"`
+		Status   int    `json:"status"`
+		Error    string `json:"error"`
+	}
+	upload := func(status int, expected string, names ...string) (out struct {
+		Files []result `json:"files"`
+		Error string   `json:"error"`
+	}) {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for _, name := range names {
+			part, _ := mw.CreateFormFile("files", name)
+			data, err := os.ReadFile(s.fixtures[name])
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write(data)
+		}
+		mw.Close()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		if expected != "" {
+			req.Header.Set("X-Expected-SHA256", expected)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, status)
+		if err := json.Unmarshal(testutil.ReadBody(t, resp), &out); err != nil || len(out.Files) > len(names) {
+			t.Fatalf("응답 = %+v (%v)", out, err)
+		}
+		return out
+	}
+
+	// 같은 이름 두 개도 경로 잠금으로 차례로 (덮어쓰기)
+	out := upload(http.StatusOK, "", "repeat.txt", "app.log", "repeat.txt")
+	for i, name := range []string{"repeat.txt", "app.log", "repeat.txt"} {
+		if f := out.Files[i]; f.Status != http.StatusOK || f.Name != name || f.SHA256 != testutil.SHA256File(t, s.fixtures[name]) {
+			t.Errorf("결과 %d = %+v", i, f)
+		}
+	}
+
+	// 정책(415)과 sha256(422)에 걸린 파일만 빠지고 207
+	os.Remove(filepath.Join(s.uploadDir, "repeat.txt"))
+	zero := strings.Repeat("0", 64)
+	out = upload(http.StatusMultiStatus, testutil.SHA256File(t, s.fixtures["app.log"])+","+zero+","+zero, "app.log", "random.bin", "repeat.txt")
+	for i, want := range []result{
+		{Name: "app.log", Original: "app.log", Status: http.StatusOK},
+		{Original: "random.bin", Status: http.StatusUnsupportedMediaType, Error: "업로드 정책에서 거절되었습니다"},
+		{Original: "repeat.txt", Status: http.StatusUnprocessableEntity, Error: "sha256 가 맞지 않습니다"},
+	} {
+		got := out.Files[i]
+		got.SHA256 = ""
+		if got != want {
+			t.Errorf("결과 %d = %+v, want %+v", i, got, want)
+		}
+	}
+	for _, name := range []string{"random.bin", "repeat.txt"} {
+		if _, err := os.Stat(filepath.Join(s.uploadDir, name)); err == nil {
+			t.Errorf("%s 가 저장됨", name)
+		}
+	}
+
+	// 본문이 한도를 넘으면 그 파일부터 413 - 앞 파일은 저장돼
+	cfg.Policy, cfg.MaxUploadSize = policy.Rules{}, 1<<20
+	s.srv.Reload(cfg)
+	out = upload(http.StatusMultiStatus, "", "app.log", "random.bin", "repeat.txt")
+	if len(out.Files) != 2 || out.Files[0].Status != http.StatusOK || out.Files[1].Status != http.StatusRequestEntityTooLarge || out.Error == "" {
+		t.Errorf("응답 = %+v", out)
+	}
+}
+
+func TestE2EUploadChecksum(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	names := []string{"app.log", "random.bin"}
+
+	// 한 요청에 두 파일 - 헤더는 응답 순서대로 쉼표로
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, name := range names {
+		part, _ := mw.CreateFormFile("file", name)
+		data, err := os.ReadFile(s.fixtures[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	mw.Close()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var out struct {
+		Files []struct {
+			Name   string `json:"name"`
+			SHA256 string `json:"sha256"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &out); err != nil || len(out.Files) != len(names) {
+		t.Fatalf("응답 = %+v (%v)", out, err)
+	}
+	var want []string
+	for i, name := range names {
+		sum := testutil.SHA256File(t, s.fixtures[name])
+		want = append(want, sum)
+		if out.Files[i].SHA256 != sum {
+			t.Errorf("%s: 응답 sha256 %s, want %s", name, out.Files[i].SHA256, sum)
+		}
+	}
+	if got := resp.Header.Get("X-Content-SHA256"); got != strings.Join(want, ", ") {
+		t.Errorf("X-Content-SHA256 = %q, want %q", got, strings.Join(want, ", "))
+	}
+
+	// 파일의 체크섬 속성에도 남아 (xattr 을 못 쓰는 파일시스템이면 건너뛰어)
+	rec, ok, err := fstree.XattrChecksums().Get(filepath.Join(s.uploadDir, "app.log"))
+	switch {
+	case errors.Is(err, fstree.ErrXattrUnsupported):
+		t.Log("xattr 미지원 - 속성 확인 건너뜀")
+	case err != nil || !ok:
+		t.Errorf("체크섬 속성 = %v, %v", ok, err)
+	case rec.SHA256 != want[0]:
+		t.Errorf("체크섬 속성 sha256 %s, want %s", rec.SHA256, want[0])
+	}
+
+	// 텍스트 응답에도
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["repeat.txt"])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	sum := testutil.SHA256File(t, s.fixtures["repeat.txt"])
+	if text := string(testutil.ReadBody(t, resp)); !strings.Contains(text, sum) || resp.Header.Get("X-Content-SHA256") != sum {
+		t.Errorf("텍스트 응답 %q, 헤더 %q", text, resp.Header.Get("X-Content-SHA256"))
+	}
+}
+
+func TestE2EUploadExpectedChecksum(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	upload := func(content, expected string) *http.Response {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", "notes.txt")
+		part.Write([]byte(content))
+		mw.Close()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("X-Expected-SHA256", expected)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	stored := filepath.Join(s.uploadDir, "notes.txt")
+
+	// 맞으면 저장 (대문자로 보내도 돼)
+	good := testutil.SHA256([]byte("첫 내용"))
+	resp := upload("첫 내용", strings.ToUpper(good))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// 다르면 422 + 서버가 잰 값, 예전 파일은 그대로고 임시 파일도 남지 않아
+	resp = upload("깨진 내용", good)
+	testutil.ExpectStatus(t, resp, http.StatusUnprocessableEntity)
+	var e struct{ Error, File, Expected, Actual string }
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &e); err != nil {
+		t.Fatal(err)
+	}
+	if actual := testutil.SHA256([]byte("깨진 내용")); e.File != "notes.txt" || e.Expected != good || e.Actual != actual || resp.Header.Get("X-Content-SHA256") != actual {
+		t.Errorf("422 본문 = %+v", e)
+	}
+	if data, _ := os.ReadFile(stored); string(data) != "첫 내용" {
+		t.Errorf("예전 파일이 바뀜: %q", data)
+	}
+	if entries, _ := os.ReadDir(s.uploadDir); len(entries) != 1 {
+		t.Errorf("업로드 디렉토리에 %d 개 - 임시 파일이 남음", len(entries))
+	}
+
+	// 형식이 틀리면 받기 전에 400
+	resp = upload("첫 내용", "abc")
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+	resp.Body.Close()
+}
+
+// evilScanner EVIL 이 들어 있으면 거절, BROKEN 이면 검사 실패 (clamdscan 대신)
+type evilScanner struct{}
+
+func (evilScanner) Scan(ctx context.Context, name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch {
+	case bytes.Contains(data, []byte("EVIL")):
+		return &scan.Rejected{Name: name, Reason: "stream: Evil-Test FOUND"}
+	case bytes.Contains(data, []byte("BROKEN")):
+		return errors.New("clamd 에 연결할 수 없음")
+	}
+	return nil
+}
+
+func TestE2EUploadScan(t *testing.T) {
+	sessions := t.TempDir()
+	s := newTestServer(t, server.Config{Scanner: evilScanner{}, SessionDir: sessions})
+	upload := func(name, content string) *http.Response {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return testutil.Upload(t, t.Context(), s.url+"/upload", "file", path)
+	}
+
+	resp := upload("ok.txt", "평범한 내용")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	// 거절이면 422 + 사유, 파일도 임시 파일도 남지 않아
+	resp = upload("bad.txt", strings.Repeat("앞부분 ", 1<<14)+"EVIL")
+	testutil.ExpectStatus(t, resp, http.StatusUnprocessableEntity)
+	var rej struct{ Error, File, Reason string }
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &rej); err != nil {
+		t.Fatal(err)
+	}
+	if rej.File != "bad.txt" || rej.Reason != "stream: Evil-Test FOUND" {
+		t.Errorf("422 본문 = %+v", rej)
+	}
+	// 검사기가 실패하면 저장하지 않고 503
+	resp = upload("broken.txt", "BROKEN")
+	testutil.ExpectStatus(t, resp, http.StatusServiceUnavailable)
+	resp.Body.Close()
+	if entries, _ := os.ReadDir(s.uploadDir); len(entries) != 1 {
+		t.Errorf("업로드 디렉토리에 %d 개 - ok.txt 만 있어야 해", len(entries))
+	}
+
+	// 이어 올리기는 다 모은 뒤 검사해서 거절이면 세션까지 버려
+	content := []byte("이어 올린 EVIL")
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file=resumed.txt", nil)
+	req.Header.Set("Upload-Length", fmt.Sprint(len(content)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	loc := s.url + resp.Header.Get("Location")
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodPatch, loc, bytes.NewReader(content))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", "0")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusUnprocessableEntity)
+	resp.Body.Close()
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "resumed.txt")); !os.IsNotExist(err) {
+		t.Errorf("거절한 이어 올리기가 저장됨 (err=%v)", err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(sessions, "*.part")); len(parts) != 0 {
+		t.Errorf("남은 .part: %v", parts)
+	}
+
+	// 압축 풀기는 풀기 전에 아카이브째
+	zipPath := filepath.Join(t.TempDir(), "evil.zip")
+	if err := os.WriteFile(zipPath, []byte("PK EVIL"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", zipPath)
+	testutil.ExpectStatus(t, resp, http.StatusUnprocessableEntity)
+	resp.Body.Close()
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "evil")); !os.IsNotExist(err) {
+		t.Errorf("거절한 아카이브가 풀림 (err=%v)", err)
+	}
+}
+
+func TestE2EUploadPolicy(t *testing.T) {
+	sessions := t.TempDir()
+	s := newTestServer(t, server.Config{SessionDir: sessions, Policy: policy.Rules{
+		Block:   []string{".exe"},
+		Types:   []string{"image/*", "text/plain"},
+		MaxSize: map[string]int64{"image/*": 1 << 10},
+	}})
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 100)...)
+	upload := func(name string, content []byte) *http.Response {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return testutil.Upload(t, t.Context(), s.url+"/upload", "file", path)
+	}
+	rejected := func(resp *http.Response, file, ct string) {
+		t.Helper()
+		testutil.ExpectStatus(t, resp, http.StatusUnsupportedMediaType)
+		var rej struct{ Error, File, Type, Reason string }
+		if err := json.Unmarshal(testutil.ReadBody(t, resp), &rej); err != nil {
+			t.Fatal(err)
+		}
+		if rej.File != file || rej.Type != ct || rej.Reason == "" {
+			t.Errorf("415 본문 = %+v", rej)
+		}
+	}
+
+	for name, content := range map[string][]byte{"a.png": png, "note.txt": []byte("평범한 글")} {
+		resp := upload(name, content)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	// 확장자는 이름으로, 형식은 내용으로 - 이름만 .png 인 실행 파일도 걸려
+	rejected(upload("tool.exe", png), "tool.exe", "")
+	rejected(upload("fake.png", append([]byte("MZ\x90\x00"), make([]byte, 4<<10)...)), "fake.png", "application/octet-stream")
+	// 형식별 한도를 넘으면 413
+	resp := upload("big.png", append(png, make([]byte, 2<<10)...))
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+	resp.Body.Close()
+	entries, _ := os.ReadDir(s.uploadDir)
+	if len(entries) != 2 {
+		t.Errorf("업로드 디렉토리에 %d 개 - a.png, note.txt 만 있어야 해", len(entries))
+	}
+
+	// 이어 올리기는 확장자를 만들 때, 내용 형식은 다 모은 뒤 봐서 걸리면 세션까지 버려
+	create := func(name string, length int) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file="+name, nil)
+		req.Header.Set("Upload-Length", fmt.Sprint(length))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	rejected(create("setup.exe", 10), "setup.exe", "")
+	content := []byte("PK\x03\x04 zip 인 척")
+	resp = create("resumed.txt", len(content))
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPatch, s.url+resp.Header.Get("Location"), bytes.NewReader(content))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejected(resp, "resumed.txt", "application/zip")
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "resumed.txt")); !os.IsNotExist(err) {
+		t.Errorf("거절한 이어 올리기가 저장됨 (err=%v)", err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(sessions, "*.part")); len(parts) != 0 {
+		t.Errorf("남은 .part: %v", parts)
+	}
+}
+
+func TestE2EDedup(t *testing.T) {
+	store := t.TempDir()
+	s := newTestServer(t, server.Config{DedupDir: store})
+	upload := func(name string) (dedup bool) {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", name)
+		part.Write(bytes.Repeat([]byte("같은 내용 "), 1000))
+		mw.Close()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		var out struct {
+			Files []struct {
+				Dedup bool `json:"deduplicated"`
+			} `json:"files"`
+		}
+		json.Unmarshal(testutil.ReadBody(t, resp), &out)
+		return len(out.Files) == 1 && out.Files[0].Dedup
+	}
+	// blobs 저장소의 블롭 수 (색인 파일은 빼고)
+	blobs := func() int {
+		n := 0
+		filepath.WalkDir(store, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && filepath.Dir(path) != store {
+				n++
+			}
+			return nil
+		})
+		return n
+	}
+	del := func(name string) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodDelete, s.fileURL("delete", name), nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	if upload("a.txt") || !upload("b.txt") {
+		t.Error("두 번째 같은 내용만 deduplicated 여야 해")
+	}
+	a, _ := os.Stat(filepath.Join(s.uploadDir, "a.txt"))
+	b, _ := os.Stat(filepath.Join(s.uploadDir, "b.txt"))
+	if a == nil || b == nil || !os.SameFile(a, b) || blobs() != 1 {
+		t.Fatalf("같은 내용이 한 벌이 아님 (블롭 %d)", blobs())
+	}
+	resp := testutil.Get(t, t.Context(), s.fileURL("download", "b.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if got := testutil.ReadBody(t, resp); !bytes.Equal(got, bytes.Repeat([]byte("같은 내용 "), 1000)) {
+		t.Errorf("다운로드 %d 바이트가 다름", len(got))
+	}
+
+	// 참조가 남아 있는 동안은 블롭도 남아
+	del("a.txt")
+	if blobs() != 1 {
+		t.Errorf("b.txt 가 남았는데 블롭 %d 개", blobs())
+	}
+	del("b.txt")
+	if blobs() != 0 {
+		t.Errorf("마지막 이름을 지웠는데 블롭 %d 개", blobs())
+	}
+}
+
+// sha256 을 미리 알려 주면 이미 있는 내용은 디스크에 쓰지 않고 링크만
+func TestE2EDedupExpected(t *testing.T) {
+	s := newTestServer(t, server.Config{DedupDir: t.TempDir()})
+	content := bytes.Repeat([]byte("미리 아는 내용 "), 1000)
+	sum := sha256.Sum256(content)
+	want := hex.EncodeToString(sum[:])
+	upload := func(name string, data []byte, expected string) (*http.Response, []byte) {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", name)
+		part.Write(data)
+		mw.Close()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("X-Expected-SHA256", expected)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, testutil.ReadBody(t, resp)
+	}
+	type result struct {
+		Files []struct {
+			Name   string `json:"name"`
+			Size   int64  `json:"size"`
+			SHA256 string `json:"sha256"`
+			Dedup  bool   `json:"deduplicated"`
+		} `json:"files"`
+	}
+
+	// 처음 보는 내용은 평소처럼 저장
+	resp, body := upload("a.txt", content, want)
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var first result
+	json.Unmarshal(body, &first)
+	if len(first.Files) != 1 || first.Files[0].Dedup {
+		t.Fatalf("처음 올린 건 deduplicated 가 아니어야 해: %s", body)
+	}
+
+	// 같은 내용 - 받기만 하고 블롭을 링크
+	resp, body = upload("b.txt", content, want)
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var out result
+	json.Unmarshal(body, &out)
+	if len(out.Files) != 1 || !out.Files[0].Dedup || out.Files[0].Name != "b.txt" || out.Files[0].Size != int64(len(content)) || out.Files[0].SHA256 != want {
+		t.Fatalf("응답 = %s", body)
+	}
+	a, _ := os.Stat(filepath.Join(s.uploadDir, "a.txt"))
+	b, _ := os.Stat(filepath.Join(s.uploadDir, "b.txt"))
+	if a == nil || b == nil || !os.SameFile(a, b) {
+		t.Error("b.txt 가 a.txt 와 같은 블롭이 아님")
+	}
+	if temps, _ := filepath.Glob(filepath.Join(s.uploadDir, ".*.upload-*")); len(temps) != 0 {
+		t.Errorf("남은 임시 파일: %v", temps)
+	}
+
+	// 해시만 알고 내용이 다르면 422 - 이름이 생기지 않아
+	resp, _ = upload("c.txt", []byte("다른 내용"), want)
+	testutil.ExpectStatus(t, resp, http.StatusUnprocessableEntity)
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "c.txt")); !os.IsNotExist(err) {
+		t.Errorf("내용이 다른데 c.txt 가 생김 (err=%v)", err)
+	}
+}
+
+// 같은 핸들러를 메모리 저장소로 - 업로드 디렉토리에는 아무것도 안 생겨
+func TestE2EMemoryBackend(t *testing.T) {
+	mem := storage.NewMemory()
+	s := newTestServer(t, server.Config{Backend: mem})
+	s.uploadAll(t)
+
+	for name, path := range s.fixtures {
+		want := testutil.SHA256File(t, path)
+		for _, handler := range []string{"download", "range-download"} {
+			resp := testutil.Get(t, t.Context(), s.fileURL(handler, name))
+			testutil.ExpectStatus(t, resp, http.StatusOK)
+			if sum := testutil.SHA256(testutil.ReadBody(t, resp)); sum != want {
+				t.Errorf("%s /%s: 받은 체크섬 %s, want %s", name, handler, sum, want)
+			}
+		}
+		resp := testutil.Get(t, t.Context(), s.url+"/files/"+url.PathEscape(name))
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		if sum := testutil.SHA256(testutil.ReadBody(t, resp)); sum != want {
+			t.Errorf("/files/%s: 받은 체크섬 %s, want %s", name, sum, want)
+		}
+	}
+	if entries, _ := os.ReadDir(s.uploadDir); len(entries) != 0 {
+		t.Errorf("업로드 디렉토리에 %d 개가 생김", len(entries))
+	}
+
+	// Range 는 저장소 핸들을 Seek 해서
+	original, _ := os.ReadFile(s.fixtures["random.bin"])
+	resp := testutil.Get(t, t.Context(), s.fileURL("range-download", "random.bin"), "Range", "bytes=1048576-1048675")
+	testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+	if body := testutil.ReadBody(t, resp); !bytes.Equal(body, original[1<<20:1<<20+100]) {
+		t.Errorf("Range 본문이 원본과 다름 (%d 바이트)", len(body))
+	}
+
+	// 목록과 sha256 도 저장소에서
+	resp = testutil.Get(t, t.Context(), s.url+"/api/files")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var list struct {
+		Count int `json:"count"`
+		Files []struct {
+			Name   string `json:"name"`
+			SHA256 string `json:"sha256"`
+		} `json:"files"`
+	}
+	json.Unmarshal(testutil.ReadBody(t, resp), &list)
+	if list.Count != len(s.fixtures) {
+		t.Errorf("목록 %d 개, want %d", list.Count, len(s.fixtures))
+	}
+	for _, f := range list.Files {
+		if want := testutil.SHA256File(t, s.fixtures[f.Name]); f.SHA256 != want {
+			t.Errorf("%s: 목록 sha256 %s, want %s", f.Name, f.SHA256, want)
+		}
+	}
+
+	// 휴지통 없이 바로 지워
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodDelete, s.fileURL("delete", "app.log"), nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if body := string(testutil.ReadBody(t, resp)); strings.Contains(body, "휴지통") {
+		t.Errorf("삭제 응답 = %q (휴지통 없이)", body)
+	}
+	if _, err := mem.Stat(t.Context(), "app.log"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("지운 뒤 Stat = %v", err)
+	}
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.fileURL("download", "app.log")), http.StatusNotFound)
+
+	// 이어 올리기도 다 모으면 저장소로
+	data := []byte("resumable into memory")
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file=r.txt", nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", fmt.Sprint(len(data)))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodPatch, s.url+resp.Header.Get("Location"), bytes.NewReader(data))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", "0")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+	resp.Body.Close()
+	resp = testutil.Get(t, t.Context(), s.fileURL("download", "r.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if got := testutil.ReadBody(t, resp); !bytes.Equal(got, data) {
+		t.Errorf("이어 올린 내용 = %q", got)
+	}
+
+	// 실제 디렉토리가 있어야 하는 기능
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", s.fixtures["repeat.txt"])
+	testutil.ExpectStatus(t, resp, http.StatusNotImplemented)
+	resp.Body.Close()
+	if _, err := server.New(server.Config{Backend: storage.NewMemory(), DedupDir: t.TempDir(), Logger: slog.New(slog.DiscardHandler)}); err == nil {
+		t.Error("메모리 저장소에 DedupDir 을 줬는데 New 가 성공")
+	}
+}
+
+// 업로드 디렉토리에는 암호문만, 다운로드와 Range 는 평문으로
+func TestE2EEncryptedBackend(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)+"\n"), 0600)
+	s := newTestServer(t, server.Config{EncryptionKeyFile: keyFile})
+	s.uploadAll(t)
+
+	for name, path := range s.fixtures {
+		original, _ := os.ReadFile(path)
+		raw, err := os.ReadFile(filepath.Join(s.uploadDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(raw)) != storage.SealedSize(int64(len(original))) || len(original) > 0 && bytes.Contains(raw, original[:min(len(original), 64)]) {
+			t.Errorf("%s: 디스크의 내용이 암호문이 아님 (%d 바이트)", name, len(raw))
+		}
+		resp := testutil.Get(t, t.Context(), s.fileURL("download", name))
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		if resp.ContentLength != int64(len(original)) {
+			t.Errorf("%s: Content-Length %d, want %d", name, resp.ContentLength, len(original))
+		}
+		if sum := testutil.SHA256(testutil.ReadBody(t, resp)); sum != testutil.SHA256(original) {
+			t.Errorf("%s: 받은 체크섬 %s", name, sum)
+		}
+	}
+
+	// 64KB 청크 경계를 걸치는 Range
+	original, _ := os.ReadFile(s.fixtures["random.bin"])
+	resp := testutil.Get(t, t.Context(), s.fileURL("range-download", "random.bin"), "Range", "bytes=65500-65600")
+	testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+	if body := testutil.ReadBody(t, resp); !bytes.Equal(body, original[65500:65601]) {
+		t.Errorf("Range 본문이 원본과 다름 (%d 바이트)", len(body))
+	}
+
+	if _, err := server.New(server.Config{UploadDir: t.TempDir(), EncryptionKeyFile: keyFile, DedupDir: t.TempDir(), Logger: slog.New(slog.DiscardHandler)}); err == nil {
+		t.Error("암호화하는데 DedupDir 을 줬는데 New 가 성공")
+	}
+	bad := filepath.Join(t.TempDir(), "bad.txt")
+	os.WriteFile(bad, []byte("not a key\n"), 0600)
+	if _, err := server.New(server.Config{UploadDir: t.TempDir(), EncryptionKeyFile: bad, Logger: slog.New(slog.DiscardHandler)}); err == nil {
+		t.Error("잘못된 키 파일로 New 가 성공")
+	}
+}
+
+func TestE2EUploadCollision(t *testing.T) {
+	upload := func(t *testing.T, s *testServer, want int) (stored string) {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", "report.txt")
+		part.Write([]byte("내용"))
+		mw.Close()
+		r, err := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		r.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, want)
+		data := testutil.ReadBody(t, resp)
+		if want != http.StatusOK {
+			return ""
+		}
+		var out struct {
+			Files []struct {
+				Name     string `json:"name"`
+				Original string `json:"original"`
+				Size     int64  `json:"size"`
+			} `json:"files"`
+		}
+		if err := json.Unmarshal(data, &out); err != nil || len(out.Files) != 1 {
+			t.Fatalf("응답 %s (%v)", data, err)
+		}
+		if f := out.Files[0]; f.Original != "report.txt" || f.Size != int64(len("내용")) {
+			t.Errorf("응답 = %+v", f)
+		}
+		return out.Files[0].Name
+	}
+
+	tests := []struct {
+		policy string
+		second int
+		match  string // 두 번째 업로드가 저장된 이름 (path.Match 패턴)
+	}{
+		{server.CollisionOverwrite, http.StatusOK, "report.txt"},
+		{server.CollisionReject, http.StatusConflict, ""},
+		{server.CollisionUUID, http.StatusOK, "report-????????-????-????-????-????????????.txt"},
+		{server.CollisionVersion, http.StatusOK, "report-v2.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			s := newTestServer(t, server.Config{Collision: tt.policy})
+			if got := upload(t, s, http.StatusOK); got != "report.txt" {
+				t.Fatalf("첫 업로드 이름 = %q", got)
+			}
+			got := upload(t, s, tt.second)
+			if ok, _ := path.Match(tt.match, got); tt.match != "" && !ok {
+				t.Errorf("두 번째 업로드 이름 = %q, want %s", got, tt.match)
+			}
+			if tt.policy == server.CollisionVersion {
+				if got := upload(t, s, http.StatusOK); got != "report-v3.txt" {
+					t.Errorf("세 번째 업로드 이름 = %q, want report-v3.txt", got)
+				}
+			}
+
+			// 이어 올리기도 같은 정책 - 빈 파일은 만들 때 바로 끝나서 저장한 이름이 Content-Location 으로 와
+			r, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file=report.txt", nil)
+			r.Header.Set("Tus-Resumable", "1.0.0")
+			r.Header.Set("Upload-Length", "0")
+			resp, err := http.DefaultClient.Do(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			switch tt.policy {
+			case server.CollisionReject:
+				testutil.ExpectStatus(t, resp, http.StatusConflict)
+			case server.CollisionVersion:
+				testutil.ExpectStatus(t, resp, http.StatusCreated)
+				if got := resp.Header.Get("Content-Location"); got != "/download?file=report-v4.txt" {
+					t.Errorf("이어 올리기 Content-Location = %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestE2EResumableUpload(t *testing.T) {
+	sessions := t.TempDir()
+	s := newTestServer(t, server.Config{SessionDir: sessions})
+	src := s.fixtures["random.bin"]
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, url string, body io.Reader, header ...string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), method, url, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Tus-Resumable", "1.0.0")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	patch := func(url string, offset int, chunk []byte) *http.Response {
+		return do(http.MethodPatch, url, bytes.NewReader(chunk),
+			"Content-Type", "application/offset+octet-stream", "Upload-Offset", fmt.Sprint(offset))
+	}
+
+	resp := do(http.MethodPost, s.url+"/api/uploads?file=random.bin", nil, "Upload-Length", fmt.Sprint(len(data)))
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	loc := s.url + resp.Header.Get("Location")
+
+	// 앞 1MB 만 보내고 끊긴 셈 치고
+	half := 1 << 20
+	resp = patch(loc, 0, data[:half])
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+	if got := resp.Header.Get("Upload-Offset"); got != fmt.Sprint(half) {
+		t.Fatalf("Upload-Offset = %s, want %d", got, half)
+	}
+
+	// 서버를 새로 띄워도 (같은 세션 디렉토리) 받은 위치를 알려줘
+	s2 := newTestServer(t, server.Config{SessionDir: sessions})
+	loc = s2.url + strings.TrimPrefix(loc, s.url)
+	resp = do(http.MethodHead, loc, nil)
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if got := resp.Header.Get("Upload-Offset"); got != fmt.Sprint(half) {
+		t.Fatalf("재시작 뒤 Upload-Offset = %s, want %d", got, half)
+	}
+	// 위치가 틀리면 409 (받은 위치를 알려줘), 맞으면 나머지를 이어서
+	resp = patch(loc, 0, data)
+	testutil.ExpectStatus(t, resp, http.StatusConflict)
+	resp = patch(loc, half, data[half:])
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+
+	if sum, want := testutil.SHA256File(t, filepath.Join(s2.uploadDir, "random.bin")), testutil.SHA256(data); sum != want {
+		t.Errorf("이어 올린 파일 체크섬 %s, want %s", sum, want)
+	}
+	// 다 받으면 세션은 없어져
+	testutil.ExpectStatus(t, do(http.MethodHead, loc, nil), http.StatusNotFound)
+	if parts, _ := filepath.Glob(filepath.Join(sessions, "*.part")); len(parts) != 0 {
+		t.Errorf("남은 .part: %v", parts)
+	}
+
+	// 취소하면 받은 것도 버려
+	resp = do(http.MethodPost, s2.url+"/api/uploads?file=x.bin", nil, "Upload-Length", "10")
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	loc = s2.url + resp.Header.Get("Location")
+	testutil.ExpectStatus(t, do(http.MethodDelete, loc, nil), http.StatusNoContent)
+	testutil.ExpectStatus(t, do(http.MethodHead, loc, nil), http.StatusNotFound)
+}
+
+// 파트를 동시에, 재시작을 거쳐서 합쳐
+func TestE2EMultipartUpload(t *testing.T) {
+	sessions := t.TempDir()
+	s := newTestServer(t, server.Config{SessionDir: sessions})
+	data, err := os.ReadFile(s.fixtures["random.bin"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func(method, url string, body []byte) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), method, url, bytes.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, testutil.ReadBody(t, resp)
+	}
+	var created struct {
+		UploadID string `json:"upload_id"`
+	}
+	resp, body := do(http.MethodPost, s.url+"/api/multipart?file=random.bin", nil)
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	json.Unmarshal(body, &created)
+	loc := s.url + resp.Header.Get("Location")
+	if created.UploadID == "" || !strings.HasSuffix(loc, created.UploadID) {
+		t.Fatalf("만들기 응답 %s, Location %s", body, loc)
+	}
+
+	// 1MB 파트 넷 (마지막은 짧게) - 뒤에서부터 동시에
+	const partSize = 1 << 20
+	var chunks [][]byte
+	for rest := data; len(rest) > 0; rest = rest[min(len(rest), partSize):] {
+		chunks = append(chunks, rest[:min(len(rest), partSize)])
+	}
+	etags := make([]string, len(chunks))
+	var wg sync.WaitGroup
+	for i := len(chunks) - 1; i >= 0; i-- {
+		wg.Go(func() {
+			resp, _ := do(http.MethodPut, fmt.Sprintf("%s?part=%d", loc, i+1), chunks[i])
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("파트 %d: %d", i+1, resp.StatusCode)
+			}
+			etags[i] = resp.Header.Get("ETag")
+		})
+	}
+	wg.Wait()
+	if want := `"` + testutil.SHA256(chunks[0]) + `"`; etags[0] != want {
+		t.Errorf("파트 1 ETag %s, want %s", etags[0], want)
+	}
+
+	// 재시작해도 받은 파트는 남아
+	s2 := newTestServer(t, server.Config{SessionDir: sessions})
+	loc = s2.url + "/api/multipart/" + created.UploadID
+	resp, body = do(http.MethodGet, loc, nil)
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var listed struct {
+		Parts []struct {
+			Part int    `json:"part"`
+			Size int64  `json:"size"`
+			ETag string `json:"etag"`
+		} `json:"parts"`
+	}
+	json.Unmarshal(body, &listed)
+	if len(listed.Parts) != len(chunks) || listed.Parts[0].ETag != etags[0] || listed.Parts[len(chunks)-1].Size != int64(len(chunks[len(chunks)-1])) {
+		t.Fatalf("재시작 뒤 파트 목록 %s", body)
+	}
+
+	// 순서가 틀리거나 ETag 가 다르면 400
+	bad := fmt.Sprintf(`{"parts":[{"part":2,"etag":%q},{"part":1,"etag":%q}]}`, etags[1], etags[0])
+	resp, _ = do(http.MethodPost, loc, []byte(bad))
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+	bad = fmt.Sprintf(`{"parts":[{"part":1,"etag":%q}]}`, etags[1])
+	resp, _ = do(http.MethodPost, loc, []byte(bad))
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+
+	var complete bytes.Buffer
+	complete.WriteString(`{"parts":[`)
+	for i, e := range etags {
+		if i > 0 {
+			complete.WriteString(",")
+		}
+		fmt.Fprintf(&complete, `{"part":%d,"etag":%q}`, i+1, e)
+	}
+	complete.WriteString(`]}`)
+	resp, body = do(http.MethodPost, loc, complete.Bytes())
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var done struct {
+		File   string `json:"file"`
+		Size   int64  `json:"size"`
+		ETag   string `json:"etag"`
+		SHA256 string `json:"sha256"`
+	}
+	json.Unmarshal(body, &done)
+	if done.File != "random.bin" || done.Size != int64(len(data)) || done.SHA256 != testutil.SHA256(data) || done.ETag == "" || resp.Header.Get("ETag") != done.ETag {
+		t.Errorf("완료 응답 %s (ETag 헤더 %s)", body, resp.Header.Get("ETag"))
+	}
+	if sum := testutil.SHA256File(t, filepath.Join(s2.uploadDir, "random.bin")); sum != testutil.SHA256(data) {
+		t.Errorf("합친 파일 체크섬 %s", sum)
+	}
+	resp = testutil.Get(t, t.Context(), s2.fileURL("download", "random.bin"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if resp.Header.Get("ETag") != done.ETag || resp.Header.Get("X-Content-SHA256") != done.SHA256 {
+		t.Errorf("/download ETag %s, sha256 %s - 완료 응답과 다름", resp.Header.Get("ETag"), resp.Header.Get("X-Content-SHA256"))
+	}
+	resp, _ = do(http.MethodGet, loc, nil)
+	testutil.ExpectStatus(t, resp, http.StatusNotFound)
+	if left, _ := os.ReadDir(filepath.Join(sessions, "multipart")); len(left) != 0 {
+		t.Errorf("남은 멀티파트 디렉토리 %d 개", len(left))
+	}
+
+	// 본문 없이 완료하면 받은 것 전부, 취소하면 버려
+	resp, body = do(http.MethodPost, s2.url+"/api/multipart?file=small.txt", nil)
+	json.Unmarshal(body, &created)
+	loc = s2.url + "/api/multipart/" + created.UploadID
+	do(http.MethodPut, loc+"?part=2", []byte("world"))
+	do(http.MethodPut, loc+"?part=1", []byte("hello "))
+	resp, _ = do(http.MethodPut, loc+"?part=0", []byte("x"))
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+	resp, _ = do(http.MethodPost, loc, nil)
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if got, _ := os.ReadFile(filepath.Join(s2.uploadDir, "small.txt")); string(got) != "hello world" {
+		t.Errorf("합친 내용 %q", got)
+	}
+	resp, body = do(http.MethodPost, s2.url+"/api/multipart?file=gone.txt", nil)
+	json.Unmarshal(body, &created)
+	loc = s2.url + "/api/multipart/" + created.UploadID
+	do(http.MethodPut, loc+"?part=1", []byte("x"))
+	resp, _ = do(http.MethodDelete, loc, nil)
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+	resp, _ = do(http.MethodPost, loc, nil)
+	testutil.ExpectStatus(t, resp, http.StatusNotFound)
+}
+
+func TestE2ERangeUpload(t *testing.T) {
+	sessions := t.TempDir()
+	s := newTestServer(t, server.Config{SessionDir: sessions})
+	data, err := os.ReadFile(s.fixtures["random.bin"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := len(data)
+	put := func(url, contentRange string, body []byte) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, url, bytes.NewReader(body))
+		if contentRange != "" {
+			req.Header.Set("Content-Range", contentRange)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, testutil.ReadBody(t, resp)
+	}
+	var status struct {
+		Received int64    `json:"received"`
+		Ranges   []string `json:"ranges"`
+	}
+
+	// Content-Range 가 없으면 통째로
+	whole, err := os.ReadFile(s.fixtures["repeat.txt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, body := put(s.url+"/upload/repeat.txt", "", whole)
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	if resp.Header.Get("X-Content-SHA256") != testutil.SHA256(whole) || testutil.SHA256File(t, filepath.Join(s.uploadDir, "repeat.txt")) != testutil.SHA256(whole) {
+		t.Errorf("통째로 올리기 %s", body)
+	}
+
+	// 세 조각 - 마지막 조각부터, 중간에 끊긴 앞부분도 남아
+	loc := s.url + "/upload/random.bin"
+	cut1, cut2 := size/3, 2*size/3
+	resp, body = put(loc, fmt.Sprintf("bytes %d-%d/%d", cut2, size-1, size), data[cut2:])
+	testutil.ExpectStatus(t, resp, http.StatusAccepted)
+	if want := fmt.Sprintf("bytes=%d-%d", cut2, size-1); resp.Header.Get("Range") != want {
+		t.Errorf("Range %q, want %q (%s)", resp.Header.Get("Range"), want, body)
+	}
+	conn, err := net.Dial("tcp", strings.TrimPrefix(s.url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(conn, "PUT /upload/random.bin HTTP/1.1\r\nHost: x\r\nContent-Range: bytes 0-%d/%d\r\nContent-Length: %d\r\n\r\n", cut1-1, size, cut1)
+	conn.Write(data[:100])
+	conn.(*net.TCPConn).CloseWrite()
+	if reply, _ := io.ReadAll(conn); !bytes.Contains(reply, []byte("Range: bytes=0-99,")) {
+		t.Errorf("끊긴 요청의 응답 %q", reply)
+	}
+	conn.Close()
+
+	// 재시작해도 받은 범위는 남아 - bytes */전체 로 물어봐
+	s2 := newTestServer(t, server.Config{SessionDir: sessions})
+	loc = s2.url + "/upload/random.bin"
+	resp, body = put(loc, fmt.Sprintf("bytes */%d", size), nil)
+	testutil.ExpectStatus(t, resp, http.StatusAccepted)
+	json.Unmarshal(body, &status)
+	if want := []string{"0-99", fmt.Sprintf("%d-%d", cut2, size-1)}; status.Received != int64(100+size-cut2) || !slices.Equal(status.Ranges, want) {
+		t.Errorf("받은 범위 %s, want %v", body, want)
+	}
+
+	// 전체 크기가 다르면 409, 범위가 전체를 넘으면 400
+	resp, _ = put(loc, fmt.Sprintf("bytes 0-9/%d", size+1), data[:10])
+	testutil.ExpectStatus(t, resp, http.StatusConflict)
+	resp, _ = put(loc, fmt.Sprintf("bytes %d-%d/%d", size-5, size+4, size), data[:10])
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+
+	// 남은 두 조각을 (겹쳐서) 동시에 - 빈틈이 없어지는 요청 하나가 201
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i, rg := range [][2]int{{50, cut1}, {cut1 - 10, cut2}} {
+		wg.Go(func() {
+			resp, _ := put(loc, fmt.Sprintf("bytes %d-%d/%d", rg[0], rg[1]-1, size), data[rg[0]:rg[1]])
+			codes[i] = resp.StatusCode
+		})
+	}
+	wg.Wait()
+	slices.Sort(codes)
+	if !slices.Equal(codes, []int{http.StatusCreated, http.StatusAccepted}) {
+		t.Errorf("상태 %v, want 201 하나와 202 하나", codes)
+	}
+	if sum := testutil.SHA256File(t, filepath.Join(s2.uploadDir, "random.bin")); sum != testutil.SHA256(data) {
+		t.Errorf("저장한 파일 sha256 %s, want %s", sum, testutil.SHA256(data))
+	}
+	resp, body = put(loc, fmt.Sprintf("bytes */%d", size), nil)
+	json.Unmarshal(body, &status)
+	if resp.StatusCode != http.StatusAccepted || status.Received != 0 {
+		t.Errorf("저장한 뒤 %d %s - 세션이 지워져야 해", resp.StatusCode, body)
+	}
+
+	// DELETE 는 받은 것을 버려
+	put(loc, fmt.Sprintf("bytes 0-9/%d", size), data[:10])
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodDelete, loc, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+	entries, _ := os.ReadDir(filepath.Join(sessions, "ranges"))
+	if len(entries) != 0 {
+		t.Errorf("취소한 뒤 ranges/ 에 %d 개가 남음", len(entries))
+	}
+}
+
+func TestE2EUsageQuota(t *testing.T) {
+	s := newTestServer(t, server.Config{UsageFile: filepath.Join(t.TempDir(), "usage.json"), MonthlyQuota: 100 << 10})
+
+	// 64KB 올리고 64KB 받으면 100KB 를 넘으니까 그다음 요청부터 429
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["repeat.txt"])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp = testutil.Get(t, t.Context(), s.fileURL("download", "repeat.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	testutil.ReadBody(t, resp)
+	resp = testutil.Get(t, t.Context(), s.fileURL("download", "repeat.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusTooManyRequests)
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("429 에 Retry-After 가 없음")
+	}
+
+	var u struct {
+		Name                              string
+		Upload, Download, Used, Remaining int64
+	}
+	resp = testutil.Get(t, t.Context(), s.url+"/api/usage")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "anonymous" || u.Upload < 64<<10 || u.Download < 64<<10 || u.Used != u.Upload+u.Download || u.Remaining != 0 {
+		t.Errorf("/api/usage = %+v", u)
+	}
+}
+
+func TestE2EUsageKeys(t *testing.T) {
+	s := newTestServer(t, server.Config{
+		UsageFile: filepath.Join(t.TempDir(), "usage.json"),
+		APIKeys:   map[string]server.APIKey{"secret-a": {Name: "alice"}, "secret-b": {Name: "bob", Monthly: 1}},
+	})
+
+	// 키가 없거나 틀리면 401, 맞으면 핸들러까지 (없는 파일이라 404)
+	for _, header := range [][]string{nil, {"X-API-Key", "wrong"}, {"Authorization", "Bearer wrong"}} {
+		testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.fileURL("download", "none.txt"), header...), http.StatusUnauthorized)
+	}
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.fileURL("download", "none.txt"), "Authorization", "Bearer secret-a"), http.StatusNotFound)
+
+	// bob 은 1바이트 한도 - 404 응답 본문만으로 다 써
+	for _, want := range []int{http.StatusNotFound, http.StatusTooManyRequests} {
+		testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.fileURL("download", "none.txt"), "X-API-Key", "secret-b"), want)
+	}
+	resp := testutil.Get(t, t.Context(), s.url+"/api/usage", "X-API-Key", "secret-a")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var u struct {
+		Name      string
+		Remaining int64
+	}
+	json.Unmarshal(testutil.ReadBody(t, resp), &u)
+	if u.Name != "alice" || u.Remaining != -1 {
+		t.Errorf("/api/usage = %+v", u)
+	}
+}
+
+func TestE2EAuth(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+	s := newTestServer(t, server.Config{
+		UsageFile:   filepath.Join(t.TempDir(), "usage.json"),
+		APIKeys:     map[string]server.APIKey{"key-a": {Name: "alice"}, "key-r": {Name: "reader", Scopes: []string{server.ScopeDownload}}},
+		JWTSecret:   secret,
+		JWTIssuer:   "login",
+		PublicFiles: true,
+	})
+	data, err := os.ReadFile(s.fixtures["repeat.txt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.uploadDir, "repeat.txt"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	token := func(key, alg string, claims map[string]any) string {
+		return "Bearer " + signJWT(t, key, alg, claims)
+	}
+	download := s.fileURL("download", "repeat.txt")
+	upload := func(header ...string) *http.Response {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", "up.txt")
+		part.Write([]byte("hello"))
+		mw.Close()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	// expect 상태 코드와 JSON 본문의 error 에 want 가 들어 있는지
+	expect := func(resp *http.Response, status int, want string) {
+		t.Helper()
+		testutil.ExpectStatus(t, resp, status)
+		body := testutil.ReadBody(t, resp)
+		if status < 400 {
+			return
+		}
+		var e struct{ Error, Scope string }
+		if err := json.Unmarshal(body, &e); err != nil || !strings.Contains(e.Error, want) {
+			t.Errorf("%d 본문 = %s (err %v), want %q", status, body, err, want)
+		}
+		if resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("%d 에 WWW-Authenticate 가 없음", status)
+		}
+	}
+
+	// 자격 증명이 없거나 틀리면 401
+	expect(testutil.Get(t, t.Context(), download), http.StatusUnauthorized, "인증이 필요합니다")
+	expect(testutil.Get(t, t.Context(), download, "X-API-Key", "wrong"), http.StatusUnauthorized, "올바르지 않습니다")
+	expect(testutil.Get(t, t.Context(), s.url+"/api/files"), http.StatusUnauthorized, "인증이 필요합니다")
+	expect(upload(), http.StatusUnauthorized, "인증이 필요합니다")
+
+	// 키 - reader 는 받기만
+	expect(testutil.Get(t, t.Context(), download, "X-API-Key", "key-r"), http.StatusOK, "")
+	expect(upload("X-API-Key", "key-r"), http.StatusForbidden, "upload")
+	expect(upload("X-API-Key", "key-a"), http.StatusOK, "")
+
+	// JWT
+	valid := map[string]any{"sub": "jwt-bob", "iss": "login", "exp": now + 60, "scope": "download"}
+	expect(testutil.Get(t, t.Context(), download, "Authorization", token(secret, "HS256", valid)), http.StatusOK, "")
+	expect(testutil.Get(t, t.Context(), download, "Authorization", token(secret, "HS512", valid)), http.StatusOK, "")
+	expect(upload("Authorization", token(secret, "HS256", valid)), http.StatusForbidden, "upload")
+	for _, c := range []struct {
+		name, key, alg string
+		claims         map[string]any
+		want           string
+	}{
+		{"다른 비밀 키", strings.Repeat("x", 32), "HS256", valid, "서명"},
+		{"alg none", secret, "none", valid, "none"},
+		{"만료", secret, "HS256", map[string]any{"sub": "jwt-bob", "iss": "login", "exp": now - 3600}, "만료"},
+		{"nbf", secret, "HS256", map[string]any{"sub": "jwt-bob", "iss": "login", "nbf": now + 3600}, "nbf"},
+		{"다른 발급자", secret, "HS256", map[string]any{"sub": "jwt-bob", "iss": "evil"}, "iss"},
+		{"sub 없음", secret, "HS256", map[string]any{"iss": "login"}, "sub"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			expect(testutil.Get(t, t.Context(), download, "Authorization", token(c.key, c.alg, c.claims)), http.StatusUnauthorized, c.want)
+		})
+	}
+
+	// 토큰 계정은 sub 이름으로 세
+	resp := testutil.Get(t, t.Context(), s.url+"/api/usage", "Authorization", token(secret, "HS256", valid))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var u struct {
+		Name     string
+		Download int64
+	}
+	json.Unmarshal(testutil.ReadBody(t, resp), &u)
+	if u.Name != "jwt-bob" || u.Download < int64(2*len(data)) {
+		t.Errorf("/api/usage = %+v", u)
+	}
+
+	// /files/ 는 키 없이 열려 있지만, 보낸 키는 확인해
+	expect(testutil.Get(t, t.Context(), s.url+"/files/repeat.txt"), http.StatusOK, "")
+	expect(testutil.Get(t, t.Context(), s.url+"/files/repeat.txt", "X-API-Key", "wrong"), http.StatusUnauthorized, "올바르지 않습니다")
+}
+
+// 서명 URL 은 자격 증명 없이 그 파일만, 만료 전까지만
+func TestE2ECORS(t *testing.T) {
+	s := newTestServer(t, server.Config{
+		APIKeys:     map[string]server.APIKey{"key-a": {Name: "alice"}},
+		CORSOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		CORSMaxAge:  10 * time.Minute,
+	})
+	if err := os.WriteFile(filepath.Join(s.uploadDir, "a.txt"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	do := func(method, url string, header ...string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), method, url, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// preflight 는 자격 증명 없이 와도 인증 앞에서 204
+	resp := do(http.MethodOptions, s.url+"/upload", "Origin", "https://app.example.com",
+		"Access-Control-Request-Method", "POST", "Access-Control-Request-Headers", "authorization, x-expected-sha256")
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+	for k, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, HEAD, POST, PUT, PATCH, DELETE",
+		"Access-Control-Allow-Headers": "authorization, x-expected-sha256",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := resp.Header.Get(k); got != want {
+			t.Errorf("preflight %s = %q, want %q", k, got, want)
+		}
+	}
+	// 하위 도메인 패턴, 허락하지 않은 출처
+	resp = do(http.MethodOptions, s.url+"/upload", "Origin", "https://files.example.org", "Access-Control-Request-Method", "PUT")
+	testutil.ExpectStatus(t, resp, http.StatusNoContent)
+	for _, origin := range []string{"https://evil.example.com", "https://example.org", "http://app.example.com"} {
+		resp = do(http.MethodOptions, s.url+"/upload", "Origin", origin, "Access-Control-Request-Method", "POST")
+		testutil.ExpectStatus(t, resp, http.StatusForbidden)
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q", origin, got)
+		}
+	}
+
+	// 실제 요청은 인증을 거치고, JS 가 Content-Range 와 sha256 을 읽을 수 있게 열어 둬 (401 도 CORS 헤더가 있어야 브라우저가 보여 줘)
+	resp = do(http.MethodGet, s.fileURL("download", "a.txt"), "Origin", "https://app.example.com")
+	testutil.ExpectStatus(t, resp, http.StatusUnauthorized)
+	if resp.Header.Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("401 에 Access-Control-Allow-Origin 이 없음: %v", resp.Header)
+	}
+	resp = do(http.MethodGet, s.fileURL("download", "a.txt"), "Origin", "https://app.example.com", "X-API-Key", "key-a", "Range", "bytes=2-4")
+	testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+	expose := resp.Header.Get("Access-Control-Expose-Headers")
+	for _, h := range []string{"Content-Range", "X-Content-SHA256", "Upload-Offset"} {
+		if !strings.Contains(expose, h) {
+			t.Errorf("Access-Control-Expose-Headers 에 %s 가 없음: %q", h, expose)
+		}
+	}
+	if !slices.Contains(resp.Header.Values("Vary"), "Origin") {
+		t.Errorf("Vary = %v", resp.Header.Values("Vary"))
+	}
+	// Origin 이 없는 요청(curl)은 손대지 않아
+	resp = do(http.MethodGet, s.fileURL("download", "a.txt"), "X-API-Key", "key-a")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if resp.Header.Get("Access-Control-Allow-Origin") != "" || resp.Header.Get("Vary") != "" {
+		t.Errorf("Origin 없는 요청에 CORS 헤더: %v", resp.Header)
+	}
+}
+
+func TestE2ESignedURL(t *testing.T) {
+	s := newTestServer(t, server.Config{
+		APIKeys:    map[string]server.APIKey{"key-r": {Name: "reader", Scopes: []string{server.ScopeDownload}}, "key-u": {Name: "uploader", Scopes: []string{server.ScopeUpload}}},
+		SignSecret: "0123456789abcdef0123456789abcdef",
+		SignMaxTTL: time.Hour,
+	})
+	data, _ := os.ReadFile(s.fixtures["repeat.txt"])
+	os.WriteFile(filepath.Join(s.uploadDir, "repeat.txt"), data, 0o644)
+	sign := func(query string, status int, key string) (signed struct {
+		URL     string    `json:"url"`
+		Path    string    `json:"path"`
+		Expires time.Time `json:"expires"`
+	}) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/sign?"+query, nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, status)
+		json.Unmarshal(testutil.ReadBody(t, resp), &signed)
+		return signed
+	}
+
+	signed := sign("file=repeat.txt&ttl=10m", http.StatusOK, "key-r")
+	if signed.URL != s.url+signed.Path || time.Until(signed.Expires) > 10*time.Minute {
+		t.Fatalf("서명 응답 = %+v", signed)
+	}
+	resp := testutil.Get(t, t.Context(), signed.URL)
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if body := testutil.ReadBody(t, resp); !bytes.Equal(body, data) {
+		t.Errorf("서명 URL 로 받은 내용이 다름 (%d 바이트)", len(body))
+	}
+	resp = testutil.Get(t, t.Context(), signed.URL, "Range", "bytes=0-9")
+	testutil.ExpectStatus(t, resp, http.StatusPartialContent)
+	resp.Body.Close()
+
+	// 다른 파일, 늘린 만료, 서명 없음
+	u, _ := url.Parse(signed.URL)
+	q := u.Query()
+	for name, change := range map[string]func(url.Values){
+		"file": func(v url.Values) { v.Set("file", "app.log") },
+		"exp":  func(v url.Values) { v.Set("exp", fmt.Sprint(signed.Expires.Unix()+3600)) },
+		"sig":  func(v url.Values) { v.Set("sig", "x"+v.Get("sig")[1:]) },
+	} {
+		v := url.Values{}
+		for k, vs := range q {
+			v[k] = slices.Clone(vs)
+		}
+		change(v)
+		resp := testutil.Get(t, t.Context(), s.url+"/download?"+v.Encode())
+		if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(testutil.ReadBody(t, resp)), "서명이 올바르지 않습니다") {
+			t.Errorf("%s 를 바꾼 URL = %d", name, resp.StatusCode)
+		}
+	}
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.fileURL("download", "repeat.txt")), http.StatusUnauthorized)
+
+	// 만료 - 과거 시각으로 서명한 URL
+	old := time.Now().Add(-time.Minute).Unix()
+	expired := url.Values{"file": {"repeat.txt"}, "exp": {fmt.Sprint(old)}}
+	mac := hmac.New(sha256.New, []byte("0123456789abcdef0123456789abcdef"))
+	fmt.Fprintf(mac, "GET /download\n%s\n%d", "repeat.txt", old)
+	expired.Set("sig", base64.RawURLEncoding.EncodeToString(mac.Sum(nil)))
+	resp = testutil.Get(t, t.Context(), s.url+"/download?"+expired.Encode())
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(testutil.ReadBody(t, resp)), "만료") {
+		t.Errorf("만료된 URL = %d", resp.StatusCode)
+	}
+
+	// 발급 쪽 - 다운로드 권한, 있는 파일, 최대 ttl
+	sign("file=repeat.txt", http.StatusForbidden, "key-u")
+	sign("file=nope.txt", http.StatusNotFound, "key-r")
+	sign("file=repeat.txt&ttl=2h", http.StatusBadRequest, "key-r")
+	sign("file=repeat.txt&ttl=-1m", http.StatusBadRequest, "key-r")
+}
+
+// signJWT HMAC 으로 서명한 JWT (alg 가 HS 계열이 아니면 서명 없이)
+func signJWT(t *testing.T, key, alg string, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := enc(map[string]string{"alg": alg, "typ": "JWT"}) + "." + enc(claims)
+	hashes := map[string]func() hash.Hash{"HS256": sha256.New, "HS512": sha512.New}
+	if hashes[alg] == nil {
+		return signed + "."
+	}
+	mac := hmac.New(hashes[alg], []byte(key))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestE2EFileManagement(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	s := newTestServer(t, server.Config{
+		AuditLog: auditPath,
+		APIKeys:  map[string]server.APIKey{"key-a": {Name: "admin"}, "key-r": {Name: "reader", Scopes: []string{server.ScopeDownload}}},
+	})
+	for _, name := range []string{"app.log", "repeat.txt"} {
+		data, err := os.ReadFile(s.fixtures[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(s.uploadDir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	do := func(method, path, key string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), method, s.url+path, nil)
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set("X-Request-ID", "req-"+method)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	expect := func(resp *http.Response, status int) []byte {
+		t.Helper()
+		testutil.ExpectStatus(t, resp, status)
+		return testutil.ReadBody(t, resp)
+	}
+
+	// 읽기 권한만 있으면 못 바꿔
+	expect(do(http.MethodDelete, "/api/files/app.log", "key-r"), http.StatusForbidden)
+	expect(do(http.MethodPost, "/api/files/app.log/rename?to=x.log", "key-r"), http.StatusForbidden)
+
+	// 이름 바꾸기 - 내용은 그대로, 옛 이름은 404
+	body := expect(do(http.MethodPost, "/api/files/app.log/rename?to=app-old.log", "key-a"), http.StatusOK)
+	var renamed struct{ File, From string }
+	if err := json.Unmarshal(body, &renamed); err != nil || renamed.File != "app-old.log" || renamed.From != "app.log" {
+		t.Errorf("rename 본문 = %s (err %v)", body, err)
+	}
+	resp := testutil.Get(t, t.Context(), s.fileURL("download", "app-old.log"), "X-API-Key", "key-r")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if got, want := testutil.SHA256(testutil.ReadBody(t, resp)), testutil.SHA256File(t, s.fixtures["app.log"]); got != want {
+		t.Errorf("바꾼 이름의 sha256 = %s, want %s", got, want)
+	}
+	expect(testutil.Get(t, t.Context(), s.fileURL("download", "app.log"), "X-API-Key", "key-r"), http.StatusNotFound)
+
+	// 덮어쓰지 않고 409, 없는 파일은 404, 잘못된 이름은 400
+	expect(do(http.MethodPost, "/api/files/app-old.log/rename?to=repeat.txt", "key-a"), http.StatusConflict)
+	expect(do(http.MethodPost, "/api/files/nope.txt/rename?to=x.txt", "key-a"), http.StatusNotFound)
+	expect(do(http.MethodPost, "/api/files/repeat.txt/rename?to=repeat.txt", "key-a"), http.StatusBadRequest)
+	expect(do(http.MethodPost, "/api/files/repeat.txt/rename?to=..", "key-a"), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/api/files/repeat.txt/rename?to=x.txt", "key-a"), http.StatusMethodNotAllowed)
+
+	// 지우기 - 휴지통 ID 를 돌려주고, 두 번째는 404
+	body = expect(do(http.MethodDelete, "/api/files/repeat.txt", "key-a"), http.StatusOK)
+	var deleted struct {
+		File    string
+		TrashID string `json:"trash_id"`
+	}
+	if err := json.Unmarshal(body, &deleted); err != nil || deleted.File != "repeat.txt" || deleted.TrashID == "" {
+		t.Errorf("delete 본문 = %s (err %v)", body, err)
+	}
+	expect(do(http.MethodDelete, "/api/files/repeat.txt", "key-a"), http.StatusNotFound)
+
+	// 감사 로그 - 권한에서 막힌 건 빼고 바꾸려 한 요청마다 한 줄
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type entry struct {
+		RequestID string `json:"request_id"`
+		Account   string `json:"account"`
+		ClientIP  string `json:"client_ip"`
+		Action    string `json:"action"`
+		File      string `json:"file"`
+		To        string `json:"to"`
+		TrashID   string `json:"trash_id"`
+		Status    int    `json:"status"`
+		Error     string `json:"error"`
+	}
+	want := []entry{
+		{Action: server.AuditRename, File: "app.log", To: "app-old.log", Status: http.StatusOK},
+		{Action: server.AuditRename, File: "app-old.log", To: "repeat.txt", Status: http.StatusConflict},
+		{Action: server.AuditRename, File: "nope.txt", To: "x.txt", Status: http.StatusNotFound},
+		{Action: server.AuditDelete, File: "repeat.txt", TrashID: deleted.TrashID, Status: http.StatusOK},
+		{Action: server.AuditDelete, File: "repeat.txt", Status: http.StatusNotFound},
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("감사 로그 %d 줄, want %d:\n%s", len(lines), len(want), data)
+	}
+	for i, line := range lines {
+		var got entry
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Account != "admin" || got.ClientIP != "127.0.0.1" || !strings.HasPrefix(got.RequestID, "req-") {
+			t.Errorf("%d: 누가 = %+v", i, got)
+		}
+		if (got.Status != http.StatusOK) != (got.Error != "") {
+			t.Errorf("%d: status %d 인데 error %q", i, got.Status, got.Error)
+		}
+		got.RequestID, got.Account, got.ClientIP, got.Error = "", "", "", ""
+		if got != want[i] {
+			t.Errorf("%d: %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestE2EFileVersions(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	versionDir := t.TempDir()
+	s := newTestServer(t, server.Config{Versions: 2, VersionDir: versionDir, AuditLog: auditPath})
+	dir := t.TempDir()
+	put := func(content string) {
+		t.Helper()
+		path := filepath.Join(dir, "v.txt")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", path)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	do := func(method, path string, status int) []byte {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), method, s.url+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, status)
+		return testutil.ReadBody(t, resp)
+	}
+	list := func() (out []int) {
+		t.Helper()
+		var body struct {
+			Versions []struct {
+				Version int   `json:"version"`
+				Size    int64 `json:"size"`
+			} `json:"versions"`
+		}
+		json.Unmarshal(do(http.MethodGet, "/api/files/v.txt/versions", http.StatusOK), &body)
+		for _, v := range body.Versions {
+			out = append(out, v.Version)
+		}
+		return out
+	}
+
+	// 처음 올린 건 버전이 없고, 덮어쓸 때마다 하나씩 - 두 개만 남겨
+	put("one")
+	if got := list(); len(got) != 0 {
+		t.Fatalf("버전 %v, want 없음", got)
+	}
+	put("two")
+	// 범위 올리기(PUT /upload/<이름>)로 덮어써도 남아
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, s.url+"/upload/v.txt", strings.NewReader("three"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	resp.Body.Close()
+	put("four")
+	if got := list(); !slices.Equal(got, []int{3, 2}) {
+		t.Fatalf("버전 %v, want [3 2] (최근 것부터, 두 개만)", got)
+	}
+	if got := string(do(http.MethodGet, "/api/files/v.txt/versions/3", http.StatusOK)); got != "three" {
+		t.Errorf("버전 3 = %q", got)
+	}
+	do(http.MethodGet, "/api/files/v.txt/versions/1", http.StatusNotFound)
+
+	// 되돌리면 지금 내용도 버전으로 남아
+	var restored struct {
+		File    string `json:"file"`
+		Version int    `json:"version"`
+		SHA256  string `json:"sha256"`
+	}
+	json.Unmarshal(do(http.MethodPost, "/api/files/v.txt/versions/2/restore", http.StatusOK), &restored)
+	if restored.File != "v.txt" || restored.Version != 2 || restored.SHA256 != testutil.SHA256([]byte("two")) {
+		t.Errorf("되돌리기 응답 %+v", restored)
+	}
+	if got := string(do(http.MethodGet, s.fileURL("/download", "v.txt")[len(s.url):], http.StatusOK)); got != "two" {
+		t.Errorf("되돌린 뒤 내용 %q, want two", got)
+	}
+	if got := list(); !slices.Equal(got, []int{4, 3}) {
+		t.Errorf("되돌린 뒤 버전 %v, want [4 3]", got)
+	}
+	if got := string(do(http.MethodGet, "/api/files/v.txt/versions/4", http.StatusOK)); got != "four" {
+		t.Errorf("버전 4 = %q", got)
+	}
+	do(http.MethodPost, "/api/files/v.txt/versions/1/restore", http.StatusNotFound)
+	do(http.MethodGet, "/api/files/v.txt/versions/2/restore", http.StatusMethodNotAllowed)
+	do(http.MethodGet, "/api/files/v.txt/versions/x", http.StatusBadRequest)
+	do(http.MethodGet, "/api/files/v.txt/other", http.StatusBadRequest)
+
+	// 지워도 버전은 남아서 되돌릴 수 있어
+	do(http.MethodDelete, "/api/files/v.txt", http.StatusOK)
+	do(http.MethodPost, "/api/files/v.txt/versions/3/restore", http.StatusOK)
+	if got := string(do(http.MethodGet, s.fileURL("/download", "v.txt")[len(s.url):], http.StatusOK)); got != "three" {
+		t.Errorf("지운 뒤 되돌린 내용 %q, want three", got)
+	}
+
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(audit, []byte(`"action":"restore"`)); n != 3 || !bytes.Contains(audit, []byte(`"version":2`)) {
+		t.Errorf("감사 로그에 restore %d 줄\n%s", n, audit)
+	}
+
+	// Versions 가 0 이면 안 남겨
+	offDir := filepath.Join(t.TempDir(), "versions")
+	off := newTestServer(t, server.Config{VersionDir: offDir})
+	for range 2 {
+		resp := testutil.Upload(t, t.Context(), off.url+"/upload", "file", off.fixtures["repeat.txt"])
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	if _, err := os.Stat(offDir); err == nil {
+		t.Error("Versions 가 0 인데 버전 디렉토리가 생김")
+	}
+}
+
+// 지운 파일은 휴지통에서 되살리고, 보관 기간이 지나면 만료 청소가 영구 삭제
+func TestE2ETrash(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	trashDir := t.TempDir()
+	cfg := server.Config{UploadDir: t.TempDir(), TrashDir: trashDir, SessionDir: t.TempDir(), TrashRetention: time.Hour, ExpireScan: 50 * time.Millisecond, AuditLog: auditPath, Logger: slog.New(slog.DiscardHandler)}
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+	base := "http://" + ln.Addr().String()
+
+	do := func(method, path, body string, status int) []byte {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), method, base+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, status)
+		return testutil.ReadBody(t, resp)
+	}
+	type entry struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Size      int64     `json:"size"`
+		DeletedAt time.Time `json:"deleted_at"`
+		PurgeAt   time.Time `json:"purge_at"`
+	}
+	list := func() map[string]entry {
+		t.Helper()
+		var body struct {
+			Items []entry `json:"items"`
+		}
+		json.Unmarshal(do(http.MethodGet, "/api/trash", "", http.StatusOK), &body)
+		out := map[string]entry{}
+		for _, e := range body.Items {
+			out[e.Name] = e
+		}
+		return out
+	}
+
+	// 다른 곳(dirsync, trash 도구)에서 온 항목은 안 보여
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	os.WriteFile(outside, []byte("밖"), 0o644)
+	shared, err := fstree.OpenTrash(trashDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := shared.Delete(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do(http.MethodPut, "/upload/a.txt", "가나다", http.StatusCreated)
+	do(http.MethodPut, "/upload/b.txt", "b", http.StatusCreated)
+	do(http.MethodDelete, "/api/files/a.txt", "", http.StatusOK)
+	do(http.MethodDelete, "/api/files/b.txt", "", http.StatusOK)
+	items := list()
+	a, b := items["a.txt"], items["b.txt"]
+	if len(items) != 2 || a.ID == "" || a.Size != int64(len("가나다")) || a.PurgeAt.Sub(a.DeletedAt) != time.Hour {
+		t.Fatalf("휴지통 %+v", items)
+	}
+
+	// 되살리면 내용이 돌아오고 목록에서 빠져
+	do(http.MethodPost, "/api/trash/"+url.PathEscape(a.ID)+"/restore", "", http.StatusOK)
+	if got := string(do(http.MethodGet, "/download?file=a.txt", "", http.StatusOK)); got != "가나다" {
+		t.Errorf("되살린 내용 %q", got)
+	}
+	if _, ok := list()["a.txt"]; ok {
+		t.Error("되살렸는데 휴지통에 남음")
+	}
+	do(http.MethodPost, "/api/trash/"+url.PathEscape(a.ID)+"/restore", "", http.StatusNotFound)
+	do(http.MethodPost, "/api/trash/"+url.PathEscape(foreign.ID)+"/restore", "", http.StatusNotFound)
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("다른 곳의 항목이 되살아남 (err=%v)", err)
+	}
+
+	// 같은 이름이 다시 생겼으면 덮어쓰지 않고 409
+	do(http.MethodPut, "/upload/b.txt", "새 b", http.StatusCreated)
+	do(http.MethodPost, "/api/trash/"+url.PathEscape(b.ID)+"/restore", "", http.StatusConflict)
+	do(http.MethodGet, "/api/trash/"+url.PathEscape(b.ID)+"/restore", "", http.StatusMethodNotAllowed)
+	do(http.MethodGet, "/api/trash/"+url.PathEscape(b.ID), "", http.StatusBadRequest)
+
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(audit, []byte(`"action":"restore"`)); n != 2 || !bytes.Contains(audit, []byte(`"trash_id":"`+a.ID+`"`)) {
+		t.Errorf("감사 로그에 restore %d 줄\n%s", n, audit)
+	}
+
+	// 보관 기간을 줄이면(SIGHUP) 다음 청소에서 지나간 항목을 영구 삭제 - 다른 곳의 항목은 그대로
+	cfg.TrashRetention = time.Millisecond
+	srv.Reload(cfg)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(list()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if items := list(); len(items) != 0 {
+		t.Errorf("보관 기간이 지났는데 남음: %+v", items)
+	}
+	left, _ := shared.List()
+	if len(left) != 1 || left[0].ID != foreign.ID {
+		t.Errorf("휴지통에 남은 것 %+v, want 다른 곳의 항목 하나", left)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestE2EExpiringUploads(t *testing.T) {
+	sessionDir, versionDir := t.TempDir(), t.TempDir()
+	cfg := server.Config{UploadDir: t.TempDir(), TrashDir: t.TempDir(), SessionDir: sessionDir, VersionDir: versionDir, Versions: 3, ExpireScan: 50 * time.Millisecond, Logger: slog.New(slog.DiscardHandler)}
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+	base := "http://" + ln.Addr().String()
+
+	// put 범위 올리기(PUT /upload/<이름>)로 통째로 - 만료 헤더를 돌려줘
+	put := func(name, body, expire string, status int) string {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, base+"/upload/"+name, strings.NewReader(body))
+		if expire != "" {
+			req.Header.Set("X-Expire-After", expire)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, status)
+		resp.Body.Close()
+		return resp.Header.Get("X-Expires")
+	}
+
+	if exp := put("keep.txt", "keep", "", http.StatusCreated); exp != "" {
+		t.Errorf("헤더 없이 올렸는데 X-Expires: %s", exp)
+	}
+	exp := put("temp.txt", "one", "1", http.StatusCreated)
+	if at, err := http.ParseTime(exp); err != nil || time.Until(at) > 2*time.Second {
+		t.Errorf("X-Expires = %q (%v), want 1초 뒤", exp, err)
+	}
+	put("temp.txt", "two", "1s", http.StatusCreated) // 덮어써서 버전도 하나
+	put("again.txt", "again", "1", http.StatusCreated)
+	put("again.txt", "again", "", http.StatusCreated) // 헤더 없이 다시 올리면 안 지워
+	put("moved.txt", "moved", "1", http.StatusCreated)
+	for _, bad := range []string{"abc", "0", "-5s"} {
+		put("bad.txt", "bad", bad, http.StatusBadRequest)
+	}
+
+	// /upload 는 JSON 에 expires
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "form.txt")
+	part.Write([]byte("form"))
+	mw.Close()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, base+"/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Expire-After", "1s")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var uploaded struct {
+		Files []struct {
+			Expires time.Time `json:"expires"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &uploaded); err != nil || len(uploaded.Files) != 1 || uploaded.Files[0].Expires.IsZero() {
+		t.Errorf("/upload 응답 %+v (%v), want expires", uploaded, err)
+	}
+
+	// 이름을 바꾸면 만료도 따라가
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodPost, base+"/api/files/moved.txt/rename?to=renamed.txt", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(cfg.UploadDir, name))
+		return err == nil
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for (exists("temp.txt") || exists("form.txt") || exists("renamed.txt")) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	for _, name := range []string{"temp.txt", "form.txt", "renamed.txt"} {
+		if exists(name) {
+			t.Errorf("%s 가 만료됐는데 남아 있음", name)
+		}
+	}
+	for _, name := range []string{"keep.txt", "again.txt"} {
+		if !exists(name) {
+			t.Errorf("%s 는 만료가 없는데 지워짐", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(versionDir, "temp.txt")); err == nil {
+		t.Error("만료된 파일의 버전이 남아 있음")
+	}
+	trash, err := fstree.OpenTrash(cfg.TrashDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := trash.List(); len(items) != 0 {
+		t.Errorf("만료된 파일은 휴지통을 거치지 않아야 해: %d 개", len(items))
+	}
+
+	// ExpireAfter 가 있으면 헤더 없이도 지우고, 헤더로는 그보다 길게 못 잡아
+	cfg.ExpireAfter = time.Hour
+	srv.Reload(cfg)
+	for _, expire := range []string{"", "48h"} {
+		at, err := http.ParseTime(put("default.txt", "x", expire, http.StatusCreated))
+		if err != nil || time.Until(at) > time.Hour || time.Until(at) < 59*time.Minute {
+			t.Errorf("X-Expire-After %q: 만료 %v (%v), want 1시간 뒤", expire, at, err)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve = %v", err)
+	}
+
+	// 만료 기록은 SessionDir 에 남아서 재시작해도 이어져
+	data, err := os.ReadFile(filepath.Join(sessionDir, "expiry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left map[string]time.Time
+	if err := json.Unmarshal(data, &left); err != nil || len(left) != 1 || left["default.txt"].IsZero() {
+		t.Errorf("expiry.json = %s (%v), want default.txt 만", data, err)
+	}
+}
+
+// writeTS seconds 초짜리 MPEG-TS - 10ms 마다 188 바이트 패킷, 100ms 마다 PCR, 2초마다 키프레임 표시
+func writeTS(t *testing.T, path string, seconds int) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	for i := range seconds * 100 {
+		p := bytes.Repeat([]byte{byte(i)}, 188)
+		p[0], p[1], p[2], p[3] = 0x47, 0x01, 0x00, 0x10
+		if i%10 == 0 {
+			base := int64(i) * 900 // 90kHz
+			p[3], p[4], p[5] = 0x30, 7, 0x10
+			if i%200 == 0 {
+				p[5] |= 0x40
+			}
+			p[6], p[7], p[8], p[9], p[10], p[11] = byte(base>>25), byte(base>>17), byte(base>>9), byte(base>>1), byte(base&1)<<7|0x7e, 0
+		}
+		b.Write(p)
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestE2EHLS(t *testing.T) {
+	cfg := server.Config{
+		HLS:        true,
+		APIKeys:    map[string]server.APIKey{"key-r": {Name: "reader", Scopes: []string{server.ScopeDownload}}},
+		SignSecret: "0123456789abcdef0123456789abcdef",
+	}
+	s := newTestServer(t, cfg)
+	data := writeTS(t, filepath.Join(s.uploadDir, "movie.ts"), 20)
+	os.WriteFile(filepath.Join(s.uploadDir, "note.txt"), []byte("not a video"), 0o644)
+
+	// 재생 목록의 조각마다 같은 URI 에 Range 로 받아서 원본 그대로인지
+	play := func(playlist string, header ...string) int {
+		t.Helper()
+		resp := testutil.Get(t, t.Context(), playlist, header...)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		if ct := resp.Header.Get("Content-Type"); ct != "application/vnd.apple.mpegurl" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		lines := strings.Split(strings.TrimSpace(string(testutil.ReadBody(t, resp))), "\n")
+		if lines[0] != "#EXTM3U" || lines[len(lines)-1] != "#EXT-X-ENDLIST" {
+			t.Fatalf("재생 목록 = %q", lines)
+		}
+		segments := 0
+		for i, line := range lines {
+			v, ok := strings.CutPrefix(line, "#EXT-X-BYTERANGE:")
+			if !ok {
+				continue
+			}
+			var length, offset int64
+			fmt.Sscanf(v, "%d@%d", &length, &offset)
+			seg := testutil.Get(t, t.Context(), s.url+lines[i+1], append([]string{"Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}, header...)...)
+			testutil.ExpectStatus(t, seg, http.StatusPartialContent)
+			if !bytes.Equal(testutil.ReadBody(t, seg), data[offset:offset+length]) {
+				t.Errorf("조각 %d@%d 내용이 원본과 다름", length, offset)
+			}
+			segments++
+		}
+		return segments
+	}
+	if n := play(s.url+"/hls?file=movie.ts", "X-API-Key", "key-r"); n != 4 {
+		t.Errorf("조각 %d개, want 4 (20초를 6초씩)", n)
+	}
+
+	// 서명 URL 을 /hls 에 붙이면 조각에도 붙어서 키 없이 재생
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/sign?file=movie.ts", nil)
+	req.Header.Set("X-API-Key", "key-r")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signed struct{ Path string }
+	json.Unmarshal(testutil.ReadBody(t, resp), &signed)
+	play(s.url + strings.Replace(signed.Path, "/download?", "/hls?", 1))
+
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.url+"/hls?file=movie.ts"), http.StatusUnauthorized)
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.url+"/hls?file=note.txt", "X-API-Key", "key-r"), http.StatusUnsupportedMediaType)
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.url+"/hls?file=none.ts", "X-API-Key", "key-r"), http.StatusNotFound)
+
+	// 끄면 404 (SIGHUP)
+	cfg.HLS = false
+	s.srv.Reload(cfg)
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.url+"/hls?file=movie.ts", "X-API-Key", "key-r"), http.StatusNotFound)
+}
+
+func TestE2EThumbnails(t *testing.T) {
+	s := newTestServer(t, server.Config{Thumbnails: true, ThumbWorkers: 1})
+	src := image.NewNRGBA(image.Rect(0, 0, 1000, 600))
+	for i := range src.Pix {
+		src.Pix[i] = 0xff
+	}
+	photo := filepath.Join(t.TempDir(), "photo.png")
+	f, err := os.Create(photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, src); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	for _, path := range []string{photo, s.fixtures["app.log"]} {
+		resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", path)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		resp.Body.Close()
+	}
+	thumbURL := func(name, size string) string {
+		return s.url + "/thumb?file=" + url.QueryEscape(name) + "&size=" + size
+	}
+	// waitThumb 백그라운드에서 만들 때까지 (그동안은 404 + Retry-After)
+	waitThumb := func(name, size string) image.Config {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			resp := testutil.Get(t, t.Context(), thumbURL(name, size))
+			body := testutil.ReadBody(t, resp)
+			if resp.StatusCode == http.StatusOK {
+				if ct := resp.Header.Get("Content-Type"); ct != "image/jpeg" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				cfg, err := jpeg.DecodeConfig(bytes.NewReader(body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return cfg
+			}
+			if resp.StatusCode != http.StatusNotFound || resp.Header.Get("Retry-After") == "" {
+				t.Fatalf("%s %s: %d %s", name, size, resp.StatusCode, body)
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s %s 썸네일을 기다렸지만 없음", name, size)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	for _, c := range []struct {
+		size string
+		w, h int
+	}{{"small", 128, 76}, {"medium", 512, 307}} {
+		if cfg := waitThumb("photo.png", c.size); cfg.Width != c.w || cfg.Height != c.h {
+			t.Errorf("%s = %d×%d, want %d×%d", c.size, cfg.Width, cfg.Height, c.w, c.h)
+		}
+	}
+	// 썸네일 디렉토리는 목록에 안 나와
+	if body := testutil.ReadBody(t, testutil.Get(t, t.Context(), s.url+"/api/files")); bytes.Contains(body, []byte(".thumbs")) {
+		t.Errorf("목록에 썸네일이 보임: %s", body)
+	}
+
+	for _, c := range []struct {
+		url    string
+		status int
+	}{
+		{thumbURL("app.log", "small"), http.StatusUnsupportedMediaType},
+		{thumbURL("nope.png", "small"), http.StatusNotFound},
+		{thumbURL("photo.png", "huge"), http.StatusBadRequest},
+		{thumbURL("../photo.png", "small"), http.StatusBadRequest},
+	} {
+		resp := testutil.Get(t, t.Context(), c.url)
+		testutil.ExpectStatus(t, resp, c.status)
+		resp.Body.Close()
+	}
+
+	// 이름을 바꾸면 따라가고, 지우면 사라져
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/files/photo.png/rename?to=moved.png", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	if cfg := waitThumb("moved.png", "small"); cfg.Width != 128 {
+		t.Errorf("옮긴 썸네일 너비 = %d", cfg.Width)
+	}
+	resp = testutil.Get(t, t.Context(), thumbURL("photo.png", "small"))
+	testutil.ExpectStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodDelete, s.url+"/api/files/moved.png", nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	for _, size := range []string{"small", "medium"} {
+		if _, err := os.Stat(filepath.Join(s.uploadDir, ".thumbs", "moved.png."+size+".jpg")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("지운 뒤에도 %s 썸네일: %v", size, err)
+		}
+	}
+
+	off := newTestServer(t, server.Config{})
+	resp = testutil.Get(t, t.Context(), off.url+"/thumb?file=photo.png")
+	testutil.ExpectStatus(t, resp, http.StatusNotFound)
+	resp.Body.Close()
+}
+
+// corruptStorage 쓰는 내용의 첫 바이트를 뒤집어 - /api/copy 의 검증이 잡아내는지
+type corruptStorage struct{ storage.Storage }
+
+func (c corruptStorage) Create(ctx context.Context, name string) (storage.Writer, error) {
+	w, err := c.Storage.Create(ctx, name)
+	return &corruptWriter{Writer: w}, err
+}
+
+type corruptWriter struct {
+	storage.Writer
+	done bool
+}
+
+func (w *corruptWriter) Write(p []byte) (int, error) {
+	if !w.done && len(p) > 0 {
+		w.done = true
+		p = slices.Clone(p)
+		p[0] ^= 0xff
+	}
+	return w.Writer.Write(p)
+}
+
+func TestE2ECopyBetweenBackends(t *testing.T) {
+	archive := storage.NewMemory()
+	broken := storage.NewMemory()
+	backupDir := t.TempDir()
+	s := newTestServer(t, server.Config{
+		Targets:    map[string]storage.Storage{"archive": archive, "broken": corruptStorage{broken}},
+		TargetURLs: map[string]string{"backup": backupDir},
+	})
+	const name = "random.bin"
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures[name])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	want := testutil.SHA256File(t, s.fixtures[name])
+
+	copyFile := func(query string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/copy?"+query, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	// expectCopy 200 이고 응답의 sha256 이 원본과 같은지
+	expectCopy := func(query, dest string) {
+		t.Helper()
+		resp := copyFile(query)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		var got struct {
+			Dest   string
+			Bytes  int64
+			SHA256 string
+		}
+		if err := json.Unmarshal(testutil.ReadBody(t, resp), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Dest != dest || got.Bytes != 3<<20+7 || got.SHA256 != want || resp.Header.Get("X-Content-SHA256") != want {
+			t.Errorf("%s: %+v, want dest %s, sha256 %s", query, got, dest, want)
+		}
+	}
+	storedSum := func(st storage.Storage, name string) string {
+		t.Helper()
+		rc, err := st.Open(t.Context(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return testutil.SHA256(data)
+	}
+
+	t.Run("uploads→archive", func(t *testing.T) {
+		// 진행률은 업로드와 같은 /api/events 로
+		events := testutil.Get(t, t.Context(), s.url+"/api/events?id=cp")
+		testutil.ExpectStatus(t, events, http.StatusOK)
+		defer events.Body.Close()
+
+		expectCopy("file=random.bin&to=archive&id=cp", name)
+		if got := storedSum(archive, name); got != want {
+			t.Errorf("archive 의 sha256 = %s, want %s", got, want)
+		}
+		var types []string
+		sc := bufio.NewScanner(events.Body)
+		for sc.Scan() {
+			data, ok := strings.CutPrefix(sc.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var ev struct{ Type string }
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				t.Fatal(err)
+			}
+			types = append(types, ev.Type)
+			if ev.Type == "complete" || ev.Type == "error" {
+				break
+			}
+		}
+		if len(types) == 0 || types[0] != "start" || types[len(types)-1] != "complete" {
+			t.Errorf("이벤트 = %v, want start … complete", types)
+		}
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		resp := copyFile("file=random.bin&to=archive")
+		testutil.ExpectStatus(t, resp, http.StatusConflict)
+		resp.Body.Close()
+		expectCopy("file=random.bin&to=archive&overwrite=1", name)
+	})
+
+	t.Run("archive→uploads", func(t *testing.T) {
+		expectCopy("file=random.bin&from=archive&to=uploads&as=restored.bin", "restored.bin")
+		resp := testutil.Get(t, t.Context(), s.fileURL("download", "restored.bin"))
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		if got := testutil.SHA256(testutil.ReadBody(t, resp)); got != want {
+			t.Errorf("되돌린 파일 sha256 = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("uploads→dir", func(t *testing.T) {
+		expectCopy("file=random.bin&to=backup", name)
+		if got := testutil.SHA256File(t, filepath.Join(backupDir, name)); got != want {
+			t.Errorf("backup 의 sha256 = %s, want %s", got, want)
+		}
+	})
+
+	// 대상에 쓴 내용이 다르면 지우고 502
+	t.Run("verify", func(t *testing.T) {
+		resp := copyFile("file=random.bin&to=broken")
+		testutil.ExpectStatus(t, resp, http.StatusBadGateway)
+		resp.Body.Close()
+		if _, err := broken.Stat(t.Context(), name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("검증에 실패한 파일이 남음: %v", err)
+		}
+	})
+
+	t.Run("bad", func(t *testing.T) {
+		for _, c := range []struct {
+			query  string
+			status int
+		}{
+			{"file=random.bin&to=nowhere", http.StatusBadRequest},
+			{"file=random.bin&from=nowhere&to=archive", http.StatusBadRequest},
+			{"file=random.bin&to=uploads", http.StatusBadRequest},
+			{"file=../random.bin&to=archive", http.StatusBadRequest},
+			{"file=nope.bin&to=archive", http.StatusNotFound},
+		} {
+			resp := copyFile(c.query)
+			testutil.ExpectStatus(t, resp, c.status)
+			resp.Body.Close()
+		}
+	})
+}
+
+func TestE2EStorageQuota(t *testing.T) {
+	const limit = 600 << 10 // app.log(512KB+123) + repeat.txt(64KB) 는 들어가고 random.bin(3MB) 은 안 들어가
+	storage := filepath.Join(t.TempDir(), "storage.json")
+	s := newTestServer(t, server.Config{StorageFile: storage, StorageQuota: limit})
+	size := func(name string) int64 {
+		fi, err := os.Stat(s.fixtures[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi.Size()
+	}
+	upload := func(name string, want int) []byte {
+		t.Helper()
+		resp := testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures[name])
+		testutil.ExpectStatus(t, resp, want)
+		return testutil.ReadBody(t, resp)
+	}
+	type quota struct {
+		Account   string `json:"account"`
+		Limit     int64  `json:"limit"`
+		Used      int64  `json:"used"`
+		Remaining int64  `json:"remaining"`
+		Requested int64  `json:"requested"`
+	}
+
+	upload("app.log", http.StatusOK)
+	upload("repeat.txt", http.StatusOK)
+	used := size("app.log") + size("repeat.txt")
+
+	// 넘치면 413 + 남은 공간, 받던 파일은 남지 않아
+	var q quota
+	if err := json.Unmarshal(upload("random.bin", http.StatusRequestEntityTooLarge), &q); err != nil {
+		t.Fatal(err)
+	}
+	if want := (quota{Account: "anonymous", Limit: limit, Used: used, Remaining: limit - used}); q != want {
+		t.Errorf("413 본문 = %+v, want %+v", q, want)
+	}
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "random.bin")); !os.IsNotExist(err) {
+		t.Errorf("한도를 넘은 파일이 남았어: %v", err)
+	}
+
+	// 자기 파일을 덮어쓰는 건 그 크기만큼 돌려받아서 돼
+	upload("app.log", http.StatusOK)
+
+	// 이어 올리기는 Upload-Length 로 미리 거절
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file=random.bin", nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", fmt.Sprint(size("random.bin")))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+	q = quota{}
+	json.Unmarshal(testutil.ReadBody(t, resp), &q)
+	if q.Requested != size("random.bin") || q.Remaining != limit-used {
+		t.Errorf("이어 올리기 413 본문 = %+v", q)
+	}
+
+	// 지우면 그만큼 다시 쓸 수 있고, 기록은 재시작해도 남아
+	req, _ = http.NewRequestWithContext(t.Context(), http.MethodDelete, s.fileURL("delete", "repeat.txt"), nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+	again, err := server.New(server.Config{UploadDir: s.uploadDir, TrashDir: t.TempDir(), SessionDir: t.TempDir(), StorageFile: storage, StorageQuota: size("app.log") + 1, Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(again.Handler())
+	defer srv.Close()
+	resp = testutil.Upload(t, t.Context(), srv.URL+"/upload", "file", s.fixtures["repeat.txt"])
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+	resp.Body.Close()
+}
+
+func TestE2EServeListener(t *testing.T) {
+	srv, err := server.New(server.Config{UploadDir: t.TempDir(), TrashDir: t.TempDir(), SessionDir: t.TempDir(), Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/api/files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve = %v", err)
+	}
+}
+
+func TestE2EDownloadThrottle(t *testing.T) {
+	const rate = 256 << 10 // repeat.txt(64KB) 는 0.25초
+	s := newTestServer(t, server.Config{DownloadRate: 1 << 30})
+	data, err := os.ReadFile(s.fixtures["repeat.txt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.uploadDir, "repeat.txt"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// get 받은 본문이 원본과 같은지 보고 걸린 시간을 돌려줘
+	get := func(url string, header ...string) time.Duration {
+		t.Helper()
+		start := time.Now()
+		resp := testutil.Get(t, t.Context(), url, header...)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		if got := testutil.ReadBody(t, resp); !bytes.Equal(got, data) {
+			t.Errorf("%s: 본문 %d 바이트가 원본과 다름", url, len(got))
+		}
+		return time.Since(start)
+	}
+
+	for _, handler := range []string{"download", "range-download"} {
+		if d := get(s.fileURL(handler, "repeat.txt") + "&limit=256KB"); d < 200*time.Millisecond {
+			t.Errorf("%s ?limit=256KB: %v 만에 받음", handler, d)
+		}
+	}
+
+	// 서버 값보다 높은 limit 은 서버 값에서 멈춰
+	s.srv.Reload(server.Config{DownloadRate: rate})
+	if d := get(s.fileURL("download", "repeat.txt") + "&limit=1GB"); d < 200*time.Millisecond {
+		t.Errorf("DownloadRate 256KB 인데 %v 만에 받음", d)
+	}
+
+	for _, bad := range []string{"abc", "0", "-1MB"} {
+		resp := testutil.Get(t, t.Context(), s.fileURL("download", "repeat.txt")+"&limit="+url.QueryEscape(bad))
+		testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	}
+}
+
+func TestE2EUploadThrottle(t *testing.T) {
+	s := newTestServer(t, server.Config{UploadRate: 256 << 10}) // repeat.txt(64KB) 는 0.25초
+	src := s.fixtures["repeat.txt"]
+	start := time.Now()
+	testutil.ExpectStatus(t, testutil.Upload(t, t.Context(), s.url+"/upload", "file", src), http.StatusOK)
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("UploadRate 256KB 인데 %v 만에 올림", d)
+	}
+	if got, want := testutil.SHA256File(t, filepath.Join(s.uploadDir, "repeat.txt")), testutil.SHA256File(t, src); got != want {
+		t.Errorf("올린 파일 sha256 %s, want %s", got, want)
+	}
+
+	// Reload 로 끄면 바로 빨라져
+	s.srv.Reload(server.Config{})
+	start = time.Now()
+	testutil.ExpectStatus(t, testutil.Upload(t, t.Context(), s.url+"/upload", "file", src), http.StatusOK)
+	if d := time.Since(start); d >= 200*time.Millisecond {
+		t.Errorf("UploadRate 를 껐는데 %v 걸림", d)
+	}
+}
+
+func TestE2EDownloadLimit(t *testing.T) {
+	s := newTestServer(t, server.Config{MaxDownloads: 1, LargeDownload: 1 << 20})
+	big := bytes.Repeat([]byte("0123456789abcdef"), 1<<16) // 1MB
+	for name, data := range map[string][]byte{"big.bin": big, "small.txt": []byte("작은 파일")} {
+		if err := os.WriteFile(filepath.Join(s.uploadDir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	queued := func() string {
+		t.Helper()
+		for line := range strings.Lines(string(testutil.ReadBody(t, testutil.Get(t, t.Context(), s.url+"/metrics")))) {
+			if v, ok := strings.CutPrefix(line, "fs_download_queue "); ok {
+				return strings.TrimSpace(v)
+			}
+		}
+		return ""
+	}
+
+	// 느리게 받는 다운로드 하나가 자리를 잡고 있어
+	slow, cancelSlow := context.WithCancel(t.Context())
+	defer cancelSlow()
+	resp := testutil.Get(t, slow, s.fileURL("download", "big.bin")+"&limit=64KB")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+
+	// 기다리지 않게 해 두면 바로 503 + Retry-After, 작은 파일과 HEAD 는 세지 않아
+	for _, u := range []string{s.fileURL("download", "big.bin"), s.fileURL("range-download", "big.bin"), s.url + "/files/big.bin"} {
+		resp := testutil.Get(t, t.Context(), u)
+		testutil.ExpectStatus(t, resp, http.StatusServiceUnavailable)
+		if resp.Header.Get("Retry-After") != "1" {
+			t.Errorf("%s: Retry-After = %q", u, resp.Header.Get("Retry-After"))
+		}
+	}
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.fileURL("download", "small.txt")), http.StatusOK)
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodHead, s.fileURL("download", "big.bin"), nil)
+	head, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head.Body.Close()
+	testutil.ExpectStatus(t, head, http.StatusOK)
+
+	// 기다리게 하면 줄을 섰다가 앞 다운로드가 끝나는 대로 받아
+	s.srv.Reload(server.Config{MaxDownloads: 1, LargeDownload: 1 << 20, DownloadQueue: time.Minute})
+	type result struct {
+		status int
+		body   []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(s.fileURL("download", "big.bin"))
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		done <- result{resp.StatusCode, body, err}
+	}()
+	for deadline := time.Now().Add(5 * time.Second); queued() != "1"; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("두 번째 다운로드가 줄에 서지 않음")
+		}
+	}
+	select {
+	case <-done:
+		t.Fatal("자리가 나기 전에 응답이 옴")
+	case <-time.After(100 * time.Millisecond):
+	}
+	cancelSlow()
+	r := <-done
+	if r.err != nil || r.status != http.StatusOK || !bytes.Equal(r.body, big) {
+		t.Errorf("줄 선 다운로드: %d, %d 바이트, %v", r.status, len(r.body), r.err)
+	}
+	if q := queued(); q != "0" {
+		t.Errorf("fs_download_queue = %s", q)
+	}
+}
+
+func TestE2EUploadLimitPerIP(t *testing.T) {
+	s := newTestServer(t, server.Config{MaxUploadsPerIP: 1})
+	put := func(name string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, s.url+"/upload/"+name, strings.NewReader("quick"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// 본문을 천천히 보내는 업로드 하나가 자리를 잡고 있어
+	pr, pw := io.Pipe()
+	slow := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, s.url+"/upload/slow.txt", pr)
+		req.ContentLength = int64(len("first half, second half"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	pw.Write([]byte("first half, "))
+
+	// 같은 주소의 다음 업로드는 기다리지 않고 429 + Retry-After
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if resp = put("other.txt"); resp.StatusCode == http.StatusTooManyRequests || time.Now().After(deadline) {
+			break
+		}
+	}
+	testutil.ExpectStatus(t, resp, http.StatusTooManyRequests)
+	if resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q", resp.Header.Get("Retry-After"))
+	}
+	// 본문을 받지 않는 요청은 세지 않아
+	testutil.ExpectStatus(t, testutil.Get(t, t.Context(), s.url+"/api/files"), http.StatusOK)
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, s.url+"/upload/other.txt", nil)
+	req.Header.Set("Content-Range", "bytes */5")
+	status, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status.Body.Close()
+	if status.StatusCode == http.StatusTooManyRequests {
+		t.Error("빈 PUT(받은 범위 조회)이 429")
+	}
+	metric := string(testutil.ReadBody(t, testutil.Get(t, t.Context(), s.url+"/metrics")))
+	if !strings.Contains(metric, "fs_upload_ip_rejected_total ") || strings.Contains(metric, "fs_upload_ip_rejected_total 0\n") {
+		t.Errorf("fs_upload_ip_rejected_total 이 안 늘었음")
+	}
+
+	// 0 으로 다시 읽으면 바로 풀려
+	s.srv.Reload(server.Config{})
+	testutil.ExpectStatus(t, put("other.txt"), http.StatusCreated)
+	s.srv.Reload(server.Config{MaxUploadsPerIP: 1})
+
+	// 앞 업로드가 끝나면 자리가 돌아와
+	pw.Write([]byte("second half"))
+	pw.Close()
+	if got := <-slow; got != http.StatusCreated {
+		t.Fatalf("느린 업로드 = %d", got)
+	}
+	testutil.ExpectStatus(t, put("after.txt"), http.StatusCreated)
+	if data, _ := os.ReadFile(filepath.Join(s.uploadDir, "slow.txt")); string(data) != "first half, second half" {
+		t.Errorf("slow.txt = %q", data)
+	}
+}
+
+func TestE2EStats(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	if err := os.WriteFile(filepath.Join(s.uploadDir, "big.bin"), bytes.Repeat([]byte("x"), 64<<10), 0644); err != nil {
+		t.Fatal(err)
+	}
+	type transfer struct {
+		Kind        string `json:"kind"`
+		File        string `json:"file"`
+		Client      string `json:"client"`
+		Transferred int64  `json:"transferred"`
+	}
+	type stats struct {
+		Uploads   int        `json:"uploads"`
+		Downloads int        `json:"downloads"`
+		Transfers []transfer `json:"transfers"`
+	}
+	get := func() stats {
+		t.Helper()
+		resp := testutil.Get(t, t.Context(), s.url+"/stats")
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		var st stats
+		if err := json.Unmarshal(testutil.ReadBody(t, resp), &st); err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+
+	// 본문을 천천히 보내는 업로드와 초당 1KB 로 받는 다운로드를 걸어 둬
+	pr, pw := io.Pipe()
+	slow := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, s.url+"/upload/slow.txt", pr)
+		req.ContentLength = int64(len("first half, second half"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	pw.Write([]byte("first half, "))
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	dl := make(chan struct{})
+	go func() {
+		defer close(dl)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/download?file=big.bin&limit=1KB", nil)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+
+	var st stats
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		st = get()
+		if st.Uploads == 1 && st.Downloads == 1 && len(st.Transfers) == 2 && st.Transfers[0].Transferred > 0 || time.Now().After(deadline) {
+			break
+		}
+	}
+	if st.Uploads != 1 || st.Downloads != 1 || len(st.Transfers) != 2 {
+		t.Fatalf("stats = %+v", st)
+	}
+	for _, tr := range st.Transfers {
+		want := map[string]string{"upload": "slow.txt", "download": "big.bin"}[tr.Kind]
+		if tr.File != want || !strings.HasPrefix(tr.Client, "127.0.0.1:") {
+			t.Errorf("전송 = %+v", tr)
+		}
+	}
+	if up := st.Transfers[0]; up.Kind != "upload" || up.Transferred != int64(len("first half, ")) {
+		t.Errorf("먼저 시작한 업로드 = %+v", up)
+	}
+
+	// 끝나거나 끊기면 빠져
+	pw.Write([]byte("second half"))
+	pw.Close()
+	if got := <-slow; got != http.StatusCreated {
+		t.Fatalf("느린 업로드 = %d", got)
+	}
+	cancel()
+	<-dl
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if st = get(); len(st.Transfers) == 0 || time.Now().After(deadline) {
+			break
+		}
+	}
+	if st.Uploads != 0 || st.Downloads != 0 || len(st.Transfers) != 0 {
+		t.Errorf("끝난 뒤 stats = %+v", st)
+	}
+}
+
+func TestE2EUploadWebhooks(t *testing.T) {
+	type event struct {
+		Event    string `json:"event"`
+		Name     string `json:"name"`
+		Original string `json:"original"`
+		Size     int64  `json:"size"`
+		SHA256   string `json:"sha256"`
+		Uploader string `json:"uploader"`
+		Client   string `json:"client"`
+	}
+	// 처음 한 번은 503 이라 다시 보내야 받는 웹훅과, 늘 400 이라 바로 포기하는 웹훅
+	var flaky atomic.Int32
+	got := make(chan event, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if flaky.Add(1) == 1 {
+			http.Error(w, "잠깐 안 돼", http.StatusServiceUnavailable)
+			return
+		}
+		var e event
+		if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&e) != nil {
+			t.Errorf("웹훅 본문이 JSON 이 아님")
+		}
+		got <- e
+	}))
+	defer hook.Close()
+	var rejected atomic.Int32
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rejected.Add(1)
+		http.Error(w, "잘못된 요청", http.StatusBadRequest)
+	}))
+	defer broken.Close()
+
+	s := newTestServer(t, server.Config{UploadWebhooks: []string{hook.URL, broken.URL}, WebhookRetries: 2})
+	wait := func() event {
+		t.Helper()
+		select {
+		case e := <-got:
+			return e
+		case <-time.After(10 * time.Second):
+			t.Fatal("웹훅이 안 옴")
+		}
+		return event{}
+	}
+
+	// /upload 는 받으면서 잰 sha256 을 그대로
+	testutil.ExpectStatus(t, testutil.Upload(t, t.Context(), s.url+"/upload", "file", s.fixtures["app.log"]), http.StatusOK)
+	data, _ := os.ReadFile(s.fixtures["app.log"])
+	e := wait()
+	if want := (event{Event: "upload", Name: "app.log", Original: "app.log", Size: int64(len(data)), SHA256: testutil.SHA256(data), Uploader: "anonymous", Client: "127.0.0.1"}); e != want {
+		t.Errorf("웹훅 = %+v, want %+v", e, want)
+	}
+	if n := flaky.Load(); n != 2 {
+		t.Errorf("웹훅에 보낸 횟수 = %d (503 다음에 한 번 더)", n)
+	}
+
+	// 범위 올리기는 다 모은 뒤에 재서 보내
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPut, s.url+"/upload/ranged.txt", strings.NewReader("hello range"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	if e := wait(); e.Name != "ranged.txt" || e.Size != 11 || e.SHA256 != testutil.SHA256([]byte("hello range")) {
+		t.Errorf("범위 올리기 웹훅 = %+v", e)
+	}
+
+	// 4xx 는 다시 보내지 않고 실패로 세
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		metric := string(testutil.ReadBody(t, testutil.Get(t, t.Context(), s.url+"/metrics")))
+		if strings.Contains(metric, "fs_upload_webhook_failed_total 2\n") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fs_upload_webhook_failed_total 이 2 가 안 됨")
+		}
+	}
+	if n := rejected.Load(); n != 2 {
+		t.Errorf("400 웹훅에 보낸 횟수 = %d", n)
+	}
+}
+
+func TestE2EHealth(t *testing.T) {
+	cfg := server.Config{APIKeys: map[string]server.APIKey{"key-a": {Name: "alice"}}}
+	s := newTestServer(t, cfg)
+	type report struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name    string `json:"name"`
+			Status  string `json:"status"`
+			Error   string `json:"error"`
+			Free    int64  `json:"free"`
+			MinFree int64  `json:"min_free"`
+		} `json:"checks"`
+	}
+	get := func(path string, status int) report {
+		t.Helper()
+		resp, err := http.Get(s.url + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, status)
+		var out report
+		if err := json.Unmarshal(testutil.ReadBody(t, resp), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// 인증을 켜도 키 없이, 세 검사 모두 ok
+	for _, path := range []string{"/healthz", "/readyz"} {
+		out := get(path, http.StatusOK)
+		if out.Status != "ok" || len(out.Checks) != 3 {
+			t.Fatalf("%s = %+v", path, out)
+		}
+		for _, c := range out.Checks {
+			if c.Status != "ok" || c.Name == "disk" && c.Free <= 0 {
+				t.Errorf("%s %s = %+v", path, c.Name, c)
+			}
+		}
+	}
+	if entries, _ := os.ReadDir(s.uploadDir); len(entries) != 0 {
+		t.Errorf("검사 파일이 남음: %v", entries)
+	}
+
+	// 남은 공간이 최소보다 적으면 disk 만 fail 이고 503
+	cfg.MinFreeSpace = math.MaxInt64
+	s.srv.Reload(cfg)
+	out := get("/readyz", http.StatusServiceUnavailable)
+	if out.Status != "fail" {
+		t.Errorf("상태 = %+v", out)
+	}
+	for _, c := range out.Checks {
+		if fail := c.Name == "disk"; (c.Status == "fail") != fail || fail && (c.Error == "" || c.MinFree != math.MaxInt64) {
+			t.Errorf("%s = %+v", c.Name, c)
+		}
+	}
+}
+
+func TestE2EDownloadContentType(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	src := t.TempDir()
+	files := map[string][]byte{
+		"사진 1":      []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), // 확장자 없이 앞부분으로
+		"doc.pdf":   []byte("%PDF-1.7\n"),
+		"page.html": []byte("<html><script>alert(1)</script></html>"),
+		"NOTES":     []byte("확장자 없는 텍스트\n"),
+	}
+	for name, data := range files {
+		path := filepath.Join(src, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, testutil.Upload(t, t.Context(), s.url+"/upload", "file", path), http.StatusOK)
+	}
+
+	tests := []struct {
+		name, query string
+		ct          string
+		disposition string
+	}{
+		{"사진 1", "", "image/png", "attachment"},
+		{"사진 1", "&inline=1", "image/png", "inline"},
+		{"doc.pdf", "&inline=1", "application/pdf", "inline"},
+		{"NOTES", "", "text/plain; charset=utf-8", "attachment"},
+		{"page.html", "&inline=1", "text/html; charset=utf-8", "attachment"}, // 스크립트가 돌 수 있는 형식은 inline 으로 안 열어
+	}
+	for _, handler := range []string{"download", "range-download"} {
+		for _, tt := range tests {
+			resp := testutil.Get(t, t.Context(), s.fileURL(handler, tt.name)+tt.query, "Accept-Encoding", "identity")
+			testutil.ExpectStatus(t, resp, http.StatusOK)
+			resp.Body.Close()
+			if got := resp.Header.Get("Content-Type"); got != tt.ct {
+				t.Errorf("%s %s: Content-Type = %q, want %q", handler, tt.name, got, tt.ct)
+			}
+			disposition, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+			if err != nil || disposition != tt.disposition || params["filename"] != tt.name {
+				t.Errorf("%s %s%s: Content-Disposition = %q, want %s; filename=%s", handler, tt.name, tt.query, resp.Header.Get("Content-Disposition"), tt.disposition, tt.name)
+			}
+			if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("%s %s: X-Content-Type-Options = %q", handler, tt.name, got)
+			}
+		}
+	}
+}
+
+func TestE2EServeTLS(t *testing.T) {
+	// 파일로 줄 인증서 - 자체 서명 인증서를 PEM 으로 써 둬
+	certDir := t.TempDir()
+	cert, _, err := transfer.SelfSignedCert("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(certDir, "cert.pem"), filepath.Join(certDir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cfg := range map[string]server.Config{
+		"self-signed": {TLS: true},
+		"files":       {CertFile: certFile, KeyFile: keyFile},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg.UploadDir, cfg.TrashDir, cfg.SessionDir = t.TempDir(), t.TempDir(), t.TempDir()
+			cfg.Logger = slog.New(slog.DiscardHandler)
+			srv, err := server.New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if srv.Scheme() != "https" {
+				t.Errorf("Scheme = %s", srv.Scheme())
+			}
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(t.Context())
+			done := make(chan error, 1)
+			go func() { done <- srv.Serve(ctx, ln) }()
+
+			client := &http.Client{Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, // 자체 서명이라
+				ForceAttemptHTTP2: true,
+			}}
+			resp, err := client.Get("https://" + ln.Addr().String() + "/api/files")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			testutil.ExpectStatus(t, resp, http.StatusOK)
+			if resp.TLS == nil || resp.ProtoMajor != 2 {
+				t.Errorf("TLS = %v, proto = %s - HTTP/2 over TLS 여야 해", resp.TLS != nil, resp.Proto)
+			}
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Serve = %v", err)
+			}
+		})
+	}
+}
+
+func TestE2EPathTraversal(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	secret := filepath.Join(filepath.Dir(s.uploadDir), "secret.txt")
+	if err := os.WriteFile(secret, []byte("비밀"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, handler := range []string{"download", "range-download"} {
+		resp := testutil.Get(t, t.Context(), s.fileURL(handler, "../secret.txt"))
+		testutil.ExpectStatus(t, resp, http.StatusNotFound)
+	}
+}
+
+// 응답 에러 문구도 msg 언어를 따라가 (서버는 시작할 때 고른 언어 하나로)
+func TestE2ELang(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	t.Cleanup(func() { msg.SetLang(msg.Korean) })
+	for lang, want := range map[msg.Lang]string{msg.Korean: "파일을 찾을 수 없습니다", msg.English: "file not found"} {
+		msg.SetLang(lang)
+		resp := testutil.Get(t, t.Context(), s.fileURL("download", "none.txt"))
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound || strings.TrimSpace(string(body)) != want {
+			t.Errorf("%s: 상태 %d, 본문 %q, want %q", lang, resp.StatusCode, body, want)
+		}
+	}
+
+	msg.SetLang(msg.English)
+	resp := testutil.Get(t, t.Context(), s.url+"/api/files?sort=color")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "sort must be one of") {
+		t.Errorf("/api/files 에러 = %q, 영어여야 해", body)
+	}
+}
+
+func TestE2EWebUI(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+
+	resp := testutil.Get(t, t.Context(), s.url+"/")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if body := testutil.ReadBody(t, resp); !bytes.Contains(body, []byte("/ui/app.js")) {
+		t.Errorf("/ 가 내장 UI 가 아님:\n%s", body)
+	}
+	for _, asset := range []string{"/ui/app.js", "/ui/style.css"} {
+		resp := testutil.Get(t, t.Context(), s.url+asset)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+	}
+	resp = testutil.Get(t, t.Context(), s.url+"/nope")
+	testutil.ExpectStatus(t, resp, http.StatusNotFound)
+
+	// IndexFile 을 주면 내장 화면 대신 그 파일
+	custom := filepath.Join(t.TempDir(), "my.html")
+	if err := os.WriteFile(custom, []byte("<h1>내 페이지</h1>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s = newTestServer(t, server.Config{IndexFile: custom})
+	resp = testutil.Get(t, t.Context(), s.url+"/")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if body := string(testutil.ReadBody(t, resp)); body != "<h1>내 페이지</h1>" {
+		t.Errorf("IndexFile 본문 = %q", body)
+	}
+}
+
+func TestE2EFileList(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+	// 받는 중인 임시 파일은 목록에 안 나와야 해
+	if err := os.WriteFile(filepath.Join(s.uploadDir, ".big.bin.upload-123"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := testutil.Get(t, t.Context(), s.url+"/api/files")
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var list struct {
+		Count int `json:"count"`
+		Files []struct {
+			Name   string `json:"name"`
+			URL    string `json:"url"`
+			Size   int64  `json:"size"`
+			SHA256 string `json:"sha256"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &list); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range list.Files {
+		names = append(names, f.Name)
+		fi, err := os.Stat(s.fixtures[f.Name])
+		if err != nil {
+			t.Errorf("목록에 모르는 파일: %s", f.Name)
+			continue
+		}
+		if f.Size != fi.Size() {
+			t.Errorf("%s: size %d, want %d", f.Name, f.Size, fi.Size())
+		}
+		if want := "/download?file=" + url.QueryEscape(f.Name); f.URL != want {
+			t.Errorf("%s: url %q, want %q", f.Name, f.URL, want)
+		}
+		if want := testutil.SHA256File(t, s.fixtures[f.Name]); f.SHA256 != want {
+			t.Errorf("%s: sha256 %s, want %s", f.Name, f.SHA256, want)
+		}
+	}
+	if want := []string{"app.log", "empty.dat", "random.bin", "repeat.txt"}; !slices.Equal(names, want) || list.Count != len(want) {
+		t.Errorf("목록 = %v (count %d), want %v", names, list.Count, want)
+	}
+}
+
+func TestE2EFileListPaging(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	s.uploadAll(t)
+
+	list := func(query string) (names []string, count int) {
+		t.Helper()
+		resp := testutil.Get(t, t.Context(), s.url+"/api/files?"+query)
+		testutil.ExpectStatus(t, resp, http.StatusOK)
+		var body struct {
+			Count int `json:"count"`
+			Files []struct {
+				Name string `json:"name"`
+			} `json:"files"`
+		}
+		if err := json.Unmarshal(testutil.ReadBody(t, resp), &body); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range body.Files {
+			names = append(names, f.Name)
+		}
+		return names, body.Count
+	}
+
+	// 크기 큰 순 (fixtures 의 Size)
+	bySize := []string{"random.bin", "app.log", "repeat.txt", "empty.dat"}
+	if names, count := list("sort=size&order=desc"); !slices.Equal(names, bySize) || count != 4 {
+		t.Errorf("size desc = %v (count %d), want %v", names, count, bySize)
+	}
+	if names, count := list("sort=size&order=desc&offset=1&limit=2"); !slices.Equal(names, bySize[1:3]) || count != 4 {
+		t.Errorf("offset=1 limit=2 = %v (count %d), want %v", names, count, bySize[1:3])
+	}
+	if names, _ := list("offset=10"); len(names) != 0 {
+		t.Errorf("목록 끝 너머 = %v, want 빈 목록", names)
+	}
+
+	for _, bad := range []string{"sort=color", "order=up", "limit=-1", "offset=x", "limit=100000"} {
+		resp := testutil.Get(t, t.Context(), s.url+"/api/files?"+bad)
+		testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+		resp.Body.Close()
+	}
+}
+
+// 내장 UI 의 업로드 흐름 - /api/uploads 로 조각마다 PATCH 하고, /api/events 의 ID 가 세션 ID 라 진행률을 이어 붙일 수 있어
+func TestE2EWebUIChunkedUpload(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	data, err := os.ReadFile(s.fixtures["random.bin"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := testutil.Get(t, t.Context(), s.url+"/api/events")
+	testutil.ExpectStatus(t, events, http.StatusOK)
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, s.url+"/api/uploads?file=ui.bin", nil)
+	req.Header.Set("Upload-Length", fmt.Sprint(len(data)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusCreated)
+	loc := resp.Header.Get("Location")
+	id := path.Base(loc)
+
+	half := len(data) / 2
+	var saved string
+	for _, part := range [][2]int{{0, half}, {half, len(data)}} {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPatch, s.url+loc, bytes.NewReader(data[part[0]:part[1]]))
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", fmt.Sprint(part[0]))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testutil.ExpectStatus(t, resp, http.StatusNoContent)
+		saved = resp.Header.Get("Content-Location")
+	}
+	if saved != "/download?file=ui.bin" {
+		t.Errorf("Content-Location = %q", saved)
+	}
+
+	// 조각마다 start … complete 가 세션 ID 로 오고, bytes 는 그 조각 안에서 받은 만큼
+	var completes []int64
+	sc := bufio.NewScanner(events.Body)
+	for len(completes) < 2 && sc.Scan() {
+		line, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev struct {
+			Type  string `json:"type"`
+			ID    string `json:"id"`
+			Bytes int64  `json:"bytes"`
+		}
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.ID != id {
+			t.Fatalf("이벤트 ID = %q, want 세션 ID %q", ev.ID, id)
+		}
+		if ev.Type == "complete" {
+			completes = append(completes, ev.Bytes)
+		}
+	}
+	if want := []int64{int64(half), int64(len(data) - half)}; !slices.Equal(completes, want) {
+		t.Errorf("complete bytes = %v, want %v", completes, want)
+	}
+}
+
+func TestE2EUploadEvents(t *testing.T) {
+	s := newTestServer(t, server.Config{})
+	const name = "random.bin"
+
+	// 구독 응답 헤더가 오면 이미 구독된 상태라, 그 뒤에 올린 업로드 이벤트는 놓치지 않아
+	events := testutil.Get(t, t.Context(), s.url+"/api/events?id=abc")
+	testutil.ExpectStatus(t, events, http.StatusOK)
+	if ct := events.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload?id=abc", "file", s.fixtures[name])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	// 다른 ID 업로드는 이 구독에 안 와야 해
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload?id=other", "file", s.fixtures["app.log"])
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+
+	fi, err := os.Stat(s.fixtures[name])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	sc := bufio.NewScanner(events.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev struct {
+			Type  string `json:"type"`
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			Bytes int64  `json:"bytes"`
+		}
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.ID != "abc" || ev.Name != name {
+			t.Fatalf("다른 업로드 이벤트: %+v", ev)
+		}
+		if len(types) == 0 || types[len(types)-1] != ev.Type {
+			types = append(types, ev.Type)
+		}
+		if ev.Type == "complete" {
+			if ev.Bytes != fi.Size() {
+				t.Errorf("complete bytes = %d, want %d", ev.Bytes, fi.Size())
+			}
+			break
+		}
+	}
+	if len(types) == 0 || types[0] != "start" || types[len(types)-1] != "complete" {
+		t.Errorf("이벤트 순서 = %v, want start … complete", types)
+	}
+}
+
+func TestE2EExtract(t *testing.T) {
+	s := newTestServer(t, server.Config{Extract: extract.Options{MaxTotal: 1 << 20}})
+	writeZip := func(name string, files ...string) string {
+		path := filepath.Join(t.TempDir(), name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for _, entry := range files {
+			w, _ := zw.Create(entry)
+			src, err := os.Open(s.fixtures[filepath.Base(entry)])
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(w, src)
+			src.Close()
+		}
+		zw.Close()
+		f.Close()
+		return path
+	}
+
+	resp := testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", writeZip("logs.zip", "app.log", "sub/repeat.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp = testutil.Get(t, t.Context(), s.url+"/files/logs/sub/repeat.txt")
+	if got, want := testutil.SHA256(testutil.ReadBody(t, resp)), testutil.SHA256File(t, s.fixtures["repeat.txt"]); got != want {
+		t.Errorf("풀린 파일 체크섬 = %s, want %s", got, want)
+	}
+
+	// 같은 이름으로 또 풀면 409, 밖으로 나가는 경로는 400, 한도를 넘으면 413 - 실패하면 아무것도 남기지 않아
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", writeZip("logs.zip", "app.log"))
+	testutil.ExpectStatus(t, resp, http.StatusConflict)
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", writeZip("evil.zip", "../../app.log"))
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", writeZip("big.zip", "random.bin"))
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+
+	entries, _ := os.ReadDir(s.uploadDir)
+	if len(entries) != 1 || entries[0].Name() != "logs" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("업로드 디렉토리 = %v, want [logs] (실패한 풀기의 임시 파일이 남으면 안 돼)", names)
+	}
+}
+
+func TestE2EUploadArchive(t *testing.T) {
+	s := newTestServer(t, server.Config{Extract: extract.Options{MaxTotal: 1 << 20}})
+	writeZip := func(name string, files ...string) string {
+		path := filepath.Join(t.TempDir(), name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for _, entry := range files {
+			w, _ := zw.Create(entry)
+			src, err := os.Open(s.fixtures[filepath.Base(entry)])
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(w, src)
+			src.Close()
+		}
+		zw.Close()
+		f.Close()
+		return path
+	}
+	type summary struct {
+		Archive string
+		Files   []struct {
+			Name string
+			Size int64
+			URL  string
+		}
+	}
+
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("logs.zip", "app.log", "sub/repeat.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var got summary
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Archive != "logs.zip" || len(got.Files) != 2 || got.Files[0].Name != "app.log" || got.Files[1].URL != "/files/sub/repeat.txt" {
+		t.Fatalf("응답 = %+v", got)
+	}
+	resp = testutil.Get(t, t.Context(), s.url+got.Files[1].URL)
+	if got, want := testutil.SHA256(testutil.ReadBody(t, resp)), testutil.SHA256File(t, s.fixtures["repeat.txt"]); got != want {
+		t.Errorf("풀린 파일 체크섬 = %s, want %s", got, want)
+	}
+
+	// 있는 디렉토리에는 합쳐 넣고, 이미 있는 파일이 하나라도 있으면 아무것도 옮기지 않아
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("more.zip", "sub/app.log"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("again.zip", "new/repeat.txt", "sub/repeat.txt"))
+	testutil.ExpectStatus(t, resp, http.StatusConflict)
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "new")); err == nil {
+		t.Error("409 인데 new/ 가 생김")
+	}
+
+	// zip slip 은 400, 한도는 413, zip 이 아니면 415
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("evil.zip", "../../app.log"))
+	testutil.ExpectStatus(t, resp, http.StatusBadRequest)
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("big.zip", "random.bin"))
+	testutil.ExpectStatus(t, resp, http.StatusRequestEntityTooLarge)
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", s.fixtures["app.log"])
+	testutil.ExpectStatus(t, resp, http.StatusUnsupportedMediaType)
+	renamed := filepath.Join(t.TempDir(), "fake.zip")
+	os.WriteFile(renamed, []byte("not a zip"), 0o644)
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", renamed)
+	testutil.ExpectStatus(t, resp, http.StatusUnsupportedMediaType)
+
+	var names []string
+	filepath.WalkDir(s.uploadDir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(s.uploadDir, p)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if want := []string{"app.log", "sub/app.log", "sub/repeat.txt"}; !slices.Equal(names, want) {
+		t.Errorf("업로드 디렉토리 = %v, want %v (실패한 풀기의 임시 파일이 남으면 안 돼)", names, want)
+	}
+}
+
+// 풀린 파일도 /upload 와 같은 정책을 받아 - 하나라도 걸리면 아카이브째 거절하고 아무것도 옮기지 않아
+func TestE2EArchivePolicy(t *testing.T) {
+	s := newTestServer(t, server.Config{Policy: policy.Rules{Block: []string{".bin"}}})
+	writeZip := func(name string, files ...string) string {
+		path := filepath.Join(t.TempDir(), name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for _, entry := range files {
+			w, _ := zw.Create(entry)
+			src, err := os.Open(s.fixtures[filepath.Base(entry)])
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(w, src)
+			src.Close()
+		}
+		zw.Close()
+		f.Close()
+		return path
+	}
+	uploadDirEmpty := func() {
+		t.Helper()
+		if entries, _ := os.ReadDir(s.uploadDir); len(entries) != 0 {
+			t.Errorf("거절했는데 업로드 디렉토리에 %d 개가 남음", len(entries))
+		}
+	}
+
+	resp := testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("mixed.zip", "app.log", "sub/random.bin"))
+	testutil.ExpectStatus(t, resp, http.StatusUnsupportedMediaType)
+	var rejected struct{ File string }
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &rejected); err != nil || rejected.File != "sub/random.bin" {
+		t.Errorf("거절한 파일 = %q (%v), want sub/random.bin", rejected.File, err)
+	}
+	uploadDirEmpty()
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", writeZip("mixed.zip", "app.log", "sub/random.bin"))
+	testutil.ExpectStatus(t, resp, http.StatusUnsupportedMediaType)
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &rejected); err != nil || rejected.File != "mixed/sub/random.bin" {
+		t.Errorf("거절한 파일 = %q (%v), want mixed/sub/random.bin", rejected.File, err)
+	}
+	uploadDirEmpty()
+
+	// 걸리는 게 없으면 /upload 처럼 sha256 까지 재서 저장
+	resp = testutil.Upload(t, t.Context(), s.url+"/upload-archive", "file", writeZip("logs.zip", "app.log"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	var got struct {
+		Files []struct{ Name, SHA256 string }
+	}
+	if err := json.Unmarshal(testutil.ReadBody(t, resp), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := testutil.SHA256File(t, s.fixtures["app.log"]); len(got.Files) != 1 || got.Files[0].SHA256 != want {
+		t.Errorf("응답 = %+v, want app.log sha256 %s", got, want)
+	}
+	resp = testutil.Upload(t, t.Context(), s.url+"/api/extract", "file", writeZip("logs.zip", "app.log"))
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	resp = testutil.Get(t, t.Context(), s.url+"/files/logs/app.log")
+	if got, want := testutil.SHA256(testutil.ReadBody(t, resp)), testutil.SHA256File(t, s.fixtures["app.log"]); got != want {
+		t.Errorf("풀린 파일 체크섬 = %s, want %s", got, want)
+	}
+}
+
+// WebDAV - 읽기는 webdav 패키지, 쓰기는 업로드/삭제/이름 바꾸기와 같은 길 (Basic 의 비밀번호가 API 키)
+func TestE2EWebDAV(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	s := newTestServer(t, server.Config{
+		WebDAV:   server.WebDAVReadWrite,
+		AuditLog: auditPath,
+		APIKeys:  map[string]server.APIKey{"key-a": {Name: "admin"}, "key-r": {Name: "reader", Scopes: []string{server.ScopeDownload}}},
+	})
+	data, err := os.ReadFile(s.fixtures["app.log"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.uploadDir, "app.log"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(s.uploadDir, ".hidden"), []byte("x"), 0o644)
+	os.Mkdir(filepath.Join(s.uploadDir, ".thumbs"), 0o755)
+
+	do := func(method, path, key string, body io.Reader, header ...string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), method, s.url+path, body)
+		if key != "" {
+			req.SetBasicAuth("anyone", key)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	expect := func(resp *http.Response, status int) []byte {
+		t.Helper()
+		testutil.ExpectStatus(t, resp, status)
+		return testutil.ReadBody(t, resp)
+	}
+	propfind := func() string {
+		t.Helper()
+		return string(expect(do("PROPFIND", "/dav/", "key-r", nil, "Depth", "1"), http.StatusMultiStatus))
+	}
+
+	// 자격 증명이 없으면 401 + Basic (탐색기/Finder 가 키를 물어 봐)
+	resp := do("PROPFIND", "/dav/", "", nil, "Depth", "1")
+	testutil.ExpectStatus(t, resp, http.StatusUnauthorized)
+	if !slices.ContainsFunc(resp.Header.Values("WWW-Authenticate"), func(v string) bool { return strings.HasPrefix(v, "Basic ") }) {
+		t.Errorf("WWW-Authenticate = %q, want Basic", resp.Header.Values("WWW-Authenticate"))
+	}
+	resp = testutil.Get(t, t.Context(), s.url+"/api/files")
+	testutil.ExpectStatus(t, resp, http.StatusUnauthorized)
+	if got := resp.Header.Values("WWW-Authenticate"); len(got) != 1 || !strings.HasPrefix(got[0], "Bearer ") {
+		t.Errorf("/api/files 의 WWW-Authenticate = %q, want Bearer 만 (브라우저 로그인 창이 뜨지 않게)", got)
+	}
+
+	// 목록에는 파일만 - 점으로 시작하는 이름과 디렉토리는 빼
+	list := propfind()
+	if !strings.Contains(list, "/dav/app.log") || strings.Contains(list, ".hidden") || strings.Contains(list, ".thumbs") {
+		t.Errorf("PROPFIND = %s", list)
+	}
+	body := expect(do(http.MethodGet, "/dav/app.log", "key-r", nil), http.StatusOK)
+	if !bytes.Equal(body, data) {
+		t.Error("GET 내용이 다름")
+	}
+	expect(do(http.MethodGet, "/dav/.hidden", "key-r", nil), http.StatusNotFound)
+
+	// 읽기 권한만 있으면 못 써
+	expect(do(http.MethodPut, "/dav/new.txt", "key-r", strings.NewReader("x")), http.StatusForbidden)
+	expect(do(http.MethodDelete, "/dav/app.log", "key-r", nil), http.StatusForbidden)
+
+	// PUT - 처음은 201, 덮어쓰면 204, sha256 은 /api/files 에도
+	content := []byte("hello webdav\n")
+	resp = do(http.MethodPut, "/dav/new.txt", "key-a", bytes.NewReader(content))
+	expect(resp, http.StatusCreated)
+	if got := resp.Header.Get("X-Content-SHA256"); got != testutil.SHA256(content) {
+		t.Errorf("PUT X-Content-SHA256 = %q", got)
+	}
+	content = []byte("hello again\n")
+	expect(do(http.MethodPut, "/dav/new.txt", "key-a", bytes.NewReader(content)), http.StatusNoContent)
+	body = expect(testutil.Get(t, t.Context(), s.fileURL("download", "new.txt"), "X-API-Key", "key-r"), http.StatusOK)
+	if !bytes.Equal(body, content) {
+		t.Errorf("/download = %q, want %q", body, content)
+	}
+	files := string(expect(testutil.Get(t, t.Context(), s.url+"/api/files", "X-API-Key", "key-r"), http.StatusOK))
+	if !strings.Contains(files, testutil.SHA256(content)) {
+		t.Errorf("/api/files 에 새 sha256 이 없음: %s", files)
+	}
+
+	// 폴더, 점으로 시작하는 이름은 안 돼
+	expect(do("MKCOL", "/dav/dir", "key-a", nil), http.StatusForbidden)
+	expect(do(http.MethodPut, "/dav/._new.txt", "key-a", strings.NewReader("x")), http.StatusForbidden)
+	expect(do(http.MethodPut, "/dav/sub/a.txt", "key-a", strings.NewReader("x")), http.StatusForbidden)
+
+	// LOCK 은 없는 이름이면 빈 파일로 자리를 잡고, UNLOCK 까지
+	lockBody := `<?xml version="1.0" encoding="utf-8"?><D:lockinfo xmlns:D="DAV:"><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype></D:lockinfo>`
+	resp = do("LOCK", "/dav/locked.txt", "key-a", strings.NewReader(lockBody), "Depth", "0")
+	expect(resp, http.StatusOK)
+	token := resp.Header.Get("Lock-Token")
+	if fi, err := os.Stat(filepath.Join(s.uploadDir, "locked.txt")); err != nil || fi.Size() != 0 {
+		t.Errorf("LOCK 이 만든 파일 = %v, %v", fi, err)
+	}
+	expect(do("UNLOCK", "/dav/locked.txt", "key-a", nil, "Lock-Token", token), http.StatusNoContent)
+
+	// COPY, MOVE - Overwrite: F 면 있는 이름에 412
+	expect(do("COPY", "/dav/new.txt", "key-a", nil, "Destination", s.url+"/dav/copy.txt"), http.StatusCreated)
+	expect(do("COPY", "/dav/new.txt", "key-a", nil, "Destination", s.url+"/dav/copy.txt", "Overwrite", "F"), http.StatusPreconditionFailed)
+	expect(do("MOVE", "/dav/copy.txt", "key-r", nil, "Destination", s.url+"/dav/moved.txt"), http.StatusForbidden)
+	expect(do("MOVE", "/dav/copy.txt", "key-a", nil, "Destination", s.url+"/dav/moved.txt"), http.StatusCreated)
+	expect(do("MOVE", "/dav/moved.txt", "key-a", nil, "Destination", s.url+"/dav/new.txt", "Overwrite", "F"), http.StatusPreconditionFailed)
+	expect(do("MOVE", "/dav/moved.txt", "key-a", nil, "Destination", s.url+"/dav/locked.txt"), http.StatusNoContent)
+	expect(do("MOVE", "/dav/nope.txt", "key-a", nil, "Destination", s.url+"/dav/x.txt"), http.StatusNotFound)
+	body = expect(do(http.MethodGet, "/dav/locked.txt", "key-r", nil), http.StatusOK)
+	if !bytes.Equal(body, content) {
+		t.Errorf("MOVE 로 덮어쓴 내용 = %q, want %q", body, content)
+	}
+
+	// DELETE - 204, 다시 지우면 404
+	expect(do(http.MethodDelete, "/dav/locked.txt", "key-a", nil), http.StatusNoContent)
+	expect(do(http.MethodDelete, "/dav/locked.txt", "key-a", nil), http.StatusNotFound)
+	list = propfind()
+	for name, want := range map[string]bool{"app.log": true, "new.txt": true, "copy.txt": false, "moved.txt": false, "locked.txt": false} {
+		if strings.Contains(list, "/dav/"+name+"<") != want {
+			t.Errorf("PROPFIND 에 %s 가 있음 = %v, want %v", name, !want, want)
+		}
+	}
+
+	// 감사 로그 - 덮어쓴 MOVE 는 지우기와 이름 바꾸기 두 줄
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for line := range strings.Lines(string(audit)) {
+		var e struct{ Action, File, To string }
+		json.Unmarshal([]byte(line), &e)
+		actions = append(actions, e.Action+" "+e.File+" "+e.To)
+	}
+	want := []string{"rename copy.txt moved.txt", "delete locked.txt ", "rename moved.txt locked.txt", "rename nope.txt x.txt", "delete locked.txt ", "delete locked.txt "}
+	if !slices.Equal(actions, want) {
+		t.Errorf("감사 로그 = %q, want %q", actions, want)
+	}
+
+	t.Run("read-only", func(t *testing.T) {
+		ro := newTestServer(t, server.Config{WebDAV: server.WebDAVReadOnly})
+		os.WriteFile(filepath.Join(ro.uploadDir, "a.txt"), []byte("a"), 0o644)
+		req, _ := http.NewRequestWithContext(t.Context(), "PROPFIND", ro.url+"/dav", nil)
+		req.Header.Set("Depth", "1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body := expect(resp, http.StatusMultiStatus); !strings.Contains(string(body), "a.txt") {
+			t.Errorf("PROPFIND /dav = %s", body)
+		}
+		for _, method := range []string{http.MethodPut, http.MethodDelete, "MKCOL", "LOCK"} {
+			req, _ := http.NewRequestWithContext(t.Context(), method, ro.url+"/dav/a.txt", strings.NewReader(""))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect(resp, http.StatusForbidden)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		off := newTestServer(t, server.Config{})
+		expect(testutil.Get(t, t.Context(), off.url+"/dav/"), http.StatusNotFound)
+	})
+}
+
+func TestE2ESFTP(t *testing.T) {
+	keyDir := t.TempDir()
+	newKey := func(t *testing.T) (ssh.Signer, string) {
+		t.Helper()
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := ssh.NewSignerFromKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	}
+	admin, adminLine := newKey(t)
+	reader, readerLine := newKey(t)
+	stranger, _ := newKey(t)
+	ghost, ghostLine := newKey(t)
+	authorized := filepath.Join(keyDir, "authorized_keys")
+	os.WriteFile(authorized, []byte("# 주석 줄\n"+adminLine+" admin\n"+readerLine+" reader\n"+ghostLine+" nobody\n"), 0o600)
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	hostKey := filepath.Join(keyDir, "host_key")
+	s := newTestServer(t, server.Config{
+		SFTPAddr:           "127.0.0.1:0",
+		SFTPHostKey:        hostKey,
+		SFTPAuthorizedKeys: authorized,
+		AuditLog:           auditPath,
+		APIKeys:            map[string]server.APIKey{"key-a": {Name: "admin"}, "key-r": {Name: "reader", Scopes: []string{server.ScopeDownload}}},
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- s.srv.ServeSFTP(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("ServeSFTP: %v", err)
+		}
+	})
+
+	// 호스트 키는 처음 띄울 때 만들어 둔 파일 - 클라이언트는 그 공개키만 믿어
+	text, err := os.ReadFile(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, err := ssh.ParsePrivateKey(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dial := func(t *testing.T, key ssh.Signer) (*sftp.Client, error) {
+		t.Helper()
+		conn, err := ssh.Dial("tcp", ln.Addr().String(), &ssh.ClientConfig{
+			User:            "anyone",
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+			HostKeyCallback: ssh.FixedHostKey(host.PublicKey()),
+		})
+		if err != nil {
+			return nil, err
+		}
+		c, err := sftp.NewClient(conn, sftp.UseConcurrentWrites(true))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		t.Cleanup(func() { c.Close(); conn.Close() })
+		return c, nil
+	}
+
+	c, err := dial(t, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 겹쳐 보내는 쓰기(순서가 섞여)로 올려도 HTTP 에서 같은 내용
+	data, err := os.ReadFile(s.fixtures["random.bin"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := c.Create("/up.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, s.fileURL("download", "up.bin"), nil)
+	req.Header.Set("X-API-Key", "key-a")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.ExpectStatus(t, resp, http.StatusOK)
+	if got, want := testutil.SHA256(testutil.ReadBody(t, resp)), testutil.SHA256(data); got != want || resp.Header.Get(fetch.ChecksumHeader) != want {
+		t.Errorf("HTTP 로 받은 sha256 %s (헤더 %s), want %s", got, resp.Header.Get(fetch.ChecksumHeader), want)
+	}
+	if spools, _ := filepath.Glob(filepath.Join(s.sessionDir, "sftp-*")); len(spools) != 0 {
+		t.Errorf("SessionDir 에 임시 파일이 남음: %q", spools)
+	}
+
+	// 목록은 한 층, 점으로 시작하는 것과 디렉토리는 빼
+	os.WriteFile(filepath.Join(s.uploadDir, ".hidden"), []byte("x"), 0o644)
+	os.Mkdir(filepath.Join(s.uploadDir, ".thumbs"), 0o755)
+	os.WriteFile(filepath.Join(s.uploadDir, "a.txt"), []byte("hello sftp"), 0o644)
+	infos, err := c.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, fi := range infos {
+		names = append(names, fi.Name())
+	}
+	if slices.Sort(names); !slices.Equal(names, []string{"a.txt", "up.bin"}) {
+		t.Errorf("목록 = %q", names)
+	}
+	if fi, err := c.Stat("/a.txt"); err != nil || fi.Size() != 10 {
+		t.Errorf("stat a.txt = %v, %v", fi, err)
+	}
+	if _, err := c.Stat("/.hidden"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("stat .hidden: %v, want 없음", err)
+	}
+	rf, err := c.Open("/up.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rf)
+	rf.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("get up.bin: %d 바이트, %v", len(got), err)
+	}
+
+	// 한 층이라 디렉토리는 못 만들고, 점으로 시작하는 이름과 하위 경로에는 못 써
+	if err := c.Mkdir("/sub"); err == nil {
+		t.Error("mkdir 성공, want 실패")
+	}
+	for _, name := range []string{"/.secret", "/sub/x.txt"} {
+		if _, err := c.Create(name); err == nil {
+			t.Errorf("create %s 성공, want 실패", name)
+		}
+	}
+	if err := c.Rename("/a.txt", "/up.bin"); err == nil {
+		t.Error("있는 이름으로 rename 성공, want 실패")
+	}
+	if err := c.Rename("/a.txt", "/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Remove("/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Remove("/b.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("두 번째 remove: %v, want 없음", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "b.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("b.txt 가 남음: %v", err)
+	}
+
+	// download 권한만 있는 계정은 읽기만
+	rc, err := dial(t, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.Stat("/up.bin"); err != nil {
+		t.Errorf("reader stat: %v", err)
+	}
+	if _, err := rc.Create("/r.txt"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("reader create: %v, want 권한 없음", err)
+	}
+	if err := rc.Remove("/up.bin"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("reader remove: %v, want 권한 없음", err)
+	}
+
+	// authorized_keys 에 없는 키, 주석이 계정이 아닌 키는 로그인 못 해
+	for name, key := range map[string]ssh.Signer{"stranger": stranger, "ghost": ghost} {
+		if _, err := dial(t, key); err == nil {
+			t.Errorf("%s 로그인 성공, want 실패", name)
+		}
+	}
+
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for line := range strings.Lines(string(audit)) {
+		var e struct {
+			Action, File, To, Account string
+			Status                    int
+		}
+		json.Unmarshal([]byte(line), &e)
+		actions = append(actions, fmt.Sprint(e.Action, " ", e.File, " ", e.To, " ", e.Account, " ", e.Status))
+	}
+	want := []string{"rename a.txt up.bin admin 409", "rename a.txt b.txt admin 200", "delete b.txt  admin 200", "delete b.txt  admin 404"}
+	if !slices.Equal(actions, want) {
+		t.Errorf("감사 로그 = %q, want %q", actions, want)
+	}
+}
+
+func TestE2ETCP(t *testing.T) {
+	s := newTestServer(t, server.Config{MaxUploadSize: 1 << 20})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- s.srv.ServeTCP(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("ServeTCP: %v", err)
+		}
+	})
+	addr := ln.Addr().String()
+	src := t.TempDir()
+	write := func(name string, size int) string {
+		path := filepath.Join(src, name)
+		if err := os.WriteFile(path, bytes.Repeat([]byte(name), size/len(name)+1)[:size], 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// streamctl send 와 같은 클라이언트로 - 여러 data 프레임으로 나뉘어도 /upload 와 같은 길로 저장
+	path := write("big.bin", 300<<10)
+	res, err := transfer.Send(t.Context(), addr, path, transfer.SendOptions{ChunkSize: 16 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := os.ReadFile(path)
+	if got, _ := os.ReadFile(filepath.Join(s.uploadDir, "big.bin")); !bytes.Equal(got, want) {
+		t.Fatalf("big.bin 이 다름 (%d 바이트)", len(got))
+	}
+	if res.Sent != int64(len(want)) || res.Resumed != 0 {
+		t.Errorf("결과 = %+v", res)
+	}
+	body := string(testutil.ReadBody(t, testutil.Get(t, t.Context(), s.url+"/api/files")))
+	if !strings.Contains(body, `"big.bin"`) || !strings.Contains(body, res.SHA256) {
+		t.Errorf("/api/files 에 sha256 과 함께 안 보임: %s", body)
+	}
+
+	// 크기 제한은 data 를 받기 전에 offer 만 보고 거절
+	_, err = transfer.Send(t.Context(), addr, write("huge.bin", 2<<20), transfer.SendOptions{})
+	var remote *transfer.RemoteError
+	if !errors.As(err, &remote) || !strings.Contains(remote.Message, "업로드 크기 제한") {
+		t.Errorf("크기 제한 = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.uploadDir, "huge.bin")); err == nil {
+		t.Error("huge.bin 이 저장됨")
+	}
+	// 디렉토리를 가리키는 이름은 offer 에서 거절
+	_, err = transfer.Send(t.Context(), addr, path, transfer.SendOptions{Name: "../escape"})
+	if !errors.As(err, &remote) {
+		t.Errorf("잘못된 이름 = %v", err)
+	}
+
+	// 인증을 켜면 자격 증명을 보낼 방법이 없어서 거절
+	s.srv.Reload(server.Config{APIKeys: map[string]server.APIKey{"key-a": {Name: "alice"}}})
+	_, err = transfer.Send(t.Context(), addr, path, transfer.SendOptions{Name: "locked.bin"})
+	if !errors.As(err, &remote) || !strings.Contains(remote.Message, "인증") {
+		t.Errorf("인증을 켠 뒤 = %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"io/fs"
+	"net/http"
+	"strconv"
+)
+
+// 이어받기용 검증자 (/range-download, 로컬이 아닌 저장소의 /files/)
+// ⭐ 클라이언트는 받다 만 파일 크기부터 Range: bytes=N- 로 다시 요청하면서 If-Range 에 처음 받은 ETag 를 실어 -
+// 그 사이 파일이 바뀌었으면 ETag 가 달라서 http.ServeContent 가 Range 를 무시하고 전체를 200 으로 보내니, 앞부분과 뒷부분이 섞인 파일이 생기지 않아.
+// ETag 는 크기와 수정 시각(나노초)으로 만든 강한 검증자야 - If-Range 는 약한 ETag(W/)를 받아 주지 않아.
+// 파일을 읽지 않고 sha256 을 알면(업로드할 때 잰 값이 캐시나 체크섬 속성에 있으면) X-Content-SHA256 도 같이 보내서 다 받은 뒤 맞춰 볼 수 있어.
+
+// etag 크기와 수정 시각으로 만든 강한 ETag
+func etag(info fs.FileInfo) string {
+	return `"` + strconv.FormatInt(info.Size(), 16) + "-" + strconv.FormatInt(info.ModTime().UnixNano(), 16) + `"`
+}
+
+// setValidators ETag 와 (알면) X-Content-SHA256 - ServeContent 전에 불러야 If-Range, If-None-Match 를 처리해
+func (s *Server) setValidators(w http.ResponseWriter, name string, info fs.FileInfo) {
+	w.Header().Set("ETag", etag(info))
+	if sum := s.hashes.known(s.backend, fileEntry{Name: name, Size: info.Size(), ModTime: info.ModTime()}); sum != "" {
+		w.Header().Set(checksumHeader, sum)
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 업로드 진행 이벤트 (Server-Sent Events)
+// ⭐ 업로드 복사에 streamio.Hooks 로 붙어서, 서버가 실제로 디스크에 쓴 만큼을 GET /api/events 구독자에게 밀어줘.
+// 브라우저는 EventSource 하나로 받으면 되고, 업로드 요청에 ?id= 를 붙이면 자기 업로드만 골라 볼 수 있어.
+//
+//	curl -N localhost:8080/api/events?id=abc
+//	event: progress
+//	data: {"type":"progress","id":"abc","name":"big.log","bytes":1048576,"size":-1}
+//
+// 느린 구독자 때문에 업로드가 멈추면 안 되니까, 구독자 버퍼가 차면 그 이벤트는 버려 (progress 는 다음 것이 곧 와).
+
+const (
+	eventBuffer       = 64                     // 구독자마다 쌓아 둘 이벤트 수
+	progressInterval  = 200 * time.Millisecond // 전송 하나의 progress 이벤트 최소 간격
+	keepAliveInterval = 15 * time.Second       // 프록시가 조용한 연결을 끊지 않게 보내는 주석 줄 간격
+)
+
+// transferEvent /api/events 로 내보내는 이벤트 하나
+type transferEvent struct {
+	Type  string `json:"type"` // start, progress, complete, error
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+	Size  int64  `json:"size"` // 모르면 -1 (스트리밍 multipart 는 파트 크기를 미리 알 수 없어)
+	Error string `json:"error,omitempty"`
+}
+
+// eventHub 전송 훅 → SSE 구독자 fan-out
+type eventHub struct {
+	streamio.NopHooks
+
+	mu     sync.Mutex
+	subs   map[chan transferEvent]string // 구독 채널 → 골라 볼 전송 ID (빈 문자열이면 전부)
+	last   map[string]time.Time          // 전송 ID → 마지막 progress 이벤트 시각
+	closed bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan transferEvent]string), last: make(map[string]time.Time)}
+}
+
+// subscribe 구독 채널 (unsubscribe 나 close 로 닫혀)
+func (h *eventHub) subscribe(id string) chan transferEvent {
+	ch := make(chan transferEvent, eventBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch
+	}
+	h.subs[ch] = id
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan transferEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// close 구독을 전부 끝내 - 서버 종료 때 열려 있는 SSE 연결이 Shutdown 을 붙잡지 않게
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+func (h *eventHub) publish(ev transferEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch ev.Type {
+	case "progress":
+		now := time.Now()
+		if now.Sub(h.last[ev.ID]) < progressInterval {
+			return
+		}
+		h.last[ev.ID] = now
+	case "complete", "error":
+		delete(h.last, ev.ID)
+	}
+	for ch, id := range h.subs {
+		if id != "" && id != ev.ID {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func newTransferEvent(typ string, info streamio.TransferInfo, n int64) transferEvent {
+	return transferEvent{Type: typ, ID: info.ID, Name: filepath.Base(info.Dst), Bytes: n, Size: info.Size}
+}
+
+func (h *eventHub) OnStart(info streamio.TransferInfo) {
+	h.publish(newTransferEvent("start", info, 0))
+}
+
+func (h *eventHub) OnProgress(info streamio.TransferInfo, transferred int64) {
+	h.publish(newTransferEvent("progress", info, transferred))
+}
+
+func (h *eventHub) OnComplete(info streamio.TransferInfo, transferred int64, elapsed time.Duration) {
+	h.publish(newTransferEvent("complete", info, transferred))
+}
+
+func (h *eventHub) OnError(info streamio.TransferInfo, err error) {
+	ev := newTransferEvent("error", info, 0)
+	ev.Error = err.Error()
+	h.publish(ev)
+}
+
+// 이벤트 핸들러 - GET /api/events?id=업로드ID (id 를 빼면 모든 업로드)
+func (s *Server) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	rc := http.NewResponseController(w)
+
+	ch := s.events.subscribe(r.URL.Query().Get("id"))
+	defer s.events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx 가 모아서 보내지 않게
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		s.logger(r).WarnContext(r.Context(), "SSE flush 를 지원하지 않는 연결", "err", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
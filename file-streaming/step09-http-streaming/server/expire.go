@@ -0,0 +1,267 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 만료되는 업로드 (X-Expire-After, Config.ExpireAfter) - 서버를 잠깐 쓰고 버리는 파일 전달용으로
+// ⭐ 올릴 때 만료 시각을 SessionDir/expiry.json 에 이름별로 적어 두고, Serve 가 ExpireScan 마다 지난 것을 지워 (휴지통을 거치지 않고 바로).
+// 헤더가 없으면 ExpireAfter(0 이면 안 지워), 있으면 그 값 - ExpireAfter 가 있으면 그보다 길게는 못 잡아.
+//
+//	curl -T report.pdf -H 'X-Expire-After: 1h' http://localhost:8080/upload/report.pdf   # "3600" 처럼 초로 줘도 돼
+//	→ X-Expires: Thu, 15 Oct 2026 13:00:00 GMT  (/upload 의 JSON 에는 "expires")
+//
+// /upload, 이어 올리기, 멀티파트, 범위 올리기, WebDAV, SFTP 모두 - 여러 요청으로 나눠 올리면 마지막(다 모인) 요청의 헤더를 봐.
+// 같은 이름으로 다시 올리면 만료도 새로 정해지고(헤더도 기본값도 없으면 안 지워), 이름을 바꾸면 따라가고, 지우면 없어져.
+// 지울 때는 그 이름의 버전(Config.Versions)도 같이 지우고, 되찾은 바이트를 로그에 남겨.
+
+const (
+	expireHeader      = "X-Expire-After"
+	expiresHeader     = "X-Expires"
+	expiryFile        = "expiry.json"
+	defaultExpireScan = time.Minute // Config.ExpireScan 이 0 이면
+)
+
+// expiryStore 파일 이름 → 만료 시각 - 바뀔 때마다 expiry.json 에 통째로 써 (업로드 한 건에 한 번이라 자주가 아니야)
+type expiryStore struct {
+	path string
+
+	mu    sync.Mutex
+	files map[string]time.Time
+}
+
+// openExpiry sessionDir/expiry.json 을 읽어 (없으면 빈 기록)
+func openExpiry(sessionDir string) (*expiryStore, error) {
+	st := &expiryStore{path: filepath.Join(sessionDir, expiryFile), files: map[string]time.Time{}}
+	data, err := os.ReadFile(st.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &st.files); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// set name 이 at 에 만료돼 (zero 면 기록을 지워 - 다시 올린 파일은 안 지워)
+func (st *expiryStore) set(name string, at time.Time) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if _, ok := st.files[name]; !ok && at.IsZero() {
+		return nil
+	}
+	if at.IsZero() {
+		delete(st.files, name)
+	} else {
+		st.files[name] = at
+	}
+	return st.saveLocked()
+}
+
+func (st *expiryStore) remove(name string) error {
+	return st.set(name, time.Time{})
+}
+
+// rename 만료 시각도 새 이름으로 (from 에 기록이 없으면 to 의 예전 기록만 지워)
+func (st *expiryStore) rename(from, to string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	at, ok := st.files[from]
+	_, had := st.files[to]
+	if !ok && !had {
+		return nil
+	}
+	delete(st.files, from)
+	delete(st.files, to)
+	if ok {
+		st.files[to] = at
+	}
+	return st.saveLocked()
+}
+
+// expired now 에 만료된 이름들 (이름 순서)
+func (st *expiryStore) expired(now time.Time) []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var names []string
+	for name, at := range st.files {
+		if !now.Before(at) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// due name 이 now 에 만료됐는지 (경로 잠금 안에서 다시 봐 - 그사이 다시 올렸을 수 있어)
+func (st *expiryStore) due(name string, now time.Time) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	at, ok := st.files[name]
+	return ok && !now.Before(at)
+}
+
+func (st *expiryStore) saveLocked() error {
+	data, err := json.MarshalIndent(st.files, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(st.path), "."+expiryFile+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), st.path)
+}
+
+// parseExpireAfter X-Expire-After 값 - "90" 은 초, "1h30m" 은 Go 의 time.Duration (0 이하는 안 돼)
+func parseExpireAfter(v string) (time.Duration, bool) {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if n <= 0 || n > math.MaxInt64/int64(time.Second) {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	d, err := time.ParseDuration(v)
+	return d, err == nil && d > 0
+}
+
+// uploadTTL 이 요청으로 올리는 파일의 수명 (0 이면 안 지워) - X-Expire-After 가 잘못됐으면 400 을 쓰고 false
+func (s *Server) uploadTTL(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	limit := s.live().ExpireAfter
+	v := r.Header.Get(expireHeader)
+	if v == "" {
+		return limit, true
+	}
+	ttl, ok := parseExpireAfter(v)
+	if !ok {
+		http.Error(w, msg.Sprintf("%s 는 초(예: 3600)나 기간(예: 1h)이어야 합니다: %s", expireHeader, v), http.StatusBadRequest)
+		return 0, false
+	}
+	if limit > 0 {
+		ttl = min(ttl, limit)
+	}
+	return ttl, true
+}
+
+// setExpiry 방금 올린 name 의 만료 시각을 적고 X-Expires 로 알려 (name 의 경로 잠금 안에서) - ttl 이 0 이면 예전 만료를 지우고 zero
+func (s *Server) setExpiry(w http.ResponseWriter, r *http.Request, name string, ttl time.Duration) time.Time {
+	var at time.Time
+	if ttl > 0 {
+		at = time.Now().Add(ttl).Truncate(time.Second)
+		w.Header().Set(expiresHeader, at.UTC().Format(http.TimeFormat))
+	}
+	if err := s.expiry.set(name, at); err != nil {
+		s.logger(r).ErrorContext(r.Context(), "만료 기록 실패", "file", name, "err", err)
+	}
+	return at
+}
+
+// runExpiry ctx 가 끝날 때까지 ExpireScan 마다 만료된 파일과 보관 기간이 지난 휴지통 항목을 지워 (주기는 Reload 하면 다음 번부터)
+func (s *Server) runExpiry(ctx context.Context) {
+	for {
+		every := s.live().ExpireScan
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(every):
+		}
+		now := time.Now()
+		s.expireFiles(ctx, now)
+		s.purgeTrash(ctx, now)
+	}
+}
+
+// expireFiles now 에 만료된 파일을 다 지우고 되찾은 바이트를 로그에
+func (s *Server) expireFiles(ctx context.Context, now time.Time) {
+	names := s.expiry.expired(now)
+	if len(names) == 0 {
+		return
+	}
+	// 지우는 도우미들(forget, removed)이 요청에서 로거와 컨텍스트를 꺼내 써
+	r := (&http.Request{Method: http.MethodDelete, URL: &url.URL{Path: "/api/files/"}, Header: http.Header{}, Body: http.NoBody}).WithContext(ctx)
+	var files, reclaimed int64
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		n, ok, err := s.expireFile(r, name, now)
+		if err != nil {
+			s.cfg.Logger.Error("만료된 파일을 지우지 못함", "file", name, "err", err)
+			continue
+		}
+		if ok {
+			files++
+			reclaimed += n
+			s.cfg.Logger.Info("만료된 파일 삭제", "file", name, "bytes", n)
+		}
+	}
+	if files > 0 {
+		s.cfg.Logger.Info("만료된 파일 정리", "files", files, "reclaimed", reclaimed)
+	}
+}
+
+// expireFile 만료된 name 을 휴지통을 거치지 않고 지워 - 되찾은 바이트(남긴 버전 포함)와 지웠는지
+// 잠근 뒤에 다시 봐서 그사이 다시 올렸으면 그대로 두고, 이미 없으면 기록만 지워
+func (s *Server) expireFile(r *http.Request, name string, now time.Time) (int64, bool, error) {
+	unlock := streamio.LockPath(s.uploadPath(name))
+	if !s.expiry.due(name, now) {
+		unlock()
+		return 0, false, nil
+	}
+	info, err := s.backend.Stat(r.Context(), name)
+	if err == nil {
+		err = s.backend.Delete(r.Context(), name)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		unlock()
+		return 0, false, err
+	}
+	gone := err == nil
+	if err := s.expiry.remove(name); err != nil {
+		s.logger(r).ErrorContext(r.Context(), "만료 기록 실패", "file", name, "err", err)
+	}
+	var size int64
+	if gone {
+		size = info.Size()
+		s.forget(r, name)
+		if s.localDir() {
+			if have, err := s.versions(name); err == nil {
+				for _, v := range have {
+					size += v.Size
+				}
+			}
+			os.RemoveAll(filepath.Join(s.cfg.VersionDir, name))
+		}
+	}
+	unlock()
+	if gone {
+		s.removed(r, name)
+	}
+	return size, gone, nil
+}
@@ -0,0 +1,353 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/extract"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/scan"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// extractHandler 올린 아카이브(zip, tar, tar.gz)를 업로드 디렉토리 아래 새 디렉토리에 풀어 (/api/extract?dir=이름)
+// ⭐ 본문은 임시 파일로 받고(zip 은 끝에 목록이 있어서), 숨은 임시 디렉토리에 다 푼 다음 rename 해 -
+// 한도를 넘거나 경로가 위험해서 중간에 멈춰도 반쯤 풀린 디렉토리가 /files/ 에 보이지 않아.
+// 풀린 파일은 rename 전에 /upload 와 같은 검사(정책, 검사기, 저장 공간 한도)를 받고, 하나라도 걸리면 아카이브째 거절해.
+func (s *Server) extractHandler(w http.ResponseWriter, r *http.Request) {
+	part, archiveName, ok := s.archivePart(w, r)
+	if !ok {
+		return
+	}
+	defer part.Close()
+	ttl, ok := s.uploadTTL(w, r)
+	if !ok {
+		return
+	}
+	acct := s.account(r)
+	dirName := r.URL.Query().Get("dir")
+	if dirName == "" {
+		dirName = trimArchiveExt(archiveName)
+	}
+	dirName, ok = sanitizeFilename(dirName)
+	if !ok {
+		http.Error(w, msg.T("잘못된 디렉토리 이름입니다"), http.StatusBadRequest)
+		return
+	}
+	target := s.uploadPath(dirName)
+	unlock := streamio.LockPath(target)
+	defer unlock()
+	if _, err := os.Lstat(target); err == nil {
+		http.Error(w, msg.Sprintf("같은 이름의 파일이나 디렉토리가 이미 있습니다: %s", dirName), http.StatusConflict)
+		return
+	}
+
+	lg := s.logger(r).With("archive", archiveName, "dir", dirName)
+	spool, ok := s.spoolArchive(w, r, part, archiveName)
+	if !ok {
+		return
+	}
+	defer os.Remove(spool)
+
+	tmpDir, err := os.MkdirTemp(s.cfg.UploadDir, "."+dirName+".extract-*")
+	if err != nil {
+		lg.ErrorContext(r.Context(), "임시 디렉토리 생성 실패", "err", err)
+		http.Error(w, msg.T("임시 디렉토리 생성 실패"), http.StatusInternalServerError)
+		return
+	}
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.RemoveAll(tmpDir)
+		}
+	}()
+
+	res, err := extract.ExtractFile(r.Context(), spool, tmpDir, s.extractOptions())
+	if err != nil {
+		extractFailed(w, r, lg, err)
+		return
+	}
+	staged, ok := s.stageExtracted(w, r, lg, tmpDir, dirName, acct)
+	if !ok {
+		return
+	}
+	os.Chmod(tmpDir, 0755) // MkdirTemp 는 0700
+	if err := streamio.Rename(tmpDir, target); err != nil {
+		extractFailed(w, r, lg, err)
+		return
+	}
+	renamed = true
+	for _, f := range staged {
+		// 디렉토리째 옮긴 뒤라 제자리에서 체크섬을 남기고 중복 제거 저장소에 넣어 - 못 넣으면 평범한 파일로 둬
+		if _, err := s.commitLocal(r, s.uploadPath(f.Name), f.Name, f.SHA256); err != nil {
+			lg.ErrorContext(r.Context(), "중복 제거 저장소에 넣지 못함 (평범한 파일로 둠)", "file", f.Name, "err", err)
+		}
+		s.recordUpload(w, r, f.Name, f.entry, acct.Name, f.Size, f.SHA256, ttl)
+	}
+
+	lg.InfoContext(r.Context(), "아카이브 풀기 완료", "format", res.Format, "files", res.Files, "bytes", res.Bytes, "skipped", len(res.Skipped))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]any{"dir": dirName, "url": "/files/" + dirName + "/", "result": res})
+}
+
+// extractedFile /upload-archive 응답의 파일 하나
+type extractedFile struct {
+	Name   string `json:"name"` // 업로드 디렉토리 기준 경로 ("sub/a.txt")
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	URL    string `json:"url"`
+}
+
+// stagedFile 임시 디렉토리에 풀어 두고 검사를 통과한 파일 하나
+type stagedFile struct {
+	extractedFile
+	entry string // 아카이브 안의 경로 (웹훅의 original)
+	path  string // 임시 디렉토리 안의 경로
+}
+
+// stageExtracted dir 에 풀린 파일을 하나씩 /upload 와 같은 기준으로 검사해 - 정책(Config.Policy), 검사기(Config.Scanner), acct 의 저장 공간 한도
+// 이름은 prefix 아래 엔트리 경로 ("logs/sub/a.txt"). 아직 아무것도 옮기기 전이라, 하나라도 걸리면 에러 응답까지 쓰고 false (아카이브째 거절).
+func (s *Server) stageExtracted(w http.ResponseWriter, r *http.Request, lg *slog.Logger, dir, prefix string, acct APIKey) ([]stagedFile, bool) {
+	var (
+		staged []stagedFile
+		total  int64
+	)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		entry := filepath.ToSlash(rel)
+		name := path.Join(prefix, entry)
+		staged = append(staged, stagedFile{extractedFile: extractedFile{Name: name, Size: info.Size(), URL: "/files/" + name}, entry: entry, path: p})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		lg.ErrorContext(r.Context(), "풀린 파일 목록 읽기 실패", "err", err)
+		http.Error(w, msg.T("아카이브 풀기 실패"), http.StatusInternalServerError)
+		return nil, false
+	}
+	// 새 이름에만 풀어서 (이미 있으면 409) 덮어쓰면서 비워질 크기가 없어 - 합친 크기가 남은 공간 안이어야 해
+	if room := s.storageRoom(acct, ""); room >= 0 && total > room {
+		s.storageFull(w, r, acct, prefix, total)
+		return nil, false
+	}
+	for i, f := range staged {
+		if !s.checkPolicyFile(w, r, f.path, f.Name, f.Size, func() {}) {
+			return nil, false
+		}
+		// 아카이브째 한 번 봤지만, 검사기가 안을 못 여는 형식일 수도 있어서 풀린 파일도 하나씩
+		if err := scan.File(r.Context(), s.live().Scanner, f.path, f.Name); err != nil {
+			s.scanFailed(w, r, f.Name, err)
+			return nil, false
+		}
+		sum, err := streamio.FileSHA256(f.path)
+		if err != nil {
+			lg.ErrorContext(r.Context(), "풀린 파일 읽기 실패", "file", f.Name, "err", err)
+			http.Error(w, msg.T("아카이브 풀기 실패"), http.StatusInternalServerError)
+			return nil, false
+		}
+		staged[i].SHA256 = sum
+	}
+	return staged, true
+}
+
+// uploadArchiveHandler 올린 zip 을 업로드 디렉토리에 그대로 풀어 (/upload-archive) - 풀린 파일 목록을 JSON 으로
+// ⭐ /api/extract 와 달리 새 디렉토리를 만들지 않고 엔트리 경로 그대로 ./uploads 아래에 놓아 (sub/a.txt → /files/sub/a.txt).
+// 숨은 임시 디렉토리에 다 푼 다음 파일마다 /upload 와 같은 검사를 하고 같은 길(commitLocal, recordUpload)로 옮기는데,
+// 하나라도 검사에 걸리거나 이미 있는 이름이면 아무것도 옮기지 않아 (415, 422, 413, 409) -
+// 기존 파일을 덮거나 반만 풀린 채로 남지 않아. 있는 디렉토리에는 합쳐서 넣어.
+func (s *Server) uploadArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	part, archiveName, ok := s.archivePart(w, r)
+	if !ok {
+		return
+	}
+	defer part.Close()
+	ttl, ok := s.uploadTTL(w, r)
+	if !ok {
+		return
+	}
+	acct := s.account(r)
+	if !strings.EqualFold(path.Ext(archiveName), ".zip") {
+		http.Error(w, msg.Sprintf("zip 파일만 받습니다: %s", archiveName), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	lg := s.logger(r).With("archive", archiveName)
+	spool, ok := s.spoolArchive(w, r, part, archiveName)
+	if !ok {
+		return
+	}
+	defer os.Remove(spool)
+	// 이름만 .zip 인 tar 같은 건 풀기 전에 거절
+	if head, err := readHead(spool, 4); err != nil || !bytes.HasPrefix(head, []byte("PK")) {
+		http.Error(w, msg.Sprintf("zip 파일이 아닙니다: %s", archiveName), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	tmpDir, err := os.MkdirTemp(s.cfg.UploadDir, ".upload-archive-*")
+	if err != nil {
+		lg.ErrorContext(r.Context(), "임시 디렉토리 생성 실패", "err", err)
+		http.Error(w, msg.T("임시 디렉토리 생성 실패"), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+	res, err := extract.ExtractFile(r.Context(), spool, tmpDir, s.extractOptions())
+	if err != nil {
+		extractFailed(w, r, lg, err)
+		return
+	}
+
+	staged, ok := s.stageExtracted(w, r, lg, tmpDir, "", acct)
+	if !ok {
+		return
+	}
+
+	// 옮길 이름을 다 잠그고 (WalkDir 순서라 늘 같은 순서) 하나라도 있으면 409
+	var conflicts []string
+	for _, f := range staged {
+		target := s.uploadPath(filepath.FromSlash(f.Name))
+		unlock := streamio.LockPath(target)
+		defer unlock()
+		if _, err := os.Lstat(target); err == nil {
+			conflicts = append(conflicts, f.Name)
+		}
+	}
+	if len(conflicts) > 0 {
+		lg.InfoContext(r.Context(), "이미 있는 파일이라 풀지 않음", "conflicts", len(conflicts))
+		http.Error(w, msg.Sprintf("같은 이름의 파일이 이미 있습니다: %s", strings.Join(conflicts, ", ")), http.StatusConflict)
+		return
+	}
+	files := make([]extractedFile, 0, len(staged))
+	for _, f := range staged {
+		rel := filepath.FromSlash(f.Name)
+		if err = os.MkdirAll(filepath.Dir(s.uploadPath(rel)), 0755); err == nil {
+			_, err = s.commitLocal(r, f.path, f.Name, f.SHA256)
+		}
+		if err != nil {
+			lg.ErrorContext(r.Context(), "풀린 파일 옮기기 실패", "file", f.Name, "err", err)
+			http.Error(w, msg.T("아카이브 풀기 실패"), http.StatusInternalServerError)
+			return
+		}
+		s.recordUpload(w, r, f.Name, f.entry, acct.Name, f.Size, f.SHA256, ttl)
+		files = append(files, f.extractedFile)
+	}
+
+	lg.InfoContext(r.Context(), "아카이브를 업로드 디렉토리에 풀었음", "files", res.Files, "bytes", res.Bytes, "skipped", len(res.Skipped))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]any{"archive": archiveName, "files": files, "bytes": res.Bytes, "skipped": res.Skipped})
+}
+
+// archivePart POST 본문의 "file" 파트와 정리한 아카이브 이름 - 안 되면 응답까지 쓰고 false
+func (s *Server) archivePart(w http.ResponseWriter, r *http.Request) (*multipart.Part, string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return nil, "", false
+	}
+	if !s.localDir() {
+		http.Error(w, msg.T("이 저장소에서는 압축 풀기를 지원하지 않습니다 (로컬 디렉토리만)"), http.StatusNotImplemented)
+		return nil, "", false
+	}
+	limit := s.live().MaxUploadSize
+	if bodyTooLargeUpfront(w, r, limit) {
+		return nil, "", false
+	}
+	limitBody(w, r, limit)
+	s.throttleBody(r)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, msg.T("폼 파싱 실패"), http.StatusBadRequest)
+		return nil, "", false
+	}
+	part, err := nextFilePart(mr, "file")
+	if err != nil {
+		if !uploadTooLarge(w, err) {
+			http.Error(w, msg.T("파일을 가져올 수 없습니다"), http.StatusBadRequest)
+		}
+		return nil, "", false
+	}
+	name, ok := sanitizeFilename(part.FileName())
+	if !ok {
+		part.Close()
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return nil, "", false
+	}
+	return part, name, true
+}
+
+// spoolArchive part 를 업로드 디렉토리의 숨은 임시 파일에 받고 검사까지 - 돌려준 경로는 부른 쪽이 지워
+// zip 은 끝에 목록이 있어서 스트림으로는 못 풀어
+func (s *Server) spoolArchive(w http.ResponseWriter, r *http.Request, part *multipart.Part, archiveName string) (string, bool) {
+	lg := s.logger(r).With("archive", archiveName)
+	spool, err := os.CreateTemp(s.cfg.UploadDir, "."+archiveName+".upload-*")
+	if err != nil {
+		lg.ErrorContext(r.Context(), "임시 파일 생성 실패", "err", err)
+		http.Error(w, msg.T("임시 파일 생성 실패"), http.StatusInternalServerError)
+		return "", false
+	}
+	defer spool.Close()
+	info := streamio.TransferInfo{ID: uploadID(r, archiveName), Src: r.RemoteAddr, Dst: spool.Name(), Size: -1}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferUpload, archiveName)}
+	if _, err := streamio.Copy(r.Context(), spool, part, info, opts); err != nil {
+		os.Remove(spool.Name())
+		if !uploadTooLarge(w, err) {
+			lg.WarnContext(r.Context(), "아카이브 받기 실패", "err", err)
+			http.Error(w, msg.T("아카이브 받기 실패"), http.StatusBadRequest)
+		}
+		return "", false
+	}
+	// 풀기 전에 아카이브째 검사 (clamdscan 은 zip/tar 안도 봐)
+	if err := scan.File(r.Context(), s.live().Scanner, spool.Name(), archiveName); err != nil {
+		os.Remove(spool.Name())
+		s.scanFailed(w, r, archiveName, err)
+		return "", false
+	}
+	return spool.Name(), true
+}
+
+// extractOptions 지금 설정의 풀기 한도 (엔트리 하나, 전체, 개수)
+func (s *Server) extractOptions() extract.Options {
+	live := s.live()
+	opts := live.Extract
+	opts.Hooks, opts.BufferSize = s.cfg.Hooks, live.BufferSize
+	return opts
+}
+
+// extractFailed 풀기 에러로 응답 - 한도는 413, 위험한 경로(zip slip)나 깨진 아카이브는 400
+func extractFailed(w http.ResponseWriter, r *http.Request, lg *slog.Logger, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, extract.ErrLimit):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, extract.ErrUnsafePath), errors.Is(err, extract.ErrFormat), errors.Is(err, fs.ErrExist):
+		status = http.StatusBadRequest
+	}
+	lg.WarnContext(r.Context(), "아카이브 풀기 실패", "status", status, "err", err)
+	http.Error(w, msg.Sprintf("아카이브 풀기 실패: %v", err), status)
+}
+
+// trimArchiveExt 아카이브 이름에서 확장자를 떼서 풀 디렉토리 이름으로 (a.tar.gz → a)
+func trimArchiveExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(lower, ext) && len(name) > len(ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name + ".d"
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"path"
+	"strings"
+)
+
+// 업로드/다운로드 파일 이름 정리
+// ⭐ 클라이언트가 준 이름에서 마지막 요소만 남겨 - 그런데 filepath.Base 는 서버 OS 의 구분자만 알아서,
+// Linux 서버에서는 "..\..\win.ini" 나 옛 브라우저가 보내는 "C:\Users\me\a.txt" 가 \ 가 든 이상한 이름 하나로 남고
+// Windows 서버에서는 잘려서, 같은 요청이 OS 마다 다른 파일을 가리켜. 구분자를 / 로 통일한 뒤 자르면 어디서든 같은 이름이 돼.
+//
+// 업로드 디렉토리를 다른 OS 로 옮기거나 동기화해도 깨지지 않게, Windows 에서 못 쓰는 이름도 어디서든 거절해:
+// 예약 장치 이름(CON, NUL, COM1 …), 드라이브/대체 데이터 스트림 표기(:), <>"|?* 와 제어 문자, 끝의 점/공백.
+
+// reservedNames Windows 예약 장치 이름 (확장자가 붙어도 장치를 가리켜: NUL.txt)
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true, "CONIN$": true, "CONOUT$": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"COM¹": true, "COM²": true, "COM³": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"LPT¹": true, "LPT²": true, "LPT³": true,
+}
+
+// sanitizeFilename name 의 마지막 요소 - 쓸 수 없는 이름이면 false
+func sanitizeFilename(name string) (string, bool) {
+	base := path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if base == "." || base == "/" || base == ".." {
+		return "", false
+	}
+	if strings.ContainsAny(base, `<>:"|?*`) || strings.IndexFunc(base, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+		return "", false
+	}
+	if strings.HasSuffix(base, ".") || strings.HasSuffix(base, " ") {
+		return "", false // Windows 는 끝의 점/공백을 떼고 저장해서 다른 이름이 돼
+	}
+	stem, _, _ := strings.Cut(base, ".")
+	if reservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		return "", false
+	}
+	return base, true
+}
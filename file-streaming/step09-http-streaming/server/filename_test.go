@@ -0,0 +1,50 @@
+package server
+
+import "testing"
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // 비어 있으면 거절
+	}{
+		{"report.txt", "report.txt"},
+		{"../../etc/passwd", "passwd"},
+		{`..\..\windows\win.ini`, "win.ini"},
+		{`C:\Users\me\report.txt`, "report.txt"}, // 옛 브라우저가 보내는 전체 경로
+		{"a/b\\c.txt", "c.txt"},
+		{"..", ""},
+		{"", ""},
+		{"/", ""},
+		{"CON", ""},
+		{"nul.txt", ""},
+		{"Com1.log", ""},
+		{"console.txt", "console.txt"},
+		{"file.txt:stream", ""},
+		{"what?.txt", ""},
+		{"tab\tname", ""},
+		{"trailing.", ""},
+		{"trailing ", ""},
+		{"한글 파일.txt", "한글 파일.txt"},
+	}
+	for _, tt := range tests {
+		got, ok := sanitizeFilename(tt.name)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, %v; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"report.txt", "report-v2.txt"},
+		{"noext", "noext-v2"},
+		{"logs.tar.gz", "logs-v2.tar.gz"},
+		{"a.b.c", "a.b-v2.c"},
+		{".bashrc", ".bashrc-v2"},
+	}
+	for _, tt := range tests {
+		if got := withSuffix(tt.name, "-v2"); got != tt.want {
+			t.Errorf("withSuffix(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
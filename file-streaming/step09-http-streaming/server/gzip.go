@@ -0,0 +1,106 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// /download 응답 gzip 압축
+// ⭐ 클라이언트가 Accept-Encoding: gzip 을 보내고 내용이 잘 줄어드는 형식(텍스트, JSON, 로그 ...)일 때만,
+// 파일 전체를 메모리에 올리지 않고 gzip.Writer 를 응답 위에 얹어서 흘려보내 - 그래서 Content-Length 는 없고 청크 전송이야.
+// 이미 압축된 형식(zip, 이미지, 동영상 ...)은 다시 압축해 봐야 CPU 만 쓰니 그대로 보내.
+// /range-download 와 /files/ 는 Range(바이트 위치)를 원본 기준으로 줘야 해서 압축하지 않아 - /download 도 Range 나 HEAD 요청이면 압축 없이 원본 기준이야.
+
+// minGzipSize 이보다 작은 파일은 압축 헤더가 더 커서 그대로
+const minGzipSize = 1 << 10
+
+// compressedExts 내용 형식과 상관없이 이미 압축된 확장자 (GzipSkip 은 여기에 더해져)
+var compressedExts = []string{
+	".gz", ".tgz", ".zip", ".zst", ".xz", ".bz2", ".7z", ".rar", ".br", ".lz4",
+	".jpg", ".jpeg", ".png", ".gif", ".webp", ".mp3", ".mp4", ".mkv", ".mov", ".pdf", ".parquet",
+}
+
+// acceptsGzip Accept-Encoding 에 gzip (또는 *) 이 q=0 이 아니게 들어 있는지
+func acceptsGzip(r *http.Request) bool {
+	for part := range strings.SplitSeq(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if coding = strings.ToLower(strings.TrimSpace(coding)); coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible name 의 확장자와 앞부분(sniff, 최대 512 바이트)으로 본 내용이 압축할 만한지
+func compressible(name string, sniff []byte, skip []string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if slices.Contains(compressedExts, ext) || slices.Contains(skip, ext) {
+		return false
+	}
+	ct, _, _ := strings.Cut(contentType(name, sniff), ";") // .log 처럼 등록 안 된 확장자는 앞부분으로
+	if strings.HasPrefix(ct, "text/") {
+		return true
+	}
+	switch ct {
+	case "application/json", "application/xml", "application/javascript", "application/x-ndjson",
+		"application/yaml", "application/x-yaml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// gzipResponse 조건이 맞으면 응답 헤더를 gzip 으로 바꾸고 압축 writer 를, 아니면 w 그대로 돌려줘 (finish 는 다 쓰고 꼭 불러)
+// Content-Length 같은 헤더를 다 정한 뒤, 본문을 쓰기 전에 불러
+func (s *Server) gzipResponse(w http.ResponseWriter, r *http.Request, name string, f io.ReadSeeker, size int64) (out io.Writer, finish func() error) {
+	nop := func() error { return nil }
+	live := s.live()
+	if !live.Gzip {
+		return w, nop
+	}
+	w.Header().Add("Vary", "Accept-Encoding") // 캐시가 압축한 응답을 gzip 못 받는 클라이언트에게 주지 않게
+	if size < minGzipSize || !acceptsGzip(r) || !compressible(name, sniffHead(f), live.GzipSkip) {
+		return w, nop
+	}
+	gz, err := gzip.NewWriterLevel(w, live.GzipLevel)
+	if err != nil {
+		return w, nop
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length") // 압축한 크기는 다 보내기 전엔 몰라
+	w.Header().Del("ETag")           // 강한 ETag 는 원본 바이트의 것이라 압축한 응답에는 맞지 않아
+	return gz, gz.Close
+}
+
+// sniffHead 파일 앞부분을 읽고 처음 위치로 되돌려
+func sniffHead(f io.ReadSeeker) []byte {
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(f, buf)
+	f.Seek(0, io.SeekStart)
+	return buf[:n]
+}
+
+// normalizeExts ".GZ", "gz" → ".gz" (설정에서 점을 빼먹어도 되게)
+func normalizeExts(exts []string) []string {
+	out := make([]string, 0, len(exts))
+	for _, e := range exts {
+		if e = strings.ToLower(strings.TrimSpace(e)); e == "" {
+			continue
+		}
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 상태 확인 (/healthz, /readyz) - 로드밸런서나 쿠버네티스 프로브가 물어볼 곳
+// ⭐ 물어볼 때마다 세 가지를 직접 확인해서, 하나라도 실패하면 503 이라 그 서버로는 요청을 보내지 않게 돼:
+//   - upload_dir: 업로드 디렉토리에 임시 파일을 만들고 지울 수 있는지
+//   - disk: 업로드 디렉토리의 남은 공간이 MinFreeSpace 이상인지
+//   - storage: 저장소(Backend)가 healthTimeout 안에 대답하는지 - 없는 이름의 Stat 이라 S3 같은 원격 저장소도 가벼워
+// 로컬 디렉토리가 아니면 앞의 둘은 skip 이야. 두 경로는 같은 검사라 프로브 설정에 맞는 이름을 쓰면 돼.
+// 인증 없이 열려 있고 (/metrics 처럼 상태만 나가) 응답은 캐시하지 않아.
+
+// healthTimeout 검사 하나를 기다릴 최대 시간 (프로브는 보통 1~5초면 실패로 봐)
+const healthTimeout = 3 * time.Second
+
+// healthProbe 저장소에 물어볼 이름 - 없어도 돼 (없다는 대답도 대답이야)
+const healthProbe = ".healthz"
+
+// 검사 결과
+const (
+	healthOK   = "ok"
+	healthFail = "fail"
+	healthSkip = "skip" // 이 저장소에서는 볼 수 없어
+)
+
+// healthCheck 검사 하나
+type healthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	Free       int64   `json:"free,omitempty"`     // disk: 남은 바이트
+	MinFree    int64   `json:"min_free,omitempty"` // disk: MinFreeSpace
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// healthReport /healthz, /readyz 응답
+type healthReport struct {
+	Status string        `json:"status"` // 검사가 하나라도 fail 이면 fail
+	Time   time.Time     `json:"time"`
+	Checks []healthCheck `json:"checks"`
+}
+
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	report := s.checkHealth(r.Context())
+	status := http.StatusOK
+	if report.Status != healthOK {
+		status = http.StatusServiceUnavailable
+		s.logger(r).WarnContext(r.Context(), "상태 확인 실패", "path", r.URL.Path, "checks", report.Checks)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// checkHealth 검사를 차례로 돌려
+func (s *Server) checkHealth(ctx context.Context) healthReport {
+	report := healthReport{Status: healthOK, Time: time.Now()}
+	for _, c := range []struct {
+		name string
+		run  func(context.Context, *healthCheck) error
+	}{
+		{"upload_dir", s.checkUploadDir},
+		{"disk", s.checkDisk},
+		{"storage", s.checkStorage},
+	} {
+		check := healthCheck{Name: c.name, Status: healthOK}
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, healthTimeout)
+		err := c.run(cctx, &check)
+		cancel()
+		check.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+		if err != nil {
+			check.Status, check.Error = healthFail, err.Error()
+			report.Status = healthFail
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report
+}
+
+// checkUploadDir 업로드 디렉토리에 파일을 만들고 지울 수 있는지 (읽기 전용으로 다시 붙었거나 권한이 바뀐 걸 잡아)
+func (s *Server) checkUploadDir(_ context.Context, check *healthCheck) error {
+	if !s.localDir() {
+		check.Status = healthSkip
+		return nil
+	}
+	f, err := os.CreateTemp(s.cfg.UploadDir, ".healthz-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write([]byte("ok"))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// checkDisk 업로드 디렉토리의 남은 공간이 MinFreeSpace 이상인지 (MinFreeSpace 가 0 이면 남은 공간만 알려줘)
+func (s *Server) checkDisk(_ context.Context, check *healthCheck) error {
+	if !s.localDir() {
+		check.Status = healthSkip
+		return nil
+	}
+	free, err := diskFree(s.cfg.UploadDir)
+	if errors.Is(err, errors.ErrUnsupported) {
+		check.Status = healthSkip
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	check.Free, check.MinFree = free, s.live().MinFreeSpace
+	if free < check.MinFree {
+		return msg.Errorf("남은 공간이 %d 바이트로 최소 %d 바이트보다 적습니다", free, check.MinFree)
+	}
+	return nil
+}
+
+// checkStorage 저장소가 대답하는지 - 없는 파일이라는 대답도 살아 있는 거야
+func (s *Server) checkStorage(ctx context.Context, _ *healthCheck) error {
+	_, err := s.backend.Stat(ctx, healthProbe)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/hls"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// MPEG-TS 동영상의 HLS 재생 목록 (Config.HLS)
+// ⭐ 조각 파일을 따로 만들지 않아 - 원본을 한 번 훑어서(hls.Scan) 키프레임 경계의 바이트 범위를 재생 목록에 적고,
+// 플레이어는 같은 /download?file= 에 Range 로 조각만 받아 (다운로드 속도 제한, 큰 다운로드 자리, 전송량도 그대로 따라가).
+//
+//	GET /hls?file=이름.ts   → application/vnd.apple.mpegurl (MPEG-TS 가 아니면 415)
+//
+// 훑은 결과는 크기/수정 시각이 그대로인 동안 메모리에 두고 다시 써 - 큰 파일도 처음 한 번만 끝까지 읽어.
+// 서명 URL(/api/sign 의 exp, sig)을 그대로 /hls 에 붙이면 조각 URI 에도 붙여서, 자격 증명을 못 보내는 플레이어도 재생할 수 있어.
+
+// playlistCache 파일 이름별로 마지막에 훑은 재생 목록 (크기나 수정 시각, 조각 길이가 바뀌면 다시 훑어)
+type playlistCache struct {
+	mu    sync.Mutex
+	files map[string]cachedPlaylist
+}
+
+type cachedPlaylist struct {
+	size    int64
+	modTime time.Time
+	segment time.Duration
+	pl      hls.Playlist
+}
+
+func (c *playlistCache) get(name string, size int64, modTime time.Time, segment time.Duration) (hls.Playlist, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.files[name]
+	if !ok || p.size != size || !p.modTime.Equal(modTime) || p.segment != segment {
+		return hls.Playlist{}, false
+	}
+	return p.pl, true
+}
+
+func (c *playlistCache) put(name string, p cachedPlaylist) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.files == nil {
+		c.files = make(map[string]cachedPlaylist)
+	}
+	c.files[name] = p
+}
+
+// hlsHandler GET /hls?file=이름 - 재생 목록
+func (s *Server) hlsHandler(w http.ResponseWriter, r *http.Request) {
+	live := s.live()
+	if !live.HLS {
+		http.Error(w, msg.T("HLS 가 꺼져 있습니다"), http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	name, ok := sanitizeFilename(q.Get("file"))
+	if !ok || name != q.Get("file") {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	info, err := s.backend.Stat(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+			return
+		}
+		s.logger(r).ErrorContext(r.Context(), "파일 정보를 읽지 못함", "file", name, "err", err)
+		http.Error(w, msg.T("파일을 읽을 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+
+	pl, ok := s.playlists.get(name, info.Size(), info.ModTime(), live.HLSSegment)
+	if !ok {
+		start := time.Now()
+		f, err := s.backend.Open(r.Context(), name)
+		if err == nil {
+			pl, err = hls.Scan(r.Context(), f, live.HLSSegment)
+			f.Close()
+		}
+		switch {
+		case errors.Is(err, hls.ErrNotTS):
+			http.Error(w, msg.Sprintf("HLS 로 나눌 수 있는 MPEG-TS(.ts) 동영상이 아닙니다: %s", name), http.StatusUnsupportedMediaType)
+			return
+		case r.Context().Err() != nil:
+			return
+		case err != nil:
+			s.logger(r).ErrorContext(r.Context(), "HLS 재생 목록 만들기 실패", "file", name, "err", err)
+			http.Error(w, msg.T("파일을 읽을 수 없습니다"), http.StatusInternalServerError)
+			return
+		}
+		s.playlists.put(name, cachedPlaylist{size: info.Size(), modTime: info.ModTime(), segment: live.HLSSegment, pl: pl})
+		s.logger(r).InfoContext(r.Context(), "HLS 재생 목록 만듦", "file", name, "segments", len(pl.Segments), "duration", pl.Duration(), "keyframe", pl.Keyframe, "took", time.Since(start))
+	}
+
+	// 조각은 /download 로 - 서명 URL 로 왔으면 같은 서명을 붙여 (서명은 파일 이름과 만료만 봐)
+	seg := url.Values{"file": {name}}
+	if q.Has("sig") {
+		seg.Set("exp", q.Get("exp"))
+		seg.Set("sig", q.Get("sig"))
+	}
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method == http.MethodHead {
+		return
+	}
+	pl.Write(w, "/download?"+seg.Encode())
+}
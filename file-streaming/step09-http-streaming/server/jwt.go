@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// HMAC 서명 JWT (HS256/HS384/HS512)
+// ⭐ 다른 서비스(로그인 서버 등)가 같은 비밀 키로 서명해 준 토큰을 Authorization: Bearer 로 받아.
+// sub 가 계정 이름이고(전송량/저장 공간도 그 이름으로 세), 한도는 usage.monthly/usage.storage 기본값,
+// scope 클레임("upload download" 처럼 공백 구분)이 있으면 그 일만 할 수 있어 (없으면 전부).
+// alg 는 헤더를 믿지 않고 HS 계열만 받아 - "none" 이나 RS256 으로 바꿔 보내는 토큰은 거절.
+
+// jwtLeeway exp/nbf 를 볼 때 봐주는 시계 차이
+const jwtLeeway = 30 * time.Second
+
+// jwtValidator Config.JWTSecret 으로 서명을 확인하는 검증기
+type jwtValidator struct {
+	secret           []byte
+	issuer, audience string // 비우면 확인 안 함
+	monthly, storage int64  // 토큰 계정의 한도 (Config.MonthlyQuota, Config.StorageQuota)
+}
+
+// jwtClaims 보는 클레임만
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Scope     string   `json:"scope"`
+}
+
+// audience aud 는 문자열 하나거나 배열이야
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+var jwtHashes = map[string]func() hash.Hash{"HS256": sha256.New, "HS384": sha512.New384, "HS512": sha512.New}
+
+func (v *jwtValidator) Validate(credential string) (APIKey, error) {
+	parts := strings.Split(credential, ".")
+	if len(parts) != 3 {
+		return APIKey{}, ErrUnknownCredential // JWT 모양이 아니면 다른 검증기의 키
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return APIKey{}, ErrUnknownCredential
+	}
+	newHash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return APIKey{}, msg.Errorf("지원하지 않는 토큰 서명 방식입니다: %s", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return APIKey{}, msg.New("토큰 서명이 올바르지 않습니다")
+	}
+	mac := hmac.New(newHash, v.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return APIKey{}, msg.New("토큰 서명이 올바르지 않습니다")
+	}
+
+	var c jwtClaims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return APIKey{}, msg.New("토큰 클레임을 읽지 못했습니다")
+	}
+	now := time.Now()
+	switch {
+	case c.Subject == "":
+		return APIKey{}, msg.New("토큰에 sub 가 없습니다")
+	case c.ExpiresAt != nil && now.After(time.Unix(*c.ExpiresAt, 0).Add(jwtLeeway)):
+		return APIKey{}, msg.New("토큰이 만료되었습니다")
+	case c.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(*c.NotBefore, 0)):
+		return APIKey{}, msg.New("아직 쓸 수 없는 토큰입니다 (nbf)")
+	case v.issuer != "" && c.Issuer != v.issuer:
+		return APIKey{}, msg.New("토큰 발급자(iss)가 다릅니다")
+	case v.audience != "" && !slices.Contains(c.Audience, v.audience):
+		return APIKey{}, msg.New("이 서버용 토큰(aud)이 아닙니다")
+	}
+	return APIKey{Name: c.Subject, Monthly: v.monthly, Storage: v.storage, Scopes: strings.Fields(c.Scope)}, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 큰 다운로드 동시 수 제한 (Config.MaxDownloads)
+// ⭐ 디스크(나 S3)에서 큰 파일 여러 개를 한꺼번에 읽으면 서로 I/O 를 뺏어서 다 같이 느려져 - 동시에 보내는 건 MaxDownloads 개까지만.
+// 자리가 없으면 DownloadQueue 동안 먼저 온 순서대로 기다리고, 그래도 안 나면 503 + Retry-After (기다리다 끊은 요청은 줄에서 빠져).
+// LargeDownload 보다 작은 파일과 HEAD 는 세지 않아 - 금방 끝나서 줄 세울 이유가 없어.
+// /download, /range-download, /files/ 에 걸려 (서명 URL 도). 설정은 SIGHUP 으로 바로 바뀌고, 줄이면 이미 보내는 건 끝까지 둬.
+
+// downloadSlots 보내는 중인 다운로드 수와 기다리는 줄
+type downloadSlots struct {
+	mu      sync.Mutex
+	active  int
+	waiting []chan struct{} // 먼저 온 순서 - release 가 닫아서 자리를 넘겨
+}
+
+// acquire 자리를 잡으면 true - 없으면 wait 동안 (또는 ctx 가 끝날 때까지) 기다려
+func (d *downloadSlots) acquire(ctx context.Context, limit int, wait time.Duration, queued func(int)) bool {
+	d.mu.Lock()
+	if d.active < limit && len(d.waiting) == 0 {
+		d.active++
+		d.mu.Unlock()
+		return true
+	}
+	if wait <= 0 {
+		d.mu.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	d.waiting = append(d.waiting, ready)
+	d.mu.Unlock()
+	queued(1)
+	defer queued(-1)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if i := slices.Index(d.waiting, ready); i >= 0 {
+		d.waiting = slices.Delete(d.waiting, i, i+1)
+		return false
+	}
+	// 그사이 자리를 넘겨받았어 - 받은 자리는 써
+	return true
+}
+
+// release 자리를 돌려줘 - 기다리는 요청이 있고 (지금 설정의) limit 안이면 그대로 넘겨
+func (d *downloadSlots) release(limit int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.waiting) > 0 && (limit <= 0 || d.active <= limit) {
+		close(d.waiting[0])
+		d.waiting = d.waiting[1:]
+		return
+	}
+	d.active--
+}
+
+// limitDownloads name(r) 의 파일이 LargeDownload 이상이면 자리를 잡고 next 로 (못 잡으면 503 + Retry-After)
+func (s *Server) limitDownloads(name func(*http.Request) string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := s.live()
+		if cfg.MaxDownloads <= 0 || r.Method == http.MethodHead {
+			next(w, r)
+			return
+		}
+		file, ok := sanitizeFilename(name(r))
+		if !ok {
+			next(w, r)
+			return
+		}
+		// 크기를 모르면(없는 파일 등) 핸들러가 알아서 응답하게 그냥 넘겨
+		info, err := s.backend.Stat(r.Context(), file)
+		if err != nil || info.Size() < cfg.LargeDownload {
+			next(w, r)
+			return
+		}
+		start := time.Now()
+		if !s.downloads.acquire(r.Context(), cfg.MaxDownloads, cfg.DownloadQueue, func(d int) { s.metrics.queued.Add(float64(d)) }) {
+			if r.Context().Err() != nil {
+				return // 기다리다 끊었어
+			}
+			retry := max(cfg.DownloadQueue, time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retry.Round(time.Second)/time.Second)))
+			http.Error(w, msg.T("동시에 보낼 수 있는 다운로드가 다 찼습니다 - 잠시 뒤 다시 시도하세요"), http.StatusServiceUnavailable)
+			s.logger(r).WarnContext(r.Context(), "다운로드 자리 없음", "file", file, "size", info.Size(), "max", cfg.MaxDownloads, "waited", time.Since(start))
+			return
+		}
+		defer func() { s.downloads.release(s.live().MaxDownloads) }()
+		if waited := time.Since(start); waited > 10*time.Millisecond {
+			s.logger(r).DebugContext(r.Context(), "다운로드 자리를 기다림", "file", file, "waited", waited)
+		}
+		next(w, r)
+	}
+}
+
+// queryFile /download?file=, /range-download?file= 의 파일 이름
+func queryFile(r *http.Request) string { return r.URL.Query().Get("file") }
+
+// pathFile /files/이름 의 파일 이름
+func pathFile(r *http.Request) string { return strings.TrimPrefix(r.URL.Path, "/files/") }
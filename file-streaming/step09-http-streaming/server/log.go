@@ -0,0 +1,62 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+)
+
+// 요청 단위 로거
+// ⭐ 요청마다 request_id 를 붙인 로거를 ctx 에 담아서, 핸들러 안의 로그를 request_id 하나로 모아 볼 수 있어.
+// 클라이언트나 앞단 프록시가 X-Request-ID 를 보내면 그걸 그대로 써서 프록시 로그와도 이어지고, 응답 헤더로도 돌려줘.
+
+// maxRequestIDLen 이보다 긴 X-Request-ID 는 믿지 않고 새로 만들어 (로그에 아무 문자열이나 길게 박히지 않게)
+const maxRequestIDLen = 64
+
+// logRequests 요청 로거를 ctx 에 담고, 끝나면 요청 로그 한 줄 (5xx 는 Error) - AccessLog 를 줬으면 접근 로그 파일에도 (access.go)
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		lg := s.cfg.Logger.With("request_id", id)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(logging.WithContext(r.Context(), lg)))
+		s.writeAccess(r, newAccessEntry(r, id, rec, body.n, start))
+
+		level := slog.LevelInfo
+		if rec.status >= 500 {
+			level = slog.LevelError
+		}
+		lg.LogAttrs(r.Context(), level, "요청",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Int64("bytes_in", body.n),
+			slog.Duration("elapsed", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+		)
+	})
+}
+
+// logger 요청에 붙은 로거 (미들웨어를 거치지 않았으면 서버 로거)
+func (s *Server) logger(r *http.Request) *slog.Logger {
+	return logging.FromContext(r.Context(), s.cfg.Logger)
+}
+
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 파일 관리 API 와 감사 로그 (Config.AuditLog)
+// ⭐ 올리고 받는 것 말고 파일을 바꾸는 요청 - 지우기와 이름 바꾸기(옮기기)는 delete 권한이 필요하고, 할 때마다 감사 로그에 한 줄씩 덧붙여:
+//
+//	DELETE /api/files/<이름>                     → 200 {"file", "trash_id"} (로컬 디렉토리면 휴지통으로 - /api/trash 로 되살려, trash.go)
+//	POST   /api/files/<이름>/rename?to=새이름     → 200 {"file": 새이름, "from": 이름} (새이름이 있으면 409)
+//	GET    /api/files/<이름>/versions             → 덮어쓴 예전 내용들, POST …/versions/<번호>/restore 로 되돌려 (versions.go)
+//
+// 예전 /delete?file= 도 같은 삭제라 감사 로그에 남아.
+// 감사 로그는 누가(계정, IP), 언제, 무엇을(동작, 파일, 새 이름), 결과(상태, 에러)를 적은 JSON 한 줄이고,
+// 접근 로그와 달리 돌리지 않고 O_APPEND 로만 덧붙여 - 지우거나 자르는 건 운영자 몫이야.
+// 이름 바꾸기는 로컬 디렉토리면 rename(2) 라서 체크섬 속성과 중복 제거 하드 링크가 그대로고, 다른 저장소는 복사한 뒤 지워.
+
+// 감사 로그의 동작
+const (
+	AuditDelete  = "delete"
+	AuditRename  = "rename"
+	AuditRestore = "restore"
+)
+
+// auditEntry 감사 로그 한 줄
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	Account   string    `json:"account"`
+	ClientIP  string    `json:"client_ip"`
+	Action    string    `json:"action"`
+	File      string    `json:"file"`
+	To        string    `json:"to,omitempty"`       // rename 의 새 이름
+	TrashID   string    `json:"trash_id,omitempty"` // delete 가 휴지통으로 옮겼으면
+	Version   int       `json:"version,omitempty"`  // restore 가 되돌린 버전
+	Status    int       `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// auditLog O_APPEND 로 연 감사 로그 파일
+type auditLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{f: f}, nil
+}
+
+func (a *auditLog) write(e auditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.f.Write(append(line, '\n'))
+	return err
+}
+
+func (a *auditLog) Close() error { return a.f.Close() }
+
+// audit 파일을 바꾼 요청 하나를 감사 로그에 (AuditLog 를 안 줬으면 아무것도 안 해)
+func (s *Server) audit(w http.ResponseWriter, r *http.Request, e auditEntry, err error) {
+	if s.auditLog == nil {
+		return
+	}
+	e.Time = time.Now()
+	e.RequestID = w.Header().Get("X-Request-ID")
+	e.Account = s.account(r).Name
+	e.ClientIP = clientIP(r)
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if werr := s.auditLog.write(e); werr != nil {
+		s.logger(r).ErrorContext(r.Context(), "감사 로그를 쓰지 못함", "action", e.Action, "file", e.File, "err", werr)
+	}
+}
+
+// errDestExists 이름을 바꿀 자리에 이미 파일이 있어
+var errDestExists = msg.New("그 이름의 파일이 이미 있습니다")
+
+// fileHandler /api/files/<이름> (DELETE), /api/files/<이름>/rename (POST), /api/files/<이름>/versions… (versions.go)
+func (s *Server) fileHandler(w http.ResponseWriter, r *http.Request) {
+	rest, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/files/"), "/")
+	name, ok := sanitizeFilename(rest)
+	versions := sub == "versions" || strings.HasPrefix(sub, "versions/")
+	rename := sub == "rename"
+	if !ok || name != rest || sub != "" && !rename && !versions {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case versions:
+		s.versionsHandler(w, r, name, strings.TrimPrefix(strings.TrimPrefix(sub, "versions"), "/"))
+	case rename && r.Method == http.MethodPost:
+		s.renameFile(w, r, name)
+	case rename:
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	case r.Method == http.MethodDelete:
+		trashID, err := s.deleteFile(r, name)
+		status := deleteStatus(err)
+		s.audit(w, r, auditEntry{Action: AuditDelete, File: name, TrashID: trashID, Status: status}, err)
+		if !s.deleted(w, r, name, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(struct {
+			File    string `json:"file"`
+			TrashID string `json:"trash_id,omitempty"`
+		}{name, trashID})
+	default:
+		http.Error(w, msg.T("DELETE 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	}
+}
+
+// deleteFile name 을 지우고 딸린 기록(중복 제거 색인, 만료, 저장 공간, 검색 색인)도 정리 - 휴지통으로 옮겼으면 그 ID
+func (s *Server) deleteFile(r *http.Request, name string) (trashID string, err error) {
+	unlock := streamio.LockPath(s.uploadPath(name))
+	trashID, err = s.remove(r.Context(), name)
+	if err == nil {
+		s.forget(r, name)
+		if err := s.expiry.remove(name); err != nil {
+			s.logger(r).ErrorContext(r.Context(), "만료 기록 실패", "file", name, "err", err)
+		}
+	}
+	unlock()
+	if err != nil {
+		return "", err
+	}
+	s.removed(r, name)
+	s.logger(r).InfoContext(r.Context(), "파일 삭제", "file", name, "trash_id", trashID)
+	return trashID, nil
+}
+
+// removed 지운 name 의 나머지 기록(저장 공간, 검색 색인, 썸네일)도 정리 - 경로 잠금 밖에서
+func (s *Server) removed(r *http.Request, name string) {
+	if s.storage != nil {
+		if err := s.storage.Remove(name); err != nil {
+			s.logger(r).ErrorContext(r.Context(), "저장 공간 기록 실패", "file", name, "err", err)
+		}
+	}
+	s.queueIndex(s.uploadPath(name), true)
+	s.removeThumbs(name)
+}
+
+func deleteStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// deleted deleteFile 의 결과 - 없으면 404, 다른 에러면 500 을 쓰고 false
+func (s *Server) deleted(w http.ResponseWriter, r *http.Request, name string, err error) bool {
+	switch deleteStatus(err) {
+	case http.StatusOK:
+		return true
+	case http.StatusNotFound:
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+	default:
+		s.logger(r).ErrorContext(r.Context(), "파일 삭제 실패", "file", name, "err", err)
+		http.Error(w, msg.T("파일 삭제 실패"), http.StatusInternalServerError)
+	}
+	return false
+}
+
+// renameFile ?to= 로 이름 바꾸기 - 새 이름에 파일이 있으면 409 (덮어쓰지 않아)
+func (s *Server) renameFile(w http.ResponseWriter, r *http.Request, from string) {
+	to, ok := sanitizeFilename(r.URL.Query().Get("to"))
+	if !ok || to != r.URL.Query().Get("to") {
+		http.Error(w, msg.T("새 파일명(to)이 잘못됐습니다"), http.StatusBadRequest)
+		return
+	}
+	if to == from {
+		http.Error(w, msg.T("새 파일명이 지금 이름과 같습니다"), http.StatusBadRequest)
+		return
+	}
+	err := s.rename(r.Context(), from, to)
+	status := renameStatus(err)
+	s.audit(w, r, auditEntry{Action: AuditRename, File: from, To: to, Status: status}, err)
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), status)
+		return
+	case http.StatusConflict:
+		http.Error(w, errDestExists.Error()+": "+to, status)
+		return
+	default:
+		s.logger(r).ErrorContext(r.Context(), "이름 바꾸기 실패", "file", from, "to", to, "err", err)
+		http.Error(w, msg.T("이름 바꾸기 실패"), status)
+		return
+	}
+
+	s.renamed(r, from, to)
+	w.Header().Set("Content-Location", "/download?file="+url.QueryEscape(to))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(struct {
+		File string `json:"file"`
+		From string `json:"from"`
+	}{to, from})
+}
+
+// renameStatus rename 의 결과 - 없으면 404, 새 이름이 있으면 409
+func renameStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, errDestExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// renamed 이름을 바꾼 뒤 딸린 기록(중복 제거 색인, 저장 공간, 만료, 검색 색인, 썸네일)도 새 이름으로
+func (s *Server) renamed(r *http.Request, from, to string) {
+	if s.blobs != nil {
+		if err := s.blobs.Rename(from, to); err != nil {
+			s.logger(r).ErrorContext(r.Context(), "저장소 색인 기록 실패", "file", from, "to", to, "err", err)
+		}
+	}
+	if s.storage != nil {
+		if err := s.storage.Rename(from, to); err != nil {
+			s.logger(r).ErrorContext(r.Context(), "저장 공간 기록 실패", "file", from, "to", to, "err", err)
+		}
+	}
+	if err := s.expiry.rename(from, to); err != nil {
+		s.logger(r).ErrorContext(r.Context(), "만료 기록 실패", "file", from, "to", to, "err", err)
+	}
+	s.queueIndex(s.uploadPath(from), true)
+	s.queueIndex(s.uploadPath(to), false)
+	s.renameThumbs(from, to)
+	s.logger(r).InfoContext(r.Context(), "파일 이름 바꿈", "file", from, "to", to)
+}
+
+// rename 두 이름을 잠그고 from 을 to 로 - 로컬 디렉토리면 rename(2), 다른 저장소는 복사한 뒤 지워 (to 가 있으면 errDestExists)
+func (s *Server) rename(ctx context.Context, from, to string) error {
+	// 두 요청이 서로 반대로 바꿀 때 엇갈려 잠그지 않게 이름 순서로
+	first, second := from, to
+	if second < first {
+		first, second = second, first
+	}
+	defer streamio.LockPath(s.uploadPath(first))()
+	defer streamio.LockPath(s.uploadPath(second))()
+
+	if _, err := s.backend.Stat(ctx, from); err != nil {
+		return err
+	}
+	if s.exists(to) {
+		return errDestExists
+	}
+	if s.localDir() {
+		return streamio.Rename(s.uploadPath(from), s.uploadPath(to))
+	}
+	// 클라이언트가 끊어도 복사와 삭제는 끝까지 - 반쯤 옮긴 채로 남지 않게
+	ctx = context.WithoutCancel(ctx)
+	if _, err := storage.CopyFile(ctx, s.backend, from, s.backend, to, streamio.CopyOptions{BufferSize: s.live().BufferSize}); err != nil {
+		return err
+	}
+	return s.backend.Delete(ctx, from)
+}
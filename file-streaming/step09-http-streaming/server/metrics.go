@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/metrics"
+)
+
+// Prometheus 지표 (GET /metrics)
+// ⭐ 핸들러 레이블은 요청 경로가 아니라 등록한 패턴("/files/", "/api/uploads/")이야 - 파일 이름마다 시계열이 생기지 않게.
+// 요청 수와 에러 수(5xx 이거나 응답을 쓰다가 끊긴 것)는 모든 핸들러에서 세고, 걸린 시간은 짧은 요청용 버킷에 넣어.
+// 파일 내용이 오가는 핸들러(metered 로 감싼 것들)는 대신 분~시간 단위 버킷의 전송 시간에, 주고받은 바이트와 진행 중인 전송 수도 세.
+// /metrics 는 인증 없이 열려 있어 - 숫자만 있고 파일 이름이나 계정은 없어서 스크레이퍼가 자격 증명 없이 긁어 가게.
+
+// serverMetrics 서버가 내보내는 지표
+type serverMetrics struct {
+	reg *metrics.Registry
+
+	requests *metrics.Counter   // handler, code
+	errors   *metrics.Counter   // handler
+	duration *metrics.Histogram // handler
+
+	uploaded   *metrics.Counter   // handler
+	downloaded *metrics.Counter   // handler
+	active     *metrics.Gauge     // handler
+	transfer   *metrics.Histogram // handler
+	queued     *metrics.Gauge     // 다운로드 자리를 기다리는 요청 (limit.go)
+
+	uploadsRejected *metrics.Counter // MaxUploadsPerIP 로 429 를 준 업로드 (uploadlimit.go)
+	webhookFailed   *metrics.Counter // 재시도까지 다 실패한 업로드 웹훅 (webhook.go)
+}
+
+func newServerMetrics() *serverMetrics {
+	reg := metrics.NewRegistry()
+	return &serverMetrics{
+		reg:        reg,
+		requests:   reg.Counter("fs_http_requests_total", "처리한 요청 수", "handler", "code"),
+		errors:     reg.Counter("fs_http_errors_total", "5xx 로 끝났거나 응답을 쓰다가 끊긴 요청 수", "handler"),
+		duration:   reg.Histogram("fs_http_request_duration_seconds", "요청 처리 시간 (초)", metrics.DefBuckets, "handler"),
+		uploaded:   reg.Counter("fs_uploaded_bytes_total", "받은 요청 본문 바이트", "handler"),
+		downloaded: reg.Counter("fs_downloaded_bytes_total", "보낸 응답 본문 바이트 (gzip 이면 압축한 크기)", "handler"),
+		active:     reg.Gauge("fs_active_transfers", "진행 중인 전송 수", "handler"),
+		transfer:   reg.Histogram("fs_transfer_duration_seconds", "전송 시간 (초)", metrics.TransferBuckets, "handler"),
+		queued:     reg.Gauge("fs_download_queue", "MaxDownloads 가 다 차서 자리를 기다리는 다운로드 수"),
+
+		uploadsRejected: reg.Counter("fs_upload_ip_rejected_total", "주소별 동시 업로드 수(MaxUploadsPerIP)를 넘어 429 로 거절한 업로드 수"),
+		webhookFailed:   reg.Counter("fs_upload_webhook_failed_total", "재시도까지 다 실패한 업로드 웹훅 수 (URL 하나에 한 번)"),
+	}
+}
+
+// handle pattern 에 h 를 등록하면서 요청 수, 에러 수, 걸린 시간을 세
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	s.mux.Handle(pattern, s.measured(pattern, false, h))
+}
+
+// handleTransfer handle 에 더해 주고받은 바이트와 진행 중인 전송도 세 (파일 내용이 오가는 핸들러)
+func (s *Server) handleTransfer(pattern string, h http.HandlerFunc) {
+	s.mux.Handle(pattern, s.measured(pattern, true, h))
+}
+
+func (s *Server) measured(route string, transfer bool, next http.HandlerFunc) http.Handler {
+	m := s.metrics
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		var body *countingReader
+		if transfer {
+			body = &countingReader{ReadCloser: r.Body}
+			r.Body = body
+			m.active.Inc(route)
+		}
+		start := time.Now()
+		defer func() {
+			elapsed := time.Since(start).Seconds()
+			m.requests.Inc(route, strconv.Itoa(rec.status))
+			if rec.status >= 500 || rec.failed {
+				m.errors.Inc(route)
+			}
+			if !transfer {
+				m.duration.Observe(elapsed, route)
+				return
+			}
+			m.active.Dec(route)
+			m.uploaded.Add(float64(body.n), route)
+			m.downloaded.Add(float64(rec.bytes), route)
+			m.transfer.Observe(elapsed, route)
+		}()
+		next(rec, r)
+	})
+}
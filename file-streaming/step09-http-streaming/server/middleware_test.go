@@ -0,0 +1,76 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChainOrder(t *testing.T) {
+	var got []string
+	mark := func(name string) Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, name)
+				next(w, r)
+			}
+		}
+	}
+	h := chain(func(http.ResponseWriter, *http.Request) { got = append(got, "handler") }, mark("log"), mark("auth"), mark("limit"))
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if want := "log auth limit handler"; strings.Join(got, " ") != want {
+		t.Fatalf("순서 = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestChainStops(t *testing.T) {
+	deny := func(http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { http.Error(w, "no", http.StatusForbidden) }
+	}
+	called := false
+	h := chain(func(http.ResponseWriter, *http.Request) { called = true }, deny)
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if called || rec.Code != http.StatusForbidden {
+		t.Fatalf("called=%v status=%d, 막혀야 해", called, rec.Code)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	s, err := New(Config{
+		UploadDir:  t.TempDir(),
+		TrashDir:   t.TempDir(),
+		SessionDir: t.TempDir(),
+		VersionDir: t.TempDir(),
+		Logger:     slog.New(slog.DiscardHandler),
+		APIKeys:    map[string]APIKey{"key-a": {Name: "alice"}, "key-r": {Name: "reader", Scopes: []string{ScopeDownload}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := chain(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(s.account(r).Name)) }, s.requireScope(ScopeUpload))
+
+	tests := []struct {
+		key    string
+		status int
+		body   string
+	}{
+		{"", http.StatusUnauthorized, ""},
+		{"wrong", http.StatusUnauthorized, ""},
+		{"key-r", http.StatusForbidden, ""},
+		{"key-a", http.StatusOK, "alice"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/upload", nil)
+		if tt.key != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+		}
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		if rec.Code != tt.status || tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("key %q: %d %q, want %d %q", tt.key, rec.Code, rec.Body.String(), tt.status, tt.body)
+		}
+	}
+}
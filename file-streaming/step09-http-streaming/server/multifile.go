@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 여러 파일 한 번에 올리기 (/upload 의 "files" 파트)
+// ⭐ 멀티파트 본문은 한 줄로 오니까 파트는 차례로 읽을 수밖에 없어 - 읽는 쪽은 파트를 파이프로 일꾼에게 흘려 주기만 하고,
+// 일꾼은 "file" 과 같은 saveFile 로 저장해 (정책, 검사, sha256, 저장 공간 한도, 이름 충돌 그대로).
+// 파트를 다 흘려 주면 읽는 쪽은 바로 다음 파트로 가고, 앞 파일의 검사 판정, fsync, 중복 제거, 커밋은 일꾼이 그동안 마저 해 -
+// 동시에 붙잡고 있는 파일은 UploadWorkers 개까지.
+// 한 파일이 실패해도 나머지는 계속 저장하고, 응답은 파일마다 결과를 담은 JSON (다 되면 200, 하나라도 실패하면 207).
+// 본문 자체가 끊기면(MaxUploadSize 초과, 연결 끊김) 그 파일부터는 받지 못해서 거기까지만 적어.
+//
+//	curl -F files=@a.jpg -F files=@b.jpg -F files=@c.pdf http://localhost:8080/upload
+
+const (
+	multiFileField       = "files"
+	defaultUploadWorkers = 4 // Config.UploadWorkers 가 0 이면
+)
+
+// errPartDone 일꾼이 파트를 더 읽지 않을 때 파이프를 닫는 에러 - 읽는 쪽은 나머지를 건너뛰고 다음 파트로
+var errPartDone = errors.New("part done")
+
+// fileResult "files" 파트 하나의 결과
+type fileResult struct {
+	*uploadedFile        // 저장했으면
+	Original      string `json:"original"`
+	Status        int    `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+// saveFiles first 부터 "files" 파트를 UploadWorkers 개까지 동시에 저장하고 결과를 JSON 으로
+func (s *Server) saveFiles(w http.ResponseWriter, r *http.Request, mr *multipart.Reader, first *multipart.Part, acct APIKey, expected []string) {
+	slots := make(chan struct{}, s.live().UploadWorkers)
+	var (
+		wg      sync.WaitGroup
+		results []*fileResult
+		readErr error
+	)
+	for part := first; part != nil; {
+		i := len(results)
+		res := &fileResult{Original: part.FileName()}
+		results = append(results, res)
+		want := "" // X-Expected-SHA256 의 i 번째 값
+		if i < len(expected) {
+			want = expected[i]
+		}
+
+		slots <- struct{}{}
+		pr, pw := io.Pipe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			rec := &partRecorder{header: http.Header{}}
+			f, ok := s.saveNamed(rec, r, res.Original, pr, acct, want)
+			pr.CloseWithError(errPartDone) // 실패해서 덜 읽었으면 읽는 쪽이 기다리지 않게
+			if ok {
+				res.uploadedFile, res.Original, res.Status = &f, f.Original, http.StatusOK
+				return
+			}
+			res.Status, res.Error = rec.status, rec.message()
+		}()
+
+		_, err := io.Copy(pw, part)
+		part.Close()
+		if err != nil && !errors.Is(err, errPartDone) {
+			pw.CloseWithError(err) // 일꾼은 이 에러로 실패를 적어 (413 이면 413)
+			readErr = err
+			break
+		}
+		pw.Close()
+
+		part, err = nextFilePart(mr, multiFileField)
+		if err != nil && !errors.Is(err, io.EOF) {
+			readErr = err
+			break
+		}
+	}
+	wg.Wait()
+	if readErr != nil {
+		s.logger(r).WarnContext(r.Context(), "여러 파일 업로드 본문이 끊김", "files", len(results), "err", readErr)
+	}
+
+	status := http.StatusOK
+	resp := map[string]any{"files": results}
+	if readErr != nil {
+		status, resp["error"] = http.StatusMultiStatus, msg.T("본문을 끝까지 받지 못했습니다")
+	}
+	saved := make([]uploadedFile, 0, len(results))
+	for _, res := range results {
+		if res.uploadedFile == nil {
+			status = http.StatusMultiStatus
+			continue
+		}
+		saved = append(saved, *res.uploadedFile)
+	}
+	setChecksumHeader(w, saved)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// partRecorder saveFile 이 쓰는 에러 응답을 파일 하나의 결과로 받아 두는 ResponseWriter
+type partRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (p *partRecorder) Header() http.Header { return p.header }
+
+func (p *partRecorder) WriteHeader(status int) {
+	if p.status == 0 {
+		p.status = status
+	}
+}
+
+func (p *partRecorder) Write(b []byte) (int, error) {
+	p.WriteHeader(http.StatusOK)
+	return p.body.Write(b)
+}
+
+// message 에러 응답 본문 - JSON 이면 error 필드
+func (p *partRecorder) message() string {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(p.body.Bytes(), &body) == nil && body.Error != "" {
+		return body.Error
+	}
+	return strings.TrimSpace(p.body.String())
+}
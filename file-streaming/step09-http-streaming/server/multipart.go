@@ -0,0 +1,481 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 멀티파트 업로드 (S3 방식) - 큰 파일을 N 개 파트로 나눠 동시에 올리고 마지막에 한 파일로 합쳐
+// ⭐ 이어 올리기(/api/uploads)는 한 줄로 이어 써야 하지만, 여기는 파트마다 번호가 있어서 순서 없이 동시에 보내도 돼:
+//
+//	POST   /api/multipart?file=이름                     → 201 {"upload_id": …}, Location: /api/multipart/<id>
+//	PUT    /api/multipart/<id>?part=N   (본문 = 파트 N) → 200, ETag: "파트의 sha256" (같은 번호를 다시 보내면 바꿔)
+//	GET    /api/multipart/<id>                          → 200 {"parts": [{"part", "size", "etag"}]}
+//	POST   /api/multipart/<id>   {"parts": [{"part": 1, "etag": …}, …]} → 200 {"file", "size", "etag", "sha256"}
+//	DELETE /api/multipart/<id>                          → 204 (받은 파트 버림)
+//
+// 파트는 SessionDir/multipart/<id>/ 에 번호 이름으로 남아서 서버를 재시작해도 이어 올리고(업로드 정보는 같은 곳의 upload.json),
+// 완료(POST)하면 적은 순서대로 이어 붙이면서 sha256 을 재고 /api/uploads 처럼 검사, 이름 정책, 저장소를 거쳐.
+// 완료 본문을 비우면 받은 파트 전부를 번호 순서로. 완료 응답의 etag 는 /download 가 주는 것과 같아서 바로 If-Range 에 쓸 수 있어.
+// 파트 하나는 MaxUploadSize 까지, 합친 크기도 MaxUploadSize 와 저장 공간 한도를 넘으면 413.
+
+const (
+	maxParts       = 10000
+	multipartDir   = "multipart"
+	multipartState = "upload.json"
+)
+
+// multipartUpload 멀티파트 업로드 하나 (upload.json 에 남는 값)
+type multipartUpload struct {
+	Name    string    `json:"name"`            // 합치면 이 이름으로 (sanitizeFilename 을 거친 값)
+	Owner   string    `json:"owner,omitempty"` // 올린 계정 (저장 공간을 셀 때만)
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"` // 마지막으로 파트를 받은 시각 - 만료 기준
+
+	dir string
+	// lock 파트는 RLock 으로 동시에, 완료/취소는 Lock (받는 중인 파트가 있으면 423)
+	lock sync.RWMutex
+}
+
+func (u *multipartUpload) expires() time.Time { return u.Updated.Add(sessionTTL) }
+
+// multipartPart 받은 파트 하나
+type multipartPart struct {
+	Part int    `json:"part"`
+	Size int64  `json:"size,omitempty"`
+	ETag string `json:"etag"`
+}
+
+// multipartStore SessionDir/multipart 의 업로드들
+type multipartStore struct {
+	dir string
+
+	mu      sync.Mutex
+	uploads map[string]*multipartUpload
+}
+
+// openMultipart dir 아래 업로드를 되살려 (upload.json 이 없거나 만료된 건 지워)
+func openMultipart(sessionDir string) (*multipartStore, error) {
+	dir := filepath.Join(sessionDir, multipartDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	st := &multipartStore{dir: dir, uploads: map[string]*multipartUpload{}}
+	for _, e := range entries {
+		u := &multipartUpload{dir: filepath.Join(dir, e.Name())}
+		data, err := os.ReadFile(filepath.Join(u.dir, multipartState))
+		if err == nil {
+			err = json.Unmarshal(data, u)
+		}
+		if err != nil || time.Now().After(u.expires()) {
+			os.RemoveAll(u.dir)
+			continue
+		}
+		st.uploads[e.Name()] = u
+	}
+	return st, nil
+}
+
+// create 빈 업로드 디렉토리와 upload.json
+func (st *multipartStore) create(name, owner string) (string, *multipartUpload, error) {
+	var b [16]byte
+	rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+	now := time.Now()
+	u := &multipartUpload{Name: name, Owner: owner, Created: now, Updated: now, dir: filepath.Join(st.dir, id)}
+	if err := os.Mkdir(u.dir, 0700); err != nil {
+		return "", nil, err
+	}
+	if err := st.save(u); err != nil {
+		os.RemoveAll(u.dir)
+		return "", nil, err
+	}
+	st.mu.Lock()
+	st.uploads[id] = u
+	st.mu.Unlock()
+	return id, u, nil
+}
+
+// get id 의 업로드 (없거나 만료됐으면 nil)
+func (st *multipartStore) get(id string) *multipartUpload {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	u := st.uploads[id]
+	if u != nil && time.Now().After(u.expires()) {
+		delete(st.uploads, id)
+		os.RemoveAll(u.dir)
+		return nil
+	}
+	return u
+}
+
+// remove 업로드와 받은 파트를 지워
+func (st *multipartStore) remove(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if u := st.uploads[id]; u != nil {
+		delete(st.uploads, id)
+		os.RemoveAll(u.dir)
+	}
+}
+
+// save upload.json 을 임시 파일 → rename 으로 (파트를 받을 때마다 Updated 가 밀려)
+func (st *multipartStore) save(u *multipartUpload) error {
+	st.mu.Lock()
+	data, err := json.Marshal(u)
+	st.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(u.dir, "."+multipartState+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), filepath.Join(u.dir, multipartState))
+}
+
+// touch 파트를 받은 시각을 갱신해서 upload.json 에
+func (st *multipartStore) touch(u *multipartUpload) error {
+	st.mu.Lock()
+	u.Updated = time.Now()
+	st.mu.Unlock()
+	return st.save(u)
+}
+
+// partPath 파트 n 의 파일 (번호를 0 으로 채워서 이름 순서가 곧 번호 순서)
+func (u *multipartUpload) partPath(n int) string {
+	return filepath.Join(u.dir, fmt.Sprintf("%05d.part", n))
+}
+
+// parts 받은 파트를 번호 순서로 - ETag 는 파트 파일 옆의 .sha256 에 적어 둔 값
+func (u *multipartUpload) parts() ([]multipartPart, error) {
+	paths, err := filepath.Glob(filepath.Join(u.dir, "*.part"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	parts := make([]multipartPart, 0, len(paths))
+	for _, path := range paths {
+		n, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(path), ".part"))
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := os.ReadFile(path + ".sha256")
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, multipartPart{Part: n, Size: info.Size(), ETag: `"` + string(sum) + `"`})
+	}
+	return parts, nil
+}
+
+// multipartHandler /api/multipart (만들기) 와 /api/multipart/<id> (파트, 목록, 완료, 취소)
+func (s *Server) multipartHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/multipart"), "/")
+	if id == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+			return
+		}
+		s.createMultipart(w, r)
+		return
+	}
+	u := s.multipart.get(id)
+	if u == nil {
+		http.Error(w, msg.T("멀티파트 업로드가 없거나 만료됐습니다"), http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		s.putPart(w, r, id, u)
+	case http.MethodGet:
+		u.lock.RLock()
+		parts, err := u.parts()
+		u.lock.RUnlock()
+		if err != nil {
+			http.Error(w, msg.T("멀티파트 업로드를 읽을 수 없습니다"), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(struct {
+			UploadID string          `json:"upload_id"`
+			File     string          `json:"file"`
+			Expires  time.Time       `json:"expires"`
+			Parts    []multipartPart `json:"parts"`
+		}{id, u.Name, u.expires(), parts})
+	case http.MethodPost:
+		s.completeMultipart(w, r, id, u)
+	case http.MethodDelete:
+		if !u.lock.TryLock() {
+			http.Error(w, msg.T("파트를 받는 중입니다"), http.StatusLocked)
+			return
+		}
+		defer u.lock.Unlock()
+		s.multipart.remove(id)
+		s.logger(r).InfoContext(r.Context(), "멀티파트 업로드 취소", "upload", id, "file", u.Name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, msg.T("PUT, GET, POST, DELETE 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	}
+}
+
+// createMultipart 업로드 만들기 - 크기는 합칠 때 알아서 저장 공간은 그때 확인해
+func (s *Server) createMultipart(w http.ResponseWriter, r *http.Request) {
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		http.Error(w, msg.T("파일명이 필요합니다"), http.StatusBadRequest)
+		return
+	}
+	name, ok := sanitizeFilename(filename)
+	if !ok {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	// 확장자 정책은 이름만 보면 되니까 받기 전에 (내용 형식은 다 모은 뒤에)
+	if err := s.live().Policy.CheckName(name); err != nil {
+		s.policyFailed(w, r, name, err)
+		return
+	}
+	// reject 정책이면 받기 전에 미리 (합칠 때 다시 확인해)
+	if s.live().Collision == CollisionReject && s.exists(name) {
+		s.nameConflict(w, r, name, errNameTaken)
+		return
+	}
+	id, u, err := s.multipart.create(name, s.account(r).Name)
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "멀티파트 업로드 생성 실패", "file", name, "err", err)
+		http.Error(w, msg.T("멀티파트 업로드 생성 실패"), http.StatusInternalServerError)
+		return
+	}
+	s.logger(r).InfoContext(r.Context(), "멀티파트 업로드 시작", "upload", id, "file", name)
+	w.Header().Set("Location", "/api/multipart/"+id)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		UploadID string    `json:"upload_id"`
+		File     string    `json:"file"`
+		Expires  time.Time `json:"expires"`
+	}{id, name, u.expires()})
+}
+
+// putPart 본문을 파트 N 으로 - 임시 파일에 다 받은 뒤 rename 해서, 끊기면 예전 파트(없었으면 빈자리)가 그대로 남아
+func (s *Server) putPart(w http.ResponseWriter, r *http.Request, id string, u *multipartUpload) {
+	n, err := strconv.Atoi(r.URL.Query().Get("part"))
+	if err != nil || n < 1 || n > maxParts {
+		http.Error(w, msg.Sprintf("part 는 1 ~ %d 이어야 합니다", maxParts), http.StatusBadRequest)
+		return
+	}
+	if !u.lock.TryRLock() {
+		http.Error(w, msg.T("업로드를 합치거나 취소하는 중입니다"), http.StatusLocked)
+		return
+	}
+	defer u.lock.RUnlock()
+	limit := s.live().MaxUploadSize
+	if bodyTooLargeUpfront(w, r, limit) {
+		return
+	}
+
+	tmp, err := os.CreateTemp(u.dir, ".part-*")
+	if err != nil {
+		http.Error(w, msg.T("멀티파트 업로드를 열 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	s.throttleBody(r)
+	limitBody(w, r, limit)
+	body := r.Body
+	h := sha256.New()
+	info := streamio.TransferInfo{ID: id + "/" + strconv.Itoa(n), Src: r.RemoteAddr, Dst: u.partPath(n), Size: r.ContentLength}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferUpload, u.Name)}
+	_, err = streamio.Copy(r.Context(), io.MultiWriter(tmp, h), body, info, opts)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		if !uploadTooLarge(w, err) {
+			s.logger(r).WarnContext(r.Context(), "파트 받기 실패", "upload", id, "part", n, "err", err)
+			http.Error(w, msg.T("본문을 끝까지 받지 못했습니다"), http.StatusBadRequest)
+		}
+		return
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	// .sha256 을 먼저 - 파트 파일이 보일 때는 ETag 가 이미 있어 (같은 번호를 동시에 두 번 보내면 나중 것이 이겨)
+	err = os.WriteFile(u.partPath(n)+".sha256", []byte(sum), 0600)
+	if err == nil {
+		err = streamio.Rename(tmp.Name(), u.partPath(n))
+	}
+	if err == nil {
+		err = s.multipart.touch(u)
+	}
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "파트 저장 실패", "upload", id, "part", n, "err", err)
+		http.Error(w, msg.T("파트 저장 실패"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", `"`+sum+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// completeMultipart 적은 파트를 순서대로 이어 붙여서 저장 - 본문이 비면 받은 파트 전부
+func (s *Server) completeMultipart(w http.ResponseWriter, r *http.Request, id string, u *multipartUpload) {
+	var req struct {
+		Parts []multipartPart `json:"parts"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, msg.T("완료 요청 본문(JSON)을 읽을 수 없습니다"), http.StatusBadRequest)
+		return
+	}
+	if !u.lock.TryLock() {
+		http.Error(w, msg.T("파트를 받는 중입니다"), http.StatusLocked)
+		return
+	}
+	defer u.lock.Unlock()
+
+	have, err := u.parts()
+	if err != nil {
+		http.Error(w, msg.T("멀티파트 업로드를 읽을 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+	parts, err := pickParts(have, req.Parts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var size int64
+	for _, p := range parts {
+		size += p.Size
+	}
+	if limit := s.live().MaxUploadSize; limit > 0 && size > limit {
+		uploadTooLarge(w, tooLarge(limit, size))
+		return
+	}
+	acct := s.account(r)
+	if room := s.storageRoom(acct, u.Name); room >= 0 && size > room {
+		s.storageFull(w, r, acct, u.Name, size)
+		return
+	}
+
+	// 파트를 세션 디렉토리의 한 파일로 모은 뒤 /api/uploads 와 같은 길로 (검사, 이름 정책, 저장소)
+	joined := filepath.Join(u.dir, "joined")
+	sum, err := joinParts(u, parts, joined)
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "파트 합치기 실패", "upload", id, "err", err)
+		http.Error(w, msg.T("파트 합치기 실패"), http.StatusInternalServerError)
+		return
+	}
+	name, ok := s.storeSession(w, r, id, joined, u.Name, u.Owner, size, func() { s.multipart.remove(id) })
+	if !ok {
+		os.Remove(joined)
+		return
+	}
+	s.multipart.remove(id)
+
+	resp := struct {
+		File   string `json:"file"`
+		Size   int64  `json:"size"`
+		Parts  int    `json:"parts"`
+		ETag   string `json:"etag,omitempty"`
+		SHA256 string `json:"sha256"`
+	}{File: name, Size: size, Parts: len(parts), SHA256: sum}
+	if info, err := s.backend.Stat(r.Context(), name); err == nil {
+		s.hashes.put(name, info.Size(), info.ModTime(), sum)
+		resp.ETag = etag(info)
+		w.Header().Set("ETag", resp.ETag)
+	}
+	w.Header().Set("Content-Location", "/download?file="+url.QueryEscape(name))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// pickParts 완료 요청이 적은 파트 - 번호가 늘어나는 순서여야 하고, ETag 를 적었으면 받은 것과 같아야 해 (비었으면 받은 것 전부)
+func pickParts(have, want []multipartPart) ([]multipartPart, error) {
+	if len(want) == 0 {
+		if len(have) == 0 {
+			return nil, msg.New("받은 파트가 없습니다")
+		}
+		return have, nil
+	}
+	parts := make([]multipartPart, 0, len(want))
+	for i, p := range want {
+		if i > 0 && p.Part <= want[i-1].Part {
+			return nil, msg.New("파트 번호는 늘어나는 순서로 적어야 합니다")
+		}
+		j := slices.IndexFunc(have, func(h multipartPart) bool { return h.Part == p.Part })
+		if j < 0 {
+			return nil, msg.Errorf("받지 않은 파트입니다: %d", p.Part)
+		}
+		if p.ETag != "" && strings.Trim(p.ETag, `"`) != strings.Trim(have[j].ETag, `"`) {
+			return nil, msg.Errorf("파트 %d 의 ETag 가 받은 것과 다릅니다", p.Part)
+		}
+		parts = append(parts, have[j])
+	}
+	return parts, nil
+}
+
+// joinParts parts 를 순서대로 dst 에 이어 쓰면서 잰 sha256
+func joinParts(u *multipartUpload, parts []multipartPart, dst string) (string, error) {
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	w := io.MultiWriter(out, h)
+	for _, p := range parts {
+		if err = appendFile(w, u.partPath(p.Part)); err != nil {
+			break
+		}
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/policy"
+)
+
+// 업로드 정책 (Config.Policy)
+// ⭐ 받을 확장자, 받지 않을 확장자, 받을 내용 형식(앞 512 바이트를 http.DetectContentType 으로 본), 형식별 최대 크기.
+// /upload, WebDAV PUT, SFTP 는 saveFile 이 본문 앞부분만 bufio 로 미리 읽어(Peek) 판정해서, 걸리면 한 바이트도 쓰지 않고 415.
+// 통과하면 미리 읽은 것까지 그대로 흘려 - 다시 읽지 않아. 형식별 최대 크기는 MaxUploadSize 처럼 읽으면서 세서 넘으면 413.
+// 이어 올리기와 멀티파트 업로드는 만들 때 확장자를, 다 모은 뒤 내용 형식과 크기를 봐 (걸리면 세션도 버려).
+// /api/extract, /upload-archive 로 푼 파일에는 적용하지 않아 - 아카이브 자체가 /upload 로 들어올 때 걸러.
+
+// policyRejection 거절했을 때 응답 본문
+type policyRejection struct {
+	Error  string `json:"error"`
+	File   string `json:"file"`
+	Type   string `json:"type,omitempty"` // 내용으로 본 형식 (확장자로 걸렸으면 없어)
+	Reason string `json:"reason"`
+}
+
+// checkPolicy body 앞부분을 보고 Config.Policy 대로 판정 - 통과하면 미리 읽은 것을 되돌린(형식별 한도가 있으면 건) 본문,
+// 걸리면 응답까지 쓰고 false
+func (s *Server) checkPolicy(w http.ResponseWriter, r *http.Request, original string, body io.Reader) (io.Reader, bool) {
+	rules := s.live().Policy
+	if rules.IsZero() {
+		return body, true
+	}
+	br := bufio.NewReaderSize(body, policy.SniffLen)
+	head, err := br.Peek(policy.SniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		if !uploadTooLarge(w, err) {
+			s.logger(r).WarnContext(r.Context(), "업로드 앞부분을 받지 못함", "file", original, "err", err)
+			http.Error(w, msg.T("본문을 끝까지 받지 못했습니다"), http.StatusBadRequest)
+		}
+		return nil, false
+	}
+	_, limit, err := rules.Check(original, head)
+	if err != nil {
+		s.policyFailed(w, r, original, err)
+		return nil, false
+	}
+	if limit > 0 {
+		return limitReader(w, io.NopCloser(br), limit, 0), true
+	}
+	return br, true
+}
+
+// checkPolicyFile 다 모은 src 를 Config.Policy 대로 판정 (이어 올리기, 멀티파트 업로드)
+// 걸리면 응답까지 쓰고 false - 같은 내용은 다시 보내도 걸리니 discard 로 세션도 버려
+func (s *Server) checkPolicyFile(w http.ResponseWriter, r *http.Request, src, original string, size int64, discard func()) bool {
+	rules := s.live().Policy
+	if rules.IsZero() {
+		return true
+	}
+	head, err := readHead(src, policy.SniffLen)
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "업로드 앞부분을 읽지 못함", "file", original, "err", err)
+		http.Error(w, msg.T("파일 저장 실패"), http.StatusInternalServerError)
+		return false
+	}
+	_, limit, err := rules.Check(original, head)
+	if err != nil {
+		discard()
+		s.policyFailed(w, r, original, err)
+		return false
+	}
+	if limit > 0 && size > limit {
+		discard()
+		uploadTooLarge(w, tooLarge(limit, size))
+		return false
+	}
+	return true
+}
+
+// policyFailed 정책 판정 err 로 응답 (*policy.Rejected 면 415 JSON)
+func (s *Server) policyFailed(w http.ResponseWriter, r *http.Request, name string, err error) {
+	var rej *policy.Rejected
+	if !errors.As(err, &rej) {
+		s.logger(r).ErrorContext(r.Context(), "업로드 정책 판정 실패", "file", name, "err", err)
+		http.Error(w, msg.T("파일 저장 실패"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnsupportedMediaType)
+	json.NewEncoder(w).Encode(policyRejection{Error: msg.T("업로드 정책에서 거절되었습니다"), File: name, Type: rej.Type, Reason: rej.Reason})
+	s.logger(r).WarnContext(r.Context(), "업로드 정책에서 거절", "file", name, "type", rej.Type, "reason", rej.Reason)
+}
+
+// readHead path 의 앞 n 바이트 (더 짧으면 있는 만큼)
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, n)
+	m, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return head[:m], nil
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// API 키별 저장 공간 한도
+// ⭐ 전송량(usage.go)과 달리 지금 업로드 디렉토리에 남아 있는 바이트로 세 - 지우면 그만큼 다시 올릴 수 있어.
+// /upload 는 본문을 남은 공간만큼만 읽다가 넘으면 받던 임시 파일을 버리고, 이어 올리기는 만들 때 Upload-Length 로 미리 거절해 (둘 다 413 + JSON).
+// 동시에 올라오는 업로드는 끝난 것만 세서 마지막 몇 건은 한도를 조금 넘을 수 있어. /api/extract, /upload-archive 는 풀린 파일을 합친 크기로 미리 거절해.
+
+// quotaError 저장 공간이 모자랄 때 413 응답 본문
+type quotaError struct {
+	Error     string `json:"error"`
+	Account   string `json:"account"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+	Remaining int64  `json:"remaining"`           // 이 파일 이름으로 더 올릴 수 있는 바이트 (자기 파일을 덮어쓰면 그 크기도 쳐)
+	Requested int64  `json:"requested,omitempty"` // 올리려던 크기 (이어 올리기처럼 미리 알 때만)
+}
+
+// openStorage StorageFile 을 열고, 서버가 꺼진 사이 디렉토리에서 직접 지우거나 바꾼 파일을 기록에 맞춰
+func (s *Server) openStorage() (*usage.Storage, error) {
+	st, err := usage.OpenStorage(s.cfg.StorageFile)
+	if err != nil {
+		return nil, err
+	}
+	err = st.Prune(func(name string) (int64, bool) {
+		info, err := s.backend.Stat(context.Background(), name)
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		return info.Size(), true
+	})
+	return st, err
+}
+
+// storageRoom acct 가 name 으로 더 올릴 수 있는 바이트 (세지 않거나 제한이 없으면 -1)
+func (s *Server) storageRoom(acct APIKey, name string) int64 {
+	if s.storage == nil {
+		return -1
+	}
+	return s.storage.Available(acct.Name, name, acct.Storage)
+}
+
+// storageFull 413 과 남은 공간을 JSON 으로
+func (s *Server) storageFull(w http.ResponseWriter, r *http.Request, acct APIKey, name string, requested int64) {
+	body := quotaError{
+		Error:     msg.T("저장 공간 한도를 넘었습니다"),
+		Account:   acct.Name,
+		Limit:     acct.Storage,
+		Used:      s.storage.Used(acct.Name),
+		Remaining: s.storageRoom(acct, name),
+		Requested: requested,
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(body)
+	s.logger(r).WarnContext(r.Context(), "저장 공간 한도 초과", "account", acct.Name, "file", name, "limit", acct.Storage, "used", body.Used)
+}
+
+// storePut 다 받은 파일을 계정의 저장 공간에 더해 (기록을 못 써도 업로드는 성공 - 로그만)
+func (s *Server) storePut(r *http.Request, name, owner string, size int64) {
+	if s.storage == nil {
+		return
+	}
+	if err := s.storage.Put(name, owner, size); err != nil {
+		s.logger(r).ErrorContext(r.Context(), "저장 공간 기록 실패", "file", name, "account", owner, "err", err)
+	}
+}
+
+// quotaReader n 바이트(남은 공간)까지만 읽고, 더 있으면 usage.ErrStorageQuota (n 이 음수면 제한 없음)
+type quotaReader struct {
+	r io.Reader
+	n int64
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	if q.n < 0 {
+		return q.r.Read(p)
+	}
+	// 한 바이트 더 읽어 봐야 딱 맞게 끝난 건지 넘친 건지 알아
+	if int64(len(p)) > q.n+1 {
+		p = p[:q.n+1]
+	}
+	n, err := q.r.Read(p)
+	if int64(n) > q.n {
+		n, q.n = int(q.n), 0
+		return n, usage.ErrStorageQuota
+	}
+	q.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,470 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 범위 올리기 (PUT /upload/<이름> + Content-Range) - 다시 보내기밖에 모르는 단순한 클라이언트를 위한 이어 올리기
+// ⭐ 세션 id 를 주고받지 않아: 이름(과 계정)이 곧 세션이고, 본문은 Content-Range 가 적은 자리에 그대로 써.
+// 범위는 순서 없이, 겹쳐도, 동시에 보내도 돼 - 받은 범위를 모아서 빈틈이 없어지면 그 요청이 저장까지 하고 201.
+//
+//	PUT    /upload/이름   Content-Range: bytes 0-1048575/5242880  (본문 = 그 범위) → 202 {"received", "ranges"}, 다 모이면 201
+//	PUT    /upload/이름   Content-Range: bytes */5242880          (빈 본문)        → 202 받은 범위만 알려줘 (Range: bytes=0-1048575,…)
+//	PUT    /upload/이름   Content-Range 없이 (Content-Length)                       → 통째로 한 번에
+//	DELETE /upload/이름                                                           → 204 (받은 것 버림)
+//
+// 받는 중인 내용은 SessionDir/ranges/<id>/data 에 전체 크기로 미리 늘려 둔 희소 파일로, 받은 범위는 같은 곳의 upload.json 에 남아서
+// 서버를 재시작해도 이어 받아. 쓴 만큼 fsync 한 뒤에 받은 범위로 적으니, 끊긴 요청도 받은 앞부분은 남아.
+// 다 모이면 /api/uploads 와 같은 길(storeSession)로 - 업로드 정책, 검사, 이름 정책, 저장소. 24시간 동안 범위가 안 오면 지워.
+//
+//	curl -T big.iso http://localhost:8080/upload/big.iso                                                       # 통째로
+//	curl -T part.0001 -H 'Content-Range: bytes 67108864-134217727/734003200' http://localhost:8080/upload/big.iso  # 두 번째 64MB
+
+const (
+	rangesDir     = "ranges"
+	rangeDataFile = "data"
+)
+
+// rangeUpload 범위 올리기 하나 (upload.json 에 남는 값)
+type rangeUpload struct {
+	Name    string     `json:"name"`            // 다 모이면 이 이름으로 (sanitizeFilename 을 거친 값)
+	Owner   string     `json:"owner,omitempty"` // 올린 계정 (저장 공간을 셀 때만)
+	Size    int64      `json:"size"`            // 전체 크기 (Content-Range 의 /뒤)
+	Ranges  [][2]int64 `json:"ranges"`          // 받은 범위 [시작, 끝) - 정렬해서 맞닿은 건 합쳐 둬
+	Created time.Time  `json:"created"`
+	Updated time.Time  `json:"updated"` // 마지막으로 범위를 받은 시각 - 만료 기준
+
+	dir string
+	// lock 범위는 RLock 으로 동시에, 저장/취소는 Lock (받는 중인 범위가 있으면 423)
+	lock sync.RWMutex
+}
+
+func (u *rangeUpload) expires() time.Time { return u.Updated.Add(sessionTTL) }
+
+func (u *rangeUpload) dataPath() string { return filepath.Join(u.dir, rangeDataFile) }
+
+// rangeStore SessionDir/ranges 의 범위 올리기들
+type rangeStore struct {
+	dir string
+
+	mu      sync.Mutex // uploads 와 각 업로드의 Ranges, Updated
+	uploads map[string]*rangeUpload
+}
+
+// openRanges dir 아래 범위 올리기를 되살려 (upload.json 이 없거나 만료된 건 지워)
+func openRanges(sessionDir string) (*rangeStore, error) {
+	dir := filepath.Join(sessionDir, rangesDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	st := &rangeStore{dir: dir, uploads: map[string]*rangeUpload{}}
+	for _, e := range entries {
+		u := &rangeUpload{dir: filepath.Join(dir, e.Name())}
+		data, err := os.ReadFile(filepath.Join(u.dir, multipartState))
+		if err == nil {
+			err = json.Unmarshal(data, u)
+		}
+		if err == nil {
+			_, err = os.Stat(u.dataPath())
+		}
+		if err != nil || time.Now().After(u.expires()) {
+			os.RemoveAll(u.dir)
+			continue
+		}
+		st.uploads[e.Name()] = u
+	}
+	return st, nil
+}
+
+// rangeID 계정과 이름으로 정해지는 세션 id - 클라이언트는 id 를 몰라도 같은 이름으로 다시 보내면 돼
+func rangeID(owner, name string) string {
+	sum := sha256.Sum256([]byte(owner + "\x00" + name))
+	return hex.EncodeToString(sum[:16])
+}
+
+// get id 의 업로드 (없거나 만료됐으면 nil)
+func (st *rangeStore) get(id string) *rangeUpload {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	u := st.uploads[id]
+	if u != nil && time.Now().After(u.expires()) {
+		delete(st.uploads, id)
+		os.RemoveAll(u.dir)
+		return nil
+	}
+	return u
+}
+
+// create size 로 늘린 빈 data 와 upload.json - 그사이 누가 같은 id 로 만들었으면 그걸 돌려줘
+func (st *rangeStore) create(id, name, owner string, size int64) (*rangeUpload, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if u := st.uploads[id]; u != nil {
+		return u, nil
+	}
+	now := time.Now()
+	u := &rangeUpload{Name: name, Owner: owner, Size: size, Ranges: [][2]int64{}, Created: now, Updated: now, dir: filepath.Join(st.dir, id)}
+	if err := os.Mkdir(u.dir, 0700); err != nil {
+		return nil, err
+	}
+	// 희소 파일 - 받지 않은 자리는 디스크를 차지하지 않아
+	err := os.WriteFile(u.dataPath(), nil, 0644)
+	if err == nil {
+		err = os.Truncate(u.dataPath(), size)
+	}
+	if err == nil {
+		err = st.saveLocked(u)
+	}
+	if err != nil {
+		os.RemoveAll(u.dir)
+		return nil, err
+	}
+	st.uploads[id] = u
+	return u, nil
+}
+
+// remove 업로드와 받은 내용을 지워
+func (st *rangeStore) remove(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if u := st.uploads[id]; u != nil {
+		delete(st.uploads, id)
+		os.RemoveAll(u.dir)
+	}
+}
+
+// add [start, end) 를 받은 범위에 더해서 upload.json 에 (만료도 밀려)
+func (st *rangeStore) add(u *rangeUpload, start, end int64) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	u.Ranges = addRange(u.Ranges, start, end)
+	u.Updated = time.Now()
+	return st.saveLocked(u)
+}
+
+// status 받은 범위와 바이트 (복사본)
+func (st *rangeStore) status(u *rangeUpload) ([][2]int64, int64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var received int64
+	for _, rg := range u.Ranges {
+		received += rg[1] - rg[0]
+	}
+	return slices.Clone(u.Ranges), received
+}
+
+// saveLocked upload.json 을 임시 파일 → rename 으로 (mu 를 잡고 불러)
+func (st *rangeStore) saveLocked(u *rangeUpload) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(u.dir, "."+multipartState+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), filepath.Join(u.dir, multipartState))
+}
+
+// addRange 정렬된 ranges 에 [start, end) 를 넣고 겹치거나 맞닿은 건 합쳐
+func addRange(ranges [][2]int64, start, end int64) [][2]int64 {
+	out := make([][2]int64, 0, len(ranges)+1)
+	for _, rg := range ranges {
+		switch {
+		case rg[1] < start:
+			out = append(out, rg)
+		case rg[0] > end:
+			if start >= 0 {
+				out = append(out, [2]int64{start, end})
+				start = -1
+			}
+			out = append(out, rg)
+		default:
+			start, end = min(start, rg[0]), max(end, rg[1])
+		}
+	}
+	if start >= 0 {
+		out = append(out, [2]int64{start, end})
+	}
+	return out
+}
+
+// parseContentRange "bytes 0-99/1000" → 0, 99, 1000 / "bytes */1000" → -1, -1, 1000 (전체 크기는 꼭 있어야 해)
+func parseContentRange(v string) (start, end, size int64, ok bool) {
+	spec, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	rng, total, found := strings.Cut(strings.TrimSpace(spec), "/")
+	size, err := strconv.ParseInt(total, 10, 64)
+	if !found || err != nil || size < 0 {
+		return 0, 0, 0, false
+	}
+	if rng == "*" {
+		return -1, -1, size, true
+	}
+	first, last, found := strings.Cut(rng, "-")
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	if !found || err1 != nil || err2 != nil || start < 0 || end < start || end >= size {
+		return 0, 0, 0, false
+	}
+	return start, end, size, true
+}
+
+// rangeHeader 받은 범위를 Range 헤더 값으로 ("bytes=0-99,200-299", 받은 게 없으면 "")
+func rangeHeader(ranges [][2]int64) string {
+	parts := make([]string, len(ranges))
+	for i, rg := range ranges {
+		parts[i] = fmt.Sprintf("%d-%d", rg[0], rg[1]-1)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "bytes=" + strings.Join(parts, ",")
+}
+
+// rangeUploadHandler /upload/<이름> - PUT 은 범위 받기(와 다 모이면 저장), DELETE 는 취소
+func (s *Server) rangeUploadHandler(w http.ResponseWriter, r *http.Request) {
+	filename := strings.TrimPrefix(r.URL.Path, "/upload/")
+	name, ok := sanitizeFilename(filename)
+	if !ok || name != filename {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	id := rangeID(s.account(r).Name, name)
+	switch r.Method {
+	case http.MethodPut:
+		s.putRange(w, r, id, name)
+	case http.MethodDelete:
+		u := s.ranges.get(id)
+		if u == nil {
+			http.Error(w, msg.T("받는 중인 업로드가 없습니다"), http.StatusNotFound)
+			return
+		}
+		if !u.lock.TryLock() {
+			http.Error(w, msg.T("범위를 받는 중입니다"), http.StatusLocked)
+			return
+		}
+		defer u.lock.Unlock()
+		s.ranges.remove(id)
+		s.logger(r).InfoContext(r.Context(), "범위 올리기 취소", "upload", id, "file", name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, msg.T("PUT, DELETE 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	}
+}
+
+// putRange Content-Range 의 자리에 본문을 써 - Content-Range 가 없으면 본문이 파일 전체
+func (s *Server) putRange(w http.ResponseWriter, r *http.Request, id, name string) {
+	start, end, size := int64(0), r.ContentLength-1, r.ContentLength
+	if cr := r.Header.Get("Content-Range"); cr != "" {
+		var ok bool
+		if start, end, size, ok = parseContentRange(cr); !ok {
+			http.Error(w, msg.T("Content-Range 는 bytes 시작-끝/전체 나 bytes */전체 여야 합니다"), http.StatusBadRequest)
+			return
+		}
+	} else if r.ContentLength < 0 {
+		http.Error(w, msg.T("Content-Range 나 Content-Length 가 필요합니다"), http.StatusLengthRequired)
+		return
+	}
+	if r.ContentLength >= 0 && r.ContentLength != end-start+1 && (start >= 0 || r.ContentLength > 0) {
+		http.Error(w, msg.T("본문 길이가 Content-Range 와 다릅니다"), http.StatusBadRequest)
+		return
+	}
+
+	u := s.ranges.get(id)
+	if u != nil && u.Size != size {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", u.Size))
+		http.Error(w, msg.Sprintf("받는 중인 업로드의 전체 크기(%d 바이트)와 다릅니다 - DELETE 로 버리고 다시 보내세요", u.Size), http.StatusConflict)
+		return
+	}
+	if start < 0 { // bytes */전체 - 받은 범위만 물어봐
+		s.rangeStatus(w, u, name, size)
+		return
+	}
+	if u == nil {
+		acct := s.account(r)
+		if limit := s.live().MaxUploadSize; limit > 0 && size > limit {
+			uploadTooLarge(w, tooLarge(limit, size))
+			return
+		}
+		// 확장자 정책은 이름만 보면 되니까 받기 전에 (내용 형식은 다 모은 뒤에)
+		if err := s.live().Policy.CheckName(name); err != nil {
+			s.policyFailed(w, r, name, err)
+			return
+		}
+		if room := s.storageRoom(acct, name); room >= 0 && size > room {
+			s.storageFull(w, r, acct, name, size)
+			return
+		}
+		// reject 정책이면 받기 전에 미리 (다 모았을 때 다시 확인해)
+		if s.live().Collision == CollisionReject && s.exists(name) {
+			s.nameConflict(w, r, name, errNameTaken)
+			return
+		}
+		var err error
+		if u, err = s.ranges.create(id, name, acct.Name, size); err != nil {
+			s.logger(r).ErrorContext(r.Context(), "범위 올리기 생성 실패", "file", name, "err", err)
+			http.Error(w, msg.T("업로드 세션 생성 실패"), http.StatusInternalServerError)
+			return
+		}
+		s.logger(r).InfoContext(r.Context(), "범위 올리기 시작", "upload", id, "file", name, "size", size)
+	}
+
+	if !u.lock.TryRLock() {
+		http.Error(w, msg.T("업로드를 저장하거나 취소하는 중입니다"), http.StatusLocked)
+		return
+	}
+	ok := s.writeRange(w, r, id, u, start, end+1)
+	u.lock.RUnlock()
+	if !ok {
+		return
+	}
+
+	// 빈틈이 없어졌으면 저장 - 같은 자리를 동시에 받는 요청이 남아 있으면 그 요청이 끝나면서 저장해
+	ranges, _ := s.ranges.status(u)
+	complete := size == 0 || len(ranges) == 1 && ranges[0] == [2]int64{0, size}
+	if !complete || !u.lock.TryLock() {
+		s.rangeStatus(w, u, name, size)
+		return
+	}
+	defer u.lock.Unlock()
+	if s.ranges.get(id) != u {
+		// 다른 요청이 먼저 저장했어
+		s.rangeStatus(w, u, name, size)
+		return
+	}
+	s.finishRange(w, r, id, u)
+}
+
+// writeRange 본문을 data 의 [start, end) 에 쓰고 받은 만큼 적어 (u.lock 을 RLock 으로 잡고 불러)
+// 끊겨도 fsync 한 앞부분은 받은 범위에 들어가 - 실패하면 에러 응답까지 쓰고 false
+func (s *Server) writeRange(w http.ResponseWriter, r *http.Request, id string, u *rangeUpload, start, end int64) bool {
+	f, err := os.OpenFile(u.dataPath(), os.O_WRONLY, 0)
+	if err != nil {
+		http.Error(w, msg.T("업로드 세션을 열 수 없습니다"), http.StatusInternalServerError)
+		return false
+	}
+	s.throttleBody(r)
+	info := streamio.TransferInfo{ID: id, Src: r.RemoteAddr, Dst: f.Name(), Size: end - start}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferUpload, u.Name)}
+	written, copyErr := streamio.Copy(r.Context(), io.NewOffsetWriter(f, start), io.LimitReader(r.Body, end-start), info, opts)
+	err = f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && written > 0 {
+		err = s.ranges.add(u, start, start+written)
+	}
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "범위 저장 실패", "upload", id, "err", err)
+		http.Error(w, msg.T("업로드 세션 저장 실패"), http.StatusInternalServerError)
+		return false
+	}
+	if copyErr == nil && written < end-start {
+		copyErr = io.ErrUnexpectedEOF
+	}
+	if copyErr != nil {
+		ranges, _ := s.ranges.status(u)
+		if v := rangeHeader(ranges); v != "" {
+			w.Header().Set("Range", v)
+		}
+		if !uploadTooLarge(w, copyErr) {
+			s.logger(r).WarnContext(r.Context(), "범위 올리기 끊김", "upload", id, "start", start, "written", written, "err", copyErr)
+			http.Error(w, msg.T("본문을 끝까지 받지 못했습니다"), http.StatusBadRequest)
+		}
+		return false
+	}
+	return true
+}
+
+// finishRange 다 모은 data 를 저장하고 업로드를 지워 (u.lock 을 잡고 불러) - 201 과 저장한 이름
+func (s *Server) finishRange(w http.ResponseWriter, r *http.Request, id string, u *rangeUpload) {
+	// 여러 요청으로 나눠 받아서 받으면서 잰 해시가 없어 - 옮기기 전에 한 번 읽어서 재
+	sum, err := streamio.FileSHA256(u.dataPath())
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "범위 올리기 읽기 실패", "upload", id, "err", err)
+		http.Error(w, msg.T("파일 저장 실패"), http.StatusInternalServerError)
+		return
+	}
+	name, ok := s.storeSession(w, r, id, u.dataPath(), u.Name, u.Owner, u.Size, func() { s.ranges.remove(id) })
+	if !ok {
+		return
+	}
+	s.ranges.remove(id)
+
+	resp := struct {
+		File   string `json:"file"`
+		Size   int64  `json:"size"`
+		ETag   string `json:"etag,omitempty"`
+		SHA256 string `json:"sha256"`
+	}{File: name, Size: u.Size, SHA256: sum}
+	if info, err := s.backend.Stat(r.Context(), name); err == nil {
+		s.hashes.put(name, info.Size(), info.ModTime(), sum)
+		resp.ETag = etag(info)
+		w.Header().Set("ETag", resp.ETag)
+	}
+	w.Header().Set(checksumHeader, sum)
+	w.Header().Set("Content-Location", "/download?file="+url.QueryEscape(name))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// rangeStatus 202 와 받은 범위 (u 가 nil 이면 아직 아무것도 안 받음)
+func (s *Server) rangeStatus(w http.ResponseWriter, u *rangeUpload, name string, size int64) {
+	resp := struct {
+		File     string    `json:"file"`
+		Size     int64     `json:"size"`
+		Received int64     `json:"received"`
+		Ranges   []string  `json:"ranges"`
+		Expires  time.Time `json:"expires,omitzero"`
+	}{File: name, Size: size, Ranges: []string{}}
+	if u != nil {
+		var ranges [][2]int64
+		ranges, resp.Received = s.ranges.status(u)
+		for _, rg := range ranges {
+			resp.Ranges = append(resp.Ranges, fmt.Sprintf("%d-%d", rg[0], rg[1]-1))
+		}
+		if v := rangeHeader(ranges); v != "" {
+			w.Header().Set("Range", v)
+		}
+		s.ranges.mu.Lock()
+		resp.Expires = u.expires()
+		s.ranges.mu.Unlock()
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,442 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/scan"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 이어 올리기 (tus 방식) - 네트워크가 끊겨도 받은 데까지는 남겨 두고, 클라이언트가 거기서부터 이어 보내
+// ⭐ tus 1.0 의 core + creation, termination, expiration 만:
+//
+//	POST   /api/uploads?file=이름   Upload-Length: 전체 크기       → 201, Location: /api/uploads/<id>
+//	HEAD   /api/uploads/<id>                                       → 200, Upload-Offset: 받은 바이트
+//	PATCH  /api/uploads/<id>        Upload-Offset: 보내는 위치     → 204, Upload-Offset (본문은 application/offset+octet-stream)
+//	DELETE /api/uploads/<id>                                       → 204 (받은 것 버림)
+//
+// 받는 중인 내용은 SessionDir 의 <id>.part 에, 세션 목록은 sessions.json 저널에 남아서 서버를 재시작해도 이어 받아
+// (오프셋은 따로 적지 않고 .part 크기 - 쓰다 죽어도 디스크에 남은 만큼이 곧 받은 만큼이야).
+// 다 받으면 업로드 디렉토리로 옮겨서 /upload 로 올린 것처럼 /files/, 검색 색인에 보여.
+// 같은 이름이 있으면 Collision 정책을 따르고, 마지막 PATCH 응답의 Content-Location 이 실제로 저장한 이름이야.
+
+const (
+	tusVersion  = "1.0.0"
+	sessionTTL  = 24 * time.Hour // 마지막으로 받은 뒤 이만큼 지난 세션은 지워
+	journalName = "sessions.json"
+)
+
+// uploadSession 이어 올리기 세션 하나 (저널에 남는 값)
+type uploadSession struct {
+	Name    string    `json:"name"`            // 다 받으면 이 이름으로 (sanitizeFilename 을 거친 값)
+	Length  int64     `json:"length"`          // 전체 크기
+	Owner   string    `json:"owner,omitempty"` // 올린 계정 (저장 공간을 셀 때만)
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"` // 마지막으로 받은 시각 - 만료 기준
+
+	busy sync.Mutex // PATCH 는 세션마다 한 번에 하나만
+}
+
+func (u *uploadSession) expires() time.Time { return u.Updated.Add(sessionTTL) }
+
+// sessionStore 세션 목록과 저널
+type sessionStore struct {
+	dir string
+
+	mu       sync.Mutex
+	sessions map[string]*uploadSession
+}
+
+// openSessions dir 의 저널을 읽어서 이어 받을 세션을 되살려 (.part 가 없거나 만료된 세션은 정리)
+func openSessions(dir string) (*sessionStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	st := &sessionStore{dir: dir, sessions: map[string]*uploadSession{}}
+	data, err := os.ReadFile(filepath.Join(dir, journalName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &st.sessions); err != nil {
+			return nil, err
+		}
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for id, u := range st.sessions {
+		if _, err := os.Stat(st.partPath(id)); err != nil || time.Now().After(u.expires()) {
+			st.removeLocked(id)
+		}
+	}
+	return st, st.saveLocked()
+}
+
+func (st *sessionStore) partPath(id string) string { return filepath.Join(st.dir, id+".part") }
+
+// create 빈 .part 를 만들고 세션을 저널에 적어
+func (st *sessionStore) create(name, owner string, length int64) (string, *uploadSession, error) {
+	var b [16]byte
+	rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+	f, err := os.OpenFile(st.partPath(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", nil, err
+	}
+	f.Close()
+
+	now := time.Now()
+	u := &uploadSession{Name: name, Length: length, Owner: owner, Created: now, Updated: now}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.sessions[id] = u
+	if err := st.saveLocked(); err != nil {
+		st.removeLocked(id)
+		return "", nil, err
+	}
+	return id, u, nil
+}
+
+// get id 의 세션 (없거나 만료됐으면 nil)
+func (st *sessionStore) get(id string) *uploadSession {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	u := st.sessions[id]
+	if u != nil && time.Now().After(u.expires()) {
+		st.removeLocked(id)
+		st.saveLocked()
+		return nil
+	}
+	return u
+}
+
+// offset 지금까지 받은 바이트 (.part 크기)
+func (st *sessionStore) offset(id string) (int64, error) {
+	info, err := os.Stat(st.partPath(id))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// touch 받은 시각을 갱신해서 저널에 (만료가 밀려)
+func (st *sessionStore) touch(u *uploadSession) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	u.Updated = time.Now()
+	return st.saveLocked()
+}
+
+// remove 세션과 .part 를 지워 (다 받아서 옮긴 뒤라면 .part 는 이미 없어)
+func (st *sessionStore) remove(id string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.removeLocked(id)
+	return st.saveLocked()
+}
+
+func (st *sessionStore) removeLocked(id string) {
+	delete(st.sessions, id)
+	os.Remove(st.partPath(id))
+}
+
+// saveLocked 저널을 임시 파일 → rename 으로 (mu 를 잡고 불러)
+func (st *sessionStore) saveLocked() error {
+	data, err := json.MarshalIndent(st.sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(st.dir, journalName)
+	tmp, err := os.CreateTemp(st.dir, "."+journalName+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), path)
+}
+
+// uploadsHandler /api/uploads (만들기) 와 /api/uploads/<id> (조회, 이어 쓰기, 취소)
+func (s *Server) uploadsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Tus-Version", tusVersion)
+		w.Header().Set("Tus-Extension", "creation,termination,expiration")
+		if limit := s.live().MaxUploadSize; limit > 0 {
+			w.Header().Set("Tus-Max-Size", strconv.FormatInt(limit, 10))
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/uploads"), "/")
+	if id == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+			return
+		}
+		s.createUpload(w, r)
+		return
+	}
+	u := s.sessions.get(id)
+	if u == nil {
+		http.Error(w, msg.T("업로드 세션이 없거나 만료됐습니다"), http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodHead:
+		offset, err := s.sessions.offset(id)
+		if err != nil {
+			http.Error(w, msg.T("업로드 세션을 읽을 수 없습니다"), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		w.Header().Set("Upload-Length", strconv.FormatInt(u.Length, 10))
+		w.Header().Set("Upload-Expires", u.expires().UTC().Format(http.TimeFormat))
+		w.Header().Set("Cache-Control", "no-store")
+	case http.MethodPatch:
+		s.patchUpload(w, r, id, u)
+	case http.MethodDelete:
+		if !u.busy.TryLock() {
+			http.Error(w, msg.T("다른 요청이 이어 쓰는 중입니다"), http.StatusLocked)
+			return
+		}
+		defer u.busy.Unlock()
+		s.sessions.remove(id)
+		s.logger(r).InfoContext(r.Context(), "이어 올리기 취소", "upload", id, "file", u.Name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, msg.T("HEAD, PATCH, DELETE 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	}
+}
+
+// createUpload 세션 만들기 - 파일명은 ?file= 이나 tus 의 Upload-Metadata filename
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request) {
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, msg.T("Upload-Length 가 필요합니다"), http.StatusBadRequest)
+		return
+	}
+	if limit := s.live().MaxUploadSize; limit > 0 && length > limit {
+		uploadTooLarge(w, tooLarge(limit, length))
+		return
+	}
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		filename = metadataValue(r.Header.Get("Upload-Metadata"), "filename")
+	}
+	if filename == "" {
+		http.Error(w, msg.T("파일명이 필요합니다"), http.StatusBadRequest)
+		return
+	}
+	name, ok := sanitizeFilename(filename)
+	if !ok {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	// 확장자 정책은 이름만 보면 되니까 받기 전에 (내용 형식은 다 모은 뒤에)
+	if err := s.live().Policy.CheckName(name); err != nil {
+		s.policyFailed(w, r, name, err)
+		return
+	}
+	// 크기를 미리 아니까 저장 공간이 모자라면 한 바이트도 받기 전에 거절
+	acct := s.account(r)
+	if room := s.storageRoom(acct, name); room >= 0 && length > room {
+		s.storageFull(w, r, acct, name, length)
+		return
+	}
+	// reject 정책이면 받기 전에 미리 (다 받았을 때 다시 확인해 - 그사이 누가 같은 이름으로 올릴 수 있어)
+	if s.live().Collision == CollisionReject && s.exists(name) {
+		s.nameConflict(w, r, name, errNameTaken)
+		return
+	}
+
+	id, u, err := s.sessions.create(name, acct.Name, length)
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "업로드 세션 생성 실패", "file", name, "err", err)
+		http.Error(w, msg.T("업로드 세션 생성 실패"), http.StatusInternalServerError)
+		return
+	}
+	s.logger(r).InfoContext(r.Context(), "이어 올리기 시작", "upload", id, "file", name, "size", length)
+	// 빈 파일은 받을 게 없으니 바로 완료 (아직 아무도 id 를 몰라서 잠글 필요 없어)
+	if length == 0 {
+		if !s.finishUpload(w, r, id, u) {
+			return
+		}
+	}
+	w.Header().Set("Location", "/api/uploads/"+id)
+	w.Header().Set("Upload-Expires", u.expires().UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// patchUpload Upload-Offset 부터 본문을 .part 뒤에 이어 써 - 끊겨도 받은 데까지는 남아
+func (s *Server) patchUpload(w http.ResponseWriter, r *http.Request, id string, u *uploadSession) {
+	if ct := r.Header.Get("Content-Type"); ct != "application/offset+octet-stream" {
+		http.Error(w, msg.T("Content-Type 은 application/offset+octet-stream 이어야 합니다"), http.StatusUnsupportedMediaType)
+		return
+	}
+	if !u.busy.TryLock() {
+		http.Error(w, msg.T("다른 요청이 이어 쓰는 중입니다"), http.StatusLocked)
+		return
+	}
+	defer u.busy.Unlock()
+
+	offset, err := s.sessions.offset(id)
+	if err != nil {
+		http.Error(w, msg.T("업로드 세션을 읽을 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+	if r.Header.Get("Upload-Offset") != strconv.FormatInt(offset, 10) {
+		// 클라이언트가 아는 위치가 틀렸어 - HEAD 로 다시 물어보고 거기서부터
+		w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		http.Error(w, msg.T("Upload-Offset 이 받은 위치와 다릅니다"), http.StatusConflict)
+		return
+	}
+
+	part, err := os.OpenFile(s.sessions.partPath(id), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		http.Error(w, msg.T("업로드 세션을 열 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+	// 전체 크기를 넘는 본문은 읽는 도중에 끊어
+	s.throttleBody(r)
+	body := http.MaxBytesReader(w, r.Body, u.Length-offset)
+	info := streamio.TransferInfo{ID: id, Src: r.RemoteAddr, Dst: part.Name(), Size: u.Length - offset}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferUpload, u.Name)}
+	written, copyErr := streamio.Copy(r.Context(), part, body, info, opts)
+	// 끊겼어도 받은 만큼은 디스크에 내려서 다음 HEAD 가 그 위치를 알려주게
+	err = part.Sync()
+	if cerr := part.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = s.sessions.touch(u)
+	}
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "업로드 세션 저장 실패", "upload", id, "err", err)
+		http.Error(w, msg.T("업로드 세션 저장 실패"), http.StatusInternalServerError)
+		return
+	}
+	offset += written
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	w.Header().Set("Upload-Expires", u.expires().UTC().Format(http.TimeFormat))
+	if copyErr != nil {
+		if !uploadTooLarge(w, copyErr) {
+			s.logger(r).WarnContext(r.Context(), "이어 올리기 끊김", "upload", id, "offset", offset, "err", copyErr)
+			http.Error(w, msg.T("본문을 끝까지 받지 못했습니다"), http.StatusBadRequest)
+		}
+		return
+	}
+
+	if offset == u.Length {
+		if !s.finishUpload(w, r, id, u) {
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// finishUpload 다 받은 .part 를 업로드 디렉토리로 옮기고 세션을 지워 (PATCH 라면 u.busy 를 잡고 불러)
+// 저장한 이름은 Content-Location 으로 알려주고, 실패하면 에러 응답까지 쓰고 false
+// (reject 정책에 걸리면 409 - 세션은 남겨둬서 DELETE 로 버릴 수 있어, 검사에서 거절이면 422, 업로드 정책에 걸리면 415)
+func (s *Server) finishUpload(w http.ResponseWriter, r *http.Request, id string, u *uploadSession) bool {
+	name, ok := s.storeSession(w, r, id, s.sessions.partPath(id), u.Name, u.Owner, u.Length, func() { s.sessions.remove(id) })
+	if !ok {
+		return false
+	}
+	s.sessions.remove(id)
+	w.Header().Set("Content-Location", "/download?file="+url.QueryEscape(name))
+	return true
+}
+
+// storeSession 세션 디렉토리에 다 모은 src 를 original 이름으로 저장하고 실제로 저장한 이름을 돌려줘 (이어 올리기, 멀티파트 업로드)
+// 실패하면 에러 응답까지 쓰고 false - 정책이나 검사에서 거절이면 이어 받을 이유가 없으니 discard 로 세션도 버려
+func (s *Server) storeSession(w http.ResponseWriter, r *http.Request, id, src, original, owner string, size int64, discard func()) (string, bool) {
+	ttl, ok := s.uploadTTL(w, r)
+	if !ok {
+		return "", false
+	}
+	if !s.checkPolicyFile(w, r, src, original, size, discard) {
+		return "", false
+	}
+	// 여러 요청으로 나눠 받아서 흐름을 검사기에 넘길 수 없어 - 다 모은 파일을 읽혀
+	if err := scan.File(r.Context(), s.live().Scanner, src, original); err != nil {
+		if scan.IsRejected(err) {
+			discard()
+		}
+		s.scanFailed(w, r, original, err)
+		return "", false
+	}
+	name, unlock, err := s.claimName(original)
+	if err != nil {
+		s.nameConflict(w, r, original, err)
+		return "", false
+	}
+	defer unlock()
+	target := s.uploadPath(name)
+	// 세션 디렉토리가 다른 파일시스템이어도 되게 Move (같으면 rename), 다른 저장소면 복사 - 클라이언트가 끊어도 옮기는 건 끝까지
+	ctx := context.WithoutCancel(r.Context())
+	if err = s.keepVersion(name); err != nil {
+		s.logger(r).ErrorContext(r.Context(), "예전 버전을 남기지 못함", "upload", id, "file", name, "err", err)
+		http.Error(w, msg.T("파일 저장 실패"), http.StatusInternalServerError)
+		return "", false
+	}
+	if s.localDir() {
+		err = streamio.Move(ctx, src, target, streamio.CopyOptions{BufferSize: s.live().BufferSize, Preserve: streamio.PreserveMode})
+	} else {
+		err = s.storeBackend(ctx, src, name)
+	}
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "파일 저장 실패", "upload", id, "file", name, "err", err)
+		http.Error(w, msg.T("파일 저장 실패"), http.StatusInternalServerError)
+		return "", false
+	}
+	if s.blobs != nil {
+		// 나눠 받아서 받으면서 잰 해시가 없어 - 다 모은 파일을 한 번 읽어서 재
+		sum, err := streamio.FileSHA256(target)
+		if err == nil {
+			_, err = s.dedupe(name, target, sum)
+		}
+		if err != nil {
+			s.logger(r).ErrorContext(r.Context(), "중복 제거 저장소에 넣지 못함 (평범한 파일로 둠)", "file", name, "err", err)
+		}
+	}
+	s.recordUpload(w, r, name, original, owner, size, "", ttl)
+	s.logger(r).InfoContext(r.Context(), "파일 업로드", "upload", id, "file", name, "original", original, "bytes", size)
+	return name, true
+}
+
+// metadataValue tus Upload-Metadata ("key base64값,key2 base64값") 에서 key 의 값
+func metadataValue(header, key string) string {
+	for pair := range strings.SplitSeq(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if k != key {
+			continue
+		}
+		if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
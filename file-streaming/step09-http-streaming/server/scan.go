@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/scan"
+)
+
+// 업로드 검사 (Config.Scanner)
+// ⭐ 파일이 이름으로 보이기 전에 검사기에 넘겨 - /upload 는 받으면서 같은 흐름을 파이프로 흘리고(다시 읽지 않아),
+// 이어 올리기는 다 모은 .part 를, /api/extract, /upload-archive 는 풀기 전의 아카이브를 읽혀.
+// 거절이면 받은 것을 버리고 422 (이어 올리기는 세션까지 버려), 검사 자체가 실패하면 503 - 검사하지 못한 파일은 저장하지 않아.
+
+// scanRejection 거절했을 때 응답 본문
+type scanRejection struct {
+	Error  string `json:"error"`
+	File   string `json:"file"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// scanFailed 검사 결과 err 로 응답 (거절이면 422 JSON, 아니면 503)
+func (s *Server) scanFailed(w http.ResponseWriter, r *http.Request, name string, err error) {
+	var rej *scan.Rejected
+	if !errors.As(err, &rej) {
+		s.logger(r).ErrorContext(r.Context(), "업로드 검사 실패", "file", name, "err", err)
+		http.Error(w, msg.T("업로드 검사 실패 - 잠시 뒤 다시 시도하세요"), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(scanRejection{Error: msg.T("업로드가 검사에서 거절되었습니다"), File: name, Reason: rej.Reason})
+	s.logger(r).WarnContext(r.Context(), "업로드 검사에서 거절", "file", name, "reason", rej.Reason)
+}
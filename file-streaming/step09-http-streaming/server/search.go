@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/search"
+)
+
+// 업로드 파일 전문 검색
+// ⭐ 색인은 업로드 요청 안에서 하지 않고 큐에 넣어서 백그라운드 고루틴 하나가 처리해 -
+// 큰 로그를 올려도 업로드 응답이 색인 때문에 늦어지지 않고, 색인 파일 쓰기도 한 곳에서만 일어나.
+
+// indexJob 색인 큐 항목
+type indexJob struct {
+	path   string
+	remove bool
+}
+
+// startIndexer 색인을 열고, 시작할 때 업로드 디렉토리를 한 번 훑어서 그동안 바뀐 파일을 맞춰
+func (s *Server) startIndexer() error {
+	ix, err := search.Open(s.cfg.SearchIndex)
+	if err != nil {
+		return err
+	}
+	s.index = ix
+	s.indexQueue = make(chan indexJob, 256)
+
+	go func() {
+		stats, err := ix.Update(context.Background(), s.cfg.UploadDir, fstree.WalkOptions{})
+		if err != nil {
+			s.cfg.Logger.Error("색인 갱신 실패", "err", err)
+			return
+		}
+		if err := ix.Save(); err != nil {
+			s.cfg.Logger.Error("색인 저장 실패", "err", err)
+		}
+		s.cfg.Logger.Info("색인 갱신", "added", stats.Added, "fresh", stats.Fresh, "skipped", stats.Skipped, "removed", stats.Removed)
+		s.indexLoop()
+	}()
+	return nil
+}
+
+// indexLoop 큐가 빌 때마다 저장 (연달아 올라오면 모아서 한 번)
+func (s *Server) indexLoop() {
+	for job := range s.indexQueue {
+		if job.remove {
+			s.index.Remove(job.path)
+		} else if err := s.index.Add(context.Background(), job.path); err != nil && !errors.Is(err, search.ErrBinary) {
+			s.cfg.Logger.Warn("색인 실패", "file", job.path, "err", err)
+		}
+		if len(s.indexQueue) == 0 {
+			if err := s.index.Save(); err != nil {
+				s.cfg.Logger.Error("색인 저장 실패", "err", err)
+			}
+		}
+	}
+}
+
+// queueIndex 색인 큐에 넣기 (검색이 꺼져 있으면 무시)
+func (s *Server) queueIndex(path string, remove bool) {
+	if s.indexQueue != nil {
+		s.indexQueue <- indexJob{path: path, remove: remove}
+	}
+}
+
+// searchResult /api/search 응답의 파일 하나 - 서버의 실제 경로 대신 업로드 이름만 보여줘
+type searchResult struct {
+	Name     string           `json:"name"`
+	URL      string           `json:"url"`
+	Size     int64            `json:"size"`
+	ModTime  time.Time        `json:"mtime"`
+	Score    float64          `json:"score"`
+	Snippets []search.Snippet `json:"snippets,omitempty"`
+}
+
+// 검색 핸들러 - GET /api/search?q=검색어&limit=20
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	if s.index == nil {
+		http.Error(w, msg.T("검색이 꺼져 있습니다"), http.StatusNotFound)
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, msg.T("검색어(q)가 필요합니다"), http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	results, err := s.index.Search(r.Context(), q, search.SearchOptions{Limit: limit})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	root, _ := filepath.Abs(s.cfg.UploadDir)
+	out := []searchResult{}
+	for _, res := range results {
+		rel, err := filepath.Rel(root, res.Path)
+		if err != nil || !filepath.IsLocal(rel) {
+			continue
+		}
+		name := filepath.ToSlash(rel)
+		out = append(out, searchResult{
+			Name:     name,
+			URL:      "/download?file=" + url.QueryEscape(name),
+			Size:     res.Size,
+			ModTime:  res.ModTime,
+			Score:    res.Score,
+			Snippets: res.Snippets,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]any{"query": q, "count": len(out), "results": out})
+}
@@ -0,0 +1,1153 @@
+// Package server 는 step09 HTTP 스트리밍 서버 본체야.
+// step09 main 뿐 아니라 streamctl serve 같은 다른 도구도 같은 서버를 띄울 수 있게 패키지로 분리했어.
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hellotect2022go/study-go/file-streaming/cas"
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/extract"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/hls"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/policy"
+	"github.com/hellotect2022go/study-go/file-streaming/scan"
+	"github.com/hellotect2022go/study-go/file-streaming/search"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// Config 서버 설정
+type Config struct {
+	Addr      string // 기본 ":8080"
+	UploadDir string // 업로드/다운로드 디렉토리 (기본 "./uploads")
+	TrashDir  string // 삭제 API 가 파일을 옮겨두는 휴지통 (기본 "./.trash", uploads 밖에 둬서 /files/ 로 노출되지 않게)
+	// SessionDir 이어 올리기(/api/uploads) 중인 .part 와 세션 저널, 멀티파트 업로드(/api/multipart)의 파트, 범위 올리기(/upload/<이름>)의 희소 파일, 업로드 만료 기록 (기본 "./.upload-sessions", 휴지통처럼 uploads 밖에)
+	SessionDir string
+	IndexFile  string // "/" 에서 내장 UI 대신 보여줄 페이지 (비우면 내장 UI)
+	VersionDir string // Versions 를 켰을 때 덮어쓴 파일의 예전 내용을 두는 곳 (기본 "./.versions", 휴지통처럼 uploads 밖에)
+
+	// TLS HTTPS 로 서빙 - CertFile/KeyFile 을 주면 그 인증서, 비우면 자체 서명 인증서를 만들어 (CertFile 만 줘도 HTTPS)
+	TLS      bool
+	CertFile string
+	KeyFile  string
+
+	// Backend 업로드 파일을 두는 저장소 (nil 이면 BackendURL, 그것도 비우면 UploadDir 을 루트로 한 storage.Dir)
+	// storage.Dir 이 아니면 DedupDir, SearchIndex, /api/extract, 휴지통은 못 써 (backend.go)
+	Backend storage.Storage
+	// BackendURL Backend 를 주소로 - BackendMemory("memory") 또는 "s3://bucket/prefix" (설정 파일의 server.backend)
+	BackendURL string
+	S3Endpoint string // s3:// 저장소의 엔드포인트 (MinIO 같은 자체 호스팅, 비우면 AWS)
+	// EncryptionKeyFile 업로드를 AES-256-GCM 으로 암호화해서 저장할 키 파일 (storage.ParseKeys 형식, 첫 줄로 암호화) - 비우면 그대로
+	EncryptionKeyFile string
+
+	// Targets /api/copy 로 업로드 저장소와 파일을 주고받을 다른 저장소 (이름 → 저장소, TargetUploads 는 업로드 저장소라 이름으로 못 써) - copy.go
+	Targets map[string]storage.Storage
+	// TargetURLs Targets 를 주소로 - BackendMemory, "s3://bucket/prefix", 그 밖은 로컬 디렉토리 (설정 파일의 server.copy_targets)
+	TargetURLs map[string]string
+
+	// DedupDir 업로드를 sha256 으로 한 벌만 두는 저장소 (비우면 안 써) - UploadDir 과 같은 파일시스템이어야 해 (하드 링크)
+	DedupDir string
+
+	// SearchIndex 업로드 파일 전문 검색 색인 파일 (비우면 검색 끔, /api/search 는 404)
+	SearchIndex string
+
+	// Thumbnails 이미지(JPEG, PNG, GIF) 업로드마다 small/medium 썸네일을 만들어 /thumb 로 (끄면 /thumb 는 404) - thumb.go
+	Thumbnails   bool
+	ThumbWorkers int // 썸네일을 동시에 만들 고루틴 수 (0 이면 2)
+
+	// HLS MPEG-TS 동영상을 /hls?file= 로 HLS 재생 목록으로 (끄면 /hls 는 404) - hls.go
+	HLS        bool
+	HLSSegment time.Duration // 조각 길이 목표 (0 이면 hls.DefaultSegment)
+
+	// WebDAV /dav/ 로 업로드 디렉토리를 WebDAV 드라이브로 - WebDAVOff(기본, 비워도 끔), WebDAVReadOnly, WebDAVReadWrite (로컬 디렉토리만) - dav.go
+	WebDAV string
+
+	// SFTPAddr 같은 저장소를 SFTP 로도 열 주소 (예: ":2022", 비우면 안 열어) - 로그인은 SFTPAuthorizedKeys 의 공개키로만 - sftp.go
+	SFTPAddr           string
+	SFTPHostKey        string // 호스트 개인키 파일 (기본 "./.sftp_host_key", 없으면 ed25519 로 만들어 저장)
+	SFTPAuthorizedKeys string // 로그인할 수 있는 공개키 (authorized_keys 형식, 인증을 켰으면 주석이 계정 이름)
+
+	// TCPAddr transfer 패키지의 프레임 프로토콜(streamctl send)로도 받을 주소 (예: ":9000", 비우면 안 열어) - 인증을 켜면 거절해 - tcp.go
+	TCPAddr string
+
+	MaxUploadSize int64 // 업로드 요청 본문 최대 크기 (0 이면 제한 없음, 넘으면 413)
+	UploadWorkers int   // /upload 의 "files" 파트를 동시에 저장할 수 (0 이면 4) - multifile.go
+	BufferSize    int   // 업로드/다운로드 복사 버퍼 (0 이면 streamio 기본값)
+
+	// Gzip /download 응답을 gzip 으로 압축 (Accept-Encoding: gzip 이고 텍스트처럼 잘 줄어드는 형식일 때만)
+	Gzip      bool
+	GzipLevel int      // gzip 레벨 (-2 ~ 9, 0 이면 gzip 기본값)
+	GzipSkip  []string // 압축하지 않을 확장자 (".zip" 같은 이미 압축된 형식은 안 적어도 알아서 건너뛰어)
+
+	// DownloadRate /download, /range-download 한 건의 초당 최대 바이트 (0 이면 제한 없음) - ?limit= 으로 더 낮출 수만 있어
+	DownloadRate int64
+	// UploadRate /upload, /upload/<이름>, /api/uploads, /api/multipart, /api/extract, /upload-archive 한 건이 요청 본문을 읽는 초당 최대 바이트 (0 이면 제한 없음)
+	UploadRate int64
+	// MaxDownloads 동시에 보낼 큰 다운로드 수 (0 이면 제한 없음) - 넘치면 DownloadQueue 동안 기다렸다가 503 + Retry-After, limit.go
+	MaxDownloads  int
+	DownloadQueue time.Duration // 자리가 날 때까지 기다릴 최대 시간 (0 이면 바로 503)
+	LargeDownload int64         // MaxDownloads 로 셀 다운로드의 최소 크기 (0 이면 전부)
+	// MaxUploadsPerIP 클라이언트 주소 하나가 동시에 올릴 수 있는 업로드 수 (0 이면 제한 없음) - 넘으면 바로 429 + Retry-After, uploadlimit.go
+	MaxUploadsPerIP int
+	// MinFreeSpace 업로드 디렉토리에 이보다 적게 남으면 /healthz, /readyz 가 503 (0 이면 남은 공간을 알려주기만) - health.go
+	MinFreeSpace int64
+
+	// Collision 올린 이름의 파일이 이미 있을 때 - CollisionOverwrite(기본), CollisionReject(409), CollisionUUID, CollisionVersion
+	Collision string
+	// Versions 업로드가 같은 이름을 덮어쓸 때 예전 내용을 VersionDir 에 남길 개수 (0 이면 안 남겨, 로컬 디렉토리만) - versions.go
+	Versions int
+	// ExpireAfter X-Expire-After 없이 올린 파일이 지워지기까지의 시간 (0 이면 안 지워) - 헤더는 이보다 길게 못 잡아, expire.go
+	ExpireAfter time.Duration
+	ExpireScan  time.Duration // 만료된 파일을 찾는 주기 (0 이면 1분)
+	// TrashRetention 지운 파일을 휴지통(TrashDir)에 남겨 두는 기간 - 지나면 ExpireScan 마다 영구 삭제 (0 이면 안 지워), trash.go
+	TrashRetention time.Duration
+
+	// Extract /api/extract, /upload-archive 가 아카이브를 풀 때 한도 (0 이면 extract 기본값, 넘으면 413)
+	Extract extract.Options
+
+	// UsageFile 계정별 전송량 기록 파일 (비우면 세지도 막지도 않아, /api/usage 는 404)
+	UsageFile  string
+	UsageFlush time.Duration // 기록 파일에 쓰는 주기 (0 이면 usage 기본값)
+	// APIKeys 키 → 계정 (비우면 키 없이 모두 "anonymous" 계정에 MonthlyQuota), 있으면 전송 요청마다 키가 필요해 (없으면 401)
+	APIKeys      map[string]APIKey
+	MonthlyQuota int64 // APIKeys 가 비었을 때 한 달 전송 한도 (0 이면 제한 없음, 다 쓰면 429)
+	// StorageFile 파일마다 올린 계정을 적는 기록 (비우면 저장 공간을 세지도 막지도 않아)
+	StorageFile  string
+	StorageQuota int64 // APIKeys 가 비었을 때 올려 둘 수 있는 총 크기 (0 이면 제한 없음, 넘으면 413)
+
+	// JWTSecret HMAC 서명 JWT 를 확인할 비밀 키 (비우면 JWT 를 안 받아) - sub 가 계정, 한도는 MonthlyQuota/StorageQuota
+	JWTSecret   string
+	JWTIssuer   string // 비우지 않으면 iss 가 같아야 해
+	JWTAudience string // 비우지 않으면 aud 에 있어야 해
+	// SignSecret /api/sign 이 임시 다운로드 URL 에 서명할 비밀 키 (비우면 /api/sign 은 404, ?sig= 도 안 봐)
+	SignSecret string
+	SignMaxTTL time.Duration // 서명 URL 의 최대 유효 시간 (0 이면 24시간)
+	// Validators APIKeys, JWT 다음에 물어볼 검증기 (하나라도 있거나 APIKeys/JWTSecret 을 주면 인증이 켜져)
+	Validators []Validator
+	// PublicFiles 인증이 켜져 있어도 /files/ 는 자격 증명 없이 열어 둬 (보내면 똑같이 확인하고 그 계정으로 세)
+	PublicFiles bool
+
+	// CORSOrigins 다른 출처의 브라우저 앱이 API 를 부를 수 있게 허락할 출처 (비우면 CORS 헤더를 안 붙여, "*" 면 어디서든) - cors.go
+	CORSOrigins []string
+	CORSMethods []string      // 허락할 메서드 (비우면 GET, HEAD, POST, PUT, PATCH, DELETE)
+	CORSHeaders []string      // 보내도 되는 요청 헤더 (비우면 브라우저가 preflight 로 물어본 그대로)
+	CORSExpose  []string      // 브라우저 JS 가 읽을 수 있는 응답 헤더 (비우면 Content-Range, X-Content-SHA256 같은 기본 목록)
+	CORSMaxAge  time.Duration // 브라우저가 preflight 결과를 기억할 시간 (0 이면 안 알려 - 브라우저 기본값)
+
+	// Scanner 업로드를 저장하기 전에 내용을 검사 (nil 이면 scan.Nop - 검사 없음, 거절이면 422)
+	Scanner scan.Scanner
+	// Policy 받을 업로드의 확장자와 내용 형식 (빈 값이면 다 받아, 걸리면 415) - policy.go
+	Policy policy.Rules
+
+	// UploadWebhooks 업로드가 저장될 때마다 이름, 크기, sha256, 올린 계정을 JSON 으로 POST 할 URL 들 (비우면 안 보내) - webhook.go
+	UploadWebhooks []string
+	WebhookRetries int // 보내기에 실패하면 다시 보낼 횟수 (0 이면 notify.DefaultRetries, 음수면 한 번만)
+
+	// Hooks 전송 훅 - 메트릭/알림/감사 로그는 여기에 streamio.Hooks 구현을 붙이면 돼
+	// 예) streamio.MultiHooks{streamio.LogHooks{}, myMetricsHooks}
+	Hooks streamio.Hooks
+
+	// Logger 서버 로그 (기본 component=server) - 요청마다 request_id 가 붙은 로거가 여기서 갈라져
+	Logger *slog.Logger
+
+	// AccessLog 요청마다 한 줄씩 남길 접근 로그 파일 (비우면 안 남겨) - AccessLogMaxSize 를 넘으면 .1, .2 … 로 돌려
+	AccessLog        string
+	AccessLogFormat  string // AccessJSON(기본) 또는 AccessCombined
+	AccessLogMaxSize int64  // 돌릴 크기 (0 이면 logging.DefaultMaxSize)
+	AccessLogBackups int    // 남길 예전 파일 수 (0 이면 돌릴 때 버려, 음수면 logging.DefaultBackups)
+
+	// AuditLog 지우기, 이름 바꾸기를 한 줄씩 덧붙일 감사 로그 파일 (비우면 안 남겨, 돌리지 않아) - manage.go
+	AuditLog string
+}
+
+func (c *Config) setDefaults() {
+	if c.Addr == "" {
+		c.Addr = ":8080"
+	}
+	if c.UploadDir == "" {
+		c.UploadDir = "./uploads"
+	}
+	if c.TrashDir == "" {
+		c.TrashDir = "./.trash"
+	}
+	if c.SessionDir == "" {
+		c.SessionDir = "./.upload-sessions"
+	}
+	if c.VersionDir == "" {
+		c.VersionDir = "./.versions"
+	}
+	if c.SFTPHostKey == "" {
+		c.SFTPHostKey = defaultSFTPHostKey
+	}
+	if c.Hooks == nil {
+		c.Hooks = streamio.NopHooks{}
+	}
+	if c.Scanner == nil {
+		c.Scanner = scan.Nop{}
+	}
+	if c.Logger == nil {
+		c.Logger = logging.For("server")
+	}
+	if c.AccessLogFormat == "" {
+		c.AccessLogFormat = AccessJSON
+	}
+}
+
+// FromConfig 공유 설정에서 서버 설정으로 (streamctl serve 와 step09 main 이 같은 설정 파일을 읽게)
+func FromConfig(c config.Config, hooks streamio.Hooks) Config {
+	return Config{
+		Addr:              c.Server.Addr,
+		UploadDir:         c.Server.UploadDir,
+		TrashDir:          c.Server.TrashDir,
+		SessionDir:        c.Server.SessionDir,
+		TLS:               c.Server.TLS,
+		CertFile:          c.Server.Cert,
+		KeyFile:           c.Server.Key,
+		IndexFile:         c.Server.IndexFile,
+		SearchIndex:       c.Search.Index,
+		Thumbnails:        c.Server.Thumbnails,
+		ThumbWorkers:      c.Server.ThumbWorkers,
+		HLS:               c.Server.HLS,
+		HLSSegment:        c.Server.HLSSegment,
+		WebDAV:            c.Server.WebDAV,
+		DedupDir:          c.Server.DedupDir,
+		BackendURL:        c.Server.Backend,
+		S3Endpoint:        c.Server.S3Endpoint,
+		TargetURLs:        c.Server.CopyTargets,
+		EncryptionKeyFile: c.Server.EncryptionKeys,
+		MaxUploadSize:     int64(c.Server.MaxUpload),
+		UploadWorkers:     c.Server.UploadWorkers,
+		DownloadRate:      int64(c.Server.DownloadRate),
+		UploadRate:        int64(c.Server.UploadRate),
+		MaxDownloads:      c.Server.MaxDownloads,
+		DownloadQueue:     c.Server.DownloadQueue,
+		LargeDownload:     int64(c.Server.LargeDownload),
+		MaxUploadsPerIP:   c.Server.MaxUploadsPerIP,
+		MinFreeSpace:      int64(c.Server.MinFree),
+		UploadWebhooks:    splitList(c.Server.UploadWebhooks),
+		WebhookRetries:    cmp.Or(c.Server.WebhookRetries, -1), // 설정 파일의 0 은 다시 보내지 않기
+		Gzip:              c.Server.Gzip,
+		GzipLevel:         c.Compress.Level,
+		GzipSkip:          strings.Split(c.Server.GzipSkip, ","),
+		Collision:         c.Server.Collision,
+		Versions:          c.Server.Versions,
+		ExpireAfter:       c.Server.ExpireAfter,
+		ExpireScan:        c.Server.ExpireScan,
+		TrashRetention:    c.Server.TrashRetention,
+		VersionDir:        c.Server.VersionDir,
+		Extract:           c.Extract.Options(),
+		Scanner:           c.Scan.Scanner(),
+		Policy:            c.Policy.Rules(),
+		BufferSize:        c.Transfer.Buffer.Int(),
+		UsageFile:         c.Usage.File,
+		UsageFlush:        c.Usage.Flush,
+		APIKeys:           apiKeys(c.Usage),
+		MonthlyQuota:      int64(c.Usage.Monthly),
+		StorageFile:       c.Usage.StorageFile,
+		StorageQuota:      int64(c.Usage.Storage),
+		JWTSecret:         c.Auth.JWTSecret,
+		JWTIssuer:         c.Auth.JWTIssuer,
+		JWTAudience:       c.Auth.JWTAudience,
+		SignSecret:        c.Auth.SignSecret,
+		SignMaxTTL:        c.Auth.SignMaxTTL,
+		PublicFiles:       c.Auth.PublicFiles,
+		Hooks:             hooks,
+
+		AccessLog:        c.Server.AccessLog,
+		AccessLogFormat:  c.Server.AccessLogFormat,
+		AccessLogMaxSize: int64(c.Server.AccessLogMaxSize),
+		AccessLogBackups: c.Server.AccessLogBackups,
+		AuditLog:         c.Server.AuditLog,
+
+		SFTPAddr:           c.Server.SFTPAddr,
+		SFTPHostKey:        c.Server.SFTPHostKey,
+		SFTPAuthorizedKeys: c.Server.SFTPAuthorizedKeys,
+		TCPAddr:            c.Server.TCPAddr,
+
+		CORSOrigins: splitList(c.CORS.Origins),
+		CORSMethods: splitList(c.CORS.Methods),
+		CORSHeaders: splitList(c.CORS.Headers),
+		CORSExpose:  splitList(c.CORS.Expose),
+		CORSMaxAge:  c.CORS.MaxAge,
+	}
+}
+
+// apiKeys 설정의 계정 이름 → 키 를 서버가 찾는 방향(키 → 계정)으로
+func apiKeys(u config.Usage) map[string]APIKey {
+	if len(u.Keys) == 0 {
+		return nil
+	}
+	keys := make(map[string]APIKey, len(u.Keys))
+	for name, k := range u.Keys {
+		monthly := u.Monthly
+		if k.Monthly != nil {
+			monthly = *k.Monthly
+		}
+		storage := u.Storage
+		if k.Storage != nil {
+			storage = *k.Storage
+		}
+		keys[k.Key] = APIKey{Name: name, Monthly: int64(monthly), Storage: int64(storage), Scopes: k.Scopes}
+	}
+	return keys
+}
+
+// Server 파일 업로드/다운로드 서버
+type Server struct {
+	cfg     Config
+	backend storage.Storage            // 업로드 파일 (Config.Backend)
+	targets map[string]storage.Storage // /api/copy 의 다른 저장소 (Config.Targets, TargetURLs)
+	trash   *fstree.Trash
+	mux     *http.ServeMux
+	metrics *serverMetrics // /metrics (metrics.go)
+
+	// tunables Reload 로 바꿀 수 있는 설정 - 요청마다 지금 값을 읽어 (cfg 의 같은 필드는 처음 값 그대로)
+	tunables atomic.Pointer[tunables]
+
+	index        *search.Index // SearchIndex 를 안 주면 nil
+	indexQueue   chan indexJob
+	thumbQueue   chan string // Thumbnails 를 안 켰으면 nil
+	thumbMu      sync.Mutex
+	thumbPending map[string]bool // 큐에서 기다리는 이름 (같은 이름은 한 번만)
+
+	events    *eventHub       // 업로드 진행 이벤트 (/api/events)
+	sessions  *sessionStore   // 이어 올리기 세션 (/api/uploads)
+	multipart *multipartStore // 멀티파트 업로드 (/api/multipart)
+	ranges    *rangeStore     // 범위 올리기 (/upload/<이름>, rangeupload.go)
+	expiry    *expiryStore    // 업로드 만료 시각 (expire.go)
+	hashes    hashCache       // /api/files 의 sha256
+	playlists playlistCache   // /hls 재생 목록 (hls.go)
+	downloads downloadSlots   // MaxDownloads 자리 (limit.go)
+	uploads   ipUploads       // MaxUploadsPerIP 자리 (uploadlimit.go)
+
+	transfers *transferRegistry // 진행 중인 전송 (/stats, transfers.go)
+	webhooks  sync.WaitGroup    // 보내는 중인 업로드 웹훅 (webhook.go)
+
+	tls  *tls.Config       // HTTPS 가 아니면 nil
+	sftp *ssh.ServerConfig // SFTPAddr 를 안 주면 nil
+
+	blobs   *cas.Store     // DedupDir 을 안 주면 nil
+	usage   *usage.Meter   // UsageFile 을 안 주면 nil
+	storage *usage.Storage // StorageFile 을 안 주면 nil
+
+	access   *logging.RotatingFile // AccessLog 를 안 주면 nil
+	auditLog *auditLog             // AuditLog 를 안 주면 nil
+}
+
+// tunables 재시작 없이 바꿀 수 있는 설정
+type tunables struct {
+	IndexFile     string
+	MaxUploadSize int64
+	UploadWorkers int
+	BufferSize    int
+	Extract       extract.Options
+	Gzip          bool
+	GzipLevel     int
+	GzipSkip      []string
+	APIKeys       map[string]APIKey
+	MonthlyQuota  int64
+	StorageQuota  int64
+	Collision     string
+	Versions      int
+	ExpireAfter   time.Duration
+	ExpireScan    time.Duration
+	WebDAV        string
+	DownloadRate  int64
+	UploadRate    int64
+	MaxDownloads  int
+	DownloadQueue time.Duration
+	LargeDownload int64
+	HLS           bool
+	HLSSegment    time.Duration
+	Scanner       scan.Scanner
+	Policy        policy.Rules
+	Validators    []Validator // Config.validators() - 비었으면 인증 없음
+	PublicFiles   bool
+	CORS          *corsPolicy // CORSOrigins 를 안 줬으면 nil
+	SignSecret    string
+	SignMaxTTL    time.Duration
+
+	MaxUploadsPerIP int
+	UploadWebhooks  []string
+	WebhookRetries  int
+	MinFreeSpace    int64
+	TrashRetention  time.Duration
+}
+
+func (c Config) tunables() *tunables {
+	t := &tunables{
+		IndexFile: c.IndexFile, MaxUploadSize: c.MaxUploadSize, UploadWorkers: c.UploadWorkers, BufferSize: c.BufferSize, Extract: c.Extract,
+		Gzip: c.Gzip, GzipLevel: c.GzipLevel, GzipSkip: normalizeExts(c.GzipSkip),
+		APIKeys: c.APIKeys, MonthlyQuota: c.MonthlyQuota, StorageQuota: c.StorageQuota,
+		Collision: c.Collision, Versions: c.Versions, ExpireAfter: c.ExpireAfter, ExpireScan: c.ExpireScan, WebDAV: c.WebDAV, DownloadRate: c.DownloadRate, UploadRate: c.UploadRate,
+		MaxDownloads: c.MaxDownloads, DownloadQueue: c.DownloadQueue, LargeDownload: c.LargeDownload, MaxUploadsPerIP: c.MaxUploadsPerIP, UploadWebhooks: c.UploadWebhooks, WebhookRetries: c.WebhookRetries, MinFreeSpace: c.MinFreeSpace, TrashRetention: c.TrashRetention, Validators: c.validators(), PublicFiles: c.PublicFiles,
+		HLS: c.HLS, HLSSegment: c.HLSSegment, Scanner: c.Scanner, Policy: c.Policy, SignSecret: c.SignSecret, SignMaxTTL: c.SignMaxTTL, CORS: c.corsPolicy(),
+	}
+	if t.Collision == "" {
+		t.Collision = CollisionOverwrite
+	}
+	if t.SignMaxTTL == 0 {
+		t.SignMaxTTL = defaultSignMax
+	}
+	if t.GzipLevel == 0 {
+		t.GzipLevel = gzip.DefaultCompression
+	}
+	if t.UploadWorkers <= 0 {
+		t.UploadWorkers = defaultUploadWorkers
+	}
+	if t.HLSSegment <= 0 {
+		t.HLSSegment = hls.DefaultSegment
+	}
+	if t.ExpireScan <= 0 {
+		t.ExpireScan = defaultExpireScan
+	}
+	return t
+}
+
+func (s *Server) live() *tunables { return s.tunables.Load() }
+
+// Reload SIGHUP 같은 때 새 설정을 적용 - 업로드 한도, 버퍼, 압축 풀기 한도, 다운로드 gzip/속도 제한, 주소별 동시 업로드 수, "/" 페이지, 업로드 검사기, WebDAV 모드, HLS, 남길 버전 수, 업로드 만료, 휴지통 보관 기간, 업로드 웹훅, 상태 확인의 최소 남은 공간, API 키와 전송/저장 공간 한도는 다음 요청부터 바로 바뀌고,
+// 리슨 주소, SFTP 주소와 키 파일, TCP 주소, 디렉토리, 저장소, 복사 대상 저장소, 중복 제거 저장소, 검색 색인, 썸네일, 전송량/저장 공간 기록 파일, 접근 로그, TLS 인증서처럼 재시작해야 바뀌는 설정이 달라졌으면 그 필드 이름을 돌려줘 (그 값들과 훅, 로거는 처음 것 그대로)
+func (s *Server) Reload(cfg Config) (needRestart []string) {
+	cfg.setDefaults()
+	s.tunables.Store(cfg.tunables())
+	for _, f := range []struct {
+		name     string
+		old, new string
+	}{
+		{"Addr", s.cfg.Addr, cfg.Addr},
+		{"SFTPAddr", s.cfg.SFTPAddr, cfg.SFTPAddr},
+		{"SFTPHostKey", s.cfg.SFTPHostKey, cfg.SFTPHostKey},
+		{"SFTPAuthorizedKeys", s.cfg.SFTPAuthorizedKeys, cfg.SFTPAuthorizedKeys},
+		{"TCPAddr", s.cfg.TCPAddr, cfg.TCPAddr},
+		{"UploadDir", s.cfg.UploadDir, cfg.UploadDir},
+		{"TrashDir", s.cfg.TrashDir, cfg.TrashDir},
+		{"SessionDir", s.cfg.SessionDir, cfg.SessionDir},
+		{"VersionDir", s.cfg.VersionDir, cfg.VersionDir},
+		{"SearchIndex", s.cfg.SearchIndex, cfg.SearchIndex},
+		{"Thumbnails", strconv.FormatBool(s.cfg.Thumbnails), strconv.FormatBool(cfg.Thumbnails)},
+		{"ThumbWorkers", strconv.Itoa(s.cfg.ThumbWorkers), strconv.Itoa(cfg.ThumbWorkers)},
+		{"DedupDir", s.cfg.DedupDir, cfg.DedupDir},
+		{"BackendURL", s.cfg.BackendURL, cfg.BackendURL},
+		{"S3Endpoint", s.cfg.S3Endpoint, cfg.S3Endpoint},
+		{"EncryptionKeyFile", s.cfg.EncryptionKeyFile, cfg.EncryptionKeyFile},
+		{"TargetURLs", fmt.Sprint(s.cfg.TargetURLs), fmt.Sprint(cfg.TargetURLs)},
+		{"UsageFile", s.cfg.UsageFile, cfg.UsageFile},
+		{"StorageFile", s.cfg.StorageFile, cfg.StorageFile},
+		{"AccessLog", s.cfg.AccessLog, cfg.AccessLog},
+		{"AccessLogFormat", s.cfg.AccessLogFormat, cfg.AccessLogFormat},
+		{"AuditLog", s.cfg.AuditLog, cfg.AuditLog},
+		{"TLS", strconv.FormatBool(s.cfg.TLS), strconv.FormatBool(cfg.TLS)},
+		{"CertFile", s.cfg.CertFile, cfg.CertFile},
+		{"KeyFile", s.cfg.KeyFile, cfg.KeyFile},
+	} {
+		if f.old != f.new {
+			needRestart = append(needRestart, f.name)
+		}
+	}
+	return needRestart
+}
+
+// copyOptions 업로드/다운로드 복사 옵션
+func (s *Server) copyOptions() streamio.CopyOptions {
+	return streamio.CopyOptions{BufferSize: s.live().BufferSize, Hooks: s.cfg.Hooks}
+}
+
+// New 디렉토리를 준비하고 핸들러를 등록한 서버 생성
+func New(cfg Config) (*Server, error) {
+	cfg.setDefaults()
+	backend, err := cfg.openBackend()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkBackend(backend); err != nil {
+		return nil, err
+	}
+	targets, err := cfg.openTargets()
+	if err != nil {
+		return nil, err
+	}
+	inner := backend
+	if enc, ok := backend.(*storage.Encrypted); ok {
+		inner = enc.Unwrap()
+	}
+	if dir, ok := inner.(storage.Dir); ok {
+		// uploads 디렉토리 생성 (storage.Dir 을 직접 줬으면 그 디렉토리가 UploadDir, 암호화해도 파일은 여기에)
+		cfg.UploadDir = string(dir)
+		if err := os.MkdirAll(cfg.UploadDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	trash, err := fstree.OpenTrash(cfg.TrashDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 재시작 전에 받다 만 이어 올리기 세션, 멀티파트 업로드, 범위 올리기도 여기서 되살아나
+	sessions, err := openSessions(cfg.SessionDir)
+	if err != nil {
+		return nil, err
+	}
+	multipart, err := openMultipart(cfg.SessionDir)
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := openRanges(cfg.SessionDir)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := openExpiry(cfg.SessionDir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{cfg: cfg, backend: backend, targets: targets, trash: trash, mux: http.NewServeMux(), metrics: newServerMetrics(), events: newEventHub(), sessions: sessions, multipart: multipart, ranges: ranges, expiry: expiry, transfers: newTransferRegistry()}
+	s.tunables.Store(cfg.tunables())
+	if s.tls, err = s.loadTLS(); err != nil {
+		return nil, err
+	}
+	if cfg.SFTPAddr != "" {
+		if s.sftp, err = s.sshServerConfig(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.DedupDir != "" {
+		if s.blobs, err = cas.Open(cfg.DedupDir, cfg.UploadDir); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.SearchIndex != "" && !s.localDir() {
+		cfg.Logger.Warn("로컬 디렉토리 저장소가 아니라 검색 색인을 끕니다", "backend", fmt.Sprintf("%T", backend))
+	} else if cfg.SearchIndex != "" {
+		if err := s.startIndexer(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Thumbnails && !s.localDir() {
+		cfg.Logger.Warn("로컬 디렉토리 저장소가 아니라 썸네일을 끕니다", "backend", fmt.Sprintf("%T", backend))
+	} else if cfg.Thumbnails {
+		s.startThumbnailer()
+	}
+	if cfg.WebDAV != "" && cfg.WebDAV != WebDAVOff && !s.localDir() {
+		cfg.Logger.Warn("로컬 디렉토리 저장소가 아니라 WebDAV 를 끕니다", "backend", fmt.Sprintf("%T", backend))
+	}
+	if cfg.UsageFile != "" {
+		s.usage, err = usage.Open(cfg.UsageFile, usage.Options{Flush: cfg.UsageFlush, Logger: cfg.Logger})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.StorageFile != "" {
+		if s.storage, err = s.openStorage(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.AccessLog != "" {
+		if err := s.openAccessLog(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.AuditLog != "" {
+		if s.auditLog, err = openAuditLog(cfg.AuditLog); err != nil {
+			return nil, err
+		}
+	}
+
+	// 루트 경로("/")는 내장 웹 UI (IndexFile 을 주면 그 파일)
+	s.mux.Handle("/", s.uiHandler())
+
+	// 핸들러 등록 - 라우트마다 chain 으로 미들웨어를 쌓아 (middleware.go), 앞에 둔 것이 먼저 봐
+	// requireScope 가 자격 증명과 권한을 먼저 보고(인증을 켰을 때만), 파일 내용이 오가는 핸들러는 metered 로 감싸서 계정별 전송량을 세고 한도를 지켜
+	// handle/handleTransfer 는 /metrics 에 나갈 요청 수와 시간을 세 (metrics.go)
+	// /download, /hls 는 서명 URL(?sig=)이면 자격 증명 없이 (sign.go)
+	// 큰 다운로드는 downloadLimit 이 MaxDownloads 자리를 잡은 뒤에 보내 (limit.go), 업로드는 limitUploads 가 주소별 MaxUploadsPerIP 자리를 (uploadlimit.go)
+	upload := []Middleware{s.requireScope(ScopeUpload), s.limitUploads, s.metered}
+	s.handleTransfer("/download", chain(s.downloadHandler, s.signedOrScope(ScopeDownload), s.downloadLimit(queryFile), s.metered))
+	s.handleTransfer("/range-download", chain(s.rangeDownloadHandler, s.requireScope(ScopeDownload), s.downloadLimit(queryFile), s.metered))
+	s.handleTransfer("/upload", chain(s.uploadHandler, upload...))
+	s.handleTransfer("/upload/", chain(s.rangeUploadHandler, upload...))
+	s.handleTransfer("/api/uploads", chain(s.uploadsHandler, upload...))
+	s.handleTransfer("/api/uploads/", chain(s.uploadsHandler, upload...))
+	s.handleTransfer("/api/multipart", chain(s.multipartHandler, upload...))
+	s.handleTransfer("/api/multipart/", chain(s.multipartHandler, upload...))
+	s.handle("/delete", chain(s.deleteHandler, s.requireScope(ScopeDelete)))
+	s.handle("/api/search", chain(s.searchHandler, s.requireScope(ScopeDownload)))
+	s.handle("/api/files", chain(s.filesHandler, s.requireScope(ScopeDownload)))
+	s.handle("/api/files/", chain(s.fileHandler, s.requireScope(ScopeDelete)))
+	s.handle("/api/trash", chain(s.trashHandler, s.requireScope(ScopeDelete)))
+	s.handle("/api/trash/", chain(s.trashHandler, s.requireScope(ScopeDelete)))
+	s.handle("/api/events", chain(s.eventsHandler, s.requireScope(ScopeDownload)))
+	s.handle("/stats", chain(s.statsHandler, s.requireScope(ScopeDownload)))
+	// 상태 확인은 로드밸런서가 키 없이 물어봐 (health.go)
+	s.handle("/healthz", s.healthHandler)
+	s.handle("/readyz", s.healthHandler)
+	s.handleTransfer("/api/extract", chain(s.extractHandler, upload...))
+	s.handleTransfer("/upload-archive", chain(s.uploadArchiveHandler, upload...))
+	s.handle("/api/usage", chain(s.usageHandler, s.requireScope("")))
+	s.handle("/api/sign", chain(s.signHandler, s.requireScope(ScopeDownload)))
+	s.handle("/thumb", chain(s.thumbHandler, s.requireScope(ScopeDownload)))
+	s.handle("/hls", chain(s.hlsHandler, s.signedOrScope(ScopeDownload)))
+	s.handle("/api/copy", chain(s.copyHandler, s.requireScope(ScopeUpload)))
+	// WebDAV 는 메서드마다 권한이 달라서 davHandler 안에서 authed 를 골라 (dav.go)
+	dav := chain(s.davHandler(), s.limitUploads)
+	s.handleTransfer(davPrefix, dav)
+	s.handleTransfer(davPrefix+"/", dav)
+
+	// 정적 파일 서빙 (PublicFiles 면 자격 증명 없이도) - 로컬 디렉토리가 아니면 파일 하나씩만 (디렉토리 목록 없이)
+	files := s.staticHandler
+	if s.localDir() {
+		files = http.StripPrefix("/files", http.FileServer(http.Dir(cfg.UploadDir))).ServeHTTP
+	}
+	public := func() bool { return !s.live().PublicFiles }
+	s.handleTransfer("/files/", chain(files, s.requireScopeIf(ScopeDownload, public), s.downloadLimit(pathFile), s.metered))
+
+	// Prometheus 지표 (인증 없이 - 숫자만 나가)
+	s.mux.Handle("GET /metrics", s.metrics.reg)
+
+	return s, nil
+}
+
+// Handler 등록된 핸들러 (httptest 나 다른 서버에 붙일 때) - 모든 요청에 trace → 요청 로그 → CORS 를 먼저 거쳐
+func (s *Server) Handler() http.Handler {
+	return chain(s.mux.ServeHTTP, wrapHandler(traceHandler), wrapHandler(s.logRequests), wrapHandler(s.cors))
+}
+
+// Addr 설정된 리슨 주소
+func (s *Server) Addr() string {
+	return s.cfg.Addr
+}
+
+// ListenAndServe 설정된 주소로 Serve
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve ln 에서 ctx 가 취소될 때까지 서비스하고, 취소되면 진행 중인 요청을 기다렸다가 종료
+// (systemd 가 넘겨준 소켓처럼 밖에서 만든 리스너로 띄울 때 - ln 은 Serve 가 닫아)
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Addr: s.cfg.Addr, Handler: s.Handler(), ErrorLog: slog.NewLogLogger(s.cfg.Logger.Handler(), slog.LevelWarn)}
+	srv.RegisterOnShutdown(s.events.close) // 열린 SSE 연결이 Shutdown 을 붙잡지 않게
+
+	if s.usage != nil {
+		// ctx 가 아니라 Shutdown 뒤에 멈춰서, 진행 중이던 요청까지 센 다음 Run 의 마지막 Flush 가 돌고 나서 돌아가
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.usage.Run(runCtx)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+	}
+
+	// 보내던 업로드 웹훅은 끝까지 (HTTP, SFTP, TCP 가 다 멈춘 뒤라 더 생기지 않아)
+	defer s.webhooks.Wait()
+
+	// 만료된 업로드와 보관 기간이 지난 휴지통 항목 지우기 - 요청처럼 Shutdown 을 기다리지 않고 ctx 와 같이 멈춰
+	expireCtx, stopExpiry := context.WithCancel(ctx)
+	expireDone := make(chan struct{})
+	go func() {
+		defer close(expireDone)
+		s.runExpiry(expireCtx)
+	}()
+	defer func() {
+		stopExpiry()
+		<-expireDone
+	}()
+
+	if s.access != nil {
+		defer s.access.Close() // Shutdown 이 진행 중이던 요청을 다 기다린 뒤라 마지막 줄까지 남아
+	}
+	if s.auditLog != nil {
+		defer s.auditLog.Close()
+	}
+
+	if s.sftp != nil {
+		// HTTP 와 같이 멈춰 - 감사 로그를 닫기 전에 SFTP 연결이 다 끝나 있어
+		sln, err := net.Listen("tcp", s.cfg.SFTPAddr)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		s.cfg.Logger.Info("SFTP 서비스 시작", "addr", sln.Addr().String())
+		sftpCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := s.ServeSFTP(sftpCtx, sln); err != nil {
+				s.cfg.Logger.Error("SFTP 서비스 실패", "err", err)
+			}
+		}()
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
+	if s.cfg.TCPAddr != "" {
+		tln, err := net.Listen("tcp", s.cfg.TCPAddr)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		s.cfg.Logger.Info("TCP 수신 시작", "addr", tln.Addr().String())
+		tcpCtx, stop := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := s.ServeTCP(tcpCtx, tln); err != nil {
+				s.cfg.Logger.Error("TCP 수신 실패", "err", err)
+			}
+		}()
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
+	errCh := make(chan error, 1)
+	if s.tls != nil {
+		// ServeTLS 가 h2 까지 맞춰 줘 (인증서는 TLSConfig 에 이미 있어서 파일 이름은 비워)
+		srv.TLSConfig = s.tls
+		go func() { errCh <- srv.ServeTLS(ln, "", "") }()
+	} else {
+		go func() { errCh <- srv.Serve(ln) }()
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) uploadPath(name string) string {
+	return filepath.Join(s.cfg.UploadDir, name)
+}
+
+// 파일 다운로드 핸들러
+func (s *Server) downloadHandler(w http.ResponseWriter, r *http.Request) {
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		http.Error(w, msg.T("파일명이 필요합니다"), http.StatusBadRequest)
+		return
+	}
+
+	// 파일 열기
+	safeFilename, ok := sanitizeFilename(filename) // "../../etc/passwd", "..\..\etc\passwd" -> "passwd"로 변경됨
+	if !ok {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	rate, err := s.downloadRate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, fileInfo, err := s.openUpload(r.Context(), safeFilename)
+	if !s.opened(w, r, safeFilename, err) {
+		return
+	}
+	defer file.Close()
+
+	// 헤더 설정 - 형식은 확장자나 앞부분으로 (?inline=1 이면 브라우저에서 바로 열게)
+	ct := contentType(safeFilename, sniffHead(file))
+	setDisposition(w, r, safeFilename, ct)
+	w.Header().Set("Content-Type", ct)
+	s.setValidators(w, safeFilename, fileInfo)
+
+	// HEAD 와 Range 는 ServeContent 에 맡겨 - HEAD 는 본문 없이 Content-Length, ETag, Last-Modified, Accept-Ranges 만,
+	// Range 는 /range-download 처럼 206 (다운로드 관리자가 HEAD 로 크기를 보고 구간을 나눠 받는 흐름)
+	if r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+		http.ServeContent(w, r, safeFilename, fileInfo.ModTime(), throttle(file, rate))
+		return
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Last-Modified", fileInfo.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.FormatInt(fileInfo.Size(), 10))
+	body, finish := s.gzipResponse(w, r, safeFilename, file, fileInfo.Size())
+
+	// 스트리밍 전송 (gzip 이면 압축하면서 - 진행률은 원본 바이트 기준)
+	info := streamio.TransferInfo{ID: safeFilename, Src: safeFilename, Dst: r.RemoteAddr, Size: fileInfo.Size()}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.transfers.track(transferDownload, safeFilename)}
+	opts.RateLimit = rate
+	written, err := streamio.Copy(r.Context(), body, file, info, opts)
+	if ferr := finish(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		s.logger(r).WarnContext(r.Context(), "전송 중 에러", "file", safeFilename, "bytes", written, "err", err)
+		return
+	}
+
+	s.logger(r).InfoContext(r.Context(), "파일 전송 완료", "file", safeFilename, "bytes", written)
+}
+
+// Range 요청을 지원하는 핸들러 (이어받기 지원)
+func (s *Server) rangeDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		http.Error(w, msg.T("파일명이 필요합니다"), http.StatusBadRequest)
+		return
+	}
+
+	// 파일 열기
+	safeFilename, ok := sanitizeFilename(filename) // "../../etc/passwd", "..\..\etc\passwd" -> "passwd"로 변경됨
+	if !ok {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	rate, err := s.downloadRate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, fileInfo, err := s.openUpload(r.Context(), safeFilename)
+	if !s.opened(w, r, safeFilename, err) {
+		return
+	}
+	defer file.Close()
+
+	s.logger(r).DebugContext(r.Context(), "Range 요청", "file", safeFilename, "size", fileInfo.Size(), "range", r.Header.Get("Range"))
+	// Content-Disposition 설정 (다운로드 창이 뜨게 함, ?inline=1 이면 바로 열게) - Content-Type 은 ServeContent 가 같은 방식으로 정해
+	ct := contentType(safeFilename, sniffHead(file))
+	w.Header().Set("Content-Type", ct)
+	setDisposition(w, r, safeFilename, ct)
+	s.setValidators(w, safeFilename, fileInfo) // If-Range 로 이어받기 (바뀐 파일이면 전체를 200 으로)
+
+	// http.ServeContent가 Range 헤더를 자동으로 확인하여
+	// 전체 전송(200 OK) 또는 부분 전송(206 Partial Content)을 알아서 처리합니다.
+	// ?limit= 이나 DownloadRate 가 있으면 읽는 쪽을 늦춰 (Range 로 건너뛰는 Seek 은 그대로)
+	http.ServeContent(w, r, safeFilename, fileInfo.ModTime(), throttle(file, rate))
+
+	// // Range 헤더 확인
+	// rangeHeader := r.Header.Get("Range")
+	// fmt.Println("rangeHeader :", rangeHeader)
+	// if rangeHeader == "" {
+	// 	// 전체 파일 전송
+	// 	w.Header().Set("Content-Length", strconv.FormatInt(fileInfo.Size(), 10))
+	// 	w.Header().Set("Content-Type", "application/octet-stream")
+	// 	io.Copy(w, file)
+	// 	return
+	// }
+
+	// // Range 요청 처리 (간단한 구현)
+	// // 실제로는 더 복잡한 파싱이 필요해
+	// var start, end int64
+	// fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
+
+	// fmt.Println("start : ", start, " end : ", end)
+
+	// if end == 0 || end >= fileInfo.Size() {
+	// 	end = fileInfo.Size() - 1
+	// }
+
+	// // 파일 포인터 이동
+	// file.Seek(start, 0)
+
+	// // 헤더 설정
+	// w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, fileInfo.Size()))
+	// w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	// w.Header().Set("Content-Type", "application/octet-stream")
+	// w.WriteHeader(http.StatusPartialContent)
+
+	// // 부분 전송
+	// io.CopyN(w, file, end-start+1)
+}
+
+// 업로드 핸들러
+func (s *Server) uploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// ⭐ 제한을 넘는 본문은 읽는 도중에 끊어 - 디스크에 다 받아놓고 나서 거절하지 않게 (bodylimit.go)
+	limitBody(w, r, s.live().MaxUploadSize)
+	s.throttleBody(r)
+
+	// ⭐ 멀티파트 폼을 메모리/임시 파일에 먼저 풀지 않고, 파일 파트를 읽으면서 바로 디스크에 써
+	// (그래야 /api/events 진행률이 서버가 실제로 받은 만큼이고, 큰 파일도 한 번만 디스크에 써 - 몇 GB 든 메모리는 버퍼 하나)
+	// "file" 파트가 여러 개면 하나씩 차례로 저장해 - 중간에 실패하면 그 앞 파일들은 저장된 채로 에러 응답
+	// 첫 파일 파트가 "files" 면 여러 개를 동시에 저장하고 파일마다 결과를 JSON 으로 (multifile.go)
+	acct := s.account(r)
+	expected, err := expectedSums(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, msg.T("폼 파싱 실패"), http.StatusBadRequest)
+		return
+	}
+	file, err := nextFilePart(mr, "file", multiFileField)
+	if err != nil {
+		if !uploadTooLarge(w, err) {
+			http.Error(w, msg.T("파일을 가져올 수 없습니다"), http.StatusBadRequest)
+		}
+		return
+	}
+	if file.FormName() == multiFileField {
+		s.saveFiles(w, r, mr, file, acct, expected)
+		return
+	}
+	var saved []uploadedFile
+	for {
+		want := "" // 이 파트가 맞아야 할 sha256 (X-Expected-SHA256 에 이 순서의 값이 없으면 확인 안 함)
+		if len(saved) < len(expected) {
+			want = expected[len(saved)]
+		}
+		f, ok := s.savePart(w, r, file, acct, want)
+		file.Close()
+		if !ok {
+			return
+		}
+		saved = append(saved, f)
+		file, err = nextFilePart(mr, "file")
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if !uploadTooLarge(w, err) {
+				http.Error(w, msg.T("파일을 가져올 수 없습니다"), http.StatusBadRequest)
+			}
+			return
+		}
+	}
+	writeUploaded(w, r, saved)
+}
+
+// uploadedFile 저장한 파일 하나 (Accept: application/json 이면 /upload 응답)
+type uploadedFile struct {
+	Name     string    `json:"name"`     // 실제로 저장한 이름 - 이걸로 /download?file= 해
+	Original string    `json:"original"` // 클라이언트가 보낸 이름 (sanitizeFilename 을 거친 값)
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`                 // 받으면서 잰 값 (X-Content-SHA256 과 같아)
+	Dedup    bool      `json:"deduplicated,omitempty"` // 같은 내용이 이미 있어서 새로 자리를 차지하지 않았어 (DedupDir)
+	Expires  time.Time `json:"expires,omitzero"`       // 이때 지워져 (X-Expire-After, ExpireAfter - expire.go)
+}
+
+// writeUploaded 저장한 파일 목록 - Accept 에 application/json 이 있으면 JSON, 아니면 한 줄에 하나씩 텍스트
+func writeUploaded(w http.ResponseWriter, r *http.Request, files []uploadedFile) {
+	setChecksumHeader(w, files)
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(map[string]any{"files": files})
+		return
+	}
+	var result bytes.Buffer
+	for _, f := range files {
+		if f.Name != f.Original {
+			msg.Fprintf(&result, "파일 업로드 성공: %s (%d 바이트, %s 은 이미 있어서 새 이름으로, sha256 %s)\n", f.Name, f.Size, f.Original, f.SHA256)
+			continue
+		}
+		msg.Fprintf(&result, "파일 업로드 성공: %s (%d 바이트, sha256 %s)\n", f.Name, f.Size, f.SHA256)
+	}
+	result.WriteTo(w)
+}
+
+// savePart 파일 파트 하나를 acct 의 파일로 업로드 디렉토리에 저장 - 실패하면 에러 응답까지 쓰고 false
+// expected 를 주면 받은 내용의 sha256 이 같아야 저장해 (다르면 422)
+func (s *Server) savePart(w http.ResponseWriter, r *http.Request, file *multipart.Part, acct APIKey, expected string) (uploadedFile, bool) {
+	return s.saveNamed(w, r, file.FileName(), file, acct, expected)
+}
+
+// saveNamed 클라이언트가 filename 이라고 보낸 body 를 저장 (이름 정리, Collision 정책, 경로 잠금까지) - 실패하면 에러 응답까지 쓰고 false
+func (s *Server) saveNamed(w http.ResponseWriter, r *http.Request, filename string, body io.Reader, acct APIKey, expected string) (uploadedFile, bool) {
+	original, ok := sanitizeFilename(filename)
+	if !ok {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return uploadedFile{}, false
+	}
+
+	// 같은 이름이 있으면 Collision 정책대로 이름을 고르고, 같은 이름으로 동시에 올라오는 업로드(또는 삭제)와 섞이지 않게 경로 잠금
+	name, unlock, err := s.claimName(original)
+	if err != nil {
+		s.nameConflict(w, r, original, err)
+		return uploadedFile{}, false
+	}
+	defer unlock()
+	return s.saveFile(w, r, original, name, body, acct, expected)
+}
+
+// saveFile body 를 acct 의 name 으로 저장 (name 의 경로 잠금은 부른 쪽이) - 실패하면 에러 응답까지 쓰고 false
+// original 은 클라이언트가 보낸 이름 (검사기와 진행률 ID 에 써)
+func (s *Server) saveFile(w http.ResponseWriter, r *http.Request, original, name string, body io.Reader, acct APIKey, expected string) (uploadedFile, bool) {
+	target := s.uploadPath(name)
+	ttl, ok := s.uploadTTL(w, r)
+	if !ok {
+		return uploadedFile{}, false
+	}
+	// 정책(Config.Policy)은 앞부분만 받아 보고 판정해 - 걸리면 아무것도 쓰지 않고 415
+	body, ok = s.checkPolicy(w, r, original, body)
+	if !ok {
+		return uploadedFile{}, false
+	}
+
+	// 다 받은 뒤 commit 해야 name 으로 보여 - 받다가 끊겨도, 누가 그 파일을 내려받는 중이어도 예전 내용이 온전히 남아
+	dst, err := s.newUpload(r, name, expected)
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "파일 생성 실패", "file", name, "err", err)
+		http.Error(w, msg.T("파일 생성 실패"), http.StatusInternalServerError)
+		return uploadedFile{}, false
+	}
+	defer dst.abort() // commit 한 뒤면 아무것도 안 해
+
+	// 스트리밍 방식으로 저장 - 파트 크기는 다 읽기 전엔 몰라 (진행률은 바이트만, 브라우저는 자기가 아는 파일 크기로 % 계산)
+	info := streamio.TransferInfo{ID: uploadID(r, original), Src: r.RemoteAddr, Dst: target, Size: -1}
+	opts := s.copyOptions()
+	opts.Hooks = streamio.MultiHooks{opts.Hooks, s.events, s.transfers.track(transferUpload, name)}
+	// 저장 공간 한도가 있으면 남은 만큼만 읽어 (같은 경로 잠금 안이라 덮어쓸 내 파일 크기가 그사이 바뀌지 않아)
+	// 디스크에 쓰는 바이트가 그대로 sha256 에도 들어가 - 다 받은 뒤 다시 읽지 않아
+	// 검사기(Config.Scanner)도 같은 흐름을 받아서 다 받은 뒤 판정만 기다려
+	digest := sha256.New()
+	scanner := scan.Start(r.Context(), s.live().Scanner, original)
+	defer scanner.Abort() // Close 한 뒤면 아무것도 안 해
+	body = io.TeeReader(&quotaReader{r: body, n: s.storageRoom(acct, name)}, io.MultiWriter(digest, scanner))
+	written, err := streamio.Copy(r.Context(), dst, body, info, opts)
+	sum := hex.EncodeToString(digest.Sum(nil))
+	if err == nil && expected != "" && sum != expected {
+		s.checksumFailed(w, r, original, expected, sum) // 받던 것은 defer 가 버려 - 예전 파일은 그대로
+		return uploadedFile{}, false
+	}
+	if err == nil {
+		if verdict := scanner.Close(); verdict != nil {
+			s.scanFailed(w, r, original, verdict)
+			return uploadedFile{}, false
+		}
+	}
+	dup := false
+	if err == nil {
+		dup, err = dst.commit(r, sum)
+	}
+	if errors.Is(err, usage.ErrStorageQuota) {
+		s.storageFull(w, r, acct, name, 0)
+		return uploadedFile{}, false
+	}
+	if err != nil {
+		if !uploadTooLarge(w, err) {
+			s.logger(r).ErrorContext(r.Context(), "파일 저장 실패", "file", name, "bytes", written, "err", err)
+			http.Error(w, msg.T("파일 저장 실패"), http.StatusInternalServerError)
+		}
+		return uploadedFile{}, false
+	}
+
+	expires := s.recordUpload(w, r, name, original, acct.Name, written, sum, ttl)
+	s.logger(r).InfoContext(r.Context(), "파일 업로드", "file", name, "original", original, "bytes", written)
+	return uploadedFile{Name: name, Original: original, Size: written, SHA256: sum, Dedup: dup, Expires: expires}, true
+}
+
+// recordUpload 업로드 디렉토리에 들어간 name 을 기록하고 알려 (저장 공간, 만료, 검색 색인, 썸네일, 웹훅) - 돌려주는 건 만료 시각
+// saveFile, 이어 올리기, 아카이브 풀기가 다 이걸로 끝내 (name 의 경로 잠금 안에서 불러).
+func (s *Server) recordUpload(w http.ResponseWriter, r *http.Request, name, original, owner string, size int64, sum string, ttl time.Duration) time.Time {
+	s.storePut(r, name, owner, size)
+	expires := s.setExpiry(w, r, name, ttl)
+	s.queueIndex(s.uploadPath(name), false)
+	s.queueThumb(name)
+	s.announceUpload(r, name, original, owner, size, sum)
+	return expires
+}
+
+// nextFilePart 멀티파트에서 fields 중 한 이름의 파일 파트 찾기 (앞에 다른 필드가 있으면 건너뛰어)
+func nextFilePart(mr *multipart.Reader, fields ...string) (*multipart.Part, error) {
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(fields, part.FormName()) && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// uploadID 전송 ID - 클라이언트가 ?id= 로 정해 주면 그걸로 (/api/events?id= 로 자기 업로드만 구독), 아니면 파일명
+func uploadID(r *http.Request, name string) string {
+	if id := r.URL.Query().Get("id"); id != "" && len(id) <= maxRequestIDLen {
+		return id
+	}
+	return name
+}
+
+// 삭제 핸들러 - 바로 지우지 않고 휴지통으로 옮겨서 /api/trash 나 trash 도구(restore)로 되살릴 수 있어
+func (s *Server) deleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete && r.Method != http.MethodPost {
+		http.Error(w, msg.T("DELETE 또는 POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		http.Error(w, msg.T("파일명이 필요합니다"), http.StatusBadRequest)
+		return
+	}
+
+	safeFilename, ok := sanitizeFilename(filename)
+	if !ok {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	trashID, err := s.deleteFile(r, safeFilename)
+	s.audit(w, r, auditEntry{Action: AuditDelete, File: safeFilename, TrashID: trashID, Status: deleteStatus(err)}, err)
+	if !s.deleted(w, r, safeFilename, err) {
+		return
+	}
+
+	if trashID == "" {
+		msg.Fprintf(w, "파일 삭제 완료: %s\n", safeFilename)
+	} else {
+		msg.Fprintf(w, "파일 삭제 완료: %s (휴지통 ID: %s)\n", safeFilename, trashID)
+	}
+}
+
+// remove name 을 지워 - 로컬 디렉토리면 휴지통으로 옮기고 그 ID 를, 다른 저장소는 바로 지우고 ""
+func (s *Server) remove(ctx context.Context, name string) (trashID string, err error) {
+	if !s.localDir() {
+		return "", s.backend.Delete(ctx, name)
+	}
+	item, err := s.trash.Delete(s.uploadPath(name))
+	if err != nil {
+		return "", err
+	}
+	return item.ID, nil
+}
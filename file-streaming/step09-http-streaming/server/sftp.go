@@ -0,0 +1,544 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// SFTP (Config.SFTPAddr)
+// ⭐ HTTP 와 같은 저장소를 SFTP 로도 열어서, 스크립트의 일괄 전송은 sftp 로, 브라우저는 HTTP 로 같은 파일을 다뤄:
+//
+//	sftp -P 2022 -i ~/.ssh/id_ed25519 alice@서버   → ls, get, put, rm, rename
+//
+// 로그인은 ssh 공개키로만 - SFTPAuthorizedKeys(authorized_keys 형식)에 있는 키면 되고, 로그인할 때마다 다시 읽어서 키를 더하고 빼는 데 재시작이 필요 없어.
+// 인증을 켰으면(APIKeys) 그 줄의 주석이 계정 이름이라 그 계정의 권한과 전송/저장 공간 한도가 따라가 - 목록과 get 은 download, put 은 upload, rm 과 rename 은 delete.
+// 인증이 꺼져 있으면 HTTP 처럼 anonymous 계정이야 (ssh 사용자 이름은 아무거나).
+// 쓰기는 HTTP 핸들러와 같은 길이라 검사기, 저장 공간 한도, sha256 기록, 중복 제거, 검색 색인, 썸네일, 휴지통, 감사 로그가 그대로 따라가.
+// sftp 클라이언트는 조각을 여러 개 겹쳐 보내서 순서가 섞여 - put 은 SessionDir 의 임시 파일에 먼저 받았다가 닫을 때 /upload 와 같은 길로 저장하고,
+// 거기서 실패하면(한도 초과, 검사기 거부) 닫기가 그 메시지로 실패해. 같은 이름이 있으면 덮어써 (Collision 정책은 WebDAV 의 PUT 처럼 안 따라).
+// 업로드 디렉토리처럼 한 층이라 디렉토리는 못 만들고, 점으로 시작하는 이름은 안 보여. 이어 올리기(reput)는 안 되고, 권한/시각 바꾸기(setstat)는 무시해.
+
+// defaultSFTPHostKey Config.SFTPHostKey 가 비었을 때
+const defaultSFTPHostKey = "./.sftp_host_key"
+
+// sshServerConfig 호스트 키를 읽고(없으면 만들고) authorized_keys 로 공개키 인증하는 ssh 설정
+func (s *Server) sshServerConfig() (*ssh.ServerConfig, error) {
+	if s.cfg.SFTPAuthorizedKeys == "" {
+		return nil, msg.New("SFTP 를 열려면 SFTPAuthorizedKeys 가 필요합니다 (공개키로만 로그인)")
+	}
+	// 시작할 때 한 번 읽어서 틀린 경로나 형식은 바로 알려 (로그인할 때는 다시 읽어)
+	if _, err := os.ReadFile(s.cfg.SFTPAuthorizedKeys); err != nil {
+		return nil, err
+	}
+	signer, err := loadHostKey(s.cfg.SFTPHostKey)
+	if err != nil {
+		return nil, msg.Errorf("SFTP 호스트 키 %s: %w", s.cfg.SFTPHostKey, err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			// 클라이언트는 가진 키를 하나씩 물어봐서 틀린 키는 흔해 - Debug 로만
+			comment, err := authorizedKey(s.cfg.SFTPAuthorizedKeys, key)
+			if err == nil {
+				_, err = s.sftpAccount(comment)
+			}
+			if err != nil {
+				s.cfg.Logger.Debug("SFTP 키 거절", "user", meta.User(), "remote", meta.RemoteAddr().String(), "key", ssh.FingerprintSHA256(key), "err", err)
+				return nil, err
+			}
+			return &ssh.Permissions{Extensions: map[string]string{"account": comment}}, nil
+		},
+	}
+	config.AddHostKey(signer)
+	s.cfg.Logger.Info("SFTP 호스트 키", "file", s.cfg.SFTPHostKey, "fingerprint", ssh.FingerprintSHA256(signer.PublicKey()))
+	return config, nil
+}
+
+// loadHostKey path 의 호스트 개인키 - 없으면 ed25519 로 만들어 저장 (다시 띄워도 클라이언트의 known_hosts 와 맞게)
+func loadHostKey(path string) (ssh.Signer, error) {
+	text, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		block, err := ssh.MarshalPrivateKey(key, "file-streaming sftp")
+		if err != nil {
+			return nil, err
+		}
+		text = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, text, 0600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(text)
+}
+
+// authorizedKey authorized_keys 파일에서 key 를 찾아 그 줄의 주석
+func authorizedKey(path string, key ssh.PublicKey) (string, error) {
+	rest, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	want := key.Marshal()
+	for len(rest) > 0 {
+		pub, comment, _, next, err := ssh.ParseAuthorizedKey(rest)
+		if err != nil {
+			break // 남은 줄에 키가 없어
+		}
+		if bytes.Equal(pub.Marshal(), want) {
+			return comment, nil
+		}
+		rest = next
+	}
+	return "", msg.New("authorized_keys 에 없는 키입니다")
+}
+
+// sftpAccount authorized_keys 주석의 계정 - 인증이 꺼져 있으면 anonymous, 켜져 있으면 같은 이름의 API 키 계정이 있어야 해
+func (s *Server) sftpAccount(name string) (APIKey, error) {
+	live := s.live()
+	if len(live.Validators) == 0 {
+		return APIKey{Name: anonymous, Monthly: live.MonthlyQuota, Storage: live.StorageQuota}, nil
+	}
+	for _, acct := range live.APIKeys {
+		if acct.Name == name {
+			return acct, nil
+		}
+	}
+	return APIKey{}, msg.Errorf("authorized_keys 의 주석 %q 와 이름이 같은 계정이 없습니다", name)
+}
+
+// ServeSFTP ln 에서 ctx 가 취소될 때까지 SFTP 를 서비스 - 취소되면 열린 연결도 끊어 (ln 은 ServeSFTP 가 닫아)
+// Serve 가 SFTPAddr 로 불러 주고, 테스트처럼 밖에서 만든 리스너로 띄울 때 직접 불러.
+func (s *Server) ServeSFTP(ctx context.Context, ln net.Listener) error {
+	if s.sftp == nil {
+		ln.Close()
+		return msg.New("SFTP 가 꺼져 있습니다 (SFTPAddr)")
+	}
+	return serveConns(ctx, ln, s.sftpConn)
+}
+
+// sftpConn ssh 연결 하나 - 세션 채널마다 sftp 서브시스템
+func (s *Server) sftpConn(ctx context.Context, conn net.Conn) {
+	remote := conn.RemoteAddr().String()
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.sftp)
+	if err != nil {
+		s.cfg.Logger.Debug("SFTP 핸드셰이크 실패", "remote", remote, "err", err)
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	// 인증과 지금 사이에 설정을 다시 읽었을 수 있어서 한 번 더
+	acct, err := s.sftpAccount(sconn.Permissions.Extensions["account"])
+	if err != nil {
+		s.cfg.Logger.Warn("SFTP 계정 없음", "remote", remote, "err", err)
+		return
+	}
+	id := newRequestID()
+	lg := s.cfg.Logger.With("sftp_session", id, "account", acct.Name)
+	sess := &sftpSession{s: s, ctx: logging.WithContext(ctx, lg), logger: lg, id: id, acct: acct, remote: remote}
+	lg.Info("SFTP 로그인", "user", sconn.User(), "remote", remote, "client", string(sconn.ClientVersion()))
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, msg.T("session 채널만 됩니다"))
+			continue
+		}
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			continue
+		}
+		wg.Go(func() { sess.serve(ch, reqs) })
+	}
+	wg.Wait()
+	lg.Info("SFTP 로그아웃", "remote", remote, "elapsed", time.Since(start))
+}
+
+// sftpSession 로그인한 연결 하나 - pkg/sftp 의 핸들러 (Fileread, Filewrite, Filecmd, Filelist)
+type sftpSession struct {
+	s      *Server
+	ctx    context.Context // 연결이 살아 있는 동안 (서버를 끄면 취소)
+	logger *slog.Logger
+	id     string // 감사 로그의 request_id
+	acct   APIKey
+	remote string
+}
+
+// serve sftp 서브시스템만 받아 (셸, exec, pty 는 거절)
+func (ss *sftpSession) serve(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	ok := false
+	for req := range reqs {
+		// 페이로드는 길이(4바이트) + 서브시스템 이름
+		ok = req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+		req.Reply(ok, nil)
+		if ok {
+			break
+		}
+	}
+	if !ok {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	rs := sftp.NewRequestServer(ch, sftp.Handlers{FileGet: ss, FilePut: ss, FileCmd: ss, FileList: ss})
+	if err := rs.Serve(); err != nil && !errors.Is(err, io.EOF) {
+		ss.logger.Debug("SFTP 세션 끝", "err", err)
+	}
+	rs.Close()
+}
+
+// request SFTP 요청 하나를 HTTP 핸들러의 도우미(saveFile, deleteFile, audit …)가 받는 요청과 응답으로 - 계정, 클라이언트 주소, 세션 로거를 담아
+func (ss *sftpSession) request(method, name string) (*http.Request, *sftpResponse) {
+	ctx := context.WithValue(ss.ctx, accountKey{}, ss.acct)
+	r := (&http.Request{Method: method, URL: &url.URL{Path: "/sftp/" + name}, Header: http.Header{}, Body: http.NoBody, RemoteAddr: ss.remote}).WithContext(ctx)
+	w := &sftpResponse{header: http.Header{}, status: http.StatusOK}
+	w.header.Set("X-Request-ID", ss.id)
+	return r, w
+}
+
+// allow 로그인한 계정이 scope 를 할 수 있는지 (권한은 로그인할 때의 것 - 바꾸면 다시 로그인해야 해)
+func (ss *sftpSession) allow(scope string) error {
+	if ss.acct.Allows(scope) {
+		return nil
+	}
+	ss.logger.Warn("권한 없음", "scope", scope)
+	return sftp.ErrSSHFxPermissionDenied
+}
+
+// transferLeft 이번 달에 더 옮길 수 있는 바이트 (제한이 없으면 -1, 다 썼으면 에러 - metered 의 429 와 같은 때)
+func (ss *sftpSession) transferLeft() (int64, error) {
+	if ss.s.usage == nil {
+		return -1, nil
+	}
+	left := ss.s.usage.Remaining(ss.acct.Name, ss.acct.Monthly)
+	if left == 0 {
+		ss.logger.Warn("전송 한도 초과", "limit", ss.acct.Monthly)
+		return 0, msg.Errorf("이번 달 전송 한도를 넘었습니다 (%s 에 초기화)", usage.NextMonth(time.Now()).Format(time.DateOnly))
+	}
+	return left, nil
+}
+
+// sftpName "/이름" → 업로드의 이름 (한 층이고 점으로 시작하지 않아야 해)
+func sftpName(p string) (string, bool) {
+	return visibleName(strings.TrimPrefix(p, "/"))
+}
+
+// Filelist ls (List) 와 stat (Stat, Lstat) - 루트 하나만 디렉토리야
+func (ss *sftpSession) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	if err := ss.allow(ScopeDownload); err != nil {
+		return nil, err
+	}
+	if r.Filepath == "/" {
+		if r.Method != "List" {
+			return listerAt{sftpRoot{}}, nil
+		}
+		entries, err := ss.s.backend.List(ss.ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		var files listerAt
+		for _, e := range entries {
+			// 받는 중인 임시 파일, .thumbs 같은 것은 빼 (/api/files 와 같아)
+			if _, ok := visibleName(e.Name()); ok && e.Mode().IsRegular() {
+				files = append(files, e)
+			}
+		}
+		return files, nil
+	}
+	name, ok := sftpName(r.Filepath)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	info, err := ss.s.backend.Stat(ss.ctx, name)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, os.ErrNotExist
+	}
+	if r.Method == "List" {
+		return nil, msg.Errorf("디렉토리가 아닙니다: %s", name)
+	}
+	return listerAt{info}, nil
+}
+
+// Fileread get - 다운로드처럼 중복 제거 저장소의 블롭이나 저장소의 파일을 열어
+func (ss *sftpSession) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	if err := ss.allow(ScopeDownload); err != nil {
+		return nil, err
+	}
+	name, ok := sftpName(r.Filepath)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if _, err := ss.transferLeft(); err != nil {
+		return nil, err
+	}
+	f, _, err := ss.s.openUpload(ss.ctx, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sftpReader{ss: ss, name: name, f: f}, nil
+}
+
+// Filewrite put - 임시 파일에 받아 두고 닫을 때 저장 (sftpUpload)
+func (ss *sftpSession) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	if err := ss.allow(ScopeUpload); err != nil {
+		return nil, err
+	}
+	name, ok := sftpName(r.Filepath)
+	if !ok {
+		return nil, sftp.ErrSSHFxPermissionDenied
+	}
+	// TRUNC 없이 있는 파일을 열면 이어 쓰려는 거야 - 임시 파일은 빈 채로 시작해서 앞부분이 0 으로 채워져
+	if flags := r.Pflags(); flags.Append || (!flags.Trunc && ss.s.exists(name)) {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+	left, err := ss.transferLeft()
+	if err != nil {
+		return nil, err
+	}
+	limit := ss.s.live().MaxUploadSize
+	if left > 0 && (limit <= 0 || left < limit) {
+		limit = left
+	}
+	spool, err := os.CreateTemp(ss.s.cfg.SessionDir, "sftp-*")
+	if err != nil {
+		ss.logger.Error("SFTP 임시 파일 생성 실패", "file", name, "err", err)
+		return nil, err
+	}
+	return &sftpUpload{ss: ss, name: name, limit: limit, spool: spool}, nil
+}
+
+// Filecmd rm, rename (delete 권한) - setstat 은 무시, 디렉토리와 링크는 못 만들어
+func (ss *sftpSession) Filecmd(r *sftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		return nil // 권한과 시각은 서버가 정해 (put -p, scp -p 가 보내도 실패하지 않게)
+	case "Remove":
+		if err := ss.allow(ScopeDelete); err != nil {
+			return err
+		}
+		name, ok := sftpName(r.Filepath)
+		if !ok {
+			return os.ErrNotExist
+		}
+		req, w := ss.request(http.MethodDelete, name)
+		trashID, err := ss.s.deleteFile(req, name)
+		ss.s.audit(w, req, auditEntry{Action: AuditDelete, File: name, TrashID: trashID, Status: deleteStatus(err)}, err)
+		ss.s.deleted(w, req, name, err)
+		return w.err()
+	case "Rename", "PosixRename":
+		if err := ss.allow(ScopeDelete); err != nil {
+			return err
+		}
+		from, ok1 := sftpName(r.Filepath)
+		to, ok2 := sftpName(r.Target)
+		if !ok1 {
+			return os.ErrNotExist
+		}
+		if !ok2 {
+			return sftp.ErrSSHFxPermissionDenied
+		}
+		if from == to {
+			return nil
+		}
+		// posix-rename 도 있는 이름은 덮지 않아 - /api/files 의 rename 처럼 실패
+		req, w := ss.request(http.MethodPost, from)
+		err := ss.s.rename(ss.ctx, from, to)
+		status := renameStatus(err)
+		ss.s.audit(w, req, auditEntry{Action: AuditRename, File: from, To: to, Status: status}, err)
+		switch status {
+		case http.StatusOK:
+			ss.s.renamed(req, from, to)
+			return nil
+		case http.StatusNotFound:
+			return os.ErrNotExist
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %s", errDestExists, to)
+		}
+		ss.logger.Error("이름 바꾸기 실패", "file", from, "to", to, "err", err)
+		return msg.New("이름 바꾸기 실패")
+	}
+	return sftp.ErrSSHFxPermissionDenied // Mkdir, Rmdir, Symlink, Link - 업로드 디렉토리는 한 층이야
+}
+
+// sftpReader get 핸들 - 클라이언트가 여러 조각을 겹쳐 물어서 ReadAt (ReaderAt 이 아닌 저장소 파일은 잠그고 Seek+Read)
+type sftpReader struct {
+	ss   *sftpSession
+	name string
+	mu   sync.Mutex
+	f    io.ReadSeekCloser
+	n    atomic.Int64 // 보낸 바이트 (전송량)
+}
+
+func (r *sftpReader) ReadAt(p []byte, off int64) (int, error) {
+	var n int
+	var err error
+	if ra, ok := r.f.(io.ReaderAt); ok {
+		n, err = ra.ReadAt(p, off)
+	} else {
+		r.mu.Lock()
+		if _, err = r.f.Seek(off, io.SeekStart); err == nil {
+			n, err = io.ReadFull(r.f, p)
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+		}
+		r.mu.Unlock()
+	}
+	r.n.Add(int64(n))
+	return n, err
+}
+
+func (r *sftpReader) Close() error {
+	n := r.n.Load()
+	if r.ss.s.usage != nil {
+		r.ss.s.usage.Add(r.ss.acct.Name, usage.Download, n)
+	}
+	r.ss.logger.Info("SFTP 다운로드", "file", r.name, "bytes", n)
+	return r.f.Close()
+}
+
+// sftpUpload put 핸들 - 조각을 SessionDir 의 임시 파일에 자리대로 받아 두고, 닫을 때 처음부터 읽어서 saveFile 로
+type sftpUpload struct {
+	ss    *sftpSession
+	name  string
+	limit int64 // MaxUploadSize 와 남은 전송 한도 중 작은 것 (0 이하면 제한 없음)
+	spool *os.File
+
+	mu     sync.Mutex
+	failed error // 받는 도중에 연결이 끊겼으면 저장하지 않아
+}
+
+func (u *sftpUpload) WriteAt(p []byte, off int64) (int, error) {
+	if u.limit > 0 && off+int64(len(p)) > u.limit {
+		return 0, msg.Errorf("업로드 크기 제한(%d 바이트)을 넘었습니다", u.limit)
+	}
+	return u.spool.WriteAt(p, off)
+}
+
+// TransferError pkg/sftp 가 핸들을 연 채로 세션이 끝났을 때 알려 줘 (곧 Close 가 불려)
+func (u *sftpUpload) TransferError(err error) {
+	u.mu.Lock()
+	u.failed = err
+	u.mu.Unlock()
+}
+
+func (u *sftpUpload) Close() error {
+	defer os.Remove(u.spool.Name())
+	defer u.spool.Close()
+	u.mu.Lock()
+	failed := u.failed
+	u.mu.Unlock()
+	if failed != nil {
+		u.ss.logger.Warn("SFTP 업로드 중단", "file", u.name, "err", failed)
+		return failed
+	}
+	if _, err := u.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	s := u.ss.s
+	r, w := u.ss.request(http.MethodPut, u.name)
+	unlock := streamio.LockPath(s.uploadPath(u.name))
+	f, ok := s.saveFile(w, r, u.name, u.name, u.spool, u.ss.acct, "")
+	unlock()
+	if !ok {
+		return w.err()
+	}
+	if s.usage != nil {
+		s.usage.Add(u.ss.acct.Name, usage.Upload, f.Size)
+	}
+	return nil
+}
+
+// sftpResponse HTTP 도우미가 쓴 응답을 모아 두고, 에러 응답이면 SFTP 에러로 바꿔
+type sftpResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *sftpResponse) Header() http.Header  { return w.header }
+func (w *sftpResponse) WriteHeader(code int) { w.status = code }
+
+func (w *sftpResponse) Write(p []byte) (int, error) {
+	if w.body.Len() < 4<<10 { // 에러 메시지만 있으면 돼
+		w.body.Write(p)
+	}
+	return len(p), nil
+}
+
+// err 4xx/5xx 면 본문의 메시지 (404, 403 은 sftp 클라이언트가 자기 말로 보여주게 그 코드로)
+func (w *sftpResponse) err() error {
+	switch {
+	case w.status < 400:
+		return nil
+	case w.status == http.StatusNotFound:
+		return os.ErrNotExist
+	case w.status == http.StatusForbidden:
+		return sftp.ErrSSHFxPermissionDenied
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(w.body.Bytes(), &body) == nil && body.Error != "" {
+		return errors.New(body.Error)
+	}
+	return errors.New(strings.TrimSpace(w.body.String()))
+}
+
+// listerAt 이미 다 읽은 목록을 ListAt 으로 나눠 줘
+type listerAt []fs.FileInfo
+
+func (l listerAt) ListAt(ls []fs.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(ls, l[offset:])
+	if n < len(ls) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// sftpRoot "/" 의 stat - 저장소마다 루트를 Stat 할 수 있는 건 아니라서 만들어 줘
+type sftpRoot struct{}
+
+func (sftpRoot) Name() string       { return "/" }
+func (sftpRoot) Size() int64        { return 0 }
+func (sftpRoot) Mode() fs.FileMode  { return fs.ModeDir | 0755 }
+func (sftpRoot) ModTime() time.Time { return time.Time{} }
+func (sftpRoot) IsDir() bool        { return true }
+func (sftpRoot) Sys() any           { return nil }
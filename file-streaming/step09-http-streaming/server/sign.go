@@ -0,0 +1,122 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 서명한 임시 다운로드 URL (Config.SignSecret)
+// ⭐ 다운로드 권한이 있는 계정이 POST /api/sign?file=이름&ttl=1h 로 /download?file=이름&exp=만료&sig=서명 을 받아서 남에게 건네면,
+// 받는 쪽은 자격 증명 없이 그 파일 하나를 만료 전까지 내려받아 (Range, HEAD 도). 서명은 HMAC-SHA256(이름, 만료) 이라
+// 이름이나 만료를 바꾸면 맞지 않고, 비밀 키를 바꾸면 이미 나눠 준 URL 이 모두 무효가 돼.
+// 서명이 틀리거나 만료되면 403 (JSON 본문) - 서명 없이 온 요청은 평소처럼 인증을 거쳐.
+// 전송량은 "anonymous" 계정으로 세.
+
+const (
+	defaultSignTTL = time.Hour      // ?ttl 을 안 주면
+	defaultSignMax = 24 * time.Hour // Config.SignMaxTTL 이 0 이면
+)
+
+// signResponse /api/sign 응답
+type signResponse struct {
+	URL     string    `json:"url"`
+	Path    string    `json:"path"` // 호스트 없이 (프록시 뒤에서 주소를 바꿔 붙일 때)
+	Expires time.Time `json:"expires"`
+}
+
+// downloadSignature name 을 exp(유닉스 초)까지 내려받을 서명
+func downloadSignature(secret []byte, name string, exp int64) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "GET /download\n%s\n%d", name, exp)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signHandler 서명한 다운로드 URL 발급 - POST /api/sign?file=이름&ttl=1h
+func (s *Server) signHandler(w http.ResponseWriter, r *http.Request) {
+	live := s.live()
+	if live.SignSecret == "" {
+		http.Error(w, msg.T("서명 URL 이 꺼져 있습니다"), http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	name, ok := sanitizeFilename(q.Get("file"))
+	if !ok || name != q.Get("file") {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	ttl := defaultSignTTL
+	if v := q.Get("ttl"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, msg.T("ttl 은 1h, 30m 같은 양의 시간이어야 합니다"), http.StatusBadRequest)
+			return
+		}
+		ttl = d
+	}
+	if ttl > live.SignMaxTTL {
+		http.Error(w, msg.Sprintf("ttl 은 %s 이하여야 합니다", live.SignMaxTTL), http.StatusBadRequest)
+		return
+	}
+	if !s.exists(name) {
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+		return
+	}
+
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	exp := expires.Unix()
+	path := "/download?" + url.Values{
+		"file": {name},
+		"exp":  {strconv.FormatInt(exp, 10)},
+		"sig":  {downloadSignature([]byte(live.SignSecret), name, exp)},
+	}.Encode()
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(signResponse{URL: scheme + "://" + r.Host + path, Path: path, Expires: expires})
+	s.logger(r).InfoContext(r.Context(), "다운로드 URL 서명", "file", name, "account", s.account(r).Name, "expires", expires)
+}
+
+// signedOr ?sig= 가 있으면 서명과 만료를 확인하고 인증 없이 next 로, 없으면 authed 로
+func (s *Server) signedOr(authed, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		secret := s.live().SignSecret
+		if !q.Has("sig") || secret == "" {
+			authed(w, r)
+			return
+		}
+		exp, err := strconv.ParseInt(q.Get("exp"), 10, 64)
+		want := downloadSignature([]byte(secret), q.Get("file"), exp)
+		switch {
+		case err != nil || !hmac.Equal([]byte(q.Get("sig")), []byte(want)):
+			s.signFailed(w, r, "서명이 올바르지 않습니다")
+		case time.Now().After(time.Unix(exp, 0)):
+			s.signFailed(w, r, "만료된 URL 입니다")
+		default:
+			next(w, r)
+		}
+	}
+}
+
+func (s *Server) signFailed(w http.ResponseWriter, r *http.Request, reason string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(authError{Error: msg.T(reason)})
+	s.logger(r).WarnContext(r.Context(), "서명 URL 거절", "path", r.URL.Path, "file", r.URL.Query().Get("file"), "err", reason)
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/transfer"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// TCP 로 받기 (Config.TCPAddr) - HTTP 없이 transfer 패키지의 프레임 프로토콜로
+// ⭐ 연결 하나 = 파일 하나: offer {name, size, sha256} → accept {offset: 0} → data 프레임들 → done → result.
+// 프레임은 transfer.DataReader 가 풀어 주는 io.Reader 라서 /upload 와 같은 saveNamed 로 그대로 흘려보내고,
+// offer 의 sha256 을 expected 로 넘겨서 다이제스트가 다르면 저장하지 않아 (error 프레임).
+//
+//	go run ./streamctl send localhost:9000 big.iso        # streamctl recv 대신 이 서버로
+//
+// Collision 정책, 검사기, 정책, 저장 공간 한도, 중복 제거, 만료(ExpireAfter), 검색 색인, 썸네일, /api/events 와 /stats 가 그대로 따라가.
+// 프로토콜에 자격 증명이 없어서 계정은 anonymous 이고, 인증을 켰으면(APIKeys) 연결을 받자마자 거절해.
+// 이어 보내기는 안 돼 - accept 가 늘 0 이라 끊기면 처음부터 다시 보내 (transfer.Send 의 Retries 가 그렇게 해).
+
+// tcpIdleTimeout 프레임 사이 최대 대기 시간 - 말없이 사라진 클라이언트 정리 (transfer.Server 의 기본값과 같아)
+const tcpIdleTimeout = time.Minute
+
+// ServeTCP ln 에서 ctx 가 취소될 때까지 TCP 로 받아 - 취소되면 열린 연결도 끊어 (ln 은 ServeTCP 가 닫아)
+// Serve 가 TCPAddr 로 불러 주고, 테스트처럼 밖에서 만든 리스너로 띄울 때 직접 불러.
+func (s *Server) ServeTCP(ctx context.Context, ln net.Listener) error {
+	return serveConns(ctx, ln, s.tcpConn)
+}
+
+// serveConns 연결마다 고루틴 하나로 handle - ctx 가 취소되면 ln 과 열린 연결을 다 닫고 handle 이 끝나길 기다려 (SFTP, TCP)
+func serveConns(ctx context.Context, ln net.Listener, handle func(context.Context, net.Conn)) error {
+	var (
+		mu    sync.Mutex
+		conns = make(map[net.Conn]bool)
+		wg    sync.WaitGroup
+	)
+	closeAll := func() {
+		ln.Close()
+		mu.Lock()
+		for c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	}
+	defer context.AfterFunc(ctx, closeAll)()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			closeAll()
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		if ctx.Err() != nil { // closeAll 이 이미 돌았어
+			conn.Close()
+		}
+		conns[conn] = true
+		mu.Unlock()
+		wg.Go(func() {
+			handle(ctx, conn)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+			conn.Close()
+		})
+	}
+}
+
+// idleConn 읽을 때마다 deadline 을 미뤄
+type idleConn struct{ net.Conn }
+
+func (c idleConn) Read(p []byte) (int, error) {
+	c.SetReadDeadline(time.Now().Add(tcpIdleTimeout))
+	return c.Conn.Read(p)
+}
+
+// tcpConn 연결 하나 - offer 를 보고 받을 수 있으면 saveNamed 로 저장하고 result, 아니면 error 프레임
+func (s *Server) tcpConn(ctx context.Context, conn net.Conn) {
+	remote := conn.RemoteAddr().String()
+	id := newRequestID()
+	lg := s.cfg.Logger.With("tcp_session", id, "remote", remote)
+	br := bufio.NewReaderSize(idleConn{conn}, transfer.DefaultChunkSize+64)
+	bw := bufio.NewWriter(conn)
+
+	offer, err := transfer.ReadOffer(br)
+	if err != nil {
+		lg.Warn("TCP offer 거절", "err", err)
+		transfer.WriteError(bw, err)
+		return
+	}
+	acct := APIKey{Name: anonymous, Monthly: s.live().MonthlyQuota, Storage: s.live().StorageQuota}
+	if err := s.tcpAllow(acct, offer); err != nil {
+		lg.Warn("TCP 업로드 거절", "file", offer.Name, "size", offer.Size, "err", err)
+		transfer.WriteError(bw, err)
+		return
+	}
+	if limit := s.live().MaxUploadsPerIP; limit > 0 {
+		ip := clientIP(&http.Request{RemoteAddr: remote})
+		if !s.uploads.acquire(ip, limit) {
+			s.metrics.uploadsRejected.Inc()
+			lg.Warn("주소별 동시 업로드 초과", "client_ip", ip, "max", limit)
+			transfer.WriteError(bw, msg.Errorf("이 주소에서 동시에 올리는 업로드가 %d 개를 넘었습니다 - 하나가 끝난 뒤 다시 시도하세요", limit))
+			return
+		}
+		defer s.uploads.release(ip)
+	}
+	if err := transfer.WriteAccept(bw, 0); err != nil {
+		return
+	}
+
+	// /upload 와 같은 길 - 에러 응답은 SFTP 처럼 모아 뒀다가 error 프레임으로
+	rctx := context.WithValue(logging.WithContext(ctx, lg), accountKey{}, acct)
+	r := (&http.Request{Method: http.MethodPut, URL: &url.URL{Path: "/tcp/" + offer.Name}, Header: http.Header{}, Body: http.NoBody, RemoteAddr: remote}).WithContext(rctx)
+	w := &sftpResponse{header: http.Header{}, status: http.StatusOK}
+	start := time.Now()
+	f, ok := s.saveNamed(w, r, offer.Name, transfer.NewDataReader(br, 0, offer.Size), acct, offer.SHA256)
+	if !ok {
+		err := w.err()
+		lg.Warn("TCP 업로드 실패", "file", offer.Name, "err", err)
+		var remoteErr *transfer.RemoteError
+		if !errors.As(err, &remoteErr) {
+			transfer.WriteError(bw, err)
+		}
+		return
+	}
+	if s.usage != nil {
+		s.usage.Add(acct.Name, usage.Upload, f.Size)
+	}
+	lg.Info("TCP 업로드", "file", f.Name, "bytes", f.Size, "elapsed", time.Since(start))
+	transfer.WriteResult(bw, transfer.Result{Size: f.Size, SHA256: f.SHA256})
+}
+
+// tcpAllow offer 를 받기 전에 - 인증, 업로드 크기 제한, 이번 달 전송 한도 (저장 공간 한도는 saveFile 이 받으면서)
+func (s *Server) tcpAllow(acct APIKey, offer transfer.Offer) error {
+	live := s.live()
+	if len(live.Validators) > 0 {
+		return msg.New("인증을 켠 서버는 TCP 로 받지 않습니다 - HTTP 나 SFTP 로 올리세요")
+	}
+	if live.MaxUploadSize > 0 && offer.Size > live.MaxUploadSize {
+		return msg.Errorf("업로드 크기 제한(%d 바이트)을 넘었습니다", live.MaxUploadSize)
+	}
+	if s.usage != nil {
+		if left := s.usage.Remaining(acct.Name, acct.Monthly); left == 0 || left > 0 && offer.Size > left {
+			return msg.Errorf("이번 달 전송 한도를 넘었습니다 (%s 에 초기화)", usage.NextMonth(time.Now()).Format(time.DateOnly))
+		}
+	}
+	return nil
+}
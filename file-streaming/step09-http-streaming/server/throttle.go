@@ -0,0 +1,60 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 다운로드/업로드 속도 제한 (연결마다)
+// ⭐ Config.DownloadRate 가 서버가 허락하는 최대 속도고, ?limit=2MB 로 요청마다 더 낮출 수 있어 (높여 달라는 건 서버 값에서 멈춰).
+// step11 의 streamio.ThrottledReader 로 파일을 읽는 쪽을 늦춰서, 프록시 없이도 큰 다운로드 하나가 회선을 다 차지하지 않게.
+// 업로드는 Config.UploadRate 로 요청 본문을 읽는 쪽을 늦춰 - 서버가 덜 읽으면 TCP 창이 차서 클라이언트도 그만큼만 보내.
+// 요청 한 건이 기준이라 여러 연결로 나눠 받으면 그만큼 더 빨라 - 계정별 총량은 usage 쪽 몫이야.
+
+// downloadRate 이 요청의 초당 바이트 (0 이면 제한 없음) - ?limit 이 잘못됐으면 에러
+func (s *Server) downloadRate(r *http.Request) (int64, error) {
+	rate := s.live().DownloadRate
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return rate, nil
+	}
+	limit, err := gendata.ParseSize(v)
+	if err != nil || limit <= 0 {
+		return 0, msg.Errorf("limit 은 0 보다 큰 크기여야 합니다 (예: 2MB): %s", v)
+	}
+	if rate > 0 {
+		limit = min(limit, rate)
+	}
+	return limit, nil
+}
+
+// throttledFile ServeContent 용 - 읽기만 늦추고 Seek 은 파일 그대로 (ThrottledReader 는 버퍼가 없어서 위치가 어긋나지 않아)
+type throttledFile struct {
+	io.Reader
+	io.Seeker
+}
+
+func throttle(f io.ReadSeeker, rate int64) io.ReadSeeker {
+	if rate <= 0 {
+		return f
+	}
+	return throttledFile{Reader: streamio.NewThrottledReader(f, rate), Seeker: f}
+}
+
+// throttleBody UploadRate 가 있으면 요청 본문을 그 속도로만 읽게 (0 이면 그대로)
+func (s *Server) throttleBody(r *http.Request) {
+	rate := s.live().UploadRate
+	if rate <= 0 {
+		return
+	}
+	r.Body = throttledBody{Reader: streamio.NewThrottledReader(r.Body, rate), Closer: r.Body}
+}
+
+type throttledBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"image"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/thumb"
+)
+
+// 이미지 업로드의 썸네일 (Config.Thumbnails)
+// ⭐ 업로드가 끝나면 이름만 큐에 넣고, ThumbWorkers 개의 고루틴이 원본을 한 번 읽어서 small(128)/medium(512) JPEG 을 만들어 -
+// 큰 사진을 여러 장 올려도 줄이는 일은 그 수만큼만 동시에 돌고, 업로드 응답은 기다리지 않아. 큐가 가득 차면 그 파일은 건너뛰고,
+// /thumb 를 처음 물을 때 다시 큐에 넣어 (시작하기 전에 올라온 이미지도 이렇게 만들어져).
+//
+//	GET /thumb?file=이름&size=small|medium   → image/jpeg (아직 없으면 404 + Retry-After, 이미지가 아니면 415)
+//
+// 썸네일은 업로드 디렉토리의 .thumbs/<이름>.<크기>.jpg - 점으로 시작해서 목록에 안 나오고, 지우거나 이름을 바꾸면 따라가.
+// 원본을 바로 읽어야 해서 로컬 디렉토리 저장소에서만 돼.
+
+const (
+	thumbDir         = ".thumbs"
+	thumbQueueSize   = 64
+	defaultThumbPool = 2 // Config.ThumbWorkers 가 0 이면
+)
+
+// startThumbnailer 썸네일 큐와 고루틴들
+func (s *Server) startThumbnailer() {
+	workers := s.cfg.ThumbWorkers
+	if workers <= 0 {
+		workers = defaultThumbPool
+	}
+	s.thumbQueue = make(chan string, thumbQueueSize)
+	s.thumbPending = make(map[string]bool)
+	for range workers {
+		go s.thumbLoop()
+	}
+}
+
+func (s *Server) thumbLoop() {
+	for name := range s.thumbQueue {
+		// 만들기 시작하면 빼 - 그 사이에 다시 올라오면 또 큐에 들어가야 해
+		s.thumbMu.Lock()
+		delete(s.thumbPending, name)
+		s.thumbMu.Unlock()
+		err := s.makeThumbs(name)
+		switch {
+		case err == nil:
+			s.cfg.Logger.Debug("썸네일 만듦", "file", name)
+		case errors.Is(err, thumb.ErrNotImage), errors.Is(err, fs.ErrNotExist):
+		default:
+			s.cfg.Logger.Warn("썸네일 실패", "file", name, "err", err)
+		}
+	}
+}
+
+// queueThumb 썸네일 큐에 넣기 (꺼져 있으면 무시, 이미 기다리는 이름이면 한 번만, 가득 찼으면 건너뛰어 - 업로드를 막지 않게)
+func (s *Server) queueThumb(name string) {
+	if s.thumbQueue == nil {
+		return
+	}
+	s.thumbMu.Lock()
+	defer s.thumbMu.Unlock()
+	if s.thumbPending[name] {
+		return
+	}
+	select {
+	case s.thumbQueue <- name:
+		s.thumbPending[name] = true
+	default:
+		s.cfg.Logger.Warn("썸네일 큐가 가득 차서 건너뜀", "file", name)
+	}
+}
+
+func (s *Server) thumbPath(name string, size thumb.Size) string {
+	return filepath.Join(s.cfg.UploadDir, thumbDir, name+"."+size.Name+".jpg")
+}
+
+// makeThumbs name 을 한 번 풀어서 크기마다 썸네일로 (이미지가 아니면 예전 썸네일을 지우고 thumb.ErrNotImage)
+func (s *Server) makeThumbs(name string) error {
+	f, err := os.Open(s.uploadPath(name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(512)
+	if !thumb.Sniff(head) {
+		s.removeThumbs(name) // 이미지였던 이름을 다른 파일로 덮어썼을 수도 있어
+		return thumb.ErrNotImage
+	}
+	img, err := thumb.Decode(br, thumb.Options{})
+	if err != nil {
+		s.removeThumbs(name)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(s.cfg.UploadDir, thumbDir), 0755); err != nil {
+		return err
+	}
+	for _, size := range thumb.Sizes {
+		if err := s.writeThumb(name, size, thumb.Resize(img, size.Max)); err != nil {
+			return err
+		}
+	}
+	// 줄이는 동안 지우거나 이름을 바꿨으면 방금 쓴 건 버려 (덮어썼으면 새 파일이 큐에 들어와 있어)
+	if now, err := os.Stat(s.uploadPath(name)); err != nil || !os.SameFile(fi, now) {
+		s.removeThumbs(name)
+	}
+	return nil
+}
+
+// writeThumb 임시 파일에 쓰고 rename - /thumb 가 반쯤 쓴 JPEG 을 내보내지 않게
+func (s *Server) writeThumb(name string, size thumb.Size, img image.Image) error {
+	path := s.thumbPath(name, size)
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".thumb-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := thumb.Encode(tmp, img, thumb.Options{}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), path)
+}
+
+// removeThumbs name 의 썸네일을 지워 (없으면 그만)
+func (s *Server) removeThumbs(name string) {
+	if s.thumbQueue == nil {
+		return
+	}
+	for _, size := range thumb.Sizes {
+		if err := os.Remove(s.thumbPath(name, size)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			s.cfg.Logger.Warn("썸네일을 지우지 못함", "file", name, "size", size.Name, "err", err)
+		}
+	}
+}
+
+// renameThumbs 이름을 바꾼 파일의 썸네일도 따라 옮겨 (아직 없던 건 새 이름으로 다시 만들어)
+func (s *Server) renameThumbs(from, to string) {
+	if s.thumbQueue == nil {
+		return
+	}
+	for _, size := range thumb.Sizes {
+		if err := streamio.Rename(s.thumbPath(from, size), s.thumbPath(to, size)); err != nil {
+			s.removeThumbs(from)
+			s.queueThumb(to)
+			return
+		}
+	}
+}
+
+// thumbHandler GET /thumb?file=이름&size=small|medium
+func (s *Server) thumbHandler(w http.ResponseWriter, r *http.Request) {
+	if s.thumbQueue == nil {
+		http.Error(w, msg.T("썸네일이 꺼져 있습니다"), http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	name, ok := sanitizeFilename(q.Get("file"))
+	if !ok || name != q.Get("file") {
+		http.Error(w, msg.T("잘못된 파일명입니다"), http.StatusBadRequest)
+		return
+	}
+	size := thumb.Small
+	if v := q.Get("size"); v != "" {
+		size = thumb.Size{}
+		for _, sz := range thumb.Sizes {
+			if sz.Name == v {
+				size = sz
+			}
+		}
+		if size.Name == "" {
+			http.Error(w, msg.T("size 는 small 또는 medium 입니다"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	f, err := os.Open(s.thumbPath(name, size))
+	if err != nil {
+		s.thumbMissing(w, name)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, msg.T("썸네일을 읽을 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "no-cache") // 원본을 덮어쓰면 바뀌어 - Last-Modified 로 다시 물어봐
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", fi.ModTime(), f)
+}
+
+// thumbMissing 썸네일이 없을 때 - 원본이 없으면 404, 이미지가 아니면 415, 이미지면 만들라고 큐에 넣고 404 + Retry-After
+func (s *Server) thumbMissing(w http.ResponseWriter, name string) {
+	f, err := os.Open(s.uploadPath(name))
+	if err != nil {
+		http.Error(w, msg.T("파일을 찾을 수 없습니다"), http.StatusNotFound)
+		return
+	}
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	f.Close()
+	if !thumb.Sniff(head[:n]) {
+		http.Error(w, msg.T("썸네일을 만들 수 없는 형식입니다 (JPEG, PNG, GIF)"), http.StatusUnsupportedMediaType)
+		return
+	}
+	s.queueThumb(name)
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, msg.T("썸네일을 만드는 중입니다"), http.StatusNotFound)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"crypto/tls"
+
+	"github.com/hellotect2022go/study-go/file-streaming/transfer"
+)
+
+// HTTPS
+// ⭐ CertFile/KeyFile 을 주면 그 인증서로, TLS 만 켜면 시작할 때마다 메모리에 자체 서명 인증서를 새로 만들어 (로컬 테스트용 -
+// 브라우저는 경고를 띄우고 curl 은 -k 나 --pinnedpubkey 가 필요해, 지문은 시작 로그에 남겨).
+// 인증서는 시작할 때 한 번 읽어서 SIGHUP 으로는 안 바뀌어 (갱신하면 재시작).
+
+// loadTLS 서버 TLS 설정 (TLS 를 안 쓰면 nil)
+func (s *Server) loadTLS() (*tls.Config, error) {
+	switch {
+	case s.cfg.CertFile != "":
+		cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	case s.cfg.TLS:
+		cert, fingerprint, err := transfer.SelfSignedCert("step09-http-streaming")
+		if err != nil {
+			return nil, err
+		}
+		s.cfg.Logger.Warn("자체 서명 인증서로 HTTPS (로컬 테스트용)", "sha256", fingerprint)
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	return nil, nil
+}
+
+// Scheme "https" (TLS 를 켰거나 인증서를 줬을 때) 또는 "http"
+func (s *Server) Scheme() string {
+	if s.tls != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 요청마다 서버 스팬
+// ⭐ 클라이언트가 traceparent 헤더를 보내면 그 트레이스 아래에 붙어서, streamctl send → 서버 업로드 → 디스크 쓰기가 한 트레이스로 보여.
+// 트레이서가 설정되지 않았으면(no-op) 헤더만 한 번 읽고 그대로 넘겨.
+
+// traceHandler 요청 하나를 "HTTP 메서드 경로" 스팬으로 감싸 - 핸들러 안의 streamio.Copy 스팬이 이 아래에 붙어
+func traceHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := streamio.Tracer().Start(ctx, "HTTP "+r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("client.address", r.RemoteAddr),
+				attribute.Int64("http.request.body.size", r.ContentLength),
+			))
+		defer span.End()
+		if !span.IsRecording() {
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", rec.status),
+			attribute.Int64("http.response.body.size", rec.bytes),
+		)
+		if rec.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+// statusRecorder 응답 코드와 보낸 바이트 기록
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	failed bool // 쓰다가 에러 (전송 중에 클라이언트가 끊음)
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += int64(n)
+	s.failed = s.failed || err != nil
+	return n, err
+}
+
+// ReadFrom 감싸도 원래 ResponseWriter 의 ReadFrom(sendfile)을 그대로 쓰게
+func (s *statusRecorder) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	var err error
+	if rf, ok := s.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(s.ResponseWriter, r)
+	}
+	s.bytes += n
+	s.failed = s.failed || err != nil
+	return n, err
+}
+
+// Unwrap http.ResponseController 가 Flush 등을 원래 ResponseWriter 에서 찾게
+func (s *statusRecorder) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
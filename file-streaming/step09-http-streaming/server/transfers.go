@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 지금 오가는 전송 (GET /stats)
+// ⭐ 업로드, 다운로드, 저장소 사이 복사가 streamio.Copy 를 지나면서 부르는 훅(ProgressReader 가 읽은 만큼)으로 목록을 채워 -
+// 끝나거나 실패하면 빠지니까 언제 물어봐도 "지금 움직이는 것" 만 있어.
+//
+//	curl -H 'X-API-Key: …' http://localhost:8080/stats
+//	{"time":"…","uploads":1,"downloads":0,"copies":0,"transfers":[
+//	  {"id":"big.iso","kind":"upload","file":"big.iso","client":"10.0.0.7:51514","size":-1,"transferred":73400320,"bytes_per_sec":10485760,"started":"…","elapsed":7000000000}]}
+//
+// bytes_per_sec 는 최근 1초 남짓 동안의 속도라 속도 제한이나 느린 클라이언트가 바로 보여 (2초 넘게 한 바이트도 안 오가면 0).
+// /api/events 가 업로드 하나를 따라가는 스트림이라면 이건 서버 전체를 한 번 찍은 스냅샷이야. 파일 이름과 클라이언트 주소가 나가서 download 권한이 필요해.
+
+// 전송 종류
+const (
+	transferUpload   = "upload"
+	transferDownload = "download"
+	transferCopy     = "copy" // /api/copy, WebDAV COPY
+)
+
+const (
+	speedWindow = time.Second     // 속도를 잴 구간
+	stalledIdle = 2 * time.Second // 이만큼 진행이 없으면 속도 0
+)
+
+// activeTransfer GET /stats 의 전송 하나
+type activeTransfer struct {
+	ID          string        `json:"id"`
+	Kind        string        `json:"kind"` // upload, download, copy
+	File        string        `json:"file"`
+	Client      string        `json:"client,omitempty"` // 업로드/다운로드의 상대 주소
+	Size        int64         `json:"size"`             // 모르면 -1
+	Transferred int64         `json:"transferred"`
+	Speed       int64         `json:"bytes_per_sec"`
+	Started     time.Time     `json:"started"`
+	Elapsed     time.Duration `json:"elapsed"`
+
+	mark     time.Time // 속도 구간의 시작
+	markN    int64
+	lastSeen time.Time // 마지막 진행
+	speed    float64   // 지난 구간의 속도 (아직 한 구간도 안 지났으면 0)
+}
+
+// transferRegistry 진행 중인 전송 - 전송 하나는 같은 TransferInfo 로 시작부터 끝까지 훅을 불러
+type transferRegistry struct {
+	mu     sync.Mutex
+	active map[streamio.TransferInfo]*activeTransfer
+}
+
+func newTransferRegistry() *transferRegistry {
+	return &transferRegistry{active: make(map[streamio.TransferInfo]*activeTransfer)}
+}
+
+// track kind 의 file 전송을 목록에 올리는 훅 - copyOptions 의 훅과 MultiHooks 로 묶어서 써
+func (t *transferRegistry) track(kind, file string) streamio.Hooks {
+	return trackedHooks{t: t, kind: kind, file: file}
+}
+
+type trackedHooks struct {
+	streamio.NopHooks
+	t    *transferRegistry
+	kind string
+	file string
+}
+
+func (h trackedHooks) OnStart(info streamio.TransferInfo) {
+	now := time.Now()
+	a := &activeTransfer{ID: info.ID, Kind: h.kind, File: h.file, Size: info.Size, Started: now, mark: now, lastSeen: now}
+	switch h.kind {
+	case transferUpload:
+		a.Client = info.Src
+	case transferDownload:
+		a.Client = info.Dst
+	}
+	h.t.mu.Lock()
+	h.t.active[info] = a
+	h.t.mu.Unlock()
+}
+
+func (h trackedHooks) OnProgress(info streamio.TransferInfo, transferred int64) {
+	now := time.Now()
+	h.t.mu.Lock()
+	defer h.t.mu.Unlock()
+	a := h.t.active[info]
+	if a == nil {
+		return
+	}
+	a.Transferred, a.lastSeen = transferred, now
+	if d := now.Sub(a.mark); d >= speedWindow {
+		a.speed = float64(transferred-a.markN) / d.Seconds()
+		a.mark, a.markN = now, transferred
+	}
+}
+
+func (h trackedHooks) OnComplete(info streamio.TransferInfo, transferred int64, elapsed time.Duration) {
+	h.t.done(info)
+}
+
+func (h trackedHooks) OnError(info streamio.TransferInfo, err error) {
+	h.t.done(info)
+}
+
+func (t *transferRegistry) done(info streamio.TransferInfo) {
+	t.mu.Lock()
+	delete(t.active, info)
+	t.mu.Unlock()
+}
+
+// snapshot 지금 진행 중인 전송들 (오래된 것부터)
+func (t *transferRegistry) snapshot(now time.Time) []activeTransfer {
+	t.mu.Lock()
+	out := make([]activeTransfer, 0, len(t.active))
+	for _, a := range t.active {
+		c := *a
+		c.Elapsed = now.Sub(a.Started)
+		switch {
+		case now.Sub(a.lastSeen) > stalledIdle:
+			c.Speed = 0
+		case a.mark.Equal(a.Started) && c.Elapsed > 0:
+			// 아직 한 구간도 안 지났으면 시작부터의 평균
+			c.Speed = int64(float64(a.Transferred) / c.Elapsed.Seconds())
+		default:
+			c.Speed = int64(a.speed)
+		}
+		out = append(out, c)
+	}
+	t.mu.Unlock()
+	slices.SortFunc(out, func(a, b activeTransfer) int { return a.Started.Compare(b.Started) })
+	return out
+}
+
+// statsHandler GET /stats - 지금 진행 중인 전송
+func (s *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	now := time.Now()
+	transfers := s.transfers.snapshot(now)
+	count := map[string]int{}
+	for _, a := range transfers {
+		count[a.Kind]++
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(struct {
+		Time      time.Time        `json:"time"`
+		Uploads   int              `json:"uploads"`
+		Downloads int              `json:"downloads"`
+		Copies    int              `json:"copies"`
+		Transfers []activeTransfer `json:"transfers"`
+	}{now, count[transferUpload], count[transferDownload], count[transferCopy], transfers})
+}
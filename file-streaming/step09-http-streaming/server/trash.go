@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// 휴지통 (Config.TrashDir, Config.TrashRetention) - 지운 파일을 되살려
+// ⭐ 삭제(/delete, DELETE /api/files/<이름>, WebDAV, SFTP)는 파일을 TrashDir 로 옮기고 원래 경로를 사이드카에 적어 둬 (fstree.Trash).
+// 그 뒤 TrashRetention 동안은 되살릴 수 있고, 지나면 만료 청소(runExpiry)가 ExpireScan 마다 영구 삭제해:
+//
+//	GET  /api/trash                   → 200 {"items": [{"id", "name", "size", "deleted_at", "purge_at"}]} (최근에 지운 것부터)
+//	POST /api/trash/<ID>/restore      → 200 {"file", "trash_id", "size"} - 그 이름에 파일이 이미 있으면 409
+//
+// trash 도구나 dirsync 가 같은 디렉토리를 같이 써도, 이 서버의 업로드 디렉토리에서 온 항목만 보이고 지워.
+// 되살린 파일은 되살린 계정의 저장 공간으로 세고(넘으면 413), 중복 제거 색인에는 다시 넣지 않아 (평범한 파일).
+// 둘 다 delete 권한이 필요하고, 되살리기는 감사 로그에 restore 로 남아. 로컬 디렉토리 저장소에서만 (다른 저장소는 바로 지워서 404).
+
+// trashEntry /api/trash 의 항목 하나 - 서버의 실제 경로 대신 업로드 이름만 보여줘
+type trashEntry struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at,omitzero"` // 이때 영구 삭제돼 (TrashRetention 이 0 이면 빠져)
+}
+
+// trashName 휴지통 항목이 이 서버의 업로드 디렉토리에서 온 파일이면 그 이름
+func (s *Server) trashName(item fstree.TrashItem) (string, bool) {
+	dir, err := filepath.Abs(s.cfg.UploadDir)
+	if err != nil || item.IsDir || filepath.Dir(item.OriginalPath) != dir {
+		return "", false
+	}
+	base := filepath.Base(item.OriginalPath)
+	name, ok := sanitizeFilename(base)
+	return name, ok && name == base
+}
+
+// trashItems 이 서버가 옮겨 둔 휴지통 항목 (최근에 지운 것부터)
+func (s *Server) trashItems() ([]trashEntry, error) {
+	items, err := s.trash.List()
+	if err != nil {
+		return nil, err
+	}
+	retention := s.live().TrashRetention
+	out := []trashEntry{}
+	for _, item := range items {
+		name, ok := s.trashName(item)
+		if !ok {
+			continue
+		}
+		e := trashEntry{ID: item.ID, Name: name, Size: item.Size, DeletedAt: item.DeletedAt}
+		if retention > 0 {
+			e.PurgeAt = item.DeletedAt.Add(retention)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// trashHandler /api/trash 와 /api/trash/<ID>/restore
+func (s *Server) trashHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.localDir() {
+		http.Error(w, msg.T("휴지통을 쓰지 않는 저장소입니다"), http.StatusNotFound)
+		return
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/trash"), "/")
+	if rest == "" {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+			return
+		}
+		items, err := s.trashItems()
+		if err != nil {
+			s.logger(r).ErrorContext(r.Context(), "휴지통 목록을 읽지 못함", "err", err)
+			http.Error(w, msg.T("휴지통 목록을 읽을 수 없습니다"), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(struct {
+			Items []trashEntry `json:"items"`
+		}{items})
+		return
+	}
+
+	id, action, _ := strings.Cut(rest, "/")
+	if id == "" || action != "restore" {
+		http.Error(w, msg.T("잘못된 휴지통 경로입니다"), http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	s.restoreTrash(w, r, id)
+}
+
+// restoreTrash 휴지통 항목 id 를 원래 이름으로 되살려
+func (s *Server) restoreTrash(w http.ResponseWriter, r *http.Request, id string) {
+	item, err := s.trash.Item(id)
+	name, ok := s.trashName(item)
+	if err != nil || !ok {
+		http.Error(w, msg.T("휴지통 항목을 찾을 수 없습니다"), http.StatusNotFound)
+		return
+	}
+	acct := s.account(r)
+
+	unlock := streamio.LockPath(s.uploadPath(name))
+	if room := s.storageRoom(acct, name); room >= 0 && item.Size > room {
+		err = usage.ErrStorageQuota
+	} else {
+		_, err = s.trash.Restore(id)
+	}
+	unlock()
+
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, fstree.ErrRestoreConflict):
+		status = http.StatusConflict
+	case errors.Is(err, fs.ErrNotExist):
+		status = http.StatusNotFound // 그사이 다른 요청이 되살렸거나 영구 삭제됐어
+	case errors.Is(err, usage.ErrStorageQuota):
+		status = http.StatusRequestEntityTooLarge
+	case err != nil:
+		status = http.StatusInternalServerError
+	}
+	s.audit(w, r, auditEntry{Action: AuditRestore, File: name, TrashID: id, Status: status}, err)
+	switch status {
+	case http.StatusOK:
+	case http.StatusConflict:
+		http.Error(w, msg.T("같은 이름의 파일이 이미 있습니다"), status)
+		return
+	case http.StatusNotFound:
+		http.Error(w, msg.T("휴지통 항목을 찾을 수 없습니다"), status)
+		return
+	case http.StatusRequestEntityTooLarge:
+		s.storageFull(w, r, acct, name, item.Size)
+		return
+	default:
+		s.logger(r).ErrorContext(r.Context(), "휴지통에서 되살리기 실패", "file", name, "trash_id", id, "err", err)
+		http.Error(w, msg.T("휴지통에서 되살리기 실패"), status)
+		return
+	}
+
+	s.storePut(r, name, acct.Name, item.Size)
+	s.queueIndex(s.uploadPath(name), false)
+	s.queueThumb(name)
+	s.logger(r).InfoContext(r.Context(), "휴지통에서 되살림", "file", name, "trash_id", id, "bytes", item.Size)
+	w.Header().Set("Content-Location", "/download?file="+url.QueryEscape(name))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(struct {
+		File    string `json:"file"`
+		TrashID string `json:"trash_id"`
+		Size    int64  `json:"size"`
+	}{name, id, item.Size})
+}
+
+// purgeTrash 보관 기간(TrashRetention)이 지난 휴지통 항목을 영구 삭제하고 되찾은 바이트를 로그에 (runExpiry 가 불러)
+func (s *Server) purgeTrash(ctx context.Context, now time.Time) {
+	retention := s.live().TrashRetention
+	if retention <= 0 || !s.localDir() {
+		return
+	}
+	items, err := s.trashItems()
+	if err != nil {
+		s.cfg.Logger.Error("휴지통 목록을 읽지 못함", "err", err)
+		return
+	}
+	var purged, reclaimed int64
+	for _, e := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		if e.PurgeAt.After(now) {
+			continue
+		}
+		if err := s.trash.Discard(e.ID); err != nil {
+			s.cfg.Logger.Error("휴지통 항목을 지우지 못함", "file", e.Name, "trash_id", e.ID, "err", err)
+			continue
+		}
+		purged++
+		reclaimed += e.Size
+		s.cfg.Logger.Debug("휴지통 항목 영구 삭제", "file", e.Name, "trash_id", e.ID, "deleted_at", e.DeletedAt)
+	}
+	if purged > 0 {
+		s.cfg.Logger.Info("휴지통 정리", "files", purged, "reclaimed", reclaimed)
+	}
+}
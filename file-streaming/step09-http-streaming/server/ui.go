@@ -0,0 +1,259 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 내장 웹 UI
+// ⭐ ui/ 아래 정적 파일을 바이너리에 embed 해서, 서버를 어느 디렉토리에서 띄워도 / 에 같은 화면이 떠 (index.html 을 옆에 둘 필요 없음).
+// 화면은 서버 API 만 써: 목록은 /api/files (정렬도 서버가), 업로드는 /api/uploads 로 8MB 씩 나눠 보내서 끊기면 받은 데부터,
+// 진행률은 /api/events (SSE - 이벤트 ID 가 세션 ID 야), 다운로드는 /range-download 로 끊기면 이어받기.
+// IndexFile 을 주면 그 파일이 내장 화면 대신 / 에 나와 (직접 만든 페이지로 바꿔 끼울 때).
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiFS ui/ 를 루트로 (index.html, app.js, style.css)
+func uiFS() fs.FS {
+	sub, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err) // embed 경로가 고정이라 일어날 수 없어
+	}
+	return sub
+}
+
+// uiHandler "/" 는 화면, "/ui/..." 는 정적 파일 - 나머지 경로는 404
+func (s *Server) uiHandler() http.Handler {
+	assets := http.StripPrefix("/ui", http.FileServerFS(uiFS()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/" && s.live().IndexFile != "":
+			http.ServeFile(w, r, s.live().IndexFile)
+		case r.URL.Path == "/":
+			http.ServeFileFS(w, r, uiFS(), "index.html")
+		case strings.HasPrefix(r.URL.Path, "/ui/"):
+			assets.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// fileEntry /api/files 응답의 파일 하나
+type fileEntry struct {
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	SHA256  string    `json:"sha256,omitempty"` // 해시를 못 읽었으면 (읽는 사이 지워짐 등) 빈 값
+}
+
+// filesResponse /api/files 응답 - count, bytes 는 페이지가 아니라 디렉토리 전체
+type filesResponse struct {
+	Count  int         `json:"count"`
+	Bytes  int64       `json:"bytes"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"` // 0 이면 끝까지
+	Files  []fileEntry `json:"files"`
+}
+
+// maxFilesLimit limit 의 최댓값
+const maxFilesLimit = 1000
+
+// fileSorts ?sort= 로 고를 수 있는 정렬 기준
+var fileSorts = map[string]func(a, b fileEntry) int{
+	"name":  func(a, b fileEntry) int { return strings.Compare(a.Name, b.Name) },
+	"size":  func(a, b fileEntry) int { return cmp.Compare(a.Size, b.Size) },
+	"mtime": func(a, b fileEntry) int { return a.ModTime.Compare(b.ModTime) },
+}
+
+// 파일 목록 핸들러 - GET /api/files?sort=name|size|mtime&order=asc|desc&offset=0&limit=100
+// ⭐ 정렬과 자르기는 stat 만으로 하고, sha256 은 돌려줄 페이지의 파일만 읽어서 재 - 크기/수정 시각이 그대로면 전에 잰 값을 다시 써.
+// 받는 중인 임시 파일과 숨김 파일은 빼.
+func (s *Server) filesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	sortBy := cmp.Or(q.Get("sort"), "name")
+	compare, ok := fileSorts[sortBy]
+	if !ok {
+		http.Error(w, msg.T("sort 는 name, size, mtime 중 하나입니다"), http.StatusBadRequest)
+		return
+	}
+	order := cmp.Or(q.Get("order"), "asc")
+	if order != "asc" && order != "desc" {
+		http.Error(w, msg.T("order 는 asc 또는 desc 입니다"), http.StatusBadRequest)
+		return
+	}
+	offset, err1 := queryInt(q, "offset")
+	limit, err2 := queryInt(q, "limit")
+	if err1 != nil || err2 != nil || limit > maxFilesLimit {
+		http.Error(w, msg.Sprintf("offset, limit 은 0 이상의 정수입니다 (limit 은 최대 %d)", maxFilesLimit), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := s.backend.List(r.Context(), "")
+	if err != nil {
+		s.logger(r).ErrorContext(r.Context(), "목록 읽기 실패", "dir", s.cfg.UploadDir, "err", err)
+		http.Error(w, msg.T("목록을 읽을 수 없습니다"), http.StatusInternalServerError)
+		return
+	}
+
+	all := []fileEntry{}
+	var total int64
+	for _, e := range entries {
+		// 업로드 임시 파일(.이름.upload-*)도 점으로 시작해
+		if strings.HasPrefix(e.Name(), ".") || !e.Mode().IsRegular() {
+			continue
+		}
+		all = append(all, fileEntry{
+			Name:    e.Name(),
+			URL:     "/download?file=" + url.QueryEscape(e.Name()),
+			Size:    e.Size(),
+			ModTime: e.ModTime(),
+		})
+		total += e.Size()
+	}
+	s.hashes.prune(all)
+
+	slices.SortStableFunc(all, func(a, b fileEntry) int {
+		if c := compare(a, b); c != 0 {
+			if order == "desc" {
+				return -c
+			}
+			return c
+		}
+		return strings.Compare(a.Name, b.Name) // 크기/시각이 같으면 이름순으로 고정
+	})
+	page := all[min(offset, len(all)):]
+	if limit > 0 {
+		page = page[:min(limit, len(page))]
+	}
+	for i := range page {
+		if r.Context().Err() != nil {
+			return // 클라이언트가 끊음
+		}
+		page[i].SHA256 = s.hashes.sum(r.Context(), s.backend, page[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(filesResponse{Count: len(all), Bytes: total, Offset: offset, Limit: limit, Files: page})
+}
+
+// queryInt 0 이상의 정수 쿼리 값 (없으면 0)
+func queryInt(q url.Values, key string) (int, error) {
+	v := q.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err == nil && n < 0 {
+		err = strconv.ErrRange
+	}
+	return n, err
+}
+
+// hashCache 파일 이름별로 마지막에 잰 sha256 (크기나 수정 시각이 바뀌면 다시 재)
+type hashCache struct {
+	mu    sync.Mutex
+	files map[string]cachedHash
+}
+
+type cachedHash struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// sum known 으로 찾고, 없으면 저장소에서 읽어서 재 (못 읽으면 "")
+func (c *hashCache) sum(ctx context.Context, st storage.Storage, e fileEntry) string {
+	if sum := c.known(st, e); sum != "" {
+		return sum
+	}
+	sum := readSHA256(ctx, st, e.Name)
+	if sum != "" {
+		c.put(e.Name, e.Size, e.ModTime, sum)
+	}
+	return sum
+}
+
+// known 파일을 읽지 않고 아는 sha256 - 캐시에 맞는 값, 없으면 파일의 체크섬 속성(업로드할 때 남긴 값, 로컬 디렉토리만) (모르면 "")
+func (c *hashCache) known(st storage.Storage, e fileEntry) string {
+	c.mu.Lock()
+	h, ok := c.files[e.Name]
+	c.mu.Unlock()
+	if ok && h.size == e.Size && h.modTime.Equal(e.ModTime) {
+		return h.sum
+	}
+	dir, ok := st.(storage.Dir)
+	if !ok {
+		return ""
+	}
+	rec, ok, _ := fstree.XattrChecksums().Get(dir.Path(e.Name))
+	if !ok || rec.Size != e.Size || !rec.ModTime.Equal(e.ModTime) {
+		return ""
+	}
+	c.put(e.Name, e.Size, e.ModTime, rec.SHA256)
+	return rec.SHA256
+}
+
+// readSHA256 저장소의 name 을 끝까지 읽어서 잰 sha256 (못 읽으면 "")
+func readSHA256(ctx context.Context, st storage.Storage, name string) string {
+	f, err := st.Open(ctx, name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.CopyBuffer(h, f, make([]byte, streamio.DefaultBufferSize)); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// put name 의 sha256 을 기록 (업로드하면서 잰 값을 바로 넣을 때도)
+func (c *hashCache) put(name string, size int64, modTime time.Time, sum string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.files == nil {
+		c.files = make(map[string]cachedHash)
+	}
+	c.files[name] = cachedHash{size: size, modTime: modTime, sum: sum}
+}
+
+// prune 목록에 없는(지워진) 파일의 기록을 버려
+func (c *hashCache) prune(files []fileEntry) {
+	keep := make(map[string]bool, len(files))
+	for _, f := range files {
+		keep[f.Name] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.files {
+		if !keep[name] {
+			delete(c.files, name)
+		}
+	}
+}
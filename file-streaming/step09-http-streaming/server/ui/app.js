@@ -0,0 +1,381 @@
+// Go 파일 서버 내장 UI
+// ⭐ 서버 API 만 써: 목록 /api/files, 업로드 /api/uploads (tus, 조각마다 PATCH), 진행률 /api/events (SSE), 이어받기 /range-download, 삭제 /delete
+'use strict';
+
+const $ = (sel) => document.querySelector(sel);
+
+// ---------- 공통 ----------
+
+function formatBytes(n) {
+    if (n < 1024) return n + ' B';
+    const units = ['KB', 'MB', 'GB', 'TB'];
+    let i = -1;
+    do { n /= 1024; i++; } while (n >= 1024 && i < units.length - 1);
+    return n.toFixed(n < 10 ? 1 : 0) + ' ' + units[i];
+}
+
+// transferRow 업로드/다운로드 한 줄 (이름, 상태 문구, 버튼, 진행 막대)
+function transferRow(list, name) {
+    const li = $('#transfer-row').content.firstElementChild.cloneNode(true);
+    li.querySelector('.name').textContent = name;
+    list.prepend(li);
+    return {
+        li,
+        status(text, cls) {
+            li.querySelector('.status').textContent = text;
+            li.className = cls || '';
+        },
+        progress(done, total) {
+            const bar = li.querySelector('progress');
+            if (total > 0) {
+                bar.value = Math.min(100, (done / total) * 100);
+            } else {
+                bar.removeAttribute('value'); // 크기를 모르면 움직이는 막대
+            }
+        },
+        button(label, onClick) {
+            const btn = document.createElement('button');
+            btn.type = 'button';
+            btn.textContent = label;
+            btn.addEventListener('click', onClick);
+            li.querySelector('.actions').append(btn);
+            return btn;
+        },
+    };
+}
+
+// ---------- 파일 목록 ----------
+
+let files = [];
+const sorting = { sort: 'name', order: 'asc' }; // 정렬은 서버가 (/api/files?sort=&order=)
+
+async function loadFiles() {
+    try {
+        const resp = await fetch(`/api/files?sort=${sorting.sort}&order=${sorting.order}`, { cache: 'no-store' });
+        if (!resp.ok) throw new Error(await resp.text());
+        const body = await resp.json();
+        files = body.files;
+        $('#summary').textContent = `${body.count}개, ${formatBytes(body.bytes)}`;
+    } catch (err) {
+        $('#summary').textContent = '목록을 읽지 못했어: ' + err.message;
+        files = [];
+    }
+    renderFiles();
+}
+
+function renderFiles() {
+    const q = $('#filter').value.trim().toLowerCase();
+    const tbody = $('#file-list');
+    tbody.replaceChildren();
+    const shown = files.filter((f) => !q || f.name.toLowerCase().includes(q));
+    for (const f of shown) {
+        const tr = document.createElement('tr');
+
+        const name = document.createElement('td');
+        const link = document.createElement('a');
+        link.href = f.url;
+        link.textContent = f.name;
+        name.append(link);
+
+        const size = document.createElement('td');
+        size.className = 'num';
+        size.textContent = formatBytes(f.size);
+
+        const mtime = document.createElement('td');
+        mtime.textContent = new Date(f.mtime).toLocaleString();
+
+        const actions = document.createElement('td');
+        actions.className = 'actions';
+        const get = document.createElement('button');
+        get.type = 'button';
+        get.textContent = '받기';
+        get.title = '끊기면 이어받기';
+        get.addEventListener('click', () => startDownload(f));
+        const del = document.createElement('button');
+        del.type = 'button';
+        del.className = 'danger';
+        del.textContent = '삭제';
+        del.addEventListener('click', () => deleteFile(f.name));
+        actions.append(get, del);
+
+        tr.append(name, size, mtime, actions);
+        tbody.append(tr);
+    }
+    $('#empty').hidden = files.length > 0;
+    for (const th of document.querySelectorAll('th[data-sort]')) {
+        th.dataset.order = th.dataset.sort === sorting.sort ? sorting.order : '';
+    }
+}
+
+// 제목을 누르면 그 열로 정렬 - 같은 열을 다시 누르면 반대로
+function setupSort() {
+    for (const th of document.querySelectorAll('th[data-sort]')) {
+        th.addEventListener('click', () => {
+            const same = sorting.sort === th.dataset.sort;
+            sorting.order = same && sorting.order === 'asc' ? 'desc' : 'asc';
+            sorting.sort = th.dataset.sort;
+            loadFiles();
+        });
+    }
+}
+
+async function deleteFile(name) {
+    if (!confirm(`${name} 을(를) 휴지통으로 옮길까?`)) return;
+    const resp = await fetch('/delete?file=' + encodeURIComponent(name), { method: 'DELETE' });
+    if (!resp.ok) alert('삭제 실패: ' + (await resp.text()));
+    loadFiles();
+}
+
+// ---------- 업로드 (조각으로 나눠 보내고, 진행률은 SSE) ----------
+
+const chunkSize = 8 << 20; // PATCH 한 번에 보낼 크기 - 끊기면 이 조각만 다시 보내
+const uploads = new Map(); // 세션 ID → { row, size, base(이번 조각의 시작 위치) }
+
+// ⭐ EventSource 하나로 모든 업로드 이벤트를 받아서 ID 로 나눠 - 다른 탭/사용자 업로드가 끝나도 목록이 새로 고쳐져
+// 이어 올리기의 이벤트 ID 는 세션 ID 고, bytes 는 이번 PATCH 에서 받은 만큼이야
+function listenEvents() {
+    const es = new EventSource('/api/events');
+    const handle = (e) => {
+        const ev = JSON.parse(e.data);
+        const up = uploads.get(ev.id);
+        if (!up) {
+            if (ev.type === 'complete') loadFiles();
+            return;
+        }
+        switch (ev.type) {
+        case 'start':
+        case 'progress':
+            up.row.progress(up.base + ev.bytes, up.size);
+            up.row.status(`${formatBytes(up.base + ev.bytes)} / ${formatBytes(up.size)}`);
+            break;
+        case 'error':
+            up.row.status('끊김: ' + ev.error, 'error');
+            break;
+        }
+    };
+    for (const type of ['start', 'progress', 'complete', 'error']) {
+        es.addEventListener(type, handle);
+    }
+    // 연결이 끊기면 EventSource 가 알아서 다시 붙어 - 그동안의 progress 는 놓치지만 PATCH 응답의 Upload-Offset 으로 따라잡아
+}
+
+// responseError 실패 응답의 문구 (413 같은 JSON 은 error 필드)
+async function responseError(resp) {
+    const text = (await resp.text()).trim();
+    try {
+        return JSON.parse(text).error || text;
+    } catch {
+        return text || resp.statusText;
+    }
+}
+
+// tus 요청 - 모든 요청에 Tus-Resumable 을 붙여
+function tus(url, method, headers = {}, body, signal) {
+    return fetch(url, { method, body, signal, headers: { 'Tus-Resumable': '1.0.0', ...headers } });
+}
+
+async function upload(file) {
+    const row = transferRow($('#uploads'), file.name);
+    row.status('대기 중');
+    row.progress(0, file.size);
+
+    const ctrl = new AbortController();
+    let loc = '';
+    const cancel = row.button('취소', () => ctrl.abort());
+    try {
+        const created = await tus('/api/uploads?file=' + encodeURIComponent(file.name), 'POST', { 'Upload-Length': String(file.size) }, null, ctrl.signal);
+        if (created.status !== 201) throw new Error(await responseError(created));
+        loc = created.headers.get('Location');
+        const up = { row, size: file.size, base: 0 };
+        uploads.set(loc.split('/').pop(), up);
+
+        let saved = created.headers.get('Content-Location'); // 빈 파일은 만들 때 바로 저장돼
+        for (let attempt = 0; !saved && up.base < file.size;) {
+            try {
+                const resp = await tus(loc, 'PATCH', {
+                    'Upload-Offset': String(up.base),
+                    'Content-Type': 'application/offset+octet-stream',
+                }, file.slice(up.base, up.base + chunkSize), ctrl.signal);
+                const offset = Number(resp.headers.get('Upload-Offset'));
+                if (resp.status === 409 && offset < file.size && resp.headers.has('Upload-Offset')) {
+                    up.base = offset; // 서버가 받은 위치와 어긋남 - 알려준 위치부터 다시
+                    continue;
+                }
+                if (resp.status !== 204) {
+                    const err = new Error(await responseError(resp));
+                    err.fatal = resp.status < 500; // 4xx 는 다시 보내도 같아 (크기 한도, 정책, 같은 이름 거절 …)
+                    throw err;
+                }
+                up.base = offset;
+                saved = resp.headers.get('Content-Location');
+                attempt = 0;
+                row.progress(up.base, file.size);
+                row.status(`${formatBytes(up.base)} / ${formatBytes(file.size)}`);
+            } catch (err) {
+                if (err.name === 'AbortError' || err.fatal || ++attempt > maxRetries) throw err;
+                const wait = Math.min(30, 2 ** (attempt - 1));
+                row.status(`끊김 - ${wait}초 뒤 ${formatBytes(up.base)} 부터 이어 올리기 (${attempt}/${maxRetries})`, 'error');
+                await new Promise((r) => setTimeout(r, wait * 1000));
+                // 서버가 어디까지 받았는지 물어보고 거기서부터 (끊긴 조각도 받은 데까지는 남아 있어)
+                const head = await tus(loc, 'HEAD', {}, null, ctrl.signal);
+                if (head.ok) up.base = Number(head.headers.get('Upload-Offset'));
+            }
+        }
+        if (!saved) throw new Error('서버가 저장한 이름을 알려주지 않았어');
+        // 같은 이름이 있어서 서버가 새 이름(-v2, -<uuid>)으로 저장했으면 그 이름도 보여줘
+        const name = new URLSearchParams(saved.split('?')[1]).get('file');
+        row.progress(1, 1);
+        row.status('완료 - ' + formatBytes(file.size) + (name !== file.name ? ` (${name} 로 저장)` : ''), 'done');
+    } catch (err) {
+        row.status(err.name === 'AbortError' ? '취소됨' : '실패: ' + err.message, 'error');
+        // 받아 둔 조각은 버려 (아직 PATCH 를 정리하는 중이라 423 이면 서버가 하루 뒤에 지워)
+        if (loc) tus(loc, 'DELETE').catch(() => {});
+    } finally {
+        cancel.remove();
+        if (loc) uploads.delete(loc.split('/').pop());
+        loadFiles();
+    }
+}
+
+function setupUpload() {
+    const input = $('#upload-input');
+    const drop = $('#drop');
+    input.addEventListener('change', () => {
+        for (const f of input.files) upload(f);
+        input.value = '';
+    });
+    drop.addEventListener('dragover', (e) => { e.preventDefault(); drop.classList.add('over'); });
+    drop.addEventListener('dragleave', () => drop.classList.remove('over'));
+    drop.addEventListener('drop', (e) => {
+        e.preventDefault();
+        drop.classList.remove('over');
+        for (const f of e.dataTransfer.files) upload(f);
+    });
+}
+
+// ---------- 이어받기 다운로드 ----------
+
+const maxRetries = 8;
+
+// sink 받은 조각을 어디에 쌓을지
+// ⭐ File System Access API 가 있으면(크로미움) 고른 파일에 바로 써서 큰 파일도 메모리에 안 올리고,
+// 없으면 조각을 메모리에 모았다가 끝나면 Blob 링크로 저장해.
+async function openSink(name) {
+    if (window.showSaveFilePicker) {
+        const handle = await window.showSaveFilePicker({ suggestedName: name });
+        const writable = await handle.createWritable();
+        return {
+            write: (data, position) => writable.write({ type: 'write', position, data }),
+            reset: () => writable.truncate(0),
+            finish: () => writable.close(),
+            abort: () => writable.abort(),
+        };
+    }
+    let parts = [];
+    return {
+        write: async (data) => { parts.push(data); },
+        reset: async () => { parts = []; },
+        finish: async () => {
+            const url = URL.createObjectURL(new Blob(parts));
+            const a = document.createElement('a');
+            a.href = url;
+            a.download = name;
+            a.click();
+            setTimeout(() => URL.revokeObjectURL(url), 60000);
+            parts = [];
+        },
+        abort: async () => { parts = []; },
+    };
+}
+
+async function startDownload(f) {
+    let sink;
+    try {
+        sink = await openSink(f.name);
+    } catch (err) {
+        if (err.name !== 'AbortError') alert('저장 위치를 열 수 없어: ' + err.message);
+        return;
+    }
+    $('#downloads-section').hidden = false;
+    const row = transferRow($('#downloads'), f.name);
+    const dl = { f, sink, row, offset: 0, validator: '', paused: false, ctrl: null, run: 0 };
+
+    const pause = row.button('일시정지', () => {
+        dl.paused = !dl.paused;
+        pause.textContent = dl.paused ? '이어받기' : '일시정지';
+        if (dl.paused) {
+            dl.ctrl?.abort();
+        } else {
+            runDownload(dl);
+        }
+    });
+    dl.done = () => pause.remove();
+    runDownload(dl);
+}
+
+// runDownload offset 부터 끝까지 - 끊기면 잠깐 쉬었다가 받은 곳부터 다시 (If-Range 로 그 사이 파일이 바뀌면 처음부터)
+async function runDownload(dl) {
+    const { f, sink, row } = dl;
+    const run = ++dl.run; // 쉬는 동안 일시정지 → 이어받기를 누르면 예전 루프는 여기서 빠져
+    for (let attempt = 0; !dl.paused && run === dl.run; attempt++) {
+        dl.ctrl = new AbortController();
+        try {
+            const headers = {};
+            if (dl.offset > 0) {
+                headers['Range'] = `bytes=${dl.offset}-`;
+                if (dl.validator) headers['If-Range'] = dl.validator;
+            }
+            const resp = await fetch('/range-download?file=' + encodeURIComponent(f.name), { headers, signal: dl.ctrl.signal });
+            if (resp.status === 200 && dl.offset > 0) {
+                // 서버 파일이 바뀌어서 전체가 다시 옴 - 받아 둔 조각은 버려
+                await sink.reset();
+                dl.offset = 0;
+            } else if (resp.status !== 200 && resp.status !== 206) {
+                throw new Error(`${resp.status} ${(await resp.text()).trim()}`);
+            }
+            dl.validator = resp.headers.get('Last-Modified') || dl.validator;
+            const total = dl.offset + Number(resp.headers.get('Content-Length') || 0);
+
+            const reader = resp.body.getReader();
+            for (;;) {
+                const { done, value } = await reader.read();
+                if (done) break;
+                await sink.write(value, dl.offset);
+                dl.offset += value.length;
+                attempt = 0; // 조금이라도 받았으면 재시도 횟수는 처음부터
+                row.progress(dl.offset, total);
+                row.status(`${formatBytes(dl.offset)} / ${formatBytes(total)}`);
+            }
+            await sink.finish();
+            row.progress(1, 1);
+            row.status('완료 - ' + formatBytes(dl.offset), 'done');
+            dl.done();
+            return;
+        } catch (err) {
+            if (dl.paused) {
+                row.status(`일시정지 - ${formatBytes(dl.offset)} 받음`);
+                return;
+            }
+            if (attempt >= maxRetries) {
+                await sink.abort();
+                row.status('실패: ' + err.message, 'error');
+                dl.done();
+                return;
+            }
+            const wait = Math.min(30, 2 ** attempt);
+            row.status(`끊김 - ${wait}초 뒤 ${formatBytes(dl.offset)} 부터 이어받기 (${attempt + 1}/${maxRetries})`, 'error');
+            await new Promise((r) => setTimeout(r, wait * 1000));
+        }
+    }
+}
+
+// ---------- 시작 ----------
+
+document.addEventListener('DOMContentLoaded', () => {
+    setupUpload();
+    setupSort();
+    $('#filter').addEventListener('input', renderFiles);
+    $('#refresh').addEventListener('click', loadFiles);
+    listenEvents();
+    loadFiles();
+});
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 클라이언트 주소별 동시 업로드 수 제한 (Config.MaxUploadsPerIP)
+// ⭐ 업로드는 받는 동안 연결, 임시 파일 디스크립터, 디스크 쓰기를 하나씩 붙잡아 - 한 클라이언트가 수백 개를 한꺼번에 열면 다른 사람 몫이 없어.
+// 그래서 주소(RemoteAddr 의 IP)마다 본문을 받는 중인 요청을 MaxUploadsPerIP 개까지만 두고, 넘으면 기다리지 않고 바로 429 + Retry-After.
+// 다운로드(limit.go)처럼 줄을 세우지 않는 건 - 업로드는 클라이언트가 보낼 양을 쥐고 있어서, 줄에서 기다리는 동안에도 연결이 묶여 있기 때문이야.
+// /upload, /upload/<이름>, /api/uploads, /api/multipart, /api/extract, /upload-archive 와 WebDAV 의 PUT 에 걸려 -
+// 본문이 있는 POST, PUT, PATCH 만 세서 이어 올리기의 HEAD, 받은 범위 조회(빈 PUT), 세션 만들기는 막히지 않아. 설정은 SIGHUP 으로 바로 바뀌어.
+
+// ipUploads 주소별 받는 중인 업로드 수
+type ipUploads struct {
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// acquire ip 의 업로드가 limit 개보다 적으면 하나 늘리고 true
+func (u *ipUploads) acquire(ip string, limit int) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.active[ip] >= limit {
+		return false
+	}
+	if u.active == nil {
+		u.active = make(map[string]int)
+	}
+	u.active[ip]++
+	return true
+}
+
+// release ip 의 업로드 하나가 끝났어 (0 이 되면 지워서 지나간 주소가 쌓이지 않게)
+func (u *ipUploads) release(ip string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.active[ip]--; u.active[ip] <= 0 {
+		delete(u.active, ip)
+	}
+}
+
+// limitUploads 본문이 있는 업로드 요청이면 클라이언트 주소의 자리를 잡고 next 로 (MaxUploadsPerIP 개가 다 찼으면 429)
+func (s *Server) limitUploads(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := s.live().MaxUploadsPerIP
+		if limit <= 0 || r.ContentLength == 0 || r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+			next(w, r)
+			return
+		}
+		ip := clientIP(r)
+		if !s.uploads.acquire(ip, limit) {
+			s.metrics.uploadsRejected.Inc()
+			s.logger(r).WarnContext(r.Context(), "주소별 동시 업로드 초과", "client_ip", ip, "max", limit)
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, msg.Sprintf("이 주소에서 동시에 올리는 업로드가 %d 개를 넘었습니다 - 하나가 끝난 뒤 다시 시도하세요", limit), http.StatusTooManyRequests)
+			return
+		}
+		defer s.uploads.release(ip)
+		next(w, r)
+	}
+}
+
+// clientIP 요청을 보낸 주소의 IP (RemoteAddr 에 포트가 없으면 그대로)
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
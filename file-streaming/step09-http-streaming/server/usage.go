@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// API 키별 전송량과 달마다의 한도
+// ⭐ 업로드는 요청 본문에서 읽은 바이트, 다운로드는 응답으로 쓴 바이트를 요청이 끝날 때 계정에 더해.
+// 한도는 요청을 시작할 때 확인하고(다 썼으면 429), 업로드 본문은 남은 만큼만 읽어 (넘으면 413) -
+// 다운로드는 보내는 도중에 끊지 않아서 마지막 한 건은 한도를 조금 넘을 수 있어.
+
+// anonymous 키를 설정하지 않았을 때 모두가 같이 쓰는 계정 이름
+const anonymous = "anonymous"
+
+// APIKey 키 하나가 가리키는 계정
+type APIKey struct {
+	Name    string   // 계정 이름 (전송량 기록의 키)
+	Monthly int64    // 한 달 업로드+다운로드 한도 (0 이면 제한 없음)
+	Storage int64    // 올려 둘 수 있는 총 크기 (0 이면 제한 없음)
+	Scopes  []string // 할 수 있는 일 - ScopeUpload, ScopeDownload, ScopeDelete (비우면 전부)
+}
+
+// metered 전송 핸들러를 감싸서 계정의 전송량을 세고 한도를 지켜 (UsageFile 을 안 줬으면 그대로)
+func (s *Server) metered(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.usage == nil {
+			next(w, r)
+			return
+		}
+		acct := s.account(r)
+		remaining := s.usage.Remaining(acct.Name, acct.Monthly)
+		if remaining == 0 {
+			reset := usage.NextMonth(time.Now())
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(time.Until(reset).Seconds())+1, 10))
+			http.Error(w, msg.Sprintf("이번 달 전송 한도를 넘었습니다 (%s 에 초기화)", reset.Format(time.DateOnly)), http.StatusTooManyRequests)
+			s.logger(r).WarnContext(r.Context(), "전송 한도 초과", "account", acct.Name, "limit", acct.Monthly)
+			return
+		}
+
+		// 업로드 본문은 남은 한도만큼만 (핸들러의 MaxUploadSize 가 더 작으면 그쪽이 먼저 걸려서 둘 다 413)
+		if remaining > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, remaining)
+		}
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			s.usage.Add(acct.Name, usage.Upload, body.n)
+			s.usage.Add(acct.Name, usage.Download, rec.bytes)
+		}()
+		next(rec, r)
+	}
+}
+
+// countingReader 읽은 바이트 수
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// usageResponse /api/usage 응답
+type usageResponse struct {
+	Name      string        `json:"name"`
+	Month     string        `json:"month"`
+	Upload    int64         `json:"upload"`
+	Download  int64         `json:"download"`
+	Used      int64         `json:"used"`
+	Limit     int64         `json:"limit"`     // 0 이면 제한 없음
+	Remaining int64         `json:"remaining"` // 제한 없으면 -1
+	Reset     time.Time     `json:"reset"`     // 이번 달 카운터가 0 으로 돌아가는 시각 (UTC)
+	Lifetime  usage.Counter `json:"lifetime"`
+	Storage   *storageUsage `json:"storage,omitempty"` // 저장 공간 (StorageFile 을 줬을 때만)
+}
+
+// storageUsage 계정이 차지한 저장 공간
+type storageUsage struct {
+	Used      int64 `json:"used"`
+	Limit     int64 `json:"limit"`     // 0 이면 제한 없음
+	Remaining int64 `json:"remaining"` // 제한 없으면 -1
+}
+
+// usageHandler 요청한 키의 이번 달 전송량 - GET /api/usage
+func (s *Server) usageHandler(w http.ResponseWriter, r *http.Request) {
+	if s.usage == nil {
+		http.Error(w, msg.T("전송량 집계가 꺼져 있습니다"), http.StatusNotFound)
+		return
+	}
+	acct := s.account(r)
+	a := s.usage.Get(acct.Name)
+	var storage *storageUsage
+	if s.storage != nil {
+		storage = &storageUsage{Used: s.storage.Used(acct.Name), Limit: acct.Storage, Remaining: s.storageRoom(acct, "")}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(usageResponse{
+		Name:      acct.Name,
+		Month:     a.Month,
+		Upload:    a.Current.Upload,
+		Download:  a.Current.Download,
+		Used:      a.Current.Total(),
+		Limit:     acct.Monthly,
+		Remaining: s.usage.Remaining(acct.Name, acct.Monthly),
+		Reset:     usage.NextMonth(time.Now()),
+		Lifetime:  a.Lifetime,
+		Storage:   storage,
+	})
+}
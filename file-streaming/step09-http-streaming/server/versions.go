@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/usage"
+)
+
+// 파일 버전 (Config.Versions) - 실수로 덮어쓴 업로드를 되돌려
+// ⭐ 업로드가 같은 이름을 덮어쓰기 직전에(경로 잠금 안에서) 지금 파일을 VersionDir/<이름>/<번호> 로 하드 링크해 -
+// 새 파일은 rename 으로 들어와서 예전 inode 는 건드리지 않으니 복사 없이 예전 내용이 남아 (다른 파일시스템이면 복사).
+// 번호는 1 부터 늘어나고, Versions 개를 넘으면 오래된 것부터 지워.
+//
+//	GET  /api/files/<이름>/versions                    → 200 {"file", "versions": [{"version", "size", "modified"}]} (최근 것부터)
+//	GET  /api/files/<이름>/versions/<번호>              → 그 버전의 내용 (Range 도 돼)
+//	POST /api/files/<이름>/versions/<번호>/restore      → 200 {"file", "version", "size", "sha256"} - 지금 파일도 버전으로 남기고 되돌려
+//
+// /upload, 이어 올리기, 멀티파트, 범위 올리기, WebDAV, SFTP 의 덮어쓰기와 되돌리기가 버전을 남기고, /api/copy 의 overwrite=1 은 안 남겨.
+// 파일을 지우거나 이름을 바꿔도 버전은 예전 이름 아래에 그대로 있어서 되돌릴 수 있어. 로컬 디렉토리 저장소에서만 남겨.
+
+// fileVersion 남겨 둔 예전 내용 하나
+type fileVersion struct {
+	Version  int       `json:"version"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"` // 그 내용을 올린 시각 (파일의 수정 시각)
+}
+
+// versionPath name 의 n 번째 버전 파일 (번호를 0 으로 채워서 이름 순서가 곧 번호 순서)
+func (s *Server) versionPath(name string, n int) string {
+	return filepath.Join(s.cfg.VersionDir, name, fmt.Sprintf("%06d", n))
+}
+
+// versions name 의 버전들 (오래된 것부터, 없으면 빈 목록)
+func (s *Server) versions(name string) ([]fileVersion, error) {
+	entries, err := os.ReadDir(filepath.Join(s.cfg.VersionDir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []fileVersion{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]fileVersion, 0, len(entries))
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // 그사이 지워졌어
+		}
+		out = append(out, fileVersion{Version: n, Size: info.Size(), Modified: info.ModTime()})
+	}
+	return out, nil
+}
+
+// keepVersion name 을 덮어쓰기 직전에 지금 내용을 다음 번호의 버전으로 (name 의 경로 잠금 안에서)
+// Versions 가 0 이거나, 로컬 디렉토리가 아니거나, 아직 파일이 없으면 아무것도 안 해
+func (s *Server) keepVersion(name string) error {
+	keep := s.live().Versions
+	if keep <= 0 || !s.localDir() {
+		return nil
+	}
+	current := s.uploadPath(name)
+	info, err := os.Lstat(current)
+	if errors.Is(err, fs.ErrNotExist) || err == nil && !info.Mode().IsRegular() {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	have, err := s.versions(name)
+	if err != nil {
+		return err
+	}
+	n := 1
+	if len(have) > 0 {
+		n = have[len(have)-1].Version + 1
+	}
+	dst := s.versionPath(name, n)
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	// 하드 링크가 안 되면(다른 파일시스템) 시각과 체크섬 속성까지 복사
+	if err := os.Link(current, dst); err != nil {
+		opts := streamio.CopyOptions{BufferSize: s.live().BufferSize, Preserve: streamio.PreserveMode | streamio.PreserveTimes | streamio.PreserveXattrs}
+		if _, err := streamio.CopyFile(context.Background(), current, dst, opts); err != nil {
+			return msg.Errorf("%s 의 버전 %d 을 남기지 못함: %w", name, n, err)
+		}
+	}
+	for _, v := range have[:max(0, len(have)+1-keep)] {
+		os.Remove(s.versionPath(name, v.Version))
+	}
+	return nil
+}
+
+// versionsHandler /api/files/<이름>/versions 아래 - rest 는 "", "<번호>", "<번호>/restore"
+func (s *Server) versionsHandler(w http.ResponseWriter, r *http.Request, name, rest string) {
+	if rest == "" {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+			return
+		}
+		have, err := s.versions(name)
+		if err != nil {
+			s.logger(r).ErrorContext(r.Context(), "버전 목록을 읽지 못함", "file", name, "err", err)
+			http.Error(w, msg.T("버전 목록을 읽을 수 없습니다"), http.StatusInternalServerError)
+			return
+		}
+		slices.Reverse(have)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(struct {
+			File     string        `json:"file"`
+			Versions []fileVersion `json:"versions"`
+		}{name, have})
+		return
+	}
+
+	num, action, _ := strings.Cut(rest, "/")
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 || action != "" && action != "restore" {
+		http.Error(w, msg.T("잘못된 버전입니다"), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case action == "restore" && r.Method == http.MethodPost:
+		s.restoreVersion(w, r, name, n)
+	case action == "restore":
+		http.Error(w, msg.T("POST 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		f, err := os.Open(s.versionPath(name, n))
+		if err != nil {
+			http.Error(w, msg.T("버전을 찾을 수 없습니다"), http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, msg.T("버전을 읽을 수 없습니다"), http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, name, info.ModTime(), f)
+	default:
+		http.Error(w, msg.T("GET 메서드만 허용됩니다"), http.StatusMethodNotAllowed)
+	}
+}
+
+// restoreVersion n 번째 버전을 name 의 내용으로 - 업로드처럼 임시 파일에 복사한 뒤 rename 이라 지금 파일도 버전으로 남아
+func (s *Server) restoreVersion(w http.ResponseWriter, r *http.Request, name string, n int) {
+	if !s.localDir() {
+		http.Error(w, msg.T("버전을 찾을 수 없습니다"), http.StatusNotFound)
+		return
+	}
+	acct := s.account(r)
+	defer streamio.LockPath(s.uploadPath(name))()
+
+	size, sum, err := s.restoreLocked(r, acct, name, n)
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, usage.ErrStorageQuota):
+		status = http.StatusRequestEntityTooLarge
+	case err != nil:
+		status = http.StatusInternalServerError
+	}
+	s.audit(w, r, auditEntry{Action: AuditRestore, File: name, Version: n, Status: status}, err)
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		http.Error(w, msg.T("버전을 찾을 수 없습니다"), status)
+		return
+	case http.StatusRequestEntityTooLarge:
+		s.storageFull(w, r, acct, name, 0)
+		return
+	default:
+		s.logger(r).ErrorContext(r.Context(), "버전 되돌리기 실패", "file", name, "version", n, "err", err)
+		http.Error(w, msg.T("버전 되돌리기 실패"), status)
+		return
+	}
+
+	s.storePut(r, name, acct.Name, size)
+	if info, err := s.backend.Stat(r.Context(), name); err == nil {
+		s.hashes.put(name, info.Size(), info.ModTime(), sum)
+	}
+	s.queueIndex(s.uploadPath(name), false)
+	s.queueThumb(name)
+	s.logger(r).InfoContext(r.Context(), "버전 되돌림", "file", name, "version", n, "bytes", size)
+	w.Header().Set(checksumHeader, sum)
+	w.Header().Set("Content-Location", "/download?file="+url.QueryEscape(name))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(struct {
+		File    string `json:"file"`
+		Version int    `json:"version"`
+		Size    int64  `json:"size"`
+		SHA256  string `json:"sha256"`
+	}{name, n, size, sum})
+}
+
+// restoreLocked 버전을 업로드 디렉토리의 임시 파일로 복사하면서 sha256 을 재고 name 으로 (경로 잠금 안에서)
+func (s *Server) restoreLocked(r *http.Request, acct APIKey, name string, n int) (int64, string, error) {
+	in, err := os.Open(s.versionPath(name, n))
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(s.cfg.UploadDir, "."+name+".restore-*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(tmp.Name()) // rename 한 뒤면 없어
+	tmp.Chmod(0644)
+	h := sha256.New()
+	// 저장 공간 한도는 업로드처럼 - 되돌린 내용도 그 계정이 올린 것으로 세
+	size, err := io.Copy(io.MultiWriter(tmp, h), &quotaReader{r: in, n: s.storageRoom(acct, name)})
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	s.storeChecksum(r, tmp.Name(), name, sum)
+	// dedupe 가 덮어쓰기 전에 지금 파일을 새 버전으로 남겨 - 되돌리기도 되돌릴 수 있어
+	if _, err := s.dedupe(name, tmp.Name(), sum); err != nil {
+		return 0, "", err
+	}
+	return size, sum, nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/notify"
+)
+
+// 업로드 웹훅 (Config.UploadWebhooks) - 새 파일이 저장되면 다른 시스템이 바로 가져가게
+// ⭐ 업로드가 이름으로 보이게 된 뒤(commit 한 뒤)에 URL 마다 JSON 하나를 POST 해:
+//
+//	{"event":"upload","name":"a.log","original":"a.log","size":1048576,"sha256":"…","uploader":"alice","client":"10.0.0.7","time":"…"}
+//
+// 보내기는 뒤에서 해서 업로드 응답은 웹훅을 기다리지 않아. 실패하면 1초, 2초, 4초 … 간격으로 WebhookRetries 번 더 보내고 (notify 패키지와 같은 방식),
+// 4xx(408, 429 빼고)는 다시 보내도 같아서 바로 포기해. 끝내 못 보낸 건 로그에만 남아.
+// /upload, 이어 올리기, 멀티파트, 범위 올리기, WebDAV, SFTP, TCP 가 보내고 - 나눠 받아서 받으면서 잰 sha256 이 없으면 보내기 전에 한 번 읽어서 재.
+// URL 과 재시도 횟수는 SIGHUP 으로 바로 바뀌고, Serve 는 멈출 때 보내던 것이 끝나길 기다려.
+
+const (
+	webhookBackoff = time.Second      // 첫 재시도까지 (두 배씩)
+	webhookTimeout = 10 * time.Second // 한 번 보내기 제한 시간
+)
+
+// uploadEvent 웹훅 본문
+type uploadEvent struct {
+	Event    string    `json:"event"` // 지금은 upload 뿐
+	Name     string    `json:"name"`
+	Original string    `json:"original"` // 클라이언트가 보낸 이름 (Collision 정책으로 name 이 달라질 수 있어)
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`
+	Uploader string    `json:"uploader"` // 계정 이름 (인증이 꺼져 있으면 anonymous)
+	Client   string    `json:"client"`
+	Time     time.Time `json:"time"`
+}
+
+// announceUpload name 이 저장됐다고 UploadWebhooks 에 알려 (뒤에서) - sum 을 모르면 보내기 전에 재
+func (s *Server) announceUpload(r *http.Request, name, original, uploader string, size int64, sum string) {
+	live := s.live()
+	if len(live.UploadWebhooks) == 0 {
+		return
+	}
+	e := uploadEvent{Event: "upload", Name: name, Original: original, Size: size, SHA256: sum, Uploader: uploader, Client: clientIP(r), Time: time.Now()}
+	lg := s.logger(r)
+	s.webhooks.Go(func() {
+		ctx := context.Background()
+		if e.SHA256 == "" {
+			info, err := s.backend.Stat(ctx, name)
+			if err != nil {
+				lg.Warn("웹훅: 올린 파일이 그사이 없어짐", "file", name, "err", err)
+				return
+			}
+			e.SHA256 = s.hashes.sum(ctx, s.backend, fileEntry{Name: name, Size: info.Size(), ModTime: info.ModTime()})
+		}
+		body, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		for _, target := range live.UploadWebhooks {
+			if err := s.deliverWebhook(ctx, target, body, live.WebhookRetries); err != nil {
+				s.metrics.webhookFailed.Inc()
+				lg.Warn("웹훅 보내기 실패", "to", webhookHost(target), "file", name, "err", err)
+			}
+		}
+	})
+}
+
+// deliverWebhook target 에 body 를 POST - 실패하면 간격을 두 배씩 늘리며 retries 번 더 (0 이면 notify.DefaultRetries, 음수면 한 번만)
+func (s *Server) deliverWebhook(ctx context.Context, target string, body []byte, retries int) error {
+	if retries == 0 {
+		retries = notify.DefaultRetries
+	}
+	wait := webhookBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := postWebhook(ctx, target, body)
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+		s.cfg.Logger.Info("웹훅 재시도", "to", webhookHost(target), "attempt", attempt+1, "wait", wait, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// postWebhook 한 번 보내기 - 실패했으면 다시 보내 볼 만한지도 (잘못된 URL, 4xx 는 아니야)
+func postWebhook(ctx context.Context, target string, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // 연결을 다시 쓰려면 본문을 비워야 해
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = msg.Errorf("웹훅 응답 %s", resp.Status)
+	return resp.StatusCode/100 != 4 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+// webhookHost 로그에는 호스트까지만 (쿼리에 토큰을 넣는 웹훅이 많아)
+func webhookHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Scheme + "://" + u.Host
+	}
+	return "webhook"
+}
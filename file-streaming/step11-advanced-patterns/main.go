@@ -1,79 +1,29 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
-	"time"
-)
-
-// 진행률 콜백 함수 타입
-type ProgressCallback func(current, total int64)
-
-// 진행률을 추적하는 Reader 어댑터
-type ProgressReader struct {
-	reader   io.Reader
-	total    int64
-	current  int64
-	callback ProgressCallback
-}
-
-func NewProgressReader(r io.Reader, total int64, callback ProgressCallback) *ProgressReader {
-	return &ProgressReader{
-		reader:   r,
-		total:    total,
-		callback: callback,
-	}
-}
-
-func (pr *ProgressReader) Read(p []byte) (n int, err error) {
-	n, err = pr.reader.Read(p)
-	pr.current += int64(n)
 
-	if pr.callback != nil {
-		pr.callback(pr.current, pr.total)
-	}
-
-	return n, err
-}
-
-// 속도 제한 Reader 어댑터
-type ThrottledReader struct {
-	reader      io.Reader
-	bytesPerSec int64
-	lastRead    time.Time
-}
-
-func NewThrottledReader(r io.Reader, bytesPerSec int64) *ThrottledReader {
-	return &ThrottledReader{
-		reader:      r,
-		bytesPerSec: bytesPerSec,
-		lastRead:    time.Now(),
-	}
-}
-
-func (tr *ThrottledReader) Read(p []byte) (n int, err error) {
-	// 읽을 수 있는 최대 바이트 계산
-	elapsed := time.Since(tr.lastRead)
-	allowedBytes := int64(float64(tr.bytesPerSec) * elapsed.Seconds())
-
-	if allowedBytes < int64(len(p)) {
-		p = p[:allowedBytes]
-	}
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
 
-	if len(p) == 0 {
-		// 대기
-		time.Sleep(time.Millisecond * 10)
-		return 0, nil
+func main() {
+	progressMode := streamio.ProgressText
+	flag.Var(&progressMode, "progress", "진행률 출력 방식 (none|text|json)")
+	flag.Parse()
+	logging.SetupFromEnv()
+
+	// 예제 입력(fake.log)이 없으면 만들어 - 직접 만들려면: go run ../gen-data -size 100MB
+	if err := gendata.Ensure("fake.log", gendata.KindLog, 10<<20); err != nil {
+		slog.Error("예제 파일 생성 실패", "err", err)
+		return
 	}
 
-	n, err = tr.reader.Read(p)
-	tr.lastRead = time.Now()
-
-	return n, err
-}
-
-func main() {
 	file, _ := os.Open("fake.log")
 	defer file.Close()
 
@@ -85,13 +35,27 @@ func main() {
 		fmt.Printf("\r진행률: %.2f%%", percent)
 	}
 
-	// 진행률 추적 Reader
-	progressReader := NewProgressReader(file, fileInfo.Size(), progressCallback)
+	// ⭐ json 모드: 콜백은 카운트만 갱신하고, 출력은 리포터가 일정 주기로 stderr 에 해
+	if progressMode != streamio.ProgressText {
+		reporter := streamio.NewProgressReporter("fake.log", fileInfo.Size(), progressMode, os.Stderr, 0)
+		reporter.Start()
+		defer reporter.Stop()
+
+		progressCallback = func(current, total int64) {
+			reporter.Set(current)
+		}
+	}
+
+	// 진행률 추적 Reader (Reader 어댑터들은 streamio 에 모여 있어)
+	progressReader := streamio.NewProgressReader(file, fileInfo.Size(), progressCallback)
 
 	// 속도 제한 Reader (1MB/s)
-	throttledReader := NewThrottledReader(progressReader, 1024*1024)
+	throttledReader := streamio.NewThrottledReader(progressReader, 1024*1024)
 
 	// 데이터 읽기
-	io.Copy(io.Discard, throttledReader)
-	fmt.Println("\n완료!")
+	n, _ := io.Copy(io.Discard, throttledReader)
+	if progressMode == streamio.ProgressText {
+		fmt.Println() // \r 로 갱신하던 진행률 줄 끝내기
+	}
+	slog.Info("완료", "bytes", n)
 }
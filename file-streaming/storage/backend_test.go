@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/s3"
+)
+
+// testBackend 서버가 쓰는 만큼의 Storage 동작 - 저장소마다 같은 결과여야 해
+func testBackend(t *testing.T, st Storage) {
+	ctx := context.Background()
+	put := func(name, content string) {
+		t.Helper()
+		w, err := st.Create(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s Close: %v", name, err)
+		}
+	}
+	put("a.txt", "hello backend")
+	put("sub/b.txt", "nested")
+	put("empty.dat", "")
+
+	// Abort 한 쓰기는 안 보이고, 예전 내용도 그대로
+	w, _ := st.Create(ctx, "a.txt")
+	io.WriteString(w, "half")
+	w.Abort()
+	if _, err := st.Stat(ctx, "half.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("없는 파일 Stat = %v, want ErrNotExist", err)
+	}
+
+	info, err := st.Stat(ctx, "a.txt")
+	if err != nil || info.Size() != 13 || info.IsDir() {
+		t.Fatalf("Stat(a.txt) = %v, %v", info, err)
+	}
+	if info, err := st.Stat(ctx, "sub"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(sub) = %v, %v (디렉토리)", info, err)
+	}
+
+	infos, err := st.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, i := range infos {
+		if i.IsDir() {
+			names = append(names, i.Name()+"/") // 디렉토리 크기는 저장소마다 달라
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s:%d", i.Name(), i.Size()))
+	}
+	if got := strings.Join(names, " "); got != "a.txt:13 empty.dat:0 sub/" {
+		t.Errorf("List = %s", got)
+	}
+
+	// 읽기, 그리고 Seek 해서 중간부터 (Range 다운로드)
+	r, err := st.Open(ctx, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		t.Fatalf("Open 이 %T - Seek 이 안 돼", r)
+	}
+	if b, _ := io.ReadAll(rs); string(b) != "hello backend" {
+		t.Errorf("내용 = %q", b)
+	}
+	rs.Seek(6, io.SeekStart)
+	if b, _ := io.ReadAll(rs); string(b) != "backend" {
+		t.Errorf("Seek(6) 뒤 = %q", b)
+	}
+	if n, _ := rs.Seek(0, io.SeekEnd); n != 13 {
+		t.Errorf("SeekEnd = %d", n)
+	}
+	r.Close()
+
+	if err := st.Delete(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Delete(ctx, "a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("두 번째 Delete = %v, want ErrNotExist", err)
+	}
+	if _, err := st.Open(ctx, "a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("지운 뒤 Open = %v, want ErrNotExist", err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	d := Dir(t.TempDir())
+	testBackend(t, d)
+	if got := d.Path("../../etc/passwd"); got != d.Path("etc/passwd") {
+		t.Errorf("Path 가 루트 밖으로: %s", got)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testBackend(t, NewMemory())
+}
+
+func TestS3(t *testing.T) {
+	srv := httptest.NewServer(newFakeS3())
+	defer srv.Close()
+	client := &s3.Client{Endpoint: srv.URL, PathStyle: true, Credentials: s3.Credentials{AccessKey: "test", SecretKey: "test"}}
+	// 파트 하나보다 큰 파일은 멀티파트로 나눠 올라가
+	st := &S3{Client: client, Bucket: "bucket", Prefix: "uploads", Upload: s3.UploadOptions{PartSize: s3.MinPartSize}}
+	testBackend(t, st)
+
+	big := bytes.Repeat([]byte("0123456789"), s3.MinPartSize/10*2+7)
+	w, _ := st.Create(context.Background(), "big.bin")
+	w.Write(big)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := st.Open(context.Background(), "big.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got, _ := io.ReadAll(r); !bytes.Equal(got, big) {
+		t.Errorf("멀티파트로 올린 내용이 다름 (%d 바이트, want %d)", len(got), len(big))
+	}
+}
+
+// fakeS3 Storage 가 부르는 API 만 흉내 내는 S3 (서명은 확인 안 해)
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte         // 키 → 내용 (버킷은 하나라고 쳐)
+	uploads map[string]map[int][]byte // uploadId → 파트
+	next    int
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && q.Get("list-type") == "2":
+		f.list(w, q.Get("prefix"), q.Get("delimiter"))
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.next++
+		id := strconv.Itoa(f.next)
+		f.uploads[id] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		body, _ := io.ReadAll(r.Body)
+		f.uploads[q.Get("uploadId")][n] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"part%d"`, n))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts := f.uploads[q.Get("uploadId")]
+		var data []byte
+		for n := 1; n <= len(parts); n++ {
+			data = append(data, parts[n]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, q.Get("uploadId"))
+		io.WriteString(w, "<CompleteMultipartUploadResult><ETag>\"done\"</ETag></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		delete(f.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key], _ = io.ReadAll(r.Body)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		http.ServeContent(w, r, key, time.Time{}, bytes.NewReader(data))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix, delimiter string) {
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	}
+	type commonPrefix struct {
+		Prefix string `xml:"Prefix"`
+	}
+	var out struct {
+		XMLName  xml.Name       `xml:"ListBucketResult"`
+		Contents []content      `xml:"Contents"`
+		Prefixes []commonPrefix `xml:"CommonPrefixes"`
+	}
+	seen := map[string]bool{}
+	var keys []string
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		if sub, _, nested := strings.Cut(rest, delimiter); delimiter != "" && nested {
+			if p := prefix + sub + delimiter; !seen[p] {
+				seen[p] = true
+				out.Prefixes = append(out.Prefixes, commonPrefix{p})
+			}
+			continue
+		}
+		out.Contents = append(out.Contents, content{k, int64(len(f.objects[k]))})
+	}
+	xml.NewEncoder(w).Encode(out)
+}
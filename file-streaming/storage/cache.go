@@ -0,0 +1,454 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// ErrCacheCorrupt 캐시에서 읽은 내용의 해시가 기록과 다름 - 그 항목은 지웠으니 다시 열면 원본에서 받아
+var ErrCacheCorrupt = msg.New("캐시 파일이 깨졌습니다")
+
+// 캐시 한도 기본값
+const (
+	DefaultCacheMaxSize = 1 << 30
+	DefaultCacheMaxAge  = 7 * 24 * time.Hour
+)
+
+// CacheOptions 디스크 캐시 설정
+type CacheOptions struct {
+	Dir     string        // 캐시 디렉토리 (objects/, tmp/, index.json)
+	MaxSize int64         // 전체 크기 한도 - 넘으면 오래 안 쓴 것부터 지워 (0 이면 DefaultCacheMaxSize)
+	MaxAge  time.Duration // 마지막으로 쓴 지 이만큼 지나면 지워 (0 이면 DefaultCacheMaxAge)
+	Logger  *slog.Logger  // 기본 component=cache
+}
+
+// cacheEntry 원본 파일 하나 → 캐시한 내용
+// ⭐ 원본의 크기와 수정 시각이 그대로일 때만 맞는 걸로 봐 (원본이 바뀌면 Stat 만으로 알 수 있게)
+type cacheEntry struct {
+	SHA256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Used    time.Time `json:"used"` // 마지막으로 캐시에서 읽은(또는 넣은) 시각 - LRU 기준
+}
+
+// Cache 느린 저장소(SFTP 등) 앞에 두는 디스크 캐시 - Storage 를 그대로 구현해서 원래 자리에 끼우면 돼
+// ⭐ 처음 Open 하면 원본을 읽어 가는 대로 캐시에 같이 쓰고(끝까지 읽었을 때만 남겨), 다음부터는 Stat 으로
+// 원본이 안 바뀐 걸 확인하고 캐시 파일을 읽어. 내용은 sha256 이름(objects/ab/abcd...)으로 두니까
+// 경로가 달라도 내용이 같으면 한 번만 저장돼. 읽으면서 해시를 다시 계산해서 디스크에서 깨진 캐시는 에러로 알려.
+//
+// List/Create/Delete 는 원본으로 바로 가고, Create/Delete 하면 그 경로의 캐시 항목도 지워.
+type Cache struct {
+	Storage
+	id   string // 원본 저장소 구분 (sftp://user@host:22) - 인덱스 키 앞에 붙여
+	opts CacheOptions
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// CacheID 원격 주소에서 캐시 id (scheme://user@host[:port]) - 비밀번호와 경로는 빼
+func CacheID(location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	id := u.Scheme + "://"
+	if u.User != nil {
+		id += u.User.Username() + "@"
+	}
+	return id + u.Host
+}
+
+// NewCache backend 앞에 opts.Dir 캐시를 둬 - id 는 같은 캐시 디렉토리를 여러 원본이 같이 쓸 때 구분용
+func NewCache(backend Storage, id string, opts CacheOptions) (*Cache, error) {
+	if opts.Dir == "" {
+		return nil, msg.New("캐시 디렉토리가 비어 있습니다")
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultCacheMaxSize
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultCacheMaxAge
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.For("cache")
+	}
+	for _, dir := range []string{"objects", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(opts.Dir, dir), 0755); err != nil {
+			return nil, msg.Errorf("캐시 디렉토리 만들기 실패: %w", err)
+		}
+	}
+	c := &Cache{Storage: backend, id: id, opts: opts}
+	var err error
+	if c.entries, err = c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) indexPath() string { return filepath.Join(c.opts.Dir, "index.json") }
+
+func (c *Cache) objectPath(sum string) string {
+	return filepath.Join(c.opts.Dir, "objects", sum[:2], sum)
+}
+
+func (c *Cache) key(name string) string { return c.id + "/" + path.Clean(name) }
+
+func (c *Cache) load() (map[string]*cacheEntry, error) {
+	entries := make(map[string]*cacheEntry)
+	data, err := os.ReadFile(c.indexPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Entries map[string]*cacheEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		// 인덱스가 깨졌으면 비우고 다시 시작 - 캐시일 뿐이라 원본에서 다시 받으면 돼
+		c.opts.Logger.Warn("캐시 인덱스가 깨져서 비웁니다", "file", c.indexPath(), "err", err)
+		return entries, nil
+	}
+	if file.Entries != nil {
+		entries = file.Entries
+	}
+	return entries, nil
+}
+
+// update 인덱스를 다시 읽어서 fn 으로 고치고 저장 (c.mu 잡은 상태로)
+// 다른 프로세스가 같은 캐시를 쓰고 있어도 그쪽 항목을 덮어 지우지 않게 매번 파일에서 다시 읽어.
+// 그래도 동시에 저장하면 한쪽 항목을 잃을 수는 있어 - 내용 파일은 해시 이름이라 깨지진 않고, 잃은 항목은 다시 받을 뿐이야.
+func (c *Cache) update(fn func(entries map[string]*cacheEntry)) error {
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	fn(entries)
+	c.entries = entries
+	data, err := json.MarshalIndent(struct {
+		Entries map[string]*cacheEntry `json:"entries"`
+	}{entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.opts.Dir, ".index.json.tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return streamio.Rename(tmp.Name(), c.indexPath())
+}
+
+// Open 캐시에 맞는 항목이 있으면 캐시 파일을, 없으면 원본을 읽으면서 캐시에 채워
+func (c *Cache) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	info, err := c.Storage.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return c.Storage.Open(ctx, name)
+	}
+	key := c.key(name)
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && e.Size == info.Size() && e.ModTime.Equal(info.ModTime()) {
+		if f, err := os.Open(c.objectPath(e.SHA256)); err == nil {
+			c.touch(key)
+			c.opts.Logger.Debug("캐시 적중", "key", key)
+			return &cacheHit{c: c, key: key, f: f, want: e.SHA256, h: sha256.New()}, nil
+		}
+	}
+
+	src, err := c.Storage.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(c.opts.Dir, "tmp"), "fill-*")
+	if err != nil {
+		// 캐시에 못 써도 원본은 읽을 수 있어야 해
+		c.opts.Logger.Warn("캐시 임시 파일 생성 실패 - 캐시 없이 읽어", "err", err)
+		return src, nil
+	}
+	c.opts.Logger.Debug("캐시 없음 - 원본에서 채움", "key", key)
+	return &cacheFill{c: c, key: key, info: info, src: src, tmp: tmp, h: sha256.New()}, nil
+}
+
+// touch 마지막 사용 시각 갱신 (LRU)
+func (c *Cache) touch(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if err := c.update(func(entries map[string]*cacheEntry) {
+		if e := entries[key]; e != nil {
+			e.Used = now
+		}
+	}); err != nil {
+		c.opts.Logger.Warn("캐시 인덱스 저장 실패", "err", err)
+	}
+}
+
+// forget 경로 하나의 항목을 지워 (내용 파일은 다른 경로가 쓰고 있을 수 있어서 Evict 에 맡겨)
+func (c *Cache) forget(name string) {
+	key := c.key(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		return
+	}
+	c.update(func(entries map[string]*cacheEntry) { delete(entries, key) })
+}
+
+// Create 원본에 쓰기 - 그 경로의 캐시 항목은 더 이상 맞지 않으니 지워
+func (c *Cache) Create(ctx context.Context, name string) (Writer, error) {
+	c.forget(name)
+	return c.Storage.Create(ctx, name)
+}
+
+// Delete 원본에서 지우고 캐시 항목도 지워
+func (c *Cache) Delete(ctx context.Context, name string) error {
+	c.forget(name)
+	return c.Storage.Delete(ctx, name)
+}
+
+// Close 원본 연결을 닫아 (캐시는 그때그때 저장해서 따로 할 일이 없어)
+func (c *Cache) Close() error {
+	if closer, ok := c.Storage.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// CacheStats Evict 결과
+type CacheStats struct {
+	Objects int   `json:"objects"` // 남은 내용 파일 수
+	Bytes   int64 `json:"bytes"`   // 남은 크기
+	Evicted int   `json:"evicted"` // 지운 내용 파일 수
+	Freed   int64 `json:"freed"`   // 지운 크기
+}
+
+// Evict 오래된 것(MaxAge)을 지우고, 그래도 MaxSize 를 넘으면 가장 오래 안 쓴 것부터 지워
+// 인덱스에 없는 내용 파일(다른 프로세스가 저장하다 항목을 잃은 것)은 파일 수정 시각을 마지막 사용으로 봐.
+func (c *Cache) Evict() (CacheStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type object struct {
+		sum  string
+		size int64
+		used time.Time
+	}
+	var stats CacheStats
+	objects := make(map[string]*object)
+	root := filepath.Join(c.opts.Dir, "objects")
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // 그사이 지워졌으면 넘어가
+		}
+		objects[d.Name()] = &object{sum: d.Name(), size: info.Size(), used: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	var evicted map[string]bool
+	err = c.update(func(entries map[string]*cacheEntry) {
+		for key, e := range entries {
+			o := objects[e.SHA256]
+			if o == nil {
+				delete(entries, key) // 내용 파일이 없어진 항목
+				continue
+			}
+			if e.Used.After(o.used) {
+				o.used = e.Used
+			}
+		}
+
+		list := make([]*object, 0, len(objects))
+		var total int64
+		for _, o := range objects {
+			list = append(list, o)
+			total += o.size
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].used.Before(list[j].used) })
+		evicted = make(map[string]bool)
+		cutoff := time.Now().Add(-c.opts.MaxAge)
+		for _, o := range list {
+			if !o.used.Before(cutoff) && total <= c.opts.MaxSize {
+				break
+			}
+			evicted[o.sum] = true
+			total -= o.size
+			stats.Evicted++
+			stats.Freed += o.size
+		}
+		for key, e := range entries {
+			if evicted[e.SHA256] {
+				delete(entries, key)
+			}
+		}
+		stats.Objects, stats.Bytes = len(list)-stats.Evicted, total
+	})
+	if err != nil {
+		return stats, err
+	}
+	for sum := range evicted {
+		if err := os.Remove(c.objectPath(sum)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.opts.Logger.Warn("캐시 파일 삭제 실패", "sha256", sum, "err", err)
+		}
+	}
+
+	// 죽은 프로세스가 남긴 채우다 만 임시 파일
+	tmps, _ := os.ReadDir(filepath.Join(c.opts.Dir, "tmp"))
+	for _, d := range tmps {
+		if info, err := d.Info(); err == nil && time.Since(info.ModTime()) > 24*time.Hour {
+			os.Remove(filepath.Join(c.opts.Dir, "tmp", d.Name()))
+		}
+	}
+	if stats.Evicted > 0 {
+		c.opts.Logger.Info("캐시 정리", "evicted", stats.Evicted, "freed", stats.Freed, "remaining", stats.Bytes)
+	}
+	return stats, nil
+}
+
+// cacheHit 캐시 파일을 읽으면서 해시를 다시 계산해서 끝에서 비교
+type cacheHit struct {
+	c    *Cache
+	key  string
+	f    *os.File
+	h    hash.Hash
+	want string
+}
+
+func (r *cacheHit) Read(p []byte) (int, error) {
+	n, err := r.f.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.h.Sum(nil)) != r.want {
+		r.c.opts.Logger.Warn("캐시 파일이 깨져서 지웁니다", "key", r.key, "sha256", r.want)
+		os.Remove(r.c.objectPath(r.want))
+		r.c.forget(r.key[len(r.c.id)+1:])
+		return n, fmt.Errorf("%w: %s", ErrCacheCorrupt, r.key)
+	}
+	return n, err
+}
+
+func (r *cacheHit) Close() error { return r.f.Close() }
+
+// cacheFill 원본을 읽는 대로 임시 파일에도 써 - 끝까지 읽으면 해시 이름으로 옮기고 인덱스에 넣어
+type cacheFill struct {
+	c    *Cache
+	key  string
+	info fs.FileInfo
+	src  io.ReadCloser
+	tmp  *os.File // 캐시에 쓰다 실패하면 nil (읽기는 계속돼)
+	h    hash.Hash
+	n    int64
+	done bool
+}
+
+func (r *cacheFill) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	if n > 0 && r.tmp != nil {
+		r.h.Write(p[:n])
+		if _, werr := r.tmp.Write(p[:n]); werr != nil {
+			r.c.opts.Logger.Warn("캐시에 쓰기 실패 - 이번에는 캐시 없이 읽어", "key", r.key, "err", werr)
+			r.discard()
+		}
+	}
+	r.n += int64(n)
+	if err == io.EOF && !r.done {
+		r.done = true
+		r.commit()
+	}
+	return n, err
+}
+
+// commit 다 읽은 내용을 objects/ 로 옮기고 항목 추가 (실패해도 읽기에는 영향 없어)
+func (r *cacheFill) commit() {
+	if r.tmp == nil {
+		return
+	}
+	if r.n != r.info.Size() {
+		// 읽는 동안 원본이 바뀐 거라 이 내용은 크기+수정 시각 항목과 맞지 않아
+		r.discard()
+		return
+	}
+	sum := hex.EncodeToString(r.h.Sum(nil))
+	tmpName := r.tmp.Name()
+	err := r.tmp.Close()
+	r.tmp = nil
+	dst := r.c.objectPath(sum)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(dst), 0755)
+	}
+	if err == nil {
+		err = streamio.Rename(tmpName, dst)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		r.c.opts.Logger.Warn("캐시 저장 실패", "key", r.key, "err", err)
+		return
+	}
+
+	r.c.mu.Lock()
+	now := time.Now()
+	err = r.c.update(func(entries map[string]*cacheEntry) {
+		entries[r.key] = &cacheEntry{SHA256: sum, Size: r.n, ModTime: r.info.ModTime(), Used: now}
+	})
+	r.c.mu.Unlock()
+	if err != nil {
+		r.c.opts.Logger.Warn("캐시 인덱스 저장 실패", "err", err)
+		return
+	}
+	if _, err := r.c.Evict(); err != nil {
+		r.c.opts.Logger.Warn("캐시 정리 실패", "err", err)
+	}
+}
+
+func (r *cacheFill) discard() {
+	if r.tmp != nil {
+		r.tmp.Close()
+		os.Remove(r.tmp.Name())
+		r.tmp = nil
+	}
+}
+
+// Close 끝까지 안 읽고 닫으면 채우던 건 버려
+func (r *cacheFill) Close() error {
+	r.discard()
+	return r.src.Close()
+}
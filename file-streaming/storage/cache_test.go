@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingStorage Open 횟수를 세는 "느린 원격" 대역
+type countingStorage struct {
+	Local
+	opens int
+}
+
+func (s *countingStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.opens++
+	return s.Local.Open(ctx, name)
+}
+
+func readAll(t *testing.T, c *Cache, name string) (string, error) {
+	t.Helper()
+	r, err := c.Open(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	return string(b), err
+}
+
+func TestCache(t *testing.T) {
+	remote, dir := t.TempDir(), t.TempDir()
+	a, b := filepath.Join(remote, "a.log"), filepath.Join(remote, "b.log")
+	os.WriteFile(a, []byte("hello cache"), 0644)
+	os.WriteFile(b, []byte("hello cache"), 0644)
+
+	backend := &countingStorage{}
+	c, err := NewCache(backend, "test", CacheOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if got, err := readAll(t, c, a); err != nil || got != "hello cache" {
+			t.Fatalf("읽기 = %q, %v", got, err)
+		}
+	}
+	if backend.opens != 1 {
+		t.Errorf("원본 Open %d번, want 1 (나머지는 캐시)", backend.opens)
+	}
+
+	// 내용이 같은 다른 경로는 원본에서 읽지만 내용 파일은 하나
+	readAll(t, c, b)
+	objects, _ := filepath.Glob(filepath.Join(dir, "objects", "*", "*"))
+	if len(objects) != 1 {
+		t.Errorf("내용 파일 %d개, want 1 (같은 내용은 한 번만)", len(objects))
+	}
+
+	// 원본이 바뀌면 (크기/수정 시각) 다시 받아
+	os.WriteFile(a, []byte("changed!"), 0644)
+	if got, _ := readAll(t, c, a); got != "changed!" || backend.opens != 3 {
+		t.Errorf("바뀐 원본: %q, Open %d번", got, backend.opens)
+	}
+
+	// 디스크에서 깨진 캐시는 끝에서 에러, 다음 Open 은 원본에서
+	for _, o := range objects {
+		os.WriteFile(o, []byte("hellX cache"), 0644)
+	}
+	if _, err := readAll(t, c, b); !errors.Is(err, ErrCacheCorrupt) {
+		t.Errorf("깨진 캐시: err = %v, want ErrCacheCorrupt", err)
+	}
+	if got, err := readAll(t, c, b); err != nil || got != "hello cache" {
+		t.Errorf("다시 읽기 = %q, %v", got, err)
+	}
+
+	// 끝까지 안 읽고 닫으면 캐시에 남기지 않아
+	big := filepath.Join(remote, "big.log")
+	os.WriteFile(big, []byte(strings.Repeat("x", 64<<10)), 0644)
+	r, _ := c.Open(context.Background(), big)
+	r.Read(make([]byte, 10))
+	r.Close()
+	opens := backend.opens
+	readAll(t, c, big)
+	if backend.opens != opens+1 {
+		t.Error("중간에 닫은 파일이 캐시에 남음")
+	}
+	if tmps, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmps) != 0 {
+		t.Errorf("임시 파일이 남음: %v", tmps)
+	}
+}
+
+func TestCacheEvict(t *testing.T) {
+	remote, dir := t.TempDir(), t.TempDir()
+	backend := &countingStorage{}
+	c, err := NewCache(backend, "test", CacheOptions{Dir: dir, MaxSize: 25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"1", "2", "3"}
+	for _, name := range names {
+		p := filepath.Join(remote, name)
+		os.WriteFile(p, []byte(strings.Repeat(name, 10)), 0644)
+		readAll(t, c, p)
+		time.Sleep(10 * time.Millisecond) // 사용 시각 순서가 분명하게
+		if name == "2" {
+			readAll(t, c, filepath.Join(remote, "1")) // 1 을 다시 써서 2 가 가장 오래 안 쓴 게 돼
+		}
+	}
+	// 10바이트짜리 3개 > 25 → 가장 오래 안 쓴 2 만 지워져
+	opens := backend.opens
+	for _, name := range []string{"1", "3"} {
+		readAll(t, c, filepath.Join(remote, name))
+	}
+	if backend.opens != opens {
+		t.Errorf("최근에 쓴 1, 3 이 캐시에서 지워짐")
+	}
+	readAll(t, c, filepath.Join(remote, "2"))
+	if backend.opens != opens+1 {
+		t.Errorf("가장 오래 안 쓴 2 가 캐시에 남음")
+	}
+
+	// 다시 열어도 인덱스가 이어져
+	c2, _ := NewCache(backend, "test", CacheOptions{Dir: dir, MaxAge: time.Millisecond})
+	time.Sleep(5 * time.Millisecond)
+	stats, err := c2.Evict()
+	if err != nil || stats.Objects != 0 || stats.Evicted == 0 {
+		t.Errorf("MaxAge 정리 = %+v, %v", stats, err)
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Dir 디렉토리 하나를 루트로 쓰는 로컬 저장소 (http.Dir 처럼) - 이름은 그 안의 슬래시 경로야
+// "../" 로 시작해도 루트 밖으로 나가지 못해 (Clean 한 뒤 루트에 붙여).
+type Dir string
+
+// Path 이름의 실제 디스크 경로 (하드 링크, 확장 속성처럼 로컬에서만 되는 일을 할 때)
+func (d Dir) Path(name string) string {
+	return filepath.Join(string(d), filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (d Dir) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	return Local{}.Stat(ctx, d.Path(name))
+}
+
+func (d Dir) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	return Local{}.List(ctx, d.Path(dir))
+}
+
+// Open *os.File 이라 Seek, Stat 도 돼
+func (d Dir) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return Local{}.Open(ctx, d.Path(name))
+}
+
+func (d Dir) Create(ctx context.Context, name string) (Writer, error) {
+	return Local{}.Create(ctx, d.Path(name))
+}
+
+func (d Dir) Delete(ctx context.Context, name string) error {
+	return Local{}.Delete(ctx, d.Path(name))
+}
+
+func (d Dir) Chtimes(ctx context.Context, name string, atime, mtime time.Time) error {
+	return Local{}.Chtimes(ctx, d.Path(name), atime, mtime)
+}
@@ -0,0 +1,405 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 저장할 때 암호화하는 저장소 (다른 Storage 를 감싸)
+// ⭐ 파일을 64KB 청크로 잘라 청크마다 AES-256-GCM 으로 봉해 - 통째로 봉하면 끝까지 읽어야 검증되지만, 청크 단위라
+// 받으면서 바로 쓰고, Range 요청은 그 위치의 청크만 읽어서 풀어 (Seek 해도 앞부분을 다시 읽지 않아).
+//
+//	헤더 16바이트: "FSE" 버전(1) | 키 ID 4바이트 | nonce 앞부분 7바이트(파일마다 무작위) | 0
+//	청크:         암호문 (평문 64KB, 마지막만 짧거나 0) + GCM 태그 16바이트
+//
+// nonce 는 앞부분 7바이트 + 청크 번호 4바이트 + 마지막 청크 표시 1바이트 (STREAM 방식)라서, 청크를 바꿔 끼우거나
+// 뒤를 잘라내면 태그가 맞지 않아. 헤더는 모든 청크의 AAD 로 들어가서 키 ID 나 nonce 를 바꿔도 마찬가지야.
+// 청크 크기가 고정이라 Stat/List 는 파일을 열지 않고 암호문 크기에서 평문 크기를 계산해.
+//
+// 키는 여러 개 줄 수 있어 - 첫 번째로 암호화하고, 읽을 때는 헤더의 키 ID 로 골라 (키를 바꿔도 예전 파일을 읽어).
+// 암호화하지 않은 파일은 헤더가 맞지 않아서 열지 못해 (평문 파일과 섞어 두지 마).
+
+const (
+	encChunk    = 64 << 10 // 평문 청크 크기
+	encTag      = 16       // GCM 태그
+	encHeader   = 16
+	encPrefix   = 7 // nonce 앞부분
+	encKeySize  = 32
+	encMagic    = "FSE"
+	encVersion  = 1
+	encSealed   = encChunk + encTag
+	encKeyIDLen = 4
+)
+
+// ErrNotEncrypted 헤더가 이 형식이 아닌 파일 (평문이거나 손상)
+var ErrNotEncrypted = msg.New("암호화된 파일이 아닙니다 (헤더가 맞지 않음)")
+
+// ErrUnknownKey 파일을 암호화한 키가 주어진 키 목록에 없음
+var ErrUnknownKey = msg.New("이 파일을 암호화한 키가 없습니다")
+
+// Encrypted inner 에 암호화해서 저장하는 Storage
+type Encrypted struct {
+	inner Storage
+	keys  []encKey // 첫 번째로 암호화
+}
+
+type encKey struct {
+	id   [encKeyIDLen]byte
+	aead cipher.AEAD
+}
+
+// NewEncrypted inner 를 감싸 - keys 는 32바이트 AES-256 키 (첫 번째로 암호화, 나머지는 예전 파일 읽기용)
+func NewEncrypted(inner Storage, keys ...[]byte) (*Encrypted, error) {
+	if len(keys) == 0 {
+		return nil, msg.New("암호화 키가 없습니다")
+	}
+	e := &Encrypted{inner: inner}
+	for i, k := range keys {
+		if len(k) != encKeySize {
+			return nil, msg.Errorf("암호화 키 %d 의 길이가 %d 바이트입니다 (32바이트여야 합니다)", i+1, len(k))
+		}
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		e.keys = append(e.keys, encKey{id: keyID(k), aead: aead})
+	}
+	return e, nil
+}
+
+// keyID 키를 드러내지 않는 짧은 식별자 (헤더에 남겨서 읽을 때 키를 골라)
+func keyID(k []byte) [encKeyIDLen]byte {
+	sum := sha256.Sum256(append([]byte("file-streaming encryption key id\x00"), k...))
+	return [encKeyIDLen]byte(sum[:encKeyIDLen])
+}
+
+// ParseKeys 키 파일 내용 - 줄마다 32바이트 키 하나 (64자리 hex 나 base64), 빈 줄과 # 주석은 건너뛰어
+func ParseKeys(text string) ([][]byte, error) {
+	var keys [][]byte
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, err := hex.DecodeString(line)
+		if err != nil {
+			k, err = base64.StdEncoding.DecodeString(line)
+		}
+		if err != nil || len(k) != encKeySize {
+			return nil, msg.Errorf("%d번째 줄: 32바이트 키를 hex(64자리)나 base64 로 적어야 합니다", i+1)
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, msg.New("암호화 키가 없습니다")
+	}
+	return keys, nil
+}
+
+// Unwrap 감싼 저장소
+func (e *Encrypted) Unwrap() Storage { return e.inner }
+
+// PlainSize 암호문 크기 → 평문 크기 (이 형식이 될 수 없는 크기면 false)
+func PlainSize(sealed int64) (int64, bool) {
+	n := sealed - encHeader
+	if n < encTag {
+		return 0, false
+	}
+	full, rem := n/encSealed, n%encSealed
+	switch {
+	case rem == 0:
+		return full * encChunk, true
+	case rem < encTag:
+		return 0, false
+	}
+	return full*encChunk + rem - encTag, true
+}
+
+// SealedSize 평문 크기 → 암호문 크기 (빈 파일도 헤더와 빈 마지막 청크)
+func SealedSize(plain int64) int64 {
+	chunks := max((plain+encChunk-1)/encChunk, 1)
+	return encHeader + plain + chunks*encTag
+}
+
+// plainInfo 크기만 평문으로 바꾼 FileInfo
+type plainInfo struct{ fs.FileInfo }
+
+func (i plainInfo) Size() int64 {
+	if i.FileInfo.IsDir() {
+		return i.FileInfo.Size()
+	}
+	n, _ := PlainSize(i.FileInfo.Size())
+	return n
+}
+
+func (e *Encrypted) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	info, err := e.inner.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return plainInfo{info}, nil
+}
+
+func (e *Encrypted) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	infos, err := e.inner.List(ctx, dir)
+	for i := range infos {
+		infos[i] = plainInfo{infos[i]}
+	}
+	return infos, err
+}
+
+func (e *Encrypted) Delete(ctx context.Context, name string) error {
+	return e.inner.Delete(ctx, name)
+}
+
+func (e *Encrypted) Chtimes(ctx context.Context, name string, atime, mtime time.Time) error {
+	ts, ok := e.inner.(TimeSetter)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return ts.Chtimes(ctx, name, atime, mtime)
+}
+
+// nonce 청크 번호와 마지막 표시를 붙인 12바이트
+func encNonce(prefix []byte, idx int64, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encPrefix:], uint32(idx))
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Create 헤더를 쓰고 청크가 찰 때마다 봉해서 inner 로 - Close 에서 남은 것을 마지막 청크로
+func (e *Encrypted) Create(ctx context.Context, name string) (Writer, error) {
+	w, err := e.inner.Create(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	k := e.keys[0]
+	header := make([]byte, encHeader)
+	copy(header, encMagic)
+	header[3] = encVersion
+	copy(header[4:], k.id[:])
+	if _, err := rand.Read(header[8 : 8+encPrefix]); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return &encWriter{w: w, aead: k.aead, header: header, buf: make([]byte, 0, encChunk)}, nil
+}
+
+type encWriter struct {
+	w      Writer
+	aead   cipher.AEAD
+	header []byte
+	wrote  bool // 헤더를 썼는지
+	buf    []byte
+	idx    int64
+	sealed []byte
+	done   bool
+}
+
+func (w *encWriter) Write(p []byte) (int, error) {
+	if w.done {
+		return 0, fs.ErrClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		// 꽉 찬 청크는 뒤에 더 올 때만 내보내 - 끝에서 마지막 표시를 붙여야 해서
+		if len(w.buf) == encChunk {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):encChunk], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (w *encWriter) flush(last bool) error {
+	if !w.wrote {
+		if _, err := w.w.Write(w.header); err != nil {
+			return err
+		}
+		w.wrote = true
+	}
+	w.sealed = w.aead.Seal(w.sealed[:0], encNonce(w.header[8:8+encPrefix], w.idx, last), w.buf, w.header)
+	if _, err := w.w.Write(w.sealed); err != nil {
+		return err
+	}
+	w.idx++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *encWriter) Close() error {
+	if w.done {
+		return fs.ErrClosed
+	}
+	w.done = true
+	if err := w.flush(true); err != nil {
+		w.w.Abort()
+		return err
+	}
+	return w.w.Close()
+}
+
+func (w *encWriter) Abort() error {
+	w.done = true
+	return w.w.Abort()
+}
+
+// Open 헤더를 읽어 키를 고르고, 읽는 위치의 청크만 풀어 (inner 가 Seek 되면 Seek 도 돼)
+func (e *Encrypted) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	rc, err := e.inner.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var info fs.FileInfo
+	if st, ok := rc.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		info, err = st.Stat()
+	} else {
+		info, err = e.inner.Stat(ctx, name)
+	}
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	r, err := e.newReader(rc, info)
+	if err != nil {
+		rc.Close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return r, nil
+}
+
+func (e *Encrypted) newReader(rc io.ReadCloser, info fs.FileInfo) (*encReader, error) {
+	size, ok := PlainSize(info.Size())
+	if !ok {
+		return nil, ErrNotEncrypted
+	}
+	header := make([]byte, encHeader)
+	if _, err := io.ReadFull(rc, header); err != nil {
+		return nil, ErrNotEncrypted
+	}
+	if string(header[:3]) != encMagic || header[3] != encVersion {
+		return nil, ErrNotEncrypted
+	}
+	r := &encReader{rc: rc, info: plainInfo{info}, header: header, size: size, innerPos: encHeader, cur: -1}
+	for _, k := range e.keys {
+		if [encKeyIDLen]byte(header[4:8]) == k.id {
+			r.aead = k.aead
+			break
+		}
+	}
+	if r.aead == nil {
+		return nil, ErrUnknownKey
+	}
+	r.chunks = max((size+encChunk-1)/encChunk, 1)
+	return r, nil
+}
+
+// encReader 복호화하는 ReadSeekCloser (Stat 은 평문 크기)
+type encReader struct {
+	rc       io.ReadCloser
+	info     fs.FileInfo
+	aead     cipher.AEAD
+	header   []byte
+	size     int64 // 평문 크기
+	chunks   int64
+	pos      int64 // 평문 위치
+	innerPos int64 // rc 의 위치
+
+	cur    int64 // buf 에 풀어 둔 청크 번호 (-1 이면 없음)
+	sealed []byte
+	buf    []byte
+}
+
+func (r *encReader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	idx := r.pos / encChunk
+	if idx != r.cur {
+		if err := r.load(idx); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf[r.pos-idx*encChunk:])
+	r.pos += int64(n)
+	return n, nil
+}
+
+// load 청크 idx 를 읽어 풀어 (앞 청크를 이어 읽는 중이 아니면 inner 를 Seek)
+func (r *encReader) load(idx int64) error {
+	off := encHeader + idx*encSealed
+	if off != r.innerPos {
+		s, ok := r.rc.(io.Seeker)
+		if !ok {
+			return msg.New("저장소의 파일이 Seek 을 지원하지 않습니다")
+		}
+		if _, err := s.Seek(off, io.SeekStart); err != nil {
+			return err
+		}
+		r.innerPos = off
+	}
+	n := int64(encSealed)
+	if idx == r.chunks-1 {
+		n = r.size - idx*encChunk + encTag
+	}
+	if int64(cap(r.sealed)) < n {
+		r.sealed = make([]byte, n)
+	}
+	r.sealed = r.sealed[:n]
+	if _, err := io.ReadFull(r.rc, r.sealed); err != nil {
+		r.cur = -1
+		return err
+	}
+	r.innerPos += n
+	var err error
+	r.buf, err = r.aead.Open(r.buf[:0], encNonce(r.header[8:8+encPrefix], idx, idx == r.chunks-1), r.sealed, r.header)
+	if err != nil {
+		r.cur = -1
+		return msg.Errorf("청크 %d 복호화 실패 (키가 다르거나 파일이 손상됨): %w", idx, err)
+	}
+	r.cur = idx
+	return nil
+}
+
+func (r *encReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, msg.New("음수 위치로 Seek 할 수 없습니다")
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+func (r *encReader) Stat() (fs.FileInfo, error) { return r.info, nil }
+func (r *encReader) Close() error               { return r.rc.Close() }
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	k := make([]byte, 32)
+	rand.Read(k)
+	return k
+}
+
+func TestEncrypted(t *testing.T) {
+	st, err := NewEncrypted(NewMemory(), testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackend(t, st)
+}
+
+func TestEncryptedSizesAndSeek(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemory()
+	st, err := NewEncrypted(inner, testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{0, 1, encChunk - 1, encChunk, encChunk + 1, 3*encChunk + 100} {
+		data := make([]byte, size)
+		rand.Read(data)
+		w, _ := st.Create(ctx, "f")
+		// 청크 경계와 안 맞게 조금씩 써
+		for rest := data; len(rest) > 0; {
+			n := min(len(rest), 7777)
+			w.Write(rest[:n])
+			rest = rest[n:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, _ := inner.Stat(ctx, "f")
+		if raw.Size() != SealedSize(int64(size)) {
+			t.Errorf("%d: 암호문 %d 바이트, SealedSize %d", size, raw.Size(), SealedSize(int64(size)))
+		}
+		if n, ok := PlainSize(raw.Size()); !ok || n != int64(size) {
+			t.Errorf("%d: PlainSize = %d, %v", size, n, ok)
+		}
+		if info, _ := st.Stat(ctx, "f"); info.Size() != int64(size) {
+			t.Errorf("%d: Stat 크기 %d", size, info.Size())
+		}
+
+		r, err := st.Open(ctx, "f")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("%d: 읽은 내용 %d 바이트 (err=%v)", size, len(got), err)
+		}
+		// 청크를 걸치는 Range 처럼 중간에서 읽기, 끝에서 거꾸로
+		rs := r.(io.ReadSeeker)
+		for _, off := range []int{size / 2, max(size-10, 0), encChunk - 3} {
+			if off > size {
+				continue
+			}
+			rs.Seek(int64(off), io.SeekStart)
+			part := make([]byte, min(10, size-off))
+			if _, err := io.ReadFull(rs, part); err != nil || !bytes.Equal(part, data[off:off+len(part)]) {
+				t.Errorf("%d: %d 에서 읽기 %x (err=%v)", size, off, part, err)
+			}
+		}
+		if end, _ := rs.Seek(0, io.SeekEnd); end != int64(size) {
+			t.Errorf("%d: SeekEnd = %d", size, end)
+		}
+		r.Close()
+	}
+}
+
+func TestEncryptedTamper(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemory()
+	key := testKey(t)
+	st, _ := NewEncrypted(inner, key)
+	data := bytes.Repeat([]byte("secret!!"), encChunk/4) // 딱 두 청크
+	w, _ := st.Create(ctx, "f")
+	w.Write(data)
+	w.Close()
+
+	rawReader, _ := inner.Open(ctx, "f")
+	raw, _ := io.ReadAll(rawReader)
+	if bytes.Contains(raw, []byte("secret!!")) {
+		t.Fatal("저장된 내용에 평문이 보임")
+	}
+	readErr := func(content []byte, keys ...[]byte) error {
+		t.Helper()
+		m := NewMemory()
+		w, _ := m.Create(ctx, "f")
+		w.Write(content)
+		w.Close()
+		es, _ := NewEncrypted(m, keys...)
+		r, err := es.Open(ctx, "f")
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		_, err = io.ReadAll(r)
+		return err
+	}
+	if err := readErr(raw, key); err != nil {
+		t.Fatalf("그대로 읽기: %v", err)
+	}
+
+	flipped := bytes.Clone(raw)
+	flipped[encHeader+100] ^= 1
+	if err := readErr(flipped, key); err == nil {
+		t.Error("바뀐 암호문을 그대로 읽음")
+	}
+	// 마지막 청크를 잘라내면 앞 청크가 마지막 표시 없이 봉해져 있어서 실패해야 해
+	if err := readErr(raw[:encHeader+encSealed], key); err == nil {
+		t.Error("잘린 파일을 그대로 읽음")
+	}
+	if err := readErr(raw, testKey(t)); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("다른 키 = %v, want ErrUnknownKey", err)
+	}
+	// 새 키를 앞에 두고 예전 키를 뒤에 두면 예전 파일도 읽어
+	if err := readErr(raw, testKey(t), key); err != nil {
+		t.Errorf("키 교체 뒤 예전 파일: %v", err)
+	}
+	if err := readErr([]byte("평문으로 둔 예전 업로드 파일입니다"), key); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("평문 파일 = %v, want ErrNotEncrypted", err)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k1, k2 := testKey(t), testKey(t)
+	text := "# 첫 줄이 지금 키\n" + hex.EncodeToString(k1) + "\n\n  " + base64.StdEncoding.EncodeToString(k2) + "  \n"
+	keys, err := ParseKeys(text)
+	if err != nil || len(keys) != 2 || !bytes.Equal(keys[0], k1) || !bytes.Equal(keys[1], k2) {
+		t.Fatalf("ParseKeys = %x, %v", keys, err)
+	}
+	for _, bad := range []string{"", "# 주석만\n", "abcd\n", hex.EncodeToString(k1[:16])} {
+		if _, err := ParseKeys(bad); err == nil {
+			t.Errorf("ParseKeys(%q) 가 에러 없이 통과", bad)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Local 로컬 디스크 - 경로를 그대로 os 함수에 넘겨
+type Local struct{}
+
+func (Local) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	return os.Stat(filepath.FromSlash(name))
+}
+
+func (Local) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(filepath.FromSlash(dir))
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue // 나열하는 사이에 지워진 파일
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	return infos, nil
+}
+
+func (Local) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return streamio.OpenShared(filepath.FromSlash(name))
+}
+
+// Create 같은 디렉토리의 임시 파일에 쓰고 Close 에서 rename (streamio.CopyFile 과 같은 방식)
+// 이미 있는 FIFO/문자 장치면 rename 으로 덮지 않고 그대로 열어서 써
+func (Local) Create(ctx context.Context, name string) (Writer, error) {
+	name = filepath.FromSlash(name)
+	if info, err := os.Stat(name); err == nil && streamio.IsStream(info) {
+		file, err := os.OpenFile(name, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, err
+		}
+		return streamWriter{file}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Chmod(0644)
+	return &localWriter{File: tmp, final: name}, nil
+}
+
+func (Local) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.FromSlash(name))
+}
+
+func (Local) Chtimes(ctx context.Context, name string, atime, mtime time.Time) error {
+	return os.Chtimes(filepath.FromSlash(name), atime, mtime)
+}
+
+type localWriter struct {
+	*os.File
+	final string
+}
+
+func (w *localWriter) Close() error {
+	if err := w.File.Sync(); err != nil {
+		w.Abort()
+		return err
+	}
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+	if err := streamio.Rename(w.Name(), w.final); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+	return nil
+}
+
+func (w *localWriter) Abort() error {
+	w.File.Close()
+	return os.Remove(w.Name())
+}
+
+// streamWriter FIFO/장치에 바로 쓰는 Writer - 이미 내보낸 데이터는 되돌릴 수 없어서 Abort 도 닫기만 해
+type streamWriter struct {
+	*os.File
+}
+
+func (w streamWriter) Abort() error { return w.File.Close() }
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory 메모리 안의 저장소 - 테스트나 잠깐 띄우는 데모 서버용 (프로세스가 끝나면 사라져)
+// 디렉토리는 따로 없고 파일 이름의 중간 경로로만 있어 (S3 처럼). 여러 고루틴에서 같이 써도 돼.
+type Memory struct {
+	mu    sync.RWMutex
+	files map[string]memFile
+}
+
+type memFile struct {
+	data    []byte // Create 가 Close 할 때 통째로 바꿔 끼워서, 읽는 중인 쪽은 예전 내용을 끝까지 읽어
+	modTime time.Time
+}
+
+// NewMemory 빈 메모리 저장소
+func NewMemory() *Memory {
+	return &Memory{files: map[string]memFile{}}
+}
+
+// memName 슬래시 경로를 맵 키로 ("/a/../b" → "b", 루트는 "")
+func memName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func (m *Memory) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	name = memName(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f, ok := m.files[name]; ok {
+		return fileInfo{name: path.Base(name), size: int64(len(f.data)), mode: 0644, modTime: f.modTime}, nil
+	}
+	if m.isDirLocked(name) {
+		return fileInfo{name: path.Base("/" + name), mode: fs.ModeDir | 0755}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// isDirLocked name 아래에 파일이 하나라도 있는지 (루트는 늘 있어)
+func (m *Memory) isDirLocked(name string) bool {
+	if name == "" {
+		return true
+	}
+	for k := range m.files {
+		if strings.HasPrefix(k, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Memory) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	dir = memName(dir)
+	prefix := dir + "/"
+	if dir == "" {
+		prefix = ""
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.isDirLocked(dir) {
+		return nil, &fs.PathError{Op: "list", Path: dir, Err: fs.ErrNotExist}
+	}
+	seen := map[string]bool{}
+	var infos []fs.FileInfo
+	for k, f := range m.files {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		if sub, _, nested := strings.Cut(rest, "/"); nested {
+			if !seen[sub] {
+				seen[sub] = true
+				infos = append(infos, fileInfo{name: sub, mode: fs.ModeDir | 0755})
+			}
+			continue
+		}
+		infos = append(infos, fileInfo{name: rest, size: int64(len(f.data)), mode: 0644, modTime: f.modTime})
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+// Open *bytes.Reader 위라서 Seek 도 돼 (Stat 은 연 순간의 정보)
+func (m *Memory) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	name = memName(name)
+	m.mu.RLock()
+	f, ok := m.files[name]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	info := fileInfo{name: path.Base(name), size: int64(len(f.data)), mode: 0644, modTime: f.modTime}
+	return &memReader{Reader: bytes.NewReader(f.data), info: info}, nil
+}
+
+func (m *Memory) Create(ctx context.Context, name string) (Writer, error) {
+	return &memWriter{m: m, name: memName(name)}, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, name string) error {
+	name = memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; !ok {
+		if m.isDirLocked(name) {
+			return &fs.PathError{Op: "delete", Path: name, Err: fs.ErrInvalid} // 비지 않은 디렉토리
+		}
+		return &fs.PathError{Op: "delete", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *Memory) Chtimes(ctx context.Context, name string, atime, mtime time.Time) error {
+	name = memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[name]
+	if !ok {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+	f.modTime = mtime
+	m.files[name] = f
+	return nil
+}
+
+type memReader struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (r *memReader) Stat() (fs.FileInfo, error) { return r.info, nil }
+func (r *memReader) Close() error               { return nil }
+
+// memWriter 다 쓰고 Close 할 때 한 번에 보여 (그 전에는 예전 내용 그대로)
+type memWriter struct {
+	m    *Memory
+	name string
+	buf  bytes.Buffer
+	done bool
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	if w.done {
+		return 0, fs.ErrClosed
+	}
+	return w.buf.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	if w.done {
+		return fs.ErrClosed
+	}
+	w.done = true
+	w.m.mu.Lock()
+	defer w.m.mu.Unlock()
+	w.m.files[w.name] = memFile{data: bytes.Clone(w.buf.Bytes()), modTime: time.Now()}
+	return nil
+}
+
+func (w *memWriter) Abort() error {
+	w.done = true
+	w.buf.Reset()
+	return nil
+}
+
+func sortInfos(infos []fs.FileInfo) {
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+}
+
+// fileInfo 디스크 파일이 아닌 저장소(메모리, S3)의 fs.FileInfo
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fileInfo) Sys() any           { return nil }
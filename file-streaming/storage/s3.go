@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/s3"
+)
+
+// S3 S3 호환 버킷의 Prefix 아래를 저장소로 (객체 키 = Prefix/이름)
+// ⭐ 본문은 presigned URL 로 흘려보내 - Open 은 GET 응답을 그대로 읽고(Seek 하면 그 위치부터 Range 로 다시),
+// Create 는 파트 크기만큼만 메모리에 모아 멀티파트로 올려서 몇 GB 든 디스크에 한 번 받아 둘 필요가 없어.
+// 디렉토리는 따로 없고 키의 중간 경로로만 있어서, 빈 디렉토리는 만들 수도 볼 수도 없어.
+type S3 struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string // 비우면 버킷 전체
+	Upload s3.UploadOptions
+}
+
+// NewS3FromEnv s3://bucket/prefix 를 AWS 환경 변수의 키로 (s3.NewClientFromEnv)
+// endpoint 를 주면(MinIO 같은 자체 호스팅) 버킷을 경로에 넣는 방식으로 불러.
+func NewS3FromEnv(endpoint, location string) (*S3, error) {
+	bucket, prefix, err := s3.ParseURL(location)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s3.NewClientFromEnv(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client.PathStyle = endpoint != ""
+	return &S3{Client: client, Bucket: bucket, Prefix: strings.Trim(prefix, "/")}, nil
+}
+
+// key 이름의 객체 키
+func (s *S3) key(name string) string {
+	return strings.TrimPrefix(path.Join(s.Prefix, memName(name)), "/")
+}
+
+// dirPrefix dir 아래를 나열할 때의 키 접두사 ("" 또는 "a/b/")
+func (s *S3) dirPrefix(dir string) string {
+	if k := s.key(dir); k != "" {
+		return k + "/"
+	}
+	return ""
+}
+
+// notExist S3 의 404 를 fs.ErrNotExist 로 (errors.Is(err, os.ErrNotExist) 로 보게)
+func notExist(op, name string, err error) error {
+	if s3.NotFound(err) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return err
+}
+
+func (s *S3) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	obj, err := s.Client.HeadObject(ctx, s.Bucket, s.key(name))
+	if err == nil {
+		return fileInfo{name: path.Base(s.key(name)), size: obj.Size, mode: 0644, modTime: obj.LastModified}, nil
+	}
+	if !s3.NotFound(err) {
+		return nil, err
+	}
+	// 그 이름으로 시작하는 키가 있으면 디렉토리
+	objects, prefixes, lerr := s.Client.ListObjects(ctx, s.Bucket, s.dirPrefix(name), "/")
+	if lerr != nil || len(objects)+len(prefixes) == 0 {
+		return nil, notExist("stat", name, err)
+	}
+	return fileInfo{name: path.Base("/" + memName(name)), mode: fs.ModeDir | 0755}, nil
+}
+
+func (s *S3) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	prefix := s.dirPrefix(dir)
+	objects, prefixes, err := s.Client.ListObjects(ctx, s.Bucket, prefix, "/")
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(objects)+len(prefixes))
+	for _, p := range prefixes {
+		infos = append(infos, fileInfo{name: strings.TrimSuffix(strings.TrimPrefix(p, prefix), "/"), mode: fs.ModeDir | 0755})
+	}
+	for _, o := range objects {
+		name := strings.TrimPrefix(o.Key, prefix)
+		if name == "" {
+			continue // 콘솔이 "디렉토리" 로 만든 빈 객체 (a/b/)
+		}
+		infos = append(infos, fileInfo{name: name, size: o.Size, mode: 0644, modTime: o.LastModified})
+	}
+	sortInfos(infos)
+	return infos, nil
+}
+
+// Open *s3.ObjectReader 를 감싸서 Seek, Stat 도 돼
+func (s *S3) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	obj, err := s.Client.OpenObject(ctx, s.Bucket, s.key(name))
+	if err != nil {
+		return nil, notExist("open", name, err)
+	}
+	return s3File{obj}, nil
+}
+
+func (s *S3) Create(ctx context.Context, name string) (Writer, error) {
+	return s.Client.NewWriter(ctx, s.Bucket, s.key(name), s.Upload), nil
+}
+
+// Delete S3 는 없는 키를 지워도 성공이라 먼저 HEAD 로 확인해 (다른 저장소처럼 없으면 fs.ErrNotExist)
+func (s *S3) Delete(ctx context.Context, name string) error {
+	if _, err := s.Client.HeadObject(ctx, s.Bucket, s.key(name)); err != nil {
+		return notExist("delete", name, err)
+	}
+	return s.Client.DeleteObject(ctx, s.Bucket, s.key(name))
+}
+
+type s3File struct {
+	*s3.ObjectReader
+}
+
+func (f s3File) Stat() (fs.FileInfo, error) {
+	info := f.Info()
+	return fileInfo{name: path.Base(info.Key), size: info.Size, mode: 0644, modTime: info.LastModified}, nil
+}
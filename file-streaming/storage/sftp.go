@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHOptions SFTP 접속 옵션 - 비워두면 ssh 명령과 비슷하게 ~/.ssh 를 찾아봐
+type SSHOptions struct {
+	// Identity 개인키 파일 (비우면 ssh-agent, 그다음 ~/.ssh/id_ed25519, id_ecdsa, id_rsa 순서)
+	Identity string
+	// Password 비밀번호 인증 (비우면 SSH_PASSWORD 환경 변수, 그것도 없으면 안 써)
+	Password string
+	// KnownHosts 호스트 키 목록 (비우면 ~/.ssh/known_hosts)
+	KnownHosts string
+	// InsecureHostKey 호스트 키를 확인하지 않아 (테스트 서버용 - 중간자 공격에 무방비)
+	InsecureHostKey bool
+	Timeout         time.Duration // 접속 타임아웃 (0 이면 15초)
+}
+
+// SFTP ssh 위에서 SFTP 로 원격 파일을 다루는 저장소
+// ⭐ 읽기는 sftp.File 의 WriteTo 가 요청을 여러 개 겹쳐 보내서, 지연이 큰 링크에서도 대역폭을 채워.
+type SFTP struct {
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+// DialSFTP sftp://[user[:password]@]host[:port]/path 로 접속해서 저장소와 path 를 돌려줘
+// 경로가 /~/ 로 시작하면 원격 사용자의 홈 기준 상대 경로야.
+func DialSFTP(ctx context.Context, rawURL string, opts SSHOptions) (*SFTP, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Scheme != "sftp" || u.Host == "" {
+		return nil, "", msg.Errorf("잘못된 SFTP 주소: %q (sftp://user@host[:port]/path)", rawURL)
+	}
+
+	user := u.User.Username()
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	if pw, ok := u.User.Password(); ok {
+		opts.Password = pw
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	config, err := sshConfig(user, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	d := net.Dialer{Timeout: config.Timeout}
+	raw, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, "", err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(raw, host, config)
+	if err != nil {
+		raw.Close()
+		return nil, "", msg.Errorf("ssh 접속 실패 (%s): %w", host, err)
+	}
+	conn := ssh.NewClient(c, chans, reqs)
+
+	client, err := sftp.NewClient(conn, sftp.UseConcurrentWrites(true), sftp.MaxConcurrentRequestsPerFile(64))
+	if err != nil {
+		conn.Close()
+		return nil, "", msg.Errorf("sftp 세션 시작 실패: %w", err)
+	}
+
+	p := u.Path
+	switch {
+	case strings.HasPrefix(p, "/~/"):
+		p = strings.TrimPrefix(p, "/~/")
+	case p == "" || p == "/~":
+		p = "."
+	}
+	return &SFTP{conn: conn, client: client}, p, nil
+}
+
+// sshConfig 인증 수단과 호스트 키 확인 방식 결정
+func sshConfig(user string, opts SSHOptions) (*ssh.ClientConfig, error) {
+	var auths []ssh.AuthMethod
+
+	if opts.Identity == "" {
+		if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+			if ac, err := net.Dial("unix", sock); err == nil {
+				auths = append(auths, ssh.PublicKeysCallback(agent.NewClient(ac).Signers))
+			}
+		}
+	}
+	var signers []ssh.Signer
+	keyFiles := []string{opts.Identity}
+	if opts.Identity == "" {
+		home, _ := os.UserHomeDir()
+		keyFiles = []string{
+			filepath.Join(home, ".ssh", "id_ed25519"),
+			filepath.Join(home, ".ssh", "id_ecdsa"),
+			filepath.Join(home, ".ssh", "id_rsa"),
+		}
+	}
+	for _, name := range keyFiles {
+		pem, err := os.ReadFile(name)
+		if err != nil {
+			if opts.Identity != "" {
+				return nil, msg.Errorf("개인키 읽기 실패: %w", err)
+			}
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(pem)
+		if err != nil {
+			if opts.Identity != "" {
+				return nil, msg.Errorf("개인키 해석 실패 (암호가 걸린 키는 ssh-agent 로 써): %w", err)
+			}
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		auths = append(auths, ssh.PublicKeys(signers...))
+	}
+
+	password := opts.Password
+	if password == "" {
+		password = os.Getenv("SSH_PASSWORD")
+	}
+	if password != "" {
+		auths = append(auths, ssh.Password(password))
+	}
+	if len(auths) == 0 {
+		return nil, msg.New("ssh 인증 수단이 없습니다 (ssh-agent, 개인키, SSH_PASSWORD 중 하나 필요)")
+	}
+
+	hostKey := ssh.InsecureIgnoreHostKey()
+	if !opts.InsecureHostKey {
+		file := opts.KnownHosts
+		if file == "" {
+			home, _ := os.UserHomeDir()
+			file = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		cb, err := knownhosts.New(file)
+		if err != nil {
+			return nil, msg.Errorf("known_hosts 읽기 실패 (처음 접속하는 서버면 ssh 로 한 번 접속해서 등록해): %w", err)
+		}
+		hostKey = cb
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &ssh.ClientConfig{User: user, Auth: auths, HostKeyCallback: hostKey, Timeout: timeout}, nil
+}
+
+func (s *SFTP) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	return s.client.Stat(name)
+}
+
+func (s *SFTP) List(ctx context.Context, dir string) ([]fs.FileInfo, error) {
+	infos, err := s.client.ReadDirContext(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	return infos, nil
+}
+
+func (s *SFTP) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.client.Open(name)
+}
+
+// Create 원격 임시 파일에 쓰고 Close 에서 rename - 받는 쪽 프로그램이 반쪽짜리 파일을 보지 않게
+func (s *SFTP) Create(ctx context.Context, name string) (Writer, error) {
+	if err := s.client.MkdirAll(path.Dir(name)); err != nil {
+		return nil, err
+	}
+	var suffix [6]byte
+	rand.Read(suffix[:])
+	tmp := path.Join(path.Dir(name), "."+path.Base(name)+".tmp-"+hex.EncodeToString(suffix[:]))
+	f, err := s.client.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	return &sftpWriter{File: f, client: s.client, tmp: tmp, final: name}, nil
+}
+
+func (s *SFTP) Delete(ctx context.Context, name string) error {
+	return s.client.Remove(name)
+}
+
+func (s *SFTP) Chtimes(ctx context.Context, name string, atime, mtime time.Time) error {
+	return s.client.Chtimes(name, atime, mtime)
+}
+
+// Close SFTP 세션과 ssh 연결을 끊어
+func (s *SFTP) Close() error {
+	s.client.Close()
+	return s.conn.Close()
+}
+
+type sftpWriter struct {
+	*sftp.File
+	client     *sftp.Client
+	tmp, final string
+}
+
+func (w *sftpWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		w.client.Remove(w.tmp)
+		return err
+	}
+	// posix-rename 은 대상이 있어도 원자적으로 덮어써. 확장이 없는 서버면 지우고 rename.
+	if err := w.client.PosixRename(w.tmp, w.final); err != nil {
+		w.client.Remove(w.final)
+		if err := w.client.Rename(w.tmp, w.final); err != nil {
+			w.client.Remove(w.tmp)
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *sftpWriter) Abort() error {
+	w.File.Close()
+	return w.client.Remove(w.tmp)
+}
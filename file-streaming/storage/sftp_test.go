@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// pipeSFTP ssh 없이 net.Pipe 위에 sftp 서버를 붙인 저장소 (root 가 원격 작업 디렉토리)
+func pipeSFTP(t *testing.T, root string) *SFTP {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	server, err := sftp.NewServer(serverConn, sftp.WithServerWorkingDirectory(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	client, err := sftp.NewClientPipe(clientConn, clientConn, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return &SFTP{client: client}
+}
+
+func TestSFTP(t *testing.T) {
+	testBackend(t, pipeSFTP(t, t.TempDir()))
+}
+
+// 로컬 → SFTP → 로컬 복사, 수정 시각까지
+func TestCopyFileSFTP(t *testing.T) {
+	local, remote := t.TempDir(), t.TempDir()
+	st := pipeSFTP(t, remote)
+	ctx := context.Background()
+
+	src := filepath.Join(local, "app.log")
+	data := make([]byte, 3*remoteBufferSize+123)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	os.WriteFile(src, data, 0644)
+	mtime := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	os.Chtimes(src, mtime, mtime)
+
+	opts := streamio.CopyOptions{Preserve: streamio.PreserveTimes}
+	if n, err := CopyFile(ctx, Local{}, src, st, "logs/app.log", opts); err != nil || n != int64(len(data)) {
+		t.Fatalf("올리기 = %d, %v", n, err)
+	}
+	info, err := st.Stat(ctx, "logs/app.log")
+	if err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("원격 Stat = %v, %v, want 수정 시각 %v", info, err, mtime)
+	}
+	// 임시 파일은 남지 않아
+	if infos, _ := st.List(ctx, "logs"); len(infos) != 1 {
+		t.Errorf("원격 logs = %d 개", len(infos))
+	}
+
+	back := filepath.Join(local, "back.log")
+	if _, err := CopyFile(ctx, st, "logs/app.log", Local{}, back, streamio.CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(back)
+	if string(got) != string(data) {
+		t.Error("내려받은 내용이 다름")
+	}
+
+	r, _ := st.Open(ctx, "logs/app.log")
+	defer r.Close()
+	if n, _ := io.Copy(io.Discard, r); n != int64(len(data)) {
+		t.Errorf("원격 Open 으로 %d 바이트", n)
+	}
+}
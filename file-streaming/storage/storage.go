@@ -0,0 +1,176 @@
+// Package storage 는 로컬 디스크와 원격 서버(SFTP 등)를 같은 방식으로 읽고 쓰게 해주는 추상화야.
+// copy/sync/analyze 같은 명령이 경로 대신 Storage + 경로를 받으면, 상대가 어디 있든 똑같이 스트리밍해.
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Storage 파일을 나열/읽기/쓰기/삭제할 수 있는 저장소
+// ⭐ 경로는 슬래시로 구분해 (원격 저장소는 전부 슬래시라서). 로컬 구현이 OS 구분자로 바꿔줘.
+type Storage interface {
+	// Stat 파일/디렉토리 정보 (링크는 따라가)
+	Stat(ctx context.Context, name string) (fs.FileInfo, error)
+	// List 디렉토리 바로 아래 항목들 (이름순)
+	List(ctx context.Context, dir string) ([]fs.FileInfo, error)
+	// Open 읽기용으로 열기
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Create 쓰기용으로 만들기 - 상위 디렉토리가 없으면 만들어. Close 해야 name 에 나타나.
+	Create(ctx context.Context, name string) (Writer, error)
+	// Delete 파일이나 빈 디렉토리 삭제
+	Delete(ctx context.Context, name string) error
+}
+
+// Writer Create 가 돌려주는 쓰기 핸들
+// ⭐ 임시 이름에 쓰다가 Close 에서 rename 하니까, 중간에 실패하면 Abort 로 버려서 대상에 반쪽짜리 파일이 안 남아.
+type Writer interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// TimeSetter 수정 시각을 바꿀 수 있는 저장소 (선택 구현)
+// 동기화가 다음 번에 크기+수정시각으로 비교하려면 대상 시각을 원본에 맞춰야 해.
+type TimeSetter interface {
+	Chtimes(ctx context.Context, name string, atime, mtime time.Time) error
+}
+
+// Location 위치 문자열 하나를 해석한 결과 (저장소 + 그 안의 경로)
+type Location struct {
+	Storage Storage
+	Path    string
+	Raw     string // 사용자가 준 원래 문자열 (출력용)
+}
+
+// Remote 로컬 디스크가 아닌지
+func (l Location) Remote() bool {
+	_, local := l.Storage.(Local)
+	return !local
+}
+
+// Close 원격 연결이면 끊어
+func (l Location) Close() error {
+	if c, ok := l.Storage.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// IsRemote 위치 문자열이 원격 주소(scheme://)인지
+func IsRemote(location string) bool {
+	return strings.HasPrefix(location, "sftp://")
+}
+
+// Resolve 위치 문자열을 저장소로
+//
+//	sftp://user@host:22/var/log/app.log  → SFTP (ssh 로 접속)
+//	./data/app.log                        → 로컬 디스크
+func Resolve(ctx context.Context, location string, ssh SSHOptions) (Location, error) {
+	if !IsRemote(location) {
+		return Location{Storage: Local{}, Path: location, Raw: location}, nil
+	}
+	s, p, err := DialSFTP(ctx, location, ssh)
+	if err != nil {
+		return Location{}, err
+	}
+	return Location{Storage: s, Path: p, Raw: location}, nil
+}
+
+// remoteBufferSize 원격 대상으로 복사할 때 최소 버퍼 크기
+const remoteBufferSize = 1 << 20
+
+// CopyFile 저장소 사이 파일 복사 (streamio.CopyFile 의 저장소 버전)
+// 재시도/속도 제한/훅은 opts 그대로 쓰고, opts.Preserve 중에서는 수정 시각만 옮겨 (권한·소유자는 저장소마다 의미가 달라서).
+func CopyFile(ctx context.Context, src Storage, srcPath string, dst Storage, dstPath string, opts streamio.CopyOptions) (int64, error) {
+	hooks := opts.Hooks
+	if hooks == nil {
+		hooks = streamio.NopHooks{}
+	}
+	info := streamio.TransferInfo{ID: path.Base(srcPath), Src: srcPath, Dst: dstPath, Size: -1}
+	srcInfo, err := src.Stat(ctx, srcPath)
+	if err != nil {
+		return 0, err
+	}
+	if srcInfo.IsDir() {
+		return 0, msg.Errorf("디렉토리는 복사할 수 없습니다: %s", srcPath)
+	}
+	info.Size = streamio.KnownSize(srcInfo)
+	if streamio.IsStream(srcInfo) {
+		opts.Retries = 0 // FIFO/장치는 다시 읽을 수 없어
+	}
+	// 원격 대상은 Write 한 번이 왕복 한 번이라 버퍼가 작으면 지연마다 멈춰 (큰 버퍼는 여러 요청으로 겹쳐 보내)
+	if _, remote := dst.(*SFTP); remote && opts.BufferSize < remoteBufferSize {
+		opts.BufferSize = remoteBufferSize
+	}
+
+	hooks.OnStart(info)
+	start := time.Now()
+
+	// 시작/완료는 여기서 한 번만 알리고, 회차별 복사는 진행률만 전달
+	attemptOpts := opts
+	attemptOpts.Hooks = progressOnly{hooks}
+
+	var written int64
+	for attempt := 0; ; attempt++ {
+		written, err = copyOnce(ctx, src, srcPath, dst, dstPath, info, attemptOpts)
+		if err == nil || attempt >= opts.Retries || ctx.Err() != nil {
+			break
+		}
+
+		hooks.OnRetry(info, attempt+1, err)
+		select {
+		case <-time.After(opts.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+		}
+	}
+	if err == nil && opts.Preserve&streamio.PreserveTimes != 0 {
+		if ts, ok := dst.(TimeSetter); ok {
+			err = ts.Chtimes(ctx, dstPath, srcInfo.ModTime(), srcInfo.ModTime())
+		}
+	}
+
+	if err != nil {
+		hooks.OnError(info, err)
+		return written, err
+	}
+	hooks.OnComplete(info, written, time.Since(start))
+	return written, nil
+}
+
+func copyOnce(ctx context.Context, src Storage, srcPath string, dst Storage, dstPath string, info streamio.TransferInfo, opts streamio.CopyOptions) (int64, error) {
+	in, err := src.Open(ctx, srcPath)
+	if err != nil {
+		return 0, msg.Errorf("소스 파일 열기 실패: %w", err)
+	}
+	defer in.Close()
+
+	out, err := dst.Create(ctx, dstPath)
+	if err != nil {
+		return 0, msg.Errorf("대상 파일 생성 실패: %w", err)
+	}
+	written, err := streamio.Copy(ctx, out, in, info, opts)
+	if err != nil {
+		out.Abort()
+		return written, msg.Errorf("복사 실패: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return written, msg.Errorf("대상 파일 닫기 실패: %w", err)
+	}
+	return written, nil
+}
+
+// progressOnly 진행률만 전달하는 훅
+type progressOnly struct {
+	streamio.Hooks
+}
+
+func (progressOnly) OnStart(streamio.TransferInfo)                          {}
+func (progressOnly) OnComplete(streamio.TransferInfo, int64, time.Duration) {}
+func (progressOnly) OnError(streamio.TransferInfo, error)                   {}
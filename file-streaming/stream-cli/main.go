@@ -0,0 +1,260 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fetch"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// step09 서버용 작은 업로드/다운로드 클라이언트
+//
+//	go run ./stream-cli upload file.bin a.log           → POST /upload (파일마다 한 번)
+//	go run ./stream-cli download file.bin [대상]         → GET /download?file=file.bin
+//	go run ./stream-cli upload -server https://files.example.com -api-key KEY big.iso
+//
+// 보내는 파일과 받는 응답 본문을 streamio.ProgressReader 로 감싸서 ProgressReporter 가 막대(속도, 남은 시간)를 그려.
+// 막대는 stderr 가 터미널일 때만 나오고(-progress none|text|bar|json), 결과는 stdout 에 한 줄씩.
+// 올리고 받으면서 sha256 을 재서 서버가 알려 준 X-Content-SHA256 과 맞춰 봐 - 다르면 실패 (받은 파일은 남기지 않아).
+func main() {
+	// 도움말, 결과, 에러도 FS_LANG / -lang 을 따라가게 (명령을 고르기 전의 사용법까지)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stream-cli: %v\n", err)
+		os.Exit(2)
+	}
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+	switch cmd {
+	case "upload", "download":
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		msg.Fprintf(os.Stderr, "알 수 없는 명령: %s\n\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	locale.RegisterFlags(fs)
+	server := fs.String("server", cmp.Or(os.Getenv("FS_SERVER"), "http://localhost:8080"), msg.T("서버 주소 (FS_SERVER)"))
+	apiKey := fs.String("api-key", os.Getenv("FS_API_KEY"), msg.T("서버의 API 키나 JWT - Authorization: Bearer 로 보내 (FS_API_KEY)"))
+	progress := streamio.ProgressBar
+	fs.Var(&progress, "progress", msg.T("진행률 출력 방식 (none|text|bar|json) - stderr 로 나가"))
+	fs.Usage = usage
+	fs.Parse(os.Args[2:])
+	logging.SetupFromEnv()
+
+	// text, bar 는 \r 로 한 줄을 덮어써서 stderr 가 파이프나 파일이면 끄기
+	if (progress == streamio.ProgressText || progress == streamio.ProgressBar) && !streamio.IsTerminal(os.Stderr) {
+		progress = streamio.ProgressNone
+	}
+	c := &client{server: strings.TrimSuffix(*server, "/"), apiKey: *apiKey, progress: progress}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args := fs.Args(); {
+	case cmd == "upload" && len(args) > 0:
+		for _, path := range args {
+			if err = c.upload(ctx, path); err != nil {
+				break
+			}
+		}
+	case cmd == "download" && (len(args) == 1 || len(args) == 2):
+		dest := args[0]
+		if len(args) == 2 {
+			dest = args[1]
+		}
+		err = c.download(ctx, args[0], dest)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stream-cli %s: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, msg.T(`사용법:
+  stream-cli upload [옵션] <파일>...       파일을 /upload 로 올리기
+  stream-cli download [옵션] <이름> [대상]  /download?file=<이름> 을 받아 대상(없으면 같은 이름, 디렉토리면 그 안)에 저장
+
+옵션:
+  -server <URL>     서버 주소 (기본 http://localhost:8080, FS_SERVER)
+  -api-key <키>     API 키나 JWT (Authorization: Bearer, FS_API_KEY)
+  -progress <방식>  none|text|bar|json (기본 bar, stderr 가 터미널이 아니면 끔)
+  -lang <언어>      ko|en (기본 FS_LANG, 없으면 LANG)`))
+}
+
+// client 서버 주소와 자격 증명, 진행률 방식
+type client struct {
+	server   string
+	apiKey   string
+	progress streamio.ProgressMode
+}
+
+// uploadedFile /upload 의 JSON 응답에서 파일 하나 (서버의 uploadedFile)
+type uploadedFile struct {
+	Name     string `json:"name"`
+	Original string `json:"original"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// upload path 를 멀티파트 "file" 파트 하나로 올려 - 파일은 한 번만 읽고 읽는 만큼 보내
+func (c *client) upload(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	bar := streamio.NewProgressReporter(filepath.Base(path), fi.Size(), c.progress, os.Stderr, 0)
+	h := sha256.New()
+	body := streamio.NewProgressReader(io.TeeReader(f, h), fi.Size(), func(current, _ int64) { bar.Set(current) })
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(part, body)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.server+"/upload", pr)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	bar.Start()
+	resp, err := c.do(req)
+	bar.Stop()
+	if err != nil {
+		pr.CloseWithError(err) // 고루틴이 파이프 쓰기에서 멈춰 있지 않게
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Files []uploadedFile `json:"files"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || len(result.Files) != 1 {
+		return msg.Errorf("서버 응답을 알아볼 수 없습니다: %v", err)
+	}
+	got, sum := result.Files[0], hex.EncodeToString(h.Sum(nil))
+	if got.Size != fi.Size() || got.SHA256 != sum {
+		return msg.Errorf("서버가 받은 내용이 다릅니다: %d 바이트 sha256 %s (보낸 건 %d 바이트 %s)", got.Size, got.SHA256, fi.Size(), sum)
+	}
+	if got.Name != got.Original {
+		msg.Printf("올림: %s → %s (같은 이름이 있어서 새 이름으로, %d 바이트, sha256 %s)\n", path, got.Name, got.Size, got.SHA256)
+		return nil
+	}
+	msg.Printf("올림: %s → %s (%d 바이트, sha256 %s)\n", path, got.Name, got.Size, got.SHA256)
+	return nil
+}
+
+// download name 을 dest 로 - 같은 디렉토리의 임시 파일에 받고 sha256 이 맞으면 rename (중간에 실패하면 dest 는 그대로)
+func (c *client) download(ctx context.Context, name, dest string) error {
+	if fi, err := os.Stat(dest); err == nil && fi.IsDir() {
+		dest = filepath.Join(dest, filepath.Base(name))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+"/download?file="+url.QueryEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := max(resp.ContentLength, 0) // 모르면(-1, gzip 등) 막대 없이 받은 양만
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	bar := streamio.NewProgressReporter(name, total, c.progress, os.Stderr, 0)
+	h := sha256.New()
+	body := streamio.NewProgressReader(resp.Body, total, func(current, _ int64) { bar.Set(current) })
+	bar.Start()
+	n, err := io.Copy(io.MultiWriter(tmp, h), body)
+	bar.Stop()
+	if err != nil {
+		return err
+	}
+	if total > 0 && n != total {
+		return msg.Errorf("%d 바이트 중 %d 바이트만 받았습니다", total, n)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	want := resp.Header.Get(fetch.ChecksumHeader)
+	if want != "" && !strings.EqualFold(want, sum) {
+		return msg.Errorf("sha256 이 다릅니다: 받은 %s, 서버 %s", sum, want)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := streamio.Rename(tmp.Name(), dest); err != nil {
+		return err
+	}
+	if want == "" {
+		msg.Printf("받음: %s → %s (%d 바이트, sha256 %s - 서버가 알려 주지 않아 검증 안 함)\n", name, dest, n, sum)
+		return nil
+	}
+	msg.Printf("받음: %s → %s (%d 바이트, sha256 확인 %s)\n", name, dest, n, sum)
+	return nil
+}
+
+// do 자격 증명을 붙여 보내고 2xx 가 아니면 서버가 보낸 메시지로 에러
+func (c *client) do(req *http.Request) (*http.Response, error) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, errors.New(resp.Status + ": " + strings.TrimSpace(string(text)))
+	}
+	return resp, nil
+}
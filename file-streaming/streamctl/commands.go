@@ -0,0 +1,1629 @@
+package main
+
+import (
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/backup"
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/daemon"
+	"github.com/hellotect2022go/study-go/file-streaming/delta"
+	"github.com/hellotect2022go/study-go/file-streaming/fetch"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/s3"
+	"github.com/hellotect2022go/study-go/file-streaming/search"
+	"github.com/hellotect2022go/study-go/file-streaming/step06-log-analyzer/analyzer"
+	"github.com/hellotect2022go/study-go/file-streaming/step09-http-streaming/server"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/transfer"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// transferResult copy/compress/hash 공통 JSON 결과
+type transferResult struct {
+	Src       string  `json:"src"`
+	Dst       string  `json:"dst,omitempty"`
+	Bytes     int64   `json:"bytes"`
+	OutBytes  int64   `json:"out_bytes,omitempty"`
+	SHA256    string  `json:"sha256,omitempty"`
+	ElapsedMS int64   `json:"elapsed_ms"`
+	MBPerSec  float64 `json:"mb_per_sec"`
+}
+
+func newTransferResult(src, dst string, n int64, elapsed time.Duration) transferResult {
+	r := transferResult{Src: src, Dst: dst, Bytes: n, ElapsedMS: elapsed.Milliseconds()}
+	if secs := elapsed.Seconds(); secs > 0 {
+		r.MBPerSec = float64(n) / secs / (1 << 20)
+	}
+	return r
+}
+
+// copy - 임시 파일 + rename 으로 안전하게 복사 (streamio.CopyFile, 원격이면 storage.CopyFile)
+func copyCommand() *command {
+	var preserve, sparse *bool
+	var ssh storage.SSHOptions
+	var filters filterFlags
+	return &command{
+		usage: "<원본|-> <대상|->",
+		help:  "파일 복사 (원자적 교체, 재시도, 속도 제한, sftp:// 원격, - 는 stdin/stdout, -filter 로 외부 명령 변환)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			preserve = fs.Bool("preserve", false, msg.T("권한/시각/소유자/xattr 까지 복제 (원격이면 수정 시각만)"))
+			sparse = fs.Bool("sparse", false, msg.T("0 블록을 구멍으로 남겨 (sparse 파일)"))
+			fs.IntVar(&cfg.Transfer.Retries, "retries", cfg.Transfer.Retries, msg.T("실패 시 재시도 횟수"))
+			filters.register(fs)
+			registerSSH(fs, &ssh)
+			cfg.Cache.RegisterFlags(fs)
+			cfg.Checksum.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			opts := c.copyOptions()
+			opts.Sparse = *sparse
+			opts.Retries = c.cfg.Transfer.Retries
+			opts.RetryDelay = time.Second
+			if *preserve {
+				opts.Preserve = streamio.PreserveAll
+			}
+
+			src, dst := args[0], args[1]
+			if isStdio(src) || isStdio(dst) || filters.enabled() {
+				// 필터를 거치면 내용이 바뀌어서 원본 그대로 복사하는 길(재시도, sparse, preserve)은 못 써 - 스트림으로 흘려
+				if *sparse || *preserve {
+					return msg.New("-sparse, -preserve 는 파일끼리 필터 없이 복사할 때만 쓸 수 있어")
+				}
+				stages, err := filters.transforms(c.cfg.Plugins)
+				if err != nil {
+					return err
+				}
+				return copyStdio(ctx, c, src, dst, opts, ssh, stages...)
+			}
+			if storage.IsRemote(src) || storage.IsRemote(dst) {
+				if *sparse {
+					return msg.New("-sparse 는 로컬끼리 복사할 때만 쓸 수 있어")
+				}
+				return copyRemote(ctx, c, src, dst, opts, ssh)
+			}
+			// 대상이 디렉토리면 그 안에 같은 이름으로 (cp 와 같은 동작)
+			if info, err := os.Stat(dst); err == nil && info.IsDir() {
+				dst = filepath.Join(dst, filepath.Base(src))
+			}
+
+			sums, err := c.checksumRecorder()
+			if err != nil {
+				return err
+			}
+			defer sums.close()
+
+			start := time.Now()
+			n, err := streamio.CopyFile(ctx, src, dst, opts)
+			if err != nil {
+				return err
+			}
+			sums.record(dst)
+			r := newTransferResult(src, dst, n, time.Since(start))
+			return c.print(r, msg.Sprintf("복사 완료: %s → %s (%d 바이트, %.2f MB/s)", src, dst, n, r.MBPerSec))
+		},
+	}
+}
+
+// resolveSource 읽을 쪽 주소 열기 - 원격이고 -cache 가 있으면 디스크 캐시를 앞에 둬
+func (c *common) resolveSource(ctx context.Context, arg string, ssh storage.SSHOptions) (storage.Location, error) {
+	loc, err := storage.Resolve(ctx, arg, ssh)
+	if err != nil || !loc.Remote() || c.cfg.Cache.Dir == "" {
+		return loc, err
+	}
+	cache, err := storage.NewCache(loc.Storage, storage.CacheID(arg), c.cfg.Cache.Options())
+	if err != nil {
+		loc.Close()
+		return storage.Location{}, err
+	}
+	loc.Storage = cache
+	return loc, nil
+}
+
+// copyRemote 한쪽이라도 sftp:// 면 저장소끼리 스트리밍 (원격 → 원격도 이 프로세스를 거쳐 가)
+func copyRemote(ctx context.Context, c *common, srcArg, dstArg string, opts streamio.CopyOptions, ssh storage.SSHOptions) error {
+	src, err := c.resolveSource(ctx, srcArg, ssh)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := storage.Resolve(ctx, dstArg, ssh)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	dstPath := dst.Path
+	if info, err := dst.Storage.Stat(ctx, dstPath); err == nil && info.IsDir() {
+		dstPath = path.Join(dstPath, path.Base(src.Path))
+	}
+
+	start := time.Now()
+	n, err := storage.CopyFile(ctx, src.Storage, src.Path, dst.Storage, dstPath, opts)
+	if err != nil {
+		return err
+	}
+	r := newTransferResult(srcArg, dstArg, n, time.Since(start))
+	return c.print(r, msg.Sprintf("복사 완료: %s → %s (%d 바이트, %.2f MB/s)", srcArg, dstArg, n, r.MBPerSec))
+}
+
+// registerSSH sftp:// 주소에 쓸 접속 옵션 (copy/sync/analyze 공통)
+func registerSSH(fs *flag.FlagSet, o *storage.SSHOptions) {
+	fs.StringVar(&o.Identity, "identity", "", msg.T("sftp:// 접속에 쓸 개인키 (기본: ssh-agent, ~/.ssh/id_*)"))
+	fs.StringVar(&o.KnownHosts, "known-hosts", "", msg.T("호스트 키 목록 (기본: ~/.ssh/known_hosts)"))
+	fs.BoolVar(&o.InsecureHostKey, "insecure-host-key", false, msg.T("호스트 키 확인 안 함 (테스트 서버용)"))
+}
+
+// split - 큰 파일을 청크로 나누고 체크섬 매니페스트 저장 (streamio.Split)
+func splitCommand() *command {
+	var size, dir, manifest *string
+	var bytesMode *bool
+	return &command{
+		usage: "<파일|->",
+		help:  "파일(- 면 stdin)을 청크로 분할 (기본: 줄 단위로 자름, 매니페스트 저장)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			size = fs.String("size", "100MB", msg.T("목표 청크 크기"))
+			dir = fs.String("dir", ".", msg.T("청크를 저장할 디렉토리"))
+			manifest = fs.String("manifest", "", msg.T("매니페스트 경로 (기본: <dir>/chunks.json)"))
+			bytesMode = fs.Bool("bytes", false, msg.T("줄바꿈과 상관없이 정확히 -size 바이트마다 자르기 (바이너리용)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			chunkSize, err := gendata.ParseSize(*size)
+			if err != nil || chunkSize <= 0 {
+				return msg.Errorf("잘못된 -size 값: %q", *size)
+			}
+			if err := os.MkdirAll(*dir, 0755); err != nil {
+				return err
+			}
+			manifestPath := *manifest
+			if manifestPath == "" {
+				manifestPath = filepath.Join(*dir, "chunks.json")
+			}
+
+			opts := streamio.SplitOptions{
+				ChunkSize:    chunkSize,
+				Mode:         streamio.SplitLines,
+				Dir:          *dir,
+				ManifestPath: manifestPath,
+			}
+			if *bytesMode {
+				opts.Mode = streamio.SplitBytes
+			}
+
+			var chunks []streamio.Chunk
+			if isStdio(args[0]) {
+				chunks, err = streamio.SplitReader(os.Stdin, "stdin", opts)
+			} else {
+				chunks, err = streamio.Split(args[0], opts)
+			}
+			if err != nil {
+				return err
+			}
+			return c.print(map[string]any{"source": args[0], "manifest": manifestPath, "chunks": chunks},
+				msg.Sprintf("%d개 청크로 분할 완료 (매니페스트: %s)", len(chunks), manifestPath))
+		},
+	}
+}
+
+// join - 매니페스트(또는 청크 디렉토리)대로 합치면서 체크섬 검증 (streamio.Merge)
+func joinCommand() *command {
+	return &command{
+		usage: "<chunks.json|청크 디렉토리> <출력 파일|->",
+		help:  "분할된 청크를 순서대로 병합 (매니페스트가 있으면 체크섬 검증, - 면 stdout 으로)",
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			source, output := args[0], args[1]
+
+			var manifest streamio.ChunkManifest
+			var err error
+			if info, statErr := os.Stat(source); statErr == nil && info.IsDir() {
+				manifest, err = streamio.FindChunks(source)
+			} else {
+				manifest, err = streamio.ReadChunkManifest(source)
+			}
+			if err != nil {
+				return err
+			}
+
+			start := time.Now()
+			merge := func() error { return streamio.Merge(output, manifest) }
+			if isStdio(output) {
+				// stdout 은 임시 파일이 없어서 검증에 실패하면 앞부분이 이미 나가 있어 - 종료 코드로 알려
+				c.useStdout()
+				merge = func() error {
+					_, err := streamio.MergeTo(os.Stdout, manifest)
+					return err
+				}
+			}
+			if err := merge(); err != nil {
+				var checksumErr *streamio.ChecksumError
+				if errors.As(err, &checksumErr) && checksumErr.Index > 0 {
+					return msg.Errorf("청크 %d (%s) 를 다시 받아야 해: %w", checksumErr.Index, checksumErr.Path, err)
+				}
+				return err
+			}
+
+			var total int64
+			for _, chunk := range manifest.Chunks {
+				total += chunk.Size
+			}
+			r := newTransferResult(source, output, total, time.Since(start))
+			r.SHA256 = manifest.SHA256
+			return c.print(r, msg.Sprintf("병합 완료: %s (%d개 청크, %d 바이트)", output, len(manifest.Chunks), total))
+		},
+	}
+}
+
+// compress - gzip 압축/해제, 스트리밍이라 파일 크기와 상관없이 메모리는 버퍼만큼만 써
+func compressCommand() *command {
+	var decompress *bool
+	var output *string
+	var filters filterFlags
+	return &command{
+		usage: "<파일|-> [출력|-]",
+		help:  "gzip 압축 (-d 면 해제, - 는 stdin/stdout, -filter 는 압축 전/해제 후 내용에)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			decompress = fs.Bool("d", false, msg.T("압축 해제"))
+			cfg.Compress.RegisterFlags(fs)
+			output = fs.String("o", "", msg.T("출력 파일, 두 번째 인자와 같아 (기본: <파일>.gz, 해제면 .gz 를 뗀 이름, 입력이 - 면 stdout)"))
+			filters.register(fs)
+			cfg.Checksum.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			opts := c.copyOptions()
+			stages, err := filters.transforms(c.cfg.Plugins)
+			if err != nil {
+				return err
+			}
+
+			src := args[0]
+			dst := *output
+			if len(args) > 1 {
+				if dst != "" && dst != args[1] {
+					return msg.New("출력은 -o 나 두 번째 인자 중 하나로만 줘")
+				}
+				dst = args[1]
+			}
+			if dst == "" {
+				switch {
+				case isStdio(src):
+					dst = stdioArg // stdin 은 이름이 없으니 결과도 stdout 으로 (gzip 과 같은 동작)
+				case *decompress:
+					dst = strings.TrimSuffix(src, ".gz")
+					if dst == src {
+						dst = src + ".out"
+					}
+				default:
+					dst = src + ".gz"
+				}
+			}
+			if isStdio(dst) {
+				if !*decompress {
+					if err := checkBinaryStdout(); err != nil {
+						return err
+					}
+				}
+				c.useStdout()
+			}
+
+			sums, err := c.checksumRecorder()
+			if err != nil {
+				return err
+			}
+			defer sums.close()
+
+			start := time.Now()
+			in, out, err := compressFile(ctx, src, dst, *decompress, c.cfg.Compress.Level, opts, stages...)
+			if err != nil {
+				return err
+			}
+			if !isStdio(dst) {
+				sums.record(dst)
+			}
+			r := newTransferResult(src, dst, in, time.Since(start))
+			r.OutBytes = out
+			verb := msg.T("압축")
+			if *decompress {
+				verb = msg.T("압축 해제")
+			}
+			return c.print(r, msg.Sprintf("%s 완료: %s → %s (%d → %d 바이트)", verb, src, dst, in, out))
+		},
+	}
+}
+
+// compressFile 원본에서 읽은 바이트(in)와 결과 파일 크기(out)를 돌려줘
+// 진행률과 속도 제한은 원본 읽기 기준이야. src/dst 가 - 면 stdin/stdout.
+// filters 는 압축 안 된 쪽에 걸려 - 압축이면 gzip 에 넣기 전, 해제면 풀고 난 뒤.
+func compressFile(ctx context.Context, src, dst string, decompress bool, level int, opts streamio.CopyOptions, filters ...streamio.Transform) (in, out int64, err error) {
+	source := os.Stdin
+	info := stdinInfo(dst)
+	if !isStdio(src) {
+		if source, err = os.Open(src); err != nil {
+			return 0, 0, err
+		}
+		defer source.Close()
+		info = streamio.TransferInfo{ID: filepath.Base(src), Src: src, Dst: dst, Size: -1}
+		if fi, err := source.Stat(); err == nil {
+			info.Size = streamio.KnownSize(fi)
+		}
+	}
+
+	target := os.Stdout
+	if !isStdio(dst) {
+		if target, err = os.Create(dst); err != nil {
+			return 0, 0, err
+		}
+		// 실패하면 반쯤 쓴 결과는 지워 - 대상이 FIFO/장치면 지우지 않아 (mkfifo 로 만든 파이프가 사라지면 안 되니까)
+		removable := true
+		if fi, statErr := target.Stat(); statErr == nil {
+			removable = fi.Mode().IsRegular()
+		}
+		defer func() {
+			target.Close()
+			if err != nil && removable {
+				os.Remove(dst)
+			}
+		}()
+	}
+
+	// ⭐ 트레이스: 압축 단계 전체 + 압축된 쪽(파일) 입출력을 따로 재서, gzip CPU 와 디스크 중 어디가 느린지 보이게 해
+	ctx, st := streamio.StartStage(ctx, "compress",
+		attribute.String("transfer.src", src), attribute.String("transfer.dst", dst),
+		attribute.Bool("gzip.decompress", decompress), attribute.Int("gzip.level", level))
+	defer func() {
+		st.Add(in)
+		st.SetAttributes(attribute.Int64("gzip.out_bytes", out))
+		st.End(err)
+	}()
+	_, gzIO := streamio.StartStage(ctx, "gzip.file")
+	defer func() { gzIO.End(err) }()
+
+	if decompress {
+		// 진행률은 압축된 원본을 얼마나 읽었는지로 보여줘 (풀린 크기는 미리 알 수 없으니까)
+		counter := &countingReader{r: gzIO.Reader(source)}
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(counter)
+		if err != nil {
+			return 0, 0, msg.Errorf("gzip 헤더 읽기 실패: %w", err)
+		}
+		defer gz.Close()
+
+		plain, err := streamio.Chain(ctx, gz, filters...)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer plain.Close()
+
+		hooks := opts.Hooks
+		opts.Hooks = nil
+		hooks.OnStart(info)
+		progress := streamio.NewProgressReader(plain, info.Size, func(int64, int64) {
+			hooks.OnProgress(info, counter.n)
+		})
+		out, err = streamio.Copy(ctx, target, progress, info, opts)
+		if err != nil {
+			hooks.OnError(info, err)
+			return counter.n, out, err
+		}
+		hooks.OnComplete(info, counter.n, 0)
+		return counter.n, out, nil
+	}
+
+	// 결과 크기는 쓴 만큼 세 (FIFO 로 내보내면 Stat 크기가 0 이라)
+	counter := &countingWriter{w: target}
+	gw, err := gzip.NewWriterLevel(gzIO.Writer(counter), level)
+	if err != nil {
+		return 0, 0, err
+	}
+	// 필터를 거치면 gzip 에 들어가는 크기를 미리 알 수 없어서 진행률은 바이트만, in 은 원본에서 읽은 양
+	read := &countingReader{r: source}
+	plain, err := streamio.Chain(ctx, read, filters...)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer plain.Close()
+	if len(filters) > 0 {
+		info.Size = -1
+	}
+	_, err = streamio.Copy(ctx, gw, plain, info, opts)
+	if err != nil {
+		return read.n, 0, err
+	}
+	if err = gw.Close(); err != nil {
+		return read.n, 0, err
+	}
+	return read.n, counter.n, nil
+}
+
+// countingReader 읽은 바이트 수 세기
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countingWriter 쓴 바이트 수 세기
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// analyze - step06 로그 분석기
+func analyzeCommand() *command {
+	var searchIndex *string
+	var ssh storage.SSHOptions
+	var filters filterFlags
+	return &command{
+		usage: "<로그 파일|->",
+		help:  "로그 분석 (레벨별 개수, IP 통계, 에러 샘플, sftp:// 원격, - 면 stdin)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			cfg.Analyzer.RegisterFlags(fs)
+			searchIndex = fs.String("search-index", "", msg.T("분석한 로그를 이 전문 검색 색인에도 추가 (로컬 파일만)"))
+			filters.register(fs)
+			registerSSH(fs, &ssh)
+			cfg.Cache.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			la := analyzer.NewLogAnalyzer()
+			// text 진행률은 stdout 에 찍혀 (-json 이면 아래에서 stderr 로 돌려)
+			progressOut := os.Stdout
+			if c.json {
+				progressOut = os.Stderr
+			}
+			la.ProgressMode = c.progressMode(progressOut)
+			if path := c.cfg.Analyzer.Parquet; path != "" {
+				sink, err := analyzer.CreateParquet(path)
+				if err != nil {
+					return err
+				}
+				defer sink.Close() // 분석이 실패했을 때 (성공하면 아래에서 먼저 닫고 에러를 확인해)
+				la.Sink = sink
+			}
+			analyze := func() error { return la.AnalyzerFile(args[0]) }
+			source := args[0]
+			switch {
+			case isStdio(args[0]):
+				source = "stdin"
+				analyze = func() error { return la.AnalyzeReader(os.Stdin, source, stdinInfo("").Size) }
+			case storage.IsRemote(args[0]):
+				// 원격 로그는 내려받지 않고 SFTP 스트림을 그대로 분석기에 흘려
+				loc, err := c.resolveSource(ctx, args[0], ssh)
+				if err != nil {
+					return err
+				}
+				defer loc.Close()
+				analyze = func() error { return analyzeRemote(ctx, la, loc) }
+			}
+			if filters.enabled() {
+				// 압축 해제나 JSON → 텍스트 변환 같은 걸 거친 내용을 분석 (크기는 모르니 진행률은 바이트만)
+				stages, err := filters.transforms(c.cfg.Plugins)
+				if err != nil {
+					return err
+				}
+				analyze = func() error { return analyzeFiltered(ctx, c, la, args[0], source, ssh, stages) }
+			}
+			if c.json {
+				// -json 이면 stdout 에는 결과 JSON 만 나가야 하니 진행 안내 문구는 버려
+				restore := redirectStdout()
+				err := analyze()
+				restore()
+				if err != nil {
+					return err
+				}
+			} else if err := analyze(); err != nil {
+				return err
+			}
+
+			if la.Sink != nil {
+				if err := la.Sink.Close(); err != nil {
+					return err
+				}
+			}
+			if report := c.cfg.Analyzer.Report; report != "" {
+				if err := la.SaveReport(report); err != nil {
+					return err
+				}
+			}
+			if journal := c.cfg.Analyzer.Journal; journal != "" {
+				if err := la.AppendSummary(journal, source); err != nil {
+					return err
+				}
+			}
+			if *searchIndex != "" && !storage.IsRemote(args[0]) && !isStdio(args[0]) {
+				if err := indexFiles(ctx, *searchIndex, args[:1]); err != nil {
+					return err
+				}
+			}
+			if c.json {
+				return c.print(la.Stats(), "")
+			}
+			la.PrintReport()
+			return nil
+		},
+	}
+}
+
+func analyzeRemote(ctx context.Context, la *analyzer.LogAnalyzer, loc storage.Location) error {
+	info, err := loc.Storage.Stat(ctx, loc.Path)
+	if err != nil {
+		return err
+	}
+	r, err := loc.Storage.Open(ctx, loc.Path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return la.AnalyzeReader(r, loc.Raw, streamio.KnownSize(info))
+}
+
+// analyzeFiltered 원본(로컬, -, sftp://)을 필터에 통과시켜서 분석
+func analyzeFiltered(ctx context.Context, c *common, la *analyzer.LogAnalyzer, arg, source string, ssh storage.SSHOptions, stages []streamio.Transform) error {
+	var r io.Reader = os.Stdin
+	if !isStdio(arg) {
+		loc, err := c.resolveSource(ctx, arg, ssh)
+		if err != nil {
+			return err
+		}
+		defer loc.Close()
+		f, err := loc.Storage.Open(ctx, loc.Path)
+		if err != nil {
+			return msg.Errorf("파일열기 실패 : %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	filtered, err := streamio.Chain(ctx, r, stages...)
+	if err != nil {
+		return err
+	}
+	defer filtered.Close()
+	return la.AnalyzeReader(filtered, source, -1)
+}
+
+// redirectStdout 분석기가 stdout 에 찍는 안내 문구를 잠시 stderr 로 돌려
+func redirectStdout() (restore func()) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return func() { os.Stdout = stdout }
+}
+
+// serve - step09 HTTP 업로드/다운로드 서버
+func serveCommand() *command {
+	return &command{
+		usage: "",
+		help:  "HTTP 파일 서버 (업로드/다운로드/Range/삭제/검색, API 키별 전송량/월 한도, systemd 소켓 활성화/sd_notify, SIGHUP 으로 설정 다시 읽기)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			cfg.Server.RegisterFlags(fs)
+			cfg.Search.RegisterFlags(fs)
+			cfg.Extract.RegisterFlags(fs)
+			cfg.Daemon.RegisterFlags(fs)
+			cfg.Usage.RegisterFlags(fs)
+			cfg.Auth.RegisterFlags(fs)
+			cfg.CORS.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if c.cfg.Daemon.PIDFile != "" {
+				remove, err := daemon.WritePIDFile(c.cfg.Daemon.PIDFile)
+				if err != nil {
+					return err
+				}
+				defer remove()
+			}
+			hooks := c.serverHooks()
+			srv, err := server.New(server.FromConfig(c.cfg, hooks))
+			if err != nil {
+				return err
+			}
+			// systemd 가 소켓을 넘겨줬으면 그걸로 (그러면 -addr 은 안 봐)
+			ln, err := daemon.Listen("tcp", srv.Addr())
+			if err != nil {
+				return err
+			}
+			daemon.OnReload(ctx, func() {
+				cfg, err := c.reload()
+				if err != nil {
+					slog.Error("설정 다시 읽기 실패 - 지금 설정 그대로", "err", err)
+					return
+				}
+				if err := logging.Setup(cfg.Log.Options()); err != nil {
+					slog.Error("로그 설정 실패", "err", err)
+				}
+				for _, name := range srv.Reload(server.FromConfig(cfg, hooks)) {
+					slog.Warn("재시작해야 바뀌는 설정이라 무시", "setting", name)
+				}
+				slog.Info("설정 다시 읽음", "max_upload", cfg.Server.MaxUpload, "log_level", cfg.Log.Level)
+			}, nil)
+
+			slog.Info("서버 시작", "addr", ln.Addr().String(), "scheme", srv.Scheme(), "dir", cmp.Or(c.cfg.Server.Backend, c.cfg.Server.UploadDir), "pid", os.Getpid())
+			daemon.Notify(daemon.Ready)
+			context.AfterFunc(ctx, func() { daemon.Notify(daemon.Stopping) })
+			return srv.Serve(ctx, ln)
+		},
+	}
+}
+
+// sync - rsync 스타일 디렉토리 동기화 (fstree.Sync)
+func syncCommand() *command {
+	var deleteExtra, dryRun, checksum, sparse, hardLinks *bool
+	var include, exclude, trashDir *string
+	var symlinks fstree.SymlinkPolicy
+	var ssh storage.SSHOptions
+	return &command{
+		usage: "<원본 디렉토리> <대상 디렉토리>",
+		help:  "디렉토리 동기화 (바뀐 파일만 복사, -delete 로 미러링, sftp:// 원격)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			deleteExtra = fs.Bool("delete", false, msg.T("원본에 없는 파일을 대상에서 삭제"))
+			trashDir = fs.String("trash", "", msg.T("-delete 때 영구 삭제 대신 옮길 휴지통 디렉토리"))
+			dryRun = fs.Bool("dry-run", false, msg.T("실제로 바꾸지 않고 할 일만 출력"))
+			checksum = fs.Bool("checksum", false, msg.T("크기+수정시각 대신 내용 해시로 비교"))
+			include = fs.String("include", "", msg.T("포함할 glob (쉼표 구분)"))
+			exclude = fs.String("exclude", "", msg.T("제외할 glob (쉼표 구분)"))
+			sparse = fs.Bool("sparse", false, msg.T("sparse 파일의 구멍을 대상에서도 유지"))
+			hardLinks = fs.Bool("hardlinks", false, msg.T("하드링크를 대상에서도 하드링크로 유지"))
+			fs.Var(&symlinks, "symlinks", msg.T("심볼릭 링크 처리 (skip|follow|copy)"))
+			registerSSH(fs, &ssh)
+			cfg.Checksum.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			copyOpts := c.copyOptions()
+
+			opts := fstree.SyncOptions{
+				Checksum:   *checksum,
+				Delete:     *deleteExtra,
+				DryRun:     *dryRun,
+				Sparse:     *sparse,
+				HardLinks:  *hardLinks,
+				BufferSize: copyOpts.BufferSize,
+				RateLimit:  copyOpts.RateLimit,
+				Hooks:      copyOpts.Hooks,
+			}
+			opts.Walk.Symlinks = symlinks
+			var err error
+			if *trashDir != "" {
+				if opts.Trash, err = fstree.OpenTrash(*trashDir); err != nil {
+					return msg.Errorf("휴지통 열기 실패: %w", err)
+				}
+			}
+			if *include != "" {
+				opts.Walk.Include = strings.Split(*include, ",")
+			}
+			if *exclude != "" {
+				opts.Walk.Exclude = strings.Split(*exclude, ",")
+			}
+			sums := &checksumRecorder{}
+			if !storage.IsRemote(args[1]) && !opts.DryRun {
+				if sums, err = c.checksumRecorder(); err != nil {
+					return err
+				}
+				defer sums.close()
+			}
+			// 파일별 결과는 -json 이면 NDJSON 으로, 아니면 한 줄씩 (진행률과 섞이지 않게 stdout)
+			opts.OnAction = func(a fstree.SyncAction) {
+				if a.Err == nil && a.Kind != fstree.SyncDelete {
+					sums.record(filepath.Join(args[1], filepath.FromSlash(a.RelPath)))
+				}
+				if c.json {
+					c.print(syncActionJSON(a), "")
+					return
+				}
+				if a.Err != nil {
+					fmt.Printf("%-7s %s: %v\n", a.Kind, a.RelPath, a.Err)
+					return
+				}
+				msg.Fprintf(os.Stdout, "%-7s %s (%d 바이트)\n", a.Kind, a.RelPath, a.Size)
+			}
+
+			var report fstree.SyncReport
+			if storage.IsRemote(args[0]) || storage.IsRemote(args[1]) {
+				if opts.Sparse || opts.HardLinks || symlinks != "" {
+					return msg.New("-sparse, -hardlinks, -symlinks 는 로컬끼리 동기화할 때만 쓸 수 있어")
+				}
+				report, err = syncRemote(ctx, args[0], args[1], opts, ssh)
+			} else {
+				report, err = fstree.Sync(ctx, args[0], args[1], opts)
+			}
+			if printErr := c.print(report, report.String()); printErr != nil {
+				return printErr
+			}
+			if err != nil {
+				return msg.Errorf("동기화 중단: %w", err)
+			}
+			if report.Failed > 0 {
+				return msg.Errorf("%d개 파일 실패", report.Failed)
+			}
+			return nil
+		},
+	}
+}
+
+// syncRemote 원본이나 대상이 sftp:// 일 때 (fstree.SyncStorage)
+func syncRemote(ctx context.Context, srcArg, dstArg string, opts fstree.SyncOptions, ssh storage.SSHOptions) (fstree.SyncReport, error) {
+	src, err := storage.Resolve(ctx, srcArg, ssh)
+	if err != nil {
+		return fstree.SyncReport{}, err
+	}
+	defer src.Close()
+	dst, err := storage.Resolve(ctx, dstArg, ssh)
+	if err != nil {
+		return fstree.SyncReport{}, err
+	}
+	defer dst.Close()
+	return fstree.SyncStorage(ctx, src.Storage, src.Path, dst.Storage, dst.Path, opts)
+}
+
+// syncActionJSON error 는 JSON 으로 그대로 안 나가니까 문자열로 바꿔서
+func syncActionJSON(a fstree.SyncAction) map[string]any {
+	m := map[string]any{"kind": a.Kind, "path": a.RelPath, "size": a.Size}
+	if a.Err != nil {
+		m["error"] = a.Err.Error()
+	}
+	return m
+}
+
+// hash - 파일이면 SHA-256 한 줄, 디렉토리면 sha256sum 호환 매니페스트
+func hashCommand() *command {
+	var output *string
+	var workers *int
+	return &command{
+		usage: "<파일|디렉토리|->",
+		help:  "SHA-256 계산 (디렉토리면 sha256sum -c 로 검증 가능한 매니페스트, - 면 stdin)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			output = fs.String("o", "", msg.T("디렉토리 매니페스트를 저장할 파일 (기본: stdout)"))
+			workers = fs.Int("workers", 0, msg.T("디렉토리 병렬 해시 워커 수 (기본: CPU 수)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			target := args[0]
+			opts := c.copyOptions()
+			var src io.Reader = os.Stdin
+			info := stdinInfo("")
+			if !isStdio(target) {
+				fi, err := os.Stat(target)
+				if err != nil {
+					return err
+				}
+				if fi.IsDir() {
+					return hashDir(ctx, c, target, *output, *workers)
+				}
+				file, err := os.Open(target)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				src = file
+				info = streamio.TransferInfo{ID: filepath.Base(target), Src: target, Size: streamio.KnownSize(fi)}
+			}
+
+			h := sha256.New()
+			start := time.Now()
+			n, err := streamio.Copy(ctx, h, src, info, opts)
+			if err != nil {
+				return err
+			}
+			r := newTransferResult(target, "", n, time.Since(start))
+			r.SHA256 = hex.EncodeToString(h.Sum(nil))
+			// sha256sum 과 같은 형식
+			return c.print(r, fmt.Sprintf("%s  %s", r.SHA256, target))
+		},
+	}
+}
+
+func hashDir(ctx context.Context, c *common, root, output string, workers int) error {
+	var w io.Writer = os.Stdout
+	opts := fstree.ManifestOptions{Workers: workers}
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+		// 매니페스트를 대상 디렉토리 안에 쓰면 자기 자신은 빼
+		opts.Walk.Exclude = []string{filepath.Base(output)}
+	} else if c.json {
+		return msg.New("디렉토리 해시에 -json 을 쓰려면 -o 로 매니페스트 파일을 지정해줘")
+	}
+
+	start := time.Now()
+	count, err := fstree.WriteManifest(ctx, root, w, opts)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		return nil
+	}
+	return c.print(map[string]any{"root": root, "manifest": output, "files": count, "elapsed_ms": time.Since(start).Milliseconds()},
+		msg.Sprintf("%d개 파일 해시 완료: %s", count, output))
+}
+
+// recv - TCP/QUIC 전송 프로토콜 수신 서버 (transfer.Server)
+func recvCommand() *command {
+	var addr, quicAddr, dir, certFile, keyFile *string
+	return &command{
+		usage: "",
+		help:  "TCP/QUIC 파일 수신 서버 (끊긴 전송은 이어받기, 청크 CRC + 전체 다이제스트 검증)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			addr = fs.String("addr", ":9000", msg.T("TCP listen 주소 (빈 문자열이면 TCP 안 받음)"))
+			quicAddr = fs.String("quic", "", msg.T("QUIC(UDP) listen 주소 (예: :9001)"))
+			certFile = fs.String("cert", "", msg.T("QUIC TLS 인증서 (비우면 자체 서명 인증서를 만들고 지문 출력)"))
+			keyFile = fs.String("key", "", msg.T("QUIC TLS 개인키"))
+			dir = fs.String("dir", "./received", msg.T("받은 파일을 저장할 디렉토리"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			hooks := c.serverHooks()
+			var tlsConf *tls.Config
+			if *certFile != "" {
+				cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+				if err != nil {
+					return msg.Errorf("인증서 읽기 실패: %w", err)
+				}
+				tlsConf = &tls.Config{Certificates: []tls.Certificate{cert}}
+			}
+
+			srv := &transfer.Server{Dir: *dir, Hooks: hooks}
+			errCh := make(chan error, 2)
+			listeners := 0
+			if *addr != "" {
+				listeners++
+				slog.Info("TCP 수신 대기", "addr", *addr, "dir", *dir)
+				go func() { errCh <- srv.ListenAndServe(ctx, *addr) }()
+			}
+			if *quicAddr != "" {
+				listeners++
+				slog.Info("QUIC 수신 대기", "addr", *quicAddr, "dir", *dir)
+				go func() { errCh <- srv.ListenAndServeQUIC(ctx, *quicAddr, tlsConf) }()
+			}
+			if listeners == 0 {
+				return msg.New("-addr 나 -quic 중 하나는 있어야 해")
+			}
+
+			// 하나가 실패하면 나머지도 멈추게 취소
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			var firstErr error
+			for range listeners {
+				if err := <-errCh; err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+			}
+			return firstErr
+		},
+	}
+}
+
+// send - TCP/QUIC 전송 프로토콜로 파일 보내기 (transfer.Send)
+func sendCommand() *command {
+	var name, fingerprint *string
+	var retries, streams *int
+	var insecure *bool
+	var migrate *time.Duration
+	var mode transfer.Transport
+	return &command{
+		usage: "<주소> <파일>",
+		help:  "recv 서버로 파일 전송 (끊기면 받은 곳부터 재전송, -transport quic 이면 병렬 스트림)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			name = fs.String("name", "", msg.T("서버에 저장할 이름 (기본: 파일 이름)"))
+			retries = fs.Int("retries", 3, msg.T("연결이 끊기면 다시 접속하는 횟수"))
+			fs.Var(&mode, "transport", msg.T("전송 방식 (tcp|quic)"))
+			streams = fs.Int("streams", 4, msg.T("QUIC 병렬 스트림 수"))
+			fingerprint = fs.String("fingerprint", "", msg.T("QUIC 서버 인증서 SHA-256 지문 (recv 가 출력한 값)"))
+			insecure = fs.Bool("insecure", false, msg.T("QUIC 서버 인증서를 검증하지 않음 (실습용)"))
+			migrate = fs.Duration("migrate-after", 0, msg.T("QUIC 전송 중 이만큼 뒤에 새 UDP 소켓으로 경로 이동 (연결 이동 실습)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			copyOpts := c.copyOptions()
+
+			opts := transfer.SendOptions{
+				Transport:    mode,
+				Name:         *name,
+				ChunkSize:    copyOpts.BufferSize, // -buffer 가 data 프레임 크기
+				RateLimit:    copyOpts.RateLimit,
+				Hooks:        copyOpts.Hooks,
+				Retries:      *retries,
+				RetryDelay:   time.Second,
+				Streams:      *streams,
+				MigrateAfter: *migrate,
+			}
+			if mode == transfer.TransportQUIC {
+				switch {
+				case *fingerprint != "":
+					opts.TLS = transfer.PinnedTLS(*fingerprint)
+				case *insecure:
+					opts.TLS = &tls.Config{InsecureSkipVerify: true}
+				default:
+					return msg.New("QUIC 은 -fingerprint 나 -insecure 가 필요해")
+				}
+			}
+
+			file := args[1]
+			if isStdio(file) {
+				if *name == "" {
+					return msg.New("stdin 을 보낼 때는 -name 으로 서버에 저장할 이름을 줘")
+				}
+				// 끊기면 받은 곳부터 다시 보내야 해서 되감을 수 있는 파일로 받아 두고 보내
+				spooled, cleanup, err := spoolStdin(ctx, c)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				file = spooled
+			}
+
+			start := time.Now()
+			res, err := transfer.Send(ctx, args[0], file, opts)
+			if err != nil {
+				return err
+			}
+			elapsed := time.Since(start)
+			if c.json {
+				return c.print(struct {
+					transfer.SendResult
+					ElapsedMS int64 `json:"elapsed_ms"`
+				}{res, elapsed.Milliseconds()}, "")
+			}
+			text := msg.Sprintf("전송 완료: %s → %s/%s (%d 바이트, 시도 %d번)", args[1], args[0], res.Name, res.Size, res.Attempts)
+			if res.Migrated {
+				text += msg.T(", 전송 중 경로 이동")
+			}
+			if res.Resumed > 0 {
+				text += msg.Sprintf(", %d 바이트부터 이어서 보냄", res.Resumed)
+			}
+			return c.print(res, text)
+		},
+	}
+}
+
+// download - HTTP 서버에서 파일 받기 (끊기면 받은 곳부터 Range + If-Range 로 이어받기, 다 받으면 sha256 검증)
+func downloadCommand() *command {
+	var sum, apiKey *string
+	var retries *int
+	return &command{
+		usage: "<URL> <대상>",
+		help:  "HTTP 로 파일 받기 (.part 가 남아 있으면 그 크기부터 이어받고, 다 받으면 sha256 검증)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			sum = fs.String("sha256", "", msg.T("기대하는 sha256 (비우면 서버의 X-Content-SHA256 으로 검증)"))
+			retries = fs.Int("retries", 3, msg.T("연결이 끊기면 다시 요청하는 횟수"))
+			apiKey = fs.String("api-key", "", msg.T("서버의 API 키나 JWT (Authorization: Bearer 로 보내)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			url, dest := args[0], args[1]
+			// 대상이 디렉토리면 URL 의 마지막 이름으로 (/range-download?file=이름 이면 그 이름)
+			if fi, err := os.Stat(dest); err == nil && fi.IsDir() {
+				name, err := downloadName(url)
+				if err != nil {
+					return err
+				}
+				dest = filepath.Join(dest, name)
+			}
+
+			opts := fetch.Options{CopyOptions: c.copyOptions(), SHA256: *sum}
+			opts.Retries = *retries
+			opts.RetryDelay = time.Second
+			if *apiKey != "" {
+				opts.Header = http.Header{"Authorization": {"Bearer " + *apiKey}}
+			}
+			start := time.Now()
+			res, err := fetch.Download(ctx, url, dest, opts)
+			if err != nil {
+				return err
+			}
+			elapsed := time.Since(start)
+			if c.json {
+				return c.print(struct {
+					fetch.Result
+					ElapsedMS int64 `json:"elapsed_ms"`
+				}{res, elapsed.Milliseconds()}, "")
+			}
+			text := msg.Sprintf("받기 완료: %s → %s (%d 바이트, 시도 %d번)", url, dest, res.Size, res.Attempts)
+			if res.Resumed > 0 {
+				text += msg.Sprintf(", %d 바이트부터 이어받음", res.Resumed)
+			}
+			if res.Restarted {
+				text += msg.T(", 서버 파일이 바뀌어서 처음부터 받음")
+			}
+			if res.Verified {
+				text += msg.Sprintf(", sha256 확인 %s", res.SHA256)
+			} else {
+				text += msg.Sprintf(", sha256 %s (서버가 알려 주지 않아 검증 안 함)", res.SHA256)
+			}
+			return c.print(res, text)
+		},
+	}
+}
+
+// downloadName URL 에서 저장할 파일 이름 - ?file= 이 있으면 그 값, 없으면 경로의 마지막
+func downloadName(raw string) (string, error) {
+	u, err := neturl.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(cmp.Or(u.Query().Get("file"), u.Path))
+	if name == "." || name == "/" || name == ".." {
+		return "", msg.Errorf("URL 에서 파일 이름을 알 수 없어: %s (대상을 파일 경로로 줘)", raw)
+	}
+	return name, nil
+}
+
+// delta-serve - rsync 스타일 델타 동기화 서버 (gRPC)
+func deltaServeCommand() *command {
+	var addr, dir *string
+	return &command{
+		usage: "",
+		help:  "델타 동기화 서버 (gRPC, 바뀐 블록만 주고받기)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			addr = fs.String("addr", ":9100", msg.T("gRPC listen 주소"))
+			dir = fs.String("dir", "./delta", msg.T("파일을 두는 디렉토리 (같은 이름의 기존 파일이 옛 버전)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			hooks := c.serverHooks()
+			slog.Info("델타 서버 시작", "addr", *addr, "dir", *dir)
+			srv := &delta.Server{Dir: *dir, Hooks: hooks}
+			return srv.ListenAndServe(ctx, *addr)
+		},
+	}
+}
+
+// delta - 델타 서버로 보내거나(push) 받기(pull)
+func deltaCommand() *command {
+	var name *string
+	var pull *bool
+	return &command{
+		usage: "<주소> <로컬 파일>",
+		help:  "delta-serve 와 파일 동기화 (옛 버전과 달라진 부분만 전송, -pull 이면 받기)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			name = fs.String("name", "", msg.T("서버 쪽 파일 이름 (기본: 로컬 파일 이름)"))
+			pull = fs.Bool("pull", false, msg.T("서버의 파일로 로컬 파일을 갱신"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			addr, local := args[0], args[1]
+			opts := delta.Options{Name: *name, Hooks: c.hooks()}
+
+			var res *delta.Result
+			var err error
+			if *pull {
+				remote := *name
+				if remote == "" {
+					remote = filepath.Base(local)
+				}
+				res, err = delta.Pull(ctx, addr, remote, local, opts)
+			} else {
+				res, err = delta.Push(ctx, addr, local, opts)
+			}
+			if err != nil {
+				return err
+			}
+
+			saved := 0.0
+			if res.Size > 0 {
+				saved = float64(res.Matched) / float64(res.Size) * 100
+			}
+			verb := msg.T("보냄")
+			if *pull {
+				verb = msg.T("받음")
+			}
+			return c.print(res, msg.Sprintf("동기화 완료: %s (%d 바이트, 블록 %d) - 데이터 %d 바이트 %s, %d 바이트는 옛 파일에서 재사용 (%.1f%% 절약)",
+				res.Name, res.Size, res.BlockSize, res.Literal, verb, res.Matched, saved))
+		},
+	}
+}
+
+// s3Flags S3 명령 공통 옵션 - 키는 AWS CLI 와 같은 환경 변수에서 읽어
+type s3Flags struct {
+	endpoint, region string
+	pathStyle        bool
+}
+
+func (f *s3Flags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.endpoint, "endpoint", "", msg.T("S3 호환 엔드포인트 (예: http://localhost:9000, 비우면 AWS)"))
+	fs.StringVar(&f.region, "region", "", msg.T("리전 (비우면 AWS_REGION, 그것도 없으면 us-east-1)"))
+	fs.BoolVar(&f.pathStyle, "path-style", false, msg.T("버킷을 경로에 넣는 주소 방식 (MinIO 등)"))
+}
+
+func (f *s3Flags) client() (*s3.Client, error) {
+	client, err := s3.NewClientFromEnv(f.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if f.region != "" {
+		client.Region = f.region
+	}
+	client.PathStyle = f.pathStyle
+	return client, nil
+}
+
+// s3-put - 멀티파트 업로드 (s3.Client.UploadFile)
+func s3PutCommand() *command {
+	var s3f s3Flags
+	var partSize, contentType *string
+	var concurrency, retries *int
+	return &command{
+		usage: "<파일|-> s3://버킷/키",
+		help:  "S3 로 업로드 (큰 파일은 파트를 나눠 동시에, 파트마다 재시도, 실패하면 업로드 정리, - 면 stdin)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			s3f.register(fs)
+			partSize = fs.String("part-size", "16MB", msg.T("파트 크기 (최소 5MB)"))
+			concurrency = fs.Int("concurrency", 4, msg.T("동시에 올리는 파트 수"))
+			retries = fs.Int("retries", 3, msg.T("파트마다 재시도 횟수"))
+			contentType = fs.String("content-type", "", msg.T("객체의 Content-Type"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 2); err != nil {
+				return err
+			}
+			bucket, key, err := s3.ParseURL(args[1])
+			if err != nil {
+				return err
+			}
+			src := args[0]
+			if isStdio(src) && (key == "" || strings.HasSuffix(key, "/")) {
+				return msg.New("stdin 은 이름이 없어서 키를 끝까지 줘야 해 (s3://버킷/경로/이름)")
+			}
+			ps, err := gendata.ParseSize(*partSize)
+			if err != nil || ps < s3.MinPartSize {
+				return msg.Errorf("잘못된 -part-size 값: %q (최소 5MB)", *partSize)
+			}
+			client, err := s3f.client()
+			if err != nil {
+				return err
+			}
+			if isStdio(src) {
+				// 파트를 동시에 읽고 실패한 파트만 다시 보내려면 되감을 수 있는 파일이 필요해
+				spooled, cleanup, err := spoolStdin(ctx, c)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				src = spooled
+			}
+
+			start := time.Now()
+			res, err := client.UploadFile(ctx, bucket, key, src, s3.UploadOptions{
+				PartSize:    ps,
+				Concurrency: *concurrency,
+				Retries:     *retries,
+				RetryDelay:  time.Second,
+				ContentType: *contentType,
+				Hooks:       c.hooks(),
+			})
+			if err != nil {
+				return err
+			}
+			r := newTransferResult(args[0], "s3://"+res.Bucket+"/"+res.Key, res.Size, time.Since(start))
+			if c.json {
+				return c.print(struct {
+					*s3.UploadResult
+					ElapsedMS int64   `json:"elapsed_ms"`
+					MBPerSec  float64 `json:"mb_per_sec"`
+				}{res, r.ElapsedMS, r.MBPerSec}, "")
+			}
+			parts := msg.T("한 번에")
+			if res.Parts > 0 {
+				parts = msg.Sprintf("파트 %d개", res.Parts)
+			}
+			return c.print(res, msg.Sprintf("업로드 완료: %s → %s (%d 바이트, %s, %.2f MB/s, ETag %s)", r.Src, r.Dst, res.Size, parts, r.MBPerSec, res.ETag))
+		},
+	}
+}
+
+// s3-cleanup - 끝나지 않은 멀티파트 업로드 정리 (s3.Client.AbortIncomplete)
+func s3CleanupCommand() *command {
+	var s3f s3Flags
+	var olderThan *time.Duration
+	var dryRun *bool
+	return &command{
+		usage: "s3://버킷[/접두사]",
+		help:  "끝나지 않은 멀티파트 업로드를 찾아 정리 (남은 파트도 용량을 차지해)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			s3f.register(fs)
+			olderThan = fs.Duration("older-than", 24*time.Hour, msg.T("이보다 오래된 업로드만 (0 이면 전부 - 진행 중인 업로드도 지워)"))
+			dryRun = fs.Bool("dry-run", false, msg.T("지우지 않고 목록만 출력"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			bucket, prefix, err := s3.ParseURL(args[0])
+			if err != nil {
+				return err
+			}
+			client, err := s3f.client()
+			if err != nil {
+				return err
+			}
+
+			var uploads []s3.Upload
+			if *dryRun {
+				all, err := client.ListMultipartUploads(ctx, bucket, prefix)
+				if err != nil {
+					return err
+				}
+				cutoff := time.Now().Add(-*olderThan)
+				for _, u := range all {
+					if *olderThan == 0 || !u.Initiated.After(cutoff) {
+						uploads = append(uploads, u)
+					}
+				}
+			} else if uploads, err = client.AbortIncomplete(ctx, bucket, prefix, *olderThan); err != nil {
+				return err
+			}
+
+			if c.json {
+				return c.print(struct {
+					Uploads []s3.Upload `json:"uploads"`
+					DryRun  bool        `json:"dry_run"`
+				}{uploads, *dryRun}, "")
+			}
+			verb := msg.T("정리함")
+			if *dryRun {
+				verb = msg.T("정리 대상")
+			}
+			for _, u := range uploads {
+				fmt.Printf("%s  %s  %s\n", u.Initiated.Local().Format(time.DateTime), u.Key, u.UploadID)
+			}
+			return c.print(nil, msg.Sprintf("미완료 업로드 %d개 %s", len(uploads), verb))
+		},
+	}
+}
+
+// backup - 중복 제거 + 암호화 백업 (backup.Repo)
+func backupCommand() *command {
+	var repoDir, passwordFile, exclude, include *string
+	var force, readData, dryRun *bool
+	var keepLast *int
+	var keepWithin *time.Duration
+	return &command{
+		usage: "init | create <디렉토리> | list | restore <스냅샷|latest> <대상> | verify | prune",
+		help:  "중복 제거 + 암호화 백업 (내용 기준 청크, 스냅샷 보존 정리)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			repoDir = fs.String("repo", "", msg.T("백업 저장소 디렉토리 (필수)"))
+			passwordFile = fs.String("password-file", "", msg.T("비밀번호 파일 (없으면 BACKUP_PASSWORD 환경 변수)"))
+			exclude = fs.String("exclude", "", msg.T("create: 제외할 glob (쉼표 구분)"))
+			force = fs.Bool("force", false, msg.T("create: 바뀌지 않은 것 같은 파일도 다시 읽기"))
+			include = fs.String("include", "", msg.T("restore: 이 glob 에 맞는 경로만 (쉼표 구분)"))
+			readData = fs.Bool("read-data", false, msg.T("verify: 청크를 전부 읽어서 복호화/무결성까지 확인"))
+			keepLast = fs.Int("keep-last", 0, msg.T("prune: 원본마다 최근 N 개 보존"))
+			keepWithin = fs.Duration("keep-within", 0, msg.T("prune: 이 기간 안의 스냅샷 보존 (예: 720h)"))
+			dryRun = fs.Bool("dry-run", false, msg.T("prune: 지우지 않고 결과만"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			if *repoDir == "" {
+				return msg.New("-repo 가 필요해")
+			}
+			password, err := backupPassword(*passwordFile)
+			if err != nil {
+				return err
+			}
+			if args[0] == "init" {
+				if _, err := backup.Init(*repoDir, password, backup.DefaultChunkerOptions); err != nil {
+					return err
+				}
+				return c.print(map[string]string{"repo": *repoDir}, msg.Sprintf("저장소 생성: %s (비밀번호를 잃어버리면 복구할 수 없어요)", *repoDir))
+			}
+			repo, err := backup.Open(*repoDir, password)
+			if err != nil {
+				return err
+			}
+
+			switch args[0] {
+			case "create":
+				if err := needArgs(fs, args, 2); err != nil {
+					return err
+				}
+				opts := backup.BackupOptions{Force: *force, Hooks: c.hooks()}
+				if *exclude != "" {
+					opts.Exclude = strings.Split(*exclude, ",")
+				}
+				snap, stats, err := repo.Backup(ctx, args[1], opts)
+				if err != nil {
+					return err
+				}
+				for _, e := range stats.Errors {
+					slog.Warn("백업하지 못한 파일", "err", e)
+				}
+				if err := c.print(struct {
+					ID string `json:"id"`
+					*backup.BackupStats
+				}{snap.ID, stats}, msg.Sprintf("스냅샷 %s: 파일 %d개 (%d 바이트, %d개는 변경 없음), 새 청크 %d개 %d 바이트 → 저장 %d 바이트, 중복 %d 바이트",
+					snap.ID, stats.Files, stats.Bytes, stats.Unchanged, stats.NewChunks, stats.NewBytes, stats.Stored, stats.DedupBytes)); err != nil {
+					return err
+				}
+				if len(stats.Errors) > 0 {
+					return msg.Errorf("%d개 항목을 백업하지 못했어 (스냅샷에서 빠짐)", len(stats.Errors))
+				}
+				return nil
+
+			case "list":
+				snaps, err := repo.Snapshots()
+				if err != nil {
+					return err
+				}
+				if c.json {
+					type item struct {
+						ID     string    `json:"id"`
+						Time   time.Time `json:"time"`
+						Source string    `json:"source"`
+						Host   string    `json:"host"`
+						Files  int       `json:"files"`
+						Bytes  int64     `json:"bytes"`
+					}
+					items := []item{}
+					for _, s := range snaps {
+						items = append(items, item{s.ID, s.Time, s.Source, s.Host, len(s.Nodes), s.Size()})
+					}
+					return c.print(items, "")
+				}
+				for _, s := range snaps {
+					msg.Fprintf(os.Stdout, "%s  %s  %-10s 항목 %6d개 %14d 바이트  %s\n", s.ID, s.Time.Local().Format(time.DateTime), s.Host, len(s.Nodes), s.Size(), s.Source)
+				}
+				return c.print(nil, msg.Sprintf("스냅샷 %d개", len(snaps)))
+
+			case "restore":
+				if err := needArgs(fs, args, 3); err != nil {
+					return err
+				}
+				snap, err := repo.FindSnapshot(args[1])
+				if err != nil {
+					return err
+				}
+				opts := backup.RestoreOptions{Hooks: c.hooks()}
+				if *include != "" {
+					opts.Include = strings.Split(*include, ",")
+				}
+				stats, err := repo.Restore(ctx, snap, args[2], opts)
+				if err != nil {
+					return err
+				}
+				return c.print(stats, msg.Sprintf("복원 완료: 스냅샷 %s → %s (파일 %d개, 디렉토리 %d개, 링크 %d개, %d 바이트)",
+					snap.ID, args[2], stats.Files, stats.Dirs, stats.Links, stats.Bytes))
+
+			case "verify":
+				report, err := repo.Verify(ctx, *readData)
+				if err != nil {
+					return err
+				}
+				for _, id := range report.Missing {
+					slog.Error("없는 청크", "chunk", id)
+				}
+				for _, id := range report.Corrupt {
+					slog.Error("손상된 청크", "chunk", id)
+				}
+				if err := c.print(report, msg.Sprintf("스냅샷 %d개, 청크 %d개 확인 - 없음 %d, 손상 %d, 안 쓰는 청크 %d",
+					report.Snapshots, report.Chunks, len(report.Missing), len(report.Corrupt), report.Unreferenced)); err != nil {
+					return err
+				}
+				if !report.OK() {
+					return msg.Errorf("온전히 복원할 수 없는 스냅샷: %s", strings.Join(report.Broken, ", "))
+				}
+				return nil
+
+			case "prune":
+				report, err := repo.Prune(ctx, backup.PruneOptions{KeepLast: *keepLast, KeepWithin: *keepWithin, DryRun: *dryRun})
+				if err != nil {
+					return err
+				}
+				verb := msg.T("삭제")
+				if *dryRun {
+					verb = msg.T("삭제 예정")
+				}
+				return c.print(report, msg.Sprintf("스냅샷 %d개 보존, %d개 %s, 청크 %d개 %s (%d 바이트)",
+					len(report.Kept), len(report.Removed), verb, report.DeletedChunks, verb, report.FreedBytes))
+
+			default:
+				return msg.Errorf("알 수 없는 작업: %s (init|create|list|restore|verify|prune)", args[0])
+			}
+		},
+	}
+}
+
+// backupPassword -password-file 또는 BACKUP_PASSWORD (명령줄 인자로 받으면 ps 에 보여서 안 받아)
+func backupPassword(file string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if p := os.Getenv("BACKUP_PASSWORD"); p != "" {
+		return p, nil
+	}
+	return "", msg.New("비밀번호가 필요해 (-password-file 또는 BACKUP_PASSWORD)")
+}
+
+// index - 전문 검색 색인 만들기/갱신 (search.Index)
+func indexCommand() *command {
+	var exclude *string
+	return &command{
+		usage: "<파일|디렉토리>...",
+		help:  "텍스트/로그(gzip 포함) 전문 검색 색인 갱신 (바뀐 파일만 다시 읽기)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			cfg.Search.RegisterFlags(fs)
+			exclude = fs.String("exclude", "", msg.T("제외할 glob (쉼표 구분)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			ix, err := search.Open(c.cfg.Search.Index)
+			if err != nil {
+				return err
+			}
+			var walk fstree.WalkOptions
+			if *exclude != "" {
+				walk.Exclude = strings.Split(*exclude, ",")
+			}
+
+			var total search.UpdateStats
+			for _, arg := range args {
+				fi, err := os.Stat(arg)
+				if err != nil {
+					return err
+				}
+				if !fi.IsDir() {
+					switch err := ix.Add(ctx, arg); {
+					case err == nil:
+						total.Added++
+					case errors.Is(err, search.ErrBinary):
+						total.Skipped++
+					default:
+						return err
+					}
+					continue
+				}
+				stats, err := ix.Update(ctx, arg, walk)
+				if err != nil {
+					return err
+				}
+				total.Added += stats.Added
+				total.Fresh += stats.Fresh
+				total.Removed += stats.Removed
+				total.Skipped += stats.Skipped
+				total.Errors = append(total.Errors, stats.Errors...)
+			}
+			if err := ix.Save(); err != nil {
+				return err
+			}
+			for _, e := range total.Errors {
+				slog.Warn("색인하지 못한 파일", "err", e)
+			}
+			return c.print(total, msg.Sprintf("색인 갱신: 새로 %d개, 그대로 %d개, 텍스트 아님 %d개, 삭제 %d개 (전체 %d개 파일)",
+				total.Added, total.Fresh, total.Skipped, total.Removed, ix.Len()))
+		},
+	}
+}
+
+// indexFiles 파일들을 색인에 추가하고 저장 (텍스트가 아닌 파일은 조용히 건너뛰어)
+func indexFiles(ctx context.Context, indexPath string, files []string) error {
+	ix, err := search.Open(indexPath)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := ix.Add(ctx, f); err != nil && !errors.Is(err, search.ErrBinary) {
+			return err
+		}
+	}
+	return ix.Save()
+}
+
+// search - 전문 검색 (search.Index.Search)
+func searchCommand() *command {
+	var limit, lines *int
+	return &command{
+		usage: "<검색어>...",
+		help:  "색인된 파일 전문 검색 (모든 단어가 들어 있는 파일, 맞는 줄 표시)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			cfg.Search.RegisterFlags(fs)
+			limit = fs.Int("limit", 20, msg.T("최대 결과 수"))
+			lines = fs.Int("lines", 3, msg.T("파일마다 보여줄 줄 수 (-1 이면 파일 이름만)"))
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			ix, err := search.Open(c.cfg.Search.Index)
+			if err != nil {
+				return err
+			}
+			results, err := ix.Search(ctx, strings.Join(args, " "), search.SearchOptions{Limit: *limit, Snippets: *lines})
+			if err != nil {
+				return err
+			}
+			if c.json {
+				return c.print(results, "")
+			}
+			for _, r := range results {
+				msg.Fprintf(os.Stdout, "%s (점수 %.2f, %d 바이트)\n", r.Path, r.Score, r.Size)
+				for _, s := range r.Snippets {
+					fmt.Printf("  %6d: %s\n", s.Line, s.Text)
+				}
+			}
+			return c.print(nil, msg.Sprintf("%d개 파일", len(results)))
+		},
+	}
+}
+
+// config - 지금 적용될 설정을 YAML 로 (파일, 환경 변수, 플래그를 다 반영한 결과 - 그대로 -config 파일로 써도 돼)
+func configCommand() *command {
+	return &command{
+		usage: "",
+		help:  "적용될 설정을 YAML 로 출력 (-config, FS_* 환경 변수, 플래그 반영)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			cfg.Compress.RegisterFlags(fs)
+			cfg.Server.RegisterFlags(fs)
+			cfg.Search.RegisterFlags(fs)
+			cfg.Analyzer.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			out, err := c.cfg.YAML()
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(out)
+			return err
+		},
+	}
+}
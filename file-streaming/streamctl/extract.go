@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/extract"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// extract - zip/tar/tar.gz 풀기 (extract.Extract)
+func extractCommand() *command {
+	var overwrite, sparse *bool
+	return &command{
+		usage: "<아카이브|-> [디렉토리]",
+		help:  "zip/tar/tar.gz 풀기 (대상 밖을 가리키는 경로 거절, 엔트리 수/풀린 크기 한도, 권한 정리, - 는 stdin)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			overwrite = fs.Bool("overwrite", false, msg.T("이미 있는 파일을 덮어써"))
+			sparse = fs.Bool("sparse", false, msg.T("0 블록을 구멍으로 남겨 (sparse 파일)"))
+			cfg.Extract.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			src, dst := args[0], "."
+			if len(args) > 1 {
+				dst = args[1]
+			}
+			opts := c.cfg.Extract.Options()
+			opts.Overwrite, opts.Sparse = *overwrite, *sparse
+			opts.Hooks = c.hooks()
+			opts.BufferSize = c.cfg.Transfer.Buffer.Int()
+
+			var r io.Reader = os.Stdin
+			if !isStdio(src) {
+				f, err := os.Open(src)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+
+			start := time.Now()
+			res, err := extract.Extract(ctx, r, dst, opts)
+			if err != nil {
+				return err
+			}
+			if !c.json {
+				for _, name := range res.Skipped {
+					msg.Fprintf(os.Stdout, "건너뜀: %s (링크/장치 파일)\n", name)
+				}
+			}
+			return c.print(map[string]any{"src": src, "dst": dst, "result": res, "elapsed_ms": time.Since(start).Milliseconds()},
+				msg.Sprintf("풀기 완료: %s → %s (%s)", src, dst, res))
+		},
+	}
+}
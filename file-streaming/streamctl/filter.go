@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 외부 명령 필터 (-filter)
+// ⭐ copy/compress/analyze 의 데이터가 지나가는 길에 stdin → stdout 프로그램을 끼워 (streamio.Command).
+// 설정 파일 plugins 에 등록한 이름을 주거나, 명령줄을 그대로 줘 - 여러 번 주면 준 순서대로 이어 붙여.
+//
+//	streamctl copy -filter 'jq -c "select(.status >= 500)"' access.json errors.json
+//	streamctl compress -filter errors-only app.log
+//	streamctl analyze -filter 'zstd -dc' app.log.zst
+//
+// 명령줄은 셸을 거치지 않고 공백으로 나눠 (따옴표로 묶을 수 있어) - 파이프(|)나 리다이렉트는 안 돼.
+
+// filterFlags -filter (여러 번) 와 -filter-timeout
+type filterFlags struct {
+	specs   []string
+	timeout time.Duration
+}
+
+func (f *filterFlags) register(fs *flag.FlagSet) {
+	fs.Func("filter", msg.T("데이터를 이 외부 명령(stdin → stdout)에 통과시켜 - plugins 이름이나 명령줄, 여러 번 주면 차례로"), func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return msg.New("빈 필터")
+		}
+		f.specs = append(f.specs, s)
+		return nil
+	})
+	fs.DurationVar(&f.timeout, "filter-timeout", 0, msg.T("필터 명령 하나의 실행 시간 제한 (0 이면 plugins 설정값, 그것도 없으면 제한 없음)"))
+}
+
+func (f *filterFlags) enabled() bool { return len(f.specs) > 0 }
+
+// transforms 플래그 → 파이프라인 단계 (plugins 에 있는 이름이면 그 설정, 아니면 명령줄로 봐)
+// 필터의 stderr 는 그대로 우리 stderr 로 흘려 (ffmpeg 처럼 진행 상황을 stderr 에 찍는 도구가 많아)
+func (f *filterFlags) transforms(plugins map[string]config.Plugin) ([]streamio.Transform, error) {
+	var out []streamio.Transform
+	for _, spec := range f.specs {
+		cmd := streamio.Command{Timeout: f.timeout, Stderr: os.Stderr}
+		if p, ok := plugins[spec]; ok {
+			cmd.Args, cmd.Env, cmd.OKExitCodes = p.Command, p.Env, p.OKExit
+			if cmd.Timeout == 0 {
+				cmd.Timeout = p.Timeout
+			}
+		} else {
+			args, err := splitCommandLine(spec)
+			if err != nil {
+				return nil, fmt.Errorf("-filter %q: %w", spec, err)
+			}
+			cmd.Args = args
+		}
+		out = append(out, cmd)
+	}
+	return out, nil
+}
+
+// splitCommandLine 공백으로 나누되 '...' 는 그대로, "..." 안에서는 \" \\ 만 풀어 (셸 흉내는 여기까지)
+func splitCommandLine(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				cur.WriteRune('\\') // "a\b" 는 a\b 그대로
+			}
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, msg.New("따옴표나 \\ 가 닫히지 않았습니다")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	if len(args) == 0 {
+		return nil, msg.New("빈 명령")
+	}
+	return args, nil
+}
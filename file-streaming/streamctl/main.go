@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/daemon"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/notify"
+	"github.com/hellotect2022go/study-go/file-streaming/stats"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 공유 라이브러리(streamio, fstree, analyzer, server) 위에 올린 단일 CLI
+// step 디렉토리들은 학습용 예제로 남겨두고, 실제로 쓸 때는 이 하나로 충분해.
+//
+//	go run ./streamctl <명령> [옵션] <인자...>
+//	go run ./streamctl copy -rate 1MB -progress json big.iso /backup/big.iso
+
+// command 서브커맨드 하나
+type command struct {
+	usage string // 인자 설명 (옵션 제외)
+	help  string
+	run   func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error
+	flags func(fs *flag.FlagSet, cfg *config.Config) // 명령 전용 옵션 등록 (nil 가능) - 설정 파일에 있는 값은 cfg 필드에 바로 묶어
+}
+
+var commands = map[string]*command{
+	"copy":     copyCommand(),
+	"split":    splitCommand(),
+	"join":     joinCommand(),
+	"compress": compressCommand(),
+	"analyze":  analyzeCommand(),
+	"serve":    serveCommand(),
+	"sync":     syncCommand(),
+	"hash":     hashCommand(),
+	"send":     sendCommand(),
+	"recv":     recvCommand(),
+	"download": downloadCommand(),
+
+	"delta":       deltaCommand(),
+	"delta-serve": deltaServeCommand(),
+	"s3-put":      s3PutCommand(),
+	"s3-cleanup":  s3CleanupCommand(),
+	"backup":      backupCommand(),
+	"index":       indexCommand(),
+	"search":      searchCommand(),
+	"config":      configCommand(),
+	"schedule":    scheduleCommand(),
+	"queue":       queueCommand(),
+	"scrub":       scrubCommand(),
+	"extract":     extractCommand(),
+}
+
+// common 모든 명령이 같은 이름/의미로 받는 옵션
+// ⭐ -json 말고는 전부 config 에 있어서 설정 파일(-config, FS_CONFIG)이나 환경 변수로도 줄 수 있어
+type common struct {
+	cfg    config.Config
+	json   bool
+	stdout bool // stdout 으로 데이터를 내보내는 중 (- 출력) - 결과는 stderr 로
+
+	notifier *notify.Notifier // 알림 대상이 없으면 Enabled() 가 false
+	batch    *notify.Batch    // 알림이 켜져 있으면 명령 하나 동안의 전송을 모아
+	stats    *stats.Collector // SIGUSR1 로 덤프할 상태 - 모든 훅에 붙어
+
+	// reload 설정 파일/환경 변수를 다시 읽고 명령줄 플래그를 다시 덮은 설정 (SIGHUP 으로 다시 읽는 serve 용)
+	reload func() (config.Config, error)
+}
+
+func (c *common) register(fs *flag.FlagSet) {
+	config.RegisterConfigFlag(fs)
+	registerConfig(fs, &c.cfg)
+	fs.BoolVar(&c.json, "json", false, msg.T("결과를 사람이 읽는 글 대신 JSON 한 줄로 stdout 에 출력"))
+}
+
+// registerConfig 모든 명령이 받는 설정 섹션 플래그 (config.Reload 가 새 설정에 다시 등록할 수 있게 cfg 를 받아)
+func registerConfig(fs *flag.FlagSet, cfg *config.Config) {
+	cfg.Transfer.RegisterFlags(fs)
+	cfg.Log.RegisterFlags(fs)
+	cfg.Trace.RegisterFlags(fs)
+	cfg.Notify.RegisterFlags(fs)
+	cfg.Stats.RegisterFlags(fs)
+	cfg.Locale.RegisterFlags(fs)
+}
+
+// copyOptions 공통 옵션을 streamio.CopyOptions 로 - 진행률은 ProgressHooks 가 맡아
+// (값 검사는 main 에서 cfg.Validate 로 이미 끝났어)
+func (c *common) copyOptions() streamio.CopyOptions {
+	t := c.cfg.Transfer
+	return streamio.CopyOptions{BufferSize: t.Buffer.Int(), RateLimit: int64(t.Rate), Hooks: c.hooks()}
+}
+
+func (c *common) hooks() streamio.Hooks {
+	var hooks streamio.Hooks = streamio.NopHooks{}
+	if mode := c.progressMode(os.Stderr); mode != streamio.ProgressNone {
+		hooks = &streamio.ProgressHooks{Mode: mode, Out: os.Stderr}
+	}
+	if c.batch != nil {
+		hooks = streamio.MultiHooks{hooks, c.batch}
+	}
+	return c.withStats(hooks)
+}
+
+// serverHooks 동시 전송이 많은 서버(serve, recv, delta-serve)용 - text 진행률(\r 갱신) 대신 전송마다 로그 한 줄
+func (c *common) serverHooks() streamio.Hooks {
+	switch c.cfg.Transfer.Progress {
+	case streamio.ProgressText, streamio.ProgressBar:
+		return c.withStats(streamio.LogHooks{})
+	case streamio.ProgressJSON:
+		return c.hooks()
+	}
+	return c.withStats(streamio.NopHooks{})
+}
+
+// withStats hooks 뒤에 상태 수집기를 붙여 (run 밖에서 불려서 아직 없으면 그대로)
+func (c *common) withStats(hooks streamio.Hooks) streamio.Hooks {
+	if c.stats == nil {
+		return hooks
+	}
+	return streamio.MultiHooks{hooks, c.stats}
+}
+
+// progressMode out 에 찍을 진행률 방식
+// ⭐ text, bar 는 \r 로 한 줄을 덮어쓰는 거라 out 이 터미널이 아니면(파이프, 2> 파일) 끄기 - json 은 그대로 남겨
+func (c *common) progressMode(out *os.File) streamio.ProgressMode {
+	mode := c.cfg.Transfer.Progress
+	if (mode == streamio.ProgressText || mode == streamio.ProgressBar) && !streamio.IsTerminal(out) {
+		return streamio.ProgressNone
+	}
+	return mode
+}
+
+// print -json 이면 v 를 JSON 으로, 아니면 text 를 출력 (stdout 이 데이터 용이면 stderr 로)
+func (c *common) print(v any, text string) error {
+	out := os.Stdout
+	if c.stdout {
+		out = os.Stderr
+	}
+	if c.json {
+		return json.NewEncoder(out).Encode(v)
+	}
+	fmt.Fprintln(out, text)
+	return nil
+}
+
+func main() {
+	// 명령을 고르기 전의 전체 도움말도 FS_LANG / LANG 을 따라가게 (설정 파일의 locale 은 명령을 안 뒤에 다시 반영)
+	lang, _ := msg.ParseLang(os.Getenv("FS_LANG"))
+	msg.SetLang(lang)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		msg.Fprintf(os.Stderr, "알 수 없는 명령: %s\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	// 설정 파일과 환경 변수를 먼저 읽어서 플래그 기본값으로 - 명령줄 플래그만 그 위에 덮어
+	cfg, err := config.Load(config.PathFromArgs(os.Args[2:]))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "streamctl %s: %v\n", name, err)
+		os.Exit(2)
+	}
+	// 도움말과 플래그 설명도 고른 언어로 나와야 해서 플래그를 등록하기 전에 (-lang 은 파싱 전에 미리 찾아)
+	if err := cfg.Locale.Apply(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "streamctl %s: %v\n", name, err)
+		os.Exit(2)
+	}
+	c := common{cfg: cfg}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	c.register(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs, &c.cfg)
+	}
+	fs.Usage = func() {
+		msg.Fprintf(os.Stderr, "사용법: streamctl %s [옵션] %s\n%s\n\n", name, msg.T(cmd.usage), msg.T(cmd.help))
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[2:])
+	if err := c.cfg.Validate(); err != nil {
+		msg.Fprintf(os.Stderr, "streamctl %s: 설정 오류\n%v\n", name, err)
+		os.Exit(2)
+	}
+	c.reload = func() (config.Config, error) {
+		return config.Reload(config.PathFromArgs(os.Args[2:]), fs, func(fs *flag.FlagSet, cfg *config.Config) {
+			registerConfig(fs, cfg)
+			if cmd.flags != nil {
+				cmd.flags(fs, cfg)
+			}
+		})
+	}
+
+	// -daemon (serve) 이면 백그라운드로 다시 실행하고, 자식이 준비됐다고 알려오면 부모는 여기서 끝
+	if c.cfg.Daemon.Detach {
+		if parent, err := daemon.Detach(c.cfg.Daemon.LogFile, 30*time.Second); parent {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "streamctl %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// Ctrl+C 나 SIGTERM(systemctl stop, docker stop)이면 진행 중인 작업을 취소 (임시 파일은 각 헬퍼가 정리해)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, name, cmd, &c, fs); err != nil {
+		fmt.Fprintf(os.Stderr, "streamctl %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// run 로그/트레이싱을 설정하고 명령 전체를 스팬 하나로 감싸서 실행 (끝나면 남은 스팬을 내보내)
+func run(ctx context.Context, name string, cmd *command, c *common, fs *flag.FlagSet) (err error) {
+	if err := logging.Setup(c.cfg.Log.Options()); err != nil {
+		return err
+	}
+	shutdown, err := tracing.Setup(ctx, c.cfg.Trace.Target, "streamctl")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if serr := shutdown(context.WithoutCancel(ctx)); serr != nil {
+			slog.Warn("트레이스 내보내기 실패", "err", serr)
+		}
+	}()
+
+	// kill -USR1 <pid> 면 진행 중인 전송, 고루틴, 속도 제한 상태를 stderr(또는 -stats-dump 파일)에
+	c.stats = stats.New("streamctl " + name)
+	c.stats.RateLimit = int64(c.cfg.Transfer.Rate)
+	c.stats.DumpOnSignal(ctx, c.cfg.Stats.Dump, nil)
+
+	if c.notifier, err = notify.New(c.cfg.Notify.Options()); err != nil {
+		return err
+	}
+	// config 는 설정을 보여주기만 해서 알리지 않아
+	if c.notifier.Enabled() && name != "config" {
+		c.batch = notify.NewBatch(strings.Join(append([]string{"streamctl", name}, fs.Args()...), " "))
+		defer func() {
+			// Ctrl+C 로 끝나도 알림은 나가야 해서 취소되지 않는 ctx 로 (결과는 명령의 err 그대로 - 알림 실패는 로그만)
+			c.notifier.Notify(context.WithoutCancel(ctx), c.batch.Event(err))
+		}()
+	}
+
+	ctx, st := streamio.StartStage(ctx, "streamctl "+name, attribute.StringSlice("args", fs.Args()))
+	defer func() { st.End(err) }()
+	return cmd.run(ctx, c, fs, fs.Args())
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, msg.T("사용법: streamctl <명령> [옵션] <인자...>"))
+	fmt.Fprintln(os.Stderr, "\n"+msg.T("명령:"))
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", name, msg.T(commands[name].help))
+	}
+	fmt.Fprintln(os.Stderr, "\n"+msg.T("공통 옵션: -buffer <크기> -rate <크기/초> -progress none|text|bar|json -json -log-level <레벨> -log-format text|json -trace <대상> -notify-webhook <URL> -notify-on all|failure -stats-dump <파일> -lang ko|en -config <YAML>"))
+	fmt.Fprintln(os.Stderr, msg.T("명령별 옵션: streamctl <명령> -h"))
+}
+
+// needArgs 인자 개수 확인
+func needArgs(fs *flag.FlagSet, args []string, n int) error {
+	if len(args) < n {
+		fs.Usage()
+		return msg.Errorf("인자가 %d개 필요해 (받은 개수: %d)", n, len(args))
+	}
+	return nil
+}
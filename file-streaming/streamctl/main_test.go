@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 테스트 바이너리를 STREAMCTL_TEST_MAIN=1 로 다시 실행하면 진짜 streamctl 처럼 main 을 돌려
+// (os.Exit 로 끝나는 명령줄 처리를 그대로 검사하려고)
+func TestMain(m *testing.M) {
+	if os.Getenv("STREAMCTL_TEST_MAIN") == "1" {
+		os.Args = append([]string{"streamctl"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// streamctl args 로 실행해서 stdout, stderr, 종료 코드
+func streamctl(t *testing.T, stdin io.Reader, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.CommandContext(t.Context(), os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "STREAMCTL_TEST_MAIN=1", "FS_LANG=ko")
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCopyAndHash(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src.txt"), filepath.Join(dir, "out", "dst.txt")
+	data := []byte(strings.Repeat("streamctl\n", 1000))
+	os.WriteFile(src, data, 0644)
+	os.Mkdir(filepath.Join(dir, "out"), 0755)
+
+	if _, stderr, code := streamctl(t, nil, "copy", src, dst); code != 0 {
+		t.Fatalf("copy 종료 코드 %d: %s", code, stderr)
+	}
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, data) {
+		t.Fatal("복사한 내용이 다름")
+	}
+
+	stdout, stderr, code := streamctl(t, nil, "hash", "-json", dst)
+	var r transferResult
+	if code != 0 || json.Unmarshal([]byte(stdout), &r) != nil {
+		t.Fatalf("hash = %q, %s (종료 코드 %d)", stdout, stderr, code)
+	}
+	if r.SHA256 != sha256Hex(data) || r.Bytes != int64(len(data)) {
+		t.Errorf("hash 결과 = %+v", r)
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.log")
+	data := []byte(strings.Repeat("line of log\n", 10000))
+	os.WriteFile(src, data, 0644)
+	chunks, out := filepath.Join(dir, "chunks"), filepath.Join(dir, "joined.log")
+
+	if _, stderr, code := streamctl(t, nil, "split", "-size", "16KB", "-dir", chunks, src); code != 0 {
+		t.Fatalf("split 종료 코드 %d: %s", code, stderr)
+	}
+	if names, _ := filepath.Glob(filepath.Join(chunks, "chunk_*")); len(names) < 7 {
+		t.Errorf("청크 %d 개, 16KB 로 나눴으면 7 개 이상이어야 함", len(names))
+	}
+	if _, stderr, code := streamctl(t, nil, "join", filepath.Join(chunks, "chunks.json"), out); code != 0 {
+		t.Fatalf("join 종료 코드 %d: %s", code, stderr)
+	}
+	if got, _ := os.ReadFile(out); !bytes.Equal(got, data) {
+		t.Error("합친 내용이 원본과 다름")
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	if _, stderr, code := streamctl(t, nil, "bogus"); code != 2 || !strings.Contains(stderr, "알 수 없는 명령") {
+		t.Errorf("없는 명령 = 종료 코드 %d, %q", code, stderr)
+	}
+	if _, _, code := streamctl(t, nil, "copy", "only-one-arg"); code == 0 {
+		t.Error("인자가 모자란데 성공함")
+	}
+	if _, _, code := streamctl(t, nil, "copy", filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "x")); code != 1 {
+		t.Errorf("없는 원본 = 종료 코드 %d, want 1", code)
+	}
+}
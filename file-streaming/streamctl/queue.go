@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/backup"
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/queue"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 작업 큐 (queue)
+// ⭐ 오래 걸리는 압축 묶음, 동기화, 백업을 큐 디렉토리에 넣어 두고 queue run 이 차례로 돌려.
+// 돌리던 프로세스가 죽거나 재부팅돼도 작업 파일이 남아서, 다음 queue run 이 종류별로 정리하고 이어 가:
+//
+//	compress  체크포인트의 "압축 중이던 파일" 의 반쯤 쓴 .gz 를 지우고 남은 파일부터 (끝난 파일은 원본이 없어서 건너뛰어져)
+//	sync      대상에 남은 임시 파일(.이름.tmp-*)을 지우고 다시 동기화 (바뀐 파일만 복사하니 이어 가는 셈)
+//	backup    죽은 프로세스가 남긴 저장소 잠금을 풀고 다시 백업 (이미 저장한 청크는 중복 제거로 건너뛰어)
+//
+// Ctrl+C 로 멈추면 돌던 작업은 실패가 아니라 "기다리는 중" 으로 돌아가서 다음 run 때 이어서 해.
+
+// queueParams 큐 작업 인자 (작업 파일에 JSON 으로 남아) - 비밀번호 같은 비밀은 넣지 않고 파일 경로만
+type queueParams struct {
+	Src       string        `json:"src"`
+	Dst       string        `json:"dst,omitempty"`
+	Match     string        `json:"match,omitempty"`      // compress
+	OlderThan time.Duration `json:"older_than,omitempty"` // compress
+	Keep      bool          `json:"keep,omitempty"`       // compress
+	Level     int           `json:"level,omitempty"`      // compress
+	Delete    bool          `json:"delete,omitempty"`     // sync
+
+	Repo         string   `json:"repo,omitempty"`          // backup
+	PasswordFile string   `json:"password_file,omitempty"` // backup (비우면 run 하는 쪽의 BACKUP_PASSWORD)
+	Exclude      []string `json:"exclude,omitempty"`       // backup
+
+	Identity        string `json:"identity,omitempty"` // sftp://
+	KnownHosts      string `json:"known_hosts,omitempty"`
+	InsecureHostKey bool   `json:"insecure_host_key,omitempty"`
+}
+
+func (p queueParams) job() config.Job {
+	return config.Job{Src: p.Src, Dst: p.Dst, Match: p.Match, OlderThan: p.OlderThan, Keep: p.Keep, Delete: p.Delete}
+}
+
+func (p queueParams) ssh() storage.SSHOptions {
+	return storage.SSHOptions{Identity: p.Identity, KnownHosts: p.KnownHosts, InsecureHostKey: p.InsecureHostKey}
+}
+
+// queueKinds queue add 로 넣을 수 있는 작업
+var queueKinds = []string{"compress", "sync", "backup"}
+
+func queueCommand() *command {
+	var drain, keep, del *bool
+	var match, repoDir, passwordFile, exclude *string
+	var olderThan *time.Duration
+	var ssh storage.SSHOptions
+	return &command{
+		usage: "add <compress|sync|backup> <원본> [대상] | run | status [ID] | cancel <ID> | retry <ID> | rm <ID>",
+		help:  "작업 큐 (압축 묶음/동기화/백업을 디스크에 쌓아 두고 차례로, 재시작하면 이어서)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			cfg.Queue.RegisterFlags(fs)
+			drain = fs.Bool("drain", false, msg.T("run: 기다리는 작업을 다 돌리면 끝 (기본은 새 작업을 계속 기다려)"))
+			cfg.Compress.RegisterFlags(fs)
+			match = fs.String("match", "*.log", msg.T("add compress: 압축할 파일 glob"))
+			olderThan = fs.Duration("older-than", 0, msg.T("add compress: 이만큼 안 바뀐 파일만"))
+			keep = fs.Bool("keep", false, msg.T("add compress: 원본을 지우지 않고 남김"))
+			del = fs.Bool("delete", false, msg.T("add sync: 원본에 없는 파일을 대상에서 삭제"))
+			repoDir = fs.String("repo", "", msg.T("add backup: 백업 저장소 디렉토리"))
+			passwordFile = fs.String("password-file", "", msg.T("add backup: 비밀번호 파일 (없으면 run 할 때의 BACKUP_PASSWORD)"))
+			exclude = fs.String("exclude", "", msg.T("add backup: 제외할 glob (쉼표 구분)"))
+			registerSSH(fs, &ssh)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			q, err := queue.Open(c.cfg.Queue.Dir, queue.Options{Workers: c.cfg.Queue.Workers, Hooks: c.withStats(c.notifier.JobHooks("queue"))})
+			if err != nil {
+				return err
+			}
+			c.stats.Gauge("queue", func() any {
+				running, workers := q.Busy()
+				return msg.Sprintf("작업 %d/%d 돌아가는 중", running, workers)
+			})
+
+			switch args[0] {
+			case "add":
+				if err := needArgs(fs, args, 3); err != nil {
+					return err
+				}
+				kind := args[1]
+				p := queueParams{
+					Src: args[2], Identity: ssh.Identity, KnownHosts: ssh.KnownHosts, InsecureHostKey: ssh.InsecureHostKey,
+				}
+				if len(args) > 3 {
+					p.Dst = args[3]
+				}
+				switch kind {
+				case "compress":
+					p.Match, p.OlderThan, p.Keep, p.Level = *match, *olderThan, *keep, c.cfg.Compress.Level
+				case "sync":
+					if p.Dst == "" {
+						return msg.New("sync 에는 대상이 필요해: queue add sync <원본> <대상>")
+					}
+					p.Delete = *del
+				case "backup":
+					if *repoDir == "" {
+						return msg.New("backup 에는 -repo 가 필요해")
+					}
+					p.Repo, p.PasswordFile = *repoDir, *passwordFile
+					if *exclude != "" {
+						p.Exclude = strings.Split(*exclude, ",")
+					}
+				default:
+					return msg.Errorf("알 수 없는 작업 종류: %q (%s)", kind, strings.Join(queueKinds, ", "))
+				}
+				// 큐는 다른 디렉토리에서 돌릴 수도 있어서 로컬 경로는 절대 경로로 남겨
+				for _, path := range []*string{&p.Src, &p.Dst, &p.Repo, &p.PasswordFile} {
+					if *path == "" || storage.IsRemote(*path) {
+						continue
+					}
+					if *path, err = filepath.Abs(*path); err != nil {
+						return err
+					}
+				}
+				j, err := q.Enqueue(kind, p)
+				if err != nil {
+					return err
+				}
+				return c.print(j, msg.Sprintf("작업 추가: %s (%s %s)", j.ID, kind, p.Src))
+
+			case "run":
+				registerQueueHandlers(q, c)
+				slog.Info("작업 큐 시작", "dir", q.Dir(), "workers", c.cfg.Queue.Workers, "drain", *drain)
+				if *drain {
+					return q.Drain(ctx)
+				}
+				return q.Run(ctx)
+
+			case "status":
+				if len(args) > 1 {
+					j, err := q.Get(args[1])
+					if err != nil {
+						return err
+					}
+					return c.print(j, formatQueueJob(j))
+				}
+				return printQueueStatus(c, q)
+
+			case "cancel", "retry", "rm":
+				if err := needArgs(fs, args, 2); err != nil {
+					return err
+				}
+				op, verb := q.Cancel, msg.T("취소 요청")
+				switch args[0] {
+				case "retry":
+					op, verb = q.Retry, msg.T("다시 넣음")
+				case "rm":
+					op, verb = q.Remove, msg.T("지움")
+				}
+				j, err := op(args[1])
+				if err != nil {
+					return err
+				}
+				return c.print(j, fmt.Sprintf("%s: %s (%s)", verb, j.ID, j.Kind))
+			}
+			return msg.Errorf("알 수 없는 queue 명령: %q", args[0])
+		},
+	}
+}
+
+// compressCheckpoint compress 작업이 어느 파일을 압축하고 있었는지
+type compressCheckpoint struct {
+	Current string `json:"current"` // 압축 중인 결과 파일 (.gz)
+}
+
+// registerQueueHandlers 작업 종류별 실행/복구 - 진행률은 상주 명령이라 \r 갱신 대신 전송마다 로그 한 줄 (schedule 과 같아)
+func registerQueueHandlers(q *queue.Queue, c *common) {
+	opts := c.copyOptions()
+	opts.Hooks = streamio.LogHooks{}
+
+	q.Handle("compress", queue.Handler{
+		Run: func(ctx context.Context, t *queue.Task) (string, error) {
+			var p queueParams
+			if err := t.Params(&p); err != nil {
+				return "", err
+			}
+			return compressJob(ctx, p.job(), p.Level, opts, func(dst string) error {
+				return t.SaveCheckpoint(compressCheckpoint{Current: dst})
+			})
+		},
+		Recover: func(ctx context.Context, t *queue.Task) error {
+			var cp compressCheckpoint
+			if !t.Checkpoint(&cp) || cp.Current == "" {
+				return nil
+			}
+			// 원본이 남아 있으면 .gz 는 다 못 쓴 거야 (다 썼으면 keep 이 아닌 한 원본을 지웠어)
+			if _, err := os.Stat(strings.TrimSuffix(cp.Current, ".gz")); err != nil {
+				return nil
+			}
+			err := os.Remove(cp.Current)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err == nil {
+				slog.Info("반쯤 쓴 압축 파일을 지움", "file", cp.Current)
+			}
+			return err
+		},
+	})
+
+	q.Handle("sync", queue.Handler{
+		Run: func(ctx context.Context, t *queue.Task) (string, error) {
+			var p queueParams
+			if err := t.Params(&p); err != nil {
+				return "", err
+			}
+			return syncJob(ctx, p.job(), opts, p.ssh())
+		},
+		Recover: func(ctx context.Context, t *queue.Task) error {
+			var p queueParams
+			if err := t.Params(&p); err != nil || storage.IsRemote(p.Dst) {
+				return err // 원격 대상의 임시 파일은 다음 동기화가 덮어써
+			}
+			n, err := removeTempFiles(p.Dst)
+			if n > 0 {
+				slog.Info("동기화가 남긴 임시 파일을 지움", "dir", p.Dst, "files", n)
+			}
+			return err
+		},
+	})
+
+	q.Handle("backup", queue.Handler{
+		Run: func(ctx context.Context, t *queue.Task) (string, error) {
+			var p queueParams
+			if err := t.Params(&p); err != nil {
+				return "", err
+			}
+			password, err := backupPassword(p.PasswordFile)
+			if err != nil {
+				return "", err
+			}
+			repo, err := backup.Open(p.Repo, password)
+			if err != nil {
+				return "", err
+			}
+			snap, stats, err := repo.Backup(ctx, p.Src, backup.BackupOptions{Exclude: p.Exclude, Hooks: opts.Hooks})
+			if err != nil {
+				return "", err
+			}
+			summary := msg.Sprintf("스냅샷 %s: 파일 %d개 (%d 바이트), 새 청크 %d개 → 저장 %d 바이트",
+				snap.ID, stats.Files, stats.Bytes, stats.NewChunks, stats.Stored)
+			if len(stats.Errors) > 0 {
+				return summary, msg.Errorf("%d개 항목을 백업하지 못했어 (스냅샷에서 빠짐): %w", len(stats.Errors), stats.Errors[0])
+			}
+			return summary, nil
+		},
+		Recover: func(ctx context.Context, t *queue.Task) error {
+			var p queueParams
+			if err := t.Params(&p); err != nil {
+				return err
+			}
+			pidText, host, ok := strings.Cut(t.Owner, "@")
+			pid, err := strconv.Atoi(pidText)
+			if !ok || err != nil {
+				return nil
+			}
+			removed, err := backup.UnlockStale(p.Repo, pid, host)
+			if removed {
+				slog.Info("죽은 프로세스가 남긴 저장소 잠금을 풂", "repo", p.Repo, "owner", t.Owner)
+			}
+			return err
+		},
+	})
+}
+
+// removeTempFiles dir 아래에서 복사 도중에 죽어서 남은 임시 파일(.이름.tmp-*) 지우기
+func removeTempFiles(dir string) (int, error) {
+	var n int
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // 대상이 아직 없어
+		}
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.Type().IsRegular() && strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-") {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// printQueueStatus 러너 상태 + 작업 목록 (만든 순서)
+func printQueueStatus(c *common, q *queue.Queue) error {
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+	holder, alive := q.Runner()
+	if c.json {
+		return c.print(map[string]any{"dir": q.Dir(), "runner": holder, "runner_alive": alive, "jobs": jobs}, "")
+	}
+
+	var b strings.Builder
+	switch {
+	case holder == "":
+		b.WriteString(msg.T("러너: 없음 (queue run 으로 돌려)\n"))
+	case alive:
+		msg.Fprintf(&b, "러너: %s\n", holder)
+	default:
+		msg.Fprintf(&b, "러너: %s (응답 없음 - 죽은 것 같아, 다음 queue run 이 넘겨받아)\n", holder)
+	}
+	if len(jobs) == 0 {
+		b.WriteString(msg.T("작업 없음"))
+		return c.print(nil, b.String())
+	}
+	counts := make(map[queue.State]int)
+	for _, j := range jobs {
+		counts[j.State]++
+		result := j.Summary
+		if j.Error != "" {
+			result = j.Error
+		}
+		msg.Fprintf(&b, "%s  %-8s  %-8s  시도 %d  %s  %s", j.ID, j.Kind, j.State, j.Attempts, j.Created.Local().Format(time.DateTime), result)
+		b.WriteByte('\n')
+	}
+	msg.Fprintf(&b, "기다림 %d, 실행 중 %d, 완료 %d, 실패 %d, 취소 %d",
+		counts[queue.Queued], counts[queue.Running], counts[queue.Done], counts[queue.Failed], counts[queue.Canceled])
+	return c.print(nil, b.String())
+}
+
+// formatQueueJob 작업 하나 자세히
+func formatQueueJob(j queue.Job) string {
+	var b strings.Builder
+	msg.Fprintf(&b, "ID:       %s\n종류:     %s\n상태:     %s\n인자:     %s\n만든 시각: %s\n",
+		j.ID, j.Kind, j.State, j.Params, j.Created.Local().Format(time.DateTime))
+	if !j.Started.IsZero() {
+		msg.Fprintf(&b, "시작:     %s\n", j.Started.Local().Format(time.DateTime))
+	}
+	if !j.Finished.IsZero() {
+		msg.Fprintf(&b, "끝:       %s (%s)\n", j.Finished.Local().Format(time.DateTime), j.Finished.Sub(j.Started).Round(time.Millisecond))
+	}
+	msg.Fprintf(&b, "시도:     %d (재시작 복구 %d)", j.Attempts, j.Recovered)
+	if j.Owner != "" {
+		msg.Fprintf(&b, "\n실행:     %s", j.Owner)
+	}
+	if len(j.Checkpoint) > 0 {
+		msg.Fprintf(&b, "\n체크포인트: %s", j.Checkpoint)
+	}
+	if j.Summary != "" {
+		msg.Fprintf(&b, "\n요약:     %s", j.Summary)
+	}
+	if j.Error != "" {
+		msg.Fprintf(&b, "\n에러:     %s", j.Error)
+	}
+	return b.String()
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/schedule"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 예약 작업 (schedule)
+// ⭐ 설정 파일의 schedule.jobs 를 cron 식대로 돌리는 상주 명령이야. 작업 종류는 이미 있는 명령을 그대로 재사용해:
+//
+//	compress  src 디렉토리에서 match 에 맞고 older_than 동안 안 바뀐 파일을 .gz 로 (원본은 keep 이 아니면 지워)
+//	sync      src → dst 동기화 (sync 명령과 같은 fstree.Sync, sftp:// 가능, delete 면 미러링)
+//	verify    src 트리를 sha256sum 매니페스트와 대조 (손상/누락이 있으면 실패로 기록)
+//
+// 상태/이력은 schedule.state 파일에 남아서, 스케줄러가 돌고 있는 동안 다른 터미널에서 -status 로 볼 수 있어.
+
+// statusHistory -status 에서 작업마다 보여줄 최근 기록 수
+const statusHistory = 5
+
+func scheduleCommand() *command {
+	var status *bool
+	var runJob *string
+	var ssh storage.SSHOptions
+	return &command{
+		usage: "",
+		help:  "설정 파일의 예약 작업(schedule.jobs)을 주기대로 실행 (로그 압축, 동기화, 체크섬 검증)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			fs.StringVar(&cfg.Schedule.State, "state", cfg.Schedule.State, msg.T("작업 상태/이력 파일"))
+			cfg.Compress.RegisterFlags(fs)
+			status = fs.Bool("status", false, msg.T("작업 상태와 최근 실행 기록을 보여주고 끝 (스케줄러가 다른 곳에서 돌고 있어도 돼)"))
+			runJob = fs.String("run", "", msg.T("이 작업만 지금 한 번 실행하고 끝"))
+			registerSSH(fs, &ssh)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if *status {
+				return printScheduleStatus(c)
+			}
+			if len(c.cfg.Schedule.Jobs) == 0 {
+				return msg.New("설정 파일에 schedule.jobs 가 없어 - config/example.yaml 의 예시를 참고해")
+			}
+
+			s, err := schedule.New(schedule.Options{StateFile: c.cfg.Schedule.State, Hooks: c.withStats(c.notifier.JobHooks("schedule"))})
+			if err != nil {
+				return err
+			}
+			for _, j := range c.cfg.Schedule.Jobs {
+				spec, err := schedule.Parse(j.Cron)
+				if err != nil {
+					return err // Validate 가 이미 봤으니 여기로 올 일은 없어
+				}
+				err = s.Add(schedule.Job{
+					Name:    j.Name,
+					Spec:    spec,
+					Jitter:  j.Jitter,
+					Timeout: j.Timeout,
+					Run:     jobRunner(c, j, ssh),
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if *runJob != "" {
+				run, err := s.RunNow(ctx, *runJob)
+				if err != nil {
+					return err
+				}
+				return c.print(run, msg.Sprintf("%s 완료 (%s): %s", *runJob, run.Duration.Round(time.Millisecond), run.Summary))
+			}
+
+			now := time.Now()
+			for _, j := range c.cfg.Schedule.Jobs {
+				spec, _ := schedule.Parse(j.Cron)
+				next := spec.Next(now)
+				c.print(map[string]any{"job": j.Name, "task": j.Task, "cron": j.Cron, "next": next},
+					msg.Sprintf("%-20s %-8s %-16s 다음 %s", j.Name, j.Task, j.Cron, next.Format("2006-01-02 15:04:05")))
+			}
+			return s.Run(ctx)
+		},
+	}
+}
+
+func findJob(jobs []config.Job, name string) config.Job {
+	for _, j := range jobs {
+		if j.Name == name {
+			return j
+		}
+	}
+	return config.Job{Name: name}
+}
+
+// jobRunner 설정의 작업 하나 → 스케줄러가 부를 함수
+// 진행률은 상주 명령이라 \r 갱신 대신 전송마다 로그 한 줄 (serve 와 같아)
+func jobRunner(c *common, j config.Job, ssh storage.SSHOptions) func(context.Context) (string, error) {
+	opts := c.copyOptions()
+	opts.Hooks = streamio.LogHooks{}
+	switch j.Task {
+	case "compress":
+		return func(ctx context.Context) (string, error) { return compressJob(ctx, j, c.cfg.Compress.Level, opts, nil) }
+	case "sync":
+		return func(ctx context.Context) (string, error) { return syncJob(ctx, j, opts, ssh) }
+	default:
+		return func(ctx context.Context) (string, error) { return verifyJob(ctx, j) }
+	}
+}
+
+// compressJob match 에 맞는 파일을 하나씩 .gz 로 - 한 파일이 실패해도 나머지는 계속하고 실패는 모아서 알려
+// onFile 은 파일마다 압축을 시작하기 전에 결과 경로로 불러 (nil 가능, 큐 작업이 체크포인트를 남길 때)
+func compressJob(ctx context.Context, j config.Job, level int, opts streamio.CopyOptions, onFile func(dst string) error) (string, error) {
+	match := j.Match
+	if match == "" {
+		match = "*.log"
+	}
+	paths, err := filepath.Glob(filepath.Join(j.Src, match))
+	if err != nil {
+		return "", err
+	}
+
+	var done, skipped int
+	var in, out int64
+	var errs []error
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		fi, err := os.Stat(path)
+		if err != nil || !fi.Mode().IsRegular() || strings.HasSuffix(path, ".gz") {
+			continue
+		}
+		if j.OlderThan > 0 && time.Since(fi.ModTime()) < j.OlderThan {
+			skipped++ // 아직 쓰고 있을 수 있는 로그
+			continue
+		}
+		dst := path + ".gz"
+		if gz, err := os.Stat(dst); err == nil && !gz.ModTime().Before(fi.ModTime()) {
+			skipped++ // keep 으로 남긴 원본 - 이미 압축했어
+			continue
+		}
+
+		if onFile != nil {
+			if err := onFile(dst); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+		n, m, err := compressFile(ctx, path, dst, false, level, opts)
+		if err == nil {
+			// 압축본 시각을 원본에 맞춰 - 다음 번에 older_than/이미 압축 여부를 같은 기준으로 보게
+			err = os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+		}
+		if err == nil && !j.Keep {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		done++
+		in += n
+		out += m
+	}
+	summary := msg.Sprintf("압축 %d개 (%d → %d 바이트), 건너뜀 %d", done, in, out, skipped)
+	return summary, errors.Join(errs...)
+}
+
+// syncJob sync 명령과 같은 동기화 (파일별 결과는 출력하지 않고 요약만)
+func syncJob(ctx context.Context, j config.Job, copyOpts streamio.CopyOptions, ssh storage.SSHOptions) (string, error) {
+	opts := fstree.SyncOptions{
+		Delete:     j.Delete,
+		BufferSize: copyOpts.BufferSize,
+		RateLimit:  copyOpts.RateLimit,
+		Hooks:      copyOpts.Hooks,
+	}
+	var report fstree.SyncReport
+	var err error
+	if storage.IsRemote(j.Src) || storage.IsRemote(j.Dst) {
+		report, err = syncRemote(ctx, j.Src, j.Dst, opts, ssh)
+	} else {
+		report, err = fstree.Sync(ctx, j.Src, j.Dst, opts)
+	}
+	if err == nil && report.Failed > 0 {
+		err = msg.Errorf("%d개 파일 실패", report.Failed)
+	}
+	return report.String(), err
+}
+
+// verifyJob 매니페스트와 대조 - 손상/누락/읽기 에러가 하나라도 있으면 실패 (새로 생긴 파일은 요약에만)
+func verifyJob(ctx context.Context, j config.Job) (string, error) {
+	manifest := j.Manifest
+	if manifest == "" {
+		manifest = filepath.Join(j.Src, "SHA256SUMS")
+	}
+	f, err := os.Open(manifest)
+	if err != nil {
+		return "", msg.Errorf("매니페스트 열기 실패: %w", err)
+	}
+	sums, err := fstree.ReadManifest(f)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	var opts fstree.ManifestOptions
+	opts.Walk.Exclude = []string{filepath.Base(manifest)} // 매니페스트가 트리 안에 있으면 자기 자신은 빼
+	report, err := fstree.VerifyManifest(ctx, j.Src, sums, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(report.Missing)+len(report.Corrupted)+len(report.Errors) > 0 {
+		var bad []string
+		bad = append(bad, report.Corrupted...)
+		bad = append(bad, report.Missing...)
+		bad = append(bad, report.Errors...)
+		if len(bad) > 5 {
+			bad = append(bad[:5], "…")
+		}
+		return report.String(), msg.Errorf("검증 실패: %s", strings.Join(bad, ", "))
+	}
+	return report.String(), nil
+}
+
+// printScheduleStatus 상태 파일 + 설정의 작업 목록
+func printScheduleStatus(c *common) error {
+	saved, err := schedule.LoadStatus(c.cfg.Schedule.State)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	byName := make(map[string]schedule.Status)
+	for _, st := range saved {
+		byName[st.Name] = st
+	}
+	var all []schedule.Status
+	for _, j := range c.cfg.Schedule.Jobs {
+		st, ok := byName[j.Name]
+		if !ok {
+			st = schedule.Status{Name: j.Name}
+		}
+		all = append(all, st)
+	}
+	if c.json {
+		return c.print(all, "")
+	}
+	if len(all) == 0 {
+		return c.print(nil, msg.T("설정된 작업이 없어"))
+	}
+
+	var b strings.Builder
+	for _, st := range all {
+		j := findJob(c.cfg.Schedule.Jobs, st.Name)
+		state := ""
+		if st.Running {
+			state = msg.T(" [실행 중]")
+		}
+		msg.Fprintf(&b, "%s (%s, %s)%s - 실행 %d, 실패 %d, 건너뜀 %d", st.Name, j.Task, j.Cron, state, st.Runs, st.Failures, st.Skips)
+		if !st.Next.IsZero() {
+			msg.Fprintf(&b, ", 다음 %s", st.Next.Format("2006-01-02 15:04:05"))
+		}
+		b.WriteByte('\n')
+		for i, run := range st.History {
+			if i == statusHistory {
+				break
+			}
+			result := msg.T("성공")
+			switch {
+			case run.Skipped:
+				result = msg.T("건너뜀 (이전 실행 중)")
+			case run.Error != "":
+				result = msg.T("실패: ") + run.Error
+			}
+			fmt.Fprintf(&b, "  %s %8s  %s", run.Start.Format("2006-01-02 15:04:05"), run.Duration.Round(time.Millisecond), result)
+			if run.Summary != "" {
+				fmt.Fprintf(&b, " - %s", run.Summary)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return c.print(nil, strings.TrimSuffix(b.String(), "\n"))
+}
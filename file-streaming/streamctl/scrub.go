@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// scrub - 쓸 때 남긴 체크섬 기록과 대조해서 비트 부패 찾기 (fstree.Scrub)
+func scrubCommand() *command {
+	var days, workers *int
+	var manifest, include, exclude *string
+	var dryRun *bool
+	return &command{
+		usage: "<디렉토리>",
+		help:  "체크섬 기록(xattr 또는 사이드카 DB)과 대조해서 비트 부패 찾기 (-days 동안 확인 안 한 파일만 다시 읽어)",
+		flags: func(fs *flag.FlagSet, cfg *config.Config) {
+			days = fs.Int("days", 30, msg.T("마지막 확인이 이 일수보다 오래된 파일만 다시 읽어 (0 이면 전부)"))
+			manifest = fs.String("manifest", "", msg.T("기록이 없는 파일은 이 SHA256SUMS 매니페스트와 대조 (hash -o, manifest create 로 만든 것)"))
+			workers = fs.Int("workers", 0, msg.T("병렬 해시 워커 수 (기본: CPU 수)"))
+			include = fs.String("include", "", msg.T("포함할 glob (쉼표 구분)"))
+			exclude = fs.String("exclude", "", msg.T("제외할 glob (쉼표 구분)"))
+			dryRun = fs.Bool("dry-run", false, msg.T("기록을 바꾸지 않고 확인만"))
+			cfg.Checksum.RegisterFlags(fs)
+		},
+		run: func(ctx context.Context, c *common, fs *flag.FlagSet, args []string) error {
+			if err := needArgs(fs, args, 1); err != nil {
+				return err
+			}
+			if *days < 0 {
+				return msg.New("-days 는 0 이상이어야 해")
+			}
+			root := args[0]
+			store, err := openChecksums(c.cfg.Checksum)
+			if err != nil {
+				return err
+			}
+			if store == nil {
+				return msg.New("체크섬 저장소가 꺼져 있어 - -checksum-store xattr|db 나 설정의 checksum.store 로 정해줘")
+			}
+
+			opts := fstree.ScrubOptions{Workers: *workers, OlderThan: time.Duration(*days) * 24 * time.Hour, DryRun: *dryRun}
+			if *include != "" {
+				opts.Walk.Include = strings.Split(*include, ",")
+			}
+			if *exclude != "" {
+				opts.Walk.Exclude = strings.Split(*exclude, ",")
+			}
+			// 트리 안에 둔 DB/매니페스트 자신은 빼 (DB 는 돌 때마다 바뀌니까)
+			if c.cfg.Checksum.Store == "db" {
+				opts.Walk.Exclude = append(opts.Walk.Exclude, filepath.Base(c.cfg.Checksum.DB))
+			}
+			if *manifest != "" {
+				if opts.Manifest, err = readManifestFile(*manifest); err != nil {
+					store.Close()
+					return err
+				}
+				opts.Walk.Exclude = append(opts.Walk.Exclude, filepath.Base(*manifest))
+			}
+
+			start := time.Now()
+			report, err := fstree.Scrub(ctx, root, store, opts)
+			if closeErr := store.Close(); err == nil {
+				err = closeErr
+			}
+			if !c.json {
+				for _, rel := range report.Rot {
+					msg.Fprintf(os.Stdout, "손상:   %s\n", rel)
+				}
+				for _, e := range report.Errors {
+					msg.Fprintf(os.Stdout, "에러:   %s\n", e)
+				}
+			}
+			if printErr := c.print(map[string]any{"root": root, "report": report, "elapsed_ms": time.Since(start).Milliseconds()}, report.String()); printErr != nil {
+				return printErr
+			}
+			if err != nil {
+				return msg.Errorf("검증 중단: %w", err)
+			}
+			if !report.Clean() {
+				return msg.Errorf("손상 %d개, 에러 %d개", len(report.Rot), len(report.Errors))
+			}
+			return nil
+		},
+	}
+}
+
+func readManifestFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return fstree.ReadManifest(f)
+}
+
+// openChecksums checksum.store 설정대로 저장소 열기 (off 면 nil)
+func openChecksums(cfg config.Checksum) (fstree.ChecksumStore, error) {
+	switch cfg.Store {
+	case "xattr":
+		return fstree.XattrChecksums(), nil
+	case "db":
+		return fstree.OpenChecksumDB(cfg.DB)
+	}
+	return nil, nil
+}
+
+// checksumRecorder copy/compress/sync 가 방금 쓴 파일의 체크섬을 남겨 (저장소가 꺼져 있으면 아무것도 안 해)
+// ⭐ 기록 실패는 경고만 - 파일은 제대로 써졌으니 명령을 실패로 만들지 않아 (다음 scrub 이 "새로 기록" 으로 채워)
+type checksumRecorder struct {
+	store fstree.ChecksumStore
+}
+
+func (c *common) checksumRecorder() (*checksumRecorder, error) {
+	store, err := openChecksums(c.cfg.Checksum)
+	if err != nil {
+		return nil, msg.Errorf("체크섬 저장소 열기 실패: %w", err)
+	}
+	return &checksumRecorder{store: store}, nil
+}
+
+func (r *checksumRecorder) record(path string) {
+	if r.store == nil {
+		return
+	}
+	if _, err := fstree.RecordChecksum(r.store, path); err != nil {
+		slog.Warn("체크섬을 기록하지 못함", "file", path, "err", err)
+	}
+}
+
+func (r *checksumRecorder) close() {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.Close(); err != nil {
+		slog.Warn("체크섬 저장소 저장 실패", "err", err)
+	}
+}
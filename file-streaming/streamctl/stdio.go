@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/storage"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// 표준 입출력 ("-")
+// ⭐ 파일 자리에 - 를 주면 stdin 에서 읽거나 stdout 으로 써서 셸 파이프라인에 끼울 수 있어.
+//
+//	cat big.log | streamctl compress - - > big.gz
+//	streamctl join chunks.json - | streamctl hash -
+//
+// stdout 으로 데이터를 내보내는 명령은 결과 문구/JSON 을 stderr 로 돌려서 데이터와 섞이지 않게 해.
+// 파이프는 크기를 모르고(진행률은 바이트만) 다시 읽을 수도 없어서(재시도 없음) 일반 파일과 조금 다르게 움직여.
+
+// stdioArg stdin/stdout 을 뜻하는 인자
+const stdioArg = "-"
+
+func isStdio(name string) bool { return name == stdioArg }
+
+// stdinInfo stdin 전송 정보 - 파일을 리다이렉트했으면(< big.log) 크기를 알아서 진행률이 % 로 나와
+func stdinInfo(dst string) streamio.TransferInfo {
+	info := streamio.TransferInfo{ID: "stdin", Src: stdioArg, Dst: dst, Size: -1}
+	if fi, err := os.Stdin.Stat(); err == nil {
+		info.Size = streamio.KnownSize(fi)
+	}
+	return info
+}
+
+// useStdout 이 명령이 stdout 으로 데이터를 내보낸다고 표시 - 이후 print 는 stderr 로 가
+func (c *common) useStdout() { c.stdout = true }
+
+// checkBinaryStdout 바이너리를 터미널에 그대로 쏟지 않게 (gzip 과 같은 동작)
+func checkBinaryStdout() error {
+	if streamio.IsTerminal(os.Stdout) {
+		return msg.New("바이너리 데이터를 터미널에 출력하지 않아 - 파이프나 > 로 받거나 출력 파일을 지정해줘")
+	}
+	return nil
+}
+
+// stdoutWriter stdout 을 storage.Writer 로 - 닫거나 버릴 게 없어 (이미 내보낸 데이터는 되돌릴 수 없으니 종료 코드로 알려)
+type stdoutWriter struct{ io.Writer }
+
+func (stdoutWriter) Close() error { return nil }
+func (stdoutWriter) Abort() error { return nil }
+
+// copyStdio 한쪽이 - 면 stdin/stdout 과 스트리밍 (다른 쪽은 로컬 파일이나 sftp://)
+// 파일 쪽은 storage 의 Create 라 임시 이름에 쓰다가 끝나면 rename 해 - 스트림은 다시 읽을 수 없어서 재시도는 안 해.
+// filters 가 있으면 원본을 그 명령들에 통과시킨 결과를 써 (-filter, 양쪽이 파일이어도 이 길로 와).
+func copyStdio(ctx context.Context, c *common, srcArg, dstArg string, opts streamio.CopyOptions, ssh storage.SSHOptions, filters ...streamio.Transform) error {
+	var src io.Reader = os.Stdin
+	info := stdinInfo(dstArg)
+	if !isStdio(srcArg) {
+		loc, err := c.resolveSource(ctx, srcArg, ssh)
+		if err != nil {
+			return err
+		}
+		defer loc.Close()
+		fi, err := loc.Storage.Stat(ctx, loc.Path)
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return msg.Errorf("디렉토리는 복사할 수 없습니다: %s", srcArg)
+		}
+		r, err := loc.Storage.Open(ctx, loc.Path)
+		if err != nil {
+			return msg.Errorf("소스 파일 열기 실패: %w", err)
+		}
+		defer r.Close()
+		src = r
+		info = streamio.TransferInfo{ID: path.Base(loc.Path), Src: srcArg, Dst: dstArg, Size: streamio.KnownSize(fi)}
+	}
+	if len(filters) > 0 {
+		filtered, err := streamio.Chain(ctx, src, filters...)
+		if err != nil {
+			return err
+		}
+		defer filtered.Close()
+		src = filtered
+		info.Size = -1 // 변환 결과 크기는 미리 알 수 없어
+	}
+
+	var dst storage.Writer = stdoutWriter{os.Stdout}
+	if isStdio(dstArg) {
+		c.useStdout()
+	} else {
+		loc, err := storage.Resolve(ctx, dstArg, ssh)
+		if err != nil {
+			return err
+		}
+		defer loc.Close()
+		dstPath := loc.Path
+		if fi, err := loc.Storage.Stat(ctx, dstPath); err == nil && fi.IsDir() {
+			if isStdio(srcArg) {
+				return msg.Errorf("stdin 은 이름이 없어서 디렉토리에 쓸 수 없어 - 파일 경로를 줘: %s", dstArg)
+			}
+			dstPath = path.Join(dstPath, info.ID) // cp 처럼 그 안에 같은 이름으로
+		}
+		if dst, err = loc.Storage.Create(ctx, dstPath); err != nil {
+			return msg.Errorf("대상 파일 생성 실패: %w", err)
+		}
+	}
+
+	start := time.Now()
+	n, err := streamio.Copy(ctx, dst, src, info, opts)
+	if err != nil {
+		dst.Abort()
+		return msg.Errorf("복사 실패: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return msg.Errorf("대상 파일 닫기 실패: %w", err)
+	}
+	r := newTransferResult(srcArg, dstArg, n, time.Since(start))
+	return c.print(r, msg.Sprintf("복사 완료: %s → %s (%d 바이트, %.2f MB/s)", srcArg, dstArg, n, r.MBPerSec))
+}
+
+// spoolStdin stdin 을 임시 파일로 받아 둬 (cleanup 으로 지워)
+// ⭐ 끊긴 곳부터 다시 보내거나(send) 파트를 동시에 읽는(s3-put) 명령은 되감을 수 있는 파일이 필요해서,
+// 입력 크기만큼 임시 디렉토리($TMPDIR) 공간을 써. 속도 제한은 보낼 때 한 번만 걸어.
+func spoolStdin(ctx context.Context, c *common) (name string, cleanup func(), err error) {
+	tmp, err := os.CreateTemp("", "streamctl-stdin-*")
+	if err != nil {
+		return "", nil, msg.Errorf("임시 파일 생성 실패: %w", err)
+	}
+	cleanup = func() { os.Remove(tmp.Name()) }
+
+	opts := c.copyOptions()
+	opts.RateLimit = 0
+	_, err = streamio.Copy(ctx, tmp, os.Stdin, stdinInfo(tmp.Name()), opts)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, msg.Errorf("stdin 받아 두기 실패: %w", err)
+	}
+	return tmp.Name(), cleanup, nil
+}
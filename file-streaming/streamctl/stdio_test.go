@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stdin → 파일 → stdout - stdout 에는 데이터만, 결과 문구는 stderr 로
+func TestCopyStdio(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(strings.Repeat("pipe line\n", 5000))
+	file := filepath.Join(dir, "from-stdin.txt")
+
+	if _, stderr, code := streamctl(t, bytes.NewReader(data), "copy", "-", file); code != 0 {
+		t.Fatalf("copy - 파일 = 종료 코드 %d: %s", code, stderr)
+	}
+	if got, _ := os.ReadFile(file); !bytes.Equal(got, data) {
+		t.Fatal("stdin 에서 받은 내용이 다름")
+	}
+
+	stdout, stderr, code := streamctl(t, nil, "copy", "-json", file, "-")
+	if code != 0 {
+		t.Fatalf("copy 파일 - = 종료 코드 %d: %s", code, stderr)
+	}
+	if stdout != string(data) {
+		t.Errorf("stdout 에 데이터 말고 다른 게 섞임 (%d 바이트, want %d)", len(stdout), len(data))
+	}
+	var r transferResult
+	if err := json.Unmarshal([]byte(stderr), &r); err != nil || r.Bytes != int64(len(data)) {
+		t.Errorf("stderr 의 결과 = %q, %v", stderr, err)
+	}
+
+	// stdin 은 이름이 없어서 디렉토리에는 못 써
+	if _, stderr, code := streamctl(t, strings.NewReader("x"), "copy", "-", dir); code != 1 || !strings.Contains(stderr, "디렉토리") {
+		t.Errorf("copy - 디렉토리 = 종료 코드 %d, %q", code, stderr)
+	}
+}
+
+// cat big.log | streamctl compress - - | streamctl compress -d - -
+func TestCompressStdio(t *testing.T) {
+	data := []byte(strings.Repeat("compress me\n", 10000))
+	gz, stderr, code := streamctl(t, bytes.NewReader(data), "compress", "-", "-")
+	if code != 0 {
+		t.Fatalf("compress - - = 종료 코드 %d: %s", code, stderr)
+	}
+	zr, err := gzip.NewReader(strings.NewReader(gz))
+	if err != nil {
+		t.Fatalf("stdout 이 gzip 이 아님: %v", err)
+	}
+	if got, _ := io.ReadAll(zr); !bytes.Equal(got, data) {
+		t.Error("gzip 을 푼 내용이 다름")
+	}
+
+	// 출력을 안 주면 입력이 - 일 때 stdout 으로
+	plain, stderr, code := streamctl(t, strings.NewReader(gz), "compress", "-d", "-")
+	if code != 0 {
+		t.Fatalf("compress -d - = 종료 코드 %d: %s", code, stderr)
+	}
+	if plain != string(data) {
+		t.Error("해제한 내용이 원본과 다름")
+	}
+}
+
+// split - 으로 나누고 join … - | hash - 로 확인
+func TestSplitJoinStdio(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(strings.Repeat("chunked log line\n", 4000))
+	chunks := filepath.Join(dir, "chunks")
+
+	if _, stderr, code := streamctl(t, bytes.NewReader(data), "split", "-size", "16KB", "-dir", chunks, "-"); code != 0 {
+		t.Fatalf("split - = 종료 코드 %d: %s", code, stderr)
+	}
+	joined, stderr, code := streamctl(t, nil, "join", filepath.Join(chunks, "chunks.json"), "-")
+	if code != 0 {
+		t.Fatalf("join … - = 종료 코드 %d: %s", code, stderr)
+	}
+	if joined != string(data) {
+		t.Fatalf("합친 내용이 원본과 다름 (%d 바이트, want %d)", len(joined), len(data))
+	}
+
+	stdout, stderr, code := streamctl(t, strings.NewReader(joined), "hash", "-json", "-")
+	var r transferResult
+	if code != 0 || json.Unmarshal([]byte(stdout), &r) != nil {
+		t.Fatalf("hash - = %q, %s (종료 코드 %d)", stdout, stderr, code)
+	}
+	if r.SHA256 != sha256Hex(data) || r.Bytes != int64(len(data)) {
+		t.Errorf("hash - 결과 = %+v", r)
+	}
+}
@@ -0,0 +1,92 @@
+package streamio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Appender 여러 프로세스가 같은 파일(리포트/저널)에 안전하게 덧붙이기 위한 Writer
+//
+// ⭐ O_APPEND 만으로는 부족해: 한 번의 write 가 짧게 끝나면(short write) 나머지를 다시 쓰는 사이에
+// 다른 프로세스의 줄이 끼어들 수 있어. 그래서 레코드 하나를 쓰는 동안 파일 잠금(flock)을 잡고,
+// 레코드 전체가 다 써질 때까지 반복해서 써.
+type Appender struct {
+	mu   sync.Mutex // 같은 프로세스 안의 고루틴끼리 직렬화
+	file *os.File
+}
+
+// OpenAppender 덧붙이기 모드로 파일 열기 (없으면 생성)
+func OpenAppender(path string) (*Appender, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, msg.Errorf("append 파일 열기 실패: %w", err)
+	}
+	return &Appender{file: file}, nil
+}
+
+// Append 레코드 하나를 통째로 덧붙여 - 다른 writer 의 레코드와 섞이지 않아
+func (a *Appender) Append(record []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := lockFile(a.file); err != nil {
+		return msg.Errorf("파일 잠금 실패: %w", err)
+	}
+	defer unlockFile(a.file)
+
+	return writeFull(a.file, record)
+}
+
+// AppendLine 줄 단위 레코드 - 끝에 줄바꿈이 없으면 붙여줘
+func (a *Appender) AppendLine(line string) error {
+	record := []byte(line)
+	if !bytes.HasSuffix(record, []byte("\n")) {
+		record = append(record, '\n')
+	}
+	return a.Append(record)
+}
+
+// Write io.Writer 구현 - 호출 한 번이 레코드 하나
+func (a *Appender) Write(p []byte) (int, error) {
+	if err := a.Append(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close 파일 닫기
+func (a *Appender) Close() error {
+	return a.file.Close()
+}
+
+// AppendFile 파일을 열고 레코드 하나를 덧붙인 뒤 닫기
+func AppendFile(path string, record []byte) error {
+	a, err := OpenAppender(path)
+	if err != nil {
+		return err
+	}
+	if err := a.Append(record); err != nil {
+		a.Close()
+		return err
+	}
+	return a.Close()
+}
+
+// writeFull short write 가 나도 끝까지 쓰기
+func writeFull(w io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := w.Write(p)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		p = p[n:]
+	}
+	return nil
+}
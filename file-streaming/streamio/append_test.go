@@ -0,0 +1,117 @@
+package streamio
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const appendRecords, appendRecordSize = 200, 8 << 10
+
+// 테스트 바이너리를 덧붙이는 다른 프로세스로 다시 띄워 - STREAMIO_TEST_APPEND=경로:글자
+func init() {
+	v := os.Getenv("STREAMIO_TEST_APPEND")
+	if v == "" {
+		return
+	}
+	path, mark, _ := strings.Cut(v, ":")
+	if err := appendMarked(path, mark[0]); err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// appendMarked mark 로만 채운 한 줄짜리 레코드를 appendRecords 번 덧붙여 (페이지보다 커서 write 가 쪼개질 수 있어)
+func appendMarked(path string, mark byte) error {
+	a, err := OpenAppender(path)
+	if err != nil {
+		return err
+	}
+	record := append(bytes.Repeat([]byte{mark}, appendRecordSize-1), '\n')
+	for range appendRecords {
+		if err := a.Append(record); err != nil {
+			a.Close()
+			return err
+		}
+	}
+	return a.Close()
+}
+
+// 다른 프로세스와 같은 프로세스의 다른 Appender 가 동시에 덧붙여도 레코드끼리 섞이지 않아
+func TestAppenderConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for _, mark := range "ab" {
+		cmd := exec.Command(os.Args[0], "-test.run=^$")
+		cmd.Env = append(os.Environ(), "STREAMIO_TEST_APPEND="+path+":"+string(mark))
+		wg.Go(func() {
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("자식 프로세스 %c: %v %s", mark, err, out)
+			}
+		})
+	}
+	for _, mark := range []byte("cd") {
+		wg.Go(func() { errs <- appendMarked(path, mark) })
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	counts := map[byte]int{}
+	r := bufio.NewReaderSize(f, appendRecordSize)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		if err != nil || len(line) != appendRecordSize || bytes.Count(line, line[:1]) != appendRecordSize-1 {
+			t.Fatalf("깨진 레코드 (%d 바이트, %v): %q…", len(line), err, line[:min(len(line), 40)])
+		}
+		counts[line[0]]++
+	}
+	for _, mark := range []byte("abcd") {
+		if counts[mark] != appendRecords {
+			t.Errorf("%c 레코드 %d 개, want %d", mark, counts[mark], appendRecords)
+		}
+	}
+}
+
+type shortWriter struct{ bytes.Buffer }
+
+func (w *shortWriter) Write(p []byte) (int, error) { return w.Buffer.Write(p[:min(len(p), 3)]) }
+
+func TestAppendLineShortWrite(t *testing.T) {
+	var w shortWriter
+	if err := writeFull(&w, []byte("한 줄 전체\n")); err != nil || w.String() != "한 줄 전체\n" {
+		t.Errorf("writeFull = %q, %v", w.String(), err)
+	}
+
+	path := filepath.Join(t.TempDir(), "a.log")
+	a, err := OpenAppender(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.AppendLine("첫 줄")
+	a.AppendLine("둘째 줄\n")
+	a.Close()
+	if got, _ := os.ReadFile(path); string(got) != "첫 줄\n둘째 줄\n" {
+		t.Errorf("파일 = %q", got)
+	}
+}
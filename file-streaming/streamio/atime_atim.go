@@ -0,0 +1,12 @@
+//go:build linux || openbsd || dragonfly || solaris || illumos || aix
+
+package streamio
+
+import (
+	"syscall"
+	"time"
+)
+
+func statAtime(st *syscall.Stat_t) time.Time {
+	return time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec))
+}
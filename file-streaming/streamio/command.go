@@ -0,0 +1,249 @@
+package streamio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 외부 명령 변환 단계 (플러그인)
+// ⭐ ffmpeg, jq, sed 나 직접 만든 필터처럼 stdin 으로 받아 stdout 으로 내보내는 프로그램이면 뭐든 파이프라인 한 단계로 끼울 수 있어.
+// 프로세스 사이는 OS 파이프라 데이터를 메모리에 모으지 않고 흘려보내고, 앞 단계가 느리면 뒤 단계도 같이 기다려 (배압).
+//
+//	r, _ := streamio.Chain(ctx, file, streamio.Command{Args: []string{"jq", "-c", "select(.level==\"error\")"}})
+//	defer r.Close()
+//	io.Copy(dst, r)
+//
+// 명령이 0 이 아닌 코드로 끝나면 스트림 끝(EOF) 대신 *CommandError 를 돌려줘서, 반쯤 변환된 결과를 성공으로 착각하지 않게 해.
+
+// Transform 스트림을 받아 바뀐 스트림을 돌려주는 단계
+type Transform interface {
+	// Transform r 을 읽어 바꾼 결과 스트림 - 다 읽거나 그만 읽을 때 Close 를 꼭 불러
+	Transform(ctx context.Context, r io.Reader) (io.ReadCloser, error)
+}
+
+// Chain r 을 stages 에 차례로 통과시킨 스트림 (단계가 없으면 r 그대로) - 닫으면 모든 단계를 닫아
+func Chain(ctx context.Context, r io.Reader, stages ...Transform) (io.ReadCloser, error) {
+	var closers []io.Closer
+	closeAll := func() error {
+		var errs []error
+		for _, c := range slices.Backward(closers) {
+			errs = append(errs, c.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	cur := r
+	for _, st := range stages {
+		out, err := st.Transform(ctx, cur)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, out)
+		cur = out
+	}
+	return &chainReader{Reader: cur, close: closeAll}, nil
+}
+
+type chainReader struct {
+	io.Reader
+	close func() error
+}
+
+func (c *chainReader) Close() error { return c.close() }
+
+// commandStderrTail 에러에 붙일 stderr 마지막 부분 크기
+const commandStderrTail = 4 << 10
+
+// Command 외부 명령 변환 단계 - stdin 으로 넣고 stdout 으로 받아
+type Command struct {
+	Args        []string      // Args[0] 이 실행 파일 (경로가 아니면 PATH 에서 찾아)
+	Dir         string        // 작업 디렉토리 (비우면 현재 디렉토리)
+	Env         []string      // 현재 환경에 덧붙일 KEY=VALUE
+	Timeout     time.Duration // 명령 전체 실행 시간 제한 (0 이면 없음)
+	OKExitCodes []int         // 0 말고도 성공으로 볼 종료 코드 (grep 은 1 = 찾은 줄 없음)
+	Stderr      io.Writer     // 명령의 stderr 도 여기로 흘려 (nil 이면 에러 메시지용으로 끝부분만 모아)
+}
+
+// Name 로그/에러에 쓸 이름 (실행 파일 이름)
+func (c Command) Name() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	return filepath.Base(c.Args[0])
+}
+
+func (c Command) String() string { return strings.Join(c.Args, " ") }
+
+// CommandError 명령이 실패 코드로 끝났을 때
+type CommandError struct {
+	Name     string
+	ExitCode int    // 시그널로 죽었으면 -1
+	Stderr   string // stderr 마지막 부분
+}
+
+func (e *CommandError) Error() string {
+	text := msg.Sprintf("%s: 종료 코드 %d", e.Name, e.ExitCode)
+	if e.ExitCode < 0 {
+		text = e.Name + msg.T(": 시그널로 종료됨")
+	}
+	if e.Stderr != "" {
+		text += ": " + e.Stderr
+	}
+	return text
+}
+
+// Transform 명령을 띄우고 stdout 을 돌려줘 - r 은 별도 고루틴(exec 패키지)이 명령 stdin 으로 밀어 넣어
+func (c Command) Transform(ctx context.Context, r io.Reader) (io.ReadCloser, error) {
+	if len(c.Args) == 0 {
+		return nil, msg.New("실행할 명령이 비어 있습니다")
+	}
+	ctx, st := StartStage(ctx, "filter "+c.Name(), attribute.String("filter.command", c.String()))
+	var cancel context.CancelFunc
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	cmd := exec.CommandContext(ctx, c.Args[0], c.Args[1:]...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin = r
+	tail := &tailBuffer{max: commandStderrTail}
+	cmd.Stderr = tail
+	if c.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(tail, c.Stderr)
+	}
+	// 죽인 뒤에도 stdin 복사나 손자 프로세스가 파이프를 붙잡고 있으면 Wait 가 안 끝나니까 이만큼만 기다려
+	cmd.WaitDelay = time.Second
+
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		cancel()
+		err = msg.Errorf("%s 실행 실패: %w", c.Name(), err)
+		st.End(err)
+		return nil, err
+	}
+	return &commandReader{c: c, cmd: cmd, stdout: stdout, ctx: ctx, cancel: cancel, tail: tail, stage: st}, nil
+}
+
+// commandReader 명령 stdout - EOF 를 만나면 종료를 기다려서 실패 코드면 EOF 대신 에러
+type commandReader struct {
+	c      Command
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelFunc
+	tail   *tailBuffer
+	stage  *Stage
+
+	once sync.Once
+	err  error
+}
+
+func (cr *commandReader) Read(p []byte) (int, error) {
+	n, err := cr.stdout.Read(p)
+	cr.stage.Add(int64(n))
+	if err == io.EOF {
+		if werr := cr.wait(false); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Close 끝까지 안 읽었으면 명령을 죽이고 정리 (읽는 쪽이 그만둔 거라 에러로 치지 않아)
+func (cr *commandReader) Close() error {
+	cr.wait(true)
+	return nil
+}
+
+func (cr *commandReader) wait(abort bool) error {
+	cr.once.Do(func() {
+		if abort {
+			cr.cancel()
+		}
+		err := cr.cmd.Wait()
+		timedOut := errors.Is(cr.ctx.Err(), context.DeadlineExceeded)
+		cr.cancel()
+		cr.err = cr.classify(err, timedOut)
+		if abort && cr.err != nil && !timedOut {
+			cr.err = nil
+		}
+		cr.stage.End(cr.err)
+	})
+	return cr.err
+}
+
+func (cr *commandReader) classify(err error, timedOut bool) error {
+	name := cr.c.Name()
+	switch {
+	case err == nil:
+		return nil
+	case timedOut:
+		return msg.Errorf("%s: %s 안에 끝나지 않아서 중단했습니다: %w", name, cr.c.Timeout, context.DeadlineExceeded)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		// 명령 자체는 성공했는데 stdin 복사가 실패 - 앞 단계(원본 읽기나 앞 명령)의 에러라 그대로 올려
+		return err
+	}
+	code := exitErr.ExitCode()
+	if slices.Contains(cr.c.OKExitCodes, code) {
+		return nil
+	}
+	if ctxErr := context.Cause(cr.ctx); ctxErr != nil && code < 0 {
+		return fmt.Errorf("%s: %w", name, ctxErr) // 바깥 ctx 취소 (Ctrl+C)
+	}
+	return &CommandError{Name: name, ExitCode: code, Stderr: cr.tail.String()}
+}
+
+// tailBuffer 마지막 max 바이트만 남기는 Writer
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+// String 앞뒤 공백을 자르고 여러 줄이면 " | " 로 이어서 한 줄로
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := bytes.Split(bytes.TrimSpace(t.buf), []byte("\n"))
+	var parts []string
+	for _, l := range lines {
+		if l := strings.TrimSpace(string(l)); l != "" {
+			parts = append(parts, l)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
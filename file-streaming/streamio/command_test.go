@@ -0,0 +1,93 @@
+package streamio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 테스트 바이너리를 필터 명령으로 다시 띄워 (tr, sleep 같은 외부 도구가 없는 OS 에서도 돌게)
+func init() {
+	switch os.Getenv("STREAMIO_TEST_FILTER") {
+	case "":
+		return
+	case "upper":
+		data, _ := io.ReadAll(os.Stdin)
+		os.Stdout.Write([]byte(strings.ToUpper(string(data))))
+		os.Exit(0)
+	case "fail":
+		io.Copy(io.Discard, os.Stdin)
+		fmt.Fprintln(os.Stderr, "잘못된 입력")
+		os.Exit(3)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
+
+func filterCommand(mode string) Command {
+	return Command{Args: []string{os.Args[0]}, Env: []string{"STREAMIO_TEST_FILTER=" + mode}}
+}
+
+func TestCommandChain(t *testing.T) {
+	r, err := Chain(context.Background(), strings.NewReader("hello"), filterCommand("upper"), filterCommand("upper"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "HELLO" {
+		t.Errorf("결과 = %q, want HELLO", got)
+	}
+}
+
+func TestCommandExitCode(t *testing.T) {
+	r, err := Chain(context.Background(), strings.NewReader("x"), filterCommand("fail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, err = io.ReadAll(r)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.ExitCode != 3 || cmdErr.Stderr != "잘못된 입력" {
+		t.Fatalf("err = %v, want 종료 코드 3 CommandError", err)
+	}
+
+	// 성공으로 볼 코드면 그냥 EOF
+	ok := filterCommand("fail")
+	ok.OKExitCodes = []int{3}
+	r2, err := ok.Transform(context.Background(), strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+	if _, err := io.ReadAll(r2); err != nil {
+		t.Errorf("OKExitCodes 인데 에러: %v", err)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	c := filterCommand("hang")
+	c.Timeout = 100 * time.Millisecond
+	r, err := c.Transform(context.Background(), strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	start := time.Now()
+	_, err = io.ReadAll(r)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("시간 제한이 걸렸는데 %s 나 걸림", elapsed)
+	}
+}
@@ -0,0 +1,154 @@
+package streamio
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// DefaultMaxDiffRanges CompareOptions.MaxRanges 기본값
+const DefaultMaxDiffRanges = 1000
+
+// CompareOptions 파일 비교 옵션
+type CompareOptions struct {
+	// StopAtFirst 첫 번째로 다른 구간을 찾으면 바로 멈춰 (같은지만 알면 될 때 빠름)
+	StopAtFirst bool
+	// MaxRanges 기록할 최대 구간 수 (0 이면 DefaultMaxDiffRanges)
+	// 넘치면 DiffBytes 는 계속 세지만 구간 목록에는 더 안 넣어 - 완전히 다른 파일이어도 메모리가 일정해
+	MaxRanges int
+}
+
+// ByteRange 서로 다른 바이트 구간 [Offset, Offset+Length)
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// CompareResult 비교 결과
+type CompareResult struct {
+	SizeA, SizeB int64
+	Equal        bool
+	Diffs        []ByteRange
+	DiffBytes    int64 // 다른 바이트 수 (길이 차이만큼 남는 꼬리도 포함)
+	Truncated    bool  // MaxRanges 를 넘어서 Diffs 가 잘렸는지
+	Stopped      bool  // StopAtFirst 로 중간에 멈췄는지 (DiffBytes/Similarity 는 부분 결과)
+}
+
+// Similarity 같은 바이트 비율(%) - 긴 쪽 크기 기준, 둘 다 비어 있으면 100
+func (r CompareResult) Similarity() float64 {
+	size := max(r.SizeA, r.SizeB)
+	if size == 0 {
+		return 100
+	}
+	return float64(size-r.DiffBytes) / float64(size) * 100
+}
+
+func (r CompareResult) String() string {
+	if r.Equal {
+		return msg.Sprintf("동일 (%d 바이트)", r.SizeA)
+	}
+	if r.Stopped {
+		return msg.Sprintf("다름 (첫 차이: %d 바이트 위치)", r.Diffs[0].Offset)
+	}
+	return msg.Sprintf("다름: %d 구간, %d 바이트 차이, 유사도 %.2f%%", len(r.Diffs), r.DiffBytes, r.Similarity())
+}
+
+// Compare 두 파일을 버퍼 단위로 동시에 읽으면서 바이트 단위로 비교
+// ⭐ 버퍼가 통째로 같으면 bytes.Equal 한 번으로 넘어가고, 다를 때만 바이트 루프를 돌아서
+// 거의 같은 큰 파일도 빠르게 비교할 수 있어. 메모리는 버퍼 두 개 + 구간 목록만 써.
+func Compare(pathA, pathB string, opts CompareOptions) (CompareResult, error) {
+	var result CompareResult
+
+	fileA, err := os.Open(pathA)
+	if err != nil {
+		return result, err
+	}
+	defer fileA.Close()
+	fileB, err := os.Open(pathB)
+	if err != nil {
+		return result, err
+	}
+	defer fileB.Close()
+
+	if info, err := fileA.Stat(); err == nil {
+		result.SizeA = info.Size()
+	}
+	if info, err := fileB.Stat(); err == nil {
+		result.SizeB = info.Size()
+	}
+
+	d := &diffCollector{result: &result, max: opts.MaxRanges}
+	if d.max <= 0 {
+		d.max = DefaultMaxDiffRanges
+	}
+
+	bufA := make([]byte, DefaultBufferSize)
+	bufB := make([]byte, DefaultBufferSize)
+	var offset int64
+
+	for {
+		na, errA := io.ReadFull(fileA, bufA)
+		nb, errB := io.ReadFull(fileB, bufB)
+		if errA != nil && errA != io.EOF && errA != io.ErrUnexpectedEOF {
+			return result, msg.Errorf("%s 읽기 실패: %w", pathA, errA)
+		}
+		if errB != nil && errB != io.EOF && errB != io.ErrUnexpectedEOF {
+			return result, msg.Errorf("%s 읽기 실패: %w", pathB, errB)
+		}
+
+		n := min(na, nb)
+		if !bytes.Equal(bufA[:n], bufB[:n]) {
+			for i := 0; i < n; i++ {
+				if bufA[i] != bufB[i] {
+					d.add(offset+int64(i), 1)
+				}
+			}
+			if opts.StopAtFirst {
+				result.Stopped = true
+				return result, nil
+			}
+		}
+		offset += int64(n)
+
+		if na != nb {
+			// 한쪽이 먼저 끝남: 나머지 꼬리는 전부 차이로 쳐 (크기는 Stat 기준)
+			if tail := max(result.SizeA, result.SizeB) - offset; tail > 0 {
+				d.add(offset, tail)
+			}
+			result.Stopped = opts.StopAtFirst
+			break
+		}
+		if errA != nil || errB != nil {
+			break
+		}
+	}
+
+	result.Equal = result.DiffBytes == 0 && result.SizeA == result.SizeB
+	return result, nil
+}
+
+// diffCollector 연속된 차이는 한 구간으로 합치면서 기록
+type diffCollector struct {
+	result *CompareResult
+	max    int
+}
+
+func (d *diffCollector) add(offset, length int64) {
+	r := d.result
+	r.DiffBytes += length
+
+	if n := len(r.Diffs); n > 0 && !r.Truncated {
+		last := &r.Diffs[n-1]
+		if last.Offset+last.Length == offset {
+			last.Length += length
+			return
+		}
+	}
+	if len(r.Diffs) >= d.max {
+		r.Truncated = true
+		return
+	}
+	r.Diffs = append(r.Diffs, ByteRange{Offset: offset, Length: length})
+}
@@ -0,0 +1,69 @@
+package streamio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writePair(t *testing.T, a, b []byte) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	pa, pb := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.WriteFile(pa, a, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pb, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return pa, pb
+}
+
+func TestCompare(t *testing.T) {
+	base := bytes.Repeat([]byte("x"), 3*DefaultBufferSize)
+
+	pa, pb := writePair(t, base, base)
+	if r, err := Compare(pa, pb, CompareOptions{}); err != nil || !r.Equal || r.Similarity() != 100 {
+		t.Errorf("같은 파일 = %v, %v", r, err)
+	}
+
+	// 버퍼 경계에 걸친 차이는 한 구간으로 합쳐지고, 짧은 쪽 뒤는 꼬리 차이
+	changed := slices.Clone(base)
+	for i := DefaultBufferSize - 2; i < DefaultBufferSize+3; i++ {
+		changed[i] = 'y'
+	}
+	changed[10] = 'z'
+	changed = changed[:len(changed)-100]
+	pa, pb = writePair(t, base, changed)
+	r, err := Compare(pa, pb, CompareOptions{})
+	if err != nil || r.Equal {
+		t.Fatalf("Compare = %v, %v", r, err)
+	}
+	want := []ByteRange{{10, 1}, {int64(DefaultBufferSize - 2), 5}, {int64(len(changed)), 100}}
+	if !slices.Equal(r.Diffs, want) || r.DiffBytes != 106 {
+		t.Errorf("Diffs = %v (%d 바이트), want %v", r.Diffs, r.DiffBytes, want)
+	}
+
+	if r, _ := Compare(pa, pb, CompareOptions{MaxRanges: 2}); !r.Truncated || len(r.Diffs) != 2 || r.DiffBytes != 106 {
+		t.Errorf("MaxRanges 2 = %+v", r)
+	}
+	if r, _ := Compare(pa, pb, CompareOptions{StopAtFirst: true}); !r.Stopped || r.Diffs[0] != (ByteRange{10, 1}) {
+		t.Errorf("StopAtFirst = %+v", r)
+	}
+}
+
+func TestCompareEmpty(t *testing.T) {
+	pa, pb := writePair(t, nil, nil)
+	if r, err := Compare(pa, pb, CompareOptions{}); err != nil || !r.Equal {
+		t.Errorf("빈 파일 둘 = %v, %v", r, err)
+	}
+	pa, pb = writePair(t, nil, []byte("abc"))
+	if r, _ := Compare(pa, pb, CompareOptions{}); r.Equal || r.Similarity() != 0 || !slices.Equal(r.Diffs, []ByteRange{{0, 3}}) {
+		t.Errorf("빈 파일 대 abc = %+v", r)
+	}
+	if _, err := Compare(pa, filepath.Join(t.TempDir(), "missing"), CompareOptions{}); err == nil {
+		t.Error("없는 파일인데 에러가 없음")
+	}
+}
@@ -0,0 +1,243 @@
+package streamio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultBufferSize 기본 복사 버퍼 크기 (32KB ~ 64KB 권장 구간)
+const DefaultBufferSize = 32 * 1024
+
+// CopyOptions 복사 헬퍼 옵션
+type CopyOptions struct {
+	BufferSize int           // 0 이면 DefaultBufferSize
+	Hooks      Hooks         // nil 이면 훅 호출 안 함
+	Retries    int           // CopyFile 에서 실패 시 재시도 횟수
+	RetryDelay time.Duration // 재시도 간격 (회차만큼 곱해서 늘어나)
+
+	// Preserve CopyFile 에서 원본 메타데이터(권한/시각/소유자/xattr)를 복제할 항목
+	// 0 이면 0644 권한에 복사한 시각이 그대로 남아
+	Preserve Metadata
+
+	// RateLimit 초당 최대 바이트 (0 이면 제한 없음) - 공유 디스크/네트워크를 독점하지 않게
+	RateLimit int64
+
+	// Sparse CopyFile 에서 0 으로 채워진 블록을 쓰지 않고 구멍으로 남겨 (sparse 파일 복사용)
+	// 끄면 구멍도 0 으로 다 채워져서 대상이 원본의 논리 크기만큼 디스크를 차지해
+	Sparse bool
+}
+
+func (o CopyOptions) hooks() Hooks {
+	if o.Hooks == nil {
+		return NopHooks{}
+	}
+	return o.Hooks
+}
+
+func (o CopyOptions) buffer() []byte {
+	if o.BufferSize <= 0 {
+		return make([]byte, DefaultBufferSize)
+	}
+	return make([]byte, o.BufferSize)
+}
+
+// hookWriter 쓰기마다 누적 바이트를 훅에 알리고 컨텍스트 취소를 확인하는 Writer
+type hookWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	hooks   Hooks
+	info    TransferInfo
+	written int64
+}
+
+func (hw *hookWriter) Write(p []byte) (int, error) {
+	if err := hw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := hw.w.Write(p)
+	hw.written += int64(n)
+	hw.hooks.OnProgress(hw.info, hw.written)
+	return n, err
+}
+
+// Copy src 를 dst 로 스트리밍 복사하면서 훅을 호출
+// 재시도는 하지 않아 (Reader 는 다시 읽을 수 없으니까) - 재시도가 필요하면 CopyFile 사용
+func Copy(ctx context.Context, dst io.Writer, src io.Reader, info TransferInfo, opts CopyOptions) (int64, error) {
+	ctx, st := StartStage(ctx, "streamio.Copy", infoAttributes(info)...)
+	hooks := opts.hooks()
+	hooks.OnStart(info)
+	start := time.Now()
+
+	written, err := copyWithHooks(ctx, dst, src, info, opts)
+	st.Add(written)
+	st.End(err)
+	if err != nil {
+		hooks.OnError(info, err)
+		return written, err
+	}
+
+	hooks.OnComplete(info, written, time.Since(start))
+	return written, nil
+}
+
+// ⭐ 읽기/쓰기를 각각 단계 스팬으로 - 속도 제한으로 기다린 시간은 읽기 busy 에 안 들어가게 바깥에서 감싸
+func copyWithHooks(ctx context.Context, dst io.Writer, src io.Reader, info TransferInfo, opts CopyOptions) (written int64, err error) {
+	_, read := StartStage(ctx, "read")
+	_, write := StartStage(ctx, "write")
+	defer func() {
+		read.End(err)
+		write.End(err)
+	}()
+
+	hw := &hookWriter{ctx: ctx, w: write.Writer(dst), hooks: opts.hooks(), info: info}
+	src = read.Reader(src)
+	if opts.RateLimit > 0 {
+		src = NewThrottledReader(src, opts.RateLimit)
+	}
+	_, err = io.CopyBuffer(hw, src, opts.buffer())
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) && ctx.Err() != nil {
+		err = ctx.Err() // 취소로 깨운 파이프 Read (copyFileOnce) 는 시간 초과가 아니라 취소로 알려
+	}
+	return hw.written, err
+}
+
+// CopyFile 파일을 안전하게 복사
+// ⭐ 같은 디렉토리의 임시 파일에 쓰고 Sync 한 뒤 rename 해서,
+// 중간에 실패해도 dst 에 불완전한 파일이 남지 않아.
+//
+// 원본이 FIFO/문자 장치면 크기를 모르는 채로(-1) 끝까지 읽고, 다시 읽을 수 없으니 재시도하지 않아.
+// 대상이 이미 있는 FIFO/문자 장치(/dev/null 등)면 임시 파일 없이 그대로 열어서 써 - rename 하면 장치가 일반 파일로 바뀌어 버려.
+func CopyFile(ctx context.Context, src, dst string, opts CopyOptions) (int64, error) {
+	hooks := opts.hooks()
+	info := TransferInfo{ID: filepath.Base(src), Src: src, Dst: dst, Size: -1}
+	if fi, err := os.Stat(src); err == nil {
+		info.Size = KnownSize(fi)
+		if IsStream(fi) {
+			opts.Retries = 0
+		}
+	}
+
+	ctx, st := StartStage(ctx, "streamio.CopyFile", infoAttributes(info)...)
+	hooks.OnStart(info)
+	start := time.Now()
+
+	var (
+		written int64
+		err     error
+	)
+	for attempt := 0; ; attempt++ {
+		written, err = copyFileOnce(ctx, src, dst, info, opts)
+		if err == nil || attempt >= opts.Retries || ctx.Err() != nil {
+			break
+		}
+
+		st.Event("retry", attribute.Int("attempt", attempt+1), attribute.String("error", err.Error()))
+		hooks.OnRetry(info, attempt+1, err)
+		select {
+		case <-time.After(opts.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+		}
+	}
+
+	st.Add(written)
+	st.End(err)
+	if err != nil {
+		hooks.OnError(info, err)
+		return written, err
+	}
+
+	hooks.OnComplete(info, written, time.Since(start))
+	return written, nil
+}
+
+func copyFileOnce(ctx context.Context, src, dst string, info TransferInfo, opts CopyOptions) (written int64, err error) {
+	// FIFO 는 쓰는 쪽이 열 때까지 Open 에서 기다려
+	source, err := OpenShared(src)
+	if err != nil {
+		return 0, msg.Errorf("소스 파일 열기 실패: %w", err)
+	}
+	defer source.Close()
+	// 파이프에서 데이터를 기다리는 Read 는 쓰기 쪽 ctx 확인까지 오지 못하니, 취소되면 읽기 기한을 지나게 해서 깨워
+	// (일반 파일/장치처럼 poll 이 안 되는 파일은 에러가 나고 무시돼 - 어차피 Read 가 오래 막히지 않아)
+	stop := context.AfterFunc(ctx, func() { source.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	if isStreamPath(dst) {
+		return copyToStream(ctx, source, dst, info, opts)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return 0, msg.Errorf("임시 파일 생성 실패: %w", err)
+	}
+	// CreateTemp 는 0600 으로 만들어서 os.Create 와 같은 기본 권한으로 맞춰줘
+	tmp.Chmod(0644)
+	// 실패하면 임시 파일 정리
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	var out io.Writer = tmp
+	var sparse *SparseWriter
+	if opts.Sparse {
+		sparse = NewSparseWriter(tmp)
+		out = sparse
+	}
+
+	written, err = copyWithHooks(ctx, out, source, info, opts)
+	if err != nil {
+		return written, msg.Errorf("복사 실패: %w", err)
+	}
+	if sparse != nil {
+		if err = sparse.Finish(); err != nil {
+			return written, msg.Errorf("sparse 파일 크기 맞추기 실패: %w", err)
+		}
+	}
+
+	_, fsync := StartStage(ctx, "fsync")
+	err = tmp.Sync()
+	fsync.End(err)
+	if err != nil {
+		return written, msg.Errorf("동기화 실패: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return written, msg.Errorf("임시 파일 닫기 실패: %w", err)
+	}
+	// rename 전에 적용해야 dst 가 나타나는 순간부터 원본과 같은 메타데이터를 가져
+	if opts.Preserve != 0 {
+		if err = CopyMetadata(src, tmp.Name(), opts.Preserve); err != nil {
+			return written, err
+		}
+	}
+	if err = Rename(tmp.Name(), dst); err != nil {
+		return written, msg.Errorf("이름 변경 실패: %w", err)
+	}
+
+	return written, nil
+}
+
+// copyToStream 대상이 FIFO/문자 장치면 그대로 열어서 써 (임시 파일, fsync, rename, sparse, 메타데이터 복제 없음)
+func copyToStream(ctx context.Context, source *os.File, dst string, info TransferInfo, opts CopyOptions) (int64, error) {
+	target, err := os.OpenFile(dst, os.O_WRONLY, 0)
+	if err != nil {
+		return 0, msg.Errorf("대상 열기 실패: %w", err)
+	}
+	written, err := copyWithHooks(ctx, target, source, info, opts)
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, msg.Errorf("복사 실패: %w", err)
+	}
+	return written, nil
+}
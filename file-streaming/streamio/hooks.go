@@ -0,0 +1,97 @@
+package streamio
+
+import (
+	"log/slog"
+	"time"
+)
+
+// TransferInfo 훅에 전달되는 전송 정보
+type TransferInfo struct {
+	ID   string // 전송 식별자 (보통 파일명)
+	Src  string // 원본 경로/주소 (없으면 빈 문자열)
+	Dst  string // 목적지 경로/주소
+	Size int64  // 전체 크기, 모르면 -1
+}
+
+// Hooks 전송 생명주기 훅
+// ⭐ 복사/업로드/다운로드 헬퍼가 이 인터페이스만 호출하니까,
+// 메트릭·알림·감사 로그는 핵심 코드를 건드리지 않고 여기에 붙이면 돼.
+type Hooks interface {
+	OnStart(info TransferInfo)
+	OnProgress(info TransferInfo, transferred int64)
+	OnRetry(info TransferInfo, attempt int, err error)
+	OnComplete(info TransferInfo, transferred int64, elapsed time.Duration)
+	OnError(info TransferInfo, err error)
+}
+
+// NopHooks 아무것도 안 하는 훅 - 필요한 메서드만 구현하고 싶을 때 임베드해서 써
+type NopHooks struct{}
+
+func (NopHooks) OnStart(TransferInfo)                          {}
+func (NopHooks) OnProgress(TransferInfo, int64)                {}
+func (NopHooks) OnRetry(TransferInfo, int, error)              {}
+func (NopHooks) OnComplete(TransferInfo, int64, time.Duration) {}
+func (NopHooks) OnError(TransferInfo, error)                   {}
+
+// MultiHooks 여러 훅에 순서대로 이벤트를 전달
+type MultiHooks []Hooks
+
+func (m MultiHooks) OnStart(info TransferInfo) {
+	for _, h := range m {
+		h.OnStart(info)
+	}
+}
+
+func (m MultiHooks) OnProgress(info TransferInfo, transferred int64) {
+	for _, h := range m {
+		h.OnProgress(info, transferred)
+	}
+}
+
+func (m MultiHooks) OnRetry(info TransferInfo, attempt int, err error) {
+	for _, h := range m {
+		h.OnRetry(info, attempt, err)
+	}
+}
+
+func (m MultiHooks) OnComplete(info TransferInfo, transferred int64, elapsed time.Duration) {
+	for _, h := range m {
+		h.OnComplete(info, transferred, elapsed)
+	}
+}
+
+func (m MultiHooks) OnError(info TransferInfo, err error) {
+	for _, h := range m {
+		h.OnError(info, err)
+	}
+}
+
+// LogHooks 시작/재시도/완료/에러를 로그로 남기는 훅 (진행률은 너무 잦아서 생략)
+type LogHooks struct {
+	NopHooks
+	Logger *slog.Logger // nil 이면 slog.Default()
+}
+
+func (l LogHooks) logger(info TransferInfo) *slog.Logger {
+	lg := l.Logger
+	if lg == nil {
+		lg = slog.Default()
+	}
+	return lg.With("transfer", info.ID)
+}
+
+func (l LogHooks) OnStart(info TransferInfo) {
+	l.logger(info).Info("전송 시작", "src", info.Src, "dst", info.Dst, "size", info.Size)
+}
+
+func (l LogHooks) OnRetry(info TransferInfo, attempt int, err error) {
+	l.logger(info).Warn("재시도", "attempt", attempt, "err", err)
+}
+
+func (l LogHooks) OnComplete(info TransferInfo, transferred int64, elapsed time.Duration) {
+	l.logger(info).Info("전송 완료", "bytes", transferred, "elapsed", elapsed)
+}
+
+func (l LogHooks) OnError(info TransferInfo, err error) {
+	l.logger(info).Error("전송 실패", "err", err)
+}
@@ -0,0 +1,78 @@
+package streamio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordHooks 받은 이벤트를 "start", "progress 3", "complete 3" 처럼 적어 둬
+type recordHooks struct {
+	events []string
+}
+
+func (h *recordHooks) OnStart(TransferInfo) { h.events = append(h.events, "start") }
+func (h *recordHooks) OnProgress(_ TransferInfo, n int64) {
+	h.events = append(h.events, fmt.Sprint("progress ", n))
+}
+func (h *recordHooks) OnRetry(_ TransferInfo, attempt int, _ error) {
+	h.events = append(h.events, fmt.Sprint("retry ", attempt))
+}
+func (h *recordHooks) OnComplete(_ TransferInfo, n int64, _ time.Duration) {
+	h.events = append(h.events, fmt.Sprint("complete ", n))
+}
+func (h *recordHooks) OnError(TransferInfo, error) { h.events = append(h.events, "error") }
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("디스크 가득 참") }
+
+func TestCopyHooks(t *testing.T) {
+	a, b := &recordHooks{}, &recordHooks{}
+	var out strings.Builder
+	// strings.Reader 는 WriterTo 라 버퍼를 안 써서 한 번 감싸
+	n, err := Copy(t.Context(), &out, struct{ io.Reader }{strings.NewReader("abcdef")}, TransferInfo{ID: "x", Size: 6}, CopyOptions{BufferSize: 4, Hooks: MultiHooks{a, b}})
+	if err != nil || n != 6 || out.String() != "abcdef" {
+		t.Fatalf("Copy = %d, %v (%q)", n, err, out.String())
+	}
+	want := "start|progress 4|progress 6|complete 6"
+	for _, h := range []*recordHooks{a, b} {
+		if got := strings.Join(h.events, "|"); got != want {
+			t.Errorf("이벤트 = %q, want %q", got, want)
+		}
+	}
+
+	// 쓰기가 실패하면 complete 대신 error
+	h := &recordHooks{}
+	if _, err := Copy(t.Context(), failWriter{}, strings.NewReader("abc"), TransferInfo{ID: "x"}, CopyOptions{Hooks: h}); err == nil {
+		t.Fatal("실패하는 Writer 인데 에러가 없음")
+	}
+	if got := strings.Join(h.events, "|"); got != "start|progress 0|error" {
+		t.Errorf("실패 이벤트 = %q", got)
+	}
+
+	// 취소된 컨텍스트면 쓰기 전에 멈춰
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := Copy(ctx, &out, strings.NewReader("abc"), TransferInfo{ID: "x"}, CopyOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("취소된 Copy = %v, want context.Canceled", err)
+	}
+}
+
+// CopyFile 은 실패할 때마다 OnRetry 를 부르고, 다 써도 안 되면 OnError 로 끝나
+func TestCopyFileRetryHooks(t *testing.T) {
+	h := &recordHooks{}
+	dir := t.TempDir()
+	_, err := CopyFile(t.Context(), filepath.Join(dir, "없는 파일"), filepath.Join(dir, "dst"), CopyOptions{Hooks: h, Retries: 2, RetryDelay: time.Millisecond})
+	if err == nil {
+		t.Fatal("없는 원본인데 에러가 없음")
+	}
+	if got, want := strings.Join(h.events, "|"), "start|retry 1|retry 2|error"; got != want {
+		t.Errorf("이벤트 = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build (!unix && !windows) || solaris || illumos || aix
+
+package streamio
+
+import "os"
+
+// flock 이 없는 플랫폼: 프로세스 간 잠금 없이 O_APPEND 에만 의존해
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
@@ -0,0 +1,28 @@
+//go:build unix && !solaris && !illumos && !aix
+
+package streamio
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 배타적 advisory 잠금 (flock) - 다른 프로세스가 잡고 있으면 풀릴 때까지 대기
+func lockFile(f *os.File) error {
+	if portable {
+		return nil
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(f *os.File) error {
+	if portable {
+		return nil
+	}
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
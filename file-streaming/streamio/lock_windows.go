@@ -0,0 +1,26 @@
+//go:build windows
+
+package streamio
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile 파일 전체 구간에 배타적 잠금 (LockFileEx) - 다른 프로세스가 잡고 있으면 풀릴 때까지 대기
+// ⭐ flock 과 달리 강제 잠금이라, 잡고 있는 동안에는 다른 핸들의 읽기/쓰기도 ERROR_LOCK_VIOLATION 으로 실패해 -
+// 그래서 레코드 하나를 쓰는 동안만 짧게 잡아 (Appender.Append)
+func lockFile(f *os.File) error {
+	if portable {
+		return nil
+	}
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, ^uint32(0), ^uint32(0), new(windows.Overlapped))
+}
+
+func unlockFile(f *os.File) error {
+	if portable {
+		return nil
+	}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, ^uint32(0), ^uint32(0), new(windows.Overlapped))
+}
@@ -0,0 +1,10 @@
+package streamio
+
+// 긴 경로 (Windows)
+// ⭐ Windows 의 옛 API 는 경로가 MAX_PATH(260자)를 넘으면 실패하고, \\?\ 를 앞에 붙인 "있는 그대로" 경로만 길게 받아줘.
+// os 패키지 함수는 Go 가 알아서 붙여주지만, x/sys/windows 로 직접 부르는 API(CreateFile, SetFileInformationByHandle)는
+// 그대로 넘어가서 깊은 디렉토리에서만 ERROR_PATH_NOT_FOUND 가 나 - 그런 곳에 넘기기 전에 LongPath 를 거쳐.
+// Unix 는 제한이 PATH_MAX(4096) 라 그대로 돌려줘.
+
+// maxShortPath 이 길이부터 \\?\ 를 붙여 (디렉토리 생성 제한이 MAX_PATH-12 라 Go 와 같은 기준)
+const maxShortPath = 248
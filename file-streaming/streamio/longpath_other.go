@@ -0,0 +1,6 @@
+//go:build !windows
+
+package streamio
+
+// LongPath Unix 는 경로 길이 제한이 넉넉해서 그대로
+func LongPath(path string) string { return path }
@@ -0,0 +1,32 @@
+//go:build windows
+
+package streamio
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LongPath 절대 경로가 maxShortPath 이상이면 \\?\ 형태로
+func LongPath(path string) string {
+	if abs, err := filepath.Abs(path); err != nil || len(abs) < maxShortPath {
+		return path
+	}
+	return verbatimPath(path)
+}
+
+// verbatimPath 길이와 상관없이 \\?\ 형태로 - Windows 가 정리해주지 않으니 절대 경로로 바꾸고(\ 로 통일, .. 정리) 붙여
+// UNC 경로(\\서버\공유\...)는 \\?\UNC\서버\공유\...
+func verbatimPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\??\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,206 @@
+package streamio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// ChunkManifest 분할 결과 설명서 - 병합할 때 순서와 체크섬 검증에 사용
+type ChunkManifest struct {
+	Source string  `json:"source"`
+	Size   int64   `json:"size"`
+	SHA256 string  `json:"sha256"` // 원본 전체 해시
+	Chunks []Chunk `json:"chunks"`
+}
+
+// ChecksumError 체크섬 불일치 - errors.As 로 어느 청크인지 확인할 수 있어
+type ChecksumError struct {
+	Path     string
+	Index    int // 0 이면 전체 파일
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Index == 0 {
+		return msg.Sprintf("전체 체크섬 불일치: 예상 %s, 실제 %s", e.Expected, e.Actual)
+	}
+	return msg.Sprintf("청크 %d 체크섬 불일치 [%s]: 예상 %s, 실제 %s", e.Index, e.Path, e.Expected, e.Actual)
+}
+
+// WriteChunkManifest 매니페스트를 JSON 으로 저장
+func WriteChunkManifest(path string, m ChunkManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadChunkManifest JSON 매니페스트 읽기 - 상대 경로인 청크는 매니페스트 디렉토리 기준으로 풀어서 돌려줘
+// (어느 디렉토리에서 불러도 Merge 가 청크를 찾아)
+func ReadChunkManifest(path string) (ChunkManifest, error) {
+	var m ChunkManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, msg.Errorf("매니페스트 읽기 실패: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, msg.Errorf("매니페스트 파싱 실패: %w", err)
+	}
+	dir := filepath.Dir(path)
+	for i, c := range m.Chunks {
+		if c.Path == "" || filepath.IsAbs(c.Path) {
+			continue
+		}
+		resolved := filepath.Join(dir, c.Path)
+		// 예전 매니페스트는 자른 곳의 현재 디렉토리 기준이라, 매니페스트 옆에 없고 그대로는 있으면 그걸 써
+		if _, err := os.Stat(resolved); err != nil {
+			if _, err := os.Stat(c.Path); err == nil {
+				continue
+			}
+		}
+		m.Chunks[i].Path = resolved
+	}
+	return m, nil
+}
+
+var chunkNumberRegex = regexp.MustCompile(`^chunk_(\d+)`)
+
+// FindChunks dir 안의 chunk_N.* 파일을 번호 순으로 찾아서 매니페스트로 만들어
+// ⭐ 문자열 정렬이면 chunk_10 이 chunk_2 앞에 오니까 반드시 숫자로 정렬해야 해.
+// 체크섬 정보가 없으니 Merge 는 순서만 맞춰서 합쳐.
+func FindChunks(dir string) (ChunkManifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ChunkManifest{}, err
+	}
+
+	var chunks []Chunk
+	for _, e := range entries {
+		m := chunkNumberRegex.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		chunks = append(chunks, Chunk{Index: n, Path: filepath.Join(dir, e.Name())})
+	}
+	if len(chunks) == 0 {
+		return ChunkManifest{}, msg.Errorf("%s 에 청크 파일이 없습니다", dir)
+	}
+
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+	for i, c := range chunks {
+		if c.Index != i+1 {
+			return ChunkManifest{}, msg.Errorf("청크 %d 가 없습니다", i+1)
+		}
+	}
+	return ChunkManifest{Chunks: chunks}, nil
+}
+
+// Merge 매니페스트 순서대로 청크를 합쳐서 dst 에 써
+// 청크마다 해시를 확인하고 (매니페스트에 있으면), 전체 해시도 확인한 뒤에야 dst 로 rename 해.
+// 검증에 실패하면 dst 는 건드리지 않아.
+func Merge(dst string, m ChunkManifest) (err error) {
+	if len(m.Chunks) == 0 {
+		return msg.New("병합할 청크가 없습니다")
+	}
+
+	chunks := append([]Chunk(nil), m.Chunks...)
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".merge-*")
+	if err != nil {
+		return msg.Errorf("임시 파일 생성 실패: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	tmp.Chmod(0644)
+
+	if _, err = mergeChunks(tmp, dst, chunks, m); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return msg.Errorf("동기화 실패: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return Rename(tmp.Name(), dst)
+}
+
+// MergeTo 매니페스트 순서대로 청크를 w 에 이어 써 (stdout 으로 내보낼 때)
+// ⭐ Merge 와 달리 임시 파일이 없어서, 검증에 실패해도 그 앞까지는 이미 w 로 나가 있어 -
+// 받는 쪽은 에러(종료 코드)를 보고 결과를 버려야 해.
+func MergeTo(w io.Writer, m ChunkManifest) (int64, error) {
+	if len(m.Chunks) == 0 {
+		return 0, msg.New("병합할 청크가 없습니다")
+	}
+	chunks := append([]Chunk(nil), m.Chunks...)
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+	return mergeChunks(w, "-", chunks, m)
+}
+
+// mergeChunks 정렬된 청크를 차례로 이어 쓰고 전체 크기/해시까지 확인 (dst 는 에러 메시지용)
+func mergeChunks(w io.Writer, dst string, chunks []Chunk, m ChunkManifest) (int64, error) {
+	total := sha256.New()
+	out := io.MultiWriter(w, total)
+	buffer := make([]byte, DefaultBufferSize)
+	var written int64
+
+	for _, c := range chunks {
+		n, err := appendChunk(out, c, buffer)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	if m.Size > 0 && written != m.Size {
+		return written, msg.Errorf("크기 불일치: 예상 %d, 실제 %d", m.Size, written)
+	}
+	if m.SHA256 != "" {
+		if actual := hex.EncodeToString(total.Sum(nil)); actual != m.SHA256 {
+			return written, &ChecksumError{Path: dst, Expected: m.SHA256, Actual: actual}
+		}
+	}
+	return written, nil
+}
+
+// appendChunk 청크 하나를 out 에 이어 쓰면서 해시 검증
+func appendChunk(out io.Writer, c Chunk, buffer []byte) (int64, error) {
+	file, err := os.Open(c.Path)
+	if err != nil {
+		return 0, msg.Errorf("청크 %d 열기 실패: %w", c.Index, err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	n, err := io.CopyBuffer(out, io.TeeReader(file, h), buffer)
+	if err != nil {
+		return n, msg.Errorf("청크 %d 복사 실패: %w", c.Index, err)
+	}
+
+	if c.Size > 0 && n != c.Size {
+		return n, msg.Errorf("청크 %d 크기 불일치: 예상 %d, 실제 %d", c.Index, c.Size, n)
+	}
+	if c.SHA256 != "" {
+		if actual := hex.EncodeToString(h.Sum(nil)); actual != c.SHA256 {
+			return n, &ChecksumError{Path: c.Path, Index: c.Index, Expected: c.SHA256, Actual: actual}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,73 @@
+package streamio
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 청크 하나가 바뀌면 어느 청크인지 ChecksumError 로 알려주고 dst 는 그대로 둬
+func TestMergeTamperedChunk(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(strings.Repeat("0123456789", 100))
+	chunks, err := SplitReader(bytes.NewReader(data), "in.bin", SplitOptions{ChunkSize: 300, Dir: dir, ManifestPath: filepath.Join(dir, "chunks.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "out.bin")
+	os.WriteFile(dst, []byte("예전 내용"), 0644)
+
+	// 크기는 같고 내용만 한 바이트 다르게
+	tampered := []byte(strings.Repeat("0123456789", 30))
+	tampered[10] = 'X'
+	os.WriteFile(chunks[1].Path, tampered, 0644)
+
+	m, err := ReadChunkManifest(filepath.Join(dir, "chunks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ce *ChecksumError
+	if err := Merge(dst, m); !errors.As(err, &ce) || ce.Index != 2 {
+		t.Fatalf("Merge = %v, want 청크 2 의 ChecksumError", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "예전 내용" {
+		t.Errorf("검증에 실패했는데 dst 가 바뀜: %q", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(dst)); len(entries) != 1 {
+		t.Errorf("임시 파일이 남음: %d 개", len(entries))
+	}
+
+	// 청크가 빠지면 열기 실패
+	os.Remove(chunks[2].Path)
+	if err := Merge(dst, m); err == nil {
+		t.Error("청크가 없는데 Merge 가 성공")
+	}
+}
+
+// 매니페스트가 없으면 chunk_N 을 숫자 순서로 (chunk_10 이 chunk_2 뒤)
+func TestFindChunksOrder(t *testing.T) {
+	dir := t.TempDir()
+	var want bytes.Buffer
+	for i := 1; i <= 11; i++ {
+		part := strings.Repeat(string(rune('a'+i-1)), i)
+		want.WriteString(part)
+		os.WriteFile(filepath.Join(dir, DefaultChunkName(i)), []byte(part), 0644)
+	}
+	m, err := FindChunks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := MergeTo(&out, m); err != nil || out.String() != want.String() {
+		t.Errorf("MergeTo = %q, %v, want %q", out.String(), err, want.String())
+	}
+
+	// 중간 번호가 빠지면 에러
+	os.Remove(filepath.Join(dir, DefaultChunkName(5)))
+	if _, err := FindChunks(dir); err == nil {
+		t.Error("chunk_5 가 없는데 에러가 없음")
+	}
+}
@@ -0,0 +1,51 @@
+package streamio
+
+import (
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Metadata 복사할 때 함께 옮길 메타데이터 종류 (비트 플래그)
+type Metadata int
+
+const (
+	PreserveMode   Metadata = 1 << iota // 권한 비트 (0644 기본값 대신 원본 권한)
+	PreserveTimes                       // 수정/접근 시각 (복사한 "지금" 대신 원본 시각)
+	PreserveOwner                       // 소유자/그룹 (보통 root 일 때만 가능, 권한 없으면 조용히 건너뜀)
+	PreserveXattrs                      // 확장 속성 (지원하는 플랫폼/파일시스템만)
+
+	PreserveAll = PreserveMode | PreserveTimes | PreserveOwner | PreserveXattrs
+)
+
+// CopyMetadata src 의 메타데이터를 dst 에 적용
+// ⭐ 시각은 마지막에 맞춰야 해 - 권한/속성을 바꾸는 것 자체는 mtime 을 안 건드리지만,
+// 순서를 바꾸면 플랫폼에 따라 다시 덮어써질 수 있어.
+func CopyMetadata(src, dst string, what Metadata) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return msg.Errorf("원본 정보 읽기 실패: %w", err)
+	}
+
+	if what&PreserveMode != 0 {
+		if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+			return msg.Errorf("권한 복사 실패: %w", err)
+		}
+	}
+	if what&PreserveOwner != 0 {
+		if err := copyOwner(dst, info); err != nil {
+			return msg.Errorf("소유자 복사 실패: %w", err)
+		}
+	}
+	if what&PreserveXattrs != 0 {
+		if err := copyXattrs(src, dst); err != nil {
+			return msg.Errorf("확장 속성 복사 실패: %w", err)
+		}
+	}
+	if what&PreserveTimes != 0 {
+		if err := os.Chtimes(dst, accessTime(info), info.ModTime()); err != nil {
+			return msg.Errorf("시각 복사 실패: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package streamio
+
+import (
+	"os"
+	"time"
+)
+
+// 소유자 개념이 다른 플랫폼은 건너뜀
+func copyOwner(dst string, info os.FileInfo) error { return nil }
+
+// 접근 시각을 못 구하면 수정 시각으로 대신해
+func accessTime(info os.FileInfo) time.Time { return info.ModTime() }
@@ -0,0 +1,43 @@
+package streamio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyFilePreserve(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(src, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// 안 고르면 0644 에 복사한 시각
+	plain := filepath.Join(dir, "plain.txt")
+	if _, err := CopyFile(t.Context(), src, plain, CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(plain)
+	if info.Mode().Perm() != 0644 || info.ModTime().Equal(old) {
+		t.Errorf("기본 복사 = %v %v, want 0644 에 지금 시각", info.Mode().Perm(), info.ModTime())
+	}
+
+	kept := filepath.Join(dir, "kept.txt")
+	if _, err := CopyFile(t.Context(), src, kept, CopyOptions{Preserve: PreserveAll}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = os.Stat(kept)
+	if info.Mode().Perm() != 0600 || !info.ModTime().Equal(old) {
+		t.Errorf("PreserveAll = %v %v, want 0600, %v", info.Mode().Perm(), info.ModTime(), old)
+	}
+	// 복사하면서 읽어서 원본 접근 시각이 바뀌었을 수도 있어 (relatime) - 복사 뒤의 원본과 같으면 돼
+	if srcInfo, _ := os.Stat(src); !accessTime(info).Equal(accessTime(srcInfo)) {
+		t.Errorf("접근 시각 = %v, want %v", accessTime(info), accessTime(srcInfo))
+	}
+}
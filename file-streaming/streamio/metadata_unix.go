@@ -0,0 +1,31 @@
+//go:build unix
+
+package streamio
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// copyOwner 소유자/그룹 복사 - 일반 사용자는 남의 소유로 바꿀 수 없으니 EPERM 은 무시
+func copyOwner(dst string, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || portable {
+		return nil
+	}
+	err := os.Lchown(dst, int(st.Uid), int(st.Gid))
+	if errors.Is(err, syscall.EPERM) {
+		return nil
+	}
+	return err
+}
+
+// accessTime 마지막 접근 시각 (플랫폼마다 Stat_t 필드 이름이 달라서 statAtime 에 위임)
+func accessTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && !portable {
+		return statAtime(st)
+	}
+	return info.ModTime()
+}
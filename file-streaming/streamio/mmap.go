@@ -0,0 +1,114 @@
+package streamio
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Advice 매핑된 영역을 어떻게 읽을지 커널에 주는 힌트 (madvise)
+type Advice int
+
+const (
+	AdviceNormal     Advice = iota // 기본
+	AdviceSequential               // 앞에서부터 순서대로 읽음 → 미리 읽기(readahead) 많이
+	AdviceRandom                   // 여기저기 읽음 → 미리 읽기 끄기
+	AdviceWillNeed                 // 곧 읽을 테니 미리 올려둬
+	AdviceDontNeed                 // 다 읽었으니 페이지 캐시에서 내려도 돼
+)
+
+var errMmapClosed = msg.New("mmap: 이미 닫힘")
+
+// Mmap 파일을 메모리에 매핑해서 랜덤 접근하는 Reader
+//
+// ⭐ Seek + Read 를 반복하는 대신 파일 전체를 바이트 슬라이스처럼 다룰 수 있어.
+// 실제 데이터는 접근할 때 페이지 단위로 올라오니까 10GB 파일도 10GB 메모리를 쓰지 않아.
+// Close 전에 Bytes() 로 얻은 슬라이스를 계속 쓰면 안 돼 (unmap 되면 접근 시 크래시).
+// ReadAt, Section, Advise 는 Close 와 겹쳐도 돼 - Close 가 읽는 중인 것들이 끝날 때까지 기다렸다가 unmap 해.
+type Mmap struct {
+	mu     sync.RWMutex // ReadAt/Advise 는 RLock, Close 는 Lock - 읽는 도중에 unmap 되지 않게
+	data   []byte
+	closed bool
+	unmap  func() error
+}
+
+// Len 매핑된 크기 (닫았으면 0)
+func (m *Mmap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// Bytes 매핑된 전체 영역 - 읽기 전용이야, 쓰면 크래시
+func (m *Mmap) Bytes() []byte {
+	return m.data
+}
+
+// ReadAt io.ReaderAt 구현 - 고루틴 여러 개가 동시에 불러도, 그사이 Close 해도 안전해 (닫힌 뒤면 에러)
+func (m *Mmap) ReadAt(p []byte, off int64) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return 0, errMmapClosed
+	}
+	if off < 0 {
+		return 0, msg.New("mmap: 음수 오프셋")
+	}
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Section 일부 구간만 읽는 io.SectionReader
+func (m *Mmap) Section(off, n int64) *io.SectionReader {
+	return io.NewSectionReader(m, off, n)
+}
+
+// Advise 읽기 패턴 힌트 전달 (지원 안 하는 플랫폼에서는 무시)
+func (m *Mmap) Advise(advice Advice) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return errMmapClosed
+	}
+	if len(m.data) == 0 || m.unmap == nil {
+		return nil // 통째로 읽은 대체 구현(readMmap)은 힌트를 줄 매핑이 없어
+	}
+	return madvise(m.data, advice)
+}
+
+// readMmap mmap 대체 구현: 파일을 통째로 메모리에 읽어서 같은 API 를 제공해
+// (큰 파일에는 적합하지 않아 - 동작 호환용, mmap 이 없는 플랫폼이나 portable 일 때)
+func readMmap(path string) (*Mmap, error) {
+	if err := checkMmappable(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, msg.Errorf("mmap 파일 열기 실패: %w", err)
+	}
+	return &Mmap{data: data}, nil
+}
+
+// Close 매핑 해제 - 진행 중인 ReadAt 이 끝난 뒤에 풀어
+func (m *Mmap) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	data := m.data
+	m.data = nil
+	if m.unmap == nil || len(data) == 0 {
+		return nil
+	}
+	return m.unmap()
+}
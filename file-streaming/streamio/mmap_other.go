@@ -0,0 +1,8 @@
+//go:build !unix
+
+package streamio
+
+// OpenMmap mmap 을 못 쓰는 플랫폼: 파일을 통째로 읽어서 같은 API 를 제공해 (readMmap)
+func OpenMmap(path string) (*Mmap, error) { return readMmap(path) }
+
+func madvise(data []byte, advice Advice) error { return nil }
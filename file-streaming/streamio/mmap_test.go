@@ -0,0 +1,108 @@
+package streamio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// 읽는 고루틴들이 도는 중에 Close - 읽기는 온전한 내용이거나 errMmapClosed 여야 해 (unmap 된 메모리를 건드리면 SIGSEGV)
+func TestMmapCloseDuringReadAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64<<10) // 1MB - 한 번 복사에 시간이 좀 걸리게
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	started := make(chan struct{}, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, len(data))
+			for i := 0; ; i++ {
+				if i == 1 {
+					started <- struct{}{}
+				}
+				n, err := m.ReadAt(buf, 0)
+				if errors.Is(err, errMmapClosed) {
+					return
+				}
+				if err != nil || n != len(data) || !bytes.Equal(buf, data) {
+					t.Errorf("ReadAt = %d, %v (내용 같음 %v)", n, err, bytes.Equal(buf, data))
+					return
+				}
+			}
+		}()
+	}
+	for range 8 {
+		<-started
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if _, err := m.ReadAt(make([]byte, 1), 0); !errors.Is(err, errMmapClosed) {
+		t.Errorf("닫은 뒤 ReadAt = %v, want errMmapClosed", err)
+	}
+	if m.Len() != 0 {
+		t.Errorf("닫은 뒤 Len = %d", m.Len())
+	}
+}
+
+func TestMmapReadAt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+	data := []byte("0123456789abcdef")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.Advise(AdviceRandom); err != nil {
+		t.Errorf("Advise = %v", err)
+	}
+
+	buf := make([]byte, 4)
+	if n, err := m.ReadAt(buf, 10); n != 4 || err != nil || string(buf) != "abcd" {
+		t.Errorf("ReadAt(10) = %d, %v, %q", n, err, buf)
+	}
+	// 끝에 걸치면 읽은 만큼과 EOF
+	if n, err := m.ReadAt(buf, 14); n != 2 || err != io.EOF || string(buf[:n]) != "ef" {
+		t.Errorf("ReadAt(14) = %d, %v", n, err)
+	}
+	if _, err := m.ReadAt(buf, 16); err != io.EOF {
+		t.Errorf("끝에서 ReadAt = %v, want EOF", err)
+	}
+	if _, err := m.ReadAt(buf, -1); err == nil {
+		t.Error("음수 오프셋인데 에러가 없음")
+	}
+	if got, err := io.ReadAll(m.Section(4, 6)); err != nil || string(got) != "456789" {
+		t.Errorf("Section = %q, %v", got, err)
+	}
+
+	// 빈 파일은 매핑 없이 열리고, 디렉토리는 거절
+	empty := filepath.Join(dir, "empty")
+	os.WriteFile(empty, nil, 0644)
+	if e, err := OpenMmap(empty); err != nil || e.Len() != 0 {
+		t.Errorf("빈 파일 = %v", err)
+	} else {
+		e.Close()
+	}
+	if _, err := OpenMmap(dir); err == nil {
+		t.Error("디렉토리인데 에러가 없음")
+	}
+}
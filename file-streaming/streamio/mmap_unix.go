@@ -0,0 +1,65 @@
+//go:build unix
+
+package streamio
+
+import (
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"golang.org/x/sys/unix"
+)
+
+// OpenMmap 파일을 읽기 전용으로 매핑
+func OpenMmap(path string) (*Mmap, error) {
+	if portable {
+		return readMmap(path)
+	}
+	// 열기 전에 확인 - FIFO 는 Open 부터 쓰는 쪽을 기다리며 멈춰
+	if err := checkMmappable(path); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, msg.Errorf("mmap 파일 열기 실패: %w", err)
+	}
+	// 매핑이 끝나면 파일 디스크립터는 닫아도 매핑은 유지돼
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, msg.Errorf("mmap: 일반 파일이 아닙니다: %s", path)
+	}
+
+	size := info.Size()
+	if size == 0 {
+		return &Mmap{}, nil
+	}
+	if int64(int(size)) != size {
+		return nil, msg.Errorf("mmap: 파일이 너무 큽니다: %d 바이트", size)
+	}
+
+	data, err := unix.Mmap(int(file.Fd()), 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, msg.Errorf("mmap 실패: %w", err)
+	}
+
+	return &Mmap{data: data, unmap: func() error { return unix.Munmap(data) }}, nil
+}
+
+func madvise(data []byte, advice Advice) error {
+	flag := unix.MADV_NORMAL
+	switch advice {
+	case AdviceSequential:
+		flag = unix.MADV_SEQUENTIAL
+	case AdviceRandom:
+		flag = unix.MADV_RANDOM
+	case AdviceWillNeed:
+		flag = unix.MADV_WILLNEED
+	case AdviceDontNeed:
+		flag = unix.MADV_DONTNEED
+	}
+	return unix.Madvise(data, flag)
+}
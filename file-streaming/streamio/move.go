@@ -0,0 +1,84 @@
+package streamio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Move 파일 이동 - 먼저 os.Rename 을 시도하고, 다른 파일시스템이라 안 되면(EXDEV) 복사 후 삭제
+// ⭐ rename 은 같은 파일시스템 안에서만 되는 메타데이터 작업이라 즉시 끝나지만,
+// 디스크/마운트가 다르면 결국 내용을 다 옮겨야 해. 이 느린 경로에서는:
+//  1. CopyFile 로 스트리밍 복사 (opts.Hooks 로 진행률 보고, 임시 파일 → rename)
+//  2. 원본과 대상의 SHA-256 을 비교해서 검증
+//  3. 검증이 끝난 뒤에야 원본 삭제 - 중간에 실패하면 원본은 그대로 남아
+//
+// opts.Preserve 가 0 이면 메타데이터를 전부(PreserveAll) 복제해서 rename 과 최대한 같게 만들어.
+// 디렉토리는 rename 만 지원해 (다른 파일시스템이면 에러).
+func Move(ctx context.Context, src, dst string, opts CopyOptions) error {
+	err := Rename(src, dst)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+
+	info, statErr := os.Lstat(src)
+	if statErr != nil {
+		return statErr
+	}
+	if !info.Mode().IsRegular() {
+		return msg.Errorf("다른 파일시스템으로는 일반 파일만 이동할 수 있습니다: %s", src)
+	}
+
+	if opts.Preserve == 0 {
+		opts.Preserve = PreserveAll
+	}
+	if _, err := CopyFile(ctx, src, dst, opts); err != nil {
+		return msg.Errorf("이동(복사) 실패: %w", err)
+	}
+
+	if err := verifyCopy(src, dst); err != nil {
+		// 잘못 복사된 대상은 지우고 원본은 남겨둬
+		os.Remove(dst)
+		return err
+	}
+
+	if err := os.Remove(src); err != nil {
+		return msg.Errorf("복사는 끝났지만 원본 삭제 실패: %w", err)
+	}
+	return nil
+}
+
+// verifyCopy 원본과 대상의 해시 비교 - 다르면 *ChecksumError
+func verifyCopy(src, dst string) error {
+	expected, err := FileSHA256(src)
+	if err != nil {
+		return msg.Errorf("원본 해시 계산 실패: %w", err)
+	}
+	actual, err := FileSHA256(dst)
+	if err != nil {
+		return msg.Errorf("대상 해시 계산 실패: %w", err)
+	}
+	if expected != actual {
+		return &ChecksumError{Path: dst, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// FileSHA256 파일 전체의 SHA-256 (16진수 문자열)
+func FileSHA256(path string) (string, error) {
+	file, err := OpenShared(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.CopyBuffer(h, file, make([]byte, DefaultBufferSize)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
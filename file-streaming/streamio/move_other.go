@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package streamio
+
+import (
+	"errors"
+	"os"
+)
+
+// isCrossDevice 플랫폼마다 에러 코드가 달라서, rename 실패(LinkError)는 모두 복사로 다시 시도해
+func isCrossDevice(err error) bool {
+	var linkErr *os.LinkError
+	return errors.As(err, &linkErr)
+}
@@ -0,0 +1,72 @@
+package streamio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// otherFSDir TempDir 과 다른 파일시스템(tmpfs)의 임시 디렉토리 - 없으면 건너뛰어
+func otherFSDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("/dev/shm", "move-test-")
+	if err != nil {
+		t.Skip("다른 파일시스템을 쓸 수 없음:", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	if err := os.Rename(dir, filepath.Join(t.TempDir(), "probe")); err == nil || !isCrossDevice(err) {
+		t.Skip("/dev/shm 이 TempDir 과 같은 파일시스템")
+	}
+	return dir
+}
+
+func TestMove(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	os.WriteFile(src, []byte("same fs"), 0600)
+
+	if err := Move(t.Context(), src, dst, CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("원본이 남아 있음: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "same fs" {
+		t.Errorf("대상 = %q", got)
+	}
+}
+
+// 다른 파일시스템이면 복사 → 검증 → 원본 삭제로, 권한도 rename 처럼 그대로
+func TestMoveCrossDevice(t *testing.T) {
+	other := otherFSDir(t)
+	src, dst := filepath.Join(t.TempDir(), "a.txt"), filepath.Join(other, "a.txt")
+	os.WriteFile(src, []byte("cross fs"), 0600)
+
+	var hooks recordHooks
+	if err := Move(t.Context(), src, dst, CopyOptions{Hooks: &hooks}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("원본이 남아 있음: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "cross fs" {
+		t.Errorf("대상 = %q", got)
+	}
+	if info, _ := os.Stat(dst); info.Mode().Perm() != 0600 {
+		t.Errorf("권한 = %v, want 0600", info.Mode().Perm())
+	}
+	if len(hooks.events) == 0 {
+		t.Error("느린 경로인데 훅이 불리지 않음")
+	}
+
+	// 디렉토리는 rename 만 돼
+	sub := filepath.Join(t.TempDir(), "sub")
+	os.Mkdir(sub, 0755)
+	if err := Move(t.Context(), sub, filepath.Join(other, "sub"), CopyOptions{}); err == nil {
+		t.Error("다른 파일시스템으로 디렉토리 이동인데 에러가 없음")
+	}
+	if _, err := os.Stat(sub); err != nil {
+		t.Errorf("실패했는데 원본 디렉토리가 사라짐: %v", err)
+	}
+}
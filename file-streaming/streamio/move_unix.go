@@ -0,0 +1,13 @@
+//go:build unix
+
+package streamio
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice rename 이 파일시스템 경계 때문에 실패했는지
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package streamio
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isCrossDevice 다른 드라이브/볼륨으로 옮기려 했는지 - 공유 위반 같은 다른 실패까지 복사로 돌리면 원본 삭제에서 또 막혀
+func isCrossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
@@ -0,0 +1,72 @@
+package streamio
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// PathLocks 경로별 뮤텍스 레지스트리
+// ⭐ 같은 프로세스 안에서 두 고루틴이 같은 파일을 동시에 쓰면 (업로드 두 개, 동기화와 업로드 등)
+// 내용이 섞이거나 rename 이 서로를 덮어써. 경로를 키로 잠가서 같은 파일 쓰기만 줄 세우고,
+// 다른 파일은 그대로 병렬로 진행돼. 아무도 안 쓰는 경로의 뮤텍스는 바로 지워서 맵이 계속 커지지 않아.
+// 프로세스 사이의 잠금은 Appender 처럼 flock 을 써야 해.
+type PathLocks struct {
+	mu    sync.Mutex
+	locks map[string]*pathLock
+}
+
+type pathLock struct {
+	mu   sync.Mutex
+	refs int // 잡고 있거나 기다리는 고루틴 수
+}
+
+// NewPathLocks 빈 레지스트리 생성
+func NewPathLocks() *PathLocks {
+	return &PathLocks{locks: make(map[string]*pathLock)}
+}
+
+// DefaultPathLocks 패키지 공용 레지스트리 - 서로 다른 기능끼리도 같은 파일이면 막아야 하니까 보통 이걸 써
+var DefaultPathLocks = NewPathLocks()
+
+// LockPath DefaultPathLocks 로 path 잠금
+func LockPath(path string) (unlock func()) {
+	return DefaultPathLocks.Lock(path)
+}
+
+// Lock path 를 잠그고 해제 함수를 돌려줘 (defer unlock() 으로 써)
+// "./a.txt" 와 "a.txt" 가 같은 잠금이 되도록 정리된 절대 경로를 키로 써.
+func (p *PathLocks) Lock(path string) (unlock func()) {
+	key := lockKey(path)
+
+	p.mu.Lock()
+	l, ok := p.locks[key]
+	if !ok {
+		l = &pathLock{}
+		p.locks[key] = l
+	}
+	l.refs++
+	p.mu.Unlock()
+
+	l.mu.Lock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Unlock()
+
+			p.mu.Lock()
+			l.refs--
+			if l.refs == 0 {
+				delete(p.locks, key)
+			}
+			p.mu.Unlock()
+		})
+	}
+}
+
+func lockKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,59 @@
+package streamio
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPathLocks(t *testing.T) {
+	p := NewPathLocks()
+
+	// 같은 경로는 한 번에 하나씩
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	inside, peak := 0, 0
+	for i := range 16 {
+		path := "a.txt"
+		if i%2 == 0 {
+			path = "./a.txt" // 같은 파일의 다른 표기
+		}
+		wg.Go(func() {
+			unlock := p.Lock(path)
+			defer unlock()
+			mu.Lock()
+			inside++
+			peak = max(peak, inside)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			inside--
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	if peak != 1 {
+		t.Errorf("같은 경로를 동시에 %d 개가 잡음", peak)
+	}
+
+	// 다른 경로는 막지 않아
+	unlockA := p.Lock("a.txt")
+	done := make(chan struct{})
+	go func() {
+		p.Lock("b.txt")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("다른 경로인데 기다림")
+	}
+
+	// 해제는 여러 번 불러도 한 번만
+	unlockA()
+	unlockA()
+	p.Lock("a.txt")()
+	if n := len(p.locks); n != 0 {
+		t.Errorf("아무도 안 쓰는데 잠금 %d 개가 남음", n)
+	}
+}
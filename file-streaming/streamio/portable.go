@@ -0,0 +1,19 @@
+package streamio
+
+// 플랫폼 전용 빠른 경로
+// ⭐ mmap, fallocate 구멍 뚫기, flock, st_blocks, chown, xattr 는 Unix 전용 시스템 콜이라 빌드 태그로 파일이 나뉘어 있고,
+// 그 옆에 어디서나 되는 대체 구현이 있어 (통째로 읽기, 0 으로 덮어쓰기, 잠금 없음, 논리 크기, 건너뛰기).
+// 대체 구현은 Windows 같은 곳에서만 돌아서 Linux CI 에서는 한 번도 안 지나가 - streamio_portable 태그로 빌드하면 Unix 에서도 대체 구현으로 돌아.
+//
+//	go test -tags streamio_portable ./...
+//
+// 하드링크 판별(inode)도 대체 구현에서는 꺼져서, fstree 의 동기화/사용량은 링크를 따로 복사하고 따로 세 - 테스트는 Portable() 로 기대값을 나눠.
+// 환경 변수로 켜지 않는 건, 대체 구현이 flock 까지 꺼서 여러 프로세스가 같은 저널에 덧붙이는 게 깨지기 때문이야 -
+// 운영 바이너리는 태그 없이 빌드하니까 언제나 빠른 경로야. 이 패키지의 테스트는 withPortable 로 테스트 하나만 켜.
+
+// portable true 면 빠른 경로 대신 대체 구현을 써 (기본값은 빌드 태그 - portable_tag.go)
+// 파일을 다루는 도중에 바꾸면 안 돼 - 테스트를 시작할 때만 바꿔.
+var portable = portableBuild
+
+// Portable 빠른 경로 대신 대체 구현으로 도는지 (fstree 처럼 같은 시스템 콜을 쓰는 패키지가 따라가게)
+func Portable() bool { return portable }
@@ -0,0 +1,5 @@
+//go:build !streamio_portable
+
+package streamio
+
+const portableBuild = false
@@ -0,0 +1,6 @@
+//go:build streamio_portable
+
+package streamio
+
+// portableBuild go test -tags streamio_portable 로 빌드하면 처음부터 대체 구현으로
+const portableBuild = true
@@ -0,0 +1,133 @@
+package streamio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 빠른 경로를 끄고(portable) Windows 등에서만 도는 대체 구현을 Linux 에서 돌려봐
+func withPortable(t *testing.T) {
+	t.Helper()
+	old := portable
+	portable = true
+	t.Cleanup(func() { portable = old })
+}
+
+func TestPortableMmap(t *testing.T) {
+	withPortable(t)
+	path := filepath.Join(t.TempDir(), "data.bin")
+	data := bytes.Repeat([]byte("mmap"), 4096)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if !bytes.Equal(m.Bytes(), data) {
+		t.Error("읽은 내용이 원본과 다름")
+	}
+	if err := m.Advise(AdviceSequential); err != nil {
+		t.Errorf("Advise = %v, 대체 구현에서는 무시해야 함", err)
+	}
+}
+
+func TestPortablePunchHole(t *testing.T) {
+	withPortable(t)
+	path := filepath.Join(t.TempDir(), "hole.bin")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xff}, 8192), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PunchHole(f, 1024, 2048); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 8192 {
+		t.Fatalf("크기 = %d, 그대로 8192 여야 함", len(got))
+	}
+	if !isZero(got[1024:3072]) || got[1023] != 0xff || got[3072] != 0xff {
+		t.Error("구간만 0 으로 덮어써야 함")
+	}
+}
+
+func TestPortableCopyPreserve(t *testing.T) {
+	withPortable(t)
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src.txt"), filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CopyFile(t.Context(), src, dst, CopyOptions{Preserve: PreserveAll, Sparse: true}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("권한 = %v, want 0600", info.Mode().Perm())
+	}
+	if AllocatedSize(info) != info.Size() {
+		t.Error("대체 구현의 AllocatedSize 는 논리 크기여야 함")
+	}
+}
+
+func TestPortableAppender(t *testing.T) {
+	withPortable(t)
+	path := filepath.Join(t.TempDir(), "journal.log")
+	a, err := OpenAppender(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"a", "b"} {
+		if err := a.AppendLine(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.Close()
+	if got, _ := os.ReadFile(path); string(got) != "a\nb\n" {
+		t.Errorf("내용 = %q", got)
+	}
+}
+
+// 읽는 중인 파일 위로 Rename 해도 되고, 읽던 쪽은 예전 내용을 끝까지 읽어 (Windows 는 OpenShared 라서)
+func TestRenameOverOpenFile(t *testing.T) {
+	dir := t.TempDir()
+	dst, tmp := filepath.Join(dir, "file.txt"), filepath.Join(dir, ".file.txt.tmp")
+	if err := os.WriteFile(dst, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tmp, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := OpenShared(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if err := Rename(tmp, dst); err != nil {
+		t.Fatalf("열린 파일 위로 Rename 실패: %v", err)
+	}
+
+	old := make([]byte, 3)
+	if _, err := reader.Read(old); err != nil || string(old) != "old" {
+		t.Errorf("열어 둔 쪽 = %q, %v (예전 내용이어야 함)", old, err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "new" {
+		t.Errorf("새로 연 쪽 = %q", got)
+	}
+}
@@ -0,0 +1,290 @@
+// Package streamio 는 각 단계(step)에서 반복되는 스트리밍 헬퍼들을 모아둔 패키지야.
+// 진행률 출력, Reader/Writer 어댑터처럼 여러 도구가 같이 쓰는 코드가 여기 들어가.
+package streamio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// DefaultProgressInterval 진행률 출력 기본 주기
+const DefaultProgressInterval = 500 * time.Millisecond
+
+// ProgressMode 진행률 출력 방식
+type ProgressMode string
+
+const (
+	ProgressNone ProgressMode = "none" // 출력 안 함
+	ProgressText ProgressMode = "text" // 사람이 읽는 한 줄 진행률 (\r 갱신)
+	ProgressBar  ProgressMode = "bar"  // 막대 + 속도 + 남은 시간 (\r 갱신, 터미널용)
+	ProgressJSON ProgressMode = "json" // 줄 단위 JSON (NDJSON) - 스크립트/UI 파싱용
+)
+
+// String flag.Value 구현
+func (m *ProgressMode) String() string {
+	if m == nil || *m == "" {
+		return string(ProgressText)
+	}
+	return string(*m)
+}
+
+// Set flag.Value 구현 - flag.Var(&mode, "progress", ...) 로 바로 쓸 수 있어
+func (m *ProgressMode) Set(s string) error {
+	switch ProgressMode(s) {
+	case ProgressNone, ProgressText, ProgressBar, ProgressJSON:
+		*m = ProgressMode(s)
+		return nil
+	}
+	return msg.Errorf("알 수 없는 진행률 모드: %q (none|text|bar|json)", s)
+}
+
+// ProgressRecord JSON 모드에서 한 줄로 출력되는 진행률 레코드
+type ProgressRecord struct {
+	ID    string  `json:"id"`
+	Bytes int64   `json:"bytes"`
+	Total int64   `json:"total"`          // 모르면 0
+	Rate  float64 `json:"rate"`           // 초당 바이트
+	ETA   float64 `json:"eta"`            // 남은 시간(초), 계산 불가면 -1
+	Done  bool    `json:"done,omitempty"` // 마지막 레코드 표시
+}
+
+// ProgressReporter 일정 주기로 진행률을 출력하는 리포터
+// ⭐ 바이트 카운트는 atomic 으로만 갱신하고, 출력은 별도 고루틴이 ticker 주기로 해.
+// 그래서 Read 루프가 아무리 자주 Add 를 불러도 출력 빈도는 일정하게 유지돼.
+type ProgressReporter struct {
+	id       string
+	total    int64
+	mode     ProgressMode
+	out      io.Writer
+	interval time.Duration
+
+	bytes atomic.Int64
+	start time.Time
+
+	mu      sync.Mutex // out 에 대한 쓰기 직렬화
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewProgressReporter 리포터 생성 - interval 이 0 이하면 DefaultProgressInterval 사용
+func NewProgressReporter(id string, total int64, mode ProgressMode, out io.Writer, interval time.Duration) *ProgressReporter {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	if mode == "" {
+		mode = ProgressText
+	}
+	return &ProgressReporter{
+		id:       id,
+		total:    total,
+		mode:     mode,
+		out:      out,
+		interval: interval,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start 주기적 출력 고루틴 시작
+func (p *ProgressReporter) Start() {
+	p.start = time.Now()
+	if p.mode == ProgressNone {
+		close(p.stopped)
+		return
+	}
+
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.emit(false)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Add 처리한 바이트 수 누적
+func (p *ProgressReporter) Add(n int64) {
+	p.bytes.Add(n)
+}
+
+// Set 처리한 바이트 수를 절대값으로 설정
+func (p *ProgressReporter) Set(n int64) {
+	p.bytes.Store(n)
+}
+
+// Stop 출력 고루틴을 멈추고 마지막 레코드(done)를 출력
+func (p *ProgressReporter) Stop() {
+	p.once.Do(func() {
+		close(p.stop)
+		<-p.stopped
+		if p.mode != ProgressNone {
+			p.emit(true)
+		}
+	})
+}
+
+// Snapshot 현재 진행 상태
+func (p *ProgressReporter) Snapshot() ProgressRecord {
+	bytes := p.bytes.Load()
+	elapsed := time.Since(p.start).Seconds()
+
+	rec := ProgressRecord{ID: p.id, Bytes: bytes, Total: p.total, ETA: -1}
+	if elapsed > 0 {
+		rec.Rate = float64(bytes) / elapsed
+	}
+	if p.total > 0 && rec.Rate > 0 {
+		remaining := p.total - bytes
+		if remaining < 0 {
+			remaining = 0
+		}
+		rec.ETA = float64(remaining) / rec.Rate
+	}
+	return rec
+}
+
+func (p *ProgressReporter) emit(done bool) {
+	rec := p.Snapshot()
+	rec.Done = done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.mode {
+	case ProgressJSON:
+		// 한 레코드 = 한 줄, 파싱하는 쪽은 줄 단위로 json.Unmarshal 하면 돼
+		line, _ := json.Marshal(rec)
+		p.out.Write(append(line, '\n'))
+	case ProgressText:
+		if rec.Total > 0 {
+			percent := float64(rec.Bytes) / float64(rec.Total) * 100
+			msg.Fprintf(p.out, "\r진행률: %.2f%% (%d/%d 바이트, %.1f KB/s)", percent, rec.Bytes, rec.Total, rec.Rate/1024)
+		} else {
+			msg.Fprintf(p.out, "\r처리: %d 바이트 (%.1f KB/s)", rec.Bytes, rec.Rate/1024)
+		}
+		if done {
+			fmt.Fprintln(p.out)
+		}
+	case ProgressBar:
+		io.WriteString(p.out, "\r"+renderBar(rec, done))
+		if done {
+			fmt.Fprintln(p.out)
+		}
+	}
+}
+
+const barWidth = 30
+
+// renderBar 막대 한 줄 - [=========>          ]  45.2%  12.3MB/27.0MB  4.1MB/s  남은 시간 0:03
+// 크기를 모르면 받은 양과 속도만. \r 로 덮어쓰니까 칸 너비를 고정해서 앞 줄 글자가 남지 않게 해.
+func renderBar(rec ProgressRecord, done bool) string {
+	if rec.Total <= 0 {
+		return fmt.Sprintf("%9s  %9s/s", humanBytes(rec.Bytes), humanBytes(int64(rec.Rate)))
+	}
+	frac := min(max(float64(rec.Bytes)/float64(rec.Total), 0), 1)
+	filled := int(frac * barWidth)
+	bar := []byte(strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled))
+	if filled < barWidth && !done {
+		bar[filled] = '>'
+	}
+	eta := "--:--"
+	if rec.ETA >= 0 {
+		eta = formatETA(time.Duration(rec.ETA * float64(time.Second)))
+	}
+	return msg.Sprintf("[%s] %5.1f%%  %9s/%-9s %9s/s  남은 시간 %-8s", bar, frac*100,
+		humanBytes(rec.Bytes), humanBytes(rec.Total), humanBytes(int64(rec.Rate)), eta)
+}
+
+// humanBytes 1536 → 1.5KB (1024 단위)
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatETA 83s → 1:23, 한 시간이 넘으면 1:02:03
+func formatETA(d time.Duration) string {
+	s := int64(d.Round(time.Second) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// ProgressHooks 전송마다 ProgressReporter 를 붙여주는 Hooks 구현
+// CopyFile/Move 같은 훅 기반 헬퍼에 넘기면 진행률 출력이 따라와.
+type ProgressHooks struct {
+	NopHooks
+	Mode     ProgressMode
+	Out      io.Writer
+	Interval time.Duration
+
+	mu        sync.Mutex
+	reporters map[string]*ProgressReporter // 전송 ID 별 리포터 (동시 전송 지원)
+}
+
+func (h *ProgressHooks) OnStart(info TransferInfo) {
+	r := NewProgressReporter(info.ID, max(info.Size, 0), h.Mode, h.Out, h.Interval)
+
+	h.mu.Lock()
+	if h.reporters == nil {
+		h.reporters = make(map[string]*ProgressReporter)
+	}
+	h.reporters[info.ID] = r
+	h.mu.Unlock()
+
+	r.Start()
+}
+
+func (h *ProgressHooks) OnProgress(info TransferInfo, transferred int64) {
+	h.mu.Lock()
+	r := h.reporters[info.ID]
+	h.mu.Unlock()
+	if r != nil {
+		r.Set(transferred)
+	}
+}
+
+// OnRetry 재시도는 처음부터 다시 쓰니까 카운트를 0 으로 돌려
+func (h *ProgressHooks) OnRetry(info TransferInfo, attempt int, err error) {
+	h.OnProgress(info, 0)
+}
+
+func (h *ProgressHooks) OnComplete(info TransferInfo, transferred int64, elapsed time.Duration) {
+	h.finish(info)
+}
+
+func (h *ProgressHooks) OnError(info TransferInfo, err error) {
+	h.finish(info)
+}
+
+func (h *ProgressHooks) finish(info TransferInfo) {
+	h.mu.Lock()
+	r := h.reporters[info.ID]
+	delete(h.reporters, info.ID)
+	h.mu.Unlock()
+	if r != nil {
+		r.Stop()
+	}
+}
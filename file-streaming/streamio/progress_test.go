@@ -0,0 +1,99 @@
+package streamio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderBar(t *testing.T) {
+	got := renderBar(ProgressRecord{Bytes: 15 << 20, Total: 30 << 20, Rate: 5 << 20, ETA: 3}, false)
+	for _, want := range []string{"[===============>              ]", " 50.0%", "15.0MB/30.0MB", "5.0MB/s", "0:03"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderBar = %q, %q 가 없음", got, want)
+		}
+	}
+	// 다 끝나면 머리(>) 없이 꽉 차
+	if got := renderBar(ProgressRecord{Bytes: 10, Total: 10, ETA: 0}, true); !strings.HasPrefix(got, "["+strings.Repeat("=", barWidth)+"] 100.0%") {
+		t.Errorf("완료 = %q", got)
+	}
+	// 크기를 모르면 막대 없이
+	if got := renderBar(ProgressRecord{Bytes: 1536, ETA: -1}, false); strings.Contains(got, "[") || !strings.Contains(got, "1.5KB") {
+		t.Errorf("크기 모름 = %q", got)
+	}
+	// \r 로 덮어써도 앞 줄이 남지 않게 너비가 같아야 해
+	a := renderBar(ProgressRecord{Bytes: 1, Total: 1 << 30, Rate: 1, ETA: 4000}, false)
+	b := renderBar(ProgressRecord{Bytes: 900 << 20, Total: 1 << 30, Rate: 100 << 20, ETA: 1}, false)
+	if len(a) != len(b) {
+		t.Errorf("너비가 다름:\n%q\n%q", a, b)
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	for d, want := range map[time.Duration]string{0: "0:00", 83 * time.Second: "1:23", 3723 * time.Second: "1:02:03"} {
+		if got := formatETA(d); got != want {
+			t.Errorf("formatETA(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestProgressReporterBar(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgressReporter("x", 100, ProgressBar, &out, time.Hour)
+	p.Start()
+	p.Set(100)
+	p.Stop()
+	if s := out.String(); !strings.HasPrefix(s, "\r[") || !strings.HasSuffix(s, "\n") || !strings.Contains(s, "100.0%") {
+		t.Errorf("출력 = %q", s)
+	}
+}
+
+// json 모드는 한 줄에 레코드 하나 - 마지막 줄만 done 이고 다 받은 바이트를 담아
+func TestProgressReporterJSON(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProgressReporter("upload-1", 300, ProgressJSON, &out, time.Millisecond)
+	p.Start()
+	for range 3 {
+		p.Add(100)
+		time.Sleep(5 * time.Millisecond)
+	}
+	p.Stop()
+	p.Stop() // 두 번 불러도 마지막 레코드는 한 번만
+
+	var recs []ProgressRecord
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		var rec ProgressRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("JSON 이 아닌 줄 %q: %v", sc.Text(), err)
+		}
+		recs = append(recs, rec)
+	}
+	if len(recs) < 2 {
+		t.Fatalf("레코드 %d 개, 주기 출력과 마지막 레코드가 있어야 해", len(recs))
+	}
+	for i, rec := range recs {
+		if rec.ID != "upload-1" || rec.Total != 300 || rec.Done != (i == len(recs)-1) {
+			t.Errorf("레코드 %d = %+v", i, rec)
+		}
+	}
+	if last := recs[len(recs)-1]; last.Bytes != 300 || last.ETA != 0 {
+		t.Errorf("마지막 레코드 = %+v, want bytes 300, eta 0", last)
+	}
+}
+
+func TestProgressModeSet(t *testing.T) {
+	var m ProgressMode
+	if m.String() != "text" {
+		t.Errorf("기본 모드 = %q, want text", m.String())
+	}
+	if err := m.Set("json"); err != nil || m != ProgressJSON {
+		t.Errorf("Set(json) = %v, 모드 %q", err, m)
+	}
+	if err := m.Set("xml"); err == nil {
+		t.Error("모르는 모드인데 에러가 없음")
+	}
+}
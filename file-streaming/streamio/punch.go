@@ -0,0 +1,42 @@
+package streamio
+
+import (
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// PunchHole 파일의 [offset, offset+length) 구간을 구멍으로 만들어서 디스크 공간을 돌려줘
+// ⭐ 파일 크기는 그대로고 그 구간을 읽으면 0 이 나와 - 파일을 다시 쓰지 않고도
+// 더 이상 쓰지 않는 구간(덮어써진 청크, 지워진 레코드)의 공간만 회수할 수 있어.
+//
+// Linux 는 fallocate(PUNCH_HOLE) 를 쓰고, 지원하지 않는 파일시스템이나 플랫폼에서는
+// 구간을 0 으로 덮어써 (읽기 결과는 같지만 공간은 회수되지 않아).
+// 파일 끝을 넘는 부분은 무시해.
+func PunchHole(file *os.File, offset, length int64) error {
+	if offset < 0 || length < 0 {
+		return msg.New("offset 과 length 는 0 이상이어야 합니다")
+	}
+	if length == 0 {
+		return nil
+	}
+	return punchHole(file, offset, length)
+}
+
+// zeroFill 구멍을 못 뚫을 때의 대체: 구간을 0 으로 덮어쓰기 (파일 크기는 늘리지 않아)
+func zeroFill(file *os.File, offset, length int64) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	end := min(offset+length, info.Size())
+
+	for offset < end {
+		n := min(int64(len(zeroBlock)), end-offset)
+		if _, err := file.WriteAt(zeroBlock[:n], offset); err != nil {
+			return err
+		}
+		offset += n
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package streamio
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func punchHole(file *os.File, offset, length int64) error {
+	if portable {
+		return zeroFill(file, offset, length)
+	}
+	// KEEP_SIZE: 파일 끝 구간을 뚫어도 크기가 줄지 않게
+	err := unix.Fallocate(int(file.Fd()), unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, offset, length)
+	if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOSYS) {
+		return zeroFill(file, offset, length)
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package streamio
+
+import "os"
+
+// 구멍 뚫기 API 가 플랫폼마다 달라서 (macOS F_PUNCHHOLE, Windows FSCTL_SET_ZERO_DATA) 여기선 0 으로만 덮어써
+func punchHole(file *os.File, offset, length int64) error {
+	return zeroFill(file, offset, length)
+}
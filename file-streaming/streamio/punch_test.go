@@ -0,0 +1,52 @@
+package streamio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPunchHole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	data := bytes.Repeat([]byte{0xff}, 16*sparseBlockSize)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	before, _ := f.Stat()
+
+	if err := PunchHole(f, 2*sparseBlockSize, 8*sparseBlockSize); err != nil {
+		t.Fatal(err)
+	}
+	// 파일 끝을 넘는 구간은 무시하고 크기를 늘리지 않아
+	if err := PunchHole(f, int64(len(data))-10, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if err := PunchHole(f, -1, 10); err == nil {
+		t.Error("음수 offset 인데 에러가 없음")
+	}
+
+	got, _ := os.ReadFile(path)
+	if len(got) != len(data) {
+		t.Fatalf("크기 = %d, want %d", len(got), len(data))
+	}
+	zeros := func(p []byte) bool { return bytes.Count(p, []byte{0}) == len(p) }
+	if !zeros(got[2*sparseBlockSize:10*sparseBlockSize]) || got[2*sparseBlockSize-1] != 0xff || got[10*sparseBlockSize] != 0xff {
+		t.Error("구간만 0 이어야 함")
+	}
+	if !zeros(got[len(got)-10:]) || got[len(got)-11] != 0xff {
+		t.Error("끝 10 바이트만 0 이어야 함")
+	}
+
+	// Linux 에서는 fallocate 로 공간도 돌려받아
+	after, _ := f.Stat()
+	if runtime.GOOS == "linux" && !portable && AllocatedSize(after) >= AllocatedSize(before) {
+		t.Errorf("할당 크기가 줄지 않음: %d → %d", AllocatedSize(before), AllocatedSize(after))
+	}
+}
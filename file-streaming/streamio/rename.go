@@ -0,0 +1,16 @@
+package streamio
+
+import "os"
+
+// 열려 있는 파일 위로 원자적 교체
+// ⭐ 임시 파일에 다 쓰고 최종 이름으로 rename 하는 패턴은 Unix 에서는 누가 대상을 열어 두고 있어도 바로 돼 -
+// 열린 쪽은 예전 inode 를 계속 읽고, 새로 여는 쪽부터 새 내용을 봐.
+// Windows 는 기본 rename(MoveFileEx)이 열린 대상을 못 덮어쓰고, 백신/검색 색인기가 새 파일을 잠깐 잡는 일도 흔해서
+// Rename 은 POSIX 방식 rename 을 먼저 쓰고, 공유 위반이면 잠깐 기다렸다 다시 시도해.
+// 읽는 쪽도 OpenShared 로 열어야 교체를 막지 않아 (os.Open 은 삭제/이름 변경 공유를 안 줘).
+
+// Rename os.Rename 처럼 src 를 dst 로 옮겨 (dst 가 있으면 교체) - 임시 파일 → 최종 이름은 다 이걸로
+func Rename(src, dst string) error { return rename(src, dst) }
+
+// OpenShared 읽기 전용으로 열기 - 읽는 동안에도 다른 쪽이 Rename 으로 교체하거나 지울 수 있게 (Unix 에서는 os.Open 과 같아)
+func OpenShared(name string) (*os.File, error) { return openShared(name) }
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package streamio
+
+import "os"
+
+func rename(src, dst string) error { return os.Rename(src, dst) }
+
+func openShared(name string) (*os.File, error) { return os.Open(name) }
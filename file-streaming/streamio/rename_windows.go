@@ -0,0 +1,96 @@
+//go:build windows
+
+package streamio
+
+import (
+	"errors"
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// renameRetries 다른 프로그램이 대상을 공유 삭제 없이 잡고 있을 때 다시 시도하는 횟수
+// (간격이 회차만큼 늘어나서 합쳐 1초 남짓 기다려)
+const renameRetries = 10
+
+func rename(src, dst string) error {
+	for attempt := 1; ; attempt++ {
+		err := renameOnce(src, dst)
+		if err == nil || !isSharingError(err) || attempt > renameRetries {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * 20 * time.Millisecond)
+	}
+}
+
+// renameOnce POSIX 방식 rename (Windows 10 1709+, NTFS) - 대상이 FILE_SHARE_DELETE 로 열려 있어도 교체돼
+// 파일시스템이 지원하지 않으면(FAT, 오래된 Windows) os.Rename (MoveFileEx) 으로
+func renameOnce(src, dst string) error {
+	err := posixRename(src, dst)
+	if errors.Is(err, windows.ERROR_INVALID_PARAMETER) || errors.Is(err, windows.ERROR_NOT_SUPPORTED) || errors.Is(err, windows.ERROR_INVALID_FUNCTION) {
+		return os.Rename(src, dst)
+	}
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: err}
+	}
+	return nil
+}
+
+// isSharingError 다른 핸들이 파일을 잡고 있어서 실패했는지 (열린 파일을 덮어쓰려 하면 ACCESS_DENIED 로 오기도 해)
+func isSharingError(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) ||
+		errors.Is(err, windows.ERROR_LOCK_VIOLATION) ||
+		errors.Is(err, windows.ERROR_ACCESS_DENIED)
+}
+
+// fileRenameInfoEx FILE_RENAME_INFO 의 Flags 형태 - FileName 은 가변 길이라 버퍼 끝에 이어 붙여
+type fileRenameInfoEx struct {
+	Flags          uint32
+	RootDirectory  windows.Handle
+	FileNameLength uint32 // 바이트 단위, NUL 제외
+	FileName       [1]uint16
+}
+
+func posixRename(src, dst string) error {
+	from, err := windows.UTF16PtrFromString(verbatimPath(src))
+	if err != nil {
+		return err
+	}
+	to, err := windows.UTF16FromString(verbatimPath(dst))
+	if err != nil {
+		return err
+	}
+	h, err := windows.CreateFile(from, windows.DELETE|windows.SYNCHRONIZE,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+
+	// 구조체 정렬을 맞추려고 uint64 로 잡아
+	size := int(unsafe.Offsetof(fileRenameInfoEx{}.FileName)) + len(to)*2
+	buf := make([]uint64, (size+7)/8)
+	info := (*fileRenameInfoEx)(unsafe.Pointer(&buf[0]))
+	info.Flags = windows.FILE_RENAME_REPLACE_IF_EXISTS | windows.FILE_RENAME_POSIX_SEMANTICS
+	info.FileNameLength = uint32((len(to) - 1) * 2)
+	copy(unsafe.Slice(&info.FileName[0], len(to)), to)
+	return windows.SetFileInformationByHandle(h, windows.FileRenameInfoEx, (*byte)(unsafe.Pointer(&buf[0])), uint32(size))
+}
+
+// openShared FILE_SHARE_DELETE 까지 줘서 열어 - os.Open 은 READ|WRITE 만 공유해서 다 읽을 때까지 교체/삭제가 막혀
+func openShared(name string) (*os.File, error) {
+	p, err := windows.UTF16PtrFromString(LongPath(name))
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	h, err := windows.CreateFile(p, windows.GENERIC_READ,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(h), name), nil
+}
@@ -0,0 +1,73 @@
+package streamio
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// sparseBlockSize 구멍(hole)을 찾는 단위 - 대부분 파일시스템의 블록 크기
+const sparseBlockSize = 4096
+
+// IsSparse 할당된 블록이 논리 크기보다 작은지 (= 중간에 구멍이 있는 sparse 파일)
+// ⭐ ls -l 은 논리 크기(apparent), du 는 실제 할당 크기(actual)를 보여줘서 둘이 다르면 sparse 야.
+func IsSparse(info os.FileInfo) bool {
+	return info.Mode().IsRegular() && AllocatedSize(info) < info.Size()
+}
+
+// SparseWriter 0 으로만 채워진 블록은 쓰지 않고 Seek 으로 건너뛰어서 구멍을 유지하는 Writer
+// 마지막이 구멍으로 끝나면 Finish 에서 Truncate 로 크기를 맞춰야 해.
+type SparseWriter struct {
+	file    *os.File
+	offset  int64
+	pending int64 // 아직 Seek 하지 않은 구멍 크기
+}
+
+// NewSparseWriter f 의 현재 위치(보통 빈 파일의 처음)부터 쓰는 SparseWriter
+func NewSparseWriter(f *os.File) *SparseWriter {
+	return &SparseWriter{file: f}
+}
+
+func (sw *SparseWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := sparseBlockSize - int(sw.offset%sparseBlockSize)
+		if n > len(p) {
+			n = len(p)
+		}
+		block := p[:n]
+
+		if isZero(block) {
+			sw.pending += int64(n)
+		} else {
+			if sw.pending > 0 {
+				if _, err := sw.file.Seek(sw.pending, io.SeekCurrent); err != nil {
+					return written, err
+				}
+				sw.pending = 0
+			}
+			if _, err := sw.file.Write(block); err != nil {
+				return written, err
+			}
+		}
+
+		sw.offset += int64(n)
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Finish 끝에 남은 구멍만큼 파일 크기를 늘려 (Truncate 는 데이터를 안 쓰고 크기만 바꿔)
+func (sw *SparseWriter) Finish() error {
+	if sw.pending == 0 {
+		return nil
+	}
+	return sw.file.Truncate(sw.offset)
+}
+
+var zeroBlock = make([]byte, sparseBlockSize)
+
+func isZero(p []byte) bool {
+	return bytes.Equal(p, zeroBlock[:len(p)])
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package streamio
+
+import "os"
+
+// AllocatedSize 할당 크기를 알 수 없는 플랫폼은 논리 크기로 대신해 (sparse 로 판단하지 않음)
+func AllocatedSize(info os.FileInfo) int64 { return info.Size() }
@@ -0,0 +1,75 @@
+package streamio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeSparse size 크기에서 off 위치에만 data 가 있는 파일 - 파일시스템이 구멍을 지원 안 하면 건너뛰어
+func makeSparse(t *testing.T, path string, size, off int64, data []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteAt(data, off); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := f.Stat(); !IsSparse(info) {
+		t.Skip("이 파일시스템은 sparse 파일을 지원하지 않음")
+	}
+}
+
+func TestCopyFileSparse(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "disk.img"), filepath.Join(dir, "copy.img")
+	makeSparse(t, src, 4<<20, 1<<20, []byte("data in the middle"))
+
+	n, err := CopyFile(t.Context(), src, dst, CopyOptions{Sparse: true})
+	if err != nil || n != 4<<20 {
+		t.Fatalf("CopyFile = %d, %v", n, err)
+	}
+	want, _ := os.ReadFile(src)
+	got, _ := os.ReadFile(dst)
+	if !bytes.Equal(got, want) {
+		t.Fatal("내용이 다름")
+	}
+	info, _ := os.Stat(dst)
+	if !IsSparse(info) {
+		t.Errorf("대상이 sparse 가 아님: 할당 %d / 크기 %d", AllocatedSize(info), info.Size())
+	}
+}
+
+// 구멍으로 끝나도 Finish 가 크기를 맞춰
+func TestSparseWriter(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data := make([]byte, 3*sparseBlockSize+100)
+	copy(data[sparseBlockSize+10:], "hello")
+	sw := NewSparseWriter(f)
+	// 블록 경계에 안 맞게 잘라서 써도 같아야 해
+	for p := data; len(p) > 0; {
+		n := min(len(p), 1000)
+		if w, err := sw.Write(p[:n]); w != n || err != nil {
+			t.Fatalf("Write = %d, %v", w, err)
+		}
+		p = p[n:]
+	}
+	if err := sw.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(f.Name())
+	if !bytes.Equal(got, data) {
+		t.Errorf("내용이 다름 (크기 %d, want %d)", len(got), len(data))
+	}
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package streamio
+
+import (
+	"os"
+	"syscall"
+)
+
+// AllocatedSize 디스크에 실제로 할당된 크기 (st_blocks 는 플랫폼 상관없이 512 바이트 단위)
+func AllocatedSize(info os.FileInfo) int64 {
+	if portable {
+		return info.Size()
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(st.Blocks) * 512
+	}
+	return info.Size()
+}
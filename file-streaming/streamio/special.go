@@ -0,0 +1,49 @@
+package streamio
+
+import (
+	"os"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 특수 파일 (FIFO, 문자 장치, 소켓)
+// ⭐ 이름 있는 파이프(mkfifo)나 /dev/stdin, /dev/urandom 은 Stat 크기가 0 이고 되감을 수도 없어.
+// 크기로 진행률을 계산하거나, mmap/seek 하거나, 실패했을 때 처음부터 다시 읽으면 안 돼 - 한 번 읽은 데이터는 사라져.
+
+// IsStream 한 번만 순서대로 읽고 쓸 수 있는 파일인지 (FIFO, 문자 장치, 소켓)
+func IsStream(info os.FileInfo) bool {
+	return info.Mode()&(os.ModeNamedPipe|os.ModeCharDevice|os.ModeSocket) != 0
+}
+
+// KnownSize 진행률에 쓸 크기 - 일반 파일만 Stat 크기를 믿고, 나머지는 -1 (모름)
+func KnownSize(info os.FileInfo) int64 {
+	if !info.Mode().IsRegular() {
+		return -1
+	}
+	return info.Size()
+}
+
+// IsTerminal f 가 터미널(문자 장치)인지 - stdout/stderr 가 파이프나 파일로 리다이렉트됐는지 확인할 때
+// (/dev/null 도 문자 장치라 터미널로 쳐 - 어차피 버려지는 출력이라 상관없어)
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// checkMmappable mmap 은 일반 파일만 - 파이프/장치는 크기도 없고 오프셋으로 접근할 수도 없어
+func checkMmappable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return msg.Errorf("mmap 파일 열기 실패: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return msg.Errorf("mmap: 일반 파일이 아닙니다 (%s): %s", info.Mode().Type(), path)
+	}
+	return nil
+}
+
+// isStreamPath path 가 이미 있는 특수 파일인지 (없거나 Stat 이 실패하면 false)
+func isStreamPath(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && IsStream(info)
+}
@@ -0,0 +1,161 @@
+//go:build linux || darwin
+
+package streamio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func mkfifo(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := unix.Mkfifo(path, 0600); err != nil {
+		t.Skipf("mkfifo 를 쓸 수 없음: %v", err)
+	}
+	return path
+}
+
+// sizeHooks OnStart 로 받은 크기도 적어 둬
+type sizeHooks struct {
+	recordHooks
+	size int64
+}
+
+func (h *sizeHooks) OnStart(info TransferInfo) {
+	h.size = info.Size
+	h.recordHooks.OnStart(info)
+}
+
+func TestSpecialFiles(t *testing.T) {
+	fifo := mkfifo(t, "pipe")
+	regular := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(regular, []byte("12345"), 0644)
+
+	for path, want := range map[string]struct {
+		stream bool
+		size   int64
+	}{regular: {false, 5}, fifo: {true, -1}, os.DevNull: {true, -1}} {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if IsStream(fi) != want.stream || KnownSize(fi) != want.size {
+			t.Errorf("%s: IsStream %v, KnownSize %d, want %v, %d", path, IsStream(fi), KnownSize(fi), want.stream, want.size)
+		}
+	}
+
+	// mmap 은 파이프를 열기 전에 Stat 으로 거절해 (쓰는 쪽이 없어도 막히지 않아)
+	if _, err := OpenMmap(fifo); err == nil {
+		t.Error("FIFO 인데 mmap 이 됨")
+	}
+}
+
+// FIFO 에서 읽으면 크기는 모르는 채로(-1) 끝까지, 재시도하지 않아
+func TestCopyFileFromFIFO(t *testing.T) {
+	fifo := mkfifo(t, "in")
+	dst := filepath.Join(t.TempDir(), "out.bin")
+	data := bytes.Repeat([]byte("stream"), 50_000)
+
+	go func() {
+		w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		w.Write(data)
+		w.Close()
+	}()
+
+	h := &sizeHooks{}
+	n, err := CopyFile(t.Context(), fifo, dst, CopyOptions{Retries: 3, Hooks: h})
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("CopyFile = %d, %v", n, err)
+	}
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, data) {
+		t.Error("복사한 내용이 다름")
+	}
+	if h.size != -1 {
+		t.Errorf("OnStart 크기 = %d, want -1", h.size)
+	}
+	if fi, _ := os.Stat(fifo); !IsStream(fi) {
+		t.Error("원본 FIFO 가 사라짐")
+	}
+}
+
+// 대상이 FIFO 면 임시 파일 + rename 없이 그대로 써서, 복사 뒤에도 FIFO 로 남아
+func TestCopyFileToFIFO(t *testing.T) {
+	fifo := mkfifo(t, "out")
+	src := filepath.Join(t.TempDir(), "in.txt")
+	data := bytes.Repeat([]byte("x"), 100_000)
+	os.WriteFile(src, data, 0644)
+
+	got := make(chan []byte, 1)
+	go func() {
+		r, err := os.Open(fifo)
+		if err != nil {
+			got <- nil
+			return
+		}
+		defer r.Close()
+		b, _ := io.ReadAll(r)
+		got <- b
+	}()
+
+	if _, err := CopyFile(t.Context(), src, fifo, CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if b := <-got; !bytes.Equal(b, data) {
+		t.Errorf("FIFO 로 받은 %d 바이트, want %d", len(b), len(data))
+	}
+	if fi, _ := os.Stat(fifo); !IsStream(fi) {
+		t.Errorf("대상 모드 = %v, FIFO 로 남아야 해", fi.Mode())
+	}
+	if n, err := CopyFile(t.Context(), src, os.DevNull, CopyOptions{}); err != nil || n != int64(len(data)) {
+		t.Errorf("/dev/null 로 CopyFile = %d, %v", n, err)
+	}
+}
+
+// 쓰는 쪽이 멈춰 있어도 취소하면 파이프 Read 에서 깨어나
+func TestCopyFileFIFOCancel(t *testing.T) {
+	fifo := mkfifo(t, "slow")
+	dst := filepath.Join(t.TempDir(), "out")
+
+	w := make(chan *os.File, 1)
+	go func() {
+		f, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if err != nil {
+			w <- nil
+			return
+		}
+		f.Write([]byte("partial"))
+		w <- f // 닫지 않고 멈춰 있어
+	}()
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := CopyFile(ctx, fifo, dst, CopyOptions{})
+	if f := <-w; f != nil {
+		defer f.Close()
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CopyFile = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("취소됐는데 오래 막혀 있었음")
+	}
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Error("취소됐는데 대상이 생김")
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(dst), ".out.tmp-*")); len(tmps) != 0 {
+		t.Errorf("임시 파일이 남음: %v", tmps)
+	}
+}
@@ -0,0 +1,244 @@
+package streamio
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// SplitMode 청크를 자르는 기준
+type SplitMode int
+
+const (
+	// SplitBytes 정확히 ChunkSize 바이트마다 자르기 (바이너리 파일용)
+	SplitBytes SplitMode = iota
+	// SplitLines ChunkSize 근처의 줄바꿈에서 자르기 (텍스트/CSV/로그용)
+	// 각 청크가 완전한 줄들로만 이루어져서 따로 파싱할 수 있어
+	SplitLines
+)
+
+// SplitOptions 분할 옵션
+type SplitOptions struct {
+	ChunkSize int64     // 목표 청크 크기 (필수)
+	Mode      SplitMode // 자르는 기준
+	// MaxChunkSize SplitLines 에서 한 줄이 ChunkSize 보다 길 때 허용할 최대 크기
+	// 0 이면 줄이 아무리 길어도 자르지 않아 (청크 하나가 그 줄 전체가 됨)
+	MaxChunkSize int64
+
+	Dir     string             // 청크 저장 디렉토리 (기본: 현재 디렉토리)
+	NameFor func(n int) string // 청크 파일 이름 (기본: chunk_N.txt, N 은 1부터)
+
+	// ManifestPath 지정하면 청크별/전체 체크섬을 담은 매니페스트(JSON)를 저장 - Merge 에서 검증용
+	ManifestPath string
+}
+
+// Chunk 분할 결과 하나
+type Chunk struct {
+	Index  int    `json:"index"`  // 1부터 시작
+	Path   string `json:"path"`   // 매니페스트에는 매니페스트 디렉토리 기준 상대 경로로 (같은 디렉토리면 파일 이름만)
+	Offset int64  `json:"offset"` // 원본에서의 시작 위치
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// DefaultChunkName chunk_N.txt 형식의 기본 청크 이름
+func DefaultChunkName(n int) string {
+	return fmt.Sprintf("chunk_%d.txt", n)
+}
+
+// chunkWriter 청크 파일 하나를 쓰면서 크기와 해시를 같이 계산
+type chunkWriter struct {
+	file *os.File
+	hash hash.Hash
+	w    io.Writer
+	size int64
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.size += int64(n)
+	return n, err
+}
+
+// Split src 파일을 청크 파일들로 나눠
+// ⭐ 청크 하나 분량(ChunkSize)만 메모리에 두고 처리해서, 원본 크기와 상관없이 메모리 사용량이 일정해.
+func Split(src string, opts SplitOptions) ([]Chunk, error) {
+	if opts.ChunkSize <= 0 {
+		return nil, msg.New("청크 크기는 0보다 커야 합니다")
+	}
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, msg.Errorf("소스 파일 열기 실패: %w", err)
+	}
+	defer file.Close()
+	return SplitReader(file, filepath.Base(src), opts)
+}
+
+// SplitReader r 을 끝까지 읽으면서 청크로 나눠 (stdin 처럼 이름도 크기도 없는 입력용)
+// name 은 매니페스트의 Source 에 그대로 들어가.
+func SplitReader(r io.Reader, name string, opts SplitOptions) ([]Chunk, error) {
+	if opts.ChunkSize <= 0 {
+		return nil, msg.New("청크 크기는 0보다 커야 합니다")
+	}
+	if opts.NameFor == nil {
+		opts.NameFor = DefaultChunkName
+	}
+
+	// 원본 전체 해시는 읽으면서 같이 계산
+	total := sha256.New()
+	reader := bufio.NewReaderSize(io.TeeReader(r, total), DefaultBufferSize)
+	buffer := make([]byte, opts.ChunkSize)
+	carry := 0 // 이전 청크에서 넘어온(줄 경계 뒤쪽) 바이트 수
+
+	var chunks []Chunk
+	var offset int64
+
+	for {
+		n, err := io.ReadFull(reader, buffer[carry:])
+		n += carry
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return chunks, msg.Errorf("청크 %d 읽기 실패: %w", len(chunks)+1, err)
+		}
+		eof := err != nil
+		if n == 0 {
+			break
+		}
+
+		cw, err := createChunk(opts, len(chunks)+1)
+		if err != nil {
+			return chunks, err
+		}
+
+		carry = 0
+		switch {
+		case opts.Mode == SplitBytes || eof:
+			_, err = cw.Write(buffer[:n])
+
+		default:
+			if idx := bytes.LastIndexByte(buffer[:n], '\n'); idx >= 0 {
+				// 버퍼 안에 줄바꿈이 있으면 마지막 줄바꿈까지만 쓰고 나머지는 다음 청크로
+				_, err = cw.Write(buffer[:idx+1])
+				carry = copy(buffer, buffer[idx+1:n])
+			} else {
+				// 한 줄이 ChunkSize 보다 길어: 줄 끝까지 이어서 써 (MaxChunkSize 까지)
+				if _, err = cw.Write(buffer[:n]); err == nil {
+					err = copyUntilNewline(cw, reader, opts.MaxChunkSize)
+				}
+			}
+		}
+
+		chunk, closeErr := cw.close(len(chunks)+1, offset)
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return chunks, msg.Errorf("청크 %d 쓰기 실패: %w", chunk.Index, err)
+		}
+
+		chunks = append(chunks, chunk)
+		offset += chunk.Size
+
+		if eof && carry == 0 {
+			break
+		}
+	}
+
+	if opts.ManifestPath != "" {
+		manifest := ChunkManifest{
+			Source: name,
+			Size:   offset,
+			SHA256: hex.EncodeToString(total.Sum(nil)),
+			Chunks: relativeChunks(opts.ManifestPath, chunks),
+		}
+		if err := WriteChunkManifest(opts.ManifestPath, manifest); err != nil {
+			return chunks, msg.Errorf("매니페스트 저장 실패: %w", err)
+		}
+	}
+
+	return chunks, nil
+}
+
+// relativeChunks 청크 경로를 매니페스트 디렉토리 기준으로 바꾼 사본
+// ⭐ 자른 곳의 현재 디렉토리 기준으로 적으면 다른 디렉토리에서 join 할 때 못 찾아 - 매니페스트와 청크 디렉토리를 통째로 옮겨도 돼.
+func relativeChunks(manifestPath string, chunks []Chunk) []Chunk {
+	out := append([]Chunk(nil), chunks...)
+	base, err := filepath.Abs(filepath.Dir(manifestPath))
+	if err != nil {
+		return out
+	}
+	for i, c := range out {
+		abs, err := filepath.Abs(c.Path)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(base, abs); err == nil {
+			out[i].Path = rel
+		} else {
+			out[i].Path = abs // Windows 에서 드라이브가 다르면 상대 경로가 없어
+		}
+	}
+	return out
+}
+
+// copyUntilNewline 줄바꿈을 만날 때까지 스트리밍으로 복사 (줄 전체를 메모리에 올리지 않아)
+// maxSize 에 닿으면 줄 중간이라도 멈추고, 나머지는 reader 에 남아서 다음 청크가 돼.
+func copyUntilNewline(cw *chunkWriter, reader *bufio.Reader, maxSize int64) error {
+	for maxSize == 0 || cw.size < maxSize {
+		limit := int64(reader.Size())
+		if maxSize > 0 && maxSize-cw.size < limit {
+			limit = maxSize - cw.size
+		}
+
+		// Peek 은 읽은 걸 소비하지 않으니까, 줄바꿈 위치를 보고 필요한 만큼만 Discard 해
+		peek, err := reader.Peek(int(limit))
+		if idx := bytes.IndexByte(peek, '\n'); idx >= 0 {
+			if _, werr := cw.Write(peek[:idx+1]); werr != nil {
+				return werr
+			}
+			reader.Discard(idx + 1)
+			return nil
+		}
+
+		if _, werr := cw.Write(peek); werr != nil {
+			return werr
+		}
+		reader.Discard(len(peek))
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			return err
+		}
+	}
+	return nil
+}
+
+func createChunk(opts SplitOptions, n int) (*chunkWriter, error) {
+	path := filepath.Join(opts.Dir, opts.NameFor(n))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, msg.Errorf("청크 %d 생성 실패: %w", n, err)
+	}
+	h := sha256.New()
+	return &chunkWriter{file: file, hash: h, w: io.MultiWriter(file, h)}, nil
+}
+
+func (cw *chunkWriter) close(n int, offset int64) (Chunk, error) {
+	chunk := Chunk{
+		Index:  n,
+		Path:   cw.file.Name(),
+		Offset: offset,
+		Size:   cw.size,
+		SHA256: hex.EncodeToString(cw.hash.Sum(nil)),
+	}
+	return chunk, cw.file.Close()
+}
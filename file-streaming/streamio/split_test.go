@@ -0,0 +1,123 @@
+package streamio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 자른 곳과 다른 디렉토리에서 합쳐도 매니페스트 옆의 청크를 찾아야 해
+func TestSplitJoinOtherCwd(t *testing.T) {
+	root := t.TempDir()
+	data := []byte(strings.Repeat("한 줄씩 있는 로그\n", 2000))
+	if err := os.WriteFile(filepath.Join(root, "src.log"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// root 에서 상대 경로로 자르기 (streamctl split -dir parts src.log 처럼)
+	t.Chdir(root)
+	if err := os.Mkdir("parts", 0755); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := Split("src.log", SplitOptions{ChunkSize: 4096, Mode: SplitLines, Dir: "parts", ManifestPath: filepath.Join("parts", "chunks.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("청크 %d 개, 여러 개여야 해", len(chunks))
+	}
+	raw, _ := os.ReadFile(filepath.Join("parts", "chunks.json"))
+	if bytes.Contains(raw, []byte("parts")) {
+		t.Errorf("매니페스트에 자른 곳 기준 경로가 남음:\n%s", raw)
+	}
+
+	for _, tc := range []struct{ name, cwd, manifest string }{
+		{"매니페스트 디렉토리", filepath.Join(root, "parts"), "chunks.json"},
+		{"다른 디렉토리", t.TempDir(), filepath.Join(root, "parts", "chunks.json")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Chdir(tc.cwd)
+			m, err := ReadChunkManifest(tc.manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := filepath.Join(t.TempDir(), "joined.log")
+			if err := Merge(out, m); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := os.ReadFile(out); !bytes.Equal(got, data) {
+				t.Errorf("합친 결과 %d 바이트가 원본 %d 바이트와 다름", len(got), len(data))
+			}
+		})
+	}
+}
+
+// 줄 단위로 자르면 마지막 청크 말고는 다 줄바꿈으로 끝나고, 이어 붙이면 원본 그대로
+func TestSplitLines(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	input := "짧은 줄\n" + long + "\n두 번째\n세 번째 줄\n끝"
+	tests := []struct {
+		name  string
+		opts  SplitOptions
+		check func(t *testing.T, sizes []int64, parts []string)
+	}{
+		{"bytes", SplitOptions{ChunkSize: 16, Mode: SplitBytes}, func(t *testing.T, sizes []int64, _ []string) {
+			for _, n := range sizes[:len(sizes)-1] {
+				if n != 16 {
+					t.Errorf("청크 크기 %v, 마지막 말고는 16 이어야 해", sizes)
+					return
+				}
+			}
+		}},
+		{"lines", SplitOptions{ChunkSize: 16, Mode: SplitLines}, func(t *testing.T, _ []int64, parts []string) {
+			if !slices.Contains(parts, long+"\n") {
+				t.Errorf("긴 줄은 자르지 않고 청크 하나로: %q", parts)
+			}
+		}},
+		{"lines max", SplitOptions{ChunkSize: 16, Mode: SplitLines, MaxChunkSize: 40}, func(t *testing.T, sizes []int64, parts []string) {
+			for i, n := range sizes {
+				if n > 40 {
+					t.Errorf("청크 %d 가 %d 바이트, MaxChunkSize 40 을 넘음", i+1, n)
+				}
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = t.TempDir()
+			chunks, err := SplitReader(strings.NewReader(input), "in.txt", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				joined strings.Builder
+				sizes  []int64
+				parts  []string
+				offset int64
+			)
+			for i, c := range chunks {
+				data, err := os.ReadFile(c.Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if c.Index != i+1 || c.Offset != offset || c.Size != int64(len(data)) {
+					t.Errorf("청크 %d = %+v (offset %d, 실제 %d 바이트)", i+1, c, offset, len(data))
+				}
+				if tt.opts.Mode == SplitLines && tt.opts.MaxChunkSize == 0 && i < len(chunks)-1 && !bytes.HasSuffix(data, []byte("\n")) {
+					t.Errorf("청크 %d 가 줄 중간에서 끝남: %q", i+1, data)
+				}
+				offset += c.Size
+				sizes = append(sizes, c.Size)
+				parts = append(parts, string(data))
+				joined.Write(data)
+			}
+			if joined.String() != input {
+				t.Errorf("이어 붙인 결과가 원본과 다름:\n%q", joined.String())
+			}
+			tt.check(t, sizes, parts)
+		})
+	}
+}
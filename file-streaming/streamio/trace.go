@@ -0,0 +1,122 @@
+package streamio
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenTelemetry 트레이싱
+// ⭐ 라이브러리는 OTel API 만 쓰고, 어디로 보낼지(TracerProvider, exporter)는 앱(streamctl 등)이 정해.
+// 앱이 아무것도 설정하지 않으면 no-op 트레이서라 스팬을 만들어도 비용이 거의 없어.
+
+// Tracer 이 모듈의 다른 패키지(s3, server 등)도 같은 이름의 트레이서를 써
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/hellotect2022go/study-go/file-streaming")
+}
+
+// Stage 파이프라인의 한 단계 (읽기, 압축, 쓰기 등) 스팬
+// ⭐ 스트리밍에서는 단계들이 번갈아 조금씩 돌아서 시작~끝 시간은 다 같아.
+// 그래서 Read/Write 호출 안에서 실제로 보낸 시간(busy)을 따로 재서, 어느 단계가 병목인지 보이게 해.
+// 안쪽 Writer 로 이어지는 단계의 busy 에는 아래 단계의 시간도 들어 있어 (gzip.Write 안에서 파일 쓰기까지).
+type Stage struct {
+	span  trace.Span
+	start time.Time
+	bytes atomic.Int64
+	busy  atomic.Int64 // 나노초
+}
+
+// StartStage ctx 의 스팬 아래에 단계 스팬 시작 - 끝나면 End 를 꼭 불러
+func StartStage(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, *Stage) {
+	ctx, span := Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, &Stage{span: span, start: time.Now()}
+}
+
+// Reader 읽은 바이트와 Read 안에서 보낸 시간을 세는 Reader (기록 중이 아니면 r 그대로)
+func (s *Stage) Reader(r io.Reader) io.Reader {
+	if !s.span.IsRecording() {
+		return r
+	}
+	return &stageReader{r: r, s: s}
+}
+
+// Writer 쓴 바이트와 Write 안에서 보낸 시간을 세는 Writer (기록 중이 아니면 w 그대로)
+func (s *Stage) Writer(w io.Writer) io.Writer {
+	if !s.span.IsRecording() {
+		return w
+	}
+	return &stageWriter{w: w, s: s}
+}
+
+// Add Reader/Writer 를 거치지 않은 바이트를 직접 더할 때
+func (s *Stage) Add(n int64) { s.bytes.Add(n) }
+
+// Event 단계 중에 일어난 일 (재시도 등)
+func (s *Stage) Event(name string, attrs ...attribute.KeyValue) {
+	s.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// SetAttributes 단계 속성 추가
+func (s *Stage) SetAttributes(attrs ...attribute.KeyValue) { s.span.SetAttributes(attrs...) }
+
+// End 바이트 수/busy 시간/처리량을 속성으로 남기고 스팬 종료 (err 가 있으면 에러 상태)
+func (s *Stage) End(err error) {
+	if s.span.IsRecording() {
+		n := s.bytes.Load()
+		attrs := []attribute.KeyValue{attribute.Int64("stream.bytes", n)}
+		if busy := time.Duration(s.busy.Load()); busy > 0 {
+			attrs = append(attrs, attribute.Float64("stream.busy_ms", float64(busy.Microseconds())/1000))
+		}
+		if secs := time.Since(s.start).Seconds(); secs > 0 && n > 0 {
+			attrs = append(attrs, attribute.Float64("stream.mb_per_sec", float64(n)/secs/(1<<20)))
+		}
+		s.span.SetAttributes(attrs...)
+		if err != nil {
+			s.span.RecordError(err)
+			s.span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	s.span.End()
+}
+
+type stageReader struct {
+	r io.Reader
+	s *Stage
+}
+
+func (sr *stageReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := sr.r.Read(p)
+	sr.s.busy.Add(int64(time.Since(start)))
+	sr.s.bytes.Add(int64(n))
+	return n, err
+}
+
+type stageWriter struct {
+	w io.Writer
+	s *Stage
+}
+
+func (sw *stageWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := sw.w.Write(p)
+	sw.s.busy.Add(int64(time.Since(start)))
+	sw.s.bytes.Add(int64(n))
+	return n, err
+}
+
+// infoAttributes 전송 정보를 스팬 속성으로
+func infoAttributes(info TransferInfo) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("transfer.id", info.ID),
+		attribute.String("transfer.src", info.Src),
+		attribute.String("transfer.dst", info.Dst),
+		attribute.Int64("transfer.size", info.Size),
+	}
+}
@@ -0,0 +1,122 @@
+package streamio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// withRecorder 끝난 스팬을 메모리에 모으는 TracerProvider 로 잠깐 바꿔 끼워
+func withRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	// 처음 것(전역 위임자)으로 되돌리면 방금 넣은 걸 계속 가리켜서 no-op 으로 돌려놔
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return rec
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestStage(t *testing.T) {
+	rec := withRecorder(t)
+
+	_, st := StartStage(t.Context(), "gzip", attribute.String("codec", "gzip"))
+	io.Copy(st.Writer(io.Discard), st.Reader(strings.NewReader("hello")))
+	st.Add(10)
+	st.Event("retry")
+	st.End(nil)
+
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != "gzip" {
+		t.Fatalf("스팬 = %v", spans)
+	}
+	s := spans[0]
+	// Reader 5 + Writer 5 + Add 10
+	if v, ok := spanAttr(s, "stream.bytes"); !ok || v.AsInt64() != 20 {
+		t.Errorf("stream.bytes = %v, want 20", v.Emit())
+	}
+	if v, _ := spanAttr(s, "codec"); v.AsString() != "gzip" {
+		t.Errorf("codec = %q", v.AsString())
+	}
+	if _, ok := spanAttr(s, "stream.mb_per_sec"); !ok {
+		t.Error("stream.mb_per_sec 가 없음")
+	}
+	if ev := s.Events(); len(ev) != 1 || ev[0].Name != "retry" {
+		t.Errorf("이벤트 = %v", ev)
+	}
+	if s.Status().Code == codes.Error {
+		t.Error("에러 없이 끝났는데 에러 상태")
+	}
+
+	_, st = StartStage(t.Context(), "upload")
+	st.End(errors.New("boom"))
+	if s := rec.Ended()[1]; s.Status().Code != codes.Error || s.Status().Description != "boom" {
+		t.Errorf("상태 = %+v, want Error boom", s.Status())
+	}
+}
+
+// 기록하지 않을 때는 Reader/Writer 를 감싸지 않아 (no-op 비용)
+func TestStageNoop(t *testing.T) {
+	_, st := StartStage(t.Context(), "noop")
+	defer st.End(nil)
+	r := strings.NewReader("x")
+	if st.Reader(r) != io.Reader(r) {
+		t.Error("no-op 트레이서인데 Reader 를 감쌈")
+	}
+	if st.Writer(io.Discard) != io.Discard {
+		t.Error("no-op 트레이서인데 Writer 를 감쌈")
+	}
+}
+
+// Copy 는 read/write 단계 스팬을 streamio.Copy 스팬 아래에 남겨
+func TestCopySpans(t *testing.T) {
+	rec := withRecorder(t)
+	data := bytes.Repeat([]byte("x"), 1000)
+	info := TransferInfo{ID: "t1", Src: "in", Dst: "out", Size: int64(len(data))}
+	var dst bytes.Buffer
+	if _, err := Copy(t.Context(), &dst, bytes.NewReader(data), info, CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		byName[s.Name()] = s
+	}
+	root, ok := byName["streamio.Copy"]
+	if !ok {
+		t.Fatalf("streamio.Copy 스팬이 없음: %v", byName)
+	}
+	if v, _ := spanAttr(root, "transfer.src"); v.AsString() != "in" {
+		t.Errorf("transfer.src = %q", v.AsString())
+	}
+	for _, name := range []string{"read", "write"} {
+		s, ok := byName[name]
+		if !ok {
+			t.Errorf("%s 스팬이 없음", name)
+			continue
+		}
+		if s.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("%s 스팬의 부모가 streamio.Copy 가 아님", name)
+		}
+		if v, _ := spanAttr(s, "stream.bytes"); v.AsInt64() != 1000 {
+			t.Errorf("%s stream.bytes = %d, want 1000", name, v.AsInt64())
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package streamio
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressFunc 진행률 콜백 - total 을 모르면 0
+type ProgressFunc func(current, total int64)
+
+// ProgressReader 읽을 때마다 누적 바이트 수를 콜백으로 알려주는 Reader 어댑터
+// 출력 빈도를 일정하게 하고 싶으면 콜백에서 ProgressReporter.Set 만 호출해.
+type ProgressReader struct {
+	reader   io.Reader
+	total    int64
+	current  int64
+	callback ProgressFunc
+}
+
+// NewProgressReader r 을 감싸는 ProgressReader 생성 (callback 은 nil 가능)
+func NewProgressReader(r io.Reader, total int64, callback ProgressFunc) *ProgressReader {
+	return &ProgressReader{reader: r, total: total, callback: callback}
+}
+
+func (pr *ProgressReader) Read(p []byte) (n int, err error) {
+	n, err = pr.reader.Read(p)
+	pr.current += int64(n)
+
+	if pr.callback != nil {
+		pr.callback(pr.current, pr.total)
+	}
+	return n, err
+}
+
+// Current 지금까지 읽은 바이트 수
+func (pr *ProgressReader) Current() int64 {
+	return pr.current
+}
+
+// 프로세스 전체 ThrottledReader 가 속도 제한 때문에 잠든 횟수와 시간 (ThrottleStats)
+var throttleWaits, throttleWaited atomic.Int64
+
+// ThrottleStat 속도 제한 상태 - 대기가 계속 늘고 있으면 제한이 실제로 걸리고 있는 거야
+type ThrottleStat struct {
+	Waits  int64         `json:"waits"`
+	Waited time.Duration `json:"waited"`
+}
+
+// ThrottleStats 프로세스 시작부터 지금까지 속도 제한으로 기다린 횟수와 총 시간
+func ThrottleStats() ThrottleStat {
+	return ThrottleStat{Waits: throttleWaits.Load(), Waited: time.Duration(throttleWaited.Load())}
+}
+
+// ThrottledReader 초당 bytesPerSec 이하로 읽기 속도를 제한하는 Reader 어댑터
+// ⭐ 시작 시각부터 읽은 총량을 기준으로 "이만큼 읽었으면 지금쯤이어야 한다" 를 계산해서
+// 앞서 나가면 그만큼 잠들어. 매 Read 사이 간격만 보는 방식보다 평균 속도가 정확하고,
+// 0 바이트를 돌려주며 헛돌지 않아.
+type ThrottledReader struct {
+	reader      io.Reader
+	bytesPerSec int64
+	start       time.Time
+	read        int64
+}
+
+// NewThrottledReader 속도 제한 Reader 생성 - bytesPerSec 가 0 이하면 제한 없음
+func NewThrottledReader(r io.Reader, bytesPerSec int64) *ThrottledReader {
+	return &ThrottledReader{reader: r, bytesPerSec: bytesPerSec}
+}
+
+func (tr *ThrottledReader) Read(p []byte) (n int, err error) {
+	if tr.bytesPerSec <= 0 {
+		return tr.reader.Read(p)
+	}
+	if tr.start.IsZero() {
+		tr.start = time.Now()
+	}
+
+	// 한 번에 0.1초 분량까지만 읽어야 흐름이 고르게 나와
+	if chunk := tr.bytesPerSec/10 + 1; int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+
+	expected := time.Duration(float64(tr.read) / float64(tr.bytesPerSec) * float64(time.Second))
+	if wait := expected - time.Since(tr.start); wait > 0 {
+		throttleWaits.Add(1)
+		throttleWaited.Add(int64(wait))
+		time.Sleep(wait)
+	}
+
+	n, err = tr.reader.Read(p)
+	tr.read += int64(n)
+	return n, err
+}
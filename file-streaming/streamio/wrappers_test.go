@@ -0,0 +1,85 @@
+package streamio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReader(t *testing.T) {
+	data := strings.Repeat("x", 10000)
+
+	var calls int
+	var last int64
+	pr := NewProgressReader(strings.NewReader(data), int64(len(data)), func(current, total int64) {
+		calls++
+		if current < last {
+			t.Errorf("진행률이 줄어듦: %d -> %d", last, current)
+		}
+		if total != int64(len(data)) {
+			t.Errorf("total = %d, want %d", total, len(data))
+		}
+		last = current
+	})
+
+	// bytes.Buffer 의 ReadFrom 이 버퍼 크기를 바꾸지 않게 Writer 만 노출
+	var out bytes.Buffer
+	if _, err := io.CopyBuffer(struct{ io.Writer }{&out}, pr, make([]byte, 1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != data {
+		t.Error("읽은 내용이 원본과 다름")
+	}
+	if last != int64(len(data)) || pr.Current() != int64(len(data)) {
+		t.Errorf("최종 진행률 = %d, want %d", last, len(data))
+	}
+	if calls < 10 {
+		t.Errorf("콜백 호출 %d번, 버퍼 크기 기준으로 최소 10번이어야 함", calls)
+	}
+}
+
+func TestProgressReaderNilCallback(t *testing.T) {
+	pr := NewProgressReader(strings.NewReader("abc"), 3, nil)
+	data, err := io.ReadAll(pr)
+	if err != nil || string(data) != "abc" {
+		t.Fatalf("ReadAll = %q, %v", data, err)
+	}
+}
+
+func TestThrottledReader(t *testing.T) {
+	const rate = 100 * 1024 // 100KB/s
+	data := bytes.Repeat([]byte("y"), 30*1024)
+
+	start := time.Now()
+	got, err := io.ReadAll(NewThrottledReader(bytes.NewReader(data), rate))
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("읽은 내용이 원본과 다름")
+	}
+	// 30KB / 100KB/s ≈ 0.3초 - 첫 청크는 기다리지 않으니 약간 여유를 둬
+	if elapsed < 200*time.Millisecond {
+		t.Errorf("속도 제한이 안 걸림: %v", elapsed)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("너무 느림: %v", elapsed)
+	}
+}
+
+func TestThrottledReaderUnlimited(t *testing.T) {
+	data := bytes.Repeat([]byte("z"), 1<<20)
+	start := time.Now()
+	got, err := io.ReadAll(NewThrottledReader(bytes.NewReader(data), 0))
+	if err != nil || len(got) != len(data) {
+		t.Fatalf("ReadAll = %d 바이트, %v", len(got), err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("제한 없음인데 느림: %v", elapsed)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !linux && !darwin
+
+package streamio
+
+// 확장 속성 API 가 다른 플랫폼은 건너뜀
+func copyXattrs(src, dst string) error { return nil }
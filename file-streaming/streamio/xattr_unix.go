@@ -0,0 +1,62 @@
+//go:build linux || darwin
+
+package streamio
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs 확장 속성 복사 - 파일시스템이 지원 안 하면(ENOTSUP) 조용히 건너뜀
+func copyXattrs(src, dst string) error {
+	if portable {
+		return nil
+	}
+	size, err := unix.Listxattr(src, nil)
+	if err != nil || size == 0 {
+		return ignoreXattrErr(err)
+	}
+	names := make([]byte, size)
+	size, err = unix.Listxattr(src, names)
+	if err != nil {
+		return ignoreXattrErr(err)
+	}
+
+	// 이름 목록은 NUL 로 구분돼 있어
+	for _, name := range bytes.Split(names[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		attr := string(name)
+
+		n, err := unix.Getxattr(src, attr, nil)
+		if err != nil {
+			continue
+		}
+		value := make([]byte, n)
+		n, err = unix.Getxattr(src, attr, value)
+		if err != nil {
+			continue
+		}
+		if err := unix.Setxattr(dst, attr, value[:n], 0); err != nil {
+			// security.* 처럼 권한이 필요한 속성은 건너뛰기
+			if errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES) {
+				continue
+			}
+			if ignoreXattrErr(err) == nil {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func ignoreXattrErr(err error) error {
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+//go:build linux || darwin
+
+package streamio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCopyXattrs(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	os.WriteFile(src, []byte("x"), 0644)
+	if err := unix.Setxattr(src, "user.origin", []byte("camera-7"), 0); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) {
+			t.Skip("이 파일시스템은 user 확장 속성을 지원하지 않음")
+		}
+		t.Fatal(err)
+	}
+	if _, err := CopyFile(t.Context(), src, dst, CopyOptions{Preserve: PreserveXattrs}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	n, err := unix.Getxattr(dst, "user.origin", buf)
+	if wantCopied := !portable; wantCopied && (err != nil || string(buf[:n]) != "camera-7") {
+		t.Errorf("복사된 속성 = %q, %v", buf[:max(n, 0)], err)
+	}
+}
@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// HTTP 클라이언트 쪽 헬퍼 - step09 서버뿐 아니라 HTTP 로 파일을 주고받는 다른 기능 테스트에서도 써
+
+// Upload path 파일을 multipart 폼(field)으로 url 에 POST
+// ⭐ io.Pipe 로 폼을 만들면서 바로 보내서, 큰 픽스처도 메모리에 다 올리지 않아
+func Upload(tb testing.TB, ctx context.Context, url, field, path string) *http.Response {
+	tb.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		defer file.Close()
+		part, err := mw.CreateFormFile(field, filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		tb.Fatalf("업로드 %s: %v", path, err)
+	}
+	tb.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// Get 헤더(키, 값 쌍)를 붙여 GET - 응답 본문은 테스트가 끝나면 닫혀
+func Get(tb testing.TB, ctx context.Context, url string, header ...string) *http.Response {
+	tb.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		tb.Fatalf("GET %s: %v", url, err)
+	}
+	tb.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// ReadBody 본문 전체 (읽다 실패하면 테스트 실패)
+func ReadBody(tb testing.TB, resp *http.Response) []byte {
+	tb.Helper()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatalf("본문 읽기 실패: %v", err)
+	}
+	return data
+}
+
+// ExpectStatus 응답 코드 확인 (다르면 본문까지 보여주고 중단)
+func ExpectStatus(tb testing.TB, resp *http.Response, want int) {
+	tb.Helper()
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		tb.Fatalf("%s %s: 상태 %d, want %d (%s)", resp.Request.Method, resp.Request.URL, resp.StatusCode, want, body)
+	}
+}
@@ -0,0 +1,132 @@
+// Package testutil 은 여러 패키지의 테스트가 같이 쓰는 헬퍼야.
+// gendata 로 결정적인(같은 seed 면 같은 내용) 입력 파일을 만들고, 결과를 SHA256SUMS 형식 골든 파일과 비교해.
+//
+// ⭐ 골든 파일은 testdata/*.golden 에 두고, 의도적으로 바뀌었으면 -update 로 다시 써:
+//
+//	go test ./step09-http-streaming/server -run E2E -update
+package testutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+)
+
+// update -update 면 골든 파일을 지금 결과로 다시 써
+var update = flag.Bool("update", false, "testdata 의 골든 파일을 지금 결과로 갱신")
+
+// Fixture 테스트 입력 파일 하나 (gendata 로 만들어서 seed 가 같으면 항상 같은 내용)
+type Fixture struct {
+	Name string
+	Kind gendata.Kind
+	Size int64
+	Seed uint64
+}
+
+// WriteFixtures dir 에 픽스처 파일들을 만들고 이름 → 경로를 돌려줘
+func WriteFixtures(tb testing.TB, dir string, fixtures []Fixture) map[string]string {
+	tb.Helper()
+	paths := make(map[string]string, len(fixtures))
+	for _, f := range fixtures {
+		path := filepath.Join(dir, f.Name)
+		if err := gendata.WriteFile(path, f.Kind, f.Size, f.Seed); err != nil {
+			tb.Fatalf("픽스처 %s 생성 실패: %v", f.Name, err)
+		}
+		paths[f.Name] = path
+	}
+	return paths
+}
+
+// SHA256 바이트의 SHA-256 (16진수)
+func SHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SHA256File 파일을 스트리밍으로 읽어서 SHA-256 (16진수)
+func SHA256File(tb testing.TB, path string) string {
+	tb.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		tb.Fatalf("%s 해시 실패: %v", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Checksums 이름 → SHA-256, 골든 파일에는 sha256sum 과 같은 형식으로 저장돼
+type Checksums map[string]string
+
+// String "<해시>  <이름>" 줄들 (이름 순)
+func (c Checksums) String() string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", c[name], name)
+	}
+	return buf.String()
+}
+
+// GoldenPath testdata/<name>.golden
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// CheckGolden got 을 testdata/<name>.golden 과 비교 (다르면 어느 항목이 다른지 알려줘)
+// -update 면 비교하지 않고 골든 파일을 got 으로 써.
+func CheckGolden(tb testing.TB, name string, got Checksums) {
+	tb.Helper()
+	path := GoldenPath(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got.String()), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		tb.Logf("골든 파일 갱신: %s", path)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		tb.Fatalf("골든 파일 없음 (-update 로 만들어): %v", err)
+	}
+	defer file.Close()
+	want, err := fstree.ReadManifest(file)
+	if err != nil {
+		tb.Fatalf("골든 파일 %s: %v", path, err)
+	}
+
+	for name, sum := range got {
+		switch w, ok := want[name]; {
+		case !ok:
+			tb.Errorf("%s: 골든 파일에 없는 항목 (-update 로 추가)", name)
+		case w != sum:
+			tb.Errorf("%s: 체크섬 불일치\n got  %s\n want %s", name, sum, w)
+		}
+	}
+	for name := range want {
+		if _, ok := got[name]; !ok {
+			tb.Errorf("%s: 골든 파일에는 있는데 결과에 없음", name)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package thumb 은 이미지를 작은 썸네일로 줄이는 패키지야.
+// step09 서버가 이미지 업로드마다 small/medium 썸네일을 만들어서 /thumb 로 내보낼 때 써.
+//
+// ⭐ 표준 라이브러리만 써 - image.Decode 로 스트림을 한 번 읽고(JPEG, PNG, GIF 는 첫 프레임),
+// 영역 평균(box filter)으로 줄여서 JPEG 로 써. 줄이기만 하고 키우지는 않아.
+// 믿을 수 없는 파일이라고 가정해서, 픽셀을 풀기 전에 헤더만 읽어 가로×세로가 MaxPixels 를 넘으면 거절해 (decompression bomb).
+// 투명한 부분은 흰 바탕으로 채워 (JPEG 에는 알파가 없어).
+package thumb
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"net/http"
+
+	_ "image/gif" // image.Decode 가 GIF 를 알아보게
+	_ "image/png" // image.Decode 가 PNG 를 알아보게
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Size 썸네일 크기 하나 - 가로세로 중 긴 쪽을 Max 픽셀로
+type Size struct {
+	Name string
+	Max  int
+}
+
+var (
+	Small  = Size{Name: "small", Max: 128}
+	Medium = Size{Name: "medium", Max: 512}
+
+	// Sizes 서버가 만드는 썸네일 (작은 것부터)
+	Sizes = []Size{Small, Medium}
+)
+
+// 기본값
+const (
+	DefaultMaxPixels = 50_000_000 // 5천만 픽셀 (RGBA 로 풀면 200MB 쯤)
+	DefaultQuality   = 80
+)
+
+var (
+	// ErrNotImage JPEG, PNG, GIF 가 아니거나 깨진 이미지
+	ErrNotImage = msg.New("이미지가 아니거나 지원하지 않는 형식 (JPEG, PNG, GIF)")
+	// ErrTooLarge 가로×세로가 MaxPixels 를 넘음
+	ErrTooLarge = msg.New("이미지 픽셀 수가 한도를 넘음")
+)
+
+// Options 썸네일 옵션 (0 이면 기본값)
+type Options struct {
+	MaxPixels int // 받아 줄 원본의 가로×세로
+	Quality   int // JPEG 품질 1~100
+}
+
+func (o *Options) setDefaults() {
+	if o.MaxPixels <= 0 {
+		o.MaxPixels = DefaultMaxPixels
+	}
+	if o.Quality <= 0 || o.Quality > 100 {
+		o.Quality = DefaultQuality
+	}
+}
+
+// Supported 썸네일을 만들 수 있는 형식인지 (Content-Type 으로)
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// Sniff 앞부분 바이트로 썸네일을 만들 수 있는 형식인지 (http.DetectContentType - 512 바이트면 충분해)
+func Sniff(head []byte) bool {
+	return Supported(http.DetectContentType(head))
+}
+
+// Decode r 을 한 번 읽어서 이미지로 - 헤더의 크기가 MaxPixels 를 넘으면 픽셀은 풀지 않고 ErrTooLarge
+func Decode(r io.Reader, opt Options) (image.Image, error) {
+	opt.setDefaults()
+	// 헤더를 읽은 만큼만 따로 받아뒀다가 본문 앞에 다시 붙여 - 파일 전체를 메모리에 올리지 않아
+	var head bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &head))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > opt.MaxPixels/cfg.Height {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(io.MultiReader(&head, r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	return img, nil
+}
+
+// Fit 원본 w×h 를 long×long 안에 비율대로 넣은 크기 (이미 작으면 그대로)
+func Fit(w, h, long int) (int, int) {
+	if w <= long && h <= long {
+		return w, h
+	}
+	if w >= h {
+		return long, max(1, h*long/w)
+	}
+	return max(1, w*long/h), long
+}
+
+// Resize img 를 long×long 안으로 줄인 새 이미지 (흰 바탕에 합쳐서 불투명)
+// 원본 픽셀 하나는 정확히 대상 픽셀 하나에 들어가서, 원본을 한 줄씩 한 번만 훑어.
+func Resize(img image.Image, long int) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := Fit(sw, sh, long)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	// 원본 열 → 대상 열, 대상 열마다 들어가는 원본 열 수
+	col := make([]int, sw)
+	cols := make([]uint64, dw)
+	for sx := range sw {
+		col[sx] = sx * dw / sw
+		cols[col[sx]]++
+	}
+	row := make([]uint8, sw*4)
+	sums := make([]uint64, dw*4)
+	for dy := range dh {
+		y0, y1 := dy*sh/dh, (dy+1)*sh/dh
+		clear(sums)
+		for sy := y0; sy < y1; sy++ {
+			readRow(img, b.Min.Y+sy, row)
+			for sx, dx := range col {
+				for k := range 4 {
+					sums[dx*4+k] += uint64(row[sx*4+k])
+				}
+			}
+		}
+		out := dst.Pix[dy*dst.Stride:]
+		for dx := range dw {
+			n := cols[dx] * uint64(y1-y0)
+			a := sums[dx*4+3] / n
+			for k := range 3 {
+				// 알파를 곱해 둔 값이라 흰 바탕 위에 얹으면 c + (255 - a)
+				out[dx*4+k] = uint8(sums[dx*4+k]/n + 255 - a)
+			}
+			out[dx*4+3] = 0xff
+		}
+	}
+	return dst
+}
+
+// readRow y 줄을 알파를 곱한 RGBA 바이트로 (JPEG 의 YCbCr, RGBA, NRGBA 는 At 없이 바로)
+func readRow(img image.Image, y int, row []uint8) {
+	b := img.Bounds()
+	switch src := img.(type) {
+	case *image.RGBA:
+		i := src.PixOffset(b.Min.X, y)
+		copy(row, src.Pix[i:i+len(row)])
+	case *image.NRGBA:
+		i := src.PixOffset(b.Min.X, y)
+		for x := 0; x < len(row); x += 4 {
+			p := src.Pix[i+x : i+x+4]
+			a := uint16(p[3])
+			row[x], row[x+1], row[x+2], row[x+3] = uint8(uint16(p[0])*a/255), uint8(uint16(p[1])*a/255), uint8(uint16(p[2])*a/255), p[3]
+		}
+	case *image.YCbCr:
+		for x := range b.Dx() {
+			yi, ci := src.YOffset(b.Min.X+x, y), src.COffset(b.Min.X+x, y)
+			row[x*4], row[x*4+1], row[x*4+2] = color.YCbCrToRGB(src.Y[yi], src.Cb[ci], src.Cr[ci])
+			row[x*4+3] = 0xff
+		}
+	default:
+		for x := range b.Dx() {
+			r, g, bl, a := img.At(b.Min.X+x, y).RGBA()
+			row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = uint8(r>>8), uint8(g>>8), uint8(bl>>8), uint8(a>>8)
+		}
+	}
+}
+
+// Encode 썸네일을 JPEG 로
+func Encode(w io.Writer, img image.Image, opt Options) error {
+	opt.setDefaults()
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: opt.Quality})
+}
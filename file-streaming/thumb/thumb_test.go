@@ -0,0 +1,106 @@
+package thumb
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	for _, c := range []struct{ w, h, long, ww, wh int }{
+		{1000, 500, 128, 128, 64},
+		{500, 1000, 128, 64, 128},
+		{100, 50, 128, 100, 50}, // 키우지 않아
+		{10000, 1, 128, 128, 1}, // 아주 길어도 1 픽셀은 남아
+	} {
+		if w, h := Fit(c.w, c.h, c.long); w != c.ww || h != c.wh {
+			t.Errorf("Fit(%d, %d, %d) = %d×%d, want %d×%d", c.w, c.h, c.long, w, h, c.ww, c.wh)
+		}
+	}
+}
+
+// 왼쪽 절반은 빨강, 오른쪽 절반은 투명 - 줄이면 빨강과 흰 바탕이 그대로 남아야 해
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := range 200 {
+		for x := range 200 {
+			src.Set(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+	img, err := Decode(bytes.NewReader(encodePNG(t, src)), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Resize(img, 128)
+	if b := got.Bounds(); b.Dx() != 128 || b.Dy() != 64 {
+		t.Fatalf("크기 = %v, want 128×64", b)
+	}
+	if c := got.RGBAAt(10, 10); c != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("왼쪽 = %v, want 빨강", c)
+	}
+	if c := got.RGBAAt(120, 50); c != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("오른쪽 = %v, want 흰 바탕", c)
+	}
+
+	var out bytes.Buffer
+	if err := Encode(&out, got, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := jpeg.DecodeConfig(&out); err != nil || cfg.Width != 128 || cfg.Height != 64 {
+		t.Errorf("JPEG = %+v (err %v)", cfg, err)
+	}
+}
+
+// JPEG 은 YCbCr 로 풀려서 따로 읽어
+func TestResizeJPEG(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	for i := range src.Pix {
+		src.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if !Sniff(buf.Bytes()) {
+		t.Fatal("JPEG 을 못 알아봄")
+	}
+	img, err := Decode(&buf, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := img.(*image.YCbCr); !ok {
+		t.Fatalf("%T, want *image.YCbCr", img)
+	}
+	c := Resize(img, 100).RGBAAt(50, 50)
+	if d := int(c.R) - 0x80; d < -2 || d > 2 || c.A != 0xff {
+		t.Errorf("가운데 = %v, want 회색 (0x80)", c)
+	}
+}
+
+func TestDecodeReject(t *testing.T) {
+	if _, err := Decode(strings.NewReader("그냥 텍스트"), Options{}); !errors.Is(err, ErrNotImage) {
+		t.Errorf("텍스트: err = %v, want ErrNotImage", err)
+	}
+	// 헤더만 보고 거절 - 픽셀은 풀지 않아
+	big := encodePNG(t, image.NewGray(image.Rect(0, 0, 2000, 2000)))
+	if _, err := Decode(bytes.NewReader(big), Options{MaxPixels: 1000 * 1000}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("큰 이미지: err = %v, want ErrTooLarge", err)
+	}
+	if Sniff([]byte("%PDF-1.7")) {
+		t.Error("PDF 를 이미지로 봄")
+	}
+}
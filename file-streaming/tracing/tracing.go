@@ -0,0 +1,87 @@
+// Package tracing 은 OpenTelemetry 트레이스를 어디로 보낼지 설정해.
+// 스팬을 만드는 쪽(streamio, s3, server)은 OTel API 만 쓰고, 앱이 시작할 때 Setup 을 한 번 부르면 돼.
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Setup target 으로 트레이스를 보내도록 전역 TracerProvider 설정 - 돌려준 shutdown 을 끝날 때 꼭 불러 (남은 스팬을 보내)
+//
+//	""                      트레이싱 끔 (no-op)
+//	"stderr"                스팬을 JSON 으로 stderr 에 (collector 없이 확인할 때)
+//	"grpc://localhost:4317" OTLP/gRPC (Jaeger, Tempo, OTel Collector 기본 포트)
+//	"http://localhost:4318" OTLP/HTTP
+//	"https://..."           OTLP/HTTP + TLS
+func Setup(ctx context.Context, target, service string) (shutdown func(context.Context) error, err error) {
+	if target == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := newExporter(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", service),
+		attribute.String("host.name", host),
+	))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	// 다른 서비스와 trace ID 를 이어 붙이려고 W3C traceparent 헤더를 주고받아
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		return tp.Shutdown(ctx)
+	}, nil
+}
+
+func newExporter(ctx context.Context, target string) (sdktrace.SpanExporter, error) {
+	if target == "stderr" {
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return nil, msg.Errorf("잘못된 트레이스 대상: %q (stderr, grpc://호스트:4317, http://호스트:4318)", target)
+	}
+	switch u.Scheme {
+	case "grpc":
+		return otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(u.Host), otlptracegrpc.WithInsecure())
+	case "http":
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(u.Host), otlptracehttp.WithInsecure(), urlPath(u))
+	case "https":
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(u.Host), urlPath(u))
+	default:
+		return nil, msg.Errorf("지원하지 않는 트레이스 프로토콜: %q (grpc, http, https)", u.Scheme)
+	}
+}
+
+// urlPath 경로를 주면 그대로 (기본 /v1/traces)
+func urlPath(u *url.URL) otlptracehttp.Option {
+	if u.Path == "" || u.Path == "/" {
+		return otlptracehttp.WithURLPath("/v1/traces")
+	}
+	return otlptracehttp.WithURLPath(u.Path)
+}
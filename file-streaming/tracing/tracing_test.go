@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetupOff(t *testing.T) {
+	before := otel.GetTracerProvider()
+	shutdown, err := Setup(t.Context(), "", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(t.Context()); err != nil {
+		t.Errorf("shutdown = %v", err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Error("대상이 비었는데 TracerProvider 를 바꿈")
+	}
+}
+
+// exporter 는 만들 때 연결하지 않아서 collector 없이도 만들어져
+func TestNewExporter(t *testing.T) {
+	for _, target := range []string{"stderr", "grpc://localhost:4317", "http://localhost:4318", "https://otel.example.com/custom/path"} {
+		exp, err := newExporter(t.Context(), target)
+		if err != nil {
+			t.Errorf("%s: %v", target, err)
+			continue
+		}
+		exp.Shutdown(t.Context())
+	}
+	for _, target := range []string{"localhost:4317", "ftp://localhost", "grpc://", "::"} {
+		if _, err := newExporter(t.Context(), target); err == nil {
+			t.Errorf("%s: 잘못된 대상인데 에러가 없음", target)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	old := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	shutdown, err := Setup(t.Context(), "http://localhost:4318", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() == old {
+		t.Error("TracerProvider 가 그대로")
+	}
+	if fields := otel.GetTextMapPropagator().Fields(); len(fields) == 0 {
+		t.Error("traceparent 전파가 설정되지 않음")
+	}
+	// 보낼 스팬이 없으면 collector 없이도 바로 끝나
+	if err := shutdown(t.Context()); err != nil {
+		t.Errorf("shutdown = %v", err)
+	}
+}
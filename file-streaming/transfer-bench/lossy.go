@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 손실/지연이 있는 링크 흉내
+//
+// UDP(QUIC) 는 패킷을 실제로 버려. QUIC 이 직접 유실을 감지하고 재전송해.
+// TCP 는 커널이 재전송을 하니까 사용자 공간에서 패킷을 버릴 수가 없어서,
+// 대신 "유실된 세그먼트는 재전송만큼(1 RTT) 늦게 도착하고, 그 뒤 세그먼트들도 순서대로 기다린다"로 흉내내.
+// 이게 TCP 에서 유실이 비싼 진짜 이유(head-of-line blocking)야.
+// 혼잡 창이 줄어드는 효과는 빠져 있으니, 실제 NIC 수준으로 재려면 tc netem 을 쓰는 게 정확해.
+
+// linkProfile 한 방향 링크 특성
+type linkProfile struct {
+	Delay time.Duration // 편도 지연
+	Loss  float64       // 패킷(세그먼트) 손실 확률 0~1
+	Rate  int64         // 대역폭 (초당 바이트, 0 이면 제한 없음) - 두 방식이 같은 병목을 지나게
+}
+
+// pacer 대역폭 제한 - 패킷이 링크를 떠나는 시각을 앞 패킷 뒤로 밀어
+// 프록시 하나의 연결들이 같이 써서, 연결을 여러 개 열어도(HTTP/1.1 동시 다운로드) 병목은 하나야.
+type pacer struct {
+	mu   sync.Mutex
+	rate int64
+	next time.Time
+}
+
+// depart n 바이트가 링크를 다 떠나는 시각
+func (p *pacer) depart(now time.Time, n int) time.Time {
+	if p.rate <= 0 {
+		return now
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	p.next = p.next.Add(time.Duration(int64(n) * int64(time.Second) / p.rate))
+	return p.next
+}
+
+// retransmitDelay 유실된 TCP 세그먼트가 빠른 재전송으로 다시 오기까지 더 걸리는 시간 (중복 ACK → 재전송 = 1 RTT)
+func (l linkProfile) retransmitDelay() time.Duration {
+	return 2 * l.Delay
+}
+
+const segmentSize = 1400 // 대략 MTU 에서 헤더를 뺀 크기
+
+// delayedPacket 도착 예정 시각이 붙은 데이터
+type delayedPacket struct {
+	at   time.Time
+	data []byte
+}
+
+// startTCPProxy listen 주소를 돌려주고, 들어온 연결을 target 으로 중계해
+func startTCPProxy(ctx context.Context, target string, link linkProfile) (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	context.AfterFunc(ctx, func() { ln.Close() })
+	up, down := &pacer{rate: link.Rate}, &pacer{rate: link.Rate}
+
+	go func() {
+		for {
+			client, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				server, err := net.Dial("tcp", target)
+				if err != nil {
+					client.Close()
+					return
+				}
+				var wg sync.WaitGroup
+				wg.Add(2)
+				go func() { defer wg.Done(); pipeTCP(server, client, link, up) }()
+				go func() { defer wg.Done(); pipeTCP(client, server, link, down) }()
+				wg.Wait()
+				client.Close()
+				server.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String(), nil
+}
+
+// pipeTCP src → dst 를 세그먼트 단위로 늦춰서 전달 (순서는 유지 = 유실 하나가 뒤를 다 막아)
+func pipeTCP(dst, src net.Conn, link linkProfile, pace *pacer) {
+	// 큐 크기가 사실상 수신 창 역할 (1400 × 2048 ≈ 2.8MB)
+	queue := make(chan delayedPacket, 2048)
+
+	go func() {
+		defer close(queue)
+		var last time.Time
+		for {
+			buf := make([]byte, segmentSize)
+			n, err := src.Read(buf)
+			if n > 0 {
+				at := pace.depart(time.Now(), n).Add(link.Delay)
+				if at.Before(last) {
+					at = last
+				}
+				if rand.Float64() < link.Loss {
+					at = at.Add(link.retransmitDelay())
+				}
+				last = at
+				queue <- delayedPacket{at: at, data: buf[:n]}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for p := range queue {
+		time.Sleep(time.Until(p.at))
+		if _, err := dst.Write(p.data); err != nil {
+			// 남은 큐를 비워야 읽는 쪽 고루틴이 막히지 않아
+			for range queue {
+			}
+			break
+		}
+	}
+	// 반대 방향은 계속 쓸 수 있게 쓰기 쪽만 닫아 (HTTP 응답 등)
+	if tcp, ok := dst.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	}
+}
+
+// startUDPProxy 클라이언트 하나(주소가 바뀌어도 마지막 주소)를 target 과 중계하면서 패킷을 버리고 늦춰
+func startUDPProxy(ctx context.Context, target string, link linkProfile) (string, error) {
+	targetAddr, err := net.ResolveUDPAddr("udp", target)
+	if err != nil {
+		return "", err
+	}
+	front, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return "", err
+	}
+	back, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		front.Close()
+		return "", err
+	}
+	context.AfterFunc(ctx, func() {
+		front.Close()
+		back.Close()
+	})
+
+	var mu sync.Mutex
+	var client *net.UDPAddr
+
+	// 클라이언트 → 서버
+	go pipeUDP(front, link, func(addr *net.UDPAddr) {
+		mu.Lock()
+		client = addr
+		mu.Unlock()
+	}, func(p []byte) { back.WriteToUDP(p, targetAddr) })
+
+	// 서버 → 클라이언트
+	go pipeUDP(back, link, nil, func(p []byte) {
+		mu.Lock()
+		to := client
+		mu.Unlock()
+		if to != nil {
+			front.WriteToUDP(p, to)
+		}
+	})
+
+	return front.LocalAddr().String(), nil
+}
+
+// pipeUDP 받은 패킷을 확률적으로 버리고, 남은 건 Delay 뒤에 도착 순서대로 보내
+// ⭐ 패킷마다 타이머를 따로 걸면 순서가 뒤섞여서 QUIC 이 재정렬을 유실로 오해해 (혼잡 창이 괜히 줄어)
+func pipeUDP(conn *net.UDPConn, link linkProfile, onRecv func(*net.UDPAddr), send func([]byte)) {
+	// 병목 큐 - 대역폭보다 빨리 보내면 여기가 차고, 넘치면 라우터처럼 버려 (tail drop)
+	const maxQueued = 256
+	var queued atomic.Int64
+	pace := &pacer{rate: link.Rate}
+
+	queue := make(chan delayedPacket, 4096)
+	go func() {
+		for p := range queue {
+			time.Sleep(time.Until(p.at))
+			queued.Add(-1)
+			send(p.data)
+		}
+	}()
+	defer close(queue)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if onRecv != nil {
+			onRecv(addr)
+		}
+		if rand.Float64() < link.Loss {
+			continue
+		}
+		now := time.Now()
+		if link.Rate > 0 && queued.Load() >= maxQueued {
+			continue
+		}
+		queued.Add(1)
+		p := delayedPacket{at: pace.depart(now, n).Add(link.Delay), data: append([]byte(nil), buf[:n]...)}
+		select {
+		case queue <- p:
+		default:
+			queued.Add(-1)
+		}
+	}
+}
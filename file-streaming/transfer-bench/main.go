@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/gendata"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/step09-http-streaming/server"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/transfer"
+)
+
+// 손실이 있는 링크에서 TCP / HTTP / QUIC 전송 비교
+//
+//	go run ./transfer-bench -size 32MB -delay 20ms -loss 0,0.01,0.03
+//	go run ./transfer-bench -modes h1,h2 -conc 1,4,16 -size 16MB   # HTTP/1.1 대 HTTP/2 다운로드
+//
+// 세 서버(transfer TCP, step09 HTTP, transfer QUIC)를 로컬에 띄우고
+// 그 앞에 손실/지연을 흉내내는 프록시를 끼워서 같은 파일을 보내 (자세한 흉내 방식은 lossy.go).
+// h1/h2 는 보내는 대신 TLS 로 띄운 step09 에서 같은 파일을 동시에 여러 번 받아 (proto.go).
+func main() {
+	// 플래그 설명과 출력도 FS_LANG / -lang 을 따라가게 (플래그를 등록하기 전에)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	locale.RegisterFlags(flag.CommandLine)
+	size := flag.String("size", "32MB", msg.T("보낼 파일 크기"))
+	delay := flag.Duration("delay", 20*time.Millisecond, msg.T("편도 지연 (RTT 는 두 배)"))
+	bandwidth := flag.String("bw", "10MB", msg.T("링크 대역폭 (초당, 0 이면 제한 없음)"))
+	losses := flag.String("loss", "0,0.01,0.03", msg.T("손실률 목록 (쉼표 구분, 0~1)"))
+	streams := flag.Int("streams", 4, msg.T("QUIC 병렬 스트림 수"))
+	modes := flag.String("modes", "tcp,http,quic", msg.T("비교할 방식 (쉼표 구분, tcp/http/quic/h1/h2)"))
+	conc := flag.String("conc", "1,4,16", msg.T("h1/h2 동시 다운로드 수 목록 (쉼표 구분)"))
+	timeout := flag.Duration("timeout", 5*time.Minute, msg.T("전송 하나의 최대 시간"))
+	jsonOut := flag.Bool("json", false, msg.T("결과를 줄 단위 JSON 으로"))
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, benchConfig{
+		size: *size, delay: *delay, bandwidth: *bandwidth, losses: *losses, streams: *streams,
+		modes: strings.Split(*modes, ","), conc: *conc, timeout: *timeout, json: *jsonOut,
+	}); err != nil {
+		logging.Fatal("벤치마크 실패", "err", err)
+	}
+}
+
+type benchConfig struct {
+	size      string
+	delay     time.Duration
+	bandwidth string
+	losses    string
+	streams   int
+	modes     []string
+	conc      string
+	timeout   time.Duration
+	json      bool
+}
+
+// benchResult 측정 한 번
+type benchResult struct {
+	Mode     string  `json:"mode"`
+	Loss     float64 `json:"loss"`
+	DelayMS  int64   `json:"delay_ms"`
+	Bytes    int64   `json:"bytes"` // h1/h2 는 받은 바이트 전체 (파일 크기 × 동시 다운로드 수)
+	Seconds  float64 `json:"seconds"`
+	MBPerSec float64 `json:"mb_per_sec"`
+	Error    string  `json:"error,omitempty"`
+
+	// h1/h2 만
+	Concurrency int    `json:"concurrency,omitempty"`
+	Proto       string `json:"proto,omitempty"` // 실제로 협상된 프로토콜 (HTTP/1.1, HTTP/2.0)
+}
+
+// label 표에 찍을 방식 이름 (h2×4 처럼 동시 다운로드 수를 붙여)
+func (r benchResult) label() string {
+	if r.Concurrency > 0 {
+		return fmt.Sprintf("%s×%d", r.Mode, r.Concurrency)
+	}
+	return r.Mode
+}
+
+// endpoints 로컬 서버들의 실제 주소
+type endpoints struct {
+	tcp, http, quic string
+	https           string // 같은 step09 서버의 TLS 리스너 (h1/h2)
+	fingerprint     string
+	recvDir         string
+	uploadDir       string
+}
+
+func run(ctx context.Context, cfg benchConfig) error {
+	n, err := gendata.ParseSize(cfg.size)
+	if err != nil {
+		return err
+	}
+	rate, err := gendata.ParseSize(cfg.bandwidth)
+	if err != nil {
+		return err
+	}
+	var lossList []float64
+	for _, s := range strings.Split(cfg.losses, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || v < 0 || v >= 1 {
+			return msg.Errorf("잘못된 손실률: %q", s)
+		}
+		lossList = append(lossList, v)
+	}
+	concList, err := parseConcurrency(cfg.conc)
+	if err != nil {
+		return err
+	}
+
+	work, err := os.MkdirTemp("", "transfer-bench-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	// 압축/중복 제거 효과가 섞이지 않게 랜덤 데이터
+	src := filepath.Join(work, "payload.bin")
+	if err := gendata.WriteFile(src, gendata.KindRandom, n, 1); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ep, err := startServers(ctx, work)
+	if err != nil {
+		return err
+	}
+
+	// h1/h2 는 서버에 있는 파일을 받아가니까 업로드 디렉토리에 미리 넣어 둬 (같은 임시 디렉토리라 하드 링크)
+	var want string
+	if slices.ContainsFunc(cfg.modes, protoMode) {
+		if err := os.Link(src, filepath.Join(ep.uploadDir, downloadName)); err != nil {
+			return err
+		}
+		if want, err = streamio.FileSHA256(src); err != nil {
+			return err
+		}
+	}
+
+	if !cfg.json {
+		msg.Printf("파일 %s, 편도 지연 %v, 대역폭 %s/s, QUIC 스트림 %d개\n", cfg.size, cfg.delay, cfg.bandwidth, cfg.streams)
+		fmt.Print(msg.T("(TCP 손실은 재전송 지연으로만 흉내내서 혼잡 창 감소가 빠져 있어 - 실제보다 TCP 에 유리해)\n\n"))
+		fmt.Printf("%-6s %-6s %10s %10s\n", msg.T("손실률"), msg.T("방식"), msg.T("시간"), "MB/s")
+	}
+
+	for _, loss := range lossList {
+		link := linkProfile{Delay: cfg.delay, Loss: loss, Rate: rate}
+		for i, mode := range cfg.modes {
+			// 업로드 방식은 한 번, h1/h2 는 동시 다운로드 수마다
+			concs := []int{0}
+			if protoMode(mode) {
+				concs = concList
+			}
+			for _, c := range concs {
+				name := fmt.Sprintf("bench-%s-%g-%d.bin", mode, loss, i)
+				res := benchResult{Mode: mode, Loss: loss, DelayMS: cfg.delay.Milliseconds(), Bytes: n, Concurrency: c}
+
+				runCtx, runCancel := context.WithTimeout(ctx, cfg.timeout)
+				var elapsed time.Duration
+				if c > 0 {
+					res.Bytes = n * int64(c)
+					elapsed, res.Proto, err = benchDownload(runCtx, mode, c, link, ep, want)
+				} else {
+					elapsed, err = benchOne(runCtx, mode, src, name, link, ep, cfg)
+				}
+				runCancel()
+
+				res.Seconds = elapsed.Seconds()
+				if err != nil {
+					res.Error = err.Error()
+				} else if res.Seconds > 0 {
+					res.MBPerSec = float64(res.Bytes) / res.Seconds / (1 << 20)
+				}
+				printResult(res, cfg.json)
+
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func printResult(r benchResult, asJSON bool) {
+	if asJSON {
+		json.NewEncoder(os.Stdout).Encode(r)
+		return
+	}
+	if r.Error != "" {
+		msg.Printf("%-6s %-6s %10s  실패: %s\n", fmt.Sprintf("%.1f%%", r.Loss*100), r.label(), "-", r.Error)
+		return
+	}
+	fmt.Printf("%-6s %-6s %9.2fs %10.2f\n", fmt.Sprintf("%.1f%%", r.Loss*100), r.label(), r.Seconds, r.MBPerSec)
+}
+
+// startServers transfer(TCP/QUIC) 와 step09 HTTP 서버를 임의 포트로 띄우기
+func startServers(ctx context.Context, work string) (endpoints, error) {
+	ep := endpoints{recvDir: filepath.Join(work, "received"), uploadDir: filepath.Join(work, "uploads")}
+	// 벤치마크 결과 표에 섞이지 않게 서버 쪽 로그는 버려
+	quiet := slog.New(slog.DiscardHandler)
+	recv := &transfer.Server{Dir: ep.recvDir, Logger: quiet}
+
+	tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return ep, err
+	}
+	ep.tcp = tcpLn.Addr().String()
+	go recv.Serve(ctx, tcpLn)
+
+	tlsConf, fingerprint, err := transfer.SelfSignedTLS()
+	if err != nil {
+		return ep, err
+	}
+	quicLn, err := recv.ListenQUIC("127.0.0.1:0", tlsConf)
+	if err != nil {
+		return ep, err
+	}
+	ep.quic, ep.fingerprint = quicLn.Addr().String(), fingerprint
+	go recv.ServeQUIC(ctx, quicLn)
+
+	httpSrv, err := server.New(server.Config{UploadDir: ep.uploadDir, TrashDir: filepath.Join(work, "trash"), SessionDir: filepath.Join(work, "sessions"), Logger: quiet})
+	if err != nil {
+		return ep, err
+	}
+	httpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return ep, err
+	}
+	ep.http = httpLn.Addr().String()
+	hs := &http.Server{Handler: httpSrv.Handler(), ErrorLog: log.New(io.Discard, "", 0)}
+	go hs.Serve(httpLn)
+	context.AfterFunc(ctx, func() { hs.Close() })
+
+	// 같은 핸들러를 TLS 로도 - ServeTLS 라 ALPN 으로 h2 / http/1.1 을 고를 수 있어 (인증서는 QUIC 과 같은 걸 써서 지문도 같아)
+	httpsLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return ep, err
+	}
+	ep.https = httpsLn.Addr().String()
+	hss := &http.Server{
+		Handler:   httpSrv.Handler(),
+		TLSConfig: &tls.Config{Certificates: tlsConf.Certificates},
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go hss.ServeTLS(httpsLn, "", "")
+	context.AfterFunc(ctx, func() { hss.Close() })
+
+	return ep, nil
+}
+
+// benchOne 프록시를 새로 끼워서 한 번 전송하고 걸린 시간 (받은 파일 해시까지 확인)
+func benchOne(ctx context.Context, mode, src, name string, link linkProfile, ep endpoints, cfg benchConfig) (time.Duration, error) {
+	proxyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var received string
+	var send func() error
+
+	switch mode {
+	case "tcp":
+		addr, err := startTCPProxy(proxyCtx, ep.tcp, link)
+		if err != nil {
+			return 0, err
+		}
+		received = filepath.Join(ep.recvDir, name)
+		send = func() error {
+			_, err := transfer.Send(ctx, addr, src, transfer.SendOptions{Name: name})
+			return err
+		}
+
+	case "quic":
+		addr, err := startUDPProxy(proxyCtx, ep.quic, link)
+		if err != nil {
+			return 0, err
+		}
+		received = filepath.Join(ep.recvDir, name)
+		send = func() error {
+			_, err := transfer.Send(ctx, addr, src, transfer.SendOptions{
+				Name:      name,
+				Transport: transfer.TransportQUIC,
+				Streams:   cfg.streams,
+				TLS:       transfer.PinnedTLS(ep.fingerprint),
+			})
+			return err
+		}
+
+	case "http":
+		addr, err := startTCPProxy(proxyCtx, ep.http, link)
+		if err != nil {
+			return 0, err
+		}
+		received = filepath.Join(ep.uploadDir, name)
+		send = func() error { return uploadHTTP(ctx, "http://"+addr+"/upload", src, name) }
+
+	default:
+		return 0, msg.Errorf("알 수 없는 방식: %s", mode)
+	}
+
+	start := time.Now()
+	if err := send(); err != nil {
+		return time.Since(start), err
+	}
+	elapsed := time.Since(start)
+
+	// 제대로 받았는지 확인 (측정 시간에는 안 넣어)
+	want, err := streamio.FileSHA256(src)
+	if err != nil {
+		return elapsed, err
+	}
+	got, err := streamio.FileSHA256(received)
+	if err != nil {
+		return elapsed, err
+	}
+	if got != want {
+		return elapsed, &streamio.ChecksumError{Path: received, Expected: want, Actual: got}
+	}
+	os.Remove(received)
+	return elapsed, nil
+}
+
+// uploadHTTP step09 /upload 로 멀티파트 스트리밍 업로드 (ingest 의 upload 액션과 같은 방식)
+func uploadHTTP(ctx context.Context, url, path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", name)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, file); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+	if err != nil {
+		pr.Close()
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return msg.Errorf("업로드 실패: %s", resp.Status)
+	}
+	return nil
+}
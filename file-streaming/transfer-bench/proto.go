@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+	"github.com/hellotect2022go/study-go/file-streaming/transfer"
+)
+
+// HTTP/1.1 대 HTTP/2 다운로드 비교 (-modes h1,h2 -conc 1,4,16)
+//
+// step09 서버를 TLS 로 하나 더 띄우고 (ServeTLS 라 h2 가 켜져 있어) 같은 파일을 동시에 c 번 받아.
+//   - h1: 요청마다 TCP 연결을 따로 열어 - 연결 c 개가 병목을 나눠 써
+//   - h2: 연결 하나에 스트림 c 개를 섞어 보내 - 세그먼트 하나가 늦으면 그 뒤의 모든 스트림이 같이 기다려 (TCP head-of-line blocking)
+//
+// 대역폭 병목은 프록시 하나를 모든 연결이 같이 지나서(lossy.go 의 pacer) 두 방식이 같은 링크를 나눠 써.
+// 손실이 없으면 둘이 비슷하고, 손실이 커질수록 연결이 여러 개인 h1 이 덜 막혀.
+
+// downloadName 다운로드 비교에 쓰는 파일 (업로드 디렉토리에 미리 넣어 둬)
+const downloadName = "bench-download.bin"
+
+// protoMode h1/h2 처럼 다운로드를 동시에 여러 번 하는 방식인지
+func protoMode(mode string) bool {
+	return mode == "h1" || mode == "h2"
+}
+
+// parseConcurrency "1,4,16" → []int{1, 4, 16}
+func parseConcurrency(s string) ([]int, error) {
+	var list []int
+	for _, v := range strings.Split(s, ",") {
+		c, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || c < 1 {
+			return nil, msg.Errorf("잘못된 동시 다운로드 수: %q", v)
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
+
+// protoClient mode 로만 말하는 클라이언트 (서버 인증서는 지문으로 확인)
+// 매번 새로 만들어서 앞 측정의 연결을 재사용하지 않아.
+func protoClient(mode, fingerprint string) *http.Client {
+	conf := transfer.PinnedTLS(fingerprint)
+	conf.NextProtos = nil // transfer 의 ALPN 대신 Transport 가 h2 / http/1.1 을 채워
+	tr := &http.Transport{TLSClientConfig: conf, MaxIdleConnsPerHost: -1}
+	if mode == "h2" {
+		// TLSClientConfig 를 직접 주면 h2 가 꺼져서 다시 켜야 해
+		tr.ForceAttemptHTTP2 = true
+	} else {
+		// 비어 있는 맵이면 ALPN 에 h2 를 넣지 않아
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return &http.Client{Transport: tr}
+}
+
+// benchDownload 프록시를 새로 끼워서 downloadName 을 동시에 c 번 받고 걸린 시간과 협상된 프로토콜 (받은 내용은 해시로 확인)
+func benchDownload(ctx context.Context, mode string, c int, link linkProfile, ep endpoints, want string) (time.Duration, string, error) {
+	proxyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	addr, err := startTCPProxy(proxyCtx, ep.https, link)
+	if err != nil {
+		return 0, "", err
+	}
+	client := protoClient(mode, ep.fingerprint)
+	defer client.CloseIdleConnections()
+	target := "https://" + addr + "/download?file=" + url.QueryEscape(downloadName)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		proto string
+		first error
+	)
+	start := time.Now()
+	for range c {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := downloadHTTP(ctx, client, mode, target, want)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && first == nil {
+				first = err
+				cancel()
+			}
+			proto = got
+		}()
+	}
+	wg.Wait()
+	return time.Since(start), proto, first
+}
+
+// downloadHTTP target 을 끝까지 받아서 sha256 을 확인하고 협상된 프로토콜을 돌려줘
+func downloadHTTP(ctx context.Context, client *http.Client, mode, target, want string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.Proto, msg.Errorf("다운로드 실패: %s", resp.Status)
+	}
+	// 다른 프로토콜로 내려가면 비교가 의미 없어
+	if wantMajor := map[string]int{"h1": 1, "h2": 2}[mode]; resp.ProtoMajor != wantMajor {
+		return resp.Proto, msg.Errorf("%s 로 협상됐습니다 (%s 모드)", resp.Proto, mode)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return resp.Proto, err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return resp.Proto, &streamio.ChecksumError{Path: target, Expected: want, Actual: got}
+	}
+	return resp.Proto, nil
+}
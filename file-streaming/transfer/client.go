@@ -0,0 +1,262 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Transport 전송 방식
+type Transport string
+
+const (
+	TransportTCP  Transport = "tcp"  // TCP 연결 하나로 순서대로 (기본)
+	TransportQUIC Transport = "quic" // QUIC 연결 하나에 병렬 스트림 여러 개
+)
+
+// String flag.Value 구현
+func (t *Transport) String() string {
+	if t == nil || *t == "" {
+		return string(TransportTCP)
+	}
+	return string(*t)
+}
+
+// Set flag.Value 구현
+func (t *Transport) Set(s string) error {
+	switch Transport(s) {
+	case TransportTCP, TransportQUIC:
+		*t = Transport(s)
+		return nil
+	}
+	return msg.Errorf("알 수 없는 전송 방식: %q (tcp|quic)", s)
+}
+
+// SendOptions 전송 옵션
+type SendOptions struct {
+	Transport Transport      // 기본 TransportTCP
+	Name      string         // 서버에 저장할 이름 (기본: 파일 이름)
+	ChunkSize int            // data 프레임 하나의 크기 (기본 DefaultChunkSize)
+	RateLimit int64          // 초당 최대 바이트 (0 이면 제한 없음)
+	Hooks     streamio.Hooks // nil 이면 훅 호출 안 함
+
+	// Retries 연결이 끊기거나 서버가 거부하면 다시 접속하는 횟수
+	// 서버가 받아둔 곳부터 이어서 보내니까 재시도해도 처음부터 다시 보내지 않아
+	Retries    int
+	RetryDelay time.Duration
+	Timeout    time.Duration // 접속/응답 대기 시간 (기본 30초)
+
+	// QUIC 모드 전용
+	Streams      int           // 파일 하나를 나눠 보낼 병렬 스트림 수 (기본 4)
+	TLS          *tls.Config   // 서버 인증서 검증 설정 (자체 서명 서버면 PinnedTLS)
+	MigrateAfter time.Duration // 0 보다 크면 이만큼 뒤에 새 UDP 소켓으로 경로를 옮겨 (연결 이동 실습용)
+}
+
+func (o SendOptions) streams() int {
+	if o.Streams <= 0 {
+		return 4
+	}
+	return o.Streams
+}
+
+func (o SendOptions) chunkSize() int {
+	if o.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return min(o.ChunkSize, MaxFrameSize-dataHeaderSize)
+}
+
+func (o SendOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return 30 * time.Second
+	}
+	return o.Timeout
+}
+
+// SendResult 전송 결과
+type SendResult struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	Sent     int64  `json:"sent"`    // 모든 시도에서 실제로 보낸 바이트 (이어받기로 건너뛴 건 빼고)
+	Resumed  int64  `json:"resumed"` // 마지막 시도에서 서버가 이미 갖고 있던 바이트
+	Attempts int    `json:"attempts"`
+	Migrated bool   `json:"migrated,omitempty"` // QUIC 경로 이동에 성공했는지
+}
+
+// Send path 를 addr 의 Server 로 전송
+// ⭐ 해시는 보내기 전에 한 번 계산해서 offer 에 실어 보내 - 서버는 이 값으로
+// 이어받아도 되는 파일인지 판단하고, 마지막에 전체 다이제스트를 검증해.
+func Send(ctx context.Context, addr, path string, opts SendOptions) (SendResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return SendResult{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return SendResult{}, msg.Errorf("일반 파일만 보낼 수 있어: %s", path)
+	}
+	sum, err := streamio.FileSHA256(path)
+	if err != nil {
+		return SendResult{}, msg.Errorf("해시 계산 실패: %w", err)
+	}
+
+	offer := Offer{Name: opts.Name, Size: info.Size(), SHA256: sum}
+	if offer.Name == "" {
+		offer.Name = filepath.Base(path)
+	}
+	result := SendResult{Name: offer.Name, Size: offer.Size, SHA256: sum}
+
+	hooks := opts.Hooks
+	if hooks == nil {
+		hooks = streamio.NopHooks{}
+	}
+	tinfo := streamio.TransferInfo{ID: offer.Name, Src: path, Dst: addr, Size: offer.Size}
+	hooks.OnStart(tinfo)
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		result.Attempts++
+		if opts.Transport == TransportQUIC {
+			err = sendQUIC(ctx, addr, path, offer, opts, hooks, tinfo, &result)
+		} else {
+			err = sendOnce(ctx, addr, path, offer, opts, hooks, tinfo, &result)
+		}
+		if err == nil || attempt >= opts.Retries || ctx.Err() != nil {
+			break
+		}
+
+		hooks.OnRetry(tinfo, attempt+1, err)
+		select {
+		case <-time.After(opts.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+		}
+	}
+
+	if err != nil {
+		hooks.OnError(tinfo, err)
+		return result, err
+	}
+	hooks.OnComplete(tinfo, result.Sent, time.Since(start))
+	return result, nil
+}
+
+func sendOnce(ctx context.Context, addr, path string, offer Offer, opts SendOptions, hooks streamio.Hooks, tinfo streamio.TransferInfo, result *SendResult) (err error) {
+	dialer := net.Dialer{Timeout: opts.timeout()}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// 취소되면 연결을 끊어서 막혀 있는 Read/Write 를 깨워
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer func() {
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			err = ctxErr
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriterSize(conn, opts.chunkSize()+frameHeaderSize+dataHeaderSize)
+
+	if err := writeJSON(w, msgOffer, offer); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(opts.timeout()))
+	var accept Accept
+	if err := readJSON(r, msgAccept, &accept); err != nil {
+		return msg.Errorf("accept 읽기 실패: %w", err)
+	}
+	conn.SetReadDeadline(time.Time{})
+	if accept.Offset < 0 || accept.Offset > offer.Size {
+		return msg.Errorf("서버가 잘못된 오프셋을 보냄: %d", accept.Offset)
+	}
+	result.Resumed = accept.Offset
+
+	position := accept.Offset
+	if err := sendChunks(w, path, accept.Offset, opts, func(n int64) {
+		position += n
+		result.Sent += n
+		hooks.OnProgress(tinfo, position)
+	}); err != nil {
+		// 서버가 거부하면서 error 프레임을 보냈을 수 있으니 이유를 읽어봐
+		return remoteReason(conn, r, err)
+	}
+	if err := writeFrame(w, msgDone, nil); err != nil {
+		return remoteReason(conn, r, err)
+	}
+	if err := w.Flush(); err != nil {
+		return remoteReason(conn, r, err)
+	}
+
+	// 서버가 전체 다이제스트를 계산하는 동안 기다려 (큰 파일이면 오래 걸려)
+	var res Result
+	if err := readJSON(r, msgResult, &res); err != nil {
+		return err
+	}
+	if res.SHA256 != offer.SHA256 || res.Size != offer.Size {
+		return &streamio.ChecksumError{Path: offer.Name, Expected: offer.SHA256, Actual: res.SHA256}
+	}
+	return nil
+}
+
+// sendChunks offset 부터 끝까지 data 프레임으로 보내기 - onSent 는 프레임마다 보낸 바이트 수로 호출
+func sendChunks(w *bufio.Writer, path string, offset int64, opts SendOptions, onSent func(int64)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var src io.Reader = file
+	if opts.RateLimit > 0 {
+		src = streamio.NewThrottledReader(src, opts.RateLimit)
+	}
+	return writeChunks(w, src, offset, opts.chunkSize(), onSent)
+}
+
+// writeChunks src 를 끝까지 chunkSize 단위 data 프레임으로 (offset 은 src 첫 바이트의 파일 내 위치)
+func writeChunks(w io.Writer, src io.Reader, offset int64, chunkSize int, onSent func(int64)) error {
+	data := NewDataWriter(w, offset)
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(src, buf)
+		if n > 0 {
+			if _, werr := data.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			onSent(int64(n))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// remoteReason 쓰기가 실패했을 때 서버가 남긴 error 프레임이 있으면 그걸 돌려줘
+func remoteReason(conn net.Conn, r *bufio.Reader, writeErr error) error {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	typ, payload, err := readFrame(r, nil)
+	if err == nil && typ == msgError {
+		return &RemoteError{Message: string(payload)}
+	}
+	return writeErr
+}
@@ -0,0 +1,163 @@
+// Package transfer 는 TCP 위에서 도는 간단한 파일 전송 프로토콜이야.
+// HTTP 없이 소켓에 직접 프레임을 흘려보내면서, 끊기면 받은 곳부터 이어서 보내.
+//
+// 흐름 (C: 클라이언트, S: 서버)
+//
+//	C → S  offer   {name, size, sha256}
+//	S → C  accept  {offset}              이미 받아둔 .part 가 있으면 그 크기부터
+//	C → S  data    [오프셋 8][CRC32 4][데이터] × N
+//	C → S  done
+//	S → C  result  {size, sha256}        전체 다이제스트가 offer 와 같을 때만
+//
+// 어느 쪽이든 문제가 생기면 error 프레임(메시지 문자열)을 보내고 연결을 끊어.
+package transfer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 프레임 종류
+const (
+	msgOffer  byte = 1 // C → S: 보낼 파일 정보 (JSON)
+	msgAccept byte = 2 // S → C: 이어받을 오프셋 (JSON)
+	msgData   byte = 3 // C → S: 청크 (바이너리)
+	msgDone   byte = 4 // C → S: 데이터 끝 (페이로드 없음)
+	msgResult byte = 5 // S → C: 최종 검증 결과 (JSON)
+	msgError  byte = 6 // 양방향: 에러 메시지
+)
+
+// ⭐ 프레임 = [종류 1바이트][길이 4바이트, big endian][페이로드]
+// 길이를 먼저 보내니까 받는 쪽은 정확히 그만큼만 읽으면 돼 (구분자 이스케이프 필요 없음)
+const frameHeaderSize = 5
+
+// dataHeaderSize data 페이로드 앞부분 = [오프셋 8][CRC32 4]
+const dataHeaderSize = 12
+
+// MaxFrameSize 받는 쪽이 허용하는 최대 페이로드 (잘못된 길이로 메모리를 다 잡아먹지 않게)
+const MaxFrameSize = 16 << 20
+
+// DefaultChunkSize 기본 data 프레임 크기
+const DefaultChunkSize = 64 * 1024
+
+// Offer 클라이언트가 처음 보내는 파일 정보
+type Offer struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Accept 서버가 이어받을 위치를 알려줘 (0 이면 처음부터)
+type Accept struct {
+	Offset int64 `json:"offset"`
+}
+
+// Result 서버가 파일을 다 받고 검증까지 끝낸 결과
+type Result struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// RemoteError 상대방이 error 프레임으로 보낸 에러
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return msg.T("상대방 에러: ") + e.Message
+}
+
+// ErrFrameTooLarge 길이 필드가 MaxFrameSize 를 넘는 프레임
+var ErrFrameTooLarge = msg.New("프레임이 너무 큼")
+
+func writeFrame(w io.Writer, typ byte, payload []byte) error {
+	var header [frameHeaderSize]byte
+	header[0] = typ
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func writeJSON(w io.Writer, typ byte, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, typ, payload)
+}
+
+// writeData data 프레임 - 헤더를 한 번에 쓰고 데이터는 복사 없이 이어서
+func writeData(w io.Writer, offset int64, data []byte) error {
+	var header [frameHeaderSize + dataHeaderSize]byte
+	header[0] = msgData
+	binary.BigEndian.PutUint32(header[1:], uint32(dataHeaderSize+len(data)))
+	binary.BigEndian.PutUint64(header[5:], uint64(offset))
+	binary.BigEndian.PutUint32(header[13:], crc32.ChecksumIEEE(data))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func writeError(w *bufio.Writer, err error) {
+	writeFrame(w, msgError, []byte(err.Error()))
+	w.Flush()
+}
+
+// readFrame 프레임 하나 읽기 - buf 가 충분히 크면 재사용해
+func readFrame(r io.Reader, buf []byte) (byte, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxFrameSize {
+		return 0, nil, msg.Errorf("%w: %d 바이트", ErrFrameTooLarge, size)
+	}
+	if int(size) > cap(buf) {
+		buf = make([]byte, size)
+	}
+	payload := buf[:size]
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, msg.Errorf("프레임 본문 읽기 실패: %w", err)
+	}
+	return header[0], payload, nil
+}
+
+// readJSON want 종류의 프레임을 읽어서 v 로 디코딩 (error 프레임이면 RemoteError)
+func readJSON(r io.Reader, want byte, v any) error {
+	typ, payload, err := readFrame(r, nil)
+	if err != nil {
+		return err
+	}
+	if typ == msgError {
+		return &RemoteError{Message: string(payload)}
+	}
+	if typ != want {
+		return msg.Errorf("예상하지 못한 프레임: %d (기대: %d)", typ, want)
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// parseData data 페이로드에서 오프셋을 꺼내고 CRC 를 검증
+func parseData(payload []byte) (int64, []byte, error) {
+	if len(payload) < dataHeaderSize {
+		return 0, nil, msg.New("data 프레임이 너무 짧음")
+	}
+	offset := int64(binary.BigEndian.Uint64(payload))
+	sum := binary.BigEndian.Uint32(payload[8:])
+	data := payload[dataHeaderSize:]
+	if crc32.ChecksumIEEE(data) != sum {
+		return offset, nil, msg.Errorf("청크 CRC 불일치 (오프셋 %d)", offset)
+	}
+	return offset, data, nil
+}
@@ -0,0 +1,517 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// ⭐ QUIC 모드
+// 연결 하나 안에 양방향 제어 스트림 하나(offer/accept/done/result)와
+// 단방향 데이터 스트림 N개를 열어. 파일의 남은 구간을 N 등분해서 스트림마다 하나씩 보내니까,
+// 패킷 하나가 유실돼도 그 스트림만 멈추고 나머지는 계속 흘러가 (TCP 의 head-of-line blocking 이 없어).
+// 데이터 프레임 형식은 TCP 와 같아 (오프셋 + CRC32) - 서버는 오프셋 위치에 WriteAt 으로 써.
+//
+// 에러는 error 프레임 대신 연결 종료 사유(CONNECTION_CLOSE)로 보내 - 데이터 스트림이 몇 개든 한 번에 끊기니까.
+
+// quicDone QUIC 모드의 done 프레임 - 서버는 데이터 스트림이 이만큼 끝날 때까지 기다려
+type quicDone struct {
+	Streams int `json:"streams"`
+}
+
+// 연결 종료 코드
+const (
+	quicCodeOK    quic.ApplicationErrorCode = 0
+	quicCodeError quic.ApplicationErrorCode = 1
+)
+
+func quicConfig(idle time.Duration) *quic.Config {
+	return &quic.Config{
+		MaxIdleTimeout:        idle,
+		KeepAlivePeriod:       idle / 3,
+		MaxIncomingUniStreams: 256,
+		// 기본 창(스트림 6MB/연결 15MB)이면 지연이 큰 링크에서 창이 먼저 바닥나
+		MaxStreamReceiveWindow:     16 << 20,
+		MaxConnectionReceiveWindow: 64 << 20,
+	}
+}
+
+// ListenAndServeQUIC addr(UDP) 에서 QUIC 연결을 받아 ctx 가 취소될 때까지 처리
+// tlsConf 가 nil 이면 자체 서명 인증서를 만들고 지문을 로그로 남겨 (클라이언트는 PinnedTLS 로 접속).
+func (s *Server) ListenAndServeQUIC(ctx context.Context, addr string, tlsConf *tls.Config) error {
+	if tlsConf == nil {
+		conf, fingerprint, err := SelfSignedTLS()
+		if err != nil {
+			return err
+		}
+		s.logger().Info("QUIC 자체 서명 인증서", "fingerprint", fingerprint)
+		tlsConf = conf
+	}
+	ln, err := s.ListenQUIC(addr, tlsConf)
+	if err != nil {
+		return err
+	}
+	return s.ServeQUIC(ctx, ln)
+}
+
+// ListenQUIC ServeQUIC 에 넘길 listener (전송 프로토콜용 ALPN/흐름 제어 설정 포함)
+// 포트를 0 으로 열고 실제 주소를 알아야 하는 테스트/벤치마크용
+func (s *Server) ListenQUIC(addr string, tlsConf *tls.Config) (*quic.Listener, error) {
+	return quic.ListenAddr(addr, withALPN(tlsConf), quicConfig(s.idleTimeout()))
+}
+
+// ServeQUIC ln 으로 들어오는 연결마다 고루틴 하나 (연결 하나 = 파일 하나)
+func (s *Server) ServeQUIC(ctx context.Context, ln *quic.Listener) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.handleQUIC(ctx, conn); err != nil {
+				s.logger().Warn("QUIC 수신 실패", "remote", conn.RemoteAddr().String(), "err", err)
+				conn.CloseWithError(quicCodeError, err.Error())
+				return
+			}
+			// result 가 클라이언트에 닿기 전에 끊지 않게, 클라이언트가 먼저 닫길 기다려
+			select {
+			case <-conn.Context().Done():
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+			}
+			conn.CloseWithError(quicCodeOK, "")
+		}()
+	}
+}
+
+func (s *Server) handleQUIC(ctx context.Context, conn *quic.Conn) error {
+	ctrl, err := conn.AcceptStream(ctx)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(ctrl)
+	w := bufio.NewWriter(ctrl)
+
+	var offer Offer
+	if err := readJSON(r, msgOffer, &offer); err != nil {
+		return msg.Errorf("offer 읽기 실패: %w", err)
+	}
+	if err := validateOffer(offer); err != nil {
+		return err
+	}
+
+	final := filepath.Join(s.Dir, offer.Name)
+	unlock := streamio.LockPath(final)
+	defer unlock()
+
+	part, offset, err := openPart(final, offer)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	if err := writeJSON(w, msgAccept, Accept{Offset: offset}); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	hooks := s.hooks()
+	info := streamio.TransferInfo{ID: offer.Name, Src: conn.RemoteAddr().String(), Dst: final, Size: offer.Size}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	rx := &rangeReceiver{part: part, base: offset, size: offer.Size, onProgress: func(n int64) { hooks.OnProgress(info, n) }}
+	rx.received.Store(offset)
+
+	err = s.receiveStreams(ctx, conn, r, rx)
+	end := rx.stop()
+	if err == nil && end != offer.Size {
+		err = msg.Errorf("빠진 구간이 있음: %d/%d 바이트", end, offer.Size)
+	}
+	if err == nil {
+		err = finishPart(part, final, offer)
+	}
+	if err != nil {
+		// 다음 시도가 이어받을 수 있게, 앞에서부터 빈틈없이 받은 곳까지만 남겨
+		part.Truncate(end)
+		hooks.OnError(info, err)
+		return err
+	}
+
+	hooks.OnComplete(info, offer.Size-offset, time.Since(start))
+	if err := writeJSON(w, msgResult, Result{Size: offer.Size, SHA256: offer.SHA256}); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return ctrl.Close()
+}
+
+// receiveStreams 제어 스트림의 done 이 알려준 개수만큼 데이터 스트림이 끝날 때까지 받기
+func (s *Server) receiveStreams(ctx context.Context, conn *quic.Conn, ctrl io.Reader, rx *rangeReceiver) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error)
+	go func() {
+		for {
+			st, err := conn.AcceptUniStream(streamCtx)
+			if err != nil {
+				return
+			}
+			go func() {
+				err := rx.stream(st)
+				select {
+				case results <- err:
+				case <-streamCtx.Done():
+				}
+			}()
+		}
+	}()
+
+	done := make(chan error, 1)
+	expected := -1
+	go func() {
+		var d quicDone
+		err := readJSON(ctrl, msgDone, &d)
+		expected = d.Streams
+		done <- err
+	}()
+
+	finished := 0
+	for {
+		select {
+		case err := <-results:
+			if err != nil {
+				return err
+			}
+			finished++
+		case err := <-done:
+			if err != nil {
+				return msg.Errorf("done 읽기 실패: %w", err)
+			}
+			done = nil // 한 번만
+		case <-conn.Context().Done():
+			return context.Cause(conn.Context())
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if done == nil && finished >= expected {
+			return nil
+		}
+	}
+}
+
+// segment 데이터 스트림 하나가 채운 구간 [start, pos)
+type segment struct {
+	start, pos int64
+}
+
+// rangeReceiver 여러 스트림이 각자 구간을 WriteAt 으로 채우는 수신기
+type rangeReceiver struct {
+	part       *os.File
+	base, size int64 // base 는 이어받기 시작 위치
+	onProgress func(received int64)
+
+	mu       sync.Mutex
+	segments []*segment
+	stopped  bool
+	received atomic.Int64
+}
+
+// stop 이후로는 쓰기를 거부하고 base 부터 빈틈없이 받은 끝 위치를 돌려줘
+func (rx *rangeReceiver) stop() int64 {
+	rx.mu.Lock()
+	rx.stopped = true
+	rx.mu.Unlock()
+	return rx.prefix()
+}
+
+// stream 데이터 스트림 하나 - 프레임은 스트림 안에서 빈틈없이 이어져야 해
+func (rx *rangeReceiver) stream(st *quic.ReceiveStream) error {
+	r := bufio.NewReaderSize(st, DefaultChunkSize+frameHeaderSize+dataHeaderSize)
+	buf := make([]byte, DefaultChunkSize+dataHeaderSize)
+	var seg *segment
+
+	for {
+		typ, payload, err := readFrame(r, buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return msg.Errorf("데이터 스트림 읽기 실패: %w", err)
+		}
+		if typ != msgData {
+			return msg.Errorf("데이터 스트림에 예상하지 못한 프레임: %d", typ)
+		}
+
+		at, data, err := parseData(payload)
+		if err != nil {
+			return err
+		}
+		if at < rx.base || at+int64(len(data)) > rx.size {
+			return msg.Errorf("범위를 벗어난 청크: %d+%d (허용: %d~%d)", at, len(data), rx.base, rx.size)
+		}
+		if seg == nil {
+			seg = &segment{start: at, pos: at}
+			rx.mu.Lock()
+			rx.segments = append(rx.segments, seg)
+			rx.mu.Unlock()
+		} else if at != seg.pos {
+			return msg.Errorf("청크 오프셋 불일치: %d (기대: %d)", at, seg.pos)
+		}
+
+		// 쓰기와 구간 갱신을 같이 잠가서, 실패 처리(stop → Truncate) 뒤에는 아무도 못 쓰게 해
+		rx.mu.Lock()
+		if rx.stopped {
+			rx.mu.Unlock()
+			return msg.New("수신 중단됨")
+		}
+		_, err = rx.part.WriteAt(data, at)
+		if err == nil {
+			seg.pos += int64(len(data))
+		}
+		rx.mu.Unlock()
+		if err != nil {
+			return msg.Errorf("쓰기 실패: %w", err)
+		}
+		rx.onProgress(rx.received.Add(int64(len(data))))
+	}
+}
+
+// prefix base 부터 빈틈없이 채워진 끝 위치
+func (rx *rangeReceiver) prefix() int64 {
+	rx.mu.Lock()
+	defer rx.mu.Unlock()
+
+	segs := make([]segment, len(rx.segments))
+	for i, s := range rx.segments {
+		segs[i] = *s
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].start < segs[j].start })
+
+	end := rx.base
+	for _, s := range segs {
+		if s.start > end {
+			break
+		}
+		end = max(end, s.pos)
+	}
+	return end
+}
+
+// splitRanges [from, size) 를 chunk 단위로 맞춘 최대 n 개 구간으로
+func splitRanges(from, size int64, n, chunk int) [][2]int64 {
+	remaining := size - from
+	if remaining <= 0 {
+		return nil
+	}
+	per := (remaining + int64(n) - 1) / int64(n)
+	per = (per + int64(chunk) - 1) / int64(chunk) * int64(chunk)
+
+	var ranges [][2]int64
+	for start := from; start < size; start += per {
+		ranges = append(ranges, [2]int64{start, min(start+per, size)})
+	}
+	return ranges
+}
+
+// sendQUIC QUIC 연결 하나로 offer → 병렬 데이터 스트림 → done → result
+func sendQUIC(ctx context.Context, addr, path string, offer Offer, opts SendOptions, hooks streamio.Hooks, tinfo streamio.TransferInfo, result *SendResult) (err error) {
+	if opts.TLS == nil {
+		return msg.New("QUIC 모드는 TLS 설정이 필요해 (SendOptions.TLS, 자체 서명 서버면 PinnedTLS)")
+	}
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return err
+	}
+	udp, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return err
+	}
+	tr := &quic.Transport{Conn: udp}
+	defer tr.Close()
+
+	dialCtx, cancel := context.WithTimeout(ctx, opts.timeout())
+	conn, err := tr.Dial(dialCtx, raddr, withALPN(opts.TLS), quicConfig(opts.timeout()))
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer conn.CloseWithError(quicCodeOK, "")
+	// 서버가 연결을 끊으면서 남긴 사유를 RemoteError 로
+	defer func() {
+		var appErr *quic.ApplicationError
+		if err != nil && errors.As(err, &appErr) && appErr.Remote {
+			err = &RemoteError{Message: appErr.ErrorMessage}
+		} else if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
+	ctrl, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(ctrl)
+	w := bufio.NewWriter(ctrl)
+	if err := writeJSON(w, msgOffer, offer); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	var accept Accept
+	if err := readJSON(r, msgAccept, &accept); err != nil {
+		return msg.Errorf("accept 읽기 실패: %w", err)
+	}
+	if accept.Offset < 0 || accept.Offset > offer.Size {
+		return msg.Errorf("서버가 잘못된 오프셋을 보냄: %d", accept.Offset)
+	}
+	result.Resumed = accept.Offset
+
+	if opts.MigrateAfter > 0 {
+		stopMigrate := migrateAfter(ctx, conn, opts.MigrateAfter, result)
+		defer stopMigrate()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ranges := splitRanges(accept.Offset, offer.Size, opts.streams(), opts.chunkSize())
+	var position atomic.Int64
+	position.Store(accept.Offset)
+	var sent atomic.Int64
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(ranges))
+	for i, rg := range ranges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sendRange(ctx, conn, file, rg, opts, len(ranges), func(n int64) {
+				sent.Add(n)
+				hooks.OnProgress(tinfo, position.Add(n))
+			})
+		}()
+	}
+	wg.Wait()
+	result.Sent += sent.Load()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if err := writeJSON(w, msgDone, quicDone{Streams: len(ranges)}); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var res Result
+	if err := readJSON(r, msgResult, &res); err != nil {
+		return err
+	}
+	if res.SHA256 != offer.SHA256 || res.Size != offer.Size {
+		return &streamio.ChecksumError{Path: offer.Name, Expected: offer.SHA256, Actual: res.SHA256}
+	}
+	return nil
+}
+
+// sendRange 데이터 스트림 하나로 구간 하나 보내기 - 속도 제한은 스트림 수로 나눠서
+func sendRange(ctx context.Context, conn *quic.Conn, file *os.File, rg [2]int64, opts SendOptions, streams int, onSent func(int64)) error {
+	st, err := conn.OpenUniStreamSync(ctx)
+	if err != nil {
+		return err
+	}
+	var src io.Reader = io.NewSectionReader(file, rg[0], rg[1]-rg[0])
+	if opts.RateLimit > 0 {
+		src = streamio.NewThrottledReader(src, max(opts.RateLimit/int64(streams), 1))
+	}
+
+	w := bufio.NewWriterSize(st, opts.chunkSize()+frameHeaderSize+dataHeaderSize)
+	if err := writeChunks(w, src, rg[0], opts.chunkSize(), onSent); err != nil {
+		st.CancelWrite(0)
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return st.Close()
+}
+
+// migrateAfter d 뒤에 새 UDP 소켓으로 경로를 옮겨 (Wi-Fi → LTE 처럼 클라이언트 주소가 바뀌는 상황 흉내)
+// ⭐ QUIC 연결은 IP/포트가 아니라 연결 ID 로 구분돼서, 주소가 바뀌어도 스트림이 끊기지 않아
+func migrateAfter(ctx context.Context, conn *quic.Conn, d time.Duration, result *SendResult) (stop func()) {
+	var newTransport *quic.Transport
+	done := make(chan struct{})
+	timer := time.AfterFunc(d, func() {
+		defer close(done)
+		udp, err := net.ListenUDP("udp", nil)
+		if err != nil {
+			defaultLogger.Warn("경로 이동 실패", "err", err)
+			return
+		}
+		newTransport = &quic.Transport{Conn: udp}
+		path, err := conn.AddPath(newTransport)
+		if err != nil {
+			defaultLogger.Warn("경로 이동 실패", "err", err)
+			return
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := path.Probe(probeCtx); err != nil {
+			defaultLogger.Warn("새 경로 검증 실패", "err", err)
+			return
+		}
+		if err := path.Switch(); err != nil {
+			defaultLogger.Warn("경로 전환 실패", "err", err)
+			return
+		}
+		result.Migrated = true
+	})
+
+	return func() {
+		if !timer.Stop() {
+			<-done
+		}
+		// 연결이 새 소켓을 쓰고 있으니 연결을 먼저 닫고 소켓을 닫아
+		if newTransport != nil {
+			conn.CloseWithError(quicCodeOK, "")
+			newTransport.Close()
+		}
+	}
+}
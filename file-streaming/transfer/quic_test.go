@@ -0,0 +1,56 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 루프백 UDP 로 QUIC 서버를 띄우고 스트림 여러 개로 나눠 보내
+func TestQUICRoundTrip(t *testing.T) {
+	conf, fingerprint, err := SelfSignedTLS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{Dir: t.TempDir(), IdleTimeout: 5 * time.Second}
+	ln, err := s.ListenQUIC("127.0.0.1:0", conf)
+	if err != nil {
+		t.Skip("UDP 로 listen 할 수 없음:", err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- s.ServeQUIC(ctx, ln) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	src := filepath.Join(t.TempDir(), "data.bin")
+	data := bytes.Repeat([]byte("quic stream "), 100000) // 1.2MB - 스트림마다 여러 청크
+	os.WriteFile(src, data, 0644)
+
+	addr := ln.Addr().String()
+	res, err := Send(t.Context(), addr, src, SendOptions{Transport: TransportQUIC, TLS: PinnedTLS(fingerprint), Streams: 4, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Sent != int64(len(data)) || res.Resumed != 0 {
+		t.Errorf("Send = %+v", res)
+	}
+	if got, _ := os.ReadFile(filepath.Join(s.Dir, "data.bin")); !bytes.Equal(got, data) {
+		t.Error("받은 내용이 다름")
+	}
+
+	// 지문이 다르면 핸드셰이크에서 끊어
+	_, err = Send(t.Context(), addr, src, SendOptions{Transport: TransportQUIC, Name: "other.bin", TLS: PinnedTLS(strings.Repeat("ab", 32)), Timeout: 2 * time.Second})
+	if err == nil {
+		t.Error("지문이 다른데 연결됨")
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir, "other.bin")); !os.IsNotExist(err) {
+		t.Errorf("거절한 파일이 생김: %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+	"github.com/hellotect2022go/study-go/file-streaming/streamio"
+)
+
+// Server 파일을 받아서 Dir 에 저장하는 서버
+// 받는 중인 파일은 .<이름>.part 로 두고, 다이제스트까지 맞으면 rename 해.
+// 연결이 끊기면 .part 가 남아 있어서 같은 파일(이름/크기/해시)을 다시 보내면 이어받아.
+type Server struct {
+	Dir   string
+	Hooks streamio.Hooks // nil 이면 훅 호출 안 함
+
+	// IdleTimeout 프레임 사이 최대 대기 시간 (기본 1분) - 말없이 사라진 클라이언트 정리용
+	IdleTimeout time.Duration
+
+	Logger *slog.Logger // nil 이면 component=transfer 로거
+}
+
+// defaultLogger Logger 를 안 줬을 때와 클라이언트 쪽 로그
+var defaultLogger = logging.For("transfer")
+
+func (s *Server) hooks() streamio.Hooks {
+	if s.Hooks == nil {
+		return streamio.NopHooks{}
+	}
+	return s.Hooks
+}
+
+func (s *Server) logger() *slog.Logger {
+	if s.Logger == nil {
+		return defaultLogger
+	}
+	return s.Logger
+}
+
+func (s *Server) idleTimeout() time.Duration {
+	if s.IdleTimeout <= 0 {
+		return time.Minute
+	}
+	return s.IdleTimeout
+}
+
+// ListenAndServe addr 에서 연결을 받아 ctx 가 취소될 때까지 처리
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve ln 으로 들어오는 연결마다 고루틴 하나 - ctx 가 취소되면 listener 를 닫고
+// 진행 중인 전송이 끝나길 기다려 (끊긴 전송은 .part 로 남아서 나중에 이어받아)
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			closeOnCancel := context.AfterFunc(ctx, func() { conn.Close() })
+			defer closeOnCancel()
+
+			if err := s.handle(conn); err != nil {
+				s.logger().Warn("수신 실패", "remote", conn.RemoteAddr().String(), "err", err)
+			}
+		}()
+	}
+}
+
+// deadlineReader 읽을 때마다 연결 deadline 을 갱신
+type deadlineReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (d deadlineReader) Read(p []byte) (int, error) {
+	d.conn.SetReadDeadline(time.Now().Add(d.timeout))
+	return d.conn.Read(p)
+}
+
+// handle 연결 하나 = 파일 하나
+func (s *Server) handle(conn net.Conn) error {
+	r := bufio.NewReaderSize(deadlineReader{conn, s.idleTimeout()}, DefaultChunkSize+frameHeaderSize+dataHeaderSize)
+	w := bufio.NewWriter(conn)
+
+	offer, err := ReadOffer(r)
+	if err != nil {
+		var remote *RemoteError
+		if !errors.As(err, &remote) {
+			writeError(w, err)
+		}
+		return err
+	}
+
+	final := filepath.Join(s.Dir, offer.Name)
+	// 같은 이름을 동시에 받으면 .part 가 섞이니까 이름 단위로 줄 세우기
+	unlock := streamio.LockPath(final)
+	defer unlock()
+
+	part, offset, err := openPart(final, offer)
+	if err != nil {
+		writeError(w, err)
+		return err
+	}
+	defer part.Close()
+
+	if err := writeJSON(w, msgAccept, Accept{Offset: offset}); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	hooks := s.hooks()
+	info := streamio.TransferInfo{ID: offer.Name, Src: conn.RemoteAddr().String(), Dst: final, Size: offer.Size}
+	hooks.OnStart(info)
+	start := time.Now()
+
+	err = s.receive(r, part, offer, offset, info)
+	if err == nil {
+		err = finishPart(part, final, offer)
+	}
+	if err != nil {
+		var remote *RemoteError
+		if !errors.As(err, &remote) {
+			writeError(w, err)
+		}
+		hooks.OnError(info, err)
+		return err
+	}
+
+	hooks.OnComplete(info, offer.Size-offset, time.Since(start))
+	if err := writeJSON(w, msgResult, Result{Size: offer.Size, SHA256: offer.SHA256}); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// receive done 프레임까지 청크를 받아서 .part 뒤에 이어 써
+// DataReader 는 CRC 가 맞은 청크만 돌려주니까, 실패해도 .part 에는 검증된 데이터만 남아.
+func (s *Server) receive(r *bufio.Reader, part *os.File, offer Offer, offset int64, info streamio.TransferInfo) error {
+	hooks := s.hooks()
+	data := NewDataReader(r, offset, offer.Size)
+	buf := make([]byte, DefaultChunkSize)
+	received := offset
+
+	for {
+		n, err := data.Read(buf)
+		if n > 0 {
+			if _, werr := part.Write(buf[:n]); werr != nil {
+				return msg.Errorf("쓰기 실패: %w", werr)
+			}
+			received += int64(n)
+			hooks.OnProgress(info, received)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// validateOffer 이름은 Dir 밖으로 나갈 수 없는 파일 이름 하나여야 해
+func validateOffer(o Offer) error {
+	if o.Name == "" || o.Name == "." || o.Name == ".." || o.Name != filepath.Base(o.Name) || strings.ContainsAny(o.Name, `/\`) {
+		return msg.Errorf("잘못된 파일 이름: %q", o.Name)
+	}
+	if strings.HasPrefix(o.Name, ".") && (strings.HasSuffix(o.Name, ".part") || strings.HasSuffix(o.Name, ".part.json")) {
+		return msg.Errorf("예약된 파일 이름: %q", o.Name)
+	}
+	if o.Size < 0 {
+		return msg.Errorf("잘못된 크기: %d", o.Size)
+	}
+	if len(o.SHA256) != 64 {
+		return msg.Errorf("잘못된 sha256: %q", o.SHA256)
+	}
+	return nil
+}
+
+func partPaths(final string) (part, meta string) {
+	part = filepath.Join(filepath.Dir(final), "."+filepath.Base(final)+".part")
+	return part, part + ".json"
+}
+
+// openPart 이어받을 수 있으면 기존 .part 를 열고 그 크기를 오프셋으로,
+// 아니면 (다른 파일이거나 처음이면) 새로 만들어.
+// ⭐ .part.json 에 offer 를 같이 저장해서 이름만 같은 다른 파일에 이어 쓰지 않게 해
+func openPart(final string, offer Offer) (*os.File, int64, error) {
+	partPath, metaPath := partPaths(final)
+
+	var prev Offer
+	if data, err := os.ReadFile(metaPath); err == nil && json.Unmarshal(data, &prev) == nil && prev == offer {
+		if f, err := os.OpenFile(partPath, os.O_WRONLY, 0644); err == nil {
+			if info, err := f.Stat(); err == nil && info.Size() <= offer.Size {
+				if _, err := f.Seek(info.Size(), 0); err == nil {
+					return f, info.Size(), nil
+				}
+			}
+			f.Close()
+		}
+	}
+
+	meta, err := json.Marshal(offer)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := os.WriteFile(metaPath, meta, 0644); err != nil {
+		return nil, 0, msg.Errorf("메타 파일 저장 실패: %w", err)
+	}
+	f, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, 0, msg.Errorf(".part 파일 생성 실패: %w", err)
+	}
+	return f, 0, nil
+}
+
+// finishPart 디스크에 내리고 전체 다이제스트를 확인한 뒤 최종 이름으로
+// 다이제스트가 틀리면 .part 는 믿을 수 없으니 지워서 다음 시도는 처음부터 받게 해
+func finishPart(part *os.File, final string, offer Offer) error {
+	if err := part.Sync(); err != nil {
+		return err
+	}
+	// rename 전에 닫아야 Windows 에서도 돼 (handle 의 defer Close 는 그냥 에러를 무시해)
+	if err := part.Close(); err != nil {
+		return err
+	}
+	partPath, metaPath := partPaths(final)
+
+	sum, err := streamio.FileSHA256(partPath)
+	if err != nil {
+		return msg.Errorf("다이제스트 계산 실패: %w", err)
+	}
+	if sum != offer.SHA256 {
+		os.Remove(partPath)
+		os.Remove(metaPath)
+		return &streamio.ChecksumError{Path: final, Expected: offer.SHA256, Actual: sum}
+	}
+
+	if err := streamio.Rename(partPath, final); err != nil {
+		return err
+	}
+	os.Remove(metaPath)
+	return nil
+}
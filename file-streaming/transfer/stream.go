@@ -0,0 +1,145 @@
+package transfer
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 프레임을 io.Reader / io.Writer 로 감싼 어댑터
+// ⭐ DataReader 는 data 프레임들을 풀어서 내용만, DataWriter 는 쓰는 바이트를 data 프레임으로 -
+// 프레임 경계, 오프셋, CRC 는 어댑터가 맡아서 양쪽 다 그냥 io.Copy 로 흘려보내면 돼.
+// 이 패키지의 Server/Send 도 쓰고, 다른 서버가 같은 프로토콜로 받을 때도 (step09 의 -tcp-addr) 저장은 자기 방식대로 하고 프레임만 이걸로 다뤄:
+//
+//	offer, err := transfer.ReadOffer(r)          // 첫 프레임
+//	transfer.WriteAccept(w, 0)                     // 처음부터 보내
+//	io.Copy(dst, transfer.NewDataReader(r, 0, offer.Size))
+//	transfer.WriteResult(w, transfer.Result{…})   // 아니면 transfer.WriteError
+
+// DataReader data 프레임들의 내용을 이어서 읽어 - done 프레임에서 io.EOF
+// CRC 가 틀린 청크는 돌려주지 않으니까, 에러가 나도 그때까지 읽은 건 검증된 데이터만이야.
+type DataReader struct {
+	r       io.Reader
+	offset  int64 // 다음 청크가 와야 할 위치
+	size    int64 // offer 의 크기
+	buf     []byte
+	pending []byte // 아직 안 읽어 간 청크 내용 (buf 안)
+	err     error
+}
+
+// NewDataReader offset 부터 size 까지 오는 data 프레임을 r 에서 읽는 DataReader
+func NewDataReader(r io.Reader, offset, size int64) *DataReader {
+	return &DataReader{r: r, offset: offset, size: size, buf: make([]byte, DefaultChunkSize+dataHeaderSize)}
+}
+
+func (d *DataReader) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+// next 프레임 하나 - 청크면 pending 에 (buf 를 다시 쓰니 pending 을 다 읽어 간 뒤에만 불러)
+func (d *DataReader) next() error {
+	typ, payload, err := readFrame(d.r, d.buf)
+	if err != nil {
+		return msg.Errorf("프레임 읽기 실패 (%d/%d 바이트 수신): %w", d.offset, d.size, err)
+	}
+	switch typ {
+	case msgData:
+		at, data, err := parseData(payload)
+		if err != nil {
+			return err
+		}
+		if at != d.offset {
+			return msg.Errorf("청크 오프셋 불일치: %d (기대: %d)", at, d.offset)
+		}
+		if d.offset+int64(len(data)) > d.size {
+			return msg.Errorf("offer 크기(%d)보다 많이 보냄", d.size)
+		}
+		d.offset += int64(len(data))
+		d.pending = data
+		return nil
+
+	case msgDone:
+		if d.offset != d.size {
+			return msg.Errorf("크기 불일치: %d 바이트 수신 (offer: %d)", d.offset, d.size)
+		}
+		return io.EOF
+
+	case msgError:
+		return &RemoteError{Message: string(payload)}
+	}
+	return msg.Errorf("예상하지 못한 프레임: %d", typ)
+}
+
+// DataWriter 쓰는 바이트를 data 프레임으로 - Write 한 번이 프레임 하나 (MaxFrameSize 를 넘으면 나눠서)
+// 다 쓰면 Close 로 done 프레임을 보내 (밑의 연결은 닫지 않아)
+type DataWriter struct {
+	w      io.Writer
+	offset int64
+}
+
+// NewDataWriter offset 위치부터 보내는 DataWriter
+func NewDataWriter(w io.Writer, offset int64) *DataWriter {
+	return &DataWriter{w: w, offset: offset}
+}
+
+func (d *DataWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), MaxFrameSize-dataHeaderSize)]
+		if err := writeData(d.w, d.offset, chunk); err != nil {
+			return written, err
+		}
+		d.offset += int64(len(chunk))
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Close done 프레임
+func (d *DataWriter) Close() error {
+	return writeFrame(d.w, msgDone, nil)
+}
+
+// ReadOffer 연결의 첫 프레임(offer)을 읽고 이름, 크기, sha256 형식까지 확인
+func ReadOffer(r io.Reader) (Offer, error) {
+	var offer Offer
+	if err := readJSON(r, msgOffer, &offer); err != nil {
+		return Offer{}, msg.Errorf("offer 읽기 실패: %w", err)
+	}
+	if err := validateOffer(offer); err != nil {
+		return Offer{}, err
+	}
+	return offer, nil
+}
+
+// WriteAccept offset 부터 보내라고 답해 (w 가 bufio.Writer 면 Flush 까지)
+func WriteAccept(w io.Writer, offset int64) error {
+	return flushed(w, writeJSON(w, msgAccept, Accept{Offset: offset}))
+}
+
+// WriteResult 다 받고 검증까지 끝났다고 답해
+func WriteResult(w io.Writer, res Result) error {
+	return flushed(w, writeJSON(w, msgResult, res))
+}
+
+// WriteError error 프레임 - 보내고 나면 연결을 끊어
+func WriteError(w io.Writer, err error) error {
+	return flushed(w, writeFrame(w, msgError, []byte(err.Error())))
+}
+
+func flushed(w io.Writer, err error) error {
+	if bw, ok := w.(*bufio.Writer); ok && err == nil {
+		return bw.Flush()
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package transfer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// ALPN QUIC 핸드셰이크에서 쓰는 응용 프로토콜 이름 - 다른 QUIC 서비스와 섞이지 않게
+const ALPN = "streamio-transfer/1"
+
+// SelfSignedTLS 실행할 때마다 새로 만드는 자체 서명 인증서 (QUIC 은 TLS 가 필수야)
+// 돌려주는 지문(SHA-256)을 클라이언트에 알려주면 PinnedTLS 로 이 서버만 믿게 할 수 있어.
+func SelfSignedTLS() (*tls.Config, string, error) {
+	cert, fingerprint, err := SelfSignedCert("streamio-transfer")
+	if err != nil {
+		return nil, "", err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{ALPN}}, fingerprint, nil
+}
+
+// SelfSignedCert 메모리에만 있는 자체 서명 인증서와 그 지문 (localhost, 127.0.0.1, ::1, 이 머신 호스트 이름용, 1년)
+// ALPN 을 정하지 않아서 HTTPS 서버(step09 -tls) 같은 다른 프로토콜에도 그대로 써.
+func SelfSignedCert(commonName string) (tls.Certificate, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, "", err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return tls.Certificate{}, "", err
+	}
+	names := []string{"localhost"}
+	if host, err := os.Hostname(); err == nil && host != "" && host != "localhost" {
+		names = append(names, host)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     names,
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, "", err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, Fingerprint(der), nil
+}
+
+// Fingerprint 인증서(DER)의 SHA-256 지문
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// PinnedTLS 서버 인증서 지문이 fingerprint 와 같을 때만 연결하는 클라이언트 설정
+// 자체 서명 인증서라 CA 검증은 못 하니까, 대신 지문을 직접 비교해.
+func PinnedTLS(fingerprint string) *tls.Config {
+	want := strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+	return &tls.Config{
+		InsecureSkipVerify: true, // CA 검증 대신 아래 지문 비교
+		NextProtos:         []string{ALPN},
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return msg.New("서버 인증서 없음")
+			}
+			if got := Fingerprint(rawCerts[0]); got != want {
+				return msg.Errorf("서버 인증서 지문 불일치: %s", got)
+			}
+			return nil
+		},
+	}
+}
+
+// withALPN 호출한 쪽 설정을 건드리지 않게 복사해서 ALPN 만 채워
+func withALPN(conf *tls.Config) *tls.Config {
+	conf = conf.Clone()
+	if len(conf.NextProtos) == 0 {
+		conf.NextProtos = []string{ALPN}
+	}
+	return conf
+}
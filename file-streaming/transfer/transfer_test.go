@@ -0,0 +1,164 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func offerFor(name string, data []byte) Offer {
+	sum := sha256.Sum256(data)
+	return Offer{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// pipeSend net.Pipe 의 서버 쪽은 s.handle 이, 이쪽은 손으로 프레임을 보내
+// data[:upTo] 까지만 보내고 끊으면 (upTo < len(data)) 잘린 전송이 돼
+func pipeSend(t *testing.T, s *Server, data []byte, upTo int) (Accept, Result, error) {
+	t.Helper()
+	client, server := net.Pipe()
+	handled := make(chan error, 1)
+	go func() {
+		defer server.Close()
+		handled <- s.handle(server)
+	}()
+
+	r, w := bufio.NewReader(client), bufio.NewWriter(client)
+	writeJSON(w, msgOffer, offerFor("data.bin", data))
+	w.Flush()
+	var accept Accept
+	if err := readJSON(r, msgAccept, &accept); err != nil {
+		t.Fatal(err)
+	}
+
+	dw := NewDataWriter(w, accept.Offset)
+	for _, chunk := range chunks(data[accept.Offset:upTo], 1000) {
+		dw.Write(chunk)
+	}
+	var res Result
+	if upTo < len(data) {
+		w.Flush()
+		client.Close()
+		return accept, res, <-handled
+	}
+	dw.Close()
+	w.Flush()
+	err := readJSON(r, msgResult, &res)
+	client.Close()
+	if herr := <-handled; err == nil {
+		err = herr
+	}
+	return accept, res, err
+}
+
+func chunks(p []byte, size int) [][]byte {
+	var out [][]byte
+	for len(p) > 0 {
+		n := min(size, len(p))
+		out = append(out, p[:n])
+		p = p[n:]
+	}
+	return out
+}
+
+func TestPipeRoundTrip(t *testing.T) {
+	s := &Server{Dir: t.TempDir()}
+	data := bytes.Repeat([]byte("transfer "), 5000)
+
+	accept, res, err := pipeSend(t, s, data, len(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := offerFor("data.bin", data); accept.Offset != 0 || res.Size != want.Size || res.SHA256 != want.SHA256 {
+		t.Errorf("accept = %+v, result = %+v", accept, res)
+	}
+	if got, _ := os.ReadFile(filepath.Join(s.Dir, "data.bin")); !bytes.Equal(got, data) {
+		t.Error("받은 내용이 다름")
+	}
+	// 다 받으면 .part 와 사이드카는 남지 않아
+	if entries, _ := os.ReadDir(s.Dir); len(entries) != 1 {
+		t.Errorf("남은 파일 = %v", entries)
+	}
+}
+
+// 잘린 전송은 .part 로 남고, 같은 파일을 Send 하면 받은 곳부터 이어 보내
+func TestResumeAfterTruncated(t *testing.T) {
+	s := &Server{Dir: t.TempDir()}
+	data := bytes.Repeat([]byte("0123456789"), 20000)
+	const cut = 120000
+
+	if _, _, err := pipeSend(t, s, data, cut); err == nil {
+		t.Fatal("끊긴 전송인데 에러가 없음")
+	}
+	part, _ := partPaths(filepath.Join(s.Dir, "data.bin"))
+	if info, err := os.Stat(part); err != nil || info.Size() != cut {
+		t.Fatalf(".part = %v, %v, want %d 바이트", info, err, cut)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(t.Context(), ln) }()
+	defer func() {
+		ln.Close()
+		<-done
+	}()
+
+	src := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(src, data, 0644)
+	res, err := Send(t.Context(), ln.Addr().String(), src, SendOptions{ChunkSize: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Resumed != cut || res.Sent != int64(len(data)-cut) || res.Attempts != 1 {
+		t.Errorf("Send = %+v, want %d 부터 이어서", res, cut)
+	}
+	if got, _ := os.ReadFile(filepath.Join(s.Dir, "data.bin")); !bytes.Equal(got, data) {
+		t.Error("이어받은 내용이 다름")
+	}
+
+	// 같은 이름이라도 다른 파일이면 처음부터
+	os.WriteFile(src, []byte("other"), 0644)
+	if res, err := Send(t.Context(), ln.Addr().String(), src, SendOptions{}); err != nil || res.Resumed != 0 {
+		t.Errorf("다른 파일 Send = %+v, %v", res, err)
+	}
+}
+
+func TestDataReaderCRC(t *testing.T) {
+	var buf bytes.Buffer
+	dw := NewDataWriter(&buf, 0)
+	dw.Write([]byte("hello"))
+	dw.Write([]byte("world"))
+	dw.Close()
+	frames := buf.Bytes()
+
+	if got, err := io.ReadAll(NewDataReader(bytes.NewReader(frames), 0, 10)); err != nil || string(got) != "helloworld" {
+		t.Fatalf("ReadAll = %q, %v", got, err)
+	}
+	// 두 번째 청크를 망가뜨리면 첫 청크까지만 나와
+	bad := bytes.Clone(frames)
+	bad[len(bad)-frameHeaderSize-1] ^= 0xff
+	got, err := io.ReadAll(NewDataReader(bytes.NewReader(bad), 0, 10))
+	if err == nil || string(got) != "hello" {
+		t.Errorf("망가진 청크 = %q, %v", got, err)
+	}
+	// offer 크기와 다르면 done 에서 거절
+	if _, err := io.ReadAll(NewDataReader(bytes.NewReader(frames), 0, 11)); err == nil {
+		t.Error("크기가 모자란데 에러가 없음")
+	}
+
+	var remote *RemoteError
+	var errFrame bytes.Buffer
+	WriteError(&errFrame, errors.New("디스크 가득 참"))
+	if _, err := io.ReadAll(NewDataReader(&errFrame, 0, 10)); !errors.As(err, &remote) {
+		t.Errorf("error 프레임 = %v, want RemoteError", err)
+	}
+}
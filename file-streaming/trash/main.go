@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/config"
+	"github.com/hellotect2022go/study-go/file-streaming/fstree"
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 휴지통 관리 도구 - dirsync -trash 나 step09 서버 삭제 API 가 옮겨둔 파일 복원/정리
+//
+//	go run ./trash -dir .trash list
+//	go run ./trash -dir .trash delete <파일>...
+//	go run ./trash -dir .trash restore <ID>...
+//	go run ./trash -dir .trash purge [-older 720h]
+func main() {
+	// 플래그 설명과 출력도 FS_LANG / -lang 을 따라가게 (플래그를 등록하기 전에)
+	locale, err := config.LocaleFromArgs(os.Args[1:])
+	if err != nil {
+		logging.Fatal("언어 설정 실패", "err", err)
+	}
+	locale.RegisterFlags(flag.CommandLine)
+	dir := flag.String("dir", ".trash", msg.T("휴지통 디렉토리"))
+	older := flag.Duration("older", 0, msg.T("purge: 이보다 오래전에 지운 것만 (0 이면 전부)"))
+	flag.Parse()
+	logging.SetupFromEnv()
+
+	if flag.NArg() < 1 {
+		fmt.Println(msg.T("사용법: go run ./trash [-dir .trash] list|delete|restore|purge [인자...]"))
+		os.Exit(2)
+	}
+
+	trash, err := fstree.OpenTrash(*dir)
+	if err != nil {
+		logging.Fatal("휴지통 열기 실패", "dir", *dir, "err", err)
+	}
+
+	if err := run(trash, flag.Arg(0), flag.Args()[1:], *older); err != nil {
+		logging.Fatal("실패", "command", flag.Arg(0), "err", err)
+	}
+}
+
+func run(trash *fstree.Trash, cmd string, args []string, older time.Duration) error {
+	switch cmd {
+	case "list":
+		items, err := trash.List()
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			fmt.Printf("%s  %s  %10d  %s\n", item.ID, item.DeletedAt.Format(time.DateTime), item.Size, item.OriginalPath)
+		}
+		msg.Printf("%d개 항목\n", len(items))
+
+	case "delete":
+		for _, path := range args {
+			item, err := trash.Delete(path)
+			if err != nil {
+				return err
+			}
+			msg.Printf("휴지통으로 이동: %s (ID: %s)\n", path, item.ID)
+		}
+
+	case "restore":
+		for _, id := range args {
+			item, err := trash.Restore(id)
+			if err != nil {
+				return err
+			}
+			msg.Printf("복원: %s\n", item.OriginalPath)
+		}
+
+	case "purge":
+		n, err := trash.Purge(older)
+		if err != nil {
+			return err
+		}
+		msg.Printf("%d개 항목 영구 삭제\n", n)
+
+	default:
+		return msg.Errorf("알 수 없는 명령: %s (list|delete|restore|purge)", cmd)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// 계정별 저장 공간 - 전송량(Meter)과 달리 "지금 업로드 디렉토리에 남아 있는" 바이트야
+// ⭐ 파일 이름마다 누가 올렸고 몇 바이트인지 적어 두고, 계정이 쓴 양은 그 합이야 -
+// 지우면 바로 줄고, 같은 이름으로 다시 올리면 예전 파일 크기만큼 돌려받아 (다른 계정이 덮어쓰면 주인이 바뀌어).
+// 바뀔 때마다 바로 파일에 써 (업로드/삭제 한 건에 한 번이라 Meter 처럼 모아 쓸 만큼 잦지 않아).
+//
+//	st, _ := usage.OpenStorage("storage.json")
+//	room := st.Available("alice", "big.iso", 10<<30)   // 이 이름으로 더 올릴 수 있는 바이트 (-1 이면 무제한)
+//	st.Put("big.iso", "alice", n)
+//	st.Rename("big.iso", "old.iso")
+//	st.Remove("old.iso")
+
+// ErrStorageQuota 저장 공간 한도를 넘었어
+var ErrStorageQuota = msg.New("저장 공간 한도를 넘었습니다")
+
+// StoredFile 업로드 디렉토리의 파일 하나
+type StoredFile struct {
+	Owner string `json:"owner"`
+	Size  int64  `json:"size"`
+}
+
+// Storage 파일 이름 → 주인, 크기 - 여러 고루틴에서 같이 써도 돼
+type Storage struct {
+	path string
+
+	mu    sync.Mutex
+	files map[string]StoredFile
+}
+
+// OpenStorage path 의 기록을 읽어 (없으면 빈 기록, path 를 비우면 파일 없이 메모리에서만)
+func OpenStorage(path string) (*Storage, error) {
+	st := &Storage{path: path, files: map[string]StoredFile{}}
+	if path == "" {
+		return st, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &st.files); err != nil {
+		return nil, msg.Errorf("저장 공간 기록 %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// Used owner 가 지금 차지한 바이트
+func (st *Storage) Used(owner string) int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.usedLocked(owner)
+}
+
+func (st *Storage) usedLocked(owner string) int64 {
+	var n int64
+	for _, f := range st.files {
+		if f.Owner == owner {
+			n += f.Size
+		}
+	}
+	return n
+}
+
+// Available owner 가 name 으로 올릴 수 있는 바이트 (limit 이 0 이하면 무제한이라 -1)
+// name 이 이미 owner 의 파일이면 덮어쓰면서 비워질 그 크기도 쳐 줘.
+func (st *Storage) Available(owner, name string, limit int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	free := limit - st.usedLocked(owner)
+	if f, ok := st.files[name]; ok && f.Owner == owner {
+		free += f.Size
+	}
+	return max(free, 0)
+}
+
+// Put name 을 owner 가 size 바이트로 올렸어 (예전 기록은 덮어써)
+func (st *Storage) Put(name, owner string, size int64) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.files[name] = StoredFile{Owner: owner, Size: size}
+	return st.saveLocked()
+}
+
+// Remove name 이 지워졌어 (기록이 없으면 아무것도 안 해)
+func (st *Storage) Remove(name string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if _, ok := st.files[name]; !ok {
+		return nil
+	}
+	delete(st.files, name)
+	return st.saveLocked()
+}
+
+// Rename oldName 이 newName 으로 옮겨졌어 - 주인과 크기는 그대로 (newName 의 예전 기록은 덮어써, oldName 기록이 없으면 아무것도 안 해)
+func (st *Storage) Rename(oldName, newName string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	f, ok := st.files[oldName]
+	if !ok {
+		return nil
+	}
+	delete(st.files, oldName)
+	st.files[newName] = f
+	return st.saveLocked()
+}
+
+// Prune 기록과 실제 파일을 맞춰 - stat 이 없다고 하면 기록을 지우고, 크기가 다르면 고쳐
+// 서버가 꺼진 사이에 누가 디렉토리에서 직접 지우거나 바꾼 파일 때문에 공간이 묶이지 않게 시작할 때 불러.
+func (st *Storage) Prune(stat func(name string) (size int64, ok bool)) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	changed := false
+	for name, f := range st.files {
+		size, ok := stat(name)
+		switch {
+		case !ok:
+			delete(st.files, name)
+		case size != f.Size:
+			f.Size = size
+			st.files[name] = f
+		default:
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return st.saveLocked()
+}
+
+func (st *Storage) saveLocked() error {
+	if st.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(st.files, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(st.path, data); err != nil {
+		return msg.Errorf("저장 공간 기록 저장 실패: %w", err)
+	}
+	return nil
+}
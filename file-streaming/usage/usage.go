@@ -0,0 +1,248 @@
+// Package usage 는 API 키(사용자)별 전송량 계정이야 - 업로드/다운로드 바이트를 달마다 세고, 파일에 저장해서 재시작해도 이어 세.
+// Storage 는 계정마다 지금 차지한 저장 공간(올려 둔 파일 크기의 합)이야.
+//
+// ⭐ Add 는 메모리 카운터만 올리고, 파일은 Run 이 주기적으로(그리고 끝날 때) 한 번에 써 -
+// 요청마다 디스크를 쓰지 않아도 되고, 죽으면 마지막 flush 이후 것만 잃어 (임시 파일 → rename 이라 파일이 깨지지는 않아).
+//
+//	m, _ := usage.Open("usage.json", usage.Options{})
+//	go m.Run(ctx)
+//	if err := m.Check("alice", 10<<30); err != nil { ... }   // 이번 달 10GB 를 넘었으면 ErrQuota
+//	m.Add("alice", usage.Download, n)
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hellotect2022go/study-go/file-streaming/logging"
+	"github.com/hellotect2022go/study-go/file-streaming/msg"
+)
+
+// Direction 옮긴 방향
+type Direction int
+
+const (
+	Upload Direction = iota
+	Download
+)
+
+// ErrQuota 이번 달 전송 한도를 다 썼어
+var ErrQuota = msg.New("이번 달 전송 한도를 넘었습니다")
+
+// Counter 업로드/다운로드 바이트
+type Counter struct {
+	Upload   int64 `json:"upload"`
+	Download int64 `json:"download"`
+}
+
+// Total 업로드 + 다운로드 (한도는 이걸로 세)
+func (c Counter) Total() int64 { return c.Upload + c.Download }
+
+func (c *Counter) add(d Direction, n int64) {
+	if d == Upload {
+		c.Upload += n
+	} else {
+		c.Download += n
+	}
+}
+
+// Account 계정 하나의 기록
+type Account struct {
+	Month    string    `json:"month"`    // "2006-01" (UTC) - 달이 바뀌면 Current 는 0 부터
+	Current  Counter   `json:"current"`  // 이번 달
+	Lifetime Counter   `json:"lifetime"` // 처음부터 전부
+	Updated  time.Time `json:"updated"`
+}
+
+// Options Open 설정
+type Options struct {
+	Flush  time.Duration    // Run 이 파일에 쓰는 주기 (기본 30초)
+	Now    func() time.Time // 테스트용 (기본 time.Now)
+	Logger *slog.Logger     // 기본 component=usage
+}
+
+// Meter 계정별 전송량 - 여러 고루틴에서 같이 써도 돼
+type Meter struct {
+	path string
+	opts Options
+
+	mu       sync.Mutex
+	accounts map[string]*Account
+	dirty    bool
+
+	flushMu sync.Mutex // Flush 끼리 - 먼저 찍은 내용이 나중 것을 덮어쓰지 않게
+}
+
+// Open path 의 기록을 읽어서 이어 세 (없으면 빈 기록, path 를 비우면 파일 없이 메모리에서만)
+func Open(path string, opts Options) (*Meter, error) {
+	if opts.Flush <= 0 {
+		opts.Flush = 30 * time.Second
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.For("usage")
+	}
+	m := &Meter{path: path, opts: opts, accounts: map[string]*Account{}}
+	if path == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.accounts); err != nil {
+		return nil, msg.Errorf("전송량 기록 %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Month t 가 속한 달 ("2006-01", UTC)
+func Month(t time.Time) string { return t.UTC().Format("2006-01") }
+
+// NextMonth t 다음 달 1일 0시 (UTC) - 한도가 풀리는 시각
+func NextMonth(t time.Time) time.Time {
+	y, mo, _ := t.UTC().Date()
+	return time.Date(y, mo+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// account name 의 기록 (없으면 만들고, 달이 바뀌었으면 이번 달 카운터를 비워) - mu 를 잡고 불러
+func (m *Meter) account(name string) *Account {
+	a := m.accounts[name]
+	if a == nil {
+		a = &Account{}
+		m.accounts[name] = a
+	}
+	if month := Month(m.opts.Now()); a.Month != month {
+		a.Month, a.Current = month, Counter{}
+	}
+	return a
+}
+
+// Add name 이 d 방향으로 n 바이트 옮김
+func (m *Meter) Add(name string, d Direction, n int64) {
+	if n <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.account(name)
+	a.Current.add(d, n)
+	a.Lifetime.add(d, n)
+	a.Updated = m.opts.Now()
+	m.dirty = true
+}
+
+// Get name 의 기록 (이번 달 기준)
+func (m *Meter) Get(name string) Account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.accounts[name]; !ok {
+		return Account{Month: Month(m.opts.Now())}
+	}
+	return *m.account(name)
+}
+
+// All 모든 계정의 기록 (복사본)
+func (m *Meter) All() map[string]Account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]Account, len(m.accounts))
+	for name := range m.accounts {
+		out[name] = *m.account(name)
+	}
+	return out
+}
+
+// Remaining 이번 달에 더 옮길 수 있는 바이트 (limit 이 0 이하면 무제한이라 -1)
+func (m *Meter) Remaining(name string, limit int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+	return max(limit-m.Get(name).Current.Total(), 0)
+}
+
+// Check 이번 달 한도(limit, 0 이하면 무제한)를 이미 다 썼으면 ErrQuota
+func (m *Meter) Check(name string, limit int64) error {
+	if m.Remaining(name, limit) == 0 {
+		return ErrQuota
+	}
+	return nil
+}
+
+// Flush 바뀐 게 있으면 파일에 써 (임시 파일 → rename)
+func (m *Meter) Flush() error {
+	if m.path == "" {
+		return nil
+	}
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+	m.mu.Lock()
+	if !m.dirty {
+		m.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(m.accounts, "", "  ")
+	m.dirty = false
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := writeFile(m.path, data); err != nil {
+		m.mu.Lock()
+		m.dirty = true // 다음 주기에 다시
+		m.mu.Unlock()
+		return msg.Errorf("전송량 기록 저장 실패: %w", err)
+	}
+	return nil
+}
+
+// Run ctx 가 끝날 때까지 주기적으로 Flush 하고, 끝나면 마지막으로 한 번 더
+func (m *Meter) Run(ctx context.Context) {
+	t := time.NewTicker(m.opts.Flush)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := m.Flush(); err != nil {
+				m.opts.Logger.Warn("전송량 기록 저장 실패", "file", m.path, "err", err)
+			}
+		case <-ctx.Done():
+			if err := m.Flush(); err != nil {
+				m.opts.Logger.Error("전송량 기록 저장 실패", "file", m.path, "err", err)
+			}
+			return
+		}
+	}
+}
+
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMeterMonth(t *testing.T) {
+	now := time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC)
+	m, err := Open("", Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Add("alice", Upload, 60)
+	m.Add("alice", Download, 40)
+	if err := m.Check("alice", 100); !errors.Is(err, ErrQuota) {
+		t.Errorf("100 바이트 다 썼는데 Check = %v", err)
+	}
+	if got := m.Remaining("alice", 150); got != 50 {
+		t.Errorf("Remaining = %d, want 50", got)
+	}
+	if got := m.Remaining("alice", 0); got != -1 {
+		t.Errorf("무제한 Remaining = %d, want -1", got)
+	}
+	if err := m.Check("bob", 100); err != nil {
+		t.Errorf("안 쓴 계정인데 Check = %v", err)
+	}
+
+	// 달이 바뀌면 이번 달은 0 부터, 전체는 그대로
+	now = now.Add(2 * time.Hour)
+	a := m.Get("alice")
+	if a.Month != "2026-02" || a.Current.Total() != 0 || a.Lifetime != (Counter{Upload: 60, Download: 40}) {
+		t.Errorf("다음 달 기록 = %+v", a)
+	}
+	if err := m.Check("alice", 100); err != nil {
+		t.Errorf("다음 달인데 Check = %v", err)
+	}
+	if got := NextMonth(now); !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextMonth = %s", got)
+	}
+}
+
+func TestMeterPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	m, err := Open(path, Options{Flush: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Add("alice", Download, 1000)
+
+	// Run 은 주기마다, 그리고 끝날 때 한 번 더 써
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() { m.Run(ctx); close(done) }()
+	m.Add("alice", Upload, 5)
+	cancel()
+	<-done
+
+	// 다시 열면 이어서 세
+	again, err := Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.Get("alice").Current; got != (Counter{Upload: 5, Download: 1000}) {
+		t.Errorf("다시 연 기록 = %+v", got)
+	}
+	again.Add("alice", Upload, 1)
+	if err := again.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	all := again.All()
+	if len(all) != 1 || all["alice"].Lifetime.Total() != 1006 {
+		t.Errorf("All = %+v", all)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	st, err := OpenStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Put("a.bin", "alice", 60)
+	st.Put("b.bin", "alice", 30)
+	st.Put("c.bin", "bob", 500)
+	if got := st.Used("alice"); got != 90 {
+		t.Errorf("Used = %d, want 90", got)
+	}
+	if got := st.Available("alice", "new.bin", 100); got != 10 {
+		t.Errorf("새 파일 Available = %d, want 10", got)
+	}
+	// 자기 파일을 덮어쓰면 그 크기만큼 돌려받아, 남의 파일은 아니고
+	if got := st.Available("alice", "a.bin", 100); got != 70 {
+		t.Errorf("덮어쓰기 Available = %d, want 70", got)
+	}
+	if got := st.Available("alice", "c.bin", 100); got != 10 {
+		t.Errorf("남의 파일 Available = %d, want 10", got)
+	}
+	if got := st.Available("alice", "x", 0); got != -1 {
+		t.Errorf("무제한 Available = %d, want -1", got)
+	}
+	if got := st.Available("bob", "x", 100); got != 0 {
+		t.Errorf("넘은 계정 Available = %d, want 0", got)
+	}
+
+	// 다른 계정이 덮어쓰면 주인이 바뀌어
+	st.Put("b.bin", "bob", 5)
+	st.Remove("a.bin")
+	if st.Used("alice") != 0 || st.Used("bob") != 505 {
+		t.Errorf("Used alice=%d bob=%d, want 0, 505", st.Used("alice"), st.Used("bob"))
+	}
+
+	// 이름을 옮겨도 주인과 크기는 그대로
+	st.Rename("b.bin", "d.bin")
+	st.Rename("없는.bin", "e.bin")
+	if st.Used("bob") != 505 || st.Available("bob", "d.bin", 600) != 100 {
+		t.Errorf("Rename 뒤 Used bob=%d, d.bin Available=%d", st.Used("bob"), st.Available("bob", "d.bin", 600))
+	}
+
+	// 다시 열면 그대로, Prune 은 없어진 파일을 지우고 크기를 맞춰
+	again, err := OpenStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.Used("bob"); got != 505 {
+		t.Errorf("다시 연 Used = %d, want 505", got)
+	}
+	err = again.Prune(func(name string) (int64, bool) {
+		if name == "c.bin" {
+			return 0, false
+		}
+		return 7, true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.Used("bob"); got != 7 {
+		t.Errorf("Prune 뒤 Used = %d, want 7", got)
+	}
+}